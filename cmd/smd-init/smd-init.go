@@ -38,8 +38,8 @@ import (
 )
 
 const APP_VERSION = "1"
-const SCHEMA_VERSION = 21
-const SCHEMA_STEPS = 23
+const SCHEMA_VERSION = 35
+const SCHEMA_STEPS = 37
 const DELETE_DUPLICATE_DETECTED_EVENTS_STEP = 23
 
 var dbName string
@@ -0,0 +1,96 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"time"
+
+	"github.com/Cray-HPE/hms-smd/v2/internal/hmsds"
+)
+
+/////////////////////////////////////////////////////////////////////////////
+// Database change feed.
+//
+// Triggers in the database send a notification whenever a table HSM keeps
+// a copy of in memory changes, whichever instance of HSM made the change.
+// Each instance listens for them and refreshes (or invalidates) its copy
+// right away instead of waiting for the next periodic refresh, which then
+// only runs occasionally as a fallback.
+//
+// Group and partition membership is not kept in memory, it is read from the
+// database for each request, so there is nothing to refresh for it.
+/////////////////////////////////////////////////////////////////////////////
+
+// How often the periodic refreshes run while the change feed is working.
+const changeFeedFallbackInterval = 5 * time.Minute
+
+// Start listening for changes.  If that isn't possible the periodic
+// refreshes keep running at their usual rate.
+func (s *SmD) ChangeFeed() {
+	changes, err := s.db.ListenChanges()
+	if err != nil {
+		s.LogAlways("ChangeFeed(): Not listening for changes, using periodic refresh only: %s", err)
+		return
+	}
+	s.changeFeed.Store(true)
+	go func() {
+		for table := range changes {
+			s.handleChange(table)
+		}
+		s.changeFeed.Store(false)
+		s.LogAlways("ChangeFeed(): Stopped listening for changes.")
+	}()
+}
+
+// Refresh the in-memory copies of the changed table.
+func (s *SmD) handleChange(table string) {
+	s.Log(LOG_DEBUG, "handleChange(): %s changed", table)
+	all := table == hmsds.ChangedAll
+	if all || table == hmsds.ChangedSCNSubscriptions {
+		if err := s.refreshSCNSubscriptions(); err != nil {
+			s.LogAlways("handleChange(): SCN subscription lookup failure: %s", err)
+		}
+	}
+	if all || table == hmsds.ChangedCompEndpoints ||
+		table == hmsds.ChangedRFEndpoints {
+		if s.smapCompEP != nil {
+			s.smapCompEP.Invalidate()
+		}
+	}
+	if all || table == hmsds.ChangedEventRules {
+		if s.eventRules != nil {
+			if err := s.refreshEventRules(); err != nil {
+				s.LogAlways("handleChange(): Event rule lookup failure: %s", err)
+			}
+		}
+	}
+}
+
+// Get how long to wait between periodic refreshes that usually run every
+// 'interval'.
+func (s *SmD) refreshInterval(interval time.Duration) time.Duration {
+	if s.changeFeed.Load() {
+		return changeFeedFallbackInterval
+	}
+	return interval
+}
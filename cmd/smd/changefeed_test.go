@@ -0,0 +1,136 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Cray-HPE/hms-smd/v2/internal/hmsds"
+	"github.com/Cray-HPE/hms-smd/v2/pkg/sm"
+)
+
+// A SyncMap with the single key "a", whose value is the number of times it
+// was built.  Fails to build if *fail is set.
+func newCountingSyncMap(fail *bool) *SyncMap {
+	builds := 0
+	return NewSyncMap(func(smap *SyncMap) error {
+		if *fail {
+			return fmt.Errorf("DB error")
+		}
+		builds++
+		smap.valStore = map[string]interface{}{"a": builds}
+		smap.keyLookup = map[string]string{"key-a": "a"}
+		return nil
+	})
+}
+
+func TestSyncMapInvalidate(t *testing.T) {
+	fail := false
+	smap := newCountingSyncMap(&fail)
+	smap.TrySync(0)
+	if val, num := smap.LookupValue("a"); val != 1 || num != 1 {
+		t.Fatalf("FAIL: Expected 1 build; Received %v (sync %d)", val, num)
+	}
+
+	// Many invalidations, one rebuild on the next lookup.
+	smap.Invalidate()
+	smap.Invalidate()
+	if key, num := smap.LookupKey("key-a"); key != "a" || num != 2 {
+		t.Errorf("FAIL: Unexpected lookup after invalidate: %s (sync %d)", key, num)
+	}
+	if val, num := smap.LookupValue("a"); val != 2 || num != 2 {
+		t.Errorf("FAIL: Expected 2 builds; Received %v (sync %d)", val, num)
+	}
+
+	// A failed rebuild keeps the old mapping and tries again next time.
+	smap.Invalidate()
+	fail = true
+	if val, _ := smap.LookupValue("a"); val != 2 {
+		t.Errorf("FAIL: Expected old mapping; Received %v", val)
+	}
+	fail = false
+	if val, _ := smap.LookupValue("a"); val != 3 {
+		t.Errorf("FAIL: Expected rebuild; Received %v", val)
+	}
+}
+
+func TestChangeFeed(t *testing.T) {
+	changes := make(chan string)
+	results.ListenChanges.Return.changes = changes
+	results.ListenChanges.Return.err = nil
+	results.GetSCNSubscriptionsAll.Return.subs = &sm.SCNSubscriptionArray{
+		SubscriptionList: []sm.SCNSubscription{{
+			ID:     1,
+			Url:    "https://foo/scn",
+			States: []string{"Ready"},
+		}},
+	}
+	results.GetSCNSubscriptionsAll.Return.err = nil
+	fail := false
+	oldSmap := s.smapCompEP
+	s.smapCompEP = newCountingSyncMap(&fail)
+	s.smapCompEP.TrySync(0)
+	defer func() {
+		results.ListenChanges.Return.changes = nil
+		results.GetSCNSubscriptionsAll.Return.subs = nil
+		s.smapCompEP = oldSmap
+		s.scnSubs = sm.SCNSubscriptionArray{}
+		s.scnSubMap = SCNSubMap{}
+	}()
+
+	s.ChangeFeed()
+	if s.refreshInterval(30*time.Second) != changeFeedFallbackInterval {
+		t.Errorf("FAIL: Periodic refresh not slowed down")
+	}
+
+	// Unbuffered, so each is handled before the next is taken.
+	changes <- hmsds.ChangedSCNSubscriptions
+	changes <- hmsds.ChangedCompEndpoints
+	s.scnSubLock.Lock()
+	urls := s.scnSubMap[SCNMAP_STATE]["ready"]
+	s.scnSubLock.Unlock()
+	if len(urls) != 1 || urls[0].url != "https://foo/scn" {
+		t.Errorf("FAIL: SCN subscriptions not refreshed: %v", urls)
+	}
+	changes <- "hwinv_by_loc"
+	if val, _ := s.smapCompEP.LookupValue("a"); val != 2 {
+		t.Errorf("FAIL: ComponentEndpoints not invalidated")
+	}
+
+	// After missing notifications, everything is refreshed.
+	changes <- hmsds.ChangedAll
+	changes <- "hwinv_by_loc"
+	if val, _ := s.smapCompEP.LookupValue("a"); val != 3 {
+		t.Errorf("FAIL: ComponentEndpoints not invalidated")
+	}
+
+	close(changes)
+	for i := 0; i < 100 && s.changeFeed.Load(); i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if s.refreshInterval(30*time.Second) != 30*time.Second {
+		t.Errorf("FAIL: Periodic refresh not restored")
+	}
+}
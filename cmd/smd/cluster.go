@@ -0,0 +1,293 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Cray-HPE/hms-smd/v2/pkg/sm"
+)
+
+/////////////////////////////////////////////////////////////////////////////
+// Coordination between HSM instances.
+//
+// Each instance records a heartbeat in the database.  The instances with a
+// recent one are the live members of the cluster and divide per-endpoint
+// work (orphaned discovery and polling jobs, Redfish subscription checks)
+// between themselves by consistent hashing on the endpoint ID, so each is
+// done by one instance and only the share of a member that comes or goes
+// moves.  Maintenance tasks that should only run in one place are done by
+// the leader, the instance holding a Postgres advisory lock, which passes
+// to another instance if the leader's DB session ends.
+/////////////////////////////////////////////////////////////////////////////
+
+const (
+	clusterHeartbeatDefault = 10                        // Seconds
+	clusterLeaderLockKey    = int64(0x534d442d4c454144) // "SMD-LEAD"
+	clusterRingReplicas     = 64                        // Points on the hash ring per member
+	clusterMissedHeartbeats = 3                         // Before a member is no longer live
+	clusterPruneAge         = time.Hour
+)
+
+// A point on the consistent hash ring, owned by member 'id'.
+type clusterRingPoint struct {
+	hash uint64
+	id   string
+}
+
+type smdCluster struct {
+	s        *SmD
+	id       string // serviceName of this instance
+	started  time.Time
+	interval time.Duration
+
+	lock    sync.RWMutex
+	leader  bool
+	members []*sm.ServiceInstance
+	ring    []clusterRingPoint // Sorted by hash
+}
+
+func NewCluster(s *SmD, id string, interval time.Duration) *smdCluster {
+	c := new(smdCluster)
+	c.s = s
+	c.id = id
+	c.started = time.Now()
+	c.interval = interval
+	return c
+}
+
+// Record the first heartbeat so the cluster view is in place before any
+// work is divided up, then keep it going in the background.
+func (c *smdCluster) Start() {
+	c.heartbeat()
+	go func() {
+		for {
+			time.Sleep(c.interval)
+			c.heartbeat()
+		}
+	}()
+}
+
+// Retake (or keep) the leader lock, record our heartbeat and refresh the
+// view of the live members.
+func (c *smdCluster) heartbeat() {
+	leader, err := c.s.db.TryAdvisoryLock(clusterLeaderLockKey)
+	if err != nil {
+		c.s.LogAlways("Cluster: Failed to get leader lock: %s", err)
+		leader = false
+	}
+	if err := c.s.db.UpsertServiceInstance(c.id, c.started, leader); err != nil {
+		c.s.LogAlways("Cluster: Failed to record heartbeat: %s", err)
+	}
+	maxAge := c.interval * clusterMissedHeartbeats
+	members, err := c.s.db.GetServiceInstances(maxAge)
+	if err != nil {
+		c.s.LogAlways("Cluster: Failed to get members: %s", err)
+		// Keep the last view of the members, but we can't be sure we
+		// are still the leader.
+		members = nil
+	}
+	if leader {
+		num, err := c.s.db.DeleteServiceInstancesStale(clusterPruneAge)
+		if err != nil {
+			c.s.LogAlways("Cluster: Failed to prune members: %s", err)
+		} else if num > 0 {
+			c.s.Log(LOG_INFO, "Cluster: Pruned %d stale members", num)
+		}
+	}
+	c.update(leader, members)
+}
+
+// Install a new view of the cluster.  Nil members keeps the current ones.
+func (c *smdCluster) update(leader bool, members []*sm.ServiceInstance) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if leader != c.leader {
+		if leader {
+			c.s.LogAlways("Cluster: %s is now the leader", c.id)
+		} else {
+			c.s.LogAlways("Cluster: %s is no longer the leader", c.id)
+		}
+		c.leader = leader
+	}
+	if members == nil {
+		return
+	}
+	c.members = members
+
+	live := make([]string, 0, len(members))
+	for _, m := range members {
+		if m.Live {
+			live = append(live, m.ID)
+		}
+	}
+	// We're a member even if our heartbeat didn't make it.
+	found := false
+	for _, id := range live {
+		if id == c.id {
+			found = true
+			break
+		}
+	}
+	if !found {
+		live = append(live, c.id)
+	}
+	sort.Strings(live)
+	if !c.sameMembers(live) {
+		c.s.LogAlways("Cluster: Live members are now %v", live)
+		c.ring = newClusterRing(live)
+	}
+}
+
+// True if the ring is made up of exactly the members 'ids' (sorted).
+// Must hold lock.
+func (c *smdCluster) sameMembers(ids []string) bool {
+	if len(c.ring) != len(ids)*clusterRingReplicas {
+		return false
+	}
+	seen := make(map[string]bool, len(ids))
+	for _, p := range c.ring {
+		seen[p.id] = true
+	}
+	for _, id := range ids {
+		if !seen[id] {
+			return false
+		}
+	}
+	return true
+}
+
+// Build the consistent hash ring for the members 'ids'.
+func newClusterRing(ids []string) []clusterRingPoint {
+	ring := make([]clusterRingPoint, 0, len(ids)*clusterRingReplicas)
+	for _, id := range ids {
+		for i := 0; i < clusterRingReplicas; i++ {
+			ring = append(ring, clusterRingPoint{
+				hash: clusterHash(id + "#" + strconv.Itoa(i)),
+				id:   id,
+			})
+		}
+	}
+	sort.Slice(ring, func(i, j int) bool {
+		if ring[i].hash == ring[j].hash {
+			return ring[i].id < ring[j].id
+		}
+		return ring[i].hash < ring[j].hash
+	})
+	return ring
+}
+
+// FNV-1a spreads similar keys (e.g. neighboring xnames) poorly on its own,
+// so mix the bits with the MurmurHash3 finalizer.
+func clusterHash(key string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	x := h.Sum64()
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	return x
+}
+
+// Get the member that owns 'key', the first point on the ring at or after
+// its hash.  Empty if there are no members yet.
+func (c *smdCluster) owner(key string) string {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	if len(c.ring) == 0 {
+		return ""
+	}
+	h := clusterHash(key)
+	i := sort.Search(len(c.ring), func(i int) bool {
+		return c.ring[i].hash >= h
+	})
+	if i == len(c.ring) {
+		i = 0
+	}
+	return c.ring[i].id
+}
+
+// Get the cluster as seen by this instance.
+func (c *smdCluster) Status() *sm.ClusterStatus {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	status := &sm.ClusterStatus{
+		ID:      c.id,
+		Leader:  c.leader,
+		Members: make([]*sm.ServiceInstance, 0, len(c.members)),
+	}
+	for _, m := range c.members {
+		mc := *m
+		status.Members = append(status.Members, &mc)
+	}
+	return status
+}
+
+// True if this instance should run the maintenance tasks that only need to
+// be done in one place.  Always true if not coordinating with others.
+func (s *SmD) isLeader() bool {
+	if s.cluster == nil {
+		return true
+	}
+	s.cluster.lock.RLock()
+	defer s.cluster.lock.RUnlock()
+	return s.cluster.leader
+}
+
+// True if this instance should do the work for the endpoint (or other
+// key) 'id'.  Always true if not coordinating with others or if the
+// members aren't known yet.
+func (s *SmD) ownsEndpoint(id string) bool {
+	if s.cluster == nil {
+		return true
+	}
+	owner := s.cluster.owner(id)
+	return owner == "" || owner == s.cluster.id
+}
+
+// True if this instance should pick up the orphaned job 'job'.  State
+// Redfish Poll jobs go to the owner of the component's BMC so its polls
+// can still be batched.
+func (s *SmD) ownsJob(job *sm.Job) bool {
+	switch job.Type {
+	case sm.JobTypeSRFP:
+		data, ok := job.Data.(*sm.SrfpJobData)
+		if ok && s.rfPoller != nil {
+			t := rfPollTarget{
+				compId: data.CompId,
+				bmc:    s.rfPoller.endpointOf(data.CompId),
+			}
+			return s.ownsEndpoint(t.key())
+		}
+	}
+	return true
+}
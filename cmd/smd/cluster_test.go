@@ -0,0 +1,198 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Cray-HPE/hms-smd/v2/pkg/sm"
+)
+
+func TestClusterRing(t *testing.T) {
+	c := NewCluster(s, "smd-1", 10*time.Second)
+	if owner := c.owner("x0c0s0b0"); owner != "" {
+		t.Errorf("FAIL: Expected no owner before any members; Received %s", owner)
+	}
+
+	two := []*sm.ServiceInstance{{ID: "smd-1", Live: true}, {ID: "smd-2", Live: true}}
+	c.update(false, two)
+	owned := map[string]int{}
+	before := map[string]string{}
+	for i := 0; i < 1000; i++ {
+		bmc := fmt.Sprintf("x%dc0s0b0", i)
+		before[bmc] = c.owner(bmc)
+		owned[before[bmc]]++
+	}
+	// Roughly even
+	if owned["smd-1"] < 300 || owned["smd-2"] < 300 {
+		t.Errorf("FAIL: Uneven split: %v", owned)
+	}
+
+	// A stale member doesn't own anything.  A new one only takes over
+	// endpoints from the others.
+	three := append(two, &sm.ServiceInstance{ID: "smd-3", Live: true},
+		&sm.ServiceInstance{ID: "smd-4", Live: false})
+	c.update(false, three)
+	owned = map[string]int{}
+	for bmc, prev := range before {
+		owner := c.owner(bmc)
+		owned[owner]++
+		if owner != prev && owner != "smd-3" {
+			t.Errorf("FAIL: %s moved from %s to %s", bmc, prev, owner)
+		}
+	}
+	if owned["smd-3"] < 200 || owned["smd-4"] != 0 {
+		t.Errorf("FAIL: Unexpected split: %v", owned)
+	}
+
+	// We're always a member, even if our own heartbeat failed.
+	c.update(false, []*sm.ServiceInstance{{ID: "smd-2", Live: true}})
+	owned = map[string]int{}
+	for bmc := range before {
+		owned[c.owner(bmc)]++
+	}
+	if owned["smd-1"] == 0 || owned["smd-2"] == 0 || len(owned) != 2 {
+		t.Errorf("FAIL: Unexpected split: %v", owned)
+	}
+}
+
+func TestClusterHeartbeat(t *testing.T) {
+	s.cluster = NewCluster(s, "smd-1", 10*time.Second)
+	defer func() { s.cluster = nil }()
+
+	results.TryAdvisoryLock.Return.locked = true
+	results.TryAdvisoryLock.Return.err = nil
+	results.UpsertServiceInstance.Return.err = nil
+	results.GetServiceInstances.Return.insts = []*sm.ServiceInstance{
+		{ID: "smd-1", Leader: true, Live: true},
+		{ID: "smd-2", Live: true},
+	}
+	results.GetServiceInstances.Return.err = nil
+	results.DeleteServiceInstancesStale.Input.maxAge = 0
+	results.DeleteServiceInstancesStale.Return.err = nil
+	defer func() {
+		results.TryAdvisoryLock.Return.locked = false
+		results.GetServiceInstances.Return.insts = nil
+	}()
+
+	s.cluster.heartbeat()
+	if results.TryAdvisoryLock.Input.key != clusterLeaderLockKey {
+		t.Errorf("FAIL: Unexpected lock key %x", results.TryAdvisoryLock.Input.key)
+	}
+	in := results.UpsertServiceInstance.Input
+	if in.id != "smd-1" || !in.leader || !in.started.Equal(s.cluster.started) {
+		t.Errorf("FAIL: Unexpected heartbeat: %+v", in)
+	}
+	if results.GetServiceInstances.Input.maxAge != 30*time.Second {
+		t.Errorf("FAIL: Unexpected max age %s", results.GetServiceInstances.Input.maxAge)
+	}
+	if results.DeleteServiceInstancesStale.Input.maxAge != clusterPruneAge {
+		t.Errorf("FAIL: Leader did not prune stale members")
+	}
+	if !s.isLeader() {
+		t.Errorf("FAIL: Expected to be the leader")
+	}
+	mine, theirs := 0, 0
+	for i := 0; i < 100; i++ {
+		if s.ownsEndpoint(fmt.Sprintf("x%dc0s0b0", i)) {
+			mine++
+		} else {
+			theirs++
+		}
+	}
+	if mine == 0 || theirs == 0 {
+		t.Errorf("FAIL: Work not shared: %d mine, %d theirs", mine, theirs)
+	}
+	status := s.cluster.Status()
+	if status.ID != "smd-1" || !status.Leader || len(status.Members) != 2 {
+		t.Errorf("FAIL: Unexpected status: %+v", status)
+	}
+
+	// Losing the DB keeps the members, but not leadership.
+	results.TryAdvisoryLock.Return.locked = false
+	results.TryAdvisoryLock.Return.err = fmt.Errorf("connection lost")
+	results.GetServiceInstances.Return.err = fmt.Errorf("connection lost")
+	results.DeleteServiceInstancesStale.Input.maxAge = 0
+	s.cluster.heartbeat()
+	results.TryAdvisoryLock.Return.err = nil
+	results.GetServiceInstances.Return.err = nil
+	if s.isLeader() {
+		t.Errorf("FAIL: Still the leader after losing the lock")
+	}
+	if results.DeleteServiceInstancesStale.Input.maxAge != 0 {
+		t.Errorf("FAIL: Pruned stale members when not the leader")
+	}
+	if len(s.cluster.Status().Members) != 2 {
+		t.Errorf("FAIL: Members lost")
+	}
+}
+
+func TestClusterOwnsJob(t *testing.T) {
+	s.rfPoller = NewRFPoller(s, defaultRFPollPolicy())
+	s.cluster = NewCluster(s, "smd-1", 10*time.Second)
+	defer func() {
+		s.rfPoller = nil
+		s.cluster = nil
+	}()
+	results.GetCompEndpointIDs.Funcs.getID = GetCompEpIDsGenGetID
+	results.GetCompEndpointIDs.Funcs.returnIDs = GetCompEpIDsGenReturnIDs(nil)
+
+	s.cluster.update(false, []*sm.ServiceInstance{
+		{ID: "smd-1", Live: true},
+		{ID: "smd-2", Live: true},
+	})
+	// Find a node owned by each instance.
+	mine, theirs := "", ""
+	for i := 0; i < 100 && (mine == "" || theirs == ""); i++ {
+		id := fmt.Sprintf("x%dc0s0b0n0", i)
+		if s.cluster.owner(id) == "smd-1" {
+			mine = id
+		} else {
+			theirs = id
+		}
+	}
+	job := func(id string) *sm.Job {
+		return &sm.Job{
+			JobData: sm.JobData{Type: sm.JobTypeSRFP},
+			Data:    &sm.SrfpJobData{CompId: id},
+		}
+	}
+	if !s.ownsJob(job(mine)) || s.ownsJob(job(theirs)) {
+		t.Errorf("FAIL: Unexpected job ownership")
+	}
+
+	// Polling another instance's node only leaves a job for it.
+	results.InsertJob.Input.j = nil
+	results.InsertJob.Return.id = "job1"
+	results.InsertJob.Return.err = nil
+	s.rfPoller.add(theirs, "Standby", -1)
+	if results.InsertJob.Input.j == nil || len(s.rfPoller.Jobs()) != 0 {
+		t.Errorf("FAIL: Expected job to be left for the other instance")
+	}
+	s.rfPoller.add(mine, "Standby", -1)
+	if len(s.rfPoller.Jobs()) != 1 {
+		t.Errorf("FAIL: Expected job to be run by this instance")
+	}
+}
@@ -0,0 +1,78 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"time"
+)
+
+////////////////////////////////////////////////////////////////////////////
+//
+// Component history - Changes to component state, flag, enabled, role and
+// software status are recorded by the database as they are made, along
+// with who asked for them.  Old entries are pruned here by age and count.
+//
+////////////////////////////////////////////////////////////////////////////
+
+const (
+	compHistMaxDefault    = 1000000 // entries, 0 is no limit
+	compHistAgeMaxDefault = 90      // days
+
+	// How often old entries are pruned.
+	compHistPruneInterval = 5 * time.Minute
+
+	// Sources recorded for changes HSM makes on its own.  Changes from
+	// Redfish events also include the BMC that sent them.
+	compHistSourceRFEvent = "RedfishEvent"
+	compHistSourceRFPoll  = "RedfishPoll"
+)
+
+// Remove component history entries older than the maximum age or beyond
+// the maximum number of entries.
+func (s *SmD) pruneCompHistory() error {
+	before := time.Now().AddDate(0, 0, -s.compHistAgeMax)
+	num, err := s.db.PruneCompHistory(before, s.compHistMax)
+	if err != nil {
+		return err
+	}
+	if num > 0 {
+		s.Log(LOG_INFO, "pruneCompHistory(): Removed %d history entries", num)
+	}
+	return nil
+}
+
+// Periodically prune the component history.  Only the leader prunes.
+func (s *SmD) CompHistoryPrune() {
+	go func() {
+		for {
+			if !s.isLeader() {
+				time.Sleep(compHistPruneInterval)
+				continue
+			}
+			if err := s.pruneCompHistory(); err != nil {
+				s.LogAlways("CompHistoryPrune(): Prune failure: %s", err)
+			}
+			time.Sleep(compHistPruneInterval)
+		}
+	}()
+}
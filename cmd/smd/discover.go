@@ -198,6 +198,9 @@ func (s *SmD) doDiscovery(rfEP *rf.RedfishEP) {
 	// Create/update HMS-level components from the retrieved discovery data
 	// from Redfish.  This also inserts the data into the database.
 	s.updateFromRfEndpoint(rfEP)
+
+	// Have the endpoint send its events to us, if we manage subscriptions.
+	s.rfSubscribeDiscovered(rfEP)
 }
 
 // Back end that writes one RedfishEndpoint's worth of structs to the DB
@@ -404,6 +407,33 @@ func (s *SmD) updateFromRfEndpoint(rfEP *rf.RedfishEP) error {
 		}
 	}
 
+	// Store firmware versions and record any that have changed.
+	err = s.updateHWInvFirmware(rfEP, hwlocs)
+	if err != nil {
+		s.LogAlways("updateHWInvFirmware(%s): Error storing: %s", rfEP.ID, err)
+		if savedErr == nil {
+			return err
+		}
+	}
+
+	// Store fans and record any that have been replaced.
+	err = s.updateHWInvFans(rfEP, hwlocs)
+	if err != nil {
+		s.LogAlways("updateHWInvFans(%s): Error storing: %s", rfEP.ID, err)
+		if savedErr == nil {
+			return err
+		}
+	}
+
+	// Store the subcomponents that raised each component's flag.
+	err = s.updateCompHealth(rfEP, comps)
+	if err != nil {
+		s.LogAlways("updateCompHealth(%s): Error storing: %s", rfEP.ID, err)
+		if savedErr == nil {
+			return err
+		}
+	}
+
 	// Return "main" error as far as whether discovered info could be written.
 	return savedErr
 }
@@ -782,6 +812,19 @@ func (s *SmD) DiscoverHWInvByLocArray(rfEP *rf.RedfishEP) ([]*sm.HWInvByLoc, err
 				hwlocs = append(hwlocs, hwloc)
 			}
 		}
+		for _, pcieDeviceEP := range sysEP.PCIeDevices.OIDs {
+			hwloc, err := s.DiscoverHWInvByLocPCIeDevice(pcieDeviceEP)
+			if err != nil {
+				if err == base.ErrHMSTypeInvalid || err == base.ErrHMSTypeUnsupported {
+					if err != base.ErrHMSTypeInvalid {
+						save_err = err
+					}
+					continue
+				}
+				return nil, err
+			}
+			hwlocs = append(hwlocs, hwloc)
+		}
 	}
 	// RackPDUs, from Redfish "PowerDistribution" objects
 	for _, pduEP := range rfEP.RackPDUs.OIDs {
@@ -890,10 +933,14 @@ func (s *SmD) GenerateHWInvHist(hwlocs []*sm.HWInvByLoc) error {
 			EventType: sm.HWInvHistEventTypeDetected,
 		}
 		// Only create a new 'detected' event if the previous event for that location
-		// is not a Location+FRUID+EventType duplicate.
+		// is not a Location+FRUID+EventType duplicate.  A firmware update
+		// or fan replacement doesn't move the FRU, so it counts as already
+		// detected.
 		if lastHist, ok := lhsMap[hwloc.ID]; !ok ||
 		   lastHist.FruId != hwloc.PopulatedFRU.FRUID ||
-		   lastHist.EventType != sm.HWInvHistEventTypeDetected {
+		   (lastHist.EventType != sm.HWInvHistEventTypeDetected &&
+		    lastHist.EventType != sm.HWInvHistEventTypeFirmwareUpdated &&
+		    lastHist.EventType != sm.HWInvHistEventTypeFanReplaced) {
 			hwhists = append(hwhists, &newHist)
 		}
 	}
@@ -904,6 +951,191 @@ func (s *SmD) GenerateHWInvHist(hwlocs []*sm.HWInvByLoc) error {
 	return err
 }
 
+////////////////////////////////////////////////////////////////////////////
+//
+// Discovery of firmware versions from the Redfish UpdateService
+//
+////////////////////////////////////////////////////////////////////////////
+
+// Create an array of HWInvFirmware entries from the FirmwareInventory and
+// SoftwareInventory collections discovered under the endpoint's
+// UpdateService.
+func (s *SmD) DiscoverHWInvFirmwareArray(rfEP *rf.RedfishEP) []*sm.HWInvFirmware {
+	fws := make([]*sm.HWInvFirmware, 0, 1)
+	if rfEP.UpdateService == nil {
+		return fws
+	}
+	for _, sis := range []rf.EpSoftwareInventories{
+		rfEP.UpdateService.FirmwareInventory,
+		rfEP.UpdateService.SoftwareInventory,
+	} {
+		for _, si := range sis.OIDs {
+			fw := sm.NewHWInvFirmware(si)
+			if fw == nil {
+				s.Log(LOG_INFO, "DiscoverHWInvFirmwareArray: %s: skipping %s: %s",
+					rfEP.ID, si.OdataID, si.LastStatus)
+				continue
+			}
+			if err := fw.VerifyNormalize(); err != nil {
+				s.LogAlways("DiscoverHWInvFirmwareArray: %s: skipping %s: %s",
+					rfEP.ID, si.OdataID, err)
+				continue
+			}
+			fws = append(fws, fw)
+		}
+	}
+	return fws
+}
+
+// Store the firmware versions reported by the endpoint and generate a
+// FirmwareUpdated hardware history event for any target whose version has
+// changed since it was last discovered.  History is tracked per FRU, so
+// targets that don't belong to a location with a FRU are not recorded.
+func (s *SmD) updateHWInvFirmware(rfEP *rf.RedfishEP, hwlocs []*sm.HWInvByLoc) error {
+	fws := s.DiscoverHWInvFirmwareArray(rfEP)
+	changed, err := s.db.UpdateHWInvFirmwareForRFEndpoint(rfEP.ID, fws)
+	if err != nil || len(changed) == 0 {
+		return err
+	}
+	fruMap := make(map[string]string, len(hwlocs))
+	for _, hwloc := range hwlocs {
+		if hwloc != nil && hwloc.PopulatedFRU != nil {
+			fruMap[hwloc.ID] = hwloc.PopulatedFRU.FRUID
+		}
+	}
+	hwhists := make([]*sm.HWInvHist, 0, len(changed))
+	seen := make(map[string]bool)
+	for _, fw := range changed {
+		s.LogAlways("Firmware '%s' for %s changed to version '%s'",
+			fw.ID, fw.RelatedID, fw.Version)
+		fruId, ok := fruMap[fw.RelatedID]
+		if !ok || seen[fw.RelatedID] {
+			continue
+		}
+		seen[fw.RelatedID] = true
+		hwhists = append(hwhists, &sm.HWInvHist{
+			ID:        fw.RelatedID,
+			FruId:     fruId,
+			EventType: sm.HWInvHistEventTypeFirmwareUpdated,
+		})
+	}
+	if len(hwhists) > 0 {
+		err = s.db.InsertHWInvHists(hwhists)
+	}
+	return err
+}
+
+////////////////////////////////////////////////////////////////////////////
+//
+// Discovery of fans from Redfish Chassis Thermal/ThermalSubsystem
+//
+////////////////////////////////////////////////////////////////////////////
+
+// Create an array of HWInvFan entries from the fans discovered under the
+// endpoint's chassis.
+func (s *SmD) DiscoverHWInvFanArray(rfEP *rf.RedfishEP) []*sm.HWInvFan {
+	fans := make([]*sm.HWInvFan, 0, 1)
+	for _, chEP := range rfEP.Chassis.OIDs {
+		for _, f := range chEP.Fans.OIDs {
+			fan := sm.NewHWInvFan(f)
+			if fan == nil {
+				s.Log(LOG_INFO, "DiscoverHWInvFanArray: %s: skipping %s: %s",
+					rfEP.ID, f.OdataID, f.LastStatus)
+				continue
+			}
+			if err := fan.VerifyNormalize(); err != nil {
+				s.LogAlways("DiscoverHWInvFanArray: %s: skipping %s: %s",
+					rfEP.ID, f.OdataID, err)
+				continue
+			}
+			fans = append(fans, fan)
+		}
+	}
+	return fans
+}
+
+// Store the fans reported by the endpoint and generate a FanReplaced
+// hardware history event for each location where a fan's FRU has changed
+// since it was last discovered.  Fans have no xname, so the event is
+// recorded against the location (and FRU) the fan is installed in.
+func (s *SmD) updateHWInvFans(rfEP *rf.RedfishEP, hwlocs []*sm.HWInvByLoc) error {
+	fans := s.DiscoverHWInvFanArray(rfEP)
+	changed, err := s.db.UpdateHWInvFansForRFEndpoint(rfEP.ID, fans)
+	if err != nil || len(changed) == 0 {
+		return err
+	}
+	fruMap := make(map[string]string, len(hwlocs))
+	for _, hwloc := range hwlocs {
+		if hwloc != nil && hwloc.PopulatedFRU != nil {
+			fruMap[hwloc.ID] = hwloc.PopulatedFRU.FRUID
+		}
+	}
+	hwhists := make([]*sm.HWInvHist, 0, len(changed))
+	seen := make(map[string]bool)
+	for _, fan := range changed {
+		s.LogAlways("Fan '%s' in %s changed to FRU '%s' (%s)",
+			fan.ID, fan.ParentID, fan.FRUID, fan.Status)
+		fruId, ok := fruMap[fan.ParentID]
+		if !ok || seen[fan.ParentID] {
+			continue
+		}
+		seen[fan.ParentID] = true
+		hwhists = append(hwhists, &sm.HWInvHist{
+			ID:        fan.ParentID,
+			FruId:     fruId,
+			EventType: sm.HWInvHistEventTypeFanReplaced,
+		})
+	}
+	if len(hwhists) > 0 {
+		err = s.db.InsertHWInvHists(hwhists)
+	}
+	return err
+}
+
+////////////////////////////////////////////////////////////////////////////
+//
+// Component health - Subcomponents that raised a component's flag
+//
+////////////////////////////////////////////////////////////////////////////
+
+// Replace the stored health contributors of each discovered component with
+// those found during this discovery.  Components that are now healthy have
+// their contributors cleared.
+func (s *SmD) updateCompHealth(rfEP *rf.RedfishEP, comps *base.ComponentArray) error {
+	if comps == nil {
+		return nil
+	}
+	found := make(map[string]rf.HealthContributors)
+	for _, chEP := range rfEP.Chassis.OIDs {
+		found[chEP.ID] = chEP.HealthContributors
+	}
+	for _, sysEP := range rfEP.Systems.OIDs {
+		found[sysEP.ID] = sysEP.HealthContributors
+	}
+	for _, mEP := range rfEP.Managers.OIDs {
+		found[mEP.ID] = mEP.HealthContributors
+	}
+	contribs := make(map[string][]*sm.CompHealthContributor)
+	for _, comp := range comps.Components {
+		hcs, ok := found[comp.ID]
+		if !ok {
+			continue
+		}
+		list := make([]*sm.CompHealthContributor, 0, len(hcs))
+		seen := make(map[string]bool)
+		for _, hc := range hcs {
+			// Keyed by Redfish URI, so skip any we can't store.
+			if hc.OdataID == "" || seen[hc.OdataID] {
+				continue
+			}
+			seen[hc.OdataID] = true
+			list = append(list, sm.NewCompHealthContributor(comp.ID, hc))
+		}
+		contribs[comp.ID] = list
+	}
+	return s.db.SetCompHealthContributors(contribs)
+}
+
 // Most components above nodes except controllers/BMCs are
 // Redfish "Chassis", objects a catch all for most physical enclosure
 // types.  Use the annotated data retrieved from the parent Redfish
@@ -1356,6 +1588,55 @@ func (s *SmD) DiscoverHWInvByLocNodeHsnNic(networkAdapterEP *rf.EpNetworkAdapter
 	return hwloc, nil
 }
 
+// HMS NICs, storage controllers and accelerators, based on info retrieved
+// by Redfish PCIeDevice objects and their PCIeFunctions.
+func (s *SmD) DiscoverHWInvByLocPCIeDevice(pcieDeviceEP *rf.EpPCIeDevice) (*sm.HWInvByLoc, error) {
+	if pcieDeviceEP.LastStatus == rf.RedfishSubtypeNoSupport {
+		s.LogAlways("DiscoverHWInvByLocPCIeDevice: EP: %s RF Subtype %s "+
+			"not supported.", pcieDeviceEP.RfEndpointID, pcieDeviceEP.RedfishSubtype)
+		return nil, base.ErrHMSTypeUnsupported
+	} else if pcieDeviceEP.LastStatus != rf.DiscoverOK {
+		s.LogAlways("DiscoverHWInvByLocPCIeDevice: Saw EP with bad status: %s",
+			pcieDeviceEP.LastStatus)
+		return nil, base.ErrHMSTypeInvalid
+	}
+	hwloc := new(sm.HWInvByLoc)
+	hwloc.ID = pcieDeviceEP.ID
+	hwloc.Type = pcieDeviceEP.Type
+	hwloc.Ordinal = pcieDeviceEP.Ordinal
+	hwloc.Status = pcieDeviceEP.Status
+	if hwloc.Status != "Empty" && pcieDeviceEP.FRUID != "" {
+		hwfru, err := s.DiscoverHWInvByFRUPCIeDevice(pcieDeviceEP)
+		if err != nil {
+			return nil, err
+		}
+		hwloc.PopulatedFRU = hwfru
+	}
+	switch xnametypes.ToHMSType(hwloc.Type) {
+	case xnametypes.NodeNic:
+		hwloc.HMSNodeNICLocationInfo = &pcieDeviceEP.PCIeDeviceRF.PCIeDeviceLocationInfoRF
+		hwloc.HWInventoryByLocationType = sm.HWInvByLocNodeNIC
+	case xnametypes.StorageGroup:
+		hwloc.HMSStorageGroupLocationInfo = &pcieDeviceEP.PCIeDeviceRF.PCIeDeviceLocationInfoRF
+		hwloc.HWInventoryByLocationType = sm.HWInvByLocStorageGroup
+	case xnametypes.NodeAccel:
+		accelInfo := rf.ProcessorLocationInfoRF{
+			Id:          pcieDeviceEP.PCIeDeviceRF.Id,
+			Name:        pcieDeviceEP.PCIeDeviceRF.Name,
+			Description: pcieDeviceEP.PCIeDeviceRF.Description,
+		}
+		hwloc.HMSNodeAccelLocationInfo = &accelInfo
+		hwloc.HWInventoryByLocationType = sm.HWInvByLocNodeAccel
+	case xnametypes.HMSTypeInvalid:
+		err := base.ErrHMSTypeInvalid
+		return nil, err
+	default:
+		err := base.ErrHMSTypeUnsupported
+		return nil, err
+	}
+	return hwloc, nil
+}
+
 ////////////////////////////////////////////////////////////////////////////
 //
 // Discovery: HW Inventory FRU info
@@ -1792,6 +2073,54 @@ func (s *SmD) DiscoverHWInvByFRUNodeHsnNic(networkAdapterEP *rf.EpNetworkAdapter
 	return hwfru, nil
 }
 
+// HMS NIC, storage controller and accelerator FRU info, based on info
+// retrieved by Redfish PCIeDevice objects.
+func (s *SmD) DiscoverHWInvByFRUPCIeDevice(pcieDeviceEP *rf.EpPCIeDevice) (*sm.HWInvByFRU, error) {
+	if pcieDeviceEP.LastStatus == rf.RedfishSubtypeNoSupport {
+		s.LogAlways("DiscoverHWInvByFRUPCIeDevice: EP: %s RF Subtype %s "+
+			"not supported.", pcieDeviceEP.RfEndpointID, pcieDeviceEP.RedfishSubtype)
+		return nil, base.ErrHMSTypeUnsupported
+	} else if pcieDeviceEP.LastStatus != rf.DiscoverOK {
+		s.LogAlways("DiscoverHWInvByFRUPCIeDevice: Saw EP with bad status: %s",
+			pcieDeviceEP.LastStatus)
+		return nil, base.ErrHMSTypeInvalid
+	}
+	hwfru := new(sm.HWInvByFRU)
+	if pcieDeviceEP.FRUID == "" {
+		return nil, sm.ErrHWFRUIDInvalid
+	}
+	hwfru.FRUID = pcieDeviceEP.FRUID
+	hwfru.Type = pcieDeviceEP.Type
+	hwfru.Subtype = pcieDeviceEP.Subtype
+
+	switch xnametypes.ToHMSType(hwfru.Type) {
+	case xnametypes.NodeNic:
+		hwfru.HMSNodeNICFRUInfo = &pcieDeviceEP.PCIeDeviceRF.PCIeDeviceFRUInfoRF
+		hwfru.HWInventoryByFRUType = sm.HWInvByFRUNodeNIC
+	case xnametypes.StorageGroup:
+		hwfru.HMSStorageGroupFRUInfo = &pcieDeviceEP.PCIeDeviceRF.PCIeDeviceFRUInfoRF
+		hwfru.HWInventoryByFRUType = sm.HWInvByFRUStorageGroup
+	case xnametypes.NodeAccel:
+		accelInfo := rf.ProcessorFRUInfoRF{
+			Manufacturer:  pcieDeviceEP.PCIeDeviceRF.Manufacturer,
+			Model:         pcieDeviceEP.PCIeDeviceRF.Model,
+			SerialNumber:  pcieDeviceEP.PCIeDeviceRF.SerialNumber,
+			PartNumber:    pcieDeviceEP.PCIeDeviceRF.PartNumber,
+			ProcessorType: "Accelerator",
+		}
+		hwfru.HMSNodeAccelFRUInfo = &accelInfo
+		hwfru.HWInventoryByFRUType = sm.HWInvByFRUNodeAccel
+	case xnametypes.HMSTypeInvalid:
+		err := base.ErrHMSTypeInvalid
+		return nil, err
+	default:
+		err := base.ErrHMSTypeUnsupported
+		return nil, err
+	}
+
+	return hwfru, nil
+}
+
 ////////////////////////////////////////////////////////////////////////////
 //
 // Discovery/creation of ServiceEndpoints from Redfish Endpoint data
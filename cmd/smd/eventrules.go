@@ -0,0 +1,630 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	base "github.com/Cray-HPE/hms-base/v2"
+	"github.com/Cray-HPE/hms-smd/v2/pkg/sm"
+	"github.com/Cray-HPE/hms-xname/xnametypes"
+)
+
+var ErrSmEventRuleParser = base.NewHMSError("smd", "Event rule Parser is not a known built-in parser")
+
+////////////////////////////////////////////////////////////////////////////
+//
+// Event rules - Declarative mapping of Redfish events to component state
+// and flag changes.  Rules are built in, read from a JSON file at start-up
+// and managed through the API.  An API rule replaces a file or built-in
+// rule of the same name, so a built-in can be changed or disabled without
+// a rebuild.
+//
+////////////////////////////////////////////////////////////////////////////
+
+// Parsers that event rules may name instead of a declarative action, for
+// events that need more than a lookup, e.g. a query of the BMC or a
+// recomputation of the flag.
+var eventRuleParsers = map[string]EventActionParser{
+	"AlertSystemPower":      AlertSystemPowerParser,
+	"ResourceInventory":     ResourceInventoryParser,
+	"ResourceStatusChanged": ResourceStatusChangedParser,
+}
+
+// Rules replacing the former hard-coded lookup table.  Each one is named
+// after the MessageId it matches so it can be overridden via the API.
+var eventRulesBuiltIn = []*sm.EventRule{
+	//
+	// Cray ResourceEvent/CrayAlerts power changes.  The args are the URI
+	// and the new state in either order.  Slots have their controllers
+	// (and when powering off, everything under them) updated as well.
+	//
+	{
+		Name:        "ResourcePowerStateChanged.Slot.On",
+		Description: "Mountain slot powered on",
+		Match: sm.EventRuleMatch{
+			MessageId:      "ResourcePowerStateChanged",
+			Registry:       "^(ResourceEvent|CrayAlerts)?$",
+			MessageArgs:    []string{`^\s*on\s*$`},
+			ControllerType: "^ChassisBMC$",
+		},
+		Action: sm.EventRuleAction{
+			State:      base.StateOn.String(),
+			Target:     sm.EventRuleTargetURI,
+			Children:   sm.EventRuleChildrenSlot,
+			Rediscover: true,
+		},
+	},
+	{
+		Name:        "ResourcePowerStateChanged.Slot.Off",
+		Description: "Mountain slot powered off",
+		Match: sm.EventRuleMatch{
+			MessageId:      "ResourcePowerStateChanged",
+			Registry:       "^(ResourceEvent|CrayAlerts)?$",
+			MessageArgs:    []string{`^\s*off\s*$`},
+			ControllerType: "^ChassisBMC$",
+		},
+		Action: sm.EventRuleAction{
+			State:    base.StateOff.String(),
+			Target:   sm.EventRuleTargetURI,
+			Children: sm.EventRuleChildrenSlot,
+		},
+	},
+	{
+		Name:        "ResourcePowerStateChanged.On",
+		Description: "Node, router or PDU outlet powered on",
+		Match: sm.EventRuleMatch{
+			MessageId:      "ResourcePowerStateChanged",
+			Registry:       "^(ResourceEvent|CrayAlerts)?$",
+			MessageArgs:    []string{`^\s*on\s*$`},
+			ControllerType: "^(NodeBMC|RouterBMC|CabinetPDUController)$",
+		},
+		Action: sm.EventRuleAction{
+			State:       base.StateOn.String(),
+			Target:      sm.EventRuleTargetURI,
+			HWInvUpdate: sm.EventRuleHWInvStandard,
+		},
+	},
+	{
+		Name:        "ResourcePowerStateChanged.Off",
+		Description: "Node, router or PDU outlet powered off",
+		Match: sm.EventRuleMatch{
+			MessageId:      "ResourcePowerStateChanged",
+			Registry:       "^(ResourceEvent|CrayAlerts)?$",
+			MessageArgs:    []string{`^\s*off\s*$`},
+			ControllerType: "^(NodeBMC|RouterBMC|CabinetPDUController)$",
+		},
+		Action: sm.EventRuleAction{
+			State:  base.StateOff.String(),
+			Target: sm.EventRuleTargetURI,
+		},
+	},
+	//
+	// Intel BMC firmware and HPE iLO.  Id in OriginOfCondition.
+	//
+	{
+		Name:        "SystemPowerOn",
+		Description: "Intel BMC system powered on",
+		Match:       sm.EventRuleMatch{MessageId: "SystemPowerOn"},
+		Action: sm.EventRuleAction{
+			State:       base.StateOn.String(),
+			Target:      sm.EventRuleTargetOrigin,
+			HWInvUpdate: sm.EventRuleHWInvStandard,
+		},
+	},
+	{
+		Name:        "SystemPowerOff",
+		Description: "Intel BMC system powered off",
+		Match:       sm.EventRuleMatch{MessageId: "SystemPowerOff"},
+		Action: sm.EventRuleAction{
+			State:  base.StateOff.String(),
+			Target: sm.EventRuleTargetOrigin,
+		},
+	},
+	{
+		Name:        "ServerPoweredOn",
+		Description: "HPE iLO server powered on",
+		Match:       sm.EventRuleMatch{MessageId: "ServerPoweredOn"},
+		Action: sm.EventRuleAction{
+			State:       base.StateOn.String(),
+			Target:      sm.EventRuleTargetOrigin,
+			HWInvUpdate: sm.EventRuleHWInvStandard,
+		},
+	},
+	{
+		Name:        "ServerPoweredOff",
+		Description: "HPE iLO server powered off",
+		Match:       sm.EventRuleMatch{MessageId: "ServerPoweredOff"},
+		Action: sm.EventRuleAction{
+			State:  base.StateOff.String(),
+			Target: sm.EventRuleTargetOrigin,
+		},
+	},
+	//
+	// Gigabyte BMC firmware.  The state may have to be read from the BMC.
+	//
+	{
+		Name:        "Alert",
+		Description: "Gigabyte BMC system power change",
+		Match:       sm.EventRuleMatch{MessageId: "Alert"},
+		Action:      sm.EventRuleAction{Parser: "AlertSystemPower"},
+	},
+	{
+		Name:        "PowerStatusChange",
+		Description: "Gigabyte BMC system power change",
+		Match:       sm.EventRuleMatch{MessageId: "PowerStatusChange"},
+		Action:      sm.EventRuleAction{Parser: "AlertSystemPower"},
+	},
+	//
+	// Foxconn Paradise OpenBmc firmware.  OriginOfCondition is usually
+	// missing, in which case the node is n0.
+	//
+	{
+		Name:        "DCPowerOn",
+		Description: "Foxconn Paradise system powered on",
+		Match:       sm.EventRuleMatch{MessageId: "DCPowerOn"},
+		Action: sm.EventRuleAction{
+			State:        base.StateOn.String(),
+			Target:       sm.EventRuleTargetOrigin,
+			TargetSuffix: "n0",
+			HWInvUpdate:  sm.EventRuleHWInvFoxconn,
+		},
+	},
+	{
+		Name:        "DCPowerOff",
+		Description: "Foxconn Paradise system powered off",
+		Match:       sm.EventRuleMatch{MessageId: "DCPowerOff"},
+		Action: sm.EventRuleAction{
+			State:        base.StateOff.String(),
+			Target:       sm.EventRuleTargetOrigin,
+			TargetSuffix: "n0",
+		},
+	},
+	//
+	// Standard ResourceEvent health changes
+	//
+	{
+		Name:        "ResourceStatusChangedOK",
+		Description: "Resource health changed to OK",
+		Match:       sm.EventRuleMatch{MessageId: "ResourceStatusChangedOK"},
+		Action:      sm.EventRuleAction{Parser: "ResourceStatusChanged"},
+	},
+	{
+		Name:        "ResourceStatusChangedWarning",
+		Description: "Resource health changed to Warning",
+		Match:       sm.EventRuleMatch{MessageId: "ResourceStatusChangedWarning"},
+		Action:      sm.EventRuleAction{Parser: "ResourceStatusChanged"},
+	},
+	{
+		Name:        "ResourceStatusChangedCritical",
+		Description: "Resource health changed to Critical",
+		Match:       sm.EventRuleMatch{MessageId: "ResourceStatusChangedCritical"},
+		Action:      sm.EventRuleAction{Parser: "ResourceStatusChanged"},
+	},
+	//
+	// Resources added, removed or swapped.  The hardware inventory of the
+	// affected component is re-read.
+	//
+	{
+		Name:        "ResourceAdded",
+		Description: "Resource added",
+		Match:       sm.EventRuleMatch{MessageId: "ResourceAdded"},
+		Action:      sm.EventRuleAction{Parser: "ResourceInventory"},
+	},
+	{
+		Name:        "ResourceRemoved",
+		Description: "Resource removed",
+		Match:       sm.EventRuleMatch{MessageId: "ResourceRemoved"},
+		Action:      sm.EventRuleAction{Parser: "ResourceInventory"},
+	},
+	{
+		Name:        "ResourceChanged",
+		Description: "Resource changed",
+		Match:       sm.EventRuleMatch{MessageId: "ResourceChanged"},
+		Action:      sm.EventRuleAction{Parser: "ResourceInventory"},
+	},
+	{
+		Name:        "DriveInserted",
+		Description: "Intel BMC drive inserted",
+		Match:       sm.EventRuleMatch{MessageId: "DriveInserted"},
+		Action:      sm.EventRuleAction{Parser: "ResourceInventory"},
+	},
+	{
+		Name:        "DriveRemoved",
+		Description: "Intel BMC drive removed",
+		Match:       sm.EventRuleMatch{MessageId: "DriveRemoved"},
+		Action:      sm.EventRuleAction{Parser: "ResourceInventory"},
+	},
+}
+
+// An event rule with its regular expressions compiled.
+type eventRule struct {
+	*sm.EventRule
+	registry   *regexp.Regexp
+	regVersion *regexp.Regexp
+	origin     *regexp.Regexp
+	severity   *regexp.Regexp
+	ctrlType   *regexp.Regexp
+	msgArgs    []*regexp.Regexp
+}
+
+// Verify the rule and compile it for matching.
+func newEventRule(r *sm.EventRule) (*eventRule, error) {
+	if err := r.VerifyNormalize(); err != nil {
+		return nil, err
+	}
+	if r.Action.Parser != "" {
+		if _, ok := eventRuleParsers[r.Action.Parser]; !ok {
+			return nil, ErrSmEventRuleParser
+		}
+	}
+	// VerifyNormalize already compiled these once, so no errors here.
+	er := &eventRule{EventRule: r}
+	er.registry, _ = sm.CompileEventRuleRegex(r.Match.Registry)
+	er.regVersion, _ = sm.CompileEventRuleRegex(r.Match.RegistryVersion)
+	er.origin, _ = sm.CompileEventRuleRegex(r.Match.OriginOfCondition)
+	er.severity, _ = sm.CompileEventRuleRegex(r.Match.Severity)
+	er.ctrlType, _ = sm.CompileEventRuleRegex(r.Match.ControllerType)
+	for _, expr := range r.Match.MessageArgs {
+		re, _ := sm.CompileEventRuleRegex(expr)
+		if re != nil {
+			er.msgArgs = append(er.msgArgs, re)
+		}
+	}
+	return er, nil
+}
+
+// True if the processed event satisfies all of the rule's Match fields.
+// The MessageId has already been matched via the index.
+func (er *eventRule) match(pe *processedRFEvent) bool {
+	if er.registry != nil && !er.registry.MatchString(pe.Registry) {
+		return false
+	}
+	if er.regVersion != nil && !er.regVersion.MatchString(pe.RegVersion) {
+		return false
+	}
+	if er.origin != nil && !er.origin.MatchString(pe.Origin) {
+		return false
+	}
+	if er.severity != nil && !er.severity.MatchString(pe.Severity) {
+		return false
+	}
+	if er.ctrlType != nil {
+		ctrlType := xnametypes.GetHMSType(pe.RfEndppointID).String()
+		if !er.ctrlType.MatchString(ctrlType) {
+			return false
+		}
+	}
+	for _, re := range er.msgArgs {
+		found := false
+		for _, arg := range pe.MessageArgs {
+			if re.MatchString(arg) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// Order of precedence when rules have the same Priority.
+func eventRuleSourceRank(source string) int {
+	switch source {
+	case sm.EventRuleSourceAPI:
+		return 2
+	case sm.EventRuleSourceFile:
+		return 1
+	}
+	return 0
+}
+
+// The effective set of event rules, indexed by lower-case MessageId.
+type EventRuleSet struct {
+	lock      sync.RWMutex
+	fileRules []*sm.EventRule
+	rules     []*eventRule            // All effective rules, in match order
+	byMsgId   map[string][]*eventRule // Enabled rules, in match order
+}
+
+// Create a rule set with the built-in rules and those read from a file, if
+// any.  API rules are added with Set().
+func NewEventRuleSet(fileRules []*sm.EventRule) *EventRuleSet {
+	rs := &EventRuleSet{fileRules: fileRules}
+	rs.Set([]*sm.EventRule{})
+	return rs
+}
+
+// Rebuild the rule set using the given API rules.  Returns the names and
+// errors of any rules that could not be used; the rest still take effect.
+func (rs *EventRuleSet) Set(apiRules []*sm.EventRule) map[string]error {
+	bad := make(map[string]error)
+	byName := make(map[string]*sm.EventRule)
+	add := func(rules []*sm.EventRule, source string) {
+		for _, r := range rules {
+			rc := *r
+			rc.Source = source
+			byName[rc.Name] = &rc
+		}
+	}
+	add(eventRulesBuiltIn, sm.EventRuleSourceBuiltIn)
+	add(rs.fileRules, sm.EventRuleSourceFile)
+	add(apiRules, sm.EventRuleSourceAPI)
+
+	rules := make([]*eventRule, 0, len(byName))
+	for name, r := range byName {
+		er, err := newEventRule(r)
+		if err != nil {
+			bad[name] = err
+			continue
+		}
+		rules = append(rules, er)
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		if rules[i].Priority != rules[j].Priority {
+			return rules[i].Priority > rules[j].Priority
+		}
+		ri := eventRuleSourceRank(rules[i].Source)
+		rj := eventRuleSourceRank(rules[j].Source)
+		if ri != rj {
+			return ri > rj
+		}
+		return rules[i].Name < rules[j].Name
+	})
+	byMsgId := make(map[string][]*eventRule)
+	for _, er := range rules {
+		if er.Disabled {
+			continue
+		}
+		key := strings.ToLower(er.Match.MessageId)
+		byMsgId[key] = append(byMsgId[key], er)
+	}
+	rs.lock.Lock()
+	rs.rules = rules
+	rs.byMsgId = byMsgId
+	rs.lock.Unlock()
+	return bad
+}
+
+// Get the first enabled rule matching the event, nil if none.
+func (rs *EventRuleSet) Match(pe *processedRFEvent) *sm.EventRule {
+	rs.lock.RLock()
+	defer rs.lock.RUnlock()
+	for _, er := range rs.byMsgId[strings.ToLower(pe.MessageId)] {
+		if er.match(pe) {
+			return er.EventRule
+		}
+	}
+	return nil
+}
+
+// Get copies of all effective rules in the order they are matched.
+func (rs *EventRuleSet) GetAll() []*sm.EventRule {
+	rs.lock.RLock()
+	defer rs.lock.RUnlock()
+	rules := make([]*sm.EventRule, 0, len(rs.rules))
+	for _, er := range rs.rules {
+		rc := *er.EventRule
+		rules = append(rules, &rc)
+	}
+	return rules
+}
+
+// Get a copy of the effective rule with the given name, nil if none.
+func (rs *EventRuleSet) Get(name string) *sm.EventRule {
+	rs.lock.RLock()
+	defer rs.lock.RUnlock()
+	for _, er := range rs.rules {
+		if er.Name == name {
+			rc := *er.EventRule
+			return &rc
+		}
+	}
+	return nil
+}
+
+// Read event rules from a JSON file containing an EventRuleArray.  All
+// rules must be valid.
+func LoadEventRulesFile(path string) ([]*sm.EventRule, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	ruleArray := new(sm.EventRuleArray)
+	if err := json.Unmarshal(data, ruleArray); err != nil {
+		return nil, err
+	}
+	for _, r := range ruleArray.EventRules {
+		r.Source = sm.EventRuleSourceFile
+		if _, err := newEventRule(r); err != nil {
+			return nil, base.NewHMSError("smd",
+				"event rule '"+r.Name+"': "+err.Error())
+		}
+	}
+	return ruleArray.EventRules, nil
+}
+
+// Reload the API-managed rules from the database and rebuild the rule set.
+func (s *SmD) refreshEventRules() error {
+	apiRules, err := s.db.GetEventRulesAll()
+	if err != nil {
+		return err
+	}
+	bad := s.eventRules.Set(apiRules)
+	for name, err := range bad {
+		s.Log(LOG_INFO, "refreshEventRules(): Skipping rule '%s': %s", name, err)
+	}
+	return nil
+}
+
+// Spin off a thread to periodically refresh the API-managed event rules so
+// that changes made through other instances of HSM take effect.  Normally
+// they take effect right away through the change feed and this is a
+// fallback.
+func (s *SmD) EventRuleRefresh() {
+	go func() {
+		for {
+			if err := s.refreshEventRules(); err != nil {
+				s.LogAlways("EventRuleRefresh(): Lookup failure: %s", err)
+				time.Sleep(10 * time.Second)
+			} else {
+				time.Sleep(s.refreshInterval(30 * time.Second))
+			}
+		}
+	}()
+}
+
+/////////////////////////////////////////////////////////////////////////////
+// Applying a matched rule
+/////////////////////////////////////////////////////////////////////////////
+
+// Produce the CompUpdate for an event matched by a rule without a Parser.
+func (s *SmD) eventRuleUpdate(r *sm.EventRule, pe *processedRFEvent) (*CompUpdate, error) {
+	a := &r.Action
+	xname, err := s.eventRuleTarget(a, pe)
+	if err != nil {
+		return nil, err
+	}
+	u := new(CompUpdate)
+	u.ComponentIDs = append(u.ComponentIDs, xname)
+	if a.State != "" {
+		u.UpdateType = StateDataUpdate.String()
+		u.State = a.State
+	} else {
+		u.UpdateType = FlagOnlyUpdate.String()
+	}
+	u.Flag = a.Flag
+
+	op := ResourceOther
+	switch a.State {
+	case base.StateOn.String():
+		op = ResourceOn
+	case base.StateOff.String():
+		op = ResourceOff
+	}
+	switch a.Children {
+	case sm.EventRuleChildrenSlot:
+		switch xnametypes.GetHMSType(xname) {
+		case xnametypes.ComputeModule:
+			u.ComponentIDs = append(u.ComponentIDs, generateNcChildIDs(s, xname, op)...)
+		case xnametypes.RouterModule:
+			u.ComponentIDs = append(u.ComponentIDs, generateRcChildIDs(s, xname, op)...)
+		}
+	case sm.EventRuleChildrenController:
+		if xnametypes.IsHMSTypeController(xnametypes.GetHMSType(xname)) {
+			children, err := s.getChildIDsForRfEP(xname)
+			if err != nil && err != ErrSmMsgBadID {
+				s.LogAlways("eventRuleUpdate(%s, %s): DB error: %s",
+					r.Name, xname, err)
+			}
+			u.ComponentIDs = append(u.ComponentIDs, children...)
+		}
+	}
+	if op != ResourceOn {
+		return u, nil
+	}
+
+	// Kick off rediscovery for any BMCs that are getting powered on.
+	// This may fail at first if the BMC isn't ready yet but the
+	// LastDiscoveryStatus will get changed to a failed state which will
+	// cause a retry later.
+	if a.Rediscover {
+		for _, id := range u.ComponentIDs {
+			if xnametypes.IsHMSTypeController(xnametypes.GetHMSType(id)) {
+				rep, err := s.db.GetRFEndpointByID(id)
+				if err != nil {
+					s.Log(LOG_INFO, "eventRuleUpdate(%s): Lookup failure on %s: %s",
+						r.Name, id, err)
+				} else if rep != nil {
+					go s.discoverFromEndpoint(rep, 0, false)
+				}
+			}
+		}
+	}
+	// Update hwinv for nodes
+	if a.HWInvUpdate != sm.EventRuleHWInvNone &&
+		xnametypes.GetHMSType(xname) == xnametypes.Node {
+		cep, ep, err := s.getCompEPInfo(xname)
+		if err == nil {
+			if a.HWInvUpdate == sm.EventRuleHWInvFoxconn {
+				go s.doUpdateCompFoxconn(cep, ep)
+			} else {
+				go s.doUpdateCompHWInv(cep, ep)
+			}
+		}
+	}
+	return u, nil
+}
+
+// Find the xname of the component a matched event is about.
+func (s *SmD) eventRuleTarget(a *sm.EventRuleAction, pe *processedRFEvent) (string, error) {
+	var uri string
+	switch a.Target {
+	case sm.EventRuleTargetController:
+		uri = ""
+	case sm.EventRuleTargetOrigin:
+		uri = pe.Origin
+	default:
+		// Take the URI from the args, in any position, falling back to
+		// the origin.
+		uri = pe.Origin
+		for _, arg := range pe.MessageArgs {
+			if strings.HasPrefix(arg, "/") {
+				uri = arg
+			}
+		}
+	}
+	if uri == "" {
+		if a.Target != sm.EventRuleTargetController && a.TargetSuffix == "" {
+			return "", ErrSmMsgNoURI
+		}
+		xname := xnametypes.NormalizeHMSCompID(pe.RfEndppointID + a.TargetSuffix)
+		if xnametypes.GetHMSType(xname) == xnametypes.HMSTypeInvalid {
+			return "", ErrSmMsgBadID
+		}
+		return xname, nil
+	}
+	var xname string
+	var err error
+	if a.Target == sm.EventRuleTargetSubURI {
+		xname, _, err = s.getIDForSubURI(pe.RfEndppointID, uri)
+	} else {
+		xname, err = s.getIDForURI(pe.RfEndppointID, uri)
+	}
+	if err != nil {
+		return "", err
+	} else if xname == "" {
+		s.Log(LOG_INFO, "eventRuleTarget(%s, %s): Not found.",
+			pe.RfEndppointID, uri)
+		return "", ErrSmMsgNoID
+	}
+	return xname, nil
+}
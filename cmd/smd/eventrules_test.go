@@ -0,0 +1,291 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	base "github.com/Cray-HPE/hms-base/v2"
+	"github.com/Cray-HPE/hms-smd/v2/internal/hmsds"
+	"github.com/Cray-HPE/hms-smd/v2/pkg/sm"
+)
+
+func TestEventRuleSetMatch(t *testing.T) {
+	fileRules := []*sm.EventRule{{
+		// New vendor event, no built-in equivalent.
+		Name:  "OemNodeUp",
+		Match: sm.EventRuleMatch{MessageId: "NodeUp", Registry: "^Oem$"},
+		Action: sm.EventRuleAction{
+			State:        "on",
+			Target:       "controller",
+			TargetSuffix: "n0",
+		},
+	}, {
+		// Overrides the built-in of the same name.
+		Name:   "SystemPowerOff",
+		Match:  sm.EventRuleMatch{MessageId: "SystemPowerOff"},
+		Action: sm.EventRuleAction{Ignore: true},
+	}}
+	apiRules := []*sm.EventRule{{
+		// Disables the built-in of the same name.
+		Name:     "ServerPoweredOn",
+		Disabled: true,
+		Match:    sm.EventRuleMatch{MessageId: "ServerPoweredOn"},
+		Action:   sm.EventRuleAction{State: "On"},
+	}, {
+		// Higher priority than the file rule for critical events only.
+		Name:     "OemNodeUpCritical",
+		Priority: 10,
+		Match: sm.EventRuleMatch{
+			MessageId:   "NodeUp",
+			Severity:    "^critical$",
+			MessageArgs: []string{"^fault"},
+		},
+		Action: sm.EventRuleAction{Flag: "Alert", Target: "Controller", TargetSuffix: "n0"},
+	}, {
+		// Bad, skipped.
+		Name:   "BadParser",
+		Match:  sm.EventRuleMatch{MessageId: "NodeDown"},
+		Action: sm.EventRuleAction{Parser: "NoSuchParser"},
+	}}
+
+	rs := NewEventRuleSet(fileRules)
+	bad := rs.Set(apiRules)
+	if len(bad) != 1 || bad["BadParser"] != ErrSmEventRuleParser {
+		t.Errorf("Expected only BadParser to be skipped; Received %v", bad)
+	}
+
+	tests := []struct {
+		pe           processedRFEvent
+		expectedRule string
+		expectedSrc  string
+	}{{
+		pe:           processedRFEvent{MessageId: "nodeup", Registry: "Oem"},
+		expectedRule: "OemNodeUp",
+		expectedSrc:  sm.EventRuleSourceFile,
+	}, {
+		pe:           processedRFEvent{MessageId: "NodeUp", Registry: "Other"},
+		expectedRule: "",
+	}, {
+		pe: processedRFEvent{MessageId: "NodeUp", Registry: "Oem",
+			Severity: "Critical", MessageArgs: []string{"x", "Fault 12"}},
+		expectedRule: "OemNodeUpCritical",
+		expectedSrc:  sm.EventRuleSourceAPI,
+	}, {
+		pe: processedRFEvent{MessageId: "NodeUp", Registry: "Oem",
+			Severity: "Critical", MessageArgs: []string{"x"}},
+		expectedRule: "OemNodeUp",
+		expectedSrc:  sm.EventRuleSourceFile,
+	}, {
+		pe:           processedRFEvent{MessageId: "SystemPowerOff"},
+		expectedRule: "SystemPowerOff",
+		expectedSrc:  sm.EventRuleSourceFile,
+	}, {
+		pe:           processedRFEvent{MessageId: "SystemPowerOn"},
+		expectedRule: "SystemPowerOn",
+		expectedSrc:  sm.EventRuleSourceBuiltIn,
+	}, {
+		pe:           processedRFEvent{MessageId: "ServerPoweredOn"},
+		expectedRule: "",
+	}, {
+		pe:           processedRFEvent{MessageId: "NodeDown"},
+		expectedRule: "",
+	}}
+
+	for i, test := range tests {
+		r := rs.Match(&test.pe)
+		if test.expectedRule == "" {
+			if r != nil {
+				t.Errorf("Test %d Failed: Expected no match; Received '%s'", i, r.Name)
+			}
+			continue
+		}
+		if r == nil {
+			t.Errorf("Test %d Failed: Expected '%s'; Received no match", i, test.expectedRule)
+		} else if r.Name != test.expectedRule || r.Source != test.expectedSrc {
+			t.Errorf("Test %d Failed: Expected '%s'/%s; Received '%s'/%s",
+				i, test.expectedRule, test.expectedSrc, r.Name, r.Source)
+		}
+	}
+
+	// The file rule builds an update for n0 of the sending controller.
+	eventRules := s.eventRules
+	s.eventRules = rs
+	defer func() { s.eventRules = eventRules }()
+
+	u, err := s.compUpdateFromRFEvent(&processedRFEvent{
+		MessageId:     "NodeUp",
+		Registry:      "Oem",
+		RfEndppointID: "x3000c0s9b0",
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if u == nil || len(u.ComponentIDs) != 1 || u.ComponentIDs[0] != "x3000c0s9b0n0" ||
+		u.State != base.StateOn.String() || u.UpdateType != StateDataUpdate.String() {
+		t.Errorf("Unexpected update: %+v", u)
+	}
+	u, err = s.compUpdateFromRFEvent(&processedRFEvent{MessageId: "SystemPowerOff"})
+	if err != nil || u != nil {
+		t.Errorf("Expected ignored event; Received %+v, %v", u, err)
+	}
+}
+
+func TestDoEventRulesAPI(t *testing.T) {
+	eventRules := s.eventRules
+	s.eventRules = NewEventRuleSet([]*sm.EventRule{})
+	defer func() { s.eventRules = eventRules }()
+
+	apiRule := &sm.EventRule{
+		Name:   "OemNodeUp",
+		Match:  sm.EventRuleMatch{MessageId: "NodeUp"},
+		Action: sm.EventRuleAction{State: "On", Target: "Origin"},
+	}
+
+	tests := []struct {
+		method         string
+		reqURI         string
+		body           string
+		dbErr          error
+		dbResult       bool
+		dbRules        []*sm.EventRule
+		expectedCode   int
+		expectedSource string
+	}{{
+		method:       "POST",
+		reqURI:       "/hsm/v2/EventRules",
+		body:         `{"Name":"OemNodeUp","Match":{"MessageId":"NodeUp"},"Action":{"State":"on","Target":"origin"}}`,
+		dbRules:      []*sm.EventRule{apiRule},
+		expectedCode: http.StatusCreated,
+	}, {
+		method:         "GET",
+		reqURI:         "/hsm/v2/EventRules/OemNodeUp",
+		expectedCode:   http.StatusOK,
+		expectedSource: sm.EventRuleSourceAPI,
+	}, {
+		method:       "POST",
+		reqURI:       "/hsm/v2/EventRules",
+		body:         `{"Name":"OemNodeUp","Match":{"MessageId":"NodeUp"},"Action":{"State":"On"}}`,
+		dbErr:        hmsds.ErrHMSDSDuplicateKey,
+		expectedCode: http.StatusConflict,
+	}, {
+		method:       "POST",
+		reqURI:       "/hsm/v2/EventRules",
+		body:         `{"Name":"NoMsgId","Action":{"State":"On"}}`,
+		expectedCode: http.StatusBadRequest,
+	}, {
+		method:       "POST",
+		reqURI:       "/hsm/v2/EventRules",
+		body:         `{"Name":"TwoActions","Match":{"MessageId":"NodeUp"},"Action":{"State":"On","Ignore":true}}`,
+		expectedCode: http.StatusBadRequest,
+	}, {
+		method:       "PUT",
+		reqURI:       "/hsm/v2/EventRules/Other",
+		body:         `{"Name":"OemNodeUp","Match":{"MessageId":"NodeUp"},"Action":{"State":"On"}}`,
+		expectedCode: http.StatusBadRequest,
+	}, {
+		method:       "PUT",
+		reqURI:       "/hsm/v2/EventRules/OemNodeUp",
+		body:         `{"Match":{"MessageId":"NodeUp"},"Action":{"State":"Off"}}`,
+		dbResult:     true,
+		dbRules:      []*sm.EventRule{apiRule},
+		expectedCode: http.StatusOK,
+	}, {
+		method:         "GET",
+		reqURI:         "/hsm/v2/EventRules/SystemPowerOn",
+		expectedCode:   http.StatusOK,
+		expectedSource: sm.EventRuleSourceBuiltIn,
+	}, {
+		method:       "DELETE",
+		reqURI:       "/hsm/v2/EventRules/SystemPowerOn",
+		dbResult:     false,
+		expectedCode: http.StatusBadRequest,
+	}, {
+		method:       "DELETE",
+		reqURI:       "/hsm/v2/EventRules/OemNodeUp",
+		dbResult:     true,
+		dbRules:      []*sm.EventRule{},
+		expectedCode: http.StatusOK,
+	}, {
+		method:       "GET",
+		reqURI:       "/hsm/v2/EventRules/OemNodeUp",
+		expectedCode: http.StatusNotFound,
+	}, {
+		method:       "DELETE",
+		reqURI:       "/hsm/v2/EventRules/OemNodeUp",
+		dbResult:     false,
+		expectedCode: http.StatusNotFound,
+	}}
+
+	for i, test := range tests {
+		results.InsertEventRule.Return.err = test.dbErr
+		results.UpdateEventRule.Return.didUpdate = test.dbResult
+		results.UpdateEventRule.Return.err = nil
+		results.DeleteEventRule.Return.didDelete = test.dbResult
+		results.DeleteEventRule.Return.err = nil
+		if test.dbRules != nil {
+			results.GetEventRulesAll.Return.rules = test.dbRules
+		}
+		results.GetEventRulesAll.Return.err = nil
+
+		req, err := http.NewRequest(test.method, test.reqURI,
+			bytes.NewBufferString(test.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if test.expectedCode != w.Code {
+			t.Errorf("Test %v Failed: Expected status code %v; Received %v (%s)",
+				i, test.expectedCode, w.Code, w.Body.String())
+			continue
+		}
+		if test.expectedSource != "" {
+			r := new(sm.EventRule)
+			if err := json.Unmarshal(w.Body.Bytes(), r); err != nil {
+				t.Errorf("Test %v Failed: Bad response: %s", i, err)
+			} else if r.Source != test.expectedSource {
+				t.Errorf("Test %v Failed: Expected source '%s'; Received '%s'",
+					i, test.expectedSource, r.Source)
+			}
+		}
+	}
+
+	// All built-ins plus nothing from the API.
+	req, _ := http.NewRequest("GET", "/hsm/v2/EventRules", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	rules := new(sm.EventRuleArray)
+	if err := json.Unmarshal(w.Body.Bytes(), rules); err != nil {
+		t.Fatalf("Bad response: %s", err)
+	}
+	if len(rules.EventRules) != len(eventRulesBuiltIn) {
+		t.Errorf("Expected %d rules; Received %d",
+			len(eventRulesBuiltIn), len(rules.EventRules))
+	}
+}
@@ -0,0 +1,175 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+/////////////////////////////////////////////////////////////////////////////
+// MQTT event source, using the Eclipse Paho client library, subscribed to
+// one topic filter at QoS 0.  For replicas to share the events use a shared
+// subscription as the topic, e.g. $share/cray-hms-smd/events.  The client
+// reconnects by itself, with backoff, and we resubscribe each time.
+/////////////////////////////////////////////////////////////////////////////
+
+const mqttPortDefault = "1883"
+const mqttsPortDefault = "8883"
+
+// Events received but not yet read.
+const mqttEventSourceQueue = 1000
+
+var mqttDialTimeout = 10 * time.Second
+var mqttKeepAlive = 60 * time.Second
+var mqttReconnectWaitMax = time.Minute
+
+var ErrMQTTTimeout = errors.New("MQTT request timed out")
+
+type mqttEventSource struct {
+	addr   string
+	topic  string
+	user   string
+	pass   string
+	tls    *tls.Config // nil for plain TCP
+	client mqtt.Client
+	events chan string
+	stop   chan struct{}
+}
+
+func (m *mqttEventSource) Connect() error {
+	m.Disconnect()
+	if m.events == nil {
+		m.events = make(chan string, mqttEventSourceQueue)
+	}
+	m.stop = make(chan struct{})
+	subscribed := make(chan error, 1)
+
+	// A clean session, so nothing is queued for us while we are away, as
+	// with the other sources.  That means subscribing again after each
+	// reconnect.
+	opts := mqtt.NewClientOptions().
+		AddBroker(m.url()).
+		SetClientID(RF_EVENT_MSGBUS_GROUPID + "-" +
+			strconv.FormatInt(time.Now().UnixNano(), 36)).
+		SetCleanSession(true).
+		SetKeepAlive(mqttKeepAlive).
+		SetConnectTimeout(mqttDialTimeout).
+		SetAutoReconnect(true).
+		SetMaxReconnectInterval(mqttReconnectWaitMax).
+		SetOnConnectHandler(func(c mqtt.Client) {
+			err := m.subscribe(c, m.stop)
+			select {
+			case subscribed <- err:
+			default:
+			}
+		})
+	if m.user != "" {
+		opts.SetUsername(m.user)
+		opts.SetPassword(m.pass)
+	}
+	if m.tls != nil {
+		opts.SetTLSConfig(m.tls)
+	}
+	m.client = mqtt.NewClient(opts)
+	token := m.client.Connect()
+	if !token.WaitTimeout(mqttDialTimeout) {
+		m.Disconnect()
+		return ErrMQTTTimeout
+	} else if err := token.Error(); err != nil {
+		m.Disconnect()
+		return err
+	}
+	select {
+	case err := <-subscribed:
+		if err != nil {
+			m.Disconnect()
+			return err
+		}
+	case <-time.After(mqttDialTimeout):
+		m.Disconnect()
+		return ErrMQTTTimeout
+	}
+	return nil
+}
+
+// Subscribe to our topic, handing messages to ReadNext until stop is
+// closed.
+func (m *mqttEventSource) subscribe(c mqtt.Client, stop chan struct{}) error {
+	events := m.events
+	token := c.Subscribe(m.topic, 0, func(c mqtt.Client, msg mqtt.Message) {
+		select {
+		case events <- string(msg.Payload()):
+		case <-stop:
+		}
+	})
+	if !token.WaitTimeout(mqttDialTimeout) {
+		return ErrMQTTTimeout
+	} else if err := token.Error(); err != nil {
+		return err
+	}
+	if st, ok := token.(*mqtt.SubscribeToken); ok {
+		if code := st.Result()[m.topic]; code == 0x80 {
+			return fmt.Errorf("MQTT subscription to '%s' refused", m.topic)
+		}
+	}
+	return nil
+}
+
+func (m *mqttEventSource) ReadNext() (string, error) {
+	if m.client == nil {
+		return "", MsgBusMissingHostSpec
+	}
+	select {
+	case e := <-m.events:
+		return e, nil
+	case <-m.stop:
+		return "", MsgBusMissingHostSpec
+	}
+}
+
+func (m *mqttEventSource) Disconnect() error {
+	if m.client == nil {
+		return nil
+	}
+	close(m.stop)
+	m.client.Disconnect(250)
+	m.client = nil
+	return nil
+}
+
+func (m *mqttEventSource) String() string {
+	return m.url() + "/" + m.topic
+}
+
+func (m *mqttEventSource) url() string {
+	if m.tls != nil {
+		return "mqtts://" + m.addr
+	}
+	return "mqtt://" + m.addr
+}
@@ -0,0 +1,153 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"crypto/tls"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+/////////////////////////////////////////////////////////////////////////////
+// NATS event source, using the NATS client library.  Replicas share a
+// queue group so each event is only handled once, as with the Kafka group.
+// The client reconnects by itself, with backoff, and resubscribes, so
+// ReadNext only fails once the connection is closed for good.
+/////////////////////////////////////////////////////////////////////////////
+
+const natsPortDefault = "4222"
+
+var natsDialTimeout = 10 * time.Second
+var natsReconnectWait = time.Second
+var natsReconnectWaitMax = time.Minute
+
+// How long ReadNext waits for a message before checking the connection.
+var natsReadTimeout = time.Minute
+
+type natsEventSource struct {
+	addr    string
+	subject string
+	queue   string
+	user    string
+	pass    string
+	creds   string      // NATS credentials file, instead of user/pass
+	tls     *tls.Config // nil for plain TCP
+	conn    *nats.Conn
+	sub     *nats.Subscription
+}
+
+func (n *natsEventSource) Connect() error {
+	n.Disconnect()
+	opts := []nats.Option{
+		nats.Name(RF_EVENT_MSGBUS_GROUPID),
+		nats.Timeout(natsDialTimeout),
+		nats.MaxReconnects(-1),
+		nats.CustomReconnectDelay(natsReconnectDelay),
+	}
+	if n.user != "" {
+		opts = append(opts, nats.UserInfo(n.user, n.pass))
+	}
+	if n.creds != "" {
+		opts = append(opts, nats.UserCredentials(n.creds))
+	}
+	if n.tls != nil {
+		opts = append(opts, nats.Secure(n.tls))
+	}
+	conn, err := nats.Connect(n.url(), opts...)
+	if err != nil {
+		return err
+	}
+	sub, err := conn.QueueSubscribeSync(n.subject, n.queue)
+	if err == nil {
+		// Make sure the server accepted it.
+		err = conn.FlushTimeout(natsDialTimeout)
+	}
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	n.conn = conn
+	n.sub = sub
+	return nil
+}
+
+func (n *natsEventSource) ReadNext() (string, error) {
+	if n.sub == nil {
+		return "", MsgBusMissingHostSpec
+	}
+	for {
+		msg, err := n.sub.NextMsg(natsReadTimeout)
+		switch err {
+		case nil:
+			return string(msg.Data), nil
+		case nats.ErrTimeout, nats.ErrSlowConsumer:
+			// Nothing yet, or we fell behind and the client dropped
+			// some; carry on with the rest.
+			continue
+		}
+		return "", err
+	}
+}
+
+func (n *natsEventSource) Disconnect() error {
+	if n.conn == nil {
+		return nil
+	}
+	n.conn.Close()
+	n.conn = nil
+	n.sub = nil
+	return nil
+}
+
+func (n *natsEventSource) String() string {
+	scheme := "nats://"
+	if n.tls != nil {
+		scheme = "nats+tls://"
+	}
+	s := scheme + n.addr + "/" + n.subject
+	if n.queue != "" {
+		s += "?queue=" + n.queue
+	}
+	return s
+}
+
+// Server URL for the client, which uses tls:// for TLS.
+func (n *natsEventSource) url() string {
+	if n.tls != nil {
+		return "tls://" + n.addr
+	}
+	return "nats://" + n.addr
+}
+
+// Double the wait after each failed reconnect, up to natsReconnectWaitMax.
+func natsReconnectDelay(attempts int) time.Duration {
+	wait := natsReconnectWait
+	for i := 1; i < attempts && wait < natsReconnectWaitMax; i++ {
+		wait *= 2
+	}
+	if wait > natsReconnectWaitMax {
+		wait = natsReconnectWaitMax
+	}
+	return wait
+}
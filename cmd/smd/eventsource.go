@@ -0,0 +1,419 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"bufio"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	base "github.com/Cray-HPE/hms-base/v2"
+)
+
+/////////////////////////////////////////////////////////////////////////////
+// Event sources.  Raw Redfish events can be read from the Kafka message bus
+// or any of the other transports below, selected by the URL scheme of the
+// -msg-host option:
+//
+//   host:port:topic                 Kafka (original format)
+//   kafka://host:port/topic         Kafka
+//   http://[host]:port/path         Events POSTed to us (https:// uses
+//   https://[host]:port/path        the -tls-cert and -tls-key files)
+//   file:///path/events.ndjson      One JSON event per line, e.g. for
+//   file:///path?follow=true        replaying a capture in a lab.  With
+//   file:-  or  stdin:              follow, wait for more like 'tail -f'
+//   nats://[user:pw@]host[:port]/subject[?queue=group][&creds=file]
+//   nats+tls://...                  NATS over TLS
+//   mqtt://[user:pw@]host[:port]/topic
+//   mqtts://...                     MQTT over TLS
+//
+// For TLS, add ca=file to trust a CA besides the system ones.  Events
+// POSTed to us are only accepted from the BMC named in their Context.
+/////////////////////////////////////////////////////////////////////////////
+
+var ErrEventSourceDone = base.NewHMSError("sm_msg",
+	"event source has no more events")
+var ErrEventSourceScheme = base.NewHMSError("sm_msg",
+	"unsupported event source scheme")
+
+// A source of raw Redfish events.  Connect may be called again after an
+// error from ReadNext to reconnect.
+type EventSource interface {
+	Connect() error
+	// Block until the next event is available.  ErrEventSourceDone
+	// means there will never be any more, e.g. at the end of a file.
+	ReadNext() (string, error)
+	Disconnect() error
+	String() string
+}
+
+// Create the EventSource given by spec, see above.
+func (s *SmD) NewEventSource(spec string) (EventSource, error) {
+	if !strings.Contains(spec, "://") {
+		switch spec {
+		case "stdin:", "file:-":
+			return &fileEventSource{path: "-"}, nil
+		}
+		// Original host:port:topic Kafka format
+		if err := s.MsgBusConfig(spec); err != nil {
+			return nil, err
+		}
+		return &kafkaEventSource{s: s}, nil
+	}
+	u, err := url.Parse(spec)
+	if err != nil {
+		return nil, MsgBusInvalidHostSpec
+	}
+	topic := strings.TrimPrefix(u.Path, "/")
+	switch strings.ToLower(u.Scheme) {
+	case "kafka":
+		if err := s.MsgBusConfig(u.Host + ":" + topic); err != nil {
+			return nil, err
+		}
+		return &kafkaEventSource{s: s}, nil
+	case "http", "https":
+		if u.Port() == "" {
+			return nil, MsgBusInvalidHostSpec
+		}
+		path := u.Path
+		if path == "" {
+			path = "/"
+		}
+		src := &httpEventSource{addr: u.Host, path: path,
+			check: s.checkRFEventSender, queue: s.queueRFEvent}
+		if u.Scheme == "https" {
+			src.tls = true
+			src.cert = s.tlsCert
+			src.key = s.tlsKey
+		}
+		return src, nil
+	case "file":
+		if u.Path == "" {
+			return nil, MsgBusMissingHostSpec
+		}
+		follow, _ := strconv.ParseBool(u.Query().Get("follow"))
+		return &fileEventSource{path: u.Path, follow: follow}, nil
+	case "nats", "nats+tls":
+		if topic == "" {
+			return nil, MsgBusMissingHostSpec
+		}
+		src := &natsEventSource{
+			addr:    hostWithPort(u.Host, natsPortDefault),
+			subject: topic,
+			queue:   u.Query().Get("queue"),
+			creds:   u.Query().Get("creds"),
+		}
+		if strings.ToLower(u.Scheme) == "nats+tls" {
+			if src.tls, err = eventSourceTLSConfig(u); err != nil {
+				return nil, err
+			}
+		}
+		if src.queue == "" {
+			src.queue = RF_EVENT_MSGBUS_GROUPID
+		}
+		if u.User != nil {
+			src.user = u.User.Username()
+			src.pass, _ = u.User.Password()
+		}
+		return src, nil
+	case "mqtt":
+		if topic == "" {
+			return nil, MsgBusMissingHostSpec
+		}
+		src := &mqttEventSource{
+			addr:  hostWithPort(u.Host, mqttPortDefault),
+			topic: topic,
+		}
+		if u.User != nil {
+			src.user = u.User.Username()
+			src.pass, _ = u.User.Password()
+		}
+		return src, nil
+	case "mqtts":
+		if topic == "" {
+			return nil, MsgBusMissingHostSpec
+		}
+		src := &mqttEventSource{
+			addr:  hostWithPort(u.Host, mqttsPortDefault),
+			topic: topic,
+		}
+		if src.tls, err = eventSourceTLSConfig(u); err != nil {
+			return nil, err
+		}
+		if u.User != nil {
+			src.user = u.User.Username()
+			src.pass, _ = u.User.Password()
+		}
+		return src, nil
+	}
+	s.LogAlways("Unsupported event source '%s'", spec)
+	return nil, ErrEventSourceScheme
+}
+
+// Add the default port to host if it has none.
+func hostWithPort(host, port string) string {
+	if _, _, err := net.SplitHostPort(host); err == nil {
+		return host
+	}
+	return net.JoinHostPort(strings.Trim(host, "[]"), port)
+}
+
+// TLS settings for a broker, trusting the CA in the ca query parameter as
+// well as the system ones.
+func eventSourceTLSConfig(u *url.URL) (*tls.Config, error) {
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+	if ca := u.Query().Get("ca"); ca != "" {
+		pem, err := ioutil.ReadFile(ca)
+		if err != nil {
+			return nil, err
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no CA certificates in '%s'", ca)
+		}
+		cfg.RootCAs = pool
+	}
+	return cfg, nil
+}
+
+/////////////////////////////////////////////////////////////////////////////
+// Kafka, via hms-msgbus
+/////////////////////////////////////////////////////////////////////////////
+
+type kafkaEventSource struct {
+	s *SmD
+}
+
+func (k *kafkaEventSource) Connect() error {
+	return k.s.MsgBusReconnect()
+}
+
+func (k *kafkaEventSource) ReadNext() (string, error) {
+	return k.s.MsgBusReadNext()
+}
+
+func (k *kafkaEventSource) Disconnect() error {
+	return k.s.MsgBusDisconnect()
+}
+
+func (k *kafkaEventSource) String() string {
+	return fmt.Sprintf("kafka://%s:%d/%s", k.s.msgbusConfig.Host,
+		k.s.msgbusConfig.Port, k.s.msgbusConfig.Topic)
+}
+
+/////////////////////////////////////////////////////////////////////////////
+// HTTP push.  Events are POSTed to us, one per request, and are only
+// accepted from the BMC named in their Context, see checkRFEventSender.
+// They are queued for processing before they are answered, so senders get
+// 503 and can retry if the queue is full, as with the Redfish event
+// listener.  ReadNext only returns errors then.
+/////////////////////////////////////////////////////////////////////////////
+
+const httpEventSourceQueue = 1000
+
+type httpEventSource struct {
+	addr      string
+	path      string
+	tls       bool
+	cert      string
+	key       string
+	boundAddr string // Actual address, if addr has port 0
+	check     func(r *http.Request, payload string) error
+	queue     func(payload string) bool // Queue directly instead of events
+	events    chan string
+	errs      chan error
+	server    *http.Server
+}
+
+func (h *httpEventSource) Connect() error {
+	h.Disconnect()
+	ln, err := net.Listen("tcp", h.addr)
+	if err != nil {
+		return err
+	}
+	h.boundAddr = ln.Addr().String()
+	if h.events == nil {
+		h.events = make(chan string, httpEventSourceQueue)
+	}
+	h.errs = make(chan error, 1)
+	mux := http.NewServeMux()
+	mux.HandleFunc(h.path, h.doEventPost)
+	h.server = &http.Server{Handler: mux}
+	go func(server *http.Server, errs chan error) {
+		if h.tls {
+			errs <- server.ServeTLS(ln, h.cert, h.key)
+		} else {
+			errs <- server.Serve(ln)
+		}
+	}(h.server, h.errs)
+	return nil
+}
+
+func (h *httpEventSource) doEventPost(w http.ResponseWriter, r *http.Request) {
+	defer base.DrainAndCloseRequestBody(r)
+
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, rfEventMaxBody))
+	if err != nil || len(body) == 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if h.check != nil {
+		if err := h.check(r, string(body)); err != nil {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+	}
+	// Queue the event before answering so that, if it can't be, the
+	// sender is told to retry later rather than it being dropped.
+	if h.queue != nil {
+		if !h.queue(string(body)) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	select {
+	case h.events <- string(body):
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		// Let the sender retry later.
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+}
+
+func (h *httpEventSource) ReadNext() (string, error) {
+	select {
+	case e := <-h.events:
+		return e, nil
+	case err := <-h.errs:
+		return "", err
+	}
+}
+
+func (h *httpEventSource) Disconnect() error {
+	if h.server == nil {
+		return nil
+	}
+	err := h.server.Close()
+	h.server = nil
+	return err
+}
+
+func (h *httpEventSource) String() string {
+	scheme := "http"
+	if h.tls {
+		scheme = "https"
+	}
+	return scheme + "://" + h.addr + h.path
+}
+
+/////////////////////////////////////////////////////////////////////////////
+// Newline-delimited JSON file or stdin
+/////////////////////////////////////////////////////////////////////////////
+
+// How often to look for more when following a file.
+var fileEventSourcePoll = time.Second
+
+type fileEventSource struct {
+	path    string // "-" is stdin
+	follow  bool
+	f       io.ReadCloser
+	rd      *bufio.Reader
+	partial string
+}
+
+func (fs *fileEventSource) Connect() error {
+	fs.Disconnect()
+	if fs.path == "-" {
+		fs.f = ioutil.NopCloser(os.Stdin)
+	} else {
+		f, err := os.Open(fs.path)
+		if err != nil {
+			return err
+		}
+		fs.f = f
+	}
+	fs.rd = bufio.NewReader(fs.f)
+	fs.partial = ""
+	return nil
+}
+
+func (fs *fileEventSource) ReadNext() (string, error) {
+	for {
+		line, err := fs.rd.ReadString('\n')
+		line = fs.partial + line
+		fs.partial = ""
+		if err == io.EOF {
+			if fs.follow {
+				// Wait for the rest of the line, or the next one.
+				fs.partial = line
+				time.Sleep(fileEventSourcePoll)
+				continue
+			}
+			if strings.TrimSpace(line) != "" {
+				return strings.TrimSpace(line), nil
+			}
+			return "", ErrEventSourceDone
+		} else if err != nil {
+			return "", err
+		}
+		if line = strings.TrimSpace(line); line != "" {
+			return line, nil
+		}
+	}
+}
+
+func (fs *fileEventSource) Disconnect() error {
+	if fs.f == nil {
+		return nil
+	}
+	err := fs.f.Close()
+	fs.f = nil
+	return err
+}
+
+func (fs *fileEventSource) String() string {
+	if fs.path == "-" {
+		return "stdin:"
+	}
+	return "file://" + fs.path
+}
@@ -0,0 +1,527 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"bufio"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	base "github.com/Cray-HPE/hms-base/v2"
+	rf "github.com/Cray-HPE/hms-smd/v2/pkg/redfish"
+	st "github.com/Cray-HPE/hms-smd/v2/pkg/sharedtest"
+	"github.com/Cray-HPE/hms-smd/v2/pkg/sm"
+	"github.com/eclipse/paho.mqtt.golang/packets"
+)
+
+func TestNewEventSource(t *testing.T) {
+	tests := []struct {
+		spec        string
+		expectedStr string
+		expectedErr error
+	}{
+		{"kafka.sma.svc.cluster.local:9092:rf-events",
+			"kafka://kafka.sma.svc.cluster.local:9092/rf-events", nil},
+		{"kafka://10.23.33.22:9092/events", "kafka://10.23.33.22:9092/events", nil},
+		{"kafka://10.23.33.22/events", "", MsgBusInvalidHostSpec},
+		{"10.23.33.22:9092", "", MsgBusInvalidHostSpec},
+		{"http://:27781/events", "http://:27781/events", nil},
+		{"https://0.0.0.0:27781/events", "https://0.0.0.0:27781/events", nil},
+		{"http://0.0.0.0/events", "", MsgBusInvalidHostSpec},
+		{"file:///tmp/events.ndjson", "file:///tmp/events.ndjson", nil},
+		{"file:///tmp/events.ndjson?follow=true", "file:///tmp/events.ndjson", nil},
+		{"stdin:", "stdin:", nil},
+		{"file:-", "stdin:", nil},
+		{"nats://nats.local/rf.events",
+			"nats://nats.local:4222/rf.events?queue=cray-hms-smd", nil},
+		{"nats://u:p@nats.local:4333/rf.events?queue=q",
+			"nats://nats.local:4333/rf.events?queue=q", nil},
+		{"nats://nats.local", "", MsgBusMissingHostSpec},
+		{"mqtt://broker.local/redfish/events",
+			"mqtt://broker.local:1883/redfish/events", nil},
+		{"mqtt://broker.local:8883/$share/smd/events",
+			"mqtt://broker.local:8883/$share/smd/events", nil},
+		{"amqp://broker.local/events", "", ErrEventSourceScheme},
+	}
+	for i, test := range tests {
+		s.msgbusHandle = nil
+		src, err := s.NewEventSource(test.spec)
+		if err != test.expectedErr {
+			t.Errorf("Test %d FAIL: %s: Expected error %v; Received %v",
+				i, test.spec, test.expectedErr, err)
+			continue
+		}
+		if err == nil && src.String() != test.expectedStr {
+			t.Errorf("Test %d FAIL: %s: Expected '%s'; Received '%s'",
+				i, test.spec, test.expectedStr, src.String())
+		}
+	}
+	src, _ := s.NewEventSource("file:///tmp/events.ndjson?follow=1")
+	if fs, ok := src.(*fileEventSource); !ok || !fs.follow {
+		t.Errorf("FAIL: follow not set for file source")
+	}
+	src, _ = s.NewEventSource("nats://u:p@nats.local/rf.events")
+	if ns, ok := src.(*natsEventSource); !ok || ns.user != "u" || ns.pass != "p" {
+		t.Errorf("FAIL: NATS credentials not set")
+	}
+}
+
+func TestHTTPEventSource(t *testing.T) {
+	h := &httpEventSource{addr: "127.0.0.1:0", path: "/events",
+		check: s.checkRFEventSender}
+	if err := h.Connect(); err != nil {
+		t.Fatalf("FAIL: Connect: %s", err)
+	}
+	defer h.Disconnect()
+	defer func() { results.GetRFEndpointByID.Return.entry = nil }()
+
+	event := st.GenEvent(st.EventCrayOnOKChassis, st.EpID("x1c4b0"))
+	url := "http://" + h.boundAddr + "/events"
+	tests := []struct {
+		fqdn         string
+		body         string
+		expectedCode int
+	}{
+		{"127.0.0.1", event, http.StatusNoContent},
+		// Not from the BMC in the Context, or no BMC in the Context.
+		{"192.0.2.9", event, http.StatusForbidden},
+		{"127.0.0.1", `{"Id": "1"}`, http.StatusForbidden},
+	}
+	for i, test := range tests {
+		results.GetRFEndpointByID.Return.entry = &sm.RedfishEndpoint{
+			RedfishEPDescription: rf.RedfishEPDescription{
+				ID:   "x1c4b0",
+				FQDN: test.fqdn,
+			},
+		}
+		rsp, err := http.Post(url, "application/json",
+			strings.NewReader(test.body))
+		if err != nil {
+			t.Fatalf("Test %d FAIL: POST: %s", i, err)
+		}
+		base.DrainAndCloseResponseBody(rsp)
+		if rsp.StatusCode != test.expectedCode {
+			t.Errorf("Test %d FAIL: POST: Expected %d; Received %d",
+				i, test.expectedCode, rsp.StatusCode)
+		}
+	}
+	rsp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("FAIL: GET: %s", err)
+	}
+	base.DrainAndCloseResponseBody(rsp)
+	if rsp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("FAIL: GET: Expected %d; Received %d",
+			http.StatusMethodNotAllowed, rsp.StatusCode)
+	}
+	// Only the accepted event.
+	if e, err := h.ReadNext(); err != nil || e != event {
+		t.Errorf("FAIL: ReadNext: Received '%s', %v", e, err)
+	}
+	if len(h.events) != 0 {
+		t.Errorf("FAIL: Expected 1 event; Found %d more", len(h.events))
+	}
+	// Listener gone
+	h.Disconnect()
+	if _, err := h.ReadNext(); err == nil {
+		t.Errorf("FAIL: ReadNext after Disconnect: no error")
+	}
+}
+
+func TestHTTPEventSourceQueue(t *testing.T) {
+	queued := []string{}
+	accept := true
+	h := &httpEventSource{addr: "127.0.0.1:0", path: "/events",
+		queue: func(payload string) bool {
+			if accept {
+				queued = append(queued, payload)
+			}
+			return accept
+		}}
+	if err := h.Connect(); err != nil {
+		t.Fatalf("FAIL: Connect: %s", err)
+	}
+	defer h.Disconnect()
+
+	// Events are queued before they are answered, and the sender is told
+	// to retry if they can't be.
+	url := "http://" + h.boundAddr + "/events"
+	tests := []struct {
+		accept       bool
+		expectedCode int
+	}{
+		{true, http.StatusNoContent},
+		{false, http.StatusServiceUnavailable},
+	}
+	for i, test := range tests {
+		accept = test.accept
+		rsp, err := http.Post(url, "application/json",
+			strings.NewReader(`{"Id": "1"}`))
+		if err != nil {
+			t.Fatalf("Test %d FAIL: POST: %s", i, err)
+		}
+		base.DrainAndCloseResponseBody(rsp)
+		if rsp.StatusCode != test.expectedCode {
+			t.Errorf("Test %d FAIL: POST: Expected %d; Received %d",
+				i, test.expectedCode, rsp.StatusCode)
+		}
+	}
+	if len(queued) != 1 || queued[0] != `{"Id": "1"}` {
+		t.Errorf("FAIL: Expected 1 queued event; Received '%v'", queued)
+	}
+	if len(h.events) != 0 {
+		t.Errorf("FAIL: Expected no events left to read; Found %d", len(h.events))
+	}
+}
+
+func TestFileEventSource(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "events.ndjson")
+	data := "{\"Id\": \"1\"}\n\n  {\"Id\": \"2\"}  \n{\"Id\": \"3\"}"
+	if err := ioutil.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatalf("WriteFile: %s", err)
+	}
+	fs := &fileEventSource{path: path}
+	if err := fs.Connect(); err != nil {
+		t.Fatalf("FAIL: Connect: %s", err)
+	}
+	for i := 1; i <= 3; i++ {
+		e, err := fs.ReadNext()
+		if err != nil || e != `{"Id": "`+string(rune('0'+i))+`"}` {
+			t.Errorf("Test %d FAIL: ReadNext: Received '%s', %v", i, e, err)
+		}
+	}
+	if _, err := fs.ReadNext(); err != ErrEventSourceDone {
+		t.Errorf("FAIL: Expected %s at end; Received %v", ErrEventSourceDone, err)
+	}
+	fs.Disconnect()
+
+	// Follow the file as it is written.
+	poll := fileEventSourcePoll
+	fileEventSourcePoll = 10 * time.Millisecond
+	defer func() { fileEventSourcePoll = poll }()
+	ioutil.WriteFile(path, []byte("{\"Id\": \"1\"}\n{\"Id\":"), 0644)
+	fs = &fileEventSource{path: path, follow: true}
+	if err := fs.Connect(); err != nil {
+		t.Fatalf("FAIL: Connect: %s", err)
+	}
+	defer fs.Disconnect()
+	if e, err := fs.ReadNext(); err != nil || e != `{"Id": "1"}` {
+		t.Errorf("FAIL: follow: Received '%s', %v", e, err)
+	}
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+		f.WriteString(" \"2\"}\n")
+		f.Close()
+	}()
+	if e, err := fs.ReadNext(); err != nil || e != `{"Id": "2"}` {
+		t.Errorf("FAIL: follow: Received '%s', %v", e, err)
+	}
+}
+
+func TestRFEventMonitorFileSource(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "events.ndjson")
+	ioutil.WriteFile(path, []byte("{\"Id\": \"1\"}\n{\"Id\": \"2\"}\n"), 0644)
+
+	listen := s.msgbusListen
+	wps := s.wpRFEvent
+	wp := base.NewWorkerPool(1, 10) // Not run
+	s.msgbusListen = "file://" + path
+	s.wpRFEvent = []*base.WorkerPool{wp}
+	defer func() {
+		s.msgbusListen = listen
+		s.wpRFEvent = wps
+	}()
+
+	// Returns once the file has been read.
+	s.StartRFEventMonitor()
+	if len(wp.JobQueue) != 2 {
+		t.Errorf("FAIL: Expected 2 queued events; Found %d", len(wp.JobQueue))
+	}
+}
+
+// Accept connections and hand each to serve in turn, with its number.
+func fakeServer(t *testing.T, serve func(conn net.Conn, n int)) string {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen: %s", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for n := 0; ; n++ {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			serve(conn, n)
+			conn.Close()
+		}
+	}()
+	return ln.Addr().String()
+}
+
+// Fake NATS server.  Sends msgs once the client has subscribed, then
+// closes the connection if hangup is set.  Client commands go to lines.
+func fakeNATSServer(conn net.Conn, lines chan string, msgs string, hangup bool) {
+	rd := bufio.NewReader(conn)
+	conn.Write([]byte("INFO {\"server_id\":\"test\",\"max_payload\":1048576}\r\n"))
+	subscribed := false
+	for {
+		line, err := rd.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		lines <- line
+		switch {
+		case line == "PING":
+			conn.Write([]byte("PONG\r\n"))
+			if subscribed {
+				// The client's flush after subscribing.
+				conn.Write([]byte(msgs))
+				if hangup {
+					time.Sleep(10 * time.Millisecond)
+					return
+				}
+				subscribed = false
+			}
+		case strings.HasPrefix(line, "SUB "):
+			subscribed = true
+		}
+	}
+}
+
+func TestNATSEventSource(t *testing.T) {
+	wait := natsReconnectWait
+	natsReconnectWait = 10 * time.Millisecond
+	defer func() { natsReconnectWait = wait }()
+
+	lines := make(chan string, 100)
+	addr := fakeServer(t, func(conn net.Conn, n int) {
+		if n == 0 {
+			// Keep alive from the server, then a message, then gone.
+			fakeNATSServer(conn, lines, "PING\r\n"+
+				"MSG rf.events 1 11\r\n{\"Id\": \"1\"}\r\n", true)
+		} else {
+			fakeNATSServer(conn, lines,
+				"MSG rf.events 1 _INBOX.x 12\r\n{\"Id\":\r\n\"2\"}\r\n", false)
+		}
+	})
+
+	src, err := s.NewEventSource("nats://u:p@" + addr + "/rf.events")
+	if err != nil {
+		t.Fatalf("FAIL: NewEventSource: %s", err)
+	}
+	n := src.(*natsEventSource)
+	if err := n.Connect(); err != nil {
+		t.Fatalf("FAIL: Connect: %s", err)
+	}
+	defer n.Disconnect()
+	if l := <-lines; !strings.HasPrefix(l, "CONNECT {") ||
+		!strings.Contains(l, `"user":"u"`) || !strings.Contains(l, `"pass":"p"`) {
+		t.Errorf("FAIL: Unexpected CONNECT: '%s'", l)
+	}
+	if e, err := n.ReadNext(); err != nil || e != `{"Id": "1"}` {
+		t.Errorf("FAIL: ReadNext: Received '%s', %v", e, err)
+	}
+	// Reconnected and resubscribed by the client.  Payloads may contain
+	// CRLF.
+	if e, err := n.ReadNext(); err != nil || e != "{\"Id\":\r\n\"2\"}" {
+		t.Errorf("FAIL: ReadNext after reconnect: Received '%q', %v", e, err)
+	}
+	subs := 0
+	for len(lines) > 0 {
+		if l := <-lines; l == "SUB rf.events cray-hms-smd 1" {
+			subs++
+		}
+	}
+	if subs != 2 {
+		t.Errorf("FAIL: Expected 2 SUBs for the queue group; Found %d", subs)
+	}
+	n.Disconnect()
+	if _, err := n.ReadNext(); err == nil {
+		t.Errorf("FAIL: ReadNext after Disconnect: no error")
+	}
+
+	// Refused
+	addr = fakeServer(t, func(conn net.Conn, n int) {
+		rd := bufio.NewReader(conn)
+		conn.Write([]byte("INFO {}\r\n"))
+		rd.ReadString('\n')
+		conn.Write([]byte("-ERR 'Authorization Violation'\r\n"))
+		time.Sleep(100 * time.Millisecond)
+	})
+	n = &natsEventSource{addr: addr, subject: "rf.events"}
+	if err := n.Connect(); err == nil ||
+		!strings.Contains(strings.ToLower(err.Error()), "authorization violation") {
+		t.Errorf("FAIL: Expected authorization error; Received %v", err)
+	}
+}
+
+// Fake MQTT broker.  Sends pubs once the client subscribes, then closes
+// the connection if hangup is set.  Packets from the client go to pkts.
+func fakeMQTTBroker(conn net.Conn, pkts chan packets.ControlPacket,
+	pubs []*packets.PublishPacket, hangup bool) {
+	for {
+		cp, err := packets.ReadPacket(conn)
+		if err != nil {
+			return
+		}
+		pkts <- cp
+		switch p := cp.(type) {
+		case *packets.ConnectPacket:
+			ack := packets.NewControlPacket(packets.Connack).(*packets.ConnackPacket)
+			ack.Write(conn)
+		case *packets.SubscribePacket:
+			ack := packets.NewControlPacket(packets.Suback).(*packets.SubackPacket)
+			ack.MessageID = p.MessageID
+			ack.ReturnCodes = []byte{0}
+			ack.Write(conn)
+			for _, pub := range pubs {
+				pub.Write(conn)
+			}
+			if hangup {
+				return
+			}
+		case *packets.PingreqPacket:
+			packets.NewControlPacket(packets.Pingresp).Write(conn)
+		}
+	}
+}
+
+func newMQTTPublish(topic, payload string, qos byte, id uint16) *packets.PublishPacket {
+	pub := packets.NewControlPacket(packets.Publish).(*packets.PublishPacket)
+	pub.TopicName = topic
+	pub.Payload = []byte(payload)
+	pub.Qos = qos
+	pub.MessageID = id
+	return pub
+}
+
+func TestMQTTEventSource(t *testing.T) {
+	pkts := make(chan packets.ControlPacket, 100)
+	addr := fakeServer(t, func(conn net.Conn, n int) {
+		if n == 0 {
+			fakeMQTTBroker(conn, pkts, []*packets.PublishPacket{
+				newMQTTPublish("redfish/events", `{"Id": "1"}`, 0, 0),
+			}, true)
+		} else {
+			// QoS 1, which needs a PUBACK.
+			fakeMQTTBroker(conn, pkts, []*packets.PublishPacket{
+				newMQTTPublish("redfish/events", strings.Repeat("x", 200), 1, 7),
+			}, false)
+		}
+	})
+
+	src, err := s.NewEventSource("mqtt://u:p@" + addr + "/redfish/events")
+	if err != nil {
+		t.Fatalf("FAIL: NewEventSource: %s", err)
+	}
+	m := src.(*mqttEventSource)
+	if err := m.Connect(); err != nil {
+		t.Fatalf("FAIL: Connect: %s", err)
+	}
+	defer m.Disconnect()
+	if e, err := m.ReadNext(); err != nil || e != `{"Id": "1"}` {
+		t.Errorf("FAIL: ReadNext: Received '%s', %v", e, err)
+	}
+	// Reconnected by the client and resubscribed by us.
+	if e, err := m.ReadNext(); err != nil || e != strings.Repeat("x", 200) {
+		t.Errorf("FAIL: ReadNext after reconnect: Received '%s', %v", e, err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	connects, subs, acked := 0, 0, false
+	for len(pkts) > 0 {
+		switch p := (<-pkts).(type) {
+		case *packets.ConnectPacket:
+			connects++
+			if !p.CleanSession || p.Username != "u" || string(p.Password) != "p" {
+				t.Errorf("FAIL: Unexpected CONNECT: %s", p)
+			}
+		case *packets.SubscribePacket:
+			subs++
+			if len(p.Topics) != 1 || p.Topics[0] != "redfish/events" ||
+				p.Qoss[0] != 0 {
+				t.Errorf("FAIL: Unexpected SUBSCRIBE: %s", p)
+			}
+		case *packets.PubackPacket:
+			acked = p.MessageID == 7
+		}
+	}
+	if connects != 2 || subs != 2 {
+		t.Errorf("FAIL: Expected 2 CONNECTs and SUBSCRIBEs; Found %d and %d",
+			connects, subs)
+	}
+	if !acked {
+		t.Errorf("FAIL: Expected PUBACK for packet 7")
+	}
+	m.Disconnect()
+	if _, err := m.ReadNext(); err == nil {
+		t.Errorf("FAIL: ReadNext after Disconnect: no error")
+	}
+
+	// Refused
+	addr = fakeServer(t, func(conn net.Conn, n int) {
+		packets.ReadPacket(conn)
+		ack := packets.NewControlPacket(packets.Connack).(*packets.ConnackPacket)
+		ack.ReturnCode = packets.ErrRefusedNotAuthorised
+		ack.Write(conn)
+		time.Sleep(100 * time.Millisecond)
+	})
+	m = &mqttEventSource{addr: addr, topic: "redfish/events"}
+	if err := m.Connect(); err != packets.ErrorRefusedNotAuthorised {
+		t.Errorf("FAIL: Expected connection refused; Received %v", err)
+	}
+}
+
+func TestEventSourceTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca := filepath.Join(dir, "ca.pem")
+	ioutil.WriteFile(ca, []byte("not a cert"), 0644)
+
+	src, err := s.NewEventSource("nats+tls://nats.local/rf.events")
+	if err != nil || src.(*natsEventSource).tls == nil ||
+		src.String() != "nats+tls://nats.local:4222/rf.events?queue=cray-hms-smd" {
+		t.Errorf("FAIL: nats+tls: Received %v, %v", src, err)
+	}
+	src, err = s.NewEventSource("mqtts://broker.local/redfish/events")
+	if err != nil || src.(*mqttEventSource).tls == nil ||
+		src.String() != "mqtts://broker.local:8883/redfish/events" {
+		t.Errorf("FAIL: mqtts: Received %v, %v", src, err)
+	}
+	if _, err := s.NewEventSource("mqtts://broker.local/events?ca=" + ca); err == nil {
+		t.Errorf("FAIL: Expected error for a bad CA file")
+	}
+	if _, err := s.NewEventSource("nats+tls://nats.local/events?ca=" +
+		filepath.Join(dir, "missing.pem")); err == nil {
+		t.Errorf("FAIL: Expected error for a missing CA file")
+	}
+}
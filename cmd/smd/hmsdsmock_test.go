@@ -24,6 +24,7 @@ package main
 
 import (
 	"log"
+	"time"
 
 	base "github.com/Cray-HPE/hms-base/v2"
 	"github.com/Cray-HPE/hms-smd/v2/internal/hmsds"
@@ -137,8 +138,9 @@ type TestResults struct {
 	}
 	UpsertComponents struct {
 		Input struct {
-			comps []*base.Component
-			force bool
+			comps  []*base.Component
+			force  bool
+			source string
 		}
 		Return struct {
 			changeMap map[string]map[string]bool
@@ -147,12 +149,16 @@ type TestResults struct {
 	}
 	UpdateCompStates struct {
 		Input struct {
-			ids   []string
-			state string
-			flag  string
-			force bool
-			pi    *hmsds.PartInfo
+			ids    []string
+			state  string
+			flag   string
+			force  bool
+			pi     *hmsds.PartInfo
+			source string
+			check  hmsds.CompStateCheck
 		}
+		// Components as locked by the update, passed to check.
+		Locked []*base.Component
 		Return struct {
 			affectedIds []string
 			err         error
@@ -160,8 +166,9 @@ type TestResults struct {
 	}
 	UpdateCompFlagOnly struct {
 		Input struct {
-			id   string
-			flag string
+			id     string
+			flag   string
+			source string
 		}
 		Return struct {
 			rowsAffected int64
@@ -170,8 +177,9 @@ type TestResults struct {
 	}
 	BulkUpdateCompFlagOnly struct {
 		Input struct {
-			ids  []string
-			flag string
+			ids    []string
+			flag   string
+			source string
 		}
 		Return struct {
 			affectedIds []string
@@ -182,6 +190,7 @@ type TestResults struct {
 		Input struct {
 			id      string
 			enabled bool
+			source  string
 		}
 		Return struct {
 			rowsAffected int64
@@ -192,6 +201,7 @@ type TestResults struct {
 		Input struct {
 			ids     []string
 			enabled bool
+			source  string
 		}
 		Return struct {
 			affectedIds []string
@@ -202,6 +212,7 @@ type TestResults struct {
 		Input struct {
 			id       string
 			swStatus string
+			source   string
 		}
 		Return struct {
 			rowsAffected int64
@@ -212,6 +223,7 @@ type TestResults struct {
 		Input struct {
 			ids      []string
 			swstatus string
+			source   string
 		}
 		Return struct {
 			affectedIds []string
@@ -223,6 +235,7 @@ type TestResults struct {
 			id      string
 			role    string
 			subRole string
+			source  string
 		}
 		Return struct {
 			rowsAffected int64
@@ -234,12 +247,28 @@ type TestResults struct {
 			ids     []string
 			role    string
 			subRole string
+			source  string
 		}
 		Return struct {
 			affectedIds []string
 			err         error
 		}
 	}
+	PatchComponents struct {
+		Input struct {
+			patches []*sm.CompPatch
+			force   bool
+			source  string
+			check   hmsds.CompStateCheck
+		}
+		// Components as locked by the patch, passed to check.
+		Locked []*base.Component
+		Return struct {
+			changedIds []string
+			failures   []sm.CompPatchFailure
+			err        error
+		}
+	}
 	BulkUpdateCompClass struct {
 		Input struct {
 			ids   []string
@@ -281,6 +310,26 @@ type TestResults struct {
 			err     error
 		}
 	}
+	GetCompHistory struct {
+		Input struct {
+			id     string
+			f_opts []hmsds.CompHistFiltFunc
+		}
+		Return struct {
+			entries []*sm.CompHistoryEntry
+			err     error
+		}
+	}
+	PruneCompHistory struct {
+		Input struct {
+			before     time.Time
+			maxEntries int
+		}
+		Return struct {
+			numDeleted int64
+			err        error
+		}
+	}
 	// NodeMaps
 	GetNodeMapByID struct {
 		Input struct {
@@ -457,6 +506,16 @@ type TestResults struct {
 			err error
 		}
 	}
+	UpdateHWInvByLocSubtree struct {
+		Input struct {
+			id  string
+			hls []*sm.HWInvByLoc
+		}
+		Return struct {
+			hists []*sm.HWInvHist
+			err   error
+		}
+	}
 	DeleteHWInvByLocID struct {
 		Input struct {
 			id string
@@ -555,6 +614,73 @@ type TestResults struct {
 			err     error
 		}
 	}
+	// HWInv Firmware
+	GetHWInvFirmwareFilter struct {
+		Input struct {
+			f *hmsds.HWInvFirmwareFilter
+		}
+		Return struct {
+			fws []*sm.HWInvFirmware
+			err error
+		}
+	}
+	UpdateHWInvFirmwareForRFEndpoint struct {
+		Input struct {
+			rfEPID string
+			fws    []*sm.HWInvFirmware
+		}
+		Return struct {
+			changed []*sm.HWInvFirmware
+			err     error
+		}
+	}
+	// HWInv Fans
+	GetHWInvFanFilter struct {
+		Input struct {
+			f *hmsds.HWInvFanFilter
+		}
+		Return struct {
+			fans []*sm.HWInvFan
+			err  error
+		}
+	}
+	UpdateHWInvFansForRFEndpoint struct {
+		Input struct {
+			rfEPID string
+			fans   []*sm.HWInvFan
+		}
+		Return struct {
+			changed []*sm.HWInvFan
+			err     error
+		}
+	}
+	// Component Health
+	GetCompHealthContributors struct {
+		Input struct {
+			id string
+		}
+		Return struct {
+			hcs []*sm.CompHealthContributor
+			err error
+		}
+	}
+	SetCompHealthContributors struct {
+		Input struct {
+			contribs map[string][]*sm.CompHealthContributor
+		}
+		Return struct {
+			err error
+		}
+	}
+	UpdateCompHealthContributor struct {
+		Input struct {
+			hc *sm.CompHealthContributor
+		}
+		Return struct {
+			hcs []*sm.CompHealthContributor
+			err error
+		}
+	}
 	// Redfish Endpoints
 	GetRFEndpointByID struct {
 		Input struct {
@@ -1016,10 +1142,81 @@ type TestResults struct {
 			err       error
 		}
 	}
+	// Event rules
+	GetEventRulesAll struct {
+		Return struct {
+			rules []*sm.EventRule
+			err   error
+		}
+	}
+	GetEventRule struct {
+		Input struct {
+			name string
+		}
+		Return struct {
+			rule *sm.EventRule
+			err  error
+		}
+	}
+	InsertEventRule struct {
+		Input struct {
+			r *sm.EventRule
+		}
+		Return struct {
+			err error
+		}
+	}
+	UpdateEventRule struct {
+		Input struct {
+			r *sm.EventRule
+		}
+		Return struct {
+			didUpdate bool
+			err       error
+		}
+	}
+	DeleteEventRule struct {
+		Input struct {
+			name string
+		}
+		Return struct {
+			didDelete bool
+			err       error
+		}
+	}
+	// Redfish event log
+	InsertRFEventLogEntries struct {
+		Input struct {
+			entries []*sm.RFEventLogEntry
+		}
+		Return struct {
+			err error
+		}
+	}
+	GetRFEventLogFilter struct {
+		Input struct {
+			f_opts []hmsds.RFEventLogFiltFunc
+		}
+		Return struct {
+			entries []*sm.RFEventLogEntry
+			err     error
+		}
+	}
+	PruneRFEventLog struct {
+		Input struct {
+			before     time.Time
+			maxEntries int
+		}
+		Return struct {
+			numDeleted int64
+			err        error
+		}
+	}
 	// Groups
 	InsertGroup struct {
 		Input struct {
-			g *sm.Group
+			g         *sm.Group
+			requester string
 		}
 		Return struct {
 			label string
@@ -1028,8 +1225,9 @@ type TestResults struct {
 	}
 	UpdateGroup struct {
 		Input struct {
-			label string
-			gp    *sm.GroupPatch
+			label     string
+			gp        *sm.GroupPatch
+			requester string
 		}
 		Return struct {
 			err error
@@ -1053,7 +1251,8 @@ type TestResults struct {
 	}
 	DeleteGroup struct {
 		Input struct {
-			label string
+			label     string
+			requester string
 		}
 		Return struct {
 			didDelete bool
@@ -1062,8 +1261,9 @@ type TestResults struct {
 	}
 	AddGroupMember struct {
 		Input struct {
-			label string
-			id    string
+			label     string
+			id        string
+			requester string
 		}
 		Return struct {
 			id  string
@@ -1072,8 +1272,9 @@ type TestResults struct {
 	}
 	DeleteGroupMember struct {
 		Input struct {
-			label string
-			id    string
+			label     string
+			id        string
+			requester string
 		}
 		Return struct {
 			didDelete bool
@@ -1083,7 +1284,8 @@ type TestResults struct {
 	// Partitions
 	InsertPartition struct {
 		Input struct {
-			p *sm.Partition
+			p         *sm.Partition
+			requester string
 		}
 		Return struct {
 			pname string
@@ -1092,8 +1294,9 @@ type TestResults struct {
 	}
 	UpdatePartition struct {
 		Input struct {
-			pname string
-			pp    *sm.PartitionPatch
+			pname     string
+			pp        *sm.PartitionPatch
+			requester string
 		}
 		Return struct {
 			err error
@@ -1116,7 +1319,8 @@ type TestResults struct {
 	}
 	DeletePartition struct {
 		Input struct {
-			pname string
+			pname     string
+			requester string
 		}
 		Return struct {
 			didDelete bool
@@ -1125,8 +1329,9 @@ type TestResults struct {
 	}
 	AddPartitionMember struct {
 		Input struct {
-			pname string
-			id    string
+			pname     string
+			id        string
+			requester string
 		}
 		Return struct {
 			id  string
@@ -1135,14 +1340,54 @@ type TestResults struct {
 	}
 	DeletePartitionMember struct {
 		Input struct {
-			pname string
-			id    string
+			pname     string
+			id        string
+			requester string
 		}
 		Return struct {
 			didDelete bool
 			err       error
 		}
 	}
+	// Component Labels
+	GetCompLabels struct {
+		Input struct {
+			id string
+		}
+		Return struct {
+			labels *sm.ComponentLabels
+			err    error
+		}
+	}
+	ReplaceCompLabels struct {
+		Input struct {
+			id     string
+			labels map[string]string
+		}
+		Return struct {
+			found bool
+			err   error
+		}
+	}
+	PatchCompLabels struct {
+		Input struct {
+			ids    []string
+			labels map[string]*string
+		}
+		Return struct {
+			ids []string
+			err error
+		}
+	}
+	DeleteCompLabels struct {
+		Input struct {
+			id string
+		}
+		Return struct {
+			found bool
+			err   error
+		}
+	}
 	// Memberships
 	GetMembership struct {
 		Input struct {
@@ -1253,6 +1498,16 @@ type TestResults struct {
 			err       error
 		}
 	}
+	UpdateJobs struct {
+		Input struct {
+			jobIds []string
+			status string
+		}
+		Return struct {
+			num int64
+			err error
+		}
+	}
 	GetJob struct {
 		Input struct {
 			jobId string
@@ -1280,6 +1535,123 @@ type TestResults struct {
 			err       error
 		}
 	}
+	// Service Instances
+	UpsertServiceInstance struct {
+		Input struct {
+			id      string
+			started time.Time
+			leader  bool
+		}
+		Return struct {
+			err error
+		}
+	}
+	GetServiceInstances struct {
+		Input struct {
+			maxAge time.Duration
+		}
+		Return struct {
+			insts []*sm.ServiceInstance
+			err   error
+		}
+	}
+	DeleteServiceInstancesStale struct {
+		Input struct {
+			maxAge time.Duration
+		}
+		Return struct {
+			num int64
+			err error
+		}
+	}
+	TryAdvisoryLock struct {
+		Input struct {
+			key int64
+		}
+		Return struct {
+			locked bool
+			err    error
+		}
+	}
+	ReleaseAdvisoryLock struct {
+		Input struct {
+			key int64
+		}
+		Return struct {
+			err error
+		}
+	}
+	// Change notifications
+	ListenChanges struct {
+		Return struct {
+			changes <-chan string
+			err     error
+		}
+	}
+	GetComponentsGroupExpr struct {
+		Input struct {
+			expr        string
+			fieldFilter hmsds.FieldFilter
+		}
+		Return struct {
+			ids []*base.Component
+			err error
+		}
+	}
+	ReplaceGroupMembers struct {
+		Input struct {
+			label     string
+			ms        *sm.Members
+			version   int64
+			requester string
+		}
+		Return struct {
+			version int64
+			err     error
+		}
+	}
+	ReplacePartitionMembers struct {
+		Input struct {
+			pname     string
+			ms        *sm.Members
+			version   int64
+			requester string
+		}
+		Return struct {
+			version int64
+			err     error
+		}
+	}
+	MovePartitionMembers struct {
+		Input struct {
+			pname     string
+			pm        *sm.PartitionMove
+			requester string
+		}
+		Return struct {
+			err error
+		}
+	}
+	GetGroupHistory struct {
+		Input struct {
+			label  string
+			f_opts []hmsds.GroupHistFiltFunc
+		}
+		Return struct {
+			entries []*sm.GroupHistoryEntry
+			err     error
+		}
+	}
+	GetPartitionHistory struct {
+		Input struct {
+			pname  string
+			f_opts []hmsds.GroupHistFiltFunc
+		}
+		Return struct {
+			entries []*sm.GroupHistoryEntry
+			err     error
+		}
+	}
 }
 
 type hmsdbtest struct {
@@ -1425,6 +1797,12 @@ func (d *hmsdbtest) GetComponentsQuery(f *hmsds.ComponentFilter, fieldFltr hmsds
 	return d.t.GetComponentsQuery.Return.ids, d.t.GetComponentsQuery.Return.err
 }
 
+func (d *hmsdbtest) GetComponentsGroupExpr(e *sm.GroupExpr, fieldFltr hmsds.FieldFilter) ([]*base.Component, error) {
+	d.t.GetComponentsGroupExpr.Input.expr = e.String()
+	d.t.GetComponentsGroupExpr.Input.fieldFilter = fieldFltr
+	return d.t.GetComponentsGroupExpr.Return.ids, d.t.GetComponentsGroupExpr.Return.err
+}
+
 // Get a single component by its NID, if one exists.
 func (d *hmsdbtest) GetComponentByNID(nid string) (*base.Component, error) {
 	d.t.GetComponentByNID.Input.nid = nid
@@ -1448,9 +1826,10 @@ func (d *hmsdbtest) InsertComponents(comps *base.ComponentArray) ([]string, erro
 // Inserts or updates ComponentArray entries in database within a
 // single all-or-none transaction. This will only overwrite the NID
 // and Role fields (if set) for existing components unless force=true.
-func (d *hmsdbtest) UpsertComponents(comps []*base.Component, force bool) (map[string]map[string]bool, error) {
+func (d *hmsdbtest) UpsertComponents(comps []*base.Component, force bool, source string) (map[string]map[string]bool, error) {
 	d.t.UpsertComponents.Input.comps = comps
 	d.t.UpsertComponents.Input.force = force
+	d.t.UpsertComponents.Input.source = source
 	return d.t.UpsertComponents.Return.changeMap, d.t.UpsertComponents.Return.err
 }
 
@@ -1461,82 +1840,126 @@ func (d *hmsdbtest) UpsertComponents(comps []*base.Component, force bool) (map[s
 // If force = true ignores any starting state restrictions and will
 // always set ids to 'state', unless it is already set.
 //   Note: If flag is not set, it will be set to OK (i.e. no flag)
-func (d *hmsdbtest) UpdateCompStates(ids []string, state string, flag string, force bool, pi *hmsds.PartInfo) ([]string, error) {
+func (d *hmsdbtest) UpdateCompStates(ids []string, state string, flag string, force bool, pi *hmsds.PartInfo, source string, check hmsds.CompStateCheck) ([]string, error) {
 	d.t.UpdateCompStates.Input.ids = ids
 	d.t.UpdateCompStates.Input.state = state
 	d.t.UpdateCompStates.Input.flag = flag
 	d.t.UpdateCompStates.Input.force = force
 	d.t.UpdateCompStates.Input.pi = pi
+	d.t.UpdateCompStates.Input.source = source
+	d.t.UpdateCompStates.Input.check = check
+	if check != nil {
+		var checkErr error
+		for _, comp := range d.t.UpdateCompStates.Locked {
+			if err := check(comp, state); err != nil && checkErr == nil {
+				checkErr = err
+			}
+		}
+		if checkErr != nil {
+			return []string{}, checkErr
+		}
+	}
 	return d.t.UpdateCompStates.Return.affectedIds, d.t.UpdateCompStates.Return.err
 }
 
 // Update Flag field in DB from c's Flag field.
 // Returns the number of affected rows. < 0 means RowsAffected() is not supported.
 // Note: Flag cannot be blank/invalid.
-func (d *hmsdbtest) UpdateCompFlagOnly(id string, flag string) (int64, error) {
+func (d *hmsdbtest) UpdateCompFlagOnly(id string, flag string, source string) (int64, error) {
 	d.t.UpdateCompFlagOnly.Input.id = id
 	d.t.UpdateCompFlagOnly.Input.flag = flag
+	d.t.UpdateCompFlagOnly.Input.source = source
 	return d.t.UpdateCompFlagOnly.Return.rowsAffected, d.t.UpdateCompFlagOnly.Return.err
 }
 
 // Update flag field in DB for a list of components
 // Note: Flag cannot be empty/invalid.
-func (d *hmsdbtest) BulkUpdateCompFlagOnly(ids []string, flag string) ([]string, error) {
+func (d *hmsdbtest) BulkUpdateCompFlagOnly(ids []string, flag string, source string) ([]string, error) {
 	d.t.BulkUpdateCompFlagOnly.Input.ids = ids
 	d.t.BulkUpdateCompFlagOnly.Input.flag = flag
+	d.t.BulkUpdateCompFlagOnly.Input.source = source
 	return d.t.BulkUpdateCompFlagOnly.Return.affectedIds, d.t.BulkUpdateCompFlagOnly.Return.err
 }
 
 // Update Enabled field in DB from c's Enabled field.
 // Returns the number of affected rows. < 0 means RowsAffected() is not supported.
 // Note: c.Enabled cannot be nil
-func (d *hmsdbtest) UpdateCompEnabled(id string, enabled bool) (int64, error) {
+func (d *hmsdbtest) UpdateCompEnabled(id string, enabled bool, source string) (int64, error) {
 	d.t.UpdateCompEnabled.Input.id = id
 	d.t.UpdateCompEnabled.Input.enabled = enabled
+	d.t.UpdateCompEnabled.Input.source = source
 	return d.t.UpdateCompEnabled.Return.rowsAffected, d.t.UpdateCompEnabled.Return.err
 }
 
 // Update Enabled field in DB for a list of components
-func (d *hmsdbtest) BulkUpdateCompEnabled(ids []string, enabled bool) ([]string, error) {
+func (d *hmsdbtest) BulkUpdateCompEnabled(ids []string, enabled bool, source string) ([]string, error) {
 	d.t.BulkUpdateCompEnabled.Input.ids = ids
 	d.t.BulkUpdateCompEnabled.Input.enabled = enabled
+	d.t.BulkUpdateCompEnabled.Input.source = source
 	return d.t.BulkUpdateCompEnabled.Return.affectedIds, d.t.BulkUpdateCompEnabled.Return.err
 }
 
 // Update SwStatus field in DB from c's SwStatus field.
 // Returns the number of affected rows. < 0 means RowsAffected() is not supported.
-func (d *hmsdbtest) UpdateCompSwStatus(id string, swStatus string) (int64, error) {
+func (d *hmsdbtest) UpdateCompSwStatus(id string, swStatus string, source string) (int64, error) {
 	d.t.UpdateCompSwStatus.Input.id = id
 	d.t.UpdateCompSwStatus.Input.swStatus = swStatus
+	d.t.UpdateCompSwStatus.Input.source = source
 	return d.t.UpdateCompSwStatus.Return.rowsAffected, d.t.UpdateCompSwStatus.Return.err
 }
 
 // Update SwStatus field in DB for a list of components
-func (d *hmsdbtest) BulkUpdateCompSwStatus(ids []string, swstatus string) ([]string, error) {
+func (d *hmsdbtest) BulkUpdateCompSwStatus(ids []string, swstatus string, source string) ([]string, error) {
 	d.t.BulkUpdateCompSwStatus.Input.ids = ids
 	d.t.BulkUpdateCompSwStatus.Input.swstatus = swstatus
+	d.t.BulkUpdateCompSwStatus.Input.source = source
 	return d.t.BulkUpdateCompSwStatus.Return.affectedIds, d.t.BulkUpdateCompSwStatus.Return.err
 }
 
 // Update Role field in DB from c's Role field.
 // Returns the number of affected rows. < 0 means RowsAffected() is not supported.
 // Note: Role cannot be blank/invalid.
-func (d *hmsdbtest) UpdateCompRole(id string, role, subRole string) (int64, error) {
+func (d *hmsdbtest) UpdateCompRole(id string, role, subRole string, source string) (int64, error) {
 	d.t.UpdateCompRole.Input.id = id
 	d.t.UpdateCompRole.Input.role = role
 	d.t.UpdateCompRole.Input.subRole = subRole
+	d.t.UpdateCompRole.Input.source = source
 	return d.t.UpdateCompRole.Return.rowsAffected, d.t.UpdateCompRole.Return.err
 }
 
 // Update Role field in DB for a list of components
 // Note: Role cannot be empty/invalid.
-func (d *hmsdbtest) BulkUpdateCompRole(ids []string, role, subRole string) ([]string, error) {
+func (d *hmsdbtest) BulkUpdateCompRole(ids []string, role, subRole string, source string) ([]string, error) {
 	d.t.BulkUpdateCompRole.Input.ids = ids
 	d.t.BulkUpdateCompRole.Input.role = role
 	d.t.BulkUpdateCompRole.Input.subRole = subRole
+	d.t.BulkUpdateCompRole.Input.source = source
 	return d.t.BulkUpdateCompRole.Return.affectedIds, d.t.BulkUpdateCompRole.Return.err
 }
 
+// Apply multi-field changes to a list of components in one transaction.
+func (d *hmsdbtest) PatchComponents(patches []*sm.CompPatch, force bool, source string, check hmsds.CompStateCheck) ([]string, []sm.CompPatchFailure, error) {
+	d.t.PatchComponents.Input.patches = patches
+	d.t.PatchComponents.Input.force = force
+	d.t.PatchComponents.Input.source = source
+	d.t.PatchComponents.Input.check = check
+	failures := d.t.PatchComponents.Return.failures
+	if check != nil {
+		for _, p := range patches {
+			for _, comp := range d.t.PatchComponents.Locked {
+				if comp.ID != p.ID || p.State == "" {
+					continue
+				}
+				if err := check(comp, p.State); err != nil {
+					failures = append(failures,
+						sm.CompPatchFailure{ID: p.ID, Reason: err.Error()})
+				}
+			}
+		}
+	}
+	return d.t.PatchComponents.Return.changedIds, failures, d.t.PatchComponents.Return.err
+}
+
 // Update Class field in DB for a list of components
 func (d *hmsdbtest) BulkUpdateCompClass(ids []string, class string) ([]string, error) {
 	d.t.BulkUpdateCompClass.Input.ids = ids
@@ -1572,6 +1995,18 @@ func (d *hmsdbtest) DeleteComponentsAll() (int64, error) {
 	return d.t.DeleteComponentsAll.Return.numRows, d.t.DeleteComponentsAll.Return.err
 }
 
+func (d *hmsdbtest) GetCompHistory(id string, f_opts ...hmsds.CompHistFiltFunc) ([]*sm.CompHistoryEntry, error) {
+	d.t.GetCompHistory.Input.id = id
+	d.t.GetCompHistory.Input.f_opts = f_opts
+	return d.t.GetCompHistory.Return.entries, d.t.GetCompHistory.Return.err
+}
+
+func (d *hmsdbtest) PruneCompHistory(before time.Time, maxEntries int) (int64, error) {
+	d.t.PruneCompHistory.Input.before = before
+	d.t.PruneCompHistory.Input.maxEntries = maxEntries
+	return d.t.PruneCompHistory.Return.numDeleted, d.t.PruneCompHistory.Return.err
+}
+
 /////////////////////////////////////////////////////////////////////////////
 //
 // Node->NID Mapping
@@ -1763,6 +2198,12 @@ func (d *hmsdbtest) InsertHWInvByLocs(hls []*sm.HWInvByLoc) error {
 	return d.t.InsertHWInvByLocs.Return.err
 }
 
+func (d *hmsdbtest) UpdateHWInvByLocSubtree(id string, hls []*sm.HWInvByLoc) ([]*sm.HWInvHist, error) {
+	d.t.UpdateHWInvByLocSubtree.Input.id = id
+	d.t.UpdateHWInvByLocSubtree.Input.hls = hls
+	return d.t.UpdateHWInvByLocSubtree.Return.hists, d.t.UpdateHWInvByLocSubtree.Return.err
+}
+
 // Delete HWInvByLoc entry with matching xname id from database, if it
 // exists.
 // Return true if there was a row affected, false if there were zero.
@@ -1864,6 +2305,78 @@ func (d *hmsdbtest) DeleteHWInvHistFilter(f_opts ...hmsds.HWInvHistFiltFunc) (in
 	return d.t.DeleteHWInvHistFilter.Return.numRows, d.t.DeleteHWInvHistFilter.Return.err
 }
 
+////////////////////////////////////////////////////////////////////////////
+//
+// HWInvFirmware - Firmware/software versions from the Redfish UpdateService
+//
+////////////////////////////////////////////////////////////////////////////
+
+// Get firmware/software inventory entries with filtering options to
+// possibly narrow the returned values.
+func (d *hmsdbtest) GetHWInvFirmwareFilter(f_opts ...hmsds.HWInvFirmwareFiltFunc) ([]*sm.HWInvFirmware, error) {
+	f := new(hmsds.HWInvFirmwareFilter)
+	for _, opts := range f_opts {
+		opts(f)
+	}
+	d.t.GetHWInvFirmwareFilter.Input.f = f
+	return d.t.GetHWInvFirmwareFilter.Return.fws, d.t.GetHWInvFirmwareFilter.Return.err
+}
+
+// Replace the firmware/software inventory entries for a RedfishEndpoint.
+func (d *hmsdbtest) UpdateHWInvFirmwareForRFEndpoint(rfEPID string, fws []*sm.HWInvFirmware) ([]*sm.HWInvFirmware, error) {
+	d.t.UpdateHWInvFirmwareForRFEndpoint.Input.rfEPID = rfEPID
+	d.t.UpdateHWInvFirmwareForRFEndpoint.Input.fws = fws
+	return d.t.UpdateHWInvFirmwareForRFEndpoint.Return.changed, d.t.UpdateHWInvFirmwareForRFEndpoint.Return.err
+}
+
+////////////////////////////////////////////////////////////////////////////
+//
+// HWInvFan - Fans from Redfish Chassis Thermal/ThermalSubsystem
+//
+////////////////////////////////////////////////////////////////////////////
+
+// Get fan inventory entries with filtering options to possibly narrow the
+// returned values.
+func (d *hmsdbtest) GetHWInvFanFilter(f_opts ...hmsds.HWInvFanFiltFunc) ([]*sm.HWInvFan, error) {
+	f := new(hmsds.HWInvFanFilter)
+	for _, opts := range f_opts {
+		opts(f)
+	}
+	d.t.GetHWInvFanFilter.Input.f = f
+	return d.t.GetHWInvFanFilter.Return.fans, d.t.GetHWInvFanFilter.Return.err
+}
+
+// Replace the fan inventory entries for a RedfishEndpoint.
+func (d *hmsdbtest) UpdateHWInvFansForRFEndpoint(rfEPID string, fans []*sm.HWInvFan) ([]*sm.HWInvFan, error) {
+	d.t.UpdateHWInvFansForRFEndpoint.Input.rfEPID = rfEPID
+	d.t.UpdateHWInvFansForRFEndpoint.Input.fans = fans
+	return d.t.UpdateHWInvFansForRFEndpoint.Return.changed, d.t.UpdateHWInvFansForRFEndpoint.Return.err
+}
+
+////////////////////////////////////////////////////////////////////////////
+//
+// Component Health - Sources of non-OK component flags
+//
+////////////////////////////////////////////////////////////////////////////
+
+// Get the health contributors recorded for the given component.
+func (d *hmsdbtest) GetCompHealthContributors(id string) ([]*sm.CompHealthContributor, error) {
+	d.t.GetCompHealthContributors.Input.id = id
+	return d.t.GetCompHealthContributors.Return.hcs, d.t.GetCompHealthContributors.Return.err
+}
+
+// Replace the health contributors of each component in contribs.
+func (d *hmsdbtest) SetCompHealthContributors(contribs map[string][]*sm.CompHealthContributor) error {
+	d.t.SetCompHealthContributors.Input.contribs = contribs
+	return d.t.SetCompHealthContributors.Return.err
+}
+
+// Record the health of a single contributor.
+func (d *hmsdbtest) UpdateCompHealthContributor(hc *sm.CompHealthContributor) ([]*sm.CompHealthContributor, error) {
+	d.t.UpdateCompHealthContributor.Input.hc = hc
+	return d.t.UpdateCompHealthContributor.Return.hcs, d.t.UpdateCompHealthContributor.Return.err
+}
+
 ////////////////////////////////////////////////////////////////////////////
 //
 // Redfish Endpoints - Top-level Redfish service roots used for discovery
@@ -2371,6 +2884,59 @@ func (d *hmsdbtest) DeleteSCNSubscriptionsAll() (int64, error) {
 	return d.t.DeleteSCNSubscriptionsAll.Return.numDelete, d.t.DeleteSCNSubscriptionsAll.Return.err
 }
 
+////////////////////////////////////////////////////////////////////////////
+//
+// Event Rules - Mapping of Redfish events to state changes
+//
+////////////////////////////////////////////////////////////////////////////
+
+func (d *hmsdbtest) GetEventRulesAll() ([]*sm.EventRule, error) {
+	return d.t.GetEventRulesAll.Return.rules, d.t.GetEventRulesAll.Return.err
+}
+
+func (d *hmsdbtest) GetEventRule(name string) (*sm.EventRule, error) {
+	d.t.GetEventRule.Input.name = name
+	return d.t.GetEventRule.Return.rule, d.t.GetEventRule.Return.err
+}
+
+func (d *hmsdbtest) InsertEventRule(r *sm.EventRule) error {
+	d.t.InsertEventRule.Input.r = r
+	return d.t.InsertEventRule.Return.err
+}
+
+func (d *hmsdbtest) UpdateEventRule(r *sm.EventRule) (bool, error) {
+	d.t.UpdateEventRule.Input.r = r
+	return d.t.UpdateEventRule.Return.didUpdate, d.t.UpdateEventRule.Return.err
+}
+
+func (d *hmsdbtest) DeleteEventRule(name string) (bool, error) {
+	d.t.DeleteEventRule.Input.name = name
+	return d.t.DeleteEventRule.Return.didDelete, d.t.DeleteEventRule.Return.err
+}
+
+////////////////////////////////////////////////////////////////////////////
+//
+// Redfish event log
+//
+////////////////////////////////////////////////////////////////////////////
+
+func (d *hmsdbtest) InsertRFEventLogEntries(entries []*sm.RFEventLogEntry) error {
+	d.t.InsertRFEventLogEntries.Input.entries = append(
+		d.t.InsertRFEventLogEntries.Input.entries, entries...)
+	return d.t.InsertRFEventLogEntries.Return.err
+}
+
+func (d *hmsdbtest) GetRFEventLogFilter(f_opts ...hmsds.RFEventLogFiltFunc) ([]*sm.RFEventLogEntry, error) {
+	d.t.GetRFEventLogFilter.Input.f_opts = f_opts
+	return d.t.GetRFEventLogFilter.Return.entries, d.t.GetRFEventLogFilter.Return.err
+}
+
+func (d *hmsdbtest) PruneRFEventLog(before time.Time, maxEntries int) (int64, error) {
+	d.t.PruneRFEventLog.Input.before = before
+	d.t.PruneRFEventLog.Input.maxEntries = maxEntries
+	return d.t.PruneRFEventLog.Return.numDeleted, d.t.PruneRFEventLog.Return.err
+}
+
 ////////////////////////////////////////////////////////////////////////////
 //
 // Group and Partition  Management
@@ -2384,15 +2950,17 @@ func (d *hmsdbtest) DeleteSCNSubscriptionsAll() (int64, error) {
 // Create a group.  Returns new label (should match one in struct,
 // unless case-normalized) if successful, otherwise empty string + non
 // nil error.
-func (d *hmsdbtest) InsertGroup(g *sm.Group) (string, error) {
+func (d *hmsdbtest) InsertGroup(g *sm.Group, requester string) (string, error) {
 	d.t.InsertGroup.Input.g = g
+	d.t.InsertGroup.Input.requester = requester
 	return d.t.InsertGroup.Return.label, d.t.InsertGroup.Return.err
 }
 
 // Update group with label
-func (d *hmsdbtest) UpdateGroup(label string, gp *sm.GroupPatch) error {
+func (d *hmsdbtest) UpdateGroup(label string, gp *sm.GroupPatch, requester string) error {
 	d.t.UpdateGroup.Input.label = label
 	d.t.UpdateGroup.Input.gp = gp
+	d.t.UpdateGroup.Input.requester = requester
 	return d.t.UpdateGroup.Return.err
 }
 
@@ -2413,8 +2981,9 @@ func (d *hmsdbtest) GetGroupLabels() ([]string, error) {
 
 // Delete entire group with the given label.  If no error, bool indicates
 // whether member was present to remove.
-func (d *hmsdbtest) DeleteGroup(label string) (bool, error) {
+func (d *hmsdbtest) DeleteGroup(label, requester string) (bool, error) {
 	d.t.DeleteGroup.Input.label = label
+	d.t.DeleteGroup.Input.requester = requester
 	return d.t.DeleteGroup.Return.didDelete, d.t.DeleteGroup.Return.err
 }
 
@@ -2424,20 +2993,32 @@ func (d *hmsdbtest) DeleteGroup(label string) (bool, error) {
 // set.
 // Returns key of new member, should be same as id after normalization,
 // if any.  Label should already be normalized.
-func (d *hmsdbtest) AddGroupMember(label, id string) (string, error) {
+func (d *hmsdbtest) AddGroupMember(label, id, requester string) (string, error) {
 	d.t.AddGroupMember.Input.label = label
 	d.t.AddGroupMember.Input.id = id
+	d.t.AddGroupMember.Input.requester = requester
 	return d.t.AddGroupMember.Return.id, d.t.AddGroupMember.Return.err
 }
 
 // Delete Group member from label.  If no error, bool indicates whether
 // group was present to remove.
-func (d *hmsdbtest) DeleteGroupMember(label, id string) (bool, error) {
+func (d *hmsdbtest) DeleteGroupMember(label, id, requester string) (bool, error) {
 	d.t.DeleteGroupMember.Input.label = label
 	d.t.DeleteGroupMember.Input.id = id
+	d.t.DeleteGroupMember.Input.requester = requester
 	return d.t.DeleteGroupMember.Return.didDelete, d.t.DeleteGroupMember.Return.err
 }
 
+// Replace the whole member list of group label with ms, in a single
+// transaction.  Returns the group's new version.
+func (d *hmsdbtest) ReplaceGroupMembers(label string, ms *sm.Members, version int64, requester string) (int64, error) {
+	d.t.ReplaceGroupMembers.Input.label = label
+	d.t.ReplaceGroupMembers.Input.ms = ms
+	d.t.ReplaceGroupMembers.Input.version = version
+	d.t.ReplaceGroupMembers.Input.requester = requester
+	return d.t.ReplaceGroupMembers.Return.version, d.t.ReplaceGroupMembers.Return.err
+}
+
 //
 // Partitions
 //
@@ -2445,15 +3026,17 @@ func (d *hmsdbtest) DeleteGroupMember(label, id string) (bool, error) {
 // Create a partition.  Returns new name (should match one in struct,
 // unless case-normalized) if successful, otherwise empty string + non
 // nil error.
-func (d *hmsdbtest) InsertPartition(p *sm.Partition) (string, error) {
+func (d *hmsdbtest) InsertPartition(p *sm.Partition, requester string) (string, error) {
 	d.t.InsertPartition.Input.p = p
+	d.t.InsertPartition.Input.requester = requester
 	return d.t.InsertPartition.Return.pname, d.t.InsertPartition.Return.err
 }
 
 // Update Partition with given name
-func (d *hmsdbtest) UpdatePartition(pname string, pp *sm.PartitionPatch) error {
+func (d *hmsdbtest) UpdatePartition(pname string, pp *sm.PartitionPatch, requester string) error {
 	d.t.UpdatePartition.Input.pname = pname
 	d.t.UpdatePartition.Input.pp = pp
+	d.t.UpdatePartition.Input.requester = requester
 	return d.t.UpdatePartition.Return.err
 }
 
@@ -2471,8 +3054,9 @@ func (d *hmsdbtest) GetPartitionNames() ([]string, error) {
 
 // Delete entire partition with pname.  If no error, bool indicates
 // whether partition was present to remove.
-func (d *hmsdbtest) DeletePartition(pname string) (bool, error) {
+func (d *hmsdbtest) DeletePartition(pname, requester string) (bool, error) {
 	d.t.DeletePartition.Input.pname = pname
+	d.t.DeletePartition.Input.requester = requester
 	return d.t.DeletePartition.Return.didDelete, d.t.DeletePartition.Return.err
 }
 
@@ -2481,20 +3065,94 @@ func (d *hmsdbtest) DeletePartition(pname string) (bool, error) {
 // is already in a different partition.
 // Returns key of new member, should be same as id after normalization,
 // if any.  pname should already be normalized.
-func (d *hmsdbtest) AddPartitionMember(pname, id string) (string, error) {
+func (d *hmsdbtest) AddPartitionMember(pname, id, requester string) (string, error) {
 	d.t.AddPartitionMember.Input.pname = pname
 	d.t.AddPartitionMember.Input.id = id
+	d.t.AddPartitionMember.Input.requester = requester
 	return d.t.AddPartitionMember.Return.id, d.t.AddPartitionMember.Return.err
 }
 
 // Delete partition member from partition.  If no error, bool indicates
 // whether member was present to remove.
-func (d *hmsdbtest) DeletePartitionMember(pname, id string) (bool, error) {
+func (d *hmsdbtest) DeletePartitionMember(pname, id, requester string) (bool, error) {
 	d.t.DeletePartitionMember.Input.pname = pname
 	d.t.DeletePartitionMember.Input.id = id
+	d.t.DeletePartitionMember.Input.requester = requester
 	return d.t.DeletePartitionMember.Return.didDelete, d.t.DeletePartitionMember.Return.err
 }
 
+// Replace the whole member list of partition pname with ms, in a single
+// transaction.  Returns the partition's new version.
+func (d *hmsdbtest) ReplacePartitionMembers(pname string, ms *sm.Members, version int64, requester string) (int64, error) {
+	d.t.ReplacePartitionMembers.Input.pname = pname
+	d.t.ReplacePartitionMembers.Input.ms = ms
+	d.t.ReplacePartitionMembers.Input.version = version
+	d.t.ReplacePartitionMembers.Input.requester = requester
+	return d.t.ReplacePartitionMembers.Return.version, d.t.ReplacePartitionMembers.Return.err
+}
+
+// Move the members in pm from sub-partition pm.Source to its sibling pname.
+func (d *hmsdbtest) MovePartitionMembers(pname string, pm *sm.PartitionMove, requester string) error {
+	d.t.MovePartitionMembers.Input.pname = pname
+	d.t.MovePartitionMembers.Input.pm = pm
+	d.t.MovePartitionMembers.Input.requester = requester
+	return d.t.MovePartitionMembers.Return.err
+}
+
+//
+// History
+//
+
+// Get the recorded changes to the group with the given label, oldest
+// first, matching the filter options.
+func (d *hmsdbtest) GetGroupHistory(label string, f_opts ...hmsds.GroupHistFiltFunc) ([]*sm.GroupHistoryEntry, error) {
+	d.t.GetGroupHistory.Input.label = label
+	d.t.GetGroupHistory.Input.f_opts = f_opts
+	return d.t.GetGroupHistory.Return.entries, d.t.GetGroupHistory.Return.err
+}
+
+// Get the recorded changes to the partition with the given name, oldest
+// first, matching the filter options.
+func (d *hmsdbtest) GetPartitionHistory(pname string, f_opts ...hmsds.GroupHistFiltFunc) ([]*sm.GroupHistoryEntry, error) {
+	d.t.GetPartitionHistory.Input.pname = pname
+	d.t.GetPartitionHistory.Input.f_opts = f_opts
+	return d.t.GetPartitionHistory.Return.entries, d.t.GetPartitionHistory.Return.err
+}
+
+//
+// Component Labels
+//
+
+// Get the labels on the component with the given xname id.  Returns nil
+// and a nil error if there is no such component.
+func (d *hmsdbtest) GetCompLabels(id string) (*sm.ComponentLabels, error) {
+	d.t.GetCompLabels.Input.id = id
+	return d.t.GetCompLabels.Return.labels, d.t.GetCompLabels.Return.err
+}
+
+// Replace all of the labels on the component with the given xname id.
+// Returns false if there is no such component.
+func (d *hmsdbtest) ReplaceCompLabels(id string, labels map[string]string) (bool, error) {
+	d.t.ReplaceCompLabels.Input.id = id
+	d.t.ReplaceCompLabels.Input.labels = labels
+	return d.t.ReplaceCompLabels.Return.found, d.t.ReplaceCompLabels.Return.err
+}
+
+// Set, or for nil values remove, the given labels on each of the
+// components with the given xname ids.  Returns the ids that were updated.
+func (d *hmsdbtest) PatchCompLabels(ids []string, labels map[string]*string) ([]string, error) {
+	d.t.PatchCompLabels.Input.ids = ids
+	d.t.PatchCompLabels.Input.labels = labels
+	return d.t.PatchCompLabels.Return.ids, d.t.PatchCompLabels.Return.err
+}
+
+// Remove all of the labels from the component with the given xname id.
+// Returns false if there is no such component.
+func (d *hmsdbtest) DeleteCompLabels(id string) (bool, error) {
+	d.t.DeleteCompLabels.Input.id = id
+	return d.t.DeleteCompLabels.Return.found, d.t.DeleteCompLabels.Return.err
+}
+
 //
 // Memberships
 //
@@ -2607,6 +3265,13 @@ func (d *hmsdbtest) UpdateJob(jobId, status string) (bool, error) {
 	return d.t.UpdateJob.Return.didUpdate, d.t.UpdateJob.Return.err
 }
 
+// Update the status of all of the jobs with the given jobIds at once.
+func (d *hmsdbtest) UpdateJobs(jobIds []string, status string) (int64, error) {
+	d.t.UpdateJobs.Input.jobIds = jobIds
+	d.t.UpdateJobs.Input.status = status
+	return d.t.UpdateJobs.Return.num, d.t.UpdateJobs.Return.err
+}
+
 // Get the job sync entry with the given job id. Nil if not found and nil
 // error, otherwise non-nil error (not normally expected).
 func (d *hmsdbtest) GetJob(jobId string) (*sm.Job, error) {
@@ -2630,3 +3295,46 @@ func (d *hmsdbtest) DeleteJob(jobId string) (bool, error) {
 	d.t.DeleteJob.Input.jobId = jobId
 	return d.t.DeleteJob.Return.didDelete, d.t.DeleteJob.Return.err
 }
+
+////////////////////////////////////////////////////////////////////////////
+//
+// Service Instances - Cluster of HSM instances
+//
+////////////////////////////////////////////////////////////////////////////
+
+func (d *hmsdbtest) UpsertServiceInstance(id string, started time.Time, leader bool) error {
+	d.t.UpsertServiceInstance.Input.id = id
+	d.t.UpsertServiceInstance.Input.started = started
+	d.t.UpsertServiceInstance.Input.leader = leader
+	return d.t.UpsertServiceInstance.Return.err
+}
+
+func (d *hmsdbtest) GetServiceInstances(maxAge time.Duration) ([]*sm.ServiceInstance, error) {
+	d.t.GetServiceInstances.Input.maxAge = maxAge
+	return d.t.GetServiceInstances.Return.insts, d.t.GetServiceInstances.Return.err
+}
+
+func (d *hmsdbtest) DeleteServiceInstancesStale(maxAge time.Duration) (int64, error) {
+	d.t.DeleteServiceInstancesStale.Input.maxAge = maxAge
+	return d.t.DeleteServiceInstancesStale.Return.num, d.t.DeleteServiceInstancesStale.Return.err
+}
+
+func (d *hmsdbtest) TryAdvisoryLock(key int64) (bool, error) {
+	d.t.TryAdvisoryLock.Input.key = key
+	return d.t.TryAdvisoryLock.Return.locked, d.t.TryAdvisoryLock.Return.err
+}
+
+func (d *hmsdbtest) ReleaseAdvisoryLock(key int64) error {
+	d.t.ReleaseAdvisoryLock.Input.key = key
+	return d.t.ReleaseAdvisoryLock.Return.err
+}
+
+////////////////////////////////////////////////////////////////////////////
+//
+// Change notifications - Invalidating in-memory copies
+//
+////////////////////////////////////////////////////////////////////////////
+
+func (d *hmsdbtest) ListenChanges() (<-chan string, error) {
+	return d.t.ListenChanges.Return.changes, d.t.ListenChanges.Return.err
+}
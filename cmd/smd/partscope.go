@@ -0,0 +1,449 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	base "github.com/Cray-HPE/hms-base/v2"
+	"github.com/Cray-HPE/hms-smd/v2/internal/hmsds"
+	"github.com/Cray-HPE/hms-smd/v2/pkg/sm"
+	"github.com/Cray-HPE/hms-xname/xnametypes"
+)
+
+/////////////////////////////////////////////////////////////////////////////
+// Partition scoping
+//
+// A request can be bound to a partition, either with the HMS-Partition
+// header or with a claim in its bearer token.  A bound request can only see
+// and change the components in that partition (and their subcomponents),
+// and only the API routes that know how to restrict themselves this way
+// will accept it at all.  Unbound requests are not affected.
+/////////////////////////////////////////////////////////////////////////////
+
+// Header binding a request to a partition.
+const PartitionHeader = "HMS-Partition"
+
+// Default token claim binding a request to a partition.  The token is
+// assumed to have been verified by the API gateway before it gets here.
+const partClaimDefault = "partition"
+
+var ErrSMDPartScopeConf = e.NewChild("HMS-Partition header does not match the token's partition claim")
+var ErrSMDPartScopeBad = e.NewChild("invalid partition in HMS-Partition header or token claim")
+var ErrSMDPartScopeRoute = e.NewChild("not permitted for requests bound to a partition")
+var ErrSMDPartScopeOther = e.NewChild("request is bound to a different partition")
+var ErrSMDPartScopeIDs = e.NewChild("component(s) outside the bound partition")
+
+// Routes accepted for requests bound to a partition.  Every handler here
+// must restrict what it reads and writes to the partition.
+var partScopedRoutes = map[string]bool{
+	"doReadyGetV2":                           true,
+	"doLivenessGetV2":                        true,
+	"doValuesGetV2":                          true,
+	"doArchValuesGetV2":                      true,
+	"doClassValuesGetV2":                     true,
+	"doFlagValuesGetV2":                      true,
+	"doNetTypeValuesGetV2":                   true,
+	"doRoleValuesGetV2":                      true,
+	"doSubRoleValuesGetV2":                   true,
+	"doStateValuesGetV2":                     true,
+	"doTypeValuesGetV2":                      true,
+	"doComponentGetV2":                       true,
+	"doComponentPutV2":                       true,
+	"doComponentDeleteV2":                    true,
+	"doComponentsGetV2":                      true,
+	"doComponentsPostV2":                     true,
+	"doCompsPatchV2":                         true,
+	"doCompBulkStateDataPatchV2":             true,
+	"doCompStateDataPatchV2":                 true,
+	"doCompBulkFlagOnlyPatchV2":              true,
+	"doCompFlagOnlyPatchV2":                  true,
+	"doCompBulkEnabledPatchV2":               true,
+	"doCompEnabledV2":                        true,
+	"doCompBulkSwStatusPatchV2":              true,
+	"doCompSwStatusV2":                       true,
+	"doCompBulkRolePatchV2":                  true,
+	"doCompRoleV2":                           true,
+	"doCompBulkNIDPatchV2":                   true,
+	"doCompNIDPatchV2":                       true,
+	"doCompBulkLabelsPatchV2":                true,
+	"doCompLabelsGetV2":                      true,
+	"doCompLabelsPutV2":                      true,
+	"doCompLabelsPatchV2":                    true,
+	"doCompLabelsDeleteV2":                   true,
+	"doCompHistoryGetV2":                     true,
+	"doComponentByNIDGetV2":                  true,
+	"doComponentByNIDQueryPostV2":            true,
+	"doCompHealthGetV2":                      true,
+	"doComponentsQueryPostV2":                true,
+	"doComponentsQueryGetV2":                 true,
+	"doHWInvHistByLocationGetV2":             true,
+	"doHWInvHistByLocationGetAllV2":          true,
+	"doHWInvHistByFRUGetV2":                  true,
+	"doHWInvHistByFRUGetAllV2":               true,
+	"doHWInvByLocationQueryGetV2":            true,
+	"doHWInvByFRUGetV2":                      true,
+	"doHWInvByFRUGetAllV2":                   true,
+	"doHWInvByLocationGetV2":                 true,
+	"doHWInvByLocationGetAllV2":              true,
+	"doHWInvFirmwareGetV2":                   true,
+	"doHWInvFirmwareQueryGetV2":              true,
+	"doHWInvFanGetV2":                        true,
+	"doHWInvFanQueryGetV2":                   true,
+	"doGroupsGetV2":                          true,
+	"doGroupsPostV2":                         true,
+	"doGroupsQueryPostV2":                    true,
+	"doGroupLabelsGetV2":                     true,
+	"doGroupGetV2":                           true,
+	"doGroupDeleteV2":                        true,
+	"doGroupPatchV2":                         true,
+	"doGroupMembersGetV2":                    true,
+	"doGroupMembersPostV2":                   true,
+	"doGroupMembersPutV2":                    true,
+	"doGroupMemberDeleteV2":                  true,
+	"doGroupSnapshotPostV2":                  true,
+	"doGroupHistoryGetV2":                    true,
+	"doPartitionsGetV2":                      true,
+	"doPartitionNamesGetV2":                  true,
+	"doPartitionGetV2":                       true,
+	"doPartitionMembersGetV2":                true,
+	"doPartitionHistoryGetV2":                true,
+	"doMembershipsGetV2":                     true,
+	"doMembershipGetV2":                      true,
+	"doCompLocksReservationRemoveV2":         true,
+	"doCompLocksReservationReleaseV2":        true,
+	"doCompLocksReservationCreateV2":         true,
+	"doCompLocksServiceReservationRenewV2":   true,
+	"doCompLocksServiceReservationReleaseV2": true,
+	"doCompLocksServiceReservationCreateV2":  true,
+	"doCompLocksServiceReservationCheckV2":   true,
+	"doCompLocksStatusV2":                    true,
+	"doCompLocksStatusGetV2":                 true,
+	"doCompLocksLockV2":                      true,
+	"doCompLocksUnlockV2":                    true,
+	"doCompLocksRepairV2":                    true,
+	"doCompLocksDisableV2":                   true,
+}
+
+// The partition a request is bound to, and its members.
+type partScope struct {
+	name    string
+	members map[string]bool
+}
+
+type partScopeKey struct{}
+
+func newPartScope(p *sm.Partition) *partScope {
+	ps := &partScope{
+		name:    p.Name,
+		members: make(map[string]bool, len(p.Members.IDs)),
+	}
+	for _, id := range p.Members.IDs {
+		ps.members[xnametypes.NormalizeHMSCompID(id)] = true
+	}
+	return ps
+}
+
+// Get the partition scope of a request, nil if it isn't bound to one.  All
+// of the partScope methods treat nil as allowing everything.
+func getPartScope(r *http.Request) *partScope {
+	ps, _ := r.Context().Value(partScopeKey{}).(*partScope)
+	return ps
+}
+
+// Get the value of a string claim from the bearer token in the
+// Authorization header, if any.  The signature is not checked.
+func getTokenClaim(r *http.Request, claim string) string {
+	auth := strings.TrimSpace(r.Header.Get("Authorization"))
+	if claim == "" || len(auth) < 7 || !strings.EqualFold(auth[:7], "Bearer ") {
+		return ""
+	}
+	parts := strings.Split(strings.TrimSpace(auth[7:]), ".")
+	if len(parts) != 3 {
+		return ""
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return ""
+	}
+	claims := make(map[string]interface{})
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return ""
+	}
+	switch val := claims[claim].(type) {
+	case string:
+		return val
+	case []interface{}:
+		if len(val) == 1 {
+			if str, ok := val[0].(string); ok {
+				return str
+			}
+		}
+	}
+	return ""
+}
+
+// Get the name of the partition a request is bound to, or the empty string
+// if there is none.  If both the header and token claim are given they
+// must agree.
+func (s *SmD) getBoundPartition(r *http.Request) (string, error) {
+	header := sm.NormalizeGroupField(strings.TrimSpace(r.Header.Get(PartitionHeader)))
+	claim := sm.NormalizeGroupField(strings.TrimSpace(getTokenClaim(r, s.partClaim)))
+	if header != "" && claim != "" && header != claim {
+		return "", ErrSMDPartScopeConf
+	}
+	name := claim
+	if name == "" {
+		name = header
+	}
+	if name != "" && sm.VerifyGroupField(name) != nil {
+		return "", ErrSMDPartScopeBad
+	}
+	return name, nil
+}
+
+// Wrap a route handler so that requests bound to a partition carry its
+// scope, or are rejected if the route doesn't support it.
+func (s *SmD) PartScope(inner http.Handler, name string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pname, err := s.getBoundPartition(r)
+		if err != nil {
+			base.DrainAndCloseRequestBody(r)
+			sendJsonError(w, http.StatusForbidden, err.Error())
+			return
+		}
+		if pname == "" {
+			inner.ServeHTTP(w, r)
+			return
+		}
+		if !partScopedRoutes[name] {
+			base.DrainAndCloseRequestBody(r)
+			sendJsonError(w, http.StatusForbidden, ErrSMDPartScopeRoute.Error())
+			return
+		}
+		part, err := s.db.GetPartition(pname)
+		if err != nil {
+			base.DrainAndCloseRequestBody(r)
+			s.lg.Printf("PartScope(): Lookup failure: %s", err)
+			sendJsonDBError(w, "", "", err)
+			return
+		}
+		if part == nil {
+			base.DrainAndCloseRequestBody(r)
+			sendJsonError(w, http.StatusForbidden, "No such partition: "+pname)
+			return
+		}
+		ctx := context.WithValue(r.Context(), partScopeKey{}, newPartScope(part))
+		inner.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// True if the component xname id is a member of the partition or under one,
+// e.g. a processor in a member node.
+func (ps *partScope) Has(id string) bool {
+	if ps == nil {
+		return true
+	}
+	id = xnametypes.NormalizeHMSCompID(id)
+	for id != "" && id != "s0" {
+		if ps.members[id] {
+			return true
+		}
+		parent := xnametypes.GetHMSCompParent(id)
+		if parent == id {
+			break
+		}
+		id = parent
+	}
+	return false
+}
+
+// Get the ids not in the partition.
+func (ps *partScope) Outside(ids []string) []string {
+	outside := []string{}
+	for _, id := range ids {
+		if !ps.Has(id) {
+			outside = append(outside, id)
+		}
+	}
+	return outside
+}
+
+// True if partition name should be hidden from the request, i.e. it is
+// neither the bound partition nor one of its sub-partitions.
+func (ps *partScope) HidesPartition(name string) bool {
+	if ps == nil {
+		return false
+	}
+	name = sm.NormalizeGroupField(name)
+	return name != ps.name && sm.PartitionParent(name) != ps.name
+}
+
+// Restrict the partitions asked for in a query to the one the request is
+// bound to, or its sub-partitions if only those were asked for.  Asking for
+// any other partition is an error.
+func (ps *partScope) Partitions(parts []string) ([]string, error) {
+	if ps == nil {
+		return parts, nil
+	}
+	for _, part := range parts {
+		if ps.HidesPartition(part) {
+			return nil, ErrSMDPartScopeOther
+		}
+	}
+	if len(parts) == 0 {
+		return []string{ps.name}, nil
+	}
+	return parts, nil
+}
+
+// As Partitions, for queries taking at most one partition.
+func (ps *partScope) Partition(part string) (string, error) {
+	if ps == nil {
+		return part, nil
+	}
+	if part == "" {
+		return ps.name, nil
+	} else if ps.HidesPartition(part) {
+		return "", ErrSMDPartScopeOther
+	}
+	return part, nil
+}
+
+// Returns the IDs of the given reservation keys that are outside the
+// partition, if any.
+func (ps *partScope) OutsideKeys(keys []sm.CompLockV2Key) []string {
+	ids := make([]string, 0, len(keys))
+	for _, key := range keys {
+		ids = append(ids, key.ID)
+	}
+	return ps.Outside(ids)
+}
+
+// Restrict a component filter to the partition, keeping any groups asked
+// for.
+func (ps *partScope) FilterComps(f *hmsds.ComponentFilter) error {
+	if ps == nil {
+		return nil
+	}
+	parts, err := ps.Partitions(f.Partition)
+	if err != nil {
+		return err
+	}
+	hmsds.PI(&hmsds.PartInfo{Group: f.Group, Partition: parts})(f)
+	return nil
+}
+
+// Send a 403 for a request touching components outside its partition, if
+// there are any.  Returns true if so.
+func sendJsonPartScopeIDsError(w http.ResponseWriter, outside []string) bool {
+	if len(outside) == 0 {
+		return false
+	}
+	sendJsonError(w, http.StatusForbidden,
+		ErrSMDPartScopeIDs.Error()+": "+strings.Join(outside, ","))
+	return true
+}
+
+// True if the FRU is installed in the partition the request is bound to, or
+// the request isn't bound to one.
+func (s *SmD) partScopeHasFRU(r *http.Request, fruID string) bool {
+	ps := getPartScope(r)
+	if ps == nil {
+		return true
+	}
+	hwfrus, err := s.db.GetHWInvByFRUFilter(
+		hmsds.HWInvLoc_FruIDs([]string{fruID}),
+		hmsds.HWInvLoc_Part(ps.name))
+	if err != nil {
+		s.lg.Printf("partScopeHasFRU(): Lookup failure: (%s) %s", fruID, err)
+		return false
+	}
+	return len(hwfrus) > 0
+}
+
+// Drop any hardware history for locations outside the partition the request
+// is bound to.
+func partScopeHWInvHist(r *http.Request, hwhists []*sm.HWInvHist) []*sm.HWInvHist {
+	ps := getPartScope(r)
+	if ps == nil {
+		return hwhists
+	}
+	inScope := make([]*sm.HWInvHist, 0, len(hwhists))
+	for _, hwhist := range hwhists {
+		if ps.Has(hwhist.ID) {
+			inScope = append(inScope, hwhist)
+		}
+	}
+	return inScope
+}
+
+// Before a request bound to a partition changes group label as a whole,
+// check that all of its members are in the partition, sending an error if
+// not.  Returns false if the request should not go on.
+func (s *SmD) checkGroupPartScope(
+	w http.ResponseWriter,
+	r *http.Request,
+	label string,
+) bool {
+	ps := getPartScope(r)
+	if ps == nil {
+		return true
+	}
+	group, err := s.db.GetGroup(label, "")
+	if err != nil {
+		s.lg.Printf("checkGroupPartScope(): Lookup failure: %s", err)
+		sendJsonDBError(w, "bad query param: ", "", err)
+		return false
+	}
+	if group == nil {
+		// Let the caller report it.
+		return true
+	}
+	return !sendJsonPartScopeIDsError(w, ps.Outside(group.Members.IDs))
+}
+
+// Drop the labels of groups a request bound to a partition can't see, i.e.
+// those with members outside the partition, as checkGroupPartScope would
+// refuse.
+func (s *SmD) partScopeGroupLabels(r *http.Request, labels []string) ([]string, error) {
+	ps := getPartScope(r)
+	if ps == nil {
+		return labels, nil
+	}
+	inScope := make([]string, 0, len(labels))
+	for _, label := range labels {
+		group, err := s.db.GetGroup(label, "")
+		if err != nil {
+			return nil, err
+		}
+		if group != nil && len(ps.Outside(group.Members.IDs)) == 0 {
+			inScope = append(inScope, label)
+		}
+	}
+	return inScope, nil
+}
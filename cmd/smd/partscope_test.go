@@ -0,0 +1,374 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	base "github.com/Cray-HPE/hms-base/v2"
+	"github.com/Cray-HPE/hms-smd/v2/internal/hmsds"
+	"github.com/Cray-HPE/hms-smd/v2/pkg/sm"
+)
+
+var partScopeTestPart = &sm.Partition{
+	Name: "p1",
+	Members: sm.Members{
+		IDs: []string{"x0c0s0b0n0", "x0c0s1b0n0"},
+	},
+}
+
+// Make an unsigned bearer token with the given claims.
+func partScopeTestToken(claims map[string]interface{}) string {
+	payload, _ := json.Marshal(claims)
+	return "Bearer " +
+		base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none"}`)) + "." +
+		base64.RawURLEncoding.EncodeToString(payload) + ".sig"
+}
+
+// Send a request bound to partition p1 with the HMS-Partition header.
+func partScopeTestReq(t *testing.T, method, uri, body string) *httptest.ResponseRecorder {
+	req, err := http.NewRequest(method, uri, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected while creating request", err)
+	}
+	req.Header.Set(PartitionHeader, "p1")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestPartScopeBinding(t *testing.T) {
+	s.partClaim = partClaimDefault
+	defer func() {
+		s.partClaim = ""
+		results.GetPartition.Return.partition = nil
+	}()
+
+	tests := []struct {
+		header       string
+		auth         string
+		partition    *sm.Partition
+		reqURI       string
+		expectedPart string
+		expectedCode int
+		expectedErr  string
+	}{{
+		// Not bound at all
+		reqURI:       "https://localhost/hsm/v2/State/Components/x0c0s2b0n0",
+		expectedCode: http.StatusOK,
+	}, {
+		header:       "p1",
+		partition:    partScopeTestPart,
+		reqURI:       "https://localhost/hsm/v2/State/Components/x0c0s0b0n0",
+		expectedPart: "p1",
+		expectedCode: http.StatusOK,
+	}, {
+		auth:         partScopeTestToken(map[string]interface{}{"partition": "P1"}),
+		partition:    partScopeTestPart,
+		reqURI:       "https://localhost/hsm/v2/State/Components/x0c0s0b0n0",
+		expectedPart: "p1",
+		expectedCode: http.StatusOK,
+	}, {
+		auth:         partScopeTestToken(map[string]interface{}{"partition": []string{"p1"}}),
+		partition:    partScopeTestPart,
+		reqURI:       "https://localhost/hsm/v2/State/Components/x0c0s2b0n0",
+		expectedPart: "p1",
+		expectedCode: http.StatusNotFound,
+	}, {
+		header:       "p2",
+		auth:         partScopeTestToken(map[string]interface{}{"partition": "p1"}),
+		reqURI:       "https://localhost/hsm/v2/State/Components/x0c0s0b0n0",
+		expectedCode: http.StatusForbidden,
+		expectedErr:  ErrSMDPartScopeConf.Error(),
+	}, {
+		header:       "p1!",
+		reqURI:       "https://localhost/hsm/v2/State/Components/x0c0s0b0n0",
+		expectedCode: http.StatusForbidden,
+		expectedErr:  ErrSMDPartScopeBad.Error(),
+	}, {
+		header:       "p1",
+		reqURI:       "https://localhost/hsm/v2/State/Components/x0c0s0b0n0",
+		expectedPart: "p1",
+		expectedCode: http.StatusForbidden,
+		expectedErr:  "No such partition: p1",
+	}, {
+		header:       "p1",
+		partition:    partScopeTestPart,
+		reqURI:       "https://localhost/hsm/v2/Inventory/RedfishEndpoints",
+		expectedCode: http.StatusForbidden,
+		expectedErr:  ErrSMDPartScopeRoute.Error(),
+	}}
+
+	for i, test := range tests {
+		results.GetPartition.Input.pname = ""
+		results.GetPartition.Return.partition = test.partition
+		results.GetComponentByID.Return.id = &base.Component{ID: "x0c0s0b0n0"}
+		results.GetComponentByID.Return.err = nil
+		req, err := http.NewRequest("GET", test.reqURI, nil)
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected while creating request", err)
+		}
+		if test.header != "" {
+			req.Header.Set(PartitionHeader, test.header)
+		}
+		if test.auth != "" {
+			req.Header.Set("Authorization", test.auth)
+		}
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+		if w.Code != test.expectedCode {
+			t.Errorf("Test %v Failed: Response code was %v; want %v", i, w.Code, test.expectedCode)
+		}
+		if test.expectedPart != results.GetPartition.Input.pname {
+			t.Errorf("Test %v Failed: Expected partition is '%v'; Received '%v'", i, test.expectedPart, results.GetPartition.Input.pname)
+		}
+		if !strings.Contains(w.Body.String(), test.expectedErr) {
+			t.Errorf("Test %v Failed: Expected error '%v'; Received '%v'", i, test.expectedErr, w.Body)
+		}
+	}
+}
+
+func TestPartScopeComponents(t *testing.T) {
+	results.GetPartition.Return.partition = partScopeTestPart
+	defer func() { results.GetPartition.Return.partition = nil }()
+
+	// Listing components only asks for the bound partition.
+	results.GetComponentsFilter.Input.compFilter = hmsds.ComponentFilter{}
+	results.GetComponentsFilter.Return.ids = []*base.Component{}
+	results.GetComponentsFilter.Return.err = nil
+	w := partScopeTestReq(t, "GET", "https://localhost/hsm/v2/State/Components?group=g1", "")
+	if w.Code != http.StatusOK {
+		t.Errorf("Test 0 Failed: Response code was %v; want 200", w.Code)
+	}
+	f := results.GetComponentsFilter.Input.compFilter
+	if !reflect.DeepEqual(f.Partition, []string{"p1"}) ||
+		!reflect.DeepEqual(f.Group, []string{"g1"}) {
+		t.Errorf("Test 0 Failed: Expected partition [p1] and group [g1]; Received %v %v", f.Partition, f.Group)
+	}
+
+	// Other partitions can't be asked for.
+	w = partScopeTestReq(t, "GET", "https://localhost/hsm/v2/State/Components?partition=p2", "")
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Test 1 Failed: Response code was %v; want 403", w.Code)
+	}
+
+	// Updates are checked against the partition.
+	filters := []hmsds.ComponentFilter{}
+	results.GetComponentIDs.Funcs.getID = func(f_opts ...hmsds.CompFiltFunc) string {
+		f := hmsds.ComponentFilter{}
+		for _, opts := range f_opts {
+			opts(&f)
+		}
+		filters = append(filters, f)
+		return strings.Join(f.ID, ",")
+	}
+	results.GetComponentIDs.Funcs.returnIDs = func(id string) ([]string, error) {
+		return []string{}, nil
+	}
+	w = partScopeTestReq(t, "PATCH", "https://localhost/hsm/v2/State/Components/x0c0s0b0n0/StateData",
+		`{"State":"Ready"}`)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Test 2 Failed: Response code was %v; want 403", w.Code)
+	}
+	if len(filters) != 1 || !reflect.DeepEqual(filters[0].Partition, []string{"p1"}) {
+		t.Errorf("Test 2 Failed: Expected partition [p1] lookup; Received %v", filters)
+	}
+
+	// Bulk updates can't name components outside it.
+	w = partScopeTestReq(t, "PATCH", "https://localhost/hsm/v2/State/Components/BulkNID",
+		`{"Components":[{"ID":"x0c0s2b0n0","NID":3}]}`)
+	if w.Code != http.StatusForbidden ||
+		!strings.Contains(w.Body.String(), "x0c0s2b0n0") {
+		t.Errorf("Test 3 Failed: Response was %v %v; want 403", w.Code, w.Body)
+	}
+	results.PatchCompLabels.Input.ids = nil
+	w = partScopeTestReq(t, "PATCH", "https://localhost/hsm/v2/State/Components/BulkLabels",
+		`{"ComponentIDs":["x0c0s2b0n0"],"Labels":{"gpu":"a100"}}`)
+	if w.Code != http.StatusForbidden ||
+		!strings.Contains(w.Body.String(), "x0c0s2b0n0") ||
+		results.PatchCompLabels.Input.ids != nil {
+		t.Errorf("Test 4 Failed: Response was %v %v; want 403", w.Code, w.Body)
+	}
+}
+
+func TestPartScopeHWInv(t *testing.T) {
+	results.GetPartition.Return.partition = partScopeTestPart
+	defer func() { results.GetPartition.Return.partition = nil }()
+
+	// Hardware outside the partition doesn't exist, but hardware under
+	// a member does.
+	results.GetHWInvByLocID.Input.id = ""
+	w := partScopeTestReq(t, "GET", "https://localhost/hsm/v2/Inventory/Hardware/x0c0s2b0n0", "")
+	if w.Code != http.StatusNotFound || results.GetHWInvByLocID.Input.id != "" {
+		t.Errorf("Test 0 Failed: Response code was %v; want 404", w.Code)
+	}
+
+	// Listing hardware only asks for the bound partition.
+	results.GetHWInvByLocFilter.Input.f = nil
+	results.GetHWInvByLocFilter.Return.hwlocs = []*sm.HWInvByLoc{}
+	results.GetHWInvByLocFilter.Return.err = nil
+	w = partScopeTestReq(t, "GET", "https://localhost/hsm/v2/Inventory/Hardware", "")
+	if w.Code != http.StatusOK {
+		t.Errorf("Test 1 Failed: Response code was %v; want 200", w.Code)
+	}
+	if f := results.GetHWInvByLocFilter.Input.f; f == nil ||
+		!reflect.DeepEqual(f.Partition, []string{"p1"}) {
+		t.Errorf("Test 1 Failed: Expected partition [p1]; Received %v", f)
+	}
+
+	w = partScopeTestReq(t, "GET", "https://localhost/hsm/v2/Inventory/Hardware?partition=p2", "")
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Test 2 Failed: Response code was %v; want 403", w.Code)
+	}
+}
+
+func TestPartScopeGroups(t *testing.T) {
+	results.GetPartition.Return.partition = partScopeTestPart
+	defer func() { results.GetPartition.Return.partition = nil }()
+
+	// Only members in the partition are shown.
+	results.GetGroup.Input.filt_part = ""
+	results.GetGroup.Return.group = &sm.Group{Label: "g1"}
+	results.GetGroup.Return.err = nil
+	w := partScopeTestReq(t, "GET", "https://localhost/hsm/v2/groups/g1/members", "")
+	if w.Code != http.StatusOK || results.GetGroup.Input.filt_part != "p1" {
+		t.Errorf("Test 0 Failed: Response was %v with partition '%v'; want 200 and 'p1'",
+			w.Code, results.GetGroup.Input.filt_part)
+	}
+
+	// Components outside the partition can't be added.
+	results.AddGroupMember.Input.id = ""
+	w = partScopeTestReq(t, "POST", "https://localhost/hsm/v2/groups/g1/members",
+		`{"id":"x0c0s2b0n0"}`)
+	if w.Code != http.StatusForbidden || results.AddGroupMember.Input.id != "" {
+		t.Errorf("Test 1 Failed: Response code was %v; want 403", w.Code)
+	}
+
+	// Groups with members outside the partition can't be deleted.
+	results.GetGroup.Return.group = &sm.Group{
+		Label:   "g1",
+		Members: sm.Members{IDs: []string{"x0c0s0b0n0", "x0c0s2b0n0"}},
+	}
+	w = partScopeTestReq(t, "DELETE", "https://localhost/hsm/v2/groups/g1", "")
+	if w.Code != http.StatusForbidden ||
+		!strings.Contains(w.Body.String(), ErrSMDPartScopeIDs.Error()+": x0c0s2b0n0") {
+		t.Errorf("Test 2 Failed: Response was %v %v; want 403", w.Code, w.Body)
+	}
+
+	// Nor are their labels listed.
+	results.GetGroupLabels.Return.labels = []string{"g1"}
+	results.GetGroupLabels.Return.err = nil
+	defer func() { results.GetGroupLabels.Return.labels = nil }()
+	w = partScopeTestReq(t, "GET", "https://localhost/hsm/v2/groups/labels", "")
+	if w.Code != http.StatusOK || strings.TrimSpace(w.Body.String()) != "[]" {
+		t.Errorf("Test 3 Failed: Response was %v %v; want 200 and []", w.Code, w.Body)
+	}
+	results.GetGroup.Return.group = &sm.Group{
+		Label:   "g1",
+		Members: sm.Members{IDs: []string{"x0c0s0b0n0", "x0c0s1b0n0p0"}},
+	}
+	w = partScopeTestReq(t, "GET", "https://localhost/hsm/v2/groups/labels", "")
+	if w.Code != http.StatusOK || strings.TrimSpace(w.Body.String()) != `["g1"]` {
+		t.Errorf("Test 4 Failed: Response was %v %v; want 200 and [\"g1\"]", w.Code, w.Body)
+	}
+	results.GetGroup.Return.group = nil
+}
+
+func TestPartScopePartitions(t *testing.T) {
+	results.GetPartition.Return.partition = partScopeTestPart
+	defer func() { results.GetPartition.Return.partition = nil }()
+
+	w := partScopeTestReq(t, "GET", "https://localhost/hsm/v2/partitions/p2", "")
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Test 0 Failed: Response code was %v; want 404", w.Code)
+	}
+
+	results.GetPartitionNames.Return.pnames = []string{"p1", "p2"}
+	results.GetPartitionNames.Return.err = nil
+	w = partScopeTestReq(t, "GET", "https://localhost/hsm/v2/partitions/names", "")
+	if w.Body.String() != `["p1"]`+"\n" {
+		t.Errorf("Test 1 Failed: Expected body is '[\"p1\"]'; Received '%v'", w.Body)
+	}
+	results.GetPartitionNames.Return.pnames = nil
+
+	// Partition-changing routes aren't allowed.
+	w = partScopeTestReq(t, "DELETE", "https://localhost/hsm/v2/partitions/p1", "")
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Test 2 Failed: Response code was %v; want 403", w.Code)
+	}
+
+	// Sub-partitions of the bound partition are visible.
+	results.GetPartitionNames.Return.pnames = []string{"p1", "p1.2", "p2", "p2.1"}
+	w = partScopeTestReq(t, "GET", "https://localhost/hsm/v2/partitions/names", "")
+	if w.Body.String() != `["p1","p1.2"]`+"\n" {
+		t.Errorf("Test 3 Failed: Expected body is '[\"p1\",\"p1.2\"]'; Received '%v'", w.Body)
+	}
+	results.GetPartitionNames.Return.pnames = nil
+}
+
+func TestPartScopeMemberships(t *testing.T) {
+	results.GetPartition.Return.partition = partScopeTestPart
+	defer func() { results.GetPartition.Return.partition = nil }()
+
+	results.GetMembership.Input.id = ""
+	w := partScopeTestReq(t, "GET", "https://localhost/hsm/v2/memberships/x0c0s2b0n0", "")
+	if w.Code != http.StatusNotFound || results.GetMembership.Input.id != "" {
+		t.Errorf("Test 0 Failed: Response code was %v; want 404", w.Code)
+	}
+}
+
+func TestPartScopeLocks(t *testing.T) {
+	results.GetPartition.Return.partition = partScopeTestPart
+	defer func() { results.GetPartition.Return.partition = nil }()
+
+	results.GetCompLocksV2.Input.f = sm.CompLockV2Filter{}
+	results.GetCompLocksV2.Return.cls = []sm.CompLockV2{}
+	results.GetCompLocksV2.Return.err = nil
+	w := partScopeTestReq(t, "POST", "https://localhost/hsm/v2/locks/status",
+		`{"ComponentIDs":["x0c0s0b0n0"]}`)
+	if w.Code != http.StatusOK {
+		t.Errorf("Test 0 Failed: Response code was %v; want 200", w.Code)
+	}
+	if f := results.GetCompLocksV2.Input.f; !reflect.DeepEqual(f.Partition, []string{"p1"}) {
+		t.Errorf("Test 0 Failed: Expected partition [p1]; Received %v", f.Partition)
+	}
+
+	w = partScopeTestReq(t, "POST", "https://localhost/hsm/v2/locks/lock",
+		`{"ComponentIDs":["x0c0s0b0n0"],"Partition":["p2"]}`)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Test 1 Failed: Response code was %v; want 403", w.Code)
+	}
+
+	w = partScopeTestReq(t, "POST", "https://localhost/hsm/v2/locks/reservations/release",
+		`{"ReservationKeys":[{"ID":"x0c0s2b0n0","Key":"x0c0s2b0n0:rk:1"}]}`)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Test 2 Failed: Response code was %v; want 403", w.Code)
+	}
+}
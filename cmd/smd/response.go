@@ -26,6 +26,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
 
 	base "github.com/Cray-HPE/hms-base/v2"
 	"github.com/Cray-HPE/hms-smd/v2/pkg/sm"
@@ -197,6 +198,72 @@ func sendJsonCompRsp(w http.ResponseWriter, comp *base.Component) {
 	}
 }
 
+// Component health response, i.e. flag plus contributors for one component.
+func sendJsonCompHealthRsp(w http.ResponseWriter, health *sm.CompHealth) {
+	http_code := 200
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http_code)
+	if health != nil {
+		err := json.NewEncoder(w).Encode(health)
+		if err != nil {
+			fmt.Printf("Couldn't encode a JSON command response: %s\n", err)
+		}
+	}
+}
+
+// Component labels response (i.e. GET on a component's labels)
+func sendJsonCompLabelsRsp(w http.ResponseWriter, labels *sm.ComponentLabels) {
+	http_code := 200
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http_code)
+	if labels != nil {
+		err := json.NewEncoder(w).Encode(labels)
+		if err != nil {
+			fmt.Printf("Couldn't encode a JSON command response: %s\n", err)
+		}
+	}
+}
+
+// Component history response (i.e. GET on a component's history)
+func sendJsonCompHistoryRsp(w http.ResponseWriter, entries []*sm.CompHistoryEntry) {
+	http_code := 200
+	if entries == nil {
+		http_code = 204
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http_code)
+	if entries != nil {
+		err := json.NewEncoder(w).Encode(entries)
+		if err != nil {
+			fmt.Printf("Couldn't encode a JSON command response: %s\n", err)
+		}
+	}
+}
+
+// Per-component results of a multi-field Components PATCH
+func sendJsonCompPatchRsp(w http.ResponseWriter, res sm.CompPatchResult) {
+	http_code := 200
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http_code)
+	err := json.NewEncoder(w).Encode(res)
+	if err != nil {
+		fmt.Printf("Couldn't encode a JSON command response: %s\n", err)
+	}
+}
+
+// Sensor snapshot response (i.e. GET on a component's sensors)
+func sendJsonCompSensorsRsp(w http.ResponseWriter, snap *sm.CompSensors) {
+	http_code := 200
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http_code)
+	if snap != nil {
+		err := json.NewEncoder(w).Encode(snap)
+		if err != nil {
+			fmt.Printf("Couldn't encode a JSON command response: %s\n", err)
+		}
+	}
+}
+
 // Named HMS Component array response (i.e. GET on HMS Components collection)
 func sendJsonCompArrayRsp(w http.ResponseWriter, comps *base.ComponentArray) {
 	http_code := 200
@@ -351,6 +418,32 @@ func sendJsonHWInvHistArrayRsp(w http.ResponseWriter, hhs *sm.HWInvHistResp) {
 	}
 }
 
+// Array of firmware/software versions from the hardware inventory.
+func sendJsonHWInvFirmwareArrayRsp(w http.ResponseWriter, fws *sm.HWInvFirmwareArray) {
+	http_code := 200
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http_code)
+	if fws != nil {
+		err := json.NewEncoder(w).Encode(fws)
+		if err != nil {
+			fmt.Printf("Couldn't encode a JSON command response: %s\n", err)
+		}
+	}
+}
+
+// Array of fans from the hardware inventory.
+func sendJsonHWInvFanArrayRsp(w http.ResponseWriter, fans *sm.HWInvFanArray) {
+	http_code := 200
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http_code)
+	if fans != nil {
+		err := json.NewEncoder(w).Encode(fans)
+		if err != nil {
+			fmt.Printf("Couldn't encode a JSON command response: %s\n", err)
+		}
+	}
+}
+
 // Individual RedfishEndpoint response, matching a single xname ID.
 func sendJsonRFEndpointRsp(w http.ResponseWriter, ep *sm.RedfishEndpoint) {
 	http_code := 200
@@ -598,6 +691,97 @@ func sendJsonSCNSubscriptionRsp(w http.ResponseWriter, sub *sm.SCNSubscription)
 	}
 }
 
+// Array of event rules
+func sendJsonEventRuleArrayRsp(w http.ResponseWriter, rules *sm.EventRuleArray) {
+	http_code := 200
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http_code)
+	err := json.NewEncoder(w).Encode(rules)
+	if err != nil {
+		fmt.Printf("Couldn't encode a JSON command response: %s\n", err)
+	}
+}
+
+// Single event rule
+func sendJsonEventRuleRsp(w http.ResponseWriter, rule *sm.EventRule) {
+	http_code := 200
+	if rule == nil {
+		http_code = 204
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http_code)
+	if rule != nil {
+		err := json.NewEncoder(w).Encode(rule)
+		if err != nil {
+			fmt.Printf("Couldn't encode a JSON command response: %s\n", err)
+		}
+	}
+}
+
+// Page of Redfish event log entries
+func sendJsonRFEventLogPageRsp(w http.ResponseWriter, page *sm.RFEventLogPage) {
+	http_code := 200
+	if page == nil {
+		http_code = 204
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http_code)
+	if page != nil {
+		err := json.NewEncoder(w).Encode(page)
+		if err != nil {
+			fmt.Printf("Couldn't encode a JSON command response: %s\n", err)
+		}
+	}
+}
+
+// Array of jobs
+func sendJsonJobArrayRsp(w http.ResponseWriter, jobs *sm.JobArray) {
+	http_code := 200
+	if jobs == nil {
+		http_code = 204
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http_code)
+	if jobs != nil {
+		err := json.NewEncoder(w).Encode(jobs)
+		if err != nil {
+			fmt.Printf("Couldn't encode a JSON command response: %s\n", err)
+		}
+	}
+}
+
+// Single job
+func sendJsonJobRsp(w http.ResponseWriter, job *sm.Job) {
+	http_code := 200
+	if job == nil {
+		http_code = 204
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http_code)
+	if job != nil {
+		err := json.NewEncoder(w).Encode(job)
+		if err != nil {
+			fmt.Printf("Couldn't encode a JSON command response: %s\n", err)
+		}
+	}
+}
+
+// Cluster of HSM instances
+func sendJsonClusterStatusRsp(w http.ResponseWriter, status *sm.ClusterStatus) {
+	http_code := 200
+	if status == nil {
+		http_code = 204
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http_code)
+	if status != nil {
+		err := json.NewEncoder(w).Encode(status)
+		if err != nil {
+			fmt.Printf("Couldn't encode a JSON command response: %s\n", err)
+		}
+	}
+}
+
 // Array of groups
 func sendJsonGroupArrayRsp(w http.ResponseWriter, groups *[]sm.Group) {
 	http_code := 200
@@ -630,6 +814,14 @@ func sendJsonGroupRsp(w http.ResponseWriter, group *sm.Group) {
 	}
 }
 
+// Set the ETag header from a group or partition version, before the
+// response is written.  Zero means no version is known, so it's left off.
+func setGroupETag(w http.ResponseWriter, version int64) {
+	if version != 0 {
+		w.Header().Set("ETag", `"`+strconv.FormatInt(version, 10)+`"`)
+	}
+}
+
 // Array of strings
 func sendJsonStringArrayRsp(w http.ResponseWriter, strs *[]string) {
 	http_code := 200
@@ -662,6 +854,22 @@ func sendJsonMembersRsp(w http.ResponseWriter, members *sm.Members) {
 	}
 }
 
+// Group or partition history entries
+func sendJsonGroupHistoryRsp(w http.ResponseWriter, entries []*sm.GroupHistoryEntry) {
+	http_code := 200
+	if entries == nil {
+		http_code = 204
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http_code)
+	if entries != nil {
+		err := json.NewEncoder(w).Encode(entries)
+		if err != nil {
+			fmt.Printf("Couldn't encode a JSON command response: %s\n", err)
+		}
+	}
+}
+
 // Array of partitions
 func sendJsonPartitionArrayRsp(w http.ResponseWriter, parts *[]sm.Partition) {
 	http_code := 200
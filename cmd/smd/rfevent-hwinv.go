@@ -0,0 +1,212 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	base "github.com/Cray-HPE/hms-base/v2"
+	rf "github.com/Cray-HPE/hms-smd/v2/pkg/redfish"
+	"github.com/Cray-HPE/hms-smd/v2/pkg/sm"
+	"github.com/Cray-HPE/hms-xname/xnametypes"
+)
+
+/////////////////////////////////////////////////////////////////////////////
+// Event-driven hardware inventory refresh.  When a BMC reports that a
+// resource was added, removed or changed, e.g. a DIMM or power supply was
+// swapped, only the component it belongs to is re-read, and the FRUs that
+// arrived or left are recorded in the hardware history.
+/////////////////////////////////////////////////////////////////////////////
+
+// Wait this long after the first event before re-reading so the BMC has
+// caught up and a burst of events for the same component is handled once.
+var hwInvRefreshDelay = 10 * time.Second
+
+// EventActionParser - ResourceAdded/ResourceRemoved/ResourceChanged and
+//
+//	vendor FRU messages.  Refresh the hardware inventory of the
+//	component the OriginOfCondition (or first URI arg) belongs to.
+//	If a component with its own ComponentEndpoint was itself removed,
+//	everything at and under its location is removed without reading it.
+//	No state change results.
+func ResourceInventoryParser(s *SmD, pe *processedRFEvent) (*CompUpdate, error) {
+	uri := pe.Origin
+	if uri == "" {
+		for _, arg := range pe.MessageArgs {
+			if strings.HasPrefix(arg, "/") == true {
+				uri = arg
+				break
+			}
+		}
+	}
+	if uri == "" {
+		return nil, ErrSmMsgNoURI
+	}
+	xname, compURI, err := s.getIDForSubURI(pe.RfEndppointID, uri)
+	if err != nil {
+		return nil, err
+	} else if xname == "" {
+		s.Log(LOG_INFO, "ResourceInventoryParser(%s, %s): Not found.",
+			pe.RfEndppointID, uri)
+		return nil, ErrSmMsgNoID
+	}
+	removed := strings.HasSuffix(strings.ToLower(pe.MessageId), "removed") &&
+		compURI == strings.TrimSuffix(strings.SplitN(uri, "#", 2)[0], "/")
+	s.hwInvRefresh(xname, removed)
+	return nil, nil
+}
+
+// Schedule a refresh of the hardware inventory at and under xname, unless
+// one is already pending.
+func (s *SmD) hwInvRefresh(xname string, removed bool) {
+	s.hwInvRefreshLock.Lock()
+	if s.hwInvRefreshMap == nil {
+		s.hwInvRefreshMap = make(map[string]bool)
+	}
+	if _, ok := s.hwInvRefreshMap[xname]; ok {
+		// Removal always wins, as there will be nothing left to read.
+		s.hwInvRefreshMap[xname] = s.hwInvRefreshMap[xname] || removed
+		s.hwInvRefreshLock.Unlock()
+		return
+	}
+	s.hwInvRefreshMap[xname] = removed
+	s.hwInvRefreshLock.Unlock()
+
+	go func() {
+		time.Sleep(hwInvRefreshDelay)
+		s.hwInvRefreshLock.Lock()
+		removed := s.hwInvRefreshMap[xname]
+		delete(s.hwInvRefreshMap, xname)
+		s.hwInvRefreshLock.Unlock()
+
+		var err error
+		if removed {
+			err = s.doRemoveCompHWInvSubtree(xname)
+		} else {
+			var cep *sm.ComponentEndpoint
+			var ep *rf.RedfishEP
+			cep, ep, err = s.getCompEPInfo(xname)
+			if err == nil {
+				err = s.doUpdateCompHWInvSubtree(cep, ep)
+			}
+		}
+		if err != nil {
+			s.Log(LOG_INFO, "hwInvRefresh(%s): %s", xname, err)
+		}
+	}()
+}
+
+// doUpdateCompHWInvSubtree - Re-read the hwinv of one component and
+//
+//	everything under it, via its stored ComponentEndpoint, and
+//	replace what was stored for it.  Only nodes and chassis-type
+//	components, i.e. Redfish Systems and Chassis, can be re-read on
+//	their own.
+func (s *SmD) doUpdateCompHWInvSubtree(cep *sm.ComponentEndpoint, ep *rf.RedfishEP) error {
+	if cep == nil || ep == nil {
+		return ErrSmMsgNoEP
+	}
+	// The ordinal depends on the other components under the endpoint so
+	// keep the one from the last full discovery.
+	ordinal := 0
+	hl, err := s.db.GetHWInvByLocID(cep.ID)
+	if err != nil {
+		return err
+	} else if hl != nil {
+		ordinal = hl.Ordinal
+	}
+	switch cep.ComponentEndpointType {
+	case sm.CompEPTypeSystem:
+		if hl == nil {
+			ordinal, _ = strconv.Atoi(strings.TrimPrefix(cep.ID,
+				cep.RfEndpointID+"n"))
+		}
+		_, err = ep.GetSystemSubtree(cep.OdataID, cep.ID, ordinal)
+	case sm.CompEPTypeChassis:
+		_, err = ep.GetChassisSubtree(cep.OdataID, cep.ID, ordinal)
+	default:
+		s.Log(LOG_INFO, "doUpdateCompHWInvSubtree(%s): Can't re-read a %s",
+			cep.ID, cep.ComponentEndpointType)
+		return nil
+	}
+	if err != nil {
+		s.Log(LOG_INFO, "doUpdateCompHWInvSubtree(%s): Failed to re-read %s: %s",
+			cep.ID, cep.OdataID, err)
+		return ErrSmMsgRFFail
+	}
+	// Only the re-read subtree is present in ep.
+	hwlocs, err := s.DiscoverHWInvByLocArray(ep)
+	if err != nil {
+		if err == base.ErrHMSTypeInvalid || err == base.ErrHMSTypeUnsupported {
+			s.Log(LOG_INFO, "DiscoverHWInvByLocArray(%s): One or more: %s",
+				cep.ID, err)
+		} else {
+			s.Log(LOG_INFO, "DiscoverHWInvByLocArray(%s): Fatal error storing: %s",
+				cep.ID, err)
+			return err
+		}
+	}
+	subtree := make([]*sm.HWInvByLoc, 0, len(hwlocs))
+	for _, hl := range hwlocs {
+		if hl != nil && isCompOrChild(hl.ID, cep.ID) {
+			subtree = append(subtree, hl)
+		}
+	}
+	hists, err := s.db.UpdateHWInvByLocSubtree(cep.ID, subtree)
+	if err != nil {
+		s.Log(LOG_INFO, "doUpdateCompHWInvSubtree(%s): Failed to update hwinv: %s",
+			cep.ID, err)
+		return err
+	}
+	for _, h := range hists {
+		s.LogAlways("Hardware inventory: %s %s at %s", h.FruId, h.EventType, h.ID)
+	}
+	return nil
+}
+
+// Remove the hwinv at and under xname after it was reported removed,
+// recording that its FRUs were removed.
+func (s *SmD) doRemoveCompHWInvSubtree(xname string) error {
+	hists, err := s.db.UpdateHWInvByLocSubtree(xname, []*sm.HWInvByLoc{})
+	if err != nil {
+		return err
+	}
+	for _, h := range hists {
+		s.LogAlways("Hardware inventory: %s %s at %s", h.FruId, h.EventType, h.ID)
+	}
+	return nil
+}
+
+// True if id is parent or one of its children, e.g. x0c0s0b0n0d1 and
+// x0c0s0b0n0 but not x0c0s0b0n01.
+func isCompOrChild(id, parent string) bool {
+	id = xnametypes.NormalizeHMSCompID(id)
+	parent = xnametypes.NormalizeHMSCompID(parent)
+	if !strings.HasPrefix(id, parent) {
+		return false
+	}
+	rest := id[len(parent):]
+	return rest == "" || (rest[0] >= 'a' && rest[0] <= 'z')
+}
@@ -0,0 +1,179 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"testing"
+	"time"
+
+	base "github.com/Cray-HPE/hms-base/v2"
+	rf "github.com/Cray-HPE/hms-smd/v2/pkg/redfish"
+	"github.com/Cray-HPE/hms-smd/v2/pkg/sm"
+)
+
+func TestDoUpdateCompHWInvSubtree(t *testing.T) {
+	// The Gigabyte mock has no drives.
+	handler := func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.RequestURI() == "/redfish/v1/Systems/SelfOn/Storage" {
+			defer base.DrainAndCloseRequestBody(req)
+			w.WriteHeader(200)
+			w.Write(json.RawMessage(`{"Members": [], "Members@odata.count": 0}`))
+			return
+		}
+		GigabyteHandler(w, req)
+	}
+	server := httptest.NewTLSServer(http.HandlerFunc(handler))
+	defer server.Close()
+	u, _ := url.Parse(server.URL)
+
+	cep := *GigabyteCompEPs[0]
+	cep.RfEndpointFQDN = u.Host
+	cep.URL = u.Host + "/redfish/v1/Systems/SelfOn"
+
+	newEP := func() *rf.RedfishEP {
+		ep, err := rf.NewRedfishEp(&rf.RedfishEPDescription{
+			ID:   cep.RfEndpointID,
+			Type: "NodeBMC",
+			FQDN: cep.RfEndpointFQDN,
+		})
+		if err != nil {
+			t.Fatalf("NewRedfishEp failed: %s", err)
+		}
+		return ep
+	}
+
+	// Re-read the node, keeping the ordinal from the last discovery.
+	results.GetHWInvByLocID.Return.entry = &sm.HWInvByLoc{
+		ID:      cep.ID,
+		Type:    "Node",
+		Ordinal: 0,
+	}
+	results.GetHWInvByLocID.Return.err = nil
+	results.UpdateHWInvByLocSubtree.Input.id = ""
+	results.UpdateHWInvByLocSubtree.Input.hls = nil
+	results.UpdateHWInvByLocSubtree.Return.hists = []*sm.HWInvHist{}
+	results.UpdateHWInvByLocSubtree.Return.err = nil
+	if err := s.doUpdateCompHWInvSubtree(&cep, newEP()); err != nil {
+		t.Fatalf("Test 0 FAIL: Unexpected error: %s", err)
+	}
+	if results.UpdateHWInvByLocSubtree.Input.id != cep.ID {
+		t.Errorf("Test 0 FAIL: Expected id %s; Received %s",
+			cep.ID, results.UpdateHWInvByLocSubtree.Input.id)
+	}
+	ids := []string{}
+	for _, hl := range results.UpdateHWInvByLocSubtree.Input.hls {
+		ids = append(ids, hl.ID)
+		if !isCompOrChild(hl.ID, cep.ID) {
+			t.Errorf("Test 0 FAIL: %s is not under %s", hl.ID, cep.ID)
+		}
+	}
+	sort.Strings(ids)
+	expected := []string{"x0c0s11b0n0", "x0c0s11b0n0d0", "x0c0s11b0n0p0"}
+	if len(ids) != len(expected) {
+		t.Fatalf("Test 0 FAIL: Expected locations %v; Received %v", expected, ids)
+	}
+	for i := range ids {
+		if ids[i] != expected[i] {
+			t.Errorf("Test 0 FAIL: Expected locations %v; Received %v",
+				expected, ids)
+			break
+		}
+	}
+
+	// The node is unreachable.  Nothing may be removed.
+	badCEP := cep
+	badCEP.OdataID = "/redfish/v1/Systems/Missing"
+	results.UpdateHWInvByLocSubtree.Input.id = ""
+	if err := s.doUpdateCompHWInvSubtree(&badCEP, newEP()); err != ErrSmMsgRFFail {
+		t.Errorf("Test 1 FAIL: Expected error %s; Received %v",
+			ErrSmMsgRFFail, err)
+	}
+	if results.UpdateHWInvByLocSubtree.Input.id != "" {
+		t.Errorf("Test 1 FAIL: Updated hwinv for %s after a failed read",
+			results.UpdateHWInvByLocSubtree.Input.id)
+	}
+}
+
+func TestHWInvRefreshRemoved(t *testing.T) {
+	delay := hwInvRefreshDelay
+	hwInvRefreshDelay = 100 * time.Millisecond
+	defer func() { hwInvRefreshDelay = delay }()
+
+	results.UpdateHWInvByLocSubtree.Input.id = ""
+	results.UpdateHWInvByLocSubtree.Input.hls = nil
+	results.UpdateHWInvByLocSubtree.Return.hists = []*sm.HWInvHist{
+		{ID: "x0c0s11b0n0d1", FruId: "FRU1", EventType: sm.HWInvHistEventTypeRemoved},
+	}
+	results.UpdateHWInvByLocSubtree.Return.err = nil
+
+	// Events in the same burst are coalesced.
+	s.hwInvRefresh("x0c0s11b0n0d1", true)
+	s.hwInvRefresh("x0c0s11b0n0d1", true)
+	s.hwInvRefreshLock.Lock()
+	pending := len(s.hwInvRefreshMap)
+	s.hwInvRefreshLock.Unlock()
+	if pending != 1 {
+		t.Errorf("FAIL: Expected 1 pending refresh; Found %d", pending)
+	}
+	time.Sleep(500 * time.Millisecond)
+
+	s.hwInvRefreshLock.Lock()
+	pending = len(s.hwInvRefreshMap)
+	s.hwInvRefreshLock.Unlock()
+	if pending != 0 {
+		t.Errorf("FAIL: Expected no pending refresh; Found %d", pending)
+	}
+	if results.UpdateHWInvByLocSubtree.Input.id != "x0c0s11b0n0d1" {
+		t.Errorf("FAIL: Expected id x0c0s11b0n0d1; Received '%s'",
+			results.UpdateHWInvByLocSubtree.Input.id)
+	}
+	if len(results.UpdateHWInvByLocSubtree.Input.hls) != 0 {
+		t.Errorf("FAIL: Expected no locations; Received %d",
+			len(results.UpdateHWInvByLocSubtree.Input.hls))
+	}
+}
+
+func TestIsCompOrChild(t *testing.T) {
+	tests := []struct {
+		id       string
+		parent   string
+		expected bool
+	}{
+		{"x0c0s0b0n0", "x0c0s0b0n0", true},
+		{"x0c0s0b0n0d1", "x0c0s0b0n0", true},
+		{"x0c0s0b0n01", "x0c0s0b0n0", false},
+		{"x0c0s0b0n1p0", "x0c0s0b0n0", false},
+		{"X0C0S0B0N0P0", "x0c0s0b0n0", true},
+	}
+	for i, test := range tests {
+		if out := isCompOrChild(test.id, test.parent); out != test.expected {
+			t.Errorf("Test %d FAIL: isCompOrChild(%s, %s) = %v",
+				i, test.id, test.parent, out)
+		}
+	}
+}
@@ -26,28 +26,33 @@ import (
 	"time"
 )
 
-// This is the main thread that monitors the event bus
+// This is the main thread that monitors the event bus, or whichever event
+// source -msg-host gives.
 func (s *SmD) StartRFEventMonitor() {
 	if s.msgbusListen != "" {
-		if err := s.MsgBusConfig(s.msgbusListen); err != nil {
+		if src, err := s.NewEventSource(s.msgbusListen); err != nil {
 			s.LogAlways("WARNING: Cannot parse message bus host: %s", err)
 		} else {
 			// Loop forever trying to connect or reconnect if an error
 			// occurs.
 			for {
-				if err := s.MsgBusReconnect(); err != nil {
-					s.LogAlways("ERROR: Cannot connect to  message bus host: %s",
-						err)
-					s.LogAlways("Retrying msg bus connection in 5 seconds")
+				if err := src.Connect(); err != nil {
+					s.LogAlways("ERROR: Cannot connect to event source %s: %s",
+						src, err)
+					s.LogAlways("Retrying event source connection in 5 seconds")
 					time.Sleep(5 * time.Second)
 					continue
 				}
-				s.LogAlways("Connected to message bus: %s:%d:%s",
-					s.msgbusConfig.Host, s.msgbusConfig.Port, s.msgbusConfig.Topic)
+				s.LogAlways("Connected to event source: %s", src)
 
-				// Consume events from topic on message bus.  If an
-				// error occurs, report it and reconnect.
-				err := s.rfEventMonitor()
+				// Consume events from the source.  If an error occurs,
+				// report it and reconnect.
+				err := s.rfEventMonitor(src)
+				if err == ErrEventSourceDone {
+					s.LogAlways("Event source %s has no more events", src)
+					src.Disconnect()
+					break
+				}
 				s.LogAlways("ERROR: Event monitor returned '%s', reconnecting",
 					err)
 			}
@@ -60,20 +65,16 @@ func (s *SmD) StartRFEventMonitor() {
 }
 
 // Dequeue a single event and hand it off for processing.
-func (s *SmD) rfEventMonitor() error {
+func (s *SmD) rfEventMonitor(src EventSource) error {
 	// Read the event
 	s.LogAlways("Starting event monitor...")
-	var err error
 	for {
 		// Read the event
-		payload, err := s.MsgBusReadNext()
+		payload, err := src.ReadNext()
 		if err != nil {
 			s.Log(LOG_INFO, "Got error reading event: %s", err)
 			return err
 		}
-		rfEventJob := NewJobRFEvent(payload, s)
-		s.wpRFEvent.Queue(rfEventJob)
+		s.queueRFEvent(payload)
 	}
-	// Shouldn't happen
-	return err
 }
@@ -0,0 +1,204 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"encoding/json"
+	"hash/fnv"
+	"strings"
+	"sync"
+	"time"
+
+	base "github.com/Cray-HPE/hms-base/v2"
+	"github.com/Cray-HPE/hms-xname/xnametypes"
+)
+
+/////////////////////////////////////////////////////////////////////////////
+// Redfish event ordering and de-duplication.
+//
+// Events are handed to one of a fixed set of single-worker pools chosen by
+// the xname of the controller that sent them, so all events from one BMC,
+// and hence for each component under it, are processed in the order they
+// were read.  Events that were delivered more than once are dropped if
+// seen again within the dedup window, and events older than the last one
+// applied to a component (e.g. re-sent after a reconnect) are rejected.
+/////////////////////////////////////////////////////////////////////////////
+
+const (
+	rfEventWorkersDefault     = 1000 // One worker each, keyed by controller
+	rfEventWorkerQueueDefault = 1000 // Events queued per worker
+	rfEventDedupWindowDefault = 300  // Seconds, 0 disables
+)
+
+// Create the keyed worker pools events are queued onto.
+func newRFEventWorkerPools(n, queue int) []*base.WorkerPool {
+	wps := make([]*base.WorkerPool, n)
+	for i := range wps {
+		wps[i] = base.NewWorkerPool(1, queue)
+		wps[i].Run()
+	}
+	return wps
+}
+
+// Queue a raw event on the worker for the controller that sent it.
+// Returns false if that worker's queue is full and the event was dropped,
+// so senders that can retry may be told to.
+func (s *SmD) queueRFEvent(payload string) bool {
+	key := rfEventKey(payload)
+	wp := s.wpRFEvent[rfEventShard(key, len(s.wpRFEvent))]
+	if wp.Queue(NewJobRFEvent(payload, s)) != 0 {
+		s.LogAlways("WARNING: Event queue for '%s' full, dropped event: %s",
+			key, payload)
+		return false
+	}
+	return true
+}
+
+// Get the xname of the controller that sent a raw event without fully
+// decoding it.  Empty if there is none, in which case the event is
+// ignored later anyway.
+func rfEventKey(payload string) string {
+	var e struct {
+		Context string `json:"Context"`
+		Events  []struct {
+			Context string `json:"Context"`
+		} `json:"Events"`
+	}
+	// Errors are reported once the event is decoded for processing.
+	json.Unmarshal([]byte(payload), &e)
+	rCtx := ""
+	if len(e.Events) > 0 {
+		rCtx = e.Events[0].Context
+	}
+	id, _ := GetEventIDAndLabels(e.Context, rCtx)
+	return id
+}
+
+// Pick the worker for key.
+func rfEventShard(key string, n int) int {
+	if n <= 1 {
+		return 0
+	}
+	h := fnv.New32a()
+	h.Write([]byte(strings.ToLower(key)))
+	return int(h.Sum32() % uint32(n))
+}
+
+// Tracks recently seen events and the timestamp of the last event applied
+// to each component.  A nil tracker filters nothing.
+type rfEventTracker struct {
+	dedupWindow time.Duration
+	seen        map[string]time.Time // Controller/EventId/MessageId -> received
+	lastPrune   time.Time
+	lastApplied map[string]time.Time // xname/update type -> EventTimestamp
+	lock        sync.Mutex
+}
+
+func newRFEventTracker(dedupWindow time.Duration) *rfEventTracker {
+	t := new(rfEventTracker)
+	t.dedupWindow = dedupWindow
+	t.seen = make(map[string]time.Time)
+	t.lastPrune = time.Now()
+	t.lastApplied = make(map[string]time.Time)
+	return t
+}
+
+// True if an event with the same EventId and MessageId from the same
+// controller was already seen within the dedup window.  Events without an
+// EventId are never duplicates.
+func (t *rfEventTracker) isDuplicate(pe *processedRFEvent) bool {
+	if t == nil || t.dedupWindow <= 0 || pe.EventId == "" {
+		return false
+	}
+	key := strings.ToLower(pe.RfEndppointID) + "/" + pe.EventId + "/" +
+		pe.MessageId
+	now := time.Now()
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if now.Sub(t.lastPrune) > t.dedupWindow {
+		for k, seen := range t.seen {
+			if now.Sub(seen) > t.dedupWindow {
+				delete(t.seen, k)
+			}
+		}
+		t.lastPrune = now
+	}
+	if seen, ok := t.seen[key]; ok && now.Sub(seen) <= t.dedupWindow {
+		return true
+	}
+	t.seen[key] = now
+	return false
+}
+
+// Drop the components from update that already had a newer event of the
+// same kind applied.  Returns nil if none are left.  Events without a
+// usable EventTimestamp are always applied.
+func (t *rfEventTracker) filterStale(pe *processedRFEvent, update *CompUpdate) *CompUpdate {
+	if t == nil || update == nil {
+		return update
+	}
+	ts, err := time.Parse(time.RFC3339, pe.EventTimestamp)
+	if err != nil {
+		return update
+	}
+	ids := make([]string, 0, len(update.ComponentIDs))
+	t.lock.Lock()
+	for _, id := range update.ComponentIDs {
+		last, ok := t.lastApplied[rfEventAppliedKey(id, update.UpdateType)]
+		if ok && ts.Before(last) {
+			continue
+		}
+		ids = append(ids, id)
+	}
+	t.lock.Unlock()
+	if len(ids) == 0 {
+		return nil
+	}
+	update.ComponentIDs = ids
+	return update
+}
+
+// Record that the event was applied to the components in update.
+func (t *rfEventTracker) applied(pe *processedRFEvent, update *CompUpdate) {
+	if t == nil || update == nil {
+		return
+	}
+	ts, err := time.Parse(time.RFC3339, pe.EventTimestamp)
+	if err != nil {
+		return
+	}
+	t.lock.Lock()
+	for _, id := range update.ComponentIDs {
+		key := rfEventAppliedKey(id, update.UpdateType)
+		if last, ok := t.lastApplied[key]; !ok || ts.After(last) {
+			t.lastApplied[key] = ts
+		}
+	}
+	t.lock.Unlock()
+}
+
+// Power and health changes for a component are ordered separately.
+func rfEventAppliedKey(id, updateType string) string {
+	return xnametypes.NormalizeHMSCompID(id) + "/" + updateType
+}
@@ -0,0 +1,252 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	base "github.com/Cray-HPE/hms-base/v2"
+	st "github.com/Cray-HPE/hms-smd/v2/pkg/sharedtest"
+)
+
+// Give a generated power event its own EventId and EventTimestamp.
+func genTimedEvent(template, eventId, ts string, opts ...st.EventTemplateArg) string {
+	e := st.GenEvent(template, opts...)
+	old := e[strings.Index(e, `"EventId"`):]
+	old = old[:strings.Index(old, ",")+1]
+	return strings.Replace(e, old,
+		fmt.Sprintf(`"EventId": "%s", "EventTimestamp": "%s",`, eventId, ts), 1)
+}
+
+func TestDoHandleRFEventOrdering(t *testing.T) {
+	tracker := s.rfEventTracker
+	s.rfEventTracker = newRFEventTracker(time.Minute)
+	defer func() { s.rfEventTracker = tracker }()
+
+	results.GetCompEndpointsAll.Return.entries = st.SampleCompEndpoints
+	results.GetCompEndpointsAll.Return.err = nil
+	results.GetCompEndpointIDs.Funcs.getID = GetCompEpIDsGenGetID
+	results.GetCompEndpointIDs.Funcs.returnIDs = GetCompEpIDsGenReturnIDs(st.SampleCompEndpoints)
+
+	// Power events for x1c4 and x1c4r0, both under x1c4b0, interleaved,
+	// late and redelivered, as replayed from the message bus.
+	tests := []struct {
+		event         string
+		expectedIds   []string
+		expectedState string
+	}{{
+		genTimedEvent(st.EventCrayOnOKChassis, "1",
+			"2026-10-19T10:00:01+00:00", st.EpID("x1c4b0")),
+		[]string{"x1c4"},
+		"On",
+	}, {
+		// Redelivered.
+		genTimedEvent(st.EventCrayOnOKChassis, "1",
+			"2026-10-19T10:00:01+00:00", st.EpID("x1c4b0")),
+		[]string{},
+		"",
+	}, {
+		genTimedEvent(st.EventCrayOffOKChassis, "3",
+			"2026-10-19T10:00:03+00:00", st.EpID("x1c4b0")),
+		[]string{"x1c4"},
+		"Off",
+	}, {
+		// Sent before the Off above but arrived after it.
+		genTimedEvent(st.EventCrayOnOKChassis, "2",
+			"2026-10-19T10:00:02+00:00", st.EpID("x1c4b0")),
+		[]string{},
+		"",
+	}, {
+		// Another component under the same controller is ordered on its
+		// own, so this older event is not stale.
+		genTimedEvent(st.EventCrayOnOKSlotX, "6", "2026-10-19T09:59:00+00:00",
+			st.EpID("x1c4b0"), st.RfId("Perif0")),
+		[]string{"x1c4r0"},
+		"On",
+	}, {
+		// Newer again.
+		genTimedEvent(st.EventCrayOnOKChassis, "4",
+			"2026-10-19T10:00:04+00:00", st.EpID("x1c4b0")),
+		[]string{"x1c4"},
+		"On",
+	}, {
+		// No timestamp, always applied.
+		genTimedEvent(st.EventCrayOffOKChassis, "5", "", st.EpID("x1c4b0")),
+		[]string{"x1c4"},
+		"Off",
+	}}
+	for i, test := range tests {
+		results.UpdateCompStates.Input.ids = []string{}
+		results.UpdateCompStates.Input.state = ""
+		results.UpdateCompStates.Return.affectedIds = test.expectedIds
+		results.UpdateCompStates.Return.err = nil
+
+		if err := s.doHandleRFEvent(test.event); err != nil {
+			t.Errorf("Test %d FAIL: Unexpected error: %s", i, err)
+		}
+		if !compareIDs(test.expectedIds, results.UpdateCompStates.Input.ids) {
+			t.Errorf("Test %d FAIL: Expected ids '%s'; Received ids '%s'",
+				i, test.expectedIds, results.UpdateCompStates.Input.ids)
+		}
+		if test.expectedState != results.UpdateCompStates.Input.state {
+			t.Errorf("Test %d FAIL: Expected state '%s'; Received state '%s'",
+				i, test.expectedState, results.UpdateCompStates.Input.state)
+		}
+	}
+}
+
+func TestRFEventTrackerDedupWindow(t *testing.T) {
+	pe := &processedRFEvent{
+		RfEndppointID: "x0c0s0b0",
+		EventId:       "1",
+		MessageId:     "ResourcePowerStateChanged",
+	}
+	other := *pe
+	other.MessageId = "ResourceStatusChangedOK"
+
+	tracker := newRFEventTracker(50 * time.Millisecond)
+	if tracker.isDuplicate(pe) {
+		t.Errorf("FAIL: First event reported as a duplicate")
+	}
+	if !tracker.isDuplicate(pe) {
+		t.Errorf("FAIL: Repeated event not reported as a duplicate")
+	}
+	if tracker.isDuplicate(&other) {
+		t.Errorf("FAIL: Different MessageId reported as a duplicate")
+	}
+	time.Sleep(100 * time.Millisecond)
+	if tracker.isDuplicate(pe) {
+		t.Errorf("FAIL: Event reported as a duplicate after the window")
+	}
+
+	// Disabled
+	tracker = newRFEventTracker(0)
+	tracker.isDuplicate(pe)
+	if tracker.isDuplicate(pe) {
+		t.Errorf("FAIL: Duplicate detected with dedup disabled")
+	}
+	// No EventId
+	tracker = newRFEventTracker(time.Minute)
+	noId := *pe
+	noId.EventId = ""
+	tracker.isDuplicate(&noId)
+	if tracker.isDuplicate(&noId) {
+		t.Errorf("FAIL: Event without EventId reported as a duplicate")
+	}
+}
+
+func TestRFEventTrackerStaleByType(t *testing.T) {
+	tracker := newRFEventTracker(time.Minute)
+	newer := &processedRFEvent{EventTimestamp: "2026-10-19T10:00:02Z"}
+	older := &processedRFEvent{EventTimestamp: "2026-10-19T10:00:01Z"}
+
+	tracker.applied(newer, &CompUpdate{
+		ComponentIDs: []string{"x0c0s0b0n0"},
+		UpdateType:   StateDataUpdate.String(),
+	})
+	// An older power change for the node is stale, but one for another
+	// node is not.
+	update := tracker.filterStale(older, &CompUpdate{
+		ComponentIDs: []string{"x0c0s0b0n0", "x0c0s0b0n1"},
+		UpdateType:   StateDataUpdate.String(),
+	})
+	if update == nil || len(update.ComponentIDs) != 1 ||
+		update.ComponentIDs[0] != "x0c0s0b0n1" {
+		t.Errorf("FAIL: Expected only x0c0s0b0n1; Received %v", update)
+	}
+	// Health changes are ordered separately from power changes.
+	update = tracker.filterStale(older, &CompUpdate{
+		ComponentIDs: []string{"x0c0s0b0n0"},
+		UpdateType:   FlagOnlyUpdate.String(),
+	})
+	if update == nil {
+		t.Errorf("FAIL: Flag update rejected as stale after a state update")
+	}
+	var nilTracker *rfEventTracker
+	if nilTracker.isDuplicate(older) || nilTracker.filterStale(older, nil) != nil {
+		t.Errorf("FAIL: nil tracker filtered an event")
+	}
+}
+
+func TestRFEventShard(t *testing.T) {
+	// Events from a controller all go to the same worker, whatever the
+	// rest of the Context says.
+	keys := []string{
+		rfEventKey(st.GenEvent(st.EventCrayOnOKChassis, st.EpID("x1c4b0:telemetry"))),
+		rfEventKey(st.GenEvent(st.EventCrayOffOKChassis, st.EpID("wrongorder:x1c4b0:context"))),
+		rfEventKey(st.GenEvent(st.EventCrayOnOKChassis, st.EpID(":X1C4B0:"))),
+	}
+	for i, key := range keys {
+		if !strings.EqualFold(key, "x1c4b0") {
+			t.Errorf("Test %d FAIL: Expected key x1c4b0; Received '%s'", i, key)
+		}
+		if rfEventShard(key, rfEventWorkersDefault) != rfEventShard("x1c4b0", rfEventWorkersDefault) {
+			t.Errorf("Test %d FAIL: '%s' assigned a different worker", i, key)
+		}
+	}
+	if key := rfEventKey("not json"); key != "" {
+		t.Errorf("FAIL: Expected no key for a bad event; Received '%s'", key)
+	}
+	if rfEventShard("x1c4b0", 1) != 0 {
+		t.Errorf("FAIL: Expected worker 0 with a single worker")
+	}
+}
+
+func TestQueueRFEventShardFull(t *testing.T) {
+	wps := s.wpRFEvent
+	// Not run, so queued events stay put, one per worker.
+	s.wpRFEvent = []*base.WorkerPool{
+		base.NewWorkerPool(1, 1),
+		base.NewWorkerPool(1, 1),
+	}
+	defer func() { s.wpRFEvent = wps }()
+
+	full := rfEventShard("x1c4b0", len(s.wpRFEvent))
+	other := ""
+	for _, ep := range []string{"x0c0s0b0", "x0c0s1b0", "x0c0s2b0", "x0c0s3b0"} {
+		if rfEventShard(ep, len(s.wpRFEvent)) != full {
+			other = ep
+			break
+		}
+	}
+	if other == "" {
+		t.Fatalf("FAIL: No controller found for the other worker")
+	}
+	if !s.queueRFEvent(st.GenEvent(st.EventCrayOnOKChassis, st.EpID("x1c4b0"))) {
+		t.Errorf("FAIL: First event for x1c4b0 not queued")
+	}
+	// The worker for x1c4b0 is full, so its next event is dropped.
+	if s.queueRFEvent(st.GenEvent(st.EventCrayOffOKChassis, st.EpID("x1c4b0"))) {
+		t.Errorf("FAIL: Event queued on a full worker")
+	}
+	// Other controllers are not held up by it.
+	if !s.queueRFEvent(st.GenEvent(st.EventCrayOnOKChassis, st.EpID(other))) {
+		t.Errorf("FAIL: Event for %s not queued", other)
+	}
+	if n := len(s.wpRFEvent[full].JobQueue); n != 1 {
+		t.Errorf("FAIL: Expected 1 event queued for x1c4b0; Found %d", n)
+	}
+}
@@ -58,18 +58,21 @@ var ErrSmMsgIgnState = em.NewChild("ignored state change type")
 var ErrSmMsgBadCached = em.NewChild("bad decode of cached type from interface")
 var ErrSmMsgNoPowerState = em.NewChild("missing power state value")
 var ErrSmMsgMissedSync = em.NewChild("unexpectedly missing after sync")
+var ErrSmMsgRFEventSender = em.NewChild("event not sent by the controller in its context")
 var ErrSmMsgFiltered = em.NewChild("message(s) filtered due to wrong type")
 
 type processedRFEvent struct {
-	MessageId     string
-	SubLabels     []string
-	Registry      string
-	RegVersion    string
-	RfEndppointID string
-	Origin        string
-	Severity      string
-	Message       string
-	MessageArgs   []string
+	MessageId      string
+	SubLabels      []string
+	Registry       string
+	RegVersion     string
+	RfEndppointID  string
+	Origin         string
+	Severity       string
+	Message        string
+	MessageArgs    []string
+	EventId        string
+	EventTimestamp string
 }
 
 // Take a string-encoded Redfish Event from the message bus and take
@@ -96,8 +99,20 @@ func (s *SmD) doHandleRFEvent(eventRaw string) error {
 		// just ignore (and possibly log) them.
 		return err
 	}
+	// Drop events that were delivered more than once.
+	newPes := make([]*processedRFEvent, 0, len(pes))
+	for _, pe := range pes {
+		if s.rfEventTracker.isDuplicate(pe) {
+			s.Log(LOG_DEBUG, "Ignoring duplicate event %s/%s from %s",
+				pe.EventId, pe.MessageId, pe.RfEndppointID)
+			continue
+		}
+		newPes = append(newPes, pe)
+	}
+	pes = newPes
 	if len(pes) > 0 {
 		s.Log(LOG_DEBUG, "Received event: '%s'", eventRaw)
+		s.logRFEvents(pes)
 	}
 	for _, pe := range pes {
 		update, err := s.compUpdateFromRFEvent(pe)
@@ -107,12 +122,21 @@ func (s *SmD) doHandleRFEvent(eventRaw string) error {
 		} else if update == nil {
 			continue
 		}
+		// Don't let an event that arrived late undo a newer one.
+		if update = s.rfEventTracker.filterStale(pe, update); update == nil {
+			s.Log(LOG_INFO, "Ignoring stale event %s/%s from %s at %s",
+				pe.EventId, pe.MessageId, pe.RfEndppointID, pe.EventTimestamp)
+			continue
+		}
+		update.Source = compHistSourceRFEvent + ":" + pe.RfEndppointID
 		s.Log(LOG_INFO, "CHANGING STATE: %s->%s: calling doCompUpdate(%s) CompUpdateType=%s",
 			pe.RfEndppointID, pe.MessageId, update.ComponentIDs, update.UpdateType)
 		err = s.doCompUpdate(update, "handleRFEvent")
 		if err != nil {
 			s.LogAlways("ERROR: %s->%s: calling doCompUpdate(%s): %s",
 				pe.RfEndppointID, pe.MessageId, update.ComponentIDs, err)
+		} else {
+			s.rfEventTracker.applied(pe, update)
 		}
 	}
 	return nil
@@ -185,10 +209,12 @@ func (s *SmD) processRFEventRecord(
 	pe.RegVersion = ver
 	pe.MessageId = msgid
 
-	pe.Message = erec.MessageId
+	pe.Message = erec.Message
 	pe.MessageArgs = erec.MessageArgs
 	pe.Origin = erec.OriginOfCondition.Oid
 	pe.Severity = erec.Severity
+	pe.EventId = erec.EventId
+	pe.EventTimestamp = erec.EventTimestamp
 
 	return pe, nil
 }
@@ -259,101 +285,34 @@ func GetEventIDAndLabels(eCtx, rCtx string) (id string, labels []string) {
 /////////////////////////////////////////////////////////////////////////////
 
 // Turn a processed RF event into a state change request that can be
-// submitted
+// submitted, using the first event rule that matches it.
 func (s *SmD) compUpdateFromRFEvent(pe *processedRFEvent) (*CompUpdate, error) {
 	if pe == nil {
 		return nil, ErrSmMsgNilProcRFE
 	}
-	action := s.GetEventActionParser(pe)
-	if action == nil {
+	r := s.eventRules.Match(pe)
+	if r == nil || r.Action.Ignore {
 		return nil, nil
 	}
-	return action(s, pe)
+	if r.Action.Parser != "" {
+		return eventRuleParsers[r.Action.Parser](s, pe)
+	}
+	return s.eventRuleUpdate(r, pe)
 }
 
 /////////////////////////////////////////////////////////////////////////////
-// EventActionParser - Lookup table and parsers
+// EventActionParsers
 //
-// This is where changes in logic due to new implementations and events go.
-// we just
+// Most events are handled by the declarative rules in eventrules.go.
+// These are for the ones that need more logic and are named by the
+// Parser of a rule.
 /////////////////////////////////////////////////////////////////////////////
 
-// Handler prototype for the lookup function below
+// Handler prototype for event rule parsers
 type EventActionParser func(*SmD, *processedRFEvent) (*CompUpdate, error)
 
-// Lookups go from most general to most specific.  If a more general
-// lookup is ambiguous,  that is the signal to keep trying with a
-// more specific key.  Unfortunately, we can't compare functions, except with
-// nil, we can't use special function names to describe the next step.  So
-// calls that need disambiguation need to return nil for each ambiguous level.
-//
-// Level 0: Just MessageId, no version or Registry.
-// Level 1: if just messageId nil, try again with MessageId:Registry
-// Level 2: If messageId + reg also nil, try again with MessageId:Registry:vers
-//
-//	where vers is :1 for 1.0 or 1.0.1
-//
-// Level 3: Version is still not specific enough.  They again with
-//
-//	MessageId:Registry:vers, but this time include maj version, i.e. 1.1
-var eventActionParserLookup = map[string]EventActionParser{
-	"resourcepowerstatechanged":               nil,
-	"resourcepowerstatechanged:resourceevent": ResourcePowerStateChangedParser,
-	"resourcepowerstatechanged:crayalerts":    ResourcePowerStateChangedParser,
-	"resourcepowerstatechanged:":              ResourcePowerStateChangedParser,
-	"systempoweron":                           AlertSystemPowerOnParser,
-	"systempoweroff":                          AlertSystemPowerOffParser,
-	"alert":                                   AlertSystemPowerParser,
-	"powerstatuschange":                       AlertSystemPowerParser,
-	"serverpoweredon":                         AlertSystemPowerOnParser,
-	"serverpoweredoff":                        AlertSystemPowerOffParser,
-	"dcpoweron":                               FoxconnAlertSystemPowerOnParser,
-	"dcpoweroff":                              FoxconnAlertSystemPowerOffParser,
-}
-
-// Gets the EventActionParser function for the processed event or returns
-// noActionFound
-func (s *SmD) GetEventActionParser(pe *processedRFEvent) EventActionParser {
-	lookup := pe.MessageId
-
-	// We may need to repeat the lookup a small number of times with
-	// additional info to disambiguate.  This keeps the table much
-	// more compact.
-	for level := 1; level <= 3; level++ {
-		action, ok := eventActionParserLookup[strings.ToLower(lookup)]
-		if ok != true {
-			// No match at all, not even a nil pointer to keep trying.
-			// No action for event.
-			return nil
-		}
-		// Determine if another lookup is needed and include the requested key.
-		if action == nil {
-			switch level {
-			case 1:
-				lookup = pe.MessageId + ":" + pe.Registry
-			case 2:
-				vers, _ := rf.VersionFields(pe.RegVersion, ".", 1)
-				lookup = pe.MessageId + ":" + pe.Registry + ":" + vers
-			case 3:
-				vers, incl := rf.VersionFields(pe.RegVersion, ".", 2)
-				if incl != 2 {
-					// Don't have this, leave it empty
-					vers = ""
-				}
-				lookup = pe.MessageId + ":" + pe.Registry + ":" + vers
-			}
-		} else {
-			// Match - return parser
-			return action
-		}
-	}
-	// Shouldn't get here, to many levels of nil function entries.  But
-	// assume that means we didn't find it.
-	return nil
-}
-
 /////////////////////////////////////////////////////////////////////////////
-// ResourceEvents - Right now just for Cray hardware.
+// ResourceEvents - Slot child expansion, right now just for Cray hardware.
 /////////////////////////////////////////////////////////////////////////////
 
 type ResourceOp string
@@ -365,166 +324,6 @@ const (
 	ResourceOther   ResourceOp = "other"
 )
 
-// EventActionParser - ResourcePowerStateChanged - Cray addition to standard
-//
-//	ResourceEvent registry.  Need to look at payload to see
-//	new state and component type.
-func ResourcePowerStateChangedParser(s *SmD, pe *processedRFEvent) (*CompUpdate, error) {
-	// Parse the arguments.  Arg1 should be the URI for the component,
-	// and Arg2 the state.  But take them in either order.  And if there
-	// is no URI, use the origin.
-	uri := pe.Origin
-	op := ResourceUnknown
-	for _, arg := range pe.MessageArgs {
-		if strings.HasPrefix(arg, "/") == true {
-			uri = arg
-		} else {
-			switch strings.ToLower(strings.TrimSpace(arg)) {
-			case string(ResourceOn):
-				op = ResourceOn
-			case string(ResourceOff):
-				op = ResourceOff
-			}
-		}
-	}
-	// If the state is not off or on, ignore it.  PoweringOn/Off is
-	// not used here.  In HSM, it means something is actively trying to
-	// turn it on.  But at the Redfish level, we don't care until it is
-	// actually on or off.
-	if op == ResourceUnknown {
-		return nil, ErrSmMsgIgnState
-	} else if uri == "" {
-		return nil, ErrSmMsgNoURI
-	}
-	xname, err := s.getIDForURI(pe.RfEndppointID, uri)
-	if err != nil {
-		return nil, err
-	} else if xname == "" {
-		s.Log(LOG_INFO, "ResourcePowerStateChangedParser(%s, %s): Not found.",
-			pe.RfEndppointID, uri)
-		return nil, ErrSmMsgNoID
-	}
-	// We already know the target, but we might need to affect other
-	// components if it is a power off operation, depending on the
-	// type of component affected, e.g. slot, etc.
-	switch xnametypes.GetHMSType(pe.RfEndppointID) {
-	case xnametypes.ChassisBMC:
-		return powerStateCMM(s, pe, xname, op)
-	case xnametypes.NodeBMC:
-		return powerStateNC(s, pe, xname, op)
-	case xnametypes.RouterBMC:
-		return powerStateRC(s, pe, xname, op)
-	case xnametypes.CabinetPDUController:
-		return powerStateCabPDUController(s, pe, xname, op)
-	default:
-		return nil, ErrSmMsgBadID
-	}
-}
-
-// Helper for ResourcePowerStateChangedParser - CMM changes
-func powerStateCMM(s *SmD, pe *processedRFEvent,
-	xname string,
-	op ResourceOp,
-) (*CompUpdate, error) {
-	u := new(CompUpdate)
-	u.ComponentIDs = append(u.ComponentIDs, xname)
-	u.UpdateType = StateDataUpdate.String()
-	switch op {
-	case ResourceOn:
-		u.State = base.StateOn.String()
-	case ResourceOff:
-		u.State = base.StateOff.String()
-	default:
-		// Should never happen.
-		return u, ErrSmMsgNoPowerState
-	}
-	switch xnametypes.GetHMSType(xname) {
-	case xnametypes.ComputeModule:
-		ids := generateNcChildIDs(s, xname, op)
-		u.ComponentIDs = append(u.ComponentIDs, ids...)
-	case xnametypes.RouterModule:
-		ids := generateRcChildIDs(s, xname, op)
-		u.ComponentIDs = append(u.ComponentIDs, ids...)
-	}
-
-	// Kick off rediscovery for any BMCs that are getting powered on.
-	// This may fail at first if the BMC isn't ready yet but the
-	// LastDiscoveryStatus will get changed to a failed state which will
-	// cause a retry later.
-	if u.State == base.StateOn.String() {
-		for _, id := range u.ComponentIDs {
-			if xnametypes.IsHMSTypeController(xnametypes.GetHMSType(id)) {
-				rep, err := s.db.GetRFEndpointByID(id)
-				if err != nil {
-					s.Log(LOG_INFO, "powerStateCMM(): Lookup failure on %s: %s", id, err)
-				} else if rep != nil {
-					go s.discoverFromEndpoint(rep, 0, false)
-				}
-			}
-		}
-	}
-
-	return u, nil
-}
-
-// Helper function for ResourcePowerStateChangedParser - nC changes
-func powerStateNC(s *SmD, pe *processedRFEvent,
-	xname string,
-	op ResourceOp,
-) (*CompUpdate, error) {
-	u := new(CompUpdate)
-	u.ComponentIDs = append(u.ComponentIDs, xname)
-	u.UpdateType = StateDataUpdate.String()
-	switch op {
-	case ResourceOn:
-		u.State = base.StateOn.String()
-		// Update hwinv for nodes
-		if xnametypes.GetHMSType(xname) == xnametypes.Node {
-			cep, ep, err := s.getCompEPInfo(xname)
-			if err == nil {
-				go s.doUpdateCompHWInv(cep, ep)
-			}
-		}
-	case ResourceOff:
-		u.State = base.StateOff.String()
-	}
-	return u, nil
-}
-
-// Helper for ResourcePowerStateChangedParser - sC/TOR changes
-func powerStateRC(s *SmD, pe *processedRFEvent,
-	xname string,
-	op ResourceOp,
-) (*CompUpdate, error) {
-	u := new(CompUpdate)
-	u.ComponentIDs = append(u.ComponentIDs, xname)
-	u.UpdateType = StateDataUpdate.String()
-	switch op {
-	case ResourceOn:
-		u.State = base.StateOn.String()
-	case ResourceOff:
-		u.State = base.StateOff.String()
-	}
-	return u, nil
-}
-
-// Helper for ResourcePowerStateChangedParser - PDU (RTS) changes.
-func powerStateCabPDUController(s *SmD, pe *processedRFEvent,
-	xname string,
-	op ResourceOp,
-) (*CompUpdate, error) {
-	u := new(CompUpdate)
-	u.ComponentIDs = append(u.ComponentIDs, xname)
-	u.UpdateType = StateDataUpdate.String()
-	switch op {
-	case ResourceOn:
-		u.State = base.StateOn.String()
-	case ResourceOff:
-		u.State = base.StateOff.String()
-	}
-	return u, nil
-}
-
 // The database will ignore any ids that don't exist, do them all.
 // If ResourceOn only include up controllers/cards themselves, other
 // If ResourceOff include all nodes and other subcomponents.
@@ -595,49 +394,71 @@ func generateRcChildIDs(s *SmD, xname string, op ResourceOp) []string {
 }
 
 /////////////////////////////////////////////////////////////////////////////
-// Intel BMC firmware & HPE iLo
+// ResourceEvents - Health changes, standard ResourceEvent registry.
 /////////////////////////////////////////////////////////////////////////////
 
-// EventActionParser - Alert, presumably from Intel BMC, that indicates
+// EventActionParser - ResourceStatusChangedOK/Warning/Critical - Standard
 //
-//	System (i.e. node) powered ON.
-//	Id in OriginOfCondition (though likely single node).
-func AlertSystemPowerOnParser(s *SmD, pe *processedRFEvent) (*CompUpdate, error) {
-	u := new(CompUpdate)
-	xname, err := s.getIDForURI(pe.RfEndppointID, pe.Origin)
+//	ResourceEvent registry.  The health of the resource in the
+//	OriginOfCondition (or first URI arg) has changed.  Record it as a
+//	contributor to the flag of the component it belongs to and
+//	recompute that component's flag from all of its contributors.
+func ResourceStatusChangedParser(s *SmD, pe *processedRFEvent) (*CompUpdate, error) {
+	uri := pe.Origin
+	if uri == "" {
+		for _, arg := range pe.MessageArgs {
+			if strings.HasPrefix(arg, "/") == true {
+				uri = arg
+				break
+			}
+		}
+	}
+	if uri == "" {
+		return nil, ErrSmMsgNoURI
+	}
+	var health string
+	switch strings.ToLower(pe.MessageId) {
+	case "resourcestatuschangedok":
+		health = "OK"
+	case "resourcestatuschangedwarning":
+		health = "Warning"
+	case "resourcestatuschangedcritical":
+		health = "Critical"
+	default:
+		return nil, ErrSmMsgIgnState
+	}
+	xname, compURI, err := s.getIDForSubURI(pe.RfEndppointID, uri)
 	if err != nil {
 		return nil, err
 	} else if xname == "" {
+		s.Log(LOG_INFO, "ResourceStatusChangedParser(%s, %s): Not found.",
+			pe.RfEndppointID, uri)
 		return nil, ErrSmMsgNoID
 	}
-	// Update hwinv for nodes
-	if xnametypes.GetHMSType(xname) == xnametypes.Node {
-		cep, ep, err := s.getCompEPInfo(xname)
-		if err == nil {
-			go s.doUpdateCompHWInv(cep, ep)
-		}
+	hc := &sm.CompHealthContributor{
+		CompID:  xname,
+		OdataID: uri,
+		Health:  health,
+		Flag:    rf.HealthFlag(rf.HealthRF(health)),
+		Source:  sm.CompHealthSourceEvent,
 	}
-	u.ComponentIDs = append(u.ComponentIDs, xname)
-	u.UpdateType = StateDataUpdate.String()
-	u.State = base.StateOn.String()
-	return u, nil
-}
-
-// EventActionParser - Alert, presumably from Intel BMC, that indicates
-//
-//	System (i.e. node) powered OFF.
-//	Id in OriginOfCondition (though likely single node).
-func AlertSystemPowerOffParser(s *SmD, pe *processedRFEvent) (*CompUpdate, error) {
-	u := new(CompUpdate)
-	xname, err := s.getIDForURI(pe.RfEndppointID, pe.Origin)
+	if compURI == strings.TrimSuffix(uri, "/") {
+		// The component itself rather than one of its subcomponents.
+		hc.ID = xname
+		hc.Type = xnametypes.GetHMSType(xname).String()
+	}
+	hcs, err := s.db.UpdateCompHealthContributor(hc)
 	if err != nil {
 		return nil, err
-	} else if xname == "" {
-		return nil, ErrSmMsgNoID
 	}
+	flag := base.FlagOK.String()
+	for _, c := range hcs {
+		flag = rf.WorstFlag(flag, c.Flag)
+	}
+	u := new(CompUpdate)
 	u.ComponentIDs = append(u.ComponentIDs, xname)
-	u.UpdateType = StateDataUpdate.String()
-	u.State = base.StateOff.String()
+	u.UpdateType = FlagOnlyUpdate.String()
+	u.Flag = flag
 	return u, nil
 }
 
@@ -928,53 +749,6 @@ func (s *SmD) doUpdateCompFoxconn(cep *sm.ComponentEndpoint, ep *rf.RedfishEP) e
 	return nil
 }
 
-// EventActionParser - Alert, presumably from Foxconn Paradise BMC
-//
-//	The OriginOfXondition (pe.Origin) will likely not be set. Assume n0 in that case
-func FoxconnAlertSystemPowerOnParser(s *SmD, pe *processedRFEvent) (*CompUpdate, error) {
-	u := new(CompUpdate)
-	xname := fmt.Sprintf("%sn0", pe.RfEndppointID)
-	if pe.Origin != "" {
-		xname, err := s.getIDForURI(pe.RfEndppointID, pe.Origin)
-		if err != nil {
-			return nil, err
-		} else if xname == "" {
-			return nil, ErrSmMsgNoID
-		}
-	}
-	// Update hwinv for nodes
-	if xnametypes.GetHMSType(xname) == xnametypes.Node {
-		cep, ep, err := s.getCompEPInfo(xname)
-		if err == nil {
-			go s.doUpdateCompFoxconn(cep, ep)
-		}
-	}
-	u.ComponentIDs = append(u.ComponentIDs, xname)
-	u.UpdateType = StateDataUpdate.String()
-	u.State = base.StateOn.String()
-	return u, nil
-}
-
-// EventActionParser - Alert, presumably from Foxconn Paradise BMC
-//
-//	The OriginOfXondition (pe.Origin) will likely not be set. Assume n0 in that case
-func FoxconnAlertSystemPowerOffParser(s *SmD, pe *processedRFEvent) (*CompUpdate, error) {
-	u := new(CompUpdate)
-	xname := fmt.Sprintf("%sn0", pe.RfEndppointID)
-	if pe.Origin != "" {
-		xname, err := s.getIDForURI(pe.RfEndppointID, pe.Origin)
-		if err != nil {
-			return nil, err
-		} else if xname == "" {
-			return nil, ErrSmMsgNoID
-		}
-	}
-	u.ComponentIDs = append(u.ComponentIDs, xname)
-	u.UpdateType = StateDataUpdate.String()
-	u.State = base.StateOff.String()
-	return u, nil
-}
-
 /////////////////////////////////////////////////////////////////////////////
 //
 // Cached DB lookups
@@ -994,6 +768,7 @@ type SyncMap struct {
 	syncNum   int
 	keyLookup map[string]string
 	f         SMapBuildFunc
+	stale     bool // Rebuild on next lookup
 }
 
 // Creates a new SyncMap with the given function for creating the mapping,
@@ -1038,11 +813,44 @@ func (smap *SyncMap) TrySync(syncNum int) (bool, int, error) {
 		return false, smap.syncNum, err
 	}
 	smap.syncNum += 1
+	smap.stale = false
 	return true, smap.syncNum, nil
 }
 
+// Mark the mapping as out of date, e.g. because the underlying data
+// changed, so that the next lookup rebuilds it first.  Many changes in
+// a row only cost one rebuild.
+func (smap *SyncMap) Invalidate() {
+	smap.rwLock.Lock()
+	defer smap.rwLock.Unlock()
+	smap.stale = true
+}
+
+// Rebuild the mapping if it was invalidated.  If that fails the old
+// mapping is kept, and it is tried again next time.
+func (smap *SyncMap) syncIfStale() {
+	smap.rwLock.RLock()
+	stale := smap.stale
+	smap.rwLock.RUnlock()
+	if !stale {
+		return
+	}
+	smap.rwLock.Lock()
+	defer smap.rwLock.Unlock()
+	if !smap.stale {
+		// Someone beat us to it
+		return
+	}
+	if err := smap.f(smap); err != nil {
+		return
+	}
+	smap.syncNum += 1
+	smap.stale = false
+}
+
 // Get the primary key for a stored object based on a lookup string
 func (smap *SyncMap) LookupKey(queryKey string) (string, int) {
+	smap.syncIfStale()
 	smap.rwLock.RLock()
 	defer smap.rwLock.RUnlock()
 	value, ok := smap.keyLookup[queryKey]
@@ -1054,6 +862,7 @@ func (smap *SyncMap) LookupKey(queryKey string) (string, int) {
 
 // Get the stored data type by it's primary key
 func (smap *SyncMap) LookupValue(key string) (interface{}, int) {
+	smap.syncIfStale()
 	smap.rwLock.RLock()
 	defer smap.rwLock.RUnlock()
 	value, ok := smap.valStore[key]
@@ -1160,6 +969,28 @@ func (s *SmD) getIDForURI(epID, URI string) (string, error) {
 	return id, nil
 }
 
+// Like getIDForURI, but URI may also be that of a subcomponent with no
+// ComponentEndpoint of its own, e.g. a DIMM or power supply.  The URI and
+// then each of its parents is tried in turn, and the xname of the first
+// component found is returned along with the URI that matched, without any
+// fragment or trailing slash.
+func (s *SmD) getIDForSubURI(epID, URI string) (string, string, error) {
+	// Legacy Thermal and Power objects use fragments for fans, etc.
+	uri := strings.SplitN(URI, "#", 2)[0]
+	uri = strings.TrimSuffix(uri, "/")
+	// Stop before collections at the top of the tree, e.g. /redfish/v1/Systems
+	for strings.Count(uri, "/") > 3 {
+		id, err := s.getIDForURI(epID, uri)
+		if err != nil {
+			return "", "", err
+		} else if id != "" {
+			return id, uri, nil
+		}
+		uri = uri[:strings.LastIndex(uri, "/")]
+	}
+	return "", "", nil
+}
+
 // Get the stored data type (in this case ComponentEndpoint) by it's xname ID
 func (s *SmD) getCompEPbyID(epID string) (*sm.ComponentEndpoint, error) {
 	var found, didUpdate bool = false, false
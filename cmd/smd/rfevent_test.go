@@ -27,6 +27,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"reflect"
 	"testing"
 
 	base "github.com/Cray-HPE/hms-base/v2"
@@ -737,6 +738,133 @@ func TestDoHandleRFEvent(t *testing.T) {
 	}
 }
 
+func TestResourceStatusChangedParser(t *testing.T) {
+	psuURI := "/redfish/v1/Chassis/Enclosure/PowerSubsystem/PowerSupplies/PSU1"
+	chassisURI := "/redfish/v1/Chassis/Enclosure"
+	tests := []struct {
+		pe             *processedRFEvent
+		hmsdsResp      []*sm.CompHealthContributor
+		expectedErr    error
+		expectedHC     *sm.CompHealthContributor
+		expectedUpdate *CompUpdate
+	}{{
+		// Subcomponent goes critical, flag the chassis it is under.
+		pe: &processedRFEvent{
+			MessageId:     "ResourceStatusChangedCritical",
+			Registry:      "ResourceEvent",
+			RfEndppointID: "x1c4b0",
+			Origin:        psuURI,
+		},
+		hmsdsResp: []*sm.CompHealthContributor{
+			{CompID: "x1c4", OdataID: psuURI, Health: "Critical", Flag: "Alert"},
+		},
+		expectedHC: &sm.CompHealthContributor{
+			CompID:  "x1c4",
+			ID:      "",
+			OdataID: psuURI,
+			Health:  "Critical",
+			Flag:    "Alert",
+			Source:  sm.CompHealthSourceEvent,
+		},
+		expectedUpdate: &CompUpdate{
+			ComponentIDs: []string{"x1c4"},
+			UpdateType:   FlagOnlyUpdate.String(),
+			Flag:         "Alert",
+		},
+	}, {
+		// Component itself recovers, but another contributor remains.
+		pe: &processedRFEvent{
+			MessageId:     "ResourceStatusChangedOK",
+			Registry:      "ResourceEvent",
+			RfEndppointID: "x1c4b0",
+			MessageArgs:   []string{chassisURI, "OK"},
+		},
+		hmsdsResp: []*sm.CompHealthContributor{
+			{CompID: "x1c4", OdataID: psuURI, Health: "Warning", Flag: "Warning"},
+		},
+		expectedHC: &sm.CompHealthContributor{
+			CompID:  "x1c4",
+			ID:      "x1c4",
+			Type:    "Chassis",
+			OdataID: chassisURI,
+			Health:  "OK",
+			Flag:    "OK",
+			Source:  sm.CompHealthSourceEvent,
+		},
+		expectedUpdate: &CompUpdate{
+			ComponentIDs: []string{"x1c4"},
+			UpdateType:   FlagOnlyUpdate.String(),
+			Flag:         "Warning",
+		},
+	}, {
+		// Everything healthy again.
+		pe: &processedRFEvent{
+			MessageId:     "ResourceStatusChangedOK",
+			Registry:      "ResourceEvent",
+			RfEndppointID: "x1c4b0",
+			Origin:        psuURI,
+		},
+		hmsdsResp: []*sm.CompHealthContributor{},
+		expectedHC: &sm.CompHealthContributor{
+			CompID:  "x1c4",
+			OdataID: psuURI,
+			Health:  "OK",
+			Flag:    "OK",
+			Source:  sm.CompHealthSourceEvent,
+		},
+		expectedUpdate: &CompUpdate{
+			ComponentIDs: []string{"x1c4"},
+			UpdateType:   FlagOnlyUpdate.String(),
+			Flag:         "OK",
+		},
+	}, {
+		// Not under any known component
+		pe: &processedRFEvent{
+			MessageId:     "ResourceStatusChangedWarning",
+			Registry:      "ResourceEvent",
+			RfEndppointID: "x1c4b0",
+			Origin:        "/redfish/v1/Chassis/Unknown/Sensors/Temp1",
+		},
+		expectedErr: ErrSmMsgNoID,
+	}, {
+		pe: &processedRFEvent{
+			MessageId:     "ResourceStatusChangedWarning",
+			Registry:      "ResourceEvent",
+			RfEndppointID: "x1c4b0",
+		},
+		expectedErr: ErrSmMsgNoURI,
+	}}
+
+	results.GetCompEndpointsAll.Return.entries = st.SampleCompEndpoints
+	results.GetCompEndpointsAll.Return.err = nil
+	results.GetCompEndpointIDs.Funcs.getID = GetCompEpIDsGenGetID
+	results.GetCompEndpointIDs.Funcs.returnIDs = GetCompEpIDsGenReturnIDs(st.SampleCompEndpoints)
+
+	for i, test := range tests {
+		results.UpdateCompHealthContributor.Input.hc = nil
+		results.UpdateCompHealthContributor.Return.hcs = test.hmsdsResp
+		results.UpdateCompHealthContributor.Return.err = nil
+
+		update, err := s.compUpdateFromRFEvent(test.pe)
+		if err != test.expectedErr {
+			t.Errorf("Test %d FAIL: Expected err '%v'; Received: '%v'",
+				i, test.expectedErr, err)
+			continue
+		}
+		if test.expectedErr != nil {
+			continue
+		}
+		if !reflect.DeepEqual(test.expectedHC, results.UpdateCompHealthContributor.Input.hc) {
+			t.Errorf("Test %d FAIL: Expected contributor '%v'; Received '%v'",
+				i, test.expectedHC, results.UpdateCompHealthContributor.Input.hc)
+		}
+		if !reflect.DeepEqual(test.expectedUpdate, update) {
+			t.Errorf("Test %d FAIL: Expected update '%v'; Received '%v'",
+				i, test.expectedUpdate, update)
+		}
+	}
+}
+
 //////////////////////////////////////////////////////////////////////////////
 //                         Dummy Mock Server
 //////////////////////////////////////////////////////////////////////////////
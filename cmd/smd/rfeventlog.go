@@ -0,0 +1,176 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"strings"
+	"time"
+
+	"github.com/Cray-HPE/hms-smd/v2/internal/hmsds"
+	"github.com/Cray-HPE/hms-smd/v2/pkg/sm"
+)
+
+////////////////////////////////////////////////////////////////////////////
+//
+// Redfish event log - Every processed Redfish event record is kept in the
+// database, up to a maximum number of entries and a maximum age, so
+// that it can be queried later alongside the hardware history.
+//
+////////////////////////////////////////////////////////////////////////////
+
+const (
+	rfEventLogMaxDefault    = 100000 // entries, 0 disables the log
+	rfEventLogAgeMaxDefault = 30     // days
+
+	// How often old entries are pruned.
+	rfEventLogPruneInterval = 5 * time.Minute
+
+	// Hardware history within this long of a FRU event is attached to it.
+	rfEventLogHWInvHistWindow = 5 * time.Minute
+)
+
+// Record the processed event records from one Redfish event in the event
+// log.  Failures are only logged as they should not stop event processing.
+func (s *SmD) logRFEvents(pes []*processedRFEvent) {
+	if s.rfEventLogMax <= 0 || len(pes) == 0 {
+		return
+	}
+	entries := make([]*sm.RFEventLogEntry, 0, len(pes))
+	for _, pe := range pes {
+		entries = append(entries, s.newRFEventLogEntry(pe))
+	}
+	if err := s.db.InsertRFEventLogEntries(entries); err != nil {
+		s.LogAlways("logRFEvents(): Failed to store %d event(s) from %s: %s",
+			len(entries), pes[0].RfEndppointID, err)
+	}
+}
+
+// Build the event log entry for pe.  The component is the one the
+// OriginOfCondition (or first URI arg) belongs to, or else the BMC that
+// sent it.  For messages that add or remove a FRU, the FRU currently at
+// that location is recorded as well.
+func (s *SmD) newRFEventLogEntry(pe *processedRFEvent) *sm.RFEventLogEntry {
+	ent := &sm.RFEventLogEntry{
+		ComponentID:       pe.RfEndppointID,
+		RfEndpointID:      pe.RfEndppointID,
+		EventTimestamp:    pe.EventTimestamp,
+		EventId:           pe.EventId,
+		Registry:          pe.Registry,
+		RegistryVersion:   pe.RegVersion,
+		MessageId:         pe.MessageId,
+		Severity:          pe.Severity,
+		Message:           pe.Message,
+		MessageArgs:       pe.MessageArgs,
+		OriginOfCondition: pe.Origin,
+	}
+	uri := pe.Origin
+	if uri == "" {
+		for _, arg := range pe.MessageArgs {
+			if strings.HasPrefix(arg, "/") == true {
+				uri = arg
+				break
+			}
+		}
+	}
+	if uri != "" {
+		xname, _, err := s.getIDForSubURI(pe.RfEndppointID, uri)
+		if err != nil {
+			s.Log(LOG_INFO, "newRFEventLogEntry(%s, %s): %s",
+				pe.RfEndppointID, uri, err)
+		} else if xname != "" {
+			ent.ComponentID = xname
+		}
+	}
+	if sm.IsRFEventFRUMessage(pe.MessageId) {
+		hl, err := s.db.GetHWInvByLocID(ent.ComponentID)
+		if err != nil {
+			s.Log(LOG_INFO, "newRFEventLogEntry(%s): FRU lookup: %s",
+				ent.ComponentID, err)
+		} else if hl != nil && hl.PopulatedFRU != nil {
+			ent.FRUID = hl.PopulatedFRU.FRUID
+		}
+	}
+	return ent
+}
+
+// Attach the hardware history of the component around the time of each
+// FRU-affecting entry so added/removed events can be matched to the FRUs
+// involved.
+func (s *SmD) addRFEventLogHWInvHist(entries []*sm.RFEventLogEntry) error {
+	for _, ent := range entries {
+		if !sm.IsRFEventFRUMessage(ent.MessageId) {
+			continue
+		}
+		ts, err := time.Parse(time.RFC3339Nano, ent.Timestamp)
+		if err != nil {
+			continue
+		}
+		hist, err := s.db.GetHWInvHistFilter(
+			hmsds.HWInvHist_ID(ent.ComponentID),
+			hmsds.HWInvHist_StartTime(
+				ts.Add(-rfEventLogHWInvHistWindow).Format(time.RFC3339)),
+			hmsds.HWInvHist_EndTime(
+				ts.Add(rfEventLogHWInvHistWindow).Format(time.RFC3339)))
+		if err != nil {
+			return err
+		}
+		if len(hist) > 0 {
+			ent.HWInvHistory = hist
+		}
+	}
+	return nil
+}
+
+// Remove event log entries older than the maximum age or beyond the
+// maximum number of entries.
+func (s *SmD) pruneRFEventLog() error {
+	before := time.Now().AddDate(0, 0, -s.rfEventLogAgeMax)
+	num, err := s.db.PruneRFEventLog(before, s.rfEventLogMax)
+	if err != nil {
+		return err
+	}
+	if num > 0 {
+		s.Log(LOG_INFO, "pruneRFEventLog(): Removed %d event log entries", num)
+	}
+	return nil
+}
+
+// Periodically prune the event log.  Does nothing if the log is disabled.
+// Only the leader prunes.
+func (s *SmD) RFEventLogPrune() {
+	if s.rfEventLogMax <= 0 {
+		return
+	}
+	go func() {
+		for {
+			if !s.isLeader() {
+				time.Sleep(rfEventLogPruneInterval)
+				continue
+			}
+			if err := s.pruneRFEventLog(); err != nil {
+				s.LogAlways("RFEventLogPrune(): Prune failure: %s", err)
+			}
+			time.Sleep(rfEventLogPruneInterval)
+		}
+	}()
+}
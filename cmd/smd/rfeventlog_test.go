@@ -0,0 +1,215 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/Cray-HPE/hms-smd/v2/internal/hmsds"
+	st "github.com/Cray-HPE/hms-smd/v2/pkg/sharedtest"
+	"github.com/Cray-HPE/hms-smd/v2/pkg/sm"
+)
+
+func TestLogRFEvents(t *testing.T) {
+	psuURI := "/redfish/v1/Chassis/Enclosure/PowerSubsystem/PowerSupplies/PSU1"
+	pes := []*processedRFEvent{{
+		// Subcomponent is removed, FRU there is recorded.
+		MessageId:      "ResourceRemoved",
+		Registry:       "ResourceEvent",
+		RegVersion:     "1.0",
+		RfEndppointID:  "x1c4b0",
+		Origin:         psuURI,
+		Severity:       "Warning",
+		Message:        "The resource has been removed.",
+		MessageArgs:    []string{psuURI},
+		EventId:        "12",
+		EventTimestamp: "2026-10-19T11:37:00Z",
+	}, {
+		// No URI, so it can only be logged against the BMC.
+		MessageId:     "Alert",
+		Registry:      "CrayAlerts",
+		RfEndppointID: "x1c4b0",
+	}}
+	expected := []*sm.RFEventLogEntry{{
+		ComponentID:       "x1c4",
+		RfEndpointID:      "x1c4b0",
+		EventTimestamp:    "2026-10-19T11:37:00Z",
+		EventId:           "12",
+		Registry:          "ResourceEvent",
+		RegistryVersion:   "1.0",
+		MessageId:         "ResourceRemoved",
+		Severity:          "Warning",
+		Message:           "The resource has been removed.",
+		MessageArgs:       []string{psuURI},
+		OriginOfCondition: psuURI,
+		FRUID:             "Chassis.Cray.1234",
+	}, {
+		ComponentID:  "x1c4b0",
+		RfEndpointID: "x1c4b0",
+		Registry:     "CrayAlerts",
+		MessageId:    "Alert",
+	}}
+
+	rfEventLogMax := s.rfEventLogMax
+	defer func() { s.rfEventLogMax = rfEventLogMax }()
+
+	results.GetCompEndpointsAll.Return.entries = st.SampleCompEndpoints
+	results.GetCompEndpointsAll.Return.err = nil
+	results.GetCompEndpointIDs.Funcs.getID = GetCompEpIDsGenGetID
+	results.GetCompEndpointIDs.Funcs.returnIDs = GetCompEpIDsGenReturnIDs(st.SampleCompEndpoints)
+	results.GetHWInvByLocID.Return.entry = &sm.HWInvByLoc{
+		ID:           "x1c4",
+		PopulatedFRU: &sm.HWInvByFRU{FRUID: "Chassis.Cray.1234"},
+	}
+	results.GetHWInvByLocID.Return.err = nil
+
+	// Disabled, nothing stored.
+	s.rfEventLogMax = 0
+	results.InsertRFEventLogEntries.Input.entries = nil
+	s.logRFEvents(pes)
+	if len(results.InsertRFEventLogEntries.Input.entries) != 0 {
+		t.Errorf("Expected no entries while disabled; Received %d",
+			len(results.InsertRFEventLogEntries.Input.entries))
+	}
+
+	s.rfEventLogMax = 100
+	s.logRFEvents(pes)
+	if results.GetHWInvByLocID.Input.id != "x1c4" {
+		t.Errorf("Expected FRU lookup for 'x1c4'; Received '%s'",
+			results.GetHWInvByLocID.Input.id)
+	}
+	if !reflect.DeepEqual(expected, results.InsertRFEventLogEntries.Input.entries) {
+		t.Errorf("Expected entries '%v'; Received '%v'",
+			expected, results.InsertRFEventLogEntries.Input.entries)
+	}
+}
+
+func TestDoRFEventLogGet(t *testing.T) {
+	ent := func(id int64, msgId string) *sm.RFEventLogEntry {
+		return &sm.RFEventLogEntry{
+			ID:           id,
+			ComponentID:  "x3000c0s1b0n0",
+			RfEndpointID: "x3000c0s1b0",
+			Timestamp:    "2026-10-19T11:37:00Z",
+			Registry:     "ResourceEvent",
+			MessageId:    msgId,
+		}
+	}
+	hist := []*sm.HWInvHist{{
+		ID:        "x3000c0s1b0n0",
+		FruId:     "Node.Cray.1",
+		Timestamp: "2026-10-19T11:37:10Z",
+		EventType: sm.HWInvHistEventTypeRemoved,
+	}}
+
+	tests := []struct {
+		reqURI         string
+		dbEntries      []*sm.RFEventLogEntry
+		dbErr          error
+		expectedCode   int
+		expectedIDs    []int64
+		expectedMarker int64
+	}{{
+		// One more than the limit, so there is another page.
+		reqURI: "/hsm/v2/Events?xname=x3000c0s1b0n0&severity=critical&limit=2",
+		dbEntries: []*sm.RFEventLogEntry{
+			ent(9, "ResourceRemoved"),
+			ent(8, "ResourceStatusChangedCritical"),
+			ent(7, "ResourceStatusChangedCritical"),
+		},
+		expectedCode:   http.StatusOK,
+		expectedIDs:    []int64{9, 8},
+		expectedMarker: 8,
+	}, {
+		// Last page.
+		reqURI:       "/hsm/v2/Events?limit=2&marker=8",
+		dbEntries:    []*sm.RFEventLogEntry{ent(7, "Alert")},
+		expectedCode: http.StatusOK,
+		expectedIDs:  []int64{7},
+	}, {
+		reqURI:       "/hsm/v2/Events?xname=foo",
+		expectedCode: http.StatusBadRequest,
+	}, {
+		reqURI:       "/hsm/v2/Events?limit=0",
+		expectedCode: http.StatusBadRequest,
+	}, {
+		reqURI:       "/hsm/v2/Events?marker=abc",
+		expectedCode: http.StatusBadRequest,
+	}, {
+		reqURI:       "/hsm/v2/Events?since=yesterday",
+		dbErr:        hmsds.ErrHMSDSArgBadTimeFormat,
+		expectedCode: http.StatusBadRequest,
+	}, {
+		reqURI:       "/hsm/v2/Events",
+		dbErr:        hmsds.ErrHMSDSArgMissing,
+		expectedCode: http.StatusInternalServerError,
+	}}
+
+	for i, test := range tests {
+		results.GetRFEventLogFilter.Return.entries = test.dbEntries
+		results.GetRFEventLogFilter.Return.err = test.dbErr
+		results.GetHWInvHistFilter.Return.hwhists = hist
+		results.GetHWInvHistFilter.Return.err = nil
+
+		req, err := http.NewRequest("GET", test.reqURI, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if test.expectedCode != w.Code {
+			t.Errorf("Test %v Failed: Expected status code %v; Received %v (%s)",
+				i, test.expectedCode, w.Code, w.Body.String())
+			continue
+		}
+		if test.expectedCode != http.StatusOK {
+			continue
+		}
+		page := new(sm.RFEventLogPage)
+		if err := json.Unmarshal(w.Body.Bytes(), page); err != nil {
+			t.Errorf("Test %v Failed: Bad response: %s", i, err)
+			continue
+		}
+		ids := []int64{}
+		for _, e := range page.Events {
+			ids = append(ids, e.ID)
+			if sm.IsRFEventFRUMessage(e.MessageId) != (e.HWInvHistory != nil) {
+				t.Errorf("Test %v Failed: Unexpected HW history for '%s': %v",
+					i, e.MessageId, e.HWInvHistory)
+			}
+		}
+		if !reflect.DeepEqual(test.expectedIDs, ids) {
+			t.Errorf("Test %v Failed: Expected IDs %v; Received %v",
+				i, test.expectedIDs, ids)
+		}
+		if test.expectedMarker != page.NextMarker {
+			t.Errorf("Test %v Failed: Expected marker %d; Received %d",
+				i, test.expectedMarker, page.NextMarker)
+		}
+	}
+}
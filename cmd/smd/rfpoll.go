@@ -0,0 +1,696 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	base "github.com/Cray-HPE/hms-base/v2"
+	"github.com/Cray-HPE/hms-smd/v2/internal/hmsds"
+	rf "github.com/Cray-HPE/hms-smd/v2/pkg/redfish"
+	"github.com/Cray-HPE/hms-smd/v2/pkg/sm"
+	"github.com/Cray-HPE/hms-xname/xnametypes"
+)
+
+// State Redfish Polling
+//
+// Components left in a transitional state, e.g. a node in Standby waiting
+// for a power off event, have their Redfish power state polled until it
+// changes or polling is cancelled.  A single poller handles all of the
+// components owned by this HSM instance.  Due polls are batched per BMC so
+// that each BMC is polled by one worker at a time using one set of
+// credentials, and the poll interval backs off both per component (while
+// the power state is unchanged) and per BMC (while it is unreachable).
+//
+// Each polled component still has a StateRFPoll job in the job sync so
+// that other HSM instances can pick it up should this one die.
+
+const (
+	rfPollWorkersDefault   = 64
+	rfPollKeepAliveDefault = 20
+	rfPollBMCBackoffMax    = 300 // seconds
+)
+
+// A rule saying which components to poll and what to do when their power
+// state changes.
+type rfPollRule struct {
+	Name        string   `json:"Name"`
+	State       string   `json:"State"`           // HSM state that starts polling
+	Types       []string `json:"Types,omitempty"` // HMS types, all if empty
+	PowerState  string   `json:"PowerState"`      // Redfish state that ends it
+	NewState    string   `json:"NewState"`        // HSM state to set then
+	Delay       int      `json:"Delay"`           // Seconds before first poll
+	Interval    int      `json:"Interval"`        // Seconds between polls
+	MaxInterval int      `json:"MaxInterval"`     // Backoff limit, seconds
+	Backoff     float64  `json:"Backoff"`         // Interval multiplier
+	Timeout     int      `json:"Timeout"`         // Seconds, 0 for no limit
+	Disabled    bool     `json:"Disabled"`
+}
+
+type rfPollPolicy struct {
+	Workers   int           `json:"Workers"`   // BMCs polled at once
+	KeepAlive int           `json:"KeepAlive"` // Job keep alive, seconds
+	Rules     []*rfPollRule `json:"Rules"`
+}
+
+// The default policy polls nodes in Standby for a missed power off event
+// as HSM always has.  Polling nodes marked Off for a missed power on is
+// available but must be enabled through a policy file.
+func defaultRFPollPolicy() *rfPollPolicy {
+	p := &rfPollPolicy{
+		Rules: []*rfPollRule{{
+			Name:        "StandbyToOff",
+			State:       base.StateStandby.String(),
+			Types:       []string{xnametypes.Node.String()},
+			PowerState:  "Off",
+			NewState:    base.StateOff.String(),
+			Delay:       30,
+			Interval:    10,
+			MaxInterval: 60,
+			Backoff:     1.5,
+		}, {
+			Name:        "OffToOn",
+			State:       base.StateOff.String(),
+			Types:       []string{xnametypes.Node.String()},
+			PowerState:  "On",
+			NewState:    base.StateOn.String(),
+			Delay:       30,
+			Interval:    30,
+			MaxInterval: 120,
+			Backoff:     2,
+			Timeout:     600,
+			Disabled:    true,
+		}},
+	}
+	p.validate()
+	return p
+}
+
+// Check the policy and fill in defaults.
+func (p *rfPollPolicy) validate() error {
+	if p.Workers <= 0 {
+		p.Workers = rfPollWorkersDefault
+	}
+	if p.KeepAlive <= 0 {
+		p.KeepAlive = rfPollKeepAliveDefault
+	} else if p.KeepAlive < 5 {
+		p.KeepAlive = 5
+	}
+	for i, r := range p.Rules {
+		if r == nil {
+			return base.NewHMSError("smd", "poll rule is empty")
+		}
+		if r.Name == "" {
+			r.Name = "Rule" + strconv.Itoa(i)
+		}
+		if err := r.validate(); err != nil {
+			return base.NewHMSError("smd",
+				"poll rule '"+r.Name+"': "+err.Error())
+		}
+	}
+	return nil
+}
+
+// Check the rule and fill in defaults.
+func (r *rfPollRule) validate() error {
+	if r.State = base.VerifyNormalizeState(r.State); r.State == "" {
+		return base.ErrHMSStateInvalid
+	}
+	if r.NewState = base.VerifyNormalizeState(r.NewState); r.NewState == "" {
+		return base.ErrHMSStateInvalid
+	}
+	if r.PowerState == "" {
+		return base.NewHMSError("smd", "PowerState is required")
+	}
+	for i, t := range r.Types {
+		if r.Types[i] = xnametypes.VerifyNormalizeType(t); r.Types[i] == "" {
+			return base.NewHMSError("smd", "invalid type '"+t+"'")
+		}
+	}
+	if r.Delay < 0 || r.Interval < 0 || r.MaxInterval < 0 ||
+		r.Backoff < 0 || r.Timeout < 0 {
+		return base.NewHMSError("smd", "times must be 0+")
+	}
+	if r.Interval == 0 {
+		r.Interval = 10
+	}
+	if r.MaxInterval < r.Interval {
+		r.MaxInterval = r.Interval
+	}
+	if r.Backoff < 1 {
+		r.Backoff = 1
+	}
+	return nil
+}
+
+// Return the first enabled rule for components of the type of 'id' in
+// 'state', nil if none.
+func (p *rfPollPolicy) match(id, state string) *rfPollRule {
+	hmsType := xnametypes.GetHMSTypeString(id)
+	for _, r := range p.Rules {
+		if r.Disabled || !strings.EqualFold(r.State, state) {
+			continue
+		}
+		if len(r.Types) == 0 {
+			return r
+		}
+		for _, t := range r.Types {
+			if t == hmsType {
+				return r
+			}
+		}
+	}
+	return nil
+}
+
+// Read a poll policy from a JSON file.  Fields left unset get the usual
+// defaults.
+func LoadRFPollPolicyFile(path string) (*rfPollPolicy, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	p := new(rfPollPolicy)
+	if err := json.Unmarshal(data, p); err != nil {
+		return nil, err
+	}
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// A component being polled.
+type rfPollTarget struct {
+	job      *sm.Job
+	rule     *rfPollRule
+	compId   string
+	bmc      string // Found on add or first poll
+	status   string // Last job status written
+	start    time.Time
+	next     time.Time
+	interval time.Duration
+	polls    int
+	failures int
+	lastErr  string
+	busy     bool
+}
+
+// Batch key: targets are batched by BMC once we know it.
+func (t *rfPollTarget) key() string {
+	if t.bmc != "" {
+		return t.bmc
+	}
+	return t.compId
+}
+
+// Backoff state of an unreachable BMC.
+type rfPollBMC struct {
+	failures int
+	hold     time.Time
+}
+
+type rfPoller struct {
+	s       *SmD
+	policy  *rfPollPolicy
+	lock    sync.Mutex
+	targets map[string]*rfPollTarget // By xname
+	bmcs    map[string]*rfPollBMC    // Only those backing off
+	wake    chan struct{}
+	workers chan struct{}
+}
+
+func NewRFPoller(s *SmD, policy *rfPollPolicy) *rfPoller {
+	return &rfPoller{
+		s:       s,
+		policy:  policy,
+		targets: make(map[string]*rfPollTarget),
+		bmcs:    make(map[string]*rfPollBMC),
+		wake:    make(chan struct{}, 1),
+		workers: make(chan struct{}, policy.Workers),
+	}
+}
+
+// Start the scheduler and keep alive threads.
+func (p *rfPoller) Start() {
+	go p.run()
+	go p.keepAlive()
+}
+
+// Have the scheduler look at the queue again, e.g. after an add.
+func (p *rfPoller) kick() {
+	select {
+	case p.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Start polling 'id', which has entered 'state', if a rule says to.
+// Otherwise stop any polling of 'id' for its previous state.  A negative
+// delay uses the delay from the rule.  If another instance of HSM owns the
+// BMC, the job is left for it to pick up instead.  The job is written to
+// the database without holding the lock, since many components may change
+// state at once, e.g. when a cabinet loses power.
+func (p *rfPoller) add(id, state string, delay time.Duration) error {
+	rule := p.policy.match(id, state)
+
+	p.lock.Lock()
+	old, ok := p.targets[id]
+	p.lock.Unlock()
+	if (ok && old.rule == rule) || (!ok && rule == nil) {
+		// Already polling for this state, or nothing to stop.
+		return nil
+	}
+
+	var t *rfPollTarget
+	if rule != nil {
+		if delay < 0 {
+			delay = time.Duration(rule.Delay) * time.Second
+		}
+		job, err := sm.NewStateRFPollJob(id, int(delay/time.Second),
+			rule.Interval, p.policy.KeepAlive+10, p.policy.KeepAlive)
+		if err != nil {
+			return err
+		}
+		job.Id, err = p.s.db.InsertJob(job)
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		t = &rfPollTarget{
+			job:      job,
+			rule:     rule,
+			compId:   id,
+			bmc:      p.endpointOf(id),
+			status:   job.Status,
+			start:    now,
+			next:     now.Add(delay),
+			interval: time.Duration(rule.Interval) * time.Second,
+		}
+	}
+
+	// If another instance of HSM polls this BMC, the job isn't kept alive,
+	// so that instance picks it up once it expires, around when the first
+	// poll was due anyways.
+	keep := t != nil && p.s.ownsEndpoint(t.key())
+
+	p.lock.Lock()
+	cur, ok := p.targets[id]
+	if ok && cur.rule == rule {
+		// Another add for the same state got here first.
+		p.lock.Unlock()
+		if t != nil {
+			p.deleteJob(t)
+		}
+		return nil
+	}
+	delete(p.targets, id)
+	if keep {
+		p.targets[id] = t
+	}
+	p.lock.Unlock()
+
+	if ok {
+		p.deleteJob(cur)
+	}
+	if keep {
+		p.kick()
+	}
+	return nil
+}
+
+// Remove the job of a target that is no longer being polled.
+func (p *rfPoller) deleteJob(t *rfPollTarget) {
+	if _, err := p.s.db.DeleteJob(t.job.Id); err != nil {
+		p.s.LogAlways("State Redfish Poll of %s: Failed to delete job %s: %s",
+			t.compId, t.job.Id, err)
+	}
+}
+
+// Get the RedfishEndpoint (BMC) of 'id', from the cache so polls can be
+// batched from the start.  Empty if it isn't known.
+func (p *rfPoller) endpointOf(id string) string {
+	if cep, err := p.s.getCompEPbyID(id); err == nil && cep != nil {
+		return cep.RfEndpointID
+	}
+	return ""
+}
+
+// Stop polling 'id'.  Returns true if it was being polled.
+func (p *rfPoller) cancel(id string) bool {
+	p.lock.Lock()
+	t, ok := p.targets[id]
+	delete(p.targets, id)
+	p.lock.Unlock()
+	if ok {
+		p.deleteJob(t)
+	}
+	return ok
+}
+
+// Returns true if 'id' is being polled by the job 'jobId'.
+func (p *rfPoller) hasJob(id, jobId string) bool {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	t, ok := p.targets[id]
+	return ok && t.job.Id == jobId
+}
+
+// Get the polled components as StateRFPoll jobs with their live status.
+func (p *rfPoller) Jobs() []*sm.Job {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	jobs := make([]*sm.Job, 0, len(p.targets))
+	for _, t := range p.targets {
+		j := *t.job
+		j.Status = t.status
+		j.Data = &sm.SrfpJobData{
+			CompId:       t.compId,
+			Delay:        t.rule.Delay,
+			Poll:         int(t.interval / time.Second),
+			State:        t.rule.State,
+			RfEndpointID: t.bmc,
+			NextPoll:     t.next.UTC().Format(time.RFC3339),
+			Polls:        t.polls,
+			Failures:     t.failures,
+			LastError:    t.lastErr,
+			Owner:        serviceName,
+		}
+		jobs = append(jobs, &j)
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].Data.(*sm.SrfpJobData).CompId <
+			jobs[j].Data.(*sm.SrfpJobData).CompId
+	})
+	return jobs
+}
+
+// Scheduler.  Hands due batches to the workers and then sleeps until the
+// next poll is due or something is added.
+func (p *rfPoller) run() {
+	for {
+		batches, next := p.due(time.Now())
+		for _, batch := range batches {
+			p.workers <- struct{}{}
+			go func(batch []*rfPollTarget) {
+				p.poll(batch)
+				<-p.workers
+				p.kick()
+			}(batch)
+		}
+		wait := time.Until(next)
+		if next.IsZero() || wait > time.Minute {
+			wait = time.Minute
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-p.wake:
+		case <-timer.C:
+		}
+		timer.Stop()
+	}
+}
+
+// Collect the targets due to be polled at 'now', batched per BMC and
+// marked busy, and drop those that have timed out.  Also returns when the
+// next not yet due poll is, zero if none.
+func (p *rfPoller) due(now time.Time) ([][]*rfPollTarget, time.Time) {
+	var next time.Time
+	var expired []*rfPollTarget
+	byKey := make(map[string][]*rfPollTarget)
+
+	p.lock.Lock()
+	for id, t := range p.targets {
+		if t.busy {
+			continue
+		}
+		if t.rule.Timeout > 0 &&
+			now.Sub(t.start) >= time.Duration(t.rule.Timeout)*time.Second {
+			delete(p.targets, id)
+			expired = append(expired, t)
+			continue
+		}
+		when := t.next
+		if b, ok := p.bmcs[t.key()]; ok && b.hold.After(when) {
+			when = b.hold
+		}
+		if when.After(now) {
+			if next.IsZero() || when.Before(next) {
+				next = when
+			}
+			continue
+		}
+		t.busy = true
+		byKey[t.key()] = append(byKey[t.key()], t)
+	}
+	p.lock.Unlock()
+
+	for _, t := range expired {
+		p.s.LogAlways("State Redfish Poll of %s gave up after %ds in %s",
+			t.compId, t.rule.Timeout, t.rule.State)
+		p.deleteJob(t)
+	}
+	keys := make([]string, 0, len(byKey))
+	for key := range byKey {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	batches := make([][]*rfPollTarget, 0, len(keys))
+	for _, key := range keys {
+		batch := byKey[key]
+		sort.Slice(batch, func(i, j int) bool {
+			return batch[i].compId < batch[j].compId
+		})
+		batches = append(batches, batch)
+	}
+	return batches, next
+}
+
+// Poll a batch of targets on the same BMC.  If the BMC can't be reached
+// the rest of the batch waits for the BMC's backoff.
+func (p *rfPoller) poll(batch []*rfPollTarget) {
+	var ep *rf.RedfishEP
+	var bmcErr error
+	for _, t := range batch {
+		var (
+			cep        *sm.ComponentEndpoint
+			powerState string
+			err        error
+		)
+		if bmcErr != nil {
+			p.done(t, "", bmcErr, false)
+			continue
+		}
+		if ep == nil {
+			cep, ep, err = p.s.getCompEPInfo(t.compId)
+		} else {
+			cep, err = p.s.getCompEPbyID(t.compId)
+			if err == nil && cep == nil {
+				err = ErrSmMsgNoEP
+			}
+		}
+		if err == nil {
+			p.lock.Lock()
+			t.bmc = cep.RfEndpointID
+			p.lock.Unlock()
+			powerState, err = p.s.getCompEPState(cep, ep)
+			if err != nil {
+				bmcErr = err
+			}
+		}
+		p.done(t, powerState, err, bmcErr != nil)
+	}
+}
+
+// Record the result of polling a target and reschedule it or, if its power
+// state has changed, update its state.
+func (p *rfPoller) done(t *rfPollTarget, powerState string, err error, bmcErr bool) {
+	now := time.Now()
+	status := sm.JobInProgress
+	changed := false
+
+	p.lock.Lock()
+	t.busy = false
+	if p.targets[t.compId] != t {
+		// Cancelled while being polled
+		p.lock.Unlock()
+		return
+	}
+	if bmcErr {
+		b, ok := p.bmcs[t.key()]
+		if !ok {
+			b = new(rfPollBMC)
+			p.bmcs[t.key()] = b
+		}
+		if b.hold.Before(now) {
+			// Only back off once per batch
+			b.failures++
+			hold := time.Duration(t.rule.Interval) * time.Second
+			for i := 1; i < b.failures && hold < rfPollBMCBackoffMax*time.Second; i++ {
+				hold *= 2
+			}
+			if hold > rfPollBMCBackoffMax*time.Second {
+				hold = rfPollBMCBackoffMax * time.Second
+			}
+			b.hold = now.Add(hold)
+		}
+	} else if err == nil {
+		delete(p.bmcs, t.key())
+	}
+	if err != nil {
+		t.failures++
+		t.lastErr = err.Error()
+		status = sm.JobError
+	} else {
+		t.polls++
+		t.lastErr = ""
+		if strings.EqualFold(powerState, t.rule.PowerState) {
+			changed = true
+			delete(p.targets, t.compId)
+		}
+	}
+	t.next = now.Add(t.interval)
+	t.interval = time.Duration(float64(t.interval) * t.rule.Backoff)
+	if max := time.Duration(t.rule.MaxInterval) * time.Second; t.interval > max {
+		t.interval = max
+	}
+	setStatus := !changed && status != t.status
+	t.status = status
+	p.lock.Unlock()
+
+	if setStatus {
+		p.s.db.UpdateJob(t.job.Id, status)
+	}
+	if changed {
+		p.deleteJob(t)
+		update := new(CompUpdate)
+		update.ComponentIDs = []string{t.compId}
+		update.UpdateType = StateDataUpdate.String()
+		update.State = t.rule.NewState
+		update.Source = compHistSourceRFPoll
+		if err := p.s.doCompUpdate(update, "doPollRFState"); err != nil {
+			p.s.LogAlways("State Redfish Poll of %s: Failed to set %s: %s",
+				t.compId, t.rule.NewState, err)
+			// Try again later
+			p.add(t.compId, t.rule.State,
+				time.Duration(t.rule.Interval)*time.Second)
+		}
+	}
+}
+
+// Periodically stop polling components that are gone or no longer in the
+// polled state, e.g. because of an update through another HSM instance,
+// and keep the rest of our jobs alive.
+func (p *rfPoller) keepAlive() {
+	for {
+		time.Sleep(time.Duration(p.policy.KeepAlive) * time.Second)
+		p.doKeepAlive()
+	}
+}
+
+func (p *rfPoller) doKeepAlive() {
+	p.lock.Lock()
+	ids := make([]string, 0, len(p.targets))
+	for id := range p.targets {
+		ids = append(ids, id)
+	}
+	p.lock.Unlock()
+	if len(ids) == 0 {
+		return
+	}
+	f := hmsds.ComponentFilter{ID: ids}
+	comps, err := p.s.db.GetComponentsFilter(&f, hmsds.FLTR_STATEONLY)
+	if err != nil {
+		// Retry next time
+		p.s.LogAlways("State Redfish Poll keep alive: Lookup failure: %s", err)
+		return
+	}
+	states := make(map[string]string, len(comps))
+	for _, comp := range comps {
+		states[comp.ID] = comp.State
+	}
+	jobIds := make([]string, 0, len(ids))
+	for _, id := range ids {
+		p.lock.Lock()
+		t, ok := p.targets[id]
+		p.lock.Unlock()
+		if !ok {
+			continue
+		}
+		if state, ok := states[id]; !ok || !strings.EqualFold(state, t.rule.State) {
+			// Gone or state was changed.  We're done
+			p.lock.Lock()
+			if p.targets[id] == t {
+				delete(p.targets, id)
+			}
+			p.lock.Unlock()
+			p.deleteJob(t)
+			continue
+		}
+		jobIds = append(jobIds, t.job.Id)
+	}
+	if len(jobIds) == 0 {
+		return
+	}
+	num, err := p.s.db.UpdateJobs(jobIds, "")
+	if err != nil {
+		p.s.LogAlways("State Redfish Poll keep alive: Update failure: %s", err)
+		return
+	}
+	if num < int64(len(jobIds)) {
+		// Some jobs were cancelled, e.g. through the jobs API on another
+		// HSM instance.  Stop polling them.
+		p.dropDeleted(jobIds)
+	}
+}
+
+// Stop polling the targets of any of the given jobs that are no longer in
+// the job sync.
+func (p *rfPoller) dropDeleted(jobIds []string) {
+	jobs, err := p.s.db.GetJobs(hmsds.JS_IDs(jobIds))
+	if err != nil {
+		p.s.LogAlways("State Redfish Poll keep alive: Lookup failure: %s", err)
+		return
+	}
+	deleted := make(map[string]bool, len(jobIds))
+	for _, jobId := range jobIds {
+		deleted[jobId] = true
+	}
+	for _, j := range jobs {
+		delete(deleted, j.Id)
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	for id, t := range p.targets {
+		if deleted[t.job.Id] {
+			p.s.LogAlways("State Redfish Poll of %s was cancelled", id)
+			delete(p.targets, id)
+		}
+	}
+}
@@ -0,0 +1,469 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	base "github.com/Cray-HPE/hms-base/v2"
+	"github.com/Cray-HPE/hms-smd/v2/internal/hmsds"
+	rf "github.com/Cray-HPE/hms-smd/v2/pkg/redfish"
+	"github.com/Cray-HPE/hms-smd/v2/pkg/sm"
+)
+
+func TestRFPollPolicy(t *testing.T) {
+	p := defaultRFPollPolicy()
+	if p.Workers != rfPollWorkersDefault || p.KeepAlive != rfPollKeepAliveDefault {
+		t.Errorf("FAIL: Unexpected defaults: %d workers, %ds keep alive",
+			p.Workers, p.KeepAlive)
+	}
+	matches := []struct {
+		id       string
+		state    string
+		expected string
+	}{
+		{"x0c0s0b0n0", "Standby", "StandbyToOff"},
+		{"x0c0s0b0n0", "standby", "StandbyToOff"},
+		{"x0c0s0b0", "Standby", ""},
+		{"x0c0s0b0n0", "On", ""},
+		{"x0c0s0b0n0", "Off", ""}, // Disabled by default
+	}
+	for i, test := range matches {
+		name := ""
+		if r := p.match(test.id, test.state); r != nil {
+			name = r.Name
+		}
+		if name != test.expected {
+			t.Errorf("Test %d FAIL: %s %s: Expected rule '%s'; Received '%s'",
+				i, test.id, test.state, test.expected, name)
+		}
+	}
+
+	dir := t.TempDir()
+	files := []struct {
+		policy      string
+		expectedErr bool
+	}{
+		{`{"Workers": 8, "Rules": [
+			{"State": "off", "Types": ["node"], "PowerState": "On",
+			 "NewState": "on", "Timeout": 300}]}`, false},
+		{`{"Rules": [{"State": "Bogus", "PowerState": "On", "NewState": "On"}]}`, true},
+		{`{"Rules": [{"State": "Off", "PowerState": "On", "NewState": "Bogus"}]}`, true},
+		{`{"Rules": [{"State": "Off", "NewState": "On"}]}`, true},
+		{`{"Rules": [{"State": "Off", "Types": ["Bogus"],
+			"PowerState": "On", "NewState": "On"}]}`, true},
+		{`{"Rules": [{"State": "Off", "PowerState": "On", "NewState": "On",
+			"Interval": -1}]}`, true},
+		{`{"Rules": [`, true},
+	}
+	for i, test := range files {
+		path := filepath.Join(dir, "policy.json")
+		ioutil.WriteFile(path, []byte(test.policy), 0644)
+		p, err := LoadRFPollPolicyFile(path)
+		if test.expectedErr {
+			if err == nil {
+				t.Errorf("Test %d FAIL: Expected an error", i)
+			}
+			continue
+		} else if err != nil {
+			t.Errorf("Test %d FAIL: Unexpected error: %s", i, err)
+			continue
+		}
+		r := p.match("x0c0s0b0n0", "Off")
+		if p.Workers != 8 || p.KeepAlive != rfPollKeepAliveDefault || r == nil {
+			t.Errorf("Test %d FAIL: Unexpected policy: %+v", i, p)
+			continue
+		}
+		if r.Name != "Rule0" || r.State != "Off" || r.NewState != "On" ||
+			r.Types[0] != "Node" || r.Interval != 10 || r.MaxInterval != 10 ||
+			r.Backoff != 1 || r.Timeout != 300 {
+			t.Errorf("Test %d FAIL: Unexpected rule: %+v", i, r)
+		}
+	}
+	if _, err := LoadRFPollPolicyFile(filepath.Join(dir, "missing")); err == nil {
+		t.Errorf("FAIL: Expected an error for a missing file")
+	}
+}
+
+func TestRFPollerAddCancel(t *testing.T) {
+	p := NewRFPoller(s, defaultRFPollPolicy())
+	results.GetCompEndpointIDs.Funcs.getID = GetCompEpIDsGenGetID
+	results.GetCompEndpointIDs.Funcs.returnIDs = GetCompEpIDsGenReturnIDs(nil)
+
+	results.InsertJob.Input.j = nil
+	results.InsertJob.Return.id = "job1"
+	results.InsertJob.Return.err = nil
+	results.DeleteJob.Input.jobId = ""
+
+	start := time.Now()
+	if err := p.add("x0c0s0b0n0", "Standby", -1); err != nil {
+		t.Fatalf("FAIL: Unexpected error: %s", err)
+	}
+	j := results.InsertJob.Input.j
+	if j == nil || j.Type != sm.JobTypeSRFP || j.KeepAlive != rfPollKeepAliveDefault ||
+		j.Lifetime != rfPollKeepAliveDefault+10 {
+		t.Fatalf("FAIL: Unexpected job inserted: %+v", j)
+	}
+	jobs := p.Jobs()
+	if len(jobs) != 1 || jobs[0].Id != "job1" {
+		t.Fatalf("FAIL: Expected job1; Received %+v", jobs)
+	}
+	data := jobs[0].Data.(*sm.SrfpJobData)
+	next, _ := time.Parse(time.RFC3339, data.NextPoll)
+	if data.CompId != "x0c0s0b0n0" || data.State != "Standby" ||
+		data.Delay != 30 || data.Poll != 10 ||
+		next.Before(start.Add(29*time.Second)) {
+		t.Errorf("FAIL: Unexpected job data: %+v", data)
+	}
+
+	// Already polled for this state
+	results.InsertJob.Input.j = nil
+	p.add("x0c0s0b0n0", "Standby", -1)
+	if results.InsertJob.Input.j != nil {
+		t.Errorf("FAIL: Job inserted again")
+	}
+
+	// Not a polled state: stops polling.
+	p.add("x0c0s0b0n0", "Ready", -1)
+	if len(p.Jobs()) != 0 || results.DeleteJob.Input.jobId != "job1" {
+		t.Errorf("FAIL: Polling not stopped")
+	}
+
+	// Not a polled type
+	p.add("x0c0s0b0", "Standby", -1)
+	if len(p.Jobs()) != 0 {
+		t.Errorf("FAIL: Polling started for a NodeBMC")
+	}
+
+	// No delay, e.g. for orphaned jobs
+	p.add("x0c0s0b0n0", "Standby", 0)
+	results.DeleteJob.Input.jobId = ""
+	if batches, _ := p.due(time.Now()); len(batches) != 1 {
+		t.Errorf("FAIL: Expected a due poll")
+	}
+	if !p.cancel("x0c0s0b0n0") || results.DeleteJob.Input.jobId != "job1" {
+		t.Errorf("FAIL: Polling not cancelled")
+	}
+	if p.cancel("x0c0s0b0n0") {
+		t.Errorf("FAIL: Polling cancelled twice")
+	}
+}
+
+// Lets another add run while one is between writing its job and taking
+// the lock again.
+type rfPollRaceDB struct {
+	hmsds.HMSDB
+	inserted int
+	deleted  []string
+	onInsert func()
+}
+
+func (d *rfPollRaceDB) InsertJob(j *sm.Job) (string, error) {
+	d.inserted++
+	id := fmt.Sprintf("job%d", d.inserted)
+	if f := d.onInsert; f != nil {
+		d.onInsert = nil
+		f()
+	}
+	return id, nil
+}
+
+func (d *rfPollRaceDB) DeleteJob(jobId string) (bool, error) {
+	d.deleted = append(d.deleted, jobId)
+	return true, nil
+}
+
+func TestRFPollerAddRace(t *testing.T) {
+	policy := defaultRFPollPolicy()
+	policy.Rules[1].Disabled = false // Poll Off too
+	p := NewRFPoller(s, policy)
+	results.GetCompEndpointIDs.Funcs.getID = GetCompEpIDsGenGetID
+	results.GetCompEndpointIDs.Funcs.returnIDs = GetCompEpIDsGenReturnIDs(nil)
+	db := &rfPollRaceDB{HMSDB: s.db}
+	saved := s.db
+	s.db = db
+	defer func() { s.db = saved }()
+
+	// The add that gets the lock first wins, and the other's job is
+	// removed.
+	db.onInsert = func() { p.add("x0c0s0b0n0", "Standby", -1) }
+	if err := p.add("x0c0s0b0n0", "Standby", -1); err != nil {
+		t.Fatalf("FAIL: Unexpected error: %s", err)
+	}
+	jobs := p.Jobs()
+	if len(jobs) != 1 || jobs[0].Id != "job2" {
+		t.Errorf("FAIL: Expected job2; Received %+v", jobs)
+	}
+	if len(db.deleted) != 1 || db.deleted[0] != "job1" {
+		t.Errorf("FAIL: Expected job1 to be deleted; Received %v", db.deleted)
+	}
+
+	// For different states, the add that takes the lock last wins, and
+	// the job it replaces is removed.
+	db.deleted = nil
+	db.onInsert = func() { p.add("x0c0s0b0n0", "Ready", -1) }
+	p.add("x0c0s0b0n0", "Off", -1)
+	if jobs := p.Jobs(); len(jobs) != 1 || jobs[0].Id != "job3" {
+		t.Errorf("FAIL: Expected job3; Received %+v", jobs)
+	}
+	if len(db.deleted) != 1 || db.deleted[0] != "job2" {
+		t.Errorf("FAIL: Expected job2 to be deleted; Received %v", db.deleted)
+	}
+}
+
+func TestRFPollerDue(t *testing.T) {
+	p := NewRFPoller(s, defaultRFPollPolicy())
+	rule := p.policy.Rules[0]
+	now := time.Now()
+	add := func(id, bmc string, next time.Duration) *rfPollTarget {
+		t := &rfPollTarget{
+			job:    &sm.Job{JobData: sm.JobData{Id: "job-" + id}},
+			rule:   rule,
+			compId: id,
+			bmc:    bmc,
+			start:  now,
+			next:   now.Add(next),
+		}
+		p.targets[id] = t
+		return t
+	}
+	add("x0c0s0b0n1", "x0c0s0b0", 0)
+	add("x0c0s0b0n0", "x0c0s0b0", -time.Second)
+	add("x0c0s1b0n0", "x0c0s1b0", 5*time.Second)
+	add("x0c0s2b0n0", "x0c0s2b0", 0)
+	add("x0c0s3b0n0", "", 0)
+	busy := add("x0c0s4b0n0", "x0c0s4b0", 0)
+	busy.busy = true
+	p.bmcs["x0c0s2b0"] = &rfPollBMC{failures: 1, hold: now.Add(3 * time.Second)}
+
+	batches, next := p.due(now)
+	ids := [][]string{}
+	for _, batch := range batches {
+		b := []string{}
+		for _, t := range batch {
+			b = append(b, t.compId)
+		}
+		ids = append(ids, b)
+	}
+	expected := [][]string{{"x0c0s0b0n0", "x0c0s0b0n1"}, {"x0c0s3b0n0"}}
+	if len(ids) != len(expected) ||
+		strings.Join(ids[0], ",") != strings.Join(expected[0], ",") ||
+		strings.Join(ids[1], ",") != strings.Join(expected[1], ",") {
+		t.Errorf("FAIL: Expected batches %v; Received %v", expected, ids)
+	}
+	// Held BMC is next
+	if !next.Equal(now.Add(3 * time.Second)) {
+		t.Errorf("FAIL: Expected next at +3s; Received %s", next.Sub(now))
+	}
+	// Busy targets aren't handed out again.
+	if batches, _ := p.due(now); len(batches) != 0 {
+		t.Errorf("FAIL: Busy targets handed out again")
+	}
+
+	// Timed out
+	timed := *rule
+	timed.Timeout = 60
+	tt := add("x0c0s5b0n0", "x0c0s5b0", time.Hour)
+	tt.rule = &timed
+	tt.start = now.Add(-time.Minute)
+	results.DeleteJob.Input.jobId = ""
+	p.due(now)
+	if _, ok := p.targets["x0c0s5b0n0"]; ok ||
+		results.DeleteJob.Input.jobId != "job-x0c0s5b0n0" {
+		t.Errorf("FAIL: Timed out target not removed")
+	}
+}
+
+func TestRFPollerPoll(t *testing.T) {
+	powerStates := map[string]string{
+		"/redfish/v1/Systems/Node0": "On",
+		"/redfish/v1/Systems/Node1": "Off",
+	}
+	handler := func(w http.ResponseWriter, req *http.Request) {
+		defer base.DrainAndCloseRequestBody(req)
+		ps, ok := powerStates[req.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]string{"PowerState": ps})
+	}
+	server := httptest.NewTLSServer(http.HandlerFunc(handler))
+	defer server.Close()
+	u, _ := url.Parse(server.URL)
+
+	ceps := []*sm.ComponentEndpoint{}
+	for i, id := range []string{"x0c0s0b0n0", "x0c0s0b0n1"} {
+		ceps = append(ceps, &sm.ComponentEndpoint{
+			ComponentDescription: rf.ComponentDescription{
+				ID:           id,
+				Type:         "Node",
+				RedfishType:  "ComputerSystem",
+				OdataID:      "/redfish/v1/Systems/Node" + string(rune('0'+i)),
+				RfEndpointID: "x0c0s0b0",
+			},
+			RfEndpointFQDN: u.Host,
+		})
+	}
+	readVault := s.readVault
+	smapCompEP := s.smapCompEP
+	defer func() {
+		s.readVault = readVault
+		s.smapCompEP = smapCompEP
+		s.rfPoller = nil
+	}()
+	s.readVault = false
+	s.smapCompEP = NewSyncMap(ComponentEndpointSMap(s))
+	results.GetCompEndpointsAll.Return.entries = ceps
+	results.GetCompEndpointsAll.Return.err = nil
+	results.GetCompEndpointIDs.Funcs.getID = GetCompEpIDsGenGetID
+	results.GetCompEndpointIDs.Funcs.returnIDs = GetCompEpIDsGenReturnIDs(ceps)
+	results.GetRFEndpointByID.Return.entry = &sm.RedfishEndpoint{
+		RedfishEPDescription: rf.RedfishEPDescription{
+			ID:       "x0c0s0b0",
+			User:     "root",
+			Password: "********",
+		},
+	}
+	results.GetRFEndpointByID.Return.err = nil
+
+	p := NewRFPoller(s, defaultRFPollPolicy())
+	s.rfPoller = p
+	results.InsertJob.Return.id = "job0"
+	p.add("x0c0s0b0n0", "Standby", 0)
+	results.InsertJob.Return.id = "job1"
+	p.add("x0c0s0b0n1", "Standby", 0)
+
+	// n1 is off: gets its state set.  n0 keeps going, less often.
+	results.UpdateCompStates.Input.ids = []string{}
+	results.UpdateCompStates.Input.state = ""
+	results.UpdateCompStates.Return.affectedIds = []string{"x0c0s0b0n1"}
+	results.UpdateCompStates.Return.err = nil
+	results.UpdateJob.Input.jobId = ""
+	results.DeleteJob.Input.jobId = ""
+	batches, _ := p.due(time.Now())
+	if len(batches) != 1 || len(batches[0]) != 2 {
+		t.Fatalf("FAIL: Expected one batch of two; Received %v", batches)
+	}
+	p.poll(batches[0])
+	if len(results.UpdateCompStates.Input.ids) != 1 ||
+		results.UpdateCompStates.Input.ids[0] != "x0c0s0b0n1" ||
+		results.UpdateCompStates.Input.state != "Off" {
+		t.Errorf("FAIL: Expected x0c0s0b0n1 set Off; Received %v %s",
+			results.UpdateCompStates.Input.ids, results.UpdateCompStates.Input.state)
+	}
+	if results.DeleteJob.Input.jobId != "job1" {
+		t.Errorf("FAIL: Expected job1 deleted; Received '%s'",
+			results.DeleteJob.Input.jobId)
+	}
+	if results.UpdateJob.Input.jobId != "job0" ||
+		results.UpdateJob.Input.status != sm.JobInProgress {
+		t.Errorf("FAIL: Expected job0 InProgress; Received %s %s",
+			results.UpdateJob.Input.jobId, results.UpdateJob.Input.status)
+	}
+	jobs := p.Jobs()
+	if len(jobs) != 1 {
+		t.Fatalf("FAIL: Expected 1 job; Received %d", len(jobs))
+	}
+	data := jobs[0].Data.(*sm.SrfpJobData)
+	if data.CompId != "x0c0s0b0n0" || data.RfEndpointID != "x0c0s0b0" ||
+		data.Polls != 1 || data.Poll != 15 || jobs[0].Status != sm.JobInProgress {
+		t.Errorf("FAIL: Unexpected job: %+v %+v", jobs[0], data)
+	}
+
+	// Polls fail: the job shows the error and the BMC backs off.
+	delete(powerStates, "/redfish/v1/Systems/Node0")
+	results.UpdateJob.Input.jobId = ""
+	batches, _ = p.due(time.Now().Add(time.Minute))
+	if len(batches) != 1 {
+		t.Fatalf("FAIL: Expected one batch; Received %v", batches)
+	}
+	p.poll(batches[0])
+	data = p.Jobs()[0].Data.(*sm.SrfpJobData)
+	if data.Failures != 1 || data.LastError == "" ||
+		results.UpdateJob.Input.status != sm.JobError {
+		t.Errorf("FAIL: Expected an error; Received %+v", data)
+	}
+	if b, ok := p.bmcs["x0c0s0b0"]; !ok || b.failures != 1 {
+		t.Errorf("FAIL: Expected BMC backoff")
+	}
+}
+
+func TestRFPollerKeepAlive(t *testing.T) {
+	p := NewRFPoller(s, defaultRFPollPolicy())
+	for i, id := range []string{"x0c0s0b0n0", "x0c0s0b0n1", "x0c0s1b0n0"} {
+		p.targets[id] = &rfPollTarget{
+			job:    &sm.Job{JobData: sm.JobData{Id: "job" + string(rune('0'+i))}},
+			rule:   p.policy.Rules[0],
+			compId: id,
+		}
+	}
+	results.GetComponentsFilter.Return.ids = []*base.Component{
+		{ID: "x0c0s0b0n0", State: "Standby"},
+		{ID: "x0c0s0b0n1", State: "On"},
+	}
+	results.GetComponentsFilter.Return.err = nil
+	results.UpdateJobs.Input.jobIds = nil
+	results.UpdateJobs.Return.num = 1
+	results.UpdateJobs.Return.err = nil
+
+	p.doKeepAlive()
+	jobs := p.Jobs()
+	if len(jobs) != 1 || jobs[0].Id != "job0" {
+		t.Errorf("FAIL: Expected only job0 left; Received %d jobs", len(jobs))
+	}
+	if len(results.UpdateJobs.Input.jobIds) != 1 ||
+		results.UpdateJobs.Input.jobIds[0] != "job0" {
+		t.Errorf("FAIL: Expected job0 kept alive; Received %v",
+			results.UpdateJobs.Input.jobIds)
+	}
+	if ids := results.GetComponentsFilter.Input.compFilter.ID; len(ids) != 3 {
+		t.Errorf("FAIL: Expected a lookup of 3 components; Received %v", ids)
+	}
+
+	// job0 was cancelled through another instance.
+	results.UpdateJobs.Return.num = 0
+	results.GetJobs.Return.js = []*sm.Job{}
+	results.GetJobs.Return.err = nil
+	results.DeleteJob.Input.jobId = ""
+	p.doKeepAlive()
+	if len(p.Jobs()) != 0 {
+		t.Errorf("FAIL: Cancelled job still polled")
+	}
+	if f := results.GetJobs.Input.f; f == nil || len(f.ID) != 1 || f.ID[0] != "job0" {
+		t.Errorf("FAIL: Expected a lookup of job0; Received %+v", f)
+	}
+	if results.DeleteJob.Input.jobId != "" {
+		t.Errorf("FAIL: Deleted job deleted again")
+	}
+}
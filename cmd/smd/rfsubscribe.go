@@ -0,0 +1,328 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	base "github.com/Cray-HPE/hms-base/v2"
+	"github.com/Cray-HPE/hms-smd/v2/internal/hmsds"
+	rf "github.com/Cray-HPE/hms-smd/v2/pkg/redfish"
+	"github.com/Cray-HPE/hms-xname/xnametypes"
+)
+
+/////////////////////////////////////////////////////////////////////////////
+// Built-in Redfish event subscriptions.
+//
+// For installations without a separate event collector and message bus,
+// smd can subscribe each discovered BMC to its own HTTPS listener and feed
+// the events it receives into the same pipeline as those read from the
+// message bus.  Enabled by setting SMD_RF_EVENT_DESTINATION to the URL
+// BMCs should POST events to, e.g. https://10.254.1.5:27780/events
+/////////////////////////////////////////////////////////////////////////////
+
+const (
+	rfEventListenDefault       = ":27780"
+	rfEventListenPath          = "/events"
+	rfSubscribeIntervalDefault = 10 // Minutes
+	rfSubscribeContext         = "smd"
+	rfEventMaxBody             = 1024 * 1024
+)
+
+// Start the HTTPS listener BMCs post events to.  Does not return unless
+// the listener fails.
+func (s *SmD) StartRFEventListener() {
+	if s.rfEventDest == "" {
+		return
+	}
+	if err := s.checkCertPath(s.tlsCert, s.tlsKey); err != nil {
+		s.LogAlways("ERROR: No TLS cert for the Redfish event listener: %s", err)
+		return
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc(rfEventListenPath, s.doRFEventPost)
+	s.LogAlways("Listening for Redfish events on %s%s for %s",
+		s.rfEventListen, rfEventListenPath, s.rfEventDest)
+	err := http.ListenAndServeTLS(s.rfEventListen, s.tlsCert, s.tlsKey, mux)
+	s.LogAlways("ERROR: Redfish event listener: %s", err)
+}
+
+// Take an event POSTed by a BMC and queue it for processing, as if it
+// had been read from the message bus.
+func (s *SmD) doRFEventPost(w http.ResponseWriter, r *http.Request) {
+	defer base.DrainAndCloseRequestBody(r)
+
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, rfEventMaxBody))
+	if err != nil {
+		s.Log(LOG_INFO, "doRFEventPost: Failed reading event from %s: %s",
+			r.RemoteAddr, err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	} else if len(body) == 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if err := s.checkRFEventSender(r, string(body)); err != nil {
+		s.LogAlways("WARNING: Rejected Redfish event from %s: %s",
+			r.RemoteAddr, err)
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	if !s.queueRFEvent(string(body)) {
+		// The BMC retries according to its DeliveryRetryPolicy.
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Resolves BMC hostnames for checkRFEventSender, replaced by tests.
+var rfEventLookupHost = net.LookupHost
+
+// Make sure an event POSTed to us came from the BMC(s) its Context names,
+// i.e. that the request came from an address the RedfishEndpoint's FQDN
+// resolves to, or its IPAddress.  Otherwise anyone who can reach the
+// listener could change the state of any component.
+func (s *SmD) checkRFEventSender(r *http.Request, payload string) error {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	sender := net.ParseIP(host)
+	if sender == nil {
+		return ErrSmMsgRFEventSender
+	}
+	ids := rfEventIDs(payload)
+	if len(ids) == 0 {
+		return ErrSmMsgNoIDCtx
+	}
+	for _, id := range ids {
+		ep, err := s.db.GetRFEndpointByID(id)
+		if err != nil {
+			return err
+		} else if ep == nil {
+			return ErrSmMsgRFEventSender
+		}
+		if !rfEndpointHasAddr(ep.FQDN, ep.IPAddr, sender) {
+			return ErrSmMsgRFEventSender
+		}
+	}
+	return nil
+}
+
+// The normalized xname of each controller named in the event's Contexts,
+// found the same way as when the event is processed.
+func rfEventIDs(payload string) []string {
+	var e rf.Event
+	if err := json.Unmarshal([]byte(payload), &e); err != nil {
+		return nil
+	}
+	recCtxs := []string{""}
+	if len(e.Events) > 0 {
+		recCtxs = recCtxs[:0]
+		for _, erec := range e.Events {
+			recCtxs = append(recCtxs, erec.Context)
+		}
+	}
+	seen := make(map[string]bool)
+	ids := []string{}
+	for _, rCtx := range recCtxs {
+		id, _ := GetEventIDAndLabels(e.Context, rCtx)
+		if id == "" {
+			return nil
+		}
+		id = xnametypes.NormalizeHMSCompID(id)
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// True if addr is ipAddr or one of the addresses fqdn resolves to.
+func rfEndpointHasAddr(fqdn, ipAddr string, addr net.IP) bool {
+	if ip := net.ParseIP(ipAddr); ip != nil && ip.Equal(addr) {
+		return true
+	}
+	if h, _, err := net.SplitHostPort(fqdn); err == nil {
+		fqdn = h
+	}
+	fqdn = strings.Trim(fqdn, "[]")
+	if fqdn == "" {
+		return false
+	}
+	if ip := net.ParseIP(fqdn); ip != nil {
+		return ip.Equal(addr)
+	}
+	resolved, err := rfEventLookupHost(fqdn)
+	if err != nil {
+		return false
+	}
+	for _, a := range resolved {
+		if ip := net.ParseIP(a); ip != nil && ip.Equal(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// The Context smd subscribes with, so events can be tied to the BMC and
+// our own subscriptions can be told apart from others.
+func rfSubscribeContextFor(epID string) string {
+	return epID + ":" + rfSubscribeContext
+}
+
+// Make sure the BMC has exactly one subscription sending events to us,
+// creating it if it is missing, e.g. after a BMC reset, and removing our
+// stale ones, e.g. for an old destination, and duplicates.  Subscriptions
+// made by others are left alone.
+func (s *SmD) doRFSubscribe(epID string, es *rf.EpEventService) error {
+	if s.rfEventDest == "" || es == nil {
+		return nil
+	}
+	subs, err := es.GetSubscriptions()
+	if err != nil {
+		return err
+	}
+	context := rfSubscribeContextFor(epID)
+	found := false
+	for _, sub := range subs {
+		if sub.Destination == s.rfEventDest && sub.Context == context && !found {
+			found = true
+			continue
+		}
+		if sub.Destination == s.rfEventDest ||
+			strings.HasSuffix(sub.Context, ":"+rfSubscribeContext) {
+			s.LogAlways("Removing stale event subscription %s on %s for %s",
+				sub.Oid, epID, sub.Destination)
+			if err := es.Unsubscribe(sub.Oid); err != nil {
+				s.LogAlways("WARNING: Removing event subscription %s on %s: %s",
+					sub.Oid, epID, err)
+			}
+		}
+	}
+	if found {
+		return nil
+	}
+	// Everything but metric reports, which have their own pipeline.
+	eventTypes := []string{}
+	for _, et := range es.EventServiceRF.EventTypesForSubscription {
+		if et != "MetricReport" {
+			eventTypes = append(eventTypes, et)
+		}
+	}
+	oid, err := es.Subscribe(s.rfEventDest, context, eventTypes)
+	if err != nil {
+		return err
+	}
+	s.LogAlways("Subscribed %s to events from %s at %s", s.rfEventDest, epID, oid)
+	return nil
+}
+
+// Subscribe a freshly discovered endpoint to our listener.
+func (s *SmD) rfSubscribeDiscovered(rfEP *rf.RedfishEP) {
+	if s.rfEventDest == "" || rfEP.EventService == nil ||
+		rfEP.DiscInfo.LastStatus != rf.DiscoverOK {
+		return
+	}
+	if err := s.doRFSubscribe(rfEP.ID, rfEP.EventService); err != nil {
+		s.LogAlways("WARNING: Failed to subscribe to events from %s: %s",
+			rfEP.ID, err)
+	}
+}
+
+// Periodically check the subscriptions on every discovered endpoint so
+// they are restored after a BMC reset or factory reset.  Each instance of
+// HSM checks the endpoints it owns.
+func (s *SmD) RFSubscriptionSync() {
+	if s.rfEventDest == "" {
+		return
+	}
+	go func() {
+		for {
+			time.Sleep(time.Duration(s.rfSubInterval) * time.Minute)
+			s.doRFSubscriptionSync()
+		}
+	}()
+}
+
+// Check the subscriptions of every enabled, discovered endpoint this
+// instance owns once.
+func (s *SmD) doRFSubscriptionSync() {
+	reps, err := s.db.GetRFEndpointsFilter(&hmsds.RedfishEPFilter{
+		LastStatus: []string{rf.DiscoverOK},
+	})
+	if err != nil {
+		s.LogAlways("RFSubscriptionSync: Failed to get RedfishEndpoints: %s", err)
+		return
+	}
+	seps, err := s.db.GetServiceEndpointsFilter(&hmsds.ServiceEPFilter{
+		Service: []string{rf.EventServiceType},
+	})
+	if err != nil {
+		s.LogAlways("RFSubscriptionSync: Failed to get EventServices: %s", err)
+		return
+	}
+	esOIDs := make(map[string]string, len(seps))
+	for _, sep := range seps {
+		esOIDs[sep.RfEndpointID] = sep.OdataID
+	}
+	for _, rep := range reps {
+		oid, ok := esOIDs[rep.ID]
+		if !ok || !rep.Enabled || !s.ownsEndpoint(rep.ID) {
+			continue
+		}
+		ep, err := rf.NewRedfishEp(&rep.RedfishEPDescription)
+		if err != nil {
+			continue
+		}
+		if s.readVault {
+			cred, err := s.ccs.GetCompCred(rep.ID)
+			if err != nil {
+				s.Log(LOG_INFO, "RFSubscriptionSync: No credentials for %s: %s",
+					rep.ID, err)
+				continue
+			}
+			ep.User = cred.Username
+			ep.Password = cred.Password
+		}
+		es, err := ep.GetEventService(oid)
+		if err == nil {
+			err = s.doRFSubscribe(rep.ID, es)
+		}
+		if err != nil {
+			s.Log(LOG_INFO, "RFSubscriptionSync: %s: %s", rep.ID, err)
+		}
+	}
+}
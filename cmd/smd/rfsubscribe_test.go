@@ -0,0 +1,328 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	base "github.com/Cray-HPE/hms-base/v2"
+	rf "github.com/Cray-HPE/hms-smd/v2/pkg/redfish"
+	st "github.com/Cray-HPE/hms-smd/v2/pkg/sharedtest"
+	"github.com/Cray-HPE/hms-smd/v2/pkg/sm"
+)
+
+func TestDoRFEventPost(t *testing.T) {
+	wps := s.wpRFEvent
+	// Not run, so queued events stay put.
+	wp := base.NewWorkerPool(1, 2)
+	s.wpRFEvent = []*base.WorkerPool{wp}
+	lookup := rfEventLookupHost
+	rfEventLookupHost = func(host string) ([]string, error) {
+		switch host {
+		case "x1c4b0.local":
+			return []string{"192.0.2.1"}, nil
+		case "x1c5b0.local":
+			return []string{"192.0.2.2", "2001:db8::2"}, nil
+		}
+		return nil, &net.DNSError{Err: "no such host", Name: host}
+	}
+	defer func() {
+		s.wpRFEvent = wps
+		rfEventLookupHost = lookup
+		results.GetRFEndpointByID.Return.entry = nil
+	}()
+
+	ep := func(fqdn, ipAddr string) *sm.RedfishEndpoint {
+		return &sm.RedfishEndpoint{
+			RedfishEPDescription: rf.RedfishEPDescription{
+				ID:     "x1c4b0",
+				FQDN:   fqdn,
+				IPAddr: ipAddr,
+			},
+		}
+	}
+	onEvent := st.GenEvent(st.EventCrayOnOKChassis, st.EpID("x1c4b0:smd"))
+	offEvent := st.GenEvent(st.EventCrayOffOKChassis, st.EpID("x1c4b0:smd"))
+	tests := []struct {
+		method       string
+		body         string
+		remoteAddr   string
+		ep           *sm.RedfishEndpoint
+		expectedCode int
+		expectedJobs int
+	}{
+		{"POST", onEvent, "192.0.2.1:1234", ep("x1c4b0.local", ""),
+			http.StatusNoContent, 1},
+		{"POST", "", "192.0.2.1:1234", ep("x1c4b0.local", ""),
+			http.StatusBadRequest, 1},
+		{"GET", "", "192.0.2.1:1234", ep("x1c4b0.local", ""),
+			http.StatusMethodNotAllowed, 1},
+		// Forged, from somewhere other than the BMC.
+		{"POST", offEvent, "198.51.100.7:1234", ep("x1c4b0.local", ""),
+			http.StatusForbidden, 1},
+		// From another BMC, claiming to be x1c4b0.
+		{"POST", offEvent, "192.0.2.2:1234", ep("x1c4b0.local", ""),
+			http.StatusForbidden, 1},
+		{"POST", offEvent, "[2001:db8::2]:1234", ep("x1c4b0.local", ""),
+			http.StatusForbidden, 1},
+		// BMC not known to us.
+		{"POST", offEvent, "192.0.2.1:1234", nil,
+			http.StatusForbidden, 1},
+		{"POST", offEvent, "192.0.2.1:1234", ep("unknown.local", ""),
+			http.StatusForbidden, 1},
+		// No xname in the Context.
+		{"POST", st.GenEvent(st.EventCrayOffOKChassis, st.EpID("smd")),
+			"192.0.2.1:1234", ep("x1c4b0.local", ""), http.StatusForbidden, 1},
+		// Matched on the RedfishEndpoint's IPAddress or an FQDN that is an
+		// IP address.
+		{"POST", offEvent, "192.0.2.1:1234", ep("unknown.local", "192.0.2.1"),
+			http.StatusNoContent, 2},
+		// Queue full, so the BMC should retry.
+		{"POST", onEvent, "[2001:db8::9]:1234", ep("[2001:db8::9]:443", ""),
+			http.StatusServiceUnavailable, 2},
+	}
+	for i, test := range tests {
+		results.GetRFEndpointByID.Input.id = ""
+		results.GetRFEndpointByID.Return.entry = test.ep
+		req := httptest.NewRequest(test.method, rfEventListenPath,
+			strings.NewReader(test.body))
+		req.RemoteAddr = test.remoteAddr
+		w := httptest.NewRecorder()
+		s.doRFEventPost(w, req)
+		if w.Code != test.expectedCode {
+			t.Errorf("Test %d FAIL: Expected code %d; Received %d",
+				i, test.expectedCode, w.Code)
+		}
+		if len(wp.JobQueue) != test.expectedJobs {
+			t.Errorf("Test %d FAIL: Expected %d queued events; Found %d",
+				i, test.expectedJobs, len(wp.JobQueue))
+		}
+		if (w.Code == http.StatusNoContent ||
+			w.Code == http.StatusServiceUnavailable) &&
+			results.GetRFEndpointByID.Input.id != "x1c4b0" {
+			t.Errorf("Test %d FAIL: Expected sender checked against "+
+				"x1c4b0; Received '%s'", i, results.GetRFEndpointByID.Input.id)
+		}
+	}
+}
+
+// Mock BMC EventService keeping the subscriptions in subs, by URI.
+type mockRFSubscriptions struct {
+	subs map[string]map[string]interface{}
+	next int
+	lock sync.Mutex
+}
+
+const testPathRFEventSubs = "/redfish/v1/EventService/Subscriptions"
+
+func (m *mockRFSubscriptions) add(sub map[string]interface{}) string {
+	m.next++
+	oid := testPathRFEventSubs + "/" + strconv.Itoa(m.next)
+	sub["@odata.id"] = oid
+	m.subs[oid] = sub
+	return oid
+}
+
+func (m *mockRFSubscriptions) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	defer base.DrainAndCloseRequestBody(req)
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	path := req.URL.Path
+	switch {
+	case req.Method == "GET" && path == "/redfish/v1/EventService":
+		w.Write([]byte(`{
+			"@odata.id": "/redfish/v1/EventService",
+			"EventTypesForSubscription": ["Alert", "StatusChange", "MetricReport"],
+			"Subscriptions": {"@odata.id": "` + testPathRFEventSubs + `"}
+		}`))
+		return
+	case req.Method == "GET" && path == testPathRFEventSubs:
+		members := []map[string]string{}
+		for oid := range m.subs {
+			members = append(members, map[string]string{"@odata.id": oid})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"Members": members})
+		return
+	case req.Method == "POST" && path == testPathRFEventSubs:
+		sub := make(map[string]interface{})
+		body, _ := ioutil.ReadAll(req.Body)
+		if json.Unmarshal(body, &sub) != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Location", m.add(sub))
+		w.WriteHeader(http.StatusCreated)
+		return
+	case req.Method == "GET":
+		if sub, ok := m.subs[path]; ok {
+			json.NewEncoder(w).Encode(sub)
+			return
+		}
+	case req.Method == "DELETE":
+		if _, ok := m.subs[path]; ok {
+			delete(m.subs, path)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+	}
+	w.WriteHeader(http.StatusNotFound)
+}
+
+func TestDoRFSubscribe(t *testing.T) {
+	dest := s.rfEventDest
+	s.rfEventDest = "https://smd.local:27780/events"
+	defer func() { s.rfEventDest = dest }()
+
+	m := &mockRFSubscriptions{subs: make(map[string]map[string]interface{})}
+	server := httptest.NewTLSServer(m)
+	defer server.Close()
+	u, _ := url.Parse(server.URL)
+
+	ep, err := rf.NewRedfishEp(&rf.RedfishEPDescription{
+		ID:   "x0c0s1b0",
+		Type: "NodeBMC",
+		FQDN: u.Host,
+	})
+	if err != nil {
+		t.Fatalf("NewRedfishEp failed: %s", err)
+	}
+
+	// Somebody else's, ours from an old address, and ours twice.
+	m.add(map[string]interface{}{
+		"Destination": "https://collector:8443/",
+		"Context":     "x0c0s1b0:telemetry",
+	})
+	m.add(map[string]interface{}{
+		"Destination": "https://old-smd:27780/events",
+		"Context":     "x0c0s1b0:smd",
+	})
+	m.add(map[string]interface{}{
+		"Destination": s.rfEventDest,
+		"Context":     "x0c0s1b0:smd",
+	})
+	m.add(map[string]interface{}{
+		"Destination": s.rfEventDest,
+		"Context":     "x0c0s1b0:smd",
+	})
+
+	check := func(test string) {
+		ours, others := 0, 0
+		for oid, sub := range m.subs {
+			if sub["Destination"] == s.rfEventDest &&
+				sub["Context"] == "x0c0s1b0:smd" {
+				ours++
+				if ets, ok := sub["EventTypes"].([]interface{}); ok {
+					for _, et := range ets {
+						if et == "MetricReport" {
+							t.Errorf("%s FAIL: %s subscribed to MetricReport",
+								test, oid)
+						}
+					}
+				}
+			} else if sub["Destination"] == "https://collector:8443/" {
+				others++
+			} else {
+				t.Errorf("%s FAIL: Stale subscription %s left: %v", test, oid, sub)
+			}
+		}
+		if ours != 1 {
+			t.Errorf("%s FAIL: Expected 1 subscription to smd; Found %d",
+				test, ours)
+		}
+		if others != 1 {
+			t.Errorf("%s FAIL: Other subscription removed", test)
+		}
+	}
+
+	es, err := ep.GetEventService("/redfish/v1/EventService")
+	if err != nil {
+		t.Fatalf("GetEventService failed: %s", err)
+	}
+	if err := s.doRFSubscribe(ep.ID, es); err != nil {
+		t.Errorf("Test 0 FAIL: Unexpected error: %s", err)
+	}
+	check("Test 0")
+
+	// BMC reset, only the subscription from its factory config is left.
+	m.lock.Lock()
+	for oid, sub := range m.subs {
+		if sub["Destination"] != "https://collector:8443/" {
+			delete(m.subs, oid)
+		}
+	}
+	m.lock.Unlock()
+	if err := s.doRFSubscribe(ep.ID, es); err != nil {
+		t.Errorf("Test 1 FAIL: Unexpected error: %s", err)
+	}
+	check("Test 1")
+
+	// Nothing to do.
+	next := m.next
+	if err := s.doRFSubscribe(ep.ID, es); err != nil {
+		t.Errorf("Test 2 FAIL: Unexpected error: %s", err)
+	}
+	check("Test 2")
+	if m.next != next {
+		t.Errorf("Test 2 FAIL: Subscribed again")
+	}
+	// The periodic check restores it after another reset.
+	readVault := s.readVault
+	s.readVault = false
+	defer func() { s.readVault = readVault }()
+	m.lock.Lock()
+	for oid, sub := range m.subs {
+		if sub["Destination"] == s.rfEventDest {
+			delete(m.subs, oid)
+		}
+	}
+	m.lock.Unlock()
+	results.GetRFEndpointsFilter.Return.entries = []*sm.RedfishEndpoint{{
+		RedfishEPDescription: rf.RedfishEPDescription{
+			ID:      "x0c0s1b0",
+			Type:    "NodeBMC",
+			FQDN:    u.Host,
+			Enabled: true,
+		},
+	}}
+	results.GetRFEndpointsFilter.Return.err = nil
+	results.GetServiceEndpointsFilter.Return.entries = []*sm.ServiceEndpoint{{
+		ServiceDescription: rf.ServiceDescription{
+			RfEndpointID: "x0c0s1b0",
+			RedfishType:  rf.EventServiceType,
+			OdataID:      "/redfish/v1/EventService",
+		},
+	}}
+	results.GetServiceEndpointsFilter.Return.err = nil
+	s.doRFSubscriptionSync()
+	check("Test 3")
+}
@@ -43,6 +43,7 @@ func (s *SmD) NewRouter(routes []Route) *mux.Router {
 	for _, route := range routes {
 		var handler http.Handler
 		handler = route.HandlerFunc
+		handler = s.PartScope(handler, route.Name)
 		if s.lgLvl >= LOG_DEBUG ||
 			(!strings.Contains(route.Name, "doReadyGet") &&
 			!strings.Contains(route.Name, "doLivenessGet")) {
@@ -117,6 +118,12 @@ func (s *SmD) generateRoutes() Routes {
 			s.serviceBaseV2 + "/liveness",
 			s.doLivenessGet,
 		},
+		Route{
+			"doClusterGetV2",
+			strings.ToUpper("Get"),
+			s.serviceBaseV2 + "/cluster",
+			s.doClusterGet,
+		},
 		Route{
 			"doValuesGetV2",
 			strings.ToUpper("Get"),
@@ -202,6 +209,12 @@ func (s *SmD) generateRoutes() Routes {
 			s.componentsBaseV2,
 			s.doComponentsPost,
 		},
+		Route{
+			"doCompsPatchV2",
+			"PATCH",
+			s.componentsBaseV2,
+			s.doCompsPatch,
+		},
 		Route{
 			"doComponentsDeleteAllV2",
 			strings.ToUpper("Delete"),
@@ -280,6 +293,42 @@ func (s *SmD) generateRoutes() Routes {
 			s.componentsBaseV2 + "/{xname}/NID",
 			s.doCompNIDPatch,
 		},
+		Route{
+			"doCompBulkLabelsPatchV2",
+			"PATCH",
+			s.componentsBaseV2 + "/BulkLabels",
+			s.doCompBulkLabelsPatch,
+		},
+		Route{
+			"doCompLabelsGetV2",
+			strings.ToUpper("Get"),
+			s.componentsBaseV2 + "/{xname}/Labels",
+			s.doCompLabelsGet,
+		},
+		Route{
+			"doCompLabelsPutV2",
+			strings.ToUpper("Put"),
+			s.componentsBaseV2 + "/{xname}/Labels",
+			s.doCompLabelsPut,
+		},
+		Route{
+			"doCompLabelsPatchV2",
+			"PATCH",
+			s.componentsBaseV2 + "/{xname}/Labels",
+			s.doCompLabelsPatch,
+		},
+		Route{
+			"doCompLabelsDeleteV2",
+			strings.ToUpper("Delete"),
+			s.componentsBaseV2 + "/{xname}/Labels",
+			s.doCompLabelsDelete,
+		},
+		Route{
+			"doCompHistoryGetV2",
+			strings.ToUpper("Get"),
+			s.componentsBaseV2 + "/{xname}/History",
+			s.doCompHistoryGet,
+		},
 		Route{
 			"doComponentByNIDGetV2",
 			strings.ToUpper("Get"),
@@ -292,6 +341,13 @@ func (s *SmD) generateRoutes() Routes {
 			s.componentsBaseV2 + "/ByNID/Query",
 			s.doComponentByNIDQueryPost,
 		},
+		// Component health
+		Route{
+			"doCompHealthGetV2",
+			strings.ToUpper("Get"),
+			s.compHealthBaseV2 + "/{xname}",
+			s.doCompHealthGet,
+		},
 		Route{
 			"doComponentsQueryPostV2",
 			strings.ToUpper("Post"),
@@ -577,6 +633,42 @@ func (s *SmD) generateRoutes() Routes {
 			s.doHWInvByLocationDeleteAll,
 		},
 
+		// Hardware Inventory - Firmware
+		Route{
+			"doHWInvFirmwareGetV2",
+			strings.ToUpper("Get"),
+			s.hwinvFwBaseV2 + "/{xname}",
+			s.doHWInvFirmwareGet,
+		},
+		Route{
+			"doHWInvFirmwareQueryGetV2",
+			strings.ToUpper("Get"),
+			s.hwinvFwBaseV2,
+			s.doHWInvFirmwareQueryGet,
+		},
+
+		// Hardware Inventory - Fans
+		Route{
+			"doHWInvFanGetV2",
+			strings.ToUpper("Get"),
+			s.hwinvFanBaseV2 + "/{xname}",
+			s.doHWInvFanGet,
+		},
+		Route{
+			"doHWInvFanQueryGetV2",
+			strings.ToUpper("Get"),
+			s.hwinvFanBaseV2,
+			s.doHWInvFanQueryGet,
+		},
+
+		// Hardware Inventory - Sensors
+		Route{
+			"doSensorsGetV2",
+			strings.ToUpper("Get"),
+			s.sensorsBaseV2 + "/{xname}",
+			s.doSensorsGet,
+		},
+
 		// RefishEndpoints
 		Route{
 			"doRedfishEndpointGetV2",
@@ -688,6 +780,66 @@ func (s *SmD) generateRoutes() Routes {
 			s.doDeleteSCNSubscription,
 		},
 
+		// Event rules
+		Route{
+			"doEventRulesGetV2",
+			strings.ToUpper("Get"),
+			s.eventRulesBaseV2,
+			s.doEventRulesGet,
+		},
+		Route{
+			"doEventRulePostV2",
+			strings.ToUpper("Post"),
+			s.eventRulesBaseV2,
+			s.doEventRulePost,
+		},
+		Route{
+			"doEventRuleGetV2",
+			strings.ToUpper("Get"),
+			s.eventRulesBaseV2 + "/{name}",
+			s.doEventRuleGet,
+		},
+		Route{
+			"doEventRulePutV2",
+			strings.ToUpper("Put"),
+			s.eventRulesBaseV2 + "/{name}",
+			s.doEventRulePut,
+		},
+		Route{
+			"doEventRuleDeleteV2",
+			strings.ToUpper("Delete"),
+			s.eventRulesBaseV2 + "/{name}",
+			s.doEventRuleDelete,
+		},
+
+		// Redfish event log
+		Route{
+			"doRFEventLogGetV2",
+			strings.ToUpper("Get"),
+			s.rfEventLogBaseV2,
+			s.doRFEventLogGet,
+		},
+
+		// Jobs
+		Route{
+			"doJobsGetV2",
+			strings.ToUpper("Get"),
+			s.jobsBaseV2,
+			s.doJobsGet,
+		},
+		Route{
+			"doJobGetV2",
+			strings.ToUpper("Get"),
+			s.jobsBaseV2 + "/{id}",
+			s.doJobGet,
+		},
+		Route{
+			"doJobDeleteV2",
+			strings.ToUpper("Delete"),
+			s.jobsBaseV2 + "/{id}",
+			s.doJobDelete,
+		},
+
 		// Groups
 		Route{
 			"doGroupsGetV2",
@@ -701,6 +853,12 @@ func (s *SmD) generateRoutes() Routes {
 			s.groupsBaseV2,
 			s.doGroupsPost,
 		},
+		Route{
+			"doGroupsQueryPostV2",
+			strings.ToUpper("Post"),
+			s.groupsBaseV2 + "/Query",
+			s.doGroupsQueryPost,
+		},
 		Route{
 			"doGroupLabelsGetV2",
 			strings.ToUpper("Get"),
@@ -737,12 +895,30 @@ func (s *SmD) generateRoutes() Routes {
 			s.groupsBaseV2 + "/{group_label}/members",
 			s.doGroupMembersPost,
 		},
+		Route{
+			"doGroupMembersPutV2",
+			strings.ToUpper("Put"),
+			s.groupsBaseV2 + "/{group_label}/members",
+			s.doGroupMembersPut,
+		},
 		Route{
 			"doGroupMemberDeleteV2",
 			strings.ToUpper("Delete"),
 			s.groupsBaseV2 + "/{group_label}/members/{xname_id}",
 			s.doGroupMemberDelete,
 		},
+		Route{
+			"doGroupSnapshotPostV2",
+			strings.ToUpper("Post"),
+			s.groupsBaseV2 + "/{group_label}/snapshot",
+			s.doGroupSnapshotPost,
+		},
+		Route{
+			"doGroupHistoryGetV2",
+			strings.ToUpper("Get"),
+			s.groupsBaseV2 + "/{group_label}/history",
+			s.doGroupHistoryGet,
+		},
 
 		// Partitions
 		Route{
@@ -793,12 +969,30 @@ func (s *SmD) generateRoutes() Routes {
 			s.partitionsBaseV2 + "/{partition_name}/members",
 			s.doPartitionMembersPost,
 		},
+		Route{
+			"doPartitionMembersPutV2",
+			strings.ToUpper("Put"),
+			s.partitionsBaseV2 + "/{partition_name}/members",
+			s.doPartitionMembersPut,
+		},
+		Route{
+			"doPartitionMembersMovePostV2",
+			strings.ToUpper("Post"),
+			s.partitionsBaseV2 + "/{partition_name}/members/move",
+			s.doPartitionMembersMovePost,
+		},
 		Route{
 			"doPartitionMemberDeleteV2",
 			strings.ToUpper("Delete"),
 			s.partitionsBaseV2 + "/{partition_name}/members/{xname_id}",
 			s.doPartitionMemberDelete,
 		},
+		Route{
+			"doPartitionHistoryGetV2",
+			strings.ToUpper("Get"),
+			s.partitionsBaseV2 + "/{partition_name}/history",
+			s.doPartitionHistoryGet,
+		},
 
 		// Memberships
 		Route{
@@ -0,0 +1,310 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"encoding/json"
+	"strconv"
+	"sync"
+	"time"
+
+	base "github.com/Cray-HPE/hms-base/v2"
+	rf "github.com/Cray-HPE/hms-smd/v2/pkg/redfish"
+	"github.com/Cray-HPE/hms-smd/v2/pkg/sm"
+)
+
+var ErrSmSensorRateLimited = base.NewHMSError("smd", "too many sensor requests for this Redfish endpoint, try again later")
+
+////////////////////////////////////////////////////////////////////////////
+//
+// Sensor snapshots - On-demand reads of a component's Redfish Sensors and
+// EnvironmentMetrics, cached for a short time.  Reads are serialized per
+// Redfish endpoint and no more than one is made every interval so that
+// polling clients cannot overload a BMC.
+//
+////////////////////////////////////////////////////////////////////////////
+
+// Default age in seconds after which a cached snapshot is re-read.
+const sensorCacheTTLDefault = 30
+
+// Default minimum number of seconds between reads of the same endpoint.
+const sensorBMCIntervalDefault = 5
+
+type SensorCache struct {
+	ttl      time.Duration // 0 means no caching
+	interval time.Duration // 0 means no rate limiting
+
+	lock    sync.Mutex
+	entries map[string]*sensorCacheEntry // By component xname
+	bmcs    map[string]*sensorBMCLimit   // By RedfishEndpoint xname
+}
+
+type sensorCacheEntry struct {
+	snap  *sm.CompSensors
+	taken time.Time
+}
+
+// Held while reading an endpoint, last is when the last read started.
+type sensorBMCLimit struct {
+	sync.Mutex
+	last time.Time
+}
+
+// Create a new SensorCache.  ttl and interval are in seconds.
+func NewSensorCache(ttl, interval int) *SensorCache {
+	sc := &SensorCache{
+		ttl:      time.Duration(ttl) * time.Second,
+		interval: time.Duration(interval) * time.Second,
+		entries:  make(map[string]*sensorCacheEntry),
+		bmcs:     make(map[string]*sensorBMCLimit),
+	}
+	return sc
+}
+
+// Look up the cached snapshot for xname.  Returns a copy with Cached set,
+// or nil if there is none, plus whether it is still younger than the TTL.
+func (sc *SensorCache) get(xname string) (*sm.CompSensors, bool) {
+	sc.lock.Lock()
+	defer sc.lock.Unlock()
+
+	entry, ok := sc.entries[xname]
+	if !ok {
+		return nil, false
+	}
+	snap := *entry.snap
+	snap.Cached = true
+	return &snap, time.Since(entry.taken) < sc.ttl
+}
+
+// Store the snapshot for xname, taken at the given time.
+func (sc *SensorCache) put(xname string, snap *sm.CompSensors, taken time.Time) {
+	if sc.ttl <= 0 {
+		return
+	}
+	sc.lock.Lock()
+	defer sc.lock.Unlock()
+
+	// Drop anything that has expired so the cache does not grow with every
+	// component that was ever asked for.
+	for id, entry := range sc.entries {
+		if time.Since(entry.taken) >= sc.ttl {
+			delete(sc.entries, id)
+		}
+	}
+	sc.entries[xname] = &sensorCacheEntry{snap: snap, taken: taken}
+}
+
+// Get the rate limit for the given RedfishEndpoint, creating it if needed.
+func (sc *SensorCache) bmcLimit(rfEPID string) *sensorBMCLimit {
+	sc.lock.Lock()
+	defer sc.lock.Unlock()
+
+	lim, ok := sc.bmcs[rfEPID]
+	if !ok {
+		lim = new(sensorBMCLimit)
+		sc.bmcs[rfEPID] = lim
+	}
+	return lim
+}
+
+// Get a snapshot of the sensor readings for xname.  A cached snapshot is
+// returned if younger than the TTL, unless refresh is set.  If the
+// endpoint was read too recently, an expired snapshot is returned if there
+// is one.  Otherwise ErrSmSensorRateLimited is returned along with the
+// time to wait.
+func (s *SmD) getCompSensors(xname string, refresh bool) (*sm.CompSensors, time.Duration, error) {
+	sc := s.sensorCache
+	if !refresh {
+		if snap, fresh := sc.get(xname); fresh {
+			return snap, 0, nil
+		}
+	}
+	cep, ep, err := s.getCompEPInfo(xname)
+	if err != nil {
+		return nil, 0, err
+	}
+	lim := sc.bmcLimit(cep.RfEndpointID)
+	lim.Lock()
+	defer lim.Unlock()
+
+	// May have been read by someone else while we waited.
+	snap, fresh := sc.get(xname)
+	if fresh && !refresh {
+		return snap, 0, nil
+	}
+	if wait := sc.interval - time.Since(lim.last); wait > 0 {
+		if snap != nil {
+			return snap, 0, nil
+		}
+		return nil, wait, ErrSmSensorRateLimited
+	}
+	lim.last = time.Now()
+	snap, err = s.readCompSensors(cep, ep)
+	if err != nil {
+		return nil, 0, err
+	}
+	sc.put(xname, snap, lim.last)
+	return snap, 0, nil
+}
+
+// Read the Sensors and EnvironmentMetrics of a component from Redfish.  For
+// a ComputerSystem or Manager, these are found under the Chassis it is
+// linked to.  Individual sensors that cannot be read are skipped.
+func (s *SmD) readCompSensors(
+	cep *sm.ComponentEndpoint,
+	ep *rf.RedfishEP,
+) (*sm.CompSensors, error) {
+	if cep == nil || ep == nil {
+		return nil, ErrSmMsgNoEP
+	}
+	snap := &sm.CompSensors{
+		ID:                cep.ID,
+		Type:              cep.Type,
+		RedfishEndpointID: cep.RfEndpointID,
+		Timestamp:         time.Now().UTC().Format(time.RFC3339),
+		Sensors:           make([]*sm.SensorReading, 0, 1),
+	}
+	seen := make(map[string]bool)
+	uris := []string{cep.OdataID}
+	for i := 0; i < len(uris); i++ {
+		links := new(rf.SensorLinksRF)
+		if err := s.getRFSensorJSON(cep.ID, ep, uris[i], links); err != nil {
+			if i == 0 {
+				return nil, ErrSmMsgRFFail
+			}
+			continue
+		}
+		if i == 0 {
+			chassis := links.Links.Chassis
+			if links.Links.ManagerInChassis.Oid != "" {
+				chassis = append(chassis, links.Links.ManagerInChassis)
+			}
+			for _, c := range chassis {
+				if c.Oid != "" && c.Oid != cep.OdataID {
+					uris = append(uris, c.Oid)
+				}
+			}
+		}
+		// Full sensors first, excerpts of the same sensors are then skipped.
+		if links.Sensors.Oid != "" {
+			coll := new(rf.SensorsCollection)
+			err := s.getRFSensorJSON(cep.ID, ep, links.Sensors.Oid, coll)
+			if err == nil {
+				for _, m := range coll.Members {
+					if m.Oid == "" || seen[m.Oid] {
+						continue
+					}
+					sensor := new(rf.Sensor)
+					if s.getRFSensorJSON(cep.ID, ep, m.Oid, sensor) != nil {
+						continue
+					}
+					if sensor.Oid == "" {
+						sensor.Oid = m.Oid
+					}
+					seen[sensor.Oid] = true
+					snap.Sensors = append(snap.Sensors, sm.NewSensorReading(sensor))
+				}
+			}
+		}
+		if links.EnvironmentMetrics.Oid != "" {
+			em := new(rf.EnvironmentMetrics)
+			err := s.getRFSensorJSON(cep.ID, ep, links.EnvironmentMetrics.Oid, em)
+			if err == nil {
+				for _, sr := range envMetricsReadings(links.EnvironmentMetrics.Oid, em) {
+					if !seen[sr.OdataID] {
+						seen[sr.OdataID] = true
+						snap.Sensors = append(snap.Sensors, sr)
+					}
+				}
+			}
+		}
+	}
+	return snap, nil
+}
+
+// Convert the sensor excerpts in an EnvironmentMetrics resource into
+// SensorReadings.
+func envMetricsReadings(uri string, em *rf.EnvironmentMetrics) []*sm.SensorReading {
+	srs := make([]*sm.SensorReading, 0, 1)
+	add := func(sr *sm.SensorReading) {
+		if sr != nil {
+			srs = append(srs, sr)
+		}
+	}
+	add(sm.NewSensorReadingFromExcerpt(uri, "TemperatureCelsius",
+		"Temperature", "Cel", em.TemperatureCelsius))
+	add(sm.NewSensorReadingFromExcerpt(uri, "DewPointCelsius",
+		"Temperature", "Cel", em.DewPointCelsius))
+	add(sm.NewSensorReadingFromExcerpt(uri, "HumidityPercent",
+		"Humidity", "%", em.HumidityPercent))
+	if em.PowerWatts != nil {
+		pw := &rf.SensorExcerpt{
+			DataSourceUri:      em.PowerWatts.DataSourceUri,
+			Name:               em.PowerWatts.Name,
+			PeakReading:        em.PowerWatts.PeakReading,
+			PhysicalContext:    em.PowerWatts.PhysicalContext,
+			PhysicalSubContext: em.PowerWatts.PhysicalSubContext,
+			Reading:            em.PowerWatts.Reading,
+			ReadingUnits:       em.PowerWatts.ReadingUnits,
+			Status:             em.PowerWatts.Status,
+		}
+		add(sm.NewSensorReadingFromExcerpt(uri, "PowerWatts",
+			"Power", "W", pw))
+	}
+	add(sm.NewSensorReadingFromExcerpt(uri, "EnergykWh",
+		"EnergykWh", "kW.h", em.EnergykWh))
+	for i, fan := range em.FanSpeedsPercent {
+		if fan == nil {
+			continue
+		}
+		sr := sm.NewSensorReadingFromExcerpt(uri,
+			"FanSpeedsPercent/"+strconv.Itoa(i), "Percent", "%",
+			&fan.SensorExcerpt)
+		if fan.SensorExcerpt.Name == "" && fan.DeviceName != "" {
+			sr.Name = fan.DeviceName
+		}
+		add(sr)
+	}
+	return srs
+}
+
+// GET a Redfish URI from ep and decode it into rfData.
+func (s *SmD) getRFSensorJSON(id string, ep *rf.RedfishEP, uri string, rfData interface{}) error {
+	rfJSON, err := ep.GETRelative(uri)
+	if err != nil {
+		s.Log(LOG_INFO, "readCompSensors(%s): redfish call failed: %s: %s",
+			id, uri, err)
+		return err
+	}
+	if err := json.Unmarshal(rfJSON, rfData); err != nil {
+		if rf.IsUnmarshalTypeError(err) {
+			s.Log(LOG_INFO, "readCompSensors(%s): bad field(s) skipped: %s: %s",
+				id, uri, err)
+		} else {
+			s.Log(LOG_INFO, "readCompSensors(%s): json decode failed: %s: %s",
+				id, uri, err)
+			return err
+		}
+	}
+	return nil
+}
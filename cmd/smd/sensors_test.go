@@ -0,0 +1,310 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+
+	base "github.com/Cray-HPE/hms-base/v2"
+	rf "github.com/Cray-HPE/hms-smd/v2/pkg/redfish"
+	"github.com/Cray-HPE/hms-smd/v2/pkg/sm"
+)
+
+//////////////////////////////////////////////////////////////////////////////
+//                         Test Redfish endpoint
+//////////////////////////////////////////////////////////////////////////////
+
+const testPathSensorSystem = "/redfish/v1/Systems/Node0"
+const testPathSensorChassis = "/redfish/v1/Chassis/Node0"
+const testPathSensorSensors = "/redfish/v1/Chassis/Node0/Sensors"
+const testPathSensorInlet = "/redfish/v1/Chassis/Node0/Sensors/InletTemp"
+const testPathSensorPower = "/redfish/v1/Chassis/Node0/Sensors/PowerDraw"
+const testPathSensorEnv = "/redfish/v1/Chassis/Node0/EnvironmentMetrics"
+
+var testSensorPayloads = map[string]string{
+	testPathSensorSystem: `{
+		"@odata.id": "/redfish/v1/Systems/Node0",
+		"Id": "Node0",
+		"Links": {"Chassis": [{"@odata.id": "/redfish/v1/Chassis/Node0"}]}
+	}`,
+	testPathSensorChassis: `{
+		"@odata.id": "/redfish/v1/Chassis/Node0",
+		"Id": "Node0",
+		"Sensors": {"@odata.id": "/redfish/v1/Chassis/Node0/Sensors"},
+		"EnvironmentMetrics": {"@odata.id": "/redfish/v1/Chassis/Node0/EnvironmentMetrics"}
+	}`,
+	testPathSensorSensors: `{
+		"@odata.id": "/redfish/v1/Chassis/Node0/Sensors",
+		"Members": [
+			{"@odata.id": "/redfish/v1/Chassis/Node0/Sensors/InletTemp"},
+			{"@odata.id": "/redfish/v1/Chassis/Node0/Sensors/PowerDraw"}
+		],
+		"Members@odata.count": 2
+	}`,
+	testPathSensorInlet: `{
+		"@odata.id": "/redfish/v1/Chassis/Node0/Sensors/InletTemp",
+		"Id": "InletTemp",
+		"Name": "Inlet Temperature",
+		"ReadingType": "Temperature",
+		"Reading": 24.5,
+		"ReadingUnits": "Cel",
+		"PhysicalContext": "Intake",
+		"Status": {"Health": "OK", "State": "Enabled"}
+	}`,
+	testPathSensorPower: `{
+		"@odata.id": "/redfish/v1/Chassis/Node0/Sensors/PowerDraw",
+		"Id": "PowerDraw",
+		"ReadingType": "Power",
+		"Reading": 412,
+		"ReadingUnits": "W",
+		"Status": {"Health": "Warning", "State": "Enabled"}
+	}`,
+	testPathSensorEnv: `{
+		"@odata.id": "/redfish/v1/Chassis/Node0/EnvironmentMetrics",
+		"Id": "EnvironmentMetrics",
+		"TemperatureCelsius": {
+			"DataSourceUri": "/redfish/v1/Chassis/Node0/Sensors/InletTemp",
+			"Reading": 24.5
+		},
+		"PowerWatts": {"Reading": 415},
+		"FanSpeedsPercent": [
+			{"DeviceName": "Fan 1", "Reading": 40, "SpeedRPM": 6200}
+		]
+	}`,
+}
+
+var testSensorRequests int32
+
+func SensorHandler(w http.ResponseWriter, req *http.Request) {
+	defer base.DrainAndCloseRequestBody(req)
+
+	atomic.AddInt32(&testSensorRequests, 1)
+	payload, ok := testSensorPayloads[req.URL.RequestURI()]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(json.RawMessage(payload))
+}
+
+//////////////////////////////////////////////////////////////////////////////
+//                         Tests
+//////////////////////////////////////////////////////////////////////////////
+
+func TestDoSensorsGet(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(SensorHandler))
+	defer server.Close()
+	u, _ := url.Parse(server.URL)
+
+	cep := &sm.ComponentEndpoint{
+		ComponentDescription: rf.ComponentDescription{
+			ID:           "x1000c0s0b0n0",
+			Type:         "Node",
+			RedfishType:  "ComputerSystem",
+			OdataID:      testPathSensorSystem,
+			RfEndpointID: "x1000c0s0b0",
+		},
+		RfEndpointFQDN:        u.Host,
+		URL:                   u.Host + testPathSensorSystem,
+		ComponentEndpointType: sm.CompEPTypeSystem,
+	}
+	results.GetCompEndpointsAll.Return.entries = []*sm.ComponentEndpoint{cep}
+	results.GetCompEndpointsAll.Return.err = nil
+	results.GetCompEndpointIDs.Funcs.getID = GetCompEpIDsGenGetID
+	results.GetCompEndpointIDs.Funcs.returnIDs = func(id string) ([]string, error) {
+		if id == cep.ID {
+			return []string{id}, nil
+		}
+		return []string{}, nil
+	}
+	results.GetRFEndpointByID.Return.entry = &sm.RedfishEndpoint{
+		RedfishEPDescription: rf.RedfishEPDescription{
+			ID:       "x1000c0s0b0",
+			User:     "root",
+			Password: "********",
+		},
+	}
+	results.GetRFEndpointByID.Return.err = nil
+
+	readVault := s.readVault
+	sensorCache := s.sensorCache
+	s.readVault = false
+	defer func() {
+		s.readVault = readVault
+		s.sensorCache = sensorCache
+	}()
+
+	expected := map[string]*sm.SensorReading{
+		testPathSensorInlet: {
+			OdataID:         testPathSensorInlet,
+			Name:            "Inlet Temperature",
+			Source:          sm.SensorSourceSensors,
+			ReadingType:     "Temperature",
+			Reading:         "24.5",
+			ReadingUnits:    "Cel",
+			PhysicalContext: "Intake",
+			Health:          "OK",
+			State:           "Enabled",
+		},
+		testPathSensorPower: {
+			OdataID:      testPathSensorPower,
+			Name:         "PowerDraw",
+			Source:       sm.SensorSourceSensors,
+			ReadingType:  "Power",
+			Reading:      "412",
+			ReadingUnits: "W",
+			Health:       "Warning",
+			State:        "Enabled",
+		},
+		testPathSensorEnv + "#/PowerWatts": {
+			OdataID:      testPathSensorEnv + "#/PowerWatts",
+			Name:         "PowerWatts",
+			Source:       sm.SensorSourceEnvironmentMetrics,
+			ReadingType:  "Power",
+			Reading:      "415",
+			ReadingUnits: "W",
+		},
+		testPathSensorEnv + "#/FanSpeedsPercent/0": {
+			OdataID:      testPathSensorEnv + "#/FanSpeedsPercent/0",
+			Name:         "Fan 1",
+			Source:       sm.SensorSourceEnvironmentMetrics,
+			ReadingType:  "Percent",
+			Reading:      "40",
+			ReadingUnits: "%",
+		},
+	}
+
+	tests := []struct {
+		reqURI         string
+		newCache       *SensorCache
+		expectedCode   int
+		expectedCached bool
+		expectedReqs   bool // Expect calls to the Redfish endpoint
+	}{{
+		// First read goes to the endpoint.
+		reqURI:         "/hsm/v2/Inventory/Sensors/x1000c0s0b0n0",
+		newCache:       NewSensorCache(30, 5),
+		expectedCode:   http.StatusOK,
+		expectedCached: false,
+		expectedReqs:   true,
+	}, {
+		// Second read comes from the cache.
+		reqURI:         "/hsm/v2/Inventory/Sensors/x1000c0s0b0n0",
+		expectedCode:   http.StatusOK,
+		expectedCached: true,
+		expectedReqs:   false,
+	}, {
+		// Refresh inside the rate limit interval gets the cached copy.
+		reqURI:         "/hsm/v2/Inventory/Sensors/x1000c0s0b0n0?refresh=true",
+		expectedCode:   http.StatusOK,
+		expectedCached: true,
+		expectedReqs:   false,
+	}, {
+		// No caching, first read goes to the endpoint.
+		reqURI:         "/hsm/v2/Inventory/Sensors/x1000c0s0b0n0",
+		newCache:       NewSensorCache(0, 60),
+		expectedCode:   http.StatusOK,
+		expectedCached: false,
+		expectedReqs:   true,
+	}, {
+		// No caching, second read is rate limited.
+		reqURI:       "/hsm/v2/Inventory/Sensors/x1000c0s0b0n0",
+		expectedCode: http.StatusTooManyRequests,
+		expectedReqs: false,
+	}, {
+		reqURI:       "/hsm/v2/Inventory/Sensors/x1000c0s0b0n0?refresh=maybe",
+		expectedCode: http.StatusBadRequest,
+		expectedReqs: false,
+	}, {
+		reqURI:       "/hsm/v2/Inventory/Sensors/foo",
+		expectedCode: http.StatusBadRequest,
+		expectedReqs: false,
+	}, {
+		reqURI:       "/hsm/v2/Inventory/Sensors/x1000c0s1b0n0",
+		newCache:     NewSensorCache(30, 5),
+		expectedCode: http.StatusNotFound,
+		expectedReqs: false,
+	}}
+
+	for i, test := range tests {
+		if test.newCache != nil {
+			s.sensorCache = test.newCache
+		}
+		atomic.StoreInt32(&testSensorRequests, 0)
+		req, err := http.NewRequest("GET", test.reqURI, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if test.expectedCode != w.Code {
+			t.Errorf("Test %v Failed: Expected status code %v; Received %v (%s)",
+				i, test.expectedCode, w.Code, w.Body.String())
+			continue
+		}
+		reqs := atomic.LoadInt32(&testSensorRequests)
+		if test.expectedReqs != (reqs > 0) {
+			t.Errorf("Test %v Failed: Expected Redfish requests %v; Received %d",
+				i, test.expectedReqs, reqs)
+		}
+		if w.Code == http.StatusTooManyRequests && w.Header().Get("Retry-After") == "" {
+			t.Errorf("Test %v Failed: Missing Retry-After header", i)
+		}
+		if w.Code != http.StatusOK {
+			continue
+		}
+		snap := new(sm.CompSensors)
+		if err := json.Unmarshal(w.Body.Bytes(), snap); err != nil {
+			t.Errorf("Test %v Failed: Bad response: %s", i, err)
+			continue
+		}
+		if snap.ID != cep.ID || snap.RedfishEndpointID != cep.RfEndpointID {
+			t.Errorf("Test %v Failed: Unexpected IDs '%s', '%s'",
+				i, snap.ID, snap.RedfishEndpointID)
+		}
+		if test.expectedCached != snap.Cached {
+			t.Errorf("Test %v Failed: Expected Cached %v; Received %v",
+				i, test.expectedCached, snap.Cached)
+		}
+		if len(expected) != len(snap.Sensors) {
+			t.Errorf("Test %v Failed: Expected %d sensors; Received %d",
+				i, len(expected), len(snap.Sensors))
+		}
+		for _, sr := range snap.Sensors {
+			exp, ok := expected[sr.OdataID]
+			if !ok {
+				t.Errorf("Test %v Failed: Unexpected sensor %s", i, sr.OdataID)
+			} else if *exp != *sr {
+				t.Errorf("Test %v Failed: Expected sensor %+v; Received %+v",
+					i, *exp, *sr)
+			}
+		}
+	}
+}
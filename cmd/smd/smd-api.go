@@ -29,6 +29,7 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	base "github.com/Cray-HPE/hms-base/v2"
 	compcreds "github.com/Cray-HPE/hms-compcredentials"
@@ -58,6 +59,11 @@ type NIDQueryIn struct {
 	NIDRanges []string `json:"NIDRanges"`
 }
 
+type GroupExprQueryIn struct {
+	Expression  string `json:"Expression"`
+	MembersOnly bool   `json:"membersonly"`
+}
+
 type FieldFltrIn struct {
 	StateOnly bool `json:"stateonly"`
 	FlagOnly  bool `json:"flagonly"`
@@ -89,6 +95,24 @@ type HwInvQueryIn struct {
 	Format       []string `json:"format"`
 }
 
+type HwInvFirmwareIn struct {
+	ID         []string `json:"id"`
+	RedfishEP  []string `json:"redfish_ep"`
+	Inventory  []string `json:"inventory"`
+	Device     []string `json:"device"`
+	Version    []string `json:"version"`
+	Updateable []string `json:"updateable"`
+}
+
+type HwInvFanIn struct {
+	ID        []string `json:"id"`
+	Parent    []string `json:"parent"`
+	RedfishEP []string `json:"redfish_ep"`
+	Status    []string `json:"status"`
+	Health    []string `json:"health"`
+	FruId     []string `json:"fruid"`
+}
+
 type HwInvHistIn struct {
 	ID        []string `json:"id"`
 	FruId     []string `json:"fruid"`
@@ -97,10 +121,45 @@ type HwInvHistIn struct {
 	EndTime   []string `json:"endtime"`
 }
 
+type RFEventLogIn struct {
+	ID        []string `json:"xname"`
+	Severity  []string `json:"severity"`
+	Registry  []string `json:"registry"`
+	MessageId []string `json:"messageid"`
+	FruId     []string `json:"fruid"`
+	Since     []string `json:"since"`
+	Until     []string `json:"until"`
+	Limit     []string `json:"limit"`
+	Marker    []string `json:"marker"`
+}
+
+type JobsIn struct {
+	Type    []string `json:"type"`
+	Status  []string `json:"status"`
+	ID      []string `json:"xname"`
+	Expired []string `json:"expired"`
+}
+
 type GrpPartFltr struct {
 	Group     []string `json:"group"`
 	Tag       []string `json:"tag"`
 	Partition []string `json:"partition"`
+	AsOf      []string `json:"asof"`
+}
+
+type GroupHistoryIn struct {
+	Member    []string `json:"member"`
+	Op        []string `json:"op"`
+	Requester []string `json:"requester"`
+	Since     []string `json:"since"`
+	Until     []string `json:"until"`
+}
+
+type CompHistoryIn struct {
+	Field  []string `json:"field"`
+	Source []string `json:"source"`
+	Since  []string `json:"since"`
+	Until  []string `json:"until"`
 }
 
 type CompLockFltr struct {
@@ -117,6 +176,7 @@ type CompGetLockFltr struct {
 	Locked              []string `json:"Locked"`
 	Reserved            []string `json:"Reserved"`
 	ReservationDisabled []string `json:"ReservationDisabled"`
+	Labels              []string `json:"Labels"`
 }
 
 type CompEthInterfaceFltr struct {
@@ -284,6 +344,7 @@ func compGetLockFltrToCompLockV2Filter(cglf CompGetLockFltr) (clf sm.CompLockV2F
 	clf.Locked = cglf.Locked
 	clf.Reserved = cglf.Reserved
 	clf.ReservationDisabled = cglf.ReservationDisabled
+	clf.Labels = cglf.Labels
 	return clf
 }
 
@@ -315,6 +376,25 @@ func (s *SmD) doLivenessGet(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// Get the instances of HSM that share the work and which is the leader, as
+// seen by this instance.
+func (s *SmD) doClusterGet(w http.ResponseWriter, r *http.Request) {
+	defer base.DrainAndCloseRequestBody(r)
+
+	var status *sm.ClusterStatus
+	if s.cluster != nil {
+		status = s.cluster.Status()
+	} else {
+		// Not coordinating with other instances, so we do everything.
+		status = &sm.ClusterStatus{
+			ID:      serviceName,
+			Leader:  true,
+			Members: []*sm.ServiceInstance{},
+		}
+	}
+	sendJsonClusterStatusRsp(w, status)
+}
+
 // Get all HMS base enum values
 func (s *SmD) doValuesGet(w http.ResponseWriter, r *http.Request) {
 	defer base.DrainAndCloseRequestBody(r)
@@ -421,6 +501,10 @@ func (s *SmD) doComponentGet(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	xname := xnametypes.NormalizeHMSCompID(vars["xname"])
 
+	if !getPartScope(r).Has(xname) {
+		sendJsonError(w, http.StatusNotFound, "no such xname.")
+		return
+	}
 	cmp, err := s.db.GetComponentByID(xname)
 	if err != nil {
 		s.LogAlways("doComponentGet(): Lookup failure: (%s) %s", xname, err)
@@ -447,6 +531,10 @@ func (s *SmD) doComponentDelete(w http.ResponseWriter, r *http.Request) {
 		sendJsonError(w, http.StatusBadRequest, "invalid xname")
 		return
 	}
+	if !getPartScope(r).Has(xname) {
+		sendJsonError(w, http.StatusNotFound, "no such xname.")
+		return
+	}
 
 	didDelete, err := s.db.DeleteComponentByID(xname)
 	if err != nil {
@@ -498,6 +586,10 @@ func (s *SmD) doComponentsGet(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	fieldFltr := getFieldFilterForm(fieldFltrIn)
+	if err = getPartScope(r).FilterComps(compFilter); err != nil {
+		sendJsonError(w, http.StatusForbidden, err.Error())
+		return
+	}
 	comps.Components, err = s.db.GetComponentsFilter(compFilter, fieldFltr)
 	if err != nil {
 		s.LogAlways("doComponentsGet(): Lookup failure: %s", err)
@@ -534,6 +626,15 @@ func (s *SmD) doComponentsPost(w http.ResponseWriter, r *http.Request) {
 			"couldn't validate components: "+err.Error())
 		return
 	}
+	if ps := getPartScope(r); ps != nil {
+		ids := make([]string, 0, len(compsIn.Components))
+		for _, comp := range compsIn.Components {
+			ids = append(ids, comp.ID)
+		}
+		if sendJsonPartScopeIDsError(w, ps.Outside(ids)) {
+			return
+		}
+	}
 	// Get the nid and role defaults for all node types
 	for _, comp := range compsIn.Components {
 		if comp.Type == xnametypes.Node.String() || comp.Type == xnametypes.VirtualNode.String() {
@@ -554,7 +655,8 @@ func (s *SmD) doComponentsPost(w http.ResponseWriter, r *http.Request) {
 			}
 		}
 	}
-	changeMap, err := s.db.UpsertComponents(compsIn.Components, compsIn.Force)
+	changeMap, err := s.db.UpsertComponents(compsIn.Components, compsIn.Force,
+		getRequester(r))
 	if err != nil {
 		sendJsonDBError(w, "operation 'Post Components' failed: ", "", err)
 		s.LogAlways("failed: %s %s, Err: %s", r.RemoteAddr, string(body), err)
@@ -667,6 +769,10 @@ func (s *SmD) doComponentsQueryPost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	fieldFltr := getFieldFilter(fieldFltrIn)
+	if err = getPartScope(r).FilterComps(compFilter); err != nil {
+		sendJsonError(w, http.StatusForbidden, err.Error())
+		return
+	}
 	comps.Components, err = s.db.GetComponentsQuery(compFilter, fieldFltr, compQuery.ComponentIDs)
 	if err != nil {
 		s.LogAlways("doComponentsQueryPost(): Lookup failure: %s", err)
@@ -717,6 +823,10 @@ func (s *SmD) doComponentsQueryGet(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	fieldFltr := getFieldFilterForm(fieldFltrIn)
+	if err = getPartScope(r).FilterComps(compFilter); err != nil {
+		sendJsonError(w, http.StatusForbidden, err.Error())
+		return
+	}
 	ids = append(ids, xname)
 	comps.Components, err = s.db.GetComponentsQuery(compFilter, fieldFltr, ids)
 	if err != nil {
@@ -760,7 +870,7 @@ func (s *SmD) doComponentByNIDGet(w http.ResponseWriter, r *http.Request) {
 		sendJsonDBError(w, "", "", err)
 		return
 	}
-	if cmp == nil {
+	if cmp == nil || !getPartScope(r).Has(cmp.ID) {
 		sendJsonError(w, http.StatusNotFound, "no such NID.")
 		return
 	}
@@ -768,6 +878,300 @@ func (s *SmD) doComponentByNIDGet(w http.ResponseWriter, r *http.Request) {
 	sendJsonCompRsp(w, cmp)
 }
 
+// Get the health of a single HMS component, i.e. its flag plus the
+// components and subcomponents whose Redfish health raised it.
+func (s *SmD) doCompHealthGet(w http.ResponseWriter, r *http.Request) {
+	defer base.DrainAndCloseRequestBody(r)
+
+	vars := mux.Vars(r)
+	xname := xnametypes.NormalizeHMSCompID(vars["xname"])
+
+	if !xnametypes.IsHMSCompIDValid(xname) {
+		sendJsonError(w, http.StatusBadRequest, "invalid xname")
+		return
+	}
+	if !getPartScope(r).Has(xname) {
+		sendJsonError(w, http.StatusNotFound, "no such xname.")
+		return
+	}
+	cmp, err := s.db.GetComponentByID(xname)
+	if err != nil {
+		s.LogAlways("doCompHealthGet(): Lookup failure: (%s) %s", xname, err)
+		sendJsonDBError(w, "", "", err)
+		return
+	}
+	if cmp == nil {
+		sendJsonError(w, http.StatusNotFound, "no such xname.")
+		return
+	}
+	hcs, err := s.db.GetCompHealthContributors(xname)
+	if err != nil {
+		s.LogAlways("doCompHealthGet(): Lookup failure: (%s) %s", xname, err)
+		sendJsonDBError(w, "", "", err)
+		return
+	}
+	health := &sm.CompHealth{
+		ID:           cmp.ID,
+		Type:         cmp.Type,
+		State:        cmp.State,
+		Flag:         cmp.Flag,
+		Contributors: hcs,
+	}
+	sendJsonCompHealthRsp(w, health)
+}
+
+// Get the labels on a single HMS component.
+func (s *SmD) doCompLabelsGet(w http.ResponseWriter, r *http.Request) {
+	defer base.DrainAndCloseRequestBody(r)
+
+	vars := mux.Vars(r)
+	xname := xnametypes.NormalizeHMSCompID(vars["xname"])
+
+	if !xnametypes.IsHMSCompIDValid(xname) {
+		sendJsonError(w, http.StatusBadRequest, "invalid xname")
+		return
+	}
+	if !getPartScope(r).Has(xname) {
+		sendJsonError(w, http.StatusNotFound, "no such xname.")
+		return
+	}
+	labels, err := s.db.GetCompLabels(xname)
+	if err != nil {
+		s.LogAlways("doCompLabelsGet(): Lookup failure: (%s) %s", xname, err)
+		sendJsonDBError(w, "", "", err)
+		return
+	}
+	if labels == nil {
+		sendJsonError(w, http.StatusNotFound, "no such xname.")
+		return
+	}
+	sendJsonCompLabelsRsp(w, labels)
+}
+
+// Replace all of the labels on a single HMS component with those in the
+// body.
+func (s *SmD) doCompLabelsPut(w http.ResponseWriter, r *http.Request) {
+	defer base.DrainAndCloseRequestBody(r)
+
+	vars := mux.Vars(r)
+	xname := xnametypes.NormalizeHMSCompID(vars["xname"])
+
+	if !xnametypes.IsHMSCompIDValid(xname) {
+		sendJsonError(w, http.StatusBadRequest, "invalid xname")
+		return
+	}
+	if !getPartScope(r).Has(xname) {
+		sendJsonError(w, http.StatusNotFound, "no such xname.")
+		return
+	}
+	var labelsIn sm.ComponentLabels
+	body, err := ioutil.ReadAll(r.Body)
+	err = json.Unmarshal(body, &labelsIn)
+	if err != nil {
+		sendJsonError(w, http.StatusInternalServerError,
+			"error decoding JSON "+err.Error())
+		return
+	}
+	// The ID in the body is optional, but should not contradict the path.
+	if labelsIn.ID != "" &&
+		xnametypes.NormalizeHMSCompID(labelsIn.ID) != xname {
+		sendJsonError(w, http.StatusBadRequest, ErrSMDIDConf.Error())
+		return
+	}
+	if labelsIn.Labels == nil {
+		labelsIn.Labels = map[string]string{}
+	}
+	if err := sm.VerifyLabels(labelsIn.Labels); err != nil {
+		sendJsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	found, err := s.db.ReplaceCompLabels(xname, labelsIn.Labels)
+	if err != nil {
+		s.LogAlways("doCompLabelsPut(): update failure: (%s) %s", xname, err)
+		sendJsonDBError(w, "", "", err)
+		return
+	}
+	if !found {
+		sendJsonError(w, http.StatusNotFound, "no such xname.")
+		return
+	}
+	sendJsonCompLabelsRsp(w, &sm.ComponentLabels{ID: xname, Labels: labelsIn.Labels})
+}
+
+// Set, or for null values remove, the labels in the body on a single HMS
+// component, leaving its other labels in place.
+func (s *SmD) doCompLabelsPatch(w http.ResponseWriter, r *http.Request) {
+	defer base.DrainAndCloseRequestBody(r)
+
+	vars := mux.Vars(r)
+	xname := xnametypes.NormalizeHMSCompID(vars["xname"])
+
+	if !xnametypes.IsHMSCompIDValid(xname) {
+		sendJsonError(w, http.StatusBadRequest, "invalid xname")
+		return
+	}
+	if !getPartScope(r).Has(xname) {
+		sendJsonError(w, http.StatusNotFound, "no such xname.")
+		return
+	}
+	patch := new(sm.CompLabelsPatch)
+	body, err := ioutil.ReadAll(r.Body)
+	err = json.Unmarshal(body, patch)
+	if err != nil {
+		sendJsonError(w, http.StatusInternalServerError,
+			"error decoding JSON "+err.Error())
+		return
+	}
+	if err := patch.Verify(false); err != nil {
+		sendJsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	ids, err := s.db.PatchCompLabels([]string{xname}, patch.Labels)
+	if err != nil {
+		s.LogAlways("doCompLabelsPatch(): update failure: (%s) %s", xname, err)
+		sendJsonDBError(w, "", "", err)
+		return
+	}
+	if len(ids) == 0 {
+		sendJsonError(w, http.StatusNotFound, "no such xname.")
+		return
+	}
+	labels, err := s.db.GetCompLabels(xname)
+	if err != nil {
+		s.LogAlways("doCompLabelsPatch(): Lookup failure: (%s) %s", xname, err)
+		sendJsonDBError(w, "", "", err)
+		return
+	}
+	sendJsonCompLabelsRsp(w, labels)
+}
+
+// Remove all of the labels from a single HMS component.
+func (s *SmD) doCompLabelsDelete(w http.ResponseWriter, r *http.Request) {
+	defer base.DrainAndCloseRequestBody(r)
+
+	vars := mux.Vars(r)
+	xname := xnametypes.NormalizeHMSCompID(vars["xname"])
+
+	if !xnametypes.IsHMSCompIDValid(xname) {
+		sendJsonError(w, http.StatusBadRequest, "invalid xname")
+		return
+	}
+	if !getPartScope(r).Has(xname) {
+		sendJsonError(w, http.StatusNotFound, "no such xname.")
+		return
+	}
+	found, err := s.db.DeleteCompLabels(xname)
+	if err != nil {
+		s.LogAlways("doCompLabelsDelete(): delete failure: (%s) %s", xname, err)
+		sendJsonDBError(w, "", "", err)
+		return
+	}
+	if !found {
+		sendJsonError(w, http.StatusNotFound, "no such xname.")
+		return
+	}
+	sendJsonError(w, http.StatusOK, "deleted labels")
+}
+
+// Get the history of state, flag, enabled, role and software status changes
+// to a single HMS component, oldest first, optionally filtered by field,
+// source and time range.  History is kept after the component is deleted.
+func (s *SmD) doCompHistoryGet(w http.ResponseWriter, r *http.Request) {
+	defer base.DrainAndCloseRequestBody(r)
+
+	vars := mux.Vars(r)
+	xname := xnametypes.NormalizeHMSCompID(vars["xname"])
+
+	if !xnametypes.IsHMSCompIDValid(xname) {
+		sendJsonError(w, http.StatusBadRequest, "invalid xname")
+		return
+	}
+	if !getPartScope(r).Has(xname) {
+		sendJsonError(w, http.StatusNotFound, "no such xname.")
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		s.lg.Printf("doCompHistoryGet(): ParseForm: %s", err)
+		sendJsonError(w, http.StatusInternalServerError,
+			"failed to decode query parameters.")
+		return
+	}
+	formJSON, err := json.Marshal(r.Form)
+	if err != nil {
+		s.lg.Printf("doCompHistoryGet(): Marshal form: %s", err)
+		sendJsonError(w, http.StatusInternalServerError,
+			"failed to decode query parameters.")
+		return
+	}
+	histIn := new(CompHistoryIn)
+	if err = json.Unmarshal(formJSON, histIn); err != nil {
+		s.lg.Printf("doCompHistoryGet(): Unmarshal form: %s", err)
+		sendJsonError(w, http.StatusInternalServerError,
+			"failed to decode query parameters.")
+		return
+	}
+	filter := []hmsds.CompHistFiltFunc{hmsds.CH_From("doCompHistoryGet")}
+	if len(histIn.Field) > 0 {
+		for _, field := range histIn.Field {
+			if sm.VerifyNormalizeCompHistField(field) == "" {
+				sendJsonError(w, http.StatusBadRequest, "Invalid field: "+field)
+				return
+			}
+		}
+		filter = append(filter, hmsds.CH_Fields(histIn.Field))
+	}
+	if len(histIn.Source) > 0 {
+		filter = append(filter, hmsds.CH_Sources(histIn.Source))
+	}
+	if len(histIn.Since) > 0 {
+		filter = append(filter, hmsds.CH_Since(histIn.Since[0]))
+	}
+	if len(histIn.Until) > 0 {
+		filter = append(filter, hmsds.CH_Until(histIn.Until[0]))
+	}
+	entries, err := s.db.GetCompHistory(xname, filter...)
+	if err != nil {
+		s.lg.Printf("doCompHistoryGet(): Lookup failure: %s", err)
+		sendJsonDBError(w, "bad query param: ", "", err)
+		return
+	}
+	sendJsonCompHistoryRsp(w, entries)
+}
+
+// Set, or for null values remove, the given labels on a list of
+// components in one operation.  Components that don't exist are skipped.
+func (s *SmD) doCompBulkLabelsPatch(w http.ResponseWriter, r *http.Request) {
+	defer base.DrainAndCloseRequestBody(r)
+
+	patch := new(sm.CompLabelsPatch)
+	body, err := ioutil.ReadAll(r.Body)
+	err = json.Unmarshal(body, patch)
+	if err != nil {
+		sendJsonError(w, http.StatusInternalServerError,
+			"error decoding JSON "+err.Error())
+		return
+	}
+	patch.Normalize()
+	if err := patch.Verify(true); err != nil {
+		sendJsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if sendJsonPartScopeIDsError(w, getPartScope(r).Outside(patch.ComponentIDs)) {
+		return
+	}
+	_, err = s.db.PatchCompLabels(patch.ComponentIDs, patch.Labels)
+	if err != nil {
+		sendJsonDBError(w, "operation 'Bulk Update Labels' failed: ",
+			"", err)
+		s.LogAlways("failed: %s %s, Err: %s", r.RemoteAddr, string(body), err)
+		return
+	}
+	s.lg.Printf("succeeded: %s %s", r.RemoteAddr, string(body))
+
+	// Send 204 status (success, no content in response)
+	sendJsonError(w, http.StatusNoContent, "")
+}
+
 // Get an array of HMS component by NID, if it exists and is a type that has a
 // NID (i.e. a node)
 func (s *SmD) doComponentByNIDQueryPost(w http.ResponseWriter, r *http.Request) {
@@ -817,6 +1221,10 @@ func (s *SmD) doComponentByNIDQueryPost(w http.ResponseWriter, r *http.Request)
 		sendJsonError(w, http.StatusBadRequest, "bad query param: "+err.Error())
 		return
 	}
+	if err = getPartScope(r).FilterComps(compFilter); err != nil {
+		sendJsonError(w, http.StatusForbidden, err.Error())
+		return
+	}
 	comps.Components, err = s.db.GetComponentsFilter(compFilter, fieldFltr)
 	if err != nil {
 		s.LogAlways("doComponentsQueryGet(): Lookup failure: %s", err)
@@ -846,6 +1254,15 @@ func (s *SmD) doCompBulkNIDPatch(w http.ResponseWriter, r *http.Request) {
 		sendJsonError(w, http.StatusBadRequest, "Missing Components")
 		return
 	}
+	if ps := getPartScope(r); ps != nil {
+		ids := make([]string, 0, len(*components))
+		for _, comp := range *components {
+			ids = append(ids, comp.ID)
+		}
+		if sendJsonPartScopeIDsError(w, ps.Outside(ids)) {
+			return
+		}
+	}
 	err = s.db.BulkUpdateCompNID(components)
 	if err != nil {
 		sendJsonDBError(w, "operation 'Bulk Update NID' failed: ",
@@ -938,14 +1355,26 @@ func (s *SmD) compPatchHelper(
 		sendJsonError(w, http.StatusBadRequest, ErrSMDNoUpType.Error())
 		return
 	}
+	// Requests bound to a partition can only update its components.
+	parts, err := getPartScope(r).Partitions(update.Partition)
+	if err != nil {
+		sendJsonError(w, http.StatusForbidden, err.Error())
+		return
+	}
+	update.Partition = parts
+	update.Source = getRequester(r)
 
 	//
 	// Update Database
 	//
-	err := s.doCompUpdate(update, name)
+	err = s.doCompUpdate(update, name)
 	if err != nil {
 		op := VerifyNormalizeCompUpdateType(update.UpdateType)
-		if base.IsHMSError(err) {
+		if serr, ok := err.(*compStateError); ok {
+			sendJsonCompStateError(w, serr)
+		} else if err == ErrSMDNotInPartInfo {
+			sendJsonError(w, http.StatusForbidden, err.Error())
+		} else if base.IsHMSError(err) {
 			// HMS error, ok to send directly
 			sendJsonError(w, http.StatusBadRequest, err.Error())
 		} else {
@@ -978,8 +1407,172 @@ func (s *SmD) compPatchHelper(
 	return
 }
 
-// Patch the State and Flag field (latter defaults to OK) for a single
-// component.
+// Apply a list of per-component changes to State, Flag, Enabled,
+// SoftwareStatus, Role, and SubRole in a single transaction.  Each component
+// either has all of its changes applied or none of them, and the results
+// are returned for each.  One SCN is sent for each distinct set of changes.
+func (s *SmD) doCompsPatch(w http.ResponseWriter, r *http.Request) {
+	defer base.DrainAndCloseRequestBody(r)
+
+	patchIn := new(sm.CompPatchArray)
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		sendJsonError(w, http.StatusBadRequest,
+			"error reading request body "+err.Error())
+		return
+	}
+	err = json.Unmarshal(body, patchIn)
+	if err != nil {
+		sendJsonError(w, http.StatusInternalServerError,
+			"error decoding JSON "+err.Error())
+		return
+	}
+	if len(patchIn.Components) < 1 {
+		sendJsonError(w, http.StatusBadRequest, "Missing Components")
+		return
+	}
+	source := getRequester(r)
+	ps := getPartScope(r)
+
+	// Anything wrong with one component's changes only fails that
+	// component.
+	failures := []sm.CompPatchFailure{}
+	patches := []*sm.CompPatch{}
+	seen := make(map[string]bool)
+	for _, p := range patchIn.Components {
+		if p == nil {
+			continue
+		}
+		id := p.ID
+		if err := p.VerifyNormalize(); err != nil {
+			failures = append(failures,
+				sm.CompPatchFailure{ID: id, Reason: err.Error()})
+			continue
+		}
+		if seen[p.ID] {
+			failures = append(failures,
+				sm.CompPatchFailure{ID: p.ID, Reason: "duplicate ID"})
+			continue
+		}
+		seen[p.ID] = true
+		if !ps.Has(p.ID) {
+			failures = append(failures,
+				sm.CompPatchFailure{ID: p.ID, Reason: ErrSMDPartScopeIDs.Error()})
+			continue
+		}
+		patches = append(patches, p)
+	}
+	//
+	// Update Database
+	//
+	// The state transition policy, if any, is checked against the locked
+	// components in the same transaction as the changes.
+	changedIDs := []string{}
+	if len(patches) != 0 {
+		var dbFailures []sm.CompPatchFailure
+		check, serr := s.statePolicyCheck(source, patchIn.Force)
+		changedIDs, dbFailures, err = s.db.PatchComponents(patches,
+			patchIn.Force, source, check)
+		if err != nil {
+			sendJsonDBError(w, "operation 'Patch Components' failed: ",
+				"", err)
+			s.LogAlways("failed: %s %s, Err: %s", r.RemoteAddr, string(body), err)
+			return
+		}
+		// Return the policy's rejections with the valid next states, not
+		// just as the reason.
+		if serr != nil && len(serr.Rejected) != 0 {
+			rejected := make(map[string]*sm.CompStateRejection,
+				len(serr.Rejected))
+			for _, rej := range serr.Rejected {
+				rejected[rej.ID] = rej
+			}
+			for i := range dbFailures {
+				if rej, ok := rejected[dbFailures[i].ID]; ok {
+					dbFailures[i].State = rej.State
+					dbFailures[i].NewState = rej.NewState
+					dbFailures[i].ValidNextStates = rej.ValidNextStates
+				}
+			}
+		}
+		failures = append(failures, dbFailures...)
+	}
+	failed := make(map[string]bool, len(failures))
+	for _, f := range failures {
+		failed[f.ID] = true
+	}
+	result := sm.CompPatchResult{
+		Success: sm.CompPatchSuccessArray{ComponentIDs: []string{}},
+		Failure: failures,
+	}
+	for _, p := range patches {
+		if !failed[p.ID] {
+			result.Success.ComponentIDs = append(result.Success.ComponentIDs,
+				p.ID)
+		}
+	}
+	result.Counts.Success = len(result.Success.ComponentIDs)
+	result.Counts.Failure = len(result.Failure)
+	result.Counts.Total = result.Counts.Success + result.Counts.Failure
+
+	s.sendCompPatchSCNs(patches, changedIDs)
+	s.lg.Printf("succeeded: %s %s", r.RemoteAddr, string(body))
+	sendJsonCompPatchRsp(w, result)
+}
+
+// Send one SCN for each distinct set of changes made by a multi-field
+// Components PATCH, covering all of the changed components it was made to,
+// and start or stop State Redfish Polling as needed.
+func (s *SmD) sendCompPatchSCNs(patches []*sm.CompPatch, changedIDs []string) {
+	changed := make(map[string]bool, len(changedIDs))
+	for _, id := range changedIDs {
+		changed[id] = true
+	}
+	keys := []string{}
+	scnData := make(map[string]base.Component)
+	scnIds := make(map[string][]string)
+	for _, p := range patches {
+		if !changed[p.ID] {
+			continue
+		}
+		if p.State != "" {
+			s.doStateRFPoll(p.ID, p.State)
+		}
+		data := base.Component{
+			State:   p.State,
+			Flag:    p.Flag,
+			Enabled: p.Enabled,
+		}
+		if p.SwStatus != nil {
+			data.SwStatus = *p.SwStatus
+		}
+		if p.Role != nil {
+			data.Role = *p.Role
+		}
+		if p.SubRole != nil {
+			data.SubRole = *p.SubRole
+		}
+		// Flag-only changes aren't a supported SCN type.
+		if data.State == "" && data.Enabled == nil && data.SwStatus == "" &&
+			data.Role == "" && data.SubRole == "" {
+			continue
+		}
+		keyBytes, _ := json.Marshal(data)
+		key := string(keyBytes)
+		if _, ok := scnData[key]; !ok {
+			keys = append(keys, key)
+			scnData[key] = data
+		}
+		scnIds[key] = append(scnIds[key], p.ID)
+	}
+	for _, key := range keys {
+		scn := NewJobSCN(scnIds[key], scnData[key], s)
+		s.wp.Queue(scn)
+	}
+}
+
+// Patch the State and Flag field (latter defaults to OK) for a single
+// component.
 func (s *SmD) doCompStateDataPatch(w http.ResponseWriter, r *http.Request) {
 	defer base.DrainAndCloseRequestBody(r)
 
@@ -1116,6 +1709,9 @@ func (s *SmD) doComponentPut(w http.ResponseWriter, r *http.Request) {
 			"couldn't validate component: "+err.Error())
 		return
 	}
+	if sendJsonPartScopeIDsError(w, getPartScope(r).Outside([]string{component.ID})) {
+		return
+	}
 	// Get the nid and role defaults for all node types
 	if component.Type == xnametypes.Node.String() || component.Type == xnametypes.VirtualNode.String() {
 		if len(component.Role) == 0 || len(component.NID) == 0 || len(component.Class) == 0 {
@@ -1134,7 +1730,8 @@ func (s *SmD) doComponentPut(w http.ResponseWriter, r *http.Request) {
 			}
 		}
 	}
-	changeMap, err := s.db.UpsertComponents([]*base.Component{component}, compIn.Force)
+	changeMap, err := s.db.UpsertComponents([]*base.Component{component}, compIn.Force,
+		getRequester(r))
 	if err != nil {
 		sendJsonDBError(w, "operation 'PUT' failed: ", "", err)
 		s.lg.Printf("failed: %s %s, Err: %s", r.RemoteAddr, string(body), err)
@@ -1394,6 +1991,10 @@ func (s *SmD) doHWInvByLocationGet(w http.ResponseWriter, r *http.Request) {
 
 	vars := mux.Vars(r)
 	xname := vars["xname"]
+	if !getPartScope(r).Has(xname) {
+		sendJsonError(w, http.StatusNotFound, "no such xname.")
+		return
+	}
 	hl, err := s.db.GetHWInvByLocID(xname)
 	if err != nil {
 		s.LogAlways("doHWInvByLocationGet(): Lookup failure: (%s) %s", xname, err)
@@ -1404,6 +2005,8 @@ func (s *SmD) doHWInvByLocationGet(w http.ResponseWriter, r *http.Request) {
 		sendJsonError(w, http.StatusNotFound, "no such xname.")
 		return
 	}
+	s.addHWInvFirmware([]*sm.HWInvByLoc{hl})
+	s.addHWInvFans([]*sm.HWInvByLoc{hl})
 	sendJsonHWInvByLocRsp(w, hl)
 }
 
@@ -1483,6 +2086,11 @@ func (s *SmD) doHWInvByLocationGetAll(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Partition
+	hwInvIn.Partition, err = getPartScope(r).Partitions(hwInvIn.Partition)
+	if err != nil {
+		sendJsonError(w, http.StatusForbidden, err.Error())
+		return
+	}
 	if len(hwInvIn.Partition) > 0 {
 		for _, p := range hwInvIn.Partition {
 			normP := sm.NormalizeGroupField(p)
@@ -1501,6 +2109,8 @@ func (s *SmD) doHWInvByLocationGetAll(w http.ResponseWriter, r *http.Request) {
 		sendJsonError(w, http.StatusInternalServerError, "failed to query DB.")
 		return
 	}
+	s.addHWInvFirmware(hwlocs)
+	s.addHWInvFans(hwlocs)
 	sendJsonHWInvByLocsRsp(w, hwlocs)
 }
 
@@ -1550,7 +2160,7 @@ func (s *SmD) doHWInvByFRUGet(w http.ResponseWriter, r *http.Request) {
 		sendJsonDBError(w, "", "", err)
 		return
 	}
-	if hf == nil {
+	if hf == nil || !s.partScopeHasFRU(r, fruID) {
 		sendJsonError(w, http.StatusNotFound, "no such FRU ID.")
 		return
 	}
@@ -1619,6 +2229,11 @@ func (s *SmD) doHWInvByFRUGetAll(w http.ResponseWriter, r *http.Request) {
 		hwInvLocFilter = append(hwInvLocFilter, hmsds.HWInvLoc_FruIDs(hwInvIn.FruId))
 	}
 
+	// Only FRUs installed in the partition, if bound to one
+	if ps := getPartScope(r); ps != nil {
+		hwInvLocFilter = append(hwInvLocFilter, hmsds.HWInvLoc_Part(ps.name))
+	}
+
 	hwfrus, err := s.db.GetHWInvByFRUFilter(hwInvLocFilter...)
 	if err != nil {
 		s.lg.Printf("doHWInvByFRUGetAll(): Lookup failure: %s", err)
@@ -1700,6 +2315,11 @@ func (s *SmD) doHWInvByLocationQueryGet(w http.ResponseWriter, r *http.Request)
 	}
 
 	// Partition
+	hwInvIn.Partition, err = getPartScope(r).Partitions(hwInvIn.Partition)
+	if err != nil {
+		sendJsonError(w, http.StatusForbidden, err.Error())
+		return
+	}
 	if len(hwInvIn.Partition) > 0 {
 		for _, p := range hwInvIn.Partition {
 			normP := sm.NormalizeGroupField(p)
@@ -1769,6 +2389,9 @@ func (s *SmD) doHWInvByLocationQueryGet(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	s.addHWInvFirmware(hwlocs)
+	s.addHWInvFans(hwlocs)
+
 	// Sort the results
 	hwinv, err := sm.NewSystemHWInventory(hwlocs, xname, format)
 	if err != nil {
@@ -1929,6 +2552,10 @@ func (s *SmD) hwInvHistGet(w http.ResponseWriter, r *http.Request, format sm.HWI
 			sendJsonError(w, http.StatusBadRequest, "Invalid xname")
 			return
 		}
+		if !getPartScope(r).Has(normId) {
+			sendJsonError(w, http.StatusNotFound, "no such xname.")
+			return
+		}
 		hwInvHistFilter = append(hwInvHistFilter, hmsds.HWInvHist_ID(normId))
 	case sm.HWInvHistFmtByFRU:
 		if id == "" {
@@ -1974,7 +2601,7 @@ func (s *SmD) hwInvHistGet(w http.ResponseWriter, r *http.Request, format sm.HWI
 	}
 	hwHistoryResp := sm.HWInvHistArray{
 		ID:      id,
-		History: hwhists,
+		History: partScopeHWInvHist(r, hwhists),
 	}
 	sendJsonHWInvHistRsp(w, &hwHistoryResp)
 }
@@ -2081,7 +2708,7 @@ func (s *SmD) hwInvHistGetAll(w http.ResponseWriter, r *http.Request, format sm.
 		sendJsonError(w, http.StatusInternalServerError, "failed to query DB.")
 		return
 	}
-	historyResp, err := sm.NewHWInvHistResp(hwhists, format)
+	historyResp, err := sm.NewHWInvHistResp(partScopeHWInvHist(r, hwhists), format)
 	if err != nil {
 		s.LogAlways("hwInvHistGetAll(%s): HWInvHist parse: %s", fmtStr, err)
 		sendJsonError(w, http.StatusInternalServerError, "Couldn't format response.")
@@ -2156,200 +2783,589 @@ func (s *SmD) doHWInvHistByFRUDelete(w http.ResponseWriter, r *http.Request) {
 }
 
 /////////////////////////////////////////////////////////////////////////////
-// Redfish endpoints
+// Hardware Inventory - Firmware
 /////////////////////////////////////////////////////////////////////////////
 
-// Get one specific RedfishEndpoint, previously created, by its xname ID.
-func (s *SmD) doRedfishEndpointGet(w http.ResponseWriter, r *http.Request) {
+// Get all firmware/software versions discovered for a single component.
+func (s *SmD) doHWInvFirmwareGet(w http.ResponseWriter, r *http.Request) {
 	defer base.DrainAndCloseRequestBody(r)
 
-	s.lg.Printf("doRedfishEndpointGet(): trying...")
 	vars := mux.Vars(r)
-	xname := vars["xname"]
-	ep, err := s.db.GetRFEndpointByID(xname)
-	if err != nil {
-		s.LogAlways("doRedfishEndpointGet(): Lookup failure: (%s) %s", xname, err)
-		sendJsonDBError(w, "", "", err)
+	xname := xnametypes.VerifyNormalizeCompID(vars["xname"])
+	if xname == "" {
+		s.lg.Printf("doHWInvFirmwareGet(): Invalid xname: %s", vars["xname"])
+		sendJsonError(w, http.StatusBadRequest, "Invalid xname")
 		return
 	}
-	if ep == nil {
+	if !getPartScope(r).Has(xname) {
 		sendJsonError(w, http.StatusNotFound, "no such xname.")
 		return
 	}
-	sendJsonRFEndpointRsp(w, ep)
+	fws, err := s.db.GetHWInvFirmwareFilter(
+		hmsds.FW_RelatedIDs([]string{xname}),
+		hmsds.FW_From("doHWInvFirmwareGet"))
+	if err != nil {
+		s.LogAlways("doHWInvFirmwareGet(): Lookup failure: (%s) %s", xname, err)
+		sendJsonDBError(w, "", "", err)
+		return
+	}
+	sendJsonHWInvFirmwareArrayRsp(w, &sm.HWInvFirmwareArray{Firmware: fws})
 }
 
-// Get all RedfishEndpoint entries in database, by doing a GET against the
-// entire collection.
-func (s *SmD) doRedfishEndpointsGet(w http.ResponseWriter, r *http.Request) {
+// Get firmware/software versions, optionally filtered by device xname,
+// version, etc.
+func (s *SmD) doHWInvFirmwareQueryGet(w http.ResponseWriter, r *http.Request) {
 	defer base.DrainAndCloseRequestBody(r)
 
-	eps := new(sm.RedfishEndpointArray)
-	var err error
-
-	// Parse arguments
 	if err := r.ParseForm(); err != nil {
-		s.lg.Printf("doRedfishEndpointsGet(): ParseForm: %s", err)
+		s.lg.Printf("doHWInvFirmwareQueryGet(): ParseForm: %s", err)
 		sendJsonError(w, http.StatusInternalServerError,
 			"failed to decode query parameters.")
 		return
 	}
 	formJSON, err := json.Marshal(r.Form)
 	if err != nil {
-		s.lg.Printf("doRedfishEndpointsGet(): Marshall form: %s", err)
+		s.lg.Printf("doHWInvFirmwareQueryGet(): Marshal form: %s", err)
 		sendJsonError(w, http.StatusInternalServerError,
 			"failed to decode query parameters.")
 		return
 	}
-	rfEPFilter := new(hmsds.RedfishEPFilter)
-	if err = json.Unmarshal(formJSON, rfEPFilter); err != nil {
-		s.lg.Printf("doRedfishEndpointsGet(): Unmarshall form: %s", err)
+	fwIn := new(HwInvFirmwareIn)
+	if err = json.Unmarshal(formJSON, fwIn); err != nil {
+		s.lg.Printf("doHWInvFirmwareQueryGet(): Unmarshal form: %s", err)
 		sendJsonError(w, http.StatusInternalServerError,
 			"failed to decode query parameters.")
 		return
 	}
-	eps.RedfishEndpoints, err = s.db.GetRFEndpointsFilter(rfEPFilter)
-	if err != nil {
-		s.LogAlways("doRedfishEndpointsGet(): Lookup failure: %s", err)
-		sendJsonDBError(w, "bad query param: ", "", err)
-		return
-	}
-	sendJsonRFEndpointArrayRsp(w, eps)
-}
-
-// We may not need this.  But need a post version for getting an arbitrary
-// list of endpoints.  If we can do these queries via filters on the
-// RedfishEndpoints collection then it is superflouous.  That would be
-// more REST-like.
-func (s *SmD) doRedfishEndpointQueryGet(w http.ResponseWriter, r *http.Request) {
-	defer base.DrainAndCloseRequestBody(r)
 
-	eps := new(sm.RedfishEndpointArray)
+	fwFilter := []hmsds.HWInvFirmwareFiltFunc{hmsds.FW_From("doHWInvFirmwareQueryGet")}
 
-	vars := mux.Vars(r)
-	xname := vars["xname"]
-	if xname == "" || xname == "all" || xname == "s0" {
-		var err error
-		eps.RedfishEndpoints, err = s.db.GetRFEndpointsAll()
-		if err != nil {
-			s.lg.Printf("doRedfishEndpointQueryGet(): Lookup failure: %s", err)
-			sendJsonError(w, http.StatusInternalServerError, "failed to query DB.")
+	if len(fwIn.ID) > 0 {
+		fwFilter = append(fwFilter, hmsds.FW_IDs(fwIn.ID))
+	}
+	if len(fwIn.RedfishEP) > 0 {
+		for i, id := range fwIn.RedfishEP {
+			normId := xnametypes.VerifyNormalizeCompID(id)
+			if normId == "" {
+				s.lg.Printf("doHWInvFirmwareQueryGet(): Invalid xname: %s", id)
+				sendJsonError(w, http.StatusBadRequest, "Invalid xname")
+				return
+			}
+			fwIn.RedfishEP[i] = normId
+		}
+		fwFilter = append(fwFilter, hmsds.FW_RfEPs(fwIn.RedfishEP))
+	}
+	if len(fwIn.Inventory) > 0 {
+		for i, inv := range fwIn.Inventory {
+			switch strings.ToLower(inv) {
+			case strings.ToLower(rf.FirmwareInventoryName):
+				fwIn.Inventory[i] = rf.FirmwareInventoryName
+			case strings.ToLower(rf.SoftwareInventoryName):
+				fwIn.Inventory[i] = rf.SoftwareInventoryName
+			default:
+				s.lg.Printf("doHWInvFirmwareQueryGet(): Invalid inventory: %s", inv)
+				sendJsonError(w, http.StatusBadRequest, "Invalid inventory")
+				return
+			}
+		}
+		fwFilter = append(fwFilter, hmsds.FW_Inventories(fwIn.Inventory))
+	}
+	// Device xnames can be negated with "!"
+	if len(fwIn.Device) > 0 {
+		for i, id := range fwIn.Device {
+			neg := ""
+			if strings.HasPrefix(id, "!") {
+				neg = "!"
+			}
+			normId := xnametypes.VerifyNormalizeCompID(strings.TrimLeft(id, "!"))
+			if normId == "" {
+				s.lg.Printf("doHWInvFirmwareQueryGet(): Invalid xname: %s", id)
+				sendJsonError(w, http.StatusBadRequest, "Invalid xname")
+				return
+			}
+			fwIn.Device[i] = neg + normId
+		}
+		fwFilter = append(fwFilter, hmsds.FW_RelatedIDs(fwIn.Device))
+	}
+	if len(fwIn.Version) > 0 {
+		fwFilter = append(fwFilter, hmsds.FW_Versions(fwIn.Version))
+	}
+	if len(fwIn.Updateable) > 0 {
+		if _, err := strconv.ParseBool(fwIn.Updateable[0]); err != nil {
+			s.lg.Printf("doHWInvFirmwareQueryGet(): Invalid string for updateable: %s", fwIn.Updateable[0])
+			sendJsonError(w, http.StatusBadRequest, "Invalid boolean for updateable")
 			return
 		}
-		sendJsonRFEndpointArrayRsp(w, eps)
-		return
-	} else {
-		sendJsonError(w, http.StatusBadRequest, "not yet implemented")
-		return
+		fwFilter = append(fwFilter, hmsds.FW_Updateable(fwIn.Updateable[0]))
 	}
-}
-
-// Delete single RedfishEndpoint, by its xname ID.  This also deletes any
-// child ComponentEndpoints, though not other structures.
-func (s *SmD) doRedfishEndpointDelete(w http.ResponseWriter, r *http.Request) {
-	defer base.DrainAndCloseRequestBody(r)
 
-	s.lg.Printf("doRedfishEndpointDelete(): trying...")
-	vars := mux.Vars(r)
-	xname := vars["xname"]
-	didDelete, affectedIDs, err := s.db.DeleteRFEndpointByIDSetEmpty(xname)
+	fws, err := s.db.GetHWInvFirmwareFilter(fwFilter...)
 	if err != nil {
-		s.LogAlways("doRedfishEndpointDelete(): delete failure: (%s) %s", xname, err)
+		s.lg.Printf("doHWInvFirmwareQueryGet(): Lookup failure: %s", err)
 		sendJsonDBError(w, "", "", err)
 		return
 	}
-	if didDelete == false {
-		sendJsonError(w, http.StatusNotFound, "no such xname.")
-		return
-	}
-	if len(affectedIDs) != 0 {
-		data := base.Component{
-			State: base.StateEmpty.String(),
-			Flag:  base.FlagOK.String(),
+	if ps := getPartScope(r); ps != nil {
+		inScope := make([]*sm.HWInvFirmware, 0, len(fws))
+		for _, fw := range fws {
+			if ps.Has(fw.RelatedID) {
+				inScope = append(inScope, fw)
+			}
 		}
-		scn := NewJobSCN(affectedIDs, data, s)
-		s.wp.Queue(scn)
+		fws = inScope
 	}
-	sendJsonError(w, http.StatusOK, "deleted 1 entry")
+	sendJsonHWInvFirmwareArrayRsp(w, &sm.HWInvFirmwareArray{Firmware: fws})
 }
 
-// Delete collection containing all RedfishEndoint entries.
-func (s *SmD) doRedfishEndpointsDeleteAll(w http.ResponseWriter, r *http.Request) {
-	defer base.DrainAndCloseRequestBody(r)
-
-	var err error
-	numDeleted, affectedIDs, err := s.db.DeleteRFEndpointsAllSetEmpty()
-	if err != nil {
-		s.lg.Printf("doRedfishEndpointsDelete(): Delete failure: %s", err)
-		sendJsonError(w, http.StatusInternalServerError, "DB query failed.")
+// Fill in the Firmware field of each location with the firmware/software
+// versions that apply to it.  Failures are logged but otherwise ignored so
+// that the hardware inventory can still be returned.
+func (s *SmD) addHWInvFirmware(hwlocs []*sm.HWInvByLoc) {
+	if len(hwlocs) == 0 {
 		return
 	}
-	if numDeleted == 0 {
-		sendJsonError(w, http.StatusNotFound, "no entries to delete")
+	fwFilter := []hmsds.HWInvFirmwareFiltFunc{hmsds.FW_From("addHWInvFirmware")}
+	// Just get everything if the list would make for an unreasonable query.
+	if len(hwlocs) <= 1000 {
+		ids := make([]string, 0, len(hwlocs))
+		for _, hwloc := range hwlocs {
+			ids = append(ids, hwloc.ID)
+		}
+		fwFilter = append(fwFilter, hmsds.FW_RelatedIDs(ids))
+	}
+	fws, err := s.db.GetHWInvFirmwareFilter(fwFilter...)
+	if err != nil {
+		s.LogAlways("addHWInvFirmware(): Lookup failure: %s", err)
 		return
 	}
-	if len(affectedIDs) != 0 {
-		data := base.Component{
-			State: base.StateEmpty.String(),
-			Flag:  base.FlagOK.String(),
+	fwMap := make(map[string][]*sm.HWInvFirmware)
+	for _, fw := range fws {
+		fwMap[fw.RelatedID] = append(fwMap[fw.RelatedID], fw)
+	}
+	for _, hwloc := range hwlocs {
+		if list, ok := fwMap[hwloc.ID]; ok {
+			hwloc.Firmware = list
 		}
-		scn := NewJobSCN(affectedIDs, data, s)
-		s.wp.Queue(scn)
 	}
-	numStr := strconv.FormatInt(numDeleted, 10)
-	sendJsonError(w, http.StatusOK, "deleted "+numStr+" entries")
 }
 
-// UPDATE existing RedfishEndpoint entry in full (or all least all
-// user-writable portions).
-func (s *SmD) doRedfishEndpointPut(w http.ResponseWriter, r *http.Request) {
+/////////////////////////////////////////////////////////////////////////////
+// Hardware Inventory - Fans
+/////////////////////////////////////////////////////////////////////////////
+
+// Get all fans discovered in a single component.
+func (s *SmD) doHWInvFanGet(w http.ResponseWriter, r *http.Request) {
 	defer base.DrainAndCloseRequestBody(r)
 
 	vars := mux.Vars(r)
-	xname := xnametypes.NormalizeHMSCompID(vars["xname"])
-
-	var rep rf.RawRedfishEP
-	var cred compcreds.CompCredentials
-	body, err := ioutil.ReadAll(r.Body)
-	err = json.Unmarshal(body, &rep)
-	if err != nil {
-		sendJsonError(w, http.StatusInternalServerError,
-			"error decoding JSON "+err.Error())
+	xname := xnametypes.VerifyNormalizeCompID(vars["xname"])
+	if xname == "" {
+		s.lg.Printf("doHWInvFanGet(): Invalid xname: %s", vars["xname"])
+		sendJsonError(w, http.StatusBadRequest, "Invalid xname")
 		return
 	}
-	if rep.ID == "" {
-		if xname != "" {
-			rep.ID = xname
-		}
-	} else if xnametypes.NormalizeHMSCompID(rep.ID) != xname {
-		sendJsonError(w, http.StatusBadRequest,
-			"xname in URL and PUT body do not match")
+	if !getPartScope(r).Has(xname) {
+		sendJsonError(w, http.StatusNotFound, "no such xname.")
 		return
 	}
-	// Make sure the information submitted is a proper endpoint and will
-	// not update the entry with invalid data.
-	epd, err := rf.NewRedfishEPDescription(&rep)
+	fans, err := s.db.GetHWInvFanFilter(
+		hmsds.Fan_ParentIDs([]string{xname}),
+		hmsds.Fan_From("doHWInvFanGet"))
 	if err != nil {
-		sendJsonError(w, http.StatusBadRequest,
-			"couldn't validate endpoint data: "+err.Error())
+		s.LogAlways("doHWInvFanGet(): Lookup failure: (%s) %s", xname, err)
+		sendJsonDBError(w, "", "", err)
 		return
 	}
-	// Package it up into a SM RedfishEndpoint representation and send to DB.
-	ep := sm.NewRedfishEndpoint(epd)
-	if s.writeVault {
-		cred = compcreds.CompCredentials{
-			Xname:    ep.ID,
-			URL:      ep.FQDN + "/redfish/v1",
-			Username: ep.User,
-			Password: ep.Password,
-		}
-		if s.readVault {
-			ep.Password = ""
-		}
+	sendJsonHWInvFanArrayRsp(w, &sm.HWInvFanArray{Fans: fans})
+}
+
+// Get fans, optionally filtered by parent xname, health, FRU ID, etc.
+func (s *SmD) doHWInvFanQueryGet(w http.ResponseWriter, r *http.Request) {
+	defer base.DrainAndCloseRequestBody(r)
+
+	if err := r.ParseForm(); err != nil {
+		s.lg.Printf("doHWInvFanQueryGet(): ParseForm: %s", err)
+		sendJsonError(w, http.StatusInternalServerError,
+			"failed to decode query parameters.")
+		return
 	}
-	retEP, affectedIDs, err := s.db.UpdateRFEndpointNoDiscInfo(ep)
+	formJSON, err := json.Marshal(r.Form)
 	if err != nil {
-		s.lg.Printf("failed: %s %s, Err: %s", r.RemoteAddr, string(body), err)
+		s.lg.Printf("doHWInvFanQueryGet(): Marshal form: %s", err)
+		sendJsonError(w, http.StatusInternalServerError,
+			"failed to decode query parameters.")
+		return
+	}
+	fanIn := new(HwInvFanIn)
+	if err = json.Unmarshal(formJSON, fanIn); err != nil {
+		s.lg.Printf("doHWInvFanQueryGet(): Unmarshal form: %s", err)
+		sendJsonError(w, http.StatusInternalServerError,
+			"failed to decode query parameters.")
+		return
+	}
+
+	fanFilter := []hmsds.HWInvFanFiltFunc{hmsds.Fan_From("doHWInvFanQueryGet")}
+
+	if len(fanIn.ID) > 0 {
+		fanFilter = append(fanFilter, hmsds.Fan_IDs(fanIn.ID))
+	}
+	// Parent xnames can be negated with "!"
+	if len(fanIn.Parent) > 0 {
+		for i, id := range fanIn.Parent {
+			neg := ""
+			if strings.HasPrefix(id, "!") {
+				neg = "!"
+			}
+			normId := xnametypes.VerifyNormalizeCompID(strings.TrimLeft(id, "!"))
+			if normId == "" {
+				s.lg.Printf("doHWInvFanQueryGet(): Invalid xname: %s", id)
+				sendJsonError(w, http.StatusBadRequest, "Invalid xname")
+				return
+			}
+			fanIn.Parent[i] = neg + normId
+		}
+		fanFilter = append(fanFilter, hmsds.Fan_ParentIDs(fanIn.Parent))
+	}
+	if len(fanIn.RedfishEP) > 0 {
+		for i, id := range fanIn.RedfishEP {
+			normId := xnametypes.VerifyNormalizeCompID(id)
+			if normId == "" {
+				s.lg.Printf("doHWInvFanQueryGet(): Invalid xname: %s", id)
+				sendJsonError(w, http.StatusBadRequest, "Invalid xname")
+				return
+			}
+			fanIn.RedfishEP[i] = normId
+		}
+		fanFilter = append(fanFilter, hmsds.Fan_RfEPs(fanIn.RedfishEP))
+	}
+	if len(fanIn.Status) > 0 {
+		for i, status := range fanIn.Status {
+			switch strings.ToLower(status) {
+			case "populated":
+				fanIn.Status[i] = "Populated"
+			case "empty":
+				fanIn.Status[i] = "Empty"
+			default:
+				s.lg.Printf("doHWInvFanQueryGet(): Invalid status: %s", status)
+				sendJsonError(w, http.StatusBadRequest, "Invalid status")
+				return
+			}
+		}
+		fanFilter = append(fanFilter, hmsds.Fan_Statuses(fanIn.Status))
+	}
+	// Health values can be negated with "!"
+	if len(fanIn.Health) > 0 {
+		for i, health := range fanIn.Health {
+			neg := ""
+			if strings.HasPrefix(health, "!") {
+				neg = "!"
+			}
+			switch strings.ToLower(strings.TrimLeft(health, "!")) {
+			case "ok":
+				fanIn.Health[i] = neg + "OK"
+			case "warning":
+				fanIn.Health[i] = neg + "Warning"
+			case "critical":
+				fanIn.Health[i] = neg + "Critical"
+			default:
+				s.lg.Printf("doHWInvFanQueryGet(): Invalid health: %s", health)
+				sendJsonError(w, http.StatusBadRequest, "Invalid health")
+				return
+			}
+		}
+		fanFilter = append(fanFilter, hmsds.Fan_Healths(fanIn.Health))
+	}
+	if len(fanIn.FruId) > 0 {
+		fanFilter = append(fanFilter, hmsds.Fan_FRUIDs(fanIn.FruId))
+	}
+
+	fans, err := s.db.GetHWInvFanFilter(fanFilter...)
+	if err != nil {
+		s.lg.Printf("doHWInvFanQueryGet(): Lookup failure: %s", err)
+		sendJsonDBError(w, "", "", err)
+		return
+	}
+	if ps := getPartScope(r); ps != nil {
+		inScope := make([]*sm.HWInvFan, 0, len(fans))
+		for _, fan := range fans {
+			if ps.Has(fan.ParentID) {
+				inScope = append(inScope, fan)
+			}
+		}
+		fans = inScope
+	}
+	sendJsonHWInvFanArrayRsp(w, &sm.HWInvFanArray{Fans: fans})
+}
+
+// Fill in the Fans field of each location with the fans installed in it.
+// Failures are logged but otherwise ignored so that the hardware inventory
+// can still be returned.
+func (s *SmD) addHWInvFans(hwlocs []*sm.HWInvByLoc) {
+	if len(hwlocs) == 0 {
+		return
+	}
+	fanFilter := []hmsds.HWInvFanFiltFunc{hmsds.Fan_From("addHWInvFans")}
+	// Just get everything if the list would make for an unreasonable query.
+	if len(hwlocs) <= 1000 {
+		ids := make([]string, 0, len(hwlocs))
+		for _, hwloc := range hwlocs {
+			ids = append(ids, hwloc.ID)
+		}
+		fanFilter = append(fanFilter, hmsds.Fan_ParentIDs(ids))
+	}
+	fans, err := s.db.GetHWInvFanFilter(fanFilter...)
+	if err != nil {
+		s.LogAlways("addHWInvFans(): Lookup failure: %s", err)
+		return
+	}
+	fanMap := make(map[string][]*sm.HWInvFan)
+	for _, fan := range fans {
+		fanMap[fan.ParentID] = append(fanMap[fan.ParentID], fan)
+	}
+	for _, hwloc := range hwlocs {
+		if list, ok := fanMap[hwloc.ID]; ok {
+			hwloc.Fans = list
+		}
+	}
+}
+
+/////////////////////////////////////////////////////////////////////////////
+// Hardware Inventory - Sensors
+/////////////////////////////////////////////////////////////////////////////
+
+// Get a snapshot of the Redfish sensor readings for a single component.
+// Readings are taken on demand and cached, refresh=true skips the cache.
+func (s *SmD) doSensorsGet(w http.ResponseWriter, r *http.Request) {
+	defer base.DrainAndCloseRequestBody(r)
+
+	vars := mux.Vars(r)
+	xname := xnametypes.VerifyNormalizeCompID(vars["xname"])
+	if xname == "" {
+		s.lg.Printf("doSensorsGet(): Invalid xname: %s", vars["xname"])
+		sendJsonError(w, http.StatusBadRequest, "Invalid xname")
+		return
+	}
+	refresh := false
+	if val := r.URL.Query().Get("refresh"); val != "" {
+		var err error
+		if refresh, err = strconv.ParseBool(val); err != nil {
+			sendJsonError(w, http.StatusBadRequest, "Invalid refresh value")
+			return
+		}
+	}
+	snap, wait, err := s.getCompSensors(xname, refresh)
+	switch err {
+	case nil:
+		sendJsonCompSensorsRsp(w, snap)
+	case ErrSmMsgNoEP:
+		sendJsonError(w, http.StatusNotFound, "no such xname.")
+	case ErrSmSensorRateLimited:
+		retry := int(wait.Seconds() + 0.999)
+		w.Header().Set("Retry-After", strconv.Itoa(retry))
+		sendJsonError(w, http.StatusTooManyRequests, err.Error())
+	default:
+		s.LogAlways("doSensorsGet(): Failed to read sensors: (%s) %s",
+			xname, err)
+		sendJsonError(w, http.StatusInternalServerError,
+			"failed to read sensors: "+err.Error())
+	}
+}
+
+/////////////////////////////////////////////////////////////////////////////
+// Redfish endpoints
+/////////////////////////////////////////////////////////////////////////////
+
+// Get one specific RedfishEndpoint, previously created, by its xname ID.
+func (s *SmD) doRedfishEndpointGet(w http.ResponseWriter, r *http.Request) {
+	defer base.DrainAndCloseRequestBody(r)
+
+	s.lg.Printf("doRedfishEndpointGet(): trying...")
+	vars := mux.Vars(r)
+	xname := vars["xname"]
+	ep, err := s.db.GetRFEndpointByID(xname)
+	if err != nil {
+		s.LogAlways("doRedfishEndpointGet(): Lookup failure: (%s) %s", xname, err)
+		sendJsonDBError(w, "", "", err)
+		return
+	}
+	if ep == nil {
+		sendJsonError(w, http.StatusNotFound, "no such xname.")
+		return
+	}
+	sendJsonRFEndpointRsp(w, ep)
+}
+
+// Get all RedfishEndpoint entries in database, by doing a GET against the
+// entire collection.
+func (s *SmD) doRedfishEndpointsGet(w http.ResponseWriter, r *http.Request) {
+	defer base.DrainAndCloseRequestBody(r)
+
+	eps := new(sm.RedfishEndpointArray)
+	var err error
+
+	// Parse arguments
+	if err := r.ParseForm(); err != nil {
+		s.lg.Printf("doRedfishEndpointsGet(): ParseForm: %s", err)
+		sendJsonError(w, http.StatusInternalServerError,
+			"failed to decode query parameters.")
+		return
+	}
+	formJSON, err := json.Marshal(r.Form)
+	if err != nil {
+		s.lg.Printf("doRedfishEndpointsGet(): Marshall form: %s", err)
+		sendJsonError(w, http.StatusInternalServerError,
+			"failed to decode query parameters.")
+		return
+	}
+	rfEPFilter := new(hmsds.RedfishEPFilter)
+	if err = json.Unmarshal(formJSON, rfEPFilter); err != nil {
+		s.lg.Printf("doRedfishEndpointsGet(): Unmarshall form: %s", err)
+		sendJsonError(w, http.StatusInternalServerError,
+			"failed to decode query parameters.")
+		return
+	}
+	eps.RedfishEndpoints, err = s.db.GetRFEndpointsFilter(rfEPFilter)
+	if err != nil {
+		s.LogAlways("doRedfishEndpointsGet(): Lookup failure: %s", err)
+		sendJsonDBError(w, "bad query param: ", "", err)
+		return
+	}
+	sendJsonRFEndpointArrayRsp(w, eps)
+}
+
+// We may not need this.  But need a post version for getting an arbitrary
+// list of endpoints.  If we can do these queries via filters on the
+// RedfishEndpoints collection then it is superflouous.  That would be
+// more REST-like.
+func (s *SmD) doRedfishEndpointQueryGet(w http.ResponseWriter, r *http.Request) {
+	defer base.DrainAndCloseRequestBody(r)
+
+	eps := new(sm.RedfishEndpointArray)
+
+	vars := mux.Vars(r)
+	xname := vars["xname"]
+	if xname == "" || xname == "all" || xname == "s0" {
+		var err error
+		eps.RedfishEndpoints, err = s.db.GetRFEndpointsAll()
+		if err != nil {
+			s.lg.Printf("doRedfishEndpointQueryGet(): Lookup failure: %s", err)
+			sendJsonError(w, http.StatusInternalServerError, "failed to query DB.")
+			return
+		}
+		sendJsonRFEndpointArrayRsp(w, eps)
+		return
+	} else {
+		sendJsonError(w, http.StatusBadRequest, "not yet implemented")
+		return
+	}
+}
+
+// Delete single RedfishEndpoint, by its xname ID.  This also deletes any
+// child ComponentEndpoints, though not other structures.
+func (s *SmD) doRedfishEndpointDelete(w http.ResponseWriter, r *http.Request) {
+	defer base.DrainAndCloseRequestBody(r)
+
+	s.lg.Printf("doRedfishEndpointDelete(): trying...")
+	vars := mux.Vars(r)
+	xname := vars["xname"]
+	didDelete, affectedIDs, err := s.db.DeleteRFEndpointByIDSetEmpty(xname)
+	if err != nil {
+		s.LogAlways("doRedfishEndpointDelete(): delete failure: (%s) %s", xname, err)
+		sendJsonDBError(w, "", "", err)
+		return
+	}
+	if didDelete == false {
+		sendJsonError(w, http.StatusNotFound, "no such xname.")
+		return
+	}
+	if len(affectedIDs) != 0 {
+		data := base.Component{
+			State: base.StateEmpty.String(),
+			Flag:  base.FlagOK.String(),
+		}
+		scn := NewJobSCN(affectedIDs, data, s)
+		s.wp.Queue(scn)
+	}
+	sendJsonError(w, http.StatusOK, "deleted 1 entry")
+}
+
+// Delete collection containing all RedfishEndoint entries.
+func (s *SmD) doRedfishEndpointsDeleteAll(w http.ResponseWriter, r *http.Request) {
+	defer base.DrainAndCloseRequestBody(r)
+
+	var err error
+	numDeleted, affectedIDs, err := s.db.DeleteRFEndpointsAllSetEmpty()
+	if err != nil {
+		s.lg.Printf("doRedfishEndpointsDelete(): Delete failure: %s", err)
+		sendJsonError(w, http.StatusInternalServerError, "DB query failed.")
+		return
+	}
+	if numDeleted == 0 {
+		sendJsonError(w, http.StatusNotFound, "no entries to delete")
+		return
+	}
+	if len(affectedIDs) != 0 {
+		data := base.Component{
+			State: base.StateEmpty.String(),
+			Flag:  base.FlagOK.String(),
+		}
+		scn := NewJobSCN(affectedIDs, data, s)
+		s.wp.Queue(scn)
+	}
+	numStr := strconv.FormatInt(numDeleted, 10)
+	sendJsonError(w, http.StatusOK, "deleted "+numStr+" entries")
+}
+
+// UPDATE existing RedfishEndpoint entry in full (or all least all
+// user-writable portions).
+func (s *SmD) doRedfishEndpointPut(w http.ResponseWriter, r *http.Request) {
+	defer base.DrainAndCloseRequestBody(r)
+
+	vars := mux.Vars(r)
+	xname := xnametypes.NormalizeHMSCompID(vars["xname"])
+
+	var rep rf.RawRedfishEP
+	var cred compcreds.CompCredentials
+	body, err := ioutil.ReadAll(r.Body)
+	err = json.Unmarshal(body, &rep)
+	if err != nil {
+		sendJsonError(w, http.StatusInternalServerError,
+			"error decoding JSON "+err.Error())
+		return
+	}
+	if rep.ID == "" {
+		if xname != "" {
+			rep.ID = xname
+		}
+	} else if xnametypes.NormalizeHMSCompID(rep.ID) != xname {
+		sendJsonError(w, http.StatusBadRequest,
+			"xname in URL and PUT body do not match")
+		return
+	}
+	// Make sure the information submitted is a proper endpoint and will
+	// not update the entry with invalid data.
+	epd, err := rf.NewRedfishEPDescription(&rep)
+	if err != nil {
+		sendJsonError(w, http.StatusBadRequest,
+			"couldn't validate endpoint data: "+err.Error())
+		return
+	}
+	// Package it up into a SM RedfishEndpoint representation and send to DB.
+	ep := sm.NewRedfishEndpoint(epd)
+	if s.writeVault {
+		cred = compcreds.CompCredentials{
+			Xname:    ep.ID,
+			URL:      ep.FQDN + "/redfish/v1",
+			Username: ep.User,
+			Password: ep.Password,
+		}
+		if s.readVault {
+			ep.Password = ""
+		}
+	}
+	retEP, affectedIDs, err := s.db.UpdateRFEndpointNoDiscInfo(ep)
+	if err != nil {
+		s.lg.Printf("failed: %s %s, Err: %s", r.RemoteAddr, string(body), err)
 		if err == hmsds.ErrHMSDSDuplicateKey {
 			sendJsonError(w, http.StatusConflict, "operation would conflict "+
 				"with an existing resource that has the same FQDN")
@@ -4021,67 +5037,500 @@ func (s *SmD) doPatchSCNSubscription(w http.ResponseWriter, r *http.Request) {
 				sendJsonError(w, http.StatusBadRequest, "Invalid Patch Op - "+patchIn.Op)
 				return
 			}
-			break
+			break
+		}
+	}
+	s.scnSubLock.Unlock()
+
+	// Send 204 status (success, no content in response)
+	sendJsonError(w, http.StatusNoContent, "Success")
+}
+
+// Delete a SCN subscription.
+func (s *SmD) doDeleteSCNSubscription(w http.ResponseWriter, r *http.Request) {
+	defer base.DrainAndCloseRequestBody(r)
+
+	var err error
+	vars := mux.Vars(r)
+	idStr := vars["id"]
+
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		sendJsonError(w, http.StatusBadRequest, "Invalid id - "+idStr)
+		return
+	}
+	if id < 1 {
+		sendJsonError(w, http.StatusBadRequest, "Invalid id - "+idStr)
+		return
+	}
+
+	s.scnSubLock.Lock()
+	// Delete the subscription from the db
+	didDelete, err := s.db.DeleteSCNSubscription(id)
+	if err != nil {
+		s.scnSubLock.Unlock()
+		sendJsonError(w, http.StatusBadRequest, "Unsubscribe failed")
+		s.lg.Printf("failed: %s %s, Err: %s", r.RemoteAddr, idStr, err)
+		return
+	}
+	// Delete the subscription from our cached subscription table.
+	if didDelete {
+		// Find the subscription.
+		for i, sub := range s.scnSubs.SubscriptionList {
+			if sub.ID == id {
+				// Found a subscription. Remove it from the map.
+				removeSCNMapSubscription(&s.scnSubMap, &sub)
+				// Remove the subscription from the subscription array.
+				s.scnSubs.SubscriptionList = append(s.scnSubs.SubscriptionList[:i], s.scnSubs.SubscriptionList[i+1:]...)
+				break
+			}
+		}
+	} else {
+		s.scnSubLock.Unlock()
+		sendJsonError(w, http.StatusNotFound, "Subscription not found")
+		return
+	}
+	s.scnSubLock.Unlock()
+	sendJsonError(w, http.StatusOK, "Subscription deleted")
+}
+
+/*
+ * Event Rules API
+ */
+
+// Get the effective event rules, in the order they are matched against
+// incoming Redfish events.
+func (s *SmD) doEventRulesGet(w http.ResponseWriter, r *http.Request) {
+	defer base.DrainAndCloseRequestBody(r)
+
+	rules := new(sm.EventRuleArray)
+	rules.EventRules = s.eventRules.GetAll()
+	sendJsonEventRuleArrayRsp(w, rules)
+}
+
+// Create a new event rule.  It may have the same name as a built-in or
+// file rule, in which case it replaces that rule.
+func (s *SmD) doEventRulePost(w http.ResponseWriter, r *http.Request) {
+	defer base.DrainAndCloseRequestBody(r)
+
+	body, err := ioutil.ReadAll(r.Body)
+	ruleIn := new(sm.EventRule)
+	err = json.Unmarshal(body, ruleIn)
+	if err != nil {
+		sendJsonError(w, http.StatusBadRequest,
+			"error decoding JSON "+err.Error())
+		return
+	}
+	ruleIn.Source = sm.EventRuleSourceAPI
+	if _, err := newEventRule(ruleIn); err != nil {
+		sendJsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	err = s.db.InsertEventRule(ruleIn)
+	if err != nil {
+		if err == hmsds.ErrHMSDSDuplicateKey {
+			sendJsonError(w, http.StatusConflict,
+				"operation would conflict with an existing event rule")
+		} else {
+			s.lg.Printf("doEventRulePost(): Insert failure: %s", err)
+			sendJsonDBError(w, "", "", err)
+		}
+		return
+	}
+	if err := s.refreshEventRules(); err != nil {
+		s.lg.Printf("doEventRulePost(): Refresh failure: %s", err)
+	}
+	uri := &sm.ResourceURI{URI: s.eventRulesBaseV2 + "/" + ruleIn.Name}
+	sendJsonNewResourceID(w, uri)
+}
+
+// Get a single effective event rule by name.
+func (s *SmD) doEventRuleGet(w http.ResponseWriter, r *http.Request) {
+	defer base.DrainAndCloseRequestBody(r)
+
+	name := mux.Vars(r)["name"]
+	rule := s.eventRules.Get(name)
+	if rule == nil {
+		sendJsonError(w, http.StatusNotFound, "No such event rule.")
+		return
+	}
+	sendJsonEventRuleRsp(w, rule)
+}
+
+// Create or replace the API event rule with the given name.
+func (s *SmD) doEventRulePut(w http.ResponseWriter, r *http.Request) {
+	defer base.DrainAndCloseRequestBody(r)
+
+	name := mux.Vars(r)["name"]
+	body, err := ioutil.ReadAll(r.Body)
+	ruleIn := new(sm.EventRule)
+	err = json.Unmarshal(body, ruleIn)
+	if err != nil {
+		sendJsonError(w, http.StatusBadRequest,
+			"error decoding JSON "+err.Error())
+		return
+	}
+	if ruleIn.Name == "" {
+		ruleIn.Name = name
+	} else if ruleIn.Name != name {
+		sendJsonError(w, http.StatusBadRequest,
+			"Name in body does not match the URI")
+		return
+	}
+	ruleIn.Source = sm.EventRuleSourceAPI
+	if _, err := newEventRule(ruleIn); err != nil {
+		sendJsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	didUpdate, err := s.db.UpdateEventRule(ruleIn)
+	if err == nil && !didUpdate {
+		err = s.db.InsertEventRule(ruleIn)
+	}
+	if err != nil {
+		s.lg.Printf("doEventRulePut(): Update failure: %s", err)
+		sendJsonDBError(w, "", "", err)
+		return
+	}
+	if err := s.refreshEventRules(); err != nil {
+		s.lg.Printf("doEventRulePut(): Refresh failure: %s", err)
+	}
+	sendJsonEventRuleRsp(w, ruleIn)
+}
+
+// Delete the API event rule with the given name.  If it replaced a file or
+// built-in rule, that rule takes effect again.
+func (s *SmD) doEventRuleDelete(w http.ResponseWriter, r *http.Request) {
+	defer base.DrainAndCloseRequestBody(r)
+
+	name := mux.Vars(r)["name"]
+	didDelete, err := s.db.DeleteEventRule(name)
+	if err != nil {
+		s.lg.Printf("doEventRuleDelete(): Delete failure: %s", err)
+		sendJsonDBError(w, "", "", err)
+		return
+	}
+	if !didDelete {
+		if s.eventRules.Get(name) != nil {
+			sendJsonError(w, http.StatusBadRequest,
+				"Only event rules created through the API can be deleted")
+		} else {
+			sendJsonError(w, http.StatusNotFound, "No such event rule.")
+		}
+		return
+	}
+	if err := s.refreshEventRules(); err != nil {
+		s.lg.Printf("doEventRuleDelete(): Refresh failure: %s", err)
+	}
+	sendJsonError(w, http.StatusOK, "deleted 1 entry")
+}
+
+/*
+ * Redfish Event Log API
+ */
+
+const (
+	rfEventLogLimitDefault = 100
+	rfEventLogLimitMax     = 1000
+)
+
+// Get a page of the Redfish event log, most recent first, optionally
+// filtered by xname, severity, registry, MessageId, FRU and time range.
+// FRU-affecting events include the matching hardware history.
+func (s *SmD) doRFEventLogGet(w http.ResponseWriter, r *http.Request) {
+	defer base.DrainAndCloseRequestBody(r)
+
+	if err := r.ParseForm(); err != nil {
+		s.lg.Printf("doRFEventLogGet(): ParseForm: %s", err)
+		sendJsonError(w, http.StatusInternalServerError,
+			"failed to decode query parameters.")
+		return
+	}
+	formJSON, err := json.Marshal(r.Form)
+	if err != nil {
+		s.lg.Printf("doRFEventLogGet(): Marshal form: %s", err)
+		sendJsonError(w, http.StatusInternalServerError,
+			"failed to decode query parameters.")
+		return
+	}
+	elIn := new(RFEventLogIn)
+	if err = json.Unmarshal(formJSON, elIn); err != nil {
+		s.lg.Printf("doRFEventLogGet(): Unmarshal form: %s", err)
+		sendJsonError(w, http.StatusInternalServerError,
+			"failed to decode query parameters.")
+		return
+	}
+
+	elFilter := []hmsds.RFEventLogFiltFunc{}
+	if len(elIn.ID) > 0 {
+		for i, id := range elIn.ID {
+			normId := xnametypes.VerifyNormalizeCompID(id)
+			if normId == "" {
+				sendJsonError(w, http.StatusBadRequest, "Invalid xname")
+				return
+			}
+			elIn.ID[i] = normId
 		}
+		elFilter = append(elFilter, hmsds.EL_IDs(elIn.ID))
 	}
-	s.scnSubLock.Unlock()
+	if len(elIn.Severity) > 0 {
+		elFilter = append(elFilter, hmsds.EL_Severities(elIn.Severity))
+	}
+	if len(elIn.Registry) > 0 {
+		elFilter = append(elFilter, hmsds.EL_Registries(elIn.Registry))
+	}
+	if len(elIn.MessageId) > 0 {
+		elFilter = append(elFilter, hmsds.EL_MessageIds(elIn.MessageId))
+	}
+	if len(elIn.FruId) > 0 {
+		elFilter = append(elFilter, hmsds.EL_FruIDs(elIn.FruId))
+	}
+	if len(elIn.Since) > 0 {
+		elFilter = append(elFilter, hmsds.EL_Since(elIn.Since[0]))
+	}
+	if len(elIn.Until) > 0 {
+		elFilter = append(elFilter, hmsds.EL_Until(elIn.Until[0]))
+	}
+	limit := rfEventLogLimitDefault
+	if len(elIn.Limit) > 0 {
+		l, err := strconv.Atoi(elIn.Limit[0])
+		if err != nil || l < 1 || l > rfEventLogLimitMax {
+			sendJsonError(w, http.StatusBadRequest,
+				"Invalid limit, must be 1-"+strconv.Itoa(rfEventLogLimitMax))
+			return
+		}
+		limit = l
+	}
+	var marker int64
+	if len(elIn.Marker) > 0 {
+		marker, err = strconv.ParseInt(elIn.Marker[0], 10, 64)
+		if err != nil || marker < 1 {
+			sendJsonError(w, http.StatusBadRequest, "Invalid marker")
+			return
+		}
+	}
+	// Get one extra to know if there is another page.
+	elFilter = append(elFilter, hmsds.EL_Page(marker, limit+1))
 
-	// Send 204 status (success, no content in response)
-	sendJsonError(w, http.StatusNoContent, "Success")
+	entries, err := s.db.GetRFEventLogFilter(elFilter...)
+	if err != nil {
+		s.lg.Printf("doRFEventLogGet(): Lookup failure: %s", err)
+		if err == hmsds.ErrHMSDSArgBadTimeFormat {
+			sendJsonError(w, http.StatusBadRequest, err.Error())
+		} else {
+			sendJsonError(w, http.StatusInternalServerError,
+				"failed to query DB.")
+		}
+		return
+	}
+	page := new(sm.RFEventLogPage)
+	if len(entries) > limit {
+		entries = entries[:limit]
+		page.NextMarker = entries[limit-1].ID
+	}
+	if err = s.addRFEventLogHWInvHist(entries); err != nil {
+		s.lg.Printf("doRFEventLogGet(): HW history lookup failure: %s", err)
+		sendJsonError(w, http.StatusInternalServerError,
+			"failed to query DB.")
+		return
+	}
+	page.Events = entries
+	sendJsonRFEventLogPageRsp(w, page)
 }
 
-// Delete a SCN subscription.
-func (s *SmD) doDeleteSCNSubscription(w http.ResponseWriter, r *http.Request) {
+/*
+ * Jobs API
+ */
+
+// Get the jobs in the job sync, optionally filtered by type, status,
+// component and whether they have expired.  Expired jobs have lost their
+// HSM instance and will be picked up by the next JobSync pass.
+func (s *SmD) doJobsGet(w http.ResponseWriter, r *http.Request) {
 	defer base.DrainAndCloseRequestBody(r)
 
-	var err error
-	vars := mux.Vars(r)
-	idStr := vars["id"]
+	if err := r.ParseForm(); err != nil {
+		s.lg.Printf("doJobsGet(): ParseForm: %s", err)
+		sendJsonError(w, http.StatusInternalServerError,
+			"failed to decode query parameters.")
+		return
+	}
+	formJSON, err := json.Marshal(r.Form)
+	if err != nil {
+		s.lg.Printf("doJobsGet(): Marshal form: %s", err)
+		sendJsonError(w, http.StatusInternalServerError,
+			"failed to decode query parameters.")
+		return
+	}
+	jobsIn := new(JobsIn)
+	if err = json.Unmarshal(formJSON, jobsIn); err != nil {
+		s.lg.Printf("doJobsGet(): Unmarshal form: %s", err)
+		sendJsonError(w, http.StatusInternalServerError,
+			"failed to decode query parameters.")
+		return
+	}
 
-	id, err := strconv.ParseInt(idStr, 10, 64)
+	jsFilter := []hmsds.JobSyncFiltFunc{hmsds.JS_From("doJobsGet")}
+	if len(jobsIn.Type) > 0 {
+		for i, jobType := range jobsIn.Type {
+			jobsIn.Type[i] = sm.VerifyNormalizeJobType(jobType)
+			if jobsIn.Type[i] == "" {
+				sendJsonError(w, http.StatusBadRequest, "Invalid job type")
+				return
+			}
+		}
+		jsFilter = append(jsFilter, hmsds.JS_Types(jobsIn.Type))
+	}
+	if len(jobsIn.Status) > 0 {
+		for i, status := range jobsIn.Status {
+			jobsIn.Status[i] = sm.VerifyNormalizeJobStatus(status)
+			if jobsIn.Status[i] == "" {
+				sendJsonError(w, http.StatusBadRequest, "Invalid job status")
+				return
+			}
+		}
+		jsFilter = append(jsFilter, hmsds.JS_Status_List(jobsIn.Status))
+	}
+	if len(jobsIn.ID) > 0 {
+		for i, id := range jobsIn.ID {
+			jobsIn.ID[i] = xnametypes.VerifyNormalizeCompID(id)
+			if jobsIn.ID[i] == "" {
+				sendJsonError(w, http.StatusBadRequest, "Invalid xname")
+				return
+			}
+		}
+		jsFilter = append(jsFilter, hmsds.JS_CompIDs(jobsIn.ID))
+	}
+	if len(jobsIn.Expired) > 0 {
+		expired, err := strconv.ParseBool(jobsIn.Expired[0])
+		if err != nil {
+			sendJsonError(w, http.StatusBadRequest,
+				"Invalid expired, must be true or false")
+			return
+		}
+		if expired {
+			jsFilter = append(jsFilter, hmsds.JS_Expired)
+		} else {
+			jsFilter = append(jsFilter, hmsds.JS_NotExpired)
+		}
+	}
+	jobs, err := s.db.GetJobs(jsFilter...)
 	if err != nil {
-		sendJsonError(w, http.StatusBadRequest, "Invalid id - "+idStr)
+		s.lg.Printf("doJobsGet(): Lookup failure: %s", err)
+		sendJsonError(w, http.StatusInternalServerError, "failed to query DB.")
 		return
 	}
-	if id < 1 {
-		sendJsonError(w, http.StatusBadRequest, "Invalid id - "+idStr)
+	s.addLiveJobStatus(jobs)
+	jobArray := &sm.JobArray{Jobs: jobs}
+	if jobArray.Jobs == nil {
+		jobArray.Jobs = []*sm.Job{}
+	}
+	sendJsonJobArrayRsp(w, jobArray)
+}
+
+// Get a single job by id.
+func (s *SmD) doJobGet(w http.ResponseWriter, r *http.Request) {
+	defer base.DrainAndCloseRequestBody(r)
+
+	id := mux.Vars(r)["id"]
+	job, err := s.db.GetJob(id)
+	if err != nil {
+		s.lg.Printf("doJobGet(): Lookup failure: (%s) %s", id, err)
+		sendJsonDBError(w, "", "", err)
+		return
+	}
+	if job == nil {
+		sendJsonError(w, http.StatusNotFound, "No such job.")
 		return
 	}
+	s.addLiveJobStatus([]*sm.Job{job})
+	sendJsonJobRsp(w, job)
+}
 
-	s.scnSubLock.Lock()
-	// Delete the subscription from the db
-	didDelete, err := s.db.DeleteSCNSubscription(id)
+// Cancel a job.  StateRFPoll jobs run by this HSM instance are stopped
+// right away.  Those run by another instance are deleted from the job sync
+// so that it stops at its next keep alive, and so that they are not picked
+// up again by JobSync.
+func (s *SmD) doJobDelete(w http.ResponseWriter, r *http.Request) {
+	defer base.DrainAndCloseRequestBody(r)
+
+	id := mux.Vars(r)["id"]
+	job, err := s.db.GetJob(id)
 	if err != nil {
-		s.scnSubLock.Unlock()
-		sendJsonError(w, http.StatusBadRequest, "Unsubscribe failed")
-		s.lg.Printf("failed: %s %s, Err: %s", r.RemoteAddr, idStr, err)
+		s.lg.Printf("doJobDelete(): Lookup failure: (%s) %s", id, err)
+		sendJsonDBError(w, "", "", err)
 		return
 	}
-	// Delete the subscription from our cached subscription table.
-	if didDelete {
-		// Find the subscription.
-		for i, sub := range s.scnSubs.SubscriptionList {
-			if sub.ID == id {
-				// Found a subscription. Remove it from the map.
-				removeSCNMapSubscription(&s.scnSubMap, &sub)
-				// Remove the subscription from the subscription array.
-				s.scnSubs.SubscriptionList = append(s.scnSubs.SubscriptionList[:i], s.scnSubs.SubscriptionList[i+1:]...)
-				break
-			}
-		}
-	} else {
-		s.scnSubLock.Unlock()
-		sendJsonError(w, http.StatusNotFound, "Subscription not found")
+	if job == nil {
+		sendJsonError(w, http.StatusNotFound, "No such job.")
 		return
 	}
-	s.scnSubLock.Unlock()
-	sendJsonError(w, http.StatusOK, "Subscription deleted")
+	if data, ok := job.Data.(*sm.SrfpJobData); ok && s.rfPoller != nil &&
+		s.rfPoller.hasJob(data.CompId, id) && s.cancelStateRFPoll(data.CompId) {
+		sendJsonError(w, http.StatusOK, "deleted 1 entry")
+		return
+	}
+	didDelete, err := s.db.DeleteJob(id)
+	if err != nil {
+		s.lg.Printf("doJobDelete(): Delete failure: (%s) %s", id, err)
+		sendJsonDBError(w, "", "", err)
+		return
+	}
+	if !didDelete {
+		sendJsonError(w, http.StatusNotFound, "No such job.")
+		return
+	}
+	sendJsonError(w, http.StatusOK, "deleted 1 entry")
+}
+
+// Replace the stored status and data of any of the jobs run by this HSM
+// instance with their live status.
+func (s *SmD) addLiveJobStatus(jobs []*sm.Job) {
+	if s.rfPoller == nil || len(jobs) == 0 {
+		return
+	}
+	live := make(map[string]*sm.Job)
+	for _, j := range s.rfPoller.Jobs() {
+		live[j.Id] = j
+	}
+	for _, j := range jobs {
+		if lj, ok := live[j.Id]; ok {
+			j.Status = lj.Status
+			j.Data = lj.Data
+		}
+	}
 }
 
 /*
  * HSM Groups API
  */
 
+const errBadIfMatch = "If-Match must be a version from an ETag or '*'."
+
+// Get the group or partition version from the If-Match header, accepting it
+// with or without quotes or a weak W/ prefix.  Zero is returned if there is
+// none or it is '*', as any version will do.  False means it can't be parsed.
+func getIfMatchVersion(r *http.Request) (int64, bool) {
+	tag := strings.TrimSpace(r.Header.Get("If-Match"))
+	if tag == "" || tag == "*" {
+		return 0, true
+	}
+	tag = strings.Trim(strings.TrimPrefix(tag, "W/"), `"`)
+	version, err := strconv.ParseInt(tag, 10, 64)
+	if err != nil || version <= 0 {
+		return 0, false
+	}
+	return version, true
+}
+
+// Get the service or user making a request, to record in the group,
+// partition and component history.  HMS services identify themselves with HMS-Service
+// or, failing that, their User-Agent.
+func getRequester(r *http.Request) string {
+	if svc := strings.TrimSpace(r.Header.Get("HMS-Service")); svc != "" {
+		return svc
+	}
+	return strings.TrimSpace(r.Header.Get(base.USERAGENT))
+}
+
 // Get all groups that currently exist, optionally filtering the set, returning
 // an array of groups.
 func (s *SmD) doGroupsGet(w http.ResponseWriter, r *http.Request) {
@@ -4122,6 +5571,11 @@ func (s *SmD) doGroupsGet(w http.ResponseWriter, r *http.Request) {
 			}
 		}
 	}
+	// Only show members of the partition the request is bound to, if any.
+	if part, err = getPartScope(r).Partition(part); err != nil {
+		sendJsonError(w, http.StatusForbidden, err.Error())
+		return
+	}
 	for i, tag := range groupFilter.Tag {
 		tag = sm.NormalizeGroupField(tag)
 		if sm.VerifyGroupField(tag) != nil {
@@ -4212,19 +5666,31 @@ func (s *SmD) doGroupsPost(w http.ResponseWriter, r *http.Request) {
 			"error decoding JSON "+err.Error())
 		return
 	}
-	group, err := sm.NewGroup(
-		groupIn.Label,
-		groupIn.Description,
-		groupIn.ExclusiveGroup,
-		groupIn.Tags,
-		groupIn.Members.IDs)
+	var group *sm.Group
+	if groupIn.Query != nil || len(groupIn.Groups) > 0 {
+		// Dynamic or nested group - NewGroup doesn't take the query or
+		// included groups, so use the input as-is.
+		group = &groupIn
+		group.Normalize()
+		err = group.Verify()
+	} else {
+		group, err = sm.NewGroup(
+			groupIn.Label,
+			groupIn.Description,
+			groupIn.ExclusiveGroup,
+			groupIn.Tags,
+			groupIn.Members.IDs)
+	}
 	if err != nil {
 		s.lg.Printf("doGroupsPost(): Couldn't validate group: %s", err)
 		sendJsonError(w, http.StatusBadRequest,
 			"couldn't validate group: "+err.Error())
 		return
 	}
-	label, err := s.db.InsertGroup(group)
+	if sendJsonPartScopeIDsError(w, getPartScope(r).Outside(group.Members.IDs)) {
+		return
+	}
+	label, err := s.db.InsertGroup(group, getRequester(r))
 	if err != nil {
 		s.lg.Printf("doGroupsPost(): %s %s Err: %s", r.RemoteAddr, string(body), err)
 		if err == hmsds.ErrHMSDSDuplicateKey {
@@ -4293,6 +5759,11 @@ func (s *SmD) doGroupGet(w http.ResponseWriter, r *http.Request) {
 			}
 		}
 	}
+	// Only show members of the partition the request is bound to, if any.
+	if part, err = getPartScope(r).Partition(part); err != nil {
+		sendJsonError(w, http.StatusForbidden, err.Error())
+		return
+	}
 	group, err := s.db.GetGroup(label, part)
 	if err != nil {
 		s.lg.Printf("doGroupGet(): Lookup failure: %s", err)
@@ -4305,6 +5776,7 @@ func (s *SmD) doGroupGet(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	setGroupETag(w, group.Version)
 	sendJsonGroupRsp(w, group)
 	return
 }
@@ -4323,7 +5795,10 @@ func (s *SmD) doGroupDelete(w http.ResponseWriter, r *http.Request) {
 			"Invalid group label.")
 		return
 	}
-	didDelete, err := s.db.DeleteGroup(label)
+	if !s.checkGroupPartScope(w, r, label) {
+		return
+	}
+	didDelete, err := s.db.DeleteGroup(label, getRequester(r))
 	if err != nil {
 		s.lg.Printf("doGroupDelete(): delete failure: (%s) %s", label, err)
 		sendJsonError(w, http.StatusInternalServerError, "DB query failed.")
@@ -4340,10 +5815,13 @@ func (s *SmD) doGroupDelete(w http.ResponseWriter, r *http.Request) {
 
 // To update the tags array and/or description, a PATCH operation can be used.
 // Omitted fields are not updated.
-// NOTE: This cannot be used to completely replace the members list. Rather,
+// NOTE: This cannot be used to change the members list. Rather,
 //
 //	individual members can be removed or added with the
-//	POST/DELETE {group_label}/members API.
+//	POST/DELETE {group_label}/members API, or all replaced with PUT.
+//
+// If there is an If-Match header, the patch is only applied if the group is
+// still at that version.
 func (s *SmD) doGroupPatch(w http.ResponseWriter, r *http.Request) {
 	defer base.DrainAndCloseRequestBody(r)
 
@@ -4359,7 +5837,8 @@ func (s *SmD) doGroupPatch(w http.ResponseWriter, r *http.Request) {
 			"error decoding JSON "+err.Error())
 		return
 	}
-	if groupPatch.Description == nil && groupPatch.Tags == nil {
+	if groupPatch.Description == nil && groupPatch.Tags == nil &&
+		groupPatch.Query == nil && groupPatch.Groups == nil {
 		s.lg.Printf("doGroupPatch(): Request must have at least one patch field.")
 		sendJsonError(w, http.StatusBadRequest,
 			"Request must have at least one patch field.")
@@ -4370,6 +5849,13 @@ func (s *SmD) doGroupPatch(w http.ResponseWriter, r *http.Request) {
 			"Invalid group label.")
 		return
 	}
+	if version, ok := getIfMatchVersion(r); !ok {
+		s.lg.Printf("doGroupPatch(): Invalid If-Match header.")
+		sendJsonError(w, http.StatusBadRequest, errBadIfMatch)
+		return
+	} else if version != 0 {
+		groupPatch.Version = version
+	}
 	if groupPatch.Tags != nil {
 		for _, tag := range *groupPatch.Tags {
 			tagNorm := sm.NormalizeGroupField(tag)
@@ -4381,11 +5867,17 @@ func (s *SmD) doGroupPatch(w http.ResponseWriter, r *http.Request) {
 			}
 		}
 	}
-	err = s.db.UpdateGroup(label, &groupPatch)
+	if !s.checkGroupPartScope(w, r, label) {
+		return
+	}
+	err = s.db.UpdateGroup(label, &groupPatch, getRequester(r))
 	if err != nil {
 		s.lg.Printf("doGroupPatch(): Lookup failure: %s", err)
 		if err == hmsds.ErrHMSDSNoGroup {
 			sendJsonError(w, http.StatusNotFound, "no such group.")
+		} else if err == hmsds.ErrHMSDSVersionMismatch {
+			sendJsonError(w, http.StatusPreconditionFailed,
+				"group has changed since the given version.")
 		} else {
 			sendJsonDBError(w, "bad query param: ", "", err)
 		}
@@ -4402,6 +5894,9 @@ func (s *SmD) doGroupLabelsGet(w http.ResponseWriter, r *http.Request) {
 	defer base.DrainAndCloseRequestBody(r)
 
 	labels, err := s.db.GetGroupLabels()
+	if err == nil {
+		labels, err = s.partScopeGroupLabels(r, labels)
+	}
 	if err != nil {
 		s.lg.Printf("doGroupLabelsGet(): Lookup failure: %s", err)
 		sendJsonDBError(w, "bad query param: ", "", err)
@@ -4447,6 +5942,15 @@ func (s *SmD) doGroupMembersGet(w http.ResponseWriter, r *http.Request) {
 			"failed to decode query parameters.")
 		return
 	}
+	if len(groupFilter.AsOf) > 0 {
+		if len(groupFilter.Partition) > 0 {
+			sendJsonError(w, http.StatusBadRequest,
+				"asof cannot be combined with partition.")
+			return
+		}
+		s.sendMembersAsOf(w, r, label, groupFilter.AsOf[0], false)
+		return
+	}
 	part := ""
 	if len(groupFilter.Partition) > 0 {
 		part = groupFilter.Partition[0]
@@ -4460,6 +5964,10 @@ func (s *SmD) doGroupMembersGet(w http.ResponseWriter, r *http.Request) {
 			}
 		}
 	}
+	if part, err = getPartScope(r).Partition(part); err != nil {
+		sendJsonError(w, http.StatusForbidden, err.Error())
+		return
+	}
 	group, err := s.db.GetGroup(label, part)
 	if err != nil {
 		s.lg.Printf("doGroupMembersGet(): Lookup failure: %s", err)
@@ -4471,9 +5979,160 @@ func (s *SmD) doGroupMembersGet(w http.ResponseWriter, r *http.Request) {
 		sendJsonError(w, http.StatusNotFound, "No such group: "+label)
 		return
 	}
-
-	sendJsonMembersRsp(w, &group.Members)
-	return
+
+	setGroupETag(w, group.Version)
+	sendJsonMembersRsp(w, &group.Members)
+	return
+}
+
+// Send the members the group or partition called name had at time asof,
+// as reconstructed from its history.  Only members added directly are
+// recorded there, not those of dynamic or included groups.
+func (s *SmD) sendMembersAsOf(
+	w http.ResponseWriter,
+	r *http.Request,
+	name, asof string,
+	isPart bool,
+) {
+	if _, err := time.Parse(time.RFC3339, asof); err != nil {
+		sendJsonError(w, http.StatusBadRequest,
+			"Invalid asof time, must be RFC3339.")
+		return
+	}
+	filter := []hmsds.GroupHistFiltFunc{
+		hmsds.GH_Until(asof),
+		hmsds.GH_Ops([]string{
+			sm.GroupHistOpCreate,
+			sm.GroupHistOpDelete,
+			sm.GroupHistOpAddMember,
+			sm.GroupHistOpRemoveMember,
+		}),
+		hmsds.GH_From("sendMembersAsOf"),
+	}
+	var entries []*sm.GroupHistoryEntry
+	var err error
+	kind := "group"
+	if isPart {
+		kind = "partition"
+		entries, err = s.db.GetPartitionHistory(name, filter...)
+	} else {
+		entries, err = s.db.GetGroupHistory(name, filter...)
+	}
+	if err != nil {
+		s.lg.Printf("sendMembersAsOf(): Lookup failure: %s", err)
+		sendJsonDBError(w, "bad query param: ", "", err)
+		return
+	}
+	ms, ok := sm.MembersFromGroupHistory(entries)
+	if !ok {
+		sendJsonError(w, http.StatusNotFound,
+			"No such "+kind+" at "+asof+": "+name)
+		return
+	}
+	if ps := getPartScope(r); ps != nil {
+		inScope := sm.NewMembers()
+		for _, id := range ms.IDs {
+			if ps.Has(id) {
+				inScope.IDs = append(inScope.IDs, id)
+			}
+		}
+		ms = inScope
+	}
+	sendJsonMembersRsp(w, ms)
+}
+
+// Get the history of changes to the group or partition called name, oldest
+// first, optionally filtered by member, op, requester and time range.
+func (s *SmD) sendGroupHistory(
+	w http.ResponseWriter,
+	r *http.Request,
+	name string,
+	isPart bool,
+) {
+	if err := r.ParseForm(); err != nil {
+		s.lg.Printf("sendGroupHistory(): ParseForm: %s", err)
+		sendJsonError(w, http.StatusInternalServerError,
+			"failed to decode query parameters.")
+		return
+	}
+	formJSON, err := json.Marshal(r.Form)
+	if err != nil {
+		s.lg.Printf("sendGroupHistory(): Marshal form: %s", err)
+		sendJsonError(w, http.StatusInternalServerError,
+			"failed to decode query parameters.")
+		return
+	}
+	histIn := new(GroupHistoryIn)
+	if err = json.Unmarshal(formJSON, histIn); err != nil {
+		s.lg.Printf("sendGroupHistory(): Unmarshal form: %s", err)
+		sendJsonError(w, http.StatusInternalServerError,
+			"failed to decode query parameters.")
+		return
+	}
+	filter := []hmsds.GroupHistFiltFunc{hmsds.GH_From("sendGroupHistory")}
+	if len(histIn.Member) > 0 {
+		filter = append(filter, hmsds.GH_Members(histIn.Member))
+	}
+	if len(histIn.Op) > 0 {
+		for _, op := range histIn.Op {
+			if sm.VerifyNormalizeGroupHistOp(op) == "" {
+				sendJsonError(w, http.StatusBadRequest, "Invalid op: "+op)
+				return
+			}
+		}
+		filter = append(filter, hmsds.GH_Ops(histIn.Op))
+	}
+	if len(histIn.Requester) > 0 {
+		filter = append(filter, hmsds.GH_Requesters(histIn.Requester))
+	}
+	if len(histIn.Since) > 0 {
+		filter = append(filter, hmsds.GH_Since(histIn.Since[0]))
+	}
+	if len(histIn.Until) > 0 {
+		filter = append(filter, hmsds.GH_Until(histIn.Until[0]))
+	}
+	var entries []*sm.GroupHistoryEntry
+	if isPart {
+		entries, err = s.db.GetPartitionHistory(name, filter...)
+	} else {
+		entries, err = s.db.GetGroupHistory(name, filter...)
+	}
+	if err != nil {
+		s.lg.Printf("sendGroupHistory(): Lookup failure: %s", err)
+		sendJsonDBError(w, "bad query param: ", "", err)
+		return
+	}
+	// Leave out changes to members outside the partition the request is
+	// bound to, if any.  Members that are included groups are kept.
+	if ps := getPartScope(r); ps != nil {
+		inScope := make([]*sm.GroupHistoryEntry, 0, len(entries))
+		for _, entry := range entries {
+			if entry.Member == "" ||
+				!xnametypes.IsHMSCompIDValid(entry.Member) ||
+				ps.Has(entry.Member) {
+				inScope = append(inScope, entry)
+			}
+		}
+		entries = inScope
+	}
+	sendJsonGroupHistoryRsp(w, entries)
+}
+
+// Get the history of changes to group {group_label}, including any earlier
+// groups with the same label that have since been deleted.
+func (s *SmD) doGroupHistoryGet(w http.ResponseWriter, r *http.Request) {
+	defer base.DrainAndCloseRequestBody(r)
+
+	vars := mux.Vars(r)
+	label := sm.NormalizeGroupField(vars["group_label"])
+
+	if sm.VerifyGroupField(label) != nil {
+		s.lg.Printf("doGroupHistoryGet(): Invalid group label.")
+		sendJsonError(w, http.StatusBadRequest,
+			"Invalid group label.")
+		return
+	}
+	s.sendGroupHistory(w, r, label, false)
 }
 
 // Create a new member of group {group_label} with the component xname id provided
@@ -4505,7 +6164,10 @@ func (s *SmD) doGroupMembersPost(w http.ResponseWriter, r *http.Request) {
 		sendJsonError(w, http.StatusBadRequest, "invalid xname ID")
 		return
 	}
-	id, err := s.db.AddGroupMember(label, normID)
+	if sendJsonPartScopeIDsError(w, getPartScope(r).Outside([]string{normID})) {
+		return
+	}
+	id, err := s.db.AddGroupMember(label, normID, getRequester(r))
 	if err != nil {
 		s.lg.Printf("doGroupMemberPost(): %s %s Err: %s", r.RemoteAddr, string(body), err)
 		if err == hmsds.ErrHMSDSNoGroup {
@@ -4529,6 +6191,71 @@ func (s *SmD) doGroupMembersPost(w http.ResponseWriter, r *http.Request) {
 	return
 }
 
+// Replace the whole member list of group {group_label} with the one in the
+// payload, at once.  If there is an If-Match header, this is only done if the
+// group is still at that version.
+func (s *SmD) doGroupMembersPut(w http.ResponseWriter, r *http.Request) {
+	defer base.DrainAndCloseRequestBody(r)
+
+	var membersIn sm.Members
+	vars := mux.Vars(r)
+	label := sm.NormalizeGroupField(vars["group_label"])
+
+	if sm.VerifyGroupField(label) != nil {
+		s.lg.Printf("doGroupMembersPut(): Invalid group label.")
+		sendJsonError(w, http.StatusBadRequest,
+			"Invalid group label.")
+		return
+	}
+	version, ok := getIfMatchVersion(r)
+	if !ok {
+		s.lg.Printf("doGroupMembersPut(): Invalid If-Match header.")
+		sendJsonError(w, http.StatusBadRequest, errBadIfMatch)
+		return
+	}
+	body, err := ioutil.ReadAll(r.Body)
+	err = json.Unmarshal(body, &membersIn)
+	if err != nil {
+		s.lg.Printf("doGroupMembersPut(): Unmarshal body: %s", err)
+		sendJsonError(w, http.StatusInternalServerError,
+			"error decoding JSON "+err.Error())
+		return
+	}
+	membersIn.Normalize()
+	if membersIn.Verify() != nil {
+		s.lg.Printf("doGroupMembersPut(): Invalid xname ID.")
+		sendJsonError(w, http.StatusBadRequest, "invalid xname ID")
+		return
+	}
+	if sendJsonPartScopeIDsError(w, getPartScope(r).Outside(membersIn.IDs)) ||
+		!s.checkGroupPartScope(w, r, label) {
+		return
+	}
+	newVersion, err := s.db.ReplaceGroupMembers(label, &membersIn, version,
+		getRequester(r))
+	if err != nil {
+		s.lg.Printf("doGroupMembersPut(): %s %s Err: %s", r.RemoteAddr,
+			string(body), err)
+		if err == hmsds.ErrHMSDSNoGroup {
+			sendJsonError(w, http.StatusNotFound, "No such group: "+label)
+		} else if err == hmsds.ErrHMSDSVersionMismatch {
+			sendJsonError(w, http.StatusPreconditionFailed,
+				"group has changed since the given version.")
+		} else if err == hmsds.ErrHMSDSExclusiveGroup {
+			sendJsonError(w, http.StatusConflict, "operation would conflict "+
+				"with an existing member in another exclusive group.")
+		} else {
+			// Send this message as 500 or 400 plus error message if it is
+			// an HMSError and not, e.g. an internal DB error code.
+			sendJsonDBError(w, "", "operation 'PUT' failed during store.", err)
+		}
+		return
+	}
+	setGroupETag(w, newVersion)
+	sendJsonError(w, http.StatusNoContent, "Success")
+	return
+}
+
 // Remove component {xname_id} from the members of group {group_label}.
 func (s *SmD) doGroupMemberDelete(w http.ResponseWriter, r *http.Request) {
 	defer base.DrainAndCloseRequestBody(r)
@@ -4548,11 +6275,16 @@ func (s *SmD) doGroupMemberDelete(w http.ResponseWriter, r *http.Request) {
 		sendJsonError(w, http.StatusBadRequest, "invalid xname ID")
 		return
 	}
-	didDelete, err := s.db.DeleteGroupMember(label, id)
+	if sendJsonPartScopeIDsError(w, getPartScope(r).Outside([]string{id})) {
+		return
+	}
+	didDelete, err := s.db.DeleteGroupMember(label, id, getRequester(r))
 	if err != nil {
 		s.lg.Printf("doGroupMemberDelete(): delete failure: (%s, %s) %s", label, id, err)
 		if err == hmsds.ErrHMSDSNoGroup {
 			sendJsonError(w, http.StatusNotFound, "No such group: "+label)
+		} else if err == hmsds.ErrHMSDSDynamicGroup {
+			sendJsonError(w, http.StatusBadRequest, err.Error())
 		} else {
 			sendJsonError(w, http.StatusInternalServerError, "DB query failed.")
 		}
@@ -4567,6 +6299,138 @@ func (s *SmD) doGroupMemberDelete(w http.ResponseWriter, r *http.Request) {
 	return
 }
 
+// Create a new static group {label} from the current members of group
+// {group_label}.  This is mainly useful for dynamic groups, to freeze the
+// result of their query, but works for any group.
+func (s *SmD) doGroupSnapshotPost(w http.ResponseWriter, r *http.Request) {
+	defer base.DrainAndCloseRequestBody(r)
+
+	var snapIn sm.GroupSnapshotBody
+	vars := mux.Vars(r)
+	label := sm.NormalizeGroupField(vars["group_label"])
+
+	if sm.VerifyGroupField(label) != nil {
+		s.lg.Printf("doGroupSnapshotPost(): Invalid group label.")
+		sendJsonError(w, http.StatusBadRequest,
+			"Invalid group label.")
+		return
+	}
+	body, err := ioutil.ReadAll(r.Body)
+	err = json.Unmarshal(body, &snapIn)
+	if err != nil {
+		s.lg.Printf("doGroupSnapshotPost(): Unmarshal body: %s", err)
+		sendJsonError(w, http.StatusInternalServerError,
+			"error decoding JSON "+err.Error())
+		return
+	}
+	// Only snapshot the members in the partition the request is bound to.
+	part, _ := getPartScope(r).Partition("")
+	group, err := s.db.GetGroup(label, part)
+	if err != nil {
+		s.lg.Printf("doGroupSnapshotPost(): Lookup failure: %s", err)
+		sendJsonDBError(w, "bad query param: ", "", err)
+		return
+	}
+	if group == nil {
+		s.lg.Printf("doGroupSnapshotPost(): No such group, %s", label)
+		sendJsonError(w, http.StatusNotFound, "No such group: "+label)
+		return
+	}
+	if snapIn.Description == "" {
+		snapIn.Description = "Snapshot of group " + label
+	}
+	snapshot, err := sm.NewGroup(
+		snapIn.Label,
+		snapIn.Description,
+		"",
+		snapIn.Tags,
+		group.Members.IDs)
+	if err != nil {
+		s.lg.Printf("doGroupSnapshotPost(): Couldn't validate group: %s", err)
+		sendJsonError(w, http.StatusBadRequest,
+			"couldn't validate group: "+err.Error())
+		return
+	}
+	newLabel, err := s.db.InsertGroup(snapshot, getRequester(r))
+	if err != nil {
+		s.lg.Printf("doGroupSnapshotPost(): %s %s Err: %s", r.RemoteAddr, string(body), err)
+		if err == hmsds.ErrHMSDSDuplicateKey {
+			sendJsonError(w, http.StatusConflict, "operation would conflict "+
+				"with an existing group that has the same label.")
+		} else {
+			sendJsonDBError(w, "", "operation 'POST' failed during store.", err)
+		}
+		return
+	}
+
+	uris := []*sm.ResourceURI{{URI: s.groupsBaseV2 + "/" + newLabel}}
+	sendJsonNewResourceIDArray(w, s.groupsBaseV2, uris)
+	return
+}
+
+// Evaluate a set-algebra expression over group labels and partition names,
+// e.g. "(compute | gpu) & !p1", returning the matching components, or just
+// their xnames if membersonly is set.
+func (s *SmD) doGroupsQueryPost(w http.ResponseWriter, r *http.Request) {
+	defer base.DrainAndCloseRequestBody(r)
+
+	body, err := ioutil.ReadAll(r.Body)
+	exprQuery := new(GroupExprQueryIn)
+	err = json.Unmarshal(body, exprQuery)
+	if err != nil {
+		sendJsonError(w, http.StatusInternalServerError,
+			"error decoding JSON "+err.Error())
+		return
+	}
+	expr, err := sm.ParseGroupExpr(exprQuery.Expression)
+	if err != nil {
+		s.lg.Printf("doGroupsQueryPost(): %s", err)
+		sendJsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	// Get the component field filter options (i.e. stateonly)
+	fieldFltrIn := new(FieldFltrIn)
+	err = json.Unmarshal(body, fieldFltrIn)
+	if err != nil {
+		sendJsonError(w, http.StatusInternalServerError,
+			"error decoding JSON "+err.Error())
+		return
+	}
+	fieldFltr := getFieldFilter(fieldFltrIn)
+	if exprQuery.MembersOnly {
+		fieldFltr = hmsds.FLTR_ID_ONLY
+	}
+	comps, err := s.db.GetComponentsGroupExpr(expr, fieldFltr)
+	if err != nil {
+		s.LogAlways("doGroupsQueryPost(): Lookup failure: %s", err)
+		if err == hmsds.ErrHMSDSNoGroup {
+			sendJsonError(w, http.StatusBadRequest,
+				"expression names a group that does not exist.")
+		} else {
+			sendJsonDBError(w, "bad query param: ", "", err)
+		}
+		return
+	}
+	if ps := getPartScope(r); ps != nil {
+		inScope := make([]*base.Component, 0, len(comps))
+		for _, comp := range comps {
+			if ps.Has(comp.ID) {
+				inScope = append(inScope, comp)
+			}
+		}
+		comps = inScope
+	}
+	if exprQuery.MembersOnly {
+		members := sm.NewMembers()
+		for _, comp := range comps {
+			members.IDs = append(members.IDs, comp.ID)
+		}
+		sendJsonMembersRsp(w, members)
+		return
+	}
+	sendJsonCompArrayRsp(w, &base.ComponentArray{Components: comps})
+}
+
 /*
  * HSM Partitions API
  */
@@ -4637,7 +6501,7 @@ func (s *SmD) doPartitionsGet(w http.ResponseWriter, r *http.Request) {
 		} else {
 			foundName = true
 		}
-		if !foundName {
+		if !foundName || getPartScope(r).HidesPartition(pname) {
 			continue
 		}
 		partition, err := s.db.GetPartition(pname)
@@ -4700,7 +6564,7 @@ func (s *SmD) doPartitionsPost(w http.ResponseWriter, r *http.Request) {
 			"couldn't validate partition: "+err.Error())
 		return
 	}
-	name, err := s.db.InsertPartition(part)
+	name, err := s.db.InsertPartition(part, getRequester(r))
 	if err != nil {
 		s.lg.Printf("doPartitionsPost(): %s %s Err: %s", r.RemoteAddr, string(body), err)
 		if err == hmsds.ErrHMSDSDuplicateKey {
@@ -4736,6 +6600,10 @@ func (s *SmD) doPartitionGet(w http.ResponseWriter, r *http.Request) {
 			"Invalid partition name.")
 		return
 	}
+	if getPartScope(r).HidesPartition(name) {
+		sendJsonError(w, http.StatusNotFound, "No such partition: "+name)
+		return
+	}
 
 	part, err := s.db.GetPartition(name)
 	if err != nil {
@@ -4749,12 +6617,15 @@ func (s *SmD) doPartitionGet(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	setGroupETag(w, part.Version)
 	sendJsonPartitionRsp(w, part)
 	return
 }
 
 // Delete partition {partition_name}. Any members previously in the partition
-// will no longer have the deleted partition name associated with them.
+// will no longer have the deleted partition name associated with them, or
+// go back to its parent if it is a sub-partition.  Partitions that still
+// have sub-partitions can't be deleted.
 func (s *SmD) doPartitionDelete(w http.ResponseWriter, r *http.Request) {
 	defer base.DrainAndCloseRequestBody(r)
 
@@ -4767,10 +6638,14 @@ func (s *SmD) doPartitionDelete(w http.ResponseWriter, r *http.Request) {
 			"Invalid partition name.")
 		return
 	}
-	didDelete, err := s.db.DeletePartition(name)
+	didDelete, err := s.db.DeletePartition(name, getRequester(r))
 	if err != nil {
 		s.lg.Printf("doPartitionDelete(): delete failure: (%s) %s", name, err)
-		sendJsonError(w, http.StatusInternalServerError, "DB query failed.")
+		if err == hmsds.ErrHMSDSPartitionHasChildren {
+			sendJsonError(w, http.StatusConflict, err.Error())
+		} else {
+			sendJsonError(w, http.StatusInternalServerError, "DB query failed.")
+		}
 		return
 	}
 	if didDelete == false {
@@ -4784,10 +6659,13 @@ func (s *SmD) doPartitionDelete(w http.ResponseWriter, r *http.Request) {
 
 // To update the tags array and/or description, a PATCH operation can be used.
 // Omitted fields are not updated.
-// NOTE: This cannot be used to completely replace the members list. Rather,
+// NOTE: This cannot be used to change the members list. Rather,
 //
 //	individual members can be removed or added with the POST/DELETE
-//	{partition_name}/members API.
+//	{partition_name}/members API, or all replaced with PUT.
+//
+// If there is an If-Match header, the patch is only applied if the
+// partition is still at that version.
 func (s *SmD) doPartitionPatch(w http.ResponseWriter, r *http.Request) {
 	defer base.DrainAndCloseRequestBody(r)
 
@@ -4821,6 +6699,13 @@ func (s *SmD) doPartitionPatch(w http.ResponseWriter, r *http.Request) {
 			"Invalid partition name.")
 		return
 	}
+	if version, ok := getIfMatchVersion(r); !ok {
+		s.lg.Printf("doPartitionPatch(): Invalid If-Match header.")
+		sendJsonError(w, http.StatusBadRequest, errBadIfMatch)
+		return
+	} else if version != 0 {
+		partPatch.Version = version
+	}
 	if partPatch.Tags != nil {
 		for _, tag := range *partPatch.Tags {
 			tagNorm := sm.NormalizeGroupField(tag)
@@ -4832,11 +6717,14 @@ func (s *SmD) doPartitionPatch(w http.ResponseWriter, r *http.Request) {
 			}
 		}
 	}
-	err = s.db.UpdatePartition(name, &partPatch)
+	err = s.db.UpdatePartition(name, &partPatch, getRequester(r))
 	if err != nil {
 		s.lg.Printf("doPartitionPatch(): Lookup failure: %s", err)
 		if err == hmsds.ErrHMSDSNoPartition {
 			sendJsonError(w, http.StatusNotFound, "no such partition.")
+		} else if err == hmsds.ErrHMSDSVersionMismatch {
+			sendJsonError(w, http.StatusPreconditionFailed,
+				"partition has changed since the given version.")
 		} else {
 			sendJsonDBError(w, "bad query param: ", "", err)
 		}
@@ -4858,6 +6746,15 @@ func (s *SmD) doPartitionNamesGet(w http.ResponseWriter, r *http.Request) {
 		sendJsonDBError(w, "bad query param: ", "", err)
 		return
 	}
+	if ps := getPartScope(r); ps != nil {
+		inScope := make([]string, 0, 1)
+		for _, name := range names {
+			if !ps.HidesPartition(name) {
+				inScope = append(inScope, name)
+			}
+		}
+		names = inScope
+	}
 	sendJsonStringArrayRsp(w, &names)
 	return
 }
@@ -4877,6 +6774,14 @@ func (s *SmD) doPartitionMembersGet(w http.ResponseWriter, r *http.Request) {
 			"Invalid partition name.")
 		return
 	}
+	if getPartScope(r).HidesPartition(name) {
+		sendJsonError(w, http.StatusNotFound, "No such partition: "+name)
+		return
+	}
+	if asof := r.URL.Query().Get("asof"); asof != "" {
+		s.sendMembersAsOf(w, r, name, asof, true)
+		return
+	}
 
 	part, err := s.db.GetPartition(name)
 	if err != nil {
@@ -4890,10 +6795,32 @@ func (s *SmD) doPartitionMembersGet(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	setGroupETag(w, part.Version)
 	sendJsonMembersRsp(w, &part.Members)
 	return
 }
 
+// Get the history of changes to partition {partition_name}, including any
+// earlier partitions with the same name that have since been deleted.
+func (s *SmD) doPartitionHistoryGet(w http.ResponseWriter, r *http.Request) {
+	defer base.DrainAndCloseRequestBody(r)
+
+	vars := mux.Vars(r)
+	name := sm.NormalizeGroupField(vars["partition_name"])
+
+	if sm.VerifyGroupField(name) != nil {
+		s.lg.Printf("doPartitionHistoryGet(): Invalid partition name.")
+		sendJsonError(w, http.StatusBadRequest,
+			"Invalid partition name.")
+		return
+	}
+	if getPartScope(r).HidesPartition(name) {
+		sendJsonError(w, http.StatusNotFound, "No such partition: "+name)
+		return
+	}
+	s.sendGroupHistory(w, r, name, true)
+}
+
 // Create a new member of partition {partition_name} with the component xname
 // id provided in the payload. New member should not already exist in the given
 // partition
@@ -4924,7 +6851,7 @@ func (s *SmD) doPartitionMembersPost(w http.ResponseWriter, r *http.Request) {
 		sendJsonError(w, http.StatusBadRequest, "invalid xname ID")
 		return
 	}
-	id, err := s.db.AddPartitionMember(name, normID)
+	id, err := s.db.AddPartitionMember(name, normID, getRequester(r))
 	if err != nil {
 		s.lg.Printf("doPartitionMembersPost(): %s %s Err: %s", r.RemoteAddr,
 			string(body), err)
@@ -4949,6 +6876,109 @@ func (s *SmD) doPartitionMembersPost(w http.ResponseWriter, r *http.Request) {
 	return
 }
 
+// Replace the whole member list of partition {partition_name} with the one in
+// the payload, at once.  If there is an If-Match header, this is only done if
+// the partition is still at that version.
+func (s *SmD) doPartitionMembersPut(w http.ResponseWriter, r *http.Request) {
+	defer base.DrainAndCloseRequestBody(r)
+
+	var membersIn sm.Members
+	vars := mux.Vars(r)
+	name := sm.NormalizeGroupField(vars["partition_name"])
+
+	if sm.VerifyGroupField(name) != nil {
+		s.lg.Printf("doPartitionMembersPut(): Invalid partition name.")
+		sendJsonError(w, http.StatusBadRequest,
+			"Invalid partition name.")
+		return
+	}
+	version, ok := getIfMatchVersion(r)
+	if !ok {
+		s.lg.Printf("doPartitionMembersPut(): Invalid If-Match header.")
+		sendJsonError(w, http.StatusBadRequest, errBadIfMatch)
+		return
+	}
+	body, err := ioutil.ReadAll(r.Body)
+	err = json.Unmarshal(body, &membersIn)
+	if err != nil {
+		s.lg.Printf("doPartitionMembersPut(): Unmarshal body: %s", err)
+		sendJsonError(w, http.StatusInternalServerError,
+			"error decoding JSON "+err.Error())
+		return
+	}
+	membersIn.Normalize()
+	if membersIn.Verify() != nil {
+		s.lg.Printf("doPartitionMembersPut(): Invalid xname ID.")
+		sendJsonError(w, http.StatusBadRequest, "invalid xname ID")
+		return
+	}
+	newVersion, err := s.db.ReplacePartitionMembers(name, &membersIn, version,
+		getRequester(r))
+	if err != nil {
+		s.lg.Printf("doPartitionMembersPut(): %s %s Err: %s", r.RemoteAddr,
+			string(body), err)
+		if err == hmsds.ErrHMSDSNoPartition {
+			sendJsonError(w, http.StatusNotFound, "No such partition: "+name)
+		} else if err == hmsds.ErrHMSDSVersionMismatch {
+			sendJsonError(w, http.StatusPreconditionFailed,
+				"partition has changed since the given version.")
+		} else if err == hmsds.ErrHMSDSExclusivePartition {
+			sendJsonError(w, http.StatusConflict, "operation would conflict "+
+				"with an existing member in another partition.")
+		} else {
+			// Send this message as 500 or 400 plus error message if it is
+			// an HMSError and not, e.g. an internal DB error code.
+			sendJsonDBError(w, "", "operation 'PUT' failed during store.", err)
+		}
+		return
+	}
+	setGroupETag(w, newVersion)
+	sendJsonError(w, http.StatusNoContent, "Success")
+	return
+}
+
+// Move the components in the payload from the sub-partition named by its
+// source field to sibling sub-partition {partition_name}, e.g. from p1.2 to
+// p1.3, at once.
+func (s *SmD) doPartitionMembersMovePost(w http.ResponseWriter, r *http.Request) {
+	defer base.DrainAndCloseRequestBody(r)
+
+	var moveIn sm.PartitionMove
+	vars := mux.Vars(r)
+	name := sm.NormalizeGroupField(vars["partition_name"])
+
+	if sm.VerifyGroupField(name) != nil {
+		s.lg.Printf("doPartitionMembersMovePost(): Invalid partition name.")
+		sendJsonError(w, http.StatusBadRequest,
+			"Invalid partition name.")
+		return
+	}
+	body, err := ioutil.ReadAll(r.Body)
+	err = json.Unmarshal(body, &moveIn)
+	if err != nil {
+		s.lg.Printf("doPartitionMembersMovePost(): Unmarshal body: %s", err)
+		sendJsonError(w, http.StatusInternalServerError,
+			"error decoding JSON "+err.Error())
+		return
+	}
+	err = s.db.MovePartitionMembers(name, &moveIn, getRequester(r))
+	if err != nil {
+		s.lg.Printf("doPartitionMembersMovePost(): %s %s Err: %s",
+			r.RemoteAddr, string(body), err)
+		if err == hmsds.ErrHMSDSNoPartition {
+			sendJsonError(w, http.StatusNotFound, "No such partition: "+name+
+				" or "+moveIn.Source)
+		} else {
+			// Send this message as 500 or 400 plus error message if it is
+			// an HMSError and not, e.g. an internal DB error code.
+			sendJsonDBError(w, "", "operation 'POST' failed during store.", err)
+		}
+		return
+	}
+	sendJsonError(w, http.StatusNoContent, "Success")
+	return
+}
+
 // Remove component {xname_id} from the members of partition {partition_name}.
 func (s *SmD) doPartitionMemberDelete(w http.ResponseWriter, r *http.Request) {
 	defer base.DrainAndCloseRequestBody(r)
@@ -4968,7 +6998,7 @@ func (s *SmD) doPartitionMemberDelete(w http.ResponseWriter, r *http.Request) {
 		sendJsonError(w, http.StatusBadRequest, "invalid xname ID")
 		return
 	}
-	didDelete, err := s.db.DeletePartitionMember(name, id)
+	didDelete, err := s.db.DeletePartitionMember(name, id, getRequester(r))
 	if err != nil {
 		s.lg.Printf("doPartitionMemberDelete(): delete failure: (%s, %s) %s", name, id, err)
 		if err == hmsds.ErrHMSDSNoPartition {
@@ -5017,6 +7047,10 @@ func (s *SmD) doMembershipsGet(w http.ResponseWriter, r *http.Request) {
 			"failed to decode query parameters.")
 		return
 	}
+	if err = getPartScope(r).FilterComps(compFilter); err != nil {
+		sendJsonError(w, http.StatusForbidden, err.Error())
+		return
+	}
 	memberships, err := s.db.GetMemberships(compFilter)
 	if err != nil {
 		s.lg.Printf("doMembershipsGet(): Lookup failure: %s", err)
@@ -5038,6 +7072,10 @@ func (s *SmD) doMembershipGet(w http.ResponseWriter, r *http.Request) {
 		sendJsonError(w, http.StatusBadRequest, "invalid xname")
 		return
 	}
+	if !getPartScope(r).Has(xname) {
+		sendJsonError(w, http.StatusNotFound, "No such xname: "+xname)
+		return
+	}
 	membership, err := s.db.GetMembership(xname)
 	if err != nil {
 		s.lg.Printf("doMembershipGet(): Lookup failure: %s", err)
@@ -5074,6 +7112,10 @@ func (s *SmD) compLocksV2Helper(w http.ResponseWriter, r *http.Request, action s
 		sendJsonError(w, http.StatusBadRequest, err.Error())
 		return
 	}
+	if filter.Partition, err = getPartScope(r).Partitions(filter.Partition); err != nil {
+		sendJsonError(w, http.StatusForbidden, err.Error())
+		return
+	}
 	results, err := s.db.UpdateCompLocksV2(filter, action)
 	if err != nil {
 		s.lg.Printf("doCompLocksV2%s(): %s %s Err: %s", action, r.RemoteAddr, string(body), err)
@@ -5106,6 +7148,10 @@ func (s *SmD) doCompLocksReservationRemove(w http.ResponseWriter, r *http.Reques
 		sendJsonError(w, http.StatusBadRequest, err.Error())
 		return
 	}
+	if filter.Partition, err = getPartScope(r).Partitions(filter.Partition); err != nil {
+		sendJsonError(w, http.StatusForbidden, err.Error())
+		return
+	}
 	results, err := s.db.DeleteCompReservationsForce(filter)
 	if err != nil {
 		s.lg.Printf("doCompLocksReservationRemove(): %s %s Err: %s", r.RemoteAddr, string(body), err)
@@ -5138,6 +7184,9 @@ func (s *SmD) doCompLocksReservationRelease(w http.ResponseWriter, r *http.Reque
 		sendJsonError(w, http.StatusBadRequest, err.Error())
 		return
 	}
+	if sendJsonPartScopeIDsError(w, getPartScope(r).OutsideKeys(filter.ReservationKeys)) {
+		return
+	}
 	results, err := s.db.DeleteCompReservations(filter)
 	if err != nil {
 		s.lg.Printf("doCompLocksReservationRelease(): %s %s Err: %s", r.RemoteAddr, string(body), err)
@@ -5170,6 +7219,10 @@ func (s *SmD) doCompLocksReservationCreate(w http.ResponseWriter, r *http.Reques
 		sendJsonError(w, http.StatusBadRequest, err.Error())
 		return
 	}
+	if filter.Partition, err = getPartScope(r).Partitions(filter.Partition); err != nil {
+		sendJsonError(w, http.StatusForbidden, err.Error())
+		return
+	}
 	filter.ReservationDuration = 0
 	results, err := s.db.InsertCompReservations(filter)
 	if err != nil {
@@ -5203,6 +7256,9 @@ func (s *SmD) doCompLocksServiceReservationRenew(w http.ResponseWriter, r *http.
 		sendJsonError(w, http.StatusBadRequest, err.Error())
 		return
 	}
+	if sendJsonPartScopeIDsError(w, getPartScope(r).OutsideKeys(filter.ReservationKeys)) {
+		return
+	}
 	if filter.ReservationDuration <= 0 {
 		s.lg.Printf("doCompLocksServiceReservationRenew(): ReservationDuration must be greater than 0")
 		sendJsonError(w, http.StatusBadRequest, "ReservationDuration must be greater than 0")
@@ -5247,6 +7303,10 @@ func (s *SmD) doCompLocksServiceReservationCreate(w http.ResponseWriter, r *http
 		sendJsonError(w, http.StatusBadRequest, err.Error())
 		return
 	}
+	if filter.Partition, err = getPartScope(r).Partitions(filter.Partition); err != nil {
+		sendJsonError(w, http.StatusForbidden, err.Error())
+		return
+	}
 	if filter.ReservationDuration <= 0 {
 		s.lg.Printf("doCompLocksServiceReservationCreate(): ReservationDuration must be greater than 0")
 		sendJsonError(w, http.StatusBadRequest, "ReservationDuration must be greater than 0")
@@ -5284,6 +7344,9 @@ func (s *SmD) doCompLocksServiceReservationCheck(w http.ResponseWriter, r *http.
 		sendJsonError(w, http.StatusBadRequest, err.Error())
 		return
 	}
+	if sendJsonPartScopeIDsError(w, getPartScope(r).OutsideKeys(filter.DeputyKeys)) {
+		return
+	}
 	results, err := s.db.GetCompReservations(filter.DeputyKeys)
 	if err != nil {
 		s.lg.Printf("doCompLocksServiceReservationCheck(): %s %s Err: %s", r.RemoteAddr, string(body), err)
@@ -5319,6 +7382,10 @@ func (s *SmD) doCompLocksStatus(w http.ResponseWriter, r *http.Request) {
 		sendJsonError(w, http.StatusBadRequest, err.Error())
 		return
 	}
+	if filter.Partition, err = getPartScope(r).Partitions(filter.Partition); err != nil {
+		sendJsonError(w, http.StatusForbidden, err.Error())
+		return
+	}
 	locks, err := s.db.GetCompLocksV2(filter)
 	if err != nil {
 		s.lg.Printf("doCompLocksStatus(): %s %s Err: %s", r.RemoteAddr, string(body), err)
@@ -5379,6 +7446,10 @@ func (s *SmD) doCompLocksStatusGet(w http.ResponseWriter, r *http.Request) {
 		sendJsonError(w, http.StatusBadRequest, err.Error())
 		return
 	}
+	if filter.Partition, err = getPartScope(r).Partitions(filter.Partition); err != nil {
+		sendJsonError(w, http.StatusForbidden, err.Error())
+		return
+	}
 	if filter.Reserved != nil {
 		// Only use the first Reserved query parameter supplied since
 		// asking for reserved and unreserved components with multiple
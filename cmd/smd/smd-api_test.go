@@ -34,6 +34,7 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	base "github.com/Cray-HPE/hms-base/v2"
 	compcreds "github.com/Cray-HPE/hms-compcredentials"
@@ -349,9 +350,22 @@ func compareGroup(grp1, grp2 *sm.Group) bool {
 		grp1.Description != grp2.Description ||
 		grp1.ExclusiveGroup != grp2.ExclusiveGroup ||
 		len(grp1.Tags) != len(grp2.Tags) ||
+		len(grp1.Groups) != len(grp2.Groups) ||
 		len(grp1.Members.IDs) != len(grp2.Members.IDs) {
 		return false
 	}
+	for i, label := range grp1.Groups {
+		if label != grp2.Groups[i] {
+			return false
+		}
+	}
+	if (grp1.Query == nil) != (grp2.Query == nil) {
+		return false
+	} else if grp1.Query != nil &&
+		(!reflect.DeepEqual(grp1.Query.Role, grp2.Query.Role) ||
+			!reflect.DeepEqual(grp1.Query.Prefix, grp2.Query.Prefix)) {
+		return false
+	}
 	if len(grp1.Tags) > 0 {
 		for i, tag := range grp1.Tags {
 			if tag != grp2.Tags[i] {
@@ -376,7 +390,14 @@ func compareGroupPatch(grp1, grp2 *sm.GroupPatch) bool {
 		return true
 	}
 	if (grp1.Description == nil) != (grp2.Description == nil) ||
-		(grp1.Tags == nil) != (grp2.Tags == nil) {
+		(grp1.Tags == nil) != (grp2.Tags == nil) ||
+		(grp1.Groups == nil) != (grp2.Groups == nil) {
+		return false
+	}
+	if grp1.Groups != nil && !reflect.DeepEqual(*grp1.Groups, *grp2.Groups) {
+		return false
+	}
+	if grp1.Version != grp2.Version {
 		return false
 	}
 	if grp1.Description != nil && grp1.Description != grp2.Description {
@@ -608,6 +629,13 @@ func TestMain(m *testing.M) {
 	s.hsnIntBaseV2 = s.apiRootV2 + "/Inventory/HSNInterfaces"
 	s.hwinvByLocBaseV2 = s.apiRootV2 + "/Inventory/Hardware"
 	s.hwinvByFRUBaseV2 = s.apiRootV2 + "/Inventory/HardwareByFRU"
+	s.hwinvFwBaseV2 = s.apiRootV2 + "/Inventory/Firmware"
+	s.hwinvFanBaseV2 = s.apiRootV2 + "/Inventory/Fans"
+	s.sensorsBaseV2 = s.apiRootV2 + "/Inventory/Sensors"
+	s.compHealthBaseV2 = s.stateBaseV2 + "/Health"
+	s.eventRulesBaseV2 = s.apiRootV2 + "/EventRules"
+	s.rfEventLogBaseV2 = s.apiRootV2 + "/Events"
+	s.jobsBaseV2 = s.apiRootV2 + "/jobs"
 	s.invDiscoverBaseV2 = s.apiRootV2 + "/Inventory/Discover"
 	s.invDiscStatusBaseV2 = s.apiRootV2 + "/Inventory/DiscoveryStatus"
 	s.subscriptionBaseV2 = s.apiRootV2 + "/Subscriptions"
@@ -619,6 +647,8 @@ func TestMain(m *testing.M) {
 	s.powerMapBaseV2 = s.sysInfoBaseV2 + "/powermaps"
 
 	s.smapCompEP = NewSyncMap(ComponentEndpointSMap(s))
+	s.sensorCache = NewSensorCache(sensorCacheTTLDefault, sensorBMCIntervalDefault)
+	s.eventRules = NewEventRuleSet([]*sm.EventRule{})
 
 	s.msgbusHandle = nil
 
@@ -799,6 +829,37 @@ func TestDoReadyGet(t *testing.T) {
 	}
 }
 
+func TestDoClusterGet(t *testing.T) {
+	// Not coordinating with other instances
+	req, _ := http.NewRequest("GET", "https://localhost/hsm/v2/service/cluster", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Response code was %v; want 200", w.Code)
+	}
+	var status sm.ClusterStatus
+	json.Unmarshal(w.Body.Bytes(), &status)
+	if !status.Leader || len(status.Members) != 0 {
+		t.Errorf("Unexpected status: %+v", status)
+	}
+
+	s.cluster = NewCluster(s, "smd-1", 10*time.Second)
+	defer func() { s.cluster = nil }()
+	s.cluster.update(false, []*sm.ServiceInstance{
+		{ID: "smd-1", Started: "2026-10-19T11:00:00Z", LastHeartbeat: "2026-10-19T11:37:00Z", Live: true},
+		{ID: "smd-2", Started: "2026-10-19T10:00:00Z", LastHeartbeat: "2026-10-19T11:37:05Z", Leader: true, Live: true},
+	})
+	req, _ = http.NewRequest("GET", "https://localhost/hsm/v2/service/cluster", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	expected := `{"ID":"smd-1","Leader":false,"Members":[` +
+		`{"ID":"smd-1","Started":"2026-10-19T11:00:00Z","LastHeartbeat":"2026-10-19T11:37:00Z","Leader":false,"Live":true},` +
+		`{"ID":"smd-2","Started":"2026-10-19T10:00:00Z","LastHeartbeat":"2026-10-19T11:37:05Z","Leader":true,"Live":true}]}` + "\n"
+	if w.Code != http.StatusOK || w.Body.String() != expected {
+		t.Errorf("Expected %v '%s'; Received %v '%s'", http.StatusOK, expected, w.Code, w.Body)
+	}
+}
+
 func TestDoLivenessGet(t *testing.T) {
 	tests := []struct {
 		reqType      string
@@ -901,6 +962,401 @@ func TestDoComponentGet(t *testing.T) {
 	}
 }
 
+func TestDoCompHealthGet(t *testing.T) {
+	enabledFlg := true
+	testComp := base.Component{
+		ID:      "x0c0s27b0n0",
+		Type:    "Node",
+		State:   "Ready",
+		Flag:    "Alert",
+		Enabled: &enabledFlg,
+	}
+	testHC := sm.CompHealthContributor{
+		CompID:  "x0c0s27b0n0",
+		ID:      "x0c0s27b0n0d3",
+		Type:    "Memory",
+		OdataID: "/redfish/v1/Systems/Node0/Memory/DIMM3",
+		Health:  "Critical",
+		Flag:    "Alert",
+		Source:  "Discovery",
+	}
+	tests := []struct {
+		reqURI       string
+		hmsdsRespID  *base.Component
+		hmsdsRespHCs []*sm.CompHealthContributor
+		hmsdsRespErr error
+		expectedCode int
+		expectedResp []byte
+	}{{
+		reqURI:       "https://localhost/hsm/v2/State/Health/x0c0s27b0n0",
+		hmsdsRespID:  &testComp,
+		hmsdsRespHCs: []*sm.CompHealthContributor{&testHC},
+		expectedCode: http.StatusOK,
+		expectedResp: json.RawMessage(`{"ID":"x0c0s27b0n0","Type":"Node","State":"Ready","Flag":"Alert","Contributors":[{"ID":"x0c0s27b0n0d3","Type":"Memory","OdataID":"/redfish/v1/Systems/Node0/Memory/DIMM3","Health":"Critical","Flag":"Alert","Source":"Discovery"}]}
+`),
+	}, {
+		reqURI:       "https://localhost/hsm/v2/State/Health/x0c0s27b0n0",
+		hmsdsRespID:  nil,
+		expectedCode: http.StatusNotFound,
+		expectedResp: json.RawMessage(`{"type":"about:blank","title":"Not Found","detail":"no such xname.","status":404}
+`),
+	}, {
+		reqURI:       "https://localhost/hsm/v2/State/Health/foo",
+		expectedCode: http.StatusBadRequest,
+		expectedResp: json.RawMessage(`{"type":"about:blank","title":"Bad Request","detail":"invalid xname","status":400}
+`),
+	}, {
+		reqURI:       "https://localhost/hsm/v2/State/Health/x0c0s27b0n0",
+		hmsdsRespID:  &testComp,
+		hmsdsRespErr: errors.New("unexpected DB error"),
+		expectedCode: http.StatusInternalServerError,
+		expectedResp: json.RawMessage(`{"type":"about:blank","title":"Internal Server Error","detail":"failed to query DB.","status":500}
+`),
+	}}
+
+	for i, test := range tests {
+		results.GetComponentByID.Return.id = test.hmsdsRespID
+		results.GetComponentByID.Return.err = nil
+		results.GetCompHealthContributors.Input.id = ""
+		results.GetCompHealthContributors.Return.hcs = test.hmsdsRespHCs
+		results.GetCompHealthContributors.Return.err = test.hmsdsRespErr
+		req, err := http.NewRequest("GET", test.reqURI, nil)
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected while creating request", err)
+		}
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+		if w.Code != test.expectedCode {
+			t.Errorf("Test %v Failed: Response code was %v; want %v", i, w.Code, test.expectedCode)
+		}
+		if test.hmsdsRespID != nil && results.GetCompHealthContributors.Input.id != test.hmsdsRespID.ID {
+			t.Errorf("Test %v Failed: Expected comp '%v'; Received comp '%v'", i, test.hmsdsRespID.ID, results.GetCompHealthContributors.Input.id)
+		}
+		if bytes.Compare(test.expectedResp, w.Body.Bytes()) != 0 {
+			t.Errorf("Test %v Failed: Expected body is '%v'; Received '%v'", i, string(test.expectedResp), w.Body)
+		}
+	}
+}
+
+func TestDoCompLabelsGet(t *testing.T) {
+	testLabels := sm.ComponentLabels{
+		ID:     "x0c0s27b0n0",
+		Labels: map[string]string{"gpu": "a100"},
+	}
+	tests := []struct {
+		reqURI       string
+		hmsdsResp    *sm.ComponentLabels
+		hmsdsRespErr error
+		expectedCode int
+		expectedResp []byte
+	}{{
+		reqURI:       "https://localhost/hsm/v2/State/Components/x0c0s27b0n0/Labels",
+		hmsdsResp:    &testLabels,
+		expectedCode: http.StatusOK,
+		expectedResp: json.RawMessage(`{"ID":"x0c0s27b0n0","Labels":{"gpu":"a100"}}
+`),
+	}, {
+		reqURI:       "https://localhost/hsm/v2/State/Components/x0c0s27b0n0/Labels",
+		hmsdsResp:    nil,
+		expectedCode: http.StatusNotFound,
+		expectedResp: json.RawMessage(`{"type":"about:blank","title":"Not Found","detail":"no such xname.","status":404}
+`),
+	}, {
+		reqURI:       "https://localhost/hsm/v2/State/Components/foo/Labels",
+		expectedCode: http.StatusBadRequest,
+		expectedResp: json.RawMessage(`{"type":"about:blank","title":"Bad Request","detail":"invalid xname","status":400}
+`),
+	}, {
+		reqURI:       "https://localhost/hsm/v2/State/Components/x0c0s27b0n0/Labels",
+		hmsdsRespErr: errors.New("unexpected DB error"),
+		expectedCode: http.StatusInternalServerError,
+		expectedResp: json.RawMessage(`{"type":"about:blank","title":"Internal Server Error","detail":"failed to query DB.","status":500}
+`),
+	}}
+
+	for i, test := range tests {
+		results.GetCompLabels.Return.labels = test.hmsdsResp
+		results.GetCompLabels.Return.err = test.hmsdsRespErr
+		req, err := http.NewRequest("GET", test.reqURI, nil)
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected while creating request", err)
+		}
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+		if w.Code != test.expectedCode {
+			t.Errorf("Test %v Failed: Response code was %v; want %v", i, w.Code, test.expectedCode)
+		}
+		if bytes.Compare(test.expectedResp, w.Body.Bytes()) != 0 {
+			t.Errorf("Test %v Failed: Expected body is '%v'; Received '%v'", i, string(test.expectedResp), w.Body)
+		}
+	}
+}
+
+func TestDoCompLabelsPut(t *testing.T) {
+	tests := []struct {
+		reqURI         string
+		reqBody        []byte
+		hmsdsRespFound bool
+		expectedLabels map[string]string
+		expectedCode   int
+		expectedResp   []byte
+	}{{
+		reqURI:         "https://localhost/hsm/v2/State/Components/x0c0s27b0n0/Labels",
+		reqBody:        json.RawMessage(`{"Labels":{"gpu":"a100","example.com/drain":""}}`),
+		hmsdsRespFound: true,
+		expectedLabels: map[string]string{"gpu": "a100", "example.com/drain": ""},
+		expectedCode:   http.StatusOK,
+		expectedResp: json.RawMessage(`{"ID":"x0c0s27b0n0","Labels":{"example.com/drain":"","gpu":"a100"}}
+`),
+	}, {
+		reqURI:         "https://localhost/hsm/v2/State/Components/x0c0s27b0n0/Labels",
+		reqBody:        json.RawMessage(`{"Labels":{"gpu":"a100"}}`),
+		hmsdsRespFound: false,
+		expectedLabels: map[string]string{"gpu": "a100"},
+		expectedCode:   http.StatusNotFound,
+		expectedResp: json.RawMessage(`{"type":"about:blank","title":"Not Found","detail":"no such xname.","status":404}
+`),
+	}, {
+		reqURI:       "https://localhost/hsm/v2/State/Components/x0c0s27b0n0/Labels",
+		reqBody:      json.RawMessage(`{"Labels":{"-gpu":"a100"}}`),
+		expectedCode: http.StatusBadRequest,
+		expectedResp: json.RawMessage(`{"type":"about:blank","title":"Bad Request","detail":"Invalid label key, must be [prefix/]name","status":400}
+`),
+	}, {
+		reqURI:       "https://localhost/hsm/v2/State/Components/x0c0s27b0n0/Labels",
+		reqBody:      json.RawMessage(`{"ID":"x0c0s28b0n0","Labels":{}}`),
+		expectedCode: http.StatusBadRequest,
+		expectedResp: json.RawMessage(`{"type":"about:blank","title":"Bad Request","detail":"` + ErrSMDIDConf.Error() + `","status":400}
+`),
+	}}
+
+	for i, test := range tests {
+		results.ReplaceCompLabels.Input.id = ""
+		results.ReplaceCompLabels.Input.labels = nil
+		results.ReplaceCompLabels.Return.found = test.hmsdsRespFound
+		results.ReplaceCompLabels.Return.err = nil
+		req, err := http.NewRequest("PUT", test.reqURI, bytes.NewBuffer(test.reqBody))
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected while creating request", err)
+		}
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+		if w.Code != test.expectedCode {
+			t.Errorf("Test %v Failed: Response code was %v; want %v", i, w.Code, test.expectedCode)
+		}
+		if !reflect.DeepEqual(test.expectedLabels, results.ReplaceCompLabels.Input.labels) {
+			t.Errorf("Test %v Failed: Expected labels '%v'; Received '%v'", i, test.expectedLabels, results.ReplaceCompLabels.Input.labels)
+		}
+		if bytes.Compare(test.expectedResp, w.Body.Bytes()) != 0 {
+			t.Errorf("Test %v Failed: Expected body is '%v'; Received '%v'", i, string(test.expectedResp), w.Body)
+		}
+	}
+}
+
+func TestDoCompBulkLabelsPatch(t *testing.T) {
+	a100 := "a100"
+	tests := []struct {
+		reqBody        []byte
+		expectedIDs    []string
+		expectedLabels map[string]*string
+		expectedCode   int
+		expectedResp   []byte
+	}{{
+		reqBody:        json.RawMessage(`{"ComponentIDs":["x0c0s27b0n0","X0C0S28B0N0"],"Labels":{"gpu":"a100","drain":null}}`),
+		expectedIDs:    []string{"x0c0s27b0n0", "x0c0s28b0n0"},
+		expectedLabels: map[string]*string{"gpu": &a100, "drain": nil},
+		expectedCode:   http.StatusNoContent,
+		expectedResp:   []byte{},
+	}, {
+		reqBody:      json.RawMessage(`{"Labels":{"gpu":"a100"}}`),
+		expectedCode: http.StatusBadRequest,
+		expectedResp: json.RawMessage(`{"type":"about:blank","title":"Bad Request","detail":"No component IDs given","status":400}
+`),
+	}, {
+		reqBody:      json.RawMessage(`{"ComponentIDs":["x0c0s27b0n0"],"Labels":{"gpu":"a 100"}}`),
+		expectedCode: http.StatusBadRequest,
+		expectedResp: json.RawMessage(`{"type":"about:blank","title":"Bad Request","detail":"Invalid label value","status":400}
+`),
+	}}
+
+	for i, test := range tests {
+		results.PatchCompLabels.Input.ids = nil
+		results.PatchCompLabels.Input.labels = nil
+		results.PatchCompLabels.Return.ids = test.expectedIDs
+		results.PatchCompLabels.Return.err = nil
+		req, err := http.NewRequest("PATCH", "https://localhost/hsm/v2/State/Components/BulkLabels", bytes.NewBuffer(test.reqBody))
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected while creating request", err)
+		}
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+		if w.Code != test.expectedCode {
+			t.Errorf("Test %v Failed: Response code was %v; want %v", i, w.Code, test.expectedCode)
+		}
+		if !reflect.DeepEqual(test.expectedIDs, results.PatchCompLabels.Input.ids) {
+			t.Errorf("Test %v Failed: Expected ids '%v'; Received '%v'", i, test.expectedIDs, results.PatchCompLabels.Input.ids)
+		}
+		if !reflect.DeepEqual(test.expectedLabels, results.PatchCompLabels.Input.labels) {
+			t.Errorf("Test %v Failed: Expected labels '%v'; Received '%v'", i, test.expectedLabels, results.PatchCompLabels.Input.labels)
+		}
+		if bytes.Compare(test.expectedResp, w.Body.Bytes()) != 0 {
+			t.Errorf("Test %v Failed: Expected body is '%v'; Received '%v'", i, string(test.expectedResp), w.Body)
+		}
+	}
+}
+
+func TestDoCompsPatch(t *testing.T) {
+	disabled := false
+	app := "Application"
+	tests := []struct {
+		reqBody         []byte
+		changedIDs      []string
+		failures        []sm.CompPatchFailure
+		expectedPatches []*sm.CompPatch
+		expectedCode    int
+		expectedResp    []byte
+	}{{
+		reqBody:    json.RawMessage(`{"Components":[{"ID":"X0C0S27B0N0","State":"ready","Enabled":false},{"ID":"x0c0s28b0n0","State":"bogus"},{"ID":"x0c0s29b0n0","Role":"application"}]}`),
+		changedIDs: []string{"x0c0s27b0n0"},
+		failures:   []sm.CompPatchFailure{{ID: "x0c0s29b0n0", Reason: "no such component"}},
+		expectedPatches: []*sm.CompPatch{
+			{ID: "x0c0s27b0n0", State: "Ready", Flag: "OK", Enabled: &disabled},
+			{ID: "x0c0s29b0n0", Role: &app},
+		},
+		expectedCode: http.StatusOK,
+		expectedResp: json.RawMessage(`{"Counts":{"Total":3,"Success":1,"Failure":2},"Success":{"ComponentIDs":["x0c0s27b0n0"]},"Failure":[{"ID":"x0c0s28b0n0","Reason":"state 'bogus' is invalid"},{"ID":"x0c0s29b0n0","Reason":"no such component"}]}
+`),
+	}, {
+		reqBody:      json.RawMessage(`{"Components":[]}`),
+		expectedCode: http.StatusBadRequest,
+		expectedResp: json.RawMessage(`{"type":"about:blank","title":"Bad Request","detail":"Missing Components","status":400}
+`),
+	}}
+
+	for i, test := range tests {
+		results.PatchComponents.Input.patches = nil
+		results.PatchComponents.Input.source = ""
+		results.PatchComponents.Return.changedIds = test.changedIDs
+		results.PatchComponents.Return.failures = test.failures
+		results.PatchComponents.Return.err = nil
+		req, err := http.NewRequest("PATCH", "https://localhost/hsm/v2/State/Components", bytes.NewBuffer(test.reqBody))
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected while creating request", err)
+		}
+		req.Header.Set("HMS-Service", "cray-bos")
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+		if w.Code != test.expectedCode {
+			t.Errorf("Test %v Failed: Response code was %v; want %v", i, w.Code, test.expectedCode)
+		}
+		if !reflect.DeepEqual(test.expectedPatches, results.PatchComponents.Input.patches) {
+			t.Errorf("Test %v Failed: Expected patches '%v'; Received '%v'", i, test.expectedPatches, results.PatchComponents.Input.patches)
+		}
+		if test.expectedPatches != nil && results.PatchComponents.Input.source != "cray-bos" {
+			t.Errorf("Test %v Failed: Expected source 'cray-bos'; Received '%s'", i, results.PatchComponents.Input.source)
+		}
+		if bytes.Compare(test.expectedResp, w.Body.Bytes()) != 0 {
+			t.Errorf("Test %v Failed: Expected body is '%v'; Received '%v'", i, string(test.expectedResp), w.Body)
+		}
+	}
+}
+
+func TestDoCompHistoryGet(t *testing.T) {
+	ent1 := &sm.CompHistoryEntry{
+		ID:          5,
+		Timestamp:   "2026-10-19T11:36:00Z",
+		ComponentID: "x0c0s1b0n0",
+		Field:       sm.CompHistFieldState,
+		OldValue:    "Off",
+		NewValue:    "On",
+		Source:      "cray-capmc",
+	}
+	tests := []struct {
+		reqURI         string
+		hmsdsResp      []*sm.CompHistoryEntry
+		hmsdsRespErr   error
+		expectedID     string
+		expectedFilter hmsds.CompHistFilter
+		expectedCode   int
+		expectedResp   []byte
+	}{{
+		reqURI:     "https://localhost/hsm/v2/State/Components/x0c0s01b0n0/History?field=state&field=Flag&source=cray-capmc&since=2026-10-19T00:00:00Z&until=2026-10-19T12:00:00Z",
+		hmsdsResp:  []*sm.CompHistoryEntry{ent1},
+		expectedID: "x0c0s1b0n0",
+		expectedFilter: hmsds.CompHistFilter{
+			Field:  []string{"state", "Flag"},
+			Source: []string{"cray-capmc"},
+			Since:  "2026-10-19T00:00:00Z",
+			Until:  "2026-10-19T12:00:00Z",
+		},
+		expectedCode: http.StatusOK,
+		expectedResp: json.RawMessage(`[{"ID":5,"Timestamp":"2026-10-19T11:36:00Z","ComponentID":"x0c0s1b0n0","Field":"State","OldValue":"Off","NewValue":"On","Source":"cray-capmc"}]` + "\n"),
+	}, {
+		reqURI:       "https://localhost/hsm/v2/State/Components/x0c0s1b0n0/History",
+		hmsdsResp:    []*sm.CompHistoryEntry{},
+		expectedID:   "x0c0s1b0n0",
+		expectedCode: http.StatusOK,
+		expectedResp: json.RawMessage(`[]` + "\n"),
+	}, {
+		reqURI:       "https://localhost/hsm/v2/State/Components/x0c0s1b0n0/History?field=nid",
+		expectedCode: http.StatusBadRequest,
+		expectedResp: json.RawMessage(`{"type":"about:blank","title":"Bad Request","detail":"Invalid field: nid","status":400}` + "\n"),
+	}, {
+		reqURI:       "https://localhost/hsm/v2/State/Components/foo/History",
+		expectedCode: http.StatusBadRequest,
+		expectedResp: json.RawMessage(`{"type":"about:blank","title":"Bad Request","detail":"invalid xname","status":400}` + "\n"),
+	}, {
+		reqURI:       "https://localhost/hsm/v2/State/Components/x0c0s1b0n0/History?since=yesterday",
+		hmsdsRespErr: hmsds.ErrHMSDSArgBadTimeFormat,
+		expectedID:   "x0c0s1b0n0",
+		expectedFilter: hmsds.CompHistFilter{
+			Since: "yesterday",
+		},
+		expectedCode: http.StatusBadRequest,
+		expectedResp: json.RawMessage(`{"type":"about:blank","title":"Bad Request","detail":"bad query param: Argument was not in a valid RFC3339 time format","status":400}` + "\n"),
+	}}
+
+	for i, test := range tests {
+		results.GetCompHistory.Return.entries = test.hmsdsResp
+		results.GetCompHistory.Return.err = test.hmsdsRespErr
+		results.GetCompHistory.Input.id = ""
+		results.GetCompHistory.Input.f_opts = nil
+		req, err := http.NewRequest("GET", test.reqURI, nil)
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected while creating request", err)
+		}
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+		if w.Code != test.expectedCode {
+			t.Errorf("Test %v Failed: Response code was %v; want %v", i, w.Code, test.expectedCode)
+		}
+		if test.expectedID != results.GetCompHistory.Input.id {
+			t.Errorf("Test %v Failed: Expected id is '%v'; Received '%v'", i, test.expectedID, results.GetCompHistory.Input.id)
+		}
+		if test.expectedID != "" {
+			f := hmsds.CompHistFilter{}
+			for _, opt := range results.GetCompHistory.Input.f_opts {
+				opt(&f)
+			}
+			if !reflect.DeepEqual(test.expectedFilter.Field, f.Field) ||
+				!reflect.DeepEqual(test.expectedFilter.Source, f.Source) ||
+				test.expectedFilter.Since != f.Since ||
+				test.expectedFilter.Until != f.Until {
+				t.Errorf("Test %v Failed: Expected filter '%v'; Received '%v'", i, test.expectedFilter, f)
+			}
+		}
+		if bytes.Compare(test.expectedResp, w.Body.Bytes()) != 0 {
+			t.Errorf("Test %v Failed: Expected body is '%v'; Received '%v'", i, string(test.expectedResp), w.Body)
+		}
+	}
+}
+
 func TestDoComponentByNIDGet(t *testing.T) {
 	enabledFlg := true
 	testComp := base.Component{
@@ -1125,7 +1581,7 @@ func TestDoComponentsGet(t *testing.T) {
 		json.RawMessage(`{"Components":[{"ID":"x0c0s14b0n0","Type":"Node","State":"On","Flag":"OK","Enabled":true,"SoftwareStatus":"AdminStatus","Role":"Compute","NID":448,"NetType":"Sling","Arch":"X86"},{"ID":"x0c0s15b0n0","Type":"Node","State":"On","Flag":"OK","Enabled":true,"SoftwareStatus":"AdminStatus","Role":"Compute","NID":480,"NetType":"Sling","Arch":"X86"},{"ID":"x0c0s18b0n0","Type":"Node","State":"Off","Flag":"OK","Enabled":true,"SoftwareStatus":"AdminStatus","Role":"Compute","NID":576,"NetType":"Sling","Arch":"X86"},{"ID":"x0c0s22b0n0","Type":"Node","State":"Off","Flag":"OK","Enabled":true,"SoftwareStatus":"AdminStatus","Role":"Compute","NID":704,"NetType":"Sling","Arch":"X86"},{"ID":"x0c0s24b0n0","Type":"Node","State":"Off","Flag":"OK","Enabled":true,"SoftwareStatus":"AdminStatus","Role":"Compute","NID":786,"NetType":"Sling","Arch":"X86"},{"ID":"x0c0s25b0n0","Type":"Node","State":"On","Flag":"OK","Enabled":true,"SoftwareStatus":"AdminStatus","Role":"Compute","NID":800,"NetType":"Sling","Arch":"X86"},{"ID":"x0c0s26b0n0","Type":"Node","State":"On","Flag":"OK","Enabled":true,"SoftwareStatus":"AdminStatus","Role":"Compute","NID":832,"NetType":"Sling","Arch":"X86"},{"ID":"x0c0s27b0n0","Type":"Node","State":"On","Flag":"OK","Enabled":true,"SoftwareStatus":"AdminStatus","Role":"Compute","NID":864,"NetType":"Sling","Arch":"X86"}]}
 `),
 	}, {
-		"PATCH",
+		"PUT",
 		"https://localhost/hsm/v2/State/Components?type=node",
 		[]*base.Component{
 			&base.Component{"x0c0s14b0n0", "Node", "On", "OK", &enabledFlg, "AdminStatus", "Compute", "", "448", "", "Sling", "X86", "", false, false},
@@ -4780,74 +5236,257 @@ func TestDoHWInvByLocationQueryGet(t *testing.T) {
 }
 
 //////////////////////////////////////////////////////////////////////////////
-// HW Inventory History
+// HW Inventory Firmware
 //////////////////////////////////////////////////////////////////////////////
 
-func TestDoHWInvHistByLocationGet(t *testing.T) {
-	testHWInvHist1 := sm.HWInvHist{
-		ID:        "x5c4s3b2n1p0",
-		FruId:     "MFR-PARTNUMBER-SERIALNUMBER_1",
-		Timestamp: "2020-01-21 11:36:00",
-		EventType: "Added",
-	}
-	testHWInvHist3 := sm.HWInvHist{
-		ID:        "x5c4s3b2n1p0",
-		FruId:     "MFR-PARTNUMBER-SERIALNUMBER_1",
-		Timestamp: "2020-01-21 12:00:00",
-		EventType: "Removed",
-	}
-	testHWInvHist5 := sm.HWInvHist{
-		ID:        "x5c4s3b2n1p0",
-		FruId:     "MFR-PARTNUMBER-SERIALNUMBER_2",
-		Timestamp: "2020-01-21 12:10:00",
-		EventType: "Added",
+func TestDoHWInvFirmwareGet(t *testing.T) {
+	testFw1 := sm.HWInvFirmware{
+		ID:           "BIOS",
+		RfEndpointID: "x0c0s0b0",
+		Inventory:    rf.FirmwareInventoryName,
+		RelatedID:    "x0c0s0b0n0",
+		RelatedType:  "Node",
+		Version:      "2.0.1",
+		Updateable:   true,
+		OdataID:      "/redfish/v1/UpdateService/FirmwareInventory/BIOS",
 	}
-
-	payload1, _ := json.Marshal(sm.HWInvHistArray{
-		ID:      testHWInvHist1.ID,
-		History: []*sm.HWInvHist{&testHWInvHist1, &testHWInvHist3, &testHWInvHist5},
-	})
-	payload2, _ := json.Marshal(sm.HWInvHistArray{
-		ID:      testHWInvHist1.ID,
-		History: []*sm.HWInvHist{&testHWInvHist1},
-	})
+	payload1, _ := json.Marshal(sm.HWInvFirmwareArray{Firmware: []*sm.HWInvFirmware{&testFw1}})
 
 	tests := []struct {
-		reqType        string
 		reqURI         string
-		hmsdsResp      []*sm.HWInvHist
+		hmsdsResp      []*sm.HWInvFirmware
 		hmsdsRespErr   error
-		expectedFilter *hmsds.HWInvHistFilter
+		expectedFilter *hmsds.HWInvFirmwareFilter
+		expectedCode   int
 		expectedResp   []byte
 	}{{
-		reqType:        "GET",
-		reqURI:         "https://localhost/hsm/v2/Inventory/Hardware/History/" + testHWInvHist1.ID,
-		hmsdsResp:      []*sm.HWInvHist{&testHWInvHist1, &testHWInvHist3, &testHWInvHist5},
-		hmsdsRespErr:   nil,
-		expectedFilter: &hmsds.HWInvHistFilter{ID: []string{testHWInvHist1.ID}},
+		reqURI:         "https://localhost/hsm/v2/Inventory/Firmware/x0c0s0b0n0",
+		hmsdsResp:      []*sm.HWInvFirmware{&testFw1},
+		expectedFilter: &hmsds.HWInvFirmwareFilter{RelatedID: []string{"x0c0s0b0n0"}},
+		expectedCode:   http.StatusOK,
 		expectedResp:   payload1,
 	}, {
-		reqType:      "GET",
-		reqURI:       "https://localhost/hsm/v2/Inventory/Hardware/History/" + testHWInvHist1.ID + "?starttime=2020-01-21T12:01:00Z&endtime=2020-01-21T11:00:00Z&eventtype=added",
-		hmsdsResp:    []*sm.HWInvHist{&testHWInvHist1},
-		hmsdsRespErr: nil,
-		expectedFilter: &hmsds.HWInvHistFilter{
-			ID:        []string{testHWInvHist1.ID},
-			EventType: []string{"Added"},
-			StartTime: "2020-01-21T12:01:00Z",
-			EndTime:   "2020-01-21T11:00:00Z",
+		reqURI:    "https://localhost/hsm/v2/Inventory/Firmware?device=x0c0s0b0n0&version=2.0.1&updateable=true&inventory=firmwareinventory",
+		hmsdsResp: []*sm.HWInvFirmware{&testFw1},
+		expectedFilter: &hmsds.HWInvFirmwareFilter{
+			RelatedID:  []string{"x0c0s0b0n0"},
+			Version:    []string{"2.0.1"},
+			Updateable: []string{"true"},
+			Inventory:  []string{rf.FirmwareInventoryName},
 		},
-		expectedResp: payload2,
+		expectedCode: http.StatusOK,
+		expectedResp: payload1,
 	}, {
-		reqType:        "GET",
-		reqURI:         "https://localhost/hsm/v2/Inventory/Hardware/History/" + testHWInvHist1.ID + "?eventtype=foo",
-		hmsdsResp:      []*sm.HWInvHist{},
-		hmsdsRespErr:   hmsds.ErrHMSDSArgMissing,
+		reqURI:         "https://localhost/hsm/v2/Inventory/Firmware?device=foo",
 		expectedFilter: nil,
-		expectedResp:   json.RawMessage(`{"type":"about:blank","title":"Bad Request","detail":"Invalid HWInvHist event type","status":400}` + "\n"),
+		expectedCode:   http.StatusBadRequest,
+		expectedResp:   json.RawMessage(`{"type":"about:blank","title":"Bad Request","detail":"Invalid xname","status":400}` + "\n"),
 	}, {
-		reqType:        "GET",
-		reqURI:         "https://localhost/hsm/v2/Inventory/Hardware/History/" + testHWInvHist1.ID,
+		reqURI:         "https://localhost/hsm/v2/Inventory/Firmware?updateable=foo",
+		expectedFilter: nil,
+		expectedCode:   http.StatusBadRequest,
+		expectedResp:   json.RawMessage(`{"type":"about:blank","title":"Bad Request","detail":"Invalid boolean for updateable","status":400}` + "\n"),
+	}, {
+		reqURI:         "https://localhost/hsm/v2/Inventory/Firmware/x0c0s0b0n0",
+		hmsdsRespErr:   errors.New("DB failure"),
+		expectedFilter: &hmsds.HWInvFirmwareFilter{RelatedID: []string{"x0c0s0b0n0"}},
+		expectedCode:   http.StatusInternalServerError,
+		expectedResp:   json.RawMessage(`{"type":"about:blank","title":"Internal Server Error","detail":"failed to query DB.","status":500}` + "\n"),
+	}}
+
+	for i, test := range tests {
+		results.GetHWInvFirmwareFilter.Input.f = nil
+		results.GetHWInvFirmwareFilter.Return.fws = test.hmsdsResp
+		results.GetHWInvFirmwareFilter.Return.err = test.hmsdsRespErr
+
+		req, err := http.NewRequest("GET", test.reqURI, nil)
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected while creating request", err)
+		}
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+		if w.Code != test.expectedCode {
+			t.Errorf("Test %v Failed: Response code was %v; want %v", i, w.Code, test.expectedCode)
+		}
+		f := results.GetHWInvFirmwareFilter.Input.f
+		if (test.expectedFilter == nil) != (f == nil) {
+			t.Errorf("Test %v Failed: Expected filter is '%v'; Received '%v'", i, test.expectedFilter, f)
+		} else if f != nil && (!reflect.DeepEqual(test.expectedFilter.RelatedID, f.RelatedID) ||
+			!reflect.DeepEqual(test.expectedFilter.Version, f.Version) ||
+			!reflect.DeepEqual(test.expectedFilter.Updateable, f.Updateable) ||
+			!reflect.DeepEqual(test.expectedFilter.Inventory, f.Inventory)) {
+			t.Errorf("Test %v Failed: Expected filter is '%v'; Received '%v'", i, test.expectedFilter, f)
+		}
+		if strings.TrimSpace(string(test.expectedResp)) !=
+			strings.TrimSpace(string(w.Body.Bytes())) {
+			t.Errorf("Test %v Failed: Expected body is '%v'; Received '%v'",
+				i, string(test.expectedResp), w.Body)
+		}
+	}
+}
+
+func TestDoHWInvFanGet(t *testing.T) {
+	testFan1 := sm.HWInvFan{
+		ID:           "Fan2",
+		ParentID:     "x0c0s0e0",
+		ParentType:   "NodeEnclosure",
+		RfEndpointID: "x0c0s0b0",
+		Status:       "Populated",
+		Health:       "Warning",
+		Flag:         "Warning",
+		FRUID:        "Fan.Delta.FT100.FAN2SN",
+		OdataID:      "/redfish/v1/Chassis/Enclosure/ThermalSubsystem/Fans/Fan2",
+	}
+	payload1, _ := json.Marshal(sm.HWInvFanArray{Fans: []*sm.HWInvFan{&testFan1}})
+
+	tests := []struct {
+		reqURI         string
+		hmsdsResp      []*sm.HWInvFan
+		hmsdsRespErr   error
+		expectedFilter *hmsds.HWInvFanFilter
+		expectedCode   int
+		expectedResp   []byte
+	}{{
+		reqURI:         "https://localhost/hsm/v2/Inventory/Fans/x0c0s0e0",
+		hmsdsResp:      []*sm.HWInvFan{&testFan1},
+		expectedFilter: &hmsds.HWInvFanFilter{ParentID: []string{"x0c0s0e0"}},
+		expectedCode:   http.StatusOK,
+		expectedResp:   payload1,
+	}, {
+		reqURI:    "https://localhost/hsm/v2/Inventory/Fans?parent=x0c0s0e0&health=warning&status=populated",
+		hmsdsResp: []*sm.HWInvFan{&testFan1},
+		expectedFilter: &hmsds.HWInvFanFilter{
+			ParentID: []string{"x0c0s0e0"},
+			Health:   []string{"Warning"},
+			Status:   []string{"Populated"},
+		},
+		expectedCode: http.StatusOK,
+		expectedResp: payload1,
+	}, {
+		reqURI:         "https://localhost/hsm/v2/Inventory/Fans?health=!ok",
+		hmsdsResp:      []*sm.HWInvFan{&testFan1},
+		expectedFilter: &hmsds.HWInvFanFilter{Health: []string{"!OK"}},
+		expectedCode:   http.StatusOK,
+		expectedResp:   payload1,
+	}, {
+		reqURI:         "https://localhost/hsm/v2/Inventory/Fans?parent=foo",
+		expectedFilter: nil,
+		expectedCode:   http.StatusBadRequest,
+		expectedResp:   json.RawMessage(`{"type":"about:blank","title":"Bad Request","detail":"Invalid xname","status":400}` + "\n"),
+	}, {
+		reqURI:         "https://localhost/hsm/v2/Inventory/Fans?health=foo",
+		expectedFilter: nil,
+		expectedCode:   http.StatusBadRequest,
+		expectedResp:   json.RawMessage(`{"type":"about:blank","title":"Bad Request","detail":"Invalid health","status":400}` + "\n"),
+	}, {
+		reqURI:         "https://localhost/hsm/v2/Inventory/Fans/x0c0s0e0",
+		hmsdsRespErr:   errors.New("DB failure"),
+		expectedFilter: &hmsds.HWInvFanFilter{ParentID: []string{"x0c0s0e0"}},
+		expectedCode:   http.StatusInternalServerError,
+		expectedResp:   json.RawMessage(`{"type":"about:blank","title":"Internal Server Error","detail":"failed to query DB.","status":500}` + "\n"),
+	}}
+
+	for i, test := range tests {
+		results.GetHWInvFanFilter.Input.f = nil
+		results.GetHWInvFanFilter.Return.fans = test.hmsdsResp
+		results.GetHWInvFanFilter.Return.err = test.hmsdsRespErr
+
+		req, err := http.NewRequest("GET", test.reqURI, nil)
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected while creating request", err)
+		}
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+		if w.Code != test.expectedCode {
+			t.Errorf("Test %v Failed: Response code was %v; want %v", i, w.Code, test.expectedCode)
+		}
+		f := results.GetHWInvFanFilter.Input.f
+		if (test.expectedFilter == nil) != (f == nil) {
+			t.Errorf("Test %v Failed: Expected filter is '%v'; Received '%v'", i, test.expectedFilter, f)
+		} else if f != nil && (!reflect.DeepEqual(test.expectedFilter.ParentID, f.ParentID) ||
+			!reflect.DeepEqual(test.expectedFilter.Health, f.Health) ||
+			!reflect.DeepEqual(test.expectedFilter.Status, f.Status)) {
+			t.Errorf("Test %v Failed: Expected filter is '%v'; Received '%v'", i, test.expectedFilter, f)
+		}
+		if strings.TrimSpace(string(test.expectedResp)) !=
+			strings.TrimSpace(string(w.Body.Bytes())) {
+			t.Errorf("Test %v Failed: Expected body is '%v'; Received '%v'",
+				i, string(test.expectedResp), w.Body)
+		}
+	}
+}
+
+//////////////////////////////////////////////////////////////////////////////
+// HW Inventory History
+//////////////////////////////////////////////////////////////////////////////
+
+func TestDoHWInvHistByLocationGet(t *testing.T) {
+	testHWInvHist1 := sm.HWInvHist{
+		ID:        "x5c4s3b2n1p0",
+		FruId:     "MFR-PARTNUMBER-SERIALNUMBER_1",
+		Timestamp: "2020-01-21 11:36:00",
+		EventType: "Added",
+	}
+	testHWInvHist3 := sm.HWInvHist{
+		ID:        "x5c4s3b2n1p0",
+		FruId:     "MFR-PARTNUMBER-SERIALNUMBER_1",
+		Timestamp: "2020-01-21 12:00:00",
+		EventType: "Removed",
+	}
+	testHWInvHist5 := sm.HWInvHist{
+		ID:        "x5c4s3b2n1p0",
+		FruId:     "MFR-PARTNUMBER-SERIALNUMBER_2",
+		Timestamp: "2020-01-21 12:10:00",
+		EventType: "Added",
+	}
+
+	payload1, _ := json.Marshal(sm.HWInvHistArray{
+		ID:      testHWInvHist1.ID,
+		History: []*sm.HWInvHist{&testHWInvHist1, &testHWInvHist3, &testHWInvHist5},
+	})
+	payload2, _ := json.Marshal(sm.HWInvHistArray{
+		ID:      testHWInvHist1.ID,
+		History: []*sm.HWInvHist{&testHWInvHist1},
+	})
+
+	tests := []struct {
+		reqType        string
+		reqURI         string
+		hmsdsResp      []*sm.HWInvHist
+		hmsdsRespErr   error
+		expectedFilter *hmsds.HWInvHistFilter
+		expectedResp   []byte
+	}{{
+		reqType:        "GET",
+		reqURI:         "https://localhost/hsm/v2/Inventory/Hardware/History/" + testHWInvHist1.ID,
+		hmsdsResp:      []*sm.HWInvHist{&testHWInvHist1, &testHWInvHist3, &testHWInvHist5},
+		hmsdsRespErr:   nil,
+		expectedFilter: &hmsds.HWInvHistFilter{ID: []string{testHWInvHist1.ID}},
+		expectedResp:   payload1,
+	}, {
+		reqType:      "GET",
+		reqURI:       "https://localhost/hsm/v2/Inventory/Hardware/History/" + testHWInvHist1.ID + "?starttime=2020-01-21T12:01:00Z&endtime=2020-01-21T11:00:00Z&eventtype=added",
+		hmsdsResp:    []*sm.HWInvHist{&testHWInvHist1},
+		hmsdsRespErr: nil,
+		expectedFilter: &hmsds.HWInvHistFilter{
+			ID:        []string{testHWInvHist1.ID},
+			EventType: []string{"Added"},
+			StartTime: "2020-01-21T12:01:00Z",
+			EndTime:   "2020-01-21T11:00:00Z",
+		},
+		expectedResp: payload2,
+	}, {
+		reqType:        "GET",
+		reqURI:         "https://localhost/hsm/v2/Inventory/Hardware/History/" + testHWInvHist1.ID + "?eventtype=foo",
+		hmsdsResp:      []*sm.HWInvHist{},
+		hmsdsRespErr:   hmsds.ErrHMSDSArgMissing,
+		expectedFilter: nil,
+		expectedResp:   json.RawMessage(`{"type":"about:blank","title":"Bad Request","detail":"Invalid HWInvHist event type","status":400}` + "\n"),
+	}, {
+		reqType:        "GET",
+		reqURI:         "https://localhost/hsm/v2/Inventory/Hardware/History/" + testHWInvHist1.ID,
 		hmsdsResp:      []*sm.HWInvHist{},
 		hmsdsRespErr:   hmsds.ErrHMSDSArgMissing,
 		expectedFilter: &hmsds.HWInvHistFilter{ID: []string{testHWInvHist1.ID}},
@@ -8203,6 +8842,74 @@ func TestDoGroupsPost(t *testing.T) {
 		},
 		expectedResp: json.RawMessage(`{"type":"about:blank","title":"Conflict","detail":"operation would conflict with an existing group that has the same label or duplicate ids found in request.","status":409}` + "\n"),
 		expectError:  true,
+	}, {
+		reqType:      "POST",
+		reqURI:       "https://localhost/hsm/v2/groups",
+		reqBody:      json.RawMessage(`{"label":"computes","description":"All compute nodes","query":{"role":["compute"],"prefix":["x3000"]}}`),
+		hmsdsResp:    "computes",
+		hmsdsRespErr: nil,
+		expectedGroup: &sm.Group{
+			Label:       "computes",
+			Description: "All compute nodes",
+			Query: &sm.GroupQuery{
+				Role:   []string{"Compute"},
+				Prefix: []string{"x3000"},
+			},
+		},
+		expectedResp: json.RawMessage(`[{"URI":"/hsm/v2/groups/computes"}]` + "\n"),
+		expectError:  false,
+	}, {
+		reqType:       "POST",
+		reqURI:        "https://localhost/hsm/v2/groups",
+		reqBody:       json.RawMessage(`{"label":"computes","query":{"role":["compute"]},"members":{"ids":["x0c0s1b0n0"]}}`),
+		hmsdsResp:     "",
+		hmsdsRespErr:  nil,
+		expectedGroup: nil,
+		expectedResp:  json.RawMessage(`{"type":"about:blank","title":"Bad Request","detail":"couldn't validate group: group query cannot be combined with members, groups or exclusiveGroup","status":400}` + "\n"),
+		expectError:   true,
+	}, {
+		reqType:       "POST",
+		reqURI:        "https://localhost/hsm/v2/groups",
+		reqBody:       json.RawMessage(`{"label":"computes","query":{"role":["foo"]}}`),
+		hmsdsResp:     "",
+		hmsdsRespErr:  nil,
+		expectedGroup: nil,
+		expectedResp:  json.RawMessage(`{"type":"about:blank","title":"Bad Request","detail":"couldn't validate group: group query has an invalid or empty field","status":400}` + "\n"),
+		expectError:   true,
+	}, {
+		reqType:      "POST",
+		reqURI:       "https://localhost/hsm/v2/groups",
+		reqBody:      json.RawMessage(`{"label":"all_nodes","groups":["Compute","uan"],"members":{"ids":["x0c0s1b0n0"]}}`),
+		hmsdsResp:    "all_nodes",
+		hmsdsRespErr: nil,
+		expectedGroup: &sm.Group{
+			Label:   "all_nodes",
+			Groups:  []string{"compute", "uan"},
+			Members: sm.Members{IDs: []string{"x0c0s1b0n0"}},
+		},
+		expectedResp: json.RawMessage(`[{"URI":"/hsm/v2/groups/all_nodes"}]` + "\n"),
+		expectError:  false,
+	}, {
+		reqType:      "POST",
+		reqURI:       "https://localhost/hsm/v2/groups",
+		reqBody:      json.RawMessage(`{"label":"all_nodes","groups":["compute"]}`),
+		hmsdsResp:    "",
+		hmsdsRespErr: hmsds.ErrHMSDSGroupCycle,
+		expectedGroup: &sm.Group{
+			Label:  "all_nodes",
+			Groups: []string{"compute"},
+		},
+		expectedResp: json.RawMessage(`{"type":"about:blank","title":"Bad Request","detail":"group includes would form a cycle","status":400}` + "\n"),
+		expectError:  true,
+	}, {
+		reqType:       "POST",
+		reqURI:        "https://localhost/hsm/v2/groups",
+		reqBody:       json.RawMessage(`{"label":"all_nodes","groups":["all_nodes"]}`),
+		hmsdsResp:     "",
+		hmsdsRespErr:  nil,
+		expectedGroup: nil,
+		expectedResp:  json.RawMessage(`{"type":"about:blank","title":"Bad Request","detail":"couldn't validate group: group cannot include itself","status":400}` + "\n"),
+		expectError:   true,
 	}}
 
 	for i, test := range tests {
@@ -8414,6 +9121,7 @@ func TestDoGroupPatch(t *testing.T) {
 	tests := []struct {
 		reqType       string
 		reqURI        string
+		reqIfMatch    string
 		reqBody       []byte
 		hmsdsRespErr  error
 		expectedLabel string
@@ -8480,6 +9188,54 @@ func TestDoGroupPatch(t *testing.T) {
 		},
 		expectedResp: json.RawMessage(`{"type":"about:blank","title":"Bad Request","detail":"bad query param: Argument was not valid","status":400}` + "\n"),
 		expectError:  true,
+	}, {
+		reqType:       "PATCH",
+		reqURI:        "https://localhost/hsm/v2/groups/my_group",
+		reqBody:       json.RawMessage(`{"groups":["compute","uan"]}`),
+		hmsdsRespErr:  nil,
+		expectedLabel: "my_group",
+		expectedPatch: &sm.GroupPatch{
+			Groups: &[]string{"compute", "uan"},
+		},
+		expectedResp: nil,
+		expectError:  false,
+	}, {
+		reqType:       "PATCH",
+		reqURI:        "https://localhost/hsm/v2/groups/my_group",
+		reqBody:       json.RawMessage(`{"groups":["all_nodes"]}`),
+		hmsdsRespErr:  hmsds.ErrHMSDSGroupCycle,
+		expectedLabel: "my_group",
+		expectedPatch: &sm.GroupPatch{
+			Groups: &[]string{"all_nodes"},
+		},
+		expectedResp: json.RawMessage(`{"type":"about:blank","title":"Bad Request","detail":"bad query param: group includes would form a cycle","status":400}` + "\n"),
+		expectError:  true,
+	}, {
+		reqType:       "PATCH",
+		reqURI:        "https://localhost/hsm/v2/groups/my_group",
+		reqIfMatch:    `"5"`,
+		reqBody:       json.RawMessage(`{"tags":["foo"]}`),
+		hmsdsRespErr:  nil,
+		expectedLabel: "my_group",
+		expectedPatch: &sm.GroupPatch{
+			Tags:    &[]string{"foo"},
+			Version: 5,
+		},
+		expectedResp: nil,
+		expectError:  false,
+	}, {
+		reqType:       "PATCH",
+		reqURI:        "https://localhost/hsm/v2/groups/my_group",
+		reqIfMatch:    `"5"`,
+		reqBody:       json.RawMessage(`{"tags":["foo"]}`),
+		hmsdsRespErr:  hmsds.ErrHMSDSVersionMismatch,
+		expectedLabel: "my_group",
+		expectedPatch: &sm.GroupPatch{
+			Tags:    &[]string{"foo"},
+			Version: 5,
+		},
+		expectedResp: json.RawMessage(`{"type":"about:blank","title":"Precondition Failed","detail":"group has changed since the given version.","status":412}` + "\n"),
+		expectError:  true,
 	}}
 
 	for i, test := range tests {
@@ -8490,6 +9246,9 @@ func TestDoGroupPatch(t *testing.T) {
 		if err != nil {
 			t.Fatalf("an error '%s' was not expected while creating request", err)
 		}
+		if test.reqIfMatch != "" {
+			req.Header.Set("If-Match", test.reqIfMatch)
+		}
 		w := httptest.NewRecorder()
 
 		router.ServeHTTP(w, req)
@@ -8783,6 +9542,344 @@ func TestDoGroupMembersPost(t *testing.T) {
 	}
 }
 
+func TestDoGroupMembersPut(t *testing.T) {
+	tests := []struct {
+		reqURI          string
+		reqIfMatch      string
+		reqBody         []byte
+		hmsdsResp       int64
+		hmsdsRespErr    error
+		expectedLabel   string
+		expectedIDs     []string
+		expectedVersion int64
+		expectedCode    int
+		expectedETag    string
+		expectedResp    []byte
+	}{{
+		reqURI:          "https://localhost/hsm/v2/groups/my_group/members",
+		reqIfMatch:      `"3"`,
+		reqBody:         json.RawMessage(`{"ids":["X0c0s1b0n0","x0c0s2b0n0"]}`),
+		hmsdsResp:       4,
+		expectedLabel:   "my_group",
+		expectedIDs:     []string{"x0c0s1b0n0", "x0c0s2b0n0"},
+		expectedVersion: 3,
+		expectedCode:    http.StatusNoContent,
+		expectedETag:    `"4"`,
+		expectedResp:    nil,
+	}, {
+		reqURI:          "https://localhost/hsm/v2/groups/my_group/members",
+		reqIfMatch:      "*",
+		reqBody:         json.RawMessage(`{"ids":[]}`),
+		hmsdsResp:       2,
+		expectedLabel:   "my_group",
+		expectedIDs:     []string{},
+		expectedVersion: 0,
+		expectedCode:    http.StatusNoContent,
+		expectedETag:    `"2"`,
+		expectedResp:    nil,
+	}, {
+		reqURI:       "https://localhost/hsm/v2/groups/my_group/members",
+		reqIfMatch:   `"abc"`,
+		reqBody:      json.RawMessage(`{"ids":["x0c0s1b0n0"]}`),
+		expectedCode: http.StatusBadRequest,
+		expectedResp: json.RawMessage(`{"type":"about:blank","title":"Bad Request","detail":"If-Match must be a version from an ETag or '*'.","status":400}` + "\n"),
+	}, {
+		reqURI:       "https://localhost/hsm/v2/groups/my_group/members",
+		reqBody:      json.RawMessage(`{"ids":["foo"]}`),
+		expectedCode: http.StatusBadRequest,
+		expectedResp: json.RawMessage(`{"type":"about:blank","title":"Bad Request","detail":"invalid xname ID","status":400}` + "\n"),
+	}, {
+		reqURI:       "https://localhost/hsm/v2/groups/~MyGroup/members",
+		reqBody:      json.RawMessage(`{"ids":["x0c0s1b0n0"]}`),
+		expectedCode: http.StatusBadRequest,
+		expectedResp: json.RawMessage(`{"type":"about:blank","title":"Bad Request","detail":"Invalid group label.","status":400}` + "\n"),
+	}, {
+		reqURI:          "https://localhost/hsm/v2/groups/your_group/members",
+		reqBody:         json.RawMessage(`{"ids":["x0c0s1b0n0"]}`),
+		hmsdsRespErr:    hmsds.ErrHMSDSNoGroup,
+		expectedLabel:   "your_group",
+		expectedIDs:     []string{"x0c0s1b0n0"},
+		expectedVersion: 0,
+		expectedCode:    http.StatusNotFound,
+		expectedResp:    json.RawMessage(`{"type":"about:blank","title":"Not Found","detail":"No such group: your_group","status":404}` + "\n"),
+	}, {
+		reqURI:          "https://localhost/hsm/v2/groups/my_group/members",
+		reqIfMatch:      `W/"3"`,
+		reqBody:         json.RawMessage(`{"ids":["x0c0s1b0n0"]}`),
+		hmsdsRespErr:    hmsds.ErrHMSDSVersionMismatch,
+		expectedLabel:   "my_group",
+		expectedIDs:     []string{"x0c0s1b0n0"},
+		expectedVersion: 3,
+		expectedCode:    http.StatusPreconditionFailed,
+		expectedResp:    json.RawMessage(`{"type":"about:blank","title":"Precondition Failed","detail":"group has changed since the given version.","status":412}` + "\n"),
+	}, {
+		reqURI:          "https://localhost/hsm/v2/groups/my_group/members",
+		reqBody:         json.RawMessage(`{"ids":["x0c0s1b0n0"]}`),
+		hmsdsRespErr:    hmsds.ErrHMSDSExclusiveGroup,
+		expectedLabel:   "my_group",
+		expectedIDs:     []string{"x0c0s1b0n0"},
+		expectedVersion: 0,
+		expectedCode:    http.StatusConflict,
+		expectedResp:    json.RawMessage(`{"type":"about:blank","title":"Conflict","detail":"operation would conflict with an existing member in another exclusive group.","status":409}` + "\n"),
+	}}
+
+	for i, test := range tests {
+		results.ReplaceGroupMembers.Return.version = test.hmsdsResp
+		results.ReplaceGroupMembers.Return.err = test.hmsdsRespErr
+		results.ReplaceGroupMembers.Input.label = ""
+		results.ReplaceGroupMembers.Input.ms = nil
+		results.ReplaceGroupMembers.Input.version = 0
+		req, err := http.NewRequest("PUT", test.reqURI, bytes.NewBuffer(test.reqBody))
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected while creating request", err)
+		}
+		if test.reqIfMatch != "" {
+			req.Header.Set("If-Match", test.reqIfMatch)
+		}
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+		if w.Code != test.expectedCode {
+			t.Errorf("Test %v Failed: Response code was %v; want %v", i, w.Code, test.expectedCode)
+		}
+		if test.expectedLabel != results.ReplaceGroupMembers.Input.label {
+			t.Errorf("Test %v Failed: Expected label is '%v'; Received '%v'", i, test.expectedLabel, results.ReplaceGroupMembers.Input.label)
+		}
+		if test.expectedIDs != nil {
+			if results.ReplaceGroupMembers.Input.ms == nil {
+				t.Errorf("Test %v Failed: Expected ids %v; Received none", i, test.expectedIDs)
+			} else if !reflect.DeepEqual(test.expectedIDs, results.ReplaceGroupMembers.Input.ms.IDs) {
+				t.Errorf("Test %v Failed: Expected ids %v; Received %v", i, test.expectedIDs, results.ReplaceGroupMembers.Input.ms.IDs)
+			}
+		}
+		if test.expectedVersion != results.ReplaceGroupMembers.Input.version {
+			t.Errorf("Test %v Failed: Expected version %v; Received %v", i, test.expectedVersion, results.ReplaceGroupMembers.Input.version)
+		}
+		if etag := w.Header().Get("ETag"); etag != test.expectedETag {
+			t.Errorf("Test %v Failed: Expected ETag '%v'; Received '%v'", i, test.expectedETag, etag)
+		}
+		if bytes.Compare(test.expectedResp, w.Body.Bytes()) != 0 {
+			t.Errorf("Test %v Failed: Expected body is '%v'; Received '%v'", i, string(test.expectedResp), w.Body)
+		}
+	}
+}
+
+func TestDoGroupHistoryGet(t *testing.T) {
+	ent1 := &sm.GroupHistoryEntry{
+		ID:        7,
+		Timestamp: "2026-10-19T11:36:00Z",
+		Name:      "my_group",
+		Op:        sm.GroupHistOpAddMember,
+		Member:    "x0c0s1b0n0",
+		Requester: "cray-smd-loader",
+	}
+	tests := []struct {
+		reqURI         string
+		hmsdsResp      []*sm.GroupHistoryEntry
+		hmsdsRespErr   error
+		expectedLabel  string
+		expectedPName  string
+		expectedFilter hmsds.GroupHistFilter
+		expectedCode   int
+		expectedResp   []byte
+	}{{
+		reqURI:        "https://localhost/hsm/v2/groups/My_Group/history?member=x0c0s1b0n0&op=addmember&op=RemoveMember&since=2026-10-19T00:00:00Z",
+		hmsdsResp:     []*sm.GroupHistoryEntry{ent1},
+		expectedLabel: "my_group",
+		expectedFilter: hmsds.GroupHistFilter{
+			Member: []string{"x0c0s1b0n0"},
+			Op:     []string{"addmember", "RemoveMember"},
+			Since:  "2026-10-19T00:00:00Z",
+		},
+		expectedCode: http.StatusOK,
+		expectedResp: json.RawMessage(`[{"id":7,"timestamp":"2026-10-19T11:36:00Z","name":"my_group","op":"AddMember","member":"x0c0s1b0n0","requester":"cray-smd-loader"}]` + "\n"),
+	}, {
+		reqURI:        "https://localhost/hsm/v2/partitions/p1/history?requester=cray-smd-loader&until=2026-10-19T12:00:00Z",
+		hmsdsResp:     []*sm.GroupHistoryEntry{},
+		expectedPName: "p1",
+		expectedFilter: hmsds.GroupHistFilter{
+			Requester: []string{"cray-smd-loader"},
+			Until:     "2026-10-19T12:00:00Z",
+		},
+		expectedCode: http.StatusOK,
+		expectedResp: json.RawMessage(`[]` + "\n"),
+	}, {
+		reqURI:       "https://localhost/hsm/v2/groups/my_group/history?op=rename",
+		expectedCode: http.StatusBadRequest,
+		expectedResp: json.RawMessage(`{"type":"about:blank","title":"Bad Request","detail":"Invalid op: rename","status":400}` + "\n"),
+	}, {
+		reqURI:       "https://localhost/hsm/v2/groups/~MyGroup/history",
+		expectedCode: http.StatusBadRequest,
+		expectedResp: json.RawMessage(`{"type":"about:blank","title":"Bad Request","detail":"Invalid group label.","status":400}` + "\n"),
+	}, {
+		reqURI:        "https://localhost/hsm/v2/groups/my_group/history?since=yesterday",
+		hmsdsRespErr:  hmsds.ErrHMSDSArgBadTimeFormat,
+		expectedLabel: "my_group",
+		expectedFilter: hmsds.GroupHistFilter{
+			Since: "yesterday",
+		},
+		expectedCode: http.StatusBadRequest,
+		expectedResp: json.RawMessage(`{"type":"about:blank","title":"Bad Request","detail":"bad query param: Argument was not in a valid RFC3339 time format","status":400}` + "\n"),
+	}}
+
+	for i, test := range tests {
+		results.GetGroupHistory.Return.entries = test.hmsdsResp
+		results.GetGroupHistory.Return.err = test.hmsdsRespErr
+		results.GetGroupHistory.Input.label = ""
+		results.GetGroupHistory.Input.f_opts = nil
+		results.GetPartitionHistory.Return.entries = test.hmsdsResp
+		results.GetPartitionHistory.Return.err = test.hmsdsRespErr
+		results.GetPartitionHistory.Input.pname = ""
+		results.GetPartitionHistory.Input.f_opts = nil
+		req, err := http.NewRequest("GET", test.reqURI, nil)
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected while creating request", err)
+		}
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+		if w.Code != test.expectedCode {
+			t.Errorf("Test %v Failed: Response code was %v; want %v", i, w.Code, test.expectedCode)
+		}
+		if test.expectedLabel != results.GetGroupHistory.Input.label {
+			t.Errorf("Test %v Failed: Expected label is '%v'; Received '%v'", i, test.expectedLabel, results.GetGroupHistory.Input.label)
+		}
+		if test.expectedPName != results.GetPartitionHistory.Input.pname {
+			t.Errorf("Test %v Failed: Expected partition is '%v'; Received '%v'", i, test.expectedPName, results.GetPartitionHistory.Input.pname)
+		}
+		f_opts := results.GetGroupHistory.Input.f_opts
+		if test.expectedPName != "" {
+			f_opts = results.GetPartitionHistory.Input.f_opts
+		}
+		if test.expectedLabel != "" || test.expectedPName != "" {
+			f := hmsds.GroupHistFilter{}
+			for _, opt := range f_opts {
+				opt(&f)
+			}
+			if !reflect.DeepEqual(test.expectedFilter.Member, f.Member) ||
+				!reflect.DeepEqual(test.expectedFilter.Op, f.Op) ||
+				!reflect.DeepEqual(test.expectedFilter.Requester, f.Requester) ||
+				test.expectedFilter.Since != f.Since ||
+				test.expectedFilter.Until != f.Until {
+				t.Errorf("Test %v Failed: Expected filter '%v'; Received '%v'", i, test.expectedFilter, f)
+			}
+		}
+		if bytes.Compare(test.expectedResp, w.Body.Bytes()) != 0 {
+			t.Errorf("Test %v Failed: Expected body is '%v'; Received '%v'", i, string(test.expectedResp), w.Body)
+		}
+	}
+}
+
+func TestDoGroupMembersGetAsOf(t *testing.T) {
+	hist := []*sm.GroupHistoryEntry{
+		{Op: sm.GroupHistOpCreate},
+		{Op: sm.GroupHistOpAddMember, Member: "x0c0s2b0n0"},
+		{Op: sm.GroupHistOpAddMember, Member: "x0c0s1b0n0"},
+		{Op: sm.GroupHistOpRemoveMember, Member: "x0c0s2b0n0"},
+	}
+	tests := []struct {
+		reqURI        string
+		hmsdsResp     []*sm.GroupHistoryEntry
+		expectedLabel string
+		expectedPName string
+		expectedUntil string
+		expectedCode  int
+		expectedResp  []byte
+	}{{
+		reqURI:        "https://localhost/hsm/v2/groups/my_group/members?asof=2026-10-19T12:00:00Z",
+		hmsdsResp:     hist,
+		expectedLabel: "my_group",
+		expectedUntil: "2026-10-19T12:00:00Z",
+		expectedCode:  http.StatusOK,
+		expectedResp:  json.RawMessage(`{"ids":["x0c0s1b0n0"]}` + "\n"),
+	}, {
+		reqURI:        "https://localhost/hsm/v2/partitions/p1/members?asof=2026-10-19T12:00:00Z",
+		hmsdsResp:     hist[:3],
+		expectedPName: "p1",
+		expectedUntil: "2026-10-19T12:00:00Z",
+		expectedCode:  http.StatusOK,
+		expectedResp:  json.RawMessage(`{"ids":["x0c0s1b0n0","x0c0s2b0n0"]}` + "\n"),
+	}, {
+		reqURI:        "https://localhost/hsm/v2/groups/my_group/members?asof=2026-10-19T12:00:00Z",
+		hmsdsResp:     []*sm.GroupHistoryEntry{},
+		expectedLabel: "my_group",
+		expectedUntil: "2026-10-19T12:00:00Z",
+		expectedCode:  http.StatusNotFound,
+		expectedResp:  json.RawMessage(`{"type":"about:blank","title":"Not Found","detail":"No such group at 2026-10-19T12:00:00Z: my_group","status":404}` + "\n"),
+	}, {
+		reqURI:       "https://localhost/hsm/v2/groups/my_group/members?asof=yesterday",
+		expectedCode: http.StatusBadRequest,
+		expectedResp: json.RawMessage(`{"type":"about:blank","title":"Bad Request","detail":"Invalid asof time, must be RFC3339.","status":400}` + "\n"),
+	}, {
+		reqURI:       "https://localhost/hsm/v2/groups/my_group/members?asof=2026-10-19T12:00:00Z&partition=p1",
+		expectedCode: http.StatusBadRequest,
+		expectedResp: json.RawMessage(`{"type":"about:blank","title":"Bad Request","detail":"asof cannot be combined with partition.","status":400}` + "\n"),
+	}}
+
+	for i, test := range tests {
+		results.GetGroupHistory.Return.entries = test.hmsdsResp
+		results.GetGroupHistory.Return.err = nil
+		results.GetGroupHistory.Input.label = ""
+		results.GetGroupHistory.Input.f_opts = nil
+		results.GetPartitionHistory.Return.entries = test.hmsdsResp
+		results.GetPartitionHistory.Return.err = nil
+		results.GetPartitionHistory.Input.pname = ""
+		results.GetPartitionHistory.Input.f_opts = nil
+		req, err := http.NewRequest("GET", test.reqURI, nil)
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected while creating request", err)
+		}
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+		if w.Code != test.expectedCode {
+			t.Errorf("Test %v Failed: Response code was %v; want %v", i, w.Code, test.expectedCode)
+		}
+		if test.expectedLabel != results.GetGroupHistory.Input.label {
+			t.Errorf("Test %v Failed: Expected label is '%v'; Received '%v'", i, test.expectedLabel, results.GetGroupHistory.Input.label)
+		}
+		if test.expectedPName != results.GetPartitionHistory.Input.pname {
+			t.Errorf("Test %v Failed: Expected partition is '%v'; Received '%v'", i, test.expectedPName, results.GetPartitionHistory.Input.pname)
+		}
+		f_opts := results.GetGroupHistory.Input.f_opts
+		if test.expectedPName != "" {
+			f_opts = results.GetPartitionHistory.Input.f_opts
+		}
+		f := hmsds.GroupHistFilter{}
+		for _, opt := range f_opts {
+			opt(&f)
+		}
+		if test.expectedUntil != f.Until {
+			t.Errorf("Test %v Failed: Expected until '%v'; Received '%v'", i, test.expectedUntil, f.Until)
+		}
+		if bytes.Compare(test.expectedResp, w.Body.Bytes()) != 0 {
+			t.Errorf("Test %v Failed: Expected body is '%v'; Received '%v'", i, string(test.expectedResp), w.Body)
+		}
+	}
+}
+
+func TestGetRequester(t *testing.T) {
+	tests := []struct {
+		hmsService string
+		userAgent  string
+		expected   string
+	}{
+		{"smd-loader", "Go-http-client/1.1", "smd-loader"},
+		{"", "cray-capmc-7f9c", "cray-capmc-7f9c"},
+		{"", "", ""},
+	}
+	for i, test := range tests {
+		req, _ := http.NewRequest("POST", "https://localhost/hsm/v2/groups", nil)
+		if test.hmsService != "" {
+			req.Header.Set("HMS-Service", test.hmsService)
+		}
+		req.Header.Set("User-Agent", test.userAgent)
+		if out := getRequester(req); out != test.expected {
+			t.Errorf("Test %v Failed: Expected requester '%v'; Received '%v'", i, test.expected, out)
+		}
+	}
+}
+
 func TestDoGroupMemberDelete(t *testing.T) {
 	tests := []struct {
 		reqType       string
@@ -8838,6 +9935,15 @@ func TestDoGroupMemberDelete(t *testing.T) {
 		expectedID:    "x0c0s1b0n0",
 		expectedResp:  json.RawMessage(`{"type":"about:blank","title":"Internal Server Error","detail":"DB query failed.","status":500}` + "\n"),
 		expectError:   true,
+	}, {
+		reqType:       "DELETE",
+		reqURI:        "https://localhost/hsm/v2/groups/computes/members/x0c0s1b0n0",
+		hmsdsResp:     false,
+		hmsdsRespErr:  hmsds.ErrHMSDSDynamicGroup,
+		expectedLabel: "computes",
+		expectedID:    "x0c0s1b0n0",
+		expectedResp:  json.RawMessage(`{"type":"about:blank","title":"Bad Request","detail":"members of a dynamic group are set by its query","status":400}` + "\n"),
+		expectError:   true,
 	}}
 
 	for i, test := range tests {
@@ -8872,6 +9978,153 @@ func TestDoGroupMemberDelete(t *testing.T) {
 	}
 }
 
+func TestDoGroupSnapshotPost(t *testing.T) {
+	dynGroup := &sm.Group{
+		Label:   "computes",
+		Query:   &sm.GroupQuery{Role: []string{"Compute"}},
+		Members: sm.Members{IDs: []string{"x0c0s1b0n0", "x0c0s2b0n0"}},
+	}
+	tests := []struct {
+		reqURI        string
+		reqBody       []byte
+		getResp       *sm.Group
+		insertRespErr error
+		expectedLabel string
+		expectedGroup *sm.Group
+		expectedResp  []byte
+		expectError   bool
+	}{{
+		reqURI:        "https://localhost/hsm/v2/groups/computes/snapshot",
+		reqBody:       json.RawMessage(`{"label":"computes_0919","tags":["frozen"]}`),
+		getResp:       dynGroup,
+		expectedLabel: "computes",
+		expectedGroup: &sm.Group{
+			Label:       "computes_0919",
+			Description: "Snapshot of group computes",
+			Tags:        []string{"frozen"},
+			Members:     sm.Members{IDs: []string{"x0c0s1b0n0", "x0c0s2b0n0"}},
+		},
+		expectedResp: json.RawMessage(`[{"URI":"/hsm/v2/groups/computes_0919"}]` + "\n"),
+		expectError:  false,
+	}, {
+		reqURI:        "https://localhost/hsm/v2/groups/nosuch/snapshot",
+		reqBody:       json.RawMessage(`{"label":"computes_0919"}`),
+		getResp:       nil,
+		expectedLabel: "nosuch",
+		expectedGroup: nil,
+		expectedResp:  json.RawMessage(`{"type":"about:blank","title":"Not Found","detail":"No such group: nosuch","status":404}` + "\n"),
+		expectError:   true,
+	}, {
+		reqURI:        "https://localhost/hsm/v2/groups/computes/snapshot",
+		reqBody:       json.RawMessage(`{"label":"computes"}`),
+		getResp:       dynGroup,
+		insertRespErr: hmsds.ErrHMSDSDuplicateKey,
+		expectedLabel: "computes",
+		expectedGroup: &sm.Group{
+			Label:       "computes",
+			Description: "Snapshot of group computes",
+			Members:     sm.Members{IDs: []string{"x0c0s1b0n0", "x0c0s2b0n0"}},
+		},
+		expectedResp: json.RawMessage(`{"type":"about:blank","title":"Conflict","detail":"operation would conflict with an existing group that has the same label.","status":409}` + "\n"),
+		expectError:  true,
+	}}
+
+	for i, test := range tests {
+		results.GetGroup.Return.group = test.getResp
+		results.GetGroup.Return.err = nil
+		results.GetGroup.Input.label = ""
+		results.InsertGroup.Return.label = ""
+		if test.expectedGroup != nil {
+			results.InsertGroup.Return.label = test.expectedGroup.Label
+		}
+		results.InsertGroup.Return.err = test.insertRespErr
+		results.InsertGroup.Input.g = nil
+		req, err := http.NewRequest("POST", test.reqURI, bytes.NewBuffer(test.reqBody))
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected while creating request", err)
+		}
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+		if !test.expectError && w.Code != http.StatusCreated {
+			t.Errorf("Test %v Failed: Response code was %v; want 201", i, w.Code)
+		} else if test.expectError && w.Code == http.StatusCreated {
+			t.Errorf("Test %v Failed: Response code was %v; expected an error", i, w.Code)
+		}
+		if test.expectedLabel != results.GetGroup.Input.label {
+			t.Errorf("Test %v Failed: Expected label is '%v'; Received '%v'", i, test.expectedLabel, results.GetGroup.Input.label)
+		}
+		if !compareGroup(test.expectedGroup, results.InsertGroup.Input.g) {
+			t.Errorf("Test %v Failed: Expected group is '%v'; Received '%v'", i, test.expectedGroup, results.InsertGroup.Input.g)
+		}
+		if bytes.Compare(test.expectedResp, w.Body.Bytes()) != 0 {
+			t.Errorf("Test %v Failed: Expected body is '%v'; Received '%v'", i, string(test.expectedResp), w.Body)
+		}
+	}
+}
+
+func TestDoGroupsQueryPost(t *testing.T) {
+	tests := []struct {
+		reqBody             []byte
+		hmsdsRespIDs        []*base.Component
+		hmsdsRespErr        error
+		expectedExpr        string
+		expectedFieldFilter hmsds.FieldFilter
+		expectedResp        []byte
+	}{{
+		reqBody: json.RawMessage(`{"Expression":"(compute | gpu) & !P1","stateonly":true}`),
+		hmsdsRespIDs: []*base.Component{
+			{ID: "x0c0s1b0n0", Type: "Node", State: "On", Flag: "OK"},
+			{ID: "x0c0s2b0n0", Type: "Node", State: "Ready", Flag: "OK"},
+		},
+		expectedExpr:        "((compute | gpu) & !p1)",
+		expectedFieldFilter: hmsds.FLTR_STATEONLY,
+		expectedResp:        json.RawMessage(`{"Components":[{"ID":"x0c0s1b0n0","Type":"Node","State":"On","Flag":"OK"},{"ID":"x0c0s2b0n0","Type":"Node","State":"Ready","Flag":"OK"}]}` + "\n"),
+	}, {
+		reqBody: json.RawMessage(`{"Expression":"compute & !drained","membersonly":true}`),
+		hmsdsRespIDs: []*base.Component{
+			{ID: "x0c0s1b0n0"},
+			{ID: "x0c0s2b0n0"},
+		},
+		expectedExpr:        "(compute & !drained)",
+		expectedFieldFilter: hmsds.FLTR_ID_ONLY,
+		expectedResp:        json.RawMessage(`{"ids":["x0c0s1b0n0","x0c0s2b0n0"]}` + "\n"),
+	}, {
+		reqBody:             json.RawMessage(`{"Expression":"compute & nosuch"}`),
+		hmsdsRespErr:        hmsds.ErrHMSDSNoGroup,
+		expectedExpr:        "(compute & nosuch)",
+		expectedFieldFilter: hmsds.FLTR_DEFAULT,
+		expectedResp:        json.RawMessage(`{"type":"about:blank","title":"Bad Request","detail":"expression names a group that does not exist.","status":400}` + "\n"),
+	}, {
+		reqBody:      json.RawMessage(`{"Expression":"(compute | gpu"}`),
+		expectedExpr: "",
+		expectedResp: json.RawMessage(`{"type":"about:blank","title":"Bad Request","detail":"invalid group expression at offset 14: missing ')'","status":400}` + "\n"),
+	}}
+
+	for i, test := range tests {
+		results.GetComponentsGroupExpr.Input.expr = ""
+		results.GetComponentsGroupExpr.Input.fieldFilter = hmsds.FLTR_DEFAULT
+		results.GetComponentsGroupExpr.Return.ids = test.hmsdsRespIDs
+		results.GetComponentsGroupExpr.Return.err = test.hmsdsRespErr
+		req, err := http.NewRequest("POST", "https://localhost/hsm/v2/groups/Query", bytes.NewBuffer(test.reqBody))
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected while creating request", err)
+		}
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+		if test.expectedExpr != results.GetComponentsGroupExpr.Input.expr {
+			t.Errorf("Test %v Failed: Expected expression is '%v'; Received '%v'", i, test.expectedExpr, results.GetComponentsGroupExpr.Input.expr)
+		}
+		if test.expectedFieldFilter != results.GetComponentsGroupExpr.Input.fieldFilter {
+			t.Errorf("Test %v Failed: Expected field filter is '%v'; Received '%v'", i, test.expectedFieldFilter, results.GetComponentsGroupExpr.Input.fieldFilter)
+		}
+		if bytes.Compare(test.expectedResp, w.Body.Bytes()) != 0 {
+			t.Errorf("Test %v Failed: Expected body is '%v'; Received '%v'", i, string(test.expectedResp), w.Body)
+		}
+	}
+}
+
 /////////////////////////////////////////////////////////////////////////////
 // Partitions
 //////////////////////////////////////////////////////////////////////////////
@@ -9205,6 +10458,14 @@ func TestDoPartitionDelete(t *testing.T) {
 		expectedName: "p1",
 		expectedResp: json.RawMessage(`{"type":"about:blank","title":"Internal Server Error","detail":"DB query failed.","status":500}` + "\n"),
 		expectError:  true,
+	}, {
+		reqType:      "DELETE",
+		reqURI:       "https://localhost/hsm/v2/partitions/p1",
+		hmsdsResp:    false,
+		hmsdsRespErr: hmsds.ErrHMSDSPartitionHasChildren,
+		expectedName: "p1",
+		expectedResp: json.RawMessage(`{"type":"about:blank","title":"Conflict","detail":"partition still has sub-partitions","status":409}` + "\n"),
+		expectError:  true,
 	}}
 
 	for i, test := range tests {
@@ -9577,6 +10838,181 @@ func TestDoPartitionMembersPost(t *testing.T) {
 	}
 }
 
+func TestDoPartitionMembersPut(t *testing.T) {
+	tests := []struct {
+		reqURI          string
+		reqIfMatch      string
+		reqBody         []byte
+		hmsdsResp       int64
+		hmsdsRespErr    error
+		expectedName    string
+		expectedIDs     []string
+		expectedVersion int64
+		expectedCode    int
+		expectedETag    string
+		expectedResp    []byte
+	}{{
+		reqURI:          "https://localhost/hsm/v2/partitions/p1/members",
+		reqIfMatch:      "7",
+		reqBody:         json.RawMessage(`{"ids":["x0c0s1b0n0","x0c0s1b0n0"]}`),
+		hmsdsResp:       8,
+		expectedName:    "p1",
+		expectedIDs:     []string{"x0c0s1b0n0", "x0c0s1b0n0"},
+		expectedVersion: 7,
+		expectedCode:    http.StatusNoContent,
+		expectedETag:    `"8"`,
+		expectedResp:    nil,
+	}, {
+		reqURI:       "https://localhost/hsm/v2/partitions/p1/members",
+		reqIfMatch:   `"0"`,
+		reqBody:      json.RawMessage(`{"ids":["x0c0s1b0n0"]}`),
+		expectedCode: http.StatusBadRequest,
+		expectedResp: json.RawMessage(`{"type":"about:blank","title":"Bad Request","detail":"If-Match must be a version from an ETag or '*'.","status":400}` + "\n"),
+	}, {
+		reqURI:          "https://localhost/hsm/v2/partitions/p2/members",
+		reqBody:         json.RawMessage(`{"ids":["x0c0s1b0n0"]}`),
+		hmsdsRespErr:    hmsds.ErrHMSDSNoPartition,
+		expectedName:    "p2",
+		expectedIDs:     []string{"x0c0s1b0n0"},
+		expectedVersion: 0,
+		expectedCode:    http.StatusNotFound,
+		expectedResp:    json.RawMessage(`{"type":"about:blank","title":"Not Found","detail":"No such partition: p2","status":404}` + "\n"),
+	}, {
+		reqURI:          "https://localhost/hsm/v2/partitions/p1/members",
+		reqIfMatch:      `"7"`,
+		reqBody:         json.RawMessage(`{"ids":["x0c0s1b0n0"]}`),
+		hmsdsRespErr:    hmsds.ErrHMSDSVersionMismatch,
+		expectedName:    "p1",
+		expectedIDs:     []string{"x0c0s1b0n0"},
+		expectedVersion: 7,
+		expectedCode:    http.StatusPreconditionFailed,
+		expectedResp:    json.RawMessage(`{"type":"about:blank","title":"Precondition Failed","detail":"partition has changed since the given version.","status":412}` + "\n"),
+	}, {
+		reqURI:          "https://localhost/hsm/v2/partitions/p1/members",
+		reqBody:         json.RawMessage(`{"ids":["x0c0s1b0n0"]}`),
+		hmsdsRespErr:    hmsds.ErrHMSDSExclusivePartition,
+		expectedName:    "p1",
+		expectedIDs:     []string{"x0c0s1b0n0"},
+		expectedVersion: 0,
+		expectedCode:    http.StatusConflict,
+		expectedResp:    json.RawMessage(`{"type":"about:blank","title":"Conflict","detail":"operation would conflict with an existing member in another partition.","status":409}` + "\n"),
+	}}
+
+	for i, test := range tests {
+		results.ReplacePartitionMembers.Return.version = test.hmsdsResp
+		results.ReplacePartitionMembers.Return.err = test.hmsdsRespErr
+		results.ReplacePartitionMembers.Input.pname = ""
+		results.ReplacePartitionMembers.Input.ms = nil
+		results.ReplacePartitionMembers.Input.version = 0
+		req, err := http.NewRequest("PUT", test.reqURI, bytes.NewBuffer(test.reqBody))
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected while creating request", err)
+		}
+		if test.reqIfMatch != "" {
+			req.Header.Set("If-Match", test.reqIfMatch)
+		}
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+		if w.Code != test.expectedCode {
+			t.Errorf("Test %v Failed: Response code was %v; want %v", i, w.Code, test.expectedCode)
+		}
+		if test.expectedName != results.ReplacePartitionMembers.Input.pname {
+			t.Errorf("Test %v Failed: Expected name is '%v'; Received '%v'", i, test.expectedName, results.ReplacePartitionMembers.Input.pname)
+		}
+		if test.expectedIDs != nil {
+			if results.ReplacePartitionMembers.Input.ms == nil {
+				t.Errorf("Test %v Failed: Expected ids %v; Received none", i, test.expectedIDs)
+			} else if !reflect.DeepEqual(test.expectedIDs, results.ReplacePartitionMembers.Input.ms.IDs) {
+				t.Errorf("Test %v Failed: Expected ids %v; Received %v", i, test.expectedIDs, results.ReplacePartitionMembers.Input.ms.IDs)
+			}
+		}
+		if test.expectedVersion != results.ReplacePartitionMembers.Input.version {
+			t.Errorf("Test %v Failed: Expected version %v; Received %v", i, test.expectedVersion, results.ReplacePartitionMembers.Input.version)
+		}
+		if etag := w.Header().Get("ETag"); etag != test.expectedETag {
+			t.Errorf("Test %v Failed: Expected ETag '%v'; Received '%v'", i, test.expectedETag, etag)
+		}
+		if bytes.Compare(test.expectedResp, w.Body.Bytes()) != 0 {
+			t.Errorf("Test %v Failed: Expected body is '%v'; Received '%v'", i, string(test.expectedResp), w.Body)
+		}
+	}
+}
+
+func TestDoPartitionMembersMovePost(t *testing.T) {
+	tests := []struct {
+		reqURI         string
+		reqBody        []byte
+		hmsdsRespErr   error
+		expectedName   string
+		expectedSource string
+		expectedIDs    []string
+		expectedCode   int
+		expectedResp   []byte
+	}{{
+		reqURI:         "https://localhost/hsm/v2/partitions/p1.3/members/move",
+		reqBody:        json.RawMessage(`{"source":"p1.2","ids":["x0c0s1b0n0"]}`),
+		expectedName:   "p1.3",
+		expectedSource: "p1.2",
+		expectedIDs:    []string{"x0c0s1b0n0"},
+		expectedCode:   http.StatusNoContent,
+		expectedResp:   nil,
+	}, {
+		reqURI:         "https://localhost/hsm/v2/partitions/p1.3/members/move",
+		reqBody:        json.RawMessage(`{"source":"p1.4","ids":["x0c0s1b0n0"]}`),
+		hmsdsRespErr:   hmsds.ErrHMSDSNoPartition,
+		expectedName:   "p1.3",
+		expectedSource: "p1.4",
+		expectedIDs:    []string{"x0c0s1b0n0"},
+		expectedCode:   http.StatusNotFound,
+		expectedResp:   json.RawMessage(`{"type":"about:blank","title":"Not Found","detail":"No such partition: p1.3 or p1.4","status":404}` + "\n"),
+	}, {
+		reqURI:         "https://localhost/hsm/v2/partitions/p2.1/members/move",
+		reqBody:        json.RawMessage(`{"source":"p1.2","ids":["x0c0s1b0n0"]}`),
+		hmsdsRespErr:   hmsds.ErrHMSDSNotSiblingPartitions,
+		expectedName:   "p2.1",
+		expectedSource: "p1.2",
+		expectedIDs:    []string{"x0c0s1b0n0"},
+		expectedCode:   http.StatusBadRequest,
+		expectedResp:   json.RawMessage(`{"type":"about:blank","title":"Bad Request","detail":"partitions are not sibling sub-partitions","status":400}` + "\n"),
+	}, {
+		reqURI:       "https://localhost/hsm/v2/partitions/,P1,/members/move",
+		reqBody:      json.RawMessage(`{"source":"p1.2","ids":["x0c0s1b0n0"]}`),
+		expectedCode: http.StatusBadRequest,
+		expectedResp: json.RawMessage(`{"type":"about:blank","title":"Bad Request","detail":"Invalid partition name.","status":400}` + "\n"),
+	}}
+
+	for i, test := range tests {
+		results.MovePartitionMembers.Return.err = test.hmsdsRespErr
+		results.MovePartitionMembers.Input.pname = ""
+		results.MovePartitionMembers.Input.pm = nil
+		req, err := http.NewRequest("POST", test.reqURI, bytes.NewBuffer(test.reqBody))
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected while creating request", err)
+		}
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+		if w.Code != test.expectedCode {
+			t.Errorf("Test %v Failed: Response code was %v; want %v", i, w.Code, test.expectedCode)
+		}
+		if test.expectedName != results.MovePartitionMembers.Input.pname {
+			t.Errorf("Test %v Failed: Expected name is '%v'; Received '%v'", i, test.expectedName, results.MovePartitionMembers.Input.pname)
+		}
+		if test.expectedIDs != nil {
+			pm := results.MovePartitionMembers.Input.pm
+			if pm == nil {
+				t.Errorf("Test %v Failed: Expected move from %v; Received none", i, test.expectedSource)
+			} else if pm.Source != test.expectedSource || !reflect.DeepEqual(test.expectedIDs, pm.IDs) {
+				t.Errorf("Test %v Failed: Expected move of %v from %v; Received %v from %v", i, test.expectedIDs, test.expectedSource, pm.IDs, pm.Source)
+			}
+		}
+		if bytes.Compare(test.expectedResp, w.Body.Bytes()) != 0 {
+			t.Errorf("Test %v Failed: Expected body is '%v'; Received '%v'", i, string(test.expectedResp), w.Body)
+		}
+	}
+}
+
 func TestDoPartitionMemberDelete(t *testing.T) {
 	tests := []struct {
 		reqType      string
@@ -11041,3 +12477,220 @@ func TestDoPowerMapPut(t *testing.T) {
 		}
 	}
 }
+
+func TestDoJobsGet(t *testing.T) {
+	job := func(id, xname string) *sm.Job {
+		return &sm.Job{
+			JobData: sm.JobData{
+				Id:         id,
+				Type:       sm.JobTypeSRFP,
+				Status:     sm.JobInProgress,
+				LastUpdate: "2026-10-19T11:37:00Z",
+				Lifetime:   30,
+			},
+			Data: &sm.SrfpJobData{CompId: xname},
+		}
+	}
+	// job2 is run by this instance.
+	p := NewRFPoller(s, defaultRFPollPolicy())
+	p.targets["x0c0s0b0n1"] = &rfPollTarget{
+		job:      &sm.Job{JobData: sm.JobData{Id: "job2"}},
+		rule:     p.policy.Rules[0],
+		compId:   "x0c0s0b0n1",
+		bmc:      "x0c0s0b0",
+		status:   sm.JobError,
+		failures: 3,
+		interval: 20 * time.Second,
+	}
+	s.rfPoller = p
+	defer func() { s.rfPoller = nil }()
+
+	tests := []struct {
+		reqURI         string
+		dbJobs         []*sm.Job
+		dbErr          error
+		expectedCode   int
+		expectedFilter hmsds.JobSyncFilter
+	}{{
+		reqURI:       "/hsm/v2/jobs",
+		dbJobs:       []*sm.Job{job("job1", "x0c0s0b0n0"), job("job2", "x0c0s0b0n1")},
+		expectedCode: http.StatusOK,
+	}, {
+		reqURI:       "/hsm/v2/jobs?type=staterfpoll&status=error&status=inprogress&xname=x00c0s0b0n0&expired=true",
+		dbJobs:       []*sm.Job{},
+		expectedCode: http.StatusOK,
+		expectedFilter: hmsds.JobSyncFilter{
+			Type:   []string{sm.JobTypeSRFP},
+			Status: []string{sm.JobError, sm.JobInProgress},
+			CompID: []string{"x0c0s0b0n0"},
+		},
+	}, {
+		reqURI:       "/hsm/v2/jobs?type=Discovery",
+		expectedCode: http.StatusBadRequest,
+	}, {
+		reqURI:       "/hsm/v2/jobs?status=Running",
+		expectedCode: http.StatusBadRequest,
+	}, {
+		reqURI:       "/hsm/v2/jobs?xname=foo",
+		expectedCode: http.StatusBadRequest,
+	}, {
+		reqURI:       "/hsm/v2/jobs?expired=maybe",
+		expectedCode: http.StatusBadRequest,
+	}, {
+		reqURI:       "/hsm/v2/jobs",
+		dbErr:        hmsds.ErrHMSDSArgMissing,
+		expectedCode: http.StatusInternalServerError,
+	}}
+
+	for i, test := range tests {
+		results.GetJobs.Input.f = nil
+		results.GetJobs.Return.js = test.dbJobs
+		results.GetJobs.Return.err = test.dbErr
+
+		req, err := http.NewRequest("GET", test.reqURI, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if test.expectedCode != w.Code {
+			t.Errorf("Test %v Failed: Expected status code %v; Received %v (%s)",
+				i, test.expectedCode, w.Code, w.Body.String())
+			continue
+		}
+		if test.expectedCode != http.StatusOK {
+			continue
+		}
+		f := results.GetJobs.Input.f
+		if !reflect.DeepEqual(test.expectedFilter.Type, f.Type) ||
+			!reflect.DeepEqual(test.expectedFilter.Status, f.Status) ||
+			!reflect.DeepEqual(test.expectedFilter.CompID, f.CompID) {
+			t.Errorf("Test %v Failed: Expected filter %+v; Received %+v",
+				i, test.expectedFilter, f)
+		}
+		jobs := new(sm.JobArray)
+		if err := json.Unmarshal(w.Body.Bytes(), jobs); err != nil {
+			t.Errorf("Test %v Failed: Bad response: %s", i, err)
+			continue
+		}
+		if len(jobs.Jobs) != len(test.dbJobs) {
+			t.Errorf("Test %v Failed: Expected %d jobs; Received %d",
+				i, len(test.dbJobs), len(jobs.Jobs))
+			continue
+		}
+		for _, j := range jobs.Jobs {
+			data := j.Data.(map[string]interface{})
+			if j.Id == "job2" {
+				// Live status
+				if j.Status != sm.JobError || data["Failures"] != 3.0 ||
+					data["Poll"] != 20.0 || data["RfEndpointID"] != "x0c0s0b0" ||
+					j.LastUpdate != "2026-10-19T11:37:00Z" {
+					t.Errorf("Test %v Failed: Expected live status: %+v", i, j)
+				}
+			} else if j.Status != sm.JobInProgress || data["Failures"] != nil {
+				t.Errorf("Test %v Failed: Unexpected live status: %+v", i, j)
+			}
+		}
+	}
+}
+
+func TestDoJobGetDelete(t *testing.T) {
+	job := &sm.Job{
+		JobData: sm.JobData{
+			Id:     "job1",
+			Type:   sm.JobTypeSRFP,
+			Status: sm.JobInProgress,
+		},
+		Data: &sm.SrfpJobData{CompId: "x0c0s0b0n0"},
+	}
+	p := NewRFPoller(s, defaultRFPollPolicy())
+	s.rfPoller = p
+	defer func() { s.rfPoller = nil }()
+
+	tests := []struct {
+		method         string
+		reqURI         string
+		dbJob          *sm.Job
+		dbErr          error
+		local          bool
+		dbDidDelete    bool
+		expectedCode   int
+		expectedDelete string
+	}{{
+		method:       "GET",
+		reqURI:       "/hsm/v2/jobs/job1",
+		dbJob:        job,
+		expectedCode: http.StatusOK,
+	}, {
+		method:       "GET",
+		reqURI:       "/hsm/v2/jobs/job1",
+		expectedCode: http.StatusNotFound,
+	}, {
+		method:       "GET",
+		reqURI:       "/hsm/v2/jobs/job1",
+		dbErr:        hmsds.ErrHMSDSArgMissing,
+		expectedCode: http.StatusBadRequest,
+	}, {
+		// Run by this instance: cancelled locally
+		method:         "DELETE",
+		reqURI:         "/hsm/v2/jobs/job1",
+		dbJob:          job,
+		local:          true,
+		expectedCode:   http.StatusOK,
+		expectedDelete: "job1",
+	}, {
+		// Run by another instance: job deleted
+		method:         "DELETE",
+		reqURI:         "/hsm/v2/jobs/job1",
+		dbJob:          job,
+		dbDidDelete:    true,
+		expectedCode:   http.StatusOK,
+		expectedDelete: "job1",
+	}, {
+		// Gone before it could be deleted
+		method:         "DELETE",
+		reqURI:         "/hsm/v2/jobs/job1",
+		dbJob:          job,
+		expectedCode:   http.StatusNotFound,
+		expectedDelete: "job1",
+	}, {
+		method:       "DELETE",
+		reqURI:       "/hsm/v2/jobs/job1",
+		expectedCode: http.StatusNotFound,
+	}}
+
+	for i, test := range tests {
+		results.GetJob.Return.j = test.dbJob
+		results.GetJob.Return.err = test.dbErr
+		results.DeleteJob.Input.jobId = ""
+		results.DeleteJob.Return.didDelete = test.dbDidDelete
+		results.DeleteJob.Return.err = nil
+		if test.local {
+			p.targets["x0c0s0b0n0"] = &rfPollTarget{
+				job:    &sm.Job{JobData: sm.JobData{Id: "job1"}},
+				rule:   p.policy.Rules[0],
+				compId: "x0c0s0b0n0",
+			}
+		}
+
+		req, err := http.NewRequest(test.method, test.reqURI, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if test.expectedCode != w.Code {
+			t.Errorf("Test %v Failed: Expected status code %v; Received %v (%s)",
+				i, test.expectedCode, w.Code, w.Body.String())
+		}
+		if test.expectedDelete != results.DeleteJob.Input.jobId {
+			t.Errorf("Test %v Failed: Expected '%s' deleted; Received '%s'",
+				i, test.expectedDelete, results.DeleteJob.Input.jobId)
+		}
+		if len(p.targets) != 0 {
+			t.Errorf("Test %v Failed: Polling not cancelled", i)
+		}
+	}
+}
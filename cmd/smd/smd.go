@@ -34,6 +34,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	base "github.com/Cray-HPE/hms-base/v2"
@@ -72,11 +73,6 @@ type SCNUrl struct {
 
 type SCNSubMap [SCNMAP_MAX]map[string][]SCNUrl
 
-type Job struct {
-	job        *sm.Job
-	cancelChan chan bool
-}
-
 const httpListenDefault = ":27779"
 
 type SmD struct {
@@ -92,18 +88,36 @@ type SmD struct {
 	dbPort    int
 	dbOpts    string
 
-	logDir          string
-	tlsCert         string
-	tlsKey          string
-	proxyURL        string
-	httpListen      string
-	msgbusListen    string
-	logLevelIn      int
-	msgbusConfig    msgbus.MsgBusConfig
-	msgbusHandle    msgbus.MsgBusIO
-	hwInvHistAgeMax int
-	smapCompEP      *SyncMap
-	genTestPayloads string
+	logDir           string
+	tlsCert          string
+	tlsKey           string
+	proxyURL         string
+	httpListen       string
+	msgbusListen     string
+	logLevelIn       int
+	msgbusConfig     msgbus.MsgBusConfig
+	msgbusHandle     msgbus.MsgBusIO
+	hwInvHistAgeMax  int
+	smapCompEP       *SyncMap
+	sensorCache      *SensorCache
+	eventRules       *EventRuleSet
+	eventRulesFile   string
+	rfEventLogMax    int
+	rfEventLogAgeMax int
+	compHistMax      int
+	compHistAgeMax   int
+	rfEventTracker   *rfEventTracker
+	rfEventWorkers   int
+	rfEventQueueLen  int
+	rfEventDest      string
+	rfEventListen    string
+	rfSubInterval    int
+	rfPollPolicyFile string
+	statePolicyFile  string
+	statePolicy      *compStatePolicy
+	clusterInterval  int
+	partClaim        string
+	genTestPayloads  string
 
 	// v2 APIs
 	apiRootV2           string
@@ -118,6 +132,13 @@ type SmD struct {
 	hsnIntBaseV2        string
 	hwinvByLocBaseV2    string
 	hwinvByFRUBaseV2    string
+	hwinvFwBaseV2       string
+	hwinvFanBaseV2      string
+	sensorsBaseV2       string
+	compHealthBaseV2    string
+	eventRulesBaseV2    string
+	rfEventLogBaseV2    string
+	jobsBaseV2          string
 	invDiscoverBaseV2   string
 	invDiscStatusBaseV2 string
 	nodeMapBaseV2       string
@@ -130,7 +151,7 @@ type SmD struct {
 	powerMapBaseV2      string
 
 	wp            *base.WorkerPool
-	wpRFEvent     *base.WorkerPool
+	wpRFEvent     []*base.WorkerPool
 	scnSubs       sm.SCNSubscriptionArray
 	scnSubMap     SCNSubMap
 	scnSubLock    sync.Mutex
@@ -150,14 +171,20 @@ type SmD struct {
 	ccs        *compcreds.CompCredStore
 
 	// Job Sync
-	jobLock     sync.Mutex
-	jobList     map[string]*Job // List of Jobs by jobId running on this HSM instance.
-	srfpJobList map[string]*Job // List of srfp Jobs by xname running on this HSM instance.
+	rfPoller *rfPoller // Runs the State Redfish Poll jobs of this HSM instance.
+
+	// Coordination with other HSM instances
+	cluster    *smdCluster
+	changeFeed atomic.Bool // Listening for database change notifications
 
 	//Discovery Sync
 	discMap     map[string]int
 	discMapLock sync.Mutex
 
+	// Event-driven hardware inventory refresh
+	hwInvRefreshMap  map[string]bool
+	hwInvRefreshLock sync.Mutex
+
 	//router
 	router *mux.Router
 
@@ -308,36 +335,51 @@ func removeSCNMapSubscription(subMap *SCNSubMap, sub *sm.SCNSubscription) {
 	}
 }
 
+// Reload the SCN subscription tables from the database.
+func (s *SmD) refreshSCNSubscriptions() error {
+	s.scnSubLock.Lock()
+	defer s.scnSubLock.Unlock()
+	subs, err := s.db.GetSCNSubscriptionsAll()
+	if err != nil {
+		return err
+	}
+	// Refresh the internal subscription list and map
+	newSCNSubMap := SCNSubMap{}
+	for _, sub := range subs.SubscriptionList {
+		addSCNMapSubscription(&newSCNSubMap, &sub)
+	}
+	s.scnSubs = *subs
+	s.scnSubMap = newSCNSubMap
+	return nil
+}
+
 // Spin off a thread to periodically refresh the SCN subscription tables.
+// Every instance of HSM sends SCNs for the changes it makes, so each keeps
+// its own copy rather than this being left to the leader.  Changes are
+// normally picked up right away from the change feed, so this is just a
+// fallback while that is working.
 func (s *SmD) SCNSubscriptionRefresh() {
 	go func() {
 		for {
-			s.scnSubLock.Lock()
-			subs, err := s.db.GetSCNSubscriptionsAll()
-			if err != nil {
-				s.scnSubLock.Unlock()
+			if err := s.refreshSCNSubscriptions(); err != nil {
 				s.LogAlways("SCNSubscriptionRefresh(): Lookup failure: %s", err)
 				time.Sleep(10 * time.Second)
 			} else {
-				//TODO: Make this only refresh if there was a change in the db not made by this instance of HSM.
-				// Refresh the internal subscription list and map
-				newSCNSubMap := SCNSubMap{}
-				for _, sub := range subs.SubscriptionList {
-					addSCNMapSubscription(&newSCNSubMap, &sub)
-				}
-				s.scnSubs = *subs
-				s.scnSubMap = newSCNSubMap
-				s.scnSubLock.Unlock()
-				time.Sleep(30 * time.Second)
+				time.Sleep(s.refreshInterval(30 * time.Second))
 			}
 		}
 	}()
 }
 
 // Spin off a thread to periodically clean up expired component locks.
+// Only the leader does this.
 func (s *SmD) CompReservationCleanup() {
 	go func() {
 		for {
+			if !s.isLeader() {
+				time.Sleep(30 * time.Second)
+				continue
+			}
 			xnames, err := s.db.DeleteCompReservationsExpired()
 			if err != nil {
 				s.LogAlways("CompReservationCleanup(): Lookup failure: %s", err)
@@ -354,7 +396,8 @@ func (s *SmD) CompReservationCleanup() {
 
 // Jobs running locally in an intance of HSM can become orphaned if that
 // instance of HSM dies. This spins off a goroutine to periodically check for
-// orphaned jobs and picks them up.
+// orphaned jobs and picks up the ones for the endpoints this instance owns.
+// Finished jobs are cleaned up by the leader.
 func (s *SmD) JobSync() {
 	go func() {
 		for {
@@ -368,8 +411,10 @@ func (s *SmD) JobSync() {
 			} else if jobs != nil {
 				for _, job := range jobs {
 					if job.Status == sm.JobComplete {
-						s.db.DeleteJob(job.Id)
-					} else {
+						if s.isLeader() {
+							s.db.DeleteJob(job.Id)
+						}
+					} else if s.ownsJob(job) {
 						// Delete and remake the job. This will make it so only
 						// one HSM instance can pick up the job.
 						didDelete, err := s.db.DeleteJob(job.Id)
@@ -381,8 +426,14 @@ func (s *SmD) JobSync() {
 						case sm.JobTypeSRFP:
 							data, ok := job.Data.(*sm.SrfpJobData)
 							if ok {
-								// Start orphaned SRFP jobs without the initial delay
-								s.doStateRFPoll(data.CompId, 0)
+								// Start orphaned SRFP jobs without the initial
+								// delay if the component is still in a state
+								// that gets polled.
+								comp, err := s.db.GetComponentByID(data.CompId)
+								if err == nil && comp != nil {
+									s.rfPoller.cancel(data.CompId)
+									s.rfPoller.add(data.CompId, comp.State, 0)
+								}
 							}
 						}
 						numNewJobs++
@@ -404,11 +455,8 @@ func (s *SmD) JobSync() {
 
 // Discovery jobs running locally in an intance of HSM can become orphaned if that
 // instance of HSM dies. This spins off a goroutine to periodically check for
-// orphaned discovery jobs and picks them up.
-// TODO: Close potential race condition between GetRFEndpointsFilter() and when
-//     discoverFromEndpoint() updates the DiscInfo.LastAttempt where another HSM
-//     instance could run GetRFEndpointsFilter() to get the same list of orphaned
-//     EPs. For now, this should be rare.
+// orphaned discovery jobs and picks up the ones for the endpoints this
+// instance owns, so no two instances pick up the same one.
 func (s *SmD) DiscoverySync() {
 	go func() {
 		for {
@@ -424,7 +472,8 @@ func (s *SmD) DiscoverySync() {
 					lastAttempt, _ := time.Parse("2006-01-02T15:04:05.000000Z07:00", ep.DiscInfo.LastAttempt)
 					// Consider discovery jobs that have not updated
 					// in 30 minutes to have been orphaned.
-					if time.Since(lastAttempt) >= (time.Minute * 30) {
+					if time.Since(lastAttempt) >= (time.Minute*30) &&
+						s.ownsEndpoint(ep.ID) {
 						// Take on orphaned discovery job
 						go s.discoverFromEndpoint(ep, 0, true)
 						numNewJobs++
@@ -524,7 +573,8 @@ func (s *SmD) GetHTTPClient() *retryablehttp.Client {
 // Parse command line options.
 func (s *SmD) parseCmdLine() {
 	flag.StringVar(&s.msgbusListen, "msg-host", "",
-		"Host:Port:Topic for message bus. Not used if unset")
+		"Host:Port:Topic for message bus, or event source URL (kafka://, "+
+			"http(s)://, file://, nats(+tls)://, mqtt(s)://). Not used if unset")
 	flag.StringVar(&s.slsUrl, "sls-url", "",
 		"Host:Port/base_path for communicating with SLS. Not used if unset")
 	flag.StringVar(&s.hbtdUrl, "hbtd-url", "",
@@ -683,6 +733,155 @@ func (s *SmD) parseCmdLine() {
 		}
 	}
 
+	sensorCacheTTL := sensorCacheTTLDefault
+	envvar = "SMD_SENSOR_CACHE_TTL"
+	if val := os.Getenv(envvar); val != "" {
+		ttl, err := strconv.ParseInt(val, 10, 64)
+		if err != nil || ttl < 0 {
+			fmt.Printf("Bad SMD_SENSOR_CACHE_TTL '%s': Must be 0+ seconds", val)
+		} else {
+			sensorCacheTTL = int(ttl)
+		}
+	}
+	sensorBMCInterval := sensorBMCIntervalDefault
+	envvar = "SMD_SENSOR_BMC_INTERVAL"
+	if val := os.Getenv(envvar); val != "" {
+		interval, err := strconv.ParseInt(val, 10, 64)
+		if err != nil || interval < 0 {
+			fmt.Printf("Bad SMD_SENSOR_BMC_INTERVAL '%s': Must be 0+ seconds", val)
+		} else {
+			sensorBMCInterval = int(interval)
+		}
+	}
+	s.sensorCache = NewSensorCache(sensorCacheTTL, sensorBMCInterval)
+
+	envvar = "SMD_EVENT_RULES_FILE"
+	if val := os.Getenv(envvar); val != "" {
+		s.eventRulesFile = val
+	}
+
+	s.rfEventLogMax = rfEventLogMaxDefault
+	envvar = "SMD_EVENTLOG_MAX_ENTRIES"
+	if val := os.Getenv(envvar); val != "" {
+		maxEnts, err := strconv.ParseInt(val, 10, 64)
+		if err != nil || maxEnts < 0 {
+			fmt.Printf("Bad SMD_EVENTLOG_MAX_ENTRIES '%s': Must be 0+ entries", val)
+		} else {
+			s.rfEventLogMax = int(maxEnts)
+		}
+	}
+	s.rfEventLogAgeMax = rfEventLogAgeMaxDefault
+	envvar = "SMD_EVENTLOG_AGE_MAX_DAYS"
+	if val := os.Getenv(envvar); val != "" {
+		maxAge, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			fmt.Printf("Bad SMD_EVENTLOG_AGE_MAX_DAYS '%s': %s", val, err)
+		} else if maxAge < 1 {
+			fmt.Printf("Bad SMD_EVENTLOG_AGE_MAX_DAYS '%s': Must be 1+ days", val)
+		} else {
+			s.rfEventLogAgeMax = int(maxAge)
+		}
+	}
+	s.compHistMax = compHistMaxDefault
+	envvar = "SMD_COMPHIST_MAX_ENTRIES"
+	if val := os.Getenv(envvar); val != "" {
+		maxEnts, err := strconv.ParseInt(val, 10, 64)
+		if err != nil || maxEnts < 0 {
+			fmt.Printf("Bad SMD_COMPHIST_MAX_ENTRIES '%s': Must be 0+ entries", val)
+		} else {
+			s.compHistMax = int(maxEnts)
+		}
+	}
+	s.compHistAgeMax = compHistAgeMaxDefault
+	envvar = "SMD_COMPHIST_AGE_MAX_DAYS"
+	if val := os.Getenv(envvar); val != "" {
+		maxAge, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			fmt.Printf("Bad SMD_COMPHIST_AGE_MAX_DAYS '%s': %s", val, err)
+		} else if maxAge < 1 {
+			fmt.Printf("Bad SMD_COMPHIST_AGE_MAX_DAYS '%s': Must be 1+ days", val)
+		} else {
+			s.compHistAgeMax = int(maxAge)
+		}
+	}
+	dedupWindow := rfEventDedupWindowDefault
+	envvar = "SMD_EVENT_DEDUP_WINDOW"
+	if val := os.Getenv(envvar); val != "" {
+		window, err := strconv.ParseInt(val, 10, 64)
+		if err != nil || window < 0 {
+			fmt.Printf("Bad SMD_EVENT_DEDUP_WINDOW '%s': Must be 0+ seconds", val)
+		} else {
+			dedupWindow = int(window)
+		}
+	}
+	s.rfEventTracker = newRFEventTracker(time.Duration(dedupWindow) * time.Second)
+	s.rfEventWorkers = rfEventWorkersDefault
+	envvar = "SMD_RF_EVENT_WORKERS"
+	if val := os.Getenv(envvar); val != "" {
+		workers, err := strconv.ParseInt(val, 10, 64)
+		if err != nil || workers < 1 {
+			fmt.Printf("Bad SMD_RF_EVENT_WORKERS '%s': Must be 1+ workers", val)
+		} else {
+			s.rfEventWorkers = int(workers)
+		}
+	}
+	s.rfEventQueueLen = rfEventWorkerQueueDefault
+	envvar = "SMD_RF_EVENT_QUEUE_DEPTH"
+	if val := os.Getenv(envvar); val != "" {
+		depth, err := strconv.ParseInt(val, 10, 64)
+		if err != nil || depth < 1 {
+			fmt.Printf("Bad SMD_RF_EVENT_QUEUE_DEPTH '%s': Must be 1+ events", val)
+		} else {
+			s.rfEventQueueLen = int(depth)
+		}
+	}
+
+	envvar = "SMD_RF_EVENT_DESTINATION"
+	if val := os.Getenv(envvar); val != "" {
+		s.rfEventDest = val
+	}
+	s.rfEventListen = rfEventListenDefault
+	envvar = "SMD_RF_EVENT_LISTEN"
+	if val := os.Getenv(envvar); val != "" {
+		s.rfEventListen = val
+	}
+	s.rfSubInterval = rfSubscribeIntervalDefault
+	envvar = "SMD_RF_SUBSCRIBE_INTERVAL"
+	if val := os.Getenv(envvar); val != "" {
+		interval, err := strconv.ParseInt(val, 10, 64)
+		if err != nil || interval < 1 {
+			fmt.Printf("Bad SMD_RF_SUBSCRIBE_INTERVAL '%s': Must be 1+ minutes", val)
+		} else {
+			s.rfSubInterval = int(interval)
+		}
+	}
+	envvar = "SMD_RF_POLL_POLICY_FILE"
+	if val := os.Getenv(envvar); val != "" {
+		s.rfPollPolicyFile = val
+	}
+	envvar = "SMD_STATE_POLICY_FILE"
+	if val := os.Getenv(envvar); val != "" {
+		s.statePolicyFile = val
+	}
+	s.clusterInterval = clusterHeartbeatDefault
+	envvar = "SMD_CLUSTER_HEARTBEAT"
+	if val := os.Getenv(envvar); val != "" {
+		interval, err := strconv.ParseInt(val, 10, 64)
+		if err != nil || interval < 1 {
+			fmt.Printf("Bad SMD_CLUSTER_HEARTBEAT '%s': Must be 1+ seconds", val)
+		} else {
+			s.clusterInterval = int(interval)
+		}
+	}
+
+	// Token claim binding requests to a partition.  Empty to only use the
+	// HMS-Partition header.
+	s.partClaim = partClaimDefault
+	envvar = "SMD_PARTITION_CLAIM"
+	if val, ok := os.LookupEnv(envvar); ok {
+		s.partClaim = strings.TrimSpace(val)
+	}
+
 	s.hmsConfigPath = "/hms_config/hms_config.json"
 	envvar = "HMS_CONFIG_PATH"
 	if val := os.Getenv(envvar); val != "" {
@@ -735,6 +934,13 @@ func main() {
 	s.hsnIntBaseV2 = s.apiRootV2 + "/Inventory/HSNInterfaces"
 	s.hwinvByLocBaseV2 = s.apiRootV2 + "/Inventory/Hardware"
 	s.hwinvByFRUBaseV2 = s.apiRootV2 + "/Inventory/HardwareByFRU"
+	s.hwinvFwBaseV2 = s.apiRootV2 + "/Inventory/Firmware"
+	s.hwinvFanBaseV2 = s.apiRootV2 + "/Inventory/Fans"
+	s.sensorsBaseV2 = s.apiRootV2 + "/Inventory/Sensors"
+	s.compHealthBaseV2 = s.stateBaseV2 + "/Health"
+	s.eventRulesBaseV2 = s.apiRootV2 + "/EventRules"
+	s.rfEventLogBaseV2 = s.apiRootV2 + "/Events"
+	s.jobsBaseV2 = s.apiRootV2 + "/jobs"
 	s.invDiscoverBaseV2 = s.apiRootV2 + "/Inventory/Discover"
 	s.invDiscStatusBaseV2 = s.apiRootV2 + "/Inventory/DiscoveryStatus"
 	s.subscriptionBaseV2 = s.apiRootV2 + "/Subscriptions"
@@ -884,6 +1090,45 @@ func main() {
 		s.LogAlways("CA_URI: '%s'.",envData)
 	}
 
+	// Load the event rules.  The built-in rules are used if the file
+	// is bad so that power state changes are still tracked.
+	fileRules := []*sm.EventRule{}
+	if s.eventRulesFile != "" {
+		rules, err := LoadEventRulesFile(s.eventRulesFile)
+		if err != nil {
+			s.LogAlways("Ignoring event rules file '%s': %s", s.eventRulesFile, err)
+		} else {
+			s.LogAlways("Loaded %d event rules from '%s'", len(rules), s.eventRulesFile)
+			fileRules = rules
+		}
+	}
+	s.eventRules = NewEventRuleSet(fileRules)
+	s.EventRuleRefresh()
+
+	// Load the state transition policy, if any.  State changes are only
+	// restricted by the built-in start states without one.
+	if s.statePolicyFile != "" {
+		policy, err := LoadCompStatePolicyFile(s.statePolicyFile)
+		if err != nil {
+			s.LogAlways("Ignoring state policy file '%s': %s", s.statePolicyFile, err)
+		} else {
+			s.LogAlways("Loaded %d state rules from '%s'", len(policy.Rules), s.statePolicyFile)
+			s.statePolicy = policy
+		}
+	}
+
+	// Join the other instances of HSM, electing a leader for the singleton
+	// maintenance tasks and dividing up per-endpoint work between them.
+	s.cluster = NewCluster(&s, serviceName,
+		time.Duration(s.clusterInterval)*time.Second)
+	s.cluster.Start()
+
+	// Keep the Redfish event log within its size and age limits.
+	s.RFEventLogPrune()
+
+	// Keep the component history within its size and age limits.
+	s.CompHistoryPrune()
+
 	//Initialize the SCN subscription list and map
 	s.scnSubs.SubscriptionList = []sm.SCNSubscription{}
 	s.SCNSubscriptionRefresh()
@@ -893,24 +1138,39 @@ func main() {
 	s.wp = base.NewWorkerPool(42, 10000)
 	s.wp.Run()
 
-	s.wpRFEvent = base.NewWorkerPool(1000, 10000)
-	s.wpRFEvent.Run()
+	// Events from each controller are handled in order by a single worker.
+	s.wpRFEvent = newRFEventWorkerPools(s.rfEventWorkers, s.rfEventQueueLen)
 
 	// Start monitoring message bus, if configured
 	s.smapCompEP = NewSyncMap(ComponentEndpointSMap(&s))
 	go s.StartRFEventMonitor()
 
+	// Refresh the SCN subscriptions, event rules and ComponentEndpoint
+	// lookups right away when they are changed through any instance of HSM.
+	s.ChangeFeed()
+
 	// Start the component lock cleanup thread
 	s.CompReservationCleanup()
 
 	// Start the Job Sync thread to pick up orphaned
 	// jobs from other HSM instances.
-	s.jobList = make(map[string]*Job, 0)
-	s.srfpJobList = make(map[string]*Job, 0)
+	pollPolicy := defaultRFPollPolicy()
+	if s.rfPollPolicyFile != "" {
+		policy, err := LoadRFPollPolicyFile(s.rfPollPolicyFile)
+		if err != nil {
+			s.LogAlways("Ignoring poll policy file '%s': %s", s.rfPollPolicyFile, err)
+		} else {
+			s.LogAlways("Loaded poll policy from '%s'", s.rfPollPolicyFile)
+			pollPolicy = policy
+		}
+	}
+	s.rfPoller = NewRFPoller(&s, pollPolicy)
+	s.rfPoller.Start()
 	s.discMap = make(map[string]int, 0)
 	s.JobSync()
 	s.DiscoverySync()
 	s.DiscoveryUpdater()
+	s.RFSubscriptionSync()
 
 	// Start serving HTTP
 	routes := s.generateRoutes()
@@ -920,6 +1180,7 @@ func main() {
 	s.LogAlways("Listening for connections.")
 	err = s.setupCerts(s.tlsCert, s.tlsKey)
 	if err == nil {
+		go s.StartRFEventListener()
 		err = http.ListenAndServeTLS(s.httpListen, s.tlsCert, s.tlsKey, router)
 	} else {
 		// This is just a fallback for testing.  There will not be a non-TLS
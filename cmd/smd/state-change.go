@@ -27,11 +27,8 @@ import (
 	base "github.com/Cray-HPE/hms-base/v2"
 	"github.com/Cray-HPE/hms-xname/xnametypes"
 	"github.com/Cray-HPE/hms-smd/v2/internal/hmsds"
-	"github.com/Cray-HPE/hms-smd/v2/pkg/redfish"
-	"github.com/Cray-HPE/hms-smd/v2/pkg/sm"
 	"strconv"
 	"strings"
-	"time"
 )
 
 var e = base.NewHMSError("sm", "server error")
@@ -52,6 +49,8 @@ var ErrSMDNoSwStatus = e.NewChild("Missing SoftwareStatus")
 var ErrSMDNoRole = e.NewChild("Missing Role")
 var ErrSMDNoNID = e.NewChild("Missing NID")
 var ErrSMDTooManyIDs = e.NewChild("too many IDs")
+var ErrSMDNotInPartInfo = e.NewChild("component(s) not in the given Group/Partition")
+var ErrSMDStateTransition = e.NewChild("state transition not allowed by policy")
 
 type CompUpdateType string
 
@@ -123,6 +122,9 @@ type CompUpdate struct {
 	UpdateType   string          `json:"UpdateType,omitempty"`
 	Force        bool            `json:"Force,omitempty"`
 	ExtendedInfo json.RawMessage `json:"ExtendedInfo,omitempty"`
+
+	// Who asked for the update, recorded in the component history.
+	Source string `json:"-"`
 }
 
 // Update the database based on the input fields and the selected operation.
@@ -153,11 +155,14 @@ func (s *SmD) doCompUpdate(u *CompUpdate, name string) error {
 			compIDs = append(compIDs, normID)
 		}
 	}
-	// This isn't used currently, but will need to be used to filter
-	// every operation so we don't have things touching data they
-	// shouldn't.
+	// Only touch components in the given groups/partitions, if any, so
+	// e.g. a request bound to a partition can't change anything outside
+	// of it.
 	pi.Group = append(pi.Group, u.Group...)
 	pi.Partition = append(pi.Partition, u.Partition...)
+	if err := s.checkCompsInPartInfo(compIDs, pi, name); err != nil {
+		return err
+	}
 
 	var err error
 	switch GetCompUpdateType(u.UpdateType) {
@@ -171,22 +176,20 @@ func (s *SmD) doCompUpdate(u *CompUpdate, name string) error {
 		}
 		data.State = base.VerifyNormalizeState(u.State)
 		data.Flag = base.VerifyNormalizeFlag(nflag)
-		scnIDs, err = s.dbUpdateCompState(compIDs, u.State, nflag, u.Force, pi)
+		// The policy, if any, is checked against the locked components
+		// in the same transaction as the update.
+		check, serr := s.statePolicyCheck(u.Source, u.Force)
+		scnIDs, err = s.dbUpdateCompState(compIDs, u.State, nflag, u.Force, pi,
+			u.Source, check)
+		if serr != nil && len(serr.Rejected) != 0 {
+			return serr
+		}
 		if err == nil {
-			if data.State == base.StateStandby.String() {
-				// Start State Redfish Polling jobs for any nodes
-				// transitioning to standby.
-				for _, id := range scnIDs {
-					if xnametypes.GetHMSTypeString(id) == xnametypes.Node.String() {
-						s.doStateRFPoll(id, 30)
-					}
-				}
-			} else {
-				// Find and cancel State Redfish Polling jobs for any component
-				// transitioning to a state other than standby.
-				for _, id := range scnIDs {
-					s.cancelStateRFPoll(id)
-				}
+			// Start State Redfish Polling of components left in a
+			// transitional state, e.g. nodes going to Standby, and cancel
+			// any polling of components leaving one.
+			for _, id := range scnIDs {
+				s.doStateRFPoll(id, data.State)
 			}
 		}
 	case FlagOnlyUpdate:
@@ -197,19 +200,20 @@ func (s *SmD) doCompUpdate(u *CompUpdate, name string) error {
 			return ErrSMDNoFlag
 		}
 		data.Flag = base.VerifyNormalizeFlag(u.Flag)
-		scnIDs, err = s.dbUpdateCompFlagOnly(compIDs, u.Flag, pi)
+		scnIDs, err = s.dbUpdateCompFlagOnly(compIDs, u.Flag, pi, u.Source)
 	case EnabledUpdate:
 		if u.Enabled == nil {
 			return ErrSMDNoEnabled
 		}
 		data.Enabled = u.Enabled
-		scnIDs, err = s.dbUpdateCompEnabled(compIDs, u.Enabled, pi)
+		scnIDs, err = s.dbUpdateCompEnabled(compIDs, u.Enabled, pi, u.Source)
 	case SwStatusUpdate:
 		if u.SwStatus == nil {
 			return ErrSMDNoSwStatus
 		}
 		data.SwStatus = *u.SwStatus
-		scnIDs, err = s.dbUpdateCompSwStatus(compIDs, *u.SwStatus, pi)
+		scnIDs, err = s.dbUpdateCompSwStatus(compIDs, *u.SwStatus, pi,
+			u.Source)
 	case RoleUpdate:
 		subRole := ""
 		if u.Role == nil {
@@ -220,7 +224,8 @@ func (s *SmD) doCompUpdate(u *CompUpdate, name string) error {
 			subRole = *u.SubRole
 			data.SubRole = base.VerifyNormalizeSubRole(subRole)
 		}
-		scnIDs, err = s.dbUpdateCompRole(compIDs, *u.Role, subRole, pi)
+		scnIDs, err = s.dbUpdateCompRole(compIDs, *u.Role, subRole, pi,
+			u.Source)
 	case SingleNIDUpdate:
 		if u.NID == nil {
 			return ErrSMDNoNID
@@ -244,6 +249,33 @@ func (s *SmD) doCompUpdate(u *CompUpdate, name string) error {
 	return nil
 }
 
+// Verify that all of ids are in the groups and partitions in pi, returning
+// ErrSMDNotInPartInfo if not.  Nothing to check if pi is empty.
+func (s *SmD) checkCompsInPartInfo(
+	ids []string,
+	pi *hmsds.PartInfo,
+	name string,
+) error {
+	if pi == nil || (len(pi.Group) == 0 && len(pi.Partition) == 0) {
+		return nil
+	}
+	inIDs, err := s.db.GetComponentIDs(hmsds.IDs(ids), hmsds.PI(pi),
+		hmsds.From(name))
+	if err != nil {
+		return err
+	}
+	inMap := make(map[string]bool, len(inIDs))
+	for _, id := range inIDs {
+		inMap[id] = true
+	}
+	for _, id := range ids {
+		if !inMap[id] {
+			return ErrSMDNotInPartInfo
+		}
+	}
+	return nil
+}
+
 // For either single or bulk State/Flag updates.  Single updates are faster
 // because we only have one target and don't need a second query to see if it
 // needs to be changed.  We can just see what happens.
@@ -252,8 +284,10 @@ func (s *SmD) dbUpdateCompState(
 	state, flag string,
 	force bool,
 	pi *hmsds.PartInfo,
+	source string,
+	check hmsds.CompStateCheck,
 ) ([]string, error) {
-	return s.db.UpdateCompStates(ids, state, flag, force, pi)
+	return s.db.UpdateCompStates(ids, state, flag, force, pi, source, check)
 }
 
 // For either single or bulk Flag-only updates (state is not affected).  Single
@@ -263,16 +297,17 @@ func (s *SmD) dbUpdateCompFlagOnly(
 	ids []string,
 	flag string,
 	pi *hmsds.PartInfo,
+	source string,
 ) ([]string, error) {
 	if len(ids) == 1 {
-		rowsAffected, err := s.db.UpdateCompFlagOnly(ids[0], flag)
+		rowsAffected, err := s.db.UpdateCompFlagOnly(ids[0], flag, source)
 		if rowsAffected != 0 {
 			return []string{ids[0]}, err
 		} else {
 			return []string{}, err
 		}
 	} else if len(ids) > 1 {
-		return s.db.BulkUpdateCompFlagOnly(ids, flag)
+		return s.db.BulkUpdateCompFlagOnly(ids, flag, source)
 	}
 	return []string{}, ErrSMDNoIDs
 }
@@ -284,15 +319,16 @@ func (s *SmD) dbUpdateCompEnabled(
 	ids []string,
 	enabled *bool,
 	pi *hmsds.PartInfo,
+	source string,
 ) ([]string, error) {
 	if len(ids) == 1 {
-		rowsAffected, err := s.db.UpdateCompEnabled(ids[0], *enabled)
+		rowsAffected, err := s.db.UpdateCompEnabled(ids[0], *enabled, source)
 		if rowsAffected != 0 {
 			return []string{ids[0]}, err
 		}
 		return []string{}, err
 	} else if len(ids) > 1 {
-		return s.db.BulkUpdateCompEnabled(ids, *enabled)
+		return s.db.BulkUpdateCompEnabled(ids, *enabled, source)
 	}
 	return []string{}, ErrSMDNoIDs
 }
@@ -304,15 +340,16 @@ func (s *SmD) dbUpdateCompSwStatus(
 	ids []string,
 	swstatus string,
 	pi *hmsds.PartInfo,
+	source string,
 ) ([]string, error) {
 	if len(ids) == 1 {
-		rowsAffected, err := s.db.UpdateCompSwStatus(ids[0], swstatus)
+		rowsAffected, err := s.db.UpdateCompSwStatus(ids[0], swstatus, source)
 		if rowsAffected != 0 {
 			return []string{ids[0]}, err
 		}
 		return []string{}, err
 	} else if len(ids) > 1 {
-		return s.db.BulkUpdateCompSwStatus(ids, swstatus)
+		return s.db.BulkUpdateCompSwStatus(ids, swstatus, source)
 	}
 	return []string{}, ErrSMDNoIDs
 }
@@ -325,15 +362,16 @@ func (s *SmD) dbUpdateCompRole(
 	role string,
 	subRole string,
 	pi *hmsds.PartInfo,
+	source string,
 ) ([]string, error) {
 	if len(ids) == 1 {
-		rowsAffected, err := s.db.UpdateCompRole(ids[0], role, subRole)
+		rowsAffected, err := s.db.UpdateCompRole(ids[0], role, subRole, source)
 		if rowsAffected != 0 {
 			return []string{ids[0]}, err
 		}
 		return []string{}, err
 	} else if len(ids) > 1 {
-		return s.db.BulkUpdateCompRole(ids, role, subRole)
+		return s.db.BulkUpdateCompRole(ids, role, subRole, source)
 	}
 	return []string{}, ErrSMDNoIDs
 }
@@ -358,249 +396,25 @@ func (s *SmD) dbUpdateCompSingleNID(
 	return ErrSMDNoIDs
 }
 
-// Starts a State Redfish Poll job for a component.
-func (s *SmD) doStateRFPoll(id string, delay int) error {
-	var err error
-	job := new(Job)
-	job.job, err = sm.NewStateRFPollJob(id, delay, 10, 30, 20)
-	if err != nil {
-		return err
-	}
-	s.jobLock.Lock()
-	job.job.Id, err = s.db.InsertJob(job.job)
-	if err != nil {
-		s.jobLock.Unlock()
-		return err
-	}
-	job.cancelChan = make(chan bool)
-	s.jobList[job.job.Id] = job
-	s.srfpJobList[id] = job
-	s.jobLock.Unlock()
-	go s.doPollRFState(job)
-	return nil
-}
-
-// Periodically poll for the redfish powerstate of a component. This gathers
-// the existing ComponentEndpoint and credentials present in either the secure
-// store or the database (if the secure store is not enabled) for the xname to
-// check the power state of the component via redfish.
-// This polls for component redfish state every 10 seconds with an initial
-// start delay of 30 seconds until the redfish state changes to "off" or
-// cancelled.
-func (s *SmD) doPollRFState(job *Job) {
-	var (
-		user string
-		pw   string
-		cep  *sm.ComponentEndpoint
-		ep   *rf.RedfishEP
-		url  string
-		err  error
-	)
-
-	data, ok := job.job.Data.(*sm.SrfpJobData)
-	if !ok {
-		return
-	}
-
-	// Start the job's keep alive goroutine
-	kaStopChan := make(chan bool)
-	go s.doSRFPKeepAlive(job.job, kaStopChan)
-	defer func() { kaStopChan <- true }()
-
-	// This initial ticker will delay the start of the polling for 30 seconds
-	// to allow for the possibility of redfish events.
-	ticker := time.NewTicker(time.Duration(data.Delay) * time.Second)
-	defer ticker.Stop()
-
-	delayed := false
-	haveData := false
-	setErrStatus := false
-	// Initially true to cause an "InProgress" update.
-	updatedErrStatus := true
-	for {
-		// Update the job status only when it changes
-		if setErrStatus && !updatedErrStatus {
-			s.db.UpdateJob(job.job.Id, sm.JobError)
-			updatedErrStatus = true
-		} else if !setErrStatus && updatedErrStatus {
-			s.db.UpdateJob(job.job.Id, sm.JobInProgress)
-			updatedErrStatus = false
-		}
-		setErrStatus = false
-		select {
-		case <-job.cancelChan:
-			return
-		case <-ticker.C:
-			// No redfish event came in. Gather the data for polling.
-			// If there are errors gathering the info, we'll retry
-			// every 10 seconds.
-			if !delayed {
-				ticker.Stop()
-				ticker = time.NewTicker(time.Duration(data.Poll) * time.Second)
-				delayed = true
-			}
-			if !haveData {
-				cep, err = s.db.GetCompEndpointByID(data.CompId)
-				if err != nil {
-					continue
-				} else if cep == nil {
-					s.Log(LOG_INFO, "doPollRFState(%s): ComponentEndpoint not found.", data.CompId)
-					setErrStatus = true
-					continue
-				}
-				if s.readVault {
-					// Read component endpoint credentials from the secure store.
-					cred, err := s.ccs.GetCompCred(data.CompId)
-					if err != nil {
-						s.Log(LOG_INFO, "doPollRFState(%s): Failed to get credentials from Vault - %s",
-							data.CompId, err)
-						setErrStatus = true
-						// Retry
-						continue
-					}
-					if len(cred.Username) == 0 {
-						// Receive empty credentials from vault for the component
-						s.Log(LOG_INFO, "doPollRFState(%s): No credentials in Vault - %s",
-							data.CompId, err)
-						setErrStatus = true
-						// Retry
-						continue
-					}
-					user = cred.Username
-					pw = cred.Password
-				} else {
-					// Get credentials from the database redfishendpoints if Vault isn't enabled
-					rep, err := s.db.GetRFEndpointByID(cep.RfEndpointID)
-					if err != nil {
-						s.Log(LOG_INFO, "doPollRFState(%s): Lookup failure: %s", data.CompId, err)
-						setErrStatus = true
-						// Retry
-						continue
-					}
-					if rep == nil {
-						s.Log(LOG_INFO, "doPollRFState(%s): No credentials in the database for %s",
-							data.CompId, cep.RfEndpointID)
-						setErrStatus = true
-						// Retry
-						continue
-					}
-					user = rep.User
-					pw = rep.Password
-				}
-				// Minimally populate a redfish description struct
-				rfEPType := xnametypes.GetHMSType(cep.RfEndpointID)
-				epDesc := rf.RedfishEPDescription{
-					ID:       cep.RfEndpointID,
-					Type:     rfEPType.String(),
-					FQDN:     cep.RfEndpointFQDN,
-					User:     user,
-					Password: pw,
-				}
-				// Make a RedfishEP struct to make our redfish call against.
-				url = cep.RfEndpointFQDN + cep.OdataID
-				ep, _ = rf.NewRedfishEp(&epDesc)
-				haveData = true
-			}
-			// The data needed to start polling has been gathered.
-			// Poll the component for redfish state.
-			if haveData {
-				rfJSON, err := ep.GETRelative(cep.OdataID)
-				if err != nil {
-					s.Log(LOG_INFO, "doPollRFState(%s): redfish call failed: %s: %s",
-						data.CompId, url, err)
-					setErrStatus = true
-					// Maybe the redfishEndpoint data changed?
-					haveData = false
-					continue
-				}
-				// Parse the data that we received
-				rfData := new(rf.ComputerSystem)
-				if err := json.Unmarshal(rfJSON, &rfData); err != nil {
-					if rf.IsUnmarshalTypeError(err) {
-						s.Log(LOG_INFO, "doPollRFState(%s): bad field(s) skipped: %s: %s",
-							data.CompId, url, err)
-					} else {
-						s.Log(LOG_INFO, "doPollRFState(%s): json decode failed: %s: %s",
-							data.CompId, url, err)
-						setErrStatus = true
-						continue
-					}
-				}
-				if strings.ToLower(rfData.PowerState) == "off" {
-					s.db.UpdateJob(job.job.Id, sm.JobComplete)
-					update := new(CompUpdate)
-					update.ComponentIDs = []string{data.CompId}
-					update.UpdateType = StateDataUpdate.String()
-					update.State = base.StateOff.String()
-					s.doCompUpdate(update, "doPollRFState")
-					// No return here because doCompUpdate() will signal our
-					// cancelChan. We'll wait to stop that way.
-				}
-			}
-		}
-	}
-}
-
-// This is the keep alive function for State Redfish Polling jobs. It updates
-// the job's status every 30 seconds until cancelled. It will cancel the job
-// itself if the component either does not exist or the component's state is
-// no longer standby.
-func (s *SmD) doSRFPKeepAlive(job *sm.Job, stopChan chan bool) {
-	data, ok := job.Data.(*sm.SrfpJobData)
-	if !ok {
-		// Shouldn't happen
-		return
-	}
-
-	keepAlive := time.NewTicker(time.Duration(job.KeepAlive) * time.Second)
-	defer keepAlive.Stop()
-
-	for {
-		cancelled := false
-		select {
-		case <-stopChan:
-			return
-		case <-keepAlive.C:
-			if cancelled {
-				continue
-			}
-			comp, err := s.db.GetComponentByID(data.CompId)
-			if err != nil {
-				// Error. Retry?
-				continue
-			}
-			if comp == nil {
-				// Not found. Stop.
-				s.cancelStateRFPoll(data.CompId)
-				cancelled = true
-			} else if comp.State == base.StateStandby.String() {
-				// Still in Standby
-				s.db.UpdateJob(job.Id, "")
-			} else {
-				// State was changed. We're done
-				s.cancelStateRFPoll(data.CompId)
-				cancelled = true
-				// No return here because cancelStateRFPoll() will cause our
-				// parent signal our stopChan. We'll wait to stop that way.
-			}
-		}
+// Starts State Redfish Polling for a component that has entered the given
+// state if the poll policy has a rule for it, and otherwise stops any
+// polling of the component.
+func (s *SmD) doStateRFPoll(id, state string) error {
+	if s.rfPoller == nil {
+		return nil
 	}
+	return s.rfPoller.add(id, state, -1)
 }
 
-// Signals State Redfish Polling jobs to stop.
-func (s *SmD) cancelStateRFPoll(id string) {
-	// Check to see if we have a polling job for this id. If not, there
-	// is either no job or another HSM instance owns the job. The other
-	// HSM instance will cancel its job when it sees that the state has
-	// changed.
-	s.jobLock.Lock()
-	defer s.jobLock.Unlock()
-	if hsmJob, ok := s.srfpJobList[id]; ok {
-		go func(cancelChan chan bool) {
-			cancelChan <- true
-		}(hsmJob.cancelChan)
-		s.db.DeleteJob(hsmJob.job.Id)
-		delete(s.srfpJobList, id)
-		delete(s.jobList, hsmJob.job.Id)
+// Stops State Redfish Polling of a component.  Returns true if this HSM
+// instance was polling it.
+func (s *SmD) cancelStateRFPoll(id string) bool {
+	// Check to see if we are polling this id. If not, there is either no
+	// job or another HSM instance owns the job. The other HSM instance will
+	// cancel its job when it sees that the state has changed or that its
+	// job was deleted.
+	if s.rfPoller != nil {
+		return s.rfPoller.cancel(id)
 	}
+	return false
 }
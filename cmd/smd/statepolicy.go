@@ -0,0 +1,297 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+
+	base "github.com/Cray-HPE/hms-base/v2"
+	"github.com/Cray-HPE/hms-smd/v2/internal/hmsds"
+	"github.com/Cray-HPE/hms-smd/v2/pkg/sm"
+	"github.com/Cray-HPE/hms-xname/xnametypes"
+)
+
+// Component State Transition Policy
+//
+// An optional table, read from a JSON file at start-up, of the state
+// transitions allowed for each component type and, optionally, which
+// services may make them or whether they need Force.  Types no rule
+// applies to are not restricted.  For the others, a transition to a new
+// state is only made if some rule allows it.  Setting a component to the
+// state it is already in, e.g. to change the flag, is always allowed.
+//
+// The policy only adds restrictions.  Transitions the built-in start
+// states don't allow are still skipped unless forced.
+
+// The order valid next states are listed in.
+var compStateOrder = []string{
+	base.StateUnknown.String(),
+	base.StateEmpty.String(),
+	base.StatePopulated.String(),
+	base.StateOff.String(),
+	base.StateOn.String(),
+	base.StateStandby.String(),
+	base.StateHalt.String(),
+	base.StateReady.String(),
+}
+
+// A rule allowing the transitions from any of its From states to any of
+// its To states.
+type compStateRule struct {
+	Name    string   `json:"Name"`
+	Types   []string `json:"Types,omitempty"`   // HMS types, all if empty
+	From    []string `json:"From,omitempty"`    // Current states, any if empty
+	To      []string `json:"To,omitempty"`      // New states, any if empty
+	Sources []string `json:"Sources,omitempty"` // Requesters, any if empty
+	Force   bool     `json:"Force,omitempty"`   // Only allowed with Force
+}
+
+type compStatePolicy struct {
+	Rules []*compStateRule `json:"Rules"`
+}
+
+// Check the policy and normalize its states and types.
+func (p *compStatePolicy) validate() error {
+	for i, r := range p.Rules {
+		if r == nil {
+			return base.NewHMSError("smd", "state rule is empty")
+		}
+		if r.Name == "" {
+			r.Name = "Rule" + strconv.Itoa(i)
+		}
+		if err := r.validate(); err != nil {
+			return base.NewHMSError("smd",
+				"state rule '"+r.Name+"': "+err.Error())
+		}
+	}
+	return nil
+}
+
+// Check the rule and normalize its states and types.
+func (r *compStateRule) validate() error {
+	for i, t := range r.Types {
+		if r.Types[i] = xnametypes.VerifyNormalizeType(t); r.Types[i] == "" {
+			return base.NewHMSError("smd", "invalid type '"+t+"'")
+		}
+	}
+	for i := range r.From {
+		if r.From[i] = base.VerifyNormalizeState(r.From[i]); r.From[i] == "" {
+			return base.ErrHMSStateInvalid
+		}
+	}
+	for i := range r.To {
+		if r.To[i] = base.VerifyNormalizeState(r.To[i]); r.To[i] == "" {
+			return base.ErrHMSStateInvalid
+		}
+	}
+	return nil
+}
+
+// True if the rule applies to components of the given HMS type.
+func (r *compStateRule) hasType(hmsType string) bool {
+	if len(r.Types) == 0 {
+		return true
+	}
+	for _, t := range r.Types {
+		if t == hmsType {
+			return true
+		}
+	}
+	return false
+}
+
+// True if the rule allows source to change a component from state to
+// newState.  Sources match case-insensitively, either in full or up to the
+// first ':', so e.g. RedfishEvent matches the events from every BMC.
+func (r *compStateRule) allows(state, newState, source string, force bool) bool {
+	if r.Force && !force {
+		return false
+	}
+	if len(r.From) != 0 && !hasState(r.From, state) {
+		return false
+	}
+	if len(r.To) != 0 && !hasState(r.To, newState) {
+		return false
+	}
+	if len(r.Sources) == 0 {
+		return true
+	}
+	prefix := strings.SplitN(source, ":", 2)[0]
+	for _, src := range r.Sources {
+		if strings.EqualFold(src, source) || strings.EqualFold(src, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasState(states []string, state string) bool {
+	for _, s := range states {
+		if strings.EqualFold(s, state) {
+			return true
+		}
+	}
+	return false
+}
+
+// True if any rule applies to components of the given HMS type.
+func (p *compStatePolicy) governs(hmsType string) bool {
+	for _, r := range p.Rules {
+		if r.hasType(hmsType) {
+			return true
+		}
+	}
+	return false
+}
+
+// True if the policy allows source to change a component of the given
+// HMS type from state to newState.
+func (p *compStatePolicy) allows(
+	hmsType, state, newState, source string,
+	force bool,
+) bool {
+	if !p.governs(hmsType) || strings.EqualFold(state, newState) {
+		return true
+	}
+	for _, r := range p.Rules {
+		if r.hasType(hmsType) && r.allows(state, newState, source, force) {
+			return true
+		}
+	}
+	return false
+}
+
+// The states source may change a component of the given HMS type to from
+// its current state.  Without force, only states the built-in start states
+// allow are included.
+func (p *compStatePolicy) nextStates(
+	hmsType, state, source string,
+	force bool,
+) []string {
+	next := []string{}
+	for _, newState := range compStateOrder {
+		if strings.EqualFold(state, newState) ||
+			!p.allows(hmsType, state, newState, source, force) {
+			continue
+		}
+		if !force {
+			startStates, _ := base.GetValidStartStates(newState)
+			if !hasState(startStates, state) {
+				continue
+			}
+		}
+		next = append(next, newState)
+	}
+	return next
+}
+
+// Read a state transition policy from a JSON file.
+func LoadCompStatePolicyFile(path string) (*compStatePolicy, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	p := new(compStatePolicy)
+	if err := json.Unmarshal(data, p); err != nil {
+		return nil, err
+	}
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Returned by doCompUpdate when the policy does not allow some of the
+// requested state changes.  None of them are made.
+type compStateError struct {
+	Rejected []*sm.CompStateRejection
+}
+
+func (e *compStateError) Error() string {
+	ids := make([]string, 0, len(e.Rejected))
+	for _, rej := range e.Rejected {
+		ids = append(ids, rej.ID+" ("+rej.State+"->"+rej.NewState+")")
+	}
+	return ErrSMDStateTransition.Error() + ": " + strings.Join(ids, ", ")
+}
+
+// Return a check for the database to run against each component a state
+// change applies to once it has been locked, and the error the policy's
+// rejections are collected in.  Both are nil if there is no policy.
+func (s *SmD) statePolicyCheck(
+	source string,
+	force bool,
+) (hmsds.CompStateCheck, *compStateError) {
+	policy := s.statePolicy
+	if policy == nil {
+		return nil, nil
+	}
+	serr := &compStateError{Rejected: []*sm.CompStateRejection{}}
+	check := func(comp *base.Component, newState string) error {
+		// Invalid states are left for the update to reject.
+		newState = base.VerifyNormalizeState(newState)
+		hmsType := xnametypes.GetHMSTypeString(comp.ID)
+		if newState == "" ||
+			policy.allows(hmsType, comp.State, newState, source, force) {
+			return nil
+		}
+		rej := &sm.CompStateRejection{
+			ID:              comp.ID,
+			State:           comp.State,
+			NewState:        newState,
+			ValidNextStates: policy.nextStates(hmsType, comp.State, source, force),
+		}
+		serr.Rejected = append(serr.Rejected, rej)
+		return fmt.Errorf("%s: %s to %s, valid next states: %s",
+			ErrSMDStateTransition, rej.State, rej.NewState,
+			strings.Join(rej.ValidNextStates, ","))
+	}
+	return check, serr
+}
+
+// Send the components a state change was rejected for as an RFC7807
+// problem with the list of rejections added.
+func sendJsonCompStateError(w http.ResponseWriter, serr *compStateError) {
+	p := struct {
+		base.ProblemDetails
+		Rejected []*sm.CompStateRejection `json:"Rejected"`
+	}{
+		ProblemDetails: base.ProblemDetails{
+			Type:   "about:blank",
+			Title:  http.StatusText(http.StatusConflict),
+			Detail: ErrSMDStateTransition.Error(),
+			Status: http.StatusConflict,
+		},
+		Rejected: serr.Rejected,
+	}
+	w.Header().Set("Content-Type", base.ProblemDetailContentType)
+	w.WriteHeader(http.StatusConflict)
+	if err := json.NewEncoder(w).Encode(p); err != nil {
+		fmt.Printf("Couldn't encode a JSON problem response: %s\n", err)
+	}
+}
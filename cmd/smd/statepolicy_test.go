@@ -0,0 +1,258 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	base "github.com/Cray-HPE/hms-base/v2"
+	"github.com/Cray-HPE/hms-smd/v2/pkg/sm"
+)
+
+const testStatePolicy = `{"Rules": [
+	{"Name": "HeartbeatReady", "Types": ["node"],
+	 "From": ["on", "standby", "halt", "ready"], "To": ["ready"],
+	 "Sources": ["cray-hbtd"]},
+	{"Name": "Power", "Types": ["Node"],
+	 "To": ["Off", "On", "Standby", "Halt"],
+	 "Sources": ["RedfishEvent", "RedfishPoll", "cray-hbtd"]},
+	{"Types": ["Node"], "Force": true}]}`
+
+func TestCompStatePolicy(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "policy.json")
+	ioutil.WriteFile(path, []byte(testStatePolicy), 0644)
+	p, err := LoadCompStatePolicyFile(path)
+	if err != nil {
+		t.Fatalf("FAIL: Unexpected error: %s", err)
+	}
+	if p.Rules[0].Types[0] != "Node" || p.Rules[0].From[0] != "On" ||
+		p.Rules[0].To[0] != "Ready" || p.Rules[2].Name != "Rule2" {
+		t.Errorf("FAIL: Rules not normalized: %+v %+v", p.Rules[0], p.Rules[2])
+	}
+
+	allows := []struct {
+		hmsType  string
+		state    string
+		newState string
+		source   string
+		force    bool
+		expected bool
+	}{
+		{"Node", "On", "Ready", "cray-hbtd", false, true},
+		{"Node", "On", "Ready", "CRAY-HBTD", false, true},
+		{"Node", "On", "Ready", "cray-bos", false, false},
+		{"Node", "Off", "Ready", "cray-hbtd", false, false},
+		{"Node", "Off", "Ready", "cray-bos", true, true},
+		{"Node", "Ready", "Ready", "cray-bos", false, true},
+		{"Node", "Ready", "Off", "RedfishEvent:x0c0s0b0", false, true},
+		{"Node", "Ready", "Off", "RedfishEvents", false, false},
+		{"Node", "Ready", "Empty", "cray-hbtd", false, false},
+		{"NodeBMC", "Off", "Ready", "cray-bos", false, true},
+	}
+	for i, test := range allows {
+		ok := p.allows(test.hmsType, test.state, test.newState, test.source,
+			test.force)
+		if ok != test.expected {
+			t.Errorf("Test %d FAIL: %s %s->%s by %s: Expected %v; Received %v",
+				i, test.hmsType, test.state, test.newState, test.source,
+				test.expected, ok)
+		}
+	}
+
+	next := []struct {
+		state    string
+		source   string
+		force    bool
+		expected []string
+	}{
+		{"On", "cray-hbtd", false, []string{"Off", "Ready"}},
+		{"Off", "cray-hbtd", false, []string{"On"}},
+		{"Ready", "cray-bos", false, []string{}},
+		{"On", "cray-bos", true, []string{"Unknown", "Empty", "Populated",
+			"Off", "Standby", "Halt", "Ready"}},
+	}
+	for i, test := range next {
+		states := p.nextStates("Node", test.state, test.source, test.force)
+		if !reflect.DeepEqual(test.expected, states) {
+			t.Errorf("Test %d FAIL: %s by %s: Expected %v; Received %v",
+				i, test.state, test.source, test.expected, states)
+		}
+	}
+
+	files := []string{
+		`{"Rules": [{"Types": ["Bogus"]}]}`,
+		`{"Rules": [{"From": ["Bogus"]}]}`,
+		`{"Rules": [{"To": ["Bogus"]}]}`,
+		`{"Rules": [null]}`,
+		`{"Rules": [`,
+	}
+	for i, policy := range files {
+		ioutil.WriteFile(path, []byte(policy), 0644)
+		if _, err := LoadCompStatePolicyFile(path); err == nil {
+			t.Errorf("Test %d FAIL: Expected an error", i)
+		}
+	}
+	if _, err := LoadCompStatePolicyFile(filepath.Join(dir, "missing")); err == nil {
+		t.Errorf("FAIL: Expected an error for a missing file")
+	}
+}
+
+func TestDoCompBulkStateDataPatchPolicy(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.json")
+	ioutil.WriteFile(path, []byte(testStatePolicy), 0644)
+	policy, err := LoadCompStatePolicyFile(path)
+	if err != nil {
+		t.Fatalf("FAIL: Unexpected error: %s", err)
+	}
+	s.statePolicy = policy
+	defer func() { s.statePolicy = nil }()
+
+	tests := []struct {
+		reqBody      []byte
+		comps        []*base.Component
+		expectedIds  []string
+		expectedCode int
+		expectedResp []byte
+	}{{
+		reqBody: json.RawMessage(`{"ComponentIDs":["x0c0s27b0n0","x0c0s25b0n0","x0c0s25b0"],"State":"Ready"}`),
+		comps: []*base.Component{
+			{ID: "x0c0s27b0n0", State: "Off"},
+			{ID: "x0c0s25b0n0", State: "On"},
+			{ID: "x0c0s25b0", State: "Off"},
+		},
+		expectedIds:  []string{"x0c0s27b0n0", "x0c0s25b0n0", "x0c0s25b0"},
+		expectedCode: http.StatusConflict,
+		expectedResp: json.RawMessage(`{"type":"about:blank","title":"Conflict","detail":"state transition not allowed by policy","status":409,"Rejected":[{"ID":"x0c0s27b0n0","State":"Off","NewState":"Ready","ValidNextStates":["On"]}]}` + "\n"),
+	}, {
+		reqBody: json.RawMessage(`{"ComponentIDs":["x0c0s27b0n0","x0c0s25b0n0"],"State":"Ready"}`),
+		comps: []*base.Component{
+			{ID: "x0c0s27b0n0", State: "Standby"},
+			{ID: "x0c0s25b0n0", State: "On"},
+		},
+		expectedIds:  []string{"x0c0s27b0n0", "x0c0s25b0n0"},
+		expectedCode: http.StatusNoContent,
+		expectedResp: []byte{},
+	}}
+	defer func() { results.UpdateCompStates.Locked = nil }()
+
+	for i, test := range tests {
+		results.UpdateCompStates.Locked = test.comps
+		results.UpdateCompStates.Input.ids = nil
+		results.UpdateCompStates.Input.check = nil
+		results.UpdateCompStates.Return.affectedIds = nil
+		results.UpdateCompStates.Return.err = nil
+		req, err := http.NewRequest("PATCH",
+			"https://localhost/hsm/v2/State/Components/BulkStateData",
+			bytes.NewBuffer(test.reqBody))
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected while creating request", err)
+		}
+		req.Header.Set("HMS-Service", "cray-hbtd")
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+		if w.Code != test.expectedCode {
+			t.Errorf("Test %v Failed: Response code was %v; want %v", i, w.Code, test.expectedCode)
+		}
+		if !reflect.DeepEqual(test.expectedIds, results.UpdateCompStates.Input.ids) {
+			t.Errorf("Test %v Failed: Expected update of '%v'; Received '%v'", i, test.expectedIds, results.UpdateCompStates.Input.ids)
+		}
+		// The policy is checked by the update against the locked
+		// components.
+		if results.UpdateCompStates.Input.check == nil {
+			t.Errorf("Test %v Failed: Expected a state check", i)
+		}
+		if bytes.Compare(test.expectedResp, w.Body.Bytes()) != 0 {
+			t.Errorf("Test %v Failed: Expected body is '%v'; Received '%v'", i, string(test.expectedResp), w.Body)
+		}
+	}
+}
+
+func TestDoCompsPatchPolicy(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.json")
+	ioutil.WriteFile(path, []byte(testStatePolicy), 0644)
+	policy, err := LoadCompStatePolicyFile(path)
+	if err != nil {
+		t.Fatalf("FAIL: Unexpected error: %s", err)
+	}
+	s.statePolicy = policy
+	defer func() { s.statePolicy = nil }()
+
+	results.PatchComponents.Input.patches = nil
+	results.PatchComponents.Input.check = nil
+	results.PatchComponents.Locked = []*base.Component{
+		{ID: "x0c0s27b0n0", State: "Off"},
+		{ID: "x0c0s25b0n0", State: "On"},
+	}
+	defer func() { results.PatchComponents.Locked = nil }()
+	results.PatchComponents.Return.changedIds = []string{"x0c0s25b0n0"}
+	results.PatchComponents.Return.failures = nil
+	results.PatchComponents.Return.err = nil
+	req, err := http.NewRequest("PATCH", "https://localhost/hsm/v2/State/Components",
+		bytes.NewBufferString(`{"Components":[{"ID":"x0c0s27b0n0","State":"Ready"},{"ID":"x0c0s25b0n0","State":"Ready"}]}`))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected while creating request", err)
+	}
+	req.Header.Set("HMS-Service", "cray-hbtd")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("Test Failed: Response code was %v; want %v", w.Code, http.StatusOK)
+	}
+	// The policy is checked by the patch against the locked components, so
+	// every patch is passed on.
+	if len(results.PatchComponents.Input.patches) != 2 {
+		t.Errorf("Test Failed: Expected 2 patches; Received '%v'", results.PatchComponents.Input.patches)
+	}
+	if results.PatchComponents.Input.check == nil {
+		t.Errorf("Test Failed: Expected a state check")
+	}
+	expectedResp := []byte(`{"Counts":{"Total":2,"Success":1,"Failure":1},"Success":{"ComponentIDs":["x0c0s25b0n0"]},"Failure":[{"ID":"x0c0s27b0n0","Reason":"state transition not allowed by policy: Off to Ready, valid next states: On","State":"Off","NewState":"Ready","ValidNextStates":["On"]}]}` + "\n")
+	if bytes.Compare(expectedResp, w.Body.Bytes()) != 0 {
+		t.Errorf("Test Failed: Expected body is '%v'; Received '%v'", string(expectedResp), w.Body)
+	}
+	// The rejection comes back as structured fields, not just the reason.
+	var result sm.CompPatchResult
+	if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+		t.Fatalf("Test Failed: Couldn't decode response: %s", err)
+	}
+	expectedFailures := []sm.CompPatchFailure{{
+		ID:              "x0c0s27b0n0",
+		Reason:          "state transition not allowed by policy: Off to Ready, valid next states: On",
+		State:           "Off",
+		NewState:        "Ready",
+		ValidNextStates: []string{"On"},
+	}}
+	if !reflect.DeepEqual(expectedFailures, result.Failure) {
+		t.Errorf("Test Failed: Expected failures '%+v'; Received '%+v'", expectedFailures, result.Failure)
+	}
+}
@@ -26,6 +26,7 @@ import (
 	"strings"
 
 	base "github.com/Cray-HPE/hms-base/v2"
+	"github.com/Cray-HPE/hms-smd/v2/pkg/sm"
 	"github.com/Cray-HPE/hms-xname/xnametypes"
 )
 
@@ -69,6 +70,7 @@ type ComponentFilter struct {
 	Partition []string `json:"partition"`
 	Locked    []string `json:"locked"`
 	ReservationDisabled []string `json:"reservation_disabled"`
+	Labels    []string `json:"labels"` // Label selectors, all must match
 
 	// private options
 	writeLock bool   // default is false
@@ -92,6 +94,13 @@ type ComponentFilter struct {
 
 	flagCondition *PCondition
 
+	// Parsed requirements of all of the Labels selectors.
+	labelReqs []sm.LabelRequirement
+
+	// Expansions of any nested or dynamic groups among Group, keyed by
+	// label.  Filled in from the database before the query is built.
+	groupsResolved map[string]*resolvedGroup
+
 	// Has VerifyAndNormalize been run?
 	verified bool
 }
@@ -138,10 +147,12 @@ type JobSyncFilter struct {
 	ID     []string `json:"id"`
 	Type   []string `json:"type"`
 	Status []string `json:"status"`
+	CompID []string `json:"comp_id"`
 
 	// private options
-	isExpired bool
-	label     string // Labels query for logging, etc.
+	isExpired    bool
+	isNotExpired bool
+	label        string // Labels query for logging, etc.
 }
 
 type HWInvLocFilter struct {
@@ -172,6 +183,74 @@ type HWInvHistFilter struct {
 	label string // Labels query for logging, etc.
 }
 
+type HWInvFirmwareFilter struct {
+	// User-writable options
+	ID           []string `json:"id"`
+	RfEndpointID []string `json:"redfish_ep"`
+	Inventory    []string `json:"inventory"`
+	RelatedID    []string `json:"device"`
+	Version      []string `json:"version"`
+	Updateable   []string `json:"updateable"`
+
+	// private options
+	label string // Labels query for logging, etc.
+}
+
+type RFEventLogFilter struct {
+	// User-writable options
+	ID        []string `json:"xname"`
+	Severity  []string `json:"severity"`
+	Registry  []string `json:"registry"`
+	MessageId []string `json:"messageid"`
+	FruId     []string `json:"fruid"`
+	Since     string   `json:"since"`
+	Until     string   `json:"until"`
+
+	// Paging - entries with IDs below Marker (if non-zero), at most Limit
+	// (if non-zero) of them.
+	Marker int64
+	Limit  int
+
+	// private options
+	label string // Labels query for logging, etc.
+}
+
+type GroupHistFilter struct {
+	// User-writable options
+	Member    []string `json:"member"`
+	Op        []string `json:"op"`
+	Requester []string `json:"requester"`
+	Since     string   `json:"since"`
+	Until     string   `json:"until"`
+
+	// private options
+	label string // Labels query for logging, etc.
+}
+
+type CompHistFilter struct {
+	// User-writable options
+	Field  []string `json:"field"`
+	Source []string `json:"source"`
+	Since  string   `json:"since"`
+	Until  string   `json:"until"`
+
+	// private options
+	label string // Labels query for logging, etc.
+}
+
+type HWInvFanFilter struct {
+	// User-writable options
+	ID           []string `json:"id"`
+	ParentID     []string `json:"parent"`
+	RfEndpointID []string `json:"redfish_ep"`
+	Status       []string `json:"status"`
+	Health       []string `json:"health"`
+	FruId        []string `json:"fruid"`
+
+	// private options
+	label string // Labels query for logging, etc.
+}
+
 type CompEthInterfaceFilter struct {
 	// User-writable options
 	ID        []string `json:"id"`
@@ -548,6 +627,14 @@ func (f *ComponentFilter) VerifyNormalize() error {
 	if err != nil {
 		return ErrHMSDSNoPartition
 	}
+	f.labelReqs = nil
+	for _, sel := range f.Labels {
+		reqs, err := sm.ParseLabelSelector(sel)
+		if err != nil {
+			return ErrHMSDSArgBadLabel
+		}
+		f.labelReqs = append(f.labelReqs, reqs...)
+	}
 	return nil
 }
 
@@ -949,6 +1036,20 @@ func JS_Status(status string) JobSyncFiltFunc {
 	}
 }
 
+// Filter includes just jobs for these components.  Only job types with a
+// component, e.g. StateRFPoll, will match.
+func JS_CompIDs(ids []string) JobSyncFiltFunc {
+	return func(f *JobSyncFilter) {
+		if f != nil {
+			if len(ids) == 0 {
+				f.CompID = []string{}
+			} else {
+				f.CompID = ids
+			}
+		}
+	}
+}
+
 // Filter for expired jobs.
 func JS_Expired(f *JobSyncFilter) {
 	if f != nil {
@@ -956,6 +1057,13 @@ func JS_Expired(f *JobSyncFilter) {
 	}
 }
 
+// Filter for jobs that have not expired.
+func JS_NotExpired(f *JobSyncFilter) {
+	if f != nil {
+		f.isNotExpired = true
+	}
+}
+
 // Set label field so any errors during the query can be attributed
 // to the calling func
 func JS_From(callingFunc string) JobSyncFiltFunc {
@@ -1204,6 +1312,162 @@ func HWInvHist_From(callingFunc string) HWInvHistFiltFunc {
 	}
 }
 
+////////////////////////////////////////////////////////////////////////////
+//  HWInvFirmware Filter options
+////////////////////////////////////////////////////////////////////////////
+
+// Filter functions: must take a pointer to a HWInvFirmwareFilter presumed to
+// be already initialized and modify the filter accordingly.
+type HWInvFirmwareFiltFunc func(*HWInvFirmwareFilter)
+
+// Filter includes just these Redfish target ids.  Overwrites previous
+// call.
+func FW_IDs(ids []string) HWInvFirmwareFiltFunc {
+	return func(f *HWInvFirmwareFilter) {
+		if f != nil {
+			f.ID = ids
+		}
+	}
+}
+
+// Filter includes just the targets reported by these RedfishEndpoints.
+// Overwrites previous call.
+func FW_RfEPs(rfEndpointIDs []string) HWInvFirmwareFiltFunc {
+	return func(f *HWInvFirmwareFilter) {
+		if f != nil {
+			f.RfEndpointID = rfEndpointIDs
+		}
+	}
+}
+
+// Filter includes just targets from these inventories, i.e.
+// FirmwareInventory and/or SoftwareInventory.  Overwrites previous call.
+func FW_Inventories(inventories []string) HWInvFirmwareFiltFunc {
+	return func(f *HWInvFirmwareFilter) {
+		if f != nil {
+			f.Inventory = inventories
+		}
+	}
+}
+
+// Filter includes just the targets that apply to these component xnames.
+// Overwrites previous call.
+func FW_RelatedIDs(ids []string) HWInvFirmwareFiltFunc {
+	return func(f *HWInvFirmwareFilter) {
+		if f != nil {
+			f.RelatedID = ids
+		}
+	}
+}
+
+// Filter includes just the targets at these versions.  Versions can be
+// negated with "!" and all such versions will be excluded.  Overwrites
+// previous call.
+func FW_Versions(versions []string) HWInvFirmwareFiltFunc {
+	return func(f *HWInvFirmwareFilter) {
+		if f != nil {
+			f.Version = versions
+		}
+	}
+}
+
+// Filter on updateable=true/false (in string form).  Replaces earlier call.
+func FW_Updateable(updateable string) HWInvFirmwareFiltFunc {
+	return func(f *HWInvFirmwareFilter) {
+		if f != nil {
+			f.Updateable = []string{updateable}
+		}
+	}
+}
+
+// Set label field so any errors during the query can be attributed
+// to the calling func
+func FW_From(callingFunc string) HWInvFirmwareFiltFunc {
+	return func(f *HWInvFirmwareFilter) {
+		if f != nil {
+			f.label = callingFunc
+		}
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////
+//  HWInvFan Filter options
+////////////////////////////////////////////////////////////////////////////
+
+// Filter functions: must take a pointer to a HWInvFanFilter presumed to
+// be already initialized and modify the filter accordingly.
+type HWInvFanFiltFunc func(*HWInvFanFilter)
+
+// Filter includes just these Redfish fan ids.  Overwrites previous call.
+func Fan_IDs(ids []string) HWInvFanFiltFunc {
+	return func(f *HWInvFanFilter) {
+		if f != nil {
+			f.ID = ids
+		}
+	}
+}
+
+// Filter includes just the fans installed in these component xnames.
+// Overwrites previous call.
+func Fan_ParentIDs(ids []string) HWInvFanFiltFunc {
+	return func(f *HWInvFanFilter) {
+		if f != nil {
+			f.ParentID = ids
+		}
+	}
+}
+
+// Filter includes just the fans reported by these RedfishEndpoints.
+// Overwrites previous call.
+func Fan_RfEPs(rfEndpointIDs []string) HWInvFanFiltFunc {
+	return func(f *HWInvFanFilter) {
+		if f != nil {
+			f.RfEndpointID = rfEndpointIDs
+		}
+	}
+}
+
+// Filter includes just fans with these statuses, i.e. Populated and/or
+// Empty.  Overwrites previous call.
+func Fan_Statuses(statuses []string) HWInvFanFiltFunc {
+	return func(f *HWInvFanFilter) {
+		if f != nil {
+			f.Status = statuses
+		}
+	}
+}
+
+// Filter includes just fans with this Redfish health, e.g. OK, Warning or
+// Critical.  Health values can be negated with "!" and all such fans will
+// be excluded.  Overwrites previous call.
+func Fan_Healths(healths []string) HWInvFanFiltFunc {
+	return func(f *HWInvFanFilter) {
+		if f != nil {
+			f.Health = healths
+		}
+	}
+}
+
+// Filter includes just the fans with these FRU IDs.  Overwrites previous
+// call.
+func Fan_FRUIDs(fruIds []string) HWInvFanFiltFunc {
+	return func(f *HWInvFanFilter) {
+		if f != nil {
+			f.FruId = fruIds
+		}
+	}
+}
+
+// Set label field so any errors during the query can be attributed
+// to the calling func
+func Fan_From(callingFunc string) HWInvFanFiltFunc {
+	return func(f *HWInvFanFilter) {
+		if f != nil {
+			f.label = callingFunc
+		}
+	}
+}
+
 ////////////////////////////////////////////////////////////////////////////
 //  CompEthInterface Filter options
 ////////////////////////////////////////////////////////////////////////////
@@ -1332,3 +1596,221 @@ func CEI_From(callingFunc string) CompEthInterfaceFiltFunc {
 		}
 	}
 }
+
+////////////////////////////////////////////////////////////////////////////
+//  RFEventLog Filter options
+////////////////////////////////////////////////////////////////////////////
+
+// Filter functions: must take a pointer to a RFEventLogFilter presumed to
+// be already initialized and modify the filter accordingly.
+type RFEventLogFiltFunc func(*RFEventLogFilter)
+
+// Filter includes just events about, or sent by, these xnames.  Overwrites
+// previous call.
+func EL_IDs(ids []string) RFEventLogFiltFunc {
+	return func(f *RFEventLogFilter) {
+		if f != nil {
+			f.ID = ids
+		}
+	}
+}
+
+// Filter includes just events with these severities, compared without
+// case.  Overwrites previous call.
+func EL_Severities(severities []string) RFEventLogFiltFunc {
+	return func(f *RFEventLogFilter) {
+		if f != nil {
+			f.Severity = severities
+		}
+	}
+}
+
+// Filter includes just events from these message registries, compared
+// without case.  Overwrites previous call.
+func EL_Registries(registries []string) RFEventLogFiltFunc {
+	return func(f *RFEventLogFilter) {
+		if f != nil {
+			f.Registry = registries
+		}
+	}
+}
+
+// Filter includes just events with these MessageIds, compared without
+// case.  Overwrites previous call.
+func EL_MessageIds(msgIds []string) RFEventLogFiltFunc {
+	return func(f *RFEventLogFilter) {
+		if f != nil {
+			f.MessageId = msgIds
+		}
+	}
+}
+
+// Filter includes just FRU events recorded for these FRU IDs.  Overwrites
+// previous call.
+func EL_FruIDs(fruIds []string) RFEventLogFiltFunc {
+	return func(f *RFEventLogFilter) {
+		if f != nil {
+			f.FruId = fruIds
+		}
+	}
+}
+
+// Filter includes just events received at or after this RFC3339 time.
+func EL_Since(since string) RFEventLogFiltFunc {
+	return func(f *RFEventLogFilter) {
+		if f != nil {
+			f.Since = since
+		}
+	}
+}
+
+// Filter includes just events received before this RFC3339 time.
+func EL_Until(until string) RFEventLogFiltFunc {
+	return func(f *RFEventLogFilter) {
+		if f != nil {
+			f.Until = until
+		}
+	}
+}
+
+// Return the page of at most limit events (0 for no limit) that are older
+// than the event with ID marker (0 for the most recent).
+func EL_Page(marker int64, limit int) RFEventLogFiltFunc {
+	return func(f *RFEventLogFilter) {
+		if f != nil {
+			f.Marker = marker
+			f.Limit = limit
+		}
+	}
+}
+
+// Set label field so any errors during the query can be attributed
+// to the calling func
+func EL_From(callingFunc string) RFEventLogFiltFunc {
+	return func(f *RFEventLogFilter) {
+		if f != nil {
+			f.label = callingFunc
+		}
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////
+//  Component history Filter options
+////////////////////////////////////////////////////////////////////////////
+
+// Filter functions: must take a pointer to a CompHistFilter presumed to
+// be already initialized and modify the filter accordingly.
+type CompHistFiltFunc func(*CompHistFilter)
+
+// Filter includes just changes to these fields, e.g. State.  Overwrites
+// previous call.
+func CH_Fields(fields []string) CompHistFiltFunc {
+	return func(f *CompHistFilter) {
+		if f != nil {
+			f.Field = fields
+		}
+	}
+}
+
+// Filter includes just changes made by these sources.  Overwrites previous
+// call.
+func CH_Sources(sources []string) CompHistFiltFunc {
+	return func(f *CompHistFilter) {
+		if f != nil {
+			f.Source = sources
+		}
+	}
+}
+
+// Filter includes just changes made at or after this RFC3339 time.
+func CH_Since(since string) CompHistFiltFunc {
+	return func(f *CompHistFilter) {
+		if f != nil {
+			f.Since = since
+		}
+	}
+}
+
+// Filter includes just changes made at or before this RFC3339 time.
+func CH_Until(until string) CompHistFiltFunc {
+	return func(f *CompHistFilter) {
+		if f != nil {
+			f.Until = until
+		}
+	}
+}
+
+// Set label field so any errors during the query can be attributed
+// to the calling func
+func CH_From(callingFunc string) CompHistFiltFunc {
+	return func(f *CompHistFilter) {
+		if f != nil {
+			f.label = callingFunc
+		}
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////
+//  Group history Filter options
+////////////////////////////////////////////////////////////////////////////
+
+// Filter functions: must take a pointer to a GroupHistFilter presumed to
+// be already initialized and modify the filter accordingly.
+type GroupHistFiltFunc func(*GroupHistFilter)
+
+// Filter includes just entries for these members.  Overwrites previous call.
+func GH_Members(members []string) GroupHistFiltFunc {
+	return func(f *GroupHistFilter) {
+		if f != nil {
+			f.Member = members
+		}
+	}
+}
+
+// Filter includes just entries with these ops (sm.GroupHistOp*).
+// Overwrites previous call.
+func GH_Ops(ops []string) GroupHistFiltFunc {
+	return func(f *GroupHistFilter) {
+		if f != nil {
+			f.Op = ops
+		}
+	}
+}
+
+// Filter includes just entries made by these requesters.  Overwrites
+// previous call.
+func GH_Requesters(requesters []string) GroupHistFiltFunc {
+	return func(f *GroupHistFilter) {
+		if f != nil {
+			f.Requester = requesters
+		}
+	}
+}
+
+// Filter includes just entries recorded at or after this RFC3339 time.
+func GH_Since(since string) GroupHistFiltFunc {
+	return func(f *GroupHistFilter) {
+		if f != nil {
+			f.Since = since
+		}
+	}
+}
+
+// Filter includes just entries recorded at or before this RFC3339 time.
+func GH_Until(until string) GroupHistFiltFunc {
+	return func(f *GroupHistFilter) {
+		if f != nil {
+			f.Until = until
+		}
+	}
+}
+
+// Set label field so any errors during the query can be attributed
+// to the calling func
+func GH_From(callingFunc string) GroupHistFiltFunc {
+	return func(f *GroupHistFilter) {
+		if f != nil {
+			f.label = callingFunc
+		}
+	}
+}
@@ -23,6 +23,8 @@
 package hmsds
 
 import (
+	"time"
+
 	base "github.com/Cray-HPE/hms-base/v2"
 	"github.com/Cray-HPE/hms-smd/v2/pkg/sm"
 )
@@ -56,6 +58,7 @@ var ErrHMSDSArgBadRedfishType = e.NewChild("Argument was not a valid Redfish typ
 var ErrHMSDSArgBadJobType = e.NewChild("Argument was not a valid job Type")
 var ErrHMSDSArgBadHWInvHistEventType = e.NewChild("Argument was not a HWInvHist event Type")
 var ErrHMSDSArgBadTimeFormat = e.NewChild("Argument was not in a valid RFC3339 time format")
+var ErrHMSDSArgBadLabel = e.NewChild("Argument was not a valid label selector")
 
 var ErrHMSDSDuplicateKey = e.NewChild("Would create a duplicate key or non-unique field")
 var ErrHMSDSNoComponent = e.NewChild("linked component does not exist")
@@ -65,6 +68,17 @@ var ErrHMSDSNoGroup = e.NewChild("no such group")
 var ErrHMSDSNoPartition = e.NewChild("no such partition")
 var ErrHMSDSExclusiveGroup = e.NewChild("Would create a duplicate key in another exclusive group")
 var ErrHMSDSExclusivePartition = e.NewChild("Would create a duplicate key in another partition")
+var ErrHMSDSDynamicGroup = e.NewChild("members of a dynamic group are set by its query")
+var ErrHMSDSStaticGroup = e.NewChild("query can only be changed on a dynamic group")
+var ErrHMSDSGroupCycle = e.NewChild("group includes would form a cycle")
+var ErrHMSDSNoIncludedGroup = e.NewChild("one or more included groups do not exist")
+var ErrHMSDSGroupIncludes = e.NewChild("exclusive and dynamic groups cannot include other groups")
+var ErrHMSDSVersionMismatch = e.NewChild("group or partition has been changed since the given version")
+var ErrHMSDSNoParentPartition = e.NewChild("parent partition does not exist")
+var ErrHMSDSNotInParentPartition = e.NewChild("sub-partition members must be members of the parent partition")
+var ErrHMSDSPartitionHasChildren = e.NewChild("partition still has sub-partitions")
+var ErrHMSDSNotSiblingPartitions = e.NewChild("partitions are not sibling sub-partitions")
+var ErrHMSDSNotInSourcePartition = e.NewChild("member(s) not in the source partition")
 
 var ErrHMSDSMultipleGroupAndPart = e.NewChild("group and partition cannot both have more than one value")
 var ErrHMSDSNullGroupBadPart = e.NewChild("NULL group and non-NULL partition arg not permitted")
@@ -124,6 +138,11 @@ type PartInfo struct {
 	Partition []string `json:"Partition"`
 }
 
+// Called with each component a state change applies to, after its row has
+// been locked, so the caller can check the change against its current state.
+// A non-nil error rejects the change and the whole update is rolled back.
+type CompStateCheck func(comp *base.Component, newState string) error
+
 type HMSDB interface {
 
 	// Return implementation name as a string
@@ -198,6 +217,11 @@ type HMSDB interface {
 	// of the non-empty strings in the filter struct.
 	GetComponentsQuery(f *ComponentFilter, fieldfltr FieldFilter, ids []string) ([]*base.Component, error)
 
+	// Get the HMS Components selected by a group expression, e.g.
+	// "(a | b) & !c" over group labels and partition names.  Returns
+	// ErrHMSDSNoGroup if the expression names a group that doesn't exist.
+	GetComponentsGroupExpr(e *sm.GroupExpr, fieldfltr FieldFilter) ([]*base.Component, error)
+
 	// Get a single component by its NID, if the NID exists.
 	GetComponentByNID(nid string) (*base.Component, error)
 
@@ -209,11 +233,14 @@ type HMSDB interface {
 	// single all-or-none transaction.
 	InsertComponents(comps *base.ComponentArray) ([]string, error)
 
+	// The methods below that take a source record it with the changes they
+	// make in the component history, e.g. the service that requested them.
+
 	// Inserts or updates ComponentArray entries in database within a single
 	// all-or-none transaction. If force=true, only the state, flag, subtype,
 	// nettype, and arch will be overwritten for existing components. Otherwise,
 	// this won't overwrite existing components.
-	UpsertComponents(comps []*base.Component, force bool) (map[string]map[string]bool, error)
+	UpsertComponents(comps []*base.Component, force bool, source string) (map[string]map[string]bool, error)
 
 	// Update state and flag fields only in DB for the given IDs.  If
 	// len(ids) is > 1 a locking read will be done to ensure the list o
@@ -222,40 +249,57 @@ type HMSDB interface {
 	// If force = true ignores any starting state restrictions and will
 	// always set ids to 'state', unless it is already set.
 	//   Note: If flag is not set, it will be set to OK (i.e. no flag)
-	UpdateCompStates(ids []string, state string, flag string, force bool, pi *PartInfo) ([]string, error)
+	//
+	// If check is not nil, it is called with every component in ids that
+	// exists, while locked, before anything is updated.  If it returns an
+	// error for any of them, nothing is updated and the first error is
+	// returned.
+	UpdateCompStates(ids []string, state string, flag string, force bool, pi *PartInfo, source string, check CompStateCheck) ([]string, error)
 
 	// Update Flag field in DB from c's Flag field.
 	// Returns the number of affected rows. < 0 means RowsAffected() is not supported.
 	// Note: Flag cannot be empty/invalid.
-	UpdateCompFlagOnly(id string, flag string) (int64, error)
+	UpdateCompFlagOnly(id string, flag string, source string) (int64, error)
 
 	// Update flag field in DB for a list of components
 	// Note: Flag cannot be empty/invalid.
-	BulkUpdateCompFlagOnly(ids []string, flag string) ([]string, error)
+	BulkUpdateCompFlagOnly(ids []string, flag string, source string) ([]string, error)
 
 	// Update enabled field in DB from c's Enabled field.
 	// Returns the number of affected rows. < 0 means RowsAffected() is not supported.
 	// Note: c.Enabled cannot be nil.
-	UpdateCompEnabled(id string, enabled bool) (int64, error)
+	UpdateCompEnabled(id string, enabled bool, source string) (int64, error)
 
 	// Update Enabled field only in DB for a list of components
-	BulkUpdateCompEnabled(ids []string, enabled bool) ([]string, error)
+	BulkUpdateCompEnabled(ids []string, enabled bool, source string) ([]string, error)
 
 	// Update SwStatus field in DB from c's SwStatus field.
 	// Returns the number of affected rows. < 0 means RowsAffected() is not supported.
-	UpdateCompSwStatus(id string, swStatus string) (int64, error)
+	UpdateCompSwStatus(id string, swStatus string, source string) (int64, error)
 
 	// Update SwStatus field only in DB for a list of components
-	BulkUpdateCompSwStatus(ids []string, swstatus string) ([]string, error)
+	BulkUpdateCompSwStatus(ids []string, swstatus string, source string) ([]string, error)
 
 	// Update Role/SubRole field in DB from c's Role/SubRole field.
 	// Returns the number of affected rows. < 0 means RowsAffected() is not supported.
 	// Note: Role cannot be blank/invalid.
-	UpdateCompRole(id string, role, subRole string) (int64, error)
+	UpdateCompRole(id string, role, subRole string, source string) (int64, error)
 
 	// Update Role/SubRole field in DB for a list of components
 	// Note: Role cannot be blank/invalid.
-	BulkUpdateCompRole(ids []string, role, subRole string) ([]string, error)
+	BulkUpdateCompRole(ids []string, role, subRole string, source string) ([]string, error)
+
+	// Apply each of the given multi-field changes to its component, all in a
+	// single transaction.  Each component either gets all of its changes, or,
+	// if it doesn't exist or can't be moved to the new state from its current
+	// one (unless force = true), none of them and is returned as a failure.
+	// The patches should already be verified and normalized.  Returns the ids
+	// of the components that were actually changed.
+	//
+	// If check is not nil, it is called with each locked component that is
+	// given a new state.  If it returns an error, the component fails with
+	// the error as the reason.
+	PatchComponents(patches []*sm.CompPatch, force bool, source string, check CompStateCheck) ([]string, []sm.CompPatchFailure, error)
 
 	// Update Class field only in DB for a list of components
 	BulkUpdateCompClass(ids []string, class string) ([]string, error)
@@ -277,6 +321,17 @@ type HMSDB interface {
 	// Also returns number of deleted rows, if error is nil.
 	DeleteComponentsAll() (int64, error)
 
+	// Get the recorded state, flag, enabled, role and software status
+	// changes to the component with the given xname id, oldest first,
+	// matching the filter options.  History is kept after a component is
+	// deleted.
+	GetCompHistory(id string, f_opts ...CompHistFiltFunc) ([]*sm.CompHistoryEntry, error)
+
+	// Delete component history entries recorded before the given time, and
+	// the oldest ones beyond maxEntries (if maxEntries > 0).  Returns the
+	// number deleted.
+	PruneCompHistory(before time.Time, maxEntries int) (int64, error)
+
 	//                                                                    //
 	//              Node to Default NID, role, etc. mapping               //
 	//                                                                    //
@@ -390,6 +445,12 @@ type HMSDB interface {
 	// transaction.
 	InsertHWInvByLocs(hls []*sm.HWInvByLoc) error
 
+	// Replace the locations at and under xname id with hls, e.g. after
+	// re-reading that part of the system.  Locations not in hls are
+	// deleted, detaching their FRUs.  Added and Removed history events are
+	// stored for each FRU that arrived at or left a location and returned.
+	UpdateHWInvByLocSubtree(id string, hls []*sm.HWInvByLoc) ([]*sm.HWInvHist, error)
+
 	// Delete HWInvByLoc entry with matching xname id from database, if it
 	// exists.
 	// Return true if there was a row affected, false if there were zero.
@@ -450,6 +511,51 @@ type HMSDB interface {
 	// Returns the number of deleted rows, if error is nil.
 	DeleteHWInvHistFilter(f_opts ...HWInvHistFiltFunc) (int64, error)
 
+	//                                                                    //
+	//   HWInvFirmware - Firmware versions from the Redfish UpdateService //
+	//                                                                    //
+
+	// Get firmware/software inventory entries with filtering options to
+	// possibly narrow the returned values.  If no filter provided, just get
+	// everything.
+	GetHWInvFirmwareFilter(f_opts ...HWInvFirmwareFiltFunc) ([]*sm.HWInvFirmware, error)
+
+	// Replace the firmware/software inventory entries for a RedfishEndpoint
+	// with fws.  Entries no longer reported by the endpoint are removed.
+	// Returns the entries that were already present, but whose version has
+	// changed.
+	UpdateHWInvFirmwareForRFEndpoint(rfEPID string, fws []*sm.HWInvFirmware) ([]*sm.HWInvFirmware, error)
+
+	//                                                                    //
+	//       HWInvFan - Fans from Redfish Thermal/ThermalSubsystem        //
+	//                                                                    //
+
+	// Get fan inventory entries with filtering options to possibly narrow
+	// the returned values.  If no filter provided, just get everything.
+	GetHWInvFanFilter(f_opts ...HWInvFanFiltFunc) ([]*sm.HWInvFan, error)
+
+	// Replace the fan inventory entries for a RedfishEndpoint with fans.
+	// Entries no longer reported by the endpoint are removed.  Returns the
+	// entries that were already present, but whose FRUID has changed.
+	UpdateHWInvFansForRFEndpoint(rfEPID string, fans []*sm.HWInvFan) ([]*sm.HWInvFan, error)
+
+	//                                                                    //
+	//    Component Health - Sources of non-OK component flags            //
+	//                                                                    //
+
+	// Get the health contributors recorded for the given component.
+	GetCompHealthContributors(id string) ([]*sm.CompHealthContributor, error)
+
+	// Replace the health contributors of each component in contribs, keyed
+	// by component xname, with the given list.  An empty list removes all
+	// contributors for the component.
+	SetCompHealthContributors(contribs map[string][]*sm.CompHealthContributor) error
+
+	// Record the health of a single contributor for hc.CompID.  If its
+	// Flag is OK, it is removed instead.  Returns the resulting set of
+	// contributors for the component.
+	UpdateCompHealthContributor(hc *sm.CompHealthContributor) ([]*sm.CompHealthContributor, error)
+
 	//                                                                    //
 	//    Redfish Endpoints - Redfish service roots used for discovery    //
 	//                                                                    //
@@ -761,26 +867,74 @@ type HMSDB interface {
 	// Delete all SCN subscriptions
 	DeleteSCNSubscriptionsAll() (int64, error)
 
+	//                                                                    //
+	//       Event Rules: Mapping of Redfish events to state changes      //
+	//                                                                    //
+
+	// Get all event rules stored via the API.
+	GetEventRulesAll() ([]*sm.EventRule, error)
+
+	// Get the stored event rule with the given name, nil if none.
+	GetEventRule(name string) (*sm.EventRule, error)
+
+	// Insert a new event rule.  If the name is taken, returns
+	// ErrHMSDSDuplicateKey.
+	InsertEventRule(r *sm.EventRule) error
+
+	// Replace an existing event rule.  Returns false if there is none.
+	UpdateEventRule(r *sm.EventRule) (bool, error)
+
+	// Delete the event rule with the given name.  Returns false if there
+	// is none.
+	DeleteEventRule(name string) (bool, error)
+
+	//                                                                    //
+	//       Redfish Event Log - Processed events received from BMCs      //
+	//                                                                    //
+
+	// Add entries to the Redfish event log.  IDs and receive timestamps
+	// are assigned by the database.
+	InsertRFEventLogEntries(entries []*sm.RFEventLogEntry) error
+
+	// Get Redfish event log entries, most recent first, matching the
+	// filter options.
+	GetRFEventLogFilter(f_opts ...RFEventLogFiltFunc) ([]*sm.RFEventLogEntry, error)
+
+	// Delete Redfish event log entries received before the given time, and
+	// the oldest ones beyond maxEntries (if maxEntries > 0).  Returns the
+	// number deleted.
+	PruneRFEventLog(before time.Time, maxEntries int) (int64, error)
+
 	//                                                                    //
 	//                 Group and Partition  Management                    //
 	//                                                                    //
 
+	// Calls that change a group or partition take the requester, i.e. the
+	// service or user asking for the change, to record in the group and
+	// partition history.  It may be empty if not known.
+
 	//                           Groups
 
 	// Create a group.  Returns new label (should match one in struct,
 	// unless case-normalized) if successful, otherwise empty string + non
 	// nil error. Will return ErrHMSDSDuplicateKey if group exits or is
 	// exclusive and xname id is already in another group in this exclusive set.
-	// In addition, returns ErrHMSDSNoComponent if a component doesn't exist.
-	InsertGroup(g *sm.Group) (string, error)
+	// In addition, returns ErrHMSDSNoComponent if a component doesn't exist,
+	// and ErrHMSDSNoIncludedGroup/ErrHMSDSGroupCycle for bad includes.
+	InsertGroup(g *sm.Group, requester string) (string, error)
 
-	// Update group with label
-	UpdateGroup(label string, gp *sm.GroupPatch) error
+	// Update group with label.  Replacing the includes can return the same
+	// errors as InsertGroup or ErrHMSDSGroupIncludes.  If gp.Version is
+	// non-zero and the group is no longer at that version, nothing is
+	// changed and ErrHMSDSVersionMismatch is returned.
+	UpdateGroup(label string, gp *sm.GroupPatch, requester string) error
 
 	// Get Group with given label.  Nil if not found and nil error, otherwise
 	// nil plus non-nil error (not normally expected)
 	// If filt_part is non-empty, the partition name is used to filter
-	// the members list.
+	// the members list.  Members of a dynamic group are evaluated from its
+	// query, and those of a group that includes others also contain the
+	// members of the included groups.
 	GetGroup(label, filt_part string) (*sm.Group, error)
 
 	// Get list of group labels (names).
@@ -788,7 +942,7 @@ type HMSDB interface {
 
 	// Delete entire group with the given label.  If no error, bool indicates
 	// whether member was present to remove.
-	DeleteGroup(label string) (bool, error)
+	DeleteGroup(label, requester string) (bool, error)
 
 	// Add member xname id to existing group label.  returns ErrHMSDSNoGroup
 	// if group with label does not exist, or ErrHMSDSDuplicateKey if Group
@@ -796,12 +950,21 @@ type HMSDB interface {
 	// In addition, returns ErrHMSDSNoComponent if the component doesn't exist.
 	//
 	// Returns key of new member id, should be same as id after normalization,
-	// if any.  Label should already be normalized.
-	AddGroupMember(label, id string) (string, error)
+	// if any.  Label should already be normalized.  Returns
+	// ErrHMSDSDynamicGroup if the group's members are set by a query.
+	AddGroupMember(label, id, requester string) (string, error)
 
 	// Delete Group member from label.  If no error, bool indicates whether
-	// group was present to remove.
-	DeleteGroupMember(label, id string) (bool, error)
+	// group was present to remove.  Returns ErrHMSDSDynamicGroup if the
+	// group's members are set by a query.
+	DeleteGroupMember(label, id, requester string) (bool, error)
+
+	// Replace the whole member list of group label with ms, in a single
+	// transaction.  Errors are as for AddGroupMember, in which case nothing
+	// is changed.  If version is non-zero and the group is no longer at
+	// that version, ErrHMSDSVersionMismatch is returned.  Returns the
+	// group's new version.
+	ReplaceGroupMembers(label string, ms *sm.Members, version int64, requester string) (int64, error)
 
 	//                        Partitions
 
@@ -810,10 +973,12 @@ type HMSDB interface {
 	// nil error.  Will return ErrHMSDSDuplicateKey if partition exits or an
 	// xname id already exists in another partition.
 	// In addition, returns ErrHMSDSNoComponent if a component doesn't exist.
-	InsertPartition(p *sm.Partition) (string, error)
+	InsertPartition(p *sm.Partition, requester string) (string, error)
 
-	// Update Partition with given name
-	UpdatePartition(pname string, pp *sm.PartitionPatch) error
+	// Update Partition with given name.  If pp.Version is non-zero and the
+	// partition is no longer at that version, nothing is changed and
+	// ErrHMSDSVersionMismatch is returned.
+	UpdatePartition(pname string, pp *sm.PartitionPatch, requester string) error
 
 	// Get partition with given name  Nil if not found and nil error, otherwise
 	// nil plus non-nil error (not normally expected)
@@ -824,18 +989,63 @@ type HMSDB interface {
 
 	// Delete entire partition with pname.  If no error, bool indicates
 	// whether partition was present to remove.
-	DeletePartition(pname string) (bool, error)
+	DeletePartition(pname, requester string) (bool, error)
 
 	// Add member xname id to existing partition.  returns ErrHMSDSNoGroup
 	// if partition name does not exist, or ErrHMSDSDuplicateKey if xname id
 	// is already in a different partition.
 	// Returns key of new member, should be same as id after normalization,
 	// if any.  pname should already be normalized.
-	AddPartitionMember(pname, id string) (string, error)
+	AddPartitionMember(pname, id, requester string) (string, error)
 
 	// Delete partition member from partition.  If no error, bool indicates
 	// whether member was present to remove.
-	DeletePartitionMember(pname, id string) (bool, error)
+	DeletePartitionMember(pname, id, requester string) (bool, error)
+
+	// Replace the whole member list of partition pname with ms, in a single
+	// transaction.  Errors are as for AddPartitionMember, in which case
+	// nothing is changed.  If version is non-zero and the partition is no
+	// longer at that version, ErrHMSDSVersionMismatch is returned.  Returns
+	// the partition's new version.
+	ReplacePartitionMembers(pname string, ms *sm.Members, version int64, requester string) (int64, error)
+
+	// Move the members in pm from sub-partition pm.Source to its sibling
+	// pname, e.g. from p1.2 to p1.3, in a single transaction.  Returns
+	// ErrHMSDSNotSiblingPartitions if they don't have the same parent,
+	// ErrHMSDSNoPartition if either doesn't exist, or
+	// ErrHMSDSNotInSourcePartition if any of the members aren't in the
+	// source, in which case nothing is changed.
+	MovePartitionMembers(pname string, pm *sm.PartitionMove, requester string) error
+
+	//                          History
+
+	// Get the recorded changes to the group with the given label, oldest
+	// first, matching the filter options.  History is kept after a group
+	// is deleted, and a later group with the same label continues it.
+	GetGroupHistory(label string, f_opts ...GroupHistFiltFunc) ([]*sm.GroupHistoryEntry, error)
+
+	// Get the recorded changes to the partition with the given name, oldest
+	// first, matching the filter options.
+	GetPartitionHistory(pname string, f_opts ...GroupHistFiltFunc) ([]*sm.GroupHistoryEntry, error)
+
+	//                        Component Labels
+
+	// Get the labels on the component with the given xname id.  Returns
+	// nil and a nil error if there is no such component.
+	GetCompLabels(id string) (*sm.ComponentLabels, error)
+
+	// Replace all of the labels on the component with the given xname id.
+	// Returns false if there is no such component.
+	ReplaceCompLabels(id string, labels map[string]string) (bool, error)
+
+	// Set, or for nil values remove, the given labels on each of the
+	// components with the given xname ids, in a single transaction.
+	// Returns the ids of the components that exist, i.e. were updated.
+	PatchCompLabels(ids []string, labels map[string]*string) ([]string, error)
+
+	// Remove all of the labels from the component with the given xname id.
+	// Returns false if there is no such component.
+	DeleteCompLabels(id string) (bool, error)
 
 	//                        Memberships
 
@@ -904,6 +1114,10 @@ type HMSDB interface {
 	// Update the status of the job with the given jobId.
 	UpdateJob(jobId, status string) (bool, error)
 
+	// Update the status of all of the jobs with the given jobIds at once,
+	// e.g. as a batched keep alive.  Returns the number of jobs updated.
+	UpdateJobs(jobIds []string, status string) (int64, error)
+
 	// Get the job sync entry with the given job id. Nil if not found and nil
 	// error, otherwise non-nil error (not normally expected).
 	GetJob(jobId string) (*sm.Job, error)
@@ -914,6 +1128,42 @@ type HMSDB interface {
 	// Delete the job entry with the given jobId. If no error, bool indicates
 	// whether component lock was present to remove.
 	DeleteJob(jobId string) (bool, error)
+
+	//                                                                    //
+	//            Service Instances - Cluster of HSM instances            //
+	//                                                                    //
+
+	// Record a heartbeat for the HSM instance 'id', creating its entry if
+	// needed.  'started' is when the instance started and 'leader' whether
+	// it currently holds the leader lock.
+	UpsertServiceInstance(id string, started time.Time, leader bool) error
+
+	// Get all of the HSM instances that have recorded a heartbeat.  Those
+	// with one in the last maxAge are marked Live.
+	GetServiceInstances(maxAge time.Duration) ([]*sm.ServiceInstance, error)
+
+	// Delete the HSM instances with no heartbeat in the last maxAge.
+	// Returns the number deleted.
+	DeleteServiceInstancesStale(maxAge time.Duration) (int64, error)
+
+	// Try to take the session-level advisory lock 'key', without waiting.
+	// It is held on a connection reserved for advisory locks until it is
+	// released or that connection is lost, so it should be retaken
+	// periodically; this returns true again while it is still held.
+	TryAdvisoryLock(key int64) (bool, error)
+
+	// Release the advisory lock 'key' if held.
+	ReleaseAdvisoryLock(key int64) error
+
+	//                                                                    //
+	//       Change notifications - Invalidating in-memory copies         //
+	//                                                                    //
+
+	// Start listening for the notifications the database sends when the
+	// Changed* tables are modified, by anyone.  The name of the changed
+	// table is sent on the returned channel, or ChangedAll if some may
+	// have been missed (e.g. after reconnecting), until Close().
+	ListenChanges() (<-chan string, error)
 }
 
 // Table identifiers for generic queries
@@ -929,6 +1179,15 @@ const (
 	ScnSubcriptionsTable    = "ScnSubscriptions"
 )
 
+// Tables that send change notifications, as received from ListenChanges()
+const (
+	ChangedAll              = "*" // Notifications may have been missed
+	ChangedSCNSubscriptions = scnSubcriptionsTableDB
+	ChangedCompEndpoints    = componentEndpointsTableDB
+	ChangedRFEndpoints      = redfishEndpointsTableDB
+	ChangedEventRules       = eventRulesTable
+)
+
 type HMSDBTx interface {
 	// Terminates transaction, reversing all changes made prior to Begin()
 	Rollback() error
@@ -1009,6 +1268,11 @@ type HMSDBTx interface {
 	// of the non-empty strings in the filter struct.
 	GetComponentsQueryTx(f *ComponentFilter, fieldFltr FieldFilter, ids []string) ([]*base.Component, error)
 
+	// Get the HMS Components selected by a group expression (in
+	// transaction).  Returns ErrHMSDSNoGroup if the expression names a group
+	// that doesn't exist.
+	GetComponentsGroupExprTx(e *sm.GroupExpr, fieldFltr FieldFilter) ([]*base.Component, error)
+
 	// Get a single HMS Component by its NID, if the NID exists (in transaction)
 	GetComponentByNIDTx(nid string) (*base.Component, error)
 
@@ -1075,6 +1339,11 @@ type HMSDBTx interface {
 	// Note: Role cannot be empty/invalid.
 	BulkUpdateCompRoleTx(ids []string, role, subRole string) (int64, error)
 
+	// Make the changes in p to the locked component comp, updating only the
+	// fields that would actually change (in transaction).  Returns true if
+	// there were any.
+	PatchComponentTx(comp *base.Component, p *sm.CompPatch) (bool, error)
+
 	// Update Class field only in DB for a list of components
 	// (In transaction.)
 	// Returns the number of affected rows. < 0 means RowsAffected() is not supported.
@@ -1097,6 +1366,11 @@ type HMSDBTx interface {
 	// Also returns number of deleted rows, if error is nil.
 	DeleteComponentsAllTx() (int64, error)
 
+	// Delete component history entries recorded before the given time, and
+	// the oldest ones beyond maxEntries (if maxEntries > 0). (in
+	// transaction)
+	PruneCompHistoryTx(before time.Time, maxEntries int) (int64, error)
+
 	//                                                                    //
 	//              Node to Default NID, role, etc. mapping               //
 	//                                                                    //
@@ -1231,6 +1505,56 @@ type HMSDBTx interface {
 	// If a duplicate is present return an error.
 	InsertHWInvHistsTx(hhs []*sm.HWInvHist) error
 
+	//                                                                    //
+	//   HWInvFirmware - Firmware versions from the Redfish UpdateService //
+	//                                                                    //
+
+	// Get firmware/software inventory entries with filtering options to
+	// possibly narrow the returned values. (in transaction)
+	GetHWInvFirmwareFilterTx(f_opts ...HWInvFirmwareFiltFunc) ([]*sm.HWInvFirmware, error)
+
+	// Insert or update an array of firmware/software inventory entries.
+	// (in transaction)
+	UpsertHWInvFirmwaresTx(fws []*sm.HWInvFirmware) error
+
+	// Delete the firmware/software inventory entries reported by a
+	// RedfishEndpoint, except those in keep. (in transaction)
+	// Returns the number of deleted rows, if error is nil.
+	DeleteHWInvFirmwareByRFEndpointTx(rfEPID string, keep []*sm.HWInvFirmware) (int64, error)
+
+	//                                                                    //
+	//       HWInvFan - Fans from Redfish Thermal/ThermalSubsystem        //
+	//                                                                    //
+
+	// Get fan inventory entries with filtering options to possibly narrow
+	// the returned values. (in transaction)
+	GetHWInvFanFilterTx(f_opts ...HWInvFanFiltFunc) ([]*sm.HWInvFan, error)
+
+	// Insert or update an array of fan inventory entries. (in transaction)
+	UpsertHWInvFansTx(fans []*sm.HWInvFan) error
+
+	// Delete the fan inventory entries reported by a RedfishEndpoint,
+	// except those in keep. (in transaction)
+	// Returns the number of deleted rows, if error is nil.
+	DeleteHWInvFansByRFEndpointTx(rfEPID string, keep []*sm.HWInvFan) (int64, error)
+
+	//                                                                    //
+	//    Component Health - Sources of non-OK component flags            //
+	//                                                                    //
+
+	// Get the health contributors recorded for the given components.
+	// (in transaction)
+	GetCompHealthContributorsTx(ids []string) ([]*sm.CompHealthContributor, error)
+
+	// Insert or update an array of health contributors. (in transaction)
+	UpsertCompHealthContributorsTx(hcs []*sm.CompHealthContributor) error
+
+	// Delete the health contributors of the given components.  If odataID
+	// is non-empty, only the contributor with that Redfish URI is removed.
+	// (in transaction)
+	// Returns the number of deleted rows, if error is nil.
+	DeleteCompHealthContributorsTx(ids []string, odataID string) (int64, error)
+
 	//                                                                    //
 	//    Redfish Endpoints - Redfish service roots used for discovery    //
 	//                                                                    //
@@ -1473,6 +1797,41 @@ type HMSDBTx interface {
 	// Delete all SCN subscriptions
 	DeleteSCNSubscriptionsAllTx() (int64, error)
 
+	//                                                                    //
+	//       Event Rules: Mapping of Redfish events to state changes      //
+	//                                                                    //
+
+	// Get the stored event rules with the given names, or all of them if
+	// names is empty. (in transaction)
+	GetEventRulesTx(names []string) ([]*sm.EventRule, error)
+
+	// Insert a new event rule. (in transaction)
+	InsertEventRuleTx(r *sm.EventRule) error
+
+	// Replace an existing event rule.  Returns false if there is none.
+	// (in transaction)
+	UpdateEventRuleTx(r *sm.EventRule) (bool, error)
+
+	// Delete the event rule with the given name.  Returns false if there
+	// is none. (in transaction)
+	DeleteEventRuleTx(name string) (bool, error)
+
+	//                                                                    //
+	//       Redfish Event Log - Processed events received from BMCs      //
+	//                                                                    //
+
+	// Add entries to the Redfish event log. (in transaction)
+	InsertRFEventLogEntriesTx(entries []*sm.RFEventLogEntry) error
+
+	// Get Redfish event log entries, most recent first, matching the
+	// filter options. (in transaction)
+	GetRFEventLogFilterTx(f_opts ...RFEventLogFiltFunc) ([]*sm.RFEventLogEntry, error)
+
+	// Delete Redfish event log entries received before the given time, and
+	// the oldest ones beyond maxEntries (if maxEntries > 0). (in
+	// transaction)
+	PruneRFEventLogTx(before time.Time, maxEntries int) (int64, error)
+
 	//                                                                    //
 	//                 Group and Partition  Management                    //
 	//                                                                    //
@@ -1511,6 +1870,25 @@ type HMSDBTx interface {
 	// of the same one).
 	GetEmptyPartitionTx(name string) (uuid string, p *sm.Partition, err error)
 
+	// Get the internal uuids of the sub-partitions of top-level partition
+	// name, e.g. p1.1 and p1.2 for p1.  Empty for sub-partitions, which
+	// can't have their own.
+	GetSubPartitionIDsTx(name string) ([]string, error)
+
+	// Get the current version of the group or partition with the given
+	// uuid, locking it until the end of the transaction so it can't be
+	// changed by anyone else in the meantime.
+	GetGroupVersionTx(uuid string) (int64, error)
+
+	// Delete the group or partition with the given uuid, along with its
+	// members.  If no error, bool indicates whether it was present to remove.
+	DeleteGroupTx(uuid string) (bool, error)
+
+	// Name the service or user making the changes in this transaction, to
+	// be recorded in the group, partition and component history.  Does
+	// nothing if requester is empty.
+	SetRequesterTx(requester string) error
+
 	//                  Members (for either Group/Partition)
 
 	// Insert memberlist for group/part.  The uuid parameter should be
@@ -1539,6 +1917,38 @@ type HMSDBTx interface {
 	// if it does not, result will be false, nil vs. true,nil on deletion.
 	DeleteMemberTx(uuid, id string) (bool, error)
 
+	// Given an internal group_id uuid, delete the members with the given
+	// (normalized) ids.
+	DeleteMembersTx(uuid string, ids []string) error
+
+	//                          Group includes
+
+	// Make the group with the given uuid include the groups with the
+	// given labels.  Returns ErrHMSDSNoIncludedGroup if one of them
+	// doesn't exist and ErrHMSDSGroupCycle if the group is already
+	// reachable from one of them, i.e. if it would end up including
+	// itself.
+	InsertGroupIncludesTx(uuid string, labels []string) error
+
+	// Remove all of the includes of the group with the given uuid.
+	DeleteGroupIncludesTx(uuid string) error
+
+	//                        Component Labels
+
+	// Get the labels on the component with the given xname id.  Empty if
+	// it has none or doesn't exist.
+	GetCompLabelsTx(id string) (map[string]string, error)
+
+	// Set the given labels on each of the components with the given
+	// (normalized) xname ids, overwriting the values of any they already
+	// have.
+	SetCompLabelsTx(ids []string, labels map[string]string) error
+
+	// Remove the labels with the given keys, or all of them if keys is
+	// nil, from each of the components with the given (normalized) xname
+	// ids.
+	DeleteCompLabelsTx(ids []string, keys []string) error
+
 	//                                                                    //
 	//                    Component Lock Management                       //
 	//                                                                    //
@@ -1584,6 +1994,10 @@ type HMSDBTx interface {
 	// Update the status and LastUpdated fields for a Job entry (in transaction).
 	UpdateEmptyJobTx(jobId string, status string) (bool, error)
 
+	// Update the status and LastUpdated fields for multiple Job entries
+	// (in transaction).  Returns the number of entries updated.
+	UpdateEmptyJobsTx(jobIds []string, status string) (int64, error)
+
 	// Get the user-readable fields in a job entry but don't fetch its job type
 	// specific data (done in transaction, so we can fetch them as part of the
 	// same one).
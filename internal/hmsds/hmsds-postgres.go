@@ -25,6 +25,7 @@ package hmsds
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -32,6 +33,7 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	base "github.com/Cray-HPE/hms-base/v2"
@@ -44,7 +46,7 @@ import (
 )
 
 // MUST be kept in sync with schema installed via smd-init job
-const HMSDS_PG_SCHEMA = 20
+const HMSDS_PG_SCHEMA = 35
 const HMSDS_PG_SYSTEM_ID = 0
 
 type hmsdbPg struct {
@@ -55,6 +57,15 @@ type hmsdbPg struct {
 	sc        *sq.StmtCache
 	lg        *log.Logger
 	lgLvl     LogLevel
+
+	// Session-level advisory locks are tied to a single connection, so
+	// they are taken on one set aside from the pool.
+	lockMutex sync.Mutex
+	lockConn  *sql.Conn
+	lockKeys  map[int64]bool
+
+	// Receives change notifications, if ListenChanges() was called.
+	listener *pq.Listener
 }
 
 // Gen DSN for MySQL/MariaDB
@@ -303,6 +314,14 @@ func (d *hmsdbPg) Close() error {
 	}
 	d.connected = false
 
+	d.lockMutex.Lock()
+	d.dropLockConn()
+	d.lockMutex.Unlock()
+	if d.listener != nil {
+		d.listener.Close()
+		d.listener = nil
+	}
+
 	err := d.db.Close()
 	return err
 }
@@ -491,6 +510,23 @@ func (d *hmsdbPg) GetComponentsQuery(f *ComponentFilter, fieldFltr FieldFilter,
 	return comps, err
 }
 
+// Get the HMS Components selected by a group expression, e.g. "(a | b) & !c"
+// over group labels and partition names.  Returns ErrHMSDSNoGroup if the
+// expression names a group that doesn't exist.
+func (d *hmsdbPg) GetComponentsGroupExpr(e *sm.GroupExpr, fieldFltr FieldFilter) ([]*base.Component, error) {
+	t, err := d.Begin()
+	if err != nil {
+		return nil, err
+	}
+	comps, err := t.GetComponentsGroupExprTx(e, fieldFltr)
+	if err != nil {
+		t.Rollback()
+		return comps, err
+	}
+	err = t.Commit()
+	return comps, err
+}
+
 // Get a single component by its NID, if one exists.
 func (d *hmsdbPg) GetComponentByNID(nid string) (*base.Component, error) {
 	t, err := d.Begin()
@@ -550,7 +586,7 @@ func (d *hmsdbPg) InsertComponents(comps *base.ComponentArray) ([]string, error)
 // all-or-none transaction. If force=true, only the state, flag, subtype,
 // nettype, and arch will be overwritten for existing components. Otherwise,
 // this won't overwrite existing components.
-func (d *hmsdbPg) UpsertComponents(comps []*base.Component, force bool) (map[string]map[string]bool, error) {
+func (d *hmsdbPg) UpsertComponents(comps []*base.Component, force bool, source string) (map[string]map[string]bool, error) {
 	affectedRowMap := make(map[string]map[string]bool, 0)
 	cmap := make(map[string]*base.Component, 0)
 	compList := make([]*base.Component, 0, 1)
@@ -558,6 +594,10 @@ func (d *hmsdbPg) UpsertComponents(comps []*base.Component, force bool) (map[str
 	if err != nil {
 		return nil, err
 	}
+	if err := t.SetRequesterTx(source); err != nil {
+		t.Rollback()
+		return nil, err
+	}
 	ids := make([]string, len(comps))
 	for i, comp := range comps {
 		ids[i] = comp.ID
@@ -646,7 +686,8 @@ func (d *hmsdbPg) UpsertComponents(comps []*base.Component, force bool) (map[str
 // Update state and flag fields only in DB for a list of components
 //   Note: If flag is not set, it will be set to OK (i.e. no flag)
 func (d *hmsdbPg) BulkUpdateCompState(ids []string, state string, flag string) ([]string, error) {
-	return d.UpdateCompStates(ids, state, flag, false, new(PartInfo))
+	return d.UpdateCompStates(ids, state, flag, false, new(PartInfo), "",
+		nil)
 }
 
 // Update state and flag fields only in DB for the given IDs.  If
@@ -656,12 +697,19 @@ func (d *hmsdbPg) BulkUpdateCompState(ids []string, state string, flag string) (
 // If force = true ignores any starting state restrictions and will
 // always set ids to 'state', unless it is already set.
 //   Note: If flag is not set, it will be set to OK (i.e. no flag)
+//
+// If check is not nil, it is called with every component in ids that
+// exists, while locked, before anything is updated.  If it returns an
+// error for any of them, nothing is updated and the first error is
+// returned.
 func (d *hmsdbPg) UpdateCompStates(
 	ids []string,
 	state string,
 	flag string,
 	force bool,
 	pi *PartInfo,
+	source string,
+	check CompStateCheck,
 ) ([]string, error) {
 	// Verify input
 	numIds := len(ids)
@@ -677,6 +725,29 @@ func (d *hmsdbPg) UpdateCompStates(
 	if err != nil {
 		return []string{}, err
 	}
+	if err := t.SetRequesterTx(source); err != nil {
+		t.Rollback()
+		return []string{}, err
+	}
+	if check != nil {
+		// Lock the components so their states can't change between
+		// checking and updating them.
+		comps, err := t.GetComponentsTx(IDs(ids), WRLock, From(fname))
+		if err != nil {
+			t.Rollback()
+			return []string{}, err
+		}
+		var checkErr error
+		for _, comp := range comps {
+			if err := check(comp, state); err != nil && checkErr == nil {
+				checkErr = err
+			}
+		}
+		if checkErr != nil {
+			t.Rollback()
+			return []string{}, checkErr
+		}
+	}
 	// We need to figure out the modified components.  If there are more
 	// than one, we need to do a locking read with those that can be
 	// updated given their current state and the allowed starting state
@@ -754,13 +825,13 @@ func (d *hmsdbPg) UpdateCompStates(
 //   Note: If flag is not set, it will be set to OK (i.e. no flag)
 func (d *hmsdbPg) UpdateCompState(c *base.Component) (int64, error) {
 	ids, err := d.UpdateCompStates([]string{c.ID}, c.State, c.Flag,
-		false, new(PartInfo))
+		false, new(PartInfo), "", nil)
 	return int64(len(ids)), err
 }
 
 // Update flag field in DB for a list of components
 // Note: Flag cannot be empty/invalid.
-func (d *hmsdbPg) BulkUpdateCompFlagOnly(ids []string, flag string) ([]string, error) {
+func (d *hmsdbPg) BulkUpdateCompFlagOnly(ids []string, flag string, source string) ([]string, error) {
 	// Verify input
 	if len(ids) < 1 {
 		d.LogAlways("Error: BulkUpdateCompFlagOnly(): id list is empty")
@@ -776,6 +847,10 @@ func (d *hmsdbPg) BulkUpdateCompFlagOnly(ids []string, flag string) ([]string, e
 	if err != nil {
 		return []string{}, err
 	}
+	if err := t.SetRequesterTx(source); err != nil {
+		t.Rollback()
+		return []string{}, err
+	}
 	// Lock components for update and get components that don't already have
 	// flag
 	// Lock components for update and select components we need to change.
@@ -800,11 +875,15 @@ func (d *hmsdbPg) BulkUpdateCompFlagOnly(ids []string, flag string) ([]string, e
 // Update Flag field in DB from c's Flag field.
 // Returns the number of affected rows. < 0 means RowsAffected() is not supported.
 // Note: Flag cannot be blank/invalid.
-func (d *hmsdbPg) UpdateCompFlagOnly(id string, flag string) (int64, error) {
+func (d *hmsdbPg) UpdateCompFlagOnly(id string, flag string, source string) (int64, error) {
 	t, err := d.Begin()
 	if err != nil {
 		return 0, err
 	}
+	if err := t.SetRequesterTx(source); err != nil {
+		t.Rollback()
+		return 0, err
+	}
 	rowsAffected, err := t.UpdateCompFlagOnlyTx(id, flag)
 	if err != nil {
 		t.Rollback()
@@ -819,11 +898,15 @@ func (d *hmsdbPg) UpdateCompFlagOnly(id string, flag string) (int64, error) {
 // Update enabled field in DB from c's Enabled field.
 // Returns the number of affected rows. < 0 means RowsAffected() is not supported.
 // Note: c.Enabled cannot be nil.
-func (d *hmsdbPg) UpdateCompEnabled(id string, enabled bool) (int64, error) {
+func (d *hmsdbPg) UpdateCompEnabled(id string, enabled bool, source string) (int64, error) {
 	t, err := d.Begin()
 	if err != nil {
 		return 0, err
 	}
+	if err := t.SetRequesterTx(source); err != nil {
+		t.Rollback()
+		return 0, err
+	}
 	rowsAffected, err := t.UpdateCompEnabledTx(id, enabled)
 	if err != nil {
 		t.Rollback()
@@ -836,7 +919,7 @@ func (d *hmsdbPg) UpdateCompEnabled(id string, enabled bool) (int64, error) {
 }
 
 // Update Enabled field only in DB for a list of components
-func (d *hmsdbPg) BulkUpdateCompEnabled(ids []string, enabled bool) ([]string, error) {
+func (d *hmsdbPg) BulkUpdateCompEnabled(ids []string, enabled bool, source string) ([]string, error) {
 	// Verify input
 	if len(ids) < 1 {
 		d.LogAlways("Error: BulkUpdateCompEnabled(): id list is empty")
@@ -848,6 +931,10 @@ func (d *hmsdbPg) BulkUpdateCompEnabled(ids []string, enabled bool) ([]string, e
 	if err != nil {
 		return []string{}, err
 	}
+	if err := t.SetRequesterTx(source); err != nil {
+		t.Rollback()
+		return []string{}, err
+	}
 	// Lock components for update and select those that still need updates.
 	affectedIDs, err := t.GetComponentIDsTx(IDs(ids),
 		Enabled("!"+strconv.FormatBool(enabled)),
@@ -870,11 +957,15 @@ func (d *hmsdbPg) BulkUpdateCompEnabled(ids []string, enabled bool) ([]string, e
 
 // Update SwStatus field in DB from c's SwStatus field.
 // Returns the number of affected rows. < 0 means RowsAffected() is not supported.
-func (d *hmsdbPg) UpdateCompSwStatus(id string, swStatus string) (int64, error) {
+func (d *hmsdbPg) UpdateCompSwStatus(id string, swStatus string, source string) (int64, error) {
 	t, err := d.Begin()
 	if err != nil {
 		return 0, err
 	}
+	if err := t.SetRequesterTx(source); err != nil {
+		t.Rollback()
+		return 0, err
+	}
 	rowsAffected, err := t.UpdateCompSwStatusTx(id, swStatus)
 	if err != nil {
 		t.Rollback()
@@ -887,7 +978,7 @@ func (d *hmsdbPg) UpdateCompSwStatus(id string, swStatus string) (int64, error)
 }
 
 // Update SwStatus field only in DB for a list of components
-func (d *hmsdbPg) BulkUpdateCompSwStatus(ids []string, swstatus string) ([]string, error) {
+func (d *hmsdbPg) BulkUpdateCompSwStatus(ids []string, swstatus string, source string) ([]string, error) {
 	// Verify input
 	if len(ids) < 1 {
 		d.LogAlways("Error: BulkUpdateCompSwStatus(): id list is empty")
@@ -899,6 +990,10 @@ func (d *hmsdbPg) BulkUpdateCompSwStatus(ids []string, swstatus string) ([]strin
 	if err != nil {
 		return []string{}, err
 	}
+	if err := t.SetRequesterTx(source); err != nil {
+		t.Rollback()
+		return []string{}, err
+	}
 	// Lock components for update
 	affectedIDs, err := t.GetComponentIDsTx(IDs(ids), SwStatus("!"+swstatus),
 		From("BulkUpdateCompSwStatus"))
@@ -920,7 +1015,7 @@ func (d *hmsdbPg) BulkUpdateCompSwStatus(ids []string, swstatus string) ([]strin
 
 // Update Role/SubRole field in DB for a list of components
 // Note: Role cannot be empty/invalid.
-func (d *hmsdbPg) BulkUpdateCompRole(ids []string, role, subRole string) ([]string, error) {
+func (d *hmsdbPg) BulkUpdateCompRole(ids []string, role, subRole string, source string) ([]string, error) {
 	var affectedIDs []string
 	// Verify input
 	if len(ids) < 1 {
@@ -944,6 +1039,10 @@ func (d *hmsdbPg) BulkUpdateCompRole(ids []string, role, subRole string) ([]stri
 	if err != nil {
 		return []string{}, err
 	}
+	if err := t.SetRequesterTx(source); err != nil {
+		t.Rollback()
+		return []string{}, err
+	}
 	// Lock components for update that still need changes (i.e. !role)
 	if subRole == "" {
 		affectedIDs, err = t.GetComponentIDsTx(IDs(ids), Role("!"+role),
@@ -971,11 +1070,15 @@ func (d *hmsdbPg) BulkUpdateCompRole(ids []string, role, subRole string) ([]stri
 // Update Role/SubRole field in DB from c's Role/SubRole field.
 // Returns the number of affected rows. < 0 means RowsAffected() is not supported.
 // Note: Role cannot be blank/invalid.
-func (d *hmsdbPg) UpdateCompRole(id string, role, subRole string) (int64, error) {
+func (d *hmsdbPg) UpdateCompRole(id string, role, subRole string, source string) (int64, error) {
 	t, err := d.Begin()
 	if err != nil {
 		return 0, err
 	}
+	if err := t.SetRequesterTx(source); err != nil {
+		t.Rollback()
+		return 0, err
+	}
 	rowsAffected, err := t.UpdateCompRoleTx(id, role, subRole)
 	if err != nil {
 		t.Rollback()
@@ -987,6 +1090,125 @@ func (d *hmsdbPg) UpdateCompRole(id string, role, subRole string) (int64, error)
 	return rowsAffected, nil
 }
 
+// Apply each of the given multi-field changes to its component, all in a
+// single transaction.  Each component either gets all of its changes, or,
+// if it doesn't exist or can't be moved to the new state from its current
+// one (unless force = true), none of them and is returned as a failure.
+// The patches should already be verified and normalized.  Returns the ids
+// of the components that were actually changed.
+//
+// If check is not nil, it is called with each locked component that is
+// given a new state.  If it returns an error, the component fails with
+// the error as the reason.
+func (d *hmsdbPg) PatchComponents(
+	patches []*sm.CompPatch,
+	force bool,
+	source string,
+	check CompStateCheck,
+) ([]string, []sm.CompPatchFailure, error) {
+	fname := "PatchComponents"
+	if len(patches) < 1 {
+		d.LogAlways("Error: %s(): patch list is empty", fname)
+		return nil, nil, ErrHMSDSArgMissing
+	}
+	ids := make([]string, 0, len(patches))
+	for _, p := range patches {
+		ids = append(ids, p.ID)
+	}
+
+	// Start transaction
+	t, err := d.Begin()
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := t.SetRequesterTx(source); err != nil {
+		t.Rollback()
+		return nil, nil, err
+	}
+	// Lock the components so they can't change between checking and
+	// updating them.
+	comps, err := t.GetComponentsTx(IDs(ids), WRLock, From(fname))
+	if err != nil {
+		t.Rollback()
+		return nil, nil, err
+	}
+	compMap := make(map[string]*base.Component, len(comps))
+	for _, comp := range comps {
+		compMap[comp.ID] = comp
+	}
+	changedIDs := []string{}
+	failures := []sm.CompPatchFailure{}
+	for _, p := range patches {
+		comp, ok := compMap[p.ID]
+		if !ok {
+			failures = append(failures,
+				sm.CompPatchFailure{ID: p.ID, Reason: "no such component"})
+			continue
+		}
+		if check != nil && p.State != "" {
+			if err := check(comp, p.State); err != nil {
+				failures = append(failures,
+					sm.CompPatchFailure{ID: p.ID, Reason: err.Error()})
+				continue
+			}
+		}
+		if reason := checkCompPatch(comp, p, force); reason != "" {
+			failures = append(failures,
+				sm.CompPatchFailure{ID: p.ID, Reason: reason})
+			continue
+		}
+		changed, err := t.PatchComponentTx(comp, p)
+		if err != nil {
+			t.Rollback()
+			return nil, nil, err
+		}
+		if changed {
+			changedIDs = append(changedIDs, p.ID)
+		}
+	}
+	if err := t.Commit(); err != nil {
+		return nil, nil, err
+	}
+	return changedIDs, failures, nil
+}
+
+// Check that the changes in p can be made to comp, returning the reason
+// they can't be if not.
+func checkCompPatch(comp *base.Component, p *sm.CompPatch, force bool) string {
+	if p.State != "" && (p.State != comp.State || p.Flag != comp.Flag) {
+		var startStates []string
+		// Same special case as UpdateCompStates, a late heartbeat should
+		// only flag components that are still Ready.
+		if p.State == base.StateReady.String() &&
+			p.Flag == base.FlagWarning.String() {
+			startStates = []string{base.StateReady.String()}
+		} else {
+			var err error
+			startStates, err = base.GetValidStartStateWForce(p.State, force)
+			if err != nil {
+				return err.Error()
+			}
+		}
+		if len(startStates) != 0 {
+			valid := false
+			for _, state := range startStates {
+				if strings.EqualFold(state, comp.State) {
+					valid = true
+					break
+				}
+			}
+			if !valid {
+				return fmt.Sprintf("state cannot be changed from %s to %s",
+					comp.State, p.State)
+			}
+		}
+	}
+	if p.Role == nil && p.SubRole != nil && comp.Role == "" {
+		return "missing Role"
+	}
+	return ""
+}
+
 // Update Class field only in DB for a list of components
 func (d *hmsdbPg) BulkUpdateCompClass(ids []string, class string) ([]string, error) {
 	// Verify input
@@ -1096,6 +1318,91 @@ func (d *hmsdbPg) DeleteComponentsAll() (int64, error) {
 	return numDeleted, nil
 }
 
+// Get the recorded state, flag, enabled, role and software status changes
+// to the component with the given xname id, oldest first, matching the
+// filter options.  History is kept after a component is deleted.
+func (d *hmsdbPg) GetCompHistory(
+	id string,
+	f_opts ...CompHistFiltFunc,
+) ([]*sm.CompHistoryEntry, error) {
+	// Parse the filter options
+	f := new(CompHistFilter)
+	for _, opts := range f_opts {
+		opts(f)
+	}
+	query := sq.Select(compHistColsSM...).
+		From(compHistTable).
+		Where(compHistCmpIdCol+" = ?", xnametypes.NormalizeHMSCompID(id))
+	if len(f.Field) > 0 {
+		fields := []string{}
+		for _, field := range f.Field {
+			normField := sm.VerifyNormalizeCompHistField(field)
+			if normField == "" {
+				return nil, ErrHMSDSArgBadArg
+			}
+			fields = append(fields, normField)
+		}
+		query = query.Where(sq.Eq{compHistFieldCol: fields})
+	}
+	if len(f.Source) > 0 {
+		query = query.Where(sq.Eq{compHistSourceCol: f.Source})
+	}
+	if f.Since != "" {
+		since, err := time.Parse(time.RFC3339, f.Since)
+		if err != nil {
+			return nil, ErrHMSDSArgBadTimeFormat
+		}
+		query = query.Where(sq.GtOrEq{compHistTimestampCol: since})
+	}
+	if f.Until != "" {
+		until, err := time.Parse(time.RFC3339, f.Until)
+		if err != nil {
+			return nil, ErrHMSDSArgBadTimeFormat
+		}
+		query = query.Where(sq.LtOrEq{compHistTimestampCol: until})
+	}
+	query = query.OrderBy(compHistIdCol + " ASC")
+
+	// Query with statement cache for caching prepared statements
+	query = query.PlaceholderFormat(sq.Dollar)
+	qStr, qArgs, _ := query.ToSql()
+	d.Log(LOG_DEBUG, "Debug: GetCompHistory(): Query: %s - With args: %v", qStr, qArgs)
+	rows, err := query.RunWith(d.sc).QueryContext(d.ctx)
+	if err != nil {
+		d.LogAlways("Error: GetCompHistory(%s): query failed: %s", f.label, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := make([]*sm.CompHistoryEntry, 0, 1)
+	for rows.Next() {
+		ent, err := d.scanCompHistoryEntry(rows)
+		if err != nil {
+			d.LogAlways("Error: GetCompHistory(%s): scan failed: %s", f.label, err)
+			return nil, err
+		}
+		entries = append(entries, ent)
+	}
+	return entries, rows.Err()
+}
+
+// Delete component history entries recorded before the given time, and the
+// oldest ones beyond maxEntries (if maxEntries > 0).  Returns the number
+// deleted.
+func (d *hmsdbPg) PruneCompHistory(before time.Time, maxEntries int) (int64, error) {
+	t, err := d.Begin()
+	if err != nil {
+		return 0, err
+	}
+	numDeleted, err := t.PruneCompHistoryTx(before, maxEntries)
+	if err != nil {
+		t.Rollback()
+		return 0, err
+	}
+	err = t.Commit()
+	return numDeleted, err
+}
+
 /////////////////////////////////////////////////////////////////////////////
 //
 // Node->NID Mapping
@@ -1430,7 +1737,7 @@ func (d *hmsdbPg) GetHWInvByLocFilter(f_opts ...HWInvLocFiltFunc) ([]*sm.HWInvBy
 	}
 	if len(f.Partition) > 0 {
 		partCol := hwInvAlias + "." + hwInvPartPartitionCol
-		query = query.Where(sq.Eq{partCol: f.Partition})
+		query = query.Where(partNameCond(partCol, f.Partition))
 	}
 
 	// Execute
@@ -1566,6 +1873,14 @@ func (d *hmsdbPg) GetHWInvByFRUFilter(f_opts ...HWInvLocFiltFunc) ([]*sm.HWInvBy
 		fruIdCol := hwInvFruAlias + "." + hwInvFruTblIdCol
 		query = query.Where(sq.Eq{fruIdCol: f.FruId})
 	}
+	// A FRU is in a partition if the location it is installed at is.
+	if len(f.Partition) > 0 {
+		fruIdCol := hwInvFruAlias + "." + hwInvFruTblIdCol
+		partQuery := sq.Select(hwInvPartFruIdCol).
+			From(hwInvPartTable).
+			Where(partNameCond(hwInvPartPartitionCol, f.Partition))
+		query = query.Where(sq.Expr(fruIdCol+" IN (?)", partQuery))
+	}
 
 	// Execute
 	query = query.PlaceholderFormat(sq.Dollar)
@@ -1684,6 +1999,54 @@ func (d *hmsdbPg) InsertHWInvByLocs(hls []*sm.HWInvByLoc) error {
 	return err
 }
 
+// Replace the locations at and under xname id with hls, e.g. after
+// re-reading that part of the system.  Locations not in hls are deleted,
+// detaching their FRUs.  Added and Removed history events are stored for
+// each FRU that arrived at or left a location and returned.
+func (d *hmsdbPg) UpdateHWInvByLocSubtree(id string, hls []*sm.HWInvByLoc) ([]*sm.HWInvHist, error) {
+	t, err := d.Begin()
+	if err != nil {
+		return nil, err
+	}
+	oldLocs, err := t.GetHWInvByLocQueryFilterTx(HWInvLoc_IDs([]string{id}), HWInvLoc_Child)
+	if err != nil {
+		t.Rollback()
+		return nil, err
+	}
+	gone, hists := sm.DiffHWInvByLocs(oldLocs, hls)
+	for _, gid := range gone {
+		if _, err := t.DeleteHWInvByLocIDTx(gid); err != nil {
+			t.Rollback()
+			return nil, err
+		}
+	}
+	hfs := make([]*sm.HWInvByFRU, 0, len(hls))
+	// Insert FRUs first because the location info links to them.
+	for _, hl := range hls {
+		if hl.PopulatedFRU != nil {
+			hfs = append(hfs, hl.PopulatedFRU)
+		}
+	}
+	if err = t.BulkInsertHWInvByFRUTx(hfs); err != nil {
+		t.Rollback()
+		return nil, err
+	}
+	if err = t.BulkInsertHWInvByLocTx(hls); err != nil {
+		t.Rollback()
+		return nil, err
+	}
+	if len(hists) > 0 {
+		if err = t.InsertHWInvHistsTx(hists); err != nil {
+			t.Rollback()
+			return nil, err
+		}
+	}
+	if err = t.Commit(); err != nil {
+		return nil, err
+	}
+	return hists, nil
+}
+
 // Delete HWInvByLoc entry with matching xname id from database, if it
 // exists.
 // Return true if there was a row affected, false if there were zero.
@@ -1942,94 +2305,312 @@ func (d *hmsdbPg) DeleteHWInvHistFilter(f_opts ...HWInvHistFiltFunc) (int64, err
 
 ////////////////////////////////////////////////////////////////////////////
 //
-// Redfish Endpoints - Top-level Redfish service roots used for discovery
+// HWInvFirmware - Firmware/software versions from the Redfish UpdateService
 //
 ////////////////////////////////////////////////////////////////////////////
 
-// Get RedfishEndpoint by ID (xname), i.e. a single entry.
-func (d *hmsdbPg) GetRFEndpointByID(id string) (*sm.RedfishEndpoint, error) {
+// Get firmware/software inventory entries with filtering options to
+// possibly narrow the returned values.  If no filter provided, just get
+// everything.
+func (d *hmsdbPg) GetHWInvFirmwareFilter(f_opts ...HWInvFirmwareFiltFunc) ([]*sm.HWInvFirmware, error) {
 	t, err := d.Begin()
 	if err != nil {
 		return nil, err
 	}
-	ep, err := t.GetRFEndpointByIDTx(id)
+	fws, err := t.GetHWInvFirmwareFilterTx(f_opts...)
 	if err != nil {
 		t.Rollback()
-		return ep, err
+		return fws, err
 	}
-	t.Commit()
-	return ep, nil
+	err = t.Commit()
+	return fws, err
 }
 
-// Get all RedfishEndpoints in system.
-func (d *hmsdbPg) GetRFEndpointsAll() ([]*sm.RedfishEndpoint, error) {
+// Replace the firmware/software inventory entries for a RedfishEndpoint
+// with fws.  Entries no longer reported by the endpoint are removed.
+// Returns the entries that were already present, but whose version has
+// changed, so they can be recorded in the hardware history.
+func (d *hmsdbPg) UpdateHWInvFirmwareForRFEndpoint(
+	rfEPID string,
+	fws []*sm.HWInvFirmware,
+) ([]*sm.HWInvFirmware, error) {
 	t, err := d.Begin()
 	if err != nil {
 		return nil, err
 	}
-	eps, err := t.GetRFEndpointsAllTx()
+	prev, err := t.GetHWInvFirmwareFilterTx(FW_RfEPs([]string{rfEPID}))
 	if err != nil {
 		t.Rollback()
-		return eps, err
+		return nil, err
 	}
-	t.Commit()
-	return eps, nil
-}
-
-// Get some or all RedfishEndpoints in system, with filtering
-// options to possibly narrow the returned values.
-// If no filter provided, just get everything.  Otherwise use it
-// to create a custom WHERE... string that filters out entries that
-// do not match ALL of the non-empty strings in the filter struct
-func (d *hmsdbPg) GetRFEndpointsFilter(f *RedfishEPFilter) ([]*sm.RedfishEndpoint, error) {
-	t, err := d.Begin()
-	if err != nil {
+	prevMap := make(map[string]*sm.HWInvFirmware, len(prev))
+	for _, fw := range prev {
+		prevMap[fw.Inventory+"/"+fw.ID] = fw
+	}
+	changed := make([]*sm.HWInvFirmware, 0, 1)
+	for _, fw := range fws {
+		if old, ok := prevMap[fw.Inventory+"/"+fw.ID]; ok && old.Version != fw.Version {
+			changed = append(changed, fw)
+		}
+	}
+	if _, err = t.DeleteHWInvFirmwareByRFEndpointTx(rfEPID, fws); err != nil {
+		t.Rollback()
 		return nil, err
 	}
-	reps, err := t.GetRFEndpointsFilterTx(f)
-	if err != nil {
+	if err = t.UpsertHWInvFirmwaresTx(fws); err != nil {
 		t.Rollback()
-		return reps, err
+		return nil, err
 	}
-	t.Commit()
-	return reps, nil
+	err = t.Commit()
+	if err != nil {
+		return nil, err
+	}
+	return changed, nil
 }
 
-// Insert new RedfishEndpoint into database.
-// Does not update any ComponentEndpoint children.
-// If ID or FQDN already exists, return ErrHMSDSDuplicateKey
-// No insertion done on err != nil
-func (d *hmsdbPg) InsertRFEndpoint(ep *sm.RedfishEndpoint) error {
+////////////////////////////////////////////////////////////////////////////
+//
+// HWInvFan - Fans from Redfish Chassis Thermal/ThermalSubsystem
+//
+////////////////////////////////////////////////////////////////////////////
+
+// Get fan inventory entries with filtering options to possibly narrow the
+// returned values.  If no filter provided, just get everything.
+func (d *hmsdbPg) GetHWInvFanFilter(f_opts ...HWInvFanFiltFunc) ([]*sm.HWInvFan, error) {
 	t, err := d.Begin()
 	if err != nil {
-		return err
+		return nil, err
 	}
-	err = t.InsertRFEndpointTx(ep)
+	fans, err := t.GetHWInvFanFilterTx(f_opts...)
 	if err != nil {
 		t.Rollback()
-		return err
-	}
-	if err := t.Commit(); err != nil {
-		return err
+		return fans, err
 	}
-	return nil
-}
-
-// Insert new RedfishEndpointArray entries into database within a
-// single all-or-none transaction.  Does not update any ComponentEndpoint
-// children.
-// If ID or FQDN already exists, return ErrHMSDSDuplicateKey
-// No insertion done on err != nil
-func (d *hmsdbPg) InsertRFEndpoints(eps *sm.RedfishEndpointArray) error {
+	err = t.Commit()
+	return fans, err
+}
+
+// Replace the fan inventory entries for a RedfishEndpoint with fans.
+// Entries no longer reported by the endpoint are removed.  Returns the
+// entries that were already present, but whose FRUID has changed, i.e.
+// the fan was installed, removed or replaced, so they can be recorded in
+// the hardware history.
+func (d *hmsdbPg) UpdateHWInvFansForRFEndpoint(
+	rfEPID string,
+	fans []*sm.HWInvFan,
+) ([]*sm.HWInvFan, error) {
 	t, err := d.Begin()
 	if err != nil {
-		return err
+		return nil, err
 	}
-
-	err = t.InsertRFEndpointsTx(eps.RedfishEndpoints)
+	prev, err := t.GetHWInvFanFilterTx(Fan_RfEPs([]string{rfEPID}))
 	if err != nil {
 		t.Rollback()
-		return err
+		return nil, err
+	}
+	prevMap := make(map[string]*sm.HWInvFan, len(prev))
+	for _, fan := range prev {
+		prevMap[fan.ParentID+"/"+fan.ID] = fan
+	}
+	changed := make([]*sm.HWInvFan, 0, 1)
+	for _, fan := range fans {
+		if old, ok := prevMap[fan.ParentID+"/"+fan.ID]; ok && old.FRUID != fan.FRUID {
+			changed = append(changed, fan)
+		}
+	}
+	if _, err = t.DeleteHWInvFansByRFEndpointTx(rfEPID, fans); err != nil {
+		t.Rollback()
+		return nil, err
+	}
+	if err = t.UpsertHWInvFansTx(fans); err != nil {
+		t.Rollback()
+		return nil, err
+	}
+	err = t.Commit()
+	if err != nil {
+		return nil, err
+	}
+	return changed, nil
+}
+
+////////////////////////////////////////////////////////////////////////////
+//
+// Component Health - Sources of non-OK component flags
+//
+////////////////////////////////////////////////////////////////////////////
+
+// Get the health contributors recorded for the given component.
+func (d *hmsdbPg) GetCompHealthContributors(id string) ([]*sm.CompHealthContributor, error) {
+	t, err := d.Begin()
+	if err != nil {
+		return nil, err
+	}
+	hcs, err := t.GetCompHealthContributorsTx([]string{id})
+	if err != nil {
+		t.Rollback()
+		return hcs, err
+	}
+	err = t.Commit()
+	return hcs, err
+}
+
+// Replace the health contributors of each component in contribs, keyed
+// by component xname, with the given list.  An empty list removes all
+// contributors for the component.
+func (d *hmsdbPg) SetCompHealthContributors(
+	contribs map[string][]*sm.CompHealthContributor,
+) error {
+	if len(contribs) == 0 {
+		return nil
+	}
+	ids := make([]string, 0, len(contribs))
+	hcs := make([]*sm.CompHealthContributor, 0, len(contribs))
+	for id, list := range contribs {
+		ids = append(ids, id)
+		hcs = append(hcs, list...)
+	}
+	t, err := d.Begin()
+	if err != nil {
+		return err
+	}
+	if _, err = t.DeleteCompHealthContributorsTx(ids, ""); err != nil {
+		t.Rollback()
+		return err
+	}
+	if err = t.UpsertCompHealthContributorsTx(hcs); err != nil {
+		t.Rollback()
+		return err
+	}
+	return t.Commit()
+}
+
+// Record the health of a single contributor for hc.CompID.  If its Flag is
+// OK, it is removed instead.  Returns the resulting set of contributors for
+// the component.
+func (d *hmsdbPg) UpdateCompHealthContributor(
+	hc *sm.CompHealthContributor,
+) ([]*sm.CompHealthContributor, error) {
+	if hc == nil {
+		return nil, ErrHMSDSArgNil
+	}
+	if err := hc.VerifyNormalize(); err != nil {
+		return nil, ErrHMSDSArgBadID
+	}
+	t, err := d.Begin()
+	if err != nil {
+		return nil, err
+	}
+	if hc.Flag == base.FlagOK.String() {
+		_, err = t.DeleteCompHealthContributorsTx([]string{hc.CompID}, hc.OdataID)
+	} else {
+		err = t.UpsertCompHealthContributorsTx([]*sm.CompHealthContributor{hc})
+	}
+	if err != nil {
+		t.Rollback()
+		return nil, err
+	}
+	hcs, err := t.GetCompHealthContributorsTx([]string{hc.CompID})
+	if err != nil {
+		t.Rollback()
+		return nil, err
+	}
+	err = t.Commit()
+	if err != nil {
+		return nil, err
+	}
+	return hcs, nil
+}
+
+////////////////////////////////////////////////////////////////////////////
+//
+// Redfish Endpoints - Top-level Redfish service roots used for discovery
+//
+////////////////////////////////////////////////////////////////////////////
+
+// Get RedfishEndpoint by ID (xname), i.e. a single entry.
+func (d *hmsdbPg) GetRFEndpointByID(id string) (*sm.RedfishEndpoint, error) {
+	t, err := d.Begin()
+	if err != nil {
+		return nil, err
+	}
+	ep, err := t.GetRFEndpointByIDTx(id)
+	if err != nil {
+		t.Rollback()
+		return ep, err
+	}
+	t.Commit()
+	return ep, nil
+}
+
+// Get all RedfishEndpoints in system.
+func (d *hmsdbPg) GetRFEndpointsAll() ([]*sm.RedfishEndpoint, error) {
+	t, err := d.Begin()
+	if err != nil {
+		return nil, err
+	}
+	eps, err := t.GetRFEndpointsAllTx()
+	if err != nil {
+		t.Rollback()
+		return eps, err
+	}
+	t.Commit()
+	return eps, nil
+}
+
+// Get some or all RedfishEndpoints in system, with filtering
+// options to possibly narrow the returned values.
+// If no filter provided, just get everything.  Otherwise use it
+// to create a custom WHERE... string that filters out entries that
+// do not match ALL of the non-empty strings in the filter struct
+func (d *hmsdbPg) GetRFEndpointsFilter(f *RedfishEPFilter) ([]*sm.RedfishEndpoint, error) {
+	t, err := d.Begin()
+	if err != nil {
+		return nil, err
+	}
+	reps, err := t.GetRFEndpointsFilterTx(f)
+	if err != nil {
+		t.Rollback()
+		return reps, err
+	}
+	t.Commit()
+	return reps, nil
+}
+
+// Insert new RedfishEndpoint into database.
+// Does not update any ComponentEndpoint children.
+// If ID or FQDN already exists, return ErrHMSDSDuplicateKey
+// No insertion done on err != nil
+func (d *hmsdbPg) InsertRFEndpoint(ep *sm.RedfishEndpoint) error {
+	t, err := d.Begin()
+	if err != nil {
+		return err
+	}
+	err = t.InsertRFEndpointTx(ep)
+	if err != nil {
+		t.Rollback()
+		return err
+	}
+	if err := t.Commit(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Insert new RedfishEndpointArray entries into database within a
+// single all-or-none transaction.  Does not update any ComponentEndpoint
+// children.
+// If ID or FQDN already exists, return ErrHMSDSDuplicateKey
+// No insertion done on err != nil
+func (d *hmsdbPg) InsertRFEndpoints(eps *sm.RedfishEndpointArray) error {
+	t, err := d.Begin()
+	if err != nil {
+		return err
+	}
+
+	err = t.InsertRFEndpointsTx(eps.RedfishEndpoints)
+	if err != nil {
+		t.Rollback()
+		return err
 	}
 
 	if err := t.Commit(); err != nil {
@@ -3612,105 +4193,305 @@ func (d *hmsdbPg) DeleteSCNSubscriptionsAll() (int64, error) {
 
 ////////////////////////////////////////////////////////////////////////////
 //
-// Group and Partition  Management
+// Event Rules - Mapping of Redfish events to state changes
 //
 ////////////////////////////////////////////////////////////////////////////
 
-//
-// Groups
-//
-
-// Create a group.  Returns new label (should match one in struct,
-// unless case-normalized) if successful, otherwise empty string + non
-// nil error. Will return ErrHMSDSDuplicateKey if group exits or is
-// exclusive and xname id is already in another group in this exclusive set.
-// In addition, returns ErrHMSDSNoComponent if a component id doesn't exist.
-func (d *hmsdbPg) InsertGroup(g *sm.Group) (string, error) {
+// Get all event rules stored via the API.
+func (d *hmsdbPg) GetEventRulesAll() ([]*sm.EventRule, error) {
 	t, err := d.Begin()
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	// Insert first the group, with no members.
-	// Note this also normalizes and verifies data - exgroup won't contain '%'
-	uuid, label, exgrp, err := t.InsertEmptyGroupTx(g)
+	rules, err := t.GetEventRulesTx([]string{})
 	if err != nil {
 		t.Rollback()
-		return "", err
+		return nil, err
 	}
-	namespace := label // Normal namespace is non-exclusive group name
-	if exgrp != "" {
-		// exclusive group - uniquified exclusive group as namespace
-		namespace = "%" + exgrp + "%"
+	err = t.Commit()
+	return rules, err
+}
+
+// Get the stored event rule with the given name, nil if none.
+func (d *hmsdbPg) GetEventRule(name string) (*sm.EventRule, error) {
+	t, err := d.Begin()
+	if err != nil {
+		return nil, err
 	}
-	err = t.InsertMembersTx(uuid, namespace, &g.Members)
+	rules, err := t.GetEventRulesTx([]string{name})
 	if err != nil {
 		t.Rollback()
-		return "", err
+		return nil, err
 	}
 	err = t.Commit()
-	return label, err
+	if err != nil || len(rules) == 0 {
+		return nil, err
+	}
+	return rules[0], nil
 }
 
-// Update group with label
-func (d *hmsdbPg) UpdateGroup(label string, gp *sm.GroupPatch) error {
-	gp.Normalize()
-	if err := gp.Verify(); err != nil {
-		return err
-	}
-	// Start the transaction
+// Insert a new event rule.  If the name is taken, returns
+// ErrHMSDSDuplicateKey.
+func (d *hmsdbPg) InsertEventRule(r *sm.EventRule) error {
 	t, err := d.Begin()
 	if err != nil {
 		return err
 	}
-	// Get the existing partition in a transaction, without members initially.
-	uuid, g, err := t.GetEmptyGroupTx(label)
+	err = t.InsertEventRuleTx(r)
 	if err != nil {
-		// Unexpected error - couldn't get partition
 		t.Rollback()
 		return err
-	} else if g == nil || uuid == "" {
-		// Lookup returned nothing - 404
+	}
+	return t.Commit()
+}
+
+// Replace an existing event rule.  Returns false if there is none.
+func (d *hmsdbPg) UpdateEventRule(r *sm.EventRule) (bool, error) {
+	t, err := d.Begin()
+	if err != nil {
+		return false, err
+	}
+	didUpdate, err := t.UpdateEventRuleTx(r)
+	if err != nil {
 		t.Rollback()
-		return ErrHMSDSNoGroup
+		return false, err
 	}
-	if err := t.UpdateEmptyGroupTx(uuid, g, gp); err != nil {
+	err = t.Commit()
+	return didUpdate, err
+}
+
+// Delete the event rule with the given name.  Returns false if there is
+// none.
+func (d *hmsdbPg) DeleteEventRule(name string) (bool, error) {
+	t, err := d.Begin()
+	if err != nil {
+		return false, err
+	}
+	didDelete, err := t.DeleteEventRuleTx(name)
+	if err != nil {
+		t.Rollback()
+		return false, err
+	}
+	err = t.Commit()
+	return didDelete, err
+}
+
+////////////////////////////////////////////////////////////////////////////
+//
+// Redfish Event Log - Processed events received from BMCs
+//
+////////////////////////////////////////////////////////////////////////////
+
+// Add entries to the Redfish event log.  IDs and receive timestamps are
+// assigned by the database.
+func (d *hmsdbPg) InsertRFEventLogEntries(entries []*sm.RFEventLogEntry) error {
+	t, err := d.Begin()
+	if err != nil {
+		return err
+	}
+	err = t.InsertRFEventLogEntriesTx(entries)
+	if err != nil {
 		t.Rollback()
 		return err
 	}
 	return t.Commit()
 }
 
-// Get Group with given label.  Nil if not found and nil error, otherwise
-// nil plus non-nil error (not normally expected)
-// If filt_part is non-empty, the partition name is used to filter
-// the members list.
-func (d *hmsdbPg) GetGroup(label, filt_part string) (*sm.Group, error) {
+// Get Redfish event log entries, most recent first, matching the filter
+// options.
+func (d *hmsdbPg) GetRFEventLogFilter(f_opts ...RFEventLogFiltFunc) ([]*sm.RFEventLogEntry, error) {
 	t, err := d.Begin()
 	if err != nil {
 		return nil, err
 	}
-	uuid, g, err := t.GetEmptyGroupTx(label)
+	entries, err := t.GetRFEventLogFilterTx(f_opts...)
 	if err != nil {
 		t.Rollback()
 		return nil, err
 	}
-	not_uuid := ""
-	null_part := false
-	if filt_part == "NULL" {
-		// Don't need to look up and_part, we want group members w NO partition
-		null_part = true
-	} else if filt_part != "" {
-		not_uuid, _, err = t.GetEmptyPartitionTx(filt_part)
-		if err != nil {
-			t.Rollback()
+	err = t.Commit()
+	return entries, err
+}
+
+// Delete Redfish event log entries received before the given time, and the
+// oldest ones beyond maxEntries (if maxEntries > 0).  Returns the number
+// deleted.
+func (d *hmsdbPg) PruneRFEventLog(before time.Time, maxEntries int) (int64, error) {
+	t, err := d.Begin()
+	if err != nil {
+		return 0, err
+	}
+	numDeleted, err := t.PruneRFEventLogTx(before, maxEntries)
+	if err != nil {
+		t.Rollback()
+		return 0, err
+	}
+	err = t.Commit()
+	return numDeleted, err
+}
+
+////////////////////////////////////////////////////////////////////////////
+//
+// Group and Partition  Management
+//
+////////////////////////////////////////////////////////////////////////////
+
+//
+// Groups
+//
+
+// Create a group.  Returns new label (should match one in struct,
+// unless case-normalized) if successful, otherwise empty string + non
+// nil error. Will return ErrHMSDSDuplicateKey if group exits or is
+// exclusive and xname id is already in another group in this exclusive set.
+// In addition, returns ErrHMSDSNoComponent if a component id doesn't exist.
+func (d *hmsdbPg) InsertGroup(g *sm.Group, requester string) (string, error) {
+	t, err := d.Begin()
+	if err != nil {
+		return "", err
+	}
+	if err := t.SetRequesterTx(requester); err != nil {
+		t.Rollback()
+		return "", err
+	}
+	// Insert first the group, with no members.
+	// Note this also normalizes and verifies data - exgroup won't contain '%'
+	uuid, label, exgrp, err := t.InsertEmptyGroupTx(g)
+	if err != nil {
+		t.Rollback()
+		return "", err
+	}
+	namespace := label // Normal namespace is non-exclusive group name
+	if exgrp != "" {
+		// exclusive group - uniquified exclusive group as namespace
+		namespace = "%" + exgrp + "%"
+	}
+	err = t.InsertMembersTx(uuid, namespace, &g.Members)
+	if err != nil {
+		t.Rollback()
+		return "", err
+	}
+	err = t.InsertGroupIncludesTx(uuid, g.Groups)
+	if err != nil {
+		t.Rollback()
+		return "", err
+	}
+	err = t.Commit()
+	return label, err
+}
+
+// Update group with label
+func (d *hmsdbPg) UpdateGroup(label string, gp *sm.GroupPatch, requester string) error {
+	gp.Normalize()
+	if err := gp.Verify(); err != nil {
+		return err
+	}
+	// Start the transaction
+	t, err := d.Begin()
+	if err != nil {
+		return err
+	}
+	if err := t.SetRequesterTx(requester); err != nil {
+		t.Rollback()
+		return err
+	}
+	// Get the existing partition in a transaction, without members initially.
+	uuid, g, err := t.GetEmptyGroupTx(label)
+	if err != nil {
+		// Unexpected error - couldn't get partition
+		t.Rollback()
+		return err
+	} else if g == nil || uuid == "" {
+		// Lookup returned nothing - 404
+		t.Rollback()
+		return ErrHMSDSNoGroup
+	}
+	if err := checkGroupVersionTx(t, uuid, gp.Version); err != nil {
+		t.Rollback()
+		return err
+	}
+	if err := t.UpdateEmptyGroupTx(uuid, g, gp); err != nil {
+		t.Rollback()
+		return err
+	}
+	if gp.Groups != nil {
+		// Replace the group's includes
+		if len(*gp.Groups) > 0 && (g.IsDynamic() || g.ExclusiveGroup != "") {
+			t.Rollback()
+			return ErrHMSDSGroupIncludes
+		}
+		if err := t.DeleteGroupIncludesTx(uuid); err != nil {
+			t.Rollback()
+			return err
+		}
+		if err := t.InsertGroupIncludesTx(uuid, *gp.Groups); err != nil {
+			t.Rollback()
+			return err
+		}
+	}
+	return t.Commit()
+}
+
+// Get Group with given label.  Nil if not found and nil error, otherwise
+// nil plus non-nil error (not normally expected)
+// If filt_part is non-empty, the partition name is used to filter
+// the members list.
+func (d *hmsdbPg) GetGroup(label, filt_part string) (*sm.Group, error) {
+	t, err := d.Begin()
+	if err != nil {
+		return nil, err
+	}
+	uuid, g, err := t.GetEmptyGroupTx(label)
+	if err != nil {
+		t.Rollback()
+		return nil, err
+	}
+	not_uuid := ""
+	null_part := false
+	if filt_part == "NULL" {
+		// Don't need to look up and_part, we want group members w NO partition
+		null_part = true
+	} else if filt_part != "" {
+		not_uuid, _, err = t.GetEmptyPartitionTx(filt_part)
+		if err != nil {
+			t.Rollback()
 			return nil, err
 		} else if not_uuid == "" {
 			t.Rollback()
 			return nil, ErrHMSDSNoPartition
 		}
 	}
+	// The member filter below doesn't know that a partition's members
+	// include those of its sub-partitions, but the component filter does.
+	subParts := []string{}
+	if not_uuid != "" {
+		subParts, err = t.GetSubPartitionIDsTx(filt_part)
+		if err != nil {
+			t.Rollback()
+			return nil, err
+		}
+	}
 	// Get Members
-	if g != nil && uuid != "" {
+	if (g.IsDynamic() || len(g.Groups) > 0 || len(subParts) > 0) && uuid != "" {
+		// Evaluate the group's query and/or includes for its current
+		// effective members.
+		f := &ComponentFilter{
+			Group: []string{g.Label},
+			label: "GetGroup",
+		}
+		if filt_part != "" {
+			f.Partition = []string{filt_part}
+		}
+		comps, err := t.GetComponentsFilterTx(f, FLTR_ID_ONLY)
+		if err != nil {
+			t.Rollback()
+			return nil, err
+		}
+		g.Members.IDs = make([]string, 0, len(comps))
+		for _, comp := range comps {
+			g.Members.IDs = append(g.Members.IDs, comp.ID)
+		}
+	} else if g != nil && uuid != "" {
 		if not_uuid == "" && null_part == false {
 			// Just get group members
 			ms, err := t.GetMembersTx(uuid)
@@ -3763,31 +4544,31 @@ func (d *hmsdbPg) GetGroupLabels() ([]string, error) {
 
 // Delete entire group with the given label.  If no error, bool indicates
 // whether member was present to remove.
-func (d *hmsdbPg) DeleteGroup(label string) (bool, error) {
-	// Build query
-	query := sq.Delete(compGroupsTable).
-		Where("name = ?", sm.NormalizeGroupField(label)).
-		Where("namespace = ?", groupNamespace)
-
-	// Execute delete query
-	query = query.PlaceholderFormat(sq.Dollar)
-	res, err := query.RunWith(d.sc).ExecContext(d.ctx)
+func (d *hmsdbPg) DeleteGroup(label, requester string) (bool, error) {
+	t, err := d.Begin()
 	if err != nil {
 		return false, err
 	}
-	// See if the rows were affected.
-	num, err := res.RowsAffected()
+	uuid, g, err := t.GetEmptyGroupTx(label)
 	if err != nil {
+		t.Rollback()
 		return false, err
-	} else {
-		if num > 0 {
-			if num > 1 {
-				d.LogAlways("Error: DeleteGroup(): multiple deletions!")
-			}
-			return true, nil
-		}
+	} else if g == nil || uuid == "" {
+		// Nothing to delete
+		t.Rollback()
+		return false, nil
 	}
-	return false, nil
+	if err := t.SetRequesterTx(requester); err != nil {
+		t.Rollback()
+		return false, err
+	}
+	didDelete, err := t.DeleteGroupTx(uuid)
+	if err != nil {
+		t.Rollback()
+		return false, err
+	}
+	err = t.Commit()
+	return didDelete, err
 }
 
 // Add member xname id to existing group label.  returns ErrHMSDSNoGroup
@@ -3797,7 +4578,7 @@ func (d *hmsdbPg) DeleteGroup(label string) (bool, error) {
 //
 // Returns key of new member id, should be same as id after normalization,
 // if any.  Label should already be normalized.
-func (d *hmsdbPg) AddGroupMember(label, id string) (string, error) {
+func (d *hmsdbPg) AddGroupMember(label, id, requester string) (string, error) {
 	// Prep id for insertion and verify it.
 	ms := new(sm.Members)
 	ms.IDs = append(ms.IDs, id)
@@ -3810,6 +4591,10 @@ func (d *hmsdbPg) AddGroupMember(label, id string) (string, error) {
 	if err != nil {
 		return "", err
 	}
+	if err := t.SetRequesterTx(requester); err != nil {
+		t.Rollback()
+		return "", err
+	}
 	// First we need to look up the group, if it exists.
 	uuid, g, err := t.GetEmptyGroupTx(label)
 	if err != nil {
@@ -3819,6 +4604,9 @@ func (d *hmsdbPg) AddGroupMember(label, id string) (string, error) {
 		// Group does not exist
 		t.Rollback()
 		return "", ErrHMSDSNoGroup
+	} else if g.IsDynamic() {
+		t.Rollback()
+		return "", ErrHMSDSDynamicGroup
 	}
 	// Default namespace is non-exclusive group name
 	namespace := g.Label
@@ -3838,12 +4626,16 @@ func (d *hmsdbPg) AddGroupMember(label, id string) (string, error) {
 
 // Delete Group member from label.  If no error, bool indicates whether
 // group was present to remove.
-func (d *hmsdbPg) DeleteGroupMember(label, id string) (bool, error) {
+func (d *hmsdbPg) DeleteGroupMember(label, id, requester string) (bool, error) {
 	// Start transaction, first we need to look up the group, if it exists.
 	t, err := d.Begin()
 	if err != nil {
 		return false, err
 	}
+	if err := t.SetRequesterTx(requester); err != nil {
+		t.Rollback()
+		return false, err
+	}
 	uuid, g, err := t.GetEmptyGroupTx(label)
 	if err != nil {
 		t.Rollback()
@@ -3852,6 +4644,9 @@ func (d *hmsdbPg) DeleteGroupMember(label, id string) (bool, error) {
 		// Group does not exist
 		t.Rollback()
 		return false, ErrHMSDSNoGroup
+	} else if g.IsDynamic() {
+		t.Rollback()
+		return false, ErrHMSDSDynamicGroup
 	}
 	didDelete, err := t.DeleteMemberTx(uuid, id)
 	if err != nil {
@@ -3862,6 +4657,57 @@ func (d *hmsdbPg) DeleteGroupMember(label, id string) (bool, error) {
 	return didDelete, err
 }
 
+// Replace the whole member list of group label with ms, in a single
+// transaction.  Errors are as for AddGroupMember, in which case nothing
+// is changed.  If version is non-zero and the group is no longer at
+// that version, ErrHMSDSVersionMismatch is returned.  Returns the
+// group's new version.
+func (d *hmsdbPg) ReplaceGroupMembers(
+	label string,
+	ms *sm.Members,
+	version int64,
+	requester string,
+) (int64, error) {
+	ms, err := uniqueMembers(ms)
+	if err != nil {
+		return 0, err
+	}
+	// Start transaction, first we need to look up the group, if it exists.
+	t, err := d.Begin()
+	if err != nil {
+		return 0, err
+	}
+	if err := t.SetRequesterTx(requester); err != nil {
+		t.Rollback()
+		return 0, err
+	}
+	uuid, g, err := t.GetEmptyGroupTx(label)
+	if err != nil {
+		t.Rollback()
+		return 0, err
+	} else if g == nil || uuid == "" {
+		// Group does not exist
+		t.Rollback()
+		return 0, ErrHMSDSNoGroup
+	} else if g.IsDynamic() {
+		t.Rollback()
+		return 0, ErrHMSDSDynamicGroup
+	}
+	// Default namespace is non-exclusive group name
+	namespace := g.Label
+	if g.ExclusiveGroup != "" {
+		// exclusive group - uniquified exclusive group as namespace
+		namespace = "%" + g.ExclusiveGroup + "%"
+	}
+	newVersion, err := replacePgMembersTx(t, uuid, namespace, ms, version)
+	if err != nil {
+		t.Rollback()
+		return 0, err
+	}
+	err = t.Commit()
+	return newVersion, err
+}
+
 //
 // Partitions
 //
@@ -3871,11 +4717,18 @@ func (d *hmsdbPg) DeleteGroupMember(label, id string) (bool, error) {
 // nil error.  Will return ErrHMSDSDuplicateKey if partition exits or an
 // xname id already exists in another partition.
 // In addition, returns ErrHMSDSNoComponent if a component doesn't exist.
-func (d *hmsdbPg) InsertPartition(p *sm.Partition) (string, error) {
+// The members of a sub-partition, e.g. p1.2, are moved out of its parent,
+// which must exist and have them (ErrHMSDSNoParentPartition or
+// ErrHMSDSNotInParentPartition otherwise).
+func (d *hmsdbPg) InsertPartition(p *sm.Partition, requester string) (string, error) {
 	t, err := d.Begin()
 	if err != nil {
 		return "", err
 	}
+	if err := t.SetRequesterTx(requester); err != nil {
+		t.Rollback()
+		return "", err
+	}
 	// Insert first the partition, with no members, after
 	// verifying/normalizing.
 	uuid, pname, err := t.InsertEmptyPartitionTx(p)
@@ -3883,6 +4736,13 @@ func (d *hmsdbPg) InsertPartition(p *sm.Partition) (string, error) {
 		t.Rollback()
 		return "", err
 	}
+	if parent := sm.PartitionParent(pname); parent != "" {
+		err = takeFromParentPartTx(t, parent, p.Members.IDs)
+		if err != nil {
+			t.Rollback()
+			return "", err
+		}
+	}
 	// special unique namespace for partitions - can't clash with due to
 	// normally disallowed '%' characters.  These were checked in the last
 	// call.
@@ -3897,7 +4757,7 @@ func (d *hmsdbPg) InsertPartition(p *sm.Partition) (string, error) {
 }
 
 // Update Partition with given name
-func (d *hmsdbPg) UpdatePartition(pname string, pp *sm.PartitionPatch) error {
+func (d *hmsdbPg) UpdatePartition(pname string, pp *sm.PartitionPatch, requester string) error {
 	// Check input before starting any DB actions
 	pp.Normalize()
 	if err := pp.Verify(); err != nil {
@@ -3908,6 +4768,10 @@ func (d *hmsdbPg) UpdatePartition(pname string, pp *sm.PartitionPatch) error {
 	if err != nil {
 		return err
 	}
+	if err := t.SetRequesterTx(requester); err != nil {
+		t.Rollback()
+		return err
+	}
 	// Get the existing partition in a transaction, without members initially.
 	uuid, p, err := t.GetEmptyPartitionTx(pname)
 	if err != nil {
@@ -3919,6 +4783,10 @@ func (d *hmsdbPg) UpdatePartition(pname string, pp *sm.PartitionPatch) error {
 		t.Rollback()
 		return ErrHMSDSNoPartition
 	}
+	if err := checkGroupVersionTx(t, uuid, pp.Version); err != nil {
+		t.Rollback()
+		return err
+	}
 	if err := t.UpdateEmptyPartitionTx(uuid, p, pp); err != nil {
 		t.Rollback()
 		return err
@@ -3927,7 +4795,8 @@ func (d *hmsdbPg) UpdatePartition(pname string, pp *sm.PartitionPatch) error {
 }
 
 // Get partition with given name  Nil if not found and nil error, otherwise
-// nil plus non-nil error (not normally expected)
+// nil plus non-nil error (not normally expected).  The members of a
+// top-level partition include those of its sub-partitions.
 func (d *hmsdbPg) GetPartition(pname string) (*sm.Partition, error) {
 	t, err := d.Begin()
 	if err != nil {
@@ -3940,135 +4809,776 @@ func (d *hmsdbPg) GetPartition(pname string) (*sm.Partition, error) {
 	} else if p != nil && uuid != "" {
 		ms, err := t.GetMembersTx(uuid)
 		if err != nil {
-			t.Rollback()
-			return nil, err
+			t.Rollback()
+			return nil, err
+		}
+		p.Members.IDs = ms.IDs
+		subParts, err := t.GetSubPartitionIDsTx(p.Name)
+		if err != nil {
+			t.Rollback()
+			return nil, err
+		}
+		for _, subUUID := range subParts {
+			ms, err := t.GetMembersTx(subUUID)
+			if err != nil {
+				t.Rollback()
+				return nil, err
+			}
+			p.Members.IDs = append(p.Members.IDs, ms.IDs...)
+		}
+	}
+	t.Commit()
+	return p, err
+}
+
+// Get list of partition names.
+func (d *hmsdbPg) GetPartitionNames() ([]string, error) {
+	query := sq.Select("name").
+		From(compGroupsTable).
+		Where("namespace = ?", partNamespace)
+
+	// Query with statement cache for caching prepared statements (local to tx)
+	query = query.PlaceholderFormat(sq.Dollar)
+	rows, err := query.RunWith(d.sc).QueryContext(d.ctx)
+	if err != nil {
+		d.LogAlways("Error: GetPartitionNames(): query failed: %s", err)
+		return []string{}, err
+	}
+	defer rows.Close()
+
+	pnames := []string{}
+	for rows.Next() {
+		var pname string
+		if err := rows.Scan(&pname); err != nil {
+			d.LogAlways("Error: GetPartitionNames(): scan failed: %s", err)
+			return []string{}, err
+		}
+		pnames = append(pnames, pname)
+	}
+	return pnames, nil
+}
+
+// Delete entire partition with pname.  If no error, bool indicates
+// whether partition was present to remove.  A partition with
+// sub-partitions can't be deleted (ErrHMSDSPartitionHasChildren), and the
+// members of a deleted sub-partition go back to its parent.
+func (d *hmsdbPg) DeletePartition(pname, requester string) (bool, error) {
+	t, err := d.Begin()
+	if err != nil {
+		return false, err
+	}
+	uuid, p, err := t.GetEmptyPartitionTx(pname)
+	if err != nil {
+		t.Rollback()
+		return false, err
+	} else if p == nil || uuid == "" {
+		// Nothing to delete
+		t.Rollback()
+		return false, nil
+	}
+	if err := t.SetRequesterTx(requester); err != nil {
+		t.Rollback()
+		return false, err
+	}
+	subParts, err := t.GetSubPartitionIDsTx(p.Name)
+	if err != nil {
+		t.Rollback()
+		return false, err
+	} else if len(subParts) > 0 {
+		t.Rollback()
+		return false, ErrHMSDSPartitionHasChildren
+	}
+	ms := sm.NewMembers()
+	parent := sm.PartitionParent(p.Name)
+	if parent != "" {
+		if ms, err = t.GetMembersTx(uuid); err != nil {
+			t.Rollback()
+			return false, err
+		}
+	}
+	didDelete, err := t.DeleteGroupTx(uuid)
+	if err != nil {
+		t.Rollback()
+		return false, err
+	}
+	if parent != "" {
+		if err = returnToParentPartTx(t, parent, ms.IDs); err != nil {
+			t.Rollback()
+			return false, err
+		}
+	}
+	err = t.Commit()
+	return didDelete, err
+}
+
+// Add member xname id to existing partition.  returns ErrHMSDSNoGroup
+// if partition name does not exist, or ErrHMSDSDuplicateKey if xname id
+// is already in a different partition.  Members added to a sub-partition
+// are moved out of its parent, see InsertPartition.
+// Returns key of new member, should be same as id after normalization,
+// if any.  pname should already be normalized.
+func (d *hmsdbPg) AddPartitionMember(pname, id, requester string) (string, error) {
+	// Prep id for insertion and verify it.
+	ms := new(sm.Members)
+	ms.IDs = append(ms.IDs, id)
+	ms.Normalize()
+	if err := ms.Verify(); err != nil {
+		return "", err
+	}
+	// Start transaction, first we need to look up the part name, if it exists.
+	t, err := d.Begin()
+	if err != nil {
+		return "", err
+	}
+	if err := t.SetRequesterTx(requester); err != nil {
+		t.Rollback()
+		return "", err
+	}
+	uuid, p, err := t.GetEmptyPartitionTx(pname)
+	if err != nil {
+		t.Rollback()
+		return "", err
+	} else if p == nil || uuid == "" {
+		// Partition does not exist
+		t.Rollback()
+		return "", ErrHMSDSNoPartition
+	}
+	if parent := sm.PartitionParent(p.Name); parent != "" {
+		if err = takeFromParentPartTx(t, parent, ms.IDs); err != nil {
+			t.Rollback()
+			return "", err
+		}
+	}
+	// special unique namespace for partitions - can't clash with due to
+	// normally disallowed '%' characters.  These were checked in the last
+	// call.
+	namespace := partGroupNamespace
+	err = t.InsertMembersTx(uuid, namespace, ms)
+	if err != nil {
+		t.Rollback()
+		return "", err
+	}
+	err = t.Commit()
+	return ms.IDs[0], err
+}
+
+// Delete partition member from partition.  If no error, bool indicates
+// whether member was present to remove.  Members removed from a
+// sub-partition go back to its parent, and removing a member of a
+// sub-partition from the parent removes it from both.
+func (d *hmsdbPg) DeletePartitionMember(pname, id, requester string) (bool, error) {
+	// Start transaction, first we need to look up the group, if it exists.
+	t, err := d.Begin()
+	if err != nil {
+		return false, err
+	}
+	if err := t.SetRequesterTx(requester); err != nil {
+		t.Rollback()
+		return false, err
+	}
+	uuid, p, err := t.GetEmptyPartitionTx(pname)
+	if err != nil {
+		t.Rollback()
+		return false, err
+	} else if p == nil || uuid == "" {
+		// Partition does not exist
+		t.Rollback()
+		return false, ErrHMSDSNoPartition
+	}
+	didDelete, err := t.DeleteMemberTx(uuid, id)
+	if err != nil {
+		t.Rollback()
+		return false, err
+	}
+	if parent := sm.PartitionParent(p.Name); parent != "" && didDelete {
+		err = returnToParentPartTx(t, parent, []string{id})
+		if err != nil {
+			t.Rollback()
+			return false, err
+		}
+	} else if !didDelete {
+		subParts, err := t.GetSubPartitionIDsTx(p.Name)
+		if err != nil {
+			t.Rollback()
+			return false, err
+		}
+		for _, subUUID := range subParts {
+			didDelete, err = t.DeleteMemberTx(subUUID, id)
+			if err != nil {
+				t.Rollback()
+				return false, err
+			} else if didDelete {
+				break
+			}
+		}
+	}
+	err = t.Commit()
+	return didDelete, err
+}
+
+// Replace the whole member list of partition pname with ms, in a single
+// transaction.  Errors are as for AddPartitionMember, in which case
+// nothing is changed.  If version is non-zero and the partition is no
+// longer at that version, ErrHMSDSVersionMismatch is returned.  Returns
+// the partition's new version.  As with adding and removing members one
+// at a time, a sub-partition takes its new members from its parent and
+// gives back the old ones.  For a top-level partition, members of its
+// sub-partitions that are in ms stay where they are.
+func (d *hmsdbPg) ReplacePartitionMembers(
+	pname string,
+	ms *sm.Members,
+	version int64,
+	requester string,
+) (int64, error) {
+	ms, err := uniqueMembers(ms)
+	if err != nil {
+		return 0, err
+	}
+	// Start transaction, first we need to look up the partition, if it exists.
+	t, err := d.Begin()
+	if err != nil {
+		return 0, err
+	}
+	if err := t.SetRequesterTx(requester); err != nil {
+		t.Rollback()
+		return 0, err
+	}
+	uuid, p, err := t.GetEmptyPartitionTx(pname)
+	if err != nil {
+		t.Rollback()
+		return 0, err
+	} else if p == nil || uuid == "" {
+		// Partition does not exist
+		t.Rollback()
+		return 0, ErrHMSDSNoPartition
+	}
+	if err := checkGroupVersionTx(t, uuid, version); err != nil {
+		t.Rollback()
+		return 0, err
+	}
+	var newVersion int64
+	if parent := sm.PartitionParent(p.Name); parent != "" {
+		newVersion, err = replacePgSubPartMembersTx(t, uuid, parent, ms)
+	} else {
+		newVersion, err = replacePgTopPartMembersTx(t, uuid, p.Name, ms)
+	}
+	if err != nil {
+		t.Rollback()
+		return 0, err
+	}
+	err = t.Commit()
+	return newVersion, err
+}
+
+// Move the members in pm from sub-partition pm.Source to its sibling
+// pname, e.g. from p1.2 to p1.3, in a single transaction.  Returns
+// ErrHMSDSNotSiblingPartitions if they don't have the same parent,
+// ErrHMSDSNoPartition if either doesn't exist, or
+// ErrHMSDSNotInSourcePartition if any of the members aren't in the source,
+// in which case nothing is changed.
+func (d *hmsdbPg) MovePartitionMembers(
+	pname string,
+	pm *sm.PartitionMove,
+	requester string,
+) error {
+	pm.Normalize()
+	if err := pm.Verify(); err != nil {
+		return err
+	}
+	pname = sm.NormalizeGroupField(pname)
+	parent := sm.PartitionParent(pname)
+	if parent == "" || parent != sm.PartitionParent(pm.Source) ||
+		pname == pm.Source {
+		return ErrHMSDSNotSiblingPartitions
+	}
+	ms, err := uniqueMembers(&sm.Members{IDs: pm.IDs})
+	if err != nil {
+		return err
+	}
+	t, err := d.Begin()
+	if err != nil {
+		return err
+	}
+	if err := t.SetRequesterTx(requester); err != nil {
+		t.Rollback()
+		return err
+	}
+	srcUUID, src, err := t.GetEmptyPartitionTx(pm.Source)
+	if err != nil {
+		t.Rollback()
+		return err
+	} else if src == nil || srcUUID == "" {
+		t.Rollback()
+		return ErrHMSDSNoPartition
+	}
+	dstUUID, dst, err := t.GetEmptyPartitionTx(pname)
+	if err != nil {
+		t.Rollback()
+		return err
+	} else if dst == nil || dstUUID == "" {
+		t.Rollback()
+		return ErrHMSDSNoPartition
+	}
+	cur, err := t.GetMembersTx(srcUUID)
+	if err != nil {
+		t.Rollback()
+		return err
+	}
+	if !hasAllMembers(cur, ms.IDs) {
+		t.Rollback()
+		return ErrHMSDSNotInSourcePartition
+	}
+	if err := t.DeleteMembersTx(srcUUID, ms.IDs); err != nil {
+		t.Rollback()
+		return err
+	}
+	if err := t.InsertMembersTx(dstUUID, partGroupNamespace, ms); err != nil {
+		t.Rollback()
+		return err
+	}
+	return t.Commit()
+}
+
+// Replace the members of sub-partition uuid with ms, taking the new ones
+// from its parent partition and giving back the old ones.  Returns the
+// new version.
+func replacePgSubPartMembersTx(
+	t HMSDBTx,
+	uuid, parent string,
+	ms *sm.Members,
+) (int64, error) {
+	cur, err := t.GetMembersTx(uuid)
+	if err != nil {
+		return 0, err
+	}
+	curMap := make(map[string]bool, len(cur.IDs))
+	for _, id := range cur.IDs {
+		curMap[id] = true
+	}
+	keep := make(map[string]bool, len(ms.IDs))
+	added := []string{}
+	for _, id := range ms.IDs {
+		keep[id] = true
+		if !curMap[id] {
+			added = append(added, id)
+		}
+	}
+	removed := []string{}
+	for _, id := range cur.IDs {
+		if !keep[id] {
+			removed = append(removed, id)
+		}
+	}
+	if err := takeFromParentPartTx(t, parent, added); err != nil {
+		return 0, err
+	}
+	newVersion, err := replacePgMembersTx(t, uuid, partGroupNamespace, ms, 0)
+	if err != nil {
+		return 0, err
+	}
+	if err := returnToParentPartTx(t, parent, removed); err != nil {
+		return 0, err
+	}
+	return newVersion, nil
+}
+
+// Replace the members of top-level partition uuid, called pname, with ms.
+// Members of its sub-partitions that are in ms stay in them and the rest
+// are removed.  Returns the new version.
+func replacePgTopPartMembersTx(
+	t HMSDBTx,
+	uuid, pname string,
+	ms *sm.Members,
+) (int64, error) {
+	subParts, err := t.GetSubPartitionIDsTx(pname)
+	if err != nil {
+		return 0, err
+	}
+	keep := make(map[string]bool, len(ms.IDs))
+	for _, id := range ms.IDs {
+		keep[id] = true
+	}
+	for _, subUUID := range subParts {
+		subMs, err := t.GetMembersTx(subUUID)
+		if err != nil {
+			return 0, err
+		}
+		removed := []string{}
+		for _, id := range subMs.IDs {
+			if keep[id] {
+				// Already in the partition, via the sub-partition.
+				delete(keep, id)
+			} else {
+				removed = append(removed, id)
+			}
+		}
+		if err := t.DeleteMembersTx(subUUID, removed); err != nil {
+			return 0, err
+		}
+	}
+	direct := sm.NewMembers()
+	for _, id := range ms.IDs {
+		if keep[id] {
+			direct.IDs = append(direct.IDs, id)
+		}
+	}
+	return replacePgMembersTx(t, uuid, partGroupNamespace, direct, 0)
+}
+
+// Take ids out of the members of parent partition pname, so they can be
+// added to one of its sub-partitions.  Returns ErrHMSDSNoParentPartition
+// if there is no such parent, or ErrHMSDSNotInParentPartition if any of
+// them aren't in it (directly, i.e. not in another sub-partition).
+func takeFromParentPartTx(t HMSDBTx, pname string, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	uuid, p, err := t.GetEmptyPartitionTx(pname)
+	if err != nil {
+		return err
+	} else if p == nil || uuid == "" {
+		return ErrHMSDSNoParentPartition
+	}
+	cur, err := t.GetMembersTx(uuid)
+	if err != nil {
+		return err
+	}
+	if !hasAllMembers(cur, ids) {
+		return ErrHMSDSNotInParentPartition
+	}
+	return t.DeleteMembersTx(uuid, ids)
+}
+
+// Give ids, taken out of a sub-partition, back to its parent partition
+// pname.
+func returnToParentPartTx(t HMSDBTx, pname string, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	uuid, p, err := t.GetEmptyPartitionTx(pname)
+	if err != nil {
+		return err
+	} else if p == nil || uuid == "" {
+		return ErrHMSDSNoParentPartition
+	}
+	ms := sm.NewMembers()
+	ms.IDs = append(ms.IDs, ids...)
+	return t.InsertMembersTx(uuid, partGroupNamespace, ms)
+}
+
+// True if all of ids are in ms.
+func hasAllMembers(ms *sm.Members, ids []string) bool {
+	in := make(map[string]bool, len(ms.IDs))
+	for _, id := range ms.IDs {
+		in[id] = true
+	}
+	for _, id := range ids {
+		if !in[id] {
+			return false
+		}
+	}
+	return true
+}
+
+// Lock the group or partition with the given uuid for the rest of the
+// transaction and return ErrHMSDSVersionMismatch if it is no longer at
+// version.  A zero version matches any.
+func checkGroupVersionTx(t HMSDBTx, uuid string, version int64) error {
+	if version == 0 {
+		return nil
+	}
+	cur, err := t.GetGroupVersionTx(uuid)
+	if err != nil {
+		return err
+	}
+	if cur != version {
+		return ErrHMSDSVersionMismatch
+	}
+	return nil
+}
+
+// Swap out all members of the group or partition with the given uuid for
+// ms, in namespace (see InsertMembersTx), after checking its version.
+// Only members that are actually added or removed are touched, so the
+// history doesn't show unchanged ones leaving and rejoining.  The unique
+// constraint on the members table rejects any that would overlap another
+// exclusive group or partition, in which case the caller should roll back.
+// Returns the new version.
+func replacePgMembersTx(
+	t HMSDBTx,
+	uuid, namespace string,
+	ms *sm.Members,
+	version int64,
+) (int64, error) {
+	if err := checkGroupVersionTx(t, uuid, version); err != nil {
+		return 0, err
+	}
+	cur, err := t.GetMembersTx(uuid)
+	if err != nil {
+		return 0, err
+	}
+	keep := make(map[string]bool, len(ms.IDs))
+	for _, id := range ms.IDs {
+		keep[id] = true
+	}
+	removed := []string{}
+	for _, id := range cur.IDs {
+		if keep[id] {
+			delete(keep, id)
+		} else {
+			removed = append(removed, id)
+		}
+	}
+	added := sm.NewMembers()
+	for _, id := range ms.IDs {
+		if keep[id] {
+			added.IDs = append(added.IDs, id)
+		}
+	}
+	if err := t.DeleteMembersTx(uuid, removed); err != nil {
+		return 0, err
+	}
+	if err := t.InsertMembersTx(uuid, namespace, added); err != nil {
+		return 0, err
+	}
+	// The version is bumped by the database when the members change.
+	return t.GetGroupVersionTx(uuid)
+}
+
+// Normalize and verify a member list, dropping any duplicate xnames so
+// they don't trip the unique constraint.  A nil list is an empty one.
+func uniqueMembers(ms *sm.Members) (*sm.Members, error) {
+	out := sm.NewMembers()
+	if ms == nil {
+		return out, nil
+	}
+	ms.Normalize()
+	if err := ms.Verify(); err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool, len(ms.IDs))
+	for _, id := range ms.IDs {
+		if !seen[id] {
+			seen[id] = true
+			out.IDs = append(out.IDs, id)
+		}
+	}
+	return out, nil
+}
+
+//
+// History
+//
+
+// Get the recorded changes to the group with the given label, oldest
+// first, matching the filter options.  History is kept after a group
+// is deleted, and a later group with the same label continues it.
+func (d *hmsdbPg) GetGroupHistory(
+	label string,
+	f_opts ...GroupHistFiltFunc,
+) ([]*sm.GroupHistoryEntry, error) {
+	return d.getGroupHistory(groupNamespace, label, f_opts...)
+}
+
+// Get the recorded changes to the partition with the given name, oldest
+// first, matching the filter options.
+func (d *hmsdbPg) GetPartitionHistory(
+	pname string,
+	f_opts ...GroupHistFiltFunc,
+) ([]*sm.GroupHistoryEntry, error) {
+	return d.getGroupHistory(partNamespace, pname, f_opts...)
+}
+
+// Get the history of the group or partition called name in namespace.
+func (d *hmsdbPg) getGroupHistory(
+	namespace, name string,
+	f_opts ...GroupHistFiltFunc,
+) ([]*sm.GroupHistoryEntry, error) {
+	// Parse the filter options
+	f := new(GroupHistFilter)
+	for _, opts := range f_opts {
+		opts(f)
+	}
+	query := sq.Select(compGroupHistColsSM...).
+		From(compGroupHistTable).
+		Where(compGroupHistNamespaceCol+" = ?", namespace).
+		Where(compGroupHistNameCol+" = ?", sm.NormalizeGroupField(name))
+	if len(f.Member) > 0 {
+		members := []string{}
+		for _, m := range f.Member {
+			// Member is an xname or, for includes, a group label.
+			if id := xnametypes.VerifyNormalizeCompID(m); id != "" {
+				members = append(members, id)
+			} else {
+				members = append(members, sm.NormalizeGroupField(m))
+			}
+		}
+		query = query.Where(sq.Eq{compGroupHistMemberCol: members})
+	}
+	if len(f.Op) > 0 {
+		ops := []string{}
+		for _, op := range f.Op {
+			normOp := sm.VerifyNormalizeGroupHistOp(op)
+			if normOp == "" {
+				return nil, ErrHMSDSArgBadArg
+			}
+			ops = append(ops, normOp)
+		}
+		query = query.Where(sq.Eq{compGroupHistOpCol: ops})
+	}
+	if len(f.Requester) > 0 {
+		query = query.Where(sq.Eq{compGroupHistRequesterCol: f.Requester})
+	}
+	if f.Since != "" {
+		since, err := time.Parse(time.RFC3339, f.Since)
+		if err != nil {
+			return nil, ErrHMSDSArgBadTimeFormat
+		}
+		query = query.Where(sq.GtOrEq{compGroupHistTimestampCol: since})
+	}
+	if f.Until != "" {
+		until, err := time.Parse(time.RFC3339, f.Until)
+		if err != nil {
+			return nil, ErrHMSDSArgBadTimeFormat
 		}
-		p.Members.IDs = ms.IDs
+		query = query.Where(sq.LtOrEq{compGroupHistTimestampCol: until})
 	}
-	t.Commit()
-	return p, err
-}
+	query = query.OrderBy(compGroupHistIdCol + " ASC")
 
-// Get list of partition names.
-func (d *hmsdbPg) GetPartitionNames() ([]string, error) {
-	query := sq.Select("name").
-		From(compGroupsTable).
-		Where("namespace = ?", partNamespace)
-
-	// Query with statement cache for caching prepared statements (local to tx)
+	// Query with statement cache for caching prepared statements
 	query = query.PlaceholderFormat(sq.Dollar)
+	qStr, qArgs, _ := query.ToSql()
+	d.Log(LOG_DEBUG, "Debug: getGroupHistory(): Query: %s - With args: %v", qStr, qArgs)
 	rows, err := query.RunWith(d.sc).QueryContext(d.ctx)
 	if err != nil {
-		d.LogAlways("Error: GetPartitionNames(): query failed: %s", err)
-		return []string{}, err
+		d.LogAlways("Error: getGroupHistory(%s): query failed: %s", f.label, err)
+		return nil, err
 	}
 	defer rows.Close()
 
-	pnames := []string{}
+	entries := make([]*sm.GroupHistoryEntry, 0, 1)
 	for rows.Next() {
-		var pname string
-		if err := rows.Scan(&pname); err != nil {
-			d.LogAlways("Error: GetPartitionNames(): scan failed: %s", err)
-			return []string{}, err
+		ent, err := d.scanGroupHistoryEntry(rows)
+		if err != nil {
+			d.LogAlways("Error: getGroupHistory(%s): scan failed: %s", f.label, err)
+			return nil, err
 		}
-		pnames = append(pnames, pname)
+		entries = append(entries, ent)
 	}
-	return pnames, nil
+	return entries, rows.Err()
 }
 
-// Delete entire partition with pname.  If no error, bool indicates
-// whether partition was present to remove.
-func (d *hmsdbPg) DeletePartition(pname string) (bool, error) {
-	// Build query
-	query := sq.Delete(compGroupsTable).
-		Where("name = ?", sm.NormalizeGroupField(pname)).
-		Where("namespace = ?", partNamespace)
+//
+// Component Labels
+//
 
-	// Execute
-	query = query.PlaceholderFormat(sq.Dollar)
-	res, err := query.RunWith(d.sc).ExecContext(d.ctx)
+// Get the labels on the component with the given xname id.  Returns nil
+// and a nil error if there is no such component.
+func (d *hmsdbPg) GetCompLabels(id string) (*sm.ComponentLabels, error) {
+	t, err := d.Begin()
 	if err != nil {
-		return false, err
+		return nil, err
 	}
-	// See if any rows were affected
-	num, err := res.RowsAffected()
+	ids, err := t.GetComponentIDsTx(IDs([]string{id}), From("GetCompLabels"))
 	if err != nil {
-		return false, err
-	} else {
-		if num > 0 {
-			if num > 1 {
-				d.LogAlways("Error: DeletePartition(): multiple deletions!")
-			}
-			return true, nil
-		}
+		t.Rollback()
+		return nil, err
+	} else if len(ids) == 0 {
+		t.Rollback()
+		return nil, nil
 	}
-	return false, nil
+	labels, err := t.GetCompLabelsTx(ids[0])
+	if err != nil {
+		t.Rollback()
+		return nil, err
+	}
+	if err := t.Commit(); err != nil {
+		return nil, err
+	}
+	return &sm.ComponentLabels{ID: ids[0], Labels: labels}, nil
 }
 
-// Add member xname id to existing partition.  returns ErrHMSDSNoGroup
-// if partition name does not exist, or ErrHMSDSDuplicateKey if xname id
-// is already in a different partition.
-// Returns key of new member, should be same as id after normalization,
-// if any.  pname should already be normalized.
-func (d *hmsdbPg) AddPartitionMember(pname, id string) (string, error) {
-	// Prep id for insertion and verify it.
-	ms := new(sm.Members)
-	ms.IDs = append(ms.IDs, id)
-	ms.Normalize()
-	if err := ms.Verify(); err != nil {
-		return "", err
+// Replace all of the labels on the component with the given xname id.
+// Returns false if there is no such component.
+func (d *hmsdbPg) ReplaceCompLabels(id string, labels map[string]string) (bool, error) {
+	if err := sm.VerifyLabels(labels); err != nil {
+		return false, err
 	}
-	// Start transaction, first we need to look up the part name, if it exists.
 	t, err := d.Begin()
 	if err != nil {
-		return "", err
+		return false, err
 	}
-	uuid, p, err := t.GetEmptyPartitionTx(pname)
+	ids, err := t.GetComponentIDsTx(IDs([]string{id}),
+		From("ReplaceCompLabels"))
 	if err != nil {
 		t.Rollback()
-		return "", err
-	} else if p == nil || uuid == "" {
-		// Partition does not exist
+		return false, err
+	} else if len(ids) == 0 {
 		t.Rollback()
-		return "", ErrHMSDSNoPartition
+		return false, nil
 	}
-	// special unique namespace for partitions - can't clash with due to
-	// normally disallowed '%' characters.  These were checked in the last
-	// call.
-	namespace := partGroupNamespace
-	err = t.InsertMembersTx(uuid, namespace, ms)
-	if err != nil {
+	if err := t.DeleteCompLabelsTx(ids, nil); err != nil {
 		t.Rollback()
-		return "", err
+		return false, err
 	}
-	err = t.Commit()
-	return ms.IDs[0], err
+	if err := t.SetCompLabelsTx(ids, labels); err != nil {
+		t.Rollback()
+		return false, err
+	}
+	if err := t.Commit(); err != nil {
+		return false, err
+	}
+	return true, nil
 }
 
-// Delete partition member from partition.  If no error, bool indicates
-// whether member was present to remove.
-func (d *hmsdbPg) DeletePartitionMember(pname, id string) (bool, error) {
-	// Start transaction, first we need to look up the group, if it exists.
+// Set, or for nil values remove, the given labels on each of the
+// components with the given xname ids, in a single transaction.  Returns
+// the ids of the components that exist, i.e. were updated.
+func (d *hmsdbPg) PatchCompLabels(ids []string, labels map[string]*string) ([]string, error) {
+	if len(ids) < 1 {
+		d.LogAlways("Error: PatchCompLabels(): id list is empty")
+		return nil, ErrHMSDSArgMissing
+	}
+	setLabels := make(map[string]string)
+	delKeys := []string{}
+	for key, val := range labels {
+		if val == nil {
+			delKeys = append(delKeys, key)
+		} else {
+			setLabels[key] = *val
+		}
+	}
 	t, err := d.Begin()
 	if err != nil {
-		return false, err
+		return []string{}, err
 	}
-	uuid, p, err := t.GetEmptyPartitionTx(pname)
+	// Select the components to update, skipping any that don't exist.
+	affectedIDs, err := t.GetComponentIDsTx(IDs(ids),
+		From("PatchCompLabels"))
 	if err != nil {
 		t.Rollback()
-		return false, err
-	} else if p == nil || uuid == "" {
-		// Partition does not exist
-		t.Rollback()
-		return false, ErrHMSDSNoPartition
+		return []string{}, err
 	}
-	didDelete, err := t.DeleteMemberTx(uuid, id)
-	if err != nil {
-		t.Rollback()
-		return false, err
+	if len(affectedIDs) != 0 {
+		if err := t.DeleteCompLabelsTx(affectedIDs, delKeys); err != nil {
+			t.Rollback()
+			return []string{}, err
+		}
+		if err := t.SetCompLabelsTx(affectedIDs, setLabels); err != nil {
+			t.Rollback()
+			return []string{}, err
+		}
 	}
-	err = t.Commit()
-	return didDelete, err
+	if err := t.Commit(); err != nil {
+		return []string{}, err
+	}
+	return affectedIDs, nil
+}
+
+// Remove all of the labels from the component with the given xname id.
+// Returns false if there is no such component.
+func (d *hmsdbPg) DeleteCompLabels(id string) (bool, error) {
+	return d.ReplaceCompLabels(id, map[string]string{})
 }
 
 //
@@ -4098,6 +5608,9 @@ func (d *hmsdbPg) GetMemberships(f *ComponentFilter) ([]*sm.Membership, error) {
 	if f != nil && f.label == "" {
 		f.label = fname
 	}
+	if err := d.loadPgResolvedGroups(d.sc, d.ctx, f); err != nil {
+		return []*sm.Membership{}, err
+	}
 	query, err := selectComponents(f, FLTR_ID_W_GROUP)
 	if err != nil {
 		d.LogAlways("Error: %s(): makeComponentQuery failed: %s", fname, err)
@@ -4154,6 +5667,13 @@ func (d *hmsdbPg) GetMemberships(f *ComponentFilter) ([]*sm.Membership, error) {
 				fname, id, *name)
 		}
 	}
+	// Dynamic and nested groups don't store all of their members, so add
+	// each one to the memberships of the components it currently contains.
+	if len(lookup) > 0 {
+		if err := d.addPgResolvedMemberships(lookup); err != nil {
+			return []*sm.Membership{}, err
+		}
+	}
 	mbs := make([]*sm.Membership, 0, len(lookup))
 	for _, m := range lookup {
 		mbs = append(mbs, m)
@@ -4161,6 +5681,56 @@ func (d *hmsdbPg) GetMemberships(f *ComponentFilter) ([]*sm.Membership, error) {
 	return mbs, nil
 }
 
+// Worker for GetMemberships - adds dynamic and nested group labels to the
+// memberships in lookup (keyed by xname id) for each component the group
+// currently contains, if not already there.
+func (d *hmsdbPg) addPgResolvedMemberships(lookup map[string]*sm.Membership) error {
+	resolved, err := d.resolvePgGroups(d.sc, d.ctx, nil)
+	if err != nil {
+		return err
+	}
+	idCol := compTable + "." + compIdCol
+	for label, r := range resolved {
+		cond, err := groupMemberCond(idCol, r)
+		if err != nil {
+			d.LogAlways("Warning: GetMemberships(): bad query for %s: %s",
+				label, err)
+			continue
+		}
+		query := sq.Select(idCol).From(compTable).Where(cond)
+		query = query.PlaceholderFormat(sq.Dollar)
+		rows, err := query.RunWith(d.sc).QueryContext(d.ctx)
+		if err != nil {
+			d.LogAlways("Error: GetMemberships(): query for %s failed: %s",
+				label, err)
+			return err
+		}
+		for rows.Next() {
+			var id string
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return err
+			}
+			mb, ok := lookup[id]
+			if !ok {
+				continue
+			}
+			found := false
+			for _, l := range mb.GroupLabels {
+				if l == label {
+					found = true
+					break
+				}
+			}
+			if !found {
+				mb.GroupLabels = append(mb.GroupLabels, label)
+			}
+		}
+		rows.Close()
+	}
+	return nil
+}
+
 ////////////////////////////////////////////////////////////////////////////
 //
 // Component Lock Management
@@ -4188,6 +5758,7 @@ func compLockFilterToCompFilter(clf sm.CompLockV2Filter) (cf ComponentFilter) {
 	cf.Partition = clf.Partition
 	cf.ReservationDisabled = clf.ReservationDisabled
 	cf.Locked = clf.Locked
+	cf.Labels = clf.Labels
 	return cf
 }
 
@@ -4890,6 +6461,22 @@ func (d *hmsdbPg) UpdateJob(jobId, status string) (bool, error) {
 	return didUpdate, err
 }
 
+// Update the status of all of the jobs with the given jobIds at once,
+// e.g. as a batched keep alive.  Returns the number of jobs updated.
+func (d *hmsdbPg) UpdateJobs(jobIds []string, status string) (int64, error) {
+	t, err := d.Begin()
+	if err != nil {
+		return 0, err
+	}
+	num, err := t.UpdateEmptyJobsTx(jobIds, status)
+	if err != nil {
+		t.Rollback()
+		return 0, err
+	}
+	err = t.Commit()
+	return num, err
+}
+
 // Get the job sync entry with the given job id. Nil if not found and nil
 // error, otherwise non-nil error (not normally expected).
 func (d *hmsdbPg) GetJob(jobId string) (*sm.Job, error) {
@@ -4980,3 +6567,212 @@ func (d *hmsdbPg) DeleteJob(jobId string) (bool, error) {
 	}
 	return false, nil
 }
+
+////////////////////////////////////////////////////////////////////////////
+//
+// Service Instances - Cluster of HSM instances
+//
+////////////////////////////////////////////////////////////////////////////
+
+// Record a heartbeat for the HSM instance 'id', creating its entry if
+// needed.  'started' is when the instance started and 'leader' whether
+// it currently holds the leader lock.
+func (d *hmsdbPg) UpsertServiceInstance(id string, started time.Time, leader bool) error {
+	if len(id) == 0 {
+		return ErrHMSDSArgMissing
+	}
+	query := sq.Insert(serviceInstTable).
+		Columns(serviceInstIdCol, serviceInstStartedCol,
+			serviceInstLastHeartbeatCol, serviceInstLeaderCol).
+		Values(id, started, sq.Expr("NOW()"), leader).
+		Suffix("ON CONFLICT(" + serviceInstIdCol + ") DO UPDATE SET " +
+			serviceInstStartedCol + " = EXCLUDED." + serviceInstStartedCol + ", " +
+			serviceInstLastHeartbeatCol + " = EXCLUDED." + serviceInstLastHeartbeatCol + ", " +
+			serviceInstLeaderCol + " = EXCLUDED." + serviceInstLeaderCol)
+
+	query = query.PlaceholderFormat(sq.Dollar)
+	_, err := query.RunWith(d.sc).ExecContext(d.ctx)
+	if err != nil {
+		d.LogAlways("Error: UpsertServiceInstance(): stmt.Exec: %s", err)
+	}
+	return err
+}
+
+// Get all of the HSM instances that have recorded a heartbeat.  Those
+// with one in the last maxAge are marked Live.  Liveness is decided by
+// the database clock so the instances' clocks need not agree.
+func (d *hmsdbPg) GetServiceInstances(maxAge time.Duration) ([]*sm.ServiceInstance, error) {
+	query := sq.Select(serviceInstCols...).
+		Column(sq.Expr(serviceInstLastHeartbeatCol+" > NOW() - ? * INTERVAL '1 second'",
+			int64(maxAge/time.Second))).
+		From(serviceInstTable).
+		OrderBy(serviceInstIdCol)
+
+	query = query.PlaceholderFormat(sq.Dollar)
+	rows, err := query.RunWith(d.sc).QueryContext(d.ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	insts := make([]*sm.ServiceInstance, 0, 1)
+	for rows.Next() {
+		inst := new(sm.ServiceInstance)
+		err := rows.Scan(&inst.ID, &inst.Started, &inst.LastHeartbeat,
+			&inst.Leader, &inst.Live)
+		if err != nil {
+			d.LogAlways("Error: GetServiceInstances(): Scan failed: %s", err)
+			return insts, err
+		}
+		insts = append(insts, inst)
+	}
+	err = rows.Err()
+	return insts, err
+}
+
+// Delete the HSM instances with no heartbeat in the last maxAge.
+// Returns the number deleted.
+func (d *hmsdbPg) DeleteServiceInstancesStale(maxAge time.Duration) (int64, error) {
+	query := sq.Delete(serviceInstTable).
+		Where(sq.Expr(serviceInstLastHeartbeatCol+" < NOW() - ? * INTERVAL '1 second'",
+			int64(maxAge/time.Second)))
+
+	query = query.PlaceholderFormat(sq.Dollar)
+	res, err := query.RunWith(d.sc).ExecContext(d.ctx)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// Try to take the session-level advisory lock 'key', without waiting.
+// It is held on a connection reserved for advisory locks until it is
+// released or that connection is lost, so it should be retaken
+// periodically; this returns true again while it is still held.
+func (d *hmsdbPg) TryAdvisoryLock(key int64) (bool, error) {
+	d.lockMutex.Lock()
+	defer d.lockMutex.Unlock()
+
+	if d.connected == false {
+		return false, ErrHMSDSPtrClosed
+	}
+	if d.lockConn == nil {
+		conn, err := d.db.Conn(d.ctx)
+		if err != nil {
+			return false, err
+		}
+		d.lockConn = conn
+		d.lockKeys = make(map[int64]bool)
+	}
+	if d.lockKeys[key] {
+		// Taking it again would stack, needing another unlock.  Just make
+		// sure the session holding it is still there.
+		var one int
+		err := d.lockConn.QueryRowContext(d.ctx, pingPgAdvisoryLockConn).Scan(&one)
+		if err != nil {
+			d.LogAlways("Warning: TryAdvisoryLock(): Lost lock %d: %s", key, err)
+			d.dropLockConn()
+			return false, err
+		}
+		return true, nil
+	}
+	var locked bool
+	err := d.lockConn.QueryRowContext(d.ctx, tryPgAdvisoryLock, key).Scan(&locked)
+	if err != nil {
+		d.dropLockConn()
+		return false, err
+	}
+	if locked {
+		d.lockKeys[key] = true
+	}
+	return locked, nil
+}
+
+// Release the advisory lock 'key' if held.
+func (d *hmsdbPg) ReleaseAdvisoryLock(key int64) error {
+	d.lockMutex.Lock()
+	defer d.lockMutex.Unlock()
+
+	if d.lockConn == nil || !d.lockKeys[key] {
+		return nil
+	}
+	delete(d.lockKeys, key)
+	_, err := d.lockConn.ExecContext(d.ctx, unlockPgAdvisoryLock, key)
+	if err != nil {
+		// Dropping the session releases it anyways.
+		d.dropLockConn()
+	}
+	return err
+}
+
+// Return the advisory lock connection to the pool.  Closing a bad
+// connection ends the session, which releases any locks held with it.
+// Must hold lockMutex.
+func (d *hmsdbPg) dropLockConn() {
+	if d.lockConn != nil {
+		d.lockConn.Raw(func(driverConn interface{}) error {
+			// Don't let the pool reuse the session with locks still held.
+			return driver.ErrBadConn
+		})
+		d.lockConn.Close()
+	}
+	d.lockConn = nil
+	d.lockKeys = nil
+}
+
+////////////////////////////////////////////////////////////////////////////
+//
+// Change notifications - Invalidating in-memory copies
+//
+////////////////////////////////////////////////////////////////////////////
+
+// Start listening for the notifications the database sends when the
+// Changed* tables are modified, by anyone.  The name of the changed
+// table is sent on the returned channel, or ChangedAll if some may
+// have been missed (e.g. after reconnecting), until Close().
+func (d *hmsdbPg) ListenChanges() (<-chan string, error) {
+	if d.connected == false {
+		return nil, ErrHMSDSPtrClosed
+	}
+	if d.listener != nil {
+		// Replaces any previous one, ending its channel.
+		d.listener.Close()
+	}
+	// LISTEN needs a connection of its own, kept outside of the pool.
+	l := pq.NewListener(d.dsn, time.Second, time.Minute,
+		func(ev pq.ListenerEventType, err error) {
+			if err != nil {
+				d.LogAlways("Warning: ListenChanges(): %s", err)
+			} else if ev == pq.ListenerEventReconnected {
+				d.LogAlways("ListenChanges(): Reconnected.")
+			}
+		})
+	if err := l.Listen(changesPgChannel); err != nil {
+		l.Close()
+		return nil, err
+	}
+	d.listener = l
+
+	changes := make(chan string, 64)
+	go func() {
+		defer close(changes)
+		for {
+			select {
+			case n, ok := <-l.Notify:
+				if !ok {
+					return
+				}
+				if n == nil {
+					// Reconnected, so we don't know what we missed.
+					changes <- ChangedAll
+				} else {
+					changes <- n.Extra
+				}
+			case <-time.After(90 * time.Second):
+				// Notice a dead connection even if nothing changes.
+				go l.Ping()
+			}
+		}
+	}()
+	return changes, nil
+}
@@ -26,6 +26,7 @@ import (
 	"database/sql"
 	"database/sql/driver"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"reflect"
 	"regexp"
@@ -96,6 +97,103 @@ const tGetCompJoinGroupsSuffix = " GROUP BY c.id"
 
 const tGetCompJoinGroupsSuffixAnd = " GROUP BY c.id HAVING COUNT(*) = 2"
 
+const tResolveGroups = "WITH RECURSIVE closure(root, id) AS (" +
+	"SELECT name, id FROM component_groups WHERE namespace = $1"
+
+var tResolveGroupsCols = []string{"root", "name", "query"}
+
+const tGetSubPartIDs = "SELECT id FROM component_groups WHERE name LIKE $1 " +
+	"AND namespace = $2"
+
+const tGetPartQuery = "SELECT id, name, description, tags, version " +
+	"FROM component_groups WHERE name = $1 AND namespace = $2"
+
+const tGetMembersQuery = "SELECT component_id FROM component_group_members " +
+	"WHERE group_id = $1"
+
+// Group filters resolve any nested or dynamic groups among the labels
+// before the component query is built.  True if f will do that lookup.
+func expectsGroupResolve(f *ComponentFilter) bool {
+	if f == nil || len(f.Group) == 0 {
+		return false
+	}
+	fc := *f
+	if fc.VerifyNormalize() != nil {
+		return false
+	}
+	for _, label := range fc.Group {
+		if label == "NULL" {
+			return false
+		}
+	}
+	return true
+}
+
+// Expect the group resolve lookup, finding no groups to expand.
+func expectGroupResolve() {
+	mockPG.ExpectPrepare(regexp.QuoteMeta(tResolveGroups)).ExpectQuery().
+		WillReturnRows(sqlmock.NewRows(tResolveGroupsCols))
+}
+
+// Expect the lookup of the sub-partitions of top-level partition name,
+// finding the ones with the given uuids.
+func expectSubPartIDs(name string, uuids ...string) {
+	rows := sqlmock.NewRows([]string{"id"})
+	for _, uuid := range uuids {
+		rows.AddRow(uuid)
+	}
+	mockPG.ExpectPrepare(regexp.QuoteMeta(tGetSubPartIDs)).ExpectQuery().
+		WithArgs(name+".%", partNamespace).WillReturnRows(rows)
+}
+
+// Expect the lookup of parent partition dgrp5p, with members cur.  If
+// cached, the lookup statement was already prepared by the transaction.
+func expectParentPart(cached bool, cur ...string) {
+	var query *sqlmock.ExpectedQuery
+	if cached {
+		query = mockPG.ExpectQuery(regexp.QuoteMeta(tGetPartQuery))
+	} else {
+		query = mockPG.ExpectPrepare(regexp.QuoteMeta(tGetPartQuery)).ExpectQuery()
+	}
+	query.WithArgs(dgrp5p.Name, partNamespace).
+		WillReturnRows(sqlmock.NewRows(compGroupsColsSMPart).
+			AddRow(uuid5, dgrp5p.Name, dgrp5p.Description,
+				pq.Array(&dgrp5p.Tags), 1))
+	if cur == nil {
+		return
+	}
+	rows := sqlmock.NewRows([]string{"component_id"})
+	for _, id := range cur {
+		rows.AddRow(id)
+	}
+	mockPG.ExpectPrepare(regexp.QuoteMeta(tGetMembersQuery)).ExpectQuery().
+		WithArgs(uuid5).WillReturnRows(rows)
+}
+
+// Expect ids to be taken out of parent partition dgrp5p, which has them.
+func expectTakeFromParent(cached bool, ids ...string) {
+	expectParentPart(cached, ids...)
+	args := []driver.Value{uuid5}
+	for _, id := range ids {
+		args = append(args, id)
+	}
+	mockPG.ExpectPrepare(regexp.QuoteMeta("DELETE FROM component_group_members " +
+		"WHERE group_id = $1 AND component_id IN (")).ExpectExec().
+		WithArgs(args...).WillReturnResult(sqlmock.NewResult(0, int64(len(ids))))
+}
+
+// Expect ids to be given back to parent partition dgrp5p.
+func expectReturnToParent(cached bool, ids ...string) {
+	expectParentPart(cached)
+	args := []driver.Value{}
+	for _, id := range ids {
+		args = append(args, id, uuid5, partGroupNamespace)
+	}
+	mockPG.ExpectPrepare(regexp.QuoteMeta("INSERT INTO component_group_members")).
+		ExpectExec().WithArgs(args...).
+		WillReturnResult(sqlmock.NewResult(0, int64(len(ids))))
+}
+
 const tGetSCNSubscriptionQueryId = "SELECT id, subscription FROM scn_subscriptions WHERE id = $1"
 
 const tGetSCNSubscriptionQueryAll = "SELECT id, subscription FROM scn_subscriptions"
@@ -322,6 +420,9 @@ func TestPgGetComponentsFilter(t *testing.T) {
 		}
 
 		mockPG.ExpectBegin()
+		if expectsGroupResolve(test.f) {
+			expectGroupResolve()
+		}
 		if test.dbError != nil {
 			mockPG.ExpectPrepare(test.expectedPrepare).ExpectQuery().WillReturnError(test.dbError)
 			mockPG.ExpectRollback()
@@ -522,6 +623,9 @@ func TestPgGetComponentsQuery(t *testing.T) {
 		}
 
 		mockPG.ExpectBegin()
+		if expectsGroupResolve(test.f) {
+			expectGroupResolve()
+		}
 		if test.dbError != nil {
 			mockPG.ExpectPrepare(test.expectedPrepare).ExpectQuery().WillReturnError(test.dbError)
 			mockPG.ExpectRollback()
@@ -790,7 +894,7 @@ func TestPgUpsertComponents(t *testing.T) {
 			}
 		}
 
-		changeMap, err := dPG.UpsertComponents(test.comps, test.force)
+		changeMap, err := dPG.UpsertComponents(test.comps, test.force, "")
 		// ensure all expectations have been met
 		if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
 			t.Errorf("Test %v Failed: Sql expectations were not met: %s", i, mock_err)
@@ -974,7 +1078,7 @@ func TestPgUpdateCompStates(t *testing.T) {
 			mockPG.ExpectCommit()
 		}
 
-		affectedIDs, err := dPG.UpdateCompStates(test.ids, test.state, test.flag, test.force, test.pi)
+		affectedIDs, err := dPG.UpdateCompStates(test.ids, test.state, test.flag, test.force, test.pi, "", nil)
 		// ensure all expectations have been met
 		if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
 			t.Errorf("Test %v Failed: Sql expectations were not met: %s",
@@ -995,6 +1099,45 @@ func TestPgUpdateCompStates(t *testing.T) {
 	}
 }
 
+func TestPgUpdateCompStatesCheck(t *testing.T) {
+	compCols := []string{"id", "type", "state", "flag", "enabled", "admin", "role", "subrole", "nid", "subtype", "nettype", "arch", "class", "reservation_disabled", "locked"}
+	ResetMockDB()
+	rows := sqlmock.NewRows(compCols).
+		AddRow("x0c0s27b0n0", "Node", "Off", "OK", true, "", "Compute", "", 27, "", "Sling", "X86", "", false, false).
+		AddRow("x0c0s25b0n0", "Node", "On", "OK", true, "", "Compute", "", 25, "", "Sling", "X86", "", false, false)
+	mockPG.ExpectBegin()
+	mockPG.ExpectPrepare(regexp.QuoteMeta(tGetCompBaseQuery+
+		" WHERE c.id IN ($1,$2) FOR UPDATE")).ExpectQuery().
+		WithArgs("x0c0s27b0n0", "x0c0s25b0n0").WillReturnRows(rows)
+	mockPG.ExpectRollback()
+
+	// A rejection by the check rolls back without updating anything.
+	checked := []string{}
+	errRejected := errors.New("rejected")
+	check := func(comp *base.Component, newState string) error {
+		checked = append(checked, comp.ID+":"+comp.State+"->"+newState)
+		if comp.State == "Off" {
+			return errRejected
+		}
+		return nil
+	}
+	affectedIDs, err := dPG.UpdateCompStates([]string{"x0c0s27b0n0", "x0c0s25b0n0"},
+		"Ready", "", false, new(PartInfo), "", check)
+	if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
+		t.Errorf("Test Failed: Sql expectations were not met: %s", mock_err)
+	}
+	if err != errRejected {
+		t.Errorf("Test Failed: Expected error '%s'; Received '%v'", errRejected, err)
+	}
+	if len(affectedIDs) != 0 {
+		t.Errorf("Test Failed: Expected no affected IDs; Received '%v'", affectedIDs)
+	}
+	expected := []string{"x0c0s27b0n0:Off->Ready", "x0c0s25b0n0:On->Ready"}
+	if !reflect.DeepEqual(expected, checked) {
+		t.Errorf("Test Failed: Expected checks '%v'; Received '%v'", expected, checked)
+	}
+}
+
 func TestPgBulkUpdateCompFlagOnly(t *testing.T) {
 	getCompIDPrefix := "SELECT id FROM components "
 	tests := []struct {
@@ -1090,7 +1233,7 @@ func TestPgBulkUpdateCompFlagOnly(t *testing.T) {
 			mockPG.ExpectCommit()
 		}
 
-		affectedIDs, err := dPG.BulkUpdateCompFlagOnly(test.ids, test.flag)
+		affectedIDs, err := dPG.BulkUpdateCompFlagOnly(test.ids, test.flag, "")
 		// ensure all expectations have been met
 		if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
 			t.Errorf("Test %v Failed: Sql expectations were not met: %s", i, mock_err)
@@ -1204,7 +1347,7 @@ func TestPgBulkUpdateCompEnabled(t *testing.T) {
 			mockPG.ExpectCommit()
 		}
 
-		affectedIDs, err := dPG.BulkUpdateCompEnabled(test.ids, test.enabled)
+		affectedIDs, err := dPG.BulkUpdateCompEnabled(test.ids, test.enabled, "")
 		// ensure all expectations have been met
 		if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
 			t.Errorf("Test %v Failed: Sql expectations were not met: %s",
@@ -1320,7 +1463,7 @@ func TestPgBulkUpdateCompSwStatus(t *testing.T) {
 			mockPG.ExpectCommit()
 		}
 
-		affectedIDs, err := dPG.BulkUpdateCompSwStatus(test.ids, test.swstatus)
+		affectedIDs, err := dPG.BulkUpdateCompSwStatus(test.ids, test.swstatus, "")
 		// ensure all expectations have been met
 		if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
 			t.Errorf("Test %v Failed: Sql expectations were not met: %s", i, mock_err)
@@ -1432,7 +1575,7 @@ func TestPgBulkUpdateCompRole(t *testing.T) {
 			mockPG.ExpectCommit()
 		}
 
-		affectedIDs, err := dPG.BulkUpdateCompRole(test.ids, test.role, "")
+		affectedIDs, err := dPG.BulkUpdateCompRole(test.ids, test.role, "", "")
 		// ensure all expectations have been met
 		if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
 			t.Errorf("Test %v Failed: Sql expectations were not met: %s",
@@ -1453,6 +1596,309 @@ func TestPgBulkUpdateCompRole(t *testing.T) {
 	}
 }
 
+func TestPgBulkUpdateCompRoleSource(t *testing.T) {
+	getCompIDPrefix := "SELECT id FROM components "
+	ResetMockDB()
+	rows := sqlmock.NewRows([]string{"id"}).AddRow("x0c0s27b0n0")
+	mockPG.ExpectBegin()
+	mockPG.ExpectPrepare(regexp.QuoteMeta("SELECT set_config($1, $2, true)")).
+		ExpectQuery().WithArgs(groupHistRequesterSetting, "cray-bos").
+		WillReturnRows(sqlmock.NewRows([]string{"set_config"}).AddRow("cray-bos"))
+	mockPG.ExpectPrepare(regexp.QuoteMeta(getCompIDPrefix+
+		" WHERE (id = $1) AND (role != $2);")).ExpectQuery().
+		WithArgs("x0c0s27b0n0", "Compute").WillReturnRows(rows)
+	mockPG.ExpectPrepare(regexp.QuoteMeta(ToPGQueryArgs(updateCompRolePrefix)+
+		"WHERE (id = $3);")).ExpectExec().
+		WithArgs("Compute", "", "x0c0s27b0n0").WillReturnResult(sqlmock.NewResult(0, 1))
+	mockPG.ExpectCommit()
+
+	affectedIDs, err := dPG.BulkUpdateCompRole([]string{"x0c0s27b0n0"}, "compute", "", "cray-bos")
+	if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
+		t.Errorf("Test Failed: Sql expectations were not met: %s", mock_err)
+	}
+	if err != nil {
+		t.Errorf("Test Failed: Unexpected error received: %s", err)
+	} else if !compareIDs([]string{"x0c0s27b0n0"}, affectedIDs) {
+		t.Errorf("Test Failed: Expected affectedIDs '%v'; Recieved affectedIDs '%v'",
+			[]string{"x0c0s27b0n0"}, affectedIDs)
+	}
+}
+
+func TestPgPatchComponents(t *testing.T) {
+	compCols := []string{"id", "type", "state", "flag", "enabled", "admin", "role", "subrole", "nid", "subtype", "nettype", "arch", "class", "reservation_disabled", "locked"}
+	ready := base.StateReady.String()
+	disabled := false
+	app := base.RoleApplication.String()
+	patches := []*sm.CompPatch{
+		&sm.CompPatch{ID: "x0c0s27b0n0", State: ready, Flag: "OK", Enabled: &disabled},
+		&sm.CompPatch{ID: "x0c0s26b0n0", State: base.StateHalt.String(), Flag: "OK"},
+		&sm.CompPatch{ID: "x0c0s25b0n0", Enabled: &disabled},
+		&sm.CompPatch{ID: "x0c0s24b0n0", Role: &app},
+	}
+	ResetMockDB()
+	rows := sqlmock.NewRows(compCols).
+		AddRow("x0c0s27b0n0", "Node", "On", "OK", true, "", "Compute", "", 27, "", "Sling", "X86", "", false, false).
+		AddRow("x0c0s26b0n0", "Node", "Off", "OK", true, "", "Compute", "", 26, "", "Sling", "X86", "", false, false).
+		AddRow("x0c0s24b0n0", "Node", "Ready", "OK", true, "", "Compute", "Worker", 24, "", "Sling", "X86", "", false, false)
+	mockPG.ExpectBegin()
+	mockPG.ExpectPrepare(regexp.QuoteMeta("SELECT set_config($1, $2, true)")).
+		ExpectQuery().WithArgs(groupHistRequesterSetting, "cray-bos").
+		WillReturnRows(sqlmock.NewRows([]string{"set_config"}).AddRow("cray-bos"))
+	mockPG.ExpectPrepare(regexp.QuoteMeta(tGetCompBaseQuery+
+		" WHERE c.id IN ($1,$2,$3,$4) FOR UPDATE")).ExpectQuery().
+		WithArgs("x0c0s27b0n0", "x0c0s26b0n0", "x0c0s25b0n0", "x0c0s24b0n0").
+		WillReturnRows(rows)
+	mockPG.ExpectPrepare(regexp.QuoteMeta(updateCompPrefix +
+		" state = 'Ready', flag = CASE WHEN flag = 'Locked' THEN 'Locked' ELSE 'OK' END WHERE (id = $1);")).
+		ExpectExec().WithArgs("x0c0s27b0n0").WillReturnResult(sqlmock.NewResult(0, 1))
+	mockPG.ExpectPrepare(regexp.QuoteMeta(ToPGQueryArgs(updateCompEnabledByIDQuery))).
+		ExpectExec().WithArgs(false, "x0c0s27b0n0").WillReturnResult(sqlmock.NewResult(0, 1))
+	// SubRole is kept since it wasn't given.
+	mockPG.ExpectPrepare(regexp.QuoteMeta(ToPGQueryArgs(updateCompRoleByIDQuery))).
+		ExpectExec().WithArgs("Application", "Worker", "x0c0s24b0n0").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mockPG.ExpectCommit()
+
+	changedIDs, failures, err := dPG.PatchComponents(patches, false, "cray-bos", nil)
+	if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
+		t.Errorf("Test Failed: Sql expectations were not met: %s", mock_err)
+	}
+	expectedIDs := []string{"x0c0s27b0n0", "x0c0s24b0n0"}
+	if err != nil {
+		t.Errorf("Test Failed: Unexpected error received: %s", err)
+	} else if !compareIDs(expectedIDs, changedIDs) {
+		t.Errorf("Test Failed: Expected changedIDs '%v'; Recieved changedIDs '%v'",
+			expectedIDs, changedIDs)
+	} else if len(failures) != 2 || failures[0].ID != "x0c0s26b0n0" ||
+		failures[1].ID != "x0c0s25b0n0" {
+		t.Errorf("Test Failed: Expected failures for x0c0s26b0n0 and x0c0s25b0n0; Recieved '%v'",
+			failures)
+	}
+
+	// A database error rolls back all of the changes.
+	ResetMockDB()
+	rows = sqlmock.NewRows(compCols).
+		AddRow("x0c0s27b0n0", "Node", "On", "OK", true, "", "Compute", "", 27, "", "Sling", "X86", "", false, false)
+	mockPG.ExpectBegin()
+	mockPG.ExpectPrepare(regexp.QuoteMeta(tGetCompBaseQuery +
+		" WHERE c.id IN ($1) FOR UPDATE")).ExpectQuery().
+		WithArgs("x0c0s27b0n0").WillReturnRows(rows)
+	mockPG.ExpectPrepare(regexp.QuoteMeta(ToPGQueryArgs(updateCompEnabledByIDQuery))).
+		ExpectExec().WithArgs(false, "x0c0s27b0n0").WillReturnError(sql.ErrConnDone)
+	mockPG.ExpectRollback()
+
+	_, _, err = dPG.PatchComponents([]*sm.CompPatch{
+		&sm.CompPatch{ID: "x0c0s27b0n0", Enabled: &disabled},
+	}, false, "", nil)
+	if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
+		t.Errorf("Test Failed: Sql expectations were not met: %s", mock_err)
+	}
+	if err == nil {
+		t.Errorf("Test Failed: Expected an error.")
+	}
+
+	// A component the check rejects fails without being changed.
+	ResetMockDB()
+	rows = sqlmock.NewRows(compCols).
+		AddRow("x0c0s27b0n0", "Node", "On", "OK", true, "", "Compute", "", 27, "", "Sling", "X86", "", false, false)
+	mockPG.ExpectBegin()
+	mockPG.ExpectPrepare(regexp.QuoteMeta(tGetCompBaseQuery +
+		" WHERE c.id IN ($1) FOR UPDATE")).ExpectQuery().
+		WithArgs("x0c0s27b0n0").WillReturnRows(rows)
+	mockPG.ExpectCommit()
+
+	check := func(comp *base.Component, newState string) error {
+		return fmt.Errorf("%s to %s rejected", comp.State, newState)
+	}
+	changedIDs, failures, err = dPG.PatchComponents([]*sm.CompPatch{
+		&sm.CompPatch{ID: "x0c0s27b0n0", State: ready, Flag: "OK", Enabled: &disabled},
+	}, false, "", check)
+	if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
+		t.Errorf("Test Failed: Sql expectations were not met: %s", mock_err)
+	}
+	if err != nil {
+		t.Errorf("Test Failed: Unexpected error received: %s", err)
+	} else if len(changedIDs) != 0 {
+		t.Errorf("Test Failed: Expected no changedIDs; Recieved changedIDs '%v'",
+			changedIDs)
+	} else if len(failures) != 1 || failures[0].ID != "x0c0s27b0n0" ||
+		failures[0].Reason != "On to Ready rejected" {
+		t.Errorf("Test Failed: Expected a rejection of x0c0s27b0n0; Recieved '%v'",
+			failures)
+	}
+}
+
+func TestPgGetCompHistory(t *testing.T) {
+	testEnt1 := sm.CompHistoryEntry{
+		ID:          5,
+		Timestamp:   "2026-10-19T11:36:00Z",
+		ComponentID: "x0c0s1b0n0",
+		Field:       sm.CompHistFieldState,
+		OldValue:    "Off",
+		NewValue:    "On",
+		Source:      "cray-capmc",
+	}
+	testEnt2 := sm.CompHistoryEntry{
+		ID:          8,
+		Timestamp:   "2026-10-19T11:37:00Z",
+		ComponentID: "x0c0s1b0n0",
+		Field:       sm.CompHistFieldState,
+		OldValue:    "On",
+		NewValue:    "Ready",
+		Source:      "RedfishEvent:x0c0s1b0",
+	}
+	entRow := func(ent sm.CompHistoryEntry) []driver.Value {
+		return []driver.Value{ent.ID, ent.Timestamp, ent.ComponentID,
+			ent.Field, ent.OldValue, ent.NewValue, ent.Source}
+	}
+	since, _ := time.Parse(time.RFC3339, "2026-10-19T00:00:00Z")
+	until, _ := time.Parse(time.RFC3339, "2026-10-20T00:00:00Z")
+
+	sqq := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	query1, _, _ := sqq.Select(compHistColsSM...).
+		From(compHistTable).
+		Where("component_id = ?", "x0c0s1b0n0").
+		Where(sq.Eq{compHistFieldCol: []string{sm.CompHistFieldState, sm.CompHistFieldSwStatus}}).
+		Where(sq.GtOrEq{compHistTimestampCol: since}).
+		Where(sq.LtOrEq{compHistTimestampCol: until}).
+		OrderBy("id ASC").ToSql()
+	query2, _, _ := sqq.Select(compHistColsSM...).
+		From(compHistTable).
+		Where("component_id = ?", "x0c0s1b0n0").
+		Where(sq.Eq{compHistSourceCol: []string{"cray-capmc"}}).
+		OrderBy("id ASC").ToSql()
+
+	tests := []struct {
+		id              string
+		fltr            []CompHistFiltFunc
+		dbRows          [][]driver.Value
+		dbError         error
+		expectedPrepare string
+		expectedArgs    []driver.Value
+		expectedErr     error
+		expectedEnts    []*sm.CompHistoryEntry
+	}{{
+		id: "x00c0s1b0n0",
+		fltr: []CompHistFiltFunc{
+			CH_Fields([]string{"state", "softwarestatus"}),
+			CH_Since("2026-10-19T00:00:00Z"),
+			CH_Until("2026-10-20T00:00:00Z"),
+		},
+		dbRows:          [][]driver.Value{entRow(testEnt1), entRow(testEnt2)},
+		expectedPrepare: regexp.QuoteMeta(query1),
+		expectedArgs: []driver.Value{"x0c0s1b0n0", sm.CompHistFieldState,
+			sm.CompHistFieldSwStatus, since, until},
+		expectedEnts: []*sm.CompHistoryEntry{&testEnt1, &testEnt2},
+	}, {
+		id:              "x0c0s1b0n0",
+		fltr:            []CompHistFiltFunc{CH_Sources([]string{"cray-capmc"})},
+		dbRows:          [][]driver.Value{},
+		expectedPrepare: regexp.QuoteMeta(query2),
+		expectedArgs:    []driver.Value{"x0c0s1b0n0", "cray-capmc"},
+		expectedEnts:    []*sm.CompHistoryEntry{},
+	}, {
+		id:              "x0c0s1b0n0",
+		fltr:            []CompHistFiltFunc{CH_Sources([]string{"cray-capmc"})},
+		dbError:         sql.ErrConnDone,
+		expectedPrepare: regexp.QuoteMeta(query2),
+		expectedArgs:    []driver.Value{"x0c0s1b0n0", "cray-capmc"},
+		expectedErr:     sql.ErrConnDone,
+	}, {
+		id:          "x0c0s1b0n0",
+		fltr:        []CompHistFiltFunc{CH_Fields([]string{"nid"})},
+		expectedErr: ErrHMSDSArgBadArg,
+	}, {
+		id:          "x0c0s1b0n0",
+		fltr:        []CompHistFiltFunc{CH_Since("yesterday")},
+		expectedErr: ErrHMSDSArgBadTimeFormat,
+	}}
+
+	for i, test := range tests {
+		ResetMockDB()
+		rows := sqlmock.NewRows(compHistColsSM)
+		for _, row := range test.dbRows {
+			rows.AddRow(row...)
+		}
+		if test.dbError != nil {
+			mockPG.ExpectPrepare(test.expectedPrepare).ExpectQuery().WithArgs(test.expectedArgs...).WillReturnError(test.dbError)
+		} else if test.expectedPrepare != "" {
+			mockPG.ExpectPrepare(test.expectedPrepare).ExpectQuery().WithArgs(test.expectedArgs...).WillReturnRows(rows)
+		}
+
+		ents, err := dPG.GetCompHistory(test.id, test.fltr...)
+		if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
+			t.Errorf("Test %v Failed: Sql expectations were not met: %s", i, mock_err)
+		}
+		if err != test.expectedErr {
+			t.Errorf("Test %v Failed: Expected error '%v'; Recieved '%v'", i, test.expectedErr, err)
+		} else if err == nil && !reflect.DeepEqual(test.expectedEnts, ents) {
+			t.Errorf("Test %v Failed: Expected entries '%v'; Recieved '%v'", i, test.expectedEnts, ents)
+		}
+	}
+}
+
+func TestPgPruneCompHistory(t *testing.T) {
+	before, _ := time.Parse(time.RFC3339, "2026-07-21T00:00:00Z")
+
+	sqq := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	delete1, _, _ := sqq.Delete(compHistTable).
+		Where(sq.Lt{compHistTimestampCol: before}).ToSql()
+	delete2, _, _ := sqq.Delete(compHistTable).
+		Where(sq.Or{
+			sq.Lt{compHistTimestampCol: before},
+			sq.Expr("id <= (SELECT id FROM component_history ORDER BY id DESC LIMIT 1 OFFSET ?)", 1000000),
+		}).ToSql()
+
+	tests := []struct {
+		maxEntries      int
+		expectedPrepare string
+		expectedArgs    []driver.Value
+		dbError         error
+		expectedNum     int64
+	}{{
+		maxEntries:      0,
+		expectedPrepare: regexp.QuoteMeta(delete1),
+		expectedArgs:    []driver.Value{before},
+		expectedNum:     12,
+	}, {
+		maxEntries:      1000000,
+		expectedPrepare: regexp.QuoteMeta(delete2),
+		expectedArgs:    []driver.Value{before, 1000000},
+		expectedNum:     40,
+	}, {
+		maxEntries:      1000000,
+		expectedPrepare: regexp.QuoteMeta(delete2),
+		expectedArgs:    []driver.Value{before, 1000000},
+		dbError:         sql.ErrConnDone,
+	}}
+
+	for i, test := range tests {
+		ResetMockDB()
+		mockPG.ExpectBegin()
+		if test.dbError != nil {
+			mockPG.ExpectPrepare(test.expectedPrepare).ExpectExec().WithArgs(test.expectedArgs...).WillReturnError(test.dbError)
+			mockPG.ExpectRollback()
+		} else {
+			mockPG.ExpectPrepare(test.expectedPrepare).ExpectExec().WithArgs(test.expectedArgs...).WillReturnResult(sqlmock.NewResult(0, test.expectedNum))
+			mockPG.ExpectCommit()
+		}
+
+		num, err := dPG.PruneCompHistory(before, test.maxEntries)
+		if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
+			t.Errorf("Test %v Failed: Sql expectations were not met: %s", i, mock_err)
+		}
+		if test.dbError == nil {
+			if err != nil {
+				t.Errorf("Test %v Failed: Unexpected error received: %s", i, err)
+			} else if num != test.expectedNum {
+				t.Errorf("Test %v Failed: Expected %d deleted; Recieved %d", i, test.expectedNum, num)
+			}
+		} else if err == nil {
+			t.Errorf("Test %v Failed: Expected an error.", i)
+		}
+	}
+}
+
 func TestPgBulkUpdateCompClass(t *testing.T) {
 	tests := []struct {
 		ids                   []string
@@ -1889,6 +2335,14 @@ func TestPgGetHWInvByFRUFilter(t *testing.T) {
 		From(hwInvFruTable + " " + hwInvFruAlias).
 		Where(sq.Eq{hwInvFruAlias + "." + hwInvFruTblTypeCol: []string{xnametypes.Processor.String()}}).ToSql()
 
+	query4, _, _ := sqq.Select(columns...).
+		From(hwInvFruTable + " " + hwInvFruAlias).
+		Where(sq.Expr(hwInvFruAlias+"."+hwInvFruTblIdCol+" IN (?)",
+			sq.Select(hwInvPartFruIdCol).
+				From(hwInvPartTable).
+				Where(sq.Or{sq.Eq{hwInvPartPartitionCol: []string{"p1"}},
+					sq.Like{hwInvPartPartitionCol: "p1.%"}}))).ToSql()
+
 	tests := []struct {
 		f_opts          []HWInvLocFiltFunc
 		dbRows          [][]driver.Value
@@ -1934,6 +2388,16 @@ func TestPgGetHWInvByFRUFilter(t *testing.T) {
 		expectedArgs:    []driver.Value{xnametypes.Processor.String()},
 		expectedHwFrus:  nil,
 		expectedErr:     nil,
+	}, {
+		f_opts: []HWInvLocFiltFunc{HWInvLoc_Part("p1")},
+		dbRows: [][]driver.Value{
+			[]driver.Value{proc1.FRUID, proc1.Type, proc1.Subtype, proc1FruInfo},
+		},
+		dbError:         nil,
+		expectedPrepare: regexp.QuoteMeta(query4),
+		expectedArgs:    []driver.Value{"p1", "p1.%"},
+		expectedHwFrus:  []*sm.HWInvByFRU{&proc1},
+		expectedErr:     nil,
 	}}
 
 	for i, test := range tests {
@@ -2144,652 +2608,662 @@ func TestPgGetHWInvHistFilter(t *testing.T) {
 	}
 }
 
-func TestInsertHWInvHists(t *testing.T) {
-	testHWInvHist1 := sm.HWInvHist{
-		ID:        "x5c4s3b2n1p0",
-		FruId:     "MFR-PARTNUMBER-SERIALNUMBER_1",
-		Timestamp: "2020-01-21 11:36:00",
-		EventType: "Added",
-	}
-	testHWInvHist2 := sm.HWInvHist{
-		ID:        "x5c4s3b2n1p1",
-		FruId:     "MFR-PARTNUMBER-SERIALNUMBER_2",
-		Timestamp: "2020-01-21 11:36:00",
-		EventType: "Added",
+func TestPgGetHWInvFirmwareFilter(t *testing.T) {
+	columns := addAliasToCols(hwInvFwAlias, hwInvFwCols, hwInvFwCols)
+
+	testFw1 := sm.HWInvFirmware{
+		ID:           "BMC",
+		RfEndpointID: "x5c4s3b0",
+		Inventory:    "FirmwareInventory",
+		RelatedID:    "x5c4s3b0",
+		RelatedType:  "NodeBMC",
+		Name:         "BMC Firmware",
+		Version:      "1.2.3",
+		Updateable:   true,
+		OdataID:      "/redfish/v1/UpdateService/FirmwareInventory/BMC",
+		LastUpdate:   "2026-10-19 11:36:00",
 	}
-	testHWInvHistBad := sm.HWInvHist{
-		ID:        "x5c4s3b2n1p0",
-		FruId:     "MFR-PARTNUMBER-SERIALNUMBER_1",
-		Timestamp: "2020-01-21 12:00:00",
-		EventType: "foo",
+	testFw2 := sm.HWInvFirmware{
+		ID:           "BIOS",
+		RfEndpointID: "x5c4s3b0",
+		Inventory:    "FirmwareInventory",
+		RelatedID:    "x5c4s3b0n0",
+		RelatedType:  "Node",
+		Name:         "System BIOS",
+		Version:      "2.0.1",
+		Updateable:   false,
+		OdataID:      "/redfish/v1/UpdateService/FirmwareInventory/BIOS",
+		LastUpdate:   "2026-10-19 11:36:00",
 	}
 
 	sqq := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	query1, _, _ := sqq.Select(columns...).
+		From(hwInvFwTable + " " + hwInvFwAlias).
+		OrderBy(hwInvFwRelatedIdColAlias, hwInvFwIdColAlias).ToSql()
 
-	insert1, _, _ := sqq.Insert(hwInvHistTable).
-		Columns(hwInvHistColsNoTS...).
-		Values("x0c0s0b0n0", "MFR-PARTNUMBER-SERIALNUMBER_1", "Scanned").
-		Values("x0c0s0b0n0", "MFR-PARTNUMBER-SERIALNUMBER_1", "Scanned").ToSql()
+	query2, _, _ := sqq.Select(columns...).
+		From(hwInvFwTable + " " + hwInvFwAlias).
+		Where(sq.Eq{hwInvFwRelatedIdColAlias: []string{testFw2.RelatedID}}).
+		Where(sq.Eq{hwInvFwVersionColAlias: []string{testFw2.Version}}).
+		Where(sq.Eq{hwInvFwUpdateableColAlias: false}).
+		OrderBy(hwInvFwRelatedIdColAlias, hwInvFwIdColAlias).ToSql()
 
 	tests := []struct {
-		hhs             []*sm.HWInvHist
+		f_opts          []HWInvFirmwareFiltFunc
+		dbRows          [][]driver.Value
+		dbError         error
 		expectedPrepare string
 		expectedArgs    []driver.Value
-		dbError         error
+		expectedFws     []*sm.HWInvFirmware
+		expectedErr     error
 	}{{
-		hhs:             []*sm.HWInvHist{&testHWInvHist1, &testHWInvHist2},
-		expectedPrepare: regexp.QuoteMeta(insert1),
-		expectedArgs:    []driver.Value{
-			testHWInvHist1.ID, testHWInvHist1.FruId, testHWInvHist1.EventType,
-			testHWInvHist2.ID, testHWInvHist2.FruId, testHWInvHist2.EventType,
+		f_opts: []HWInvFirmwareFiltFunc{},
+		dbRows: [][]driver.Value{
+			[]driver.Value{testFw1.ID, testFw1.RfEndpointID, testFw1.Inventory, testFw1.RelatedID, testFw1.RelatedType, testFw1.Name, testFw1.Version, testFw1.Updateable, testFw1.SoftwareId, testFw1.Manufacturer, testFw1.ReleaseDate, testFw1.OdataID, testFw1.LastUpdate},
+			[]driver.Value{testFw2.ID, testFw2.RfEndpointID, testFw2.Inventory, testFw2.RelatedID, testFw2.RelatedType, testFw2.Name, testFw2.Version, testFw2.Updateable, testFw2.SoftwareId, testFw2.Manufacturer, testFw2.ReleaseDate, testFw2.OdataID, testFw2.LastUpdate},
 		},
-		dbError: nil,
-	}, {
-		hhs:             []*sm.HWInvHist{&testHWInvHistBad},
-		expectedPrepare: "",
+		dbError:         nil,
+		expectedPrepare: regexp.QuoteMeta(query1),
 		expectedArgs:    []driver.Value{},
-		dbError:         ErrHMSDSArgBadHWInvHistEventType,
+		expectedFws:     []*sm.HWInvFirmware{&testFw1, &testFw2},
+		expectedErr:     nil,
 	}, {
-		hhs:             []*sm.HWInvHist{&testHWInvHist1, &testHWInvHist2},
-		expectedPrepare: regexp.QuoteMeta(insert1),
-		expectedArgs:    []driver.Value{
-			testHWInvHist1.ID, testHWInvHist1.FruId, testHWInvHist1.EventType,
-			testHWInvHist2.ID, testHWInvHist2.FruId, testHWInvHist2.EventType,
+		f_opts: []HWInvFirmwareFiltFunc{
+			FW_RelatedIDs([]string{testFw2.RelatedID}),
+			FW_Versions([]string{testFw2.Version}),
+			FW_Updateable("false"),
 		},
-		dbError: sql.ErrNoRows,
+		dbRows: [][]driver.Value{
+			[]driver.Value{testFw2.ID, testFw2.RfEndpointID, testFw2.Inventory, testFw2.RelatedID, testFw2.RelatedType, testFw2.Name, testFw2.Version, testFw2.Updateable, testFw2.SoftwareId, testFw2.Manufacturer, testFw2.ReleaseDate, testFw2.OdataID, testFw2.LastUpdate},
+		},
+		dbError:         nil,
+		expectedPrepare: regexp.QuoteMeta(query2),
+		expectedArgs:    []driver.Value{testFw2.RelatedID, testFw2.Version, false},
+		expectedFws:     []*sm.HWInvFirmware{&testFw2},
+		expectedErr:     nil,
+	}, {
+		f_opts:          []HWInvFirmwareFiltFunc{FW_Updateable("foo")},
+		dbRows:          nil,
+		dbError:         nil,
+		expectedPrepare: "",
+		expectedArgs:    nil,
+		expectedFws:     nil,
+		expectedErr:     ErrHMSDSArgBadArg,
+	}, {
+		f_opts:          []HWInvFirmwareFiltFunc{},
+		dbRows:          nil,
+		dbError:         sql.ErrNoRows,
+		expectedPrepare: regexp.QuoteMeta(query1),
+		expectedArgs:    nil,
+		expectedFws:     nil,
+		expectedErr:     nil,
 	}}
 
 	for i, test := range tests {
 		ResetMockDB()
+		rows := sqlmock.NewRows(columns)
+		for _, row := range test.dbRows {
+			rows.AddRow(row...)
+		}
+
 		mockPG.ExpectBegin()
-		if test.expectedPrepare == "" && test.dbError != nil {
-			mockPG.ExpectRollback()
-		} else if test.dbError != nil {
-			mockPG.ExpectPrepare(test.expectedPrepare).ExpectExec().WillReturnError(test.dbError)
+		if test.dbError != nil {
+			mockPG.ExpectPrepare(test.expectedPrepare).ExpectQuery().WillReturnError(test.dbError)
 			mockPG.ExpectRollback()
-		} else {
-			mockPG.ExpectPrepare(test.expectedPrepare).ExpectExec().WithArgs(test.expectedArgs...).WillReturnResult(sqlmock.NewResult(0, 1))
+		} else if test.expectedErr == nil {
+			if len(test.expectedArgs) > 0 {
+				mockPG.ExpectPrepare(test.expectedPrepare).ExpectQuery().WithArgs(test.expectedArgs...).WillReturnRows(rows)
+			} else {
+				mockPG.ExpectPrepare(test.expectedPrepare).ExpectQuery().WillReturnRows(rows)
+			}
 			mockPG.ExpectCommit()
 		}
 
-		err := dPG.InsertHWInvHists(test.hhs)
-		// ensure all expectations have been met
-		if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
-			t.Errorf("Test %v Failed: Sql expectations were not met: %s", i, mock_err)
+		fws, err := dPG.GetHWInvFirmwareFilter(test.f_opts...)
+		if test.expectedErr == nil {
+			if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
+				t.Errorf("Test %v Failed: Sql expectations were not met: %s", i, mock_err)
+			}
 		}
-		if test.dbError == nil && err != nil {
-			t.Errorf("Test %v Failed: Unexpected error received: %s", i, err)
-		} else if test.dbError != nil && err == nil {
+		if test.dbError == nil && test.expectedErr == nil {
+			if err != nil {
+				t.Errorf("Test %v Failed: Unexpected error received: %s", i, err)
+			} else if !reflect.DeepEqual(test.expectedFws, fws) {
+				t.Errorf("Test %v Failed: Expected firmware '%v'; Recieved firmware '%v'", i, test.expectedFws, fws)
+			}
+		} else if err == nil {
 			t.Errorf("Test %v Failed: Expected an error.", i)
 		}
 	}
 }
 
-func TestDeleteHWInvHistByLocID(t *testing.T) {
-	testHWInvHist1 := sm.HWInvHist{
-		ID:        "x5c4s3b2n1p0",
-		FruId:     "MFR-PARTNUMBER-SERIALNUMBER_1",
-		Timestamp: "2020-01-21 11:36:00",
-		EventType: "Added",
+func TestPgGetHWInvFanFilter(t *testing.T) {
+	columns := addAliasToCols(hwInvFanAlias, hwInvFanCols, hwInvFanCols)
+
+	testFan1 := sm.HWInvFan{
+		ID:           "Fan1",
+		ParentID:     "x5c4s3e0",
+		ParentType:   "NodeEnclosure",
+		RfEndpointID: "x5c4s3b0",
+		Name:         "Fan Tray 1",
+		Status:       "Populated",
+		Health:       "OK",
+		Flag:         "OK",
+		FRUID:        "Fan.Delta.FT100.FAN1SN",
+		Manufacturer: "Delta",
+		PartNumber:   "FT-100",
+		SerialNumber: "FAN1SN",
+		OdataID:      "/redfish/v1/Chassis/Enclosure/ThermalSubsystem/Fans/Fan1",
+		LastUpdate:   "2026-10-19 11:36:00",
+	}
+	testFan2 := sm.HWInvFan{
+		ID:           "Fan2",
+		ParentID:     "x5c4s3e0",
+		ParentType:   "NodeEnclosure",
+		RfEndpointID: "x5c4s3b0",
+		Name:         "Fan Tray 2",
+		Status:       "Populated",
+		Health:       "Warning",
+		Flag:         "Warning",
+		FRUID:        "Fan.Delta.FT100.FAN2SN",
+		Manufacturer: "Delta",
+		PartNumber:   "FT-100",
+		SerialNumber: "FAN2SN",
+		OdataID:      "/redfish/v1/Chassis/Enclosure/ThermalSubsystem/Fans/Fan2",
+		LastUpdate:   "2026-10-19 11:36:00",
+	}
+	fanRow := func(fan sm.HWInvFan) []driver.Value {
+		return []driver.Value{fan.ID, fan.ParentID, fan.ParentType,
+			fan.RfEndpointID, fan.Name, fan.PhysicalContext, fan.ServiceLabel,
+			fan.Status, fan.Health, fan.Flag, fan.FRUID, fan.Manufacturer,
+			fan.Model, fan.PartNumber, fan.SparePartNumber, fan.SerialNumber,
+			fan.OdataID, fan.LastUpdate}
 	}
 
 	sqq := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	query1, _, _ := sqq.Select(columns...).
+		From(hwInvFanTable + " " + hwInvFanAlias).
+		OrderBy(hwInvFanParentIdColAlias, hwInvFanIdColAlias).ToSql()
 
-	delete1, _, _ := sqq.Delete(hwInvHistTable).
-		Where(sq.Eq{hwInvHistIdCol: "x0c0s0b0n0"}).ToSql()
+	query2, _, _ := sqq.Select(columns...).
+		From(hwInvFanTable + " " + hwInvFanAlias).
+		Where(sq.Eq{hwInvFanParentIdColAlias: []string{testFan2.ParentID}}).
+		Where(sq.Eq{hwInvFanHealthColAlias: []string{testFan2.Health}}).
+		OrderBy(hwInvFanParentIdColAlias, hwInvFanIdColAlias).ToSql()
 
 	tests := []struct {
-		id              string
+		f_opts          []HWInvFanFiltFunc
+		dbRows          [][]driver.Value
+		dbError         error
 		expectedPrepare string
 		expectedArgs    []driver.Value
-		expectedError   error
+		expectedFans    []*sm.HWInvFan
+		expectedErr     error
 	}{{
-		id:              testHWInvHist1.ID,
-		expectedPrepare: regexp.QuoteMeta(delete1),
-		expectedArgs:    []driver.Value{testHWInvHist1.ID},
-		expectedError:   nil,
+		f_opts:          []HWInvFanFiltFunc{},
+		dbRows:          [][]driver.Value{fanRow(testFan1), fanRow(testFan2)},
+		dbError:         nil,
+		expectedPrepare: regexp.QuoteMeta(query1),
+		expectedArgs:    []driver.Value{},
+		expectedFans:    []*sm.HWInvFan{&testFan1, &testFan2},
+		expectedErr:     nil,
 	}, {
-		id:              testHWInvHist1.ID,
-		expectedPrepare: regexp.QuoteMeta(delete1),
-		expectedArgs:    []driver.Value{testHWInvHist1.ID},
-		expectedError:   sql.ErrNoRows,
+		f_opts: []HWInvFanFiltFunc{
+			Fan_ParentIDs([]string{testFan2.ParentID}),
+			Fan_Healths([]string{testFan2.Health}),
+		},
+		dbRows:          [][]driver.Value{fanRow(testFan2)},
+		dbError:         nil,
+		expectedPrepare: regexp.QuoteMeta(query2),
+		expectedArgs:    []driver.Value{testFan2.ParentID, testFan2.Health},
+		expectedFans:    []*sm.HWInvFan{&testFan2},
+		expectedErr:     nil,
+	}, {
+		f_opts:          []HWInvFanFiltFunc{},
+		dbRows:          nil,
+		dbError:         sql.ErrNoRows,
+		expectedPrepare: regexp.QuoteMeta(query1),
+		expectedArgs:    nil,
+		expectedFans:    nil,
+		expectedErr:     nil,
 	}}
 
 	for i, test := range tests {
 		ResetMockDB()
-		if test.expectedError != nil {
-			mockPG.ExpectPrepare(test.expectedPrepare).ExpectExec().WillReturnError(test.expectedError)
-		} else {
-			mockPG.ExpectPrepare(test.expectedPrepare).ExpectExec().WithArgs(test.expectedArgs...).WillReturnResult(sqlmock.NewResult(0, 1))
+		rows := sqlmock.NewRows(columns)
+		for _, row := range test.dbRows {
+			rows.AddRow(row...)
 		}
 
-		_, err := dPG.DeleteHWInvHistByLocID(test.id)
-		if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
-			t.Errorf("Test %v Failed: Sql expectations were not met: %s", i, mock_err)
+		mockPG.ExpectBegin()
+		if test.dbError != nil {
+			mockPG.ExpectPrepare(test.expectedPrepare).ExpectQuery().WillReturnError(test.dbError)
+			mockPG.ExpectRollback()
+		} else if test.expectedErr == nil {
+			if len(test.expectedArgs) > 0 {
+				mockPG.ExpectPrepare(test.expectedPrepare).ExpectQuery().WithArgs(test.expectedArgs...).WillReturnRows(rows)
+			} else {
+				mockPG.ExpectPrepare(test.expectedPrepare).ExpectQuery().WillReturnRows(rows)
+			}
+			mockPG.ExpectCommit()
 		}
-		if test.expectedError == nil && err != nil {
-			t.Errorf("Test %v Failed: Unexpected error received: %s", i, err)
-		} else if test.expectedError != nil && err == nil {
-			t.Errorf("Test %v Failed: Expected an error (%s).", i, test.expectedError)
+
+		fans, err := dPG.GetHWInvFanFilter(test.f_opts...)
+		if test.expectedErr == nil {
+			if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
+				t.Errorf("Test %v Failed: Sql expectations were not met: %s", i, mock_err)
+			}
+		}
+		if test.dbError == nil && test.expectedErr == nil {
+			if err != nil {
+				t.Errorf("Test %v Failed: Unexpected error received: %s", i, err)
+			} else if !reflect.DeepEqual(test.expectedFans, fans) {
+				t.Errorf("Test %v Failed: Expected fans '%v'; Recieved fans '%v'", i, test.expectedFans, fans)
+			}
+		} else if err == nil {
+			t.Errorf("Test %v Failed: Expected an error.", i)
 		}
 	}
 }
 
-func TestDeleteHWInvHistByFRUID(t *testing.T) {
-	testHWInvHist1 := sm.HWInvHist{
-		ID:        "x5c4s3b2n1p0",
-		FruId:     "MFR-PARTNUMBER-SERIALNUMBER_1",
-		Timestamp: "2020-01-21 11:36:00",
-		EventType: "Added",
+func TestPgGetCompHealthContributors(t *testing.T) {
+	columns := addAliasToCols(compHealthAlias, compHealthCols, compHealthCols)
+
+	testHC1 := sm.CompHealthContributor{
+		CompID:     "x5c4s3b0n0",
+		ID:         "x5c4s3b0n0d3",
+		Type:       "Memory",
+		OdataID:    "/redfish/v1/Systems/Node0/Memory/DIMM3",
+		Health:     "Critical",
+		Flag:       "Alert",
+		Source:     "Discovery",
+		LastUpdate: "2026-10-19 11:36:00",
+	}
+	testHC2 := sm.CompHealthContributor{
+		CompID:     "x5c4s3b0n0",
+		OdataID:    "/redfish/v1/Chassis/Node0/PowerSubsystem/PowerSupplies/PSU1",
+		Health:     "Warning",
+		Flag:       "Warning",
+		Source:     "Event",
+		LastUpdate: "2026-10-19 11:37:00",
+	}
+	hcRow := func(hc sm.CompHealthContributor) []driver.Value {
+		return []driver.Value{hc.CompID, hc.OdataID, hc.ID, hc.Type,
+			hc.Health, hc.HealthRollUp, hc.State, hc.Flag, hc.Source,
+			hc.LastUpdate}
 	}
 
 	sqq := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
-
-	delete1, _, _ := sqq.Delete(hwInvHistTable).
-		Where(sq.Eq{hwInvHistFruIdCol: "MFR-PARTNUMBER-SERIALNUMBER_1"}).ToSql()
+	query1, _, _ := sqq.Select(columns...).
+		From(compHealthTable + " " + compHealthAlias).
+		Where(sq.Eq{compHealthCompIdColAlias: []string{"x5c4s3b0n0"}}).
+		OrderBy(compHealthCompIdColAlias, compHealthOdataIdColAlias).ToSql()
 
 	tests := []struct {
-		fruid           string
+		id              string
+		dbRows          [][]driver.Value
+		dbError         error
 		expectedPrepare string
 		expectedArgs    []driver.Value
-		expectedError   error
+		expectedHCs     []*sm.CompHealthContributor
 	}{{
-		fruid:           testHWInvHist1.FruId,
-		expectedPrepare: regexp.QuoteMeta(delete1),
-		expectedArgs:    []driver.Value{testHWInvHist1.FruId},
-		expectedError:   nil,
+		id:              "x5c4s3b0n0",
+		dbRows:          [][]driver.Value{hcRow(testHC1), hcRow(testHC2)},
+		expectedPrepare: regexp.QuoteMeta(query1),
+		expectedArgs:    []driver.Value{"x5c4s3b0n0"},
+		expectedHCs:     []*sm.CompHealthContributor{&testHC1, &testHC2},
 	}, {
-		fruid:           testHWInvHist1.FruId,
-		expectedPrepare: regexp.QuoteMeta(delete1),
-		expectedArgs:    []driver.Value{testHWInvHist1.FruId},
-		expectedError:   sql.ErrNoRows,
+		id:              "X5C4S3B0N0",
+		dbRows:          [][]driver.Value{},
+		expectedPrepare: regexp.QuoteMeta(query1),
+		expectedArgs:    []driver.Value{"x5c4s3b0n0"},
+		expectedHCs:     []*sm.CompHealthContributor{},
+	}, {
+		id:              "x5c4s3b0n0",
+		dbError:         sql.ErrConnDone,
+		expectedPrepare: regexp.QuoteMeta(query1),
+		expectedArgs:    []driver.Value{"x5c4s3b0n0"},
 	}}
 
 	for i, test := range tests {
 		ResetMockDB()
-		if test.expectedError != nil {
-			mockPG.ExpectPrepare(test.expectedPrepare).ExpectExec().WillReturnError(test.expectedError)
+		rows := sqlmock.NewRows(columns)
+		for _, row := range test.dbRows {
+			rows.AddRow(row...)
+		}
+
+		mockPG.ExpectBegin()
+		if test.dbError != nil {
+			mockPG.ExpectPrepare(test.expectedPrepare).ExpectQuery().WithArgs(test.expectedArgs...).WillReturnError(test.dbError)
+			mockPG.ExpectRollback()
 		} else {
-			mockPG.ExpectPrepare(test.expectedPrepare).ExpectExec().WithArgs(test.expectedArgs...).WillReturnResult(sqlmock.NewResult(0, 1))
+			mockPG.ExpectPrepare(test.expectedPrepare).ExpectQuery().WithArgs(test.expectedArgs...).WillReturnRows(rows)
+			mockPG.ExpectCommit()
 		}
 
-		_, err := dPG.DeleteHWInvHistByFRUID(test.fruid)
+		hcs, err := dPG.GetCompHealthContributors(test.id)
 		if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
 			t.Errorf("Test %v Failed: Sql expectations were not met: %s", i, mock_err)
 		}
-		if test.expectedError == nil && err != nil {
-			t.Errorf("Test %v Failed: Unexpected error received: %s", i, err)
-		} else if test.expectedError != nil && err == nil {
-			t.Errorf("Test %v Failed: Expected an error (%s).", i, test.expectedError)
+		if test.dbError == nil {
+			if err != nil {
+				t.Errorf("Test %v Failed: Unexpected error received: %s", i, err)
+			} else if !reflect.DeepEqual(test.expectedHCs, hcs) {
+				t.Errorf("Test %v Failed: Expected contributors '%v'; Recieved '%v'", i, test.expectedHCs, hcs)
+			}
+		} else if err == nil {
+			t.Errorf("Test %v Failed: Expected an error.", i)
 		}
 	}
 }
 
-func TestDeleteHWInvHistAll(t *testing.T) {
+func TestPgGetEventRule(t *testing.T) {
+	columns := addAliasToCols(eventRulesAlias, eventRulesCols, eventRulesCols)
 
-	sqq := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	testRule := sm.EventRule{
+		Name:     "OemPowerOn",
+		Priority: 10,
+		Match: sm.EventRuleMatch{
+			MessageId: "OemPowerOn",
+			Registry:  "^OemAlerts$",
+		},
+		Action: sm.EventRuleAction{
+			State:  base.StateOn.String(),
+			Target: sm.EventRuleTargetOrigin,
+		},
+		Source: sm.EventRuleSourceAPI,
+	}
+	ruleJSON, _ := json.Marshal(sm.EventRule{
+		Priority: testRule.Priority,
+		Match:    testRule.Match,
+		Action:   testRule.Action,
+	})
 
-	delete1, _, _ := sqq.Delete(hwInvHistTable).ToSql()
+	sqq := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	query1, _, _ := sqq.Select(columns...).
+		From(eventRulesTable+" "+eventRulesAlias).
+		Where(sq.Eq{eventRulesNameColAlias: []string{"OemPowerOn"}}).
+		OrderBy(eventRulesPriorityColAlias+" DESC", eventRulesNameColAlias).ToSql()
 
 	tests := []struct {
+		name            string
+		dbRows          [][]driver.Value
+		dbError         error
 		expectedPrepare string
-		expectedError   error
+		expectedArgs    []driver.Value
+		expectedRule    *sm.EventRule
 	}{{
-		expectedPrepare: regexp.QuoteMeta(delete1),
-		expectedError:   nil,
+		name:            "OemPowerOn",
+		dbRows:          [][]driver.Value{{"OemPowerOn", ruleJSON}},
+		expectedPrepare: regexp.QuoteMeta(query1),
+		expectedArgs:    []driver.Value{"OemPowerOn"},
+		expectedRule:    &testRule,
 	}, {
-		expectedPrepare: regexp.QuoteMeta(delete1),
-		expectedError:   sql.ErrNoRows,
+		name:            "OemPowerOn",
+		dbRows:          [][]driver.Value{},
+		expectedPrepare: regexp.QuoteMeta(query1),
+		expectedArgs:    []driver.Value{"OemPowerOn"},
+		expectedRule:    nil,
+	}, {
+		name:            "OemPowerOn",
+		dbError:         sql.ErrConnDone,
+		expectedPrepare: regexp.QuoteMeta(query1),
+		expectedArgs:    []driver.Value{"OemPowerOn"},
 	}}
 
 	for i, test := range tests {
 		ResetMockDB()
-		if test.expectedError != nil {
-			mockPG.ExpectPrepare(test.expectedPrepare).ExpectExec().WillReturnError(test.expectedError)
+		rows := sqlmock.NewRows(columns)
+		for _, row := range test.dbRows {
+			rows.AddRow(row...)
+		}
+
+		mockPG.ExpectBegin()
+		if test.dbError != nil {
+			mockPG.ExpectPrepare(test.expectedPrepare).ExpectQuery().WithArgs(test.expectedArgs...).WillReturnError(test.dbError)
+			mockPG.ExpectRollback()
 		} else {
-			mockPG.ExpectPrepare(test.expectedPrepare).ExpectExec().WillReturnResult(sqlmock.NewResult(0, 1))
+			mockPG.ExpectPrepare(test.expectedPrepare).ExpectQuery().WithArgs(test.expectedArgs...).WillReturnRows(rows)
+			mockPG.ExpectCommit()
 		}
 
-		_, err := dPG.DeleteHWInvHistAll()
+		r, err := dPG.GetEventRule(test.name)
 		if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
 			t.Errorf("Test %v Failed: Sql expectations were not met: %s", i, mock_err)
 		}
-		if test.expectedError == nil && err != nil {
-			t.Errorf("Test %v Failed: Unexpected error received: %s", i, err)
-		} else if test.expectedError != nil && err == nil {
-			t.Errorf("Test %v Failed: Expected an error (%s).", i, test.expectedError)
+		if test.dbError == nil {
+			if err != nil {
+				t.Errorf("Test %v Failed: Unexpected error received: %s", i, err)
+			} else if !reflect.DeepEqual(test.expectedRule, r) {
+				t.Errorf("Test %v Failed: Expected rule '%v'; Recieved '%v'", i, test.expectedRule, r)
+			}
+		} else if err == nil {
+			t.Errorf("Test %v Failed: Expected an error.", i)
 		}
 	}
 }
 
-func TestDeleteHWInvHistFilter(t *testing.T) {
-	testHWInvHist1 := sm.HWInvHist{
-		ID:        "x5c4s3b2n1p0",
-		FruId:     "MFR-PARTNUMBER-SERIALNUMBER_1",
-		Timestamp: "2020-01-21 11:36:00",
-		EventType: "Added",
+func TestPgInsertEventRule(t *testing.T) {
+	testRule := sm.EventRule{
+		Name:     "OemPowerOff",
+		Priority: 5,
+		Match:    sm.EventRuleMatch{MessageId: "OemPowerOff"},
+		Action:   sm.EventRuleAction{State: base.StateOff.String()},
+		Source:   sm.EventRuleSourceAPI,
 	}
-	timeStartArg, _ := time.Parse(time.RFC3339, "2020-01-01T00:00:00Z")
-	timeEndArg, _ := time.Parse(time.RFC3339, "2020-02-01T00:00:00Z")
+	ruleJSON, _ := eventRuleJSON(&testRule)
 
 	sqq := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
-
-	delete1, _, _ := sqq.Delete(hwInvHistTable).ToSql()
-
-	delete2, _, _ := sqq.Delete(hwInvHistTable).
-		Where(sq.Eq{hwInvHistIdCol: []string{testHWInvHist1.ID}}).
-		Where(sq.Gt{hwInvHistTimestampCol: timeStartArg}).
-		Where(sq.Lt{hwInvHistTimestampCol: timeEndArg}).ToSql()
-
-	delete3, _, _ := sqq.Delete(hwInvHistTable).
-		Where(sq.Eq{hwInvHistEventTypeCol: []string{sm.HWInvHistEventTypeAdded}}).ToSql()
+	insert1, _, _ := sqq.Insert(eventRulesTable).
+		Columns(eventRulesNameCol, eventRulesPriorityCol, eventRulesRuleCol).
+		Values("", 0, "").ToSql()
 
 	tests := []struct {
-		f_opts          []HWInvHistFiltFunc
-		dbError         error
-		expectedPrepare string
-		expectedArgs    []driver.Value
-		expectedErr     error
+		dbError       error
+		expectedError error
 	}{{
-		f_opts:          []HWInvHistFiltFunc{},
-		dbError:         nil,
-		expectedPrepare: regexp.QuoteMeta(delete1),
-		expectedArgs:    []driver.Value{},
-		expectedErr:     nil,
-	}, {
-		f_opts: []HWInvHistFiltFunc{
-			HWInvHist_ID(testHWInvHist1.ID),
-			HWInvHist_StartTime("2020-01-01T00:00:00Z"),
-			HWInvHist_EndTime("2020-02-01T00:00:00Z"),
-		},
-		dbError:         nil,
-		expectedPrepare: regexp.QuoteMeta(delete2),
-		expectedArgs:    []driver.Value{testHWInvHist1.ID, timeStartArg, timeEndArg},
-		expectedErr:     nil,
+		dbError:       nil,
+		expectedError: nil,
 	}, {
-		f_opts:          []HWInvHistFiltFunc{HWInvHist_EventTypes([]string{"foo"})},
-		dbError:         nil,
-		expectedPrepare: "",
-		expectedArgs:    nil,
-		expectedErr:     ErrHMSDSArgBadHWInvHistEventType,
-	}, {
-		f_opts:          []HWInvHistFiltFunc{HWInvHist_EventTypes([]string{sm.HWInvHistEventTypeAdded})},
-		dbError:         sql.ErrNoRows,
-		expectedPrepare: regexp.QuoteMeta(delete3),
-		expectedArgs:    []driver.Value{sm.HWInvHistEventTypeAdded},
-		expectedErr:     nil,
+		dbError:       &pq.Error{Code: "23505"},
+		expectedError: ErrHMSDSDuplicateKey,
 	}}
 
 	for i, test := range tests {
 		ResetMockDB()
+		mockPG.ExpectBegin()
 		if test.dbError != nil {
-			mockPG.ExpectPrepare(test.expectedPrepare).ExpectExec().WillReturnError(test.dbError)
-		} else if test.expectedErr == nil {
-			if len(test.expectedArgs) > 0 {
-				mockPG.ExpectPrepare(test.expectedPrepare).ExpectExec().WithArgs(test.expectedArgs...).WillReturnResult(sqlmock.NewResult(0, 1))
-				//mockPG.ExpectExec(test.expectedPrepare).WithArgs(test.expectedArgs...).WillReturnResult(sqlmock.NewResult(0, 1))
-			} else {
-				mockPG.ExpectPrepare(test.expectedPrepare).ExpectExec().WillReturnResult(sqlmock.NewResult(0, 1))
-				//mockPG.ExpectExec(test.expectedPrepare).WillReturnResult(sqlmock.NewResult(0, 1))
-			}
+			mockPG.ExpectPrepare(regexp.QuoteMeta(insert1)).ExpectExec().WithArgs(testRule.Name, testRule.Priority, ruleJSON).WillReturnError(test.dbError)
+			mockPG.ExpectRollback()
+		} else {
+			mockPG.ExpectPrepare(regexp.QuoteMeta(insert1)).ExpectExec().WithArgs(testRule.Name, testRule.Priority, ruleJSON).WillReturnResult(sqlmock.NewResult(0, 1))
+			mockPG.ExpectCommit()
 		}
 
-		_, err := dPG.DeleteHWInvHistFilter(test.f_opts...)
-		if test.expectedErr == nil {
-			if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
-				t.Errorf("Test %v Failed: Sql expectations were not met: %s", i, mock_err)
-			}
+		err := dPG.InsertEventRule(&testRule)
+		if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
+			t.Errorf("Test %v Failed: Sql expectations were not met: %s", i, mock_err)
 		}
-		if test.dbError == nil && test.expectedErr == nil && err != nil {
-			t.Errorf("Test %v Failed: Unexpected error received: %s", i, err)
-		} else if test.dbError != nil && test.expectedErr != nil && err == nil {
-			t.Errorf("Test %v Failed: Expected an error (%s).", i, test.expectedErr)
+		if err != test.expectedError {
+			t.Errorf("Test %v Failed: Expected error '%v'; Recieved '%v'", i, test.expectedError, err)
 		}
 	}
 }
 
-///////////////////////////////////////////////////////////////////////////////
-// Redfish Endpoints Tests
-///////////////////////////////////////////////////////////////////////////////
+func TestPgGetRFEventLogFilter(t *testing.T) {
+	columns := addAliasToCols(rfEventLogAlias, rfEventLogCols, rfEventLogCols)
+
+	testEnt1 := sm.RFEventLogEntry{
+		ID:                12,
+		Timestamp:         "2026-10-19T11:37:00Z",
+		ComponentID:       "x3000c0s1b0n0",
+		RfEndpointID:      "x3000c0s1b0",
+		EventId:           "40",
+		Registry:          "ResourceEvent",
+		RegistryVersion:   "1.0",
+		MessageId:         "ResourceRemoved",
+		Severity:          "Critical",
+		Message:           "The resource has been removed.",
+		MessageArgs:       []string{"/redfish/v1/Systems/1/Memory/proc1dimm1"},
+		OriginOfCondition: "/redfish/v1/Systems/1/Memory/proc1dimm1",
+		FRUID:             "Memory.Hynix.1.2",
+	}
+	testEnt2 := sm.RFEventLogEntry{
+		ID:           11,
+		Timestamp:    "2026-10-19T11:36:00Z",
+		ComponentID:  "x3000c0s1b0n0",
+		RfEndpointID: "x3000c0s1b0",
+		Registry:     "iLOEvents",
+		MessageId:    "ServerPoweredOn",
+		Severity:     "OK",
+	}
+	entRow := func(ent sm.RFEventLogEntry) []driver.Value {
+		args, _ := json.Marshal(ent.MessageArgs)
+		return []driver.Value{ent.ID, ent.Timestamp, ent.ComponentID,
+			ent.RfEndpointID, ent.EventTimestamp, ent.EventId, ent.Registry,
+			ent.RegistryVersion, ent.MessageId, ent.Severity, ent.Message,
+			args, ent.OriginOfCondition, ent.FRUID}
+	}
+	since, _ := time.Parse(time.RFC3339, "2026-10-19T00:00:00Z")
 
-func TestPgPatchRFEndpointNoDiscInfo(t *testing.T) {
-	trueBool := true
-	//falseBool := false
-	columns := rfEPsAllCols
-	emptyDiscInfo := rf.DiscoveryInfo{}
-	badHost := "x0c0s0b0"
-	ipAddr := "10.254.2.13"
+	sqq := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	query1, _, _ := sqq.Select(columns...).
+		From(rfEventLogTable + " " + rfEventLogAlias).
+		Where(sq.Or{
+			sq.Eq{rfEventLogCompIdColAlias: []string{"x3000c0s1b0n0"}},
+			sq.Eq{rfEventLogRfEPColAlias: []string{"x3000c0s1b0n0"}},
+		}).
+		Where(sq.Eq{"LOWER(" + rfEventLogSeverityColAlias + ")": []string{"critical", "ok"}}).
+		Where(sq.GtOrEq{rfEventLogTimestampColAlias: since}).
+		Where(sq.Lt{rfEventLogIdColAlias: int64(20)}).
+		OrderBy(rfEventLogIdColAlias + " DESC").
+		Limit(2).ToSql()
+	query2, _, _ := sqq.Select(columns...).
+		From(rfEventLogTable + " " + rfEventLogAlias).
+		OrderBy(rfEventLogIdColAlias + " DESC").ToSql()
 
 	tests := []struct {
-		id                  string
-		epp                 sm.RedfishEndpointPatch
-		dbErrorGet1         error
-		dbRowsGet1          [][]driver.Value
-		expectedPrepareGet1 string
-		expectedArgsGet1    []driver.Value
-		dbError             error
-		expectedPrepare     string
-		expectedArgs        []driver.Value
-		dbErrorGet2         error
-		dbRowsGet2          [][]driver.Value
-		expectedPrepareGet2 string
-		expectedArgsGet2    []driver.Value
-		expectedResp        *sm.RedfishEndpoint
-		expectedIDs         []string
-		expectedErr         error
-		expectErr           bool
-	}{{ // Test 0 Success
-		id: "x0c0s1b1",
-		epp: sm.RedfishEndpointPatch{
-			Enabled:        &trueBool,
-			RediscOnUpdate: &trueBool,
-		},
-		dbErrorGet1: nil,
-		dbRowsGet1: [][]driver.Value{
-			[]driver.Value{"x0c0s1b1", "NodeBMC", "", "10.254.2.12", "", "10.254.2.12", false, "da4faffe-6491-4f3f-ab54-3bf8fce57531", "root", "", false, false, "a4bf012e85b5", "10.254.2.12", false, "", json.RawMessage(`{}`)},
-		},
-		expectedPrepareGet1: regexp.QuoteMeta(getRFEndpointPrefix + " WHERE (id = $1);"),
-		expectedArgsGet1:    []driver.Value{"x0c0s1b1"},
-		dbError:             nil,
-		expectedPrepare:     regexp.QuoteMeta(updatePgRFEndpointNoDiscInfoQuery),
-		expectedArgs:        []driver.Value{"NodeBMC", "", "10.254.2.12", "", "10.254.2.12", true, "da4faffe-6491-4f3f-ab54-3bf8fce57531", "root", "", false, false, "a4bf012e85b5", "10.254.2.12", true, "", "x0c0s1b1"},
-		dbErrorGet2:         nil,
-		dbRowsGet2: [][]driver.Value{
-			[]driver.Value{"x0c0s1b1", "NodeBMC", "", "10.254.2.12", "", "10.254.2.12", true, "da4faffe-6491-4f3f-ab54-3bf8fce57531", "root", "", false, false, "a4bf012e85b5", "10.254.2.12", true, "", json.RawMessage(`{}`)},
-		},
-		expectedPrepareGet2: regexp.QuoteMeta(getRFEndpointByIDQuery),
-		expectedArgsGet2:    []driver.Value{"x0c0s1b1"},
-		expectedResp: &sm.RedfishEndpoint{
-			RedfishEPDescription: rf.RedfishEPDescription{
-				ID:             "x0c0s1b1",
-				Type:           "NodeBMC",
-				Name:           "",
-				Hostname:       "10.254.2.12",
-				Domain:         "",
-				FQDN:           "10.254.2.12",
-				Enabled:        true,
-				UUID:           "da4faffe-6491-4f3f-ab54-3bf8fce57531",
-				User:           "root",
-				Password:       "",
-				UseSSDP:        false,
-				MACRequired:    false,
-				MACAddr:        "a4bf012e85b5",
-				IPAddr:         "10.254.2.12",
-				RediscOnUpdate: true,
-				TemplateID:     "",
-				DiscInfo:       emptyDiscInfo,
-			},
-		},
-		expectedIDs: []string{},
-		expectedErr: nil,
-		expectErr:   false,
-	}, { // Test 1 No Update
-		id:          "x0c0s1b1",
-		epp:         sm.RedfishEndpointPatch{},
-		dbErrorGet1: nil,
-		dbRowsGet1: [][]driver.Value{
-			[]driver.Value{"x0c0s1b1", "NodeBMC", "", "10.254.2.12", "", "10.254.2.12", false, "da4faffe-6491-4f3f-ab54-3bf8fce57531", "root", "", false, false, "a4bf012e85b5", "10.254.2.12", false, "", json.RawMessage(`{}`)},
-		},
-		expectedPrepareGet1: regexp.QuoteMeta(getRFEndpointPrefix + " WHERE (id = $1);"),
-		expectedArgsGet1:    []driver.Value{"x0c0s1b1"},
-		dbError:             nil,
-		expectedPrepare:     "",
-		expectedArgs:        []driver.Value{},
-		dbErrorGet2:         nil,
-		dbRowsGet2:          [][]driver.Value{},
-		expectedPrepareGet2: "",
-		expectedArgsGet2:    []driver.Value{},
-		expectedResp: &sm.RedfishEndpoint{
-			RedfishEPDescription: rf.RedfishEPDescription{
-				ID:             "x0c0s1b1",
-				Type:           "NodeBMC",
-				Name:           "",
-				Hostname:       "10.254.2.12",
-				Domain:         "",
-				FQDN:           "10.254.2.12",
-				Enabled:        false,
-				UUID:           "da4faffe-6491-4f3f-ab54-3bf8fce57531",
-				User:           "root",
-				Password:       "",
-				UseSSDP:        false,
-				MACRequired:    false,
-				MACAddr:        "a4bf012e85b5",
-				IPAddr:         "10.254.2.12",
-				RediscOnUpdate: false,
-				TemplateID:     "",
-				DiscInfo:       emptyDiscInfo,
-			},
-		},
-		expectedIDs: []string{},
-		expectedErr: nil,
-		expectErr:   true,
-	}, { // Test 2 Update would result in bad RFEndpoint
-		id: "x0c0s1b1",
-		epp: sm.RedfishEndpointPatch{
-			Hostname: &badHost,
-		},
-		dbErrorGet1: nil,
-		dbRowsGet1: [][]driver.Value{
-			[]driver.Value{"x0c0s1b1", "NodeBMC", "", "10.254.2.12", "", "10.254.2.12", false, "da4faffe-6491-4f3f-ab54-3bf8fce57531", "root", "", false, false, "a4bf012e85b5", "10.254.2.12", false, "", json.RawMessage(`{}`)},
-		},
-		expectedPrepareGet1: regexp.QuoteMeta(getRFEndpointPrefix + " WHERE (id = $1);"),
-		expectedArgsGet1:    []driver.Value{"x0c0s1b1"},
-		dbError:             nil,
-		expectedPrepare:     "",
-		expectedArgs:        []driver.Value{},
-		dbErrorGet2:         nil,
-		dbRowsGet2:          [][]driver.Value{},
-		expectedPrepareGet2: "",
-		expectedArgsGet2:    []driver.Value{},
-		expectedResp:        nil,
-		expectedErr:         fmt.Errorf("Some Error"),
-		expectErr:           true,
-	}, { // Test 3 No RFEndpoint
-		id:                  "x0c0s1b1",
-		epp:                 sm.RedfishEndpointPatch{},
-		dbErrorGet1:         nil,
-		dbRowsGet1:          [][]driver.Value{},
-		expectedPrepareGet1: regexp.QuoteMeta(getRFEndpointPrefix + " WHERE (id = $1);"),
-		expectedArgsGet1:    []driver.Value{"x0c0s1b1"},
-		dbError:             nil,
-		expectedPrepare:     "",
-		expectedArgs:        []driver.Value{},
-		dbErrorGet2:         nil,
-		dbRowsGet2:          [][]driver.Value{},
-		expectedPrepareGet2: "",
-		expectedArgsGet2:    []driver.Value{},
-		expectedResp:        nil,
-		expectedIDs:         []string{},
-		expectedErr:         nil,
-		expectErr:           true,
-	}, { // Test 4 IPAddress update
-		id: "x0c0s1b1",
-		epp: sm.RedfishEndpointPatch{
-			IPAddr: &ipAddr,
-		},
-		dbErrorGet1: nil,
-		dbRowsGet1: [][]driver.Value{
-			[]driver.Value{"x0c0s1b1", "NodeBMC", "", "10.254.2.12", "", "10.254.2.12", false, "da4faffe-6491-4f3f-ab54-3bf8fce57531", "root", "", false, false, "a4bf012e85b5", "10.254.2.12", false, "", json.RawMessage(`{}`)},
-		},
-		expectedPrepareGet1: regexp.QuoteMeta(getRFEndpointPrefix + " WHERE (id = $1);"),
-		expectedArgsGet1:    []driver.Value{"x0c0s1b1"},
-		dbError:             nil,
-		expectedPrepare:     regexp.QuoteMeta(updatePgRFEndpointNoDiscInfoQuery),
-		expectedArgs:        []driver.Value{"NodeBMC", "", "10.254.2.13", "", "10.254.2.13", false, "da4faffe-6491-4f3f-ab54-3bf8fce57531", "root", "", false, false, "a4bf012e85b5", "10.254.2.13", false, "", "x0c0s1b1"},
-		dbErrorGet2:         nil,
-		dbRowsGet2: [][]driver.Value{
-			[]driver.Value{"x0c0s1b1", "NodeBMC", "", "10.254.2.13", "", "10.254.2.13", false, "da4faffe-6491-4f3f-ab54-3bf8fce57531", "root", "", false, false, "a4bf012e85b5", "10.254.2.13", false, "", json.RawMessage(`{}`)},
-		},
-		expectedPrepareGet2: regexp.QuoteMeta(getRFEndpointByIDQuery),
-		expectedArgsGet2:    []driver.Value{"x0c0s1b1"},
-		expectedResp: &sm.RedfishEndpoint{
-			RedfishEPDescription: rf.RedfishEPDescription{
-				ID:             "x0c0s1b1",
-				Type:           "NodeBMC",
-				Name:           "",
-				Hostname:       "10.254.2.13",
-				Domain:         "",
-				FQDN:           "10.254.2.13",
-				Enabled:        false,
-				UUID:           "da4faffe-6491-4f3f-ab54-3bf8fce57531",
-				User:           "root",
-				Password:       "",
-				UseSSDP:        false,
-				MACRequired:    false,
-				MACAddr:        "a4bf012e85b5",
-				IPAddr:         "10.254.2.13",
-				RediscOnUpdate: false,
-				TemplateID:     "",
-				DiscInfo:       emptyDiscInfo,
-			},
+		fltr            []RFEventLogFiltFunc
+		dbRows          [][]driver.Value
+		dbError         error
+		expectedPrepare string
+		expectedArgs    []driver.Value
+		expectedErr     error
+		expectedEnts    []*sm.RFEventLogEntry
+	}{{
+		fltr: []RFEventLogFiltFunc{
+			EL_IDs([]string{"x3000c0s1b0n0"}),
+			EL_Severities([]string{"Critical", "OK"}),
+			EL_Since("2026-10-19T00:00:00Z"),
+			EL_Page(20, 2),
 		},
-		expectedIDs: []string{},
-		expectedErr: nil,
-		expectErr:   false,
+		dbRows:          [][]driver.Value{entRow(testEnt1), entRow(testEnt2)},
+		expectedPrepare: regexp.QuoteMeta(query1),
+		expectedArgs: []driver.Value{"x3000c0s1b0n0", "x3000c0s1b0n0",
+			"critical", "ok", since, int64(20)},
+		expectedEnts: []*sm.RFEventLogEntry{&testEnt1, &testEnt2},
+	}, {
+		fltr:            []RFEventLogFiltFunc{},
+		dbRows:          [][]driver.Value{},
+		expectedPrepare: regexp.QuoteMeta(query2),
+		expectedArgs:    []driver.Value{},
+		expectedEnts:    []*sm.RFEventLogEntry{},
+	}, {
+		fltr:            []RFEventLogFiltFunc{},
+		dbError:         sql.ErrConnDone,
+		expectedPrepare: regexp.QuoteMeta(query2),
+		expectedArgs:    []driver.Value{},
+		expectedErr:     sql.ErrConnDone,
+	}, {
+		fltr:        []RFEventLogFiltFunc{EL_Since("yesterday")},
+		expectedErr: ErrHMSDSArgBadTimeFormat,
 	}}
 
 	for i, test := range tests {
 		ResetMockDB()
-		// before we actually execute our api function, we need to expect required DB actions
-		rows1 := sqlmock.NewRows(columns)
-		for _, row := range test.dbRowsGet1 {
-			rows1.AddRow(row...)
-		}
-		rows2 := sqlmock.NewRows(columns)
-		for _, row := range test.dbRowsGet2 {
-			rows2.AddRow(row...)
+		rows := sqlmock.NewRows(columns)
+		for _, row := range test.dbRows {
+			rows.AddRow(row...)
 		}
 
 		mockPG.ExpectBegin()
-		if test.dbErrorGet1 != nil {
-			mockPG.ExpectPrepare(ToPGQueryArgs(test.expectedPrepareGet1)).ExpectQuery().WillReturnError(test.dbErrorGet1)
+		if test.expectedPrepare == "" {
+			mockPG.ExpectRollback()
+		} else if test.dbError != nil {
+			mockPG.ExpectPrepare(test.expectedPrepare).ExpectQuery().WithArgs(test.expectedArgs...).WillReturnError(test.dbError)
 			mockPG.ExpectRollback()
 		} else {
-			mockPG.ExpectPrepare(ToPGQueryArgs(test.expectedPrepareGet1)).ExpectQuery().WithArgs(test.expectedArgsGet1...).WillReturnRows(rows1)
-			if test.expectErr {
-				mockPG.ExpectRollback()
-			} else if test.dbError != nil {
-				mockPG.ExpectPrepare(ToPGQueryArgs(test.expectedPrepare)).ExpectExec().WillReturnError(test.dbError)
-				mockPG.ExpectRollback()
-			} else {
-				mockPG.ExpectPrepare(ToPGQueryArgs(test.expectedPrepare)).ExpectExec().WithArgs(test.expectedArgs...).WillReturnResult(sqlmock.NewResult(0, 1))
-				if test.dbErrorGet2 != nil {
-					mockPG.ExpectPrepare(ToPGQueryArgs(test.expectedPrepareGet2)).ExpectQuery().WillReturnError(test.dbErrorGet2)
-					mockPG.ExpectRollback()
-				} else {
-					mockPG.ExpectPrepare(ToPGQueryArgs(test.expectedPrepareGet2)).ExpectQuery().WithArgs(test.expectedArgsGet2...).WillReturnRows(rows2)
-					mockPG.ExpectCommit()
-				}
-			}
+			mockPG.ExpectPrepare(test.expectedPrepare).ExpectQuery().WithArgs(test.expectedArgs...).WillReturnRows(rows)
+			mockPG.ExpectCommit()
 		}
 
-		resp, affectedIDs, err := dPG.PatchRFEndpointNoDiscInfo(test.id, test.epp)
-		// ensure all expectations have been met
+		ents, err := dPG.GetRFEventLogFilter(test.fltr...)
 		if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
 			t.Errorf("Test %v Failed: Sql expectations were not met: %s", i, mock_err)
 		}
-		if test.dbErrorGet1 == nil && test.dbError == nil && test.dbErrorGet2 == nil && test.expectedErr == nil {
-			if err != nil {
-				t.Errorf("Test %v Failed: Unexpected error received: %s", i, err)
-			} else {
-				if !reflect.DeepEqual(test.expectedResp, resp) {
-					t.Errorf("Test %v Failed: Expected RFEndpoint is %v - Received %v", i, test.expectedResp, resp)
-				}
-				if !reflect.DeepEqual(test.expectedIDs, affectedIDs) {
-					t.Errorf("Test %v Failed: Expected affectedIDs is %v - Received %v", i, test.expectedIDs, affectedIDs)
-				}
-			}
-		} else if err == nil {
-			t.Errorf("Test %v Failed: Expected an error.", i)
+		if err != test.expectedErr {
+			t.Errorf("Test %v Failed: Expected error '%v'; Recieved '%v'", i, test.expectedErr, err)
+		} else if err == nil && !reflect.DeepEqual(test.expectedEnts, ents) {
+			t.Errorf("Test %v Failed: Expected events '%v'; Recieved '%v'", i, test.expectedEnts, ents)
 		}
 	}
 }
 
-///////////////////////////////////////////////////////////////////////////////
-// Service Endpoint Query Tests
-///////////////////////////////////////////////////////////////////////////////
+func TestPgPruneRFEventLog(t *testing.T) {
+	before, _ := time.Parse(time.RFC3339, "2026-09-19T00:00:00Z")
 
-func TestPgGetServiceEndpointByID(t *testing.T) {
-	columns := []string{"rf_endpoint_id", "redfish_type", "redfish_subtype", "uuid", "odata_id", "rf_endpoint_fqdn", "service_info"}
+	sqq := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	delete1, _, _ := sqq.Delete(rfEventLogTable).
+		Where(sq.Lt{rfEventLogTimestampCol: before}).ToSql()
+	delete2, _, _ := sqq.Delete(rfEventLogTable).
+		Where(sq.Or{
+			sq.Lt{rfEventLogTimestampCol: before},
+			sq.Expr("id <= (SELECT id FROM rf_event_log ORDER BY id DESC LIMIT 1 OFFSET ?)", 1000),
+		}).ToSql()
 
 	tests := []struct {
-		svc             string
-		id              string
-		dbRows          [][]driver.Value
-		dbError         error
+		maxEntries      int
 		expectedPrepare string
 		expectedArgs    []driver.Value
-		expectedEP      *sm.ServiceEndpoint
-		expectedErr     error
+		dbError         error
+		expectedNum     int64
 	}{{
-		svc: "UpdateService",
-		id:  "x0c0s21b0",
-		dbRows: [][]driver.Value{
-			[]driver.Value{"x0c0s21b0", "UpdateService", "", "", "/redfish/v1/UpdateService", "10.4.0.8", json.RawMessage(`{}`)},
-		},
-		dbError:         nil,
-		expectedPrepare: regexp.QuoteMeta(getServiceEndpointPrefix + " WHERE (rf_endpoint_id = $1) AND (redfish_type = $2)"),
-		expectedArgs:    []driver.Value{"x0c0s21b0", "UpdateService"},
-		expectedEP:      &stest.TestServiceEndpointUpdate1,
-		expectedErr:     nil,
-	}, {
-		svc:             "UpdateService",
-		id:              "foo",
-		dbRows:          nil,
-		dbError:         nil,
-		expectedPrepare: "",
-		expectedArgs:    nil,
-		expectedEP:      nil,
-		expectedErr:     ErrHMSDSArgBadID,
+		maxEntries:      0,
+		expectedPrepare: regexp.QuoteMeta(delete1),
+		expectedArgs:    []driver.Value{before},
+		expectedNum:     3,
 	}, {
-		svc:             "Update:Service",
-		id:              "x0c0s21b0",
-		dbRows:          nil,
-		dbError:         nil,
-		expectedPrepare: "",
-		expectedArgs:    nil,
-		expectedEP:      nil,
-		expectedErr:     ErrHMSDSArgBadRedfishType,
+		maxEntries:      1000,
+		expectedPrepare: regexp.QuoteMeta(delete2),
+		expectedArgs:    []driver.Value{before, 1000},
+		expectedNum:     7,
 	}, {
-		svc:             "UpdateService",
-		id:              "x0c0s21b0",
-		dbRows:          nil,
-		dbError:         sql.ErrNoRows,
-		expectedPrepare: regexp.QuoteMeta(getServiceEndpointPrefix + " WHERE (rf_endpoint_id = $1) AND (redfish_type = $2)"),
-		expectedArgs:    []driver.Value{"x0c0s21b0", "UpdateService"},
-		expectedEP:      nil,
-		expectedErr:     nil,
+		maxEntries:      1000,
+		expectedPrepare: regexp.QuoteMeta(delete2),
+		expectedArgs:    []driver.Value{before, 1000},
+		dbError:         sql.ErrConnDone,
 	}}
 
 	for i, test := range tests {
 		ResetMockDB()
-		// before we actually execute our api function, we need to expect required DB actions
-		rows := sqlmock.NewRows(columns)
-		for _, row := range test.dbRows {
-			rows.AddRow(row...)
-		}
-
 		mockPG.ExpectBegin()
 		if test.dbError != nil {
-			mockPG.ExpectPrepare(test.expectedPrepare).ExpectQuery().WillReturnError(test.dbError)
-			mockPG.ExpectRollback()
-		} else if test.expectedErr != nil {
+			mockPG.ExpectPrepare(test.expectedPrepare).ExpectExec().WithArgs(test.expectedArgs...).WillReturnError(test.dbError)
 			mockPG.ExpectRollback()
 		} else {
-			mockPG.ExpectPrepare(test.expectedPrepare).ExpectQuery().WithArgs(test.expectedArgs...).WillReturnRows(rows)
+			mockPG.ExpectPrepare(test.expectedPrepare).ExpectExec().WithArgs(test.expectedArgs...).WillReturnResult(sqlmock.NewResult(0, test.expectedNum))
 			mockPG.ExpectCommit()
 		}
 
-		sep, err := dPG.GetServiceEndpointByID(test.svc, test.id)
-		// ensure all expectations have been met
+		num, err := dPG.PruneRFEventLog(before, test.maxEntries)
 		if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
 			t.Errorf("Test %v Failed: Sql expectations were not met: %s", i, mock_err)
 		}
-		if test.dbError == nil && test.expectedErr == nil {
+		if test.dbError == nil {
 			if err != nil {
 				t.Errorf("Test %v Failed: Unexpected error received: %s", i, err)
-			} else if !reflect.DeepEqual(test.expectedEP, sep) {
-				t.Errorf("Test %v Failed: Expected service endpoint '%v'; Recieved service endpoint '%v'", i, test.expectedEP, sep)
+			} else if num != test.expectedNum {
+				t.Errorf("Test %v Failed: Expected %d deleted; Recieved %d", i, test.expectedNum, num)
 			}
 		} else if err == nil {
 			t.Errorf("Test %v Failed: Expected an error.", i)
@@ -2797,914 +3271,2604 @@ func TestPgGetServiceEndpointByID(t *testing.T) {
 	}
 }
 
-func TestPgGetServiceEndpointsByAll(t *testing.T) {
-	columns := []string{"rf_endpoint_id", "redfish_type", "redfish_subtype", "uuid", "odata_id", "rf_endpoint_fqdn", "service_info"}
+func TestInsertHWInvHists(t *testing.T) {
+	testHWInvHist1 := sm.HWInvHist{
+		ID:        "x5c4s3b2n1p0",
+		FruId:     "MFR-PARTNUMBER-SERIALNUMBER_1",
+		Timestamp: "2020-01-21 11:36:00",
+		EventType: "Added",
+	}
+	testHWInvHist2 := sm.HWInvHist{
+		ID:        "x5c4s3b2n1p1",
+		FruId:     "MFR-PARTNUMBER-SERIALNUMBER_2",
+		Timestamp: "2020-01-21 11:36:00",
+		EventType: "Added",
+	}
+	testHWInvHistBad := sm.HWInvHist{
+		ID:        "x5c4s3b2n1p0",
+		FruId:     "MFR-PARTNUMBER-SERIALNUMBER_1",
+		Timestamp: "2020-01-21 12:00:00",
+		EventType: "foo",
+	}
+
+	sqq := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+
+	insert1, _, _ := sqq.Insert(hwInvHistTable).
+		Columns(hwInvHistColsNoTS...).
+		Values("x0c0s0b0n0", "MFR-PARTNUMBER-SERIALNUMBER_1", "Scanned").
+		Values("x0c0s0b0n0", "MFR-PARTNUMBER-SERIALNUMBER_1", "Scanned").ToSql()
 
 	tests := []struct {
-		dbRows          [][]driver.Value
-		dbError         error
+		hhs             []*sm.HWInvHist
 		expectedPrepare string
-		expectedEPs     []*sm.ServiceEndpoint
+		expectedArgs    []driver.Value
+		dbError         error
 	}{{
-		dbRows: [][]driver.Value{
-			[]driver.Value{"x0c0s21b0", "UpdateService", "", "", "/redfish/v1/UpdateService", "10.4.0.8", json.RawMessage(`{}`)},
-			[]driver.Value{"x0c0s22b0", "UpdateService", "", "", "/redfish/v1/UpdateService", "10.4.0.9", json.RawMessage(`{}`)},
+		hhs:             []*sm.HWInvHist{&testHWInvHist1, &testHWInvHist2},
+		expectedPrepare: regexp.QuoteMeta(insert1),
+		expectedArgs:    []driver.Value{
+			testHWInvHist1.ID, testHWInvHist1.FruId, testHWInvHist1.EventType,
+			testHWInvHist2.ID, testHWInvHist2.FruId, testHWInvHist2.EventType,
 		},
-		dbError:         nil,
-		expectedPrepare: regexp.QuoteMeta(getServiceEndpointsAllQuery),
-		expectedEPs:     stest.TestServiceEndpointArrayUpdates.ServiceEndpoints,
+		dbError: nil,
 	}, {
-		dbRows:          nil,
-		dbError:         sql.ErrNoRows,
-		expectedPrepare: regexp.QuoteMeta(getServiceEndpointsAllQuery),
-		expectedEPs:     nil,
+		hhs:             []*sm.HWInvHist{&testHWInvHistBad},
+		expectedPrepare: "",
+		expectedArgs:    []driver.Value{},
+		dbError:         ErrHMSDSArgBadHWInvHistEventType,
+	}, {
+		hhs:             []*sm.HWInvHist{&testHWInvHist1, &testHWInvHist2},
+		expectedPrepare: regexp.QuoteMeta(insert1),
+		expectedArgs:    []driver.Value{
+			testHWInvHist1.ID, testHWInvHist1.FruId, testHWInvHist1.EventType,
+			testHWInvHist2.ID, testHWInvHist2.FruId, testHWInvHist2.EventType,
+		},
+		dbError: sql.ErrNoRows,
 	}}
 
 	for i, test := range tests {
 		ResetMockDB()
-		// before we actually execute our api function, we need to expect required DB actions
-		rows := sqlmock.NewRows(columns)
-		for _, row := range test.dbRows {
-			rows.AddRow(row...)
-		}
-
 		mockPG.ExpectBegin()
-		if test.dbError != nil {
-			mockPG.ExpectPrepare(test.expectedPrepare).ExpectQuery().WillReturnError(test.dbError)
+		if test.expectedPrepare == "" && test.dbError != nil {
+			mockPG.ExpectRollback()
+		} else if test.dbError != nil {
+			mockPG.ExpectPrepare(test.expectedPrepare).ExpectExec().WillReturnError(test.dbError)
 			mockPG.ExpectRollback()
 		} else {
-			mockPG.ExpectPrepare(test.expectedPrepare).ExpectQuery().WillReturnRows(rows)
+			mockPG.ExpectPrepare(test.expectedPrepare).ExpectExec().WithArgs(test.expectedArgs...).WillReturnResult(sqlmock.NewResult(0, 1))
 			mockPG.ExpectCommit()
 		}
 
-		seps, err := dPG.GetServiceEndpointsAll()
+		err := dPG.InsertHWInvHists(test.hhs)
 		// ensure all expectations have been met
 		if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
 			t.Errorf("Test %v Failed: Sql expectations were not met: %s", i, mock_err)
 		}
-		if test.dbError == nil {
-			if err != nil {
-				t.Errorf("Test %v Failed: Unexpected error received: %s", i, err)
-			} else if !reflect.DeepEqual(test.expectedEPs, seps) {
-				t.Errorf("Test %v Failed: Expected service endpoints '%v'; Recieved service endpoints '%v'", i, test.expectedEPs, seps)
-			}
-		} else if err == nil {
+		if test.dbError == nil && err != nil {
+			t.Errorf("Test %v Failed: Unexpected error received: %s", i, err)
+		} else if test.dbError != nil && err == nil {
 			t.Errorf("Test %v Failed: Expected an error.", i)
 		}
 	}
 }
 
-func TestPgGetServiceEndpointsFilter(t *testing.T) {
-	columns := []string{"rf_endpoint_id", "redfish_type", "redfish_subtype", "uuid", "odata_id", "rf_endpoint_fqdn", "service_info"}
+func TestDeleteHWInvHistByLocID(t *testing.T) {
+	testHWInvHist1 := sm.HWInvHist{
+		ID:        "x5c4s3b2n1p0",
+		FruId:     "MFR-PARTNUMBER-SERIALNUMBER_1",
+		Timestamp: "2020-01-21 11:36:00",
+		EventType: "Added",
+	}
+
+	sqq := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+
+	delete1, _, _ := sqq.Delete(hwInvHistTable).
+		Where(sq.Eq{hwInvHistIdCol: "x0c0s0b0n0"}).ToSql()
 
 	tests := []struct {
-		f               *ServiceEPFilter
-		dbRows          [][]driver.Value
-		dbError         error
+		id              string
 		expectedPrepare string
 		expectedArgs    []driver.Value
-		expectedEPs     []*sm.ServiceEndpoint
-		expectedErr     error
+		expectedError   error
 	}{{
-		f: &ServiceEPFilter{
-			Service:      []string{"UpdateService"},
-			RfEndpointID: []string{"x0c0s21b0"},
-		},
-		dbRows: [][]driver.Value{
-			[]driver.Value{"x0c0s21b0", "UpdateService", "", "", "/redfish/v1/UpdateService", "10.4.0.8", json.RawMessage(`{}`)},
-		},
-		dbError:         nil,
-		expectedPrepare: regexp.QuoteMeta(getServiceEndpointPrefix + " WHERE (rf_endpoint_id = $1) AND (redfish_type = $2)"),
-		expectedArgs:    []driver.Value{"x0c0s21b0", "UpdateService"},
-		expectedEPs:     stest.TestServiceEndpointArrayUpdate1.ServiceEndpoints,
-		expectedErr:     nil,
-	}, {
-		f: &ServiceEPFilter{
-			Service:      []string{"UpdateService"},
-			RfEndpointID: []string{"x0c0s21b0", "x0c0s22b0"},
-		},
-		dbRows: [][]driver.Value{
-			[]driver.Value{"x0c0s21b0", "UpdateService", "", "", "/redfish/v1/UpdateService", "10.4.0.8", json.RawMessage(`{}`)},
-			[]driver.Value{"x0c0s22b0", "UpdateService", "", "", "/redfish/v1/UpdateService", "10.4.0.9", json.RawMessage(`{}`)},
-		},
-		dbError:         nil,
-		expectedPrepare: regexp.QuoteMeta(getServiceEndpointPrefix + " WHERE (rf_endpoint_id = $1 OR rf_endpoint_id = $2) AND (redfish_type = $3)"),
-		expectedArgs:    []driver.Value{"x0c0s21b0", "x0c0s22b0", "UpdateService"},
-		expectedEPs:     stest.TestServiceEndpointArrayUpdates.ServiceEndpoints,
-		expectedErr:     nil,
-	}, {
-		f: nil,
-		dbRows: [][]driver.Value{
-			[]driver.Value{"x0c0s21b0", "UpdateService", "", "", "/redfish/v1/UpdateService", "10.4.0.8", json.RawMessage(`{}`)},
-		},
-		dbError:         nil,
-		expectedPrepare: regexp.QuoteMeta(getServiceEndpointsAllQuery),
-		expectedArgs:    nil,
-		expectedEPs:     stest.TestServiceEndpointArrayUpdate1.ServiceEndpoints,
-		expectedErr:     nil,
-	}, {
-		f: &ServiceEPFilter{
-			Service:      []string{"UpdateService"},
-			RfEndpointID: []string{"foo"},
-		},
-		dbRows:          nil,
-		dbError:         nil,
-		expectedPrepare: "",
-		expectedArgs:    nil,
-		expectedEPs:     nil,
-		expectedErr:     ErrHMSDSArgBadID,
-	}, {
-		f: &ServiceEPFilter{
-			Service:      []string{"Update:Service"},
-			RfEndpointID: []string{"x0c0s21b0"},
-		},
-		dbRows:          nil,
-		dbError:         nil,
-		expectedPrepare: "",
-		expectedArgs:    nil,
-		expectedEPs:     nil,
-		expectedErr:     ErrHMSDSArgBadRedfishType,
+		id:              testHWInvHist1.ID,
+		expectedPrepare: regexp.QuoteMeta(delete1),
+		expectedArgs:    []driver.Value{testHWInvHist1.ID},
+		expectedError:   nil,
 	}, {
-		f: &ServiceEPFilter{
-			Service:      []string{"UpdateService"},
-			RfEndpointID: []string{"x0c0s21b0"},
-		},
-		dbRows:          nil,
-		dbError:         sql.ErrNoRows,
-		expectedPrepare: regexp.QuoteMeta(getServiceEndpointPrefix + " WHERE (rf_endpoint_id = $1) AND (redfish_type = $2)"),
-		expectedArgs:    []driver.Value{"x0c0s21b0", "UpdateService"},
-		expectedEPs:     nil,
-		expectedErr:     nil,
+		id:              testHWInvHist1.ID,
+		expectedPrepare: regexp.QuoteMeta(delete1),
+		expectedArgs:    []driver.Value{testHWInvHist1.ID},
+		expectedError:   sql.ErrNoRows,
 	}}
 
 	for i, test := range tests {
 		ResetMockDB()
-		// before we actually execute our api function, we need to expect required DB actions
-		rows := sqlmock.NewRows(columns)
-		for _, row := range test.dbRows {
-			rows.AddRow(row...)
+		if test.expectedError != nil {
+			mockPG.ExpectPrepare(test.expectedPrepare).ExpectExec().WillReturnError(test.expectedError)
+		} else {
+			mockPG.ExpectPrepare(test.expectedPrepare).ExpectExec().WithArgs(test.expectedArgs...).WillReturnResult(sqlmock.NewResult(0, 1))
 		}
 
-		mockPG.ExpectBegin()
-		if test.dbError != nil {
-			mockPG.ExpectPrepare(test.expectedPrepare).ExpectQuery().WillReturnError(test.dbError)
-			mockPG.ExpectRollback()
-		} else if test.expectedErr != nil {
-			mockPG.ExpectRollback()
-		} else {
-			if test.expectedArgs != nil {
-				mockPG.ExpectPrepare(test.expectedPrepare).ExpectQuery().WithArgs(test.expectedArgs...).WillReturnRows(rows)
-			} else {
-				mockPG.ExpectPrepare(test.expectedPrepare).ExpectQuery().WillReturnRows(rows)
-			}
-			mockPG.ExpectCommit()
-		}
-
-		seps, err := dPG.GetServiceEndpointsFilter(test.f)
-		// ensure all expectations have been met
+		_, err := dPG.DeleteHWInvHistByLocID(test.id)
 		if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
 			t.Errorf("Test %v Failed: Sql expectations were not met: %s", i, mock_err)
 		}
-		if test.dbError == nil && test.expectedErr == nil {
-			if err != nil {
-				t.Errorf("Test %v Failed: Unexpected error received: %s", i, err)
-			} else if !reflect.DeepEqual(test.expectedEPs, seps) {
-				t.Errorf("Test %v Failed: Expected service endpoint '%v'; Recieved service endpoint '%v'", i, test.expectedEPs, seps)
-			}
-		} else if err == nil {
-			t.Errorf("Test %v Failed: Expected an error.", i)
+		if test.expectedError == nil && err != nil {
+			t.Errorf("Test %v Failed: Unexpected error received: %s", i, err)
+		} else if test.expectedError != nil && err == nil {
+			t.Errorf("Test %v Failed: Expected an error (%s).", i, test.expectedError)
 		}
 	}
 }
 
-func TestPgUpsertServiceEndpoint(t *testing.T) {
+func TestDeleteHWInvHistByFRUID(t *testing.T) {
+	testHWInvHist1 := sm.HWInvHist{
+		ID:        "x5c4s3b2n1p0",
+		FruId:     "MFR-PARTNUMBER-SERIALNUMBER_1",
+		Timestamp: "2020-01-21 11:36:00",
+		EventType: "Added",
+	}
+
+	sqq := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+
+	delete1, _, _ := sqq.Delete(hwInvHistTable).
+		Where(sq.Eq{hwInvHistFruIdCol: "MFR-PARTNUMBER-SERIALNUMBER_1"}).ToSql()
+
 	tests := []struct {
-		sep             *sm.ServiceEndpoint
-		dbError         error
+		fruid           string
 		expectedPrepare string
 		expectedArgs    []driver.Value
-		expectedErr     error
+		expectedError   error
 	}{{
-		sep:             &stest.TestServiceEndpointUpdate1,
-		dbError:         nil,
-		expectedPrepare: regexp.QuoteMeta(upsertPgServiceEndpointQuery),
-		expectedArgs: []driver.Value{
-			stest.TestServiceEndpointUpdate1.RfEndpointID,
-			stest.TestServiceEndpointUpdate1.RedfishType,
-			stest.TestServiceEndpointUpdate1.RedfishSubtype,
-			stest.TestServiceEndpointUpdate1.UUID,
-			stest.TestServiceEndpointUpdate1.OdataID,
-			stest.TestServiceEndpointUpdate1.ServiceInfo,
-		},
-		expectedErr: nil,
-	}, {
-		sep:             &stest.TestServiceEndpointUpdate1,
-		dbError:         sql.ErrNoRows,
-		expectedPrepare: regexp.QuoteMeta(upsertPgServiceEndpointQuery),
-		expectedArgs: []driver.Value{
-			stest.TestServiceEndpointUpdate1.RfEndpointID,
-			stest.TestServiceEndpointUpdate1.RedfishType,
-			stest.TestServiceEndpointUpdate1.RedfishSubtype,
-			stest.TestServiceEndpointUpdate1.UUID,
-			stest.TestServiceEndpointUpdate1.OdataID,
-			stest.TestServiceEndpointUpdate1.ServiceInfo,
-		},
-		expectedErr: nil,
+		fruid:           testHWInvHist1.FruId,
+		expectedPrepare: regexp.QuoteMeta(delete1),
+		expectedArgs:    []driver.Value{testHWInvHist1.FruId},
+		expectedError:   nil,
 	}, {
-		sep:             nil,
-		dbError:         nil,
-		expectedPrepare: "",
-		expectedArgs:    nil,
-		expectedErr:     ErrHMSDSArgNil,
+		fruid:           testHWInvHist1.FruId,
+		expectedPrepare: regexp.QuoteMeta(delete1),
+		expectedArgs:    []driver.Value{testHWInvHist1.FruId},
+		expectedError:   sql.ErrNoRows,
 	}}
 
 	for i, test := range tests {
 		ResetMockDB()
-		// before we actually execute our api function, we need to expect required DB actions
-		mockPG.ExpectBegin()
-		if test.dbError != nil {
-			mockPG.ExpectPrepare(ToPGQueryArgs(test.expectedPrepare)).ExpectExec().WillReturnError(test.dbError)
-			mockPG.ExpectRollback()
-		} else if test.expectedErr != nil {
-			mockPG.ExpectRollback()
+		if test.expectedError != nil {
+			mockPG.ExpectPrepare(test.expectedPrepare).ExpectExec().WillReturnError(test.expectedError)
 		} else {
-			mockPG.ExpectPrepare(ToPGQueryArgs(test.expectedPrepare)).ExpectExec().WithArgs(test.expectedArgs...).WillReturnResult(sqlmock.NewResult(0, 1))
-			mockPG.ExpectCommit()
+			mockPG.ExpectPrepare(test.expectedPrepare).ExpectExec().WithArgs(test.expectedArgs...).WillReturnResult(sqlmock.NewResult(0, 1))
 		}
 
-		err := dPG.UpsertServiceEndpoint(test.sep)
-		// ensure all expectations have been met
+		_, err := dPG.DeleteHWInvHistByFRUID(test.fruid)
 		if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
 			t.Errorf("Test %v Failed: Sql expectations were not met: %s", i, mock_err)
 		}
-		if test.dbError == nil && test.expectedErr == nil {
-			if err != nil {
-				t.Errorf("Test %v Failed: Unexpected error received: %s", i, err)
-			}
-		} else if err == nil {
-			t.Errorf("Test %v Failed: Expected an error.", i)
+		if test.expectedError == nil && err != nil {
+			t.Errorf("Test %v Failed: Unexpected error received: %s", i, err)
+		} else if test.expectedError != nil && err == nil {
+			t.Errorf("Test %v Failed: Expected an error (%s).", i, test.expectedError)
 		}
 	}
 }
 
-func TestPgUpsertServiceEndpoints(t *testing.T) {
-	upsertSuffix := "ON CONFLICT(" + serviceEPsRFEndpointIDCol + ", "+ serviceEPsRedfishTypeCol + ") DO UPDATE SET " +
-		serviceEPsRedfishSubtypeCol + " = EXCLUDED." + serviceEPsRedfishSubtypeCol + ", " +
-		serviceEPsUUIDCol + " = EXCLUDED." + serviceEPsUUIDCol + ", " +
-		serviceEPsODataIDCol + " = EXCLUDED." + serviceEPsODataIDCol + ", " +
-		serviceEPsServiceInfoCol + " = EXCLUDED." + serviceEPsServiceInfoCol
-
-	var servEP sm.ServiceEndpoint
+func TestDeleteHWInvHistAll(t *testing.T) {
 
 	sqq := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
-	insert1, _, _ := sqq.Insert(serviceEPsTable).
-		Columns(serviceEPsCols...).
-		Values(servEP.RfEndpointID, servEP.RedfishType, servEP.RedfishSubtype, servEP.UUID, servEP.OdataID, servEP.ServiceInfo).
-		Suffix(upsertSuffix).ToSql()
-	insert2, _, _ := sqq.Insert(serviceEPsTable).
-		Columns(serviceEPsCols...).
-		Values(servEP.RfEndpointID, servEP.RedfishType, servEP.RedfishSubtype, servEP.UUID, servEP.OdataID, servEP.ServiceInfo).
-		Values(servEP.RfEndpointID, servEP.RedfishType, servEP.RedfishSubtype, servEP.UUID, servEP.OdataID, servEP.ServiceInfo).
-		Suffix(upsertSuffix).ToSql()
+
+	delete1, _, _ := sqq.Delete(hwInvHistTable).ToSql()
+
 	tests := []struct {
-		seps            *sm.ServiceEndpointArray
-		dbError         error
 		expectedPrepare string
-		expectedArgs    []driver.Value
-		expectedErr     error
+		expectedError   error
 	}{{
-		seps:            &stest.TestServiceEndpointArrayUpdates,
-		dbError:         nil,
-		expectedPrepare: regexp.QuoteMeta(insert2),
-		expectedArgs:    []driver.Value{
-			stest.TestServiceEndpointArrayUpdates.ServiceEndpoints[0].RfEndpointID,
-			stest.TestServiceEndpointArrayUpdates.ServiceEndpoints[0].RedfishType,
-			stest.TestServiceEndpointArrayUpdates.ServiceEndpoints[0].RedfishSubtype,
-			stest.TestServiceEndpointArrayUpdates.ServiceEndpoints[0].UUID,
-			stest.TestServiceEndpointArrayUpdates.ServiceEndpoints[0].OdataID,
-			stest.TestServiceEndpointArrayUpdates.ServiceEndpoints[0].ServiceInfo,
-			stest.TestServiceEndpointArrayUpdates.ServiceEndpoints[1].RfEndpointID,
-			stest.TestServiceEndpointArrayUpdates.ServiceEndpoints[1].RedfishType,
-			stest.TestServiceEndpointArrayUpdates.ServiceEndpoints[1].RedfishSubtype,
-			stest.TestServiceEndpointArrayUpdates.ServiceEndpoints[1].UUID,
-			stest.TestServiceEndpointArrayUpdates.ServiceEndpoints[1].OdataID,
-			stest.TestServiceEndpointArrayUpdates.ServiceEndpoints[1].ServiceInfo,
-		},
-		expectedErr: nil,
-	}, {
-		seps:            &stest.TestServiceEndpointArrayUpdate1,
-		dbError:         nil,
-		expectedPrepare: regexp.QuoteMeta(insert1),
-		expectedArgs:    []driver.Value{
-			stest.TestServiceEndpointArrayUpdate1.ServiceEndpoints[0].RfEndpointID,
-			stest.TestServiceEndpointArrayUpdate1.ServiceEndpoints[0].RedfishType,
-			stest.TestServiceEndpointArrayUpdate1.ServiceEndpoints[0].RedfishSubtype,
-			stest.TestServiceEndpointArrayUpdate1.ServiceEndpoints[0].UUID,
-			stest.TestServiceEndpointArrayUpdate1.ServiceEndpoints[0].OdataID,
-			stest.TestServiceEndpointArrayUpdate1.ServiceEndpoints[0].ServiceInfo,
-		},
-		expectedErr: nil,
-	}, {
-		seps:            &stest.TestServiceEndpointArrayUpdate1,
-		dbError:         sql.ErrNoRows,
-		expectedPrepare: regexp.QuoteMeta(insert1),
-		expectedArgs:    []driver.Value{
-			stest.TestServiceEndpointArrayUpdate1.ServiceEndpoints[0].RfEndpointID,
-			stest.TestServiceEndpointArrayUpdate1.ServiceEndpoints[0].RedfishType,
-			stest.TestServiceEndpointArrayUpdate1.ServiceEndpoints[0].RedfishSubtype,
-			stest.TestServiceEndpointArrayUpdate1.ServiceEndpoints[0].UUID,
-			stest.TestServiceEndpointArrayUpdate1.ServiceEndpoints[0].OdataID,
-			stest.TestServiceEndpointArrayUpdate1.ServiceEndpoints[0].ServiceInfo,
-		},
-		expectedErr: nil,
+		expectedPrepare: regexp.QuoteMeta(delete1),
+		expectedError:   nil,
 	}, {
-		seps:            &sm.ServiceEndpointArray{ServiceEndpoints: []*sm.ServiceEndpoint{nil}},
-		dbError:         nil,
-		expectedPrepare: "",
-		expectedArgs:    nil,
-		expectedErr:     ErrHMSDSArgNil,
+		expectedPrepare: regexp.QuoteMeta(delete1),
+		expectedError:   sql.ErrNoRows,
 	}}
 
 	for i, test := range tests {
 		ResetMockDB()
-		// before we actually execute our api function, we need to expect required DB actions
-		mockPG.ExpectBegin()
-		if test.dbError != nil {
-			mockPG.ExpectPrepare(test.expectedPrepare).ExpectExec().WillReturnError(test.dbError)
-			mockPG.ExpectRollback()
-		} else if test.expectedErr != nil {
-			mockPG.ExpectRollback()
+		if test.expectedError != nil {
+			mockPG.ExpectPrepare(test.expectedPrepare).ExpectExec().WillReturnError(test.expectedError)
 		} else {
-			mockPG.ExpectPrepare(test.expectedPrepare).ExpectExec().WithArgs(test.expectedArgs...).WillReturnResult(sqlmock.NewResult(0, int64(len(test.seps.ServiceEndpoints))))
-			mockPG.ExpectCommit()
+			mockPG.ExpectPrepare(test.expectedPrepare).ExpectExec().WillReturnResult(sqlmock.NewResult(0, 1))
 		}
 
-		err := dPG.UpsertServiceEndpoints(test.seps)
-		// ensure all expectations have been met
+		_, err := dPG.DeleteHWInvHistAll()
 		if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
 			t.Errorf("Test %v Failed: Sql expectations were not met: %s", i, mock_err)
 		}
-		if test.dbError == nil && test.expectedErr == nil {
-			if err != nil {
-				t.Errorf("Test %v Failed: Unexpected error received: %s", i, err)
-			}
-		} else if err == nil {
-			t.Errorf("Test %v Failed: Expected an error.", i)
+		if test.expectedError == nil && err != nil {
+			t.Errorf("Test %v Failed: Unexpected error received: %s", i, err)
+		} else if test.expectedError != nil && err == nil {
+			t.Errorf("Test %v Failed: Expected an error (%s).", i, test.expectedError)
 		}
 	}
 }
 
-func TestPgDeleteServiceEndpointByID(t *testing.T) {
+func TestDeleteHWInvHistFilter(t *testing.T) {
+	testHWInvHist1 := sm.HWInvHist{
+		ID:        "x5c4s3b2n1p0",
+		FruId:     "MFR-PARTNUMBER-SERIALNUMBER_1",
+		Timestamp: "2020-01-21 11:36:00",
+		EventType: "Added",
+	}
+	timeStartArg, _ := time.Parse(time.RFC3339, "2020-01-01T00:00:00Z")
+	timeEndArg, _ := time.Parse(time.RFC3339, "2020-02-01T00:00:00Z")
+
+	sqq := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+
+	delete1, _, _ := sqq.Delete(hwInvHistTable).ToSql()
+
+	delete2, _, _ := sqq.Delete(hwInvHistTable).
+		Where(sq.Eq{hwInvHistIdCol: []string{testHWInvHist1.ID}}).
+		Where(sq.Gt{hwInvHistTimestampCol: timeStartArg}).
+		Where(sq.Lt{hwInvHistTimestampCol: timeEndArg}).ToSql()
+
+	delete3, _, _ := sqq.Delete(hwInvHistTable).
+		Where(sq.Eq{hwInvHistEventTypeCol: []string{sm.HWInvHistEventTypeAdded}}).ToSql()
+
 	tests := []struct {
-		svc             string
-		id              string
-		dbResult        int64
+		f_opts          []HWInvHistFiltFunc
 		dbError         error
 		expectedPrepare string
 		expectedArgs    []driver.Value
-		expectedResult  bool
 		expectedErr     error
 	}{{
-		svc:             "UpdateService",
-		id:              "x0c0s21b0",
-		dbResult:        1,
+		f_opts:          []HWInvHistFiltFunc{},
 		dbError:         nil,
-		expectedPrepare: regexp.QuoteMeta(deleteServiceByRfEPandRfTypeQuery),
-		expectedArgs:    []driver.Value{"x0c0s21b0", "UpdateService"},
-		expectedResult:  true,
+		expectedPrepare: regexp.QuoteMeta(delete1),
+		expectedArgs:    []driver.Value{},
 		expectedErr:     nil,
 	}, {
-		svc:             "",
-		id:              "x0c0s21b0",
-		dbResult:        0,
+		f_opts: []HWInvHistFiltFunc{
+			HWInvHist_ID(testHWInvHist1.ID),
+			HWInvHist_StartTime("2020-01-01T00:00:00Z"),
+			HWInvHist_EndTime("2020-02-01T00:00:00Z"),
+		},
 		dbError:         nil,
-		expectedPrepare: "",
-		expectedArgs:    nil,
-		expectedResult:  false,
-		expectedErr:     ErrHMSDSArgNil,
-	}, {
-		svc:             "UpdateService",
-		id:              "",
-		dbResult:        0,
+		expectedPrepare: regexp.QuoteMeta(delete2),
+		expectedArgs:    []driver.Value{testHWInvHist1.ID, timeStartArg, timeEndArg},
+		expectedErr:     nil,
+	}, {
+		f_opts:          []HWInvHistFiltFunc{HWInvHist_EventTypes([]string{"foo"})},
 		dbError:         nil,
 		expectedPrepare: "",
 		expectedArgs:    nil,
-		expectedResult:  false,
-		expectedErr:     ErrHMSDSArgNil,
-	}, {
-		svc:             "foo",
-		id:              "x0c0s21b0",
-		dbResult:        0,
-		dbError:         nil,
-		expectedPrepare: regexp.QuoteMeta(deleteServiceByRfEPandRfTypeQuery),
-		expectedArgs:    []driver.Value{"x0c0s21b0", "foo"},
-		expectedResult:  false,
-		expectedErr:     nil,
+		expectedErr:     ErrHMSDSArgBadHWInvHistEventType,
 	}, {
-		svc:             "UpdateService",
-		id:              "x0c0s21b0",
-		dbResult:        0,
+		f_opts:          []HWInvHistFiltFunc{HWInvHist_EventTypes([]string{sm.HWInvHistEventTypeAdded})},
 		dbError:         sql.ErrNoRows,
-		expectedPrepare: regexp.QuoteMeta(deleteServiceByRfEPandRfTypeQuery),
-		expectedArgs:    []driver.Value{"x0c0s21b0", "UpdateService"},
-		expectedResult:  false,
+		expectedPrepare: regexp.QuoteMeta(delete3),
+		expectedArgs:    []driver.Value{sm.HWInvHistEventTypeAdded},
 		expectedErr:     nil,
 	}}
 
 	for i, test := range tests {
 		ResetMockDB()
-		// before we actually execute our api function, we need to expect required DB actions
-		mockPG.ExpectBegin()
-		if test.dbError != nil {
-			mockPG.ExpectPrepare(ToPGQueryArgs(test.expectedPrepare)).ExpectExec().WillReturnError(test.dbError)
-			mockPG.ExpectRollback()
-		} else if test.expectedErr != nil {
-			mockPG.ExpectRollback()
-		} else {
-			mockPG.ExpectPrepare(ToPGQueryArgs(test.expectedPrepare)).ExpectExec().WithArgs(test.expectedArgs...).WillReturnResult(sqlmock.NewResult(0, test.dbResult))
-			mockPG.ExpectCommit()
-		}
-
-		didDelete, err := dPG.DeleteServiceEndpointByID(test.svc, test.id)
-		// ensure all expectations have been met
-		if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
-			t.Errorf("Test %v Failed: Sql expectations were not met: %s", i, mock_err)
-		}
-		if test.dbError == nil && test.expectedErr == nil {
-			if err != nil {
-				t.Errorf("Test %v Failed: Unexpected error received: %s", i, err)
-			} else if test.expectedResult != didDelete {
-				t.Errorf("Test %v Failed: Expected didDelete '%v'; Recieved didDelete '%v'", i, test.expectedResult, didDelete)
-			}
-		} else if err == nil {
-			t.Errorf("Test %v Failed: Expected an error.", i)
-		}
-	}
-}
-
-func TestPgDeleteServiceEndpointsAll(t *testing.T) {
-	tests := []struct {
-		svc             string
-		id              string
-		dbResult        int64
-		dbError         error
-		expectedPrepare string
-		expectedResult  int64
-		expectedErr     error
-	}{{
-		dbResult:        1,
-		dbError:         nil,
-		expectedPrepare: regexp.QuoteMeta(deleteServiceEndpointsAllQuery),
-		expectedResult:  1,
-	}, {
-		dbResult:        0,
-		dbError:         nil,
-		expectedPrepare: regexp.QuoteMeta(deleteServiceEndpointsAllQuery),
-		expectedResult:  0,
-	}, {
-		dbResult:        0,
-		dbError:         sql.ErrNoRows,
-		expectedPrepare: regexp.QuoteMeta(deleteServiceEndpointsAllQuery),
-		expectedResult:  0,
-	}}
-
-	for i, test := range tests {
-		ResetMockDB()
-		// before we actually execute our api function, we need to expect required DB actions
-		mockPG.ExpectBegin()
 		if test.dbError != nil {
 			mockPG.ExpectPrepare(test.expectedPrepare).ExpectExec().WillReturnError(test.dbError)
-			mockPG.ExpectRollback()
-		} else {
-			mockPG.ExpectPrepare(test.expectedPrepare).ExpectExec().WillReturnResult(sqlmock.NewResult(0, test.dbResult))
-			mockPG.ExpectCommit()
-		}
-
-		numDelete, err := dPG.DeleteServiceEndpointsAll()
-		// ensure all expectations have been met
-		if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
-			t.Errorf("Test %v Failed: Sql expectations were not met: %s", i, mock_err)
-		}
-		if test.dbError == nil {
-			if err != nil {
-				t.Errorf("Test %v Failed: Unexpected error received: %s", i, err)
-			} else if test.expectedResult != numDelete {
-				t.Errorf("Test %v Failed: Expected numDelete '%v'; Recieved numDelete '%v'", i, test.expectedResult, numDelete)
-			}
-		} else if err == nil {
-			t.Errorf("Test %v Failed: Expected an error.", i)
-		}
-	}
-}
-
-////////////////////////////////////////////////////////////////////////////
-//
-// Component Ethernet Interface Management
-//
-////////////////////////////////////////////////////////////////////////////
-
-func TestPgGetCompEthInterfaceFilter(t *testing.T) {
-	columns := addAliasToCols(compEthAlias, compEthCols, compEthCols)
-
-	// TODO: Add Network test for testing, test IPAddress/Network Filters
-	testCompEth1 := sm.CompEthInterfaceV2{
-		ID:      "a4bf0138ee65",
-		Desc:    "My description",
-		MACAddr: "a4:bf:01:38:ee:65",
-		IPAddrs: []sm.IPAddressMapping{
-			{IPAddr: "10.254.2.14"},
-		},
-		LastUpdate: "2020-05-13T21:59:02.363448Z",
-		CompID:     "x3000c0s26b0",
-		Type:       "NodeBMC",
-	}
-	testCompEth1IPAddrsRaw, _ := json.Marshal(testCompEth1.IPAddrs)
-
-	testCompEth2 := sm.CompEthInterfaceV2{
-		ID:      "a4bf0138ee67",
-		Desc:    "My description 2",
-		MACAddr: "a4:bf:01:38:ee:67",
-		IPAddrs: []sm.IPAddressMapping{
-			{IPAddr: "10.254.2.14"},
-		},
-		LastUpdate: "2020-05-13T21:59:02.363448Z",
-		CompID:     "x3000c0s26b0",
-		Type:       "NodeBMC",
-	}
-	testCompEth2IPAddrsRaw, _ := json.Marshal(testCompEth2.IPAddrs)
-
-	newerThanArg, _ := time.Parse(time.RFC3339, "2020-01-01T00:00:00Z")
-	olderThanArg, _ := time.Parse(time.RFC3339, "2020-02-01T00:00:00Z")
-
-	sqq := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
-	query1, _, _ := sqq.Select(columns...).
-		From(compEthTable + " " + compEthAlias).ToSql()
-
-	query2, _, _ := sqq.Select(columns...).
-		From(compEthTable + " " + compEthAlias).
-		Where(sq.Eq{compEthAlias + "." + compEthIdCol: []string{testCompEth1.ID}}).
-		Where(sq.Gt{compEthAlias + "." + compEthLastUpdateCol: newerThanArg}).
-		Where(sq.Lt{compEthAlias + "." + compEthLastUpdateCol: olderThanArg}).ToSql()
-
-	tests := []struct {
-		f_opts          []CompEthInterfaceFiltFunc
-		dbRows          [][]driver.Value
-		dbError         error
-		expectedPrepare string
-		expectedArgs    []driver.Value
-		expectedOut     []*sm.CompEthInterfaceV2
-		expectedErr     error
-	}{{
-		f_opts: []CompEthInterfaceFiltFunc{},
-		dbRows: [][]driver.Value{
-			[]driver.Value{testCompEth1.ID, testCompEth1.Desc, testCompEth1.MACAddr, testCompEth1.LastUpdate, testCompEth1.CompID, testCompEth1.Type, testCompEth1IPAddrsRaw},
-			[]driver.Value{testCompEth2.ID, testCompEth2.Desc, testCompEth2.MACAddr, testCompEth2.LastUpdate, testCompEth2.CompID, testCompEth2.Type, testCompEth2IPAddrsRaw},
-		},
-		dbError:         nil,
-		expectedPrepare: regexp.QuoteMeta(query1),
-		expectedArgs:    []driver.Value{},
-		expectedOut: []*sm.CompEthInterfaceV2{
-			&testCompEth1,
-			&testCompEth2,
-		},
-		expectedErr: nil,
-	}, {
-		f_opts: []CompEthInterfaceFiltFunc{
-			CEI_ID(testCompEth1.ID),
-			CEI_NewerThan("2020-01-01T00:00:00Z"),
-			CEI_OlderThan("2020-02-01T00:00:00Z"),
-		},
-		dbRows: [][]driver.Value{
-			[]driver.Value{testCompEth1.ID, testCompEth1.Desc, testCompEth1.MACAddr, testCompEth1.LastUpdate, testCompEth1.CompID, testCompEth1.Type, testCompEth1IPAddrsRaw},
-		},
-		dbError:         nil,
-		expectedPrepare: regexp.QuoteMeta(query2),
-		expectedArgs:    []driver.Value{testCompEth1.ID, newerThanArg, olderThanArg},
-		expectedOut: []*sm.CompEthInterfaceV2{
-			&testCompEth1,
-		},
-		expectedErr: nil,
-	}}
-
-	for i, test := range tests {
-		ResetMockDB()
-		// before we actually execute our api function, we need to expect required DB actions
-		rows := sqlmock.NewRows(columns)
-		for _, row := range test.dbRows {
-			rows.AddRow(row...)
-		}
-
-		if test.dbError != nil {
-			mockPG.ExpectPrepare(test.expectedPrepare).ExpectQuery().WillReturnError(test.dbError)
 		} else if test.expectedErr == nil {
 			if len(test.expectedArgs) > 0 {
-				mockPG.ExpectPrepare(test.expectedPrepare).ExpectQuery().WithArgs(test.expectedArgs...).WillReturnRows(rows)
+				mockPG.ExpectPrepare(test.expectedPrepare).ExpectExec().WithArgs(test.expectedArgs...).WillReturnResult(sqlmock.NewResult(0, 1))
+				//mockPG.ExpectExec(test.expectedPrepare).WithArgs(test.expectedArgs...).WillReturnResult(sqlmock.NewResult(0, 1))
 			} else {
-				mockPG.ExpectPrepare(test.expectedPrepare).ExpectQuery().WillReturnRows(rows)
+				mockPG.ExpectPrepare(test.expectedPrepare).ExpectExec().WillReturnResult(sqlmock.NewResult(0, 1))
+				//mockPG.ExpectExec(test.expectedPrepare).WillReturnResult(sqlmock.NewResult(0, 1))
 			}
 		}
 
-		out, err := dPG.GetCompEthInterfaceFilter(test.f_opts...)
+		_, err := dPG.DeleteHWInvHistFilter(test.f_opts...)
 		if test.expectedErr == nil {
-			// ensure all expectations have been met
 			if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
 				t.Errorf("Test %v Failed: Sql expectations were not met: %s", i, mock_err)
 			}
 		}
-		if test.dbError == nil && test.expectedErr == nil {
-			if err != nil {
-				t.Errorf("Test %v Failed: Unexpected error received: %s", i, err)
-			} else if !reflect.DeepEqual(test.expectedOut, out) {
-				t.Errorf("Test %v Failed: Expected CompEthInterfaces '%v'; Received CompEthInterfaces '%v'", i, test.expectedOut, out)
-			}
-		} else if err == nil {
-			t.Errorf("Test %v Failed: Expected an error.", i)
+		if test.dbError == nil && test.expectedErr == nil && err != nil {
+			t.Errorf("Test %v Failed: Unexpected error received: %s", i, err)
+		} else if test.dbError != nil && test.expectedErr != nil && err == nil {
+			t.Errorf("Test %v Failed: Expected an error (%s).", i, test.expectedErr)
 		}
 	}
 }
 
-func TestInsertCompEthInterfaces(t *testing.T) {
-	testCompEth1 := sm.CompEthInterfaceV2{
-		ID:      "a4bf0138ee65",
-		Desc:    "My description",
-		MACAddr: "a4:bf:01:38:ee:65",
-		IPAddrs: []sm.IPAddressMapping{
-			{IPAddr: "10.254.2.14"},
-		},
-		CompID: "x3000c0s26b0",
-		Type:   "NodeBMC",
-	}
-	testCompEth1IPAddrsRaw, _ := json.Marshal(testCompEth1.IPAddrs)
-
-	testCompEth2 := sm.CompEthInterfaceV2{
-		MACAddr: "a4:bf:01:38:ee:67",
-	}
-	testCompEth2IPAddrsRaw, _ := json.Marshal(testCompEth2.IPAddrs)
-
-	sqq := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+///////////////////////////////////////////////////////////////////////////////
+// Redfish Endpoints Tests
+///////////////////////////////////////////////////////////////////////////////
 
-	insert1, _, _ := sqq.Insert(compEthTable).
-		Columns(compEthCols...).
-		Values("a4bf0138ee65", "My description", "a4:bf:01:38:ee:65", "NOW()", "x3000c0s26b0", "NodeBMC", `[{"IPAddress":"10.254.2.14","Network":""}]`).
-		Values("a4bf0138ee65", "My description", "a4:bf:01:38:ee:65", "NOW()", "x3000c0s26b0", "NodeBMC", `[{"IPAddress":"10.254.2.14","Network":""}]`).ToSql()
+func TestPgPatchRFEndpointNoDiscInfo(t *testing.T) {
+	trueBool := true
+	//falseBool := false
+	columns := rfEPsAllCols
+	emptyDiscInfo := rf.DiscoveryInfo{}
+	badHost := "x0c0s0b0"
+	ipAddr := "10.254.2.13"
 
 	tests := []struct {
-		in              []*sm.CompEthInterfaceV2
-		expectedPrepare string
-		expectedArgs    []driver.Value
-		dbError         error
-	}{{ // Test 0 - Insert 2 new rows
-		in:              []*sm.CompEthInterfaceV2{&testCompEth1, &testCompEth2},
-		expectedPrepare: regexp.QuoteMeta(insert1),
-		expectedArgs:    []driver.Value{
-			testCompEth1.ID, testCompEth1.Desc, testCompEth1.MACAddr, "NOW()", testCompEth1.CompID, testCompEth1.Type, testCompEth1IPAddrsRaw,
-			"a4bf0138ee67", testCompEth2.Desc, testCompEth2.MACAddr, "NOW()", testCompEth2.CompID, testCompEth2.Type, testCompEth2IPAddrsRaw,
+		id                  string
+		epp                 sm.RedfishEndpointPatch
+		dbErrorGet1         error
+		dbRowsGet1          [][]driver.Value
+		expectedPrepareGet1 string
+		expectedArgsGet1    []driver.Value
+		dbError             error
+		expectedPrepare     string
+		expectedArgs        []driver.Value
+		dbErrorGet2         error
+		dbRowsGet2          [][]driver.Value
+		expectedPrepareGet2 string
+		expectedArgsGet2    []driver.Value
+		expectedResp        *sm.RedfishEndpoint
+		expectedIDs         []string
+		expectedErr         error
+		expectErr           bool
+	}{{ // Test 0 Success
+		id: "x0c0s1b1",
+		epp: sm.RedfishEndpointPatch{
+			Enabled:        &trueBool,
+			RediscOnUpdate: &trueBool,
 		},
-		dbError: nil,
-	}, { // Test 1 - Test that database error is passed back
-		in:              []*sm.CompEthInterfaceV2{&testCompEth1, &testCompEth2},
-		expectedPrepare: regexp.QuoteMeta(insert1),
-		expectedArgs:    []driver.Value{
-			testCompEth1.ID, testCompEth1.Desc, testCompEth1.MACAddr, "NOW()", testCompEth1.CompID, testCompEth1.Type, testCompEth1IPAddrsRaw,
-			"a4bf0138ee67", testCompEth2.Desc, testCompEth2.MACAddr, "NOW()", testCompEth2.CompID, testCompEth2.Type, testCompEth2IPAddrsRaw,
+		dbErrorGet1: nil,
+		dbRowsGet1: [][]driver.Value{
+			[]driver.Value{"x0c0s1b1", "NodeBMC", "", "10.254.2.12", "", "10.254.2.12", false, "da4faffe-6491-4f3f-ab54-3bf8fce57531", "root", "", false, false, "a4bf012e85b5", "10.254.2.12", false, "", json.RawMessage(`{}`)},
 		},
-		dbError: sql.ErrNoRows,
+		expectedPrepareGet1: regexp.QuoteMeta(getRFEndpointPrefix + " WHERE (id = $1);"),
+		expectedArgsGet1:    []driver.Value{"x0c0s1b1"},
+		dbError:             nil,
+		expectedPrepare:     regexp.QuoteMeta(updatePgRFEndpointNoDiscInfoQuery),
+		expectedArgs:        []driver.Value{"NodeBMC", "", "10.254.2.12", "", "10.254.2.12", true, "da4faffe-6491-4f3f-ab54-3bf8fce57531", "root", "", false, false, "a4bf012e85b5", "10.254.2.12", true, "", "x0c0s1b1"},
+		dbErrorGet2:         nil,
+		dbRowsGet2: [][]driver.Value{
+			[]driver.Value{"x0c0s1b1", "NodeBMC", "", "10.254.2.12", "", "10.254.2.12", true, "da4faffe-6491-4f3f-ab54-3bf8fce57531", "root", "", false, false, "a4bf012e85b5", "10.254.2.12", true, "", json.RawMessage(`{}`)},
+		},
+		expectedPrepareGet2: regexp.QuoteMeta(getRFEndpointByIDQuery),
+		expectedArgsGet2:    []driver.Value{"x0c0s1b1"},
+		expectedResp: &sm.RedfishEndpoint{
+			RedfishEPDescription: rf.RedfishEPDescription{
+				ID:             "x0c0s1b1",
+				Type:           "NodeBMC",
+				Name:           "",
+				Hostname:       "10.254.2.12",
+				Domain:         "",
+				FQDN:           "10.254.2.12",
+				Enabled:        true,
+				UUID:           "da4faffe-6491-4f3f-ab54-3bf8fce57531",
+				User:           "root",
+				Password:       "",
+				UseSSDP:        false,
+				MACRequired:    false,
+				MACAddr:        "a4bf012e85b5",
+				IPAddr:         "10.254.2.12",
+				RediscOnUpdate: true,
+				TemplateID:     "",
+				DiscInfo:       emptyDiscInfo,
+			},
+		},
+		expectedIDs: []string{},
+		expectedErr: nil,
+		expectErr:   false,
+	}, { // Test 1 No Update
+		id:          "x0c0s1b1",
+		epp:         sm.RedfishEndpointPatch{},
+		dbErrorGet1: nil,
+		dbRowsGet1: [][]driver.Value{
+			[]driver.Value{"x0c0s1b1", "NodeBMC", "", "10.254.2.12", "", "10.254.2.12", false, "da4faffe-6491-4f3f-ab54-3bf8fce57531", "root", "", false, false, "a4bf012e85b5", "10.254.2.12", false, "", json.RawMessage(`{}`)},
+		},
+		expectedPrepareGet1: regexp.QuoteMeta(getRFEndpointPrefix + " WHERE (id = $1);"),
+		expectedArgsGet1:    []driver.Value{"x0c0s1b1"},
+		dbError:             nil,
+		expectedPrepare:     "",
+		expectedArgs:        []driver.Value{},
+		dbErrorGet2:         nil,
+		dbRowsGet2:          [][]driver.Value{},
+		expectedPrepareGet2: "",
+		expectedArgsGet2:    []driver.Value{},
+		expectedResp: &sm.RedfishEndpoint{
+			RedfishEPDescription: rf.RedfishEPDescription{
+				ID:             "x0c0s1b1",
+				Type:           "NodeBMC",
+				Name:           "",
+				Hostname:       "10.254.2.12",
+				Domain:         "",
+				FQDN:           "10.254.2.12",
+				Enabled:        false,
+				UUID:           "da4faffe-6491-4f3f-ab54-3bf8fce57531",
+				User:           "root",
+				Password:       "",
+				UseSSDP:        false,
+				MACRequired:    false,
+				MACAddr:        "a4bf012e85b5",
+				IPAddr:         "10.254.2.12",
+				RediscOnUpdate: false,
+				TemplateID:     "",
+				DiscInfo:       emptyDiscInfo,
+			},
+		},
+		expectedIDs: []string{},
+		expectedErr: nil,
+		expectErr:   true,
+	}, { // Test 2 Update would result in bad RFEndpoint
+		id: "x0c0s1b1",
+		epp: sm.RedfishEndpointPatch{
+			Hostname: &badHost,
+		},
+		dbErrorGet1: nil,
+		dbRowsGet1: [][]driver.Value{
+			[]driver.Value{"x0c0s1b1", "NodeBMC", "", "10.254.2.12", "", "10.254.2.12", false, "da4faffe-6491-4f3f-ab54-3bf8fce57531", "root", "", false, false, "a4bf012e85b5", "10.254.2.12", false, "", json.RawMessage(`{}`)},
+		},
+		expectedPrepareGet1: regexp.QuoteMeta(getRFEndpointPrefix + " WHERE (id = $1);"),
+		expectedArgsGet1:    []driver.Value{"x0c0s1b1"},
+		dbError:             nil,
+		expectedPrepare:     "",
+		expectedArgs:        []driver.Value{},
+		dbErrorGet2:         nil,
+		dbRowsGet2:          [][]driver.Value{},
+		expectedPrepareGet2: "",
+		expectedArgsGet2:    []driver.Value{},
+		expectedResp:        nil,
+		expectedErr:         fmt.Errorf("Some Error"),
+		expectErr:           true,
+	}, { // Test 3 No RFEndpoint
+		id:                  "x0c0s1b1",
+		epp:                 sm.RedfishEndpointPatch{},
+		dbErrorGet1:         nil,
+		dbRowsGet1:          [][]driver.Value{},
+		expectedPrepareGet1: regexp.QuoteMeta(getRFEndpointPrefix + " WHERE (id = $1);"),
+		expectedArgsGet1:    []driver.Value{"x0c0s1b1"},
+		dbError:             nil,
+		expectedPrepare:     "",
+		expectedArgs:        []driver.Value{},
+		dbErrorGet2:         nil,
+		dbRowsGet2:          [][]driver.Value{},
+		expectedPrepareGet2: "",
+		expectedArgsGet2:    []driver.Value{},
+		expectedResp:        nil,
+		expectedIDs:         []string{},
+		expectedErr:         nil,
+		expectErr:           true,
+	}, { // Test 4 IPAddress update
+		id: "x0c0s1b1",
+		epp: sm.RedfishEndpointPatch{
+			IPAddr: &ipAddr,
+		},
+		dbErrorGet1: nil,
+		dbRowsGet1: [][]driver.Value{
+			[]driver.Value{"x0c0s1b1", "NodeBMC", "", "10.254.2.12", "", "10.254.2.12", false, "da4faffe-6491-4f3f-ab54-3bf8fce57531", "root", "", false, false, "a4bf012e85b5", "10.254.2.12", false, "", json.RawMessage(`{}`)},
+		},
+		expectedPrepareGet1: regexp.QuoteMeta(getRFEndpointPrefix + " WHERE (id = $1);"),
+		expectedArgsGet1:    []driver.Value{"x0c0s1b1"},
+		dbError:             nil,
+		expectedPrepare:     regexp.QuoteMeta(updatePgRFEndpointNoDiscInfoQuery),
+		expectedArgs:        []driver.Value{"NodeBMC", "", "10.254.2.13", "", "10.254.2.13", false, "da4faffe-6491-4f3f-ab54-3bf8fce57531", "root", "", false, false, "a4bf012e85b5", "10.254.2.13", false, "", "x0c0s1b1"},
+		dbErrorGet2:         nil,
+		dbRowsGet2: [][]driver.Value{
+			[]driver.Value{"x0c0s1b1", "NodeBMC", "", "10.254.2.13", "", "10.254.2.13", false, "da4faffe-6491-4f3f-ab54-3bf8fce57531", "root", "", false, false, "a4bf012e85b5", "10.254.2.13", false, "", json.RawMessage(`{}`)},
+		},
+		expectedPrepareGet2: regexp.QuoteMeta(getRFEndpointByIDQuery),
+		expectedArgsGet2:    []driver.Value{"x0c0s1b1"},
+		expectedResp: &sm.RedfishEndpoint{
+			RedfishEPDescription: rf.RedfishEPDescription{
+				ID:             "x0c0s1b1",
+				Type:           "NodeBMC",
+				Name:           "",
+				Hostname:       "10.254.2.13",
+				Domain:         "",
+				FQDN:           "10.254.2.13",
+				Enabled:        false,
+				UUID:           "da4faffe-6491-4f3f-ab54-3bf8fce57531",
+				User:           "root",
+				Password:       "",
+				UseSSDP:        false,
+				MACRequired:    false,
+				MACAddr:        "a4bf012e85b5",
+				IPAddr:         "10.254.2.13",
+				RediscOnUpdate: false,
+				TemplateID:     "",
+				DiscInfo:       emptyDiscInfo,
+			},
+		},
+		expectedIDs: []string{},
+		expectedErr: nil,
+		expectErr:   false,
 	}}
 
 	for i, test := range tests {
 		ResetMockDB()
+		// before we actually execute our api function, we need to expect required DB actions
+		rows1 := sqlmock.NewRows(columns)
+		for _, row := range test.dbRowsGet1 {
+			rows1.AddRow(row...)
+		}
+		rows2 := sqlmock.NewRows(columns)
+		for _, row := range test.dbRowsGet2 {
+			rows2.AddRow(row...)
+		}
+
 		mockPG.ExpectBegin()
-		if test.expectedPrepare == "" && test.dbError != nil {
-			mockPG.ExpectRollback()
-		} else if test.dbError != nil {
-			mockPG.ExpectPrepare(test.expectedPrepare).ExpectExec().WillReturnError(test.dbError)
+		if test.dbErrorGet1 != nil {
+			mockPG.ExpectPrepare(ToPGQueryArgs(test.expectedPrepareGet1)).ExpectQuery().WillReturnError(test.dbErrorGet1)
 			mockPG.ExpectRollback()
 		} else {
-			mockPG.ExpectPrepare(test.expectedPrepare).ExpectExec().WithArgs(test.expectedArgs...).WillReturnResult(sqlmock.NewResult(0, int64(len(test.in))))
-			mockPG.ExpectCommit()
+			mockPG.ExpectPrepare(ToPGQueryArgs(test.expectedPrepareGet1)).ExpectQuery().WithArgs(test.expectedArgsGet1...).WillReturnRows(rows1)
+			if test.expectErr {
+				mockPG.ExpectRollback()
+			} else if test.dbError != nil {
+				mockPG.ExpectPrepare(ToPGQueryArgs(test.expectedPrepare)).ExpectExec().WillReturnError(test.dbError)
+				mockPG.ExpectRollback()
+			} else {
+				mockPG.ExpectPrepare(ToPGQueryArgs(test.expectedPrepare)).ExpectExec().WithArgs(test.expectedArgs...).WillReturnResult(sqlmock.NewResult(0, 1))
+				if test.dbErrorGet2 != nil {
+					mockPG.ExpectPrepare(ToPGQueryArgs(test.expectedPrepareGet2)).ExpectQuery().WillReturnError(test.dbErrorGet2)
+					mockPG.ExpectRollback()
+				} else {
+					mockPG.ExpectPrepare(ToPGQueryArgs(test.expectedPrepareGet2)).ExpectQuery().WithArgs(test.expectedArgsGet2...).WillReturnRows(rows2)
+					mockPG.ExpectCommit()
+				}
+			}
 		}
 
-		err := dPG.InsertCompEthInterfaces(test.in)
+		resp, affectedIDs, err := dPG.PatchRFEndpointNoDiscInfo(test.id, test.epp)
 		// ensure all expectations have been met
 		if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
 			t.Errorf("Test %v Failed: Sql expectations were not met: %s", i, mock_err)
 		}
-		if test.dbError == nil && err != nil {
-			t.Errorf("Test %v Failed: Unexpected error received: %s", i, err)
-		} else if test.dbError != nil && err == nil {
-			t.Errorf("Test %v Failed: Expected an error.", i)
-		}
-	}
-}
-
-func TestDeleteCompEthInterfaceByID(t *testing.T) {
-
-	sqq := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+		if test.dbErrorGet1 == nil && test.dbError == nil && test.dbErrorGet2 == nil && test.expectedErr == nil {
+			if err != nil {
+				t.Errorf("Test %v Failed: Unexpected error received: %s", i, err)
+			} else {
+				if !reflect.DeepEqual(test.expectedResp, resp) {
+					t.Errorf("Test %v Failed: Expected RFEndpoint is %v - Received %v", i, test.expectedResp, resp)
+				}
+				if !reflect.DeepEqual(test.expectedIDs, affectedIDs) {
+					t.Errorf("Test %v Failed: Expected affectedIDs is %v - Received %v", i, test.expectedIDs, affectedIDs)
+				}
+			}
+		} else if err == nil {
+			t.Errorf("Test %v Failed: Expected an error.", i)
+		}
+	}
+}
 
-	delete1, _, _ := sqq.Delete(compEthTable).
-		Where(sq.Eq{compEthIdCol: "a4bf0138ee65"}).ToSql()
+///////////////////////////////////////////////////////////////////////////////
+// Service Endpoint Query Tests
+///////////////////////////////////////////////////////////////////////////////
+
+func TestPgGetServiceEndpointByID(t *testing.T) {
+	columns := []string{"rf_endpoint_id", "redfish_type", "redfish_subtype", "uuid", "odata_id", "rf_endpoint_fqdn", "service_info"}
 
 	tests := []struct {
+		svc             string
 		id              string
+		dbRows          [][]driver.Value
+		dbError         error
 		expectedPrepare string
 		expectedArgs    []driver.Value
-		expectedError   error
+		expectedEP      *sm.ServiceEndpoint
+		expectedErr     error
 	}{{
-		id:              "a4bf0138ee65",
-		expectedPrepare: regexp.QuoteMeta(delete1),
-		expectedArgs:    []driver.Value{"a4bf0138ee65"},
-		expectedError:   nil,
+		svc: "UpdateService",
+		id:  "x0c0s21b0",
+		dbRows: [][]driver.Value{
+			[]driver.Value{"x0c0s21b0", "UpdateService", "", "", "/redfish/v1/UpdateService", "10.4.0.8", json.RawMessage(`{}`)},
+		},
+		dbError:         nil,
+		expectedPrepare: regexp.QuoteMeta(getServiceEndpointPrefix + " WHERE (rf_endpoint_id = $1) AND (redfish_type = $2)"),
+		expectedArgs:    []driver.Value{"x0c0s21b0", "UpdateService"},
+		expectedEP:      &stest.TestServiceEndpointUpdate1,
+		expectedErr:     nil,
 	}, {
-		id:              "a4bf0138ee65",
-		expectedPrepare: regexp.QuoteMeta(delete1),
-		expectedArgs:    []driver.Value{"a4bf0138ee65"},
-		expectedError:   sql.ErrNoRows,
+		svc:             "UpdateService",
+		id:              "foo",
+		dbRows:          nil,
+		dbError:         nil,
+		expectedPrepare: "",
+		expectedArgs:    nil,
+		expectedEP:      nil,
+		expectedErr:     ErrHMSDSArgBadID,
+	}, {
+		svc:             "Update:Service",
+		id:              "x0c0s21b0",
+		dbRows:          nil,
+		dbError:         nil,
+		expectedPrepare: "",
+		expectedArgs:    nil,
+		expectedEP:      nil,
+		expectedErr:     ErrHMSDSArgBadRedfishType,
+	}, {
+		svc:             "UpdateService",
+		id:              "x0c0s21b0",
+		dbRows:          nil,
+		dbError:         sql.ErrNoRows,
+		expectedPrepare: regexp.QuoteMeta(getServiceEndpointPrefix + " WHERE (rf_endpoint_id = $1) AND (redfish_type = $2)"),
+		expectedArgs:    []driver.Value{"x0c0s21b0", "UpdateService"},
+		expectedEP:      nil,
+		expectedErr:     nil,
+	}}
+
+	for i, test := range tests {
+		ResetMockDB()
+		// before we actually execute our api function, we need to expect required DB actions
+		rows := sqlmock.NewRows(columns)
+		for _, row := range test.dbRows {
+			rows.AddRow(row...)
+		}
+
+		mockPG.ExpectBegin()
+		if test.dbError != nil {
+			mockPG.ExpectPrepare(test.expectedPrepare).ExpectQuery().WillReturnError(test.dbError)
+			mockPG.ExpectRollback()
+		} else if test.expectedErr != nil {
+			mockPG.ExpectRollback()
+		} else {
+			mockPG.ExpectPrepare(test.expectedPrepare).ExpectQuery().WithArgs(test.expectedArgs...).WillReturnRows(rows)
+			mockPG.ExpectCommit()
+		}
+
+		sep, err := dPG.GetServiceEndpointByID(test.svc, test.id)
+		// ensure all expectations have been met
+		if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
+			t.Errorf("Test %v Failed: Sql expectations were not met: %s", i, mock_err)
+		}
+		if test.dbError == nil && test.expectedErr == nil {
+			if err != nil {
+				t.Errorf("Test %v Failed: Unexpected error received: %s", i, err)
+			} else if !reflect.DeepEqual(test.expectedEP, sep) {
+				t.Errorf("Test %v Failed: Expected service endpoint '%v'; Recieved service endpoint '%v'", i, test.expectedEP, sep)
+			}
+		} else if err == nil {
+			t.Errorf("Test %v Failed: Expected an error.", i)
+		}
+	}
+}
+
+func TestPgGetServiceEndpointsByAll(t *testing.T) {
+	columns := []string{"rf_endpoint_id", "redfish_type", "redfish_subtype", "uuid", "odata_id", "rf_endpoint_fqdn", "service_info"}
+
+	tests := []struct {
+		dbRows          [][]driver.Value
+		dbError         error
+		expectedPrepare string
+		expectedEPs     []*sm.ServiceEndpoint
+	}{{
+		dbRows: [][]driver.Value{
+			[]driver.Value{"x0c0s21b0", "UpdateService", "", "", "/redfish/v1/UpdateService", "10.4.0.8", json.RawMessage(`{}`)},
+			[]driver.Value{"x0c0s22b0", "UpdateService", "", "", "/redfish/v1/UpdateService", "10.4.0.9", json.RawMessage(`{}`)},
+		},
+		dbError:         nil,
+		expectedPrepare: regexp.QuoteMeta(getServiceEndpointsAllQuery),
+		expectedEPs:     stest.TestServiceEndpointArrayUpdates.ServiceEndpoints,
+	}, {
+		dbRows:          nil,
+		dbError:         sql.ErrNoRows,
+		expectedPrepare: regexp.QuoteMeta(getServiceEndpointsAllQuery),
+		expectedEPs:     nil,
+	}}
+
+	for i, test := range tests {
+		ResetMockDB()
+		// before we actually execute our api function, we need to expect required DB actions
+		rows := sqlmock.NewRows(columns)
+		for _, row := range test.dbRows {
+			rows.AddRow(row...)
+		}
+
+		mockPG.ExpectBegin()
+		if test.dbError != nil {
+			mockPG.ExpectPrepare(test.expectedPrepare).ExpectQuery().WillReturnError(test.dbError)
+			mockPG.ExpectRollback()
+		} else {
+			mockPG.ExpectPrepare(test.expectedPrepare).ExpectQuery().WillReturnRows(rows)
+			mockPG.ExpectCommit()
+		}
+
+		seps, err := dPG.GetServiceEndpointsAll()
+		// ensure all expectations have been met
+		if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
+			t.Errorf("Test %v Failed: Sql expectations were not met: %s", i, mock_err)
+		}
+		if test.dbError == nil {
+			if err != nil {
+				t.Errorf("Test %v Failed: Unexpected error received: %s", i, err)
+			} else if !reflect.DeepEqual(test.expectedEPs, seps) {
+				t.Errorf("Test %v Failed: Expected service endpoints '%v'; Recieved service endpoints '%v'", i, test.expectedEPs, seps)
+			}
+		} else if err == nil {
+			t.Errorf("Test %v Failed: Expected an error.", i)
+		}
+	}
+}
+
+func TestPgGetServiceEndpointsFilter(t *testing.T) {
+	columns := []string{"rf_endpoint_id", "redfish_type", "redfish_subtype", "uuid", "odata_id", "rf_endpoint_fqdn", "service_info"}
+
+	tests := []struct {
+		f               *ServiceEPFilter
+		dbRows          [][]driver.Value
+		dbError         error
+		expectedPrepare string
+		expectedArgs    []driver.Value
+		expectedEPs     []*sm.ServiceEndpoint
+		expectedErr     error
+	}{{
+		f: &ServiceEPFilter{
+			Service:      []string{"UpdateService"},
+			RfEndpointID: []string{"x0c0s21b0"},
+		},
+		dbRows: [][]driver.Value{
+			[]driver.Value{"x0c0s21b0", "UpdateService", "", "", "/redfish/v1/UpdateService", "10.4.0.8", json.RawMessage(`{}`)},
+		},
+		dbError:         nil,
+		expectedPrepare: regexp.QuoteMeta(getServiceEndpointPrefix + " WHERE (rf_endpoint_id = $1) AND (redfish_type = $2)"),
+		expectedArgs:    []driver.Value{"x0c0s21b0", "UpdateService"},
+		expectedEPs:     stest.TestServiceEndpointArrayUpdate1.ServiceEndpoints,
+		expectedErr:     nil,
+	}, {
+		f: &ServiceEPFilter{
+			Service:      []string{"UpdateService"},
+			RfEndpointID: []string{"x0c0s21b0", "x0c0s22b0"},
+		},
+		dbRows: [][]driver.Value{
+			[]driver.Value{"x0c0s21b0", "UpdateService", "", "", "/redfish/v1/UpdateService", "10.4.0.8", json.RawMessage(`{}`)},
+			[]driver.Value{"x0c0s22b0", "UpdateService", "", "", "/redfish/v1/UpdateService", "10.4.0.9", json.RawMessage(`{}`)},
+		},
+		dbError:         nil,
+		expectedPrepare: regexp.QuoteMeta(getServiceEndpointPrefix + " WHERE (rf_endpoint_id = $1 OR rf_endpoint_id = $2) AND (redfish_type = $3)"),
+		expectedArgs:    []driver.Value{"x0c0s21b0", "x0c0s22b0", "UpdateService"},
+		expectedEPs:     stest.TestServiceEndpointArrayUpdates.ServiceEndpoints,
+		expectedErr:     nil,
+	}, {
+		f: nil,
+		dbRows: [][]driver.Value{
+			[]driver.Value{"x0c0s21b0", "UpdateService", "", "", "/redfish/v1/UpdateService", "10.4.0.8", json.RawMessage(`{}`)},
+		},
+		dbError:         nil,
+		expectedPrepare: regexp.QuoteMeta(getServiceEndpointsAllQuery),
+		expectedArgs:    nil,
+		expectedEPs:     stest.TestServiceEndpointArrayUpdate1.ServiceEndpoints,
+		expectedErr:     nil,
+	}, {
+		f: &ServiceEPFilter{
+			Service:      []string{"UpdateService"},
+			RfEndpointID: []string{"foo"},
+		},
+		dbRows:          nil,
+		dbError:         nil,
+		expectedPrepare: "",
+		expectedArgs:    nil,
+		expectedEPs:     nil,
+		expectedErr:     ErrHMSDSArgBadID,
+	}, {
+		f: &ServiceEPFilter{
+			Service:      []string{"Update:Service"},
+			RfEndpointID: []string{"x0c0s21b0"},
+		},
+		dbRows:          nil,
+		dbError:         nil,
+		expectedPrepare: "",
+		expectedArgs:    nil,
+		expectedEPs:     nil,
+		expectedErr:     ErrHMSDSArgBadRedfishType,
+	}, {
+		f: &ServiceEPFilter{
+			Service:      []string{"UpdateService"},
+			RfEndpointID: []string{"x0c0s21b0"},
+		},
+		dbRows:          nil,
+		dbError:         sql.ErrNoRows,
+		expectedPrepare: regexp.QuoteMeta(getServiceEndpointPrefix + " WHERE (rf_endpoint_id = $1) AND (redfish_type = $2)"),
+		expectedArgs:    []driver.Value{"x0c0s21b0", "UpdateService"},
+		expectedEPs:     nil,
+		expectedErr:     nil,
+	}}
+
+	for i, test := range tests {
+		ResetMockDB()
+		// before we actually execute our api function, we need to expect required DB actions
+		rows := sqlmock.NewRows(columns)
+		for _, row := range test.dbRows {
+			rows.AddRow(row...)
+		}
+
+		mockPG.ExpectBegin()
+		if test.dbError != nil {
+			mockPG.ExpectPrepare(test.expectedPrepare).ExpectQuery().WillReturnError(test.dbError)
+			mockPG.ExpectRollback()
+		} else if test.expectedErr != nil {
+			mockPG.ExpectRollback()
+		} else {
+			if test.expectedArgs != nil {
+				mockPG.ExpectPrepare(test.expectedPrepare).ExpectQuery().WithArgs(test.expectedArgs...).WillReturnRows(rows)
+			} else {
+				mockPG.ExpectPrepare(test.expectedPrepare).ExpectQuery().WillReturnRows(rows)
+			}
+			mockPG.ExpectCommit()
+		}
+
+		seps, err := dPG.GetServiceEndpointsFilter(test.f)
+		// ensure all expectations have been met
+		if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
+			t.Errorf("Test %v Failed: Sql expectations were not met: %s", i, mock_err)
+		}
+		if test.dbError == nil && test.expectedErr == nil {
+			if err != nil {
+				t.Errorf("Test %v Failed: Unexpected error received: %s", i, err)
+			} else if !reflect.DeepEqual(test.expectedEPs, seps) {
+				t.Errorf("Test %v Failed: Expected service endpoint '%v'; Recieved service endpoint '%v'", i, test.expectedEPs, seps)
+			}
+		} else if err == nil {
+			t.Errorf("Test %v Failed: Expected an error.", i)
+		}
+	}
+}
+
+func TestPgUpsertServiceEndpoint(t *testing.T) {
+	tests := []struct {
+		sep             *sm.ServiceEndpoint
+		dbError         error
+		expectedPrepare string
+		expectedArgs    []driver.Value
+		expectedErr     error
+	}{{
+		sep:             &stest.TestServiceEndpointUpdate1,
+		dbError:         nil,
+		expectedPrepare: regexp.QuoteMeta(upsertPgServiceEndpointQuery),
+		expectedArgs: []driver.Value{
+			stest.TestServiceEndpointUpdate1.RfEndpointID,
+			stest.TestServiceEndpointUpdate1.RedfishType,
+			stest.TestServiceEndpointUpdate1.RedfishSubtype,
+			stest.TestServiceEndpointUpdate1.UUID,
+			stest.TestServiceEndpointUpdate1.OdataID,
+			stest.TestServiceEndpointUpdate1.ServiceInfo,
+		},
+		expectedErr: nil,
+	}, {
+		sep:             &stest.TestServiceEndpointUpdate1,
+		dbError:         sql.ErrNoRows,
+		expectedPrepare: regexp.QuoteMeta(upsertPgServiceEndpointQuery),
+		expectedArgs: []driver.Value{
+			stest.TestServiceEndpointUpdate1.RfEndpointID,
+			stest.TestServiceEndpointUpdate1.RedfishType,
+			stest.TestServiceEndpointUpdate1.RedfishSubtype,
+			stest.TestServiceEndpointUpdate1.UUID,
+			stest.TestServiceEndpointUpdate1.OdataID,
+			stest.TestServiceEndpointUpdate1.ServiceInfo,
+		},
+		expectedErr: nil,
+	}, {
+		sep:             nil,
+		dbError:         nil,
+		expectedPrepare: "",
+		expectedArgs:    nil,
+		expectedErr:     ErrHMSDSArgNil,
+	}}
+
+	for i, test := range tests {
+		ResetMockDB()
+		// before we actually execute our api function, we need to expect required DB actions
+		mockPG.ExpectBegin()
+		if test.dbError != nil {
+			mockPG.ExpectPrepare(ToPGQueryArgs(test.expectedPrepare)).ExpectExec().WillReturnError(test.dbError)
+			mockPG.ExpectRollback()
+		} else if test.expectedErr != nil {
+			mockPG.ExpectRollback()
+		} else {
+			mockPG.ExpectPrepare(ToPGQueryArgs(test.expectedPrepare)).ExpectExec().WithArgs(test.expectedArgs...).WillReturnResult(sqlmock.NewResult(0, 1))
+			mockPG.ExpectCommit()
+		}
+
+		err := dPG.UpsertServiceEndpoint(test.sep)
+		// ensure all expectations have been met
+		if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
+			t.Errorf("Test %v Failed: Sql expectations were not met: %s", i, mock_err)
+		}
+		if test.dbError == nil && test.expectedErr == nil {
+			if err != nil {
+				t.Errorf("Test %v Failed: Unexpected error received: %s", i, err)
+			}
+		} else if err == nil {
+			t.Errorf("Test %v Failed: Expected an error.", i)
+		}
+	}
+}
+
+func TestPgUpsertServiceEndpoints(t *testing.T) {
+	upsertSuffix := "ON CONFLICT(" + serviceEPsRFEndpointIDCol + ", "+ serviceEPsRedfishTypeCol + ") DO UPDATE SET " +
+		serviceEPsRedfishSubtypeCol + " = EXCLUDED." + serviceEPsRedfishSubtypeCol + ", " +
+		serviceEPsUUIDCol + " = EXCLUDED." + serviceEPsUUIDCol + ", " +
+		serviceEPsODataIDCol + " = EXCLUDED." + serviceEPsODataIDCol + ", " +
+		serviceEPsServiceInfoCol + " = EXCLUDED." + serviceEPsServiceInfoCol
+
+	var servEP sm.ServiceEndpoint
+
+	sqq := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	insert1, _, _ := sqq.Insert(serviceEPsTable).
+		Columns(serviceEPsCols...).
+		Values(servEP.RfEndpointID, servEP.RedfishType, servEP.RedfishSubtype, servEP.UUID, servEP.OdataID, servEP.ServiceInfo).
+		Suffix(upsertSuffix).ToSql()
+	insert2, _, _ := sqq.Insert(serviceEPsTable).
+		Columns(serviceEPsCols...).
+		Values(servEP.RfEndpointID, servEP.RedfishType, servEP.RedfishSubtype, servEP.UUID, servEP.OdataID, servEP.ServiceInfo).
+		Values(servEP.RfEndpointID, servEP.RedfishType, servEP.RedfishSubtype, servEP.UUID, servEP.OdataID, servEP.ServiceInfo).
+		Suffix(upsertSuffix).ToSql()
+	tests := []struct {
+		seps            *sm.ServiceEndpointArray
+		dbError         error
+		expectedPrepare string
+		expectedArgs    []driver.Value
+		expectedErr     error
+	}{{
+		seps:            &stest.TestServiceEndpointArrayUpdates,
+		dbError:         nil,
+		expectedPrepare: regexp.QuoteMeta(insert2),
+		expectedArgs:    []driver.Value{
+			stest.TestServiceEndpointArrayUpdates.ServiceEndpoints[0].RfEndpointID,
+			stest.TestServiceEndpointArrayUpdates.ServiceEndpoints[0].RedfishType,
+			stest.TestServiceEndpointArrayUpdates.ServiceEndpoints[0].RedfishSubtype,
+			stest.TestServiceEndpointArrayUpdates.ServiceEndpoints[0].UUID,
+			stest.TestServiceEndpointArrayUpdates.ServiceEndpoints[0].OdataID,
+			stest.TestServiceEndpointArrayUpdates.ServiceEndpoints[0].ServiceInfo,
+			stest.TestServiceEndpointArrayUpdates.ServiceEndpoints[1].RfEndpointID,
+			stest.TestServiceEndpointArrayUpdates.ServiceEndpoints[1].RedfishType,
+			stest.TestServiceEndpointArrayUpdates.ServiceEndpoints[1].RedfishSubtype,
+			stest.TestServiceEndpointArrayUpdates.ServiceEndpoints[1].UUID,
+			stest.TestServiceEndpointArrayUpdates.ServiceEndpoints[1].OdataID,
+			stest.TestServiceEndpointArrayUpdates.ServiceEndpoints[1].ServiceInfo,
+		},
+		expectedErr: nil,
+	}, {
+		seps:            &stest.TestServiceEndpointArrayUpdate1,
+		dbError:         nil,
+		expectedPrepare: regexp.QuoteMeta(insert1),
+		expectedArgs:    []driver.Value{
+			stest.TestServiceEndpointArrayUpdate1.ServiceEndpoints[0].RfEndpointID,
+			stest.TestServiceEndpointArrayUpdate1.ServiceEndpoints[0].RedfishType,
+			stest.TestServiceEndpointArrayUpdate1.ServiceEndpoints[0].RedfishSubtype,
+			stest.TestServiceEndpointArrayUpdate1.ServiceEndpoints[0].UUID,
+			stest.TestServiceEndpointArrayUpdate1.ServiceEndpoints[0].OdataID,
+			stest.TestServiceEndpointArrayUpdate1.ServiceEndpoints[0].ServiceInfo,
+		},
+		expectedErr: nil,
+	}, {
+		seps:            &stest.TestServiceEndpointArrayUpdate1,
+		dbError:         sql.ErrNoRows,
+		expectedPrepare: regexp.QuoteMeta(insert1),
+		expectedArgs:    []driver.Value{
+			stest.TestServiceEndpointArrayUpdate1.ServiceEndpoints[0].RfEndpointID,
+			stest.TestServiceEndpointArrayUpdate1.ServiceEndpoints[0].RedfishType,
+			stest.TestServiceEndpointArrayUpdate1.ServiceEndpoints[0].RedfishSubtype,
+			stest.TestServiceEndpointArrayUpdate1.ServiceEndpoints[0].UUID,
+			stest.TestServiceEndpointArrayUpdate1.ServiceEndpoints[0].OdataID,
+			stest.TestServiceEndpointArrayUpdate1.ServiceEndpoints[0].ServiceInfo,
+		},
+		expectedErr: nil,
+	}, {
+		seps:            &sm.ServiceEndpointArray{ServiceEndpoints: []*sm.ServiceEndpoint{nil}},
+		dbError:         nil,
+		expectedPrepare: "",
+		expectedArgs:    nil,
+		expectedErr:     ErrHMSDSArgNil,
+	}}
+
+	for i, test := range tests {
+		ResetMockDB()
+		// before we actually execute our api function, we need to expect required DB actions
+		mockPG.ExpectBegin()
+		if test.dbError != nil {
+			mockPG.ExpectPrepare(test.expectedPrepare).ExpectExec().WillReturnError(test.dbError)
+			mockPG.ExpectRollback()
+		} else if test.expectedErr != nil {
+			mockPG.ExpectRollback()
+		} else {
+			mockPG.ExpectPrepare(test.expectedPrepare).ExpectExec().WithArgs(test.expectedArgs...).WillReturnResult(sqlmock.NewResult(0, int64(len(test.seps.ServiceEndpoints))))
+			mockPG.ExpectCommit()
+		}
+
+		err := dPG.UpsertServiceEndpoints(test.seps)
+		// ensure all expectations have been met
+		if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
+			t.Errorf("Test %v Failed: Sql expectations were not met: %s", i, mock_err)
+		}
+		if test.dbError == nil && test.expectedErr == nil {
+			if err != nil {
+				t.Errorf("Test %v Failed: Unexpected error received: %s", i, err)
+			}
+		} else if err == nil {
+			t.Errorf("Test %v Failed: Expected an error.", i)
+		}
+	}
+}
+
+func TestPgDeleteServiceEndpointByID(t *testing.T) {
+	tests := []struct {
+		svc             string
+		id              string
+		dbResult        int64
+		dbError         error
+		expectedPrepare string
+		expectedArgs    []driver.Value
+		expectedResult  bool
+		expectedErr     error
+	}{{
+		svc:             "UpdateService",
+		id:              "x0c0s21b0",
+		dbResult:        1,
+		dbError:         nil,
+		expectedPrepare: regexp.QuoteMeta(deleteServiceByRfEPandRfTypeQuery),
+		expectedArgs:    []driver.Value{"x0c0s21b0", "UpdateService"},
+		expectedResult:  true,
+		expectedErr:     nil,
+	}, {
+		svc:             "",
+		id:              "x0c0s21b0",
+		dbResult:        0,
+		dbError:         nil,
+		expectedPrepare: "",
+		expectedArgs:    nil,
+		expectedResult:  false,
+		expectedErr:     ErrHMSDSArgNil,
+	}, {
+		svc:             "UpdateService",
+		id:              "",
+		dbResult:        0,
+		dbError:         nil,
+		expectedPrepare: "",
+		expectedArgs:    nil,
+		expectedResult:  false,
+		expectedErr:     ErrHMSDSArgNil,
+	}, {
+		svc:             "foo",
+		id:              "x0c0s21b0",
+		dbResult:        0,
+		dbError:         nil,
+		expectedPrepare: regexp.QuoteMeta(deleteServiceByRfEPandRfTypeQuery),
+		expectedArgs:    []driver.Value{"x0c0s21b0", "foo"},
+		expectedResult:  false,
+		expectedErr:     nil,
+	}, {
+		svc:             "UpdateService",
+		id:              "x0c0s21b0",
+		dbResult:        0,
+		dbError:         sql.ErrNoRows,
+		expectedPrepare: regexp.QuoteMeta(deleteServiceByRfEPandRfTypeQuery),
+		expectedArgs:    []driver.Value{"x0c0s21b0", "UpdateService"},
+		expectedResult:  false,
+		expectedErr:     nil,
+	}}
+
+	for i, test := range tests {
+		ResetMockDB()
+		// before we actually execute our api function, we need to expect required DB actions
+		mockPG.ExpectBegin()
+		if test.dbError != nil {
+			mockPG.ExpectPrepare(ToPGQueryArgs(test.expectedPrepare)).ExpectExec().WillReturnError(test.dbError)
+			mockPG.ExpectRollback()
+		} else if test.expectedErr != nil {
+			mockPG.ExpectRollback()
+		} else {
+			mockPG.ExpectPrepare(ToPGQueryArgs(test.expectedPrepare)).ExpectExec().WithArgs(test.expectedArgs...).WillReturnResult(sqlmock.NewResult(0, test.dbResult))
+			mockPG.ExpectCommit()
+		}
+
+		didDelete, err := dPG.DeleteServiceEndpointByID(test.svc, test.id)
+		// ensure all expectations have been met
+		if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
+			t.Errorf("Test %v Failed: Sql expectations were not met: %s", i, mock_err)
+		}
+		if test.dbError == nil && test.expectedErr == nil {
+			if err != nil {
+				t.Errorf("Test %v Failed: Unexpected error received: %s", i, err)
+			} else if test.expectedResult != didDelete {
+				t.Errorf("Test %v Failed: Expected didDelete '%v'; Recieved didDelete '%v'", i, test.expectedResult, didDelete)
+			}
+		} else if err == nil {
+			t.Errorf("Test %v Failed: Expected an error.", i)
+		}
+	}
+}
+
+func TestPgDeleteServiceEndpointsAll(t *testing.T) {
+	tests := []struct {
+		svc             string
+		id              string
+		dbResult        int64
+		dbError         error
+		expectedPrepare string
+		expectedResult  int64
+		expectedErr     error
+	}{{
+		dbResult:        1,
+		dbError:         nil,
+		expectedPrepare: regexp.QuoteMeta(deleteServiceEndpointsAllQuery),
+		expectedResult:  1,
+	}, {
+		dbResult:        0,
+		dbError:         nil,
+		expectedPrepare: regexp.QuoteMeta(deleteServiceEndpointsAllQuery),
+		expectedResult:  0,
+	}, {
+		dbResult:        0,
+		dbError:         sql.ErrNoRows,
+		expectedPrepare: regexp.QuoteMeta(deleteServiceEndpointsAllQuery),
+		expectedResult:  0,
+	}}
+
+	for i, test := range tests {
+		ResetMockDB()
+		// before we actually execute our api function, we need to expect required DB actions
+		mockPG.ExpectBegin()
+		if test.dbError != nil {
+			mockPG.ExpectPrepare(test.expectedPrepare).ExpectExec().WillReturnError(test.dbError)
+			mockPG.ExpectRollback()
+		} else {
+			mockPG.ExpectPrepare(test.expectedPrepare).ExpectExec().WillReturnResult(sqlmock.NewResult(0, test.dbResult))
+			mockPG.ExpectCommit()
+		}
+
+		numDelete, err := dPG.DeleteServiceEndpointsAll()
+		// ensure all expectations have been met
+		if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
+			t.Errorf("Test %v Failed: Sql expectations were not met: %s", i, mock_err)
+		}
+		if test.dbError == nil {
+			if err != nil {
+				t.Errorf("Test %v Failed: Unexpected error received: %s", i, err)
+			} else if test.expectedResult != numDelete {
+				t.Errorf("Test %v Failed: Expected numDelete '%v'; Recieved numDelete '%v'", i, test.expectedResult, numDelete)
+			}
+		} else if err == nil {
+			t.Errorf("Test %v Failed: Expected an error.", i)
+		}
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////
+//
+// Component Ethernet Interface Management
+//
+////////////////////////////////////////////////////////////////////////////
+
+func TestPgGetCompEthInterfaceFilter(t *testing.T) {
+	columns := addAliasToCols(compEthAlias, compEthCols, compEthCols)
+
+	// TODO: Add Network test for testing, test IPAddress/Network Filters
+	testCompEth1 := sm.CompEthInterfaceV2{
+		ID:      "a4bf0138ee65",
+		Desc:    "My description",
+		MACAddr: "a4:bf:01:38:ee:65",
+		IPAddrs: []sm.IPAddressMapping{
+			{IPAddr: "10.254.2.14"},
+		},
+		LastUpdate: "2020-05-13T21:59:02.363448Z",
+		CompID:     "x3000c0s26b0",
+		Type:       "NodeBMC",
+	}
+	testCompEth1IPAddrsRaw, _ := json.Marshal(testCompEth1.IPAddrs)
+
+	testCompEth2 := sm.CompEthInterfaceV2{
+		ID:      "a4bf0138ee67",
+		Desc:    "My description 2",
+		MACAddr: "a4:bf:01:38:ee:67",
+		IPAddrs: []sm.IPAddressMapping{
+			{IPAddr: "10.254.2.14"},
+		},
+		LastUpdate: "2020-05-13T21:59:02.363448Z",
+		CompID:     "x3000c0s26b0",
+		Type:       "NodeBMC",
+	}
+	testCompEth2IPAddrsRaw, _ := json.Marshal(testCompEth2.IPAddrs)
+
+	newerThanArg, _ := time.Parse(time.RFC3339, "2020-01-01T00:00:00Z")
+	olderThanArg, _ := time.Parse(time.RFC3339, "2020-02-01T00:00:00Z")
+
+	sqq := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	query1, _, _ := sqq.Select(columns...).
+		From(compEthTable + " " + compEthAlias).ToSql()
+
+	query2, _, _ := sqq.Select(columns...).
+		From(compEthTable + " " + compEthAlias).
+		Where(sq.Eq{compEthAlias + "." + compEthIdCol: []string{testCompEth1.ID}}).
+		Where(sq.Gt{compEthAlias + "." + compEthLastUpdateCol: newerThanArg}).
+		Where(sq.Lt{compEthAlias + "." + compEthLastUpdateCol: olderThanArg}).ToSql()
+
+	tests := []struct {
+		f_opts          []CompEthInterfaceFiltFunc
+		dbRows          [][]driver.Value
+		dbError         error
+		expectedPrepare string
+		expectedArgs    []driver.Value
+		expectedOut     []*sm.CompEthInterfaceV2
+		expectedErr     error
+	}{{
+		f_opts: []CompEthInterfaceFiltFunc{},
+		dbRows: [][]driver.Value{
+			[]driver.Value{testCompEth1.ID, testCompEth1.Desc, testCompEth1.MACAddr, testCompEth1.LastUpdate, testCompEth1.CompID, testCompEth1.Type, testCompEth1IPAddrsRaw},
+			[]driver.Value{testCompEth2.ID, testCompEth2.Desc, testCompEth2.MACAddr, testCompEth2.LastUpdate, testCompEth2.CompID, testCompEth2.Type, testCompEth2IPAddrsRaw},
+		},
+		dbError:         nil,
+		expectedPrepare: regexp.QuoteMeta(query1),
+		expectedArgs:    []driver.Value{},
+		expectedOut: []*sm.CompEthInterfaceV2{
+			&testCompEth1,
+			&testCompEth2,
+		},
+		expectedErr: nil,
+	}, {
+		f_opts: []CompEthInterfaceFiltFunc{
+			CEI_ID(testCompEth1.ID),
+			CEI_NewerThan("2020-01-01T00:00:00Z"),
+			CEI_OlderThan("2020-02-01T00:00:00Z"),
+		},
+		dbRows: [][]driver.Value{
+			[]driver.Value{testCompEth1.ID, testCompEth1.Desc, testCompEth1.MACAddr, testCompEth1.LastUpdate, testCompEth1.CompID, testCompEth1.Type, testCompEth1IPAddrsRaw},
+		},
+		dbError:         nil,
+		expectedPrepare: regexp.QuoteMeta(query2),
+		expectedArgs:    []driver.Value{testCompEth1.ID, newerThanArg, olderThanArg},
+		expectedOut: []*sm.CompEthInterfaceV2{
+			&testCompEth1,
+		},
+		expectedErr: nil,
+	}}
+
+	for i, test := range tests {
+		ResetMockDB()
+		// before we actually execute our api function, we need to expect required DB actions
+		rows := sqlmock.NewRows(columns)
+		for _, row := range test.dbRows {
+			rows.AddRow(row...)
+		}
+
+		if test.dbError != nil {
+			mockPG.ExpectPrepare(test.expectedPrepare).ExpectQuery().WillReturnError(test.dbError)
+		} else if test.expectedErr == nil {
+			if len(test.expectedArgs) > 0 {
+				mockPG.ExpectPrepare(test.expectedPrepare).ExpectQuery().WithArgs(test.expectedArgs...).WillReturnRows(rows)
+			} else {
+				mockPG.ExpectPrepare(test.expectedPrepare).ExpectQuery().WillReturnRows(rows)
+			}
+		}
+
+		out, err := dPG.GetCompEthInterfaceFilter(test.f_opts...)
+		if test.expectedErr == nil {
+			// ensure all expectations have been met
+			if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
+				t.Errorf("Test %v Failed: Sql expectations were not met: %s", i, mock_err)
+			}
+		}
+		if test.dbError == nil && test.expectedErr == nil {
+			if err != nil {
+				t.Errorf("Test %v Failed: Unexpected error received: %s", i, err)
+			} else if !reflect.DeepEqual(test.expectedOut, out) {
+				t.Errorf("Test %v Failed: Expected CompEthInterfaces '%v'; Received CompEthInterfaces '%v'", i, test.expectedOut, out)
+			}
+		} else if err == nil {
+			t.Errorf("Test %v Failed: Expected an error.", i)
+		}
+	}
+}
+
+func TestInsertCompEthInterfaces(t *testing.T) {
+	testCompEth1 := sm.CompEthInterfaceV2{
+		ID:      "a4bf0138ee65",
+		Desc:    "My description",
+		MACAddr: "a4:bf:01:38:ee:65",
+		IPAddrs: []sm.IPAddressMapping{
+			{IPAddr: "10.254.2.14"},
+		},
+		CompID: "x3000c0s26b0",
+		Type:   "NodeBMC",
+	}
+	testCompEth1IPAddrsRaw, _ := json.Marshal(testCompEth1.IPAddrs)
+
+	testCompEth2 := sm.CompEthInterfaceV2{
+		MACAddr: "a4:bf:01:38:ee:67",
+	}
+	testCompEth2IPAddrsRaw, _ := json.Marshal(testCompEth2.IPAddrs)
+
+	sqq := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+
+	insert1, _, _ := sqq.Insert(compEthTable).
+		Columns(compEthCols...).
+		Values("a4bf0138ee65", "My description", "a4:bf:01:38:ee:65", "NOW()", "x3000c0s26b0", "NodeBMC", `[{"IPAddress":"10.254.2.14","Network":""}]`).
+		Values("a4bf0138ee65", "My description", "a4:bf:01:38:ee:65", "NOW()", "x3000c0s26b0", "NodeBMC", `[{"IPAddress":"10.254.2.14","Network":""}]`).ToSql()
+
+	tests := []struct {
+		in              []*sm.CompEthInterfaceV2
+		expectedPrepare string
+		expectedArgs    []driver.Value
+		dbError         error
+	}{{ // Test 0 - Insert 2 new rows
+		in:              []*sm.CompEthInterfaceV2{&testCompEth1, &testCompEth2},
+		expectedPrepare: regexp.QuoteMeta(insert1),
+		expectedArgs:    []driver.Value{
+			testCompEth1.ID, testCompEth1.Desc, testCompEth1.MACAddr, "NOW()", testCompEth1.CompID, testCompEth1.Type, testCompEth1IPAddrsRaw,
+			"a4bf0138ee67", testCompEth2.Desc, testCompEth2.MACAddr, "NOW()", testCompEth2.CompID, testCompEth2.Type, testCompEth2IPAddrsRaw,
+		},
+		dbError: nil,
+	}, { // Test 1 - Test that database error is passed back
+		in:              []*sm.CompEthInterfaceV2{&testCompEth1, &testCompEth2},
+		expectedPrepare: regexp.QuoteMeta(insert1),
+		expectedArgs:    []driver.Value{
+			testCompEth1.ID, testCompEth1.Desc, testCompEth1.MACAddr, "NOW()", testCompEth1.CompID, testCompEth1.Type, testCompEth1IPAddrsRaw,
+			"a4bf0138ee67", testCompEth2.Desc, testCompEth2.MACAddr, "NOW()", testCompEth2.CompID, testCompEth2.Type, testCompEth2IPAddrsRaw,
+		},
+		dbError: sql.ErrNoRows,
+	}}
+
+	for i, test := range tests {
+		ResetMockDB()
+		mockPG.ExpectBegin()
+		if test.expectedPrepare == "" && test.dbError != nil {
+			mockPG.ExpectRollback()
+		} else if test.dbError != nil {
+			mockPG.ExpectPrepare(test.expectedPrepare).ExpectExec().WillReturnError(test.dbError)
+			mockPG.ExpectRollback()
+		} else {
+			mockPG.ExpectPrepare(test.expectedPrepare).ExpectExec().WithArgs(test.expectedArgs...).WillReturnResult(sqlmock.NewResult(0, int64(len(test.in))))
+			mockPG.ExpectCommit()
+		}
+
+		err := dPG.InsertCompEthInterfaces(test.in)
+		// ensure all expectations have been met
+		if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
+			t.Errorf("Test %v Failed: Sql expectations were not met: %s", i, mock_err)
+		}
+		if test.dbError == nil && err != nil {
+			t.Errorf("Test %v Failed: Unexpected error received: %s", i, err)
+		} else if test.dbError != nil && err == nil {
+			t.Errorf("Test %v Failed: Expected an error.", i)
+		}
+	}
+}
+
+func TestDeleteCompEthInterfaceByID(t *testing.T) {
+
+	sqq := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+
+	delete1, _, _ := sqq.Delete(compEthTable).
+		Where(sq.Eq{compEthIdCol: "a4bf0138ee65"}).ToSql()
+
+	tests := []struct {
+		id              string
+		expectedPrepare string
+		expectedArgs    []driver.Value
+		expectedError   error
+	}{{
+		id:              "a4bf0138ee65",
+		expectedPrepare: regexp.QuoteMeta(delete1),
+		expectedArgs:    []driver.Value{"a4bf0138ee65"},
+		expectedError:   nil,
+	}, {
+		id:              "a4bf0138ee65",
+		expectedPrepare: regexp.QuoteMeta(delete1),
+		expectedArgs:    []driver.Value{"a4bf0138ee65"},
+		expectedError:   sql.ErrNoRows,
+	}}
+
+	for i, test := range tests {
+		ResetMockDB()
+		mockPG.ExpectBegin()
+		if test.expectedError != nil {
+			mockPG.ExpectPrepare(test.expectedPrepare).ExpectExec().WillReturnError(test.expectedError)
+			mockPG.ExpectRollback()
+		} else {
+			mockPG.ExpectPrepare(test.expectedPrepare).ExpectExec().WithArgs(test.expectedArgs...).WillReturnResult(sqlmock.NewResult(0, 1))
+			mockPG.ExpectCommit()
+		}
+
+		_, err := dPG.DeleteCompEthInterfaceByID(test.id)
+		if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
+			t.Errorf("Test %v Failed: Sql expectations were not met: %s", i, mock_err)
+		}
+		if test.expectedError == nil && err != nil {
+			t.Errorf("Test %v Failed: Unexpected error received: %s", i, err)
+		} else if test.expectedError != nil && err == nil {
+			t.Errorf("Test %v Failed: Expected an error (%s).", i, test.expectedError)
+		}
+	}
+}
+
+func TestDeleteCompEthInterfacesAll(t *testing.T) {
+
+	sqq := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+
+	delete1, _, _ := sqq.Delete(compEthTable).ToSql()
+
+	tests := []struct {
+		expectedPrepare string
+		expectedError   error
+	}{{
+		expectedPrepare: regexp.QuoteMeta(delete1),
+		expectedError:   nil,
+	}, {
+		expectedPrepare: regexp.QuoteMeta(delete1),
+		expectedError:   sql.ErrNoRows,
+	}}
+
+	for i, test := range tests {
+		ResetMockDB()
+		mockPG.ExpectBegin()
+		if test.expectedError != nil {
+			mockPG.ExpectPrepare(test.expectedPrepare).ExpectExec().WillReturnError(test.expectedError)
+			mockPG.ExpectRollback()
+		} else {
+			mockPG.ExpectPrepare(test.expectedPrepare).ExpectExec().WillReturnResult(sqlmock.NewResult(0, 1))
+			mockPG.ExpectCommit()
+		}
+
+		_, err := dPG.DeleteCompEthInterfacesAll()
+		if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
+			t.Errorf("Test %v Failed: Sql expectations were not met: %s", i, mock_err)
+		}
+		if test.expectedError == nil && err != nil {
+			t.Errorf("Test %v Failed: Unexpected error received: %s", i, err)
+		} else if test.expectedError != nil && err == nil {
+			t.Errorf("Test %v Failed: Expected an error (%s).", i, test.expectedError)
+		}
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////
+//
+// State Change Notification (SCN) Subscriptions
+//
+////////////////////////////////////////////////////////////////////////////
+
+func TestGetSCNSubscriptionsAll(t *testing.T) {
+	tests := []struct {
+		dbColumns		[]string
+		dbRows			[][]driver.Value
+		dbError			error
+		expectedPrepare		string
+		expectedSCNSubs		*sm.SCNSubscriptionArray
+	}{{
+		dbColumns:		[]string{"id", "subscription"},
+		dbRows:			[][]driver.Value{
+						[]driver.Value{2, `{"Subscriber":"hmfd@sms01","States":["On","Off"],"Url":"https://foo/bar"}`},
+		},
+		dbError:		nil,
+		expectedPrepare:	regexp.QuoteMeta(tGetSCNSubscriptionQueryAll),
+		expectedSCNSubs:	&sm.SCNSubscriptionArray{[]sm.SCNSubscription{
+			sm.SCNSubscription{
+				ID:		2,
+				Subscriber:	"hmfd@sms01",
+				States:		[]string{"On", "Off"},
+				Url:		"https://foo/bar",
+			},
+		}},
+	}, {
+		dbColumns:		[]string{"id", "subscription"},
+		dbRows:			[][]driver.Value{
+						[]driver.Value{2, `{"Subscriber":"hmfd@sms01","States":["On","Off"],"Url":"https://foo/bar"}`},
+						[]driver.Value{3, `{"Subscriber":"hmfd@sms02","States":["Off","Ready"],"Url":"https://foo2/bar"}`},
+		},
+		dbError:		nil,
+		expectedPrepare:	regexp.QuoteMeta(tGetSCNSubscriptionQueryAll),
+		expectedSCNSubs:	&sm.SCNSubscriptionArray{[]sm.SCNSubscription{
+			sm.SCNSubscription{
+				ID:		2,
+				Subscriber:	"hmfd@sms01",
+				States:		[]string{"On", "Off"},
+				Url:		"https://foo/bar",
+			},
+			sm.SCNSubscription{
+				ID:		3,
+				Subscriber:	"hmfd@sms02",
+				States:		[]string{"Off", "Ready"},
+				Url:		"https://foo2/bar",
+			},
+		}},
+	}, {
+		dbColumns:		[]string{"id", "subscription"},
+		dbRows:			[][]driver.Value{
+						[]driver.Value{1, `{"Subscriber":"cray-hmnfd-798784bd66-s69mg_4","Roles":["compute","service"],"States":["Empty","Populated","Off","On","Standby","Halt","Ready"],"Url":"http://cray-hmnfd/hmi/v1/scn"}`},
+						[]driver.Value{2, `{"Subscriber":"cray-hmnfd-798784bd66-rkvzn_4","Roles":["service"],"States":["Populated","Off","On","Standby","Halt","Ready"],"Url":"http://cray-hmnfd/hmi/v1/scn"}`},
+						[]driver.Value{3, `{"Subscriber":"cray-hmnfd-798784bd66-bp8rc_23","Roles":["compute"],"States":["Empty","Off","On","Standby","Halt","Ready"],"Url":"http://cray-hmnfd/hmi/v1/scn"}`},
+		},
+		dbError:		nil,
+		expectedPrepare:	regexp.QuoteMeta(tGetSCNSubscriptionQueryAll),
+		expectedSCNSubs:	&sm.SCNSubscriptionArray{[]sm.SCNSubscription{
+			sm.SCNSubscription{
+				ID:		1,
+				Subscriber:	"cray-hmnfd-798784bd66-s69mg_4",
+				Roles:		[]string{"compute", "service"},
+				States:		[]string{"Empty", "Populated", "Off", "On", "Standby", "Halt", "Ready"},
+				Url:		"http://cray-hmnfd/hmi/v1/scn",
+			},
+			sm.SCNSubscription{
+				ID:		2,
+				Subscriber:	"cray-hmnfd-798784bd66-rkvzn_4",
+				Roles:		[]string{"service"},
+				States:		[]string{"Populated", "Off", "On", "Standby", "Halt", "Ready"},
+				Url:		"http://cray-hmnfd/hmi/v1/scn",
+			},
+			sm.SCNSubscription{
+				ID:		3,
+				Subscriber:	"cray-hmnfd-798784bd66-bp8rc_23",
+				Roles:		[]string{"compute"},
+				States:		[]string{"Empty", "Off", "On", "Standby", "Halt", "Ready"},
+				Url:		"http://cray-hmnfd/hmi/v1/scn",
+			},
+		}},
+	}, {
+		dbColumns:		[]string{"id", "subscription"},
+		dbRows:			[][]driver.Value{
+						[]driver.Value{"", `{}`},
+		},
+		dbError:		sql.ErrNoRows,
+		expectedPrepare:	regexp.QuoteMeta(tGetSCNSubscriptionQueryAll),
+		expectedSCNSubs:	&sm.SCNSubscriptionArray{[]sm.SCNSubscription{
+			sm.SCNSubscription{},
+		}},
+	}}
+
+	for i, test := range tests {
+		ResetMockDB()
+		// before we actually execute our api function, we need to expect required DB actions
+		rows := sqlmock.NewRows(test.dbColumns)
+		for _, row := range test.dbRows {
+			rows.AddRow(row...)
+		}
+
+		mockPG.ExpectBegin()
+		if test.dbError != nil {
+			mockPG.ExpectPrepare(test.expectedPrepare).ExpectQuery().WillReturnError(test.dbError)
+			mockPG.ExpectRollback()
+		} else {
+			mockPG.ExpectPrepare(test.expectedPrepare).ExpectQuery().WillReturnRows(rows)
+			mockPG.ExpectCommit()
+		}
+
+		subs, err := dPG.GetSCNSubscriptionsAll()
+		// ensure all expectations have been met
+		if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
+			t.Errorf("Test %v Failed: Sql expectations were not met: %s", i, mock_err)
+		}
+		if test.dbError == nil {
+			if err != nil {
+				t.Errorf("Test %v Failed: Unexpected error received: %s", i, err)
+			} else if !reflect.DeepEqual(test.expectedSCNSubs, subs) {
+				t.Errorf("Test %v Failed: Expected subArray '%v'; Recieved subArray '%v'", i, test.expectedSCNSubs, subs)
+			}
+		} else if err == nil {
+			t.Errorf("Test %v Failed: Expected an error.", i)
+		}
+	}
+}
+
+func TestGetSCNSubscription(t *testing.T) {
+	tests := []struct {
+		id			int64
+		dbColumns		[]string
+		dbRows			[][]driver.Value
+		dbError			error
+		expectedPrepare		string
+		expectedArgs		[]driver.Value
+		expectedSCNSub		*sm.SCNSubscription
+	}{{
+		id:			2,
+		dbColumns:		[]string{"id", "subscription"},
+		dbRows:			[][]driver.Value{
+						[]driver.Value{2, `{"Subscriber":"cray-hmnfd-69d99579c5-shpmk_3","States":["Empty","Populated","Off","On","Standby","Halt","Ready"],"Url":"http://cray-hmnfd/hmi/v1/scn"}`},
+		},
+		dbError:		nil,
+		expectedPrepare:	regexp.QuoteMeta(tGetSCNSubscriptionQueryId),
+		expectedArgs:		[]driver.Value{2},
+		expectedSCNSub:		&sm.SCNSubscription{
+			ID:		2,
+			Subscriber:	"cray-hmnfd-69d99579c5-shpmk_3",
+			States:		[]string{"Empty","Populated","Off","On","Standby","Halt","Ready"},
+			Url:		"http://cray-hmnfd/hmi/v1/scn",
+		},
+	}, {
+		id:			3,
+		dbColumns:		[]string{"id", "subscription"},
+		dbRows:			[][]driver.Value{
+						[]driver.Value{3, `{"Subscriber":"cray-hmnfd-798784bd66-s69mg_4","Roles":["compute","service"],"States":["Empty","Populated","Off","On","Standby","Halt","Ready"],"Url":"http://cray-hmnfd/hmi/v1/scn"}`},
+		},
+		dbError:		nil,
+		expectedPrepare:	regexp.QuoteMeta(tGetSCNSubscriptionQueryId),
+		expectedArgs:		[]driver.Value{3},
+		expectedSCNSub:		&sm.SCNSubscription{
+			ID:		3,
+			Subscriber:	"cray-hmnfd-798784bd66-s69mg_4",
+			Roles:		[]string{"compute","service"},
+			States:		[]string{"Empty","Populated","Off","On","Standby","Halt","Ready"},
+			Url:		"http://cray-hmnfd/hmi/v1/scn",
+		},
+	}, {
+		id:			0,
+		dbColumns:		[]string{"id", "subscription"},
+		dbRows:			[][]driver.Value{
+						[]driver.Value{"", `{}`},
+		},
+		dbError:		sql.ErrNoRows,
+		expectedPrepare:	regexp.QuoteMeta(tGetSCNSubscriptionQueryId),
+		expectedArgs:		[]driver.Value{},
+		expectedSCNSub:		&sm.SCNSubscription{},
+	}}
+
+	for i, test := range tests {
+		ResetMockDB()
+		// before we actually execute our api function, we need to expect required DB actions
+		rows := sqlmock.NewRows(test.dbColumns)
+		for _, row := range test.dbRows {
+			rows.AddRow(row...)
+		}
+
+		mockPG.ExpectBegin()
+		if test.dbError != nil {
+			mockPG.ExpectPrepare(test.expectedPrepare).ExpectQuery().WillReturnError(test.dbError)
+			mockPG.ExpectRollback()
+		} else {
+			mockPG.ExpectPrepare(test.expectedPrepare).ExpectQuery().WithArgs(test.expectedArgs...).WillReturnRows(rows)
+			mockPG.ExpectCommit()
+		}
+
+		sub, err := dPG.GetSCNSubscription(test.id)
+		// ensure all expectations have been met
+		if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
+			t.Errorf("Test %v Failed: Sql expectations were not met: %s", i, mock_err)
+		}
+		if test.dbError == nil {
+			if err != nil {
+				t.Errorf("Test %v Failed: Unexpected error received: %s", i, err)
+			} else if !reflect.DeepEqual(test.expectedSCNSub, sub) {
+				t.Errorf("Test %v Failed: Expected subArray '%v'; Recieved subArray '%v'", i, test.expectedSCNSub, sub)
+			}
+		} else if err == nil {
+			t.Errorf("Test %v Failed: Expected an error.", i)
+		}
+	}
+}
+
+func TestInsertSCNSubscription(t *testing.T) {
+	tests := []struct {
+		sub			sm.SCNPostSubscription
+		dbColumns		[]string
+		dbRows			[][]driver.Value
+		dbError			error
+		expectedPrepare		string
+		expectedArgs		[]driver.Value
+		expectedID		int64
+	}{{
+		sub:			sm.SCNPostSubscription{
+						Subscriber:	"hmfd@sms01",
+						States:		[]string{"On", "Off"},
+						Url:		"https://foo/bar",
+		},
+		dbColumns:		[]string{"id"},
+		dbRows:			[][]driver.Value{
+						[]driver.Value{4},
+		},
+		dbError:		nil,
+		expectedPrepare:	regexp.QuoteMeta(tInsertSCNSubscription),
+		expectedArgs:		[]driver.Value{"hmfd@sms01https://foo/bar",json.RawMessage(`{"Subscriber":"hmfd@sms01","States":["On","Off"],"Url":"https://foo/bar"}`)},
+		expectedID:		4,
+	}, {
+		sub:			sm.SCNPostSubscription{
+						Subscriber:	"cray-hmnfd-798784bd66-s69mg_4",
+						Roles:		[]string{"compute","service"},
+						States:		[]string{"Empty","Populated","Off","On","Standby","Halt","Ready"},
+						Url:		"http://cray-hmnfd/hmi/v1/scn",
+		},
+		dbColumns:		[]string{"id"},
+		dbRows:			[][]driver.Value{
+						[]driver.Value{5},
+		},
+		dbError:		nil,
+		expectedPrepare:	regexp.QuoteMeta(tInsertSCNSubscription),
+		expectedArgs:		[]driver.Value{"cray-hmnfd-798784bd66-s69mg_4http://cray-hmnfd/hmi/v1/scn",json.RawMessage(`{"Subscriber":"cray-hmnfd-798784bd66-s69mg_4","Roles":["compute","service"],"States":["Empty","Populated","Off","On","Standby","Halt","Ready"],"Url":"http://cray-hmnfd/hmi/v1/scn"}`)},
+		expectedID:		5,
+	}, {
+		sub:			sm.SCNPostSubscription{},
+		dbColumns:		[]string{"id"},
+		dbRows:			[][]driver.Value{
+						[]driver.Value{0},
+		},
+		dbError:		sql.ErrNoRows,
+		expectedPrepare:	regexp.QuoteMeta(tInsertSCNSubscription),
+		expectedArgs:		[]driver.Value{"",json.RawMessage(`{}`)},
+		expectedID:		0,
+	}}
+
+	for i, test := range tests {
+		ResetMockDB()
+		// before we actually execute our api function, we need to expect required DB actions
+		rows := sqlmock.NewRows(test.dbColumns)
+		for _, row := range test.dbRows {
+			rows.AddRow(row...)
+		}
+
+		mockPG.ExpectBegin()
+		if test.dbError != nil {
+			mockPG.ExpectPrepare(test.expectedPrepare).ExpectExec().WillReturnError(test.dbError)
+			mockPG.ExpectRollback()
+		} else {
+			mockPG.ExpectPrepare(test.expectedPrepare).ExpectExec().WithArgs(test.expectedArgs...).WillReturnResult(sqlmock.NewResult(0, 1))
+			mockPG.ExpectPrepare(regexp.QuoteMeta(tInsertSCNSubscriptionQueryLastVal)).ExpectQuery().WillReturnRows(rows)
+			mockPG.ExpectCommit()
+		}
+
+		id, err := dPG.InsertSCNSubscription(test.sub)
+		// ensure all expectations have been met
+		if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
+			t.Errorf("Test %v Failed: Sql expectations were not met: %s", i, mock_err)
+		}
+		if test.dbError == nil {
+			if err != nil {
+				t.Errorf("Test %v Failed: Unexpected error received: %s", i, err)
+			} else if test.expectedID != id {
+				t.Errorf("Test %v Failed: Expected id '%v'; Recieved id '%v'", i, test.expectedID, id)
+			}
+		} else if err == nil {
+			t.Errorf("Test %v Failed: Expected an error.", i)
+		}
+	}
+}
+
+func TestUpdateSCNSubscription(t *testing.T) {
+	tests := []struct {
+		id			int64
+		sub			sm.SCNPostSubscription
+		dbError			error
+		expectedPrepare		string
+		expectedArgs		[]driver.Value
+		expectedDidUpdate	bool
+	}{{
+		id:			4,
+		sub:			sm.SCNPostSubscription{
+						Subscriber:	"hmfd@sms01",
+						States:		[]string{"On", "Off"},
+						Url:		"https://foo/bar",
+		},
+		dbError:		nil,
+		expectedPrepare:	regexp.QuoteMeta(tUpdateSCNSubscription),
+		expectedArgs:		[]driver.Value{"hmfd@sms01https://foo/bar",json.RawMessage(`{"Subscriber":"hmfd@sms01","States":["On","Off"],"Url":"https://foo/bar"}`),4},
+		expectedDidUpdate:	true,
+	}, {
+		id:			0,
+		sub:			sm.SCNPostSubscription{},
+		dbError:		sql.ErrNoRows,
+		expectedPrepare:	regexp.QuoteMeta(tUpdateSCNSubscription),
+		expectedArgs:		[]driver.Value{"",json.RawMessage(`{}`),0},
+		expectedDidUpdate:	false,
+	}}
+
+	for i, test := range tests {
+		ResetMockDB()
+		mockPG.ExpectBegin()
+		if test.dbError != nil {
+			mockPG.ExpectPrepare(test.expectedPrepare).ExpectExec().WillReturnError(test.dbError)
+			mockPG.ExpectRollback()
+		} else {
+			mockPG.ExpectPrepare(test.expectedPrepare).ExpectExec().WithArgs(test.expectedArgs...).WillReturnResult(sqlmock.NewResult(0, 1))
+			mockPG.ExpectCommit()
+		}
+
+		didUpdate, err := dPG.UpdateSCNSubscription(test.id, test.sub)
+		// ensure all expectations have been met
+		if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
+			t.Errorf("Test %v Failed: Sql expectations were not met: %s", i, mock_err)
+		}
+		if test.dbError == nil {
+			if err != nil {
+				t.Errorf("Test %v Failed: Unexpected error received: %s", i, err)
+			} else if test.expectedDidUpdate && !didUpdate {
+				t.Errorf("Test %v Failed: Expected update; no update occurred", i)
+			} else if !test.expectedDidUpdate && didUpdate {
+				t.Errorf("Test %v Failed: Did not expect update; update occurred", i)
+			}
+		} else if err == nil {
+			t.Errorf("Test %v Failed: Expected an error.", i)
+		}
+	}
+}
+
+func TestPatchSCNSubscription(t *testing.T) {
+	tests := []struct {
+		id			int64
+		op			string
+		sub			sm.SCNPatchSubscription
+		dbColumns		[]string
+		dbRows			[][]driver.Value
+		dbError			error
+		expectedQueryPrepare	string
+		expectedUpdatePrepare	string
+		expectedQueryArgs	[]driver.Value
+		expectedUpdateArgs	[]driver.Value
+		expectedDidPatch	bool
+	}{{
+		id:			4,
+		op:			"add",
+		sub:			sm.SCNPatchSubscription{
+						Op:		"add",
+						States:		[]string{"On", "Off"},
+		},
+		dbColumns:		[]string{"id", "subscription"},
+		dbRows:			[][]driver.Value{
+						[]driver.Value{4, `{"Subscriber":"cray-hmnfd-69d99579c5-shpmk_3","States":["Empty","Populated","Off","On","Standby","Halt","Ready"],"Url":"http://cray-hmnfd/hmi/v1/scn"}`},
+		},
+		dbError:		nil,
+		expectedQueryPrepare:	regexp.QuoteMeta(tGetSCNSubscriptionUpdate),
+		expectedUpdatePrepare:	regexp.QuoteMeta(tUpdateSCNSubscription),
+		expectedQueryArgs:	[]driver.Value{4},
+		expectedUpdateArgs:	[]driver.Value{"cray-hmnfd-69d99579c5-shpmk_3http://cray-hmnfd/hmi/v1/scn",json.RawMessage(`{"Subscriber":"cray-hmnfd-69d99579c5-shpmk_3","States":["Empty","Populated","Off","On","Standby","Halt","Ready"],"Url":"http://cray-hmnfd/hmi/v1/scn"}`),4},
+		expectedDidPatch:	true,
+	}, {
+		id:			0,
+		op:			"remove",
+		sub:			sm.SCNPatchSubscription{},
+		dbColumns:		[]string{"", ""},
+		dbRows:			[][]driver.Value{
+						[]driver.Value{0, `{}`},
+		},
+		dbError:		sql.ErrNoRows,
+		expectedQueryPrepare:	regexp.QuoteMeta(tGetSCNSubscriptionUpdate),
+		expectedUpdatePrepare:	regexp.QuoteMeta(tUpdateSCNSubscription),
+		expectedQueryArgs:	[]driver.Value{},
+		expectedUpdateArgs:	[]driver.Value{"",json.RawMessage(`{}`),0},
+		expectedDidPatch:	false,
+	}}
+
+	for i, test := range tests {
+		ResetMockDB()
+		// before we actually execute our api function, we need to expect required DB actions
+		rows := sqlmock.NewRows(test.dbColumns)
+		for _, row := range test.dbRows {
+			rows.AddRow(row...)
+		}
+
+		mockPG.ExpectBegin()
+		if test.dbError != nil {
+			mockPG.ExpectPrepare(test.expectedQueryPrepare).ExpectQuery().WillReturnError(test.dbError)
+			mockPG.ExpectRollback()
+		} else {
+			mockPG.ExpectPrepare(test.expectedQueryPrepare).ExpectQuery().WithArgs(test.expectedQueryArgs...).WillReturnRows(rows)
+			mockPG.ExpectPrepare(test.expectedUpdatePrepare).ExpectExec().WithArgs(test.expectedUpdateArgs...).WillReturnResult(sqlmock.NewResult(0, 1))
+			mockPG.ExpectCommit()
+		}
+
+		didPatch, err := dPG.PatchSCNSubscription(test.id, test.op, test.sub)
+		// ensure all expectations have been met
+		if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
+			t.Errorf("Test %v Failed: Sql expectations were not met: %s", i, mock_err)
+		}
+		if test.dbError == nil {
+			if err != nil {
+				t.Errorf("Test %v Failed: Unexpected error received: %s", i, err)
+			} else if test.expectedDidPatch && !didPatch {
+				t.Errorf("Test %v Failed: Expected patch; no patch occurred", i)
+			} else if !test.expectedDidPatch && didPatch {
+				t.Errorf("Test %v Failed: Did not expect patch; patch occurred", i)
+			}
+		} else if err == nil {
+			t.Errorf("Test %v Failed: Expected an error.", i)
+		}
+	}
+}
+
+func TestDeleteSCNSubscription(t *testing.T) {
+	tests := []struct {
+		id			int64
+		dbError			error
+		expectedPrepare		string
+		expectedArgs		[]driver.Value
+		expectedDidDelete	bool
+	}{{
+		id:			5,
+		dbError:		nil,
+		expectedPrepare:	regexp.QuoteMeta(tDeleteSCNSubscription),
+		expectedArgs:		[]driver.Value{5},
+		expectedDidDelete:	true,
+	}, {
+		id:			0,
+		dbError:		sql.ErrNoRows,
+		expectedPrepare:	regexp.QuoteMeta(tDeleteSCNSubscription),
+		expectedArgs:		[]driver.Value{},
+		expectedDidDelete:	false,
+	}}
+
+	for i, test := range tests {
+		ResetMockDB()
+		mockPG.ExpectBegin()
+		if test.dbError != nil {
+			mockPG.ExpectPrepare(test.expectedPrepare).ExpectExec().WillReturnError(test.dbError)
+			mockPG.ExpectRollback()
+		} else {
+			mockPG.ExpectPrepare(test.expectedPrepare).ExpectExec().WithArgs(test.expectedArgs...).WillReturnResult(sqlmock.NewResult(0, 1))
+			mockPG.ExpectCommit()
+		}
+
+		didDelete, err := dPG.DeleteSCNSubscription(test.id)
+		// ensure all expectations have been met
+		if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
+			t.Errorf("Test %v Failed: Sql expectations were not met: %s", i, mock_err)
+		}
+		if test.dbError == nil {
+			if err != nil {
+				t.Errorf("Test %v Failed: Unexpected error received: %s", i, err)
+			} else if test.expectedDidDelete && !didDelete {
+				t.Errorf("Test %v Failed: Expected deletion; no deletion occurred", i)
+			} else if !test.expectedDidDelete && didDelete {
+				t.Errorf("Test %v Failed: Did not expect deletion; deletion occurred", i)
+			}
+		} else if err == nil {
+			t.Errorf("Test %v Failed: Expected an error.", i)
+		}
+	}
+}
+
+func TestDeleteSCNSubscriptionsAll(t *testing.T) {
+	tests := []struct {
+		dbError			error
+		expectedPrepare		string
+		expectedNumSubsDeleted	int64
+	}{{
+		dbError:		nil,
+		expectedPrepare:	regexp.QuoteMeta(tDeleteSCNSubscriptionAll),
+		expectedNumSubsDeleted:	1,
+	}, {
+		dbError:		nil,
+		expectedPrepare:	regexp.QuoteMeta(tDeleteSCNSubscriptionAll),
+		expectedNumSubsDeleted:	5,
+	}, {
+		dbError:		sql.ErrNoRows,
+		expectedPrepare:	regexp.QuoteMeta(tDeleteSCNSubscriptionAll),
+		expectedNumSubsDeleted:	0,
+	}}
+
+	for i, test := range tests {
+                ResetMockDB()
+		mockPG.ExpectBegin()
+		if test.dbError != nil {
+			mockPG.ExpectPrepare(test.expectedPrepare).ExpectExec().WillReturnError(test.dbError)
+			mockPG.ExpectRollback()
+		} else {
+			mockPG.ExpectPrepare(test.expectedPrepare).ExpectExec().WillReturnResult(sqlmock.NewResult(0, test.expectedNumSubsDeleted))
+			mockPG.ExpectCommit()
+		}
+
+		numSubsDeleted, err := dPG.DeleteSCNSubscriptionsAll()
+		// ensure all expectations have been met
+		if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
+			t.Errorf("Test %v Failed: Sql expectations were not met: %s", i, mock_err)
+		}
+		if test.dbError == nil {
+			if err != nil {
+				t.Errorf("Test %v Failed: Unexpected error received: %s", i, err)
+			} else if test.expectedNumSubsDeleted != numSubsDeleted {
+				t.Errorf("Test %v Failed: Expected deletion of '%v' subs; Deleted '%v'", i, test.expectedNumSubsDeleted, numSubsDeleted)
+			}
+		} else if err == nil {
+			t.Errorf("Test %v Failed: Expected an error.", i)
+		}
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////
+//
+// Group and Partition  Management
+//
+////////////////////////////////////////////////////////////////////////////
+
+//
+// Mock entries and arguments to share amongst tests
+//
+
+var uuid1 = uuid.New().String()
+var uuid2 = uuid.New().String()
+var uuid3 = uuid.New().String()
+var uuid4 = uuid.New().String()
+var uuid5 = uuid.New().String()
+var uuid6 = uuid.New().String()
+
+var tags1 = []string{}
+var tags2 = []string{"tag1", "otag1"}
+var tags3 = []string{"otag1"}
+var tags4 = []string{"tag1"}
+var tags5 = []string{"otag1", "pp1"}
+var tags6 = []string{"tag1", "pp1"}
+
+var dgrp1 = &sm.Group{
+	Label:          "grp1",
+	Description:    "desc1",
+	Tags:           tags1,
+	ExclusiveGroup: "",
+	Members: sm.Members{
+		IDs: []string{"x0c0s0b0n0", "x0c0s0b0n1"},
+	},
+}
+
+var dgrp1c1 = []driver.Value{"x0c0s0b0n0"}
+var dgrp1c2 = []driver.Value{"x0c0s0b0n1"}
+
+var dval1c1 = []driver.Value{"x0c0s0b0n0", uuid1, "grp1"}
+var dval1c2 = []driver.Value{"x0c0s0b0n1", uuid1, "grp1"}
+
+// Member list filtered on dgrp5p
+var dgrp1f = &sm.Group{
+	Label:          "grp1",
+	Description:    "desc1",
+	Tags:           tags1,
+	ExclusiveGroup: "",
+	Members: sm.Members{
+		IDs: []string{"x0c0s0b0n1"},
+	},
+}
+
+var dgrp1fc1 = []driver.Value{"x0c0s0b0n1"}
+
+var dval1fc1 = []driver.Value{"x0c0s0b0n1", uuid1, "grp1"}
+
+var dgrp2 = &sm.Group{
+	Label:          "grp2",
+	Description:    "desc2",
+	Tags:           tags2,
+	ExclusiveGroup: "",
+	Members: sm.Members{
+		IDs: []string{"x0c0s0b0n0", "x0c0s0b1n0"},
+	},
+}
+
+var dgrp2c1 = []driver.Value{"x0c0s0b0n0"}
+var dgrp2c2 = []driver.Value{"x0c0s0b1n0"}
+
+var dval2c1 = []driver.Value{"x0c0s0b0n0", uuid2, "grp2"}
+var dval2c2 = []driver.Value{"x0c0s0b1n0", uuid2, "grp2"}
+
+var dgrp3x = &sm.Group{
+	Label:          "grp3x",
+	Description:    "desc3",
+	Tags:           tags3,
+	ExclusiveGroup: "exgrp1",
+	Members: sm.Members{
+		IDs: []string{"x0c0s0b0n1"},
+	},
+}
+
+var dgrp3c1 = []driver.Value{"x0c0s0b0n1"}
+
+var dval3c1 = []driver.Value{"x0c0s0b0n1", uuid3, "%exgrp1%"}
+
+var dgrp4x = &sm.Group{
+	Label:          "grp4x",
+	Description:    "desc4",
+	Tags:           tags4,
+	ExclusiveGroup: "exgrp1",
+	Members: sm.Members{
+		IDs: []string{"x0c0s0b1n0"},
+	},
+}
+
+var dgrp4c1 = []driver.Value{"x0c0s0b1n0"}
+
+var dval4c1 = []driver.Value{"x0c0s0b1n0", uuid4, "%exgrp1%"}
+
+var dgrp5p = &sm.Partition{
+	Name:        "p1",
+	Description: "desc5",
+	Tags:        tags5,
+	Members: sm.Members{
+		IDs: []string{"x0c0s0b0n0"},
+	},
+}
+
+var dgrp5c1 = []driver.Value{"x0c0s0b0n0"}
+
+var dval5c1 = []driver.Value{"x0c0s0b0n0", uuid5, partGroupNamespace}
+
+var dgrp6p = &sm.Partition{
+	Name:        "p1.4",
+	Description: "desc6",
+	Tags:        tags6,
+	Members: sm.Members{
+		IDs: []string{"x0c0s0b0n0", "x0c0s0b1n0"},
+	},
+}
+
+var dgrp6c1 = []driver.Value{"x0c0s0b0n0"}
+var dgrp6c2 = []driver.Value{"x0c0s0b1n0"}
+
+var dval6c1 = []driver.Value{"x0c0s0b0n0", uuid6, partGroupNamespace}
+var dval6c2 = []driver.Value{"x0c0s0b1n0", uuid6, partGroupNamespace}
+
+//
+// Helper functions
+//
+
+// Define UUID dummy type and implement sqlmocj Match interface.
+type AnyUUID struct{}
+
+func (a AnyUUID) Match(v driver.Value) bool {
+	_, ok := v.(string)
+	return ok
+}
+
+//
+// API Tests
+//
+
+func TestPgGetGroup(t *testing.T) {
+	columns := compGroupsColsSMGroup // "id", "name", "description", "tags", "exclusive_group_identifier", "query", "groups", "version"
+	columns2 := compGroupsColsSMPart
+
+	dval1 := []driver.Value{uuid1, dgrp1.Label, dgrp1.Description, pq.Array(&dgrp1.Tags), dgrp1.ExclusiveGroup, nil, nil, 1}
+	dval2 := []driver.Value{uuid2, dgrp2.Label, dgrp2.Description, pq.Array(&dgrp2.Tags), dgrp2.ExclusiveGroup, nil, nil, 1}
+	dval3 := []driver.Value{uuid3, dgrp3x.Label, dgrp3x.Description, pq.Array(&dgrp3x.Tags), dgrp3x.ExclusiveGroup, nil, nil, 1}
+	dval4 := []driver.Value{uuid4, dgrp4x.Label, dgrp4x.Description, pq.Array(&dgrp4x.Tags), dgrp4x.ExclusiveGroup, nil, nil, 1}
+	dval5 := []driver.Value{uuid5, dgrp5p.Name, dgrp5p.Description, pq.Array(&dgrp5p.Tags), 1}
+	//dval6 := []driver.Value{uuid6, dgrp6p.Name, dgrp5p.Description, dgrp6p.Tags}
+
+	memberCols := []string{"component_id"}
+
+	sqq := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+
+	dgrp1Query, _, _ := sqq.Select(compGroupsColsSMGroup...).
+		From(compGroupsTable).
+		Where("name = ?", strings.ToLower(dgrp1.Label)).
+		Where("namespace = ?", groupNamespace).ToSql()
+
+	dgrp1MQuery, _, _ := sqq.Select(compGroupMembersColsUser...).
+		From(compGroupMembersTable).
+		Where("group_id = ?", uuid1).ToSql()
+
+	dgrp2Query, _, _ := sqq.Select(compGroupsColsSMGroup...).
+		From(compGroupsTable).
+		Where("name = ?", strings.ToLower(dgrp2.Label)).
+		Where("namespace = ?", groupNamespace).ToSql()
+
+	dgrp2MQuery, _, _ := sqq.Select(compGroupMembersColsUser...).
+		From(compGroupMembersTable).
+		Where("group_id = ?", uuid2).ToSql()
+
+	dgrp3Query, _, _ := sqq.Select(compGroupsColsSMGroup...).
+		From(compGroupsTable).
+		Where("name = ?", strings.ToLower(dgrp3x.Label)).
+		Where("namespace = ?", groupNamespace).ToSql()
+
+	dgrp3MQuery, _, _ := sqq.Select(compGroupMembersColsUser...).
+		From(compGroupMembersTable).
+		Where("group_id = ?", uuid3).ToSql()
+
+	dgrp4Query, _, _ := sqq.Select(compGroupsColsSMGroup...).
+		From(compGroupsTable).
+		Where("name = ?", strings.ToLower(dgrp4x.Label)).
+		Where("namespace = ?", groupNamespace).ToSql()
+
+	dgrp4MQuery, _, _ := sqq.Select(compGroupMembersColsUser...).
+		From(compGroupMembersTable).
+		Where("group_id = ?", uuid4).ToSql()
+
+	dgrp1Query2, _, _ := sqq.Select(compGroupsColsSMPart...).
+		From(compGroupsTable).
+		Where("name = ?", strings.ToLower(dgrp5p.Name)).
+		Where("namespace = ?", partNamespace).ToSql()
+
+	dgrp1MQuery2, _, _ := sqq.Select("a.component_id").
+		From(compGroupMembersTable+" a").
+		Join(compGroupMembersTable+" b ON a.component_id = b.component_id").
+		Where("a.group_id = ?", uuid1).
+		Where("b.group_id IN (?,?)", uuid1, uuid1, uuid5).
+		GroupBy("a.component_id").
+		Having("COUNT(*) = 2").ToSql()
+
+	tests := []struct {
+		label                 string
+		filt_part             string
+		dbColumns             []string
+		dbRows                [][]driver.Value
+		dbQueryError          error
+		expectedQueryPrepare  string
+		expectedQueryArgs     []driver.Value
+		dbColumns2            []string
+		dbRows2               [][]driver.Value
+		dbQuery2Error         error
+		expectedQuery2Prepare string
+		expectedQuery2Args    []driver.Value
+		dbMColumns            []string
+		dbMRows               [][]driver.Value
+		dbMQueryError         error
+		expectedMQueryPrepare string
+		expectedMQueryArgs    []driver.Value
+		expGroup              *sm.Group
+		expError              error
+	}{{
+		dgrp1.Label, // label
+		"",          // filt_part
+		columns,
+		[][]driver.Value{dval1},
+		nil,
+		regexp.QuoteMeta(dgrp1Query),
+		[]driver.Value{dgrp1.Label, groupNamespace},
+		columns2,
+		[][]driver.Value{},
+		nil,
+		regexp.QuoteMeta(""),
+		[]driver.Value{},
+		memberCols,
+		[][]driver.Value{
+			dgrp1c1,
+			dgrp1c2,
+		},
+		nil,
+		regexp.QuoteMeta(dgrp1MQuery),
+		[]driver.Value{uuid1},
+		dgrp1,
+		nil,
+	}, {
+		dgrp2.Label, // label
+		"",          // filt_part
+		columns,
+		[][]driver.Value{dval2},
+		nil,
+		regexp.QuoteMeta(dgrp2Query),
+		[]driver.Value{dgrp2.Label, groupNamespace},
+		columns2,
+		[][]driver.Value{},
+		nil,
+		regexp.QuoteMeta(""),
+		[]driver.Value{},
+		memberCols,
+		[][]driver.Value{
+			dgrp2c1,
+			dgrp2c2,
+		},
+		nil,
+		regexp.QuoteMeta(dgrp2MQuery),
+		[]driver.Value{uuid2},
+		dgrp2,
+		nil,
+	}, {
+		dgrp3x.Label, // label
+		"",           // filt_part
+		columns,
+		[][]driver.Value{dval3},
+		nil,
+		regexp.QuoteMeta(dgrp3Query),
+		[]driver.Value{dgrp3x.Label, groupNamespace},
+		columns2,
+		[][]driver.Value{},
+		nil,
+		regexp.QuoteMeta(""),
+		[]driver.Value{},
+		memberCols,
+		[][]driver.Value{
+			dgrp3c1,
+		},
+		nil,
+		regexp.QuoteMeta(dgrp3MQuery),
+		[]driver.Value{uuid3},
+		dgrp3x,
+		nil,
+	}, {
+		dgrp4x.Label, // label
+		"",           // filt_part
+		columns,
+		[][]driver.Value{dval4},
+		nil,
+		regexp.QuoteMeta(dgrp4Query),
+		[]driver.Value{dgrp4x.Label, groupNamespace},
+		columns2,
+		[][]driver.Value{},
+		nil,
+		regexp.QuoteMeta(""),
+		[]driver.Value{},
+		memberCols,
+		[][]driver.Value{
+			dgrp4c1,
+		},
+		nil,
+		regexp.QuoteMeta(dgrp4MQuery),
+		[]driver.Value{uuid4},
+		dgrp4x,
+		nil,
+	}, {
+		dgrp1.Label, // label
+		dgrp5p.Name, // filt_part
+		columns,
+		[][]driver.Value{dval1},
+		nil,
+		regexp.QuoteMeta(dgrp1Query),
+		[]driver.Value{dgrp1.Label, groupNamespace},
+		columns2,
+		[][]driver.Value{dval5},
+		nil,
+		regexp.QuoteMeta(dgrp1Query2),
+		[]driver.Value{dgrp5p.Name, partNamespace},
+		memberCols,
+		[][]driver.Value{
+			dgrp1fc1,
+		},
+		nil,
+		regexp.QuoteMeta(dgrp1MQuery2),
+		[]driver.Value{uuid1, uuid1, uuid5},
+		dgrp1f,
+		nil,
+	}, {
+		dgrp1.Label, // label
+		dgrp5p.Name, // filt_part
+		columns,
+		[][]driver.Value{dval1},
+		nil,
+		regexp.QuoteMeta(dgrp1Query),
+		[]driver.Value{dgrp1.Label, groupNamespace},
+		columns2,
+		[][]driver.Value{},
+		sql.ErrNoRows, // Simulates unexpected error
+		regexp.QuoteMeta(dgrp1Query2),
+		[]driver.Value{dgrp5p.Name, partNamespace},
+		memberCols,
+		[][]driver.Value{},
+		nil,
+		regexp.QuoteMeta(""),
+		[]driver.Value{},
+		nil,
+		sql.ErrNoRows,
+	}, {
+		dgrp1.Label, // label
+		dgrp5p.Name, // filt_part
+		columns,
+		[][]driver.Value{dval1},
+		nil,
+		regexp.QuoteMeta(dgrp1Query),
+		[]driver.Value{dgrp1.Label, groupNamespace},
+		columns2,
+		[][]driver.Value{},
+		nil,
+		regexp.QuoteMeta(dgrp1Query2),
+		[]driver.Value{dgrp5p.Name, partNamespace},
+		memberCols,
+		[][]driver.Value{},
+		nil,
+		regexp.QuoteMeta(dgrp1MQuery2),
+		[]driver.Value{uuid1, uuid1, uuid1},
+		nil,
+		ErrHMSDSNoPartition,
 	}}
 
 	for i, test := range tests {
 		ResetMockDB()
+		// before we actually execute our api function, we need to expect required DB actions
+		rows := sqlmock.NewRows(test.dbColumns)
+		for _, row := range test.dbRows {
+			rows.AddRow(row...)
+		}
+		rows2 := sqlmock.NewRows(test.dbColumns2)
+		for _, row := range test.dbRows2 {
+			rows2.AddRow(row...)
+		}
+		mrows := sqlmock.NewRows(test.dbMColumns)
+		for _, mrow := range test.dbMRows {
+			mrows.AddRow(mrow...)
+		}
+
 		mockPG.ExpectBegin()
-		if test.expectedError != nil {
-			mockPG.ExpectPrepare(test.expectedPrepare).ExpectExec().WillReturnError(test.expectedError)
+		if test.expectedQueryPrepare == "" && test.dbQueryError != nil {
+			mockPG.ExpectRollback()
+		} else if test.dbQueryError != nil {
+			mockPG.ExpectPrepare(test.expectedQueryPrepare).ExpectQuery().WillReturnError(test.dbQueryError)
+			mockPG.ExpectRollback()
+		} else if test.dbQuery2Error != nil {
+			mockPG.ExpectPrepare(test.expectedQueryPrepare).ExpectQuery().WithArgs(test.expectedQueryArgs...).WillReturnRows(rows)
+			mockPG.ExpectPrepare(test.expectedQuery2Prepare).ExpectQuery().WithArgs(test.expectedQuery2Args...).WillReturnError(test.dbQuery2Error)
+			mockPG.ExpectRollback()
+		} else if test.dbMQueryError != nil || test.expError != nil { // expError caues MQuery to be skipped.
+			mockPG.ExpectPrepare(test.expectedQueryPrepare).ExpectQuery().WithArgs(test.expectedQueryArgs...).WillReturnRows(rows)
+			if test.filt_part != "" && len(test.dbRows) > 0 {
+				// We skip this if no rows were returned by first query and GetGroup returns an error
+				mockPG.ExpectPrepare(test.expectedQuery2Prepare).ExpectQuery().WithArgs(test.expectedQuery2Args...).WillReturnRows(rows2)
+			}
+			if test.dbMQueryError != nil {
+				mockPG.ExpectPrepare(test.expectedMQueryPrepare).ExpectExec().WillReturnError(test.dbMQueryError)
+			}
 			mockPG.ExpectRollback()
 		} else {
-			mockPG.ExpectPrepare(test.expectedPrepare).ExpectExec().WithArgs(test.expectedArgs...).WillReturnResult(sqlmock.NewResult(0, 1))
+			mockPG.ExpectPrepare(test.expectedQueryPrepare).ExpectQuery().WithArgs(test.expectedQueryArgs...).WillReturnRows(rows)
+			if test.filt_part != "" {
+				mockPG.ExpectPrepare(test.expectedQuery2Prepare).ExpectQuery().WithArgs(test.expectedQuery2Args...).WillReturnRows(rows2)
+				if sm.IsTopPartition(test.filt_part) {
+					expectSubPartIDs(test.filt_part)
+				}
+			}
+			mockPG.ExpectPrepare(test.expectedMQueryPrepare).ExpectQuery().WithArgs(test.expectedMQueryArgs...).WillReturnRows(mrows)
 			mockPG.ExpectCommit()
 		}
 
-		_, err := dPG.DeleteCompEthInterfaceByID(test.id)
+		g, err := dPG.GetGroup(test.label, test.filt_part)
+		// ensure all expectations have been met
 		if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
 			t.Errorf("Test %v Failed: Sql expectations were not met: %s", i, mock_err)
 		}
-		if test.expectedError == nil && err != nil {
-			t.Errorf("Test %v Failed: Unexpected error received: %s", i, err)
-		} else if test.expectedError != nil && err == nil {
-			t.Errorf("Test %v Failed: Expected an error (%s).", i, test.expectedError)
+		//if test.dbQueryError == nil && test.dbUpdateError == nil {
+		if test.dbQueryError == nil && test.dbQuery2Error == nil &&
+			test.dbMQueryError == nil && test.expError == nil {
+
+			if err != nil {
+				t.Errorf("Test %v Failed: Unexpected error received: %s", i, err)
+			}
+			if g == nil {
+				t.Errorf("Test %v Failed: Expected non-nil group", i)
+			} else {
+				if g.Label != test.expGroup.Label {
+					t.Errorf("Test %v Failed: Expected label %s (got %s)",
+						i, g.Label, test.expGroup.Label)
+				}
+				if g.Description != test.expGroup.Description {
+					t.Errorf("Test %v Failed: Expected description %s (got %s)",
+						i, g.Description, test.expGroup.Description)
+				}
+				if g.ExclusiveGroup != test.expGroup.ExclusiveGroup {
+					t.Errorf("Test %v Failed: Expected excl grp %s (got %s)",
+						i, g.ExclusiveGroup, test.expGroup.ExclusiveGroup)
+				}
+				if !reflect.DeepEqual(g.Tags, test.expGroup.Tags) {
+					t.Errorf("Test %v Failed: Expected Tags %s (got %s)",
+						i, g.Tags, test.expGroup.Tags)
+				}
+				if !reflect.DeepEqual(g.Members.IDs, test.expGroup.Members.IDs) {
+					t.Errorf("Test %v Failed: Expected Members %s (got %s)",
+						i, g.Members.IDs, test.expGroup.Members.IDs)
+				}
+			}
+		} else if err != test.expError {
+			t.Errorf("Test %v Failed: Expected error %v.", i, err)
 		}
 	}
 }
 
-func TestDeleteCompEthInterfacesAll(t *testing.T) {
+func TestInsertPgGroup(t *testing.T) {
+	dval1 := []driver.Value{AnyUUID{}, dgrp1.Label, dgrp1.Description, pq.Array(&dgrp1.Tags), groupType, groupNamespace, dgrp1.ExclusiveGroup}
+	dval2 := []driver.Value{AnyUUID{}, dgrp2.Label, dgrp2.Description, pq.Array(&dgrp2.Tags), groupType, groupNamespace, dgrp2.ExclusiveGroup}
+	dval3 := []driver.Value{AnyUUID{}, dgrp3x.Label, dgrp3x.Description, pq.Array(&dgrp3x.Tags), exclGroupType, groupNamespace, dgrp3x.ExclusiveGroup}
+	dval4 := []driver.Value{AnyUUID{}, dgrp4x.Label, dgrp4x.Description, pq.Array(&dgrp4x.Tags), exclGroupType, groupNamespace, dgrp4x.ExclusiveGroup}
 
 	sqq := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
 
-	delete1, _, _ := sqq.Delete(compEthTable).ToSql()
+	// Note we don't use the args here.  We can't in any case because
+	// we need driver.Value because it supports AnyUUID{}.  Can't predict
+	// the value used by the function because it is generated for each new
+	// group.
+	dgrp1UpdateGrp, _, _ := sqq.Insert(compGroupsTable).
+		Columns(compGroupsColsAll7...).
+		Values(sq.Expr("?", uuid1), dgrp1.Label, dgrp1.Description,
+			pq.Array(&dgrp1.Tags), groupType, groupNamespace,
+			dgrp1.ExclusiveGroup).ToSql()
 
-	tests := []struct {
-		expectedPrepare string
-		expectedError   error
-	}{{
-		expectedPrepare: regexp.QuoteMeta(delete1),
-		expectedError:   nil,
-	}, {
-		expectedPrepare: regexp.QuoteMeta(delete1),
-		expectedError:   sql.ErrNoRows,
-	}}
+	dgrp1Update, _, _ := sqq.Insert(compGroupMembersTable).
+		Columns(compGroupMembersColsNoTS...).
+		Values(dgrp1.Members.IDs[0], sq.Expr("?", uuid1),
+			dgrp1.Label).
+		Values(dgrp1.Members.IDs[1], sq.Expr("?", uuid1),
+			dgrp1.Label).ToSql()
 
-	for i, test := range tests {
-		ResetMockDB()
-		mockPG.ExpectBegin()
-		if test.expectedError != nil {
-			mockPG.ExpectPrepare(test.expectedPrepare).ExpectExec().WillReturnError(test.expectedError)
-			mockPG.ExpectRollback()
-		} else {
-			mockPG.ExpectPrepare(test.expectedPrepare).ExpectExec().WillReturnResult(sqlmock.NewResult(0, 1))
-			mockPG.ExpectCommit()
-		}
+	dgrp2UpdateGrp, _, _ := sqq.Insert(compGroupsTable).
+		Columns(compGroupsColsAll7...).
+		Values(sq.Expr("?", uuid2), dgrp2.Label, dgrp2.Description,
+			pq.Array(&dgrp2.Tags), groupType, groupNamespace,
+			dgrp2.ExclusiveGroup).ToSql()
 
-		_, err := dPG.DeleteCompEthInterfacesAll()
-		if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
-			t.Errorf("Test %v Failed: Sql expectations were not met: %s", i, mock_err)
-		}
-		if test.expectedError == nil && err != nil {
-			t.Errorf("Test %v Failed: Unexpected error received: %s", i, err)
-		} else if test.expectedError != nil && err == nil {
-			t.Errorf("Test %v Failed: Expected an error (%s).", i, test.expectedError)
-		}
-	}
-}
+	dgrp2Update, _, _ := sqq.Insert(compGroupMembersTable).
+		Columns(compGroupMembersColsNoTS...).
+		Values(dgrp2.Members.IDs[0], sq.Expr("?", uuid2),
+			dgrp2.Label).
+		Values(dgrp2.Members.IDs[1], sq.Expr("?", uuid2),
+			dgrp2.Label).ToSql()
 
-////////////////////////////////////////////////////////////////////////////
-//
-// State Change Notification (SCN) Subscriptions
-//
-////////////////////////////////////////////////////////////////////////////
+	dgrp3UpdateGrp, _, _ := sqq.Insert(compGroupsTable).
+		Columns(compGroupsColsAll7...).
+		Values(sq.Expr("?", uuid3), dgrp3x.Label, dgrp3x.Description,
+			pq.Array(&dgrp3x.Tags), exclGroupType, groupNamespace,
+			dgrp3x.ExclusiveGroup).ToSql()
+
+	dgrp3Update, _, _ := sqq.Insert(compGroupMembersTable).
+		Columns(compGroupMembersColsNoTS...).
+		Values(dgrp3x.Members.IDs[0], sq.Expr("?", uuid3),
+			"%"+dgrp3x.ExclusiveGroup+"%").ToSql()
+
+	dgrp4UpdateGrp, _, _ := sqq.Insert(compGroupsTable).
+		Columns(compGroupsColsAll7...).
+		Values(sq.Expr("?", uuid4), dgrp4x.Label, dgrp4x.Description,
+			pq.Array(&dgrp4x.Tags), exclGroupType, groupNamespace,
+			dgrp4x.ExclusiveGroup).ToSql()
+
+	dgrp4Update, _, _ := sqq.Insert(compGroupMembersTable).
+		Columns(compGroupMembersColsNoTS...).
+		Values(dgrp4x.Members.IDs[0], sq.Expr("?", uuid4),
+			"%"+dgrp4x.ExclusiveGroup+"%").ToSql()
 
-func TestGetSCNSubscriptionsAll(t *testing.T) {
 	tests := []struct {
-		dbColumns		[]string
-		dbRows			[][]driver.Value
-		dbError			error
-		expectedPrepare		string
-		expectedSCNSubs		*sm.SCNSubscriptionArray
+		group                    *sm.Group
+		dbUpdateGrpError         error
+		expectedUpdateGrpPrepare string
+		expectedUpdateGrpArgs    []driver.Value
+		dbUpdateError            error
+		expectedUpdatePrepare    string
+		expectedUpdateArgs       []driver.Value
+		expectedMembers          int64
+		expectedLabel            string
 	}{{
-		dbColumns:		[]string{"id", "subscription"},
-		dbRows:			[][]driver.Value{
-						[]driver.Value{2, `{"Subscriber":"hmfd@sms01","States":["On","Off"],"Url":"https://foo/bar"}`},
-		},
-		dbError:		nil,
-		expectedPrepare:	regexp.QuoteMeta(tGetSCNSubscriptionQueryAll),
-		expectedSCNSubs:	&sm.SCNSubscriptionArray{[]sm.SCNSubscription{
-			sm.SCNSubscription{
-				ID:		2,
-				Subscriber:	"hmfd@sms01",
-				States:		[]string{"On", "Off"},
-				Url:		"https://foo/bar",
-			},
-		}},
+		dgrp1,
+		nil,
+		regexp.QuoteMeta(dgrp1UpdateGrp),
+		dval1,
+		nil,
+		regexp.QuoteMeta(dgrp1Update),
+		[]driver.Value{dgrp1.Members.IDs[0], AnyUUID{}, dgrp1.Label,
+			dgrp1.Members.IDs[1], AnyUUID{}, dgrp1.Label},
+		int64(len(dgrp1.Members.IDs)),
+		strings.ToLower(dgrp1.Label), // label
 	}, {
-		dbColumns:		[]string{"id", "subscription"},
-		dbRows:			[][]driver.Value{
-						[]driver.Value{2, `{"Subscriber":"hmfd@sms01","States":["On","Off"],"Url":"https://foo/bar"}`},
-						[]driver.Value{3, `{"Subscriber":"hmfd@sms02","States":["Off","Ready"],"Url":"https://foo2/bar"}`},
-		},
-		dbError:		nil,
-		expectedPrepare:	regexp.QuoteMeta(tGetSCNSubscriptionQueryAll),
-		expectedSCNSubs:	&sm.SCNSubscriptionArray{[]sm.SCNSubscription{
-			sm.SCNSubscription{
-				ID:		2,
-				Subscriber:	"hmfd@sms01",
-				States:		[]string{"On", "Off"},
-				Url:		"https://foo/bar",
-			},
-			sm.SCNSubscription{
-				ID:		3,
-				Subscriber:	"hmfd@sms02",
-				States:		[]string{"Off", "Ready"},
-				Url:		"https://foo2/bar",
-			},
-		}},
+		dgrp2,
+		nil,
+		regexp.QuoteMeta(dgrp2UpdateGrp),
+		dval2,
+		nil,
+		regexp.QuoteMeta(dgrp2Update),
+		[]driver.Value{dgrp2.Members.IDs[0], AnyUUID{}, dgrp2.Label,
+			dgrp2.Members.IDs[1], AnyUUID{}, dgrp2.Label},
+		int64(len(dgrp2.Members.IDs)),
+		strings.ToLower(dgrp2.Label),
 	}, {
-		dbColumns:		[]string{"id", "subscription"},
-		dbRows:			[][]driver.Value{
-						[]driver.Value{1, `{"Subscriber":"cray-hmnfd-798784bd66-s69mg_4","Roles":["compute","service"],"States":["Empty","Populated","Off","On","Standby","Halt","Ready"],"Url":"http://cray-hmnfd/hmi/v1/scn"}`},
-						[]driver.Value{2, `{"Subscriber":"cray-hmnfd-798784bd66-rkvzn_4","Roles":["service"],"States":["Populated","Off","On","Standby","Halt","Ready"],"Url":"http://cray-hmnfd/hmi/v1/scn"}`},
-						[]driver.Value{3, `{"Subscriber":"cray-hmnfd-798784bd66-bp8rc_23","Roles":["compute"],"States":["Empty","Off","On","Standby","Halt","Ready"],"Url":"http://cray-hmnfd/hmi/v1/scn"}`},
-		},
-		dbError:		nil,
-		expectedPrepare:	regexp.QuoteMeta(tGetSCNSubscriptionQueryAll),
-		expectedSCNSubs:	&sm.SCNSubscriptionArray{[]sm.SCNSubscription{
-			sm.SCNSubscription{
-				ID:		1,
-				Subscriber:	"cray-hmnfd-798784bd66-s69mg_4",
-				Roles:		[]string{"compute", "service"},
-				States:		[]string{"Empty", "Populated", "Off", "On", "Standby", "Halt", "Ready"},
-				Url:		"http://cray-hmnfd/hmi/v1/scn",
-			},
-			sm.SCNSubscription{
-				ID:		2,
-				Subscriber:	"cray-hmnfd-798784bd66-rkvzn_4",
-				Roles:		[]string{"service"},
-				States:		[]string{"Populated", "Off", "On", "Standby", "Halt", "Ready"},
-				Url:		"http://cray-hmnfd/hmi/v1/scn",
-			},
-			sm.SCNSubscription{
-				ID:		3,
-				Subscriber:	"cray-hmnfd-798784bd66-bp8rc_23",
-				Roles:		[]string{"compute"},
-				States:		[]string{"Empty", "Off", "On", "Standby", "Halt", "Ready"},
-				Url:		"http://cray-hmnfd/hmi/v1/scn",
-			},
-		}},
+		dgrp3x,
+		nil,
+		regexp.QuoteMeta(dgrp3UpdateGrp),
+		dval3,
+		nil,
+		regexp.QuoteMeta(dgrp3Update),
+		[]driver.Value{dgrp3x.Members.IDs[0], AnyUUID{}, "%" + dgrp3x.ExclusiveGroup + "%"},
+		int64(len(dgrp3x.Members.IDs)),
+		strings.ToLower(dgrp3x.Label), // label
 	}, {
-		dbColumns:		[]string{"id", "subscription"},
-		dbRows:			[][]driver.Value{
-						[]driver.Value{"", `{}`},
-		},
-		dbError:		sql.ErrNoRows,
-		expectedPrepare:	regexp.QuoteMeta(tGetSCNSubscriptionQueryAll),
-		expectedSCNSubs:	&sm.SCNSubscriptionArray{[]sm.SCNSubscription{
-			sm.SCNSubscription{},
-		}},
+		dgrp4x,
+		nil,
+		regexp.QuoteMeta(dgrp4UpdateGrp),
+		dval4,
+		nil,
+		regexp.QuoteMeta(dgrp4Update),
+		[]driver.Value{dgrp4x.Members.IDs[0], AnyUUID{}, "%" + dgrp4x.ExclusiveGroup + "%"},
+		int64(len(dgrp4x.Members.IDs)),
+		strings.ToLower(dgrp4x.Label), // label
 	}}
 
 	for i, test := range tests {
 		ResetMockDB()
-		// before we actually execute our api function, we need to expect required DB actions
-		rows := sqlmock.NewRows(test.dbColumns)
-		for _, row := range test.dbRows {
-			rows.AddRow(row...)
-		}
-
 		mockPG.ExpectBegin()
-		if test.dbError != nil {
-			mockPG.ExpectPrepare(test.expectedPrepare).ExpectQuery().WillReturnError(test.dbError)
+		if test.expectedUpdateGrpPrepare == "" && test.dbUpdateGrpError != nil {
+			mockPG.ExpectRollback()
+		} else if test.dbUpdateGrpError != nil {
+			mockPG.ExpectPrepare(test.expectedUpdateGrpPrepare).ExpectQuery().WillReturnError(test.dbUpdateGrpError)
+			mockPG.ExpectRollback()
+		} else if test.dbUpdateError != nil {
+			mockPG.ExpectPrepare(test.expectedUpdateGrpPrepare).ExpectExec().WithArgs(test.expectedUpdateGrpArgs...).WillReturnResult(sqlmock.NewResult(0, 1))
+			mockPG.ExpectPrepare(test.expectedUpdatePrepare).ExpectExec().WillReturnError(test.dbUpdateError)
 			mockPG.ExpectRollback()
 		} else {
-			mockPG.ExpectPrepare(test.expectedPrepare).ExpectQuery().WillReturnRows(rows)
+			mockPG.ExpectPrepare(test.expectedUpdateGrpPrepare).ExpectExec().WithArgs(test.expectedUpdateGrpArgs...).WillReturnResult(sqlmock.NewResult(0, 1))
+			mockPG.ExpectPrepare(test.expectedUpdatePrepare).ExpectExec().WithArgs(test.expectedUpdateArgs...).WillReturnResult(sqlmock.NewResult(0, test.expectedMembers))
 			mockPG.ExpectCommit()
 		}
 
-		subs, err := dPG.GetSCNSubscriptionsAll()
+		label, err := dPG.InsertGroup(test.group, "")
 		// ensure all expectations have been met
 		if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
-			t.Errorf("Test %v Failed: Sql expectations were not met: %s", i, mock_err)
+			t.Errorf("Test %v Failed: Sql expectations were not met: %s",
+				i, mock_err)
 		}
-		if test.dbError == nil {
+		if test.dbUpdateGrpError == nil && test.dbUpdateError == nil {
 			if err != nil {
 				t.Errorf("Test %v Failed: Unexpected error received: %s", i, err)
-			} else if !reflect.DeepEqual(test.expectedSCNSubs, subs) {
-				t.Errorf("Test %v Failed: Expected subArray '%v'; Recieved subArray '%v'", i, test.expectedSCNSubs, subs)
+			}
+			if label == "" {
+				t.Errorf("Test %v Failed: Expected non-empty label", i)
+			} else {
+				if label != test.expectedLabel {
+					t.Errorf("Test %v Failed: Expected label %s (got %s)",
+						i, label, test.expectedLabel)
+				}
 			}
 		} else if err == nil {
 			t.Errorf("Test %v Failed: Expected an error.", i)
@@ -3712,56 +5876,137 @@ func TestGetSCNSubscriptionsAll(t *testing.T) {
 	}
 }
 
-func TestGetSCNSubscription(t *testing.T) {
+func TestPgUpdateGroup(t *testing.T) {
+	newDescription := "newDescription" // shouldn't match any existing desc
+
+	columns := compGroupsColsSMGroup // "id", "name", "description", "tags", "exclusive_group_identifier", "query", "groups", "version"
+	//
+	dval1 := []driver.Value{uuid1, dgrp1.Label, dgrp1.Description, pq.Array(&dgrp1.Tags), dgrp1.ExclusiveGroup, nil, nil, 1}
+	dval2 := []driver.Value{uuid2, dgrp2.Label, dgrp2.Description, pq.Array(&dgrp2.Tags), dgrp2.ExclusiveGroup, nil, nil, 1}
+	dval3 := []driver.Value{uuid3, dgrp3x.Label, dgrp3x.Description, pq.Array(&dgrp3x.Tags), dgrp3x.ExclusiveGroup, nil, nil, 1}
+	dval4 := []driver.Value{uuid4, dgrp4x.Label, dgrp4x.Description, pq.Array(&dgrp4x.Tags), dgrp4x.ExclusiveGroup, nil, nil, 1}
+
+	sqq := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+
+	dgrp1Query, _, _ := sqq.Select(compGroupsColsSMGroup...).
+		From(compGroupsTable).
+		Where("name = ?", strings.ToLower(dgrp1.Label)).
+		Where("namespace = ?", groupNamespace).ToSql()
+
+	dgrp1Update, _, _ := sqq.Update("").Table(compGroupsTable).
+		Where("id = ?", uuid1).
+		Set(compGroupDescCol, newDescription).
+		Set(compGroupTagsCol, pq.Array(&dgrp2.Tags)).ToSql()
+
+	dgrp2Query, _, _ := sqq.Select(compGroupsColsSMGroup...).
+		From(compGroupsTable).
+		Where("name = ?", strings.ToLower(dgrp2.Label)).
+		Where("namespace = ?", groupNamespace).ToSql()
+
+	//dgrp2Update, _, _ := sqq.Update("").Table(compGroupsTable).
+	//	Where("id = ?", uuid2).ToSql()
+
+	dgrp3Query, _, _ := sqq.Select(compGroupsColsSMGroup...).
+		From(compGroupsTable).
+		Where("name = ?", strings.ToLower(dgrp3x.Label)).
+		Where("namespace = ?", groupNamespace).ToSql()
+
+	//dgrp3Update, _, _ := sqq.Update("").Table(compGroupsTable).
+	//	Where("id = ?", uuid3).ToSql()
+
+	dgrp4Query, _, _ := sqq.Select(compGroupsColsSMGroup...).
+		From(compGroupsTable).
+		Where("name = ?", strings.ToLower(dgrp4x.Label)).
+		Where("namespace = ?", groupNamespace).ToSql()
+
+	dgrp4Update, _, _ := sqq.Update("").Table(compGroupsTable).
+		Where("id = ?", uuid4).
+		Set(compGroupTagsCol, pq.Array(&dgrp3x.Tags)).ToSql()
+
 	tests := []struct {
-		id			int64
-		dbColumns		[]string
-		dbRows			[][]driver.Value
-		dbError			error
-		expectedPrepare		string
-		expectedArgs		[]driver.Value
-		expectedSCNSub		*sm.SCNSubscription
+		label                 string
+		gp                    *sm.GroupPatch
+		dbColumns             []string
+		dbRows                [][]driver.Value
+		dbQueryError          error
+		expectedQueryPrepare  string
+		expectedQueryArgs     []driver.Value
+		dbUpdateError         error
+		expectedUpdatePrepare string
+		expectedUpdateArgs    []driver.Value
+		expectedError         error
 	}{{
-		id:			2,
-		dbColumns:		[]string{"id", "subscription"},
-		dbRows:			[][]driver.Value{
-						[]driver.Value{2, `{"Subscriber":"cray-hmnfd-69d99579c5-shpmk_3","States":["Empty","Populated","Off","On","Standby","Halt","Ready"],"Url":"http://cray-hmnfd/hmi/v1/scn"}`},
-		},
-		dbError:		nil,
-		expectedPrepare:	regexp.QuoteMeta(tGetSCNSubscriptionQueryId),
-		expectedArgs:		[]driver.Value{2},
-		expectedSCNSub:		&sm.SCNSubscription{
-			ID:		2,
-			Subscriber:	"cray-hmnfd-69d99579c5-shpmk_3",
-			States:		[]string{"Empty","Populated","Off","On","Standby","Halt","Ready"},
-			Url:		"http://cray-hmnfd/hmi/v1/scn",
-		},
+		dgrp1.Label, // label
+		&sm.GroupPatch{Description: &newDescription, Tags: &dgrp2.Tags},
+		columns,
+		[][]driver.Value{dval1},
+		nil,
+		regexp.QuoteMeta(dgrp1Query),
+		[]driver.Value{dgrp1.Label, groupNamespace},
+		nil,
+		regexp.QuoteMeta(dgrp1Update),
+		[]driver.Value{newDescription, pq.Array(&dgrp2.Tags), uuid1},
+		nil,
 	}, {
-		id:			3,
-		dbColumns:		[]string{"id", "subscription"},
-		dbRows:			[][]driver.Value{
-						[]driver.Value{3, `{"Subscriber":"cray-hmnfd-798784bd66-s69mg_4","Roles":["compute","service"],"States":["Empty","Populated","Off","On","Standby","Halt","Ready"],"Url":"http://cray-hmnfd/hmi/v1/scn"}`},
-		},
-		dbError:		nil,
-		expectedPrepare:	regexp.QuoteMeta(tGetSCNSubscriptionQueryId),
-		expectedArgs:		[]driver.Value{3},
-		expectedSCNSub:		&sm.SCNSubscription{
-			ID:		3,
-			Subscriber:	"cray-hmnfd-798784bd66-s69mg_4",
-			Roles:		[]string{"compute","service"},
-			States:		[]string{"Empty","Populated","Off","On","Standby","Halt","Ready"},
-			Url:		"http://cray-hmnfd/hmi/v1/scn",
-		},
+		strings.ToUpper(dgrp2.Label), // label
+		&sm.GroupPatch{},
+		columns,
+		[][]driver.Value{dval2},
+		nil,
+		regexp.QuoteMeta(dgrp2Query),
+		[]driver.Value{dgrp2.Label, groupNamespace},
+		nil,
+		regexp.QuoteMeta(""),
+		[]driver.Value{},
+		nil,
 	}, {
-		id:			0,
-		dbColumns:		[]string{"id", "subscription"},
-		dbRows:			[][]driver.Value{
-						[]driver.Value{"", `{}`},
-		},
-		dbError:		sql.ErrNoRows,
-		expectedPrepare:	regexp.QuoteMeta(tGetSCNSubscriptionQueryId),
-		expectedArgs:		[]driver.Value{},
-		expectedSCNSub:		&sm.SCNSubscription{},
+		strings.ToUpper(dgrp3x.Label), // label
+		&sm.GroupPatch{Description: &dgrp3x.Description, Tags: &dgrp3x.Tags},
+		columns,
+		[][]driver.Value{dval3},
+		nil,
+		regexp.QuoteMeta(dgrp3Query),
+		[]driver.Value{dgrp3x.Label, groupNamespace},
+		nil,
+		regexp.QuoteMeta(""),
+		[]driver.Value{},
+		nil,
+	}, {
+		dgrp4x.Label, // label
+		&sm.GroupPatch{Tags: &dgrp3x.Tags},
+		columns,
+		[][]driver.Value{dval4},
+		nil,
+		regexp.QuoteMeta(dgrp4Query),
+		[]driver.Value{dgrp4x.Label, groupNamespace},
+		nil,
+		regexp.QuoteMeta(dgrp4Update),
+		[]driver.Value{pq.Array(&dgrp3x.Tags), uuid4},
+		nil,
+	}, {
+		dgrp4x.Label, // label
+		&sm.GroupPatch{Tags: &dgrp3x.Tags},
+		columns,
+		[][]driver.Value{dval4},
+		nil,
+		regexp.QuoteMeta(dgrp4Query),
+		[]driver.Value{dgrp4x.Label, groupNamespace},
+		sql.ErrNoRows,
+		regexp.QuoteMeta(dgrp4Update),
+		[]driver.Value{pq.Array(&dgrp3x.Tags), uuid4},
+		sql.ErrNoRows,
+	}, {
+		"badlabel", // label
+		&sm.GroupPatch{},
+		columns,
+		[][]driver.Value{},
+		nil,
+		regexp.QuoteMeta(dgrp4Query),
+		[]driver.Value{"badlabel", groupNamespace},
+		nil,
+		regexp.QuoteMeta(""),
+		[]driver.Value{},
+		ErrHMSDSNoGroup,
 	}}
 
 	for i, test := range tests {
@@ -3773,218 +6018,182 @@ func TestGetSCNSubscription(t *testing.T) {
 		}
 
 		mockPG.ExpectBegin()
-		if test.dbError != nil {
-			mockPG.ExpectPrepare(test.expectedPrepare).ExpectQuery().WillReturnError(test.dbError)
+		if test.expectedQueryPrepare == "" && test.dbQueryError != nil {
+			mockPG.ExpectRollback()
+		} else if test.dbQueryError != nil {
+			mockPG.ExpectPrepare(test.expectedQueryPrepare).ExpectQuery().WillReturnError(test.dbQueryError)
+			mockPG.ExpectRollback()
+		} else if test.dbUpdateError != nil || test.expectedError != nil {
+			mockPG.ExpectPrepare(test.expectedQueryPrepare).ExpectQuery().WithArgs(test.expectedQueryArgs...).WillReturnRows(rows)
+			if test.dbUpdateError != nil {
+				mockPG.ExpectPrepare(test.expectedUpdatePrepare).ExpectExec().WillReturnError(test.dbUpdateError)
+			}
 			mockPG.ExpectRollback()
 		} else {
-			mockPG.ExpectPrepare(test.expectedPrepare).ExpectQuery().WithArgs(test.expectedArgs...).WillReturnRows(rows)
+			mockPG.ExpectPrepare(test.expectedQueryPrepare).ExpectQuery().WithArgs(test.expectedQueryArgs...).WillReturnRows(rows)
+			if test.expectedUpdatePrepare != "" {
+				mockPG.ExpectPrepare(test.expectedUpdatePrepare).ExpectExec().WithArgs(test.expectedUpdateArgs...).WillReturnResult(sqlmock.NewResult(0, 1))
+			}
 			mockPG.ExpectCommit()
 		}
 
-		sub, err := dPG.GetSCNSubscription(test.id)
-		// ensure all expectations have been met
+		err := dPG.UpdateGroup(test.label, test.gp, "")
 		if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
-			t.Errorf("Test %v Failed: Sql expectations were not met: %s", i, mock_err)
+			t.Errorf("Test %v Failed: Sql expectations were not met: %s",
+				i, mock_err)
 		}
-		if test.dbError == nil {
+		if test.dbQueryError == nil && test.dbUpdateError == nil && test.expectedError == nil {
 			if err != nil {
 				t.Errorf("Test %v Failed: Unexpected error received: %s", i, err)
-			} else if !reflect.DeepEqual(test.expectedSCNSub, sub) {
-				t.Errorf("Test %v Failed: Expected subArray '%v'; Recieved subArray '%v'", i, test.expectedSCNSub, sub)
 			}
-		} else if err == nil {
-			t.Errorf("Test %v Failed: Expected an error.", i)
+		} else if err != test.expectedError {
+			t.Errorf("Test %v Failed: Expected an error (%s).",
+				i, test.expectedError)
 		}
 	}
 }
 
-func TestInsertSCNSubscription(t *testing.T) {
-	tests := []struct {
-		sub			sm.SCNPostSubscription
-		dbColumns		[]string
-		dbRows			[][]driver.Value
-		dbError			error
-		expectedPrepare		string
-		expectedArgs		[]driver.Value
-		expectedID		int64
-	}{{
-		sub:			sm.SCNPostSubscription{
-						Subscriber:	"hmfd@sms01",
-						States:		[]string{"On", "Off"},
-						Url:		"https://foo/bar",
-		},
-		dbColumns:		[]string{"id"},
-		dbRows:			[][]driver.Value{
-						[]driver.Value{4},
-		},
-		dbError:		nil,
-		expectedPrepare:	regexp.QuoteMeta(tInsertSCNSubscription),
-		expectedArgs:		[]driver.Value{"hmfd@sms01https://foo/bar",json.RawMessage(`{"Subscriber":"hmfd@sms01","States":["On","Off"],"Url":"https://foo/bar"}`)},
-		expectedID:		4,
-	}, {
-		sub:			sm.SCNPostSubscription{
-						Subscriber:	"cray-hmnfd-798784bd66-s69mg_4",
-						Roles:		[]string{"compute","service"},
-						States:		[]string{"Empty","Populated","Off","On","Standby","Halt","Ready"},
-						Url:		"http://cray-hmnfd/hmi/v1/scn",
-		},
-		dbColumns:		[]string{"id"},
-		dbRows:			[][]driver.Value{
-						[]driver.Value{5},
-		},
-		dbError:		nil,
-		expectedPrepare:	regexp.QuoteMeta(tInsertSCNSubscription),
-		expectedArgs:		[]driver.Value{"cray-hmnfd-798784bd66-s69mg_4http://cray-hmnfd/hmi/v1/scn",json.RawMessage(`{"Subscriber":"cray-hmnfd-798784bd66-s69mg_4","Roles":["compute","service"],"States":["Empty","Populated","Off","On","Standby","Halt","Ready"],"Url":"http://cray-hmnfd/hmi/v1/scn"}`)},
-		expectedID:		5,
-	}, {
-		sub:			sm.SCNPostSubscription{},
-		dbColumns:		[]string{"id"},
-		dbRows:			[][]driver.Value{
-						[]driver.Value{0},
-		},
-		dbError:		sql.ErrNoRows,
-		expectedPrepare:	regexp.QuoteMeta(tInsertSCNSubscription),
-		expectedArgs:		[]driver.Value{"",json.RawMessage(`{}`)},
-		expectedID:		0,
-	}}
+func TestPgAddGroupMember(t *testing.T) {
+	columns := compGroupsColsSMGroup // "id", "name", "description", "tags", "exclusive_group_identifier", "query", "groups", "version"
+	//
+	dval1 := []driver.Value{uuid1, dgrp1.Label, dgrp1.Description, pq.Array(&dgrp1.Tags), dgrp1.ExclusiveGroup, nil, nil, 1}
+	dval2 := []driver.Value{uuid2, dgrp2.Label, dgrp2.Description, pq.Array(&dgrp2.Tags), dgrp2.ExclusiveGroup, nil, nil, 1}
+	dval3 := []driver.Value{uuid3, dgrp3x.Label, dgrp3x.Description, pq.Array(&dgrp3x.Tags), dgrp3x.ExclusiveGroup, nil, nil, 1}
+	dval4 := []driver.Value{uuid4, dgrp4x.Label, dgrp4x.Description, pq.Array(&dgrp4x.Tags), dgrp4x.ExclusiveGroup, nil, nil, 1}
 
-	for i, test := range tests {
-		ResetMockDB()
-		// before we actually execute our api function, we need to expect required DB actions
-		rows := sqlmock.NewRows(test.dbColumns)
-		for _, row := range test.dbRows {
-			rows.AddRow(row...)
-		}
+	sqq := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
 
-		mockPG.ExpectBegin()
-		if test.dbError != nil {
-			mockPG.ExpectPrepare(test.expectedPrepare).ExpectExec().WillReturnError(test.dbError)
-			mockPG.ExpectRollback()
-		} else {
-			mockPG.ExpectPrepare(test.expectedPrepare).ExpectExec().WithArgs(test.expectedArgs...).WillReturnResult(sqlmock.NewResult(0, 1))
-			mockPG.ExpectPrepare(regexp.QuoteMeta(tInsertSCNSubscriptionQueryLastVal)).ExpectQuery().WillReturnRows(rows)
-			mockPG.ExpectCommit()
-		}
+	dgrp1Query, _, _ := sqq.Select(compGroupsColsSMGroup...).
+		From(compGroupsTable).
+		Where("name = ?", strings.ToLower(dgrp1.Label)).
+		Where("namespace = ?", groupNamespace).ToSql()
 
-		id, err := dPG.InsertSCNSubscription(test.sub)
-		// ensure all expectations have been met
-		if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
-			t.Errorf("Test %v Failed: Sql expectations were not met: %s", i, mock_err)
-		}
-		if test.dbError == nil {
-			if err != nil {
-				t.Errorf("Test %v Failed: Unexpected error received: %s", i, err)
-			} else if test.expectedID != id {
-				t.Errorf("Test %v Failed: Expected id '%v'; Recieved id '%v'", i, test.expectedID, id)
-			}
-		} else if err == nil {
-			t.Errorf("Test %v Failed: Expected an error.", i)
-		}
-	}
-}
+	dgrp1Update, _, _ := sqq.Insert(compGroupMembersTable).
+		Columns(compGroupMembersColsNoTS...).
+		Values("x0c0s22b0n0", sq.Expr("?", uuid1),
+			dgrp1.Label).ToSql()
 
-func TestUpdateSCNSubscription(t *testing.T) {
-	tests := []struct {
-		id			int64
-		sub			sm.SCNPostSubscription
-		dbError			error
-		expectedPrepare		string
-		expectedArgs		[]driver.Value
-		expectedDidUpdate	bool
-	}{{
-		id:			4,
-		sub:			sm.SCNPostSubscription{
-						Subscriber:	"hmfd@sms01",
-						States:		[]string{"On", "Off"},
-						Url:		"https://foo/bar",
-		},
-		dbError:		nil,
-		expectedPrepare:	regexp.QuoteMeta(tUpdateSCNSubscription),
-		expectedArgs:		[]driver.Value{"hmfd@sms01https://foo/bar",json.RawMessage(`{"Subscriber":"hmfd@sms01","States":["On","Off"],"Url":"https://foo/bar"}`),4},
-		expectedDidUpdate:	true,
-	}, {
-		id:			0,
-		sub:			sm.SCNPostSubscription{},
-		dbError:		sql.ErrNoRows,
-		expectedPrepare:	regexp.QuoteMeta(tUpdateSCNSubscription),
-		expectedArgs:		[]driver.Value{"",json.RawMessage(`{}`),0},
-		expectedDidUpdate:	false,
-	}}
+	dgrp2Query, _, _ := sqq.Select(compGroupsColsSMGroup...).
+		From(compGroupsTable).
+		Where("name = ?", strings.ToLower(dgrp2.Label)).
+		Where("namespace = ?", groupNamespace).ToSql()
 
-	for i, test := range tests {
-		ResetMockDB()
-		mockPG.ExpectBegin()
-		if test.dbError != nil {
-			mockPG.ExpectPrepare(test.expectedPrepare).ExpectExec().WillReturnError(test.dbError)
-			mockPG.ExpectRollback()
-		} else {
-			mockPG.ExpectPrepare(test.expectedPrepare).ExpectExec().WithArgs(test.expectedArgs...).WillReturnResult(sqlmock.NewResult(0, 1))
-			mockPG.ExpectCommit()
-		}
+	dgrp2Update, _, _ := sqq.Insert(compGroupMembersTable).
+		Columns(compGroupMembersColsNoTS...).
+		Values("x0c0s2b0n0", sq.Expr("?", uuid2),
+			dgrp2.Label).ToSql()
 
-		didUpdate, err := dPG.UpdateSCNSubscription(test.id, test.sub)
-		// ensure all expectations have been met
-		if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
-			t.Errorf("Test %v Failed: Sql expectations were not met: %s", i, mock_err)
-		}
-		if test.dbError == nil {
-			if err != nil {
-				t.Errorf("Test %v Failed: Unexpected error received: %s", i, err)
-			} else if test.expectedDidUpdate && !didUpdate {
-				t.Errorf("Test %v Failed: Expected update; no update occurred", i)
-			} else if !test.expectedDidUpdate && didUpdate {
-				t.Errorf("Test %v Failed: Did not expect update; update occurred", i)
-			}
-		} else if err == nil {
-			t.Errorf("Test %v Failed: Expected an error.", i)
-		}
-	}
-}
+	dgrp3Query, _, _ := sqq.Select(compGroupsColsSMGroup...).
+		From(compGroupsTable).
+		Where("name = ?", strings.ToLower(dgrp3x.Label)).
+		Where("namespace = ?", groupNamespace).ToSql()
+
+	dgrp3Update, _, _ := sqq.Insert(compGroupMembersTable).
+		Columns(compGroupMembersColsNoTS...).
+		Values("x0c0s22b1n1", sq.Expr("?", uuid3),
+			"%"+dgrp3x.ExclusiveGroup+"%").ToSql()
+
+	dgrp4Query, _, _ := sqq.Select(compGroupsColsSMGroup...).
+		From(compGroupsTable).
+		Where("name = ?", strings.ToLower(dgrp4x.Label)).
+		Where("namespace = ?", groupNamespace).ToSql()
+
+	dgrp4Update, _, _ := sqq.Insert(compGroupMembersTable).
+		Columns(compGroupMembersColsNoTS...).
+		Values("x0c0s4b0n0", sq.Expr("?", uuid4),
+			"%"+dgrp4x.ExclusiveGroup+"%").ToSql()
 
-func TestPatchSCNSubscription(t *testing.T) {
 	tests := []struct {
-		id			int64
-		op			string
-		sub			sm.SCNPatchSubscription
-		dbColumns		[]string
-		dbRows			[][]driver.Value
-		dbError			error
-		expectedQueryPrepare	string
-		expectedUpdatePrepare	string
-		expectedQueryArgs	[]driver.Value
-		expectedUpdateArgs	[]driver.Value
-		expectedDidPatch	bool
-	}{{
-		id:			4,
-		op:			"add",
-		sub:			sm.SCNPatchSubscription{
-						Op:		"add",
-						States:		[]string{"On", "Off"},
-		},
-		dbColumns:		[]string{"id", "subscription"},
-		dbRows:			[][]driver.Value{
-						[]driver.Value{4, `{"Subscriber":"cray-hmnfd-69d99579c5-shpmk_3","States":["Empty","Populated","Off","On","Standby","Halt","Ready"],"Url":"http://cray-hmnfd/hmi/v1/scn"}`},
-		},
-		dbError:		nil,
-		expectedQueryPrepare:	regexp.QuoteMeta(tGetSCNSubscriptionUpdate),
-		expectedUpdatePrepare:	regexp.QuoteMeta(tUpdateSCNSubscription),
-		expectedQueryArgs:	[]driver.Value{4},
-		expectedUpdateArgs:	[]driver.Value{"cray-hmnfd-69d99579c5-shpmk_3http://cray-hmnfd/hmi/v1/scn",json.RawMessage(`{"Subscriber":"cray-hmnfd-69d99579c5-shpmk_3","States":["Empty","Populated","Off","On","Standby","Halt","Ready"],"Url":"http://cray-hmnfd/hmi/v1/scn"}`),4},
-		expectedDidPatch:	true,
+		label                 string
+		new_id                string
+		dbColumns             []string
+		dbRows                [][]driver.Value
+		dbQueryError          error
+		expectedQueryPrepare  string
+		expectedQueryArgs     []driver.Value
+		dbUpdateError         error
+		expectedUpdatePrepare string
+		expectedUpdateArgs    []driver.Value
+		expectedId            string
+		expectedError         error
+	}{{
+		dgrp1.Label,    // label
+		"X00c0s22b0n0", // new_id
+		columns,
+		[][]driver.Value{dval1},
+		nil,
+		regexp.QuoteMeta(dgrp1Query),
+		[]driver.Value{dgrp1.Label, groupNamespace},
+		nil,
+		regexp.QuoteMeta(dgrp1Update),
+		[]driver.Value{"x0c0s22b0n0", uuid1, dgrp1.Label},
+		"x0c0s22b0n0",
+		nil,
 	}, {
-		id:			0,
-		op:			"remove",
-		sub:			sm.SCNPatchSubscription{},
-		dbColumns:		[]string{"", ""},
-		dbRows:			[][]driver.Value{
-						[]driver.Value{0, `{}`},
-		},
-		dbError:		sql.ErrNoRows,
-		expectedQueryPrepare:	regexp.QuoteMeta(tGetSCNSubscriptionUpdate),
-		expectedUpdatePrepare:	regexp.QuoteMeta(tUpdateSCNSubscription),
-		expectedQueryArgs:	[]driver.Value{},
-		expectedUpdateArgs:	[]driver.Value{"",json.RawMessage(`{}`),0},
-		expectedDidPatch:	false,
+		dgrp2.Label,   // label
+		"x0c0s2b0n00", // new_id
+		columns,
+		[][]driver.Value{dval2},
+		nil,
+		regexp.QuoteMeta(dgrp2Query),
+		[]driver.Value{dgrp2.Label, groupNamespace},
+		nil,
+		regexp.QuoteMeta(dgrp2Update),
+		[]driver.Value{"x0c0s2b0n0", uuid2, dgrp2.Label},
+		"x0c0s2b0n0",
+		nil,
+	}, {
+		dgrp3x.Label,   // label
+		"x0c0s022b1n1", // new_id
+		columns,
+		[][]driver.Value{dval3},
+		nil,
+		regexp.QuoteMeta(dgrp3Query),
+		[]driver.Value{dgrp3x.Label, groupNamespace},
+		nil,
+		regexp.QuoteMeta(dgrp3Update),
+		[]driver.Value{"x0c0s22b1n1", uuid3, "%" + dgrp3x.ExclusiveGroup + "%"},
+		"x0c0s22b1n1",
+		nil,
+	}, {
+		dgrp4x.Label, // label
+		"x0c0s4b0n0", // new_id
+		columns,
+		[][]driver.Value{dval4},
+		nil,
+		regexp.QuoteMeta(dgrp4Query),
+		[]driver.Value{dgrp4x.Label, groupNamespace},
+		nil,
+		regexp.QuoteMeta(dgrp4Update),
+		[]driver.Value{"x0c0s4b0n0", uuid4, "%" + dgrp4x.ExclusiveGroup + "%"},
+		"x0c0s4b0n0",
+		nil,
+	}, {
+		"badlabel",   // label
+		"x0c0s4b0n0", // new_id
+		columns,
+		[][]driver.Value{},
+		nil,
+		regexp.QuoteMeta(dgrp4Query),
+		[]driver.Value{"badlabel", groupNamespace},
+		nil,
+		regexp.QuoteMeta(""),
+		[]driver.Value{},
+		"",
+		ErrHMSDSNoGroup,
+	}, {
+		dgrp4x.Label, // label
+		"x0c0s4b0n0", // new_id
+		columns,
+		[][]driver.Value{dval4},
+		nil,
+		regexp.QuoteMeta(dgrp4Query),
+		[]driver.Value{dgrp4x.Label, groupNamespace},
+		ErrHMSDSDuplicateKey,
+		regexp.QuoteMeta(dgrp4Update),
+		[]driver.Value{"x0c0s4b0n0", uuid4, "%" + dgrp4x.ExclusiveGroup + "%"},
+		"",
+		ErrHMSDSDuplicateKey,
 	}}
 
 	for i, test := range tests {
@@ -3996,8 +6205,16 @@ func TestPatchSCNSubscription(t *testing.T) {
 		}
 
 		mockPG.ExpectBegin()
-		if test.dbError != nil {
-			mockPG.ExpectPrepare(test.expectedQueryPrepare).ExpectQuery().WillReturnError(test.dbError)
+		if test.expectedQueryPrepare == "" && test.dbQueryError != nil {
+			mockPG.ExpectRollback()
+		} else if test.dbQueryError != nil {
+			mockPG.ExpectPrepare(test.expectedQueryPrepare).ExpectQuery().WillReturnError(test.dbQueryError)
+			mockPG.ExpectRollback()
+		} else if test.dbUpdateError != nil || test.expectedError != nil {
+			mockPG.ExpectPrepare(test.expectedQueryPrepare).ExpectQuery().WithArgs(test.expectedQueryArgs...).WillReturnRows(rows)
+			if test.dbUpdateError != nil {
+				mockPG.ExpectPrepare(test.expectedUpdatePrepare).ExpectExec().WillReturnError(test.dbUpdateError)
+			}
 			mockPG.ExpectRollback()
 		} else {
 			mockPG.ExpectPrepare(test.expectedQueryPrepare).ExpectQuery().WithArgs(test.expectedQueryArgs...).WillReturnRows(rows)
@@ -4005,502 +6222,457 @@ func TestPatchSCNSubscription(t *testing.T) {
 			mockPG.ExpectCommit()
 		}
 
-		didPatch, err := dPG.PatchSCNSubscription(test.id, test.op, test.sub)
+		id, err := dPG.AddGroupMember(test.label, test.new_id, "")
 		// ensure all expectations have been met
 		if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
-			t.Errorf("Test %v Failed: Sql expectations were not met: %s", i, mock_err)
+			t.Errorf("Test %v Failed: Sql expectations were not met: %s",
+				i, mock_err)
 		}
-		if test.dbError == nil {
+		if test.dbQueryError == nil &&
+			test.dbUpdateError == nil && test.expectedError == nil {
 			if err != nil {
 				t.Errorf("Test %v Failed: Unexpected error received: %s", i, err)
-			} else if test.expectedDidPatch && !didPatch {
-				t.Errorf("Test %v Failed: Expected patch; no patch occurred", i)
-			} else if !test.expectedDidPatch && didPatch {
-				t.Errorf("Test %v Failed: Did not expect patch; patch occurred", i)
 			}
-		} else if err == nil {
-			t.Errorf("Test %v Failed: Expected an error.", i)
+			if id == "" {
+				t.Errorf("Test %v Failed: Expected non-empty id", i)
+			} else {
+				if id != test.expectedId {
+					t.Errorf("Test %v Failed: Expected label %s (got %s)",
+						i, id, test.expectedId)
+				}
+			}
+		} else if err == nil && err != test.expectedError {
+			t.Errorf("Test %v Failed: Expected an error (%s).",
+				i, test.expectedError)
 		}
 	}
 }
 
-func TestDeleteSCNSubscription(t *testing.T) {
+func TestPgDeleteGroupMember(t *testing.T) {
+	columns := compGroupsColsSMGroup // "id", "name", "description", "tags", "exclusive_group_identifier", "query", "groups", "version"
+	//
+	dval1 := []driver.Value{uuid1, dgrp1.Label, dgrp1.Description, pq.Array(&dgrp1.Tags), dgrp1.ExclusiveGroup, nil, nil, 1}
+	dval2 := []driver.Value{uuid2, dgrp2.Label, dgrp2.Description, pq.Array(&dgrp2.Tags), dgrp2.ExclusiveGroup, nil, nil, 1}
+	dval3 := []driver.Value{uuid3, dgrp3x.Label, dgrp3x.Description, pq.Array(&dgrp3x.Tags), dgrp3x.ExclusiveGroup, nil, nil, 1}
+	dval4 := []driver.Value{uuid4, dgrp4x.Label, dgrp4x.Description, pq.Array(&dgrp4x.Tags), dgrp4x.ExclusiveGroup, nil, nil, 1}
+
+	sqq := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+
+	dgrp1Query, _, _ := sqq.Select(compGroupsColsSMGroup...).
+		From(compGroupsTable).
+		Where("name = ?", strings.ToLower(dgrp1.Label)).
+		Where("namespace = ?", groupNamespace).ToSql()
+
+	dgrp1Update, _, _ := sqq.Delete(compGroupMembersTable).
+		Where("group_id = ?", uuid1).
+		Where("component_id = ?",
+			xnametypes.NormalizeHMSCompID(dgrp1.Members.IDs[0])).ToSql()
+
+	dgrp2Query, _, _ := sqq.Select(compGroupsColsSMGroup...).
+		From(compGroupsTable).
+		Where("name = ?", strings.ToLower(dgrp2.Label)).
+		Where("namespace = ?", groupNamespace).ToSql()
+
+	dgrp2Update, _, _ := sqq.Delete(compGroupMembersTable).
+		Where("group_id = ?", uuid2).
+		Where("component_id = ?",
+			xnametypes.NormalizeHMSCompID(dgrp2.Members.IDs[0])).ToSql()
+
+	dgrp3Query, _, _ := sqq.Select(compGroupsColsSMGroup...).
+		From(compGroupsTable).
+		Where("name = ?", strings.ToLower(dgrp3x.Label)).
+		Where("namespace = ?", groupNamespace).ToSql()
+
+	dgrp3Update, _, _ := sqq.Delete(compGroupMembersTable).
+		Where("group_id = ?", uuid3).
+		Where("component_id = ?",
+			xnametypes.NormalizeHMSCompID(dgrp3x.Members.IDs[0])).ToSql()
+
+	dgrp4Query, _, _ := sqq.Select(compGroupsColsSMGroup...).
+		From(compGroupsTable).
+		Where("name = ?", strings.ToLower(dgrp4x.Label)).
+		Where("namespace = ?", groupNamespace).ToSql()
+
+	dgrp4Update, _, _ := sqq.Delete(compGroupMembersTable).
+		Where("group_id = ?", uuid4).
+		Where("component_id = ?",
+			xnametypes.NormalizeHMSCompID(dgrp4x.Members.IDs[0])).ToSql()
+
 	tests := []struct {
-		id			int64
-		dbError			error
-		expectedPrepare		string
-		expectedArgs		[]driver.Value
-		expectedDidDelete	bool
+		label                 string
+		del_id                string
+		dbColumns             []string
+		dbRows                [][]driver.Value
+		dbQueryError          error
+		expectedQueryPrepare  string
+		expectedQueryArgs     []driver.Value
+		dbUpdateError         error
+		expectedUpdatePrepare string
+		expectedUpdateArgs    []driver.Value
+		expectedDeleted       bool
+		expectedId            string
+		expectedError         error
 	}{{
-		id:			5,
-		dbError:		nil,
-		expectedPrepare:	regexp.QuoteMeta(tDeleteSCNSubscription),
-		expectedArgs:		[]driver.Value{5},
-		expectedDidDelete:	true,
+		dgrp1.Label, // label
+		strings.ToUpper(dgrp1.Members.IDs[0]),
+		columns,
+		[][]driver.Value{dval1},
+		nil,
+		regexp.QuoteMeta(dgrp1Query),
+		[]driver.Value{dgrp1.Label, groupNamespace},
+		nil,
+		regexp.QuoteMeta(dgrp1Update),
+		[]driver.Value{uuid1, dgrp1.Members.IDs[0]},
+		true,
+		dgrp1.Members.IDs[0],
+		nil,
+	}, {
+		strings.ToUpper(dgrp2.Label), // label
+		dgrp2.Members.IDs[1],
+		columns,
+		[][]driver.Value{dval2},
+		nil,
+		regexp.QuoteMeta(dgrp2Query),
+		[]driver.Value{dgrp2.Label, groupNamespace},
+		nil,
+		regexp.QuoteMeta(dgrp2Update),
+		[]driver.Value{uuid2, dgrp2.Members.IDs[1]},
+		true,
+		dgrp2.Members.IDs[1],
+		nil,
+	}, {
+		strings.ToUpper(dgrp3x.Label), // label
+		strings.ToUpper(dgrp3x.Members.IDs[0]),
+		columns,
+		[][]driver.Value{dval3},
+		nil,
+		regexp.QuoteMeta(dgrp3Query),
+		[]driver.Value{dgrp3x.Label, groupNamespace},
+		nil,
+		regexp.QuoteMeta(dgrp3Update),
+		[]driver.Value{uuid3, dgrp3x.Members.IDs[0]},
+		true,
+		dgrp3x.Members.IDs[0],
+		nil,
+	}, {
+		dgrp4x.Label, // label
+		dgrp4x.Members.IDs[0],
+		columns,
+		[][]driver.Value{dval4},
+		nil,
+		regexp.QuoteMeta(dgrp4Query),
+		[]driver.Value{dgrp4x.Label, groupNamespace},
+		nil,
+		regexp.QuoteMeta(dgrp4Update),
+		[]driver.Value{uuid4, dgrp4x.Members.IDs[0]},
+		true,
+		dgrp4x.Members.IDs[0],
+		nil,
+	}, {
+		dgrp4x.Label, // label
+		dgrp1.Members.IDs[1],
+		columns,
+		[][]driver.Value{dval4},
+		nil,
+		regexp.QuoteMeta(dgrp4Query),
+		[]driver.Value{dgrp4x.Label, groupNamespace},
+		nil,
+		regexp.QuoteMeta(dgrp4Update),
+		[]driver.Value{uuid4, dgrp1.Members.IDs[1]},
+		false,
+		"",
+		nil,
 	}, {
-		id:			0,
-		dbError:		sql.ErrNoRows,
-		expectedPrepare:	regexp.QuoteMeta(tDeleteSCNSubscription),
-		expectedArgs:		[]driver.Value{},
-		expectedDidDelete:	false,
+		"badlabel", // label
+		dgrp4x.Members.IDs[0],
+		columns,
+		[][]driver.Value{},
+		nil,
+		regexp.QuoteMeta(dgrp4Query),
+		[]driver.Value{"badlabel", groupNamespace},
+		nil,
+		regexp.QuoteMeta(""),
+		[]driver.Value{uuid4, dgrp4x.Members.IDs[0]},
+		true,
+		dgrp4x.Members.IDs[0],
+		ErrHMSDSNoGroup,
 	}}
 
 	for i, test := range tests {
 		ResetMockDB()
-		mockPG.ExpectBegin()
-		if test.dbError != nil {
-			mockPG.ExpectPrepare(test.expectedPrepare).ExpectExec().WillReturnError(test.dbError)
-			mockPG.ExpectRollback()
-		} else {
-			mockPG.ExpectPrepare(test.expectedPrepare).ExpectExec().WithArgs(test.expectedArgs...).WillReturnResult(sqlmock.NewResult(0, 1))
-			mockPG.ExpectCommit()
-		}
-
-		didDelete, err := dPG.DeleteSCNSubscription(test.id)
-		// ensure all expectations have been met
-		if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
-			t.Errorf("Test %v Failed: Sql expectations were not met: %s", i, mock_err)
-		}
-		if test.dbError == nil {
-			if err != nil {
-				t.Errorf("Test %v Failed: Unexpected error received: %s", i, err)
-			} else if test.expectedDidDelete && !didDelete {
-				t.Errorf("Test %v Failed: Expected deletion; no deletion occurred", i)
-			} else if !test.expectedDidDelete && didDelete {
-				t.Errorf("Test %v Failed: Did not expect deletion; deletion occurred", i)
-			}
-		} else if err == nil {
-			t.Errorf("Test %v Failed: Expected an error.", i)
+		// before we actually execute our api function, we need to expect required DB actions
+		rows := sqlmock.NewRows(test.dbColumns)
+		for _, row := range test.dbRows {
+			rows.AddRow(row...)
 		}
-	}
-}
-
-func TestDeleteSCNSubscriptionsAll(t *testing.T) {
-	tests := []struct {
-		dbError			error
-		expectedPrepare		string
-		expectedNumSubsDeleted	int64
-	}{{
-		dbError:		nil,
-		expectedPrepare:	regexp.QuoteMeta(tDeleteSCNSubscriptionAll),
-		expectedNumSubsDeleted:	1,
-	}, {
-		dbError:		nil,
-		expectedPrepare:	regexp.QuoteMeta(tDeleteSCNSubscriptionAll),
-		expectedNumSubsDeleted:	5,
-	}, {
-		dbError:		sql.ErrNoRows,
-		expectedPrepare:	regexp.QuoteMeta(tDeleteSCNSubscriptionAll),
-		expectedNumSubsDeleted:	0,
-	}}
 
-	for i, test := range tests {
-                ResetMockDB()
 		mockPG.ExpectBegin()
-		if test.dbError != nil {
-			mockPG.ExpectPrepare(test.expectedPrepare).ExpectExec().WillReturnError(test.dbError)
+		if test.expectedQueryPrepare == "" && test.dbQueryError != nil {
+			mockPG.ExpectRollback()
+		} else if test.dbQueryError != nil {
+			mockPG.ExpectPrepare(test.expectedQueryPrepare).ExpectQuery().WillReturnError(test.dbQueryError)
+			mockPG.ExpectRollback()
+		} else if test.dbUpdateError != nil || test.expectedError != nil {
+			mockPG.ExpectPrepare(test.expectedQueryPrepare).ExpectQuery().WithArgs(test.expectedQueryArgs...).WillReturnRows(rows)
+			if test.dbUpdateError != nil {
+				mockPG.ExpectPrepare(test.expectedUpdatePrepare).ExpectExec().WillReturnError(test.dbUpdateError)
+			}
 			mockPG.ExpectRollback()
 		} else {
-			mockPG.ExpectPrepare(test.expectedPrepare).ExpectExec().WillReturnResult(sqlmock.NewResult(0, test.expectedNumSubsDeleted))
+			mockPG.ExpectPrepare(test.expectedQueryPrepare).ExpectQuery().WithArgs(test.expectedQueryArgs...).WillReturnRows(rows)
+			if test.expectedDeleted {
+				mockPG.ExpectPrepare(test.expectedUpdatePrepare).ExpectExec().WithArgs(test.expectedUpdateArgs...).WillReturnResult(sqlmock.NewResult(0, 1))
+			} else {
+				mockPG.ExpectPrepare(test.expectedUpdatePrepare).ExpectExec().WithArgs(test.expectedUpdateArgs...).WillReturnResult(sqlmock.NewResult(0, 0))
+			}
 			mockPG.ExpectCommit()
 		}
 
-		numSubsDeleted, err := dPG.DeleteSCNSubscriptionsAll()
-		// ensure all expectations have been met
+		didDelete, err := dPG.DeleteGroupMember(test.label, test.del_id, "")
 		if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
-			t.Errorf("Test %v Failed: Sql expectations were not met: %s", i, mock_err)
+			t.Errorf("Test %v Failed: Sql expectations were not met: %s",
+				i, mock_err)
 		}
-		if test.dbError == nil {
+		if test.dbQueryError == nil && test.dbUpdateError == nil && test.expectedError == nil {
 			if err != nil {
 				t.Errorf("Test %v Failed: Unexpected error received: %s", i, err)
-			} else if test.expectedNumSubsDeleted != numSubsDeleted {
-				t.Errorf("Test %v Failed: Expected deletion of '%v' subs; Deleted '%v'", i, test.expectedNumSubsDeleted, numSubsDeleted)
 			}
-		} else if err == nil {
-			t.Errorf("Test %v Failed: Expected an error.", i)
-		}
-	}
-}
-
-////////////////////////////////////////////////////////////////////////////
-//
-// Group and Partition  Management
-//
-////////////////////////////////////////////////////////////////////////////
-
-//
-// Mock entries and arguments to share amongst tests
-//
-
-var uuid1 = uuid.New().String()
-var uuid2 = uuid.New().String()
-var uuid3 = uuid.New().String()
-var uuid4 = uuid.New().String()
-var uuid5 = uuid.New().String()
-var uuid6 = uuid.New().String()
-
-var tags1 = []string{}
-var tags2 = []string{"tag1", "otag1"}
-var tags3 = []string{"otag1"}
-var tags4 = []string{"tag1"}
-var tags5 = []string{"otag1", "pp1"}
-var tags6 = []string{"tag1", "pp1"}
-
-var dgrp1 = &sm.Group{
-	Label:          "grp1",
-	Description:    "desc1",
-	Tags:           tags1,
-	ExclusiveGroup: "",
-	Members: sm.Members{
-		IDs: []string{"x0c0s0b0n0", "x0c0s0b0n1"},
-	},
-}
-
-var dgrp1c1 = []driver.Value{"x0c0s0b0n0"}
-var dgrp1c2 = []driver.Value{"x0c0s0b0n1"}
-
-var dval1c1 = []driver.Value{"x0c0s0b0n0", uuid1, "grp1"}
-var dval1c2 = []driver.Value{"x0c0s0b0n1", uuid1, "grp1"}
-
-// Member list filtered on dgrp5p
-var dgrp1f = &sm.Group{
-	Label:          "grp1",
-	Description:    "desc1",
-	Tags:           tags1,
-	ExclusiveGroup: "",
-	Members: sm.Members{
-		IDs: []string{"x0c0s0b0n1"},
-	},
-}
-
-var dgrp1fc1 = []driver.Value{"x0c0s0b0n1"}
-
-var dval1fc1 = []driver.Value{"x0c0s0b0n1", uuid1, "grp1"}
-
-var dgrp2 = &sm.Group{
-	Label:          "grp2",
-	Description:    "desc2",
-	Tags:           tags2,
-	ExclusiveGroup: "",
-	Members: sm.Members{
-		IDs: []string{"x0c0s0b0n0", "x0c0s0b1n0"},
-	},
-}
-
-var dgrp2c1 = []driver.Value{"x0c0s0b0n0"}
-var dgrp2c2 = []driver.Value{"x0c0s0b1n0"}
-
-var dval2c1 = []driver.Value{"x0c0s0b0n0", uuid2, "grp2"}
-var dval2c2 = []driver.Value{"x0c0s0b1n0", uuid2, "grp2"}
-
-var dgrp3x = &sm.Group{
-	Label:          "grp3x",
-	Description:    "desc3",
-	Tags:           tags3,
-	ExclusiveGroup: "exgrp1",
-	Members: sm.Members{
-		IDs: []string{"x0c0s0b0n1"},
-	},
-}
-
-var dgrp3c1 = []driver.Value{"x0c0s0b0n1"}
-
-var dval3c1 = []driver.Value{"x0c0s0b0n1", uuid3, "%exgrp1%"}
-
-var dgrp4x = &sm.Group{
-	Label:          "grp4x",
-	Description:    "desc4",
-	Tags:           tags4,
-	ExclusiveGroup: "exgrp1",
-	Members: sm.Members{
-		IDs: []string{"x0c0s0b1n0"},
-	},
-}
-
-var dgrp4c1 = []driver.Value{"x0c0s0b1n0"}
-
-var dval4c1 = []driver.Value{"x0c0s0b1n0", uuid4, "%exgrp1%"}
-
-var dgrp5p = &sm.Partition{
-	Name:        "p1",
-	Description: "desc5",
-	Tags:        tags5,
-	Members: sm.Members{
-		IDs: []string{"x0c0s0b0n0"},
-	},
-}
-
-var dgrp5c1 = []driver.Value{"x0c0s0b0n0"}
-
-var dval5c1 = []driver.Value{"x0c0s0b0n0", uuid5, partGroupNamespace}
-
-var dgrp6p = &sm.Partition{
-	Name:        "p1.4",
-	Description: "desc6",
-	Tags:        tags6,
-	Members: sm.Members{
-		IDs: []string{"x0c0s0b0n0", "x0c0s0b1n0"},
-	},
-}
-
-var dgrp6c1 = []driver.Value{"x0c0s0b0n0"}
-var dgrp6c2 = []driver.Value{"x0c0s0b1n0"}
-
-var dval6c1 = []driver.Value{"x0c0s0b0n0", uuid6, partGroupNamespace}
-var dval6c2 = []driver.Value{"x0c0s0b1n0", uuid6, partGroupNamespace}
-
-//
-// Helper functions
-//
-
-// Define UUID dummy type and implement sqlmocj Match interface.
-type AnyUUID struct{}
-
-func (a AnyUUID) Match(v driver.Value) bool {
-	_, ok := v.(string)
-	return ok
+			if didDelete != test.expectedDeleted {
+				t.Errorf("Test %v Failed: Expected %t",
+					i, test.expectedDeleted)
+			}
+		} else if err != test.expectedError {
+			t.Errorf("Test %v Failed: Expected an error (%s).",
+				i, test.expectedError)
+		}
+	}
 }
 
 //
-// API Tests
+// Dynamic groups
 //
 
-func TestPgGetGroup(t *testing.T) {
-	columns := compGroupsColsSMGroup // "id", "name", "description", "tags", "exclusive_group_identifier"
-	columns2 := compGroupsColsSMPart
-
-	dval1 := []driver.Value{uuid1, dgrp1.Label, dgrp1.Description, pq.Array(&dgrp1.Tags), dgrp1.ExclusiveGroup}
-	dval2 := []driver.Value{uuid2, dgrp2.Label, dgrp2.Description, pq.Array(&dgrp2.Tags), dgrp2.ExclusiveGroup}
-	dval3 := []driver.Value{uuid3, dgrp3x.Label, dgrp3x.Description, pq.Array(&dgrp3x.Tags), dgrp3x.ExclusiveGroup}
-	dval4 := []driver.Value{uuid4, dgrp4x.Label, dgrp4x.Description, pq.Array(&dgrp4x.Tags), dgrp4x.ExclusiveGroup}
-	dval5 := []driver.Value{uuid5, dgrp5p.Name, dgrp5p.Description, pq.Array(&dgrp5p.Tags)}
-	//dval6 := []driver.Value{uuid6, dgrp6p.Name, dgrp5p.Description, dgrp6p.Tags}
-
-	memberCols := []string{"component_id"}
+var dgrpDynQuery = []byte(`{"role":["Compute"],"prefix":["x3000"]}`)
+
+const tGetDynGroupIDs = "SELECT dyn.id AS id FROM components dyn " +
+	"WHERE dyn.role IN ($1) AND (dyn.id SIMILAR TO $2)"
+
+func TestPgGetComponentsFilterDynGroup(t *testing.T) {
+	f := &ComponentFilter{Group: []string{"grp1", "dyn1"}}
+	expectedQuery := tGetCompStateOnlyQuery + " WHERE (c.id IN ( " +
+		tGetDynGroupIDs + " ) OR c.id IN ( SELECT gm.component_id " +
+		"FROM component_group_members gm JOIN component_groups g " +
+		"ON g.id = gm.group_id WHERE g.name IN ($3) AND g.namespace = $4 ))"
+
+	ResetMockDB()
+	mockPG.ExpectBegin()
+	mockPG.ExpectPrepare(regexp.QuoteMeta(tResolveGroups)).ExpectQuery().
+		WithArgs(groupNamespace, "grp1", "dyn1").
+		WillReturnRows(sqlmock.NewRows(tResolveGroupsCols).
+			AddRow("grp1", "grp1", nil).
+			AddRow("dyn1", "dyn1", dgrpDynQuery))
+	mockPG.ExpectPrepare(regexp.QuoteMeta(expectedQuery)).ExpectQuery().
+		WithArgs("Compute", "x3000([[:alpha:]][[:alnum:]]*)?", "grp1", groupNamespace).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "type", "state", "flag"}).
+			AddRow("x3000c0s1b0n0", "Node", "Ready", "OK"))
+	mockPG.ExpectCommit()
+
+	comps, err := dPG.GetComponentsFilter(f, FLTR_STATEONLY)
+	if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
+		t.Errorf("Test Failed: Sql expectations were not met: %s", mock_err)
+	}
+	if err != nil {
+		t.Errorf("Test Failed: Unexpected error received: %s", err)
+	} else if len(comps) != 1 || comps[0].ID != "x3000c0s1b0n0" {
+		t.Errorf("Test Failed: Unexpected components: %v", comps)
+	}
+}
 
+func TestPgGetGroupDynamic(t *testing.T) {
 	sqq := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
-
-	dgrp1Query, _, _ := sqq.Select(compGroupsColsSMGroup...).
+	dynQuery, _, _ := sqq.Select(compGroupsColsSMGroup...).
 		From(compGroupsTable).
-		Where("name = ?", strings.ToLower(dgrp1.Label)).
+		Where("name = ?", "dyn1").
 		Where("namespace = ?", groupNamespace).ToSql()
+	membersQuery := "SELECT c.id AS id FROM components c WHERE (c.id IN ( " +
+		tGetDynGroupIDs + " ))"
+
+	ResetMockDB()
+	mockPG.ExpectBegin()
+	mockPG.ExpectPrepare(regexp.QuoteMeta(dynQuery)).ExpectQuery().
+		WithArgs("dyn1", groupNamespace).
+		WillReturnRows(sqlmock.NewRows(compGroupsColsSMGroup).
+			AddRow(uuid1, "dyn1", "computes", pq.Array([]string{}), "", dgrpDynQuery, nil, 1))
+	mockPG.ExpectPrepare(regexp.QuoteMeta(tResolveGroups)).ExpectQuery().
+		WithArgs(groupNamespace, "dyn1").
+		WillReturnRows(sqlmock.NewRows(tResolveGroupsCols).
+			AddRow("dyn1", "dyn1", dgrpDynQuery))
+	mockPG.ExpectPrepare(regexp.QuoteMeta(membersQuery)).ExpectQuery().
+		WithArgs("Compute", "x3000([[:alpha:]][[:alnum:]]*)?").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).
+			AddRow("x3000c0s1b0n0").AddRow("x3000c0s2b0n0"))
+	mockPG.ExpectCommit()
+
+	g, err := dPG.GetGroup("dyn1", "")
+	if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
+		t.Errorf("Test Failed: Sql expectations were not met: %s", mock_err)
+	}
+	if err != nil {
+		t.Fatalf("Test Failed: Unexpected error received: %s", err)
+	}
+	if g == nil || !g.IsDynamic() {
+		t.Fatalf("Test Failed: Expected dynamic group, got %v", g)
+	}
+	if !reflect.DeepEqual(g.Query.Role, []string{"Compute"}) ||
+		!reflect.DeepEqual(g.Query.Prefix, []string{"x3000"}) {
+		t.Errorf("Test Failed: Unexpected query %v", *g.Query)
+	}
+	expIDs := []string{"x3000c0s1b0n0", "x3000c0s2b0n0"}
+	if !reflect.DeepEqual(g.Members.IDs, expIDs) {
+		t.Errorf("Test Failed: Expected members %v, got %v", expIDs, g.Members.IDs)
+	}
+}
 
-	dgrp1MQuery, _, _ := sqq.Select(compGroupMembersColsUser...).
-		From(compGroupMembersTable).
-		Where("group_id = ?", uuid1).ToSql()
-
-	dgrp2Query, _, _ := sqq.Select(compGroupsColsSMGroup...).
+func TestPgAddGroupMemberDynamic(t *testing.T) {
+	sqq := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	dynQuery, _, _ := sqq.Select(compGroupsColsSMGroup...).
 		From(compGroupsTable).
-		Where("name = ?", strings.ToLower(dgrp2.Label)).
+		Where("name = ?", "dyn1").
 		Where("namespace = ?", groupNamespace).ToSql()
 
-	dgrp2MQuery, _, _ := sqq.Select(compGroupMembersColsUser...).
-		From(compGroupMembersTable).
-		Where("group_id = ?", uuid2).ToSql()
+	ResetMockDB()
+	mockPG.ExpectBegin()
+	mockPG.ExpectPrepare(regexp.QuoteMeta(dynQuery)).ExpectQuery().
+		WithArgs("dyn1", groupNamespace).
+		WillReturnRows(sqlmock.NewRows(compGroupsColsSMGroup).
+			AddRow(uuid1, "dyn1", "computes", pq.Array([]string{}), "", dgrpDynQuery, nil, 1))
+	mockPG.ExpectRollback()
+
+	_, err := dPG.AddGroupMember("dyn1", "x3000c0s1b0n0", "")
+	if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
+		t.Errorf("Test Failed: Sql expectations were not met: %s", mock_err)
+	}
+	if err != ErrHMSDSDynamicGroup {
+		t.Errorf("Test Failed: Expected error %v, got %v", ErrHMSDSDynamicGroup, err)
+	}
+}
 
-	dgrp3Query, _, _ := sqq.Select(compGroupsColsSMGroup...).
-		From(compGroupsTable).
-		Where("name = ?", strings.ToLower(dgrp3x.Label)).
-		Where("namespace = ?", groupNamespace).ToSql()
+func TestPgGetMembershipsDynGroup(t *testing.T) {
+	f := &ComponentFilter{ID: []string{"x3000c0s1b0n0", "x3000c0s2b0n0"}}
+
+	ResetMockDB()
+	mockPG.ExpectPrepare(regexp.QuoteMeta(tGetMembershipsBaseQuery +
+		tGetCompJoinGroupsQuery + " WHERE c.id IN ($1,$2)")).ExpectQuery().
+		WillReturnRows(sqlmock.NewRows(append(compColsIdOnly, compGroupPartCols...)).
+			AddRow("x3000c0s1b0n0", "grp1", "group").
+			AddRow("x3000c0s2b0n0", nil, nil))
+	mockPG.ExpectPrepare(regexp.QuoteMeta(tResolveGroups)).ExpectQuery().
+		WithArgs(groupNamespace).
+		WillReturnRows(sqlmock.NewRows(tResolveGroupsCols).
+			AddRow("dyn1", "dyn1", dgrpDynQuery))
+	mockPG.ExpectPrepare(regexp.QuoteMeta(tGetDynGroupIDs)).ExpectQuery().
+		WithArgs("Compute", "x3000([[:alpha:]][[:alnum:]]*)?").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).
+			AddRow("x3000c0s2b0n0").AddRow("x3000c0s3b0n0"))
+
+	mbs, err := dPG.GetMemberships(f)
+	if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
+		t.Errorf("Test Failed: Sql expectations were not met: %s", mock_err)
+	}
+	if err != nil {
+		t.Fatalf("Test Failed: Unexpected error received: %s", err)
+	}
+	expected := map[string][]string{
+		"x3000c0s1b0n0": []string{"grp1"},
+		"x3000c0s2b0n0": []string{"dyn1"},
+	}
+	if len(mbs) != len(expected) {
+		t.Fatalf("Test Failed: Expected %d memberships, got %v", len(expected), mbs)
+	}
+	for _, mb := range mbs {
+		if !reflect.DeepEqual(mb.GroupLabels, expected[mb.ID]) {
+			t.Errorf("Test Failed: Expected %s labels %v, got %v",
+				mb.ID, expected[mb.ID], mb.GroupLabels)
+		}
+	}
+}
 
-	dgrp3MQuery, _, _ := sqq.Select(compGroupMembersColsUser...).
-		From(compGroupMembersTable).
-		Where("group_id = ?", uuid3).ToSql()
+//
+// Partitions
+//
 
-	dgrp4Query, _, _ := sqq.Select(compGroupsColsSMGroup...).
+func TestPgGetPartition(t *testing.T) {
+	columns := compGroupsColsSMPart // "id", "name", "description", "tags", "version"
+
+	dval5 := []driver.Value{uuid5, dgrp5p.Name, dgrp5p.Description, pq.Array(&dgrp5p.Tags), 1}
+	dval6 := []driver.Value{uuid6, dgrp6p.Name, dgrp6p.Description, pq.Array(&dgrp6p.Tags), 1}
+
+	memberCols := []string{"component_id"}
+
+	sqq := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+
+	dgrp5Query, _, _ := sqq.Select(compGroupsColsSMPart...).
 		From(compGroupsTable).
-		Where("name = ?", strings.ToLower(dgrp4x.Label)).
-		Where("namespace = ?", groupNamespace).ToSql()
+		Where("name = ?", strings.ToLower(dgrp5p.Name)).
+		Where("namespace = ?", partNamespace).ToSql()
 
-	dgrp4MQuery, _, _ := sqq.Select(compGroupMembersColsUser...).
+	dgrp5MQuery, _, _ := sqq.Select(compGroupMembersColsUser...).
 		From(compGroupMembersTable).
-		Where("group_id = ?", uuid4).ToSql()
+		Where("group_id = ?", uuid5).ToSql()
 
-	dgrp1Query2, _, _ := sqq.Select(compGroupsColsSMPart...).
+	dgrp6Query, _, _ := sqq.Select(compGroupsColsSMPart...).
 		From(compGroupsTable).
-		Where("name = ?", strings.ToLower(dgrp5p.Name)).
+		Where("name = ?", strings.ToLower(dgrp6p.Name)).
 		Where("namespace = ?", partNamespace).ToSql()
 
-	dgrp1MQuery2, _, _ := sqq.Select("a.component_id").
-		From(compGroupMembersTable+" a").
-		Join(compGroupMembersTable+" b ON a.component_id = b.component_id").
-		Where("a.group_id = ?", uuid1).
-		Where("b.group_id IN (?,?)", uuid1, uuid1, uuid5).
-		GroupBy("a.component_id").
-		Having("COUNT(*) = 2").ToSql()
+	dgrp6MQuery, _, _ := sqq.Select(compGroupMembersColsUser...).
+		From(compGroupMembersTable).
+		Where("group_id = ?", uuid6).ToSql()
 
 	tests := []struct {
-		label                 string
-		filt_part             string
+		name                  string
 		dbColumns             []string
 		dbRows                [][]driver.Value
 		dbQueryError          error
 		expectedQueryPrepare  string
 		expectedQueryArgs     []driver.Value
-		dbColumns2            []string
-		dbRows2               [][]driver.Value
-		dbQuery2Error         error
-		expectedQuery2Prepare string
-		expectedQuery2Args    []driver.Value
 		dbMColumns            []string
 		dbMRows               [][]driver.Value
 		dbMQueryError         error
 		expectedMQueryPrepare string
 		expectedMQueryArgs    []driver.Value
-		expGroup              *sm.Group
-		expError              error
+		expPart               *sm.Partition
 	}{{
-		dgrp1.Label, // label
-		"",          // filt_part
-		columns,
-		[][]driver.Value{dval1},
-		nil,
-		regexp.QuoteMeta(dgrp1Query),
-		[]driver.Value{dgrp1.Label, groupNamespace},
-		columns2,
-		[][]driver.Value{},
-		nil,
-		regexp.QuoteMeta(""),
-		[]driver.Value{},
-		memberCols,
-		[][]driver.Value{
-			dgrp1c1,
-			dgrp1c2,
-		},
-		nil,
-		regexp.QuoteMeta(dgrp1MQuery),
-		[]driver.Value{uuid1},
-		dgrp1,
-		nil,
-	}, {
-		dgrp2.Label, // label
-		"",          // filt_part
-		columns,
-		[][]driver.Value{dval2},
-		nil,
-		regexp.QuoteMeta(dgrp2Query),
-		[]driver.Value{dgrp2.Label, groupNamespace},
-		columns2,
-		[][]driver.Value{},
-		nil,
-		regexp.QuoteMeta(""),
-		[]driver.Value{},
-		memberCols,
-		[][]driver.Value{
-			dgrp2c1,
-			dgrp2c2,
-		},
-		nil,
-		regexp.QuoteMeta(dgrp2MQuery),
-		[]driver.Value{uuid2},
-		dgrp2,
-		nil,
-	}, {
-		dgrp3x.Label, // label
-		"",           // filt_part
-		columns,
-		[][]driver.Value{dval3},
-		nil,
-		regexp.QuoteMeta(dgrp3Query),
-		[]driver.Value{dgrp3x.Label, groupNamespace},
-		columns2,
-		[][]driver.Value{},
-		nil,
-		regexp.QuoteMeta(""),
-		[]driver.Value{},
-		memberCols,
-		[][]driver.Value{
-			dgrp3c1,
-		},
-		nil,
-		regexp.QuoteMeta(dgrp3MQuery),
-		[]driver.Value{uuid3},
-		dgrp3x,
-		nil,
-	}, {
-		dgrp4x.Label, // label
-		"",           // filt_part
-		columns,
-		[][]driver.Value{dval4},
-		nil,
-		regexp.QuoteMeta(dgrp4Query),
-		[]driver.Value{dgrp4x.Label, groupNamespace},
-		columns2,
-		[][]driver.Value{},
-		nil,
-		regexp.QuoteMeta(""),
-		[]driver.Value{},
-		memberCols,
-		[][]driver.Value{
-			dgrp4c1,
-		},
-		nil,
-		regexp.QuoteMeta(dgrp4MQuery),
-		[]driver.Value{uuid4},
-		dgrp4x,
-		nil,
-	}, {
-		dgrp1.Label, // label
-		dgrp5p.Name, // filt_part
+		dgrp5p.Name, // label
 		columns,
-		[][]driver.Value{dval1},
-		nil,
-		regexp.QuoteMeta(dgrp1Query),
-		[]driver.Value{dgrp1.Label, groupNamespace},
-		columns2,
 		[][]driver.Value{dval5},
 		nil,
-		regexp.QuoteMeta(dgrp1Query2),
+		regexp.QuoteMeta(dgrp5Query),
 		[]driver.Value{dgrp5p.Name, partNamespace},
 		memberCols,
 		[][]driver.Value{
-			dgrp1fc1,
+			dgrp5c1,
 		},
 		nil,
-		regexp.QuoteMeta(dgrp1MQuery2),
-		[]driver.Value{uuid1, uuid1, uuid5},
-		dgrp1f,
-		nil,
-	}, {
-		dgrp1.Label, // label
-		dgrp5p.Name, // filt_part
-		columns,
-		[][]driver.Value{dval1},
-		nil,
-		regexp.QuoteMeta(dgrp1Query),
-		[]driver.Value{dgrp1.Label, groupNamespace},
-		columns2,
-		[][]driver.Value{},
-		sql.ErrNoRows, // Simulates unexpected error
-		regexp.QuoteMeta(dgrp1Query2),
-		[]driver.Value{dgrp5p.Name, partNamespace},
-		memberCols,
-		[][]driver.Value{},
-		nil,
-		regexp.QuoteMeta(""),
-		[]driver.Value{},
-		nil,
-		sql.ErrNoRows,
+		regexp.QuoteMeta(dgrp5MQuery),
+		[]driver.Value{uuid5},
+		dgrp5p,
 	}, {
-		dgrp1.Label, // label
-		dgrp5p.Name, // filt_part
+		dgrp6p.Name, // label
 		columns,
-		[][]driver.Value{dval1},
-		nil,
-		regexp.QuoteMeta(dgrp1Query),
-		[]driver.Value{dgrp1.Label, groupNamespace},
-		columns2,
-		[][]driver.Value{},
+		[][]driver.Value{dval6},
 		nil,
-		regexp.QuoteMeta(dgrp1Query2),
-		[]driver.Value{dgrp5p.Name, partNamespace},
+		regexp.QuoteMeta(dgrp6Query),
+		[]driver.Value{dgrp6p.Name, partNamespace},
 		memberCols,
-		[][]driver.Value{},
-		nil,
-		regexp.QuoteMeta(dgrp1MQuery2),
-		[]driver.Value{uuid1, uuid1, uuid1},
+		[][]driver.Value{
+			dgrp6c1,
+			dgrp6c2,
+		},
 		nil,
-		ErrHMSDSNoPartition,
+		regexp.QuoteMeta(dgrp6MQuery),
+		[]driver.Value{uuid6},
+		dgrp6p,
 	}}
 
 	for i, test := range tests {
@@ -4510,10 +6682,6 @@ func TestPgGetGroup(t *testing.T) {
 		for _, row := range test.dbRows {
 			rows.AddRow(row...)
 		}
-		rows2 := sqlmock.NewRows(test.dbColumns2)
-		for _, row := range test.dbRows2 {
-			rows2.AddRow(row...)
-		}
 		mrows := sqlmock.NewRows(test.dbMColumns)
 		for _, mrow := range test.dbMRows {
 			mrows.AddRow(mrow...)
@@ -4525,133 +6693,88 @@ func TestPgGetGroup(t *testing.T) {
 		} else if test.dbQueryError != nil {
 			mockPG.ExpectPrepare(test.expectedQueryPrepare).ExpectQuery().WillReturnError(test.dbQueryError)
 			mockPG.ExpectRollback()
-		} else if test.dbQuery2Error != nil {
-			mockPG.ExpectPrepare(test.expectedQueryPrepare).ExpectQuery().WithArgs(test.expectedQueryArgs...).WillReturnRows(rows)
-			mockPG.ExpectPrepare(test.expectedQuery2Prepare).ExpectQuery().WithArgs(test.expectedQuery2Args...).WillReturnError(test.dbQuery2Error)
-			mockPG.ExpectRollback()
-		} else if test.dbMQueryError != nil || test.expError != nil { // expError caues MQuery to be skipped.
+		} else if test.dbMQueryError != nil {
 			mockPG.ExpectPrepare(test.expectedQueryPrepare).ExpectQuery().WithArgs(test.expectedQueryArgs...).WillReturnRows(rows)
-			if test.filt_part != "" && len(test.dbRows) > 0 {
-				// We skip this if no rows were returned by first query and GetGroup returns an error
-				mockPG.ExpectPrepare(test.expectedQuery2Prepare).ExpectQuery().WithArgs(test.expectedQuery2Args...).WillReturnRows(rows2)
-			}
-			if test.dbMQueryError != nil {
-				mockPG.ExpectPrepare(test.expectedMQueryPrepare).ExpectExec().WillReturnError(test.dbMQueryError)
-			}
+			mockPG.ExpectPrepare(test.expectedMQueryPrepare).ExpectExec().WillReturnError(test.dbMQueryError)
 			mockPG.ExpectRollback()
 		} else {
 			mockPG.ExpectPrepare(test.expectedQueryPrepare).ExpectQuery().WithArgs(test.expectedQueryArgs...).WillReturnRows(rows)
-			if test.filt_part != "" {
-				mockPG.ExpectPrepare(test.expectedQuery2Prepare).ExpectQuery().WithArgs(test.expectedQuery2Args...).WillReturnRows(rows2)
-			}
 			mockPG.ExpectPrepare(test.expectedMQueryPrepare).ExpectQuery().WithArgs(test.expectedMQueryArgs...).WillReturnRows(mrows)
+			if sm.IsTopPartition(test.name) {
+				mockPG.ExpectPrepare(regexp.QuoteMeta(tGetSubPartIDs)).ExpectQuery().
+					WithArgs(test.name+".%", partNamespace).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}))
+			}
 			mockPG.ExpectCommit()
 		}
 
-		g, err := dPG.GetGroup(test.label, test.filt_part)
+		p, err := dPG.GetPartition(test.name)
 		// ensure all expectations have been met
 		if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
 			t.Errorf("Test %v Failed: Sql expectations were not met: %s", i, mock_err)
 		}
 		//if test.dbQueryError == nil && test.dbUpdateError == nil {
-		if test.dbQueryError == nil && test.dbQuery2Error == nil &&
-			test.dbMQueryError == nil && test.expError == nil {
-
+		if test.dbQueryError == nil && test.dbMQueryError == nil {
 			if err != nil {
 				t.Errorf("Test %v Failed: Unexpected error received: %s", i, err)
 			}
-			if g == nil {
+			if p == nil {
 				t.Errorf("Test %v Failed: Expected non-nil group", i)
 			} else {
-				if g.Label != test.expGroup.Label {
-					t.Errorf("Test %v Failed: Expected label %s (got %s)",
-						i, g.Label, test.expGroup.Label)
+				if p.Name != test.expPart.Name {
+					t.Errorf("Test %v Failed: Expected name %s (got %s)",
+						i, p.Name, test.expPart.Name)
 				}
-				if g.Description != test.expGroup.Description {
+				if p.Description != test.expPart.Description {
 					t.Errorf("Test %v Failed: Expected description %s (got %s)",
-						i, g.Description, test.expGroup.Description)
-				}
-				if g.ExclusiveGroup != test.expGroup.ExclusiveGroup {
-					t.Errorf("Test %v Failed: Expected excl grp %s (got %s)",
-						i, g.ExclusiveGroup, test.expGroup.ExclusiveGroup)
+						i, p.Description, test.expPart.Description)
 				}
-				if !reflect.DeepEqual(g.Tags, test.expGroup.Tags) {
+				if !reflect.DeepEqual(p.Tags, test.expPart.Tags) {
 					t.Errorf("Test %v Failed: Expected Tags %s (got %s)",
-						i, g.Tags, test.expGroup.Tags)
+						i, p.Tags, test.expPart.Tags)
 				}
-				if !reflect.DeepEqual(g.Members.IDs, test.expGroup.Members.IDs) {
+				if !reflect.DeepEqual(p.Members.IDs, test.expPart.Members.IDs) {
 					t.Errorf("Test %v Failed: Expected Members %s (got %s)",
-						i, g.Members.IDs, test.expGroup.Members.IDs)
+						i, p.Members.IDs, test.expPart.Members.IDs)
 				}
 			}
-		} else if err != test.expError {
-			t.Errorf("Test %v Failed: Expected error %v.", i, err)
+		} else if err == nil {
+			t.Errorf("Test %v Failed: Expected an error.", i)
 		}
 	}
 }
 
-func TestInsertPgGroup(t *testing.T) {
-	dval1 := []driver.Value{AnyUUID{}, dgrp1.Label, dgrp1.Description, pq.Array(&dgrp1.Tags), groupType, groupNamespace, dgrp1.ExclusiveGroup}
-	dval2 := []driver.Value{AnyUUID{}, dgrp2.Label, dgrp2.Description, pq.Array(&dgrp2.Tags), groupType, groupNamespace, dgrp2.ExclusiveGroup}
-	dval3 := []driver.Value{AnyUUID{}, dgrp3x.Label, dgrp3x.Description, pq.Array(&dgrp3x.Tags), exclGroupType, groupNamespace, dgrp3x.ExclusiveGroup}
-	dval4 := []driver.Value{AnyUUID{}, dgrp4x.Label, dgrp4x.Description, pq.Array(&dgrp4x.Tags), exclGroupType, groupNamespace, dgrp4x.ExclusiveGroup}
+func TestPgInsertPartition(t *testing.T) {
+	dval5 := []driver.Value{AnyUUID{}, dgrp5p.Name, dgrp5p.Description, pq.Array(&dgrp5p.Tags), partType, partNamespace, ""}
+	dval6 := []driver.Value{AnyUUID{}, dgrp6p.Name, dgrp6p.Description, pq.Array(&dgrp6p.Tags), partType, partNamespace, ""}
 
 	sqq := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
 
-	// Note we don't use the args here.  We can't in any case because
-	// we need driver.Value because it supports AnyUUID{}.  Can't predict
-	// the value used by the function because it is generated for each new
-	// group.
-	dgrp1UpdateGrp, _, _ := sqq.Insert(compGroupsTable).
-		Columns(compGroupsColsAll7...).
-		Values(sq.Expr("?", uuid1), dgrp1.Label, dgrp1.Description,
-			pq.Array(&dgrp1.Tags), groupType, groupNamespace,
-			dgrp1.ExclusiveGroup).ToSql()
-
-	dgrp1Update, _, _ := sqq.Insert(compGroupMembersTable).
-		Columns(compGroupMembersColsNoTS...).
-		Values(dgrp1.Members.IDs[0], sq.Expr("?", uuid1),
-			dgrp1.Label).
-		Values(dgrp1.Members.IDs[1], sq.Expr("?", uuid1),
-			dgrp1.Label).ToSql()
-
-	dgrp2UpdateGrp, _, _ := sqq.Insert(compGroupsTable).
-		Columns(compGroupsColsAll7...).
-		Values(sq.Expr("?", uuid2), dgrp2.Label, dgrp2.Description,
-			pq.Array(&dgrp2.Tags), groupType, groupNamespace,
-			dgrp2.ExclusiveGroup).ToSql()
-
-	dgrp2Update, _, _ := sqq.Insert(compGroupMembersTable).
-		Columns(compGroupMembersColsNoTS...).
-		Values(dgrp2.Members.IDs[0], sq.Expr("?", uuid2),
-			dgrp2.Label).
-		Values(dgrp2.Members.IDs[1], sq.Expr("?", uuid2),
-			dgrp2.Label).ToSql()
-
-	dgrp3UpdateGrp, _, _ := sqq.Insert(compGroupsTable).
+	// Note we only use the query here so the args values don't really matter.
+	dgrp5UpdateGrp, _, _ := sqq.Insert(compGroupsTable).
 		Columns(compGroupsColsAll7...).
-		Values(sq.Expr("?", uuid3), dgrp3x.Label, dgrp3x.Description,
-			pq.Array(&dgrp3x.Tags), exclGroupType, groupNamespace,
-			dgrp3x.ExclusiveGroup).ToSql()
+		Values(sq.Expr("?", uuid5), dgrp5p.Name, dgrp5p.Description,
+			pq.Array(&dgrp5p.Tags), partType, partNamespace, "").ToSql()
 
-	dgrp3Update, _, _ := sqq.Insert(compGroupMembersTable).
+	dgrp5Update, _, _ := sqq.Insert(compGroupMembersTable).
 		Columns(compGroupMembersColsNoTS...).
-		Values(dgrp3x.Members.IDs[0], sq.Expr("?", uuid3),
-			"%"+dgrp3x.ExclusiveGroup+"%").ToSql()
+		Values(dgrp5p.Members.IDs[0], sq.Expr("?", uuid5),
+			partGroupNamespace).ToSql()
 
-	dgrp4UpdateGrp, _, _ := sqq.Insert(compGroupsTable).
+	dgrp6UpdateGrp, _, _ := sqq.Insert(compGroupsTable).
 		Columns(compGroupsColsAll7...).
-		Values(sq.Expr("?", uuid4), dgrp4x.Label, dgrp4x.Description,
-			pq.Array(&dgrp4x.Tags), exclGroupType, groupNamespace,
-			dgrp4x.ExclusiveGroup).ToSql()
+		Values(sq.Expr("?", uuid6), dgrp6p.Name, dgrp6p.Description,
+			pq.Array(&dgrp6p.Tags), partType, partNamespace, "").ToSql()
 
-	dgrp4Update, _, _ := sqq.Insert(compGroupMembersTable).
+	dgrp6Update, _, _ := sqq.Insert(compGroupMembersTable).
 		Columns(compGroupMembersColsNoTS...).
-		Values(dgrp4x.Members.IDs[0], sq.Expr("?", uuid4),
-			"%"+dgrp4x.ExclusiveGroup+"%").ToSql()
+		Values(dgrp6p.Members.IDs[0], sq.Expr("?", uuid6),
+			partGroupNamespace).
+		Values(dgrp6p.Members.IDs[1], sq.Expr("?", uuid6),
+			partGroupNamespace).ToSql()
 
 	tests := []struct {
-		group                    *sm.Group
+		part                     *sm.Partition
 		dbUpdateGrpError         error
 		expectedUpdateGrpPrepare string
 		expectedUpdateGrpArgs    []driver.Value
@@ -4659,49 +6782,28 @@ func TestInsertPgGroup(t *testing.T) {
 		expectedUpdatePrepare    string
 		expectedUpdateArgs       []driver.Value
 		expectedMembers          int64
-		expectedLabel            string
+		expectedName             string
 	}{{
-		dgrp1,
-		nil,
-		regexp.QuoteMeta(dgrp1UpdateGrp),
-		dval1,
-		nil,
-		regexp.QuoteMeta(dgrp1Update),
-		[]driver.Value{dgrp1.Members.IDs[0], AnyUUID{}, dgrp1.Label,
-			dgrp1.Members.IDs[1], AnyUUID{}, dgrp1.Label},
-		int64(len(dgrp1.Members.IDs)),
-		strings.ToLower(dgrp1.Label), // label
-	}, {
-		dgrp2,
-		nil,
-		regexp.QuoteMeta(dgrp2UpdateGrp),
-		dval2,
-		nil,
-		regexp.QuoteMeta(dgrp2Update),
-		[]driver.Value{dgrp2.Members.IDs[0], AnyUUID{}, dgrp2.Label,
-			dgrp2.Members.IDs[1], AnyUUID{}, dgrp2.Label},
-		int64(len(dgrp2.Members.IDs)),
-		strings.ToLower(dgrp2.Label),
-	}, {
-		dgrp3x,
+		dgrp5p,
 		nil,
-		regexp.QuoteMeta(dgrp3UpdateGrp),
-		dval3,
+		regexp.QuoteMeta(dgrp5UpdateGrp),
+		dval5,
 		nil,
-		regexp.QuoteMeta(dgrp3Update),
-		[]driver.Value{dgrp3x.Members.IDs[0], AnyUUID{}, "%" + dgrp3x.ExclusiveGroup + "%"},
-		int64(len(dgrp3x.Members.IDs)),
-		strings.ToLower(dgrp3x.Label), // label
+		regexp.QuoteMeta(dgrp5Update),
+		[]driver.Value{dgrp5p.Members.IDs[0], AnyUUID{}, partGroupNamespace},
+		int64(len(dgrp5p.Members.IDs)),
+		strings.ToLower(dgrp5p.Name), // Name
 	}, {
-		dgrp4x,
+		dgrp6p,
 		nil,
-		regexp.QuoteMeta(dgrp4UpdateGrp),
-		dval4,
+		regexp.QuoteMeta(dgrp6UpdateGrp),
+		dval6,
 		nil,
-		regexp.QuoteMeta(dgrp4Update),
-		[]driver.Value{dgrp4x.Members.IDs[0], AnyUUID{}, "%" + dgrp4x.ExclusiveGroup + "%"},
-		int64(len(dgrp4x.Members.IDs)),
-		strings.ToLower(dgrp4x.Label), // label
+		regexp.QuoteMeta(dgrp6Update),
+		[]driver.Value{dgrp6p.Members.IDs[0], AnyUUID{}, partGroupNamespace,
+			dgrp6p.Members.IDs[1], AnyUUID{}, partGroupNamespace},
+		int64(len(dgrp6p.Members.IDs)),
+		strings.ToLower(dgrp6p.Name),
 	}}
 
 	for i, test := range tests {
@@ -4714,15 +6816,21 @@ func TestInsertPgGroup(t *testing.T) {
 			mockPG.ExpectRollback()
 		} else if test.dbUpdateError != nil {
 			mockPG.ExpectPrepare(test.expectedUpdateGrpPrepare).ExpectExec().WithArgs(test.expectedUpdateGrpArgs...).WillReturnResult(sqlmock.NewResult(0, 1))
+			if !sm.IsTopPartition(test.part.Name) {
+				expectTakeFromParent(false, test.part.Members.IDs...)
+			}
 			mockPG.ExpectPrepare(test.expectedUpdatePrepare).ExpectExec().WillReturnError(test.dbUpdateError)
 			mockPG.ExpectRollback()
 		} else {
 			mockPG.ExpectPrepare(test.expectedUpdateGrpPrepare).ExpectExec().WithArgs(test.expectedUpdateGrpArgs...).WillReturnResult(sqlmock.NewResult(0, 1))
+			if !sm.IsTopPartition(test.part.Name) {
+				expectTakeFromParent(false, test.part.Members.IDs...)
+			}
 			mockPG.ExpectPrepare(test.expectedUpdatePrepare).ExpectExec().WithArgs(test.expectedUpdateArgs...).WillReturnResult(sqlmock.NewResult(0, test.expectedMembers))
 			mockPG.ExpectCommit()
 		}
 
-		label, err := dPG.InsertGroup(test.group)
+		name, err := dPG.InsertPartition(test.part, "")
 		// ensure all expectations have been met
 		if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
 			t.Errorf("Test %v Failed: Sql expectations were not met: %s",
@@ -4732,12 +6840,12 @@ func TestInsertPgGroup(t *testing.T) {
 			if err != nil {
 				t.Errorf("Test %v Failed: Unexpected error received: %s", i, err)
 			}
-			if label == "" {
+			if name == "" {
 				t.Errorf("Test %v Failed: Expected non-empty label", i)
 			} else {
-				if label != test.expectedLabel {
+				if name != test.expectedName {
 					t.Errorf("Test %v Failed: Expected label %s (got %s)",
-						i, label, test.expectedLabel)
+						i, name, test.expectedName)
 				}
 			}
 		} else if err == nil {
@@ -4746,56 +6854,38 @@ func TestInsertPgGroup(t *testing.T) {
 	}
 }
 
-func TestPgUpdateGroup(t *testing.T) {
+func TestPgUpdatePartition(t *testing.T) {
 	newDescription := "newDescription" // shouldn't match any existing desc
 
-	columns := compGroupsColsSMGroup // "id", "name", "description", "tags", "exclusive_group_identifier"
-	//
-	dval1 := []driver.Value{uuid1, dgrp1.Label, dgrp1.Description, pq.Array(&dgrp1.Tags), dgrp1.ExclusiveGroup}
-	dval2 := []driver.Value{uuid2, dgrp2.Label, dgrp2.Description, pq.Array(&dgrp2.Tags), dgrp2.ExclusiveGroup}
-	dval3 := []driver.Value{uuid3, dgrp3x.Label, dgrp3x.Description, pq.Array(&dgrp3x.Tags), dgrp3x.ExclusiveGroup}
-	dval4 := []driver.Value{uuid4, dgrp4x.Label, dgrp4x.Description, pq.Array(&dgrp4x.Tags), dgrp4x.ExclusiveGroup}
+	columns := compGroupsColsSMPart // "id", "name", "description", "tags", "version"
+
+	dval5 := []driver.Value{uuid5, dgrp5p.Name, dgrp5p.Description, pq.Array(&dgrp5p.Tags), 1}
+	dval6 := []driver.Value{uuid6, dgrp6p.Name, dgrp5p.Description, pq.Array(&dgrp6p.Tags), 1}
 
 	sqq := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
 
-	dgrp1Query, _, _ := sqq.Select(compGroupsColsSMGroup...).
+	dgrp5Query, _, _ := sqq.Select(compGroupsColsSMPart...).
 		From(compGroupsTable).
-		Where("name = ?", strings.ToLower(dgrp1.Label)).
-		Where("namespace = ?", groupNamespace).ToSql()
+		Where("name = ?", strings.ToLower(dgrp5p.Name)).
+		Where("namespace = ?", partNamespace).ToSql()
 
-	dgrp1Update, _, _ := sqq.Update("").Table(compGroupsTable).
-		Where("id = ?", uuid1).
+	dgrp5Update, _, _ := sqq.Update("").Table(compGroupsTable).
+		Where("id = ?", uuid5).
 		Set(compGroupDescCol, newDescription).
-		Set(compGroupTagsCol, pq.Array(&dgrp2.Tags)).ToSql()
-
-	dgrp2Query, _, _ := sqq.Select(compGroupsColsSMGroup...).
-		From(compGroupsTable).
-		Where("name = ?", strings.ToLower(dgrp2.Label)).
-		Where("namespace = ?", groupNamespace).ToSql()
-
-	//dgrp2Update, _, _ := sqq.Update("").Table(compGroupsTable).
-	//	Where("id = ?", uuid2).ToSql()
-
-	dgrp3Query, _, _ := sqq.Select(compGroupsColsSMGroup...).
-		From(compGroupsTable).
-		Where("name = ?", strings.ToLower(dgrp3x.Label)).
-		Where("namespace = ?", groupNamespace).ToSql()
-
-	//dgrp3Update, _, _ := sqq.Update("").Table(compGroupsTable).
-	//	Where("id = ?", uuid3).ToSql()
+		Set(compGroupTagsCol, pq.Array(&dgrp6p.Tags)).ToSql()
 
-	dgrp4Query, _, _ := sqq.Select(compGroupsColsSMGroup...).
+	dgrp6Query, _, _ := sqq.Select(compGroupsColsSMPart...).
 		From(compGroupsTable).
-		Where("name = ?", strings.ToLower(dgrp4x.Label)).
-		Where("namespace = ?", groupNamespace).ToSql()
+		Where("name = ?", strings.ToLower(dgrp6p.Name)).
+		Where("namespace = ?", partNamespace).ToSql()
 
-	dgrp4Update, _, _ := sqq.Update("").Table(compGroupsTable).
-		Where("id = ?", uuid4).
-		Set(compGroupTagsCol, pq.Array(&dgrp3x.Tags)).ToSql()
+	dgrp6Update, _, _ := sqq.Update("").Table(compGroupsTable).
+		Where("id = ?", uuid6).
+		Set(compGroupTagsCol, pq.Array(&dgrp5p.Tags)).ToSql()
 
 	tests := []struct {
 		label                 string
-		gp                    *sm.GroupPatch
+		gp                    *sm.PartitionPatch
 		dbColumns             []string
 		dbRows                [][]driver.Value
 		dbQueryError          error
@@ -4806,77 +6896,77 @@ func TestPgUpdateGroup(t *testing.T) {
 		expectedUpdateArgs    []driver.Value
 		expectedError         error
 	}{{
-		dgrp1.Label, // label
-		&sm.GroupPatch{Description: &newDescription, Tags: &dgrp2.Tags},
+		dgrp5p.Name,
+		&sm.PartitionPatch{Description: &newDescription, Tags: &dgrp6p.Tags},
 		columns,
-		[][]driver.Value{dval1},
+		[][]driver.Value{dval5},
 		nil,
-		regexp.QuoteMeta(dgrp1Query),
-		[]driver.Value{dgrp1.Label, groupNamespace},
+		regexp.QuoteMeta(dgrp5Query),
+		[]driver.Value{dgrp5p.Name, partNamespace},
 		nil,
-		regexp.QuoteMeta(dgrp1Update),
-		[]driver.Value{newDescription, pq.Array(&dgrp2.Tags), uuid1},
+		regexp.QuoteMeta(dgrp5Update),
+		[]driver.Value{newDescription, pq.Array(&dgrp6p.Tags), uuid5},
 		nil,
 	}, {
-		strings.ToUpper(dgrp2.Label), // label
-		&sm.GroupPatch{},
+		strings.ToUpper(dgrp5p.Name),
+		&sm.PartitionPatch{}, // No updates - do nothing
 		columns,
-		[][]driver.Value{dval2},
+		[][]driver.Value{dval5},
 		nil,
-		regexp.QuoteMeta(dgrp2Query),
-		[]driver.Value{dgrp2.Label, groupNamespace},
+		regexp.QuoteMeta(dgrp5Query),
+		[]driver.Value{dgrp5p.Name, partNamespace},
 		nil,
 		regexp.QuoteMeta(""),
 		[]driver.Value{},
 		nil,
 	}, {
-		strings.ToUpper(dgrp3x.Label), // label
-		&sm.GroupPatch{Description: &dgrp3x.Description, Tags: &dgrp3x.Tags},
+		strings.ToUpper(dgrp5p.Name), // No update- should already match
+		&sm.PartitionPatch{Description: &dgrp5p.Description, Tags: &dgrp5p.Tags},
 		columns,
-		[][]driver.Value{dval3},
+		[][]driver.Value{dval5},
 		nil,
-		regexp.QuoteMeta(dgrp3Query),
-		[]driver.Value{dgrp3x.Label, groupNamespace},
+		regexp.QuoteMeta(dgrp5Query),
+		[]driver.Value{dgrp5p.Name, partNamespace},
 		nil,
 		regexp.QuoteMeta(""),
 		[]driver.Value{},
 		nil,
 	}, {
-		dgrp4x.Label, // label
-		&sm.GroupPatch{Tags: &dgrp3x.Tags},
+		dgrp6p.Name, // Just tags are different, not description.
+		&sm.PartitionPatch{Tags: &dgrp5p.Tags},
 		columns,
-		[][]driver.Value{dval4},
+		[][]driver.Value{dval6},
 		nil,
-		regexp.QuoteMeta(dgrp4Query),
-		[]driver.Value{dgrp4x.Label, groupNamespace},
+		regexp.QuoteMeta(dgrp6Query),
+		[]driver.Value{dgrp6p.Name, partNamespace},
 		nil,
-		regexp.QuoteMeta(dgrp4Update),
-		[]driver.Value{pq.Array(&dgrp3x.Tags), uuid4},
+		regexp.QuoteMeta(dgrp6Update),
+		[]driver.Value{pq.Array(&dgrp5p.Tags), uuid6},
 		nil,
 	}, {
-		dgrp4x.Label, // label
-		&sm.GroupPatch{Tags: &dgrp3x.Tags},
+		dgrp6p.Name, // Unexpected DB error during update.
+		&sm.PartitionPatch{Tags: &dgrp5p.Tags},
 		columns,
-		[][]driver.Value{dval4},
+		[][]driver.Value{dval6},
 		nil,
-		regexp.QuoteMeta(dgrp4Query),
-		[]driver.Value{dgrp4x.Label, groupNamespace},
+		regexp.QuoteMeta(dgrp6Query),
+		[]driver.Value{dgrp6p.Name, partNamespace},
 		sql.ErrNoRows,
-		regexp.QuoteMeta(dgrp4Update),
-		[]driver.Value{pq.Array(&dgrp3x.Tags), uuid4},
+		regexp.QuoteMeta(dgrp6Update),
+		[]driver.Value{pq.Array(&dgrp5p.Tags), uuid6},
 		sql.ErrNoRows,
 	}, {
-		"badlabel", // label
-		&sm.GroupPatch{},
+		"badName",
+		&sm.PartitionPatch{},
 		columns,
 		[][]driver.Value{},
 		nil,
-		regexp.QuoteMeta(dgrp4Query),
-		[]driver.Value{"badlabel", groupNamespace},
+		regexp.QuoteMeta(dgrp6Query),
+		[]driver.Value{"badname", partNamespace},
 		nil,
 		regexp.QuoteMeta(""),
 		[]driver.Value{},
-		ErrHMSDSNoGroup,
+		ErrHMSDSNoPartition,
 	}}
 
 	for i, test := range tests {
@@ -4907,7 +6997,7 @@ func TestPgUpdateGroup(t *testing.T) {
 			mockPG.ExpectCommit()
 		}
 
-		err := dPG.UpdateGroup(test.label, test.gp)
+		err := dPG.UpdatePartition(test.label, test.gp, "")
 		if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
 			t.Errorf("Test %v Failed: Sql expectations were not met: %s",
 				i, mock_err)
@@ -4923,58 +7013,36 @@ func TestPgUpdateGroup(t *testing.T) {
 	}
 }
 
-func TestPgAddGroupMember(t *testing.T) {
-	columns := compGroupsColsSMGroup // "id", "name", "description", "tags", "exclusive_group_identifier"
-	//
-	dval1 := []driver.Value{uuid1, dgrp1.Label, dgrp1.Description, pq.Array(&dgrp1.Tags), dgrp1.ExclusiveGroup}
-	dval2 := []driver.Value{uuid2, dgrp2.Label, dgrp2.Description, pq.Array(&dgrp2.Tags), dgrp2.ExclusiveGroup}
-	dval3 := []driver.Value{uuid3, dgrp3x.Label, dgrp3x.Description, pq.Array(&dgrp3x.Tags), dgrp3x.ExclusiveGroup}
-	dval4 := []driver.Value{uuid4, dgrp4x.Label, dgrp4x.Description, pq.Array(&dgrp4x.Tags), dgrp4x.ExclusiveGroup}
-
-	sqq := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
-
-	dgrp1Query, _, _ := sqq.Select(compGroupsColsSMGroup...).
-		From(compGroupsTable).
-		Where("name = ?", strings.ToLower(dgrp1.Label)).
-		Where("namespace = ?", groupNamespace).ToSql()
-
-	dgrp1Update, _, _ := sqq.Insert(compGroupMembersTable).
-		Columns(compGroupMembersColsNoTS...).
-		Values("x0c0s22b0n0", sq.Expr("?", uuid1),
-			dgrp1.Label).ToSql()
+func TestPgAddPartitionMember(t *testing.T) {
+	columns := compGroupsColsSMPart // "id", "name", "description", "tags", "version"
 
-	dgrp2Query, _, _ := sqq.Select(compGroupsColsSMGroup...).
-		From(compGroupsTable).
-		Where("name = ?", strings.ToLower(dgrp2.Label)).
-		Where("namespace = ?", groupNamespace).ToSql()
+	dval5 := []driver.Value{uuid5, dgrp5p.Name, dgrp5p.Description, pq.Array(&dgrp5p.Tags), 1}
+	dval6 := []driver.Value{uuid6, dgrp6p.Name, dgrp6p.Description, pq.Array(&dgrp6p.Tags), 1}
 
-	dgrp2Update, _, _ := sqq.Insert(compGroupMembersTable).
-		Columns(compGroupMembersColsNoTS...).
-		Values("x0c0s2b0n0", sq.Expr("?", uuid2),
-			dgrp2.Label).ToSql()
+	sqq := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
 
-	dgrp3Query, _, _ := sqq.Select(compGroupsColsSMGroup...).
+	dgrp5Query, _, _ := sqq.Select(compGroupsColsSMPart...).
 		From(compGroupsTable).
-		Where("name = ?", strings.ToLower(dgrp3x.Label)).
-		Where("namespace = ?", groupNamespace).ToSql()
+		Where("name = ?", strings.ToLower(dgrp5p.Name)).
+		Where("namespace = ?", partNamespace).ToSql()
 
-	dgrp3Update, _, _ := sqq.Insert(compGroupMembersTable).
+	dgrp5Update, _, _ := sqq.Insert(compGroupMembersTable).
 		Columns(compGroupMembersColsNoTS...).
-		Values("x0c0s22b1n1", sq.Expr("?", uuid3),
-			"%"+dgrp3x.ExclusiveGroup+"%").ToSql()
+		Values("x0c0s22b1n1", sq.Expr("?", uuid5),
+			partGroupNamespace).ToSql()
 
-	dgrp4Query, _, _ := sqq.Select(compGroupsColsSMGroup...).
+	dgrp6Query, _, _ := sqq.Select(compGroupsColsSMPart...).
 		From(compGroupsTable).
-		Where("name = ?", strings.ToLower(dgrp4x.Label)).
-		Where("namespace = ?", groupNamespace).ToSql()
+		Where("name = ?", strings.ToLower(dgrp6p.Name)).
+		Where("namespace = ?", partNamespace).ToSql()
 
-	dgrp4Update, _, _ := sqq.Insert(compGroupMembersTable).
+	dgrp6Update, _, _ := sqq.Insert(compGroupMembersTable).
 		Columns(compGroupMembersColsNoTS...).
-		Values("x0c0s4b0n0", sq.Expr("?", uuid4),
-			"%"+dgrp4x.ExclusiveGroup+"%").ToSql()
+		Values("x0c0s4b0n0", sq.Expr("?", uuid6),
+			partGroupNamespace).ToSql()
 
 	tests := []struct {
-		label                 string
+		name                  string
 		new_id                string
 		dbColumns             []string
 		dbRows                [][]driver.Value
@@ -4987,81 +7055,55 @@ func TestPgAddGroupMember(t *testing.T) {
 		expectedId            string
 		expectedError         error
 	}{{
-		dgrp1.Label,    // label
-		"X00c0s22b0n0", // new_id
-		columns,
-		[][]driver.Value{dval1},
-		nil,
-		regexp.QuoteMeta(dgrp1Query),
-		[]driver.Value{dgrp1.Label, groupNamespace},
-		nil,
-		regexp.QuoteMeta(dgrp1Update),
-		[]driver.Value{"x0c0s22b0n0", uuid1, dgrp1.Label},
-		"x0c0s22b0n0",
-		nil,
-	}, {
-		dgrp2.Label,   // label
-		"x0c0s2b0n00", // new_id
-		columns,
-		[][]driver.Value{dval2},
-		nil,
-		regexp.QuoteMeta(dgrp2Query),
-		[]driver.Value{dgrp2.Label, groupNamespace},
-		nil,
-		regexp.QuoteMeta(dgrp2Update),
-		[]driver.Value{"x0c0s2b0n0", uuid2, dgrp2.Label},
-		"x0c0s2b0n0",
-		nil,
-	}, {
-		dgrp3x.Label,   // label
+		dgrp5p.Name,    // name
 		"x0c0s022b1n1", // new_id
 		columns,
-		[][]driver.Value{dval3},
+		[][]driver.Value{dval5},
 		nil,
-		regexp.QuoteMeta(dgrp3Query),
-		[]driver.Value{dgrp3x.Label, groupNamespace},
+		regexp.QuoteMeta(dgrp5Query),
+		[]driver.Value{dgrp5p.Name, partNamespace},
 		nil,
-		regexp.QuoteMeta(dgrp3Update),
-		[]driver.Value{"x0c0s22b1n1", uuid3, "%" + dgrp3x.ExclusiveGroup + "%"},
+		regexp.QuoteMeta(dgrp5Update),
+		[]driver.Value{"x0c0s22b1n1", uuid5, partGroupNamespace},
 		"x0c0s22b1n1",
 		nil,
 	}, {
-		dgrp4x.Label, // label
+		dgrp6p.Name,  // name
 		"x0c0s4b0n0", // new_id
 		columns,
-		[][]driver.Value{dval4},
+		[][]driver.Value{dval6},
 		nil,
-		regexp.QuoteMeta(dgrp4Query),
-		[]driver.Value{dgrp4x.Label, groupNamespace},
+		regexp.QuoteMeta(dgrp6Query),
+		[]driver.Value{dgrp6p.Name, partNamespace},
 		nil,
-		regexp.QuoteMeta(dgrp4Update),
-		[]driver.Value{"x0c0s4b0n0", uuid4, "%" + dgrp4x.ExclusiveGroup + "%"},
+		regexp.QuoteMeta(dgrp6Update),
+		[]driver.Value{"x0c0s4b0n0", uuid6, partGroupNamespace},
 		"x0c0s4b0n0",
 		nil,
 	}, {
-		"badlabel",   // label
+		"badname",    // name
 		"x0c0s4b0n0", // new_id
 		columns,
 		[][]driver.Value{},
 		nil,
-		regexp.QuoteMeta(dgrp4Query),
-		[]driver.Value{"badlabel", groupNamespace},
+		regexp.QuoteMeta(dgrp6Query),
+		[]driver.Value{"badname", partNamespace},
 		nil,
 		regexp.QuoteMeta(""),
 		[]driver.Value{},
 		"",
-		ErrHMSDSNoGroup,
+		ErrHMSDSNoPartition,
 	}, {
-		dgrp4x.Label, // label
+		dgrp6p.Name,  // name
 		"x0c0s4b0n0", // new_id
 		columns,
-		[][]driver.Value{dval4},
+		[][]driver.Value{dval6},
 		nil,
-		regexp.QuoteMeta(dgrp4Query),
-		[]driver.Value{dgrp4x.Label, groupNamespace},
+		regexp.QuoteMeta(dgrp6Query),
+		[]driver.Value{dgrp6p.Name, partNamespace},
 		ErrHMSDSDuplicateKey,
-		regexp.QuoteMeta(dgrp4Update),
-		[]driver.Value{"x0c0s4b0n0", uuid4, "%" + dgrp4x.ExclusiveGroup + "%"},
+		regexp.QuoteMeta(dgrp6Update),
+		[]driver.Value{"x0c0s4b0n0", uuid6, partGroupNamespace},
 		"",
 		ErrHMSDSDuplicateKey,
 	}}
@@ -5083,16 +7125,22 @@ func TestPgAddGroupMember(t *testing.T) {
 		} else if test.dbUpdateError != nil || test.expectedError != nil {
 			mockPG.ExpectPrepare(test.expectedQueryPrepare).ExpectQuery().WithArgs(test.expectedQueryArgs...).WillReturnRows(rows)
 			if test.dbUpdateError != nil {
+				if !sm.IsTopPartition(test.name) {
+					expectTakeFromParent(true, test.new_id)
+				}
 				mockPG.ExpectPrepare(test.expectedUpdatePrepare).ExpectExec().WillReturnError(test.dbUpdateError)
 			}
 			mockPG.ExpectRollback()
 		} else {
 			mockPG.ExpectPrepare(test.expectedQueryPrepare).ExpectQuery().WithArgs(test.expectedQueryArgs...).WillReturnRows(rows)
+			if !sm.IsTopPartition(test.name) {
+				expectTakeFromParent(true, test.new_id)
+			}
 			mockPG.ExpectPrepare(test.expectedUpdatePrepare).ExpectExec().WithArgs(test.expectedUpdateArgs...).WillReturnResult(sqlmock.NewResult(0, 1))
 			mockPG.ExpectCommit()
 		}
 
-		id, err := dPG.AddGroupMember(test.label, test.new_id)
+		id, err := dPG.AddPartitionMember(test.name, test.new_id, "")
 		// ensure all expectations have been met
 		if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
 			t.Errorf("Test %v Failed: Sql expectations were not met: %s",
@@ -5107,7 +7155,7 @@ func TestPgAddGroupMember(t *testing.T) {
 				t.Errorf("Test %v Failed: Expected non-empty id", i)
 			} else {
 				if id != test.expectedId {
-					t.Errorf("Test %v Failed: Expected label %s (got %s)",
+					t.Errorf("Test %v Failed: Expected name %s (got %s)",
 						i, id, test.expectedId)
 				}
 			}
@@ -5118,58 +7166,36 @@ func TestPgAddGroupMember(t *testing.T) {
 	}
 }
 
-func TestPgDeleteGroupMember(t *testing.T) {
-	columns := compGroupsColsSMGroup // "id", "name", "description", "tags", "exclusive_group_identifier"
-	//
-	dval1 := []driver.Value{uuid1, dgrp1.Label, dgrp1.Description, pq.Array(&dgrp1.Tags), dgrp1.ExclusiveGroup}
-	dval2 := []driver.Value{uuid2, dgrp2.Label, dgrp2.Description, pq.Array(&dgrp2.Tags), dgrp2.ExclusiveGroup}
-	dval3 := []driver.Value{uuid3, dgrp3x.Label, dgrp3x.Description, pq.Array(&dgrp3x.Tags), dgrp3x.ExclusiveGroup}
-	dval4 := []driver.Value{uuid4, dgrp4x.Label, dgrp4x.Description, pq.Array(&dgrp4x.Tags), dgrp4x.ExclusiveGroup}
-
-	sqq := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
-
-	dgrp1Query, _, _ := sqq.Select(compGroupsColsSMGroup...).
-		From(compGroupsTable).
-		Where("name = ?", strings.ToLower(dgrp1.Label)).
-		Where("namespace = ?", groupNamespace).ToSql()
-
-	dgrp1Update, _, _ := sqq.Delete(compGroupMembersTable).
-		Where("group_id = ?", uuid1).
-		Where("component_id = ?",
-			xnametypes.NormalizeHMSCompID(dgrp1.Members.IDs[0])).ToSql()
+func TestPgDeletePartitionMember(t *testing.T) {
+	columns := compGroupsColsSMPart // "id", "name", "description", "tags", "version"
 
-	dgrp2Query, _, _ := sqq.Select(compGroupsColsSMGroup...).
-		From(compGroupsTable).
-		Where("name = ?", strings.ToLower(dgrp2.Label)).
-		Where("namespace = ?", groupNamespace).ToSql()
+	dval5 := []driver.Value{uuid5, dgrp5p.Name, dgrp5p.Description, pq.Array(&dgrp5p.Tags), 1}
+	dval6 := []driver.Value{uuid6, dgrp6p.Name, dgrp6p.Description, pq.Array(&dgrp6p.Tags), 1}
 
-	dgrp2Update, _, _ := sqq.Delete(compGroupMembersTable).
-		Where("group_id = ?", uuid2).
-		Where("component_id = ?",
-			xnametypes.NormalizeHMSCompID(dgrp2.Members.IDs[0])).ToSql()
+	sqq := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
 
-	dgrp3Query, _, _ := sqq.Select(compGroupsColsSMGroup...).
+	dgrp5Query, _, _ := sqq.Select(compGroupsColsSMPart...).
 		From(compGroupsTable).
-		Where("name = ?", strings.ToLower(dgrp3x.Label)).
-		Where("namespace = ?", groupNamespace).ToSql()
+		Where("name = ?", strings.ToLower(dgrp5p.Name)).
+		Where("namespace = ?", partNamespace).ToSql()
 
-	dgrp3Update, _, _ := sqq.Delete(compGroupMembersTable).
-		Where("group_id = ?", uuid3).
+	dgrp5Update, _, _ := sqq.Delete(compGroupMembersTable).
+		Where("group_id = ?", uuid5).
 		Where("component_id = ?",
-			xnametypes.NormalizeHMSCompID(dgrp3x.Members.IDs[0])).ToSql()
+			xnametypes.NormalizeHMSCompID(dgrp5p.Members.IDs[0])).ToSql()
 
-	dgrp4Query, _, _ := sqq.Select(compGroupsColsSMGroup...).
+	dgrp6Query, _, _ := sqq.Select(compGroupsColsSMPart...).
 		From(compGroupsTable).
-		Where("name = ?", strings.ToLower(dgrp4x.Label)).
-		Where("namespace = ?", groupNamespace).ToSql()
+		Where("name = ?", strings.ToLower(dgrp6p.Name)).
+		Where("namespace = ?", partNamespace).ToSql()
 
-	dgrp4Update, _, _ := sqq.Delete(compGroupMembersTable).
-		Where("group_id = ?", uuid4).
+	dgrp6Update, _, _ := sqq.Delete(compGroupMembersTable).
+		Where("group_id = ?", uuid6).
 		Where("component_id = ?",
-			xnametypes.NormalizeHMSCompID(dgrp4x.Members.IDs[0])).ToSql()
+			xnametypes.NormalizeHMSCompID(dgrp6p.Members.IDs[0])).ToSql()
 
 	tests := []struct {
-		label                 string
+		name                  string
 		del_id                string
 		dbColumns             []string
 		dbRows                [][]driver.Value
@@ -5183,89 +7209,61 @@ func TestPgDeleteGroupMember(t *testing.T) {
 		expectedId            string
 		expectedError         error
 	}{{
-		dgrp1.Label, // label
-		strings.ToUpper(dgrp1.Members.IDs[0]),
-		columns,
-		[][]driver.Value{dval1},
-		nil,
-		regexp.QuoteMeta(dgrp1Query),
-		[]driver.Value{dgrp1.Label, groupNamespace},
-		nil,
-		regexp.QuoteMeta(dgrp1Update),
-		[]driver.Value{uuid1, dgrp1.Members.IDs[0]},
-		true,
-		dgrp1.Members.IDs[0],
-		nil,
-	}, {
-		strings.ToUpper(dgrp2.Label), // label
-		dgrp2.Members.IDs[1],
-		columns,
-		[][]driver.Value{dval2},
-		nil,
-		regexp.QuoteMeta(dgrp2Query),
-		[]driver.Value{dgrp2.Label, groupNamespace},
-		nil,
-		regexp.QuoteMeta(dgrp2Update),
-		[]driver.Value{uuid2, dgrp2.Members.IDs[1]},
-		true,
-		dgrp2.Members.IDs[1],
-		nil,
-	}, {
-		strings.ToUpper(dgrp3x.Label), // label
-		strings.ToUpper(dgrp3x.Members.IDs[0]),
+		dgrp5p.Name, // name
+		dgrp5p.Members.IDs[0],
 		columns,
-		[][]driver.Value{dval3},
+		[][]driver.Value{dval5},
 		nil,
-		regexp.QuoteMeta(dgrp3Query),
-		[]driver.Value{dgrp3x.Label, groupNamespace},
+		regexp.QuoteMeta(dgrp5Query),
+		[]driver.Value{dgrp5p.Name, partNamespace},
 		nil,
-		regexp.QuoteMeta(dgrp3Update),
-		[]driver.Value{uuid3, dgrp3x.Members.IDs[0]},
+		regexp.QuoteMeta(dgrp5Update),
+		[]driver.Value{uuid5, dgrp5p.Members.IDs[0]},
 		true,
-		dgrp3x.Members.IDs[0],
+		dgrp5p.Members.IDs[0],
 		nil,
 	}, {
-		dgrp4x.Label, // label
-		dgrp4x.Members.IDs[0],
+		dgrp6p.Name, // name
+		dgrp6p.Members.IDs[0],
 		columns,
-		[][]driver.Value{dval4},
+		[][]driver.Value{dval6},
 		nil,
-		regexp.QuoteMeta(dgrp4Query),
-		[]driver.Value{dgrp4x.Label, groupNamespace},
+		regexp.QuoteMeta(dgrp6Query),
+		[]driver.Value{dgrp6p.Name, partNamespace},
 		nil,
-		regexp.QuoteMeta(dgrp4Update),
-		[]driver.Value{uuid4, dgrp4x.Members.IDs[0]},
+		regexp.QuoteMeta(dgrp6Update),
+		[]driver.Value{uuid6, dgrp6p.Members.IDs[0]},
 		true,
-		dgrp4x.Members.IDs[0],
+		dgrp6p.Members.IDs[0],
 		nil,
 	}, {
-		dgrp4x.Label, // label
-		dgrp1.Members.IDs[1],
+		dgrp6p.Name, // name
+		dgrp6p.Members.IDs[1],
 		columns,
-		[][]driver.Value{dval4},
+		[][]driver.Value{dval6},
 		nil,
-		regexp.QuoteMeta(dgrp4Query),
-		[]driver.Value{dgrp4x.Label, groupNamespace},
+		regexp.QuoteMeta(dgrp6Query),
+		[]driver.Value{dgrp6p.Name, partNamespace},
 		nil,
-		regexp.QuoteMeta(dgrp4Update),
-		[]driver.Value{uuid4, dgrp1.Members.IDs[1]},
+		regexp.QuoteMeta(dgrp6Update),
+		[]driver.Value{uuid6, dgrp6p.Members.IDs[1]},
 		false,
 		"",
 		nil,
 	}, {
-		"badlabel", // label
-		dgrp4x.Members.IDs[0],
+		"badname", // name
+		dgrp6p.Members.IDs[0],
 		columns,
 		[][]driver.Value{},
 		nil,
-		regexp.QuoteMeta(dgrp4Query),
-		[]driver.Value{"badlabel", groupNamespace},
+		regexp.QuoteMeta(dgrp6Query),
+		[]driver.Value{"badname", partNamespace},
 		nil,
 		regexp.QuoteMeta(""),
-		[]driver.Value{uuid4, dgrp4x.Members.IDs[0]},
-		true,
-		dgrp4x.Members.IDs[0],
-		ErrHMSDSNoGroup,
+		[]driver.Value{},
+		false,
+		dgrp6p.Members.IDs[0],
+		ErrHMSDSNoPartition,
 	}}
 
 	for i, test := range tests {
@@ -5292,13 +7290,16 @@ func TestPgDeleteGroupMember(t *testing.T) {
 			mockPG.ExpectPrepare(test.expectedQueryPrepare).ExpectQuery().WithArgs(test.expectedQueryArgs...).WillReturnRows(rows)
 			if test.expectedDeleted {
 				mockPG.ExpectPrepare(test.expectedUpdatePrepare).ExpectExec().WithArgs(test.expectedUpdateArgs...).WillReturnResult(sqlmock.NewResult(0, 1))
+				if !sm.IsTopPartition(test.name) {
+					expectReturnToParent(true, test.del_id)
+				}
 			} else {
 				mockPG.ExpectPrepare(test.expectedUpdatePrepare).ExpectExec().WithArgs(test.expectedUpdateArgs...).WillReturnResult(sqlmock.NewResult(0, 0))
 			}
 			mockPG.ExpectCommit()
 		}
 
-		didDelete, err := dPG.DeleteGroupMember(test.label, test.del_id)
+		didDelete, err := dPG.DeletePartitionMember(test.name, test.del_id, "")
 		if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
 			t.Errorf("Test %v Failed: Sql expectations were not met: %s",
 				i, mock_err)
@@ -5318,82 +7319,247 @@ func TestPgDeleteGroupMember(t *testing.T) {
 	}
 }
 
-//
-// Partitions
-//
-
-func TestPgGetPartition(t *testing.T) {
-	columns := compGroupsColsSMPart // "id", "name", "description", "tags"
-
-	dval5 := []driver.Value{uuid5, dgrp5p.Name, dgrp5p.Description, pq.Array(&dgrp5p.Tags)}
-	dval6 := []driver.Value{uuid6, dgrp6p.Name, dgrp6p.Description, pq.Array(&dgrp6p.Tags)}
-
-	memberCols := []string{"component_id"}
-
-	sqq := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
-
-	dgrp5Query, _, _ := sqq.Select(compGroupsColsSMPart...).
-		From(compGroupsTable).
-		Where("name = ?", strings.ToLower(dgrp5p.Name)).
-		Where("namespace = ?", partNamespace).ToSql()
-
-	dgrp5MQuery, _, _ := sqq.Select(compGroupMembersColsUser...).
-		From(compGroupMembersTable).
-		Where("group_id = ?", uuid5).ToSql()
-
-	dgrp6Query, _, _ := sqq.Select(compGroupsColsSMPart...).
-		From(compGroupsTable).
-		Where("name = ?", strings.ToLower(dgrp6p.Name)).
-		Where("namespace = ?", partNamespace).ToSql()
-
-	dgrp6MQuery, _, _ := sqq.Select(compGroupMembersColsUser...).
-		From(compGroupMembersTable).
-		Where("group_id = ?", uuid6).ToSql()
-
+// Note: GetMembership is basically a special case where f contains ID={id}
+func TestPgGetMemberships(t *testing.T) {
 	tests := []struct {
-		name                  string
-		dbColumns             []string
-		dbRows                [][]driver.Value
-		dbQueryError          error
-		expectedQueryPrepare  string
-		expectedQueryArgs     []driver.Value
-		dbMColumns            []string
-		dbMRows               [][]driver.Value
-		dbMQueryError         error
-		expectedMQueryPrepare string
-		expectedMQueryArgs    []driver.Value
-		expPart               *sm.Partition
+		f               *ComponentFilter
+		dbColumns       []string
+		dbRows          [][]driver.Value
+		dbError         error
+		expectedPrepare string
+		expectedArgs    []driver.Value
+		expectedMembers []*sm.Membership
+		expectedError   error
 	}{{
-		dgrp5p.Name, // label
-		columns,
-		[][]driver.Value{dval5},
+		&ComponentFilter{
+			Type: []string{"node"},
+		},
+		append(compColsIdOnly, compGroupPartCols...),
+		[][]driver.Value{
+			[]driver.Value{"x0c0s14b0n0", "grp1", "group"},
+			[]driver.Value{"x0c0s24b0n0", "grp1", "group"},
+			[]driver.Value{"x0c0s24b0n0", "grp2", "group"},
+			[]driver.Value{"x1c0s0b0n0", "grp2", "group"},
+			[]driver.Value{"x1c0s0b0n0", "grp3", "group"},
+			[]driver.Value{"x1c0s0b0n0", "part1", "partition"},
+		},
+		nil,
+		regexp.QuoteMeta(tGetMembershipsBaseQuery + tGetCompJoinGroupsQuery + " WHERE c.type IN ($1)"),
+		[]driver.Value{"Node"},
+		[]*sm.Membership{
+			&sm.Membership{ID: "x0c0s14b0n0", GroupLabels: []string{"grp1"}},
+			&sm.Membership{ID: "x0c0s24b0n0", GroupLabels: []string{"grp1", "grp2"}},
+			&sm.Membership{ID: "x1c0s0b0n0", GroupLabels: []string{"grp2", "grp3"}, PartitionName: "part1"},
+		},
+		nil,
+	}, {
+		&ComponentFilter{
+			Type: []string{},
+		},
+		append(compColsIdOnly, compGroupPartCols...),
+		[][]driver.Value{
+			[]driver.Value{"x0c0s14b0n0", nil, nil},
+			[]driver.Value{"x0c0s24b0n0", "grp1", "group"},
+			[]driver.Value{"x0c0s24b0n0", "grp2", "group"},
+			[]driver.Value{"x1c0s0b0n0", "grp2", "group"},
+			[]driver.Value{"x1c0s0b0n0", "grp3", "group"},
+			[]driver.Value{"x1c0s0b0n0", "part1", "partition"},
+		},
+		nil,
+		regexp.QuoteMeta(tGetMembershipsBaseQuery + tGetCompJoinGroupsQuery),
+		[]driver.Value{},
+		[]*sm.Membership{
+			&sm.Membership{ID: "x0c0s14b0n0", GroupLabels: []string{}},
+			&sm.Membership{ID: "x0c0s24b0n0", GroupLabels: []string{"grp1", "grp2"}},
+			&sm.Membership{ID: "x1c0s0b0n0", GroupLabels: []string{"grp2", "grp3"}, PartitionName: "part1"},
+		},
+		nil,
+	}, {
+		&ComponentFilter{
+			ID: []string{"x1c0s0b0n0"}, // Same as GetMembership
+		},
+		append(compColsIdOnly, compGroupPartCols...),
+		[][]driver.Value{
+			[]driver.Value{"x1c0s0b0n0", "grp2", "group"},
+			[]driver.Value{"x1c0s0b0n0", "grp3", "group"},
+			[]driver.Value{"x1c0s0b0n0", "part1", "partition"},
+		},
+		nil,
+		regexp.QuoteMeta(tGetMembershipsBaseQuery + tGetCompJoinGroupsQuery + " WHERE c.id IN ($1)"),
+		[]driver.Value{"x1c0s0b0n0"},
+		[]*sm.Membership{
+			&sm.Membership{ID: "x1c0s0b0n0", GroupLabels: []string{"grp2", "grp3"}, PartitionName: "part1"},
+		},
+		nil,
+	}, {
+		&ComponentFilter{
+			NID:      []string{"800"},
+			NIDStart: []string{"16300", "440"},
+			NIDEnd:   []string{"16500", "480"},
+		},
+		append(compColsIdOnly, compGroupPartCols...),
+		[][]driver.Value{
+			[]driver.Value{"x0c0s14b0n0", "grp1", "group"},
+			[]driver.Value{"x0c0s24b0n0", "grp1", "group"},
+			[]driver.Value{"x0c0s24b0n0", "grp2", "group"},
+			[]driver.Value{"x1c0s0b0n0", "grp2", "group"},
+			[]driver.Value{"x1c0s0b0n0", "grp3", "group"},
+			[]driver.Value{"x1c0s0b0n0", "part1", "partition"},
+		},
+		nil,
+		regexp.QuoteMeta(tGetMembershipsBaseQuery + tGetCompJoinGroupsQuery + " WHERE ((c.nid >= $1 AND c.nid <= $2) OR (c.nid >= $3 AND c.nid <= $4) OR c.nid = $5)"),
+		[]driver.Value{"16300", "16500", "440", "480", "800"},
+		[]*sm.Membership{
+			&sm.Membership{ID: "x0c0s14b0n0", GroupLabels: []string{"grp1"}},
+			&sm.Membership{ID: "x0c0s24b0n0", GroupLabels: []string{"grp1", "grp2"}},
+			&sm.Membership{ID: "x1c0s0b0n0", GroupLabels: []string{"grp2", "grp3"}, PartitionName: "part1"},
+		},
+		nil,
+	}, {
+		&ComponentFilter{
+			Group: []string{"grp2", "grp3"},
+		},
+		append(compColsIdOnly, compGroupPartCols...),
+		[][]driver.Value{
+			[]driver.Value{"x0c0s24b0n0", "grp1", "group"},
+			[]driver.Value{"x0c0s24b0n0", "grp2", "group"},
+			[]driver.Value{"x1c0s0b0n0", "grp2", "group"},
+			[]driver.Value{"x1c0s0b0n0", "grp3", "group"},
+			[]driver.Value{"x1c0s0b0n0", "part1", "partition"},
+		},
+		nil,
+		regexp.QuoteMeta(tGetMembershipsBaseQueryWSub + tGetMembershipsSubQuery +
+			tGetCompJoinGroupsQuery +
+			" WHERE (cg.name IN ($1,$2) AND cg.namespace = $3)" +
+			tGetMembershipsBaseSuffixWSub),
+		[]driver.Value{"grp2", "grp3", "group"},
+		[]*sm.Membership{
+			&sm.Membership{ID: "x0c0s24b0n0", GroupLabels: []string{"grp1", "grp2"}},
+			&sm.Membership{ID: "x1c0s0b0n0", GroupLabels: []string{"grp2", "grp3"}, PartitionName: "part1"},
+		},
+		nil,
+	}, {
+		&ComponentFilter{
+			Group:     []string{"grp2"},
+			Partition: []string{"part1"},
+		},
+		append(compColsIdOnly, compGroupPartCols...),
+		[][]driver.Value{
+			[]driver.Value{"x1c0s0b0n0", "grp2", "group"},
+			[]driver.Value{"x1c0s0b0n0", "grp3", "group"},
+			[]driver.Value{"x1c0s0b0n0", "part1", "partition"},
+		},
+		nil,
+		regexp.QuoteMeta(tGetMembershipsBaseQueryWSub + tGetMembershipsSubQuery +
+			tGetCompJoinGroupsQuery +
+			" WHERE ((cg.name IN ($1) AND cg.namespace = $2) OR (cg.name IN ($3) AND cg.namespace = $4))" +
+			tGetMembershipsGroupSuffixWSub),
+		[]driver.Value{"grp2", "group", "part1", "partition"},
+		[]*sm.Membership{
+			&sm.Membership{ID: "x1c0s0b0n0", GroupLabels: []string{"grp2", "grp3"}, PartitionName: "part1"},
+		},
+		nil,
+	}, {
+		&ComponentFilter{
+			Group:     []string{"NULL"},
+			Partition: []string{"NULL"},
+		},
+		append(compColsIdOnly, compGroupPartCols...),
+		[][]driver.Value{
+			[]driver.Value{"x0c0s14b0n0", nil, nil},
+		},
+		nil,
+		regexp.QuoteMeta(tGetMembershipsBaseQueryWSub + tGetMembershipsSubQuery +
+			tGetCompJoinGroupsQuery +
+			" WHERE c" + compGroupMembersNsColAlias + " IS NULL" +
+			tGetMembershipsBaseSuffixWSub),
+		[]driver.Value{},
+		[]*sm.Membership{
+			&sm.Membership{ID: "x0c0s14b0n0", GroupLabels: []string{}},
+		},
+		nil,
+	}, {
+		&ComponentFilter{
+			Group:     []string{"NULL"},
+			Partition: []string{"part1"},
+		},
+		append(compColsIdOnly, compGroupPartCols...),
+		[][]driver.Value{},
 		nil,
-		regexp.QuoteMeta(dgrp5Query),
-		[]driver.Value{dgrp5p.Name, partNamespace},
-		memberCols,
-		[][]driver.Value{
-			dgrp5c1,
+		regexp.QuoteMeta(""),
+		[]driver.Value{},
+		[]*sm.Membership{},
+		ErrHMSDSNullGroupBadPart,
+	}, {
+		&ComponentFilter{
+			Group:     []string{"grp1"},
+			Partition: []string{"NULL"},
 		},
+		append(compColsIdOnly, compGroupPartCols...),
+		[][]driver.Value{},
 		nil,
-		regexp.QuoteMeta(dgrp5MQuery),
-		[]driver.Value{uuid5},
-		dgrp5p,
+		regexp.QuoteMeta(""),
+		[]driver.Value{},
+		[]*sm.Membership{},
+		ErrHMSDSNullPartBadGroup,
 	}, {
-		dgrp6p.Name, // label
-		columns,
-		[][]driver.Value{dval6},
+		&ComponentFilter{
+			Group: []string{"NULL", "grp1", "grp2"},
+		},
+		append(compColsIdOnly, compGroupPartCols...),
+		[][]driver.Value{},
 		nil,
-		regexp.QuoteMeta(dgrp6Query),
-		[]driver.Value{dgrp6p.Name, partNamespace},
-		memberCols,
-		[][]driver.Value{
-			dgrp6c1,
-			dgrp6c2,
+		regexp.QuoteMeta(""),
+		[]driver.Value{},
+		[]*sm.Membership{},
+		ErrHMSDSNullBadMixGroup,
+	}, {
+		&ComponentFilter{
+			Partition: []string{"p1", "NULL", "p2"},
 		},
+		append(compColsIdOnly, compGroupPartCols...),
+		[][]driver.Value{},
 		nil,
-		regexp.QuoteMeta(dgrp6MQuery),
-		[]driver.Value{uuid6},
-		dgrp6p,
+		regexp.QuoteMeta(""),
+		[]driver.Value{},
+		[]*sm.Membership{},
+		ErrHMSDSNullBadMixPart,
+	}, {
+		&ComponentFilter{
+			Group:     []string{"grp1", "grp2"},
+			Partition: []string{"part1"},
+		},
+		append(compColsIdOnly, compGroupPartCols...),
+		[][]driver.Value{},
+		nil,
+		regexp.QuoteMeta(""),
+		[]driver.Value{},
+		[]*sm.Membership{},
+		ErrHMSDSMultipleGroupAndPart,
+	}, {
+		&ComponentFilter{
+			Group:     []string{"grp1"},
+			Partition: []string{"part1", "part2"},
+		},
+		append(compColsIdOnly, compGroupPartCols...),
+		[][]driver.Value{},
+		nil,
+		regexp.QuoteMeta(""),
+		[]driver.Value{},
+		[]*sm.Membership{},
+		ErrHMSDSMultipleGroupAndPart,
+	}, {
+		&ComponentFilter{
+			Group:     []string{"grp1"},
+			Partition: []string{"part$^#$^#$@1"}, // invalid part
+		},
+		append(compColsIdOnly, compGroupPartCols...),
+		[][]driver.Value{},
+		nil,
+		regexp.QuoteMeta(""),
+		[]driver.Value{},
+		[]*sm.Membership{},
+		ErrHMSDSNoPartition,
 	}}
 
 	for i, test := range tests {
@@ -5403,1124 +7569,781 @@ func TestPgGetPartition(t *testing.T) {
 		for _, row := range test.dbRows {
 			rows.AddRow(row...)
 		}
-		mrows := sqlmock.NewRows(test.dbMColumns)
-		for _, mrow := range test.dbMRows {
-			mrows.AddRow(mrow...)
+		if expectsGroupResolve(test.f) {
+			expectGroupResolve()
 		}
-
-		mockPG.ExpectBegin()
-		if test.expectedQueryPrepare == "" && test.dbQueryError != nil {
-			mockPG.ExpectRollback()
-		} else if test.dbQueryError != nil {
-			mockPG.ExpectPrepare(test.expectedQueryPrepare).ExpectQuery().WillReturnError(test.dbQueryError)
-			mockPG.ExpectRollback()
-		} else if test.dbMQueryError != nil {
-			mockPG.ExpectPrepare(test.expectedQueryPrepare).ExpectQuery().WithArgs(test.expectedQueryArgs...).WillReturnRows(rows)
-			mockPG.ExpectPrepare(test.expectedMQueryPrepare).ExpectExec().WillReturnError(test.dbMQueryError)
-			mockPG.ExpectRollback()
-		} else {
-			mockPG.ExpectPrepare(test.expectedQueryPrepare).ExpectQuery().WithArgs(test.expectedQueryArgs...).WillReturnRows(rows)
-			mockPG.ExpectPrepare(test.expectedMQueryPrepare).ExpectQuery().WithArgs(test.expectedMQueryArgs...).WillReturnRows(mrows)
-			mockPG.ExpectCommit()
+		if test.dbError != nil {
+			mockPG.ExpectPrepare(test.expectedPrepare).ExpectQuery().WillReturnError(test.dbError)
+		} else if test.expectedError == nil {
+			if len(test.dbColumns) == 0 {
+				mockPG.ExpectPrepare(test.expectedPrepare).ExpectQuery()
+			} else if test.expectedArgs != nil {
+				mockPG.ExpectPrepare(test.expectedPrepare).ExpectQuery().WithArgs(test.expectedArgs...).WillReturnRows(rows)
+			} else {
+				mockPG.ExpectPrepare(test.expectedPrepare).ExpectQuery().WillReturnRows(rows)
+			}
+			if len(test.dbRows) > 0 {
+				// Dynamic and nested group memberships are added
+				// afterwards.
+				expectGroupResolve()
+			}
 		}
-
-		p, err := dPG.GetPartition(test.name)
-		// ensure all expectations have been met
+		mbs, err := dPG.GetMemberships(test.f)
 		if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
 			t.Errorf("Test %v Failed: Sql expectations were not met: %s", i, mock_err)
 		}
-		//if test.dbQueryError == nil && test.dbUpdateError == nil {
-		if test.dbQueryError == nil && test.dbMQueryError == nil {
+		// Note if dbError is != nil test.expectedError should be non-nil also
+		if test.dbError == nil && test.expectedError == nil {
 			if err != nil {
 				t.Errorf("Test %v Failed: Unexpected error received: %s", i, err)
-			}
-			if p == nil {
-				t.Errorf("Test %v Failed: Expected non-nil group", i)
+			} else if len(mbs) != len(test.expectedMembers) {
+				t.Errorf("Test %v Failed: Expected members '%v'; Got members '%v'",
+					i, test.expectedMembers, mbs)
 			} else {
-				if p.Name != test.expPart.Name {
-					t.Errorf("Test %v Failed: Expected name %s (got %s)",
-						i, p.Name, test.expPart.Name)
-				}
-				if p.Description != test.expPart.Description {
-					t.Errorf("Test %v Failed: Expected description %s (got %s)",
-						i, p.Description, test.expPart.Description)
-				}
-				if !reflect.DeepEqual(p.Tags, test.expPart.Tags) {
-					t.Errorf("Test %v Failed: Expected Tags %s (got %s)",
-						i, p.Tags, test.expPart.Tags)
-				}
-				if !reflect.DeepEqual(p.Members.IDs, test.expPart.Members.IDs) {
-					t.Errorf("Test %v Failed: Expected Members %s (got %s)",
-						i, p.Members.IDs, test.expPart.Members.IDs)
+				for _, em := range test.expectedMembers {
+					found := false
+					for _, m := range mbs {
+						if reflect.DeepEqual(*m, *em) {
+							found = true
+							break
+						}
+					}
+					if found != true {
+						t.Errorf("Test %v Failed: Expected member '%v'", i, *em)
+					}
 				}
 			}
-		} else if err == nil {
-			t.Errorf("Test %v Failed: Expected an error.", i)
+		} else if err != test.expectedError {
+			t.Errorf("Test %v Failed: Expected error '%s', got '%s'",
+				i, err, test.expectedError)
 		}
 	}
 }
 
-func TestPgInsertPartition(t *testing.T) {
-	dval5 := []driver.Value{AnyUUID{}, dgrp5p.Name, dgrp5p.Description, pq.Array(&dgrp5p.Tags), partType, partNamespace, ""}
-	dval6 := []driver.Value{AnyUUID{}, dgrp6p.Name, dgrp6p.Description, pq.Array(&dgrp6p.Tags), partType, partNamespace, ""}
+////////////////////////////////////////////////////////////////////////////
+//
+// Component Lock Management
+//
+////////////////////////////////////////////////////////////////////////////
 
-	sqq := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+// Define UUID dummy type and implement sqlmocj Match interface.
+type AnyTime struct{}
 
-	// Note we only use the query here so the args values don't really matter.
-	dgrp5UpdateGrp, _, _ := sqq.Insert(compGroupsTable).
-		Columns(compGroupsColsAll7...).
-		Values(sq.Expr("?", uuid5), dgrp5p.Name, dgrp5p.Description,
-			pq.Array(&dgrp5p.Tags), partType, partNamespace, "").ToSql()
+func (a AnyTime) Match(v driver.Value) bool {
+	if _, ok := v.(string); ok {
+		return true
+	} else if _, ok := v.(time.Time); ok {
+		return true
+	} else if _, ok := v.(sql.NullTime); ok {
+		return true
+	} else {
+		return false
+	}
+}
 
-	dgrp5Update, _, _ := sqq.Insert(compGroupMembersTable).
-		Columns(compGroupMembersColsNoTS...).
-		Values(dgrp5p.Members.IDs[0], sq.Expr("?", uuid5),
-			partGroupNamespace).ToSql()
+type AnyString struct{}
 
-	dgrp6UpdateGrp, _, _ := sqq.Insert(compGroupsTable).
-		Columns(compGroupsColsAll7...).
-		Values(sq.Expr("?", uuid6), dgrp6p.Name, dgrp6p.Description,
-			pq.Array(&dgrp6p.Tags), partType, partNamespace, "").ToSql()
+func (a AnyString) Match(v driver.Value) bool {
+	if v == nil {
+		return true
+	} else if _, ok := v.(string); ok {
+		return true
+	} else if _, ok := v.(sql.NullString); ok {
+		return true
+	} else {
+		return false
+	}
+}
 
-	dgrp6Update, _, _ := sqq.Insert(compGroupMembersTable).
-		Columns(compGroupMembersColsNoTS...).
-		Values(dgrp6p.Members.IDs[0], sq.Expr("?", uuid6),
-			partGroupNamespace).
-		Values(dgrp6p.Members.IDs[1], sq.Expr("?", uuid6),
-			partGroupNamespace).ToSql()
+func TestPgInsertCompReservations(t *testing.T) {
+	resInsert := compReservation{
+		component_id: "x3000c0s9b0n0",
+		create_timestamp: sql.NullTime{
+			Valid: true,
+			Time:  time.Now(),
+		},
+		expiration_timestamp: sql.NullTime{
+			Valid: true,
+			Time:  time.Now(),
+		},
+		deputy_key:      "x3000c0s9b0n0:dk:de1a20c2-efc9-41ad-b839-1e3cef197d17",
+		reservation_key: "x3000c0s9b0n0:rk:cbff2077-952f-4536-a102-c442227fdc5d",
+	}
+
+	sqq := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+
+	// Note we only use the query here so the args values don't really matter.
+	resInsertReservation, _, _ := sqq.Insert(compResTable).
+		Columns(compResCols...).
+		Values(resInsert.component_id,
+			sq.Expr("?", resInsert.create_timestamp),
+			sq.Expr("?", resInsert.expiration_timestamp),
+			sq.Expr("?", resInsert.deputy_key),
+			sq.Expr("?", resInsert.reservation_key)).
+		Suffix("ON CONFLICT DO NOTHING RETURNING " + compResCompIdCol + ", " + compResDKCol + ", " + compResRKCol).ToSql()
 
 	tests := []struct {
-		part                     *sm.Partition
-		dbUpdateGrpError         error
-		expectedUpdateGrpPrepare string
-		expectedUpdateGrpArgs    []driver.Value
-		dbUpdateError            error
-		expectedUpdatePrepare    string
-		expectedUpdateArgs       []driver.Value
-		expectedMembers          int64
-		expectedName             string
+		f                         sm.CompLockV2Filter
+		dbGetCompIDsError         error
+		expectedGetCompIDsPrepare string
+		expectedGetCompIDsArgs    []driver.Value
+		dbGetCompIDsReturnCols    []string
+		dbGetCompIDsReturnRows    [][]driver.Value
+		dbInsertError             error
+		expectedInsertPrepare     string
+		expectedInsertArgs        []driver.Value
+		dbInsertV2ResReturnCols   []string
+		dbInsertV2ResReturnRows   [][]driver.Value
+		expectedSuccess           int
+		expectedFailure           int
+		expectErr                 bool
 	}{{
-		dgrp5p,
-		nil,
-		regexp.QuoteMeta(dgrp5UpdateGrp),
-		dval5,
-		nil,
-		regexp.QuoteMeta(dgrp5Update),
-		[]driver.Value{dgrp5p.Members.IDs[0], AnyUUID{}, partGroupNamespace},
-		int64(len(dgrp5p.Members.IDs)),
-		strings.ToLower(dgrp5p.Name), // Name
+		f: sm.CompLockV2Filter{
+			ID:                  []string{"x3000c0s9b0n0"},
+			ProcessingModel:     sm.CLProcessingModelRigid,
+			ReservationDuration: 1,
+		},
+		dbGetCompIDsError:         nil,
+		expectedGetCompIDsPrepare: regexp.QuoteMeta(tGetCompBaseQuery + " WHERE c.id IN ($1)"),
+		expectedGetCompIDsArgs:    []driver.Value{"x3000c0s9b0n0"},
+		dbGetCompIDsReturnCols:    []string{"id", "type", "state", "flag", "enabled", "admin", "role", "subrole", "nid", "subtype", "nettype", "arch", "class", "reservation_disabled", "locked"},
+		dbGetCompIDsReturnRows:    [][]driver.Value{
+			[]driver.Value{"x3000c0s9b0n0", "Node", "Ready", "OK", true, "", "Compute", "", 42, "", "Sling", "X86", "Mountain", false, false},
+		},
+		dbInsertError:           nil,
+		expectedInsertPrepare:   regexp.QuoteMeta(resInsertReservation),
+		expectedInsertArgs:      []driver.Value{"x3000c0s9b0n0", AnyTime{}, AnyTime{}, AnyUUID{}, AnyUUID{}},
+		dbInsertV2ResReturnCols: []string{"component_id", "deputy_key", "reservation_key"},
+		dbInsertV2ResReturnRows: [][]driver.Value{
+			[]driver.Value{"x3000c0s9b0n0", "x3000c0s9b0n0:dk:de1a20c2-efc9-41ad-b839-1e3cef197d17", "x3000c0s9b0n0:rk:cbff2077-952f-4536-a102-c442227fdc5d"},
+		},
+		expectedSuccess:       1,
+		expectedFailure:       0,
+		expectErr:             false,
 	}, {
-		dgrp6p,
-		nil,
-		regexp.QuoteMeta(dgrp6UpdateGrp),
-		dval6,
-		nil,
-		regexp.QuoteMeta(dgrp6Update),
-		[]driver.Value{dgrp6p.Members.IDs[0], AnyUUID{}, partGroupNamespace,
-			dgrp6p.Members.IDs[1], AnyUUID{}, partGroupNamespace},
-		int64(len(dgrp6p.Members.IDs)),
-		strings.ToLower(dgrp6p.Name),
+		f: sm.CompLockV2Filter{
+			ID:                  []string{"x3000c0s9b0n0"},
+			ProcessingModel:     sm.CLProcessingModelRigid,
+			ReservationDuration: 1,
+		},
+		dbGetCompIDsError:         nil,
+		expectedGetCompIDsPrepare: regexp.QuoteMeta(tGetCompBaseQuery + " WHERE c.id IN ($1)"),
+		expectedGetCompIDsArgs:    []driver.Value{"x3000c0s9b0n0"},
+		dbGetCompIDsReturnCols:    []string{"id", "type", "state", "flag", "enabled", "admin", "role", "subrole", "nid", "subtype", "nettype", "arch", "class", "reservation_disabled", "locked"},
+		dbGetCompIDsReturnRows:    [][]driver.Value{
+			[]driver.Value{"x3000c0s9b0n0", "Node", "Ready", "OK", true, "", "Compute", "", 42, "", "Sling", "X86", "Mountain", false, true},
+		},
+		dbInsertError:           nil,
+		expectedInsertPrepare:   "",
+		expectedInsertArgs:      []driver.Value{},
+		dbInsertV2ResReturnCols: []string{},
+		dbInsertV2ResReturnRows: [][]driver.Value{},
+		expectedSuccess:         0,
+		expectedFailure:         0,
+		expectErr:               true,
+	}, {
+		f: sm.CompLockV2Filter{
+			ID:              []string{"x3000c0s9b0n0"},
+			ProcessingModel: sm.CLProcessingModelRigid,
+		},
+		dbGetCompIDsError:         nil,
+		expectedGetCompIDsPrepare: regexp.QuoteMeta(tGetCompBaseQuery + " WHERE c.id IN ($1)"),
+		expectedGetCompIDsArgs:    []driver.Value{"x3000c0s9b0n0"},
+		dbGetCompIDsReturnCols:    []string{"id", "type", "state", "flag", "enabled", "admin", "role", "subrole", "nid", "subtype", "nettype", "arch", "class", "reservation_disabled", "locked"},
+		dbGetCompIDsReturnRows:    [][]driver.Value{
+			[]driver.Value{"x3000c0s9b0n0", "Node", "Ready", "OK", true, "", "Compute", "", 42, "", "Sling", "X86", "Mountain", false, false},
+		},
+		dbInsertError:           nil,
+		expectedInsertPrepare:   "",
+		expectedInsertArgs:      []driver.Value{},
+		dbInsertV2ResReturnCols: []string{},
+		dbInsertV2ResReturnRows: [][]driver.Value{},
+		expectedSuccess:         0,
+		expectedFailure:         0,
+		expectErr:               true,
+	}, {
+		f: sm.CompLockV2Filter{
+			ID:              []string{"x3000c0s9b0n0"},
+			ProcessingModel: sm.CLProcessingModelRigid,
+		},
+		dbGetCompIDsError:         nil,
+		expectedGetCompIDsPrepare: regexp.QuoteMeta(tGetCompBaseQuery + " WHERE c.id IN ($1)"),
+		expectedGetCompIDsArgs:    []driver.Value{"x3000c0s9b0n0"},
+		dbGetCompIDsReturnCols:    []string{"id", "type", "state", "flag", "enabled", "admin", "role", "subrole", "nid", "subtype", "nettype", "arch", "class", "reservation_disabled", "locked"},
+		dbGetCompIDsReturnRows:    [][]driver.Value{
+			[]driver.Value{"x3000c0s9b0n0", "Node", "Ready", "OK", true, "", "Compute", "", 42, "", "Sling", "X86", "Mountain", true, false},
+		},
+		dbInsertError:           nil,
+		expectedInsertPrepare:   "",
+		expectedInsertArgs:      []driver.Value{},
+		dbInsertV2ResReturnCols: []string{},
+		dbInsertV2ResReturnRows: [][]driver.Value{},
+		expectedSuccess:         0,
+		expectedFailure:         0,
+		expectErr:               true,
 	}}
 
 	for i, test := range tests {
 		ResetMockDB()
+		rows := sqlmock.NewRows(test.dbGetCompIDsReturnCols)
+		for _, row := range test.dbGetCompIDsReturnRows {
+			rows.AddRow(row...)
+		}
+		v2rows := sqlmock.NewRows(test.dbInsertV2ResReturnCols)
+		for _, row := range test.dbInsertV2ResReturnRows {
+			v2rows.AddRow(row...)
+		}
+
 		mockPG.ExpectBegin()
-		if test.expectedUpdateGrpPrepare == "" && test.dbUpdateGrpError != nil {
+		if test.expectedGetCompIDsPrepare == "" && test.dbGetCompIDsError == nil {
 			mockPG.ExpectRollback()
-		} else if test.dbUpdateGrpError != nil {
-			mockPG.ExpectPrepare(test.expectedUpdateGrpPrepare).ExpectQuery().WillReturnError(test.dbUpdateGrpError)
+		} else if test.dbGetCompIDsError != nil {
+			mockPG.ExpectPrepare(test.expectedGetCompIDsPrepare).ExpectQuery().WillReturnError(test.dbGetCompIDsError)
 			mockPG.ExpectRollback()
-		} else if test.dbUpdateError != nil {
-			mockPG.ExpectPrepare(test.expectedUpdateGrpPrepare).ExpectExec().WithArgs(test.expectedUpdateGrpArgs...).WillReturnResult(sqlmock.NewResult(0, 1))
-			mockPG.ExpectPrepare(test.expectedUpdatePrepare).ExpectExec().WillReturnError(test.dbUpdateError)
+		} else if test.expectedInsertPrepare == "" && test.dbInsertError == nil {
+			mockPG.ExpectPrepare(test.expectedGetCompIDsPrepare).ExpectQuery().WithArgs(test.expectedGetCompIDsArgs...).WillReturnRows(rows)
+			mockPG.ExpectRollback()
+		} else if test.dbInsertError != nil {
+			mockPG.ExpectPrepare(test.expectedGetCompIDsPrepare).ExpectQuery().WithArgs(test.expectedGetCompIDsArgs...).WillReturnRows(rows)
+			mockPG.ExpectPrepare(test.expectedInsertPrepare).ExpectQuery().WillReturnError(test.dbInsertError)
 			mockPG.ExpectRollback()
 		} else {
-			mockPG.ExpectPrepare(test.expectedUpdateGrpPrepare).ExpectExec().WithArgs(test.expectedUpdateGrpArgs...).WillReturnResult(sqlmock.NewResult(0, 1))
-			mockPG.ExpectPrepare(test.expectedUpdatePrepare).ExpectExec().WithArgs(test.expectedUpdateArgs...).WillReturnResult(sqlmock.NewResult(0, test.expectedMembers))
+			mockPG.ExpectPrepare(test.expectedGetCompIDsPrepare).ExpectQuery().WithArgs(test.expectedGetCompIDsArgs...).WillReturnRows(rows)
+			mockPG.ExpectPrepare(test.expectedInsertPrepare).ExpectQuery().WithArgs(test.expectedInsertArgs...).WillReturnRows(v2rows)
 			mockPG.ExpectCommit()
 		}
 
-		name, err := dPG.InsertPartition(test.part)
+		results, err := dPG.InsertCompReservations(test.f)
 		// ensure all expectations have been met
 		if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
 			t.Errorf("Test %v Failed: Sql expectations were not met: %s",
 				i, mock_err)
 		}
-		if test.dbUpdateGrpError == nil && test.dbUpdateError == nil {
+		if !test.expectErr {
 			if err != nil {
 				t.Errorf("Test %v Failed: Unexpected error received: %s", i, err)
-			}
-			if name == "" {
-				t.Errorf("Test %v Failed: Expected non-empty label", i)
 			} else {
-				if name != test.expectedName {
-					t.Errorf("Test %v Failed: Expected label %s (got %s)",
-						i, name, test.expectedName)
+				if len(results.Success) != test.expectedSuccess {
+					t.Errorf("Test %v Failed: Expected %v Successes. Got %v", i, test.expectedSuccess, len(results.Success))
+				} else if len(results.Failure) != test.expectedFailure {
+					t.Errorf("Test %v Failed: Expected %v Failures. Got %v", i, test.expectedFailure, len(results.Failure))
 				}
-			}
-		} else if err == nil {
-			t.Errorf("Test %v Failed: Expected an error.", i)
-		}
-	}
-}
-
-func TestPgUpdatePartition(t *testing.T) {
-	newDescription := "newDescription" // shouldn't match any existing desc
-
-	columns := compGroupsColsSMPart // "id", "name", "description", "tags"
-
-	dval5 := []driver.Value{uuid5, dgrp5p.Name, dgrp5p.Description, pq.Array(&dgrp5p.Tags)}
-	dval6 := []driver.Value{uuid6, dgrp6p.Name, dgrp5p.Description, pq.Array(&dgrp6p.Tags)}
-
-	sqq := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
-
-	dgrp5Query, _, _ := sqq.Select(compGroupsColsSMPart...).
-		From(compGroupsTable).
-		Where("name = ?", strings.ToLower(dgrp5p.Name)).
-		Where("namespace = ?", partNamespace).ToSql()
-
-	dgrp5Update, _, _ := sqq.Update("").Table(compGroupsTable).
-		Where("id = ?", uuid5).
-		Set(compGroupDescCol, newDescription).
-		Set(compGroupTagsCol, pq.Array(&dgrp6p.Tags)).ToSql()
-
-	dgrp6Query, _, _ := sqq.Select(compGroupsColsSMPart...).
-		From(compGroupsTable).
-		Where("name = ?", strings.ToLower(dgrp6p.Name)).
-		Where("namespace = ?", partNamespace).ToSql()
-
-	dgrp6Update, _, _ := sqq.Update("").Table(compGroupsTable).
-		Where("id = ?", uuid6).
-		Set(compGroupTagsCol, pq.Array(&dgrp5p.Tags)).ToSql()
-
-	tests := []struct {
-		label                 string
-		gp                    *sm.PartitionPatch
-		dbColumns             []string
-		dbRows                [][]driver.Value
-		dbQueryError          error
-		expectedQueryPrepare  string
-		expectedQueryArgs     []driver.Value
-		dbUpdateError         error
-		expectedUpdatePrepare string
-		expectedUpdateArgs    []driver.Value
-		expectedError         error
-	}{{
-		dgrp5p.Name,
-		&sm.PartitionPatch{Description: &newDescription, Tags: &dgrp6p.Tags},
-		columns,
-		[][]driver.Value{dval5},
-		nil,
-		regexp.QuoteMeta(dgrp5Query),
-		[]driver.Value{dgrp5p.Name, partNamespace},
-		nil,
-		regexp.QuoteMeta(dgrp5Update),
-		[]driver.Value{newDescription, pq.Array(&dgrp6p.Tags), uuid5},
-		nil,
-	}, {
-		strings.ToUpper(dgrp5p.Name),
-		&sm.PartitionPatch{}, // No updates - do nothing
-		columns,
-		[][]driver.Value{dval5},
-		nil,
-		regexp.QuoteMeta(dgrp5Query),
-		[]driver.Value{dgrp5p.Name, partNamespace},
-		nil,
-		regexp.QuoteMeta(""),
-		[]driver.Value{},
-		nil,
-	}, {
-		strings.ToUpper(dgrp5p.Name), // No update- should already match
-		&sm.PartitionPatch{Description: &dgrp5p.Description, Tags: &dgrp5p.Tags},
-		columns,
-		[][]driver.Value{dval5},
-		nil,
-		regexp.QuoteMeta(dgrp5Query),
-		[]driver.Value{dgrp5p.Name, partNamespace},
-		nil,
-		regexp.QuoteMeta(""),
-		[]driver.Value{},
-		nil,
-	}, {
-		dgrp6p.Name, // Just tags are different, not description.
-		&sm.PartitionPatch{Tags: &dgrp5p.Tags},
-		columns,
-		[][]driver.Value{dval6},
-		nil,
-		regexp.QuoteMeta(dgrp6Query),
-		[]driver.Value{dgrp6p.Name, partNamespace},
-		nil,
-		regexp.QuoteMeta(dgrp6Update),
-		[]driver.Value{pq.Array(&dgrp5p.Tags), uuid6},
-		nil,
-	}, {
-		dgrp6p.Name, // Unexpected DB error during update.
-		&sm.PartitionPatch{Tags: &dgrp5p.Tags},
-		columns,
-		[][]driver.Value{dval6},
-		nil,
-		regexp.QuoteMeta(dgrp6Query),
-		[]driver.Value{dgrp6p.Name, partNamespace},
-		sql.ErrNoRows,
-		regexp.QuoteMeta(dgrp6Update),
-		[]driver.Value{pq.Array(&dgrp5p.Tags), uuid6},
-		sql.ErrNoRows,
+			}
+		} else if err == nil {
+			t.Errorf("Test %v Failed: Expected an error.", i)
+		}
+	}
+}
+
+func TestPgDeleteCompReservationsForce(t *testing.T) {
+	res := compReservation{
+		component_id: "x3000c0s9b0n0",
+		create_timestamp: sql.NullTime{
+			Valid: true,
+			Time:  time.Now(),
+		},
+		expiration_timestamp: sql.NullTime{
+			Valid: true,
+			Time:  time.Now(),
+		},
+		deputy_key:      "x3000c0s9b0n0:dk:de1a20c2-efc9-41ad-b839-1e3cef197d17",
+		reservation_key: "x3000c0s9b0n0:rk:cbff2077-952f-4536-a102-c442227fdc5d",
+	}
+
+	sqq := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+
+	// Note we only use the query here so the args values don't really matter.
+	resDeleteReservation, _, _ := sqq.Delete(compResTable).
+		Where(sq.Eq{compResCompIdCol: []string{res.component_id}}).
+		Suffix("RETURNING " + compResCompIdCol).ToSql()
+
+	tests := []struct {
+		f                         sm.CompLockV2Filter
+		dbGetCompIDsError         error
+		expectedGetCompIDsPrepare string
+		expectedGetCompIDsArgs    []driver.Value
+		dbGetCompIDsReturnCols    []string
+		dbGetCompIDsReturnRows    [][]driver.Value
+		dbDeleteError             error
+		expectedDeletePrepare     string
+		expectedDeleteArgs        []driver.Value
+		dbDeleteReturnCols        []string
+		dbDeleteReturnRows        [][]driver.Value
+		expectedSuccess           int
+		expectedFailure           int
+		expectErr                 bool
+	}{{
+		f: sm.CompLockV2Filter{
+			ID:              []string{"x3000c0s9b0n0"},
+			ProcessingModel: sm.CLProcessingModelRigid,
+		},
+		dbGetCompIDsError:         nil,
+		expectedGetCompIDsPrepare: regexp.QuoteMeta(tGetCompBaseQuery + " WHERE c.id IN ($1)"),
+		expectedGetCompIDsArgs:    []driver.Value{"x3000c0s9b0n0"},
+		dbGetCompIDsReturnCols:    []string{"id", "type", "state", "flag", "enabled", "admin", "role", "subrole", "nid", "subtype", "nettype", "arch", "class", "reservation_disabled", "locked"},
+		dbGetCompIDsReturnRows:    [][]driver.Value{
+			[]driver.Value{"x3000c0s9b0n0", "Node", "Ready", "OK", true, "", "Compute", "", 42, "", "Sling", "X86", "Mountain", false, false},
+		},
+		dbDeleteError:         nil,
+		expectedDeletePrepare: regexp.QuoteMeta(resDeleteReservation),
+		expectedDeleteArgs:    []driver.Value{"x3000c0s9b0n0"},
+		dbDeleteReturnCols:    []string{"component_id"},
+		dbDeleteReturnRows:    [][]driver.Value{
+			[]driver.Value{"x3000c0s9b0n0"},
+		},
+		expectedSuccess: 1,
+		expectedFailure: 0,
+		expectErr:       false,
 	}, {
-		"badName",
-		&sm.PartitionPatch{},
-		columns,
-		[][]driver.Value{},
-		nil,
-		regexp.QuoteMeta(dgrp6Query),
-		[]driver.Value{"badname", partNamespace},
-		nil,
-		regexp.QuoteMeta(""),
-		[]driver.Value{},
-		ErrHMSDSNoPartition,
+		f: sm.CompLockV2Filter{
+			ID:              []string{"x3000c0s9b0n0"},
+			ProcessingModel: sm.CLProcessingModelRigid,
+		},
+		dbGetCompIDsError:         sql.ErrNoRows,
+		expectedGetCompIDsPrepare: regexp.QuoteMeta(tGetCompBaseQuery + " WHERE c.id IN ($1)"),
+		expectedGetCompIDsArgs:    []driver.Value{"x3000c0s9b0n0"},
+		dbGetCompIDsReturnCols:    []string{"id", "type", "state", "flag", "enabled", "admin", "role", "subrole", "nid", "subtype", "nettype", "arch", "class", "reservation_disabled", "locked"},
+		dbGetCompIDsReturnRows:    [][]driver.Value{
+			[]driver.Value{"x3000c0s9b0n0", "Node", "Ready", "OK", true, "", "Compute", "", 42, "", "Sling", "X86", "Mountain", false, false},
+		},
+		dbDeleteError:         nil,
+		expectedDeletePrepare: "",
+		expectedDeleteArgs:    []driver.Value{},
+		dbDeleteReturnCols:    []string{},
+		dbDeleteReturnRows:    [][]driver.Value{},
+		expectedSuccess:       0,
+		expectedFailure:       0,
+		expectErr:             true,
 	}}
 
 	for i, test := range tests {
 		ResetMockDB()
-		// before we actually execute our api function, we need to expect required DB actions
-		rows := sqlmock.NewRows(test.dbColumns)
-		for _, row := range test.dbRows {
+		rows := sqlmock.NewRows(test.dbGetCompIDsReturnCols)
+		for _, row := range test.dbGetCompIDsReturnRows {
 			rows.AddRow(row...)
 		}
+		drows := sqlmock.NewRows(test.dbDeleteReturnCols)
+		for _, row := range test.dbDeleteReturnRows {
+			drows.AddRow(row...)
+		}
 
 		mockPG.ExpectBegin()
-		if test.expectedQueryPrepare == "" && test.dbQueryError != nil {
+		if test.expectedGetCompIDsPrepare == "" && test.dbGetCompIDsError == nil {
 			mockPG.ExpectRollback()
-		} else if test.dbQueryError != nil {
-			mockPG.ExpectPrepare(test.expectedQueryPrepare).ExpectQuery().WillReturnError(test.dbQueryError)
+		} else if test.dbGetCompIDsError != nil {
+			mockPG.ExpectPrepare(test.expectedGetCompIDsPrepare).ExpectQuery().WillReturnError(test.dbGetCompIDsError)
 			mockPG.ExpectRollback()
-		} else if test.dbUpdateError != nil || test.expectedError != nil {
-			mockPG.ExpectPrepare(test.expectedQueryPrepare).ExpectQuery().WithArgs(test.expectedQueryArgs...).WillReturnRows(rows)
-			if test.dbUpdateError != nil {
-				mockPG.ExpectPrepare(test.expectedUpdatePrepare).ExpectExec().WillReturnError(test.dbUpdateError)
-			}
+		} else if test.expectedDeletePrepare == "" && test.dbDeleteError == nil {
+			mockPG.ExpectPrepare(test.expectedGetCompIDsPrepare).ExpectQuery().WithArgs(test.expectedGetCompIDsArgs...).WillReturnRows(rows)
+			mockPG.ExpectRollback()
+		} else if test.dbDeleteError != nil {
+			mockPG.ExpectPrepare(test.expectedGetCompIDsPrepare).ExpectQuery().WithArgs(test.expectedGetCompIDsArgs...).WillReturnRows(rows)
+			mockPG.ExpectPrepare(test.expectedDeletePrepare).ExpectQuery().WillReturnError(test.dbDeleteError)
 			mockPG.ExpectRollback()
 		} else {
-			mockPG.ExpectPrepare(test.expectedQueryPrepare).ExpectQuery().WithArgs(test.expectedQueryArgs...).WillReturnRows(rows)
-			if test.expectedUpdatePrepare != "" {
-				mockPG.ExpectPrepare(test.expectedUpdatePrepare).ExpectExec().WithArgs(test.expectedUpdateArgs...).WillReturnResult(sqlmock.NewResult(0, 1))
-			}
+			mockPG.ExpectPrepare(test.expectedGetCompIDsPrepare).ExpectQuery().WithArgs(test.expectedGetCompIDsArgs...).WillReturnRows(rows)
+			mockPG.ExpectPrepare(test.expectedDeletePrepare).ExpectQuery().WithArgs(test.expectedDeleteArgs...).WillReturnRows(drows)
 			mockPG.ExpectCommit()
 		}
 
-		err := dPG.UpdatePartition(test.label, test.gp)
+		results, err := dPG.DeleteCompReservationsForce(test.f)
+		// ensure all expectations have been met
 		if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
 			t.Errorf("Test %v Failed: Sql expectations were not met: %s",
 				i, mock_err)
 		}
-		if test.dbQueryError == nil && test.dbUpdateError == nil && test.expectedError == nil {
+		if !test.expectErr {
 			if err != nil {
 				t.Errorf("Test %v Failed: Unexpected error received: %s", i, err)
+			} else {
+				if len(results.Success.ComponentIDs) != test.expectedSuccess {
+					t.Errorf("Test %v Failed: Expected %v Successes. Got %v", i, test.expectedSuccess, len(results.Success.ComponentIDs))
+				} else if len(results.Failure) != test.expectedFailure {
+					t.Errorf("Test %v Failed: Expected %v Failures. Got %v", i, test.expectedFailure, len(results.Failure))
+				}
 			}
-		} else if err != test.expectedError {
-			t.Errorf("Test %v Failed: Expected an error (%s).",
-				i, test.expectedError)
+		} else if err == nil {
+			t.Errorf("Test %v Failed: Expected an error.", i)
 		}
 	}
 }
 
-func TestPgAddPartitionMember(t *testing.T) {
-	columns := compGroupsColsSMPart // "id", "name", "description", "tags"
-
-	dval5 := []driver.Value{uuid5, dgrp5p.Name, dgrp5p.Description, pq.Array(&dgrp5p.Tags)}
-	dval6 := []driver.Value{uuid6, dgrp6p.Name, dgrp6p.Description, pq.Array(&dgrp6p.Tags)}
+func TestPgDeleteCompReservations(t *testing.T) {
+	res := compReservation{
+		component_id: "x3000c0s9b0n0",
+		create_timestamp: sql.NullTime{
+			Valid: true,
+			Time:  time.Now(),
+		},
+		expiration_timestamp: sql.NullTime{
+			Valid: true,
+			Time:  time.Now(),
+		},
+		deputy_key:      "x3000c0s9b0n0:dk:de1a20c2-efc9-41ad-b839-1e3cef197d17",
+		reservation_key: "x3000c0s9b0n0:rk:cbff2077-952f-4536-a102-c442227fdc5d",
+	}
 
 	sqq := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
 
-	dgrp5Query, _, _ := sqq.Select(compGroupsColsSMPart...).
-		From(compGroupsTable).
-		Where("name = ?", strings.ToLower(dgrp5p.Name)).
-		Where("namespace = ?", partNamespace).ToSql()
-
-	dgrp5Update, _, _ := sqq.Insert(compGroupMembersTable).
-		Columns(compGroupMembersColsNoTS...).
-		Values("x0c0s22b1n1", sq.Expr("?", uuid5),
-			partGroupNamespace).ToSql()
-
-	dgrp6Query, _, _ := sqq.Select(compGroupsColsSMPart...).
-		From(compGroupsTable).
-		Where("name = ?", strings.ToLower(dgrp6p.Name)).
-		Where("namespace = ?", partNamespace).ToSql()
-
-	dgrp6Update, _, _ := sqq.Insert(compGroupMembersTable).
-		Columns(compGroupMembersColsNoTS...).
-		Values("x0c0s4b0n0", sq.Expr("?", uuid6),
-			partGroupNamespace).ToSql()
+	// Note we only use the query here so the args values don't really matter.
+	resDeleteReservation, _, _ := sqq.Delete(compResTable).
+		Where(sq.Eq{compResRKCol: []string{res.reservation_key}}).
+		Suffix("RETURNING " + compResCompIdCol).ToSql()
 
 	tests := []struct {
-		name                  string
-		new_id                string
-		dbColumns             []string
-		dbRows                [][]driver.Value
-		dbQueryError          error
-		expectedQueryPrepare  string
-		expectedQueryArgs     []driver.Value
-		dbUpdateError         error
-		expectedUpdatePrepare string
-		expectedUpdateArgs    []driver.Value
-		expectedId            string
-		expectedError         error
+		f                     sm.CompLockV2ReservationFilter
+		dbDeleteError         error
+		expectedDeletePrepare string
+		expectedDeleteArgs    []driver.Value
+		dbDeleteReturnCols    []string
+		dbDeleteReturnRows    [][]driver.Value
+		expectedSuccess       int
+		expectedFailure       int
+		expectErr             bool
 	}{{
-		dgrp5p.Name,    // name
-		"x0c0s022b1n1", // new_id
-		columns,
-		[][]driver.Value{dval5},
-		nil,
-		regexp.QuoteMeta(dgrp5Query),
-		[]driver.Value{dgrp5p.Name, partNamespace},
-		nil,
-		regexp.QuoteMeta(dgrp5Update),
-		[]driver.Value{"x0c0s22b1n1", uuid5, partGroupNamespace},
-		"x0c0s22b1n1",
-		nil,
-	}, {
-		dgrp6p.Name,  // name
-		"x0c0s4b0n0", // new_id
-		columns,
-		[][]driver.Value{dval6},
-		nil,
-		regexp.QuoteMeta(dgrp6Query),
-		[]driver.Value{dgrp6p.Name, partNamespace},
-		nil,
-		regexp.QuoteMeta(dgrp6Update),
-		[]driver.Value{"x0c0s4b0n0", uuid6, partGroupNamespace},
-		"x0c0s4b0n0",
-		nil,
-	}, {
-		"badname",    // name
-		"x0c0s4b0n0", // new_id
-		columns,
-		[][]driver.Value{},
-		nil,
-		regexp.QuoteMeta(dgrp6Query),
-		[]driver.Value{"badname", partNamespace},
-		nil,
-		regexp.QuoteMeta(""),
-		[]driver.Value{},
-		"",
-		ErrHMSDSNoPartition,
+		f: sm.CompLockV2ReservationFilter{
+			ReservationKeys: []sm.CompLockV2Key{
+				sm.CompLockV2Key{
+					ID:  "x3000c0s9b0n0",
+					Key: "x3000c0s9b0n0:rk:cbff2077-952f-4536-a102-c442227fdc5d",
+				},
+			},
+			ProcessingModel: sm.CLProcessingModelRigid,
+		},
+		dbDeleteError:         nil,
+		expectedDeletePrepare: regexp.QuoteMeta(resDeleteReservation),
+		expectedDeleteArgs:    []driver.Value{"x3000c0s9b0n0:rk:cbff2077-952f-4536-a102-c442227fdc5d"},
+		dbDeleteReturnCols:    []string{"component_id"},
+		dbDeleteReturnRows:    [][]driver.Value{
+			[]driver.Value{"x3000c0s9b0n0"},
+		},
+		expectedSuccess:    1,
+		expectedFailure:    0,
+		expectErr:          false,
 	}, {
-		dgrp6p.Name,  // name
-		"x0c0s4b0n0", // new_id
-		columns,
-		[][]driver.Value{dval6},
-		nil,
-		regexp.QuoteMeta(dgrp6Query),
-		[]driver.Value{dgrp6p.Name, partNamespace},
-		ErrHMSDSDuplicateKey,
-		regexp.QuoteMeta(dgrp6Update),
-		[]driver.Value{"x0c0s4b0n0", uuid6, partGroupNamespace},
-		"",
-		ErrHMSDSDuplicateKey,
+		f: sm.CompLockV2ReservationFilter{
+			ReservationKeys: []sm.CompLockV2Key{
+				sm.CompLockV2Key{
+					ID: "x3000c0s9b0n0",
+				},
+			},
+			ProcessingModel: sm.CLProcessingModelRigid,
+		},
+		dbDeleteError:         nil,
+		expectedDeletePrepare: "",
+		expectedDeleteArgs:    []driver.Value{},
+		dbDeleteReturnCols:    []string{},
+		dbDeleteReturnRows:    [][]driver.Value{},
+		expectedSuccess:       0,
+		expectedFailure:       0,
+		expectErr:             true,
 	}}
 
 	for i, test := range tests {
 		ResetMockDB()
-		// before we actually execute our api function, we need to expect required DB actions
-		rows := sqlmock.NewRows(test.dbColumns)
-		for _, row := range test.dbRows {
+		rows := sqlmock.NewRows(test.dbDeleteReturnCols)
+		for _, row := range test.dbDeleteReturnRows {
 			rows.AddRow(row...)
 		}
 
+		v1rows := sqlmock.NewRows([]string{"component_id"})
+		v1rows.AddRow([]driver.Value{"x3000c0s9b0n0"}...)
+
+		compRows := sqlmock.NewRows([]string{"id"})
+		compRows.AddRow([]driver.Value{"x3000c0s9b0n0"}...)
+
 		mockPG.ExpectBegin()
-		if test.expectedQueryPrepare == "" && test.dbQueryError != nil {
-			mockPG.ExpectRollback()
-		} else if test.dbQueryError != nil {
-			mockPG.ExpectPrepare(test.expectedQueryPrepare).ExpectQuery().WillReturnError(test.dbQueryError)
+		if test.expectedDeletePrepare == "" && test.dbDeleteError == nil {
 			mockPG.ExpectRollback()
-		} else if test.dbUpdateError != nil || test.expectedError != nil {
-			mockPG.ExpectPrepare(test.expectedQueryPrepare).ExpectQuery().WithArgs(test.expectedQueryArgs...).WillReturnRows(rows)
-			if test.dbUpdateError != nil {
-				mockPG.ExpectPrepare(test.expectedUpdatePrepare).ExpectExec().WillReturnError(test.dbUpdateError)
-			}
+		} else if test.dbDeleteError != nil {
+			mockPG.ExpectPrepare(test.expectedDeletePrepare).ExpectQuery().WillReturnError(test.dbDeleteError)
 			mockPG.ExpectRollback()
 		} else {
-			mockPG.ExpectPrepare(test.expectedQueryPrepare).ExpectQuery().WithArgs(test.expectedQueryArgs...).WillReturnRows(rows)
-			mockPG.ExpectPrepare(test.expectedUpdatePrepare).ExpectExec().WithArgs(test.expectedUpdateArgs...).WillReturnResult(sqlmock.NewResult(0, 1))
+			mockPG.ExpectPrepare(test.expectedDeletePrepare).ExpectQuery().WithArgs(test.expectedDeleteArgs...).WillReturnRows(rows)
 			mockPG.ExpectCommit()
 		}
 
-		id, err := dPG.AddPartitionMember(test.name, test.new_id)
+		results, err := dPG.DeleteCompReservations(test.f)
 		// ensure all expectations have been met
 		if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
 			t.Errorf("Test %v Failed: Sql expectations were not met: %s",
 				i, mock_err)
 		}
-		if test.dbQueryError == nil &&
-			test.dbUpdateError == nil && test.expectedError == nil {
+		if !test.expectErr {
 			if err != nil {
 				t.Errorf("Test %v Failed: Unexpected error received: %s", i, err)
-			}
-			if id == "" {
-				t.Errorf("Test %v Failed: Expected non-empty id", i)
 			} else {
-				if id != test.expectedId {
-					t.Errorf("Test %v Failed: Expected name %s (got %s)",
-						i, id, test.expectedId)
+				if len(results.Success.ComponentIDs) != test.expectedSuccess {
+					t.Errorf("Test %v Failed: Expected %v Successes. Got %v", i, test.expectedSuccess, len(results.Success.ComponentIDs))
+				} else if len(results.Failure) != test.expectedFailure {
+					t.Errorf("Test %v Failed: Expected %v Failures. Got %v", i, test.expectedFailure, len(results.Failure))
 				}
 			}
-		} else if err == nil && err != test.expectedError {
-			t.Errorf("Test %v Failed: Expected an error (%s).",
-				i, test.expectedError)
+		} else if err == nil {
+			t.Errorf("Test %v Failed: Expected an error.", i)
 		}
 	}
 }
 
-func TestPgDeletePartitionMember(t *testing.T) {
-	columns := compGroupsColsSMPart // "id", "name", "description", "tags"
-
-	dval5 := []driver.Value{uuid5, dgrp5p.Name, dgrp5p.Description, pq.Array(&dgrp5p.Tags)}
-	dval6 := []driver.Value{uuid6, dgrp6p.Name, dgrp6p.Description, pq.Array(&dgrp6p.Tags)}
+func TestPgDeleteCompReservationsExpired(t *testing.T) {
 
 	sqq := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
 
-	dgrp5Query, _, _ := sqq.Select(compGroupsColsSMPart...).
-		From(compGroupsTable).
-		Where("name = ?", strings.ToLower(dgrp5p.Name)).
-		Where("namespace = ?", partNamespace).ToSql()
+	// Note we only use the query here so the args values don't really matter.
+	resDeleteReservation, _, _ := sqq.Delete(compResTable).
+		Where(compResExpireCol + " IS NOT NULL AND NOW() >= " + compResExpireCol).
+		Suffix("RETURNING " + compResCompIdCol).ToSql()
 
-	dgrp5Update, _, _ := sqq.Delete(compGroupMembersTable).
-		Where("group_id = ?", uuid5).
-		Where("component_id = ?",
-			xnametypes.NormalizeHMSCompID(dgrp5p.Members.IDs[0])).ToSql()
+	tests := []struct {
+		dbDeleteError         error
+		expectedDeletePrepare string
+		dbDeleteReturnCols    []string
+		dbDeleteReturnRows    [][]driver.Value
+		expectedSuccess       int
+		expectErr             bool
+	}{{
+		dbDeleteError:         nil,
+		expectedDeletePrepare: regexp.QuoteMeta(resDeleteReservation),
+		dbDeleteReturnCols:    []string{"component_id"},
+		dbDeleteReturnRows:    [][]driver.Value{
+			[]driver.Value{"x3000c0s9b0n0"},
+		},
+		expectedSuccess:    1,
+		expectErr:          false,
+	}}
 
-	dgrp6Query, _, _ := sqq.Select(compGroupsColsSMPart...).
-		From(compGroupsTable).
-		Where("name = ?", strings.ToLower(dgrp6p.Name)).
-		Where("namespace = ?", partNamespace).ToSql()
+	for i, test := range tests {
+		ResetMockDB()
+		rows := sqlmock.NewRows(test.dbDeleteReturnCols)
+		for _, row := range test.dbDeleteReturnRows {
+			rows.AddRow(row...)
+		}
 
-	dgrp6Update, _, _ := sqq.Delete(compGroupMembersTable).
-		Where("group_id = ?", uuid6).
-		Where("component_id = ?",
-			xnametypes.NormalizeHMSCompID(dgrp6p.Members.IDs[0])).ToSql()
+		v1rows := sqlmock.NewRows([]string{"component_id"})
+		v1rows.AddRow([]driver.Value{"x3000c0s9b0n0"}...)
+
+		compRows := sqlmock.NewRows([]string{"id"})
+		compRows.AddRow([]driver.Value{"x3000c0s9b0n0"}...)
+
+		mockPG.ExpectBegin()
+		if test.expectedDeletePrepare == "" && test.dbDeleteError == nil {
+			mockPG.ExpectRollback()
+		} else if test.dbDeleteError != nil {
+			mockPG.ExpectPrepare(test.expectedDeletePrepare).ExpectQuery().WillReturnError(test.dbDeleteError)
+			mockPG.ExpectRollback()
+		} else {
+			mockPG.ExpectPrepare(test.expectedDeletePrepare).ExpectQuery().WillReturnRows(rows)
+			mockPG.ExpectCommit()
+		}
+
+		results, err := dPG.DeleteCompReservationsExpired()
+		// ensure all expectations have been met
+		if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
+			t.Errorf("Test %v Failed: Sql expectations were not met: %s",
+				i, mock_err)
+		}
+		if !test.expectErr {
+			if err != nil {
+				t.Errorf("Test %v Failed: Unexpected error received: %s", i, err)
+			} else {
+				if len(results) != test.expectedSuccess {
+					t.Errorf("Test %v Failed: Expected %v Successes. Got %v", i, test.expectedSuccess, len(results))
+				}
+			}
+		} else if err == nil {
+			t.Errorf("Test %v Failed: Expected an error.", i)
+		}
+	}
+}
+
+///////////////////////////////////////////////////////////////////////////////
+// Power Map Query Tests
+///////////////////////////////////////////////////////////////////////////////
+
+func TestPgGetPowerMapByID(t *testing.T) {
+	columns := []string{"id", "powered_by"}
 
 	tests := []struct {
-		name                  string
-		del_id                string
-		dbColumns             []string
-		dbRows                [][]driver.Value
-		dbQueryError          error
-		expectedQueryPrepare  string
-		expectedQueryArgs     []driver.Value
-		dbUpdateError         error
-		expectedUpdatePrepare string
-		expectedUpdateArgs    []driver.Value
-		expectedDeleted       bool
-		expectedId            string
-		expectedError         error
+		id               string
+		dbRows           [][]driver.Value
+		dbError          error
+		expectedPrepare  string
+		expectedArgs     []driver.Value
+		expectedPowerMap *sm.PowerMap
+		expectedErr      error
 	}{{
-		dgrp5p.Name, // name
-		dgrp5p.Members.IDs[0],
-		columns,
-		[][]driver.Value{dval5},
-		nil,
-		regexp.QuoteMeta(dgrp5Query),
-		[]driver.Value{dgrp5p.Name, partNamespace},
-		nil,
-		regexp.QuoteMeta(dgrp5Update),
-		[]driver.Value{uuid5, dgrp5p.Members.IDs[0]},
-		true,
-		dgrp5p.Members.IDs[0],
-		nil,
-	}, {
-		dgrp6p.Name, // name
-		dgrp6p.Members.IDs[0],
-		columns,
-		[][]driver.Value{dval6},
-		nil,
-		regexp.QuoteMeta(dgrp6Query),
-		[]driver.Value{dgrp6p.Name, partNamespace},
-		nil,
-		regexp.QuoteMeta(dgrp6Update),
-		[]driver.Value{uuid6, dgrp6p.Members.IDs[0]},
-		true,
-		dgrp6p.Members.IDs[0],
-		nil,
+		id: "x0c0s21b0",
+		dbRows: [][]driver.Value{
+			[]driver.Value{"x0c0s21b0", pq.Array([]string{"x0m0p0j10", "x0m0p0j11"})},
+		},
+		dbError:          nil,
+		expectedPrepare:  regexp.QuoteMeta(getPowerMapByIDQuery),
+		expectedArgs:     []driver.Value{"x0c0s21b0"},
+		expectedPowerMap: &sm.PowerMap{ID: "x0c0s21b0", PoweredBy: []string{"x0m0p0j10", "x0m0p0j11"}},
+		expectedErr:      nil,
 	}, {
-		dgrp6p.Name, // name
-		dgrp6p.Members.IDs[1],
-		columns,
-		[][]driver.Value{dval6},
-		nil,
-		regexp.QuoteMeta(dgrp6Query),
-		[]driver.Value{dgrp6p.Name, partNamespace},
-		nil,
-		regexp.QuoteMeta(dgrp6Update),
-		[]driver.Value{uuid6, dgrp6p.Members.IDs[1]},
-		false,
-		"",
-		nil,
+		id:               "",
+		dbRows:           nil,
+		dbError:          nil,
+		expectedPrepare:  "",
+		expectedArgs:     nil,
+		expectedPowerMap: nil,
+		expectedErr:      ErrHMSDSArgMissing,
 	}, {
-		"badname", // name
-		dgrp6p.Members.IDs[0],
-		columns,
-		[][]driver.Value{},
-		nil,
-		regexp.QuoteMeta(dgrp6Query),
-		[]driver.Value{"badname", partNamespace},
-		nil,
-		regexp.QuoteMeta(""),
-		[]driver.Value{},
-		false,
-		dgrp6p.Members.IDs[0],
-		ErrHMSDSNoPartition,
+		id:               "x0c0s21b0",
+		dbRows:           nil,
+		dbError:          sql.ErrNoRows,
+		expectedPrepare:  regexp.QuoteMeta(getPowerMapByIDQuery),
+		expectedArgs:     []driver.Value{"x0c0s21b0"},
+		expectedPowerMap: nil,
+		expectedErr:      nil,
 	}}
 
 	for i, test := range tests {
 		ResetMockDB()
 		// before we actually execute our api function, we need to expect required DB actions
-		rows := sqlmock.NewRows(test.dbColumns)
+		rows := sqlmock.NewRows(columns)
 		for _, row := range test.dbRows {
 			rows.AddRow(row...)
 		}
 
 		mockPG.ExpectBegin()
-		if test.expectedQueryPrepare == "" && test.dbQueryError != nil {
-			mockPG.ExpectRollback()
-		} else if test.dbQueryError != nil {
-			mockPG.ExpectPrepare(test.expectedQueryPrepare).ExpectQuery().WillReturnError(test.dbQueryError)
+		if test.dbError != nil {
+			mockPG.ExpectPrepare(ToPGQueryArgs(test.expectedPrepare)).ExpectQuery().WillReturnError(test.dbError)
 			mockPG.ExpectRollback()
-		} else if test.dbUpdateError != nil || test.expectedError != nil {
-			mockPG.ExpectPrepare(test.expectedQueryPrepare).ExpectQuery().WithArgs(test.expectedQueryArgs...).WillReturnRows(rows)
-			if test.dbUpdateError != nil {
-				mockPG.ExpectPrepare(test.expectedUpdatePrepare).ExpectExec().WillReturnError(test.dbUpdateError)
-			}
+		} else if test.expectedErr != nil {
 			mockPG.ExpectRollback()
 		} else {
-			mockPG.ExpectPrepare(test.expectedQueryPrepare).ExpectQuery().WithArgs(test.expectedQueryArgs...).WillReturnRows(rows)
-			if test.expectedDeleted {
-				mockPG.ExpectPrepare(test.expectedUpdatePrepare).ExpectExec().WithArgs(test.expectedUpdateArgs...).WillReturnResult(sqlmock.NewResult(0, 1))
-			} else {
-				mockPG.ExpectPrepare(test.expectedUpdatePrepare).ExpectExec().WithArgs(test.expectedUpdateArgs...).WillReturnResult(sqlmock.NewResult(0, 0))
-			}
+			mockPG.ExpectPrepare(ToPGQueryArgs(test.expectedPrepare)).ExpectQuery().WithArgs(test.expectedArgs...).WillReturnRows(rows)
 			mockPG.ExpectCommit()
 		}
 
-		didDelete, err := dPG.DeletePartitionMember(test.name, test.del_id)
+		m, err := dPG.GetPowerMapByID(test.id)
+		// ensure all expectations have been met
 		if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
-			t.Errorf("Test %v Failed: Sql expectations were not met: %s",
-				i, mock_err)
+			t.Errorf("Test %v Failed: Sql expectations were not met: %s", i, mock_err)
 		}
-		if test.dbQueryError == nil && test.dbUpdateError == nil && test.expectedError == nil {
+		if test.dbError == nil && test.expectedErr == nil {
 			if err != nil {
 				t.Errorf("Test %v Failed: Unexpected error received: %s", i, err)
+			} else if !reflect.DeepEqual(test.expectedPowerMap, m) {
+				t.Errorf("Test %v Failed: Expected power map '%v'; Recieved power map '%v'", i, test.expectedPowerMap, m)
 			}
-			if didDelete != test.expectedDeleted {
-				t.Errorf("Test %v Failed: Expected %t",
-					i, test.expectedDeleted)
-			}
-		} else if err != test.expectedError {
-			t.Errorf("Test %v Failed: Expected an error (%s).",
-				i, test.expectedError)
+		} else if err == nil {
+			t.Errorf("Test %v Failed: Expected an error.", i)
 		}
 	}
 }
 
-// Note: GetMembership is basically a special case where f contains ID={id}
-func TestPgGetMemberships(t *testing.T) {
+func TestPgGetPowerMapsAll(t *testing.T) {
+	columns := []string{"id", "powered_by"}
+
 	tests := []struct {
-		f               *ComponentFilter
-		dbColumns       []string
-		dbRows          [][]driver.Value
-		dbError         error
-		expectedPrepare string
-		expectedArgs    []driver.Value
-		expectedMembers []*sm.Membership
-		expectedError   error
-	}{{
-		&ComponentFilter{
-			Type: []string{"node"},
-		},
-		append(compColsIdOnly, compGroupPartCols...),
-		[][]driver.Value{
-			[]driver.Value{"x0c0s14b0n0", "grp1", "group"},
-			[]driver.Value{"x0c0s24b0n0", "grp1", "group"},
-			[]driver.Value{"x0c0s24b0n0", "grp2", "group"},
-			[]driver.Value{"x1c0s0b0n0", "grp2", "group"},
-			[]driver.Value{"x1c0s0b0n0", "grp3", "group"},
-			[]driver.Value{"x1c0s0b0n0", "part1", "partition"},
-		},
-		nil,
-		regexp.QuoteMeta(tGetMembershipsBaseQuery + tGetCompJoinGroupsQuery + " WHERE c.type IN ($1)"),
-		[]driver.Value{"Node"},
-		[]*sm.Membership{
-			&sm.Membership{ID: "x0c0s14b0n0", GroupLabels: []string{"grp1"}},
-			&sm.Membership{ID: "x0c0s24b0n0", GroupLabels: []string{"grp1", "grp2"}},
-			&sm.Membership{ID: "x1c0s0b0n0", GroupLabels: []string{"grp2", "grp3"}, PartitionName: "part1"},
-		},
-		nil,
-	}, {
-		&ComponentFilter{
-			Type: []string{},
-		},
-		append(compColsIdOnly, compGroupPartCols...),
-		[][]driver.Value{
-			[]driver.Value{"x0c0s14b0n0", nil, nil},
-			[]driver.Value{"x0c0s24b0n0", "grp1", "group"},
-			[]driver.Value{"x0c0s24b0n0", "grp2", "group"},
-			[]driver.Value{"x1c0s0b0n0", "grp2", "group"},
-			[]driver.Value{"x1c0s0b0n0", "grp3", "group"},
-			[]driver.Value{"x1c0s0b0n0", "part1", "partition"},
-		},
-		nil,
-		regexp.QuoteMeta(tGetMembershipsBaseQuery + tGetCompJoinGroupsQuery),
-		[]driver.Value{},
-		[]*sm.Membership{
-			&sm.Membership{ID: "x0c0s14b0n0", GroupLabels: []string{}},
-			&sm.Membership{ID: "x0c0s24b0n0", GroupLabels: []string{"grp1", "grp2"}},
-			&sm.Membership{ID: "x1c0s0b0n0", GroupLabels: []string{"grp2", "grp3"}, PartitionName: "part1"},
-		},
-		nil,
-	}, {
-		&ComponentFilter{
-			ID: []string{"x1c0s0b0n0"}, // Same as GetMembership
-		},
-		append(compColsIdOnly, compGroupPartCols...),
-		[][]driver.Value{
-			[]driver.Value{"x1c0s0b0n0", "grp2", "group"},
-			[]driver.Value{"x1c0s0b0n0", "grp3", "group"},
-			[]driver.Value{"x1c0s0b0n0", "part1", "partition"},
-		},
-		nil,
-		regexp.QuoteMeta(tGetMembershipsBaseQuery + tGetCompJoinGroupsQuery + " WHERE c.id IN ($1)"),
-		[]driver.Value{"x1c0s0b0n0"},
-		[]*sm.Membership{
-			&sm.Membership{ID: "x1c0s0b0n0", GroupLabels: []string{"grp2", "grp3"}, PartitionName: "part1"},
-		},
-		nil,
-	}, {
-		&ComponentFilter{
-			NID:      []string{"800"},
-			NIDStart: []string{"16300", "440"},
-			NIDEnd:   []string{"16500", "480"},
-		},
-		append(compColsIdOnly, compGroupPartCols...),
-		[][]driver.Value{
-			[]driver.Value{"x0c0s14b0n0", "grp1", "group"},
-			[]driver.Value{"x0c0s24b0n0", "grp1", "group"},
-			[]driver.Value{"x0c0s24b0n0", "grp2", "group"},
-			[]driver.Value{"x1c0s0b0n0", "grp2", "group"},
-			[]driver.Value{"x1c0s0b0n0", "grp3", "group"},
-			[]driver.Value{"x1c0s0b0n0", "part1", "partition"},
-		},
-		nil,
-		regexp.QuoteMeta(tGetMembershipsBaseQuery + tGetCompJoinGroupsQuery + " WHERE ((c.nid >= $1 AND c.nid <= $2) OR (c.nid >= $3 AND c.nid <= $4) OR c.nid = $5)"),
-		[]driver.Value{"16300", "16500", "440", "480", "800"},
-		[]*sm.Membership{
-			&sm.Membership{ID: "x0c0s14b0n0", GroupLabels: []string{"grp1"}},
-			&sm.Membership{ID: "x0c0s24b0n0", GroupLabels: []string{"grp1", "grp2"}},
-			&sm.Membership{ID: "x1c0s0b0n0", GroupLabels: []string{"grp2", "grp3"}, PartitionName: "part1"},
-		},
-		nil,
-	}, {
-		&ComponentFilter{
-			Group: []string{"grp2", "grp3"},
-		},
-		append(compColsIdOnly, compGroupPartCols...),
-		[][]driver.Value{
-			[]driver.Value{"x0c0s24b0n0", "grp1", "group"},
-			[]driver.Value{"x0c0s24b0n0", "grp2", "group"},
-			[]driver.Value{"x1c0s0b0n0", "grp2", "group"},
-			[]driver.Value{"x1c0s0b0n0", "grp3", "group"},
-			[]driver.Value{"x1c0s0b0n0", "part1", "partition"},
-		},
-		nil,
-		regexp.QuoteMeta(tGetMembershipsBaseQueryWSub + tGetMembershipsSubQuery +
-			tGetCompJoinGroupsQuery +
-			" WHERE (cg.name IN ($1,$2) AND cg.namespace = $3)" +
-			tGetMembershipsBaseSuffixWSub),
-		[]driver.Value{"grp2", "grp3", "group"},
-		[]*sm.Membership{
-			&sm.Membership{ID: "x0c0s24b0n0", GroupLabels: []string{"grp1", "grp2"}},
-			&sm.Membership{ID: "x1c0s0b0n0", GroupLabels: []string{"grp2", "grp3"}, PartitionName: "part1"},
-		},
-		nil,
-	}, {
-		&ComponentFilter{
-			Group:     []string{"grp2"},
-			Partition: []string{"part1"},
-		},
-		append(compColsIdOnly, compGroupPartCols...),
-		[][]driver.Value{
-			[]driver.Value{"x1c0s0b0n0", "grp2", "group"},
-			[]driver.Value{"x1c0s0b0n0", "grp3", "group"},
-			[]driver.Value{"x1c0s0b0n0", "part1", "partition"},
-		},
-		nil,
-		regexp.QuoteMeta(tGetMembershipsBaseQueryWSub + tGetMembershipsSubQuery +
-			tGetCompJoinGroupsQuery +
-			" WHERE ((cg.name IN ($1) AND cg.namespace = $2) OR (cg.name IN ($3) AND cg.namespace = $4))" +
-			tGetMembershipsGroupSuffixWSub),
-		[]driver.Value{"grp2", "group", "part1", "partition"},
-		[]*sm.Membership{
-			&sm.Membership{ID: "x1c0s0b0n0", GroupLabels: []string{"grp2", "grp3"}, PartitionName: "part1"},
-		},
-		nil,
-	}, {
-		&ComponentFilter{
-			Group:     []string{"NULL"},
-			Partition: []string{"NULL"},
-		},
-		append(compColsIdOnly, compGroupPartCols...),
-		[][]driver.Value{
-			[]driver.Value{"x0c0s14b0n0", nil, nil},
-		},
-		nil,
-		regexp.QuoteMeta(tGetMembershipsBaseQueryWSub + tGetMembershipsSubQuery +
-			tGetCompJoinGroupsQuery +
-			" WHERE c" + compGroupMembersNsColAlias + " IS NULL" +
-			tGetMembershipsBaseSuffixWSub),
-		[]driver.Value{},
-		[]*sm.Membership{
-			&sm.Membership{ID: "x0c0s14b0n0", GroupLabels: []string{}},
-		},
-		nil,
-	}, {
-		&ComponentFilter{
-			Group:     []string{"NULL"},
-			Partition: []string{"part1"},
-		},
-		append(compColsIdOnly, compGroupPartCols...),
-		[][]driver.Value{},
-		nil,
-		regexp.QuoteMeta(""),
-		[]driver.Value{},
-		[]*sm.Membership{},
-		ErrHMSDSNullGroupBadPart,
-	}, {
-		&ComponentFilter{
-			Group:     []string{"grp1"},
-			Partition: []string{"NULL"},
-		},
-		append(compColsIdOnly, compGroupPartCols...),
-		[][]driver.Value{},
-		nil,
-		regexp.QuoteMeta(""),
-		[]driver.Value{},
-		[]*sm.Membership{},
-		ErrHMSDSNullPartBadGroup,
-	}, {
-		&ComponentFilter{
-			Group: []string{"NULL", "grp1", "grp2"},
-		},
-		append(compColsIdOnly, compGroupPartCols...),
-		[][]driver.Value{},
-		nil,
-		regexp.QuoteMeta(""),
-		[]driver.Value{},
-		[]*sm.Membership{},
-		ErrHMSDSNullBadMixGroup,
-	}, {
-		&ComponentFilter{
-			Partition: []string{"p1", "NULL", "p2"},
-		},
-		append(compColsIdOnly, compGroupPartCols...),
-		[][]driver.Value{},
-		nil,
-		regexp.QuoteMeta(""),
-		[]driver.Value{},
-		[]*sm.Membership{},
-		ErrHMSDSNullBadMixPart,
-	}, {
-		&ComponentFilter{
-			Group:     []string{"grp1", "grp2"},
-			Partition: []string{"part1"},
+		dbRows          [][]driver.Value
+		dbError         error
+		expectedPrepare string
+		expectedPMs     []*sm.PowerMap
+	}{{
+		dbRows: [][]driver.Value{
+			[]driver.Value{"x0c0s21b0", pq.Array([]string{"x0m0p0j10", "x0m0p0j11"})},
+			[]driver.Value{"x0c0s22b0", pq.Array([]string{"x0m0p0j12", "x0m0p0j13"})},
 		},
-		append(compColsIdOnly, compGroupPartCols...),
-		[][]driver.Value{},
-		nil,
-		regexp.QuoteMeta(""),
-		[]driver.Value{},
-		[]*sm.Membership{},
-		ErrHMSDSMultipleGroupAndPart,
-	}, {
-		&ComponentFilter{
-			Group:     []string{"grp1"},
-			Partition: []string{"part1", "part2"},
+		dbError:         nil,
+		expectedPrepare: regexp.QuoteMeta(getPowerMapsAllQuery),
+		expectedPMs: []*sm.PowerMap{
+			&sm.PowerMap{ID: "x0c0s21b0", PoweredBy: []string{"x0m0p0j10", "x0m0p0j11"}},
+			&sm.PowerMap{ID: "x0c0s22b0", PoweredBy: []string{"x0m0p0j12", "x0m0p0j13"}},
 		},
-		append(compColsIdOnly, compGroupPartCols...),
-		[][]driver.Value{},
-		nil,
-		regexp.QuoteMeta(""),
-		[]driver.Value{},
-		[]*sm.Membership{},
-		ErrHMSDSMultipleGroupAndPart,
 	}, {
-		&ComponentFilter{
-			Group:     []string{"grp1"},
-			Partition: []string{"part$^#$^#$@1"}, // invalid part
-		},
-		append(compColsIdOnly, compGroupPartCols...),
-		[][]driver.Value{},
-		nil,
-		regexp.QuoteMeta(""),
-		[]driver.Value{},
-		[]*sm.Membership{},
-		ErrHMSDSNoPartition,
+		dbRows:          nil,
+		dbError:         sql.ErrNoRows,
+		expectedPrepare: regexp.QuoteMeta(getPowerMapsAllQuery),
+		expectedPMs:     nil,
 	}}
 
 	for i, test := range tests {
 		ResetMockDB()
 		// before we actually execute our api function, we need to expect required DB actions
-		rows := sqlmock.NewRows(test.dbColumns)
+		rows := sqlmock.NewRows(columns)
 		for _, row := range test.dbRows {
 			rows.AddRow(row...)
 		}
+
+		mockPG.ExpectBegin()
 		if test.dbError != nil {
 			mockPG.ExpectPrepare(test.expectedPrepare).ExpectQuery().WillReturnError(test.dbError)
-		} else if test.expectedError == nil {
-			if len(test.dbColumns) == 0 {
-				mockPG.ExpectPrepare(test.expectedPrepare).ExpectQuery()
-			} else if test.expectedArgs != nil {
-				mockPG.ExpectPrepare(test.expectedPrepare).ExpectQuery().WithArgs(test.expectedArgs...).WillReturnRows(rows)
-			} else {
-				mockPG.ExpectPrepare(test.expectedPrepare).ExpectQuery().WillReturnRows(rows)
-			}
+			mockPG.ExpectRollback()
+		} else {
+			mockPG.ExpectPrepare(test.expectedPrepare).ExpectQuery().WillReturnRows(rows)
+			mockPG.ExpectCommit()
 		}
-		mbs, err := dPG.GetMemberships(test.f)
+
+		ms, err := dPG.GetPowerMapsAll()
+		// ensure all expectations have been met
 		if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
 			t.Errorf("Test %v Failed: Sql expectations were not met: %s", i, mock_err)
 		}
-		// Note if dbError is != nil test.expectedError should be non-nil also
-		if test.dbError == nil && test.expectedError == nil {
+		if test.dbError == nil {
 			if err != nil {
 				t.Errorf("Test %v Failed: Unexpected error received: %s", i, err)
-			} else if len(mbs) != len(test.expectedMembers) {
-				t.Errorf("Test %v Failed: Expected members '%v'; Got members '%v'",
-					i, test.expectedMembers, mbs)
-			} else {
-				for _, em := range test.expectedMembers {
-					found := false
-					for _, m := range mbs {
-						if reflect.DeepEqual(*m, *em) {
-							found = true
-							break
-						}
-					}
-					if found != true {
-						t.Errorf("Test %v Failed: Expected member '%v'", i, *em)
-					}
-				}
+			} else if !reflect.DeepEqual(test.expectedPMs, ms) {
+				t.Errorf("Test %v Failed: Expected power maps '%v'; Recieved power maps '%v'", i, test.expectedPMs, ms)
 			}
-		} else if err != test.expectedError {
-			t.Errorf("Test %v Failed: Expected error '%s', got '%s'",
-				i, err, test.expectedError)
+		} else if err == nil {
+			t.Errorf("Test %v Failed: Expected an error.", i)
 		}
 	}
 }
 
-////////////////////////////////////////////////////////////////////////////
-//
-// Component Lock Management
-//
-////////////////////////////////////////////////////////////////////////////
-
-// Define UUID dummy type and implement sqlmocj Match interface.
-type AnyTime struct{}
-
-func (a AnyTime) Match(v driver.Value) bool {
-	if _, ok := v.(string); ok {
-		return true
-	} else if _, ok := v.(time.Time); ok {
-		return true
-	} else if _, ok := v.(sql.NullTime); ok {
-		return true
-	} else {
-		return false
-	}
-}
-
-type AnyString struct{}
-
-func (a AnyString) Match(v driver.Value) bool {
-	if v == nil {
-		return true
-	} else if _, ok := v.(string); ok {
-		return true
-	} else if _, ok := v.(sql.NullString); ok {
-		return true
-	} else {
-		return false
-	}
-}
-
-func TestPgInsertCompReservations(t *testing.T) {
-	resInsert := compReservation{
-		component_id: "x3000c0s9b0n0",
-		create_timestamp: sql.NullTime{
-			Valid: true,
-			Time:  time.Now(),
-		},
-		expiration_timestamp: sql.NullTime{
-			Valid: true,
-			Time:  time.Now(),
-		},
-		deputy_key:      "x3000c0s9b0n0:dk:de1a20c2-efc9-41ad-b839-1e3cef197d17",
-		reservation_key: "x3000c0s9b0n0:rk:cbff2077-952f-4536-a102-c442227fdc5d",
-	}
-
-	sqq := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
-
-	// Note we only use the query here so the args values don't really matter.
-	resInsertReservation, _, _ := sqq.Insert(compResTable).
-		Columns(compResCols...).
-		Values(resInsert.component_id,
-			sq.Expr("?", resInsert.create_timestamp),
-			sq.Expr("?", resInsert.expiration_timestamp),
-			sq.Expr("?", resInsert.deputy_key),
-			sq.Expr("?", resInsert.reservation_key)).
-		Suffix("ON CONFLICT DO NOTHING RETURNING " + compResCompIdCol + ", " + compResDKCol + ", " + compResRKCol).ToSql()
-
+func TestPgInsertPowerMap(t *testing.T) {
 	tests := []struct {
-		f                         sm.CompLockV2Filter
-		dbGetCompIDsError         error
-		expectedGetCompIDsPrepare string
-		expectedGetCompIDsArgs    []driver.Value
-		dbGetCompIDsReturnCols    []string
-		dbGetCompIDsReturnRows    [][]driver.Value
-		dbInsertError             error
-		expectedInsertPrepare     string
-		expectedInsertArgs        []driver.Value
-		dbInsertV2ResReturnCols   []string
-		dbInsertV2ResReturnRows   [][]driver.Value
-		expectedSuccess           int
-		expectedFailure           int
-		expectErr                 bool
-	}{{
-		f: sm.CompLockV2Filter{
-			ID:                  []string{"x3000c0s9b0n0"},
-			ProcessingModel:     sm.CLProcessingModelRigid,
-			ReservationDuration: 1,
-		},
-		dbGetCompIDsError:         nil,
-		expectedGetCompIDsPrepare: regexp.QuoteMeta(tGetCompBaseQuery + " WHERE c.id IN ($1)"),
-		expectedGetCompIDsArgs:    []driver.Value{"x3000c0s9b0n0"},
-		dbGetCompIDsReturnCols:    []string{"id", "type", "state", "flag", "enabled", "admin", "role", "subrole", "nid", "subtype", "nettype", "arch", "class", "reservation_disabled", "locked"},
-		dbGetCompIDsReturnRows:    [][]driver.Value{
-			[]driver.Value{"x3000c0s9b0n0", "Node", "Ready", "OK", true, "", "Compute", "", 42, "", "Sling", "X86", "Mountain", false, false},
-		},
-		dbInsertError:           nil,
-		expectedInsertPrepare:   regexp.QuoteMeta(resInsertReservation),
-		expectedInsertArgs:      []driver.Value{"x3000c0s9b0n0", AnyTime{}, AnyTime{}, AnyUUID{}, AnyUUID{}},
-		dbInsertV2ResReturnCols: []string{"component_id", "deputy_key", "reservation_key"},
-		dbInsertV2ResReturnRows: [][]driver.Value{
-			[]driver.Value{"x3000c0s9b0n0", "x3000c0s9b0n0:dk:de1a20c2-efc9-41ad-b839-1e3cef197d17", "x3000c0s9b0n0:rk:cbff2077-952f-4536-a102-c442227fdc5d"},
-		},
-		expectedSuccess:       1,
-		expectedFailure:       0,
-		expectErr:             false,
-	}, {
-		f: sm.CompLockV2Filter{
-			ID:                  []string{"x3000c0s9b0n0"},
-			ProcessingModel:     sm.CLProcessingModelRigid,
-			ReservationDuration: 1,
-		},
-		dbGetCompIDsError:         nil,
-		expectedGetCompIDsPrepare: regexp.QuoteMeta(tGetCompBaseQuery + " WHERE c.id IN ($1)"),
-		expectedGetCompIDsArgs:    []driver.Value{"x3000c0s9b0n0"},
-		dbGetCompIDsReturnCols:    []string{"id", "type", "state", "flag", "enabled", "admin", "role", "subrole", "nid", "subtype", "nettype", "arch", "class", "reservation_disabled", "locked"},
-		dbGetCompIDsReturnRows:    [][]driver.Value{
-			[]driver.Value{"x3000c0s9b0n0", "Node", "Ready", "OK", true, "", "Compute", "", 42, "", "Sling", "X86", "Mountain", false, true},
-		},
-		dbInsertError:           nil,
-		expectedInsertPrepare:   "",
-		expectedInsertArgs:      []driver.Value{},
-		dbInsertV2ResReturnCols: []string{},
-		dbInsertV2ResReturnRows: [][]driver.Value{},
-		expectedSuccess:         0,
-		expectedFailure:         0,
-		expectErr:               true,
-	}, {
-		f: sm.CompLockV2Filter{
-			ID:              []string{"x3000c0s9b0n0"},
-			ProcessingModel: sm.CLProcessingModelRigid,
-		},
-		dbGetCompIDsError:         nil,
-		expectedGetCompIDsPrepare: regexp.QuoteMeta(tGetCompBaseQuery + " WHERE c.id IN ($1)"),
-		expectedGetCompIDsArgs:    []driver.Value{"x3000c0s9b0n0"},
-		dbGetCompIDsReturnCols:    []string{"id", "type", "state", "flag", "enabled", "admin", "role", "subrole", "nid", "subtype", "nettype", "arch", "class", "reservation_disabled", "locked"},
-		dbGetCompIDsReturnRows:    [][]driver.Value{
-			[]driver.Value{"x3000c0s9b0n0", "Node", "Ready", "OK", true, "", "Compute", "", 42, "", "Sling", "X86", "Mountain", false, false},
-		},
-		dbInsertError:           nil,
-		expectedInsertPrepare:   "",
-		expectedInsertArgs:      []driver.Value{},
-		dbInsertV2ResReturnCols: []string{},
-		dbInsertV2ResReturnRows: [][]driver.Value{},
-		expectedSuccess:         0,
-		expectedFailure:         0,
-		expectErr:               true,
-	}, {
-		f: sm.CompLockV2Filter{
-			ID:              []string{"x3000c0s9b0n0"},
-			ProcessingModel: sm.CLProcessingModelRigid,
-		},
-		dbGetCompIDsError:         nil,
-		expectedGetCompIDsPrepare: regexp.QuoteMeta(tGetCompBaseQuery + " WHERE c.id IN ($1)"),
-		expectedGetCompIDsArgs:    []driver.Value{"x3000c0s9b0n0"},
-		dbGetCompIDsReturnCols:    []string{"id", "type", "state", "flag", "enabled", "admin", "role", "subrole", "nid", "subtype", "nettype", "arch", "class", "reservation_disabled", "locked"},
-		dbGetCompIDsReturnRows:    [][]driver.Value{
-			[]driver.Value{"x3000c0s9b0n0", "Node", "Ready", "OK", true, "", "Compute", "", 42, "", "Sling", "X86", "Mountain", true, false},
-		},
-		dbInsertError:           nil,
-		expectedInsertPrepare:   "",
-		expectedInsertArgs:      []driver.Value{},
-		dbInsertV2ResReturnCols: []string{},
-		dbInsertV2ResReturnRows: [][]driver.Value{},
-		expectedSuccess:         0,
-		expectedFailure:         0,
-		expectErr:               true,
+		m               *sm.PowerMap
+		dbError         error
+		expectedPrepare string
+		expectedArgs    []driver.Value
+		expectedErr     error
+	}{{
+		m:               &sm.PowerMap{ID: "x0c0s21b0", PoweredBy: []string{"x0m0p0j10", "x0m0p0j11"}},
+		dbError:         nil,
+		expectedPrepare: regexp.QuoteMeta(insertPgPowerMapQuery),
+		expectedArgs:    []driver.Value{"x0c0s21b0", pq.Array([]string{"x0m0p0j10", "x0m0p0j11"})},
+		expectedErr:     nil,
+	}, {
+		m:               &sm.PowerMap{ID: "x0c0s21b0", PoweredBy: []string{"x0m0p0j10", "x0m0p0j11"}},
+		dbError:         sql.ErrNoRows,
+		expectedPrepare: regexp.QuoteMeta(insertPgPowerMapQuery),
+		expectedArgs:    []driver.Value{"x0c0s21b0", pq.Array([]string{"x0m0p0j10", "x0m0p0j11"})},
+		expectedErr:     nil,
+	}, {
+		m:               nil,
+		dbError:         nil,
+		expectedPrepare: "",
+		expectedArgs:    nil,
+		expectedErr:     ErrHMSDSArgNil,
 	}}
 
 	for i, test := range tests {
 		ResetMockDB()
-		rows := sqlmock.NewRows(test.dbGetCompIDsReturnCols)
-		for _, row := range test.dbGetCompIDsReturnRows {
-			rows.AddRow(row...)
-		}
-		v2rows := sqlmock.NewRows(test.dbInsertV2ResReturnCols)
-		for _, row := range test.dbInsertV2ResReturnRows {
-			v2rows.AddRow(row...)
-		}
-
+		// before we actually execute our api function, we need to expect required DB actions
 		mockPG.ExpectBegin()
-		if test.expectedGetCompIDsPrepare == "" && test.dbGetCompIDsError == nil {
-			mockPG.ExpectRollback()
-		} else if test.dbGetCompIDsError != nil {
-			mockPG.ExpectPrepare(test.expectedGetCompIDsPrepare).ExpectQuery().WillReturnError(test.dbGetCompIDsError)
-			mockPG.ExpectRollback()
-		} else if test.expectedInsertPrepare == "" && test.dbInsertError == nil {
-			mockPG.ExpectPrepare(test.expectedGetCompIDsPrepare).ExpectQuery().WithArgs(test.expectedGetCompIDsArgs...).WillReturnRows(rows)
+		if test.dbError != nil {
+			mockPG.ExpectPrepare(ToPGQueryArgs(test.expectedPrepare)).ExpectExec().WillReturnError(test.dbError)
 			mockPG.ExpectRollback()
-		} else if test.dbInsertError != nil {
-			mockPG.ExpectPrepare(test.expectedGetCompIDsPrepare).ExpectQuery().WithArgs(test.expectedGetCompIDsArgs...).WillReturnRows(rows)
-			mockPG.ExpectPrepare(test.expectedInsertPrepare).ExpectQuery().WillReturnError(test.dbInsertError)
+		} else if test.expectedErr != nil {
 			mockPG.ExpectRollback()
 		} else {
-			mockPG.ExpectPrepare(test.expectedGetCompIDsPrepare).ExpectQuery().WithArgs(test.expectedGetCompIDsArgs...).WillReturnRows(rows)
-			mockPG.ExpectPrepare(test.expectedInsertPrepare).ExpectQuery().WithArgs(test.expectedInsertArgs...).WillReturnRows(v2rows)
+			mockPG.ExpectPrepare(ToPGQueryArgs(test.expectedPrepare)).ExpectExec().WithArgs(test.expectedArgs...).WillReturnResult(sqlmock.NewResult(0, 1))
 			mockPG.ExpectCommit()
 		}
 
-		results, err := dPG.InsertCompReservations(test.f)
+		err := dPG.InsertPowerMap(test.m)
 		// ensure all expectations have been met
 		if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
-			t.Errorf("Test %v Failed: Sql expectations were not met: %s",
-				i, mock_err)
+			t.Errorf("Test %v Failed: Sql expectations were not met: %s", i, mock_err)
 		}
-		if !test.expectErr {
+		if test.dbError == nil && test.expectedErr == nil {
 			if err != nil {
 				t.Errorf("Test %v Failed: Unexpected error received: %s", i, err)
-			} else {
-				if len(results.Success) != test.expectedSuccess {
-					t.Errorf("Test %v Failed: Expected %v Successes. Got %v", i, test.expectedSuccess, len(results.Success))
-				} else if len(results.Failure) != test.expectedFailure {
-					t.Errorf("Test %v Failed: Expected %v Failures. Got %v", i, test.expectedFailure, len(results.Failure))
-				}
 			}
 		} else if err == nil {
 			t.Errorf("Test %v Failed: Expected an error.", i)
@@ -6528,132 +8351,141 @@ func TestPgInsertCompReservations(t *testing.T) {
 	}
 }
 
-func TestPgDeleteCompReservationsForce(t *testing.T) {
-	res := compReservation{
-		component_id: "x3000c0s9b0n0",
-		create_timestamp: sql.NullTime{
-			Valid: true,
-			Time:  time.Now(),
-		},
-		expiration_timestamp: sql.NullTime{
-			Valid: true,
-			Time:  time.Now(),
-		},
-		deputy_key:      "x3000c0s9b0n0:dk:de1a20c2-efc9-41ad-b839-1e3cef197d17",
-		reservation_key: "x3000c0s9b0n0:rk:cbff2077-952f-4536-a102-c442227fdc5d",
-	}
-
-	sqq := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
-
-	// Note we only use the query here so the args values don't really matter.
-	resDeleteReservation, _, _ := sqq.Delete(compResTable).
-		Where(sq.Eq{compResCompIdCol: []string{res.component_id}}).
-		Suffix("RETURNING " + compResCompIdCol).ToSql()
-
+func TestPgInsertPowerMaps(t *testing.T) {
 	tests := []struct {
-		f                         sm.CompLockV2Filter
-		dbGetCompIDsError         error
-		expectedGetCompIDsPrepare string
-		expectedGetCompIDsArgs    []driver.Value
-		dbGetCompIDsReturnCols    []string
-		dbGetCompIDsReturnRows    [][]driver.Value
-		dbDeleteError             error
-		expectedDeletePrepare     string
-		expectedDeleteArgs        []driver.Value
-		dbDeleteReturnCols        []string
-		dbDeleteReturnRows        [][]driver.Value
-		expectedSuccess           int
-		expectedFailure           int
-		expectErr                 bool
+		ms              []sm.PowerMap
+		dbError         error
+		expectedPrepare string
+		expectedArgs    [][]driver.Value
+		expectedErr     error
 	}{{
-		f: sm.CompLockV2Filter{
-			ID:              []string{"x3000c0s9b0n0"},
-			ProcessingModel: sm.CLProcessingModelRigid,
+		ms: []sm.PowerMap{
+			sm.PowerMap{ID: "x0c0s21b0", PoweredBy: []string{"x0m0p0j10", "x0m0p0j11"}},
+			sm.PowerMap{ID: "x0c0s22b0", PoweredBy: []string{"x0m0p0j12", "x0m0p0j13"}},
 		},
-		dbGetCompIDsError:         nil,
-		expectedGetCompIDsPrepare: regexp.QuoteMeta(tGetCompBaseQuery + " WHERE c.id IN ($1)"),
-		expectedGetCompIDsArgs:    []driver.Value{"x3000c0s9b0n0"},
-		dbGetCompIDsReturnCols:    []string{"id", "type", "state", "flag", "enabled", "admin", "role", "subrole", "nid", "subtype", "nettype", "arch", "class", "reservation_disabled", "locked"},
-		dbGetCompIDsReturnRows:    [][]driver.Value{
-			[]driver.Value{"x3000c0s9b0n0", "Node", "Ready", "OK", true, "", "Compute", "", 42, "", "Sling", "X86", "Mountain", false, false},
+		dbError:         nil,
+		expectedPrepare: regexp.QuoteMeta(insertPgPowerMapQuery),
+		expectedArgs: [][]driver.Value{
+			[]driver.Value{"x0c0s21b0", pq.Array([]string{"x0m0p0j10", "x0m0p0j11"})},
+			[]driver.Value{"x0c0s22b0", pq.Array([]string{"x0m0p0j12", "x0m0p0j13"})},
 		},
-		dbDeleteError:         nil,
-		expectedDeletePrepare: regexp.QuoteMeta(resDeleteReservation),
-		expectedDeleteArgs:    []driver.Value{"x3000c0s9b0n0"},
-		dbDeleteReturnCols:    []string{"component_id"},
-		dbDeleteReturnRows:    [][]driver.Value{
-			[]driver.Value{"x3000c0s9b0n0"},
+		expectedErr: nil,
+	}, {
+		ms: []sm.PowerMap{
+			sm.PowerMap{ID: "x0c0s21b0", PoweredBy: []string{"x0m0p0j10", "x0m0p0j11"}},
 		},
-		expectedSuccess: 1,
-		expectedFailure: 0,
-		expectErr:       false,
+		dbError:         nil,
+		expectedPrepare: regexp.QuoteMeta(insertPgPowerMapQuery),
+		expectedArgs: [][]driver.Value{
+			[]driver.Value{"x0c0s21b0", pq.Array([]string{"x0m0p0j10", "x0m0p0j11"})},
+		},
+		expectedErr: nil,
 	}, {
-		f: sm.CompLockV2Filter{
-			ID:              []string{"x3000c0s9b0n0"},
-			ProcessingModel: sm.CLProcessingModelRigid,
+		ms: []sm.PowerMap{
+			sm.PowerMap{ID: "x0c0s21b0", PoweredBy: []string{"x0m0p0j10", "x0m0p0j11"}},
 		},
-		dbGetCompIDsError:         sql.ErrNoRows,
-		expectedGetCompIDsPrepare: regexp.QuoteMeta(tGetCompBaseQuery + " WHERE c.id IN ($1)"),
-		expectedGetCompIDsArgs:    []driver.Value{"x3000c0s9b0n0"},
-		dbGetCompIDsReturnCols:    []string{"id", "type", "state", "flag", "enabled", "admin", "role", "subrole", "nid", "subtype", "nettype", "arch", "class", "reservation_disabled", "locked"},
-		dbGetCompIDsReturnRows:    [][]driver.Value{
-			[]driver.Value{"x3000c0s9b0n0", "Node", "Ready", "OK", true, "", "Compute", "", 42, "", "Sling", "X86", "Mountain", false, false},
+		dbError:         sql.ErrNoRows,
+		expectedPrepare: regexp.QuoteMeta(insertPgPowerMapQuery),
+		expectedArgs: [][]driver.Value{
+			[]driver.Value{"x0c0s21b0", pq.Array([]string{"x0m0p0j10", "x0m0p0j11"})},
 		},
-		dbDeleteError:         nil,
-		expectedDeletePrepare: "",
-		expectedDeleteArgs:    []driver.Value{},
-		dbDeleteReturnCols:    []string{},
-		dbDeleteReturnRows:    [][]driver.Value{},
-		expectedSuccess:       0,
-		expectedFailure:       0,
-		expectErr:             true,
+		expectedErr: nil,
 	}}
 
 	for i, test := range tests {
 		ResetMockDB()
-		rows := sqlmock.NewRows(test.dbGetCompIDsReturnCols)
-		for _, row := range test.dbGetCompIDsReturnRows {
-			rows.AddRow(row...)
+		// before we actually execute our api function, we need to expect required DB actions
+		mockPG.ExpectBegin()
+		if test.dbError != nil {
+			mockPG.ExpectPrepare(ToPGQueryArgs(test.expectedPrepare)).ExpectExec().WillReturnError(test.dbError)
+			mockPG.ExpectRollback()
+		} else if test.expectedErr != nil {
+			mockPG.ExpectRollback()
+		} else {
+			for j, args := range test.expectedArgs {
+				if j == 0 {
+					mockPG.ExpectPrepare(ToPGQueryArgs(test.expectedPrepare)).ExpectExec().WithArgs(args...).WillReturnResult(sqlmock.NewResult(0, 1))
+				} else {
+					mockPG.ExpectExec(ToPGQueryArgs(test.expectedPrepare)).WithArgs(args...).WillReturnResult(sqlmock.NewResult(0, 1))
+				}
+			}
+			mockPG.ExpectCommit()
 		}
-		drows := sqlmock.NewRows(test.dbDeleteReturnCols)
-		for _, row := range test.dbDeleteReturnRows {
-			drows.AddRow(row...)
+
+		err := dPG.InsertPowerMaps(test.ms)
+		// ensure all expectations have been met
+		if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
+			t.Errorf("Test %v Failed: Sql expectations were not met: %s", i, mock_err)
+		}
+		if test.dbError == nil && test.expectedErr == nil {
+			if err != nil {
+				t.Errorf("Test %v Failed: Unexpected error received: %s", i, err)
+			}
+		} else if err == nil {
+			t.Errorf("Test %v Failed: Expected an error.", i)
 		}
+	}
+}
+
+func TestPgDeletePowerMapByID(t *testing.T) {
+	tests := []struct {
+		id              string
+		dbResult        int64
+		dbError         error
+		expectedPrepare string
+		expectedArgs    []driver.Value
+		expectedResult  bool
+		expectedErr     error
+	}{{
+		id:              "x0c0s21b0",
+		dbResult:        1,
+		dbError:         nil,
+		expectedPrepare: regexp.QuoteMeta(deletePowerMapByIDQuery),
+		expectedArgs:    []driver.Value{"x0c0s21b0"},
+		expectedResult:  true,
+		expectedErr:     nil,
+	}, {
+		id:              "",
+		dbResult:        0,
+		dbError:         nil,
+		expectedPrepare: "",
+		expectedArgs:    nil,
+		expectedResult:  false,
+		expectedErr:     ErrHMSDSArgNil,
+	}, {
+		id:              "x0c0s21b0",
+		dbResult:        0,
+		dbError:         sql.ErrNoRows,
+		expectedPrepare: regexp.QuoteMeta(deletePowerMapByIDQuery),
+		expectedArgs:    []driver.Value{"x0c0s21b0"},
+		expectedResult:  false,
+		expectedErr:     nil,
+	}}
 
+	for i, test := range tests {
+		ResetMockDB()
+		// before we actually execute our api function, we need to expect required DB actions
 		mockPG.ExpectBegin()
-		if test.expectedGetCompIDsPrepare == "" && test.dbGetCompIDsError == nil {
-			mockPG.ExpectRollback()
-		} else if test.dbGetCompIDsError != nil {
-			mockPG.ExpectPrepare(test.expectedGetCompIDsPrepare).ExpectQuery().WillReturnError(test.dbGetCompIDsError)
-			mockPG.ExpectRollback()
-		} else if test.expectedDeletePrepare == "" && test.dbDeleteError == nil {
-			mockPG.ExpectPrepare(test.expectedGetCompIDsPrepare).ExpectQuery().WithArgs(test.expectedGetCompIDsArgs...).WillReturnRows(rows)
+		if test.dbError != nil {
+			mockPG.ExpectPrepare(ToPGQueryArgs(test.expectedPrepare)).ExpectExec().WillReturnError(test.dbError)
 			mockPG.ExpectRollback()
-		} else if test.dbDeleteError != nil {
-			mockPG.ExpectPrepare(test.expectedGetCompIDsPrepare).ExpectQuery().WithArgs(test.expectedGetCompIDsArgs...).WillReturnRows(rows)
-			mockPG.ExpectPrepare(test.expectedDeletePrepare).ExpectQuery().WillReturnError(test.dbDeleteError)
+		} else if test.expectedErr != nil {
 			mockPG.ExpectRollback()
 		} else {
-			mockPG.ExpectPrepare(test.expectedGetCompIDsPrepare).ExpectQuery().WithArgs(test.expectedGetCompIDsArgs...).WillReturnRows(rows)
-			mockPG.ExpectPrepare(test.expectedDeletePrepare).ExpectQuery().WithArgs(test.expectedDeleteArgs...).WillReturnRows(drows)
+			mockPG.ExpectPrepare(ToPGQueryArgs(test.expectedPrepare)).ExpectExec().WithArgs(test.expectedArgs...).WillReturnResult(sqlmock.NewResult(0, test.dbResult))
 			mockPG.ExpectCommit()
 		}
 
-		results, err := dPG.DeleteCompReservationsForce(test.f)
+		didDelete, err := dPG.DeletePowerMapByID(test.id)
 		// ensure all expectations have been met
-		if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
-			t.Errorf("Test %v Failed: Sql expectations were not met: %s",
-				i, mock_err)
+		if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
+			t.Errorf("Test %v Failed: Sql expectations were not met: %s", i, mock_err)
 		}
-		if !test.expectErr {
+		if test.dbError == nil && test.expectedErr == nil {
 			if err != nil {
 				t.Errorf("Test %v Failed: Unexpected error received: %s", i, err)
-			} else {
-				if len(results.Success.ComponentIDs) != test.expectedSuccess {
-					t.Errorf("Test %v Failed: Expected %v Successes. Got %v", i, test.expectedSuccess, len(results.Success.ComponentIDs))
-				} else if len(results.Failure) != test.expectedFailure {
-					t.Errorf("Test %v Failed: Expected %v Failures. Got %v", i, test.expectedFailure, len(results.Failure))
-				}
+			} else if test.expectedResult != didDelete {
+				t.Errorf("Test %v Failed: Expected didDelete '%v'; Recieved didDelete '%v'", i, test.expectedResult, didDelete)
 			}
 		} else if err == nil {
 			t.Errorf("Test %v Failed: Expected an error.", i)
@@ -6661,116 +8493,52 @@ func TestPgDeleteCompReservationsForce(t *testing.T) {
 	}
 }
 
-func TestPgDeleteCompReservations(t *testing.T) {
-	res := compReservation{
-		component_id: "x3000c0s9b0n0",
-		create_timestamp: sql.NullTime{
-			Valid: true,
-			Time:  time.Now(),
-		},
-		expiration_timestamp: sql.NullTime{
-			Valid: true,
-			Time:  time.Now(),
-		},
-		deputy_key:      "x3000c0s9b0n0:dk:de1a20c2-efc9-41ad-b839-1e3cef197d17",
-		reservation_key: "x3000c0s9b0n0:rk:cbff2077-952f-4536-a102-c442227fdc5d",
-	}
-
-	sqq := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
-
-	// Note we only use the query here so the args values don't really matter.
-	resDeleteReservation, _, _ := sqq.Delete(compResTable).
-		Where(sq.Eq{compResRKCol: []string{res.reservation_key}}).
-		Suffix("RETURNING " + compResCompIdCol).ToSql()
-
+func TestPgDeletePowerMapsAll(t *testing.T) {
 	tests := []struct {
-		f                     sm.CompLockV2ReservationFilter
-		dbDeleteError         error
-		expectedDeletePrepare string
-		expectedDeleteArgs    []driver.Value
-		dbDeleteReturnCols    []string
-		dbDeleteReturnRows    [][]driver.Value
-		expectedSuccess       int
-		expectedFailure       int
-		expectErr             bool
+		dbResult        int64
+		dbError         error
+		expectedPrepare string
+		expectedResult  int64
+		expectedErr     error
 	}{{
-		f: sm.CompLockV2ReservationFilter{
-			ReservationKeys: []sm.CompLockV2Key{
-				sm.CompLockV2Key{
-					ID:  "x3000c0s9b0n0",
-					Key: "x3000c0s9b0n0:rk:cbff2077-952f-4536-a102-c442227fdc5d",
-				},
-			},
-			ProcessingModel: sm.CLProcessingModelRigid,
-		},
-		dbDeleteError:         nil,
-		expectedDeletePrepare: regexp.QuoteMeta(resDeleteReservation),
-		expectedDeleteArgs:    []driver.Value{"x3000c0s9b0n0:rk:cbff2077-952f-4536-a102-c442227fdc5d"},
-		dbDeleteReturnCols:    []string{"component_id"},
-		dbDeleteReturnRows:    [][]driver.Value{
-			[]driver.Value{"x3000c0s9b0n0"},
-		},
-		expectedSuccess:    1,
-		expectedFailure:    0,
-		expectErr:          false,
+		dbResult:        1,
+		dbError:         nil,
+		expectedPrepare: regexp.QuoteMeta(deletePowerMapsAllQuery),
+		expectedResult:  1,
 	}, {
-		f: sm.CompLockV2ReservationFilter{
-			ReservationKeys: []sm.CompLockV2Key{
-				sm.CompLockV2Key{
-					ID: "x3000c0s9b0n0",
-				},
-			},
-			ProcessingModel: sm.CLProcessingModelRigid,
-		},
-		dbDeleteError:         nil,
-		expectedDeletePrepare: "",
-		expectedDeleteArgs:    []driver.Value{},
-		dbDeleteReturnCols:    []string{},
-		dbDeleteReturnRows:    [][]driver.Value{},
-		expectedSuccess:       0,
-		expectedFailure:       0,
-		expectErr:             true,
+		dbResult:        0,
+		dbError:         nil,
+		expectedPrepare: regexp.QuoteMeta(deletePowerMapsAllQuery),
+		expectedResult:  0,
+	}, {
+		dbResult:        0,
+		dbError:         sql.ErrNoRows,
+		expectedPrepare: regexp.QuoteMeta(deletePowerMapsAllQuery),
+		expectedResult:  0,
 	}}
 
 	for i, test := range tests {
 		ResetMockDB()
-		rows := sqlmock.NewRows(test.dbDeleteReturnCols)
-		for _, row := range test.dbDeleteReturnRows {
-			rows.AddRow(row...)
-		}
-
-		v1rows := sqlmock.NewRows([]string{"component_id"})
-		v1rows.AddRow([]driver.Value{"x3000c0s9b0n0"}...)
-
-		compRows := sqlmock.NewRows([]string{"id"})
-		compRows.AddRow([]driver.Value{"x3000c0s9b0n0"}...)
-
+		// before we actually execute our api function, we need to expect required DB actions
 		mockPG.ExpectBegin()
-		if test.expectedDeletePrepare == "" && test.dbDeleteError == nil {
-			mockPG.ExpectRollback()
-		} else if test.dbDeleteError != nil {
-			mockPG.ExpectPrepare(test.expectedDeletePrepare).ExpectQuery().WillReturnError(test.dbDeleteError)
+		if test.dbError != nil {
+			mockPG.ExpectPrepare(test.expectedPrepare).ExpectExec().WillReturnError(test.dbError)
 			mockPG.ExpectRollback()
 		} else {
-			mockPG.ExpectPrepare(test.expectedDeletePrepare).ExpectQuery().WithArgs(test.expectedDeleteArgs...).WillReturnRows(rows)
+			mockPG.ExpectPrepare(test.expectedPrepare).ExpectExec().WillReturnResult(sqlmock.NewResult(0, test.dbResult))
 			mockPG.ExpectCommit()
 		}
 
-		results, err := dPG.DeleteCompReservations(test.f)
+		numDelete, err := dPG.DeletePowerMapsAll()
 		// ensure all expectations have been met
 		if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
-			t.Errorf("Test %v Failed: Sql expectations were not met: %s",
-				i, mock_err)
+			t.Errorf("Test %v Failed: Sql expectations were not met: %s", i, mock_err)
 		}
-		if !test.expectErr {
+		if test.dbError == nil {
 			if err != nil {
 				t.Errorf("Test %v Failed: Unexpected error received: %s", i, err)
-			} else {
-				if len(results.Success.ComponentIDs) != test.expectedSuccess {
-					t.Errorf("Test %v Failed: Expected %v Successes. Got %v", i, test.expectedSuccess, len(results.Success.ComponentIDs))
-				} else if len(results.Failure) != test.expectedFailure {
-					t.Errorf("Test %v Failed: Expected %v Failures. Got %v", i, test.expectedFailure, len(results.Failure))
-				}
+			} else if test.expectedResult != numDelete {
+				t.Errorf("Test %v Failed: Expected numDelete '%v'; Recieved numDelete '%v'", i, test.expectedResult, numDelete)
 			}
 		} else if err == nil {
 			t.Errorf("Test %v Failed: Expected an error.", i)
@@ -6778,58 +8546,114 @@ func TestPgDeleteCompReservations(t *testing.T) {
 	}
 }
 
-func TestPgDeleteCompReservationsExpired(t *testing.T) {
+////////////////////////////////////////////////////////////////////////////
+//
+// Job Sync Management
+//
+////////////////////////////////////////////////////////////////////////////
+
+func TestPgInsertJob(t *testing.T) {
+	var dJob1 = &sm.Job{
+		JobData: sm.JobData{
+			Id:       uuid.New().String(),
+			Type:     sm.JobTypeSRFP,
+			Status:   sm.JobNotStarted,
+			Lifetime: 30,
+		},
+		Data: &sm.SrfpJobData{
+			CompId: "x0c0s0b0n0",
+		},
+	}
+	var dJob2 = &sm.Job{
+		JobData: sm.JobData{
+			Id:       uuid.New().String(),
+			Type:     sm.JobTypeSRFP,
+			Status:   sm.JobNotStarted,
+			Lifetime: 30,
+		},
+	}
 
 	sqq := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
 
 	// Note we only use the query here so the args values don't really matter.
-	resDeleteReservation, _, _ := sqq.Delete(compResTable).
-		Where(compResExpireCol + " IS NOT NULL AND NOW() >= " + compResExpireCol).
-		Suffix("RETURNING " + compResCompIdCol).ToSql()
+	dJob1InsertJob, _, _ := sqq.Insert(jobTable).
+		Columns(jobColsNoTS...).
+		Values(sq.Expr("?", dJob1.Id), dJob1.Type, dJob1.Status, dJob1.Lifetime).ToSql()
+
+	dJob1InsertStateRFPollJob, _, _ := sqq.Insert(stateRfPollTable).
+		Columns(stateRfPollCols...).
+		Values(dJob1.Data.(*sm.SrfpJobData).CompId, sq.Expr("?", dJob1.Id)).ToSql()
 
 	tests := []struct {
-		dbDeleteError         error
-		expectedDeletePrepare string
-		dbDeleteReturnCols    []string
-		dbDeleteReturnRows    [][]driver.Value
-		expectedSuccess       int
-		expectErr             bool
+		j                       *sm.Job
+		dbInsertJError          error
+		expectedInsertJPrepare  string
+		expectedInsertJArgs     []driver.Value
+		dbInsertJDError         error
+		expectedInsertJDPrepare string
+		expectedInsertJDArgs    []driver.Value
+		expectErr               bool
 	}{{
-		dbDeleteError:         nil,
-		expectedDeletePrepare: regexp.QuoteMeta(resDeleteReservation),
-		dbDeleteReturnCols:    []string{"component_id"},
-		dbDeleteReturnRows:    [][]driver.Value{
-			[]driver.Value{"x3000c0s9b0n0"},
-		},
-		expectedSuccess:    1,
-		expectErr:          false,
+		j:                       dJob1,
+		dbInsertJError:          nil,
+		expectedInsertJPrepare:  regexp.QuoteMeta(dJob1InsertJob),
+		expectedInsertJArgs:     []driver.Value{AnyUUID{}, dJob1.Type, dJob1.Status, dJob1.Lifetime},
+		dbInsertJDError:         nil,
+		expectedInsertJDPrepare: regexp.QuoteMeta(dJob1InsertStateRFPollJob),
+		expectedInsertJDArgs:    []driver.Value{dJob1.Data.(*sm.SrfpJobData).CompId, AnyUUID{}},
+		expectErr:               false,
+	}, {
+		j:                       dJob1,
+		dbInsertJError:          nil,
+		expectedInsertJPrepare:  regexp.QuoteMeta(dJob1InsertJob),
+		expectedInsertJArgs:     []driver.Value{AnyUUID{}, dJob1.Type, dJob1.Status, dJob1.Lifetime},
+		dbInsertJDError:         ErrHMSDSArgBadID,
+		expectedInsertJDPrepare: regexp.QuoteMeta(dJob1InsertStateRFPollJob),
+		expectedInsertJDArgs:    []driver.Value{dJob1.Data.(*sm.SrfpJobData).CompId, AnyUUID{}},
+		expectErr:               true,
+	}, {
+		j:                       dJob1,
+		dbInsertJError:          ErrHMSDSArgBadID,
+		expectedInsertJPrepare:  regexp.QuoteMeta(dJob1InsertJob),
+		expectedInsertJArgs:     []driver.Value{AnyUUID{}, dJob1.Type, dJob1.Status, dJob1.Lifetime},
+		dbInsertJDError:         nil,
+		expectedInsertJDPrepare: "",
+		expectedInsertJDArgs:    []driver.Value{},
+		expectErr:               true,
+	}, {
+		j:                       dJob2,
+		dbInsertJError:          nil,
+		expectedInsertJPrepare:  regexp.QuoteMeta(dJob1InsertJob),
+		expectedInsertJArgs:     []driver.Value{AnyUUID{}, dJob2.Type, dJob2.Status, dJob2.Lifetime},
+		dbInsertJDError:         ErrHMSDSArgBadID,
+		expectedInsertJDPrepare: "",
+		expectedInsertJDArgs:    []driver.Value{},
+		expectErr:               true,
 	}}
 
 	for i, test := range tests {
 		ResetMockDB()
-		rows := sqlmock.NewRows(test.dbDeleteReturnCols)
-		for _, row := range test.dbDeleteReturnRows {
-			rows.AddRow(row...)
-		}
-
-		v1rows := sqlmock.NewRows([]string{"component_id"})
-		v1rows.AddRow([]driver.Value{"x3000c0s9b0n0"}...)
-
-		compRows := sqlmock.NewRows([]string{"id"})
-		compRows.AddRow([]driver.Value{"x3000c0s9b0n0"}...)
 
 		mockPG.ExpectBegin()
-		if test.expectedDeletePrepare == "" && test.dbDeleteError == nil {
+		if test.expectedInsertJPrepare == "" && test.dbInsertJError != nil {
 			mockPG.ExpectRollback()
-		} else if test.dbDeleteError != nil {
-			mockPG.ExpectPrepare(test.expectedDeletePrepare).ExpectQuery().WillReturnError(test.dbDeleteError)
+		} else if test.dbInsertJError != nil {
+			mockPG.ExpectPrepare(test.expectedInsertJPrepare).ExpectExec().WillReturnError(test.dbInsertJError)
+			mockPG.ExpectRollback()
+		} else if test.expectedInsertJDPrepare == "" && test.dbInsertJDError != nil {
+			mockPG.ExpectPrepare(test.expectedInsertJPrepare).ExpectExec().WithArgs(test.expectedInsertJArgs...).WillReturnResult(sqlmock.NewResult(0, 1))
+			mockPG.ExpectRollback()
+		} else if test.dbInsertJDError != nil {
+			mockPG.ExpectPrepare(test.expectedInsertJPrepare).ExpectExec().WithArgs(test.expectedInsertJArgs...).WillReturnResult(sqlmock.NewResult(0, 1))
+			mockPG.ExpectPrepare(test.expectedInsertJDPrepare).ExpectExec().WillReturnError(test.dbInsertJDError)
 			mockPG.ExpectRollback()
 		} else {
-			mockPG.ExpectPrepare(test.expectedDeletePrepare).ExpectQuery().WillReturnRows(rows)
+			mockPG.ExpectPrepare(test.expectedInsertJPrepare).ExpectExec().WithArgs(test.expectedInsertJArgs...).WillReturnResult(sqlmock.NewResult(0, 1))
+			mockPG.ExpectPrepare(test.expectedInsertJDPrepare).ExpectExec().WithArgs(test.expectedInsertJDArgs...).WillReturnResult(sqlmock.NewResult(0, 1))
 			mockPG.ExpectCommit()
 		}
 
-		results, err := dPG.DeleteCompReservationsExpired()
+		jobId, err := dPG.InsertJob(test.j)
 		// ensure all expectations have been met
 		if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
 			t.Errorf("Test %v Failed: Sql expectations were not met: %s",
@@ -6839,8 +8663,8 @@ func TestPgDeleteCompReservationsExpired(t *testing.T) {
 			if err != nil {
 				t.Errorf("Test %v Failed: Unexpected error received: %s", i, err)
 			} else {
-				if len(results) != test.expectedSuccess {
-					t.Errorf("Test %v Failed: Expected %v Successes. Got %v", i, test.expectedSuccess, len(results))
+				if jobId == "" {
+					t.Errorf("Test %v Failed: Expected a new job id", i)
 				}
 			}
 		} else if err == nil {
@@ -6849,336 +8673,573 @@ func TestPgDeleteCompReservationsExpired(t *testing.T) {
 	}
 }
 
-///////////////////////////////////////////////////////////////////////////////
-// Power Map Query Tests
-///////////////////////////////////////////////////////////////////////////////
+func TestPgUpdateJob(t *testing.T) {
+	id := uuid.New().String()
+	status := sm.JobInProgress
 
-func TestPgGetPowerMapByID(t *testing.T) {
-	columns := []string{"id", "powered_by"}
+	sqq := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+
+	dJob1Update1, _, _ := sqq.Update("").
+		Table(jobTable).
+		Where("id = ?", id).
+		Set(jobStatusCol, status).
+		Set(jobLastUpdateCol, "NOW()").ToSql()
+
+	dJob1Update2, _, _ := sqq.Update("").
+		Table(jobTable).
+		Where("id = ?", id).
+		Set(jobLastUpdateCol, "NOW()").ToSql()
 
 	tests := []struct {
-		id               string
-		dbRows           [][]driver.Value
-		dbError          error
-		expectedPrepare  string
-		expectedArgs     []driver.Value
-		expectedPowerMap *sm.PowerMap
-		expectedErr      error
+		id                    string
+		status                string
+		dbUpdateError         error
+		expectedUpdatePrepare string
+		expectedUpdateArgs    []driver.Value
 	}{{
-		id: "x0c0s21b0",
-		dbRows: [][]driver.Value{
-			[]driver.Value{"x0c0s21b0", pq.Array([]string{"x0m0p0j10", "x0m0p0j11"})},
-		},
-		dbError:          nil,
-		expectedPrepare:  regexp.QuoteMeta(getPowerMapByIDQuery),
-		expectedArgs:     []driver.Value{"x0c0s21b0"},
-		expectedPowerMap: &sm.PowerMap{ID: "x0c0s21b0", PoweredBy: []string{"x0m0p0j10", "x0m0p0j11"}},
-		expectedErr:      nil,
+		id:                    id,
+		status:                status,
+		dbUpdateError:         nil,
+		expectedUpdatePrepare: regexp.QuoteMeta(dJob1Update1),
+		expectedUpdateArgs:    []driver.Value{status, "NOW()", id},
 	}, {
-		id:               "",
-		dbRows:           nil,
-		dbError:          nil,
-		expectedPrepare:  "",
-		expectedArgs:     nil,
-		expectedPowerMap: nil,
-		expectedErr:      ErrHMSDSArgMissing,
+		id:                    id,
+		status:                "",
+		dbUpdateError:         nil,
+		expectedUpdatePrepare: regexp.QuoteMeta(dJob1Update2),
+		expectedUpdateArgs:    []driver.Value{"NOW()", id},
 	}, {
-		id:               "x0c0s21b0",
-		dbRows:           nil,
-		dbError:          sql.ErrNoRows,
-		expectedPrepare:  regexp.QuoteMeta(getPowerMapByIDQuery),
-		expectedArgs:     []driver.Value{"x0c0s21b0"},
-		expectedPowerMap: nil,
-		expectedErr:      nil,
+		id:                    id,
+		status:                "",
+		dbUpdateError:         ErrHMSDSArgBadID,
+		expectedUpdatePrepare: regexp.QuoteMeta(dJob1Update2),
+		expectedUpdateArgs:    []driver.Value{"NOW()", id},
+	}, {
+		id:                    "",
+		status:                "",
+		dbUpdateError:         ErrHMSDSArgBadID,
+		expectedUpdatePrepare: "",
+		expectedUpdateArgs:    []driver.Value{},
 	}}
 
 	for i, test := range tests {
 		ResetMockDB()
-		// before we actually execute our api function, we need to expect required DB actions
-		rows := sqlmock.NewRows(columns)
-		for _, row := range test.dbRows {
-			rows.AddRow(row...)
-		}
-
 		mockPG.ExpectBegin()
-		if test.dbError != nil {
-			mockPG.ExpectPrepare(ToPGQueryArgs(test.expectedPrepare)).ExpectQuery().WillReturnError(test.dbError)
+		if test.expectedUpdatePrepare == "" && test.dbUpdateError != nil {
 			mockPG.ExpectRollback()
-		} else if test.expectedErr != nil {
+		} else if test.dbUpdateError != nil {
+			mockPG.ExpectPrepare(test.expectedUpdatePrepare).ExpectExec().WillReturnError(test.dbUpdateError)
 			mockPG.ExpectRollback()
 		} else {
-			mockPG.ExpectPrepare(ToPGQueryArgs(test.expectedPrepare)).ExpectQuery().WithArgs(test.expectedArgs...).WillReturnRows(rows)
+			mockPG.ExpectPrepare(test.expectedUpdatePrepare).ExpectExec().WithArgs(test.expectedUpdateArgs...).WillReturnResult(sqlmock.NewResult(0, 1))
 			mockPG.ExpectCommit()
 		}
 
-		m, err := dPG.GetPowerMapByID(test.id)
-		// ensure all expectations have been met
+		_, err := dPG.UpdateJob(test.id, test.status)
 		if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
-			t.Errorf("Test %v Failed: Sql expectations were not met: %s", i, mock_err)
+			t.Errorf("Test %v Failed: Sql expectations were not met: %s",
+				i, mock_err)
 		}
-		if test.dbError == nil && test.expectedErr == nil {
-			if err != nil {
-				t.Errorf("Test %v Failed: Unexpected error received: %s", i, err)
-			} else if !reflect.DeepEqual(test.expectedPowerMap, m) {
-				t.Errorf("Test %v Failed: Expected power map '%v'; Recieved power map '%v'", i, test.expectedPowerMap, m)
-			}
-		} else if err == nil {
+		if err != nil && test.dbUpdateError == nil {
+			t.Errorf("Test %v Failed: Unexpected error received: %s", i, err)
+		} else if err == nil && test.dbUpdateError != nil {
 			t.Errorf("Test %v Failed: Expected an error.", i)
 		}
 	}
 }
 
-func TestPgGetPowerMapsAll(t *testing.T) {
-	columns := []string{"id", "powered_by"}
+func TestPgUpdateJobs(t *testing.T) {
+	id1 := uuid.New().String()
+	id2 := uuid.New().String()
+	ids := []string{id1, id2}
+
+	sqq := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+
+	dJobsUpdate, _, _ := sqq.Update("").
+		Table(jobTable).
+		Where(sq.Eq{jobIdCol: ids}).
+		Set(jobLastUpdateCol, "NOW()").ToSql()
 
 	tests := []struct {
-		dbRows          [][]driver.Value
-		dbError         error
-		expectedPrepare string
-		expectedPMs     []*sm.PowerMap
+		ids                   []string
+		dbUpdateError         error
+		expectedUpdatePrepare string
+		expectedUpdateArgs    []driver.Value
+		expectedNum           int64
 	}{{
-		dbRows: [][]driver.Value{
-			[]driver.Value{"x0c0s21b0", pq.Array([]string{"x0m0p0j10", "x0m0p0j11"})},
-			[]driver.Value{"x0c0s22b0", pq.Array([]string{"x0m0p0j12", "x0m0p0j13"})},
-		},
-		dbError:         nil,
-		expectedPrepare: regexp.QuoteMeta(getPowerMapsAllQuery),
-		expectedPMs: []*sm.PowerMap{
-			&sm.PowerMap{ID: "x0c0s21b0", PoweredBy: []string{"x0m0p0j10", "x0m0p0j11"}},
-			&sm.PowerMap{ID: "x0c0s22b0", PoweredBy: []string{"x0m0p0j12", "x0m0p0j13"}},
-		},
+		ids:                   ids,
+		dbUpdateError:         nil,
+		expectedUpdatePrepare: regexp.QuoteMeta(dJobsUpdate),
+		expectedUpdateArgs:    []driver.Value{"NOW()", id1, id2},
+		expectedNum:           2,
 	}, {
-		dbRows:          nil,
-		dbError:         sql.ErrNoRows,
-		expectedPrepare: regexp.QuoteMeta(getPowerMapsAllQuery),
-		expectedPMs:     nil,
+		ids:                   ids,
+		dbUpdateError:         ErrHMSDSArgBadID,
+		expectedUpdatePrepare: regexp.QuoteMeta(dJobsUpdate),
+		expectedUpdateArgs:    []driver.Value{"NOW()", id1, id2},
+	}, {
+		ids:                   []string{},
+		dbUpdateError:         ErrHMSDSArgEmpty,
+		expectedUpdatePrepare: "",
+		expectedUpdateArgs:    []driver.Value{},
 	}}
 
 	for i, test := range tests {
 		ResetMockDB()
-		// before we actually execute our api function, we need to expect required DB actions
-		rows := sqlmock.NewRows(columns)
-		for _, row := range test.dbRows {
-			rows.AddRow(row...)
-		}
-
 		mockPG.ExpectBegin()
-		if test.dbError != nil {
-			mockPG.ExpectPrepare(test.expectedPrepare).ExpectQuery().WillReturnError(test.dbError)
+		if test.expectedUpdatePrepare == "" && test.dbUpdateError != nil {
+			mockPG.ExpectRollback()
+		} else if test.dbUpdateError != nil {
+			mockPG.ExpectPrepare(test.expectedUpdatePrepare).ExpectExec().WillReturnError(test.dbUpdateError)
 			mockPG.ExpectRollback()
 		} else {
-			mockPG.ExpectPrepare(test.expectedPrepare).ExpectQuery().WillReturnRows(rows)
+			mockPG.ExpectPrepare(test.expectedUpdatePrepare).ExpectExec().WithArgs(test.expectedUpdateArgs...).WillReturnResult(sqlmock.NewResult(0, test.expectedNum))
 			mockPG.ExpectCommit()
 		}
 
-		ms, err := dPG.GetPowerMapsAll()
-		// ensure all expectations have been met
+		num, err := dPG.UpdateJobs(test.ids, "")
 		if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
-			t.Errorf("Test %v Failed: Sql expectations were not met: %s", i, mock_err)
+			t.Errorf("Test %v Failed: Sql expectations were not met: %s",
+				i, mock_err)
 		}
-		if test.dbError == nil {
-			if err != nil {
-				t.Errorf("Test %v Failed: Unexpected error received: %s", i, err)
-			} else if !reflect.DeepEqual(test.expectedPMs, ms) {
-				t.Errorf("Test %v Failed: Expected power maps '%v'; Recieved power maps '%v'", i, test.expectedPMs, ms)
-			}
-		} else if err == nil {
+		if err != nil && test.dbUpdateError == nil {
+			t.Errorf("Test %v Failed: Unexpected error received: %s", i, err)
+		} else if err == nil && test.dbUpdateError != nil {
 			t.Errorf("Test %v Failed: Expected an error.", i)
+		} else if num != test.expectedNum {
+			t.Errorf("Test %v Failed: Expected %d updates; Received %d",
+				i, test.expectedNum, num)
 		}
 	}
 }
 
-func TestPgInsertPowerMap(t *testing.T) {
+func TestPgGetJob(t *testing.T) {
+	var dJob1 = &sm.Job{
+		JobData: sm.JobData{
+			Id:         uuid.New().String(),
+			Type:       sm.JobTypeSRFP,
+			Status:     sm.JobNotStarted,
+			LastUpdate: "",
+			Lifetime:   30,
+		},
+		Data: &sm.SrfpJobData{
+			CompId: "x0c0s0b0n0",
+		},
+	}
+
+	columns := jobCols
+
+	dataCols := stateRfPollColsId
+
+	sqq := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+
+	dJob1Query1, _, _ := sqq.Select(jobCols...).
+		From(jobTable).
+		Where("id = ?", dJob1.Id).ToSql()
+
+	dJob1JDQuery1, _, _ := sqq.Select(stateRfPollColsId...).
+		From(stateRfPollTable).
+		Where("job_id = ?", dJob1.Id).ToSql()
+
 	tests := []struct {
-		m               *sm.PowerMap
-		dbError         error
-		expectedPrepare string
-		expectedArgs    []driver.Value
-		expectedErr     error
+		jobId                  string
+		dbColumns              []string
+		dbRows                 [][]driver.Value
+		dbQueryError           error
+		expectedQueryPrepare   string
+		expectedQueryArgs      []driver.Value
+		dbJDColumns            []string
+		dbJDRows               [][]driver.Value
+		dbJDQueryError         error
+		expectedJDQueryPrepare string
+		expectedJDQueryArgs    []driver.Value
+		expectedJ              *sm.Job
 	}{{
-		m:               &sm.PowerMap{ID: "x0c0s21b0", PoweredBy: []string{"x0m0p0j10", "x0m0p0j11"}},
-		dbError:         nil,
-		expectedPrepare: regexp.QuoteMeta(insertPgPowerMapQuery),
-		expectedArgs:    []driver.Value{"x0c0s21b0", pq.Array([]string{"x0m0p0j10", "x0m0p0j11"})},
-		expectedErr:     nil,
+		jobId:     dJob1.Id,
+		dbColumns: columns,
+		dbRows: [][]driver.Value{
+			[]driver.Value{dJob1.Id, dJob1.Type, dJob1.Status, dJob1.LastUpdate, dJob1.Lifetime},
+		},
+		dbQueryError:         nil,
+		expectedQueryPrepare: regexp.QuoteMeta(dJob1Query1),
+		expectedQueryArgs:    []driver.Value{dJob1.Id},
+		dbJDColumns:          dataCols,
+		dbJDRows: [][]driver.Value{
+			[]driver.Value{dJob1.Data.(*sm.SrfpJobData).CompId},
+		},
+		dbJDQueryError:         nil,
+		expectedJDQueryPrepare: regexp.QuoteMeta(dJob1JDQuery1),
+		expectedJDQueryArgs:    []driver.Value{dJob1.Id},
+		expectedJ:              dJob1,
 	}, {
-		m:               &sm.PowerMap{ID: "x0c0s21b0", PoweredBy: []string{"x0m0p0j10", "x0m0p0j11"}},
-		dbError:         sql.ErrNoRows,
-		expectedPrepare: regexp.QuoteMeta(insertPgPowerMapQuery),
-		expectedArgs:    []driver.Value{"x0c0s21b0", pq.Array([]string{"x0m0p0j10", "x0m0p0j11"})},
-		expectedErr:     nil,
+		jobId:     dJob1.Id,
+		dbColumns: columns,
+		dbRows: [][]driver.Value{
+			[]driver.Value{dJob1.Id, dJob1.Type, dJob1.Status, dJob1.LastUpdate, dJob1.Lifetime},
+		},
+		dbQueryError:         nil,
+		expectedQueryPrepare: regexp.QuoteMeta(dJob1Query1),
+		expectedQueryArgs:    []driver.Value{dJob1.Id},
+		dbJDColumns:          dataCols,
+		dbJDRows: [][]driver.Value{
+			[]driver.Value{dJob1.Data.(*sm.SrfpJobData).CompId},
+		},
+		dbJDQueryError:         ErrHMSDSArgBadID,
+		expectedJDQueryPrepare: regexp.QuoteMeta(dJob1JDQuery1),
+		expectedJDQueryArgs:    []driver.Value{dJob1.Id},
+		expectedJ:              nil,
 	}, {
-		m:               nil,
-		dbError:         nil,
-		expectedPrepare: "",
-		expectedArgs:    nil,
-		expectedErr:     ErrHMSDSArgNil,
+		jobId:     dJob1.Id,
+		dbColumns: columns,
+		dbRows: [][]driver.Value{
+			[]driver.Value{dJob1.Id, dJob1.Type, dJob1.Status, dJob1.LastUpdate, dJob1.Lifetime},
+		},
+		dbQueryError:         ErrHMSDSArgBadID,
+		expectedQueryPrepare: regexp.QuoteMeta(dJob1Query1),
+		expectedQueryArgs:    []driver.Value{dJob1.Id},
+		dbJDColumns:          dataCols,
+		dbJDRows: [][]driver.Value{
+			[]driver.Value{dJob1.Data.(*sm.SrfpJobData).CompId},
+		},
+		dbJDQueryError:         nil,
+		expectedJDQueryPrepare: "",
+		expectedJDQueryArgs:    []driver.Value{},
+		expectedJ:              nil,
+	}, {
+		jobId:     "",
+		dbColumns: columns,
+		dbRows: [][]driver.Value{
+			[]driver.Value{dJob1.Id, dJob1.Type, dJob1.Status, dJob1.LastUpdate, dJob1.Lifetime},
+		},
+		dbQueryError:         ErrHMSDSArgEmpty,
+		expectedQueryPrepare: "",
+		expectedQueryArgs:    []driver.Value{},
+		dbJDColumns:          dataCols,
+		dbJDRows: [][]driver.Value{
+			[]driver.Value{dJob1.Data.(*sm.SrfpJobData).CompId},
+		},
+		dbJDQueryError:         nil,
+		expectedJDQueryPrepare: "",
+		expectedJDQueryArgs:    []driver.Value{},
+		expectedJ:              nil,
 	}}
 
 	for i, test := range tests {
 		ResetMockDB()
 		// before we actually execute our api function, we need to expect required DB actions
+		rows := sqlmock.NewRows(test.dbColumns)
+		for _, row := range test.dbRows {
+			rows.AddRow(row...)
+		}
+		jdrows := sqlmock.NewRows(test.dbJDColumns)
+		for _, jdrow := range test.dbJDRows {
+			jdrows.AddRow(jdrow...)
+		}
+
 		mockPG.ExpectBegin()
-		if test.dbError != nil {
-			mockPG.ExpectPrepare(ToPGQueryArgs(test.expectedPrepare)).ExpectExec().WillReturnError(test.dbError)
+		if test.expectedQueryPrepare == "" && test.dbQueryError != nil {
 			mockPG.ExpectRollback()
-		} else if test.expectedErr != nil {
+		} else if test.dbQueryError != nil {
+			mockPG.ExpectPrepare(test.expectedQueryPrepare).ExpectQuery().WillReturnError(test.dbQueryError)
+			mockPG.ExpectRollback()
+		} else if test.dbJDQueryError != nil {
+			mockPG.ExpectPrepare(test.expectedQueryPrepare).ExpectQuery().WithArgs(test.expectedQueryArgs...).WillReturnRows(rows)
+			mockPG.ExpectPrepare(test.expectedJDQueryPrepare).ExpectQuery().WillReturnError(test.dbJDQueryError)
 			mockPG.ExpectRollback()
 		} else {
-			mockPG.ExpectPrepare(ToPGQueryArgs(test.expectedPrepare)).ExpectExec().WithArgs(test.expectedArgs...).WillReturnResult(sqlmock.NewResult(0, 1))
+			mockPG.ExpectPrepare(test.expectedQueryPrepare).ExpectQuery().WithArgs(test.expectedQueryArgs...).WillReturnRows(rows)
+			mockPG.ExpectPrepare(test.expectedJDQueryPrepare).ExpectQuery().WithArgs(test.expectedJDQueryArgs...).WillReturnRows(jdrows)
 			mockPG.ExpectCommit()
 		}
 
-		err := dPG.InsertPowerMap(test.m)
+		j, err := dPG.GetJob(test.jobId)
 		// ensure all expectations have been met
 		if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
 			t.Errorf("Test %v Failed: Sql expectations were not met: %s", i, mock_err)
 		}
-		if test.dbError == nil && test.expectedErr == nil {
+		if test.dbQueryError == nil && test.dbJDQueryError == nil {
 			if err != nil {
 				t.Errorf("Test %v Failed: Unexpected error received: %s", i, err)
 			}
+			if j == nil {
+				t.Errorf("Test %v Failed: Expected non-nil Job", i)
+			} else if !reflect.DeepEqual(j, test.expectedJ) {
+				t.Errorf("Test %v Failed: Expected Job %v (got %v)",
+					i, j, test.expectedJ)
+			}
 		} else if err == nil {
 			t.Errorf("Test %v Failed: Expected an error.", i)
 		}
 	}
 }
 
-func TestPgInsertPowerMaps(t *testing.T) {
+func TestPgGetJobs(t *testing.T) {
+	var dJob1 = &sm.Job{
+		JobData: sm.JobData{
+			Id:         uuid.New().String(),
+			Type:       sm.JobTypeSRFP,
+			Status:     sm.JobNotStarted,
+			LastUpdate: "",
+			Lifetime:   30,
+		},
+		Data: &sm.SrfpJobData{
+			CompId: "x0c0s0b0n0",
+		},
+	}
+
+	columns := addAliasToCols(jobAlias, jobCols, jobCols)
+
+	dataCols := stateRfPollColsId
+
+	sqq := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+
+	dJob1Query1, _, _ := sqq.Select(columns...).
+		From(jobTable + " " + jobAlias).
+		Where(sq.Eq{jobIdColAlias: []string{dJob1.Id}}).ToSql()
+
+	dJob1JDQuery1, _, _ := sqq.Select(stateRfPollColsId...).
+		From(stateRfPollTable).
+		Where("job_id = ?", dJob1.Id).ToSql()
+
+	dJob1Query2, _, _ := sqq.Select(columns...).
+		From(jobTable + " " + jobAlias).
+		Where(sq.Eq{jobTypeColAlias: []string{dJob1.Type}}).ToSql()
+
+	dJob1JDQuery2, _, _ := sqq.Select(stateRfPollColsId...).
+		From(stateRfPollTable).
+		Where("job_id = ?", dJob1.Id).ToSql()
+
+	dJob1Query3, _, _ := sqq.Select(columns...).
+		From(jobTable + " " + jobAlias).
+		Where(sq.Eq{jobStatusColAlias: []string{dJob1.Status}}).ToSql()
+
+	dJob1JDQuery3, _, _ := sqq.Select(stateRfPollColsId...).
+		From(stateRfPollTable).
+		Where("job_id = ?", dJob1.Id).ToSql()
+
+	dJob1Query4, _, _ := sqq.Select(columns...).
+		From(jobTable + " " + jobAlias).
+		Where("NOW()-" + jobLastUpdateColAlias +
+			" >= (" + jobLifetimeColAlias + " * '1 sec'::interval)").ToSql()
+
+	dJob1JDQuery4, _, _ := sqq.Select(stateRfPollColsId...).
+		From(stateRfPollTable).
+		Where("job_id = ?", dJob1.Id).ToSql()
+
+	dJob1Query5, _, _ := sqq.Select(columns...).
+		From(jobTable + " " + jobAlias).
+		Join(stateRfPollTable + " " + stateRfPollAlias +
+			" ON " + stateRfPollAlias + "." + stateRfPollJobIdCol +
+			" = " + jobIdColAlias).
+		Where(sq.Eq{stateRfPollAlias + "." + stateRfPollCmpIdCol: []string{"x0c0s0b0n0"}}).
+		Where("NOW()-" + jobLastUpdateColAlias +
+			" < (" + jobLifetimeColAlias + " * '1 sec'::interval)").ToSql()
+
+	dJob1JDQuery5, _, _ := sqq.Select(stateRfPollColsId...).
+		From(stateRfPollTable).
+		Where("job_id = ?", dJob1.Id).ToSql()
+
 	tests := []struct {
-		ms              []sm.PowerMap
-		dbError         error
-		expectedPrepare string
-		expectedArgs    [][]driver.Value
-		expectedErr     error
+		f_opts                 []JobSyncFiltFunc
+		dbColumns              []string
+		dbRows                 [][]driver.Value
+		dbQueryError           error
+		expectedQueryPrepare   string
+		expectedQueryArgs      []driver.Value
+		dbJDColumns            []string
+		dbJDRows               [][]driver.Value
+		dbJDQueryError         error
+		expectedJDQueryPrepare string
+		expectedJDQueryArgs    []driver.Value
+		expectedJ              *sm.Job
 	}{{
-		ms: []sm.PowerMap{
-			sm.PowerMap{ID: "x0c0s21b0", PoweredBy: []string{"x0m0p0j10", "x0m0p0j11"}},
-			sm.PowerMap{ID: "x0c0s22b0", PoweredBy: []string{"x0m0p0j12", "x0m0p0j13"}},
+		f_opts:    []JobSyncFiltFunc{JS_ID(dJob1.Id)},
+		dbColumns: columns,
+		dbRows: [][]driver.Value{
+			[]driver.Value{dJob1.Id, dJob1.Type, dJob1.Status, dJob1.LastUpdate, dJob1.Lifetime},
 		},
-		dbError:         nil,
-		expectedPrepare: regexp.QuoteMeta(insertPgPowerMapQuery),
-		expectedArgs: [][]driver.Value{
-			[]driver.Value{"x0c0s21b0", pq.Array([]string{"x0m0p0j10", "x0m0p0j11"})},
-			[]driver.Value{"x0c0s22b0", pq.Array([]string{"x0m0p0j12", "x0m0p0j13"})},
+		dbQueryError:         nil,
+		expectedQueryPrepare: regexp.QuoteMeta(dJob1Query1),
+		expectedQueryArgs:    []driver.Value{dJob1.Id},
+		dbJDColumns:          dataCols,
+		dbJDRows: [][]driver.Value{
+			[]driver.Value{dJob1.Data.(*sm.SrfpJobData).CompId},
 		},
-		expectedErr: nil,
+		dbJDQueryError:         nil,
+		expectedJDQueryPrepare: regexp.QuoteMeta(dJob1JDQuery1),
+		expectedJDQueryArgs:    []driver.Value{dJob1.Id},
+		expectedJ:              dJob1,
 	}, {
-		ms: []sm.PowerMap{
-			sm.PowerMap{ID: "x0c0s21b0", PoweredBy: []string{"x0m0p0j10", "x0m0p0j11"}},
+		f_opts:    []JobSyncFiltFunc{JS_Type(dJob1.Type)},
+		dbColumns: columns,
+		dbRows: [][]driver.Value{
+			[]driver.Value{dJob1.Id, dJob1.Type, dJob1.Status, dJob1.LastUpdate, dJob1.Lifetime},
 		},
-		dbError:         nil,
-		expectedPrepare: regexp.QuoteMeta(insertPgPowerMapQuery),
-		expectedArgs: [][]driver.Value{
-			[]driver.Value{"x0c0s21b0", pq.Array([]string{"x0m0p0j10", "x0m0p0j11"})},
+		dbQueryError:         nil,
+		expectedQueryPrepare: regexp.QuoteMeta(dJob1Query2),
+		expectedQueryArgs:    []driver.Value{dJob1.Type},
+		dbJDColumns:          dataCols,
+		dbJDRows: [][]driver.Value{
+			[]driver.Value{dJob1.Data.(*sm.SrfpJobData).CompId},
 		},
-		expectedErr: nil,
+		dbJDQueryError:         nil,
+		expectedJDQueryPrepare: regexp.QuoteMeta(dJob1JDQuery2),
+		expectedJDQueryArgs:    []driver.Value{dJob1.Id},
+		expectedJ:              dJob1,
 	}, {
-		ms: []sm.PowerMap{
-			sm.PowerMap{ID: "x0c0s21b0", PoweredBy: []string{"x0m0p0j10", "x0m0p0j11"}},
+		f_opts:    []JobSyncFiltFunc{JS_Status(dJob1.Status)},
+		dbColumns: columns,
+		dbRows: [][]driver.Value{
+			[]driver.Value{dJob1.Id, dJob1.Type, dJob1.Status, dJob1.LastUpdate, dJob1.Lifetime},
 		},
-		dbError:         sql.ErrNoRows,
-		expectedPrepare: regexp.QuoteMeta(insertPgPowerMapQuery),
-		expectedArgs: [][]driver.Value{
-			[]driver.Value{"x0c0s21b0", pq.Array([]string{"x0m0p0j10", "x0m0p0j11"})},
+		dbQueryError:         nil,
+		expectedQueryPrepare: regexp.QuoteMeta(dJob1Query3),
+		expectedQueryArgs:    []driver.Value{dJob1.Status},
+		dbJDColumns:          dataCols,
+		dbJDRows: [][]driver.Value{
+			[]driver.Value{dJob1.Data.(*sm.SrfpJobData).CompId},
 		},
-		expectedErr: nil,
+		dbJDQueryError:         nil,
+		expectedJDQueryPrepare: regexp.QuoteMeta(dJob1JDQuery3),
+		expectedJDQueryArgs:    []driver.Value{dJob1.Id},
+		expectedJ:              dJob1,
+	}, {
+		f_opts:    []JobSyncFiltFunc{JS_Expired},
+		dbColumns: columns,
+		dbRows: [][]driver.Value{
+			[]driver.Value{dJob1.Id, dJob1.Type, dJob1.Status, dJob1.LastUpdate, dJob1.Lifetime},
+		},
+		dbQueryError:         nil,
+		expectedQueryPrepare: regexp.QuoteMeta(dJob1Query4),
+		expectedQueryArgs:    []driver.Value{},
+		dbJDColumns:          dataCols,
+		dbJDRows: [][]driver.Value{
+			[]driver.Value{dJob1.Data.(*sm.SrfpJobData).CompId},
+		},
+		dbJDQueryError:         nil,
+		expectedJDQueryPrepare: regexp.QuoteMeta(dJob1JDQuery4),
+		expectedJDQueryArgs:    []driver.Value{dJob1.Id},
+		expectedJ:              dJob1,
+	}, {
+		f_opts:    []JobSyncFiltFunc{JS_CompIDs([]string{"x0c0s0b0n0"}), JS_NotExpired},
+		dbColumns: columns,
+		dbRows: [][]driver.Value{
+			[]driver.Value{dJob1.Id, dJob1.Type, dJob1.Status, dJob1.LastUpdate, dJob1.Lifetime},
+		},
+		dbQueryError:         nil,
+		expectedQueryPrepare: regexp.QuoteMeta(dJob1Query5),
+		expectedQueryArgs:    []driver.Value{"x0c0s0b0n0"},
+		dbJDColumns:          dataCols,
+		dbJDRows: [][]driver.Value{
+			[]driver.Value{dJob1.Data.(*sm.SrfpJobData).CompId},
+		},
+		dbJDQueryError:         nil,
+		expectedJDQueryPrepare: regexp.QuoteMeta(dJob1JDQuery5),
+		expectedJDQueryArgs:    []driver.Value{dJob1.Id},
+		expectedJ:              dJob1,
 	}}
 
 	for i, test := range tests {
 		ResetMockDB()
 		// before we actually execute our api function, we need to expect required DB actions
-		mockPG.ExpectBegin()
-		if test.dbError != nil {
-			mockPG.ExpectPrepare(ToPGQueryArgs(test.expectedPrepare)).ExpectExec().WillReturnError(test.dbError)
+		rows := sqlmock.NewRows(test.dbColumns)
+		for _, row := range test.dbRows {
+			rows.AddRow(row...)
+		}
+		jdrows := sqlmock.NewRows(test.dbJDColumns)
+		for _, jdrow := range test.dbJDRows {
+			jdrows.AddRow(jdrow...)
+		}
+
+		mockPG.ExpectBegin()
+		if test.expectedQueryPrepare == "" && test.dbQueryError != nil {
 			mockPG.ExpectRollback()
-		} else if test.expectedErr != nil {
+		} else if test.dbQueryError != nil {
+			mockPG.ExpectPrepare(test.expectedQueryPrepare).ExpectQuery().WillReturnError(test.dbQueryError)
+			mockPG.ExpectRollback()
+		} else if test.dbJDQueryError != nil {
+			if len(test.expectedQueryArgs) == 0 {
+				mockPG.ExpectPrepare(test.expectedQueryPrepare).ExpectQuery().WillReturnRows(rows)
+			} else {
+				mockPG.ExpectPrepare(test.expectedQueryPrepare).ExpectQuery().WithArgs(test.expectedQueryArgs...).WillReturnRows(rows)
+			}
+			mockPG.ExpectPrepare(test.expectedJDQueryPrepare).ExpectQuery().WillReturnError(test.dbJDQueryError)
 			mockPG.ExpectRollback()
 		} else {
-			for j, args := range test.expectedArgs {
-				if j == 0 {
-					mockPG.ExpectPrepare(ToPGQueryArgs(test.expectedPrepare)).ExpectExec().WithArgs(args...).WillReturnResult(sqlmock.NewResult(0, 1))
-				} else {
-					mockPG.ExpectExec(ToPGQueryArgs(test.expectedPrepare)).WithArgs(args...).WillReturnResult(sqlmock.NewResult(0, 1))
-				}
+			if len(test.expectedQueryArgs) == 0 {
+				mockPG.ExpectPrepare(test.expectedQueryPrepare).ExpectQuery().WillReturnRows(rows)
+			} else {
+				mockPG.ExpectPrepare(test.expectedQueryPrepare).ExpectQuery().WithArgs(test.expectedQueryArgs...).WillReturnRows(rows)
 			}
+			mockPG.ExpectPrepare(test.expectedJDQueryPrepare).ExpectQuery().WithArgs(test.expectedJDQueryArgs...).WillReturnRows(jdrows)
 			mockPG.ExpectCommit()
 		}
 
-		err := dPG.InsertPowerMaps(test.ms)
+		js, err := dPG.GetJobs(test.f_opts...)
 		// ensure all expectations have been met
 		if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
 			t.Errorf("Test %v Failed: Sql expectations were not met: %s", i, mock_err)
 		}
-		if test.dbError == nil && test.expectedErr == nil {
+		if test.dbQueryError == nil && test.dbJDQueryError == nil {
 			if err != nil {
 				t.Errorf("Test %v Failed: Unexpected error received: %s", i, err)
 			}
+			if len(js) == 0 {
+				t.Errorf("Test %v Failed: Expected non-empty list of Jobs", i)
+			} else if !reflect.DeepEqual(js[0], test.expectedJ) {
+				t.Errorf("Test %v Failed: Expected Job %v (got %v)",
+					i, js[0], test.expectedJ)
+			}
 		} else if err == nil {
 			t.Errorf("Test %v Failed: Expected an error.", i)
 		}
 	}
 }
 
-func TestPgDeletePowerMapByID(t *testing.T) {
+func TestPgUpsertServiceInstance(t *testing.T) {
+	started, _ := time.Parse(time.RFC3339, "2026-10-19T11:00:00Z")
+
+	sqq := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	upsert, _, _ := sqq.Insert(serviceInstTable).
+		Columns(serviceInstIdCol, serviceInstStartedCol,
+			serviceInstLastHeartbeatCol, serviceInstLeaderCol).
+		Values("smd-1", started, sq.Expr("NOW()"), true).
+		Suffix("ON CONFLICT(id) DO UPDATE SET started = EXCLUDED.started, " +
+			"last_heartbeat = EXCLUDED.last_heartbeat, leader = EXCLUDED.leader").
+		ToSql()
+
 	tests := []struct {
-		id              string
-		dbResult        int64
-		dbError         error
-		expectedPrepare string
-		expectedArgs    []driver.Value
-		expectedResult  bool
-		expectedErr     error
+		id      string
+		dbError error
+		noQuery bool
 	}{{
-		id:              "x0c0s21b0",
-		dbResult:        1,
-		dbError:         nil,
-		expectedPrepare: regexp.QuoteMeta(deletePowerMapByIDQuery),
-		expectedArgs:    []driver.Value{"x0c0s21b0"},
-		expectedResult:  true,
-		expectedErr:     nil,
+		id: "smd-1",
 	}, {
-		id:              "",
-		dbResult:        0,
-		dbError:         nil,
-		expectedPrepare: "",
-		expectedArgs:    nil,
-		expectedResult:  false,
-		expectedErr:     ErrHMSDSArgNil,
+		id:      "smd-1",
+		dbError: sql.ErrConnDone,
 	}, {
-		id:              "x0c0s21b0",
-		dbResult:        0,
-		dbError:         sql.ErrNoRows,
-		expectedPrepare: regexp.QuoteMeta(deletePowerMapByIDQuery),
-		expectedArgs:    []driver.Value{"x0c0s21b0"},
-		expectedResult:  false,
-		expectedErr:     nil,
+		id:      "",
+		noQuery: true,
 	}}
 
 	for i, test := range tests {
 		ResetMockDB()
-		// before we actually execute our api function, we need to expect required DB actions
-		mockPG.ExpectBegin()
-		if test.dbError != nil {
-			mockPG.ExpectPrepare(ToPGQueryArgs(test.expectedPrepare)).ExpectExec().WillReturnError(test.dbError)
-			mockPG.ExpectRollback()
-		} else if test.expectedErr != nil {
-			mockPG.ExpectRollback()
-		} else {
-			mockPG.ExpectPrepare(ToPGQueryArgs(test.expectedPrepare)).ExpectExec().WithArgs(test.expectedArgs...).WillReturnResult(sqlmock.NewResult(0, test.dbResult))
-			mockPG.ExpectCommit()
+		if !test.noQuery {
+			exec := mockPG.ExpectPrepare(regexp.QuoteMeta(upsert)).ExpectExec().
+				WithArgs(test.id, started, true)
+			if test.dbError != nil {
+				exec.WillReturnError(test.dbError)
+			} else {
+				exec.WillReturnResult(sqlmock.NewResult(0, 1))
+			}
 		}
-
-		didDelete, err := dPG.DeletePowerMapByID(test.id)
-		// ensure all expectations have been met
+		err := dPG.UpsertServiceInstance(test.id, started, true)
 		if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
 			t.Errorf("Test %v Failed: Sql expectations were not met: %s", i, mock_err)
 		}
-		if test.dbError == nil && test.expectedErr == nil {
+		if test.dbError == nil && !test.noQuery {
 			if err != nil {
 				t.Errorf("Test %v Failed: Unexpected error received: %s", i, err)
-			} else if test.expectedResult != didDelete {
-				t.Errorf("Test %v Failed: Expected didDelete '%v'; Recieved didDelete '%v'", i, test.expectedResult, didDelete)
 			}
 		} else if err == nil {
 			t.Errorf("Test %v Failed: Expected an error.", i)
@@ -7186,52 +9247,55 @@ func TestPgDeletePowerMapByID(t *testing.T) {
 	}
 }
 
-func TestPgDeletePowerMapsAll(t *testing.T) {
+func TestPgGetServiceInstances(t *testing.T) {
+	sqq := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	query, _, _ := sqq.Select(serviceInstCols...).
+		Column(sq.Expr("last_heartbeat > NOW() - ? * INTERVAL '1 second'", 30)).
+		From(serviceInstTable).
+		OrderBy(serviceInstIdCol).ToSql()
+	cols := append(serviceInstCols, "live")
+
 	tests := []struct {
-		dbResult        int64
-		dbError         error
-		expectedPrepare string
-		expectedResult  int64
-		expectedErr     error
+		dbRows       [][]driver.Value
+		dbError      error
+		expectedInst []*sm.ServiceInstance
 	}{{
-		dbResult:        1,
-		dbError:         nil,
-		expectedPrepare: regexp.QuoteMeta(deletePowerMapsAllQuery),
-		expectedResult:  1,
+		dbRows: [][]driver.Value{
+			{"smd-1", "2026-10-19T11:00:00Z", "2026-10-19T11:37:00Z", true, true},
+			{"smd-2", "2026-10-19T10:00:00Z", "2026-10-19T10:37:00Z", false, false},
+		},
+		expectedInst: []*sm.ServiceInstance{
+			{ID: "smd-1", Started: "2026-10-19T11:00:00Z", LastHeartbeat: "2026-10-19T11:37:00Z", Leader: true, Live: true},
+			{ID: "smd-2", Started: "2026-10-19T10:00:00Z", LastHeartbeat: "2026-10-19T10:37:00Z"},
+		},
 	}, {
-		dbResult:        0,
-		dbError:         nil,
-		expectedPrepare: regexp.QuoteMeta(deletePowerMapsAllQuery),
-		expectedResult:  0,
+		dbRows:       [][]driver.Value{},
+		expectedInst: []*sm.ServiceInstance{},
 	}, {
-		dbResult:        0,
-		dbError:         sql.ErrNoRows,
-		expectedPrepare: regexp.QuoteMeta(deletePowerMapsAllQuery),
-		expectedResult:  0,
+		dbError: sql.ErrConnDone,
 	}}
 
 	for i, test := range tests {
 		ResetMockDB()
-		// before we actually execute our api function, we need to expect required DB actions
-		mockPG.ExpectBegin()
+		q := mockPG.ExpectPrepare(regexp.QuoteMeta(query)).ExpectQuery().WithArgs(int64(30))
 		if test.dbError != nil {
-			mockPG.ExpectPrepare(test.expectedPrepare).ExpectExec().WillReturnError(test.dbError)
-			mockPG.ExpectRollback()
+			q.WillReturnError(test.dbError)
 		} else {
-			mockPG.ExpectPrepare(test.expectedPrepare).ExpectExec().WillReturnResult(sqlmock.NewResult(0, test.dbResult))
-			mockPG.ExpectCommit()
+			rows := sqlmock.NewRows(cols)
+			for _, row := range test.dbRows {
+				rows.AddRow(row...)
+			}
+			q.WillReturnRows(rows)
 		}
-
-		numDelete, err := dPG.DeletePowerMapsAll()
-		// ensure all expectations have been met
+		insts, err := dPG.GetServiceInstances(30 * time.Second)
 		if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
 			t.Errorf("Test %v Failed: Sql expectations were not met: %s", i, mock_err)
 		}
 		if test.dbError == nil {
 			if err != nil {
 				t.Errorf("Test %v Failed: Unexpected error received: %s", i, err)
-			} else if test.expectedResult != numDelete {
-				t.Errorf("Test %v Failed: Expected numDelete '%v'; Recieved numDelete '%v'", i, test.expectedResult, numDelete)
+			} else if !reflect.DeepEqual(test.expectedInst, insts) {
+				t.Errorf("Test %v Failed: Expected %v; Received %v", i, test.expectedInst, insts)
 			}
 		} else if err == nil {
 			t.Errorf("Test %v Failed: Expected an error.", i)
@@ -7239,557 +9303,993 @@ func TestPgDeletePowerMapsAll(t *testing.T) {
 	}
 }
 
-////////////////////////////////////////////////////////////////////////////
+func TestPgDeleteServiceInstancesStale(t *testing.T) {
+	sqq := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	del, _, _ := sqq.Delete(serviceInstTable).
+		Where(sq.Expr("last_heartbeat < NOW() - ? * INTERVAL '1 second'", 3600)).
+		ToSql()
+
+	ResetMockDB()
+	mockPG.ExpectPrepare(regexp.QuoteMeta(del)).ExpectExec().
+		WithArgs(int64(3600)).WillReturnResult(sqlmock.NewResult(0, 2))
+	num, err := dPG.DeleteServiceInstancesStale(time.Hour)
+	if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
+		t.Errorf("Test Failed: Sql expectations were not met: %s", mock_err)
+	}
+	if err != nil || num != 2 {
+		t.Errorf("Test Failed: Expected 2 deleted; Received %d, %v", num, err)
+	}
+}
+
+func TestPgAdvisoryLock(t *testing.T) {
+	const key = int64(42)
+	lockQ := regexp.QuoteMeta(tryPgAdvisoryLock)
+	unlockQ := regexp.QuoteMeta(unlockPgAdvisoryLock)
+	pingQ := regexp.QuoteMeta(pingPgAdvisoryLockConn)
+
+	ResetMockDB()
+	defer ResetMockDB()
+
+	// Held by someone else
+	mockPG.ExpectQuery(lockQ).WithArgs(key).
+		WillReturnRows(sqlmock.NewRows([]string{"locked"}).AddRow(false))
+	// Taken
+	mockPG.ExpectQuery(lockQ).WithArgs(key).
+		WillReturnRows(sqlmock.NewRows([]string{"locked"}).AddRow(true))
+	// Still held, so just check the session is still there.
+	mockPG.ExpectQuery(pingQ).
+		WillReturnRows(sqlmock.NewRows([]string{"one"}).AddRow(1))
+	// Released
+	mockPG.ExpectExec(unlockQ).WithArgs(key).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	// Taken again, but the session is then lost.
+	mockPG.ExpectQuery(lockQ).WithArgs(key).
+		WillReturnRows(sqlmock.NewRows([]string{"locked"}).AddRow(true))
+	mockPG.ExpectQuery(pingQ).WillReturnError(driver.ErrBadConn)
+
+	expected := []bool{false, true, true}
+	for i, exp := range expected {
+		locked, err := dPG.TryAdvisoryLock(key)
+		if err != nil || locked != exp {
+			t.Errorf("Test %v Failed: Expected %v; Received %v, %v", i, exp, locked, err)
+		}
+	}
+	if err := dPG.ReleaseAdvisoryLock(key); err != nil {
+		t.Errorf("Test Failed: Unexpected error releasing lock: %s", err)
+	}
+	// Not held, so nothing to do.
+	if err := dPG.ReleaseAdvisoryLock(key); err != nil {
+		t.Errorf("Test Failed: Unexpected error releasing lock: %s", err)
+	}
+	if locked, err := dPG.TryAdvisoryLock(key); err != nil || !locked {
+		t.Errorf("Test Failed: Expected lock; Received %v, %v", locked, err)
+	}
+	if locked, err := dPG.TryAdvisoryLock(key); err == nil || locked {
+		t.Errorf("Test Failed: Expected lock to be lost; Received %v, %v", locked, err)
+	}
+	if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
+		t.Errorf("Test Failed: Sql expectations were not met: %s", mock_err)
+	}
+}
+
 //
-// Job Sync Management
+// Nested groups and group expressions
 //
-////////////////////////////////////////////////////////////////////////////
 
-func TestPgInsertJob(t *testing.T) {
-	var dJob1 = &sm.Job{
-		JobData: sm.JobData{
-			Id:       uuid.New().String(),
-			Type:     sm.JobTypeSRFP,
-			Status:   sm.JobNotStarted,
-			Lifetime: 30,
-		},
-		Data: &sm.SrfpJobData{
-			CompId: "x0c0s0b0n0",
-		},
+const tGetStaticGroupIDs = "SELECT gm.component_id FROM component_group_members gm " +
+	"JOIN component_groups g ON g.id = gm.group_id WHERE g.name IN "
+
+func TestPgGetComponentsFilterNestedGroup(t *testing.T) {
+	f := &ComponentFilter{Group: []string{"nest1"}}
+	expectedQuery := tGetCompStateOnlyQuery + " WHERE (c.id IN ( " +
+		tGetDynGroupIDs + " ) OR c.id IN ( " + tGetStaticGroupIDs +
+		"($3,$4) AND g.namespace = $5 ))"
+
+	ResetMockDB()
+	mockPG.ExpectBegin()
+	mockPG.ExpectPrepare(regexp.QuoteMeta(tResolveGroups)).ExpectQuery().
+		WithArgs(groupNamespace, "nest1").
+		WillReturnRows(sqlmock.NewRows(tResolveGroupsCols).
+			AddRow("nest1", "nest1", nil).
+			AddRow("nest1", "grp1", nil).
+			AddRow("nest1", "dyn1", dgrpDynQuery))
+	mockPG.ExpectPrepare(regexp.QuoteMeta(expectedQuery)).ExpectQuery().
+		WithArgs("Compute", "x3000([[:alpha:]][[:alnum:]]*)?", "nest1", "grp1",
+			groupNamespace).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "type", "state", "flag"}).
+			AddRow("x3000c0s1b0n0", "Node", "Ready", "OK"))
+	mockPG.ExpectCommit()
+
+	comps, err := dPG.GetComponentsFilter(f, FLTR_STATEONLY)
+	if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
+		t.Errorf("Test Failed: Sql expectations were not met: %s", mock_err)
 	}
-	var dJob2 = &sm.Job{
-		JobData: sm.JobData{
-			Id:       uuid.New().String(),
-			Type:     sm.JobTypeSRFP,
-			Status:   sm.JobNotStarted,
-			Lifetime: 30,
-		},
+	if err != nil {
+		t.Errorf("Test Failed: Unexpected error received: %s", err)
+	} else if len(comps) != 1 || comps[0].ID != "x3000c0s1b0n0" {
+		t.Errorf("Test Failed: Unexpected components: %v", comps)
 	}
+}
 
-	sqq := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+func TestPgInsertGroupIncludes(t *testing.T) {
+	g := &sm.Group{Label: "nest1", Groups: []string{"grp1"}}
+	tests := []struct {
+		includedIDs   []driver.Value
+		cycleCount    int
+		expectedError error
+	}{{
+		includedIDs:   []driver.Value{uuid2},
+		cycleCount:    0,
+		expectedError: nil,
+	}, {
+		includedIDs:   []driver.Value{uuid2},
+		cycleCount:    1,
+		expectedError: ErrHMSDSGroupCycle,
+	}, {
+		includedIDs:   []driver.Value{},
+		expectedError: ErrHMSDSNoIncludedGroup,
+	}}
+	for i, test := range tests {
+		g.Groups = []string{"grp1"}
+		ResetMockDB()
+		mockPG.ExpectBegin()
+		mockPG.ExpectPrepare(regexp.QuoteMeta("INSERT INTO component_groups")).
+			ExpectExec().WillReturnResult(sqlmock.NewResult(0, 1))
+		mockPG.ExpectPrepare(regexp.QuoteMeta(lockPgGroupIncludesTx)).
+			ExpectExec().WithArgs(groupIncludesLockKey).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		rows := sqlmock.NewRows([]string{"id"})
+		for _, id := range test.includedIDs {
+			rows.AddRow(id)
+		}
+		mockPG.ExpectPrepare(regexp.QuoteMeta("SELECT id FROM component_groups "+
+			"WHERE name IN ($1) AND namespace = $2")).ExpectQuery().
+			WithArgs("grp1", groupNamespace).WillReturnRows(rows)
+		if len(test.includedIDs) > 0 {
+			mockPG.ExpectPrepare(regexp.QuoteMeta("WITH RECURSIVE closure(root, id) AS ("+
+				"SELECT name, id FROM component_groups WHERE id IN ($1)")).
+				ExpectQuery().WithArgs(uuid2, AnyUUID{}).
+				WillReturnRows(sqlmock.NewRows([]string{"count"}).
+					AddRow(test.cycleCount))
+		}
+		if test.expectedError == nil {
+			mockPG.ExpectPrepare(regexp.QuoteMeta("INSERT INTO component_group_includes "+
+				"(group_id,included_id) VALUES ($1,$2)")).ExpectExec().
+				WithArgs(AnyUUID{}, uuid2).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mockPG.ExpectCommit()
+		} else {
+			mockPG.ExpectRollback()
+		}
 
-	// Note we only use the query here so the args values don't really matter.
-	dJob1InsertJob, _, _ := sqq.Insert(jobTable).
-		Columns(jobColsNoTS...).
-		Values(sq.Expr("?", dJob1.Id), dJob1.Type, dJob1.Status, dJob1.Lifetime).ToSql()
+		_, err := dPG.InsertGroup(g, "")
+		if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
+			t.Errorf("Test %v Failed: Sql expectations were not met: %s", i, mock_err)
+		}
+		if err != test.expectedError {
+			t.Errorf("Test %v Failed: Expected error %v, got %v", i, test.expectedError, err)
+		}
+	}
+}
 
-	dJob1InsertStateRFPollJob, _, _ := sqq.Insert(stateRfPollTable).
-		Columns(stateRfPollCols...).
-		Values(dJob1.Data.(*sm.SrfpJobData).CompId, sq.Expr("?", dJob1.Id)).ToSql()
+func TestPgGetComponentsGroupExpr(t *testing.T) {
+	e, err := sm.ParseGroupExpr("(grp1 | dyn1) & !p1")
+	if err != nil {
+		t.Fatalf("Test Failed: Unexpected parse error: %s", err)
+	}
+	expectedQuery := tGetCompStateOnlyQuery + " WHERE (((c.id IN ( " +
+		tGetStaticGroupIDs + "($1) AND g.namespace = $2 )) OR " +
+		"(c.id IN ( SELECT dyn.id AS id FROM components dyn " +
+		"WHERE dyn.role IN ($3) AND (dyn.id SIMILAR TO $4) ))) " +
+		"AND NOT (c.id IN ( SELECT gm.component_id " +
+		"FROM component_group_members gm JOIN component_groups g " +
+		"ON g.id = gm.group_id WHERE (g.name IN ($5) OR g.name LIKE $6) " +
+		"AND g.namespace = $7 )))"
+
+	ResetMockDB()
+	mockPG.ExpectBegin()
+	mockPG.ExpectPrepare(regexp.QuoteMeta(tResolveGroups)).ExpectQuery().
+		WithArgs(groupNamespace, "grp1", "dyn1").
+		WillReturnRows(sqlmock.NewRows(tResolveGroupsCols).
+			AddRow("grp1", "grp1", nil).
+			AddRow("dyn1", "dyn1", dgrpDynQuery))
+	mockPG.ExpectPrepare(regexp.QuoteMeta(expectedQuery)).ExpectQuery().
+		WithArgs("grp1", groupNamespace, "Compute",
+			"x3000([[:alpha:]][[:alnum:]]*)?", "p1", "p1.%", partNamespace).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "type", "state", "flag"}).
+			AddRow("x3000c0s1b0n0", "Node", "Ready", "OK"))
+	mockPG.ExpectCommit()
+
+	comps, err := dPG.GetComponentsGroupExpr(e, FLTR_STATEONLY)
+	if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
+		t.Errorf("Test Failed: Sql expectations were not met: %s", mock_err)
+	}
+	if err != nil {
+		t.Errorf("Test Failed: Unexpected error received: %s", err)
+	} else if len(comps) != 1 || comps[0].ID != "x3000c0s1b0n0" {
+		t.Errorf("Test Failed: Unexpected components: %v", comps)
+	}
+
+	// A group that doesn't exist is an error, unlike a partition.
+	e, _ = sm.ParseGroupExpr("grp1 & nosuchgroup")
+	ResetMockDB()
+	mockPG.ExpectBegin()
+	mockPG.ExpectPrepare(regexp.QuoteMeta(tResolveGroups)).ExpectQuery().
+		WithArgs(groupNamespace, "grp1", "nosuchgroup").
+		WillReturnRows(sqlmock.NewRows(tResolveGroupsCols).
+			AddRow("grp1", "grp1", nil))
+	mockPG.ExpectRollback()
+
+	_, err = dPG.GetComponentsGroupExpr(e, FLTR_STATEONLY)
+	if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
+		t.Errorf("Test Failed: Sql expectations were not met: %s", mock_err)
+	}
+	if err != ErrHMSDSNoGroup {
+		t.Errorf("Test Failed: Expected error %v, got %v", ErrHMSDSNoGroup, err)
+	}
+}
+
+func TestPgReplaceGroupMembers(t *testing.T) {
+	sqq := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	grpQuery, _, _ := sqq.Select(compGroupsColsSMGroup...).
+		From(compGroupsTable).
+		Where("name = ?", dgrp3x.Label).
+		Where("namespace = ?", groupNamespace).ToSql()
+	dval3 := []driver.Value{uuid3, dgrp3x.Label, dgrp3x.Description, pq.Array(&dgrp3x.Tags), dgrp3x.ExclusiveGroup, nil, nil, 3}
+	versionQuery := "SELECT version FROM component_groups WHERE id = $1 FOR UPDATE"
+	exclErr := &pq.Error{Code: "23505",
+		Detail: "Key (component_id, group_namespace)=(x0c0s0b0n1, %exgrp1%) already exists."}
 
 	tests := []struct {
-		j                       *sm.Job
-		dbInsertJError          error
-		expectedInsertJPrepare  string
-		expectedInsertJArgs     []driver.Value
-		dbInsertJDError         error
-		expectedInsertJDPrepare string
-		expectedInsertJDArgs    []driver.Value
-		expectErr               bool
+		ids             []string
+		version         int64
+		dbRows          [][]driver.Value
+		dbVersion       int64
+		dbMembers       []string
+		dbInsertError   error
+		expectedDelete  []driver.Value
+		expectedInsert  []driver.Value
+		expectedVersion int64
+		expectedError   error
 	}{{
-		j:                       dJob1,
-		dbInsertJError:          nil,
-		expectedInsertJPrepare:  regexp.QuoteMeta(dJob1InsertJob),
-		expectedInsertJArgs:     []driver.Value{AnyUUID{}, dJob1.Type, dJob1.Status, dJob1.Lifetime},
-		dbInsertJDError:         nil,
-		expectedInsertJDPrepare: regexp.QuoteMeta(dJob1InsertStateRFPollJob),
-		expectedInsertJDArgs:    []driver.Value{dJob1.Data.(*sm.SrfpJobData).CompId, AnyUUID{}},
-		expectErr:               false,
-	}, {
-		j:                       dJob1,
-		dbInsertJError:          nil,
-		expectedInsertJPrepare:  regexp.QuoteMeta(dJob1InsertJob),
-		expectedInsertJArgs:     []driver.Value{AnyUUID{}, dJob1.Type, dJob1.Status, dJob1.Lifetime},
-		dbInsertJDError:         ErrHMSDSArgBadID,
-		expectedInsertJDPrepare: regexp.QuoteMeta(dJob1InsertStateRFPollJob),
-		expectedInsertJDArgs:    []driver.Value{dJob1.Data.(*sm.SrfpJobData).CompId, AnyUUID{}},
-		expectErr:               true,
-	}, {
-		j:                       dJob1,
-		dbInsertJError:          ErrHMSDSArgBadID,
-		expectedInsertJPrepare:  regexp.QuoteMeta(dJob1InsertJob),
-		expectedInsertJArgs:     []driver.Value{AnyUUID{}, dJob1.Type, dJob1.Status, dJob1.Lifetime},
-		dbInsertJDError:         nil,
-		expectedInsertJDPrepare: "",
-		expectedInsertJDArgs:    []driver.Value{},
-		expectErr:               true,
+		ids:             []string{"X0c0s0b0n1", "x0c0s0b0n2", "x0c0s0b0n1"},
+		version:         3,
+		dbRows:          [][]driver.Value{dval3},
+		dbVersion:       3,
+		dbMembers:       []string{"x0c0s0b0n2", "x0c0s0b0n3"},
+		expectedDelete:  []driver.Value{uuid3, "x0c0s0b0n3"},
+		expectedInsert:  []driver.Value{"x0c0s0b0n1", uuid3, "%exgrp1%"},
+		expectedVersion: 4,
+	}, {
+		ids:             []string{},
+		version:         0,
+		dbRows:          [][]driver.Value{dval3},
+		dbMembers:       []string{"x0c0s0b0n1"},
+		expectedDelete:  []driver.Value{uuid3, "x0c0s0b0n1"},
+		expectedVersion: 4,
+	}, {
+		ids:           []string{"x0c0s0b0n1"},
+		version:       2,
+		dbRows:        [][]driver.Value{dval3},
+		dbVersion:     3,
+		expectedError: ErrHMSDSVersionMismatch,
+	}, {
+		ids:            []string{"x0c0s0b0n1"},
+		version:        0,
+		dbRows:         [][]driver.Value{dval3},
+		dbInsertError:  exclErr,
+		expectedInsert: []driver.Value{"x0c0s0b0n1", uuid3, "%exgrp1%"},
+		expectedError:  ErrHMSDSExclusiveGroup,
+	}, {
+		ids:           []string{"x0c0s0b0n1"},
+		version:       0,
+		dbRows:        [][]driver.Value{},
+		expectedError: ErrHMSDSNoGroup,
+	}}
+
+	for i, test := range tests {
+		ResetMockDB()
+		rows := sqlmock.NewRows(compGroupsColsSMGroup)
+		for _, row := range test.dbRows {
+			rows.AddRow(row...)
+		}
+		mockPG.ExpectBegin()
+		mockPG.ExpectPrepare(regexp.QuoteMeta(grpQuery)).ExpectQuery().
+			WithArgs(dgrp3x.Label, groupNamespace).WillReturnRows(rows)
+		if len(test.dbRows) == 0 {
+			mockPG.ExpectRollback()
+		} else {
+			if test.version != 0 {
+				mockPG.ExpectPrepare(regexp.QuoteMeta(versionQuery)).ExpectQuery().
+					WithArgs(uuid3).WillReturnRows(sqlmock.NewRows([]string{"version"}).
+					AddRow(test.dbVersion))
+			}
+			if test.expectedError != ErrHMSDSVersionMismatch {
+				memberRows := sqlmock.NewRows([]string{"component_id"})
+				for _, id := range test.dbMembers {
+					memberRows.AddRow(id)
+				}
+				mockPG.ExpectPrepare(regexp.QuoteMeta("SELECT component_id FROM component_group_members " +
+					"WHERE group_id = $1")).ExpectQuery().WithArgs(uuid3).
+					WillReturnRows(memberRows)
+			}
+			if len(test.expectedDelete) > 0 {
+				mockPG.ExpectPrepare(regexp.QuoteMeta("DELETE FROM component_group_members " +
+					"WHERE group_id = $1 AND component_id IN ($2)")).ExpectExec().
+					WithArgs(test.expectedDelete...).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			}
+			if test.dbInsertError != nil {
+				mockPG.ExpectPrepare(regexp.QuoteMeta("INSERT INTO component_group_members")).
+					ExpectExec().WithArgs(test.expectedInsert...).
+					WillReturnError(test.dbInsertError)
+			} else if len(test.expectedInsert) > 0 {
+				mockPG.ExpectPrepare(regexp.QuoteMeta("INSERT INTO component_group_members " +
+					"(component_id,group_id,group_namespace) VALUES ($1,$2,$3)")).
+					ExpectExec().WithArgs(test.expectedInsert...).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			}
+			if test.expectedError == nil {
+				// The statement cache only prepares the version query once.
+				if test.version == 0 {
+					mockPG.ExpectPrepare(regexp.QuoteMeta(versionQuery))
+				}
+				mockPG.ExpectQuery(regexp.QuoteMeta(versionQuery)).
+					WithArgs(uuid3).WillReturnRows(sqlmock.NewRows([]string{"version"}).
+					AddRow(test.expectedVersion))
+				mockPG.ExpectCommit()
+			} else {
+				mockPG.ExpectRollback()
+			}
+		}
+
+		ms := &sm.Members{IDs: test.ids}
+		version, err := dPG.ReplaceGroupMembers(dgrp3x.Label, ms, test.version, "")
+		if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
+			t.Errorf("Test %v Failed: Sql expectations were not met: %s", i, mock_err)
+		}
+		if err != test.expectedError {
+			t.Errorf("Test %v Failed: Expected error %v, got %v", i, test.expectedError, err)
+		} else if version != test.expectedVersion {
+			t.Errorf("Test %v Failed: Expected version %v, got %v", i, test.expectedVersion, version)
+		}
+	}
+}
+
+func TestPgReplacePartitionMembers(t *testing.T) {
+	sqq := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	partQuery, _, _ := sqq.Select(compGroupsColsSMPart...).
+		From(compGroupsTable).
+		Where("name = ?", dgrp5p.Name).
+		Where("namespace = ?", partNamespace).ToSql()
+	versionQuery := "SELECT version FROM component_groups WHERE id = $1 FOR UPDATE"
+	partErr := &pq.Error{Code: "23505",
+		Detail: "Key (component_id, group_namespace)=(x0c0s0b0n0, %%partition%%) already exists."}
+
+	tests := []struct {
+		dbInsertError   error
+		expectedVersion int64
+		expectedError   error
+	}{{
+		expectedVersion: 8,
 	}, {
-		j:                       dJob2,
-		dbInsertJError:          nil,
-		expectedInsertJPrepare:  regexp.QuoteMeta(dJob1InsertJob),
-		expectedInsertJArgs:     []driver.Value{AnyUUID{}, dJob2.Type, dJob2.Status, dJob2.Lifetime},
-		dbInsertJDError:         ErrHMSDSArgBadID,
-		expectedInsertJDPrepare: "",
-		expectedInsertJDArgs:    []driver.Value{},
-		expectErr:               true,
+		dbInsertError: partErr,
+		expectedError: ErrHMSDSExclusivePartition,
 	}}
 
 	for i, test := range tests {
 		ResetMockDB()
-
 		mockPG.ExpectBegin()
-		if test.expectedInsertJPrepare == "" && test.dbInsertJError != nil {
-			mockPG.ExpectRollback()
-		} else if test.dbInsertJError != nil {
-			mockPG.ExpectPrepare(test.expectedInsertJPrepare).ExpectExec().WillReturnError(test.dbInsertJError)
-			mockPG.ExpectRollback()
-		} else if test.expectedInsertJDPrepare == "" && test.dbInsertJDError != nil {
-			mockPG.ExpectPrepare(test.expectedInsertJPrepare).ExpectExec().WithArgs(test.expectedInsertJArgs...).WillReturnResult(sqlmock.NewResult(0, 1))
-			mockPG.ExpectRollback()
-		} else if test.dbInsertJDError != nil {
-			mockPG.ExpectPrepare(test.expectedInsertJPrepare).ExpectExec().WithArgs(test.expectedInsertJArgs...).WillReturnResult(sqlmock.NewResult(0, 1))
-			mockPG.ExpectPrepare(test.expectedInsertJDPrepare).ExpectExec().WillReturnError(test.dbInsertJDError)
+		mockPG.ExpectPrepare(regexp.QuoteMeta(partQuery)).ExpectQuery().
+			WithArgs(dgrp5p.Name, partNamespace).
+			WillReturnRows(sqlmock.NewRows(compGroupsColsSMPart).
+				AddRow(uuid5, dgrp5p.Name, dgrp5p.Description, pq.Array(&dgrp5p.Tags), 7))
+		mockPG.ExpectPrepare(regexp.QuoteMeta(versionQuery)).ExpectQuery().
+			WithArgs(uuid5).WillReturnRows(sqlmock.NewRows([]string{"version"}).
+			AddRow(7))
+		expectSubPartIDs(dgrp5p.Name)
+		mockPG.ExpectPrepare(regexp.QuoteMeta("SELECT component_id FROM component_group_members " +
+			"WHERE group_id = $1")).ExpectQuery().WithArgs(uuid5).
+			WillReturnRows(sqlmock.NewRows([]string{"component_id"}).AddRow("x0c0s0b0n1"))
+		mockPG.ExpectPrepare(regexp.QuoteMeta("DELETE FROM component_group_members "+
+			"WHERE group_id = $1 AND component_id IN ($2)")).ExpectExec().
+			WithArgs(uuid5, "x0c0s0b0n1").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		insert := mockPG.ExpectPrepare(regexp.QuoteMeta("INSERT INTO component_group_members")).
+			ExpectExec().WithArgs("x0c0s0b0n0", uuid5, partGroupNamespace)
+		if test.dbInsertError != nil {
+			insert.WillReturnError(test.dbInsertError)
 			mockPG.ExpectRollback()
 		} else {
-			mockPG.ExpectPrepare(test.expectedInsertJPrepare).ExpectExec().WithArgs(test.expectedInsertJArgs...).WillReturnResult(sqlmock.NewResult(0, 1))
-			mockPG.ExpectPrepare(test.expectedInsertJDPrepare).ExpectExec().WithArgs(test.expectedInsertJDArgs...).WillReturnResult(sqlmock.NewResult(0, 1))
+			insert.WillReturnResult(sqlmock.NewResult(0, 1))
+			mockPG.ExpectQuery(regexp.QuoteMeta(versionQuery)).
+				WithArgs(uuid5).WillReturnRows(sqlmock.NewRows([]string{"version"}).
+				AddRow(test.expectedVersion))
 			mockPG.ExpectCommit()
 		}
 
-		jobId, err := dPG.InsertJob(test.j)
-		// ensure all expectations have been met
+		ms := &sm.Members{IDs: []string{"x0c0s0b0n0"}}
+		version, err := dPG.ReplacePartitionMembers(dgrp5p.Name, ms, 7, "")
 		if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
-			t.Errorf("Test %v Failed: Sql expectations were not met: %s",
-				i, mock_err)
+			t.Errorf("Test %v Failed: Sql expectations were not met: %s", i, mock_err)
 		}
-		if !test.expectErr {
-			if err != nil {
-				t.Errorf("Test %v Failed: Unexpected error received: %s", i, err)
-			} else {
-				if jobId == "" {
-					t.Errorf("Test %v Failed: Expected a new job id", i)
-				}
-			}
-		} else if err == nil {
-			t.Errorf("Test %v Failed: Expected an error.", i)
+		if err != test.expectedError {
+			t.Errorf("Test %v Failed: Expected error %v, got %v", i, test.expectedError, err)
+		} else if version != test.expectedVersion {
+			t.Errorf("Test %v Failed: Expected version %v, got %v", i, test.expectedVersion, version)
 		}
 	}
 }
 
-func TestPgUpdateJob(t *testing.T) {
-	id := uuid.New().String()
-	status := sm.JobInProgress
-
-	sqq := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
-
-	dJob1Update1, _, _ := sqq.Update("").
-		Table(jobTable).
-		Where("id = ?", id).
-		Set(jobStatusCol, status).
-		Set(jobLastUpdateCol, "NOW()").ToSql()
-
-	dJob1Update2, _, _ := sqq.Update("").
-		Table(jobTable).
-		Where("id = ?", id).
-		Set(jobLastUpdateCol, "NOW()").ToSql()
-
+func TestPgInsertSubPartition(t *testing.T) {
+	sub := &sm.Partition{
+		Name:    "p1.5",
+		Members: sm.Members{IDs: []string{"x0c0s0b0n0", "x0c0s0b1n0"}},
+	}
 	tests := []struct {
-		id                    string
-		status                string
-		dbUpdateError         error
-		expectedUpdatePrepare string
-		expectedUpdateArgs    []driver.Value
+		parentFound   bool
+		parentMembers []string
+		expectedError error
 	}{{
-		id:                    id,
-		status:                status,
-		dbUpdateError:         nil,
-		expectedUpdatePrepare: regexp.QuoteMeta(dJob1Update1),
-		expectedUpdateArgs:    []driver.Value{status, "NOW()", id},
-	}, {
-		id:                    id,
-		status:                "",
-		dbUpdateError:         nil,
-		expectedUpdatePrepare: regexp.QuoteMeta(dJob1Update2),
-		expectedUpdateArgs:    []driver.Value{"NOW()", id},
+		parentFound:   true,
+		parentMembers: []string{"x0c0s0b0n0", "x0c0s0b1n0", "x0c0s1b0n0"},
 	}, {
-		id:                    id,
-		status:                "",
-		dbUpdateError:         ErrHMSDSArgBadID,
-		expectedUpdatePrepare: regexp.QuoteMeta(dJob1Update2),
-		expectedUpdateArgs:    []driver.Value{"NOW()", id},
+		parentFound:   true,
+		parentMembers: []string{"x0c0s0b0n0"},
+		expectedError: ErrHMSDSNotInParentPartition,
 	}, {
-		id:                    "",
-		status:                "",
-		dbUpdateError:         ErrHMSDSArgBadID,
-		expectedUpdatePrepare: "",
-		expectedUpdateArgs:    []driver.Value{},
+		parentFound:   false,
+		expectedError: ErrHMSDSNoParentPartition,
 	}}
 
 	for i, test := range tests {
 		ResetMockDB()
 		mockPG.ExpectBegin()
-		if test.expectedUpdatePrepare == "" && test.dbUpdateError != nil {
+		mockPG.ExpectPrepare(regexp.QuoteMeta("INSERT INTO component_groups")).
+			ExpectExec().WillReturnResult(sqlmock.NewResult(0, 1))
+		if !test.parentFound {
+			mockPG.ExpectPrepare(regexp.QuoteMeta(tGetPartQuery)).ExpectQuery().
+				WithArgs(dgrp5p.Name, partNamespace).
+				WillReturnRows(sqlmock.NewRows(compGroupsColsSMPart))
 			mockPG.ExpectRollback()
-		} else if test.dbUpdateError != nil {
-			mockPG.ExpectPrepare(test.expectedUpdatePrepare).ExpectExec().WillReturnError(test.dbUpdateError)
+		} else if test.expectedError != nil {
+			expectParentPart(false, test.parentMembers...)
 			mockPG.ExpectRollback()
 		} else {
-			mockPG.ExpectPrepare(test.expectedUpdatePrepare).ExpectExec().WithArgs(test.expectedUpdateArgs...).WillReturnResult(sqlmock.NewResult(0, 1))
+			expectParentPart(false, test.parentMembers...)
+			mockPG.ExpectPrepare(regexp.QuoteMeta("DELETE FROM component_group_members "+
+				"WHERE group_id = $1 AND component_id IN ($2,$3)")).ExpectExec().
+				WithArgs(uuid5, "x0c0s0b0n0", "x0c0s0b1n0").
+				WillReturnResult(sqlmock.NewResult(0, 2))
+			mockPG.ExpectPrepare(regexp.QuoteMeta("INSERT INTO component_group_members")).
+				ExpectExec().WithArgs("x0c0s0b0n0", AnyUUID{}, partGroupNamespace,
+				"x0c0s0b1n0", AnyUUID{}, partGroupNamespace).
+				WillReturnResult(sqlmock.NewResult(0, 2))
 			mockPG.ExpectCommit()
 		}
 
-		_, err := dPG.UpdateJob(test.id, test.status)
+		p := *sub
+		name, err := dPG.InsertPartition(&p, "")
 		if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
-			t.Errorf("Test %v Failed: Sql expectations were not met: %s",
-				i, mock_err)
+			t.Errorf("Test %v Failed: Sql expectations were not met: %s", i, mock_err)
 		}
-		if err != nil && test.dbUpdateError == nil {
-			t.Errorf("Test %v Failed: Unexpected error received: %s", i, err)
-		} else if err == nil && test.dbUpdateError != nil {
-			t.Errorf("Test %v Failed: Expected an error.", i)
+		if err != test.expectedError {
+			t.Errorf("Test %v Failed: Expected error %v, got %v", i, test.expectedError, err)
+		} else if err == nil && name != sub.Name {
+			t.Errorf("Test %v Failed: Expected name %s, got %s", i, sub.Name, name)
 		}
 	}
 }
 
-func TestPgGetJob(t *testing.T) {
-	var dJob1 = &sm.Job{
-		JobData: sm.JobData{
-			Id:         uuid.New().String(),
-			Type:       sm.JobTypeSRFP,
-			Status:     sm.JobNotStarted,
-			LastUpdate: "",
-			Lifetime:   30,
-		},
-		Data: &sm.SrfpJobData{
-			CompId: "x0c0s0b0n0",
-		},
+func TestPgGetPartitionWithSubPartitions(t *testing.T) {
+	ResetMockDB()
+	mockPG.ExpectBegin()
+	mockPG.ExpectPrepare(regexp.QuoteMeta(tGetPartQuery)).ExpectQuery().
+		WithArgs(dgrp5p.Name, partNamespace).
+		WillReturnRows(sqlmock.NewRows(compGroupsColsSMPart).
+			AddRow(uuid5, dgrp5p.Name, dgrp5p.Description, pq.Array(&dgrp5p.Tags), 1))
+	mockPG.ExpectPrepare(regexp.QuoteMeta(tGetMembersQuery)).ExpectQuery().
+		WithArgs(uuid5).WillReturnRows(sqlmock.NewRows([]string{"component_id"}).
+		AddRow("x0c0s1b0n0"))
+	expectSubPartIDs(dgrp5p.Name, uuid6)
+	mockPG.ExpectQuery(regexp.QuoteMeta(tGetMembersQuery)).
+		WithArgs(uuid6).WillReturnRows(sqlmock.NewRows([]string{"component_id"}).
+		AddRow(dgrp6p.Members.IDs[0]).AddRow(dgrp6p.Members.IDs[1]))
+	mockPG.ExpectCommit()
+
+	p, err := dPG.GetPartition(dgrp5p.Name)
+	if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
+		t.Errorf("Test Failed: Sql expectations were not met: %s", mock_err)
 	}
+	expected := []string{"x0c0s1b0n0", dgrp6p.Members.IDs[0], dgrp6p.Members.IDs[1]}
+	if err != nil {
+		t.Errorf("Test Failed: Unexpected error received: %s", err)
+	} else if p == nil || !reflect.DeepEqual(p.Members.IDs, expected) {
+		t.Errorf("Test Failed: Expected members %v, got %v", expected, p)
+	}
+}
 
-	columns := jobCols
+func TestPgDeleteSubPartition(t *testing.T) {
+	sqq := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	subQuery, _, _ := sqq.Select(compGroupsColsSMPart...).
+		From(compGroupsTable).
+		Where("name = ?", dgrp6p.Name).
+		Where("namespace = ?", partNamespace).ToSql()
 
-	dataCols := stateRfPollColsId
+	// A partition with sub-partitions can't be deleted.
+	ResetMockDB()
+	mockPG.ExpectBegin()
+	mockPG.ExpectPrepare(regexp.QuoteMeta(tGetPartQuery)).ExpectQuery().
+		WithArgs(dgrp5p.Name, partNamespace).
+		WillReturnRows(sqlmock.NewRows(compGroupsColsSMPart).
+			AddRow(uuid5, dgrp5p.Name, dgrp5p.Description, pq.Array(&dgrp5p.Tags), 1))
+	expectSubPartIDs(dgrp5p.Name, uuid6)
+	mockPG.ExpectRollback()
+
+	didDelete, err := dPG.DeletePartition(dgrp5p.Name, "")
+	if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
+		t.Errorf("Test 0 Failed: Sql expectations were not met: %s", mock_err)
+	}
+	if err != ErrHMSDSPartitionHasChildren || didDelete {
+		t.Errorf("Test 0 Failed: Expected error %v, got %v (%t)",
+			ErrHMSDSPartitionHasChildren, err, didDelete)
+	}
+
+	// The members of a deleted sub-partition go back to its parent.
+	ResetMockDB()
+	mockPG.ExpectBegin()
+	mockPG.ExpectPrepare(regexp.QuoteMeta(subQuery)).ExpectQuery().
+		WithArgs(dgrp6p.Name, partNamespace).
+		WillReturnRows(sqlmock.NewRows(compGroupsColsSMPart).
+			AddRow(uuid6, dgrp6p.Name, dgrp6p.Description, pq.Array(&dgrp6p.Tags), 1))
+	mockPG.ExpectPrepare(regexp.QuoteMeta(tGetMembersQuery)).ExpectQuery().
+		WithArgs(uuid6).WillReturnRows(sqlmock.NewRows([]string{"component_id"}).
+		AddRow(dgrp6p.Members.IDs[0]).AddRow(dgrp6p.Members.IDs[1]))
+	mockPG.ExpectPrepare(regexp.QuoteMeta("DELETE FROM component_groups WHERE id = $1")).
+		ExpectExec().WithArgs(uuid6).WillReturnResult(sqlmock.NewResult(0, 1))
+	expectReturnToParent(true, dgrp6p.Members.IDs...)
+	mockPG.ExpectCommit()
+
+	didDelete, err = dPG.DeletePartition(dgrp6p.Name, "")
+	if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
+		t.Errorf("Test 1 Failed: Sql expectations were not met: %s", mock_err)
+	}
+	if err != nil || !didDelete {
+		t.Errorf("Test 1 Failed: Expected deletion, got %v (%t)", err, didDelete)
+	}
+}
 
+func TestPgMovePartitionMembers(t *testing.T) {
 	sqq := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	srcQuery, _, _ := sqq.Select(compGroupsColsSMPart...).
+		From(compGroupsTable).
+		Where("name = ?", dgrp6p.Name).
+		Where("namespace = ?", partNamespace).ToSql()
 
-	dJob1Query1, _, _ := sqq.Select(jobCols...).
-		From(jobTable).
-		Where("id = ?", dJob1.Id).ToSql()
+	tests := []struct {
+		pname         string
+		pm            sm.PartitionMove
+		srcMembers    []string
+		expectDB      bool
+		expectedError error
+	}{{
+		pname:      "p1.5",
+		pm:         sm.PartitionMove{Source: dgrp6p.Name, IDs: []string{"x0c0s0b1n0"}},
+		srcMembers: dgrp6p.Members.IDs,
+		expectDB:   true,
+	}, {
+		pname:         "p1.5",
+		pm:            sm.PartitionMove{Source: dgrp6p.Name, IDs: []string{"x0c0s1b0n0"}},
+		srcMembers:    dgrp6p.Members.IDs,
+		expectDB:      true,
+		expectedError: ErrHMSDSNotInSourcePartition,
+	}, {
+		pname:         "p2.1",
+		pm:            sm.PartitionMove{Source: dgrp6p.Name, IDs: []string{"x0c0s0b1n0"}},
+		expectedError: ErrHMSDSNotSiblingPartitions,
+	}, {
+		pname:         dgrp6p.Name,
+		pm:            sm.PartitionMove{Source: dgrp6p.Name, IDs: []string{"x0c0s0b1n0"}},
+		expectedError: ErrHMSDSNotSiblingPartitions,
+	}, {
+		pname:         "p1.5",
+		pm:            sm.PartitionMove{Source: dgrp5p.Name, IDs: []string{"x0c0s0b1n0"}},
+		expectedError: sm.ErrPartBadName,
+	}}
 
-	dJob1JDQuery1, _, _ := sqq.Select(stateRfPollColsId...).
-		From(stateRfPollTable).
-		Where("job_id = ?", dJob1.Id).ToSql()
+	for i, test := range tests {
+		ResetMockDB()
+		if test.expectDB {
+			mockPG.ExpectBegin()
+			mockPG.ExpectPrepare(regexp.QuoteMeta(srcQuery)).ExpectQuery().
+				WithArgs(dgrp6p.Name, partNamespace).
+				WillReturnRows(sqlmock.NewRows(compGroupsColsSMPart).
+					AddRow(uuid6, dgrp6p.Name, dgrp6p.Description, pq.Array(&dgrp6p.Tags), 1))
+			mockPG.ExpectQuery(regexp.QuoteMeta(srcQuery)).
+				WithArgs(test.pname, partNamespace).
+				WillReturnRows(sqlmock.NewRows(compGroupsColsSMPart).
+					AddRow(uuid4, test.pname, "", pq.Array(&[]string{}), 1))
+			mrows := sqlmock.NewRows([]string{"component_id"})
+			for _, id := range test.srcMembers {
+				mrows.AddRow(id)
+			}
+			mockPG.ExpectPrepare(regexp.QuoteMeta(tGetMembersQuery)).ExpectQuery().
+				WithArgs(uuid6).WillReturnRows(mrows)
+			if test.expectedError != nil {
+				mockPG.ExpectRollback()
+			} else {
+				mockPG.ExpectPrepare(regexp.QuoteMeta("DELETE FROM component_group_members "+
+					"WHERE group_id = $1 AND component_id IN ($2)")).ExpectExec().
+					WithArgs(uuid6, "x0c0s0b1n0").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mockPG.ExpectPrepare(regexp.QuoteMeta("INSERT INTO component_group_members")).
+					ExpectExec().WithArgs("x0c0s0b1n0", uuid4, partGroupNamespace).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mockPG.ExpectCommit()
+			}
+		}
+
+		pm := test.pm
+		err := dPG.MovePartitionMembers(test.pname, &pm, "")
+		if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
+			t.Errorf("Test %v Failed: Sql expectations were not met: %s", i, mock_err)
+		}
+		if err != test.expectedError {
+			t.Errorf("Test %v Failed: Expected error %v, got %v", i, test.expectedError, err)
+		}
+	}
+}
+
+func TestPgUpdateGroupVersion(t *testing.T) {
+	sqq := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	grpQuery, _, _ := sqq.Select(compGroupsColsSMGroup...).
+		From(compGroupsTable).
+		Where("name = ?", dgrp1.Label).
+		Where("namespace = ?", groupNamespace).ToSql()
+	dval1 := []driver.Value{uuid1, dgrp1.Label, dgrp1.Description, pq.Array(&dgrp1.Tags), dgrp1.ExclusiveGroup, nil, nil, 5}
+	newDescription := "newDescription"
 
 	tests := []struct {
-		jobId                  string
-		dbColumns              []string
-		dbRows                 [][]driver.Value
-		dbQueryError           error
-		expectedQueryPrepare   string
-		expectedQueryArgs      []driver.Value
-		dbJDColumns            []string
-		dbJDRows               [][]driver.Value
-		dbJDQueryError         error
-		expectedJDQueryPrepare string
-		expectedJDQueryArgs    []driver.Value
-		expectedJ              *sm.Job
+		dbVersion     int64
+		expectedError error
 	}{{
-		jobId:     dJob1.Id,
-		dbColumns: columns,
-		dbRows: [][]driver.Value{
-			[]driver.Value{dJob1.Id, dJob1.Type, dJob1.Status, dJob1.LastUpdate, dJob1.Lifetime},
-		},
-		dbQueryError:         nil,
-		expectedQueryPrepare: regexp.QuoteMeta(dJob1Query1),
-		expectedQueryArgs:    []driver.Value{dJob1.Id},
-		dbJDColumns:          dataCols,
-		dbJDRows: [][]driver.Value{
-			[]driver.Value{dJob1.Data.(*sm.SrfpJobData).CompId},
-		},
-		dbJDQueryError:         nil,
-		expectedJDQueryPrepare: regexp.QuoteMeta(dJob1JDQuery1),
-		expectedJDQueryArgs:    []driver.Value{dJob1.Id},
-		expectedJ:              dJob1,
-	}, {
-		jobId:     dJob1.Id,
-		dbColumns: columns,
-		dbRows: [][]driver.Value{
-			[]driver.Value{dJob1.Id, dJob1.Type, dJob1.Status, dJob1.LastUpdate, dJob1.Lifetime},
-		},
-		dbQueryError:         nil,
-		expectedQueryPrepare: regexp.QuoteMeta(dJob1Query1),
-		expectedQueryArgs:    []driver.Value{dJob1.Id},
-		dbJDColumns:          dataCols,
-		dbJDRows: [][]driver.Value{
-			[]driver.Value{dJob1.Data.(*sm.SrfpJobData).CompId},
-		},
-		dbJDQueryError:         ErrHMSDSArgBadID,
-		expectedJDQueryPrepare: regexp.QuoteMeta(dJob1JDQuery1),
-		expectedJDQueryArgs:    []driver.Value{dJob1.Id},
-		expectedJ:              nil,
-	}, {
-		jobId:     dJob1.Id,
-		dbColumns: columns,
-		dbRows: [][]driver.Value{
-			[]driver.Value{dJob1.Id, dJob1.Type, dJob1.Status, dJob1.LastUpdate, dJob1.Lifetime},
-		},
-		dbQueryError:         ErrHMSDSArgBadID,
-		expectedQueryPrepare: regexp.QuoteMeta(dJob1Query1),
-		expectedQueryArgs:    []driver.Value{dJob1.Id},
-		dbJDColumns:          dataCols,
-		dbJDRows: [][]driver.Value{
-			[]driver.Value{dJob1.Data.(*sm.SrfpJobData).CompId},
-		},
-		dbJDQueryError:         nil,
-		expectedJDQueryPrepare: "",
-		expectedJDQueryArgs:    []driver.Value{},
-		expectedJ:              nil,
+		dbVersion:     5,
+		expectedError: nil,
 	}, {
-		jobId:     "",
-		dbColumns: columns,
-		dbRows: [][]driver.Value{
-			[]driver.Value{dJob1.Id, dJob1.Type, dJob1.Status, dJob1.LastUpdate, dJob1.Lifetime},
-		},
-		dbQueryError:         ErrHMSDSArgEmpty,
-		expectedQueryPrepare: "",
-		expectedQueryArgs:    []driver.Value{},
-		dbJDColumns:          dataCols,
-		dbJDRows: [][]driver.Value{
-			[]driver.Value{dJob1.Data.(*sm.SrfpJobData).CompId},
+		dbVersion:     6,
+		expectedError: ErrHMSDSVersionMismatch,
+	}}
+
+	for i, test := range tests {
+		ResetMockDB()
+		mockPG.ExpectBegin()
+		mockPG.ExpectPrepare(regexp.QuoteMeta(grpQuery)).ExpectQuery().
+			WithArgs(dgrp1.Label, groupNamespace).
+			WillReturnRows(sqlmock.NewRows(compGroupsColsSMGroup).AddRow(dval1...))
+		mockPG.ExpectPrepare(regexp.QuoteMeta("SELECT version FROM component_groups " +
+			"WHERE id = $1 FOR UPDATE")).ExpectQuery().WithArgs(uuid1).
+			WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(test.dbVersion))
+		if test.expectedError == nil {
+			mockPG.ExpectPrepare(regexp.QuoteMeta("UPDATE component_groups SET description = $1 "+
+				"WHERE id = $2")).ExpectExec().WithArgs(newDescription, uuid1).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mockPG.ExpectCommit()
+		} else {
+			mockPG.ExpectRollback()
+		}
+
+		gp := &sm.GroupPatch{Description: &newDescription, Version: 5}
+		err := dPG.UpdateGroup(dgrp1.Label, gp, "")
+		if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
+			t.Errorf("Test %v Failed: Sql expectations were not met: %s", i, mock_err)
+		}
+		if err != test.expectedError {
+			t.Errorf("Test %v Failed: Expected error %v, got %v", i, test.expectedError, err)
+		}
+	}
+}
+
+func TestPgGetGroupHistory(t *testing.T) {
+	testEnt1 := sm.GroupHistoryEntry{
+		ID:        3,
+		Timestamp: "2026-10-19T11:36:00Z",
+		Name:      "grp1",
+		Op:        sm.GroupHistOpAddMember,
+		Member:    "x0c0s1b0n0",
+		Requester: "cray-smd-loader",
+	}
+	testEnt2 := sm.GroupHistoryEntry{
+		ID:        4,
+		Timestamp: "2026-10-19T11:37:00Z",
+		Name:      "grp1",
+		Op:        sm.GroupHistOpUpdate,
+		Data:      json.RawMessage(`{"description":"foo"}`),
+	}
+	entRow := func(ent sm.GroupHistoryEntry) []driver.Value {
+		var data []byte
+		if ent.Data != nil {
+			data = []byte(ent.Data)
+		}
+		return []driver.Value{ent.ID, ent.Timestamp, ent.Name, ent.Op,
+			ent.Member, ent.Requester, data}
+	}
+	since, _ := time.Parse(time.RFC3339, "2026-10-19T00:00:00Z")
+
+	sqq := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	query1, _, _ := sqq.Select(compGroupHistColsSM...).
+		From(compGroupHistTable).
+		Where("namespace = ?", groupNamespace).
+		Where("name = ?", "grp1").
+		Where(sq.Eq{compGroupHistMemberCol: []string{"x0c0s1b0n0", "grp2"}}).
+		Where(sq.Eq{compGroupHistOpCol: []string{sm.GroupHistOpAddMember, sm.GroupHistOpUpdate}}).
+		Where(sq.GtOrEq{compGroupHistTimestampCol: since}).
+		OrderBy("id ASC").ToSql()
+	query2, _, _ := sqq.Select(compGroupHistColsSM...).
+		From(compGroupHistTable).
+		Where("namespace = ?", partNamespace).
+		Where("name = ?", "p1").
+		OrderBy("id ASC").ToSql()
+
+	tests := []struct {
+		part            bool
+		name            string
+		fltr            []GroupHistFiltFunc
+		dbRows          [][]driver.Value
+		dbError         error
+		expectedPrepare string
+		expectedArgs    []driver.Value
+		expectedErr     error
+		expectedEnts    []*sm.GroupHistoryEntry
+	}{{
+		name: "Grp1",
+		fltr: []GroupHistFiltFunc{
+			GH_Members([]string{"x00c0s1b0n0", "GRP2"}),
+			GH_Ops([]string{"addmember", "Update"}),
+			GH_Since("2026-10-19T00:00:00Z"),
 		},
-		dbJDQueryError:         nil,
-		expectedJDQueryPrepare: "",
-		expectedJDQueryArgs:    []driver.Value{},
-		expectedJ:              nil,
+		dbRows:          [][]driver.Value{entRow(testEnt1), entRow(testEnt2)},
+		expectedPrepare: regexp.QuoteMeta(query1),
+		expectedArgs: []driver.Value{groupNamespace, "grp1", "x0c0s1b0n0",
+			"grp2", sm.GroupHistOpAddMember, sm.GroupHistOpUpdate, since},
+		expectedEnts: []*sm.GroupHistoryEntry{&testEnt1, &testEnt2},
+	}, {
+		part:            true,
+		name:            "p1",
+		dbRows:          [][]driver.Value{},
+		expectedPrepare: regexp.QuoteMeta(query2),
+		expectedArgs:    []driver.Value{partNamespace, "p1"},
+		expectedEnts:    []*sm.GroupHistoryEntry{},
+	}, {
+		part:            true,
+		name:            "p1",
+		dbError:         sql.ErrConnDone,
+		expectedPrepare: regexp.QuoteMeta(query2),
+		expectedArgs:    []driver.Value{partNamespace, "p1"},
+		expectedErr:     sql.ErrConnDone,
+	}, {
+		name:        "grp1",
+		fltr:        []GroupHistFiltFunc{GH_Ops([]string{"rename"})},
+		expectedErr: ErrHMSDSArgBadArg,
+	}, {
+		name:        "grp1",
+		fltr:        []GroupHistFiltFunc{GH_Until("yesterday")},
+		expectedErr: ErrHMSDSArgBadTimeFormat,
 	}}
 
 	for i, test := range tests {
 		ResetMockDB()
-		// before we actually execute our api function, we need to expect required DB actions
-		rows := sqlmock.NewRows(test.dbColumns)
+		rows := sqlmock.NewRows(compGroupHistColsSM)
 		for _, row := range test.dbRows {
 			rows.AddRow(row...)
 		}
-		jdrows := sqlmock.NewRows(test.dbJDColumns)
-		for _, jdrow := range test.dbJDRows {
-			jdrows.AddRow(jdrow...)
+		if test.dbError != nil {
+			mockPG.ExpectPrepare(test.expectedPrepare).ExpectQuery().WithArgs(test.expectedArgs...).WillReturnError(test.dbError)
+		} else if test.expectedPrepare != "" {
+			mockPG.ExpectPrepare(test.expectedPrepare).ExpectQuery().WithArgs(test.expectedArgs...).WillReturnRows(rows)
 		}
 
-		mockPG.ExpectBegin()
-		if test.expectedQueryPrepare == "" && test.dbQueryError != nil {
-			mockPG.ExpectRollback()
-		} else if test.dbQueryError != nil {
-			mockPG.ExpectPrepare(test.expectedQueryPrepare).ExpectQuery().WillReturnError(test.dbQueryError)
-			mockPG.ExpectRollback()
-		} else if test.dbJDQueryError != nil {
-			mockPG.ExpectPrepare(test.expectedQueryPrepare).ExpectQuery().WithArgs(test.expectedQueryArgs...).WillReturnRows(rows)
-			mockPG.ExpectPrepare(test.expectedJDQueryPrepare).ExpectQuery().WillReturnError(test.dbJDQueryError)
-			mockPG.ExpectRollback()
+		var ents []*sm.GroupHistoryEntry
+		var err error
+		if test.part {
+			ents, err = dPG.GetPartitionHistory(test.name, test.fltr...)
 		} else {
-			mockPG.ExpectPrepare(test.expectedQueryPrepare).ExpectQuery().WithArgs(test.expectedQueryArgs...).WillReturnRows(rows)
-			mockPG.ExpectPrepare(test.expectedJDQueryPrepare).ExpectQuery().WithArgs(test.expectedJDQueryArgs...).WillReturnRows(jdrows)
-			mockPG.ExpectCommit()
+			ents, err = dPG.GetGroupHistory(test.name, test.fltr...)
 		}
-
-		j, err := dPG.GetJob(test.jobId)
-		// ensure all expectations have been met
 		if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
 			t.Errorf("Test %v Failed: Sql expectations were not met: %s", i, mock_err)
 		}
-		if test.dbQueryError == nil && test.dbJDQueryError == nil {
-			if err != nil {
-				t.Errorf("Test %v Failed: Unexpected error received: %s", i, err)
-			}
-			if j == nil {
-				t.Errorf("Test %v Failed: Expected non-nil Job", i)
-			} else if !reflect.DeepEqual(j, test.expectedJ) {
-				t.Errorf("Test %v Failed: Expected Job %v (got %v)",
-					i, j, test.expectedJ)
-			}
-		} else if err == nil {
-			t.Errorf("Test %v Failed: Expected an error.", i)
+		if err != test.expectedErr {
+			t.Errorf("Test %v Failed: Expected error '%v'; Recieved '%v'", i, test.expectedErr, err)
+		} else if err == nil && !reflect.DeepEqual(test.expectedEnts, ents) {
+			t.Errorf("Test %v Failed: Expected entries '%v'; Recieved '%v'", i, test.expectedEnts, ents)
 		}
 	}
 }
 
-func TestPgGetJobs(t *testing.T) {
-	var dJob1 = &sm.Job{
-		JobData: sm.JobData{
-			Id:         uuid.New().String(),
-			Type:       sm.JobTypeSRFP,
-			Status:     sm.JobNotStarted,
-			LastUpdate: "",
-			Lifetime:   30,
-		},
-		Data: &sm.SrfpJobData{
-			CompId: "x0c0s0b0n0",
-		},
-	}
-
-	columns := addAliasToCols(jobAlias, jobCols, jobCols)
-
-	dataCols := stateRfPollColsId
-
+func TestPgDeleteGroup(t *testing.T) {
 	sqq := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
-
-	dJob1Query1, _, _ := sqq.Select(columns...).
-		From(jobTable + " " + jobAlias).
-		Where(sq.Eq{jobIdColAlias: []string{dJob1.Id}}).ToSql()
-
-	dJob1JDQuery1, _, _ := sqq.Select(stateRfPollColsId...).
-		From(stateRfPollTable).
-		Where("job_id = ?", dJob1.Id).ToSql()
-
-	dJob1Query2, _, _ := sqq.Select(columns...).
-		From(jobTable + " " + jobAlias).
-		Where(sq.Eq{jobTypeColAlias: []string{dJob1.Type}}).ToSql()
-
-	dJob1JDQuery2, _, _ := sqq.Select(stateRfPollColsId...).
-		From(stateRfPollTable).
-		Where("job_id = ?", dJob1.Id).ToSql()
-
-	dJob1Query3, _, _ := sqq.Select(columns...).
-		From(jobTable + " " + jobAlias).
-		Where(sq.Eq{jobStatusColAlias: []string{dJob1.Status}}).ToSql()
-
-	dJob1JDQuery3, _, _ := sqq.Select(stateRfPollColsId...).
-		From(stateRfPollTable).
-		Where("job_id = ?", dJob1.Id).ToSql()
-
-	dJob1Query4, _, _ := sqq.Select(columns...).
-		From(jobTable + " " + jobAlias).
-		Where("NOW()-" + jobLastUpdateColAlias +
-			" >= (" + jobLifetimeColAlias + " * '1 sec'::interval)").ToSql()
-
-	dJob1JDQuery4, _, _ := sqq.Select(stateRfPollColsId...).
-		From(stateRfPollTable).
-		Where("job_id = ?", dJob1.Id).ToSql()
+	grpQuery, _, _ := sqq.Select(compGroupsColsSMGroup...).
+		From(compGroupsTable).
+		Where("name = ?", dgrp1.Label).
+		Where("namespace = ?", groupNamespace).ToSql()
+	dval1 := []driver.Value{uuid1, dgrp1.Label, dgrp1.Description, pq.Array(&dgrp1.Tags), dgrp1.ExclusiveGroup, nil, nil, 1}
 
 	tests := []struct {
-		f_opts                 []JobSyncFiltFunc
-		dbColumns              []string
-		dbRows                 [][]driver.Value
-		dbQueryError           error
-		expectedQueryPrepare   string
-		expectedQueryArgs      []driver.Value
-		dbJDColumns            []string
-		dbJDRows               [][]driver.Value
-		dbJDQueryError         error
-		expectedJDQueryPrepare string
-		expectedJDQueryArgs    []driver.Value
-		expectedJ              *sm.Job
+		requester         string
+		dbRows            [][]driver.Value
+		expectedDidDelete bool
 	}{{
-		f_opts:    []JobSyncFiltFunc{JS_ID(dJob1.Id)},
-		dbColumns: columns,
-		dbRows: [][]driver.Value{
-			[]driver.Value{dJob1.Id, dJob1.Type, dJob1.Status, dJob1.LastUpdate, dJob1.Lifetime},
-		},
-		dbQueryError:         nil,
-		expectedQueryPrepare: regexp.QuoteMeta(dJob1Query1),
-		expectedQueryArgs:    []driver.Value{dJob1.Id},
-		dbJDColumns:          dataCols,
-		dbJDRows: [][]driver.Value{
-			[]driver.Value{dJob1.Data.(*sm.SrfpJobData).CompId},
-		},
-		dbJDQueryError:         nil,
-		expectedJDQueryPrepare: regexp.QuoteMeta(dJob1JDQuery1),
-		expectedJDQueryArgs:    []driver.Value{dJob1.Id},
-		expectedJ:              dJob1,
-	}, {
-		f_opts:    []JobSyncFiltFunc{JS_Type(dJob1.Type)},
-		dbColumns: columns,
-		dbRows: [][]driver.Value{
-			[]driver.Value{dJob1.Id, dJob1.Type, dJob1.Status, dJob1.LastUpdate, dJob1.Lifetime},
-		},
-		dbQueryError:         nil,
-		expectedQueryPrepare: regexp.QuoteMeta(dJob1Query2),
-		expectedQueryArgs:    []driver.Value{dJob1.Type},
-		dbJDColumns:          dataCols,
-		dbJDRows: [][]driver.Value{
-			[]driver.Value{dJob1.Data.(*sm.SrfpJobData).CompId},
-		},
-		dbJDQueryError:         nil,
-		expectedJDQueryPrepare: regexp.QuoteMeta(dJob1JDQuery2),
-		expectedJDQueryArgs:    []driver.Value{dJob1.Id},
-		expectedJ:              dJob1,
+		requester:         "cray-smd-loader",
+		dbRows:            [][]driver.Value{dval1},
+		expectedDidDelete: true,
 	}, {
-		f_opts:    []JobSyncFiltFunc{JS_Status(dJob1.Status)},
-		dbColumns: columns,
-		dbRows: [][]driver.Value{
-			[]driver.Value{dJob1.Id, dJob1.Type, dJob1.Status, dJob1.LastUpdate, dJob1.Lifetime},
-		},
-		dbQueryError:         nil,
-		expectedQueryPrepare: regexp.QuoteMeta(dJob1Query3),
-		expectedQueryArgs:    []driver.Value{dJob1.Status},
-		dbJDColumns:          dataCols,
-		dbJDRows: [][]driver.Value{
-			[]driver.Value{dJob1.Data.(*sm.SrfpJobData).CompId},
-		},
-		dbJDQueryError:         nil,
-		expectedJDQueryPrepare: regexp.QuoteMeta(dJob1JDQuery3),
-		expectedJDQueryArgs:    []driver.Value{dJob1.Id},
-		expectedJ:              dJob1,
+		requester:         "",
+		dbRows:            [][]driver.Value{dval1},
+		expectedDidDelete: true,
 	}, {
-		f_opts:    []JobSyncFiltFunc{JS_Expired},
-		dbColumns: columns,
-		dbRows: [][]driver.Value{
-			[]driver.Value{dJob1.Id, dJob1.Type, dJob1.Status, dJob1.LastUpdate, dJob1.Lifetime},
-		},
-		dbQueryError:         nil,
-		expectedQueryPrepare: regexp.QuoteMeta(dJob1Query4),
-		expectedQueryArgs:    []driver.Value{},
-		dbJDColumns:          dataCols,
-		dbJDRows: [][]driver.Value{
-			[]driver.Value{dJob1.Data.(*sm.SrfpJobData).CompId},
-		},
-		dbJDQueryError:         nil,
-		expectedJDQueryPrepare: regexp.QuoteMeta(dJob1JDQuery4),
-		expectedJDQueryArgs:    []driver.Value{dJob1.Id},
-		expectedJ:              dJob1,
+		requester:         "cray-smd-loader",
+		dbRows:            [][]driver.Value{},
+		expectedDidDelete: false,
 	}}
 
 	for i, test := range tests {
 		ResetMockDB()
-		// before we actually execute our api function, we need to expect required DB actions
-		rows := sqlmock.NewRows(test.dbColumns)
+		rows := sqlmock.NewRows(compGroupsColsSMGroup)
 		for _, row := range test.dbRows {
 			rows.AddRow(row...)
 		}
-		jdrows := sqlmock.NewRows(test.dbJDColumns)
-		for _, jdrow := range test.dbJDRows {
-			jdrows.AddRow(jdrow...)
-		}
-
 		mockPG.ExpectBegin()
-		if test.expectedQueryPrepare == "" && test.dbQueryError != nil {
-			mockPG.ExpectRollback()
-		} else if test.dbQueryError != nil {
-			mockPG.ExpectPrepare(test.expectedQueryPrepare).ExpectQuery().WillReturnError(test.dbQueryError)
-			mockPG.ExpectRollback()
-		} else if test.dbJDQueryError != nil {
-			if len(test.expectedQueryArgs) == 0 {
-				mockPG.ExpectPrepare(test.expectedQueryPrepare).ExpectQuery().WillReturnRows(rows)
-			} else {
-				mockPG.ExpectPrepare(test.expectedQueryPrepare).ExpectQuery().WithArgs(test.expectedQueryArgs...).WillReturnRows(rows)
-			}
-			mockPG.ExpectPrepare(test.expectedJDQueryPrepare).ExpectQuery().WillReturnError(test.dbJDQueryError)
+		mockPG.ExpectPrepare(regexp.QuoteMeta(grpQuery)).ExpectQuery().
+			WithArgs(dgrp1.Label, groupNamespace).WillReturnRows(rows)
+		if len(test.dbRows) == 0 {
 			mockPG.ExpectRollback()
 		} else {
-			if len(test.expectedQueryArgs) == 0 {
-				mockPG.ExpectPrepare(test.expectedQueryPrepare).ExpectQuery().WillReturnRows(rows)
-			} else {
-				mockPG.ExpectPrepare(test.expectedQueryPrepare).ExpectQuery().WithArgs(test.expectedQueryArgs...).WillReturnRows(rows)
+			if test.requester != "" {
+				mockPG.ExpectPrepare(regexp.QuoteMeta("SELECT set_config($1, $2, true)")).
+					ExpectQuery().WithArgs(groupHistRequesterSetting, test.requester).
+					WillReturnRows(sqlmock.NewRows([]string{"set_config"}).AddRow(test.requester))
 			}
-			mockPG.ExpectPrepare(test.expectedJDQueryPrepare).ExpectQuery().WithArgs(test.expectedJDQueryArgs...).WillReturnRows(jdrows)
+			mockPG.ExpectPrepare(regexp.QuoteMeta("DELETE FROM component_groups WHERE id = $1")).
+				ExpectExec().WithArgs(uuid1).WillReturnResult(sqlmock.NewResult(0, 1))
 			mockPG.ExpectCommit()
 		}
 
-		js, err := dPG.GetJobs(test.f_opts...)
-		// ensure all expectations have been met
+		didDelete, err := dPG.DeleteGroup(dgrp1.Label, test.requester)
 		if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
 			t.Errorf("Test %v Failed: Sql expectations were not met: %s", i, mock_err)
 		}
-		if test.dbQueryError == nil && test.dbJDQueryError == nil {
-			if err != nil {
-				t.Errorf("Test %v Failed: Unexpected error received: %s", i, err)
-			}
-			if len(js) == 0 {
-				t.Errorf("Test %v Failed: Expected non-empty list of Jobs", i)
-			} else if !reflect.DeepEqual(js[0], test.expectedJ) {
-				t.Errorf("Test %v Failed: Expected Job %v (got %v)",
-					i, js[0], test.expectedJ)
-			}
-		} else if err == nil {
-			t.Errorf("Test %v Failed: Expected an error.", i)
+		if err != nil {
+			t.Errorf("Test %v Failed: Unexpected error %v", i, err)
+		} else if didDelete != test.expectedDidDelete {
+			t.Errorf("Test %v Failed: Expected didDelete %v, got %v", i, test.expectedDidDelete, didDelete)
+		}
+	}
+}
+
+func TestPgGetComponentsFilterLabels(t *testing.T) {
+	f := &ComponentFilter{Labels: []string{"gpu=a100,row notin (3,4),!drain"}}
+	expectedQuery := tGetCompStateOnlyQuery + " WHERE c.id IN (" +
+		"SELECT component_id FROM component_labels WHERE key = $1 AND " +
+		"value IN ($2)) AND c.id NOT IN (SELECT component_id FROM " +
+		"component_labels WHERE key = $3 AND value IN ($4,$5)) AND " +
+		"c.id NOT IN (SELECT component_id FROM component_labels " +
+		"WHERE key = $6)"
+
+	ResetMockDB()
+	mockPG.ExpectBegin()
+	mockPG.ExpectPrepare(regexp.QuoteMeta(expectedQuery)).ExpectQuery().
+		WithArgs("gpu", "a100", "row", "3", "4", "drain").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "type", "state", "flag"}).
+			AddRow("x3000c0s1b0n0", "Node", "Ready", "OK"))
+	mockPG.ExpectCommit()
+
+	comps, err := dPG.GetComponentsFilter(f, FLTR_STATEONLY)
+	if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
+		t.Errorf("Test Failed: Sql expectations were not met: %s", mock_err)
+	}
+	if err != nil {
+		t.Errorf("Test Failed: Unexpected error received: %s", err)
+	} else if len(comps) != 1 || comps[0].ID != "x3000c0s1b0n0" {
+		t.Errorf("Test Failed: Unexpected components: %v", comps)
+	}
+
+	// Bad selectors are rejected before anything is queried.
+	ResetMockDB()
+	mockPG.ExpectBegin()
+	mockPG.ExpectRollback()
+	f = &ComponentFilter{Labels: []string{"gpu in a100"}}
+	if _, err := dPG.GetComponentsFilter(f, FLTR_STATEONLY); err != ErrHMSDSArgBadLabel {
+		t.Errorf("Test Failed: Expected '%s', got '%v'", ErrHMSDSArgBadLabel, err)
+	}
+	if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
+		t.Errorf("Test Failed: Sql expectations were not met: %s", mock_err)
+	}
+}
+
+func TestPgGetCompLabels(t *testing.T) {
+	tests := []struct {
+		id             string
+		dbIDs          []string
+		expectedLabels *sm.ComponentLabels
+	}{{
+		"x3000c0s1b0n0",
+		[]string{"x3000c0s1b0n0"},
+		&sm.ComponentLabels{
+			ID:     "x3000c0s1b0n0",
+			Labels: map[string]string{"gpu": "a100", "drain": ""},
+		},
+	}, {
+		"x3000c0s2b0n0",
+		[]string{},
+		nil,
+	}}
+	for i, test := range tests {
+		ResetMockDB()
+		ids := sqlmock.NewRows([]string{"id"})
+		for _, id := range test.dbIDs {
+			ids.AddRow(id)
 		}
+		mockPG.ExpectBegin()
+		mockPG.ExpectPrepare(regexp.QuoteMeta(getCompIDPrefix +
+			" WHERE (id = $1);")).ExpectQuery().
+			WithArgs(test.id).WillReturnRows(ids)
+		if test.expectedLabels != nil {
+			mockPG.ExpectPrepare(regexp.QuoteMeta(
+				"SELECT key, value FROM component_labels " +
+					"WHERE component_id = $1")).ExpectQuery().
+				WithArgs(test.id).
+				WillReturnRows(sqlmock.NewRows([]string{"key", "value"}).
+					AddRow("gpu", "a100").
+					AddRow("drain", ""))
+			mockPG.ExpectCommit()
+		} else {
+			mockPG.ExpectRollback()
+		}
+
+		labels, err := dPG.GetCompLabels(test.id)
+		if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
+			t.Errorf("Test %v Failed: Sql expectations were not met: %s",
+				i, mock_err)
+		}
+		if err != nil {
+			t.Errorf("Test %v Failed: Unexpected error received: %s", i, err)
+		} else if !reflect.DeepEqual(test.expectedLabels, labels) {
+			t.Errorf("Test %v Failed: Expected labels '%v'; Received '%v'",
+				i, test.expectedLabels, labels)
+		}
+	}
+}
+
+func TestPgPatchCompLabels(t *testing.T) {
+	a100 := "a100"
+	ResetMockDB()
+	mockPG.ExpectBegin()
+	mockPG.ExpectPrepare(regexp.QuoteMeta(getCompIDPrefix +
+		" WHERE (id = $1 OR id = $2);")).ExpectQuery().
+		WithArgs("x3000c0s1b0n0", "x3000c0s2b0n0").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("x3000c0s1b0n0"))
+	mockPG.ExpectPrepare(regexp.QuoteMeta(
+		"DELETE FROM component_labels WHERE component_id IN ($1) "+
+			"AND key IN ($2)")).ExpectExec().
+		WithArgs("x3000c0s1b0n0", "drain").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mockPG.ExpectPrepare(regexp.QuoteMeta(
+		"INSERT INTO component_labels (component_id,key,value) "+
+			"VALUES ($1,$2,$3) ON CONFLICT(component_id, key) DO UPDATE "+
+			"SET value = EXCLUDED.value")).ExpectExec().
+		WithArgs("x3000c0s1b0n0", "gpu", "a100").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mockPG.ExpectCommit()
+
+	ids, err := dPG.PatchCompLabels(
+		[]string{"x3000c0s1b0n0", "x3000c0s2b0n0"},
+		map[string]*string{"gpu": &a100, "drain": nil})
+	if mock_err := mockPG.ExpectationsWereMet(); mock_err != nil {
+		t.Errorf("Test Failed: Sql expectations were not met: %s", mock_err)
+	}
+	if err != nil {
+		t.Errorf("Test Failed: Unexpected error received: %s", err)
+	} else if !compareIDs([]string{"x3000c0s1b0n0"}, ids) {
+		t.Errorf("Test Failed: Unexpected ids: %v", ids)
 	}
 }
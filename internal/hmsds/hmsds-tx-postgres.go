@@ -551,6 +551,9 @@ func (t *hmsdbPgTx) GetComponentsFilterTx(f *ComponentFilter, fieldFltr FieldFil
 	var err error
 	label := "GetComponentsFilterTx"
 
+	if err = t.hdb.loadPgResolvedGroups(t.sc, t.ctx, f); err != nil {
+		return comps, err
+	}
 	query, err := selectComponents(f, fieldFltr)
 	if err != nil {
 		t.LogAlways("Error: %s(): makeComponentQuery failed: %s", label, err)
@@ -591,6 +594,9 @@ func (t *hmsdbPgTx) GetComponentsQueryTx(f *ComponentFilter, fieldFltr FieldFilt
 	if f == nil {
 		f = new(ComponentFilter)
 	}
+	if err = t.hdb.loadPgResolvedGroups(t.sc, t.ctx, f); err != nil {
+		return nil, err
+	}
 	// Get query string
 	query, err := selectComponentsHierarchy(f, fieldFltr, ids)
 	if err != nil {
@@ -609,6 +615,48 @@ func (t *hmsdbPgTx) GetComponentsQueryTx(f *ComponentFilter, fieldFltr FieldFilt
 	return comps, nil
 }
 
+// Get the HMS Components selected by a group expression (in transaction).
+// Returns ErrHMSDSNoGroup if the expression names a group that doesn't
+// exist.  Partitions that don't exist are just empty.
+func (t *hmsdbPgTx) GetComponentsGroupExprTx(e *sm.GroupExpr, fieldFltr FieldFilter) ([]*base.Component, error) {
+	label := "GetComponentsGroupExprTx"
+
+	if e == nil {
+		return nil, ErrHMSDSArgNil
+	}
+	if fieldFltr == FLTR_ID_W_GROUP || fieldFltr == FLTR_ALL_W_GROUP {
+		return nil, ErrHMSDSArgBadArg
+	}
+	resolved := make(map[string]*resolvedGroup)
+	if labels := e.Groups(); len(labels) > 0 {
+		var err error
+		resolved, err = t.hdb.resolvePgGroups(t.sc, t.ctx, labels)
+		if err != nil {
+			return nil, err
+		}
+	}
+	cond, err := groupExprCond(compTableJoinAlias+"."+compIdCol, e, resolved)
+	if err != nil {
+		return nil, err
+	}
+	query, err := makeComponentQuery(compTableJoinAlias, nil, fieldFltr)
+	if err != nil {
+		t.LogAlways("Error: %s(): makeComponentQuery failed: %s", label, err)
+		return nil, err
+	}
+	query = query.Where(cond)
+
+	// Perform corresponding query on DB
+	comps, err := t.sqQueryComponent(query, label, fieldFltr)
+	if err != nil {
+		return nil, err
+	}
+	if len(comps) == 0 {
+		t.Log(LOG_INFO, "Info: %s(): no matches: %s", label, e)
+	}
+	return comps, nil
+}
+
 // Get a single HMS Component by its NID, if the NID exists (in transaction)
 func (t *hmsdbPgTx) GetComponentByNIDTx(nid string) (*base.Component, error) {
 	if nid == "" {
@@ -1298,6 +1346,69 @@ func (t *hmsdbPgTx) BulkUpdateCompRoleTx(ids []string, role, subRole string) (in
 	return rowsAffected, nil
 }
 
+// Make the changes in p to the locked component comp, updating only the
+// fields that would actually change (in transaction).  Returns true if
+// there were any.
+func (t *hmsdbPgTx) PatchComponentTx(
+	comp *base.Component,
+	p *sm.CompPatch,
+) (bool, error) {
+	var cnt int64
+	var err error
+	changed := false
+
+	if p.State != "" {
+		if p.State != comp.State || p.Flag != comp.Flag {
+			// Start states were already checked for the locked row.
+			cnt, err = t.UpdateCompStatesTx([]string{comp.ID}, p.State,
+				p.Flag, true, true, new(PartInfo))
+			if err != nil {
+				return false, err
+			}
+			changed = changed || cnt != 0
+		}
+	} else if p.Flag != "" && p.Flag != comp.Flag {
+		cnt, err = t.UpdateCompFlagOnlyTx(comp.ID, p.Flag)
+		if err != nil {
+			return false, err
+		}
+		changed = changed || cnt != 0
+	}
+	if p.Enabled != nil &&
+		(comp.Enabled == nil || *comp.Enabled != *p.Enabled) {
+		cnt, err = t.UpdateCompEnabledTx(comp.ID, *p.Enabled)
+		if err != nil {
+			return false, err
+		}
+		changed = changed || cnt != 0
+	}
+	if p.SwStatus != nil && *p.SwStatus != comp.SwStatus {
+		cnt, err = t.UpdateCompSwStatusTx(comp.ID, *p.SwStatus)
+		if err != nil {
+			return false, err
+		}
+		changed = changed || cnt != 0
+	}
+	if p.Role != nil || p.SubRole != nil {
+		// Keep whichever of the two wasn't given.
+		role, subRole := comp.Role, comp.SubRole
+		if p.Role != nil {
+			role = *p.Role
+		}
+		if p.SubRole != nil {
+			subRole = *p.SubRole
+		}
+		if role != comp.Role || subRole != comp.SubRole {
+			cnt, err = t.UpdateCompRoleTx(comp.ID, role, subRole)
+			if err != nil {
+				return false, err
+			}
+			changed = changed || cnt != 0
+		}
+	}
+	return changed, nil
+}
+
 // Update Class field only in DB for a list of components
 // (In transaction.)
 // Returns the number of affected rows. < 0 means RowsAffected() is not supported.
@@ -1513,6 +1624,33 @@ func (t *hmsdbPgTx) DeleteComponentsAllTx() (int64, error) {
 	return res.RowsAffected()
 }
 
+// Delete component history entries recorded before the given time, and the
+// oldest ones beyond maxEntries (if maxEntries > 0). (in transaction)
+func (t *hmsdbPgTx) PruneCompHistoryTx(before time.Time, maxEntries int) (int64, error) {
+	if !t.IsConnected() {
+		return 0, ErrHMSDSPtrClosed
+	}
+	var where sq.Sqlizer = sq.Lt{compHistTimestampCol: before}
+	if maxEntries > 0 {
+		// IDs only increase, so the newest maxEntries have the highest.
+		where = sq.Or{
+			where,
+			sq.Expr(compHistIdCol+" <= (SELECT "+compHistIdCol+
+				" FROM "+compHistTable+" ORDER BY "+compHistIdCol+
+				" DESC LIMIT 1 OFFSET ?)", maxEntries),
+		}
+	}
+	query := sq.Delete(compHistTable).Where(where)
+	query = query.PlaceholderFormat(sq.Dollar)
+	qStr, qArgs, _ := query.ToSql()
+	t.Log(LOG_DEBUG, "Debug: PruneCompHistoryTx(): Query: %s - With args: %v", qStr, qArgs)
+	res, err := query.RunWith(t.sc).ExecContext(t.ctx)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
 /////////////////////////////////////////////////////////////////////////////
 //
 // HMSDBTx Interface - Node NID Mapping queries
@@ -2603,150 +2741,571 @@ func (t *hmsdbPgTx) InsertHWInvHistsTx(hhs []*sm.HWInvHist) error {
 
 /////////////////////////////////////////////////////////////////////////////
 //
-// HMSDBTx Interface - RedfishEndpoint queries
+// HMSDBTx Interface - HWInvFirmware queries
 //
 /////////////////////////////////////////////////////////////////////////////
 
-// Back end for all queries that produce one or more RedfishEndpoint rows in
-// the result.
-func (t *hmsdbPgTx) queryRedfishEndpoint(qname, query string, args ...interface{}) ([]*sm.RedfishEndpoint, error) {
-	rows, err := t.getRowsForQuery(qname, query, args...)
+// Get firmware/software inventory entries with filtering options to
+// possibly narrow the returned values.  If no filter provided, just get
+// everything. (in transaction)
+func (t *hmsdbPgTx) GetHWInvFirmwareFilterTx(f_opts ...HWInvFirmwareFiltFunc) ([]*sm.HWInvFirmware, error) {
+	if !t.IsConnected() {
+		return nil, ErrHMSDSPtrClosed
+	}
+	// Parse the filter options
+	f := new(HWInvFirmwareFilter)
+	for _, opts := range f_opts {
+		opts(f)
+	}
+
+	query := sq.Select(addAliasToCols(hwInvFwAlias, hwInvFwCols, hwInvFwCols)...).
+		From(hwInvFwTable + " " + hwInvFwAlias)
+	if len(f.ID) > 0 {
+		query = query.Where(sq.Eq{hwInvFwIdColAlias: f.ID})
+	}
+	if len(f.RfEndpointID) > 0 {
+		ids := []string{}
+		for _, id := range f.RfEndpointID {
+			ids = append(ids, xnametypes.NormalizeHMSCompID(id))
+		}
+		query = query.Where(sq.Eq{hwInvFwRfEPColAlias: ids})
+	}
+	if len(f.Inventory) > 0 {
+		query = query.Where(sq.Eq{hwInvFwInventoryColAlias: f.Inventory})
+	}
+	if len(f.RelatedID) > 0 {
+		ids := []string{}
+		for _, id := range f.RelatedID {
+			ids = append(ids, xnametypes.NormalizeHMSCompID(id))
+		}
+		query = whereComponentCol(query, hwInvFwRelatedIdColAlias, ids)
+	}
+	if len(f.Version) > 0 {
+		query = whereComponentCol(query, hwInvFwVersionColAlias, f.Version)
+	}
+	if len(f.Updateable) > 0 {
+		val := strToDbBool(f.Updateable[0])
+		if val == "" {
+			return nil, ErrHMSDSArgBadArg
+		}
+		query = query.Where(sq.Eq{hwInvFwUpdateableColAlias: val == "1"})
+	}
+	query = query.OrderBy(hwInvFwRelatedIdColAlias, hwInvFwIdColAlias)
+
+	// Execute
+	query = query.PlaceholderFormat(sq.Dollar)
+	qStr, qArgs, _ := query.ToSql()
+	t.Log(LOG_DEBUG, "Debug: GetHWInvFirmwareFilterTx(): Query: %s - With args: %v", qStr, qArgs)
+	rows, err := query.RunWith(t.sc).QueryContext(t.ctx)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	eps := make([]*sm.RedfishEndpoint, 0, 1)
+	fws := make([]*sm.HWInvFirmware, 0, 1)
 	i := 0
 	for rows.Next() {
-		ep, err := t.hdb.scanRedfishEndpoint(rows)
+		fw, err := t.hdb.scanHwInvFirmware(rows)
 		if err != nil {
-			t.LogAlways("Error: %s(%v): Scan failed: %s", qname, args, err)
-			return eps, err
+			t.LogAlways("Error: GetHWInvFirmwareFilterTx(): Scan failed: %s", err)
+			return fws, err
 		}
-		t.Log(LOG_DEBUG, "Debug: %s() scanned[%d]: %v", qname, i, ep)
-		eps = append(eps, ep)
+		t.Log(LOG_DEBUG, "Debug: GetHWInvFirmwareFilterTx() scanned[%d]: %v", i, fw)
+		fws = append(fws, fw)
 		i += 1
 	}
 	err = rows.Err()
-	t.Log(LOG_INFO, "Info: %s(%v) returned %d EPs.", qname, args, len(eps))
-	return eps, err
+	t.Log(LOG_INFO, "Info: GetHWInvFirmwareFilterTx() returned %d firmware items.", len(fws))
+	return fws, err
 }
 
-// Build filter query for RedfishEndpoints using filter functions and
-// then return the set of matching components as an array.
-//
-// NOTE: Most args allow negated arguments, i.e. "!x0c0s0b0", so be careful about
-// passing in user data if the query should only return a single result. RFE_ID
-// does not.
-func (t *hmsdbPgTx) GetRFEndpointsTx(f_opts ...RedfishEPFiltFunc) ([]*sm.RedfishEndpoint, error) {
-
-	f := new(RedfishEPFilter)
-	for _, opts := range f_opts {
-		opts(f)
+// Insert or update an array of firmware/software inventory entries.
+// (in transaction)
+func (t *hmsdbPgTx) UpsertHWInvFirmwaresTx(fws []*sm.HWInvFirmware) error {
+	if !t.IsConnected() {
+		return ErrHMSDSPtrClosed
 	}
-	return t.GetRFEndpointsFilterTx(f)
+	if len(fws) == 0 {
+		// Nothing to do
+		return nil
+	}
+	// Generate query
+	query := sq.Insert(hwInvFwTable).
+		Columns(hwInvFwColsNoTS...)
+	for _, fw := range fws {
+		if fw == nil {
+			return ErrHMSDSArgNil
+		}
+		if err := fw.VerifyNormalize(); err != nil {
+			return ErrHMSDSArgBadID
+		}
+		query = query.Values(fw.ID, fw.RfEndpointID, fw.Inventory,
+			fw.RelatedID, fw.RelatedType, fw.Name, fw.Version, fw.Updateable,
+			fw.SoftwareId, fw.Manufacturer, fw.ReleaseDate, fw.OdataID)
+	}
+	query = query.Suffix("ON CONFLICT(" + hwInvFwRfEPCol + ", " +
+		hwInvFwInventoryCol + ", " + hwInvFwIdCol + ") DO UPDATE SET " +
+		hwInvFwRelatedIdCol + " = EXCLUDED." + hwInvFwRelatedIdCol + ", " +
+		hwInvFwRelatedTypeCol + " = EXCLUDED." + hwInvFwRelatedTypeCol + ", " +
+		hwInvFwNameCol + " = EXCLUDED." + hwInvFwNameCol + ", " +
+		hwInvFwVersionCol + " = EXCLUDED." + hwInvFwVersionCol + ", " +
+		hwInvFwUpdateableCol + " = EXCLUDED." + hwInvFwUpdateableCol + ", " +
+		hwInvFwSoftwareIdCol + " = EXCLUDED." + hwInvFwSoftwareIdCol + ", " +
+		hwInvFwManufacturerCol + " = EXCLUDED." + hwInvFwManufacturerCol + ", " +
+		hwInvFwReleaseDateCol + " = EXCLUDED." + hwInvFwReleaseDateCol + ", " +
+		hwInvFwOdataIdCol + " = EXCLUDED." + hwInvFwOdataIdCol + ", " +
+		hwInvFwLastUpdateCol + " = CURRENT_TIMESTAMP")
+
+	// Exec with statement cache for caching prepared statements (local to tx)
+	query = query.PlaceholderFormat(sq.Dollar)
+	qStr, qArgs, _ := query.ToSql()
+	t.Log(LOG_DEBUG, "Debug: UpsertHWInvFirmwaresTx(): Query: %s - With args: %v", qStr, qArgs)
+	_, err := query.RunWith(t.sc).ExecContext(t.ctx)
+	return ParsePgDBError(err)
 }
 
-// Get RedfishEndpoint by ID (xname), i.e. a single entry.
-func (t *hmsdbPgTx) GetRFEndpointByIDTx(id string) (*sm.RedfishEndpoint, error) {
-	if id == "" {
-		t.LogAlways("Error: GetRFEndpointByIDTx(): xname was empty")
-		return nil, ErrHMSDSArgNil
+// Delete the firmware/software inventory entries reported by a
+// RedfishEndpoint, except those in keep. (in transaction)
+// Returns the number of deleted rows, if error is nil.
+func (t *hmsdbPgTx) DeleteHWInvFirmwareByRFEndpointTx(rfEPID string, keep []*sm.HWInvFirmware) (int64, error) {
+	if !t.IsConnected() {
+		return 0, ErrHMSDSPtrClosed
 	}
-	// Perform corresponding query on DB
-	eps, err := t.queryRedfishEndpoint("GetRFEndpointByIDTx",
-		getRFEndpointByIDQuery, xnametypes.NormalizeHMSCompID(id))
-	if err != nil {
-		return nil, err
+	query := sq.Delete(hwInvFwTable).
+		Where(sq.Eq{hwInvFwRfEPCol: xnametypes.NormalizeHMSCompID(rfEPID)})
+	for _, fw := range keep {
+		query = query.Where(sq.Or{
+			sq.NotEq{hwInvFwInventoryCol: fw.Inventory},
+			sq.NotEq{hwInvFwIdCol: fw.ID},
+		})
 	}
-	// Query succeeded.  There should be at most 1 row returned...
-	if len(eps) == 0 {
-		t.Log(LOG_INFO, "Info: GetRFEndpointByIDTx(%s) matched no entry.", id)
-		return nil, nil
-	} else if len(eps) > 1 {
-		t.LogAlways("Warning: GetRFEndpointByIDTx(%s): multiple entries!.", id)
+	query = query.PlaceholderFormat(sq.Dollar)
+	res, err := query.RunWith(t.sc).ExecContext(t.ctx)
+	if err != nil {
+		return 0, ParsePgDBError(err)
 	}
-	return eps[0], nil
+	return res.RowsAffected()
 }
 
-// Get all RedfishEndpoints in system
-func (t *hmsdbPgTx) GetRFEndpointsAllTx() ([]*sm.RedfishEndpoint, error) {
-	return t.GetRFEndpointsTx(RFE_From("GetRFEndpointsAllTx"))
-}
+/////////////////////////////////////////////////////////////////////////////
+//
+// HMSDBTx Interface - HWInvFan queries
+//
+/////////////////////////////////////////////////////////////////////////////
 
-// Get some or all RedfishEndpoints in system (in transaction), with
-// filtering options to possibly narrow the returned values.
-// If no filter provided, just get everything.  Otherwise use it
-// to create a custom WHERE... string that filters out entries that
-// do not match ALL of the non-empty strings in the filter struct
-func (t *hmsdbPgTx) GetRFEndpointsFilterTx(f *RedfishEPFilter) ([]*sm.RedfishEndpoint, error) {
-	var filterQuery string
-	var reps []*sm.RedfishEndpoint
-	var err error
-	label := "GetRFEndpointsFilterTx" // Use for errors and debug output
+// Get fan inventory entries with filtering options to possibly narrow the
+// returned values.  If no filter provided, just get everything.
+// (in transaction)
+func (t *hmsdbPgTx) GetHWInvFanFilterTx(f_opts ...HWInvFanFiltFunc) ([]*sm.HWInvFan, error) {
+	if !t.IsConnected() {
+		return nil, ErrHMSDSPtrClosed
+	}
+	// Parse the filter options
+	f := new(HWInvFanFilter)
+	for _, opts := range f_opts {
+		opts(f)
+	}
 
-	// If no filter provided, just get everything.  Otherwise use it
-	// to create a custom WHERE... string.
-	if f == nil {
-		filterQuery = getRFEndpointsAllQuery
-		// Perform corresponding query on DB
-		reps, err = t.queryRedfishEndpoint(label, filterQuery)
-		if err != nil {
-			return nil, err
-		}
-	} else {
-		if f.label != "" {
-			label = f.label
+	query := sq.Select(addAliasToCols(hwInvFanAlias, hwInvFanCols, hwInvFanCols)...).
+		From(hwInvFanTable + " " + hwInvFanAlias)
+	if len(f.ID) > 0 {
+		query = query.Where(sq.Eq{hwInvFanIdColAlias: f.ID})
+	}
+	if len(f.ParentID) > 0 {
+		ids := []string{}
+		for _, id := range f.ParentID {
+			ids = append(ids, xnametypes.NormalizeHMSCompID(id))
 		}
-		filterQuery, args, err := buildRedfishEPQuery(getRFEndpointPrefix, f)
-		if err != nil {
-			return nil, err
+		query = whereComponentCol(query, hwInvFanParentIdColAlias, ids)
+	}
+	if len(f.RfEndpointID) > 0 {
+		ids := []string{}
+		for _, id := range f.RfEndpointID {
+			ids = append(ids, xnametypes.NormalizeHMSCompID(id))
 		}
-		// Perform corresponding query on DB
-		reps, err = t.queryRedfishEndpoint(label, filterQuery, args...)
+		query = query.Where(sq.Eq{hwInvFanRfEPColAlias: ids})
+	}
+	if len(f.Status) > 0 {
+		query = query.Where(sq.Eq{hwInvFanStatusColAlias: f.Status})
+	}
+	if len(f.Health) > 0 {
+		query = whereComponentCol(query, hwInvFanHealthColAlias, f.Health)
+	}
+	if len(f.FruId) > 0 {
+		query = query.Where(sq.Eq{hwInvFanFruIdColAlias: f.FruId})
+	}
+	query = query.OrderBy(hwInvFanParentIdColAlias, hwInvFanIdColAlias)
+
+	// Execute
+	query = query.PlaceholderFormat(sq.Dollar)
+	qStr, qArgs, _ := query.ToSql()
+	t.Log(LOG_DEBUG, "Debug: GetHWInvFanFilterTx(): Query: %s - With args: %v", qStr, qArgs)
+	rows, err := query.RunWith(t.sc).QueryContext(t.ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	fans := make([]*sm.HWInvFan, 0, 1)
+	i := 0
+	for rows.Next() {
+		fan, err := t.hdb.scanHwInvFan(rows)
 		if err != nil {
-			return nil, err
+			t.LogAlways("Error: GetHWInvFanFilterTx(): Scan failed: %s", err)
+			return fans, err
 		}
+		t.Log(LOG_DEBUG, "Debug: GetHWInvFanFilterTx() scanned[%d]: %v", i, fan)
+		fans = append(fans, fan)
+		i += 1
 	}
-	// Query succeeded.
-	if len(reps) == 0 {
-		t.Log(LOG_INFO, "Info: %s(): no matches: %s", label, filterQuery)
-		return reps, nil
-	}
-	return reps, nil
+	err = rows.Err()
+	t.Log(LOG_INFO, "Info: GetHWInvFanFilterTx() returned %d fans.", len(fans))
+	return fans, err
 }
 
-// Insert new RedfishEndpoint into database. Does not insert any
-// ComponentEndpoint children.(In transaction.)
-// If ID or FQDN already exists, return ErrHMSDSDuplicateKey
-// No insertion done on err != nil
-func (t *hmsdbPgTx) InsertRFEndpointTx(ep *sm.RedfishEndpoint) error {
-	if ep == nil {
-		t.LogAlways("Error: InsertRFEndpointTx(): EP was nil.")
-		return ErrHMSDSArgNil
-	}
+// Insert or update an array of fan inventory entries. (in transaction)
+func (t *hmsdbPgTx) UpsertHWInvFansTx(fans []*sm.HWInvFan) error {
 	if !t.IsConnected() {
 		return ErrHMSDSPtrClosed
 	}
-	// Prepare query
-	stmt, err := t.conditionalPrepare("InsertRFEndpointTx",
-		insertPgRFEndpointQuery)
-	if err != nil {
-		return err
-	}
-	discInfoJSON, err := json.Marshal(ep.DiscInfo)
-	if err != nil {
-		// This should never fail
-		t.LogAlways(" InsertRFEndpointTx: decode DiscoveryInfo: %s", err)
-	}
-	// Ensure endpoint name is normalized and valid
-	normID := xnametypes.VerifyNormalizeCompID(ep.ID)
-	if normID == "" {
-		t.LogAlways("InsertRFEndpointTx(%s): %s", ep.ID, ErrHMSDSArgBadID)
-		return ErrHMSDSArgBadID
+	if len(fans) == 0 {
+		// Nothing to do
+		return nil
 	}
-
+	// Generate query
+	query := sq.Insert(hwInvFanTable).
+		Columns(hwInvFanColsNoTS...)
+	for _, fan := range fans {
+		if fan == nil {
+			return ErrHMSDSArgNil
+		}
+		if err := fan.VerifyNormalize(); err != nil {
+			return ErrHMSDSArgBadID
+		}
+		query = query.Values(fan.ID, fan.ParentID, fan.ParentType,
+			fan.RfEndpointID, fan.Name, fan.PhysicalContext, fan.ServiceLabel,
+			fan.Status, fan.Health, fan.Flag, fan.FRUID, fan.Manufacturer,
+			fan.Model, fan.PartNumber, fan.SparePartNumber, fan.SerialNumber,
+			fan.OdataID)
+	}
+	query = query.Suffix("ON CONFLICT(" + hwInvFanRfEPCol + ", " +
+		hwInvFanParentIdCol + ", " + hwInvFanIdCol + ") DO UPDATE SET " +
+		hwInvFanParentTypeCol + " = EXCLUDED." + hwInvFanParentTypeCol + ", " +
+		hwInvFanNameCol + " = EXCLUDED." + hwInvFanNameCol + ", " +
+		hwInvFanPhysicalContextCol + " = EXCLUDED." + hwInvFanPhysicalContextCol + ", " +
+		hwInvFanServiceLabelCol + " = EXCLUDED." + hwInvFanServiceLabelCol + ", " +
+		hwInvFanStatusCol + " = EXCLUDED." + hwInvFanStatusCol + ", " +
+		hwInvFanHealthCol + " = EXCLUDED." + hwInvFanHealthCol + ", " +
+		hwInvFanFlagCol + " = EXCLUDED." + hwInvFanFlagCol + ", " +
+		hwInvFanFruIdCol + " = EXCLUDED." + hwInvFanFruIdCol + ", " +
+		hwInvFanManufacturerCol + " = EXCLUDED." + hwInvFanManufacturerCol + ", " +
+		hwInvFanModelCol + " = EXCLUDED." + hwInvFanModelCol + ", " +
+		hwInvFanPartNumberCol + " = EXCLUDED." + hwInvFanPartNumberCol + ", " +
+		hwInvFanSparePartNumberCol + " = EXCLUDED." + hwInvFanSparePartNumberCol + ", " +
+		hwInvFanSerialNumberCol + " = EXCLUDED." + hwInvFanSerialNumberCol + ", " +
+		hwInvFanOdataIdCol + " = EXCLUDED." + hwInvFanOdataIdCol + ", " +
+		hwInvFanLastUpdateCol + " = CURRENT_TIMESTAMP")
+
+	// Exec with statement cache for caching prepared statements (local to tx)
+	query = query.PlaceholderFormat(sq.Dollar)
+	qStr, qArgs, _ := query.ToSql()
+	t.Log(LOG_DEBUG, "Debug: UpsertHWInvFansTx(): Query: %s - With args: %v", qStr, qArgs)
+	_, err := query.RunWith(t.sc).ExecContext(t.ctx)
+	return ParsePgDBError(err)
+}
+
+// Delete the fan inventory entries reported by a RedfishEndpoint, except
+// those in keep. (in transaction)
+// Returns the number of deleted rows, if error is nil.
+func (t *hmsdbPgTx) DeleteHWInvFansByRFEndpointTx(rfEPID string, keep []*sm.HWInvFan) (int64, error) {
+	if !t.IsConnected() {
+		return 0, ErrHMSDSPtrClosed
+	}
+	query := sq.Delete(hwInvFanTable).
+		Where(sq.Eq{hwInvFanRfEPCol: xnametypes.NormalizeHMSCompID(rfEPID)})
+	for _, fan := range keep {
+		query = query.Where(sq.Or{
+			sq.NotEq{hwInvFanParentIdCol: fan.ParentID},
+			sq.NotEq{hwInvFanIdCol: fan.ID},
+		})
+	}
+	query = query.PlaceholderFormat(sq.Dollar)
+	res, err := query.RunWith(t.sc).ExecContext(t.ctx)
+	if err != nil {
+		return 0, ParsePgDBError(err)
+	}
+	return res.RowsAffected()
+}
+
+/////////////////////////////////////////////////////////////////////////////
+//
+// HMSDBTx Interface - Component Health queries
+//
+/////////////////////////////////////////////////////////////////////////////
+
+// Get the health contributors recorded for the given components.
+// (in transaction)
+func (t *hmsdbPgTx) GetCompHealthContributorsTx(ids []string) ([]*sm.CompHealthContributor, error) {
+	if !t.IsConnected() {
+		return nil, ErrHMSDSPtrClosed
+	}
+	normIDs := make([]string, 0, len(ids))
+	for _, id := range ids {
+		normIDs = append(normIDs, xnametypes.NormalizeHMSCompID(id))
+	}
+	query := sq.Select(addAliasToCols(compHealthAlias, compHealthCols, compHealthCols)...).
+		From(compHealthTable + " " + compHealthAlias).
+		Where(sq.Eq{compHealthCompIdColAlias: normIDs}).
+		OrderBy(compHealthCompIdColAlias, compHealthOdataIdColAlias)
+
+	// Execute
+	query = query.PlaceholderFormat(sq.Dollar)
+	qStr, qArgs, _ := query.ToSql()
+	t.Log(LOG_DEBUG, "Debug: GetCompHealthContributorsTx(): Query: %s - With args: %v", qStr, qArgs)
+	rows, err := query.RunWith(t.sc).QueryContext(t.ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	hcs := make([]*sm.CompHealthContributor, 0, 1)
+	i := 0
+	for rows.Next() {
+		hc, err := t.hdb.scanCompHealthContributor(rows)
+		if err != nil {
+			t.LogAlways("Error: GetCompHealthContributorsTx(): Scan failed: %s", err)
+			return hcs, err
+		}
+		t.Log(LOG_DEBUG, "Debug: GetCompHealthContributorsTx() scanned[%d]: %v", i, hc)
+		hcs = append(hcs, hc)
+		i += 1
+	}
+	err = rows.Err()
+	t.Log(LOG_INFO, "Info: GetCompHealthContributorsTx() returned %d contributors.", len(hcs))
+	return hcs, err
+}
+
+// Insert or update an array of health contributors. (in transaction)
+func (t *hmsdbPgTx) UpsertCompHealthContributorsTx(hcs []*sm.CompHealthContributor) error {
+	if !t.IsConnected() {
+		return ErrHMSDSPtrClosed
+	}
+	if len(hcs) == 0 {
+		// Nothing to do
+		return nil
+	}
+	// Generate query
+	query := sq.Insert(compHealthTable).
+		Columns(compHealthColsNoTS...)
+	for _, hc := range hcs {
+		if hc == nil {
+			return ErrHMSDSArgNil
+		}
+		if err := hc.VerifyNormalize(); err != nil {
+			return ErrHMSDSArgBadID
+		}
+		query = query.Values(hc.CompID, hc.OdataID, hc.ID, hc.Type,
+			hc.Health, hc.HealthRollUp, hc.State, hc.Flag, hc.Source)
+	}
+	// Events don't know the xname or type of the subcomponent, so keep
+	// any that were already recorded at discovery time.
+	query = query.Suffix("ON CONFLICT(" + compHealthCompIdCol + ", " +
+		compHealthOdataIdCol + ") DO UPDATE SET " +
+		compHealthSourceIdCol + " = COALESCE(NULLIF(EXCLUDED." +
+		compHealthSourceIdCol + ", ''), " + compHealthTable + "." +
+		compHealthSourceIdCol + "), " +
+		compHealthSourceTypeCol + " = COALESCE(NULLIF(EXCLUDED." +
+		compHealthSourceTypeCol + ", ''), " + compHealthTable + "." +
+		compHealthSourceTypeCol + "), " +
+		compHealthHealthCol + " = EXCLUDED." + compHealthHealthCol + ", " +
+		compHealthHealthRollUpCol + " = EXCLUDED." + compHealthHealthRollUpCol + ", " +
+		compHealthStateCol + " = EXCLUDED." + compHealthStateCol + ", " +
+		compHealthFlagCol + " = EXCLUDED." + compHealthFlagCol + ", " +
+		compHealthSourceCol + " = EXCLUDED." + compHealthSourceCol + ", " +
+		compHealthLastUpdateCol + " = CURRENT_TIMESTAMP")
+
+	// Exec with statement cache for caching prepared statements (local to tx)
+	query = query.PlaceholderFormat(sq.Dollar)
+	qStr, qArgs, _ := query.ToSql()
+	t.Log(LOG_DEBUG, "Debug: UpsertCompHealthContributorsTx(): Query: %s - With args: %v", qStr, qArgs)
+	_, err := query.RunWith(t.sc).ExecContext(t.ctx)
+	return ParsePgDBError(err)
+}
+
+// Delete the health contributors of the given components.  If odataID is
+// non-empty, only the contributor with that Redfish URI is removed.
+// (in transaction)
+// Returns the number of deleted rows, if error is nil.
+func (t *hmsdbPgTx) DeleteCompHealthContributorsTx(ids []string, odataID string) (int64, error) {
+	if !t.IsConnected() {
+		return 0, ErrHMSDSPtrClosed
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	normIDs := make([]string, 0, len(ids))
+	for _, id := range ids {
+		normIDs = append(normIDs, xnametypes.NormalizeHMSCompID(id))
+	}
+	query := sq.Delete(compHealthTable).
+		Where(sq.Eq{compHealthCompIdCol: normIDs})
+	if odataID != "" {
+		query = query.Where(sq.Eq{compHealthOdataIdCol: odataID})
+	}
+	query = query.PlaceholderFormat(sq.Dollar)
+	res, err := query.RunWith(t.sc).ExecContext(t.ctx)
+	if err != nil {
+		return 0, ParsePgDBError(err)
+	}
+	return res.RowsAffected()
+}
+
+/////////////////////////////////////////////////////////////////////////////
+//
+// HMSDBTx Interface - RedfishEndpoint queries
+//
+/////////////////////////////////////////////////////////////////////////////
+
+// Back end for all queries that produce one or more RedfishEndpoint rows in
+// the result.
+func (t *hmsdbPgTx) queryRedfishEndpoint(qname, query string, args ...interface{}) ([]*sm.RedfishEndpoint, error) {
+	rows, err := t.getRowsForQuery(qname, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	eps := make([]*sm.RedfishEndpoint, 0, 1)
+	i := 0
+	for rows.Next() {
+		ep, err := t.hdb.scanRedfishEndpoint(rows)
+		if err != nil {
+			t.LogAlways("Error: %s(%v): Scan failed: %s", qname, args, err)
+			return eps, err
+		}
+		t.Log(LOG_DEBUG, "Debug: %s() scanned[%d]: %v", qname, i, ep)
+		eps = append(eps, ep)
+		i += 1
+	}
+	err = rows.Err()
+	t.Log(LOG_INFO, "Info: %s(%v) returned %d EPs.", qname, args, len(eps))
+	return eps, err
+}
+
+// Build filter query for RedfishEndpoints using filter functions and
+// then return the set of matching components as an array.
+//
+// NOTE: Most args allow negated arguments, i.e. "!x0c0s0b0", so be careful about
+// passing in user data if the query should only return a single result. RFE_ID
+// does not.
+func (t *hmsdbPgTx) GetRFEndpointsTx(f_opts ...RedfishEPFiltFunc) ([]*sm.RedfishEndpoint, error) {
+
+	f := new(RedfishEPFilter)
+	for _, opts := range f_opts {
+		opts(f)
+	}
+	return t.GetRFEndpointsFilterTx(f)
+}
+
+// Get RedfishEndpoint by ID (xname), i.e. a single entry.
+func (t *hmsdbPgTx) GetRFEndpointByIDTx(id string) (*sm.RedfishEndpoint, error) {
+	if id == "" {
+		t.LogAlways("Error: GetRFEndpointByIDTx(): xname was empty")
+		return nil, ErrHMSDSArgNil
+	}
+	// Perform corresponding query on DB
+	eps, err := t.queryRedfishEndpoint("GetRFEndpointByIDTx",
+		getRFEndpointByIDQuery, xnametypes.NormalizeHMSCompID(id))
+	if err != nil {
+		return nil, err
+	}
+	// Query succeeded.  There should be at most 1 row returned...
+	if len(eps) == 0 {
+		t.Log(LOG_INFO, "Info: GetRFEndpointByIDTx(%s) matched no entry.", id)
+		return nil, nil
+	} else if len(eps) > 1 {
+		t.LogAlways("Warning: GetRFEndpointByIDTx(%s): multiple entries!.", id)
+	}
+	return eps[0], nil
+}
+
+// Get all RedfishEndpoints in system
+func (t *hmsdbPgTx) GetRFEndpointsAllTx() ([]*sm.RedfishEndpoint, error) {
+	return t.GetRFEndpointsTx(RFE_From("GetRFEndpointsAllTx"))
+}
+
+// Get some or all RedfishEndpoints in system (in transaction), with
+// filtering options to possibly narrow the returned values.
+// If no filter provided, just get everything.  Otherwise use it
+// to create a custom WHERE... string that filters out entries that
+// do not match ALL of the non-empty strings in the filter struct
+func (t *hmsdbPgTx) GetRFEndpointsFilterTx(f *RedfishEPFilter) ([]*sm.RedfishEndpoint, error) {
+	var filterQuery string
+	var reps []*sm.RedfishEndpoint
+	var err error
+	label := "GetRFEndpointsFilterTx" // Use for errors and debug output
+
+	// If no filter provided, just get everything.  Otherwise use it
+	// to create a custom WHERE... string.
+	if f == nil {
+		filterQuery = getRFEndpointsAllQuery
+		// Perform corresponding query on DB
+		reps, err = t.queryRedfishEndpoint(label, filterQuery)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		if f.label != "" {
+			label = f.label
+		}
+		filterQuery, args, err := buildRedfishEPQuery(getRFEndpointPrefix, f)
+		if err != nil {
+			return nil, err
+		}
+		// Perform corresponding query on DB
+		reps, err = t.queryRedfishEndpoint(label, filterQuery, args...)
+		if err != nil {
+			return nil, err
+		}
+	}
+	// Query succeeded.
+	if len(reps) == 0 {
+		t.Log(LOG_INFO, "Info: %s(): no matches: %s", label, filterQuery)
+		return reps, nil
+	}
+	return reps, nil
+}
+
+// Insert new RedfishEndpoint into database. Does not insert any
+// ComponentEndpoint children.(In transaction.)
+// If ID or FQDN already exists, return ErrHMSDSDuplicateKey
+// No insertion done on err != nil
+func (t *hmsdbPgTx) InsertRFEndpointTx(ep *sm.RedfishEndpoint) error {
+	if ep == nil {
+		t.LogAlways("Error: InsertRFEndpointTx(): EP was nil.")
+		return ErrHMSDSArgNil
+	}
+	if !t.IsConnected() {
+		return ErrHMSDSPtrClosed
+	}
+	// Prepare query
+	stmt, err := t.conditionalPrepare("InsertRFEndpointTx",
+		insertPgRFEndpointQuery)
+	if err != nil {
+		return err
+	}
+	discInfoJSON, err := json.Marshal(ep.DiscInfo)
+	if err != nil {
+		// This should never fail
+		t.LogAlways(" InsertRFEndpointTx: decode DiscoveryInfo: %s", err)
+	}
+	// Ensure endpoint name is normalized and valid
+	normID := xnametypes.VerifyNormalizeCompID(ep.ID)
+	if normID == "" {
+		t.LogAlways("InsertRFEndpointTx(%s): %s", ep.ID, ErrHMSDSArgBadID)
+		return ErrHMSDSArgBadID
+	}
+
 	// Perform insert
 	res, err := stmt.ExecContext(t.ctx,
 		&normID,
@@ -4699,30 +5258,331 @@ func (t *hmsdbPgTx) DeleteSCNSubscriptionsAllTx() (int64, error) {
 	return num, err
 }
 
-////////////////////////////////////////////////////////////////////////////
-//
-// Group and Partition  Management
-//
-////////////////////////////////////////////////////////////////////////////
-
-//
-// Groups
+/////////////////////////////////////////////////////////////////////////////
 //
-
-// Creates new group in component groups, but adds nothing to the members
-// table (in tx, so this can be done in separate query)
+// HMSDBTx Interface - Event Rules
 //
-// Returns: (new UUID string, new group's label, excl group name, error)
-func (t *hmsdbPgTx) InsertEmptyGroupTx(g *sm.Group) (
-	string, string, string, error,
-) {
-	var err error
-	gi := new(compGroupsInsert)
+/////////////////////////////////////////////////////////////////////////////
 
+// Get the stored event rules with the given names, or all of them if names
+// is empty, in descending order of priority. (in transaction)
+func (t *hmsdbPgTx) GetEventRulesTx(names []string) ([]*sm.EventRule, error) {
 	if !t.IsConnected() {
-		return "", "", "", ErrHMSDSPtrClosed
+		return nil, ErrHMSDSPtrClosed
 	}
-	// Normalize and verify fields (note these functions track if this
+	query := sq.Select(addAliasToCols(eventRulesAlias, eventRulesCols, eventRulesCols)...).
+		From(eventRulesTable + " " + eventRulesAlias).
+		OrderBy(eventRulesPriorityColAlias+" DESC", eventRulesNameColAlias)
+	if len(names) > 0 {
+		query = query.Where(sq.Eq{eventRulesNameColAlias: names})
+	}
+
+	// Execute
+	query = query.PlaceholderFormat(sq.Dollar)
+	qStr, qArgs, _ := query.ToSql()
+	t.Log(LOG_DEBUG, "Debug: GetEventRulesTx(): Query: %s - With args: %v", qStr, qArgs)
+	rows, err := query.RunWith(t.sc).QueryContext(t.ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rules := make([]*sm.EventRule, 0, 1)
+	i := 0
+	for rows.Next() {
+		r, err := t.hdb.scanEventRule(rows)
+		if err != nil {
+			t.LogAlways("Error: GetEventRulesTx(): Scan failed: %s", err)
+			return rules, err
+		}
+		t.Log(LOG_DEBUG, "Debug: GetEventRulesTx() scanned[%d]: %v", i, r)
+		rules = append(rules, r)
+		i += 1
+	}
+	err = rows.Err()
+	t.Log(LOG_INFO, "Info: GetEventRulesTx() returned %d rules.", len(rules))
+	return rules, err
+}
+
+// Insert a new event rule. (in transaction)
+func (t *hmsdbPgTx) InsertEventRuleTx(r *sm.EventRule) error {
+	if !t.IsConnected() {
+		return ErrHMSDSPtrClosed
+	}
+	if r == nil {
+		return ErrHMSDSArgNil
+	}
+	ruleJSON, err := eventRuleJSON(r)
+	if err != nil {
+		t.LogAlways("InsertEventRuleTx: encode EventRule: %s", err)
+		return err
+	}
+	query := sq.Insert(eventRulesTable).
+		Columns(eventRulesNameCol, eventRulesPriorityCol, eventRulesRuleCol).
+		Values(r.Name, r.Priority, ruleJSON)
+
+	query = query.PlaceholderFormat(sq.Dollar)
+	qStr, qArgs, _ := query.ToSql()
+	t.Log(LOG_DEBUG, "Debug: InsertEventRuleTx(): Query: %s - With args: %v", qStr, qArgs)
+	_, err = query.RunWith(t.sc).ExecContext(t.ctx)
+	return ParsePgDBError(err)
+}
+
+// Replace an existing event rule.  Returns false if there is none.
+// (in transaction)
+func (t *hmsdbPgTx) UpdateEventRuleTx(r *sm.EventRule) (bool, error) {
+	if !t.IsConnected() {
+		return false, ErrHMSDSPtrClosed
+	}
+	if r == nil {
+		return false, ErrHMSDSArgNil
+	}
+	ruleJSON, err := eventRuleJSON(r)
+	if err != nil {
+		t.LogAlways("UpdateEventRuleTx: encode EventRule: %s", err)
+		return false, err
+	}
+	query := sq.Update(eventRulesTable).
+		Set(eventRulesPriorityCol, r.Priority).
+		Set(eventRulesRuleCol, ruleJSON).
+		Set(eventRulesLastUpdateCol, sq.Expr("CURRENT_TIMESTAMP")).
+		Where(sq.Eq{eventRulesNameCol: r.Name})
+
+	query = query.PlaceholderFormat(sq.Dollar)
+	qStr, qArgs, _ := query.ToSql()
+	t.Log(LOG_DEBUG, "Debug: UpdateEventRuleTx(): Query: %s - With args: %v", qStr, qArgs)
+	res, err := query.RunWith(t.sc).ExecContext(t.ctx)
+	if err != nil {
+		return false, ParsePgDBError(err)
+	}
+	num, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return num > 0, nil
+}
+
+// Delete the event rule with the given name.  Returns false if there is
+// none. (in transaction)
+func (t *hmsdbPgTx) DeleteEventRuleTx(name string) (bool, error) {
+	if !t.IsConnected() {
+		return false, ErrHMSDSPtrClosed
+	}
+	query := sq.Delete(eventRulesTable).
+		Where(sq.Eq{eventRulesNameCol: name})
+
+	query = query.PlaceholderFormat(sq.Dollar)
+	res, err := query.RunWith(t.sc).ExecContext(t.ctx)
+	if err != nil {
+		return false, ParsePgDBError(err)
+	}
+	num, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return num > 0, nil
+}
+
+// Encode an event rule for storage.  The name and priority have their own
+// columns and Source is implied by being stored.
+func eventRuleJSON(r *sm.EventRule) ([]byte, error) {
+	stored := *r
+	stored.Source = ""
+	return json.Marshal(&stored)
+}
+
+/////////////////////////////////////////////////////////////////////////////
+//
+// HMSDBTx Interface - Redfish Event Log
+//
+/////////////////////////////////////////////////////////////////////////////
+
+// Add entries to the Redfish event log. (in transaction)
+func (t *hmsdbPgTx) InsertRFEventLogEntriesTx(entries []*sm.RFEventLogEntry) error {
+	if !t.IsConnected() {
+		return ErrHMSDSPtrClosed
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+	query := sq.Insert(rfEventLogTable).
+		Columns(rfEventLogColsNoID...)
+	for _, ent := range entries {
+		if ent == nil {
+			return ErrHMSDSArgNil
+		}
+		args := ent.MessageArgs
+		if args == nil {
+			args = []string{}
+		}
+		argsJSON, err := json.Marshal(args)
+		if err != nil {
+			return err
+		}
+		query = query.Values(
+			ent.ComponentID,
+			ent.RfEndpointID,
+			ent.EventTimestamp,
+			ent.EventId,
+			ent.Registry,
+			ent.RegistryVersion,
+			ent.MessageId,
+			ent.Severity,
+			ent.Message,
+			argsJSON,
+			ent.OriginOfCondition,
+			ent.FRUID)
+	}
+	query = query.PlaceholderFormat(sq.Dollar)
+	qStr, qArgs, _ := query.ToSql()
+	t.Log(LOG_DEBUG, "Debug: InsertRFEventLogEntriesTx(): Query: %s - With args: %v", qStr, qArgs)
+	_, err := query.RunWith(t.sc).ExecContext(t.ctx)
+	return err
+}
+
+// Get Redfish event log entries, most recent first, matching the filter
+// options. (in transaction)
+func (t *hmsdbPgTx) GetRFEventLogFilterTx(f_opts ...RFEventLogFiltFunc) ([]*sm.RFEventLogEntry, error) {
+	if !t.IsConnected() {
+		return nil, ErrHMSDSPtrClosed
+	}
+	// Parse the filter options
+	f := new(RFEventLogFilter)
+	for _, opts := range f_opts {
+		opts(f)
+	}
+	lower := func(vals []string) []string {
+		lvals := make([]string, 0, len(vals))
+		for _, val := range vals {
+			lvals = append(lvals, strings.ToLower(val))
+		}
+		return lvals
+	}
+
+	query := sq.Select(addAliasToCols(rfEventLogAlias, rfEventLogCols, rfEventLogCols)...).
+		From(rfEventLogTable + " " + rfEventLogAlias)
+	if len(f.ID) > 0 {
+		ids := []string{}
+		for _, id := range f.ID {
+			ids = append(ids, xnametypes.NormalizeHMSCompID(id))
+		}
+		query = query.Where(sq.Or{
+			sq.Eq{rfEventLogCompIdColAlias: ids},
+			sq.Eq{rfEventLogRfEPColAlias: ids},
+		})
+	}
+	if len(f.Severity) > 0 {
+		query = query.Where(sq.Eq{"LOWER(" + rfEventLogSeverityColAlias + ")": lower(f.Severity)})
+	}
+	if len(f.Registry) > 0 {
+		query = query.Where(sq.Eq{"LOWER(" + rfEventLogRegistryColAlias + ")": lower(f.Registry)})
+	}
+	if len(f.MessageId) > 0 {
+		query = query.Where(sq.Eq{"LOWER(" + rfEventLogMessageIdColAlias + ")": lower(f.MessageId)})
+	}
+	if len(f.FruId) > 0 {
+		query = query.Where(sq.Eq{rfEventLogFruIdColAlias: f.FruId})
+	}
+	if f.Since != "" {
+		since, err := time.Parse(time.RFC3339, f.Since)
+		if err != nil {
+			return nil, ErrHMSDSArgBadTimeFormat
+		}
+		query = query.Where(sq.GtOrEq{rfEventLogTimestampColAlias: since})
+	}
+	if f.Until != "" {
+		until, err := time.Parse(time.RFC3339, f.Until)
+		if err != nil {
+			return nil, ErrHMSDSArgBadTimeFormat
+		}
+		query = query.Where(sq.Lt{rfEventLogTimestampColAlias: until})
+	}
+	if f.Marker > 0 {
+		query = query.Where(sq.Lt{rfEventLogIdColAlias: f.Marker})
+	}
+	query = query.OrderBy(rfEventLogIdColAlias + " DESC")
+	if f.Limit > 0 {
+		query = query.Limit(uint64(f.Limit))
+	}
+
+	// Execute
+	query = query.PlaceholderFormat(sq.Dollar)
+	qStr, qArgs, _ := query.ToSql()
+	t.Log(LOG_DEBUG, "Debug: GetRFEventLogFilterTx(): Query: %s - With args: %v", qStr, qArgs)
+	rows, err := query.RunWith(t.sc).QueryContext(t.ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := make([]*sm.RFEventLogEntry, 0, 1)
+	i := 0
+	for rows.Next() {
+		ent, err := t.hdb.scanRFEventLogEntry(rows)
+		if err != nil {
+			t.LogAlways("Error: GetRFEventLogFilterTx(): Scan failed: %s", err)
+			return entries, err
+		}
+		t.Log(LOG_DEBUG, "Debug: GetRFEventLogFilterTx() scanned[%d]: %v", i, ent)
+		entries = append(entries, ent)
+		i += 1
+	}
+	err = rows.Err()
+	t.Log(LOG_INFO, "Info: GetRFEventLogFilterTx() returned %d events.", len(entries))
+	return entries, err
+}
+
+// Delete Redfish event log entries received before the given time, and the
+// oldest ones beyond maxEntries (if maxEntries > 0). (in transaction)
+func (t *hmsdbPgTx) PruneRFEventLogTx(before time.Time, maxEntries int) (int64, error) {
+	if !t.IsConnected() {
+		return 0, ErrHMSDSPtrClosed
+	}
+	var where sq.Sqlizer = sq.Lt{rfEventLogTimestampCol: before}
+	if maxEntries > 0 {
+		// IDs only increase, so the newest maxEntries have the highest.
+		where = sq.Or{
+			where,
+			sq.Expr(rfEventLogIdCol+" <= (SELECT "+rfEventLogIdCol+
+				" FROM "+rfEventLogTable+" ORDER BY "+rfEventLogIdCol+
+				" DESC LIMIT 1 OFFSET ?)", maxEntries),
+		}
+	}
+	query := sq.Delete(rfEventLogTable).Where(where)
+	query = query.PlaceholderFormat(sq.Dollar)
+	qStr, qArgs, _ := query.ToSql()
+	t.Log(LOG_DEBUG, "Debug: PruneRFEventLogTx(): Query: %s - With args: %v", qStr, qArgs)
+	res, err := query.RunWith(t.sc).ExecContext(t.ctx)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+////////////////////////////////////////////////////////////////////////////
+//
+// Group and Partition  Management
+//
+////////////////////////////////////////////////////////////////////////////
+
+//
+// Groups
+//
+
+// Creates new group in component groups, but adds nothing to the members
+// table (in tx, so this can be done in separate query)
+//
+// Returns: (new UUID string, new group's label, excl group name, error)
+func (t *hmsdbPgTx) InsertEmptyGroupTx(g *sm.Group) (
+	string, string, string, error,
+) {
+	var err error
+	gi := new(compGroupsInsert)
+
+	if !t.IsConnected() {
+		return "", "", "", ErrHMSDSPtrClosed
+	}
+	// Normalize and verify fields (note these functions track if this
 	// has been done and only does each once.)
 	g.Normalize()
 	if err = g.Verify(); err != nil {
@@ -4744,10 +5604,22 @@ func (t *hmsdbPgTx) InsertEmptyGroupTx(g *sm.Group) (
 	gi.exclusiveGroupId = g.ExclusiveGroup // empty string == no exclusive group
 
 	// Generate query
-	query := sq.Insert(compGroupsTable).
-		Columns(compGroupsColsAll7...).
-		Values(gi.id, gi.name, gi.description,
-			pq.Array(gi.tags), gi.gtype, gi.namespace, gi.exclusiveGroupId)
+	query := sq.Insert(compGroupsTable)
+	if g.Query == nil {
+		query = query.Columns(compGroupsColsAll7...).
+			Values(gi.id, gi.name, gi.description,
+				pq.Array(gi.tags), gi.gtype, gi.namespace, gi.exclusiveGroupId)
+	} else {
+		// Dynamic group - members are evaluated from the stored query.
+		jsonQuery, err := json.Marshal(g.Query)
+		if err != nil {
+			return "", "", "", err
+		}
+		query = query.Columns(compGroupsColsAll8...).
+			Values(gi.id, gi.name, gi.description,
+				pq.Array(gi.tags), gi.gtype, gi.namespace, gi.exclusiveGroupId,
+				jsonQuery)
+	}
 
 	// Exec with statement cache for caching prepared statements (local to tx)
 	query = query.PlaceholderFormat(sq.Dollar)
@@ -4804,6 +5676,19 @@ func (t *hmsdbPgTx) UpdateEmptyGroupTx(
 			}
 		}
 	}
+	if gp.Query != nil {
+		// Can't turn a static group into a dynamic one, as it would lose
+		// its members.
+		if g.Query == nil {
+			return ErrHMSDSStaticGroup
+		}
+		jsonQuery, err := json.Marshal(gp.Query)
+		if err != nil {
+			return err
+		}
+		update = update.Set(compGroupQueryCol, jsonQuery)
+		doUpdate = true
+	}
 	// Have a change to make...
 	if doUpdate == true {
 		// Exec with statement cache for caching prepared statements
@@ -4991,6 +5876,125 @@ func (t *hmsdbPgTx) GetEmptyPartitionTx(name string) (
 	return
 }
 
+// Get the internal uuids of the sub-partitions of top-level partition name,
+// e.g. p1.1 and p1.2 for p1.  Empty for sub-partitions, which can't have
+// their own.
+func (t *hmsdbPgTx) GetSubPartitionIDsTx(name string) ([]string, error) {
+	if !t.IsConnected() {
+		return nil, ErrHMSDSPtrClosed
+	}
+	uuids := []string{}
+	name = sm.NormalizeGroupField(name)
+	if !sm.IsTopPartition(name) {
+		return uuids, nil
+	}
+	// Generate query
+	query := sq.Select(compGroupIdCol).
+		From(compGroupsTable).
+		Where(sq.Like{compGroupNameCol: name + ".%"}).
+		Where("namespace = ?", partNamespace)
+
+	// Query with statement cache for caching prepared statements (local to tx)
+	query = query.PlaceholderFormat(sq.Dollar)
+	rows, err := query.RunWith(t.sc).QueryContext(t.ctx)
+	if err != nil {
+		t.LogAlways("Error: GetSubPartitionIDsTx(%s): query failed: %s",
+			name, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var uuid string
+		if err := rows.Scan(&uuid); err != nil {
+			t.LogAlways("Error: GetSubPartitionIDsTx(%s): scan failed: %s",
+				name, err)
+			return nil, err
+		}
+		uuids = append(uuids, uuid)
+	}
+	return uuids, nil
+}
+
+// Get the current version of the group or partition with the given uuid,
+// locking it until the end of the transaction so it can't be changed by
+// anyone else in the meantime.
+func (t *hmsdbPgTx) GetGroupVersionTx(uuid string) (int64, error) {
+	var version int64
+
+	if !t.IsConnected() {
+		return 0, ErrHMSDSPtrClosed
+	}
+	// Generate query
+	query := sq.Select(compGroupVersionCol).
+		From(compGroupsTable).
+		Where("id = ?", uuid).
+		Suffix("FOR UPDATE")
+
+	// Query with statement cache for caching prepared statements (local to tx)
+	query = query.PlaceholderFormat(sq.Dollar)
+	err := query.RunWith(t.sc).QueryRowContext(t.ctx).Scan(&version)
+	if err != nil {
+		t.LogAlways("Error: GetGroupVersionTx(%s): query failed: %s",
+			uuid, err)
+		return 0, err
+	}
+	return version, nil
+}
+
+// Delete the group or partition with the given uuid, along with its
+// members.  If no error, bool indicates whether it was present to remove.
+func (t *hmsdbPgTx) DeleteGroupTx(uuid string) (bool, error) {
+	if !t.IsConnected() {
+		return false, ErrHMSDSPtrClosed
+	}
+	query := sq.Delete(compGroupsTable).
+		Where("id = ?", uuid)
+
+	// Execute - Should delete one row.
+	query = query.PlaceholderFormat(sq.Dollar)
+	res, err := query.RunWith(t.sc).ExecContext(t.ctx)
+	if err != nil {
+		return false, err
+	}
+	// See if any rows were affected
+	num, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return num > 0, nil
+}
+
+// Name the service or user making the changes in this transaction, to be
+// recorded in the group, partition and component history.  Does nothing if
+// requester is empty.
+func (t *hmsdbPgTx) SetRequesterTx(requester string) error {
+	if !t.IsConnected() {
+		return ErrHMSDSPtrClosed
+	}
+	if requester == "" {
+		return nil
+	}
+	// Limited by the size of the history column.
+	if len(requester) > 255 {
+		requester = requester[:255]
+	}
+	// Local to the transaction.
+	query := sq.Select().
+		Column(sq.Expr("set_config(?, ?, true)",
+			groupHistRequesterSetting, requester))
+
+	query = query.PlaceholderFormat(sq.Dollar)
+	var val string
+	err := query.RunWith(t.sc).QueryRowContext(t.ctx).Scan(&val)
+	if err != nil {
+		t.LogAlways("Error: SetRequesterTx(%s): query failed: %s",
+			requester, err)
+		return err
+	}
+	return nil
+}
+
 //
 // Members (for either Group/Partition)
 //
@@ -5151,6 +6155,202 @@ func (t *hmsdbPgTx) DeleteMemberTx(uuid, id string) (bool, error) {
 	return false, nil
 }
 
+// Given an internal group_id uuid, delete the members with the given
+// (normalized) ids.
+func (t *hmsdbPgTx) DeleteMembersTx(uuid string, ids []string) error {
+	if !t.IsConnected() {
+		return ErrHMSDSPtrClosed
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	query := sq.Delete(compGroupMembersTable).
+		Where("group_id = ?", uuid).
+		Where(sq.Eq{compGroupMembersCmpIdCol: ids})
+
+	// Exec with statement cache for caching prepared statements (local to tx)
+	query = query.PlaceholderFormat(sq.Dollar)
+	_, err := query.RunWith(t.sc).ExecContext(t.ctx)
+	return err
+}
+
+//
+// Group includes
+//
+
+// Make the group with the given uuid include the groups with the given
+// labels.  Returns ErrHMSDSNoIncludedGroup if one of them doesn't exist and
+// ErrHMSDSGroupCycle if the group is already reachable from one of them, i.e.
+// if it would end up including itself.
+func (t *hmsdbPgTx) InsertGroupIncludesTx(uuid string, labels []string) error {
+	if !t.IsConnected() {
+		return ErrHMSDSPtrClosed
+	}
+	if len(labels) == 0 {
+		return nil
+	}
+	// Serialize with other changes to includes before checking for cycles.
+	if _, err := t.sc.ExecContext(t.ctx, lockPgGroupIncludesTx,
+		groupIncludesLockKey); err != nil {
+		t.LogAlways("Error: InsertGroupIncludesTx(): lock failed: %s", err)
+		return err
+	}
+	// Look up the included groups
+	query := sq.Select(compGroupIdCol).
+		From(compGroupsTable).
+		Where(sq.Eq{compGroupNameCol: labels}).
+		Where("namespace = ?", groupNamespace)
+	query = query.PlaceholderFormat(sq.Dollar)
+	rows, err := query.RunWith(t.sc).QueryContext(t.ctx)
+	if err != nil {
+		t.LogAlways("Error: InsertGroupIncludesTx(): query failed: %s", err)
+		return err
+	}
+	ids := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	unique := make(map[string]bool)
+	for _, label := range labels {
+		unique[label] = true
+	}
+	if len(ids) != len(unique) {
+		return ErrHMSDSNoIncludedGroup
+	}
+	// Check that uuid isn't reachable from any of them
+	roots := sq.Select(compGroupNameCol, compGroupIdCol).
+		From(compGroupsTable).
+		Where(sq.Eq{compGroupIdCol: ids})
+	rootsSql, rootsArgs, err := roots.ToSql()
+	if err != nil {
+		return err
+	}
+	cycle := sq.Select("COUNT(*)").
+		Prefix(fmt.Sprintf(compGroupClosureCTE, rootsSql), rootsArgs...).
+		From("closure cl").
+		Where("cl.id = ?", uuid)
+	cycle = cycle.PlaceholderFormat(sq.Dollar)
+	var count int
+	if err := cycle.RunWith(t.sc).QueryRowContext(t.ctx).Scan(&count); err != nil {
+		t.LogAlways("Error: InsertGroupIncludesTx(): cycle check failed: %s",
+			err)
+		return err
+	}
+	if count > 0 {
+		return ErrHMSDSGroupCycle
+	}
+	// Add the includes
+	insert := sq.Insert(compGroupIncludesTable).
+		Columns(compGroupIncludesColsAll2...)
+	for _, id := range ids {
+		insert = insert.Values(uuid, id)
+	}
+	insert = insert.PlaceholderFormat(sq.Dollar)
+	_, err = insert.RunWith(t.sc).ExecContext(t.ctx)
+	return ParsePgDBError(err)
+}
+
+// Remove all of the includes of the group with the given uuid.
+func (t *hmsdbPgTx) DeleteGroupIncludesTx(uuid string) error {
+	if !t.IsConnected() {
+		return ErrHMSDSPtrClosed
+	}
+	query := sq.Delete(compGroupIncludesTable).
+		Where(compGroupIncludesGrpIdCol+" = ?", uuid)
+	query = query.PlaceholderFormat(sq.Dollar)
+	_, err := query.RunWith(t.sc).ExecContext(t.ctx)
+	return err
+}
+
+////////////////////////////////////////////////////////////////////////////
+//
+// Component Labels
+//
+////////////////////////////////////////////////////////////////////////////
+
+// Get the labels on the component with the given xname id.  Empty if it has
+// none or doesn't exist.
+func (t *hmsdbPgTx) GetCompLabelsTx(id string) (map[string]string, error) {
+	if !t.IsConnected() {
+		return nil, ErrHMSDSPtrClosed
+	}
+	query := sq.Select(compLabelsKeyCol, compLabelsValueCol).
+		From(compLabelsTable).
+		Where(compLabelsCmpIdCol+" = ?", xnametypes.NormalizeHMSCompID(id))
+
+	// Query with statement cache for caching prepared statements (local to tx)
+	query = query.PlaceholderFormat(sq.Dollar)
+	rows, err := query.RunWith(t.sc).QueryContext(t.ctx)
+	if err != nil {
+		t.LogAlways("Error: GetCompLabelsTx(%s): query failed: %s", id, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	labels := make(map[string]string)
+	for rows.Next() {
+		var key, val string
+		if err := rows.Scan(&key, &val); err != nil {
+			t.LogAlways("Error: GetCompLabelsTx(%s): scan failed: %s", id, err)
+			return nil, err
+		}
+		labels[key] = val
+	}
+	return labels, rows.Err()
+}
+
+// Set the given labels on each of the components with the given
+// (normalized) xname ids, overwriting the values of any they already have.
+func (t *hmsdbPgTx) SetCompLabelsTx(ids []string, labels map[string]string) error {
+	if !t.IsConnected() {
+		return ErrHMSDSPtrClosed
+	}
+	if len(ids) == 0 || len(labels) == 0 {
+		return nil
+	}
+	query := sq.Insert(compLabelsTable).
+		Columns(compLabelsColsAll...)
+	for _, id := range ids {
+		for key, val := range labels {
+			query = query.Values(id, key, val)
+		}
+	}
+	query = query.Suffix("ON CONFLICT(" + compLabelsCmpIdCol + ", " +
+		compLabelsKeyCol + ") DO UPDATE SET " + compLabelsValueCol +
+		" = EXCLUDED." + compLabelsValueCol)
+
+	// Exec with statement cache for caching prepared statements (local to tx)
+	query = query.PlaceholderFormat(sq.Dollar)
+	_, err := query.RunWith(t.sc).ExecContext(t.ctx)
+	return ParsePgDBError(err)
+}
+
+// Remove the labels with the given keys, or all of them if keys is nil,
+// from each of the components with the given (normalized) xname ids.
+func (t *hmsdbPgTx) DeleteCompLabelsTx(ids []string, keys []string) error {
+	if !t.IsConnected() {
+		return ErrHMSDSPtrClosed
+	}
+	if len(ids) == 0 || (keys != nil && len(keys) == 0) {
+		return nil
+	}
+	query := sq.Delete(compLabelsTable).
+		Where(sq.Eq{compLabelsCmpIdCol: ids})
+	if keys != nil {
+		query = query.Where(sq.Eq{compLabelsKeyCol: keys})
+	}
+	// Exec with statement cache for caching prepared statements (local to tx)
+	query = query.PlaceholderFormat(sq.Dollar)
+	_, err := query.RunWith(t.sc).ExecContext(t.ctx)
+	return err
+}
+
 ////////////////////////////////////////////////////////////////////////////
 //
 // Component Lock Management
@@ -5601,6 +6801,35 @@ func (t *hmsdbPgTx) UpdateEmptyJobTx(jobId string, status string) (bool, error)
 	return false, nil
 }
 
+// Update the status and LastUpdated fields for multiple Job entries
+// (in transaction).  Returns the number of entries updated.
+func (t *hmsdbPgTx) UpdateEmptyJobsTx(jobIds []string, status string) (int64, error) {
+	if !t.IsConnected() {
+		return 0, ErrHMSDSPtrClosed
+	}
+	if len(jobIds) == 0 {
+		return 0, ErrHMSDSArgEmpty
+	}
+
+	update := sq.Update("").
+		Table(jobTable).
+		Where(sq.Eq{jobIdCol: jobIds})
+	if len(status) > 0 {
+		update = update.Set(jobStatusCol, status)
+	}
+	// Always update the timestamp
+	update = update.Set(jobLastUpdateCol, "NOW()")
+
+	// Exec with statement cache for caching prepared statements
+	update = update.PlaceholderFormat(sq.Dollar)
+	res, err := update.RunWith(t.sc).ExecContext(t.ctx)
+	if err != nil {
+		t.LogAlways("Error: UpdateEmptyJobsTx(): stmt.Exec: %s", err)
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
 // Get the user-readable fields in a job entry but don't fetch its job type
 // specific data (done in transaction, so we can fetch them as part of the
 // same one).
@@ -5674,9 +6903,20 @@ func (t *hmsdbPgTx) GetEmptyJobsTx(f_opts ...JobSyncFiltFunc) (js []*sm.Job, err
 		query = query.Where(sq.Eq{jobStatusColAlias: f.Status})
 	}
 
+	// Filter by component, which is in the job type's table.
+	if f.CompID != nil && len(f.CompID) != 0 {
+		query = query.Join(stateRfPollTable + " " + stateRfPollAlias +
+			" ON " + stateRfPollAlias + "." + stateRfPollJobIdCol +
+			" = " + jobIdColAlias).
+			Where(sq.Eq{stateRfPollAlias + "." + stateRfPollCmpIdCol: f.CompID})
+	}
+
 	if f.isExpired {
 		query = query.Where("NOW()-" + jobLastUpdateColAlias +
 			" >= (" + jobLifetimeColAlias + " * '1 sec'::interval)")
+	} else if f.isNotExpired {
+		query = query.Where("NOW()-" + jobLastUpdateColAlias +
+			" < (" + jobLifetimeColAlias + " * '1 sec'::interval)")
 	}
 
 	// Exec with statement cache for caching prepared statements (local to tx)
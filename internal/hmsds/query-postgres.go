@@ -23,6 +23,7 @@
 package hmsds
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
@@ -32,6 +33,7 @@ import (
 	base "github.com/Cray-HPE/hms-base/v2"
 	"github.com/Cray-HPE/hms-smd/v2/pkg/sm"
 
+	sq "github.com/Masterminds/squirrel"
 	"github.com/lib/pq"
 )
 
@@ -323,6 +325,25 @@ const compGroupsTablePg = pgSchema + "." + compGroupsTable
 const compGroupMembersTablePg = pgSchema + "." + compGroupMembersTable
 
 ////////////////////////////////////////////////////////////////////////////
+//
+// Advisory locks - session-level, so held for as long as the connection
+//
+
+const tryPgAdvisoryLock = `SELECT pg_try_advisory_lock($1)`
+const unlockPgAdvisoryLock = `SELECT pg_advisory_unlock($1)`
+const pingPgAdvisoryLockConn = `SELECT 1`
+
+// Transaction-level lock serializing changes to group includes, so that
+// concurrent changes can't together form a cycle that neither sees.
+const groupIncludesLockKey = int64(0x534d442d47494e43) // "SMD-GINC"
+const lockPgGroupIncludesTx = `SELECT pg_advisory_xact_lock($1)`
+
+//
+// Change notifications - sent by triggers, with the table name as payload
+//
+
+const changesPgChannel = `hsm_changes`
+
 //
 // Row parsing routines by object type
 //
@@ -547,6 +568,172 @@ func (d *hmsdbPg) scanHwInvHist(rows *sql.Rows) (*sm.HWInvHist, error) {
 	return hwhist, nil
 }
 
+// This is used for all routines that read HWInvFirmware structs as rows and
+// replaces rows.Scan in normal usage.
+func (d *hmsdbPg) scanHwInvFirmware(rows *sql.Rows) (*sm.HWInvFirmware, error) {
+	fw := new(sm.HWInvFirmware)
+	err := rows.Scan(
+		&fw.ID,
+		&fw.RfEndpointID,
+		&fw.Inventory,
+		&fw.RelatedID,
+		&fw.RelatedType,
+		&fw.Name,
+		&fw.Version,
+		&fw.Updateable,
+		&fw.SoftwareId,
+		&fw.Manufacturer,
+		&fw.ReleaseDate,
+		&fw.OdataID,
+		&fw.LastUpdate)
+	if err != nil {
+		return nil, err
+	}
+	return fw, nil
+}
+
+// This is used for all routines that read HWInvFan structs as rows and
+// replaces rows.Scan in normal usage.
+func (d *hmsdbPg) scanHwInvFan(rows *sql.Rows) (*sm.HWInvFan, error) {
+	fan := new(sm.HWInvFan)
+	err := rows.Scan(
+		&fan.ID,
+		&fan.ParentID,
+		&fan.ParentType,
+		&fan.RfEndpointID,
+		&fan.Name,
+		&fan.PhysicalContext,
+		&fan.ServiceLabel,
+		&fan.Status,
+		&fan.Health,
+		&fan.Flag,
+		&fan.FRUID,
+		&fan.Manufacturer,
+		&fan.Model,
+		&fan.PartNumber,
+		&fan.SparePartNumber,
+		&fan.SerialNumber,
+		&fan.OdataID,
+		&fan.LastUpdate)
+	if err != nil {
+		return nil, err
+	}
+	return fan, nil
+}
+
+// This is used for all routines that read CompHealthContributor structs as
+// rows and replaces rows.Scan in normal usage.
+func (d *hmsdbPg) scanCompHealthContributor(rows *sql.Rows) (*sm.CompHealthContributor, error) {
+	hc := new(sm.CompHealthContributor)
+	err := rows.Scan(
+		&hc.CompID,
+		&hc.OdataID,
+		&hc.ID,
+		&hc.Type,
+		&hc.Health,
+		&hc.HealthRollUp,
+		&hc.State,
+		&hc.Flag,
+		&hc.Source,
+		&hc.LastUpdate)
+	if err != nil {
+		return nil, err
+	}
+	return hc, nil
+}
+
+// This is used for all routines that read RFEventLogEntry structs as rows
+// and replaces rows.Scan in normal usage.
+func (d *hmsdbPg) scanRFEventLogEntry(rows *sql.Rows) (*sm.RFEventLogEntry, error) {
+	var argsJSON []byte
+
+	ent := new(sm.RFEventLogEntry)
+	err := rows.Scan(
+		&ent.ID,
+		&ent.Timestamp,
+		&ent.ComponentID,
+		&ent.RfEndpointID,
+		&ent.EventTimestamp,
+		&ent.EventId,
+		&ent.Registry,
+		&ent.RegistryVersion,
+		&ent.MessageId,
+		&ent.Severity,
+		&ent.Message,
+		&argsJSON,
+		&ent.OriginOfCondition,
+		&ent.FRUID)
+	if err != nil {
+		return nil, err
+	}
+	if len(argsJSON) > 0 {
+		if err := json.Unmarshal(argsJSON, &ent.MessageArgs); err != nil {
+			d.LogAlways("Warning: scanRFEventLogEntry(): Decode MessageArgs: %s", err)
+		}
+	}
+	return ent, nil
+}
+
+// This is used for all routines that read GroupHistoryEntry structs as rows
+// and replaces rows.Scan in normal usage.
+func (d *hmsdbPg) scanGroupHistoryEntry(rows *sql.Rows) (*sm.GroupHistoryEntry, error) {
+	var data []byte
+
+	ent := new(sm.GroupHistoryEntry)
+	err := rows.Scan(
+		&ent.ID,
+		&ent.Timestamp,
+		&ent.Name,
+		&ent.Op,
+		&ent.Member,
+		&ent.Requester,
+		&data)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > 0 {
+		ent.Data = json.RawMessage(data)
+	}
+	return ent, nil
+}
+
+// This is used for all routines that read CompHistoryEntry structs as rows
+// and replaces rows.Scan in normal usage.
+func (d *hmsdbPg) scanCompHistoryEntry(rows *sql.Rows) (*sm.CompHistoryEntry, error) {
+	ent := new(sm.CompHistoryEntry)
+	err := rows.Scan(
+		&ent.ID,
+		&ent.Timestamp,
+		&ent.ComponentID,
+		&ent.Field,
+		&ent.OldValue,
+		&ent.NewValue,
+		&ent.Source)
+	if err != nil {
+		return nil, err
+	}
+	return ent, nil
+}
+
+// This is used for all routines that read EventRule structs as rows and
+// replaces rows.Scan in normal usage.
+func (d *hmsdbPg) scanEventRule(rows *sql.Rows) (*sm.EventRule, error) {
+	var name string
+	var ruleJSON []byte
+
+	err := rows.Scan(&name, &ruleJSON)
+	if err != nil {
+		return nil, err
+	}
+	r := new(sm.EventRule)
+	if err := json.Unmarshal(ruleJSON, r); err != nil {
+		return nil, err
+	}
+	r.Name = name
+	r.Source = sm.EventRuleSourceAPI
+	return r, nil
+}
+
 // This is used for all routines that read RedfishEndpoint struct as rows and
 // replaces rows.Scan in normal usage.
 func (d *hmsdbPg) scanRedfishEndpoint(rows *sql.Rows) (*sm.RedfishEndpoint, error) {
@@ -695,13 +882,17 @@ func (d *hmsdbPg) scanSCNSubscription(rows *sql.Rows) (*sm.SCNSubscription, erro
 // This is used for all routines that read group entries (sans members) as
 // rows and replaces rows.Scan in normal usage.
 func (d *hmsdbPg) scanPgGroup(rows *sql.Rows) (uuid string, g *sm.Group, err error) {
+	var query []byte
 	g = new(sm.Group)
 	err = rows.Scan(
 		&uuid,
 		&g.Label,
 		&g.Description,
 		pq.Array(&g.Tags), // tags
-		&g.ExclusiveGroup)
+		&g.ExclusiveGroup,
+		&query,              // NULL unless dynamic group
+		pq.Array(&g.Groups), // included groups
+		&g.Version)
 	if err != nil {
 		uuid = ""
 		g = nil
@@ -711,9 +902,119 @@ func (d *hmsdbPg) scanPgGroup(rows *sql.Rows) (uuid string, g *sm.Group, err err
 	if g.Tags == nil {
 		g.Tags = make([]string, 0, 1)
 	}
+	if len(query) > 0 {
+		g.Query = new(sm.GroupQuery)
+		if err = json.Unmarshal(query, g.Query); err != nil {
+			d.LogAlways("Error: scanPgGroup(): Decode query: %s", err)
+			uuid = ""
+			g = nil
+		}
+	}
 	return
 }
 
+// Resolve groups into the static groups and dynamic group queries they
+// contain, following includes transitively, keyed by label.  If labels is
+// non-empty, only those groups are looked up (plain ones included), otherwise
+// all groups that are dynamic or include others are returned.  sc/ctx are
+// either the hmsdbPg's or a transaction's.
+func (d *hmsdbPg) resolvePgGroups(
+	sc *sq.StmtCache,
+	ctx context.Context,
+	labels []string,
+) (map[string]*resolvedGroup, error) {
+	roots := sq.Select(compGroupNameCol, compGroupIdCol).
+		From(compGroupsTable).
+		Where("namespace = ?", groupNamespace)
+	if len(labels) > 0 {
+		roots = roots.Where(sq.Eq{compGroupNameCol: labels})
+	} else {
+		roots = roots.Where(sq.Or{
+			sq.Expr(compGroupQueryCol + " IS NOT NULL"),
+			sq.Expr(compGroupIdCol + " IN (SELECT " +
+				compGroupIncludesGrpIdCol + " FROM " +
+				compGroupIncludesTable + ")"),
+		})
+	}
+	rootsSql, rootsArgs, err := roots.ToSql()
+	if err != nil {
+		return nil, err
+	}
+	query := sq.Select("cl.root", compGroupNameColAlias, compGroupQueryColAlias).
+		Prefix(fmt.Sprintf(compGroupClosureCTE, rootsSql), rootsArgs...).
+		From("closure cl").
+		Join(compGroupsTable + " " + compGroupsAlias + " ON " +
+			compGroupIdColAlias + " = cl.id")
+	query = query.PlaceholderFormat(sq.Dollar)
+	rows, err := query.RunWith(sc).QueryContext(ctx)
+	if err != nil {
+		d.LogAlways("Error: resolvePgGroups(): query failed: %s", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	resolved := make(map[string]*resolvedGroup)
+	for rows.Next() {
+		var root, label string
+		var jsonQuery []byte
+		if err := rows.Scan(&root, &label, &jsonQuery); err != nil {
+			d.LogAlways("Error: resolvePgGroups(): scan failed: %s", err)
+			return nil, err
+		}
+		r, ok := resolved[root]
+		if !ok {
+			r = new(resolvedGroup)
+			resolved[root] = r
+		}
+		if len(jsonQuery) == 0 {
+			r.labels = append(r.labels, label)
+			continue
+		}
+		gq := new(sm.GroupQuery)
+		if err := json.Unmarshal(jsonQuery, gq); err != nil {
+			d.LogAlways("Error: resolvePgGroups(): Decode %s: %s",
+				label, err)
+			return nil, err
+		}
+		r.queries = append(r.queries, gq)
+	}
+	return resolved, rows.Err()
+}
+
+// If any of the groups in f are nested or dynamic, resolve them so they can
+// be expanded when the component query is built.  Filters with no groups
+// (or only NULL, i.e. no group membership) need no lookup.
+func (d *hmsdbPg) loadPgResolvedGroups(
+	sc *sq.StmtCache,
+	ctx context.Context,
+	f *ComponentFilter,
+) error {
+	if f == nil || len(f.Group) == 0 || f.groupsResolved != nil {
+		return nil
+	}
+	if err := f.VerifyNormalize(); err != nil {
+		return err
+	}
+	for _, label := range f.Group {
+		if label == "NULL" {
+			return nil
+		}
+	}
+	resolved, err := d.resolvePgGroups(sc, ctx, f.Group)
+	if err != nil {
+		return err
+	}
+	for label, r := range resolved {
+		if r.isPlain() {
+			delete(resolved, label)
+		}
+	}
+	if len(resolved) > 0 {
+		f.groupsResolved = resolved
+	}
+	return nil
+}
+
 // This is used for all routines that read group entries (sans members) as
 // rows and replaces rows.Scan in normal usage.
 func (d *hmsdbPg) scanPgPartition(rows *sql.Rows) (
@@ -724,7 +1025,8 @@ func (d *hmsdbPg) scanPgPartition(rows *sql.Rows) (
 		&uuid,
 		&p.Name,
 		&p.Description,
-		pq.Array(&p.Tags)) // tags
+		pq.Array(&p.Tags), // tags
+		&p.Version)
 	if err != nil {
 		uuid = ""
 		p = nil
@@ -30,6 +30,7 @@ import (
 	"database/sql"
 	"strings"
 
+	"github.com/Cray-HPE/hms-smd/v2/pkg/sm"
 	"github.com/Cray-HPE/hms-xname/xnametypes"
 
 	sq "github.com/Masterminds/squirrel"
@@ -55,6 +56,7 @@ const compTable = `components`
 
 const compTableJoinAlias = `c`
 const compTableSubAlias = `comp`
+const compTableDynAlias = `dyn` // Used when evaluating dynamic group queries
 
 const (
 	compIdCol          = `id`
@@ -184,6 +186,8 @@ const (
 	compGroupTypeCol      = `type`
 	compGroupNamespaceCol = `namespace`
 	compGroupExGrpCol     = `exclusive_group_identifier`
+	compGroupQueryCol     = `query`
+	compGroupVersionCol   = `version`
 )
 
 // This adds the base table alias to each column.  it can later be appended to.
@@ -195,6 +199,7 @@ const (
 	compGroupTypeColAlias      = compGroupsAlias + "." + compGroupTypeCol
 	compGroupNamespaceColAlias = compGroupsAlias + "." + compGroupNamespaceCol
 	compGroupExGrpColAlias     = compGroupsAlias + "." + compGroupExGrpCol
+	compGroupQueryColAlias     = compGroupsAlias + "." + compGroupQueryCol
 )
 
 // These are the namespace enums used in the DB
@@ -212,13 +217,20 @@ var compGroupsColsAll7 = []string{compGroupIdCol, compGroupNameCol,
 	compGroupDescCol, compGroupTagsCol, compGroupTypeCol,
 	compGroupNamespaceCol, compGroupExGrpCol}
 
-// Columns that go in the group structure plus (uu)id
+// As above, plus the stored query of a dynamic group.
+var compGroupsColsAll8 = []string{compGroupIdCol, compGroupNameCol,
+	compGroupDescCol, compGroupTagsCol, compGroupTypeCol,
+	compGroupNamespaceCol, compGroupExGrpCol, compGroupQueryCol}
+
+// Columns that go in the group structure plus (uu)id.  The next to last is
+// the sorted labels of the groups it directly includes.
 var compGroupsColsSMGroup = []string{compGroupIdCol, compGroupNameCol,
-	compGroupDescCol, compGroupTagsCol, compGroupExGrpCol}
+	compGroupDescCol, compGroupTagsCol, compGroupExGrpCol, compGroupQueryCol,
+	compGroupIncludesArrayCol, compGroupVersionCol}
 
 // Columns that go in the partition structure plus (uu)id
 var compGroupsColsSMPart = []string{compGroupIdCol, compGroupNameCol,
-	compGroupDescCol, compGroupTagsCol}
+	compGroupDescCol, compGroupTagsCol, compGroupVersionCol}
 
 type compGroupsInsert struct {
 	id               string
@@ -270,6 +282,101 @@ type compGroupMembersInsertNoTS struct {
 // Get only user visible columns from component_group_members
 var compGroupMembersColsUser = []string{compGroupMembersCmpIdCol}
 
+// component_group_includes table
+
+const compGroupIncludesTable = `component_group_includes`
+const compGroupIncludesAlias = `gi` // used during joins, i.e. gi.group_id
+
+const (
+	compGroupIncludesGrpIdCol = `group_id`
+	compGroupIncludesIncIdCol = `included_id`
+)
+
+// This adds the base table alias to each column.  it can later be appended to.
+const (
+	compGroupIncludesGrpIdColAlias = compGroupIncludesAlias + "." + compGroupIncludesGrpIdCol
+	compGroupIncludesIncIdColAlias = compGroupIncludesAlias + "." + compGroupIncludesIncIdCol
+)
+
+// component_group_includes table - all columns
+var compGroupIncludesColsAll2 = []string{compGroupIncludesGrpIdCol,
+	compGroupIncludesIncIdCol}
+
+// Labels of the groups directly included by a component_groups row, as a
+// column expression for selects on the unaliased component_groups table.
+const compGroupIncludesArrayCol = `ARRAY(SELECT ig.name FROM ` +
+	compGroupIncludesTable + ` ` + compGroupIncludesAlias +
+	` JOIN ` + compGroupsTable + ` ig ON ig.id = ` +
+	compGroupIncludesIncIdColAlias + ` WHERE ` +
+	compGroupIncludesGrpIdColAlias + ` = ` + compGroupsTable + `.id` +
+	` ORDER BY ig.name) AS groups`
+
+// Recursive CTE giving, for each root group, the ids of itself and every
+// group it includes, transitively.  The args and Where clauses of the
+// non-recursive term are filled in with fmt.Sprintf.  UNION rather than
+// UNION ALL so that a cycle, if one ever got in, would still terminate.
+const compGroupClosureCTE = `WITH RECURSIVE closure(root, id) AS (%s` +
+	` UNION SELECT cl.root, ` + compGroupIncludesIncIdColAlias +
+	` FROM closure cl JOIN ` + compGroupIncludesTable + ` ` +
+	compGroupIncludesAlias + ` ON ` + compGroupIncludesGrpIdColAlias +
+	` = cl.id)`
+
+// component_group_history table
+
+const compGroupHistTable = `component_group_history`
+
+const (
+	compGroupHistIdCol        = `id`
+	compGroupHistTimestampCol = `timestamp`
+	compGroupHistNamespaceCol = `namespace`
+	compGroupHistNameCol      = `name`
+	compGroupHistOpCol        = `op`
+	compGroupHistMemberCol    = `member`
+	compGroupHistRequesterCol = `requester`
+	compGroupHistDataCol      = `data`
+)
+
+// component_group_history table - columns for sm.GroupHistoryEntry
+var compGroupHistColsSM = []string{compGroupHistIdCol,
+	compGroupHistTimestampCol, compGroupHistNameCol, compGroupHistOpCol,
+	compGroupHistMemberCol, compGroupHistRequesterCol, compGroupHistDataCol}
+
+// Setting read by the group and component history triggers for the
+// requester of the changes made in the current transaction.
+const groupHistRequesterSetting = `hsm.requester`
+
+// component_history table
+
+const compHistTable = `component_history`
+
+const (
+	compHistIdCol        = `id`
+	compHistTimestampCol = `timestamp`
+	compHistCmpIdCol     = `component_id`
+	compHistFieldCol     = `field`
+	compHistOldValueCol  = `old_value`
+	compHistNewValueCol  = `new_value`
+	compHistSourceCol    = `source`
+)
+
+// component_history table - columns for sm.CompHistoryEntry
+var compHistColsSM = []string{compHistIdCol, compHistTimestampCol,
+	compHistCmpIdCol, compHistFieldCol, compHistOldValueCol,
+	compHistNewValueCol, compHistSourceCol}
+
+// component_labels table
+
+const compLabelsTable = `component_labels`
+
+const (
+	compLabelsCmpIdCol = `component_id`
+	compLabelsKeyCol   = `key`
+	compLabelsValueCol = `value`
+)
+
+var compLabelsColsAll = []string{compLabelsCmpIdCol, compLabelsKeyCol,
+	compLabelsValueCol}
+
 //                                                                           //
 //                            Component Locks V2                             //
 //                                                                           //
@@ -681,6 +788,223 @@ var hwInvHistCols = []string{hwInvHistIdCol, hwInvHistFruIdCol,
 var hwInvHistColsNoTS = []string{hwInvHistIdCol, hwInvHistFruIdCol,
 	hwInvHistEventTypeCol}
 
+//                                                                          //
+//                         HwInv Firmware structs                           //
+//                                                                          //
+
+const hwInvFwTable = `hwinv_firmware`
+const hwInvFwAlias = `fw`
+
+const (
+	hwInvFwIdCol           = `id`
+	hwInvFwRfEPCol         = `rf_endpoint_id`
+	hwInvFwInventoryCol    = `inventory`
+	hwInvFwRelatedIdCol    = `related_id`
+	hwInvFwRelatedTypeCol  = `related_type`
+	hwInvFwNameCol         = `name`
+	hwInvFwVersionCol      = `version`
+	hwInvFwUpdateableCol   = `updateable`
+	hwInvFwSoftwareIdCol   = `software_id`
+	hwInvFwManufacturerCol = `manufacturer`
+	hwInvFwReleaseDateCol  = `release_date`
+	hwInvFwOdataIdCol      = `odata_id`
+	hwInvFwLastUpdateCol   = `last_update`
+)
+
+// This adds the base table alias to each column.  it can later be appended to.
+const (
+	hwInvFwIdColAlias         = hwInvFwAlias + "." + hwInvFwIdCol
+	hwInvFwRfEPColAlias       = hwInvFwAlias + "." + hwInvFwRfEPCol
+	hwInvFwInventoryColAlias  = hwInvFwAlias + "." + hwInvFwInventoryCol
+	hwInvFwRelatedIdColAlias  = hwInvFwAlias + "." + hwInvFwRelatedIdCol
+	hwInvFwVersionColAlias    = hwInvFwAlias + "." + hwInvFwVersionCol
+	hwInvFwUpdateableColAlias = hwInvFwAlias + "." + hwInvFwUpdateableCol
+)
+
+// hwInvFw table columns.
+var hwInvFwCols = []string{hwInvFwIdCol, hwInvFwRfEPCol, hwInvFwInventoryCol,
+	hwInvFwRelatedIdCol, hwInvFwRelatedTypeCol, hwInvFwNameCol,
+	hwInvFwVersionCol, hwInvFwUpdateableCol, hwInvFwSoftwareIdCol,
+	hwInvFwManufacturerCol, hwInvFwReleaseDateCol, hwInvFwOdataIdCol,
+	hwInvFwLastUpdateCol}
+
+var hwInvFwColsNoTS = []string{hwInvFwIdCol, hwInvFwRfEPCol,
+	hwInvFwInventoryCol, hwInvFwRelatedIdCol, hwInvFwRelatedTypeCol,
+	hwInvFwNameCol, hwInvFwVersionCol, hwInvFwUpdateableCol,
+	hwInvFwSoftwareIdCol, hwInvFwManufacturerCol, hwInvFwReleaseDateCol,
+	hwInvFwOdataIdCol}
+
+//                                                                          //
+//                           HwInv Fan structs                              //
+//                                                                          //
+
+const hwInvFanTable = `hwinv_fans`
+const hwInvFanAlias = `fan`
+
+const (
+	hwInvFanIdCol              = `id`
+	hwInvFanParentIdCol        = `parent_id`
+	hwInvFanParentTypeCol      = `parent_type`
+	hwInvFanRfEPCol            = `rf_endpoint_id`
+	hwInvFanNameCol            = `name`
+	hwInvFanPhysicalContextCol = `physical_context`
+	hwInvFanServiceLabelCol    = `service_label`
+	hwInvFanStatusCol          = `status`
+	hwInvFanHealthCol          = `health`
+	hwInvFanFlagCol            = `flag`
+	hwInvFanFruIdCol           = `fru_id`
+	hwInvFanManufacturerCol    = `manufacturer`
+	hwInvFanModelCol           = `model`
+	hwInvFanPartNumberCol      = `part_number`
+	hwInvFanSparePartNumberCol = `spare_part_number`
+	hwInvFanSerialNumberCol    = `serial_number`
+	hwInvFanOdataIdCol         = `odata_id`
+	hwInvFanLastUpdateCol      = `last_update`
+)
+
+// This adds the base table alias to each column.  it can later be appended to.
+const (
+	hwInvFanIdColAlias       = hwInvFanAlias + "." + hwInvFanIdCol
+	hwInvFanParentIdColAlias = hwInvFanAlias + "." + hwInvFanParentIdCol
+	hwInvFanRfEPColAlias     = hwInvFanAlias + "." + hwInvFanRfEPCol
+	hwInvFanStatusColAlias   = hwInvFanAlias + "." + hwInvFanStatusCol
+	hwInvFanHealthColAlias   = hwInvFanAlias + "." + hwInvFanHealthCol
+	hwInvFanFruIdColAlias    = hwInvFanAlias + "." + hwInvFanFruIdCol
+)
+
+// hwInvFan table columns.
+var hwInvFanCols = []string{hwInvFanIdCol, hwInvFanParentIdCol,
+	hwInvFanParentTypeCol, hwInvFanRfEPCol, hwInvFanNameCol,
+	hwInvFanPhysicalContextCol, hwInvFanServiceLabelCol, hwInvFanStatusCol,
+	hwInvFanHealthCol, hwInvFanFlagCol, hwInvFanFruIdCol,
+	hwInvFanManufacturerCol, hwInvFanModelCol, hwInvFanPartNumberCol,
+	hwInvFanSparePartNumberCol, hwInvFanSerialNumberCol, hwInvFanOdataIdCol,
+	hwInvFanLastUpdateCol}
+
+var hwInvFanColsNoTS = []string{hwInvFanIdCol, hwInvFanParentIdCol,
+	hwInvFanParentTypeCol, hwInvFanRfEPCol, hwInvFanNameCol,
+	hwInvFanPhysicalContextCol, hwInvFanServiceLabelCol, hwInvFanStatusCol,
+	hwInvFanHealthCol, hwInvFanFlagCol, hwInvFanFruIdCol,
+	hwInvFanManufacturerCol, hwInvFanModelCol, hwInvFanPartNumberCol,
+	hwInvFanSparePartNumberCol, hwInvFanSerialNumberCol, hwInvFanOdataIdCol}
+
+//                                                                          //
+//                        Component Health structs                          //
+//                                                                          //
+
+const compHealthTable = `comp_health`
+const compHealthAlias = `ch`
+
+const (
+	compHealthCompIdCol       = `comp_id`
+	compHealthOdataIdCol      = `odata_id`
+	compHealthSourceIdCol     = `source_id`
+	compHealthSourceTypeCol   = `source_type`
+	compHealthHealthCol       = `health`
+	compHealthHealthRollUpCol = `health_rollup`
+	compHealthStateCol        = `state`
+	compHealthFlagCol         = `flag`
+	compHealthSourceCol       = `source`
+	compHealthLastUpdateCol   = `last_update`
+)
+
+// This adds the base table alias to each column.  it can later be appended to.
+const (
+	compHealthCompIdColAlias  = compHealthAlias + "." + compHealthCompIdCol
+	compHealthOdataIdColAlias = compHealthAlias + "." + compHealthOdataIdCol
+)
+
+// comp_health table columns.
+var compHealthCols = []string{compHealthCompIdCol, compHealthOdataIdCol,
+	compHealthSourceIdCol, compHealthSourceTypeCol, compHealthHealthCol,
+	compHealthHealthRollUpCol, compHealthStateCol, compHealthFlagCol,
+	compHealthSourceCol, compHealthLastUpdateCol}
+
+var compHealthColsNoTS = []string{compHealthCompIdCol, compHealthOdataIdCol,
+	compHealthSourceIdCol, compHealthSourceTypeCol, compHealthHealthCol,
+	compHealthHealthRollUpCol, compHealthStateCol, compHealthFlagCol,
+	compHealthSourceCol}
+
+//                                                                          //
+//                           Event Rule structs                             //
+//                                                                          //
+
+const eventRulesTable = `event_rules`
+const eventRulesAlias = `er`
+
+const (
+	eventRulesNameCol       = `name`
+	eventRulesPriorityCol   = `priority`
+	eventRulesRuleCol       = `rule`
+	eventRulesLastUpdateCol = `last_update`
+)
+
+// This adds the base table alias to each column.  it can later be appended to.
+const (
+	eventRulesNameColAlias     = eventRulesAlias + "." + eventRulesNameCol
+	eventRulesPriorityColAlias = eventRulesAlias + "." + eventRulesPriorityCol
+)
+
+// event_rules table columns.
+var eventRulesCols = []string{eventRulesNameCol, eventRulesRuleCol}
+
+//                                                                          //
+//                         Redfish Event Log structs                        //
+//                                                                          //
+
+const rfEventLogTable = `rf_event_log`
+const rfEventLogAlias = `el`
+
+const (
+	rfEventLogIdCol             = `id`
+	rfEventLogCompIdCol         = `comp_id`
+	rfEventLogRfEPCol           = `rf_endpoint_id`
+	rfEventLogTimestampCol      = `timestamp`
+	rfEventLogEventTimestampCol = `event_timestamp`
+	rfEventLogEventIdCol        = `event_id`
+	rfEventLogRegistryCol       = `registry`
+	rfEventLogRegVersionCol     = `registry_version`
+	rfEventLogMessageIdCol      = `message_id`
+	rfEventLogSeverityCol       = `severity`
+	rfEventLogMessageCol        = `message`
+	rfEventLogMessageArgsCol    = `message_args`
+	rfEventLogOriginCol         = `origin`
+	rfEventLogFruIdCol          = `fru_id`
+)
+
+// This adds the base table alias to each column.  it can later be appended to.
+const (
+	rfEventLogIdColAlias        = rfEventLogAlias + "." + rfEventLogIdCol
+	rfEventLogCompIdColAlias    = rfEventLogAlias + "." + rfEventLogCompIdCol
+	rfEventLogRfEPColAlias      = rfEventLogAlias + "." + rfEventLogRfEPCol
+	rfEventLogTimestampColAlias = rfEventLogAlias + "." + rfEventLogTimestampCol
+	rfEventLogRegistryColAlias  = rfEventLogAlias + "." + rfEventLogRegistryCol
+	rfEventLogMessageIdColAlias = rfEventLogAlias + "." + rfEventLogMessageIdCol
+	rfEventLogSeverityColAlias  = rfEventLogAlias + "." + rfEventLogSeverityCol
+	rfEventLogFruIdColAlias     = rfEventLogAlias + "." + rfEventLogFruIdCol
+)
+
+// rf_event_log table columns that are written.  The id and timestamp are
+// assigned by the database.
+var rfEventLogColsNoID = []string{
+	rfEventLogCompIdCol,
+	rfEventLogRfEPCol,
+	rfEventLogEventTimestampCol,
+	rfEventLogEventIdCol,
+	rfEventLogRegistryCol,
+	rfEventLogRegVersionCol,
+	rfEventLogMessageIdCol,
+	rfEventLogSeverityCol,
+	rfEventLogMessageCol,
+	rfEventLogMessageArgsCol,
+	rfEventLogOriginCol,
+	rfEventLogFruIdCol,
+}
+
+// rf_event_log table columns, in scan order.
+var rfEventLogCols = append([]string{rfEventLogIdCol, rfEventLogTimestampCol},
+	rfEventLogColsNoID...)
+
 //                                                                           //
 //                                 Job Sync                                  //
 //                                                                           //
@@ -763,6 +1087,26 @@ type stateRfPollInsert struct {
 	job_id  string
 }
 
+//                                                                           //
+//                             Service Instances                             //
+//                                                                           //
+
+// service_instances table - one row per running instance of HSM,
+// refreshed as a heartbeat.
+
+const serviceInstTable = `service_instances`
+
+const (
+	serviceInstIdCol            = `id`
+	serviceInstStartedCol       = `started`
+	serviceInstLastHeartbeatCol = `last_heartbeat`
+	serviceInstLeaderCol        = `leader`
+)
+
+// service_instances table columns, in scan order.
+var serviceInstCols = []string{serviceInstIdCol, serviceInstStartedCol,
+	serviceInstLastHeartbeatCol, serviceInstLeaderCol}
+
 ////////////////////////////////////////////////////////////////////////////
 //
 // Helper functions - Query building
@@ -949,10 +1293,24 @@ func makeComponentQuery(alias string, f *ComponentFilter, fltr FieldFilter) (
 	// sql statement.
 	query = whereComponentCols(query, alias, f)
 
+	// If one or more groups are nested or dynamic, do all of them as
+	// sub-selects so the join is only needed for partitions.
+	var groupArgs []string
+	if f != nil {
+		groupArgs = f.Group
+		if len(f.groupsResolved) > 0 {
+			var err error
+			query, err = whereComponentDynGroupCol(query, alias, f)
+			if err != nil {
+				return query, err
+			}
+			groupArgs = nil
+		}
+	}
 	// Determine if we really need a join
 	needJoin := false
 	groupAfterJoin := true
-	if f != nil && (len(f.Partition) > 0 || len(f.Group) > 0) {
+	if f != nil && (len(f.Partition) > 0 || len(groupArgs) > 0) {
 		needJoin = true
 	}
 	if fltr == FLTR_ID_W_GROUP || fltr == FLTR_ALL_W_GROUP {
@@ -962,7 +1320,7 @@ func makeComponentQuery(alias string, f *ComponentFilter, fltr FieldFilter) (
 	// Yes - need a join
 	if needJoin {
 		var err error
-		query, err = joinComponentsWithGroups(query, alias, f.Group,
+		query, err = joinComponentsWithGroups(query, alias, groupArgs,
 			f.Partition, groupAfterJoin)
 		if err != nil {
 			return query, err
@@ -1000,6 +1358,33 @@ func whereComponentCols(q sq.SelectBuilder, alias string, f *ComponentFilter) sq
 	// interaction between them
 	q = whereComponentNIDCol(q, alias, f)
 
+	q = whereComponentLabels(q, alias, f.labelReqs)
+
+	return q
+}
+
+// Adds the requirements of the filter's label selectors to the where clause
+// of an existing query, each as a sub-select of the components with a
+// matching label.
+func whereComponentLabels(
+	q sq.SelectBuilder,
+	alias string,
+	reqs []sm.LabelRequirement,
+) sq.SelectBuilder {
+	idCol := alias + "." + compIdCol
+	for _, req := range reqs {
+		labelQuery := sq.Select(compLabelsCmpIdCol).
+			From(compLabelsTable).
+			Where(sq.Eq{compLabelsKeyCol: req.Key})
+		if req.Values != nil {
+			labelQuery = labelQuery.Where(sq.Eq{compLabelsValueCol: req.Values})
+		}
+		if req.Negate {
+			q = q.Where(sq.Expr(idCol+" NOT IN (?)", labelQuery))
+		} else {
+			q = q.Where(sq.Expr(idCol+" IN (?)", labelQuery))
+		}
+	}
 	return q
 }
 
@@ -1162,18 +1547,192 @@ func whereComponentGrpCol(
 		q = q.Where(sq.Or{
 			sq.And{sq.Eq{alias + compGroupNameColAlias: group_args},
 				sq.Eq{alias + compGroupNamespaceColAlias: groupNamespace}},
-			sq.And{sq.Eq{alias + compGroupNameColAlias: part_args},
+			sq.And{partNameCond(alias+compGroupNameColAlias, part_args),
 				sq.Eq{alias + compGroupNamespaceColAlias: partNamespace}}})
 	} else if group_args != nil {
 		q = q.Where(sq.And{sq.Eq{alias + compGroupNameColAlias: group_args},
 			sq.Eq{alias + compGroupNamespaceColAlias: groupNamespace}})
 	} else if part_args != nil {
-		q = q.Where(sq.And{sq.Eq{alias + compGroupNameColAlias: part_args},
+		q = q.Where(sq.And{partNameCond(alias+compGroupNameColAlias, part_args),
 			sq.Eq{alias + compGroupNamespaceColAlias: partNamespace}})
 	}
 	return q, nil
 }
 
+// Effective definition of a nested or dynamic group: the labels of the
+// static groups whose stored members it contains and the queries of the
+// dynamic groups it contains, itself included in either case.
+type resolvedGroup struct {
+	labels  []string
+	queries []*sm.GroupQuery
+}
+
+// True if the group is just a static group with no includes, i.e. its
+// stored members are all there is.
+func (r *resolvedGroup) isPlain() bool {
+	return len(r.queries) == 0 && len(r.labels) <= 1
+}
+
+// Does the group filter when at least one of the groups is nested or
+// dynamic, i.e. f.groupsResolved has been filled in.  Components match if
+// they are in any of the groups: plain ones via their stored members, the
+// others by expanding them with groupMemberCond.
+func whereComponentDynGroupCol(
+	q sq.SelectBuilder,
+	alias string,
+	f *ComponentFilter,
+) (sq.SelectBuilder, error) {
+	idCol := alias + "." + compIdCol
+	all := new(resolvedGroup)
+	for _, label := range f.Group {
+		r, ok := f.groupsResolved[label]
+		if !ok {
+			all.labels = append(all.labels, label)
+			continue
+		}
+		all.labels = append(all.labels, r.labels...)
+		all.queries = append(all.queries, r.queries...)
+	}
+	cond, err := groupMemberCond(idCol, all)
+	if err != nil {
+		return q, err
+	}
+	return q.Where(cond), nil
+}
+
+// Condition matching idCol against the effective members of a resolved
+// group: the stored members of its static groups or anything matching one
+// of its queries.
+func groupMemberCond(idCol string, r *resolvedGroup) (sq.Sqlizer, error) {
+	or := sq.Or{}
+	for _, gq := range r.queries {
+		dq, err := selectGroupQueryIDs(gq)
+		if err != nil {
+			return nil, err
+		}
+		or = append(or, dq.Prefix(idCol+" IN (").Suffix(")"))
+	}
+	if len(r.labels) > 0 {
+		mq := selectMemberIDs(r.labels, groupNamespace)
+		or = append(or, mq.Prefix(idCol+" IN (").Suffix(")"))
+	}
+	if len(or) == 0 {
+		// Nothing can match, e.g. a group that only includes empty ones.
+		return sq.Expr("FALSE"), nil
+	}
+	return or, nil
+}
+
+// Select the stored member ids of the groups or partitions (according to
+// namespace) with the given names.  The result is meant to be used as a
+// sub-select.
+func selectMemberIDs(names []string, namespace string) sq.SelectBuilder {
+	var nameCond sq.Sqlizer = sq.Eq{compGroupNameColAlias: names}
+	if namespace == partNamespace {
+		nameCond = partNameCond(compGroupNameColAlias, names)
+	}
+	return sq.Select(compGroupMembersCmpIdColAlias).
+		From(compGroupMembersTable + " " + compGroupMembersAlias).
+		Join(compGroupsTable + " " + compGroupsAlias + " ON " +
+			compGroupIdColAlias + " = " + compGroupMembersGrpIdColAlias).
+		Where(nameCond).
+		Where(sq.Eq{compGroupNamespaceColAlias: namespace})
+}
+
+// Condition matching the partition names in col against parts.  A
+// top-level partition also matches its sub-partitions, e.g. p1 matches
+// p1.2, since its members include theirs.
+func partNameCond(col string, parts []string) sq.Sqlizer {
+	cond := sq.Or{sq.Eq{col: parts}}
+	for _, part := range parts {
+		if sm.IsTopPartition(part) {
+			cond = append(cond, sq.Like{col: part + ".%"})
+		}
+	}
+	if len(cond) == 1 {
+		return cond[0]
+	}
+	return cond
+}
+
+// Condition matching idCol against the components selected by group
+// expression e.  resolved must hold an entry for every group in e, see
+// resolvePgGroups.
+func groupExprCond(
+	idCol string,
+	e *sm.GroupExpr,
+	resolved map[string]*resolvedGroup,
+) (sq.Sqlizer, error) {
+	switch e.Op {
+	case sm.GroupExprGroup:
+		r, ok := resolved[e.Name]
+		if !ok {
+			return nil, ErrHMSDSNoGroup
+		}
+		return groupMemberCond(idCol, r)
+	case sm.GroupExprPartition:
+		mq := selectMemberIDs([]string{e.Name}, partNamespace)
+		return mq.Prefix(idCol + " IN (").Suffix(")"), nil
+	case sm.GroupExprNot:
+		c, err := groupExprCond(idCol, e.Left, resolved)
+		if err != nil {
+			return nil, err
+		}
+		return sq.Expr("NOT (?)", c), nil
+	case sm.GroupExprAnd, sm.GroupExprOr:
+		l, err := groupExprCond(idCol, e.Left, resolved)
+		if err != nil {
+			return nil, err
+		}
+		r, err := groupExprCond(idCol, e.Right, resolved)
+		if err != nil {
+			return nil, err
+		}
+		if e.Op == sm.GroupExprAnd {
+			return sq.And{l, r}, nil
+		}
+		return sq.Or{l, r}, nil
+	}
+	return nil, ErrHMSDSArgBadArg
+}
+
+// Select the ids of the components matching a dynamic group's query.  The
+// result is meant to be used as a sub-select.
+func selectGroupQueryIDs(gq *sm.GroupQuery) (sq.SelectBuilder, error) {
+	// Copy so that normalizing the filter doesn't touch the group.
+	f := &ComponentFilter{
+		Type:      append([]string(nil), gq.Type...),
+		State:     append([]string(nil), gq.State...),
+		Role:      append([]string(nil), gq.Role...),
+		SubRole:   append([]string(nil), gq.SubRole...),
+		Arch:      append([]string(nil), gq.Arch...),
+		Class:     append([]string(nil), gq.Class...),
+		Partition: append([]string(nil), gq.Partition...),
+		Labels:    append([]string(nil), gq.Labels...),
+	}
+	q, err := makeComponentQuery(compTableDynAlias, f, FLTR_ID_ONLY)
+	if err != nil {
+		return q, err
+	}
+	if len(gq.Prefix) > 0 {
+		// Same expansion as for hierarchy queries: the prefix itself and
+		// anything below it.
+		idCol := compTableDynAlias + "." + compIdCol
+		filterQuery := ""
+		args := make([]interface{}, 0, len(gq.Prefix))
+		for i, id := range gq.Prefix {
+			if i > 0 {
+				filterQuery += " OR "
+			}
+			filterQuery += "(" + idCol + " SIMILAR TO ?)"
+			args = append(args, xnametypes.NormalizeHMSCompID(id)+
+				"([[:alpha:]][[:alnum:]]*)?")
+		}
+		q = q.Where(sq.Expr(filterQuery, args...))
+	}
+	return q, nil
+}
+
 // Special handling for NIDStart, NIDEnd and NID because of the
 // interaction between them.  Adds to where clause of an existing query.
 func whereComponentNIDCol(q sq.SelectBuilder, alias string, f *ComponentFilter) sq.SelectBuilder {
@@ -1412,7 +1971,7 @@ func getHWInvByLocQuery(f_opts ...HWInvLocFiltFunc) (sq.SelectBuilder, error) {
 	// or one of their parent components are in the partition.
 	if len(f.Partition) > 0 {
 		partCol := hwInvAlias + "." + hwInvPartPartitionCol
-		query = query.Where(sq.Eq{partCol: f.Partition})
+		query = query.Where(partNameCond(partCol, f.Partition))
 	}
 	return query, nil
 }
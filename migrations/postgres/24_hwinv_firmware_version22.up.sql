@@ -0,0 +1,56 @@
+/*
+ * MIT License
+ *
+ * (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a
+ * copy of this software and associated documentation files (the "Software"),
+ * to deal in the Software without restriction, including without limitation
+ * the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ * and/or sell copies of the Software, and to permit persons to whom the
+ * Software is furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included
+ * in all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+ * THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+ * OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+ * ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+ * OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+-- Adds a table for firmware/software versions discovered via the Redfish
+-- UpdateService (FirmwareInventory and SoftwareInventory).
+
+BEGIN;
+
+create table if not exists hwinv_firmware (
+    "id"             VARCHAR(255) NOT NULL, -- Redfish Id of the target
+    "rf_endpoint_id" VARCHAR(63) NOT NULL,
+    "inventory"      VARCHAR(32) NOT NULL,  -- FirmwareInventory/SoftwareInventory
+    "related_id"     VARCHAR(63) NOT NULL,  -- xname the target applies to
+    "related_type"   VARCHAR(63) NOT NULL DEFAULT '',
+    "name"           VARCHAR(255) NOT NULL DEFAULT '',
+    "version"        VARCHAR(255) NOT NULL DEFAULT '',
+    "updateable"     BOOLEAN NOT NULL DEFAULT FALSE,
+    "software_id"    VARCHAR(255) NOT NULL DEFAULT '',
+    "manufacturer"   VARCHAR(255) NOT NULL DEFAULT '',
+    "release_date"   VARCHAR(63) NOT NULL DEFAULT '',
+    "odata_id"       VARCHAR(512) NOT NULL DEFAULT '',
+    "last_update"    TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (rf_endpoint_id, inventory, id),
+    FOREIGN KEY (rf_endpoint_id) REFERENCES rf_endpoints (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS hwinv_firmware_related_id_idx ON hwinv_firmware(related_id);
+
+CREATE INDEX IF NOT EXISTS hwinv_firmware_version_idx ON hwinv_firmware(version);
+
+-- Bump the schema version
+insert into system values(0, 22, '{}'::JSON)
+    on conflict(id) do update set schema_version=22;
+
+COMMIT;
@@ -0,0 +1,49 @@
+/*
+ * MIT License
+ *
+ * (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a
+ * copy of this software and associated documentation files (the "Software"),
+ * to deal in the Software without restriction, including without limitation
+ * the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ * and/or sell copies of the Software, and to permit persons to whom the
+ * Software is furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included
+ * in all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+ * THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+ * OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+ * ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+ * OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+-- Adds a table recording which components and subcomponents raised the
+-- Flag of a component above OK, based on their Redfish health.
+
+BEGIN;
+
+create table if not exists comp_health (
+    "comp_id"       VARCHAR(63) NOT NULL,   -- xname of the flagged component
+    "odata_id"      VARCHAR(512) NOT NULL,  -- Redfish URI of the source
+    "source_id"     VARCHAR(255) NOT NULL DEFAULT '', -- xname or Redfish Id
+    "source_type"   VARCHAR(63) NOT NULL DEFAULT '',
+    "health"        VARCHAR(32) NOT NULL DEFAULT '',
+    "health_rollup" VARCHAR(32) NOT NULL DEFAULT '',
+    "state"         VARCHAR(32) NOT NULL DEFAULT '',
+    "flag"          VARCHAR(32) NOT NULL,
+    "source"        VARCHAR(32) NOT NULL DEFAULT '',  -- Discovery or Event
+    "last_update"   TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (comp_id, odata_id),
+    FOREIGN KEY (comp_id) REFERENCES components (id) ON DELETE CASCADE
+);
+
+-- Bump the schema version
+insert into system values(0, 24, '{}'::JSON)
+    on conflict(id) do update set schema_version=24;
+
+COMMIT;
@@ -0,0 +1,55 @@
+/*
+ * MIT License
+ *
+ * (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a
+ * copy of this software and associated documentation files (the "Software"),
+ * to deal in the Software without restriction, including without limitation
+ * the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ * and/or sell copies of the Software, and to permit persons to whom the
+ * Software is furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included
+ * in all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+ * THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+ * OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+ * ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+ * OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+-- Adds a bounded log of the Redfish events received from BMCs.  Old
+-- entries are pruned by the service.
+
+BEGIN;
+
+create table if not exists rf_event_log (
+    "id"               BIGSERIAL PRIMARY KEY,
+    "timestamp"        TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "comp_id"          VARCHAR(63) NOT NULL DEFAULT '', -- xname it concerns
+    "rf_endpoint_id"   VARCHAR(63) NOT NULL,            -- BMC that sent it
+    "event_timestamp"  VARCHAR(64) NOT NULL DEFAULT '', -- As reported by BMC
+    "event_id"         VARCHAR(255) NOT NULL DEFAULT '',
+    "registry"         VARCHAR(255) NOT NULL DEFAULT '',
+    "registry_version" VARCHAR(32) NOT NULL DEFAULT '',
+    "message_id"       VARCHAR(255) NOT NULL,
+    "severity"         VARCHAR(32) NOT NULL DEFAULT '',
+    "message"          TEXT NOT NULL DEFAULT '',
+    "message_args"     JSON NOT NULL DEFAULT '[]'::JSON,
+    "origin"           VARCHAR(512) NOT NULL DEFAULT '',
+    "fru_id"           VARCHAR(255) NOT NULL DEFAULT '' -- FRU messages only
+);
+
+create index if not exists rf_event_log_comp_id_idx on rf_event_log (comp_id, id);
+create index if not exists rf_event_log_rf_ep_idx on rf_event_log (rf_endpoint_id, id);
+create index if not exists rf_event_log_timestamp_idx on rf_event_log (timestamp);
+
+-- Bump the schema version
+insert into system values(0, 26, '{}'::JSON)
+    on conflict(id) do update set schema_version=26;
+
+COMMIT;
@@ -0,0 +1,65 @@
+/*
+ * MIT License
+ *
+ * (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a
+ * copy of this software and associated documentation files (the "Software"),
+ * to deal in the Software without restriction, including without limitation
+ * the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ * and/or sell copies of the Software, and to permit persons to whom the
+ * Software is furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included
+ * in all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+ * THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+ * OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+ * ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+ * OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+-- Adds triggers that notify listeners on the hsm_changes channel when
+-- tables that HSM caches in memory change, so every instance of HSM can
+-- refresh its copy immediately.  The payload is the table name.  They are
+-- per-statement, so a bulk change sends one notification, and Postgres
+-- folds identical notifications in the same transaction into one.
+
+BEGIN;
+
+CREATE OR REPLACE FUNCTION hsm_notify_change()
+RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM pg_notify('hsm_changes', TG_TABLE_NAME);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS scn_subscriptions_notify ON scn_subscriptions;
+CREATE TRIGGER scn_subscriptions_notify
+    AFTER INSERT OR UPDATE OR DELETE OR TRUNCATE ON scn_subscriptions
+    FOR EACH STATEMENT EXECUTE PROCEDURE hsm_notify_change();
+
+DROP TRIGGER IF EXISTS comp_endpoints_notify ON comp_endpoints;
+CREATE TRIGGER comp_endpoints_notify
+    AFTER INSERT OR UPDATE OR DELETE OR TRUNCATE ON comp_endpoints
+    FOR EACH STATEMENT EXECUTE PROCEDURE hsm_notify_change();
+
+DROP TRIGGER IF EXISTS rf_endpoints_notify ON rf_endpoints;
+CREATE TRIGGER rf_endpoints_notify
+    AFTER INSERT OR UPDATE OR DELETE OR TRUNCATE ON rf_endpoints
+    FOR EACH STATEMENT EXECUTE PROCEDURE hsm_notify_change();
+
+DROP TRIGGER IF EXISTS event_rules_notify ON event_rules;
+CREATE TRIGGER event_rules_notify
+    AFTER INSERT OR UPDATE OR DELETE OR TRUNCATE ON event_rules
+    FOR EACH STATEMENT EXECUTE PROCEDURE hsm_notify_change();
+
+-- Bump the schema version
+insert into system values(0, 28, '{}'::JSON)
+    on conflict(id) do update set schema_version=28;
+
+COMMIT;
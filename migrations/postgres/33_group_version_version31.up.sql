@@ -0,0 +1,92 @@
+/*
+ * MIT License
+ *
+ * (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a
+ * copy of this software and associated documentation files (the "Software"),
+ * to deal in the Software without restriction, including without limitation
+ * the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ * and/or sell copies of the Software, and to permit persons to whom the
+ * Software is furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included
+ * in all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+ * THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+ * OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+ * ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+ * OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+-- Gives each group and partition a version that is bumped whenever it, its
+-- members or the groups it includes change, so clients can make updates
+-- conditional on not having missed someone else's.  The member and include
+-- triggers are per-statement, so replacing a whole member list in one
+-- statement bumps each group once.
+
+BEGIN;
+
+ALTER TABLE component_groups
+    ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
+
+CREATE OR REPLACE FUNCTION hsm_group_version_bump()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.version = OLD.version THEN
+        NEW.version := OLD.version + 1;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION hsm_group_version_changed()
+RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE component_groups SET version = version + 1
+        WHERE id IN (SELECT DISTINCT group_id FROM changed);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS component_groups_version ON component_groups;
+CREATE TRIGGER component_groups_version
+    BEFORE UPDATE ON component_groups
+    FOR EACH ROW EXECUTE PROCEDURE hsm_group_version_bump();
+
+DROP TRIGGER IF EXISTS component_group_members_insert_version
+    ON component_group_members;
+CREATE TRIGGER component_group_members_insert_version
+    AFTER INSERT ON component_group_members
+    REFERENCING NEW TABLE AS changed
+    FOR EACH STATEMENT EXECUTE PROCEDURE hsm_group_version_changed();
+
+DROP TRIGGER IF EXISTS component_group_members_delete_version
+    ON component_group_members;
+CREATE TRIGGER component_group_members_delete_version
+    AFTER DELETE ON component_group_members
+    REFERENCING OLD TABLE AS changed
+    FOR EACH STATEMENT EXECUTE PROCEDURE hsm_group_version_changed();
+
+DROP TRIGGER IF EXISTS component_group_includes_insert_version
+    ON component_group_includes;
+CREATE TRIGGER component_group_includes_insert_version
+    AFTER INSERT ON component_group_includes
+    REFERENCING NEW TABLE AS changed
+    FOR EACH STATEMENT EXECUTE PROCEDURE hsm_group_version_changed();
+
+DROP TRIGGER IF EXISTS component_group_includes_delete_version
+    ON component_group_includes;
+CREATE TRIGGER component_group_includes_delete_version
+    AFTER DELETE ON component_group_includes
+    REFERENCING OLD TABLE AS changed
+    FOR EACH STATEMENT EXECUTE PROCEDURE hsm_group_version_changed();
+
+-- Bump the schema version
+INSERT INTO system VALUES(0, 31, '{}'::JSON)
+    ON CONFLICT(id) DO UPDATE SET schema_version=31;
+
+COMMIT;
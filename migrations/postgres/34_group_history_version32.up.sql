@@ -0,0 +1,181 @@
+/*
+ * MIT License
+ *
+ * (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a
+ * copy of this software and associated documentation files (the "Software"),
+ * to deal in the Software without restriction, including without limitation
+ * the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ * and/or sell copies of the Software, and to permit persons to whom the
+ * Software is furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included
+ * in all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+ * THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+ * OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+ * ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+ * OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+-- Keeps an append-only history of changes to groups and partitions.  Entries
+-- are written by triggers so that every path that changes a group, including
+-- members dropped when their component is deleted, is recorded.  The
+-- service names the requester for the current transaction with the
+-- hsm.requester setting.
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS component_group_history (
+    "id"        BIGSERIAL PRIMARY KEY,
+    "timestamp" TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "namespace" group_namespace NOT NULL,
+    "name"      VARCHAR(255) NOT NULL,
+    "op"        VARCHAR(32) NOT NULL,
+    "member"    VARCHAR(255) NOT NULL DEFAULT '',
+    "requester" VARCHAR(255) NOT NULL DEFAULT '',
+    "data"      JSON
+);
+
+CREATE INDEX IF NOT EXISTS component_group_history_name_idx
+    ON component_group_history ("namespace", "name", "id");
+CREATE INDEX IF NOT EXISTS component_group_history_timestamp_idx
+    ON component_group_history ("timestamp");
+
+CREATE OR REPLACE FUNCTION hsm_group_history_requester()
+RETURNS VARCHAR AS $$
+BEGIN
+    RETURN COALESCE(current_setting('hsm.requester', true), '');
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION hsm_group_history_groups()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        INSERT INTO component_group_history
+            (namespace, name, op, requester, data)
+        VALUES (NEW.namespace, NEW.name, 'Create',
+            hsm_group_history_requester(),
+            json_build_object(
+                'description', NEW.description,
+                'tags', NEW.tags,
+                'exclusiveGroup', NEW.exclusive_group_identifier,
+                'query', NEW.query));
+    ELSIF TG_OP = 'UPDATE' THEN
+        -- Version bumps alone are not worth recording.
+        IF NEW.description IS DISTINCT FROM OLD.description OR
+           NEW.tags IS DISTINCT FROM OLD.tags OR
+           NEW.query::TEXT IS DISTINCT FROM OLD.query::TEXT THEN
+            INSERT INTO component_group_history
+                (namespace, name, op, requester, data)
+            VALUES (NEW.namespace, NEW.name, 'Update',
+                hsm_group_history_requester(),
+                json_build_object(
+                    'description', NEW.description,
+                    'tags', NEW.tags,
+                    'query', NEW.query));
+        END IF;
+    ELSE
+        INSERT INTO component_group_history
+            (namespace, name, op, requester)
+        VALUES (OLD.namespace, OLD.name, 'Delete',
+            hsm_group_history_requester());
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Members removed because their group was deleted are not recorded
+-- separately; the group is already gone when the cascade reaches them.
+CREATE OR REPLACE FUNCTION hsm_group_history_members()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        INSERT INTO component_group_history
+            (namespace, name, op, member, requester)
+        SELECT g.namespace, g.name, 'AddMember', NEW.component_id,
+            hsm_group_history_requester()
+        FROM component_groups g WHERE g.id = NEW.group_id;
+    ELSE
+        INSERT INTO component_group_history
+            (namespace, name, op, member, requester)
+        SELECT g.namespace, g.name, 'RemoveMember', OLD.component_id,
+            hsm_group_history_requester()
+        FROM component_groups g WHERE g.id = OLD.group_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION hsm_group_history_includes()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        INSERT INTO component_group_history
+            (namespace, name, op, member, requester)
+        SELECT g.namespace, g.name, 'AddGroup', i.name,
+            hsm_group_history_requester()
+        FROM component_groups g, component_groups i
+        WHERE g.id = NEW.group_id AND i.id = NEW.included_id;
+    ELSE
+        INSERT INTO component_group_history
+            (namespace, name, op, member, requester)
+        SELECT g.namespace, g.name, 'RemoveGroup', i.name,
+            hsm_group_history_requester()
+        FROM component_groups g, component_groups i
+        WHERE g.id = OLD.group_id AND i.id = OLD.included_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS component_groups_history ON component_groups;
+CREATE TRIGGER component_groups_history
+    AFTER INSERT OR UPDATE OR DELETE ON component_groups
+    FOR EACH ROW EXECUTE PROCEDURE hsm_group_history_groups();
+
+DROP TRIGGER IF EXISTS component_group_members_history
+    ON component_group_members;
+CREATE TRIGGER component_group_members_history
+    AFTER INSERT OR DELETE ON component_group_members
+    FOR EACH ROW EXECUTE PROCEDURE hsm_group_history_members();
+
+DROP TRIGGER IF EXISTS component_group_includes_history
+    ON component_group_includes;
+CREATE TRIGGER component_group_includes_history
+    AFTER INSERT OR DELETE ON component_group_includes
+    FOR EACH ROW EXECUTE PROCEDURE hsm_group_history_includes();
+
+-- Start the history with the groups and partitions that already exist.
+INSERT INTO component_group_history (namespace, name, op, requester, data)
+SELECT namespace, name, 'Create', 'migration',
+    json_build_object(
+        'description', description,
+        'tags', tags,
+        'exclusiveGroup', exclusive_group_identifier,
+        'query', query)
+FROM component_groups ORDER BY name;
+
+INSERT INTO component_group_history (namespace, name, op, member, requester)
+SELECT g.namespace, g.name, 'AddMember', m.component_id, 'migration'
+FROM component_group_members m
+JOIN component_groups g ON g.id = m.group_id
+ORDER BY g.name, m.component_id;
+
+INSERT INTO component_group_history (namespace, name, op, member, requester)
+SELECT g.namespace, g.name, 'AddGroup', i.name, 'migration'
+FROM component_group_includes gi
+JOIN component_groups g ON g.id = gi.group_id
+JOIN component_groups i ON i.id = gi.included_id
+ORDER BY g.name, i.name;
+
+-- Bump the schema version
+INSERT INTO system VALUES(0, 32, '{}'::JSON)
+    ON CONFLICT(id) DO UPDATE SET schema_version=32;
+
+COMMIT;
@@ -0,0 +1,93 @@
+/*
+ * MIT License
+ *
+ * (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a
+ * copy of this software and associated documentation files (the "Software"),
+ * to deal in the Software without restriction, including without limitation
+ * the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ * and/or sell copies of the Software, and to permit persons to whom the
+ * Software is furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included
+ * in all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+ * THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+ * OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+ * ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+ * OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+-- Keeps a history of changes to component state, flag, enabled, role,
+-- subrole and software status (the admin column).  Entries are written by
+-- a trigger so that every path that changes a component, including
+-- discovery, is recorded.  The service names the source of the changes
+-- for the current transaction with the hsm.requester setting.  There is
+-- no foreign key to components so the history outlives the component.
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS component_history (
+    "id"           BIGSERIAL PRIMARY KEY,
+    "timestamp"    TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "component_id" VARCHAR(63) NOT NULL,
+    "field"        VARCHAR(32) NOT NULL,
+    "old_value"    VARCHAR(255) NOT NULL DEFAULT '',
+    "new_value"    VARCHAR(255) NOT NULL DEFAULT '',
+    "source"       VARCHAR(255) NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS component_history_component_id_idx
+    ON component_history ("component_id", "id");
+CREATE INDEX IF NOT EXISTS component_history_timestamp_idx
+    ON component_history ("timestamp");
+
+CREATE OR REPLACE FUNCTION hsm_component_history()
+RETURNS TRIGGER AS $$
+DECLARE
+    req VARCHAR := hsm_group_history_requester();
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        INSERT INTO component_history
+            (component_id, field, new_value, source)
+        SELECT NEW.id, f.field, f.val, req
+        FROM (VALUES
+            ('State', NEW.state),
+            ('Flag', NEW.flag),
+            ('Enabled', NEW.enabled::TEXT),
+            ('Role', NEW.role),
+            ('SubRole', NEW.subrole),
+            ('SoftwareStatus', NEW.admin)) AS f(field, val)
+        WHERE f.val <> '';
+    ELSE
+        INSERT INTO component_history
+            (component_id, field, old_value, new_value, source)
+        SELECT NEW.id, f.field, f.old_val, f.new_val, req
+        FROM (VALUES
+            ('State', OLD.state, NEW.state),
+            ('Flag', OLD.flag, NEW.flag),
+            ('Enabled', OLD.enabled::TEXT, NEW.enabled::TEXT),
+            ('Role', OLD.role, NEW.role),
+            ('SubRole', OLD.subrole, NEW.subrole),
+            ('SoftwareStatus', OLD.admin, NEW.admin)) AS f(field, old_val, new_val)
+        WHERE f.old_val IS DISTINCT FROM f.new_val;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS components_history ON components;
+CREATE TRIGGER components_history
+    AFTER INSERT OR UPDATE OF state, flag, enabled, role, subrole, admin
+    ON components
+    FOR EACH ROW EXECUTE PROCEDURE hsm_component_history();
+
+-- Bump the schema version
+INSERT INTO system VALUES(0, 34, '{}'::JSON)
+    ON CONFLICT(id) DO UPDATE SET schema_version=34;
+
+COMMIT;
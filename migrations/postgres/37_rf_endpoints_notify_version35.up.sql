@@ -0,0 +1,90 @@
+/*
+ * MIT License
+ *
+ * (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a
+ * copy of this software and associated documentation files (the "Software"),
+ * to deal in the Software without restriction, including without limitation
+ * the rights to use, copy, modify, merge, publish, distribute, sublicense,
+ * and/or sell copies of the Software, and to permit persons to whom the
+ * Software is furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included
+ * in all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+ * THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+ * OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+ * ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+ * OTHER DEALINGS IN THE SOFTWARE.
+ */
+
+-- Only notify about rf_endpoints and comp_endpoints changes that matter to
+-- the cached ComponentEndpoint lookups, i.e. to the columns in the
+-- comp_endpoints_info view.  Discovery rewrites every row it touches in
+-- both tables, so notifying on every UPDATE threw the cache away all the
+-- time.  Every UPDATE sets every column, so UPDATE OF can't tell these
+-- apart; compare the old and new values instead.  json has no equality
+-- operator, so component_info is compared as text.  Discovery writes
+-- both with INSERT ... ON CONFLICT DO UPDATE, which fires statement-level
+-- INSERT triggers even when every row already exists, so INSERT is
+-- notified per row too.  Postgres folds the identical notifications from
+-- a bulk insert into one.
+
+BEGIN;
+
+DROP TRIGGER IF EXISTS rf_endpoints_notify ON rf_endpoints;
+CREATE TRIGGER rf_endpoints_notify
+    AFTER DELETE OR TRUNCATE ON rf_endpoints
+    FOR EACH STATEMENT EXECUTE PROCEDURE hsm_notify_change();
+
+DROP TRIGGER IF EXISTS rf_endpoints_notify_insert ON rf_endpoints;
+CREATE TRIGGER rf_endpoints_notify_insert
+    AFTER INSERT ON rf_endpoints
+    FOR EACH ROW EXECUTE PROCEDURE hsm_notify_change();
+
+DROP TRIGGER IF EXISTS rf_endpoints_notify_update ON rf_endpoints;
+CREATE TRIGGER rf_endpoints_notify_update
+    AFTER UPDATE ON rf_endpoints
+    FOR EACH ROW
+    WHEN (OLD.fqdn IS DISTINCT FROM NEW.fqdn OR
+          OLD."user" IS DISTINCT FROM NEW."user" OR
+          OLD.password IS DISTINCT FROM NEW.password OR
+          OLD.enabled IS DISTINCT FROM NEW.enabled)
+    EXECUTE PROCEDURE hsm_notify_change();
+
+DROP TRIGGER IF EXISTS comp_endpoints_notify ON comp_endpoints;
+CREATE TRIGGER comp_endpoints_notify
+    AFTER DELETE OR TRUNCATE ON comp_endpoints
+    FOR EACH STATEMENT EXECUTE PROCEDURE hsm_notify_change();
+
+DROP TRIGGER IF EXISTS comp_endpoints_notify_insert ON comp_endpoints;
+CREATE TRIGGER comp_endpoints_notify_insert
+    AFTER INSERT ON comp_endpoints
+    FOR EACH ROW EXECUTE PROCEDURE hsm_notify_change();
+
+DROP TRIGGER IF EXISTS comp_endpoints_notify_update ON comp_endpoints;
+CREATE TRIGGER comp_endpoints_notify_update
+    AFTER UPDATE ON comp_endpoints
+    FOR EACH ROW
+    WHEN (OLD.id IS DISTINCT FROM NEW.id OR
+          OLD.type IS DISTINCT FROM NEW.type OR
+          OLD.domain IS DISTINCT FROM NEW.domain OR
+          OLD.redfish_type IS DISTINCT FROM NEW.redfish_type OR
+          OLD.redfish_subtype IS DISTINCT FROM NEW.redfish_subtype OR
+          OLD.rf_endpoint_id IS DISTINCT FROM NEW.rf_endpoint_id OR
+          OLD.mac IS DISTINCT FROM NEW.mac OR
+          OLD.uuid IS DISTINCT FROM NEW.uuid OR
+          OLD.odata_id IS DISTINCT FROM NEW.odata_id OR
+          OLD.component_info::text IS DISTINCT FROM
+              NEW.component_info::text)
+    EXECUTE PROCEDURE hsm_notify_change();
+
+-- Bump the schema version
+insert into system values(0, 35, '{}'::JSON)
+    on conflict(id) do update set schema_version=35;
+
+COMMIT;
@@ -0,0 +1,134 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package rf
+
+// Redfish PCIeDevice collection, e.g. /redfish/v1/Chassis/<id>/PCIeDevices
+type PCIeDeviceCollection GenericCollection
+
+// Redfish PCIeFunction collection, e.g.
+// /redfish/v1/Chassis/<id>/PCIeDevices/<id>/PCIeFunctions
+type PCIeFunctionCollection GenericCollection
+
+// Redfish pass-through from Redfish "PCIeDevice"
+// This is the set of Redfish fields for this object that HMS understands
+// and/or finds useful.  Those assigned to either the *LocationInfo
+// or *FRUInfo subfields constitute the type specific fields in the
+// HWInventory objects that are returned in response to queries.
+type PCIeDevice struct {
+	OContext string `json:"@odata.context"`
+	Oid      string `json:"@odata.id"`
+	Otype    string `json:"@odata.type"`
+
+	PCIeDeviceLocationInfoRF
+	PCIeDeviceFRUInfoRF
+
+	FirmwareVersion string           `json:"FirmwareVersion,omitempty"`
+	PCIeInterface   *PCIeInterfaceRF `json:"PCIeInterface,omitempty"`
+	PCIeFunctions   ResourceID       `json:"PCIeFunctions"`
+	Links           PCIeDeviceLinks  `json:"Links"`
+	Status          *StatusRF        `json:"Status,omitempty"`
+}
+
+// Location-specific Redfish properties to be stored in hardware inventory
+// These are only relevant to the currently installed location of the FRU
+type PCIeDeviceLocationInfoRF struct {
+	Id          string `json:"Id"`
+	Name        string `json:"Name"`
+	Description string `json:"Description"`
+
+	// Summary of the functions the device exposes, filled in by HMS from
+	// the PCIeFunction resources.  Not part of the Redfish PCIeDevice.
+	Functions []PCIeFunctionInfo `json:"Functions,omitempty"`
+}
+
+// Durable Redfish properties to be stored in hardware inventory as
+// a specific FRU, which is then link with it's current location
+// i.e. an x-name.  These properties should follow the hardware and
+// allow it to be tracked even when it is removed from the system.
+type PCIeDeviceFRUInfoRF struct {
+	Manufacturer string `json:"Manufacturer"`
+	Model        string `json:"Model"`
+	PartNumber   string `json:"PartNumber"`
+	SKU          string `json:"SKU,omitempty"`
+	SerialNumber string `json:"SerialNumber"`
+	DeviceType   string `json:"DeviceType,omitempty"`
+
+	// The PCI IDs of the device's first function, filled in by HMS.
+	DeviceClass       string `json:"DeviceClass,omitempty"`
+	VendorId          string `json:"VendorId,omitempty"`
+	DeviceId          string `json:"DeviceId,omitempty"`
+	SubsystemVendorId string `json:"SubsystemVendorId,omitempty"`
+	SubsystemId       string `json:"SubsystemId,omitempty"`
+}
+
+// Redfish PCIeDevice sub-struct - PCIeInterface
+type PCIeInterfaceRF struct {
+	PCIeType    string `json:"PCIeType,omitempty"`
+	MaxPCIeType string `json:"MaxPCIeType,omitempty"`
+	LanesInUse  int    `json:"LanesInUse,omitempty"`
+	MaxLanes    int    `json:"MaxLanes,omitempty"`
+}
+
+// Redfish PCIeDevice sub-struct - Links
+type PCIeDeviceLinks struct {
+	Chassis       []ResourceID `json:"Chassis"`
+	PCIeFunctions []ResourceID `json:"PCIeFunctions"`
+}
+
+// Redfish pass-through from Redfish "PCIeFunction"
+type PCIeFunction struct {
+	OContext string `json:"@odata.context"`
+	Oid      string `json:"@odata.id"`
+	Otype    string `json:"@odata.type"`
+
+	Id                string    `json:"Id"`
+	Name              string    `json:"Name"`
+	FunctionId        int       `json:"FunctionId"`
+	FunctionType      string    `json:"FunctionType,omitempty"`
+	DeviceClass       string    `json:"DeviceClass,omitempty"`
+	ClassCode         string    `json:"ClassCode,omitempty"`
+	VendorId          string    `json:"VendorId,omitempty"`
+	DeviceId          string    `json:"DeviceId,omitempty"`
+	SubsystemVendorId string    `json:"SubsystemVendorId,omitempty"`
+	SubsystemId       string    `json:"SubsystemId,omitempty"`
+	RevisionId        string    `json:"RevisionId,omitempty"`
+	Status            *StatusRF `json:"Status,omitempty"`
+}
+
+// Summary of a PCIeFunction kept in the PCIeDevice location info.
+type PCIeFunctionInfo struct {
+	FunctionId   int    `json:"FunctionId"`
+	FunctionType string `json:"FunctionType,omitempty"`
+	DeviceClass  string `json:"DeviceClass,omitempty"`
+	ClassCode    string `json:"ClassCode,omitempty"`
+}
+
+// Valid values for PCIeFunction DeviceClass that we map to HMS types.
+// Others are valid Redfish values but are skipped.
+const (
+	PCIeClassNetworkController     = "NetworkController"
+	PCIeClassMassStorageController = "MassStorageController"
+	PCIeClassProcessingAccelerator = "ProcessingAccelerators"
+	PCIeClassCoprocessor           = "Coprocessor"
+	PCIeClassDisplayController     = "DisplayController"
+)
@@ -0,0 +1,83 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package rf
+
+import (
+	"encoding/json"
+)
+
+// Sensor readings are not part of discovery and are not stored.  These
+// structs are used to take an on-demand snapshot of the sensors under a
+// Chassis, e.g. /redfish/v1/Chassis/<id>/Sensors (see Sensor in
+// redfish-power.go) and /redfish/v1/Chassis/<id>/EnvironmentMetrics.
+
+// Redfish pass-through from Redfish "EnvironmentMetrics"
+//
+// From DMTF: "This resource shall represent the environmental metrics for
+// a Redfish implementation."
+//
+//	Example: /redfish/v1/Chassis/Enclosure/EnvironmentMetrics
+type EnvironmentMetrics struct {
+	OContext string `json:"@odata.context"`
+	Oid      string `json:"@odata.id"`
+	Otype    string `json:"@odata.type"`
+
+	Id          string `json:"Id"`
+	Description string `json:"Description"`
+	Name        string `json:"Name"`
+
+	TemperatureCelsius *SensorExcerpt           `json:"TemperatureCelsius,omitempty"`
+	DewPointCelsius    *SensorExcerpt           `json:"DewPointCelsius,omitempty"`
+	HumidityPercent    *SensorExcerpt           `json:"HumidityPercent,omitempty"`
+	PowerWatts         *SensorPowerExcerpt      `json:"PowerWatts,omitempty"`
+	EnergykWh          *SensorExcerpt           `json:"EnergykWh,omitempty"`
+	FanSpeedsPercent   []*SensorFanArrayExcerpt `json:"FanSpeedsPercent,omitempty"`
+
+	OEM *json.RawMessage `json:"Oem,omitempty"`
+}
+
+// EnvironmentMetrics sub-struct - SensorFanArrayExcerpt
+type SensorFanArrayExcerpt struct {
+	SensorExcerpt
+	DeviceName string      `json:"DeviceName,omitempty"`
+	SpeedRPM   json.Number `json:"SpeedRPM,omitempty"`
+}
+
+// The links to the sensor resources of a Chassis or PowerDistribution, and
+// the Chassis links of a ComputerSystem or Manager, whose sensors are found
+// under the Chassis it is in.  Used to find the sensors for any of these
+// from its @odata.id alone.
+type SensorLinksRF struct {
+	Oid string `json:"@odata.id"`
+
+	Sensors            ResourceID `json:"Sensors"`
+	EnvironmentMetrics ResourceID `json:"EnvironmentMetrics"`
+
+	Links SensorLinksLinksRF `json:"Links"`
+}
+
+// SensorLinksRF sub-struct - Links
+type SensorLinksLinksRF struct {
+	Chassis          []ResourceID `json:"Chassis"`          // ComputerSystem
+	ManagerInChassis ResourceID   `json:"ManagerInChassis"` // Manager
+}
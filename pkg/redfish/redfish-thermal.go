@@ -0,0 +1,110 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package rf
+
+// Redfish Fan collection, e.g. /redfish/v1/Chassis/<id>/ThermalSubsystem/Fans
+type FanCollection GenericCollection
+
+// Redfish pass-through from the (deprecated) Redfish "Thermal" object,
+// e.g. /redfish/v1/Chassis/<id>/Thermal.  Fans are embedded in the
+// object itself rather than being separate resources.
+type Thermal struct {
+	OContext string `json:"@odata.context"`
+	Oid      string `json:"@odata.id"`
+	Otype    string `json:"@odata.type"`
+
+	Id   string `json:"Id"`
+	Name string `json:"Name"`
+
+	Fans       []*Fan `json:"Fans"`
+	FansOCount int    `json:"Fans@odata.count"`
+
+	Status StatusRF `json:"Status"`
+}
+
+// Redfish pass-through from Redfish "ThermalSubsystem", e.g.
+// /redfish/v1/Chassis/<id>/ThermalSubsystem.  This replaces Thermal
+// in newer schemas and links to a collection of Fan resources.
+type ThermalSubsystem struct {
+	OContext string `json:"@odata.context"`
+	Oid      string `json:"@odata.id"`
+	Otype    string `json:"@odata.type"`
+
+	Id   string `json:"Id"`
+	Name string `json:"Name"`
+
+	Fans ResourceID `json:"Fans"`
+
+	Status StatusRF `json:"Status"`
+}
+
+// Redfish pass-through from Redfish "Fan".  This covers both the entries
+// in the Thermal Fans array and the standalone ThermalSubsystem Fan
+// resource, which share most of their fields.  Those assigned to either
+// the *LocationInfo or *FRUInfo subfields constitute the type specific
+// fields in the hardware inventory.
+type Fan struct {
+	Oid string `json:"@odata.id"`
+
+	FanLocationInfoRF
+	FanFRUInfoRF
+
+	HotPluggable *bool    `json:"HotPluggable,omitempty"`
+	Status       StatusRF `json:"Status"`
+
+	// Thermal (legacy) only.  Reading may be an int or a float.
+	Reading      interface{} `json:"Reading,omitempty"`
+	ReadingUnits string      `json:"ReadingUnits,omitempty"`
+
+	// ThermalSubsystem only.
+	SpeedPercent *FanSpeedRF `json:"SpeedPercent,omitempty"`
+}
+
+// Location-specific Redfish properties to be stored in hardware inventory
+// These are only relevant to the currently installed location of the FRU
+type FanLocationInfoRF struct {
+	Id              string    `json:"Id,omitempty"`       // ThermalSubsystem
+	MemberId        string    `json:"MemberId,omitempty"` // Thermal
+	Name            string    `json:"Name"`
+	PhysicalContext string    `json:"PhysicalContext,omitempty"`
+	Location        *Location `json:"Location,omitempty"`
+}
+
+// Durable Redfish properties to be stored in hardware inventory as
+// a specific FRU, which is then link with it's current location
+// i.e. an x-name.  These properties should follow the hardware and
+// allow it to be tracked even when it is removed from the system.
+type FanFRUInfoRF struct {
+	Manufacturer    string `json:"Manufacturer"`
+	Model           string `json:"Model"`
+	PartNumber      string `json:"PartNumber"`
+	SparePartNumber string `json:"SparePartNumber,omitempty"`
+	SerialNumber    string `json:"SerialNumber"`
+}
+
+// Redfish ThermalSubsystem Fan sub-struct - SpeedPercent
+type FanSpeedRF struct {
+	DataSourceUri string      `json:"DataSourceUri,omitempty"`
+	Reading       interface{} `json:"Reading,omitempty"`
+	SpeedRPM      interface{} `json:"SpeedRPM,omitempty"`
+}
@@ -132,6 +132,11 @@ type SerialInterfaceCollection GenericCollection
 // Example: /redfish/v1/Chassis/<chassis_id>/NetworkAdapters
 type NetworkAdapterCollection GenericCollection
 
+// JSON decoded collection struct returned from Redfish "FirmwareInventory"
+// or "SoftwareInventory"
+// Example: /redfish/v1/UpdateService/FirmwareInventory
+type SoftwareInventoryCollection GenericCollection
+
 // JSON decoded collection struct returned from Redfish "Controls"
 // Example: /redfish/v1/Chassis/<chassis_id>/Controls
 type ControlCollection GenericCollection
@@ -286,11 +291,17 @@ type Chassis struct {
 	Status     StatusRF `json:"Status"`
 
 	NetworkAdapters ResourceID `json:"NetworkAdapters"`
+	PCIeDevices     ResourceID `json:"PCIeDevices"`
 	Power           ResourceID `json:"Power"`
 	Assembly        ResourceID `json:"Assembly"`
 	Thermal         ResourceID `json:"Thermal"`
 	Controls        ResourceID `json:"Controls"`
 
+	ThermalSubsystem ResourceID `json:"ThermalSubsystem"`
+
+	Sensors            ResourceID `json:"Sensors"`
+	EnvironmentMetrics ResourceID `json:"EnvironmentMetrics"`
+
 	Links ChassisLinks `json:"Links"`
 
 	OEM *ChassisOEM `json:"Oem,omitempty"`
@@ -434,6 +445,10 @@ type ComputerSystem struct {
 	SimpleStorage      ResourceID `json:"SimpleStorage"`
 	Storage            ResourceID `json:"Storage"`
 
+	// Deprecated in favor of the Chassis PCIeDevices collection, but
+	// still the only place some implementations list them.
+	PCIeDevices []ResourceID `json:"PCIeDevices,omitempty"`
+
 	Links ComputerSystemLinks `json:"Links"`
 
 	OEM	*ComputerSystemOEM `json:"Oem,omitempty"`
@@ -951,6 +966,27 @@ type UpdateService struct {
 	HttpPushUriTargetsBusy *bool               `json:"HttpPushUriTargetsBusy,omitempty"`
 }
 
+// Redfish SoftwareInventory.  These are the members of the FirmwareInventory
+// and SoftwareInventory collections linked from the UpdateService.  Each one
+// describes a single updateable (or not) target and its current version.
+type SoftwareInventory struct {
+	OContext               string        `json:"@odata.context"`
+	Oetag                  string        `json:"@odata.etag,omitempty"`
+	Oid                    string        `json:"@odata.id"`
+	Otype                  string        `json:"@odata.type"`
+	Id                     string        `json:"Id"`
+	Name                   string        `json:"Name"`
+	Description            string        `json:"Description,omitempty"`
+	Version                string        `json:"Version"`
+	Updateable             *bool         `json:"Updateable,omitempty"`
+	SoftwareId             string        `json:"SoftwareId,omitempty"`
+	Manufacturer           string        `json:"Manufacturer,omitempty"`
+	ReleaseDate            string        `json:"ReleaseDate,omitempty"`
+	LowestSupportedVersion string        `json:"LowestSupportedVersion,omitempty"`
+	Status                 *StatusRF     `json:"Status,omitempty"`
+	RelatedItem            []*ResourceID `json:"RelatedItem,omitempty"`
+}
+
 // UpdateService - Actions defined by Redfish for this type
 type UpdateServiceActions struct {
 	SimpleUpdate *ActionSimpleUpdate `json:"#UpdateService.SimpleUpdate,omitempty"`
@@ -263,7 +263,7 @@ func (r *EpNodeAccelRiser) discoverLocalPhase2() {
 	if r.NodeAccelRiserRF.Status.State != "Absent" {
 		r.Status = "Populated"
 		r.State = base.StatePopulated.String()
-		r.Flag = base.FlagOK.String()
+		r.Flag = HealthFlag(r.NodeAccelRiserRF.Status.Health)
 		generatedFRUID, err := GetNodeAccelRiserFRUID(r)
 		if err != nil {
 			errlog.Printf("FRUID Error: %s\n", err.Error())
@@ -215,7 +215,7 @@ func (d *EpHpeDevice) discoverLocalPhase2() {
 	if d.DeviceRF.Status.State != "Absent" {
 		d.Status = "Populated"
 		d.State = base.StatePopulated.String()
-		d.Flag = base.FlagOK.String()
+		d.Flag = HealthFlag(d.DeviceRF.Status.Health)
 		generatedFRUID, err := GetHpeDeviceFRUID(d)
 		if err != nil {
 			errlog.Printf("FRUID Error: %s\n", err.Error())
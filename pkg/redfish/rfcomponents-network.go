@@ -180,6 +180,9 @@ func (na *EpNetworkAdapter) discoverLocalPhase2() {
 	na.Status = "Populated"
 	na.State = base.StatePopulated.String()
 	na.Flag = base.FlagOK.String()
+	if na.NetworkAdapterRF.Status != nil {
+		na.Flag = HealthFlag(na.NetworkAdapterRF.Status.Health)
+	}
 	generatedFRUID, err := GetNetworkAdapterFRUID(na)
 	if err != nil {
 		errlog.Printf("FRUID Error: %s\n", err.Error())
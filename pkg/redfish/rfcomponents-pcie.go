@@ -0,0 +1,416 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package rf
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	base "github.com/Cray-HPE/hms-base/v2"
+	"github.com/Cray-HPE/hms-xname/xnametypes"
+)
+
+/////////////////////////////////////////////////////////////////////////////
+// Chassis/System - PCIeDevices
+/////////////////////////////////////////////////////////////////////////////
+
+// Set of EpPCIeDevices, each representing a Redfish PCIeDevice listed under
+// a ComputerSystem or its Chassis.
+type EpPCIeDevices struct {
+	Num  int                      `json:"num"`
+	OIDs map[string]*EpPCIeDevice `json:"oids"`
+}
+
+// This is one of possibly several PCIeDevices for a particular EpSystem.
+// The HMS type it is inventoried as depends on the DeviceClass of its
+// first PCIeFunction.
+type EpPCIeDevice struct {
+	// Embedded struct: id, type, odataID and associated RfEndpointID.
+	ComponentDescription
+
+	BaseOdataID string `json:"BaseOdataID"`
+
+	// Embedded struct - Locational/FRU, state, and status info
+	InventoryData
+
+	PCIeDeviceURL string `json:"pcieDeviceURL"` // Full URL to this RF PCIeDevice obj
+	ParentOID     string `json:"parentOID"`     // odata.id for parent
+	ParentType    string `json:"parentType"`    // Chassis or ComputerSystem
+	LastStatus    string `json:"LastStatus"`
+
+	PCIeDeviceRF  PCIeDevice     `json:"PCIeDeviceRF"`
+	PCIeFunctions []PCIeFunction `json:"PCIeFunctions"`
+	pcieDeviceRaw *json.RawMessage
+
+	epRF     *RedfishEP // Backpointer to RF EP, for connection details, etc.
+	systemRF *EpSystem  // Backpointer to the associated system.
+}
+
+// Initializes EpPCIeDevice struct with minimal information needed to
+// discover it, i.e. endpoint info and the odataID of the PCIeDevice to
+// look at.  This should be the only way this struct is created.
+func NewEpPCIeDevice(s *EpSystem, poid, pType string, odataID ResourceID, rawOrdinal int) *EpPCIeDevice {
+	d := new(EpPCIeDevice)
+	d.OdataID = odataID.Oid
+
+	d.Type = PCIeDeviceType
+	d.BaseOdataID = odataID.Basename()
+	d.RedfishType = PCIeDeviceType
+	d.RfEndpointID = s.epRF.ID
+
+	d.PCIeDeviceURL = s.epRF.FQDN + odataID.Oid
+	d.ParentOID = poid
+	d.ParentType = pType
+
+	d.Ordinal = -1
+	d.RawOrdinal = rawOrdinal
+
+	d.LastStatus = NotYetQueried
+	d.epRF = s.epRF
+	d.systemRF = s
+
+	return d
+}
+
+// Makes contact with redfish endpoint to discover information about
+// all PCIeDevices for a given Redfish System.  EpPCIeDevice entries
+// should be created with the appropriate constructor first.
+func (ds *EpPCIeDevices) discoverRemotePhase1() {
+	for _, d := range ds.OIDs {
+		d.discoverRemotePhase1()
+	}
+}
+
+// Makes contact with redfish endpoint to discover information about
+// a particular PCIeDevice and its PCIeFunctions.  Note that the
+// EpPCIeDevice should be created with the appropriate constructor first.
+func (d *EpPCIeDevice) discoverRemotePhase1() {
+	rpath := d.OdataID
+	url := d.epRF.FQDN + rpath
+	urlJSON, err := d.epRF.GETRelative(rpath)
+	if err != nil || urlJSON == nil {
+		if err == ErrRFDiscURLNotFound {
+			errlog.Printf("%s: Redfish bug! Link %s was dead (404).  "+
+				"Will try to continue.  No component will be created.",
+				d.epRF.ID, rpath)
+			d.LastStatus = RedfishSubtypeNoSupport
+			d.RedfishSubtype = RFSubtypeUnknown
+		} else {
+			d.LastStatus = HTTPsGetFailed
+		}
+		return
+	}
+	d.pcieDeviceRaw = &urlJSON
+	d.LastStatus = HTTPsGetOk
+
+	if rfDebug > 0 {
+		errlog.Printf("%s: %s\n", url, urlJSON)
+	}
+	// Decode JSON into PCIeDevice structure containing Redfish data
+	if err := json.Unmarshal(urlJSON, &d.PCIeDeviceRF); err != nil {
+		if IsUnmarshalTypeError(err) {
+			errlog.Printf("bad field(s) skipped: %s: %s\n", url, err)
+		} else {
+			errlog.Printf("ERROR: json decode failed: %s: %s\n", url, err)
+			d.LastStatus = EPResponseFailedDecode
+			return
+		}
+	}
+	d.discoverPCIeFunctions()
+
+	if rfVerbose > 0 {
+		jout, _ := json.MarshalIndent(d, "", "   ")
+		errlog.Printf("%s: %s\n", url, jout)
+	}
+
+	d.LastStatus = VerifyingData
+}
+
+// Get the PCIeFunctions of the device, either via the Links (newer
+// schemas) or the PCIeFunctions collection.  The DeviceClass of the lowest
+// numbered function determines how the device is inventoried.  Failures
+// here are logged but otherwise ignored, leaving the device unclassified.
+func (d *EpPCIeDevice) discoverPCIeFunctions() {
+	fOIDs := d.PCIeDeviceRF.Links.PCIeFunctions
+	if len(fOIDs) == 0 && d.PCIeDeviceRF.PCIeFunctions.Oid != "" {
+		path := d.PCIeDeviceRF.PCIeFunctions.Oid
+		collJSON, err := d.epRF.GETRelative(path)
+		if err != nil || collJSON == nil {
+			errlog.Printf("%s: Failed to get PCIeFunctions: %s\n",
+				d.epRF.FQDN+path, err)
+			return
+		}
+		var fInfo PCIeFunctionCollection
+		if err := json.Unmarshal(collJSON, &fInfo); err != nil {
+			errlog.Printf("Failed to decode %s: %s\n", d.epRF.FQDN+path, err)
+			return
+		}
+		fOIDs = fInfo.Members
+	}
+	d.PCIeFunctions = make([]PCIeFunction, 0, len(fOIDs))
+	for _, fOID := range fOIDs {
+		fJSON, err := d.epRF.GETRelative(fOID.Oid)
+		if err != nil || fJSON == nil {
+			errlog.Printf("%s: Failed to get PCIeFunction: %s\n",
+				d.epRF.FQDN+fOID.Oid, err)
+			continue
+		}
+		var f PCIeFunction
+		if err := json.Unmarshal(fJSON, &f); err != nil && !IsUnmarshalTypeError(err) {
+			errlog.Printf("Failed to decode %s: %s\n", d.epRF.FQDN+fOID.Oid, err)
+			continue
+		}
+		d.PCIeFunctions = append(d.PCIeFunctions, f)
+	}
+	sort.Slice(d.PCIeFunctions, func(i, j int) bool {
+		return d.PCIeFunctions[i].FunctionId < d.PCIeFunctions[j].FunctionId
+	})
+
+	d.PCIeDeviceRF.Functions = make([]PCIeFunctionInfo, 0, len(d.PCIeFunctions))
+	for _, f := range d.PCIeFunctions {
+		d.PCIeDeviceRF.Functions = append(d.PCIeDeviceRF.Functions, PCIeFunctionInfo{
+			FunctionId:   f.FunctionId,
+			FunctionType: f.FunctionType,
+			DeviceClass:  f.DeviceClass,
+			ClassCode:    f.ClassCode,
+		})
+	}
+	if len(d.PCIeFunctions) > 0 {
+		f := d.PCIeFunctions[0]
+		d.PCIeDeviceRF.DeviceClass = f.DeviceClass
+		d.PCIeDeviceRF.VendorId = f.VendorId
+		d.PCIeDeviceRF.DeviceId = f.DeviceId
+		d.PCIeDeviceRF.SubsystemVendorId = f.SubsystemVendorId
+		d.PCIeDeviceRF.SubsystemId = f.SubsystemId
+	}
+	d.RedfishSubtype = d.PCIeDeviceRF.DeviceClass
+}
+
+// This is the second discovery phase, after all information from
+// the parent system has been gathered.  This is not intended to
+// be run as a separate step; it is separate because certain discovery
+// activities may require that information is gathered for all components
+// under the system first, so that it is available during later steps.
+//
+// The HMS type of every device is set first since ordinals are assigned
+// among the devices of the same type.
+func (ds *EpPCIeDevices) discoverLocalPhase2() error {
+	var savedError error
+	for _, d := range ds.OIDs {
+		if d.epRF != nil && d.LastStatus == VerifyingData {
+			d.Type = d.epRF.getPCIeDeviceHMSType(d)
+		}
+	}
+	for i, d := range ds.OIDs {
+		d.discoverLocalPhase2()
+		if d.LastStatus == RedfishSubtypeNoSupport {
+			errlog.Printf("Key %s: RF PCIeDevice class not supported: %s",
+				i, d.RedfishSubtype)
+		} else if d.LastStatus != DiscoverOK {
+			err := fmt.Errorf("Key %s: %s", i, d.LastStatus)
+			errlog.Printf("PCIeDevice discoverLocalPhase2: saw error: %s", err)
+			savedError = err
+		}
+	}
+	return savedError
+}
+
+// Phase2 discovery for an individual PCIeDevice.  Now that all information
+// has been gathered, we can set the remaining fields needed to provide
+// HMS with information about where the PCIeDevice is located
+func (d *EpPCIeDevice) discoverLocalPhase2() {
+	// Should never happen
+	if d.epRF == nil {
+		errlog.Printf("Error: RedfishEP == nil for odataID: %s\n",
+			d.OdataID)
+		d.LastStatus = EndpointInvalid
+		return
+	}
+	if d.LastStatus != VerifyingData {
+		return
+	}
+	if d.Type == xnametypes.HMSTypeInvalid.String() {
+		d.LastStatus = RedfishSubtypeNoSupport
+		return
+	}
+	d.Ordinal = d.epRF.getPCIeDeviceOrdinal(d)
+	d.ID = d.epRF.getPCIeDeviceHMSID(d, d.Type, d.Ordinal)
+	if d.PCIeDeviceRF.Status != nil && d.PCIeDeviceRF.Status.State == "Absent" {
+		d.Status = "Empty"
+		d.State = base.StateEmpty.String()
+		//the state of the component is known (empty), it is not locked, does not have an alert or warning, so therefore Flag defaults to OK.
+		d.Flag = base.FlagOK.String()
+	} else {
+		d.Status = "Populated"
+		d.State = base.StatePopulated.String()
+		d.Flag = base.FlagOK.String()
+		if d.PCIeDeviceRF.Status != nil {
+			d.Flag = HealthFlag(d.PCIeDeviceRF.Status.Health)
+		}
+		d.FRUID = GetPCIeDeviceFRUID(d.Type, d.ID, &d.PCIeDeviceRF.PCIeDeviceFRUInfoRF)
+	}
+
+	// Check if we have something valid to insert into the data store
+	hmsType := xnametypes.GetHMSType(d.ID)
+	if hmsType.String() != d.Type ||
+		(hmsType != xnametypes.NodeNic &&
+			hmsType != xnametypes.StorageGroup &&
+			hmsType != xnametypes.NodeAccel) {
+		errlog.Printf("Error: Bad xname ID ('%s') or Type ('%s') for: %s\n",
+			d.ID, d.Type, d.PCIeDeviceURL)
+		d.LastStatus = VerificationFailed
+		return
+	}
+	if rfVerbose > 0 {
+		jout, _ := json.MarshalIndent(d, "", "   ")
+		errlog.Printf("%s\n", jout)
+		errlog.Printf("PCIeDevice ID: %s\n", d.ID)
+		errlog.Printf("PCIeDevice FRUID: %s\n", d.FRUID)
+	}
+	d.LastStatus = DiscoverOK
+}
+
+// Gets the HMS type of the PCIeDevice from the DeviceClass of its first
+// function - Note Invalid means something special here, namely, "skip
+// it"/"not supported".  Devices that are already inventoried via a
+// NetworkAdapter, HPE device or Processor are also skipped.
+// Post phase 1 discovery.
+func (ep *RedfishEP) getPCIeDeviceHMSType(d *EpPCIeDevice) string {
+	s := d.systemRF
+	for _, na := range s.NetworkAdapters.OIDs {
+		if na.NetworkAdapterRF == nil {
+			continue
+		}
+		for _, c := range na.NetworkAdapterRF.Controllers {
+			for _, pOID := range c.Links.PCIeDevices {
+				if normalizeOID(pOID.Oid) == normalizeOID(d.OdataID) {
+					return xnametypes.HMSTypeInvalid.String()
+				}
+			}
+		}
+	}
+	serial := strings.TrimSpace(d.PCIeDeviceRF.SerialNumber)
+	if serial != "" {
+		for _, na := range s.NetworkAdapters.OIDs {
+			if na.NetworkAdapterRF != nil && na.NetworkAdapterRF.SerialNumber == serial {
+				return xnametypes.HMSTypeInvalid.String()
+			}
+		}
+		for _, hd := range s.HpeDevices.OIDs {
+			if hd.DeviceRF.SerialNumber == serial {
+				return xnametypes.HMSTypeInvalid.String()
+			}
+		}
+		for _, p := range s.Processors.OIDs {
+			if p.ProcessorRF.SerialNumber == serial {
+				return xnametypes.HMSTypeInvalid.String()
+			}
+		}
+	}
+	switch d.PCIeDeviceRF.DeviceClass {
+	case PCIeClassNetworkController:
+		return xnametypes.NodeNic.String()
+	case PCIeClassMassStorageController:
+		return xnametypes.StorageGroup.String()
+	case PCIeClassProcessingAccelerator, PCIeClassCoprocessor:
+		return xnametypes.NodeAccel.String()
+	}
+	// GPUs (DisplayController) are found as Processors, others are not
+	// tracked.
+	return xnametypes.HMSTypeInvalid.String()
+}
+
+// Determined based on discovered info and original list order what the
+// PCIeDevice ordinal is.  Devices are ordered among the others of the same
+// HMS type, after any of that type discovered via other Redfish objects.
+func (ep *RedfishEP) getPCIeDeviceOrdinal(d *EpPCIeDevice) int {
+	s := d.systemRF
+	dsOIDs := make([]string, 0, len(s.PCIeDevices.OIDs))
+	for oid, device := range s.PCIeDevices.OIDs {
+		if device.Type == d.Type {
+			dsOIDs = append(dsOIDs, oid)
+		}
+	}
+	sort.Strings(dsOIDs)
+	ordinal := d.RawOrdinal
+	for i, dsOID := range dsOIDs {
+		if s.PCIeDevices.OIDs[dsOID] == d {
+			ordinal = i
+			break
+		}
+	}
+	switch xnametypes.ToHMSType(d.Type) {
+	case xnametypes.StorageGroup:
+		// Don't collide with the storage groups drives are under.
+		ordinal += s.StorageGroups.Num
+	case xnametypes.NodeAccel:
+		// Come after GPUs found as Processors or HPE devices.
+		next := 0
+		for _, p := range s.Processors.OIDs {
+			if p.Type == xnametypes.NodeAccel.String() && p.Ordinal >= next {
+				next = p.Ordinal + 1
+			}
+		}
+		for _, hd := range s.HpeDevices.OIDs {
+			if hd.Type == xnametypes.NodeAccel.String() && hd.Ordinal >= next {
+				next = hd.Ordinal + 1
+			}
+		}
+		ordinal += next
+	}
+	return ordinal
+}
+
+// Determined based on discovered info the xname of the PCIeDevice.
+// Need to know real (not raw ordinal) and HMS type first.
+// Post phase 1 discovery.
+func (ep *RedfishEP) getPCIeDeviceHMSID(d *EpPCIeDevice, hmsType string, ordinal int) string {
+	if ordinal < 0 {
+		// Invalid ordinal or initial -1 value.
+		return ""
+	}
+	switch xnametypes.ToHMSType(hmsType) {
+	case xnametypes.NodeNic:
+		return d.systemRF.ID + "i" + strconv.Itoa(ordinal)
+	case xnametypes.StorageGroup:
+		return d.systemRF.ID + "g" + strconv.Itoa(ordinal)
+	case xnametypes.NodeAccel:
+		return d.systemRF.ID + "a" + strconv.Itoa(ordinal)
+	}
+	// This is an error or a skipped type.
+	return ""
+}
+
+// Build FRUID using standard fields: <Type>.<Manufacturer>.<PartNumber>.<SerialNumber>
+// Falls back to an untrackable FRUID if these are missing.
+func GetPCIeDeviceFRUID(hmstype, id string, fru *PCIeDeviceFRUInfoRF) string {
+	fruID, err := getStandardFRUID(hmstype, id, fru.Manufacturer, fru.PartNumber, fru.SerialNumber)
+	if err != nil {
+		errlog.Printf("FRUID Error: %s\n", err.Error())
+		errlog.Printf("Using untrackable FRUID: %s\n", fruID)
+	}
+	return fruID
+}
@@ -0,0 +1,257 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package rf
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"testing"
+)
+
+////////////////////////////////////////////////////////////////////////////
+//
+//  PCIeDevices and PCIeFunctions
+//
+///////////////////////////////////////////////////////////////////////////
+
+const testPathPCIeDevNIC = "/redfish/v1/Chassis/Self/PCIeDevices/NIC1"
+const testPathPCIeDevNICFunc0 = "/redfish/v1/Chassis/Self/PCIeDevices/NIC1/PCIeFunctions/0"
+const testPathPCIeDevNICFunc1 = "/redfish/v1/Chassis/Self/PCIeDevices/NIC1/PCIeFunctions/1"
+const testPathPCIeDevNVMe = "/redfish/v1/Chassis/Self/PCIeDevices/NVMe0"
+const testPathPCIeDevNVMeFuncs = "/redfish/v1/Chassis/Self/PCIeDevices/NVMe0/PCIeFunctions"
+const testPathPCIeDevNVMeFunc0 = "/redfish/v1/Chassis/Self/PCIeDevices/NVMe0/PCIeFunctions/0"
+const testPathPCIeDevFPGA = "/redfish/v1/Chassis/Self/PCIeDevices/FPGA0"
+const testPathPCIeDevFPGAFunc0 = "/redfish/v1/Chassis/Self/PCIeDevices/FPGA0/PCIeFunctions/0"
+const testPathPCIeDevBridge = "/redfish/v1/Chassis/Self/PCIeDevices/Bridge0"
+const testPathPCIeDevBridgeFunc0 = "/redfish/v1/Chassis/Self/PCIeDevices/Bridge0/PCIeFunctions/0"
+const testPathPCIeDevHSN = "/redfish/v1/Chassis/Self/PCIeDevices/HSN0"
+const testPathPCIeDevHSNFunc0 = "/redfish/v1/Chassis/Self/PCIeDevices/HSN0/PCIeFunctions/0"
+
+var testPayloadsPCIe = map[string]string{
+	testPathPCIeDevNIC: `{
+		"@odata.id": "/redfish/v1/Chassis/Self/PCIeDevices/NIC1",
+		"Id": "NIC1",
+		"Name": "Ethernet Adapter",
+		"Manufacturer": "Intel",
+		"Model": "X710",
+		"PartNumber": "X710-DA2",
+		"SerialNumber": "NIC1SN",
+		"Status": {"State": "Enabled", "Health": "OK"},
+		"Links": {"PCIeFunctions": [
+			{"@odata.id": "/redfish/v1/Chassis/Self/PCIeDevices/NIC1/PCIeFunctions/1"},
+			{"@odata.id": "/redfish/v1/Chassis/Self/PCIeDevices/NIC1/PCIeFunctions/0"}
+		]}
+	}`,
+	testPathPCIeDevNICFunc0: `{"Id": "0", "FunctionId": 0, "DeviceClass": "NetworkController",
+		"VendorId": "0x8086", "DeviceId": "0x1572", "ClassCode": "0x020000"}`,
+	testPathPCIeDevNICFunc1: `{"Id": "1", "FunctionId": 1, "DeviceClass": "NetworkController",
+		"VendorId": "0x8086", "DeviceId": "0x1572", "ClassCode": "0x020000"}`,
+	testPathPCIeDevNVMe: `{
+		"@odata.id": "/redfish/v1/Chassis/Self/PCIeDevices/NVMe0",
+		"Id": "NVMe0",
+		"Name": "NVMe Controller",
+		"Manufacturer": "Samsung",
+		"PartNumber": "PM1733",
+		"SerialNumber": "NVMESN",
+		"PCIeFunctions": {"@odata.id": "/redfish/v1/Chassis/Self/PCIeDevices/NVMe0/PCIeFunctions"}
+	}`,
+	testPathPCIeDevNVMeFuncs: `{"Members": [
+		{"@odata.id": "/redfish/v1/Chassis/Self/PCIeDevices/NVMe0/PCIeFunctions/0"}
+	]}`,
+	testPathPCIeDevNVMeFunc0: `{"Id": "0", "FunctionId": 0, "DeviceClass": "MassStorageController"}`,
+	testPathPCIeDevFPGA: `{
+		"@odata.id": "/redfish/v1/Chassis/Self/PCIeDevices/FPGA0",
+		"Id": "FPGA0",
+		"Name": "FPGA Card",
+		"Manufacturer": "Xilinx",
+		"PartNumber": "U250",
+		"SerialNumber": "FPGASN",
+		"Links": {"PCIeFunctions": [
+			{"@odata.id": "/redfish/v1/Chassis/Self/PCIeDevices/FPGA0/PCIeFunctions/0"}
+		]}
+	}`,
+	testPathPCIeDevFPGAFunc0: `{"Id": "0", "FunctionId": 0, "DeviceClass": "ProcessingAccelerators"}`,
+	testPathPCIeDevBridge: `{
+		"@odata.id": "/redfish/v1/Chassis/Self/PCIeDevices/Bridge0",
+		"Id": "Bridge0",
+		"Links": {"PCIeFunctions": [
+			{"@odata.id": "/redfish/v1/Chassis/Self/PCIeDevices/Bridge0/PCIeFunctions/0"}
+		]}
+	}`,
+	testPathPCIeDevBridgeFunc0: `{"Id": "0", "FunctionId": 0, "DeviceClass": "Bridge"}`,
+	testPathPCIeDevHSN: `{
+		"@odata.id": "/redfish/v1/Chassis/Self/PCIeDevices/HSN0",
+		"Id": "HSN0",
+		"Manufacturer": "HPE",
+		"SerialNumber": "HSNSN",
+		"Links": {"PCIeFunctions": [
+			{"@odata.id": "/redfish/v1/Chassis/Self/PCIeDevices/HSN0/PCIeFunctions/0"}
+		]}
+	}`,
+	testPathPCIeDevHSNFunc0: `{"Id": "0", "FunctionId": 0, "DeviceClass": "NetworkController"}`,
+}
+
+func NewRTFuncPCIe() RTFunc {
+	return func(req *http.Request) *http.Response {
+		for path, payload := range testPayloadsPCIe {
+			if req.URL.String() == "https://"+testFQDN+path {
+				return &http.Response{
+					StatusCode: 200,
+					// Send mock response for rpath
+					Body: ioutil.NopCloser(bytes.NewBufferString(payload)),
+					// Header must always be non-nil or it will cause a panic.
+					Header: make(http.Header),
+				}
+			}
+		}
+		return &http.Response{
+			StatusCode: 404,
+			Body:       ioutil.NopCloser(bytes.NewBufferString("")),
+			Header:     make(http.Header),
+		}
+	}
+}
+
+func TestPCIeDeviceDiscovery(t *testing.T) {
+	ep := TestRedfishEPInitIntel
+	ep.client = NewTestClient(NewRTFuncPCIe())
+
+	s := new(EpSystem)
+	s.epRF = &ep
+	s.ID = testXName + "n0"
+	s.OdataID = "/redfish/v1/Systems/Self"
+	// One storage group already holds the drives.
+	s.StorageGroups.Num = 1
+	// An HSN NIC found via NetworkAdapters, with the same serial number.
+	s.NetworkAdapters.OIDs = map[string]*EpNetworkAdapter{
+		"HSN": &EpNetworkAdapter{
+			NetworkAdapterRF: &NetworkAdapter{
+				NAFRUInfoRF: NAFRUInfoRF{SerialNumber: "HSNSN"},
+			},
+		},
+	}
+	// A GPU found as a processor, which the FPGA must be numbered after.
+	s.Processors.OIDs = map[string]*EpProcessor{
+		"GPU0": &EpProcessor{
+			ComponentDescription: ComponentDescription{Type: "NodeAccel"},
+			InventoryData:        InventoryData{Ordinal: 0},
+		},
+	}
+
+	oids := []string{testPathPCIeDevNIC, testPathPCIeDevNVMe, testPathPCIeDevFPGA,
+		testPathPCIeDevBridge, testPathPCIeDevHSN}
+	s.PCIeDevices.OIDs = make(map[string]*EpPCIeDevice)
+	for i, oid := range oids {
+		rid := ResourceID{Oid: oid}
+		s.PCIeDevices.OIDs[rid.Basename()] = NewEpPCIeDevice(s, s.OdataID, ComputerSystemType, rid, i)
+	}
+	s.PCIeDevices.Num = len(oids)
+	s.PCIeDevices.discoverRemotePhase1()
+	if err := s.PCIeDevices.discoverLocalPhase2(); err != nil {
+		t.Fatalf("PCIeDevices discoverLocalPhase2 failed: %s", err)
+	}
+
+	tests := []struct {
+		key        string
+		lastStatus string
+		id         string
+		hmsType    string
+		class      string
+		numFuncs   int
+		fruID      string
+	}{{
+		// Functions are sorted by FunctionId
+		key:        "NIC1",
+		lastStatus: DiscoverOK,
+		id:         testXName + "n0i0",
+		hmsType:    "NodeNic",
+		class:      PCIeClassNetworkController,
+		numFuncs:   2,
+		fruID:      "NodeNic.Intel.X710DA2.NIC1SN",
+	}, {
+		// Functions from the collection, numbered after the drive groups
+		key:        "NVMe0",
+		lastStatus: DiscoverOK,
+		id:         testXName + "n0g1",
+		hmsType:    "StorageGroup",
+		class:      PCIeClassMassStorageController,
+		numFuncs:   1,
+		fruID:      "StorageGroup.Samsung.PM1733.NVMESN",
+	}, {
+		// Numbered after the GPU processor
+		key:        "FPGA0",
+		lastStatus: DiscoverOK,
+		id:         testXName + "n0a1",
+		hmsType:    "NodeAccel",
+		class:      PCIeClassProcessingAccelerator,
+		numFuncs:   1,
+		fruID:      "NodeAccel.Xilinx.U250.FPGASN",
+	}, {
+		// Unsupported class
+		key:        "Bridge0",
+		lastStatus: RedfishSubtypeNoSupport,
+		class:      "Bridge",
+		numFuncs:   1,
+	}, {
+		// Already inventoried as an HSN NIC
+		key:        "HSN0",
+		lastStatus: RedfishSubtypeNoSupport,
+		class:      PCIeClassNetworkController,
+		numFuncs:   1,
+	}}
+	for i, test := range tests {
+		d, ok := s.PCIeDevices.OIDs[test.key]
+		if !ok {
+			t.Errorf("Test %d Failed: %s was not discovered", i, test.key)
+			continue
+		}
+		if d.LastStatus != test.lastStatus {
+			t.Errorf("Test %d Failed: %s Expected LastStatus '%s', got '%s'",
+				i, test.key, test.lastStatus, d.LastStatus)
+		}
+		if d.PCIeDeviceRF.DeviceClass != test.class {
+			t.Errorf("Test %d Failed: %s Expected class '%s', got '%s'",
+				i, test.key, test.class, d.PCIeDeviceRF.DeviceClass)
+		}
+		if len(d.PCIeDeviceRF.Functions) != test.numFuncs {
+			t.Errorf("Test %d Failed: %s Expected %d functions, got %d",
+				i, test.key, test.numFuncs, len(d.PCIeDeviceRF.Functions))
+		}
+		if test.lastStatus != DiscoverOK {
+			continue
+		}
+		if d.ID != test.id || d.Type != test.hmsType {
+			t.Errorf("Test %d Failed: %s Expected '%s/%s', got '%s/%s'",
+				i, test.key, test.id, test.hmsType, d.ID, d.Type)
+		}
+		if d.FRUID != test.fruID {
+			t.Errorf("Test %d Failed: %s Expected FRUID '%s', got '%s'",
+				i, test.key, test.fruID, d.FRUID)
+		}
+	}
+	nic := s.PCIeDevices.OIDs["NIC1"]
+	if nic.PCIeDeviceRF.Functions[0].FunctionId != 0 || nic.PCIeDeviceRF.VendorId != "0x8086" {
+		t.Errorf("NIC1 function 0 not used for PCI IDs: %v", nic.PCIeDeviceRF.PCIeDeviceFRUInfoRF)
+	}
+}
@@ -875,7 +875,7 @@ func (p *EpPowerSupply) discoverLocalPhase2() {
 	if p.PowerSupplyRF.Status.State != "Absent" {
 		p.Status = "Populated"
 		p.State = base.StatePopulated.String()
-		p.Flag = base.FlagOK.String()
+		p.Flag = HealthFlag(p.PowerSupplyRF.Status.Health)
 		generatedFRUID, err := GetPowerSupplyFRUID(p)
 		if err != nil {
 			errlog.Printf("FRUID Error: %s\n", err.Error())
@@ -359,7 +359,7 @@ func (d *EpDrive) discoverLocalPhase2() {
 	if d.DriveRF.Status.State != "Absent" {
 		d.Status = "Populated"
 		d.State = base.StatePopulated.String()
-		d.Flag = base.FlagOK.String()
+		d.Flag = HealthFlag(d.DriveRF.Status.Health)
 		generatedFRUID, err := GetDriveFRUID(d)
 		if err != nil {
 			errlog.Printf("FRUID Error: %s\n", err.Error())
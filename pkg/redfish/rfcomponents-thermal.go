@@ -0,0 +1,333 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package rf
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+
+	base "github.com/Cray-HPE/hms-base/v2"
+	"github.com/Cray-HPE/hms-xname/xnametypes"
+)
+
+/////////////////////////////////////////////////////////////////////////////
+// Chassis - Fans
+/////////////////////////////////////////////////////////////////////////////
+
+// Set of EpFan, each representing a Redfish "Fan" listed under a Redfish
+// Chassis' Thermal or ThermalSubsystem object.
+type EpFans struct {
+	Num  int               `json:"num"`
+	OIDs map[string]*EpFan `json:"oids"`
+}
+
+// This is one of possibly several Fans for a particular EpChassis (Redfish
+// "Chassis").  There is no HMS type for fans, so they are tracked as
+// inventory of the component the parent chassis maps to (ParentID), using
+// the Redfish Id (FanID) to tell them apart.
+type EpFan struct {
+	// Embedded struct: id, type, odataID and associated RfEndpointID.
+	ComponentDescription
+
+	BaseOdataID string `json:"BaseOdataID"`
+
+	// Embedded struct - Locational/FRU, state, and status info
+	InventoryData
+
+	FanID      string `json:"fanID"`      // Redfish Id/MemberId of the fan
+	ParentID   string `json:"parentID"`   // xname of the parent chassis
+	FanURL     string `json:"fanURL"`     // Full URL to this RF Fan obj
+	ParentOID  string `json:"parentOID"`  // odata.id for parent
+	ParentType string `json:"parentType"` // Thermal or ThermalSubsystem
+	LastStatus string `json:"LastStatus"`
+
+	FanRF  *Fan `json:"FanRF"`
+	fanRaw *json.RawMessage
+
+	epRF      *RedfishEP // Backpointer to RF EP, for connection details, etc.
+	chassisRF *EpChassis // Backpointer to parent chassis.
+}
+
+// Initializes EpFan struct with minimal information needed to discover
+// it, i.e. endpoint info and the odataID of the Fan to look at.  For fans
+// embedded in a (legacy) Thermal object, fanRF is the already decoded
+// array entry and no further query is needed.  This should be the only
+// way this struct is created for Fans under a chassis.
+func NewEpFan(c *EpChassis, pOID, pType string, odataID ResourceID, rawOrdinal int, fanRF *Fan) *EpFan {
+	f := new(EpFan)
+	f.OdataID = odataID.Oid
+	f.Type = FanType
+	f.BaseOdataID = odataID.Basename()
+	f.RedfishType = FanType
+	f.RfEndpointID = c.epRF.ID
+
+	f.FanURL = c.epRF.FQDN + odataID.Oid
+	f.ParentOID = pOID
+	f.ParentType = pType
+
+	f.Ordinal = -1
+	f.RawOrdinal = rawOrdinal
+
+	f.LastStatus = NotYetQueried
+	f.FanRF = fanRF
+	f.epRF = c.epRF
+	f.chassisRF = c
+
+	return f
+}
+
+// Discovers the fans for a chassis, preferring the newer ThermalSubsystem
+// and falling back to the legacy Thermal object.  Fans are optional, so
+// problems are logged but do not fail discovery of the chassis.
+func (c *EpChassis) discoverFans() {
+	c.Fans.Num = 0
+	c.Fans.OIDs = make(map[string]*EpFan)
+
+	if c.ChassisRF.ThermalSubsystem.Oid != "" {
+		c.discoverThermalSubsystemFans()
+	}
+	if c.Fans.Num == 0 && c.ChassisRF.Thermal.Oid != "" {
+		c.discoverThermalFans()
+	}
+	c.Fans.discoverRemotePhase1()
+}
+
+// Gets the Fans collection linked from the chassis' ThermalSubsystem.
+func (c *EpChassis) discoverThermalSubsystemFans() {
+	path := c.ChassisRF.ThermalSubsystem.Oid
+	url := c.epRF.FQDN + path
+	tsJSON, err := c.epRF.GETRelative(path)
+	if err != nil || tsJSON == nil {
+		errlog.Printf("%s: ThermalSubsystem query failed: %v\n", url, err)
+		return
+	}
+	if rfDebug > 0 {
+		errlog.Printf("%s: %s\n", url, tsJSON)
+	}
+	var ts ThermalSubsystem
+	if err := json.Unmarshal(tsJSON, &ts); err != nil {
+		if IsUnmarshalTypeError(err) {
+			errlog.Printf("bad field(s) skipped: %s: %s\n", url, err)
+		} else {
+			errlog.Printf("ERROR: json decode failed: %s: %s\n", url, err)
+			return
+		}
+	}
+	if ts.Fans.Oid == "" {
+		return
+	}
+	path = ts.Fans.Oid
+	url = c.epRF.FQDN + path
+	fansJSON, err := c.epRF.GETRelative(path)
+	if err != nil || fansJSON == nil {
+		errlog.Printf("%s: Fans query failed: %v\n", url, err)
+		return
+	}
+	if rfDebug > 0 {
+		errlog.Printf("%s: %s\n", url, fansJSON)
+	}
+	var fanInfo FanCollection
+	if err := json.Unmarshal(fansJSON, &fanInfo); err != nil {
+		errlog.Printf("Failed to decode %s: %s\n", url, err)
+		return
+	}
+	if fanInfo.MembersOCount > 0 && fanInfo.MembersOCount != len(fanInfo.Members) {
+		errlog.Printf("%s: Member@odata.count != Member array len\n", url)
+	}
+	// Sort in lexical order, so the ordinal values will keep the same
+	// ordering.
+	sort.Sort(ResourceIDSlice(fanInfo.Members))
+	for i, fanOID := range fanInfo.Members {
+		c.Fans.OIDs[fanOID.Oid] = NewEpFan(c, ts.Fans.Oid,
+			ThermalSubsystemType, fanOID, i, nil)
+	}
+	c.Fans.Num = len(c.Fans.OIDs)
+}
+
+// Gets the Fans array embedded in the chassis' (legacy) Thermal object.
+func (c *EpChassis) discoverThermalFans() {
+	path := c.ChassisRF.Thermal.Oid
+	url := c.epRF.FQDN + path
+	thermalJSON, err := c.epRF.GETRelative(path)
+	if err != nil || thermalJSON == nil {
+		errlog.Printf("%s: Thermal query failed: %v\n", url, err)
+		return
+	}
+	if rfDebug > 0 {
+		errlog.Printf("%s: %s\n", url, thermalJSON)
+	}
+	var thermal Thermal
+	if err := json.Unmarshal(thermalJSON, &thermal); err != nil {
+		if IsUnmarshalTypeError(err) {
+			errlog.Printf("bad field(s) skipped: %s: %s\n", url, err)
+		} else {
+			errlog.Printf("ERROR: json decode failed: %s: %s\n", url, err)
+			return
+		}
+	}
+	if thermal.FansOCount > 0 && thermal.FansOCount != len(thermal.Fans) {
+		errlog.Printf("%s: Fans@odata.count != Fans array len\n", url)
+	}
+	for i, fan := range thermal.Fans {
+		if fan == nil {
+			continue
+		}
+		// Array entries should have their own odata.id, but not all
+		// implementations provide one.
+		fanOID := fan.Oid
+		if fanOID == "" {
+			fanOID = path + "#/Fans/" + strconv.Itoa(i)
+		}
+		c.Fans.OIDs[fanOID] = NewEpFan(c, path, ThermalType,
+			ResourceID{fanOID}, i, fan)
+	}
+	c.Fans.Num = len(c.Fans.OIDs)
+}
+
+// Retrieves the FanRF for each fan that is not embedded in its parent.
+func (fs *EpFans) discoverRemotePhase1() {
+	for _, f := range fs.OIDs {
+		f.discoverRemotePhase1()
+	}
+}
+
+// Makes contact with redfish endpoint to discover information about
+// the Fan, if it was not already retrieved with its parent Thermal object.
+func (f *EpFan) discoverRemotePhase1() {
+	if f.FanRF == nil {
+		path := f.OdataID
+		fanJSON, err := f.epRF.GETRelative(path)
+		if err != nil || fanJSON == nil {
+			if err == ErrRFDiscURLNotFound {
+				errlog.Printf("%s: Redfish bug! Link %s was dead (404).  "+
+					"Will try to continue.  No fan will be created.",
+					f.epRF.ID, path)
+				f.LastStatus = RedfishSubtypeNoSupport
+			} else {
+				f.LastStatus = HTTPsGetFailed
+			}
+			return
+		}
+		if rfDebug > 0 {
+			errlog.Printf("%s: %s\n", f.FanURL, fanJSON)
+		}
+		f.fanRaw = &fanJSON
+		f.FanRF = new(Fan)
+		if err := json.Unmarshal(fanJSON, f.FanRF); err != nil {
+			if IsUnmarshalTypeError(err) {
+				errlog.Printf("bad field(s) skipped: %s: %s\n", f.FanURL, err)
+			} else {
+				errlog.Printf("ERROR: json decode failed: %s: %s\n", f.FanURL, err)
+				f.LastStatus = EPResponseFailedDecode
+				return
+			}
+		}
+	}
+	f.LastStatus = HTTPsGetOk
+	f.RedfishSubtype = f.ParentType
+
+	if rfVerbose > 0 {
+		jout, _ := json.MarshalIndent(f, "", "   ")
+		errlog.Printf("%s: %s\n", f.FanURL, jout)
+	}
+	f.LastStatus = VerifyingData
+}
+
+// This is the second discovery phase, after the parent chassis has been
+// assigned its xname.
+func (fs *EpFans) discoverLocalPhase2() error {
+	var savedError error
+	for i, f := range fs.OIDs {
+		f.discoverLocalPhase2()
+		if f.LastStatus == RedfishSubtypeNoSupport {
+			errlog.Printf("Key %s: RF Fan not supported", i)
+		} else if f.LastStatus != DiscoverOK {
+			err := fmt.Errorf("Key %s: %s", i, f.LastStatus)
+			errlog.Printf("Fans discoverLocalPhase2: saw error: %s", err)
+			savedError = err
+		}
+	}
+	return savedError
+}
+
+// Phase2 discovery for an individual Fan.  Sets the parent xname, the
+// Redfish Id used to tell fans apart within it, and the state and FRU info.
+func (f *EpFan) discoverLocalPhase2() {
+	// Should never happen
+	if f.epRF == nil {
+		errlog.Printf("Error: RedfishEP == nil for odataID: %s\n",
+			f.OdataID)
+		f.LastStatus = EndpointInvalid
+		return
+	}
+	if f.LastStatus != VerifyingData {
+		return
+	}
+	f.ParentID = f.chassisRF.ID
+	if !xnametypes.IsHMSCompIDValid(f.ParentID) {
+		errlog.Printf("Error: Bad parent xname ID ('%s') for: %s\n",
+			f.ParentID, f.FanURL)
+		f.LastStatus = VerificationFailed
+		return
+	}
+	f.Ordinal = f.RawOrdinal
+	f.FanID = f.FanRF.Id
+	if f.FanID == "" {
+		f.FanID = f.FanRF.MemberId
+	}
+	if f.FanID == "" {
+		f.FanID = strconv.Itoa(f.RawOrdinal)
+	}
+
+	if f.FanRF.Status.State != "Absent" {
+		f.Status = "Populated"
+		f.State = base.StatePopulated.String()
+		f.Flag = HealthFlag(f.FanRF.Status.Health)
+		generatedFRUID, err := GetFanFRUID(f)
+		if err != nil {
+			errlog.Printf("FRUID Error: %s\n", err.Error())
+			errlog.Printf("Using untrackable FRUID: %s\n", generatedFRUID)
+		}
+		f.FRUID = generatedFRUID
+	} else {
+		f.Status = "Empty"
+		f.State = base.StateEmpty.String()
+		//the state of the component is known (empty), it is not locked, does not have an alert or warning, so therefore Flag defaults to OK.
+		f.Flag = base.FlagOK.String()
+	}
+	if rfVerbose > 0 {
+		jout, _ := json.MarshalIndent(f, "", "   ")
+		errlog.Printf("%s\n", jout)
+		errlog.Printf("Fan %s/%s FRUID: %s\n", f.ParentID, f.FanID, f.FRUID)
+	}
+	f.LastStatus = DiscoverOK
+}
+
+// Build FRUID using standard fields: <Type>.<Manufacturer>.<PartNumber>.<SerialNumber>
+// else return an error.
+func GetFanFRUID(f *EpFan) (fruid string, err error) {
+	return getStandardFRUID(f.Type, f.ParentID+"-"+f.FanID,
+		f.FanRF.Manufacturer, f.FanRF.PartNumber, f.FanRF.SerialNumber)
+}
@@ -0,0 +1,212 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package rf
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	base "github.com/Cray-HPE/hms-base/v2"
+)
+
+////////////////////////////////////////////////////////////////////////////
+//
+//  Thermal and ThermalSubsystem Fans
+//
+///////////////////////////////////////////////////////////////////////////
+
+const testPathThermalSubsystem = "/redfish/v1/Chassis/Enclosure/ThermalSubsystem"
+const testPathThermalSubsystemFans = "/redfish/v1/Chassis/Enclosure/ThermalSubsystem/Fans"
+const testPathThermalSubsystemFan1 = "/redfish/v1/Chassis/Enclosure/ThermalSubsystem/Fans/Fan1"
+const testPathThermalSubsystemFan2 = "/redfish/v1/Chassis/Enclosure/ThermalSubsystem/Fans/Fan2"
+const testPathThermal = "/redfish/v1/Chassis/Self/Thermal"
+
+var testPayloadsThermal = map[string]string{
+	testPathThermalSubsystem: `{
+		"@odata.id": "/redfish/v1/Chassis/Enclosure/ThermalSubsystem",
+		"Id": "ThermalSubsystem",
+		"Fans": {"@odata.id": "/redfish/v1/Chassis/Enclosure/ThermalSubsystem/Fans"}
+	}`,
+	testPathThermalSubsystemFans: `{"Members": [
+		{"@odata.id": "/redfish/v1/Chassis/Enclosure/ThermalSubsystem/Fans/Fan2"},
+		{"@odata.id": "/redfish/v1/Chassis/Enclosure/ThermalSubsystem/Fans/Fan1"}
+	], "Members@odata.count": 2}`,
+	testPathThermalSubsystemFan1: `{
+		"@odata.id": "/redfish/v1/Chassis/Enclosure/ThermalSubsystem/Fans/Fan1",
+		"Id": "Fan1",
+		"Name": "Fan Tray 1",
+		"Manufacturer": "Delta",
+		"PartNumber": "FT-100",
+		"SerialNumber": "FAN1SN",
+		"Location": {"PartLocation": {"ServiceLabel": "FT1", "LocationType": "Bay"}},
+		"SpeedPercent": {"Reading": 45, "SpeedRPM": 9000},
+		"Status": {"State": "Enabled", "Health": "OK"}
+	}`,
+	testPathThermalSubsystemFan2: `{
+		"@odata.id": "/redfish/v1/Chassis/Enclosure/ThermalSubsystem/Fans/Fan2",
+		"Id": "Fan2",
+		"Name": "Fan Tray 2",
+		"Manufacturer": "Delta",
+		"PartNumber": "FT-100",
+		"SerialNumber": "FAN2SN",
+		"Status": {"State": "Enabled", "Health": "Warning"}
+	}`,
+	testPathThermal: `{
+		"@odata.id": "/redfish/v1/Chassis/Self/Thermal",
+		"Id": "Thermal",
+		"Fans": [{
+			"MemberId": "0",
+			"Name": "System Fan 1",
+			"PhysicalContext": "SystemBoard",
+			"Manufacturer": "Nidec",
+			"SerialNumber": "SYSFAN1SN",
+			"Reading": 4200,
+			"ReadingUnits": "RPM",
+			"Status": {"State": "Enabled", "Health": "Critical"}
+		}, {
+			"MemberId": "1",
+			"Name": "System Fan 2",
+			"Status": {"State": "Absent"}
+		}],
+		"Fans@odata.count": 2
+	}`,
+}
+
+func NewRTFuncThermal() RTFunc {
+	return func(req *http.Request) *http.Response {
+		for path, payload := range testPayloadsThermal {
+			if req.URL.String() == "https://"+testFQDN+path {
+				return &http.Response{
+					StatusCode: 200,
+					// Send mock response for rpath
+					Body: ioutil.NopCloser(bytes.NewBufferString(payload)),
+					// Header must always be non-nil or it will cause a panic.
+					Header: make(http.Header),
+				}
+			}
+		}
+		return &http.Response{
+			StatusCode: 404,
+			Body:       ioutil.NopCloser(bytes.NewBufferString("")),
+			Header:     make(http.Header),
+		}
+	}
+}
+
+func TestFanDiscovery(t *testing.T) {
+	ep := TestRedfishEPInitIntel
+	ep.client = NewTestClient(NewRTFuncThermal())
+
+	tests := []struct {
+		chassisOID string
+		thermal    string
+		subsystem  string
+		fans       map[string]string // FanID -> FRUID
+		empty      map[string]bool   // FanID -> absent
+		flag       string
+		fanFlags   map[string]string
+	}{{
+		// ThermalSubsystem is preferred and fans are read individually.
+		chassisOID: "/redfish/v1/Chassis/Enclosure",
+		thermal:    "/redfish/v1/Chassis/Enclosure/Thermal",
+		subsystem:  testPathThermalSubsystem,
+		fans: map[string]string{
+			"Fan1": "Fan.Delta.FT100.FAN1SN",
+			"Fan2": "Fan.Delta.FT100.FAN2SN",
+		},
+		flag: base.FlagWarning.String(),
+		fanFlags: map[string]string{
+			"Fan1": base.FlagOK.String(),
+			"Fan2": base.FlagWarning.String(),
+		},
+	}, {
+		// Legacy Thermal fans are embedded and have no odata.id
+		chassisOID: "/redfish/v1/Chassis/Self",
+		thermal:    testPathThermal,
+		fans: map[string]string{
+			"0": "Fan.Nidec.SYSFAN1SN",
+			"1": "",
+		},
+		empty: map[string]bool{"1": true},
+		flag:  base.FlagAlert.String(),
+		fanFlags: map[string]string{
+			"0": base.FlagAlert.String(),
+			"1": base.FlagOK.String(),
+		},
+	}}
+	for i, test := range tests {
+		c := NewEpChassis(&ep, ResourceID{Oid: test.chassisOID}, 0)
+		c.ChassisRF.Thermal.Oid = test.thermal
+		c.ChassisRF.ThermalSubsystem.Oid = test.subsystem
+		c.ChassisRF.PowerState = POWER_STATE_ON
+		c.ChassisRF.Status = StatusRF{State: "Enabled", Health: "OK"}
+		c.discoverFans()
+
+		c.ID = testXName
+		c.Type = "Chassis"
+		c.discoverComponentState()
+		if err := c.Fans.discoverLocalPhase2(); err != nil {
+			t.Errorf("Test %d Failed: Fans discoverLocalPhase2: %s", i, err)
+		}
+		c.discoverHealthRollup()
+		if c.Flag != test.flag {
+			t.Errorf("Test %d Failed: Expected chassis flag '%s', got '%s'",
+				i, test.flag, c.Flag)
+		}
+		// Only the fan that raised the flag should be a contributor.
+		if len(c.HealthContributors) != 1 ||
+			c.HealthContributors[0].Flag != test.flag {
+			t.Errorf("Test %d Failed: Expected one %s contributor, got %v",
+				i, test.flag, c.HealthContributors)
+		}
+		if c.Fans.Num != len(test.fans) {
+			t.Errorf("Test %d Failed: Expected %d fans, got %d",
+				i, len(test.fans), c.Fans.Num)
+		}
+		for _, f := range c.Fans.OIDs {
+			fruID, ok := test.fans[f.FanID]
+			if !ok {
+				t.Errorf("Test %d Failed: Unexpected fan '%s'", i, f.FanID)
+				continue
+			}
+			if f.LastStatus != DiscoverOK || f.ParentID != testXName {
+				t.Errorf("Test %d Failed: Fan %s: got status '%s', parent '%s'",
+					i, f.FanID, f.LastStatus, f.ParentID)
+			}
+			if test.empty[f.FanID] {
+				if f.Status != "Empty" {
+					t.Errorf("Test %d Failed: Fan %s should be Empty", i, f.FanID)
+				}
+			} else if f.FRUID != fruID {
+				t.Errorf("Test %d Failed: Fan %s: Expected FRUID '%s', got '%s'",
+					i, f.FanID, fruID, f.FRUID)
+			}
+			if f.Flag != test.fanFlags[f.FanID] {
+				t.Errorf("Test %d Failed: Fan %s: Expected flag '%s', got '%s'",
+					i, f.FanID, test.fanFlags[f.FanID], f.Flag)
+			}
+		}
+	}
+}
@@ -209,6 +209,15 @@ type EpChassis struct {
 	Power         *EpPower        `json:"Power"`
 	PowerSupplies EpPowerSupplies `json:"PowerSupplies"`
 
+	// Fans from the chassis' ThermalSubsystem or Thermal object.
+	Fans EpFans `json:"Fans"`
+
+	// Chassis and subcomponents whose health raised Flag above OK.
+	HealthContributors HealthContributors `json:"HealthContributors,omitempty"`
+
+	// xname from the last full discovery when only this chassis is re-read.
+	subtreeID string
+
 	epRF *RedfishEP // Backpointer, for connection details, etc.
 }
 
@@ -373,6 +382,9 @@ func (c *EpChassis) discoverRemotePhase1() {
 
 	}
 
+	// Fans are not required, so any problems here are not fatal.
+	c.discoverFans()
+
 	c.LastStatus = VerifyingData
 	if rfVerbose > 0 {
 		jout, _ := json.MarshalIndent(c, "", "   ")
@@ -416,17 +428,25 @@ func (c *EpChassis) discoverLocalPhase2() {
 	if c.LastStatus != VerifyingData {
 		return
 	}
-	// There may be chassis types that are not supported.
-	c.Type = c.epRF.getChassisHMSType(c)
-	if c.Type == xnametypes.HMSTypeInvalid.String() {
-		c.LastStatus = RedfishSubtypeNoSupport
-		return
-	}
-	c.Ordinal = c.epRF.getChassisOrdinal(c)
-	c.ID = c.epRF.getChassisHMSID(c, c.Type, c.Ordinal)
-	if c.ID == "" {
-		c.LastStatus = RedfishSubtypeNoSupport
-		return
+	if c.subtreeID != "" {
+		// Only this chassis was re-read so there is nothing to compare it
+		// with.  Keep what the last full discovery found.
+		c.Type = xnametypes.GetHMSType(c.subtreeID).String()
+		c.Ordinal = c.RawOrdinal
+		c.ID = c.subtreeID
+	} else {
+		// There may be chassis types that are not supported.
+		c.Type = c.epRF.getChassisHMSType(c)
+		if c.Type == xnametypes.HMSTypeInvalid.String() {
+			c.LastStatus = RedfishSubtypeNoSupport
+			return
+		}
+		c.Ordinal = c.epRF.getChassisOrdinal(c)
+		c.ID = c.epRF.getChassisHMSID(c, c.Type, c.Ordinal)
+		if c.ID == "" {
+			c.LastStatus = RedfishSubtypeNoSupport
+			return
+		}
 	}
 	c.Name = c.ChassisRF.Name
 
@@ -456,6 +476,12 @@ func (c *EpChassis) discoverLocalPhase2() {
 		fmt.Printf("c.PowerSupplies.discoverLocalPhase2(): returned err %v", err)
 		childStatus = ChildVerificationFailed
 	}
+	// Fans are optional, so errors are logged but not propagated.
+	if err := c.Fans.discoverLocalPhase2(); err != nil {
+		errlog.Printf("c.Fans.discoverLocalPhase2(): returned err %v", err)
+	}
+	// Needs the health of the power supplies and fans.
+	c.discoverHealthRollup()
 
 	c.LastStatus = childStatus
 }
@@ -564,6 +590,9 @@ type EpManager struct {
 	// reference these via the epRF pointer.
 	ENetInterfaces EpEthInterfaces `json:"enetInterfaces"`
 
+	// Manager whose health raised Flag above OK, if any.
+	HealthContributors HealthContributors `json:"HealthContributors,omitempty"`
+
 	epRF *RedfishEP // Backpointer, for connection details, etc.
 }
 
@@ -775,6 +804,7 @@ func (m *EpManager) discoverLocalPhase2() {
 
 	// Sets up HMS state fields using Status/State/Health info from Redfish
 	m.discoverComponentState()
+	m.discoverHealthRollup()
 
 	// TODO: actually discover these
 	m.Arch = base.ArchX86.String()
@@ -1003,6 +1033,17 @@ type EpSystem struct {
 	StorageGroups EpStorageCollections `json:"storageGroups"`
 	Drives        EpDrives             `json:"drives"`
 
+	// PCIeDevices are listed under the system (older schemas) or its
+	// chassis.  Only those not already found via one of the above are kept
+	// as components.
+	PCIeDevices EpPCIeDevices `json:"PCIeDevices"`
+
+	// System and subcomponents whose health raised Flag above OK.
+	HealthContributors HealthContributors `json:"HealthContributors,omitempty"`
+
+	// xname from the last full discovery when only this system is re-read.
+	subtreeID string
+
 	epRF *RedfishEP // Backpointer, for connection details, Chassis maps, etc.
 }
 
@@ -1786,6 +1827,47 @@ func (s *EpSystem) discoverRemotePhase1() {
 		s.Drives.discoverRemotePhase1()
 	}
 
+	//
+	// Get PCIeDevices, from the system if listed there, else the chassis.
+	// These are optional, so failures don't fail discovery of the system.
+	//
+
+	s.PCIeDevices.Num = 0
+	s.PCIeDevices.OIDs = make(map[string]*EpPCIeDevice)
+	pcieOIDs := s.SystemRF.PCIeDevices
+	pcieParentOID, pcieParentType := s.OdataID, s.RedfishType
+	if len(pcieOIDs) == 0 && nodeChassis != nil &&
+		nodeChassis.ChassisRF.PCIeDevices.Oid != "" {
+
+		path = nodeChassis.ChassisRF.PCIeDevices.Oid
+		url = s.epRF.FQDN + path
+		pcieJSON, err := s.epRF.GETRelative(path)
+		if err != nil || pcieJSON == nil {
+			errlog.Printf("%s: Failed to get PCIeDevices: %s\n", url, err)
+		} else {
+			if rfDebug > 0 {
+				errlog.Printf("%s: %s\n", url, pcieJSON)
+			}
+			var pcieInfo PCIeDeviceCollection
+			if err := json.Unmarshal(pcieJSON, &pcieInfo); err != nil {
+				errlog.Printf("Failed to decode %s: %s\n", url, err)
+			} else {
+				pcieOIDs = pcieInfo.Members
+				pcieParentOID = nodeChassis.OdataID
+				pcieParentType = nodeChassis.RedfishType
+			}
+		}
+	}
+	if len(pcieOIDs) > 0 {
+		sort.Sort(ResourceIDSlice(pcieOIDs))
+		for i, doid := range pcieOIDs {
+			did := doid.Basename()
+			s.PCIeDevices.OIDs[did] = NewEpPCIeDevice(s, pcieParentOID, pcieParentType, doid, i)
+		}
+		s.PCIeDevices.Num = len(s.PCIeDevices.OIDs)
+		s.PCIeDevices.discoverRemotePhase1()
+	}
+
 	if rfVerbose > 0 {
 		jout, _ := json.MarshalIndent(s, "", "   ")
 		errlog.Printf("%s: %s\n", topURL, jout)
@@ -1834,7 +1916,13 @@ func (s *EpSystem) discoverLocalPhase2() {
 	// that seem consistent with the HMS notion of a node, and
 	// we don't know how or if the other types will be used at higher
 	// levels.)
-	s.Ordinal, s.Type = s.epRF.getSystemOrdinalAndType(s)
+	if s.subtreeID != "" {
+		// Only this system was re-read so there is nothing to compare it
+		// with.  Keep what the last full discovery found.
+		s.Ordinal, s.Type = s.RawOrdinal, xnametypes.Node.String()
+	} else {
+		s.Ordinal, s.Type = s.epRF.getSystemOrdinalAndType(s)
+	}
 	if s.Ordinal == -1 || s.Type == "" {
 		errlog.Printf("%s: Unsupported RF type '%s'",
 			s.epRF.ID, s.SystemRF.SystemType)
@@ -1895,11 +1983,19 @@ func (s *EpSystem) discoverLocalPhase2() {
 		fmt.Printf("s.HpeDevices.discoverLocalPhase2(): returned err %v", err)
 		childStatus = ChildVerificationFailed
 	}
+	// Must come last since it skips devices already found above.
+	if err := s.PCIeDevices.discoverLocalPhase2(); err != nil {
+		fmt.Printf("s.PCIeDevices.discoverLocalPhase2(): returned err %v", err)
+		childStatus = ChildVerificationFailed
+	}
 
 	// GetSystemArch() requires the processor Arch information detected by
 	// Processors.discoverLocalPhase2().
 	s.Arch = GetSystemArch(s)
 
+	// Roll up subcomponent health into the system flag.
+	s.discoverHealthRollup()
+
 	s.LastStatus = childStatus
 }
 
@@ -2361,7 +2457,7 @@ func (p *EpProcessor) discoverLocalPhase2() {
 	if p.ProcessorRF.Status.State != "Absent" {
 		p.Status = "Populated"
 		p.State = base.StatePopulated.String()
-		p.Flag = base.FlagOK.String()
+		p.Flag = HealthFlag(p.ProcessorRF.Status.Health)
 		if p.ProcessorRF.SerialNumber == "" {
 			//look for special case GBTProcessorOemProperty
 			if p.ProcessorRF.Oem != nil {
@@ -2568,7 +2664,7 @@ func (m *EpMemory) discoverLocalPhase2() {
 	if m.MemoryRF.Status.State != "Absent" {
 		m.Status = "Populated"
 		m.State = base.StatePopulated.String()
-		m.Flag = base.FlagOK.String()
+		m.Flag = HealthFlag(m.MemoryRF.Status.Health)
 		generatedFRUID, err := GetMemoryFRUID(m)
 		if err != nil {
 			errlog.Printf("FRUID Error: %s\n", err.Error())
@@ -87,12 +87,17 @@ const (
 	OutletType            = "Outlet"
 	PDUType               = "PowerDistribution"
 	NetworkAdapterType    = "NetworkAdapter"
+	PCIeDeviceType        = "PCIeDevice"
+	ThermalType           = "Thermal"
+	ThermalSubsystemType  = "ThermalSubsystem"
+	FanType               = "Fan"
 	AccountServiceType    = "AccountService"
 	EventServiceType      = "EventService"
 	LogServiceType        = "LogService"
 	SessionServiceType    = "SessionService"
 	TaskServiceType       = "TaskService"
 	UpdateServiceType     = "UpdateService"
+	SoftwareInventoryType = "SoftwareInventory"
 )
 
 // Redfish object subtypes, i.e. {type-name}Type,
@@ -159,6 +164,7 @@ const MaxFanout int = 1000
 var ErrRFDiscFQDNMissing = errors.New("FQDN unexpectedly empty string")
 var ErrRFDiscURLNotFound = errors.New("URL request returned 404: Not Found")
 var ErrRFDiscILOLicenseReq = errors.New("iLO License Required")
+var ErrRFDiscSubtreeID = errors.New("xname does not belong to endpoint")
 
 /////////////////////////////////////////////////////////////////////////////
 //
@@ -756,6 +762,66 @@ func (ep *RedfishEP) GETRelative(rpath string, optionalArgs ...int) (json.RawMes
 	return jsonBody, nil
 }
 
+// POST body to the given rpath relative to the redfish hostname of the
+// given endpoint, as for GETRelative.  It is not retried as the request may
+// not be idempotent, e.g. the creation of an event subscription.  Returns
+// the response body, if any, and the Location header, which gives the
+// URI of a resource that was created.
+func (ep *RedfishEP) POSTRelative(rpath string, body []byte) (json.RawMessage, string, error) {
+	return ep.sendRelative("POST", rpath, body)
+}
+
+// DELETE the resource at the given rpath relative to the redfish hostname
+// of the given endpoint, as for GETRelative.
+func (ep *RedfishEP) DELETERelative(rpath string) error {
+	_, _, err := ep.sendRelative("DELETE", rpath, nil)
+	return err
+}
+
+// Back end for requests that change things on the endpoint.  Any 2xx
+// response is a success.
+func (ep *RedfishEP) sendRelative(method, rpath string, body []byte) (json.RawMessage, string, error) {
+	var path string = "https://" + ep.FQDN + strings.Replace(rpath, "#", "%23", -1)
+
+	if ep.FQDN == "" {
+		errlog.Printf("Can't HTTP %s (%s): FQDN is empty", method, path)
+		return nil, "", ErrRFDiscFQDNMissing
+	}
+	req, err := http.NewRequest(method, path, bytes.NewReader(body))
+	if err != nil {
+		errlog.Printf("Error forming new request for (%s) %s", path, err)
+		return nil, "", err
+	}
+	req.SetBasicAuth(ep.User, ep.Password)
+	req.Header.Set("Accept", "*/*")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Close = true
+
+	rsp, err := ep.client.Do(req)
+	if err != nil {
+		base.DrainAndCloseResponseBody(rsp)
+		errlog.Printf("%s (%s) ERROR: %s", method, path, err)
+		return nil, "", err
+	}
+	var rspBody []byte
+	if rsp.Body != nil {
+		rspBody, _ = ioutil.ReadAll(rsp.Body)
+	}
+	base.DrainAndCloseResponseBody(rsp)
+
+	if rsp.StatusCode < 200 || rsp.StatusCode > 299 {
+		rerr := fmt.Errorf("%s", http.StatusText(rsp.StatusCode))
+		errlog.Printf("%s (%s) Bad rsp: %s: %s", method, path, rerr, rspBody)
+		if rsp.StatusCode == http.StatusNotFound {
+			return nil, "", ErrRFDiscURLNotFound
+		}
+		return nil, "", rerr
+	}
+	return json.RawMessage(rspBody), rsp.Header.Get("Location"), nil
+}
+
 // Loop through all endpoints to get top-level information, i.e.
 // how many systems, etc.  and initalize these structures so they
 // can be discovered in more detail.
@@ -1076,6 +1142,12 @@ func (ep *RedfishEP) GetRootInfo() {
 		errlog.Printf("ERROR: Systems verification failed: %s", err)
 		childStatus = ChildVerificationFailed
 	}
+	// Firmware/software targets are tied back to the xnames of the
+	// components discovered above, so this must come after everything else.
+	// Problems here are not fatal to discovery as a whole.
+	if ep.UpdateService != nil {
+		ep.UpdateService.discoverLocalPhase2()
+	}
 	ep.DiscInfo.UpdateLastStatusWithTS(childStatus)
 }
 
@@ -1142,6 +1214,103 @@ func (ep *RedfishEP) VerifySystems() error {
 	return ep.Systems.discoverLocalPhase2()
 }
 
+// Re-read just the System at oid and everything under it, e.g. after a FRU
+// in it was added or removed, without rediscovering the rest of the
+// endpoint.  A System's xname and ordinal depend on the others under the
+// endpoint, so those from the last full discovery are given and kept.
+func (ep *RedfishEP) GetSystemSubtree(oid, xname string, ordinal int) (*EpSystem, error) {
+	if xnametypes.GetHMSType(xname) != xnametypes.Node ||
+		xname != ep.ID+"n"+strconv.Itoa(ordinal) {
+		return nil, ErrRFDiscSubtreeID
+	}
+	s := NewEpSystem(ep, ResourceID{oid}, ordinal)
+	s.subtreeID = xname
+	ep.NumSystems = 1
+	ep.Systems.Num = 1
+	ep.Systems.OIDs = map[string]*EpSystem{s.BaseOdataID: s}
+
+	s.discoverRemotePhase1()
+	s.discoverLocalPhase2()
+	// Anything short of a complete read would make missing children look
+	// removed.
+	if s.LastStatus != DiscoverOK {
+		return nil, fmt.Errorf("%s: %s", s.OdataID, s.LastStatus)
+	}
+	return s, nil
+}
+
+// Re-read just the Chassis at oid and its power supplies and fans, as for
+// GetSystemSubtree.
+func (ep *RedfishEP) GetChassisSubtree(oid, xname string, ordinal int) (*EpChassis, error) {
+	if !strings.HasPrefix(xname, xnametypes.GetHMSCompParent(ep.ID)) {
+		return nil, ErrRFDiscSubtreeID
+	}
+	c := NewEpChassis(ep, ResourceID{oid}, ordinal)
+	c.subtreeID = xname
+	ep.NumChassis = 1
+	ep.Chassis.Num = 1
+	ep.Chassis.OIDs = map[string]*EpChassis{c.BaseOdataID: c}
+
+	c.discoverRemotePhase1()
+	c.discoverLocalPhase2()
+	if c.LastStatus != DiscoverOK {
+		return nil, fmt.Errorf("%s: %s", c.OdataID, c.LastStatus)
+	}
+	return c, nil
+}
+
+// Build a map of the odata.ids of every component under the endpoint that
+// was given an xname during phase2 discovery.  This lets other resources
+// that link to these components (e.g. via RelatedItem) find their xnames.
+func (ep *RedfishEP) getComponentOIDMap() map[string]*ComponentDescription {
+	oidMap := make(map[string]*ComponentDescription)
+	add := func(cd *ComponentDescription) {
+		if cd.ID != "" && cd.OdataID != "" {
+			oidMap[normalizeOID(cd.OdataID)] = cd
+		}
+	}
+	for _, c := range ep.Chassis.OIDs {
+		add(&c.ComponentDescription)
+		for _, ps := range c.PowerSupplies.OIDs {
+			add(&ps.ComponentDescription)
+		}
+	}
+	for _, m := range ep.Managers.OIDs {
+		add(&m.ComponentDescription)
+	}
+	for _, pdu := range ep.RackPDUs.OIDs {
+		add(&pdu.ComponentDescription)
+		for _, o := range pdu.Outlets.OIDs {
+			add(&o.ComponentDescription)
+		}
+	}
+	for _, s := range ep.Systems.OIDs {
+		add(&s.ComponentDescription)
+		for _, p := range s.Processors.OIDs {
+			add(&p.ComponentDescription)
+		}
+		for _, m := range s.MemoryMods.OIDs {
+			add(&m.ComponentDescription)
+		}
+		for _, d := range s.Drives.OIDs {
+			add(&d.ComponentDescription)
+		}
+		for _, r := range s.NodeAccelRisers.OIDs {
+			add(&r.ComponentDescription)
+		}
+		for _, na := range s.NetworkAdapters.OIDs {
+			add(&na.ComponentDescription)
+		}
+		for _, hd := range s.HpeDevices.OIDs {
+			add(&hd.ComponentDescription)
+		}
+		for _, pd := range s.PCIeDevices.OIDs {
+			add(&pd.ComponentDescription)
+		}
+	}
+	return oidMap
+}
+
 // Checks any fields that must be set in order to properly query the endpoint
 // and organize and name subcomponents afterwards.
 func (ep *RedfishEP) CheckPrePhase1() error {
@@ -1534,7 +1703,8 @@ func (ep *RedfishEP) getPowerSupplyHMSID(p *EpPowerSupply, hmsType string, ordin
 // special here, namely, "skip it"/"not supported".
 // Post phase 1 discovery.
 func (ep *RedfishEP) getPowerSupplyHMSType(p *EpPowerSupply) string {
-	parentChassisType := ep.getChassisHMSType(p.chassisRF)
+	// Set during the parent chassis' phase 2, before its power supplies.
+	parentChassisType := p.chassisRF.Type
 	if parentChassisType == xnametypes.NodeEnclosure.String() {
 		return xnametypes.NodeEnclosurePowerSupply.String()
 	}
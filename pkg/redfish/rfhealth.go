@@ -0,0 +1,210 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package rf
+
+import (
+	"strings"
+
+	base "github.com/Cray-HPE/hms-base/v2"
+)
+
+/////////////////////////////////////////////////////////////////////////////
+//
+// Health rollup
+//
+// The Flag of a top-level component (node, chassis, controller) is computed
+// from its own Redfish Status.Health plus that of the subcomponents found
+// under it, e.g. a failed DIMM or degraded power supply.  Each source that
+// raised the flag above OK is recorded as a HealthContributor so the reason
+// for the flag can be explained later on.
+//
+/////////////////////////////////////////////////////////////////////////////
+
+// A component or subcomponent whose Redfish health raised the Flag of a
+// top-level component.
+type HealthContributor struct {
+	ID           string `json:"ID"`   // xname, or Redfish Id if none
+	Type         string `json:"Type"` // HMS type, or Redfish type if none
+	OdataID      string `json:"OdataID"`
+	Health       string `json:"Health"`
+	HealthRollUp string `json:"HealthRollUp,omitempty"`
+	State        string `json:"State,omitempty"`
+	Flag         string `json:"Flag"`
+}
+
+// Set of HealthContributors for a single top-level component.
+type HealthContributors []*HealthContributor
+
+// Records the given Redfish Status as a contributor if its Health implies
+// a flag other than OK.  Absent components never contribute.
+func (hcs *HealthContributors) add(id, hmsType, odataID string, status *StatusRF) {
+	if status == nil || status.State == "Absent" {
+		return
+	}
+	flag := HealthFlag(status.Health)
+	if flag == base.FlagOK.String() {
+		return
+	}
+	hc := &HealthContributor{
+		ID:           id,
+		Type:         hmsType,
+		OdataID:      odataID,
+		Health:       string(status.Health),
+		HealthRollUp: string(status.HealthRollUp),
+		State:        string(status.State),
+		Flag:         flag,
+	}
+	*hcs = append(*hcs, hc)
+}
+
+// Returns the most severe flag among the contributors, or OK if there
+// are none.
+func (hcs HealthContributors) GetFlag() string {
+	flag := base.FlagOK.String()
+	for _, hc := range hcs {
+		flag = WorstFlag(flag, hc.Flag)
+	}
+	return flag
+}
+
+// Maps a Redfish Health (or event Severity) value to the HMS flag it
+// implies.
+func HealthFlag(health HealthRF) string {
+	switch strings.ToLower(string(health)) {
+	case "warning":
+		return base.FlagWarning.String()
+	case "critical":
+		return base.FlagAlert.String()
+	}
+	return base.FlagOK.String()
+}
+
+// Returns the more severe of two HMS flags, i.e. Alert > Warning > OK.
+func WorstFlag(a, b string) string {
+	rank := func(flag string) int {
+		switch base.VerifyNormalizeFlag(flag) {
+		case base.FlagAlert.String():
+			return 2
+		case base.FlagWarning.String():
+			return 1
+		}
+		return 0
+	}
+	if rank(b) > rank(a) {
+		return b
+	}
+	return a
+}
+
+// Collects the subcomponents of the chassis whose health raised its flag,
+// i.e. power supplies and fans, along with the chassis itself.  Must be
+// called after the subcomponents have completed discoverLocalPhase2.
+func (c *EpChassis) discoverHealthRollup() {
+	c.HealthContributors = nil
+	// Flags are not set on components without tracked state, see
+	// discoverComponentState.
+	if c.State == base.StateEmpty.String() ||
+		c.State == base.StatePopulated.String() {
+		return
+	}
+	c.HealthContributors.add(c.ID, c.Type, c.OdataID, &c.ChassisRF.Status)
+	c.HealthContributors.addChassisChildren(c)
+	c.Flag = WorstFlag(c.Flag, c.HealthContributors.GetFlag())
+}
+
+// Adds the power supplies and fans of the given chassis.
+func (hcs *HealthContributors) addChassisChildren(c *EpChassis) {
+	for _, p := range c.PowerSupplies.OIDs {
+		if p.LastStatus == DiscoverOK && p.PowerSupplyRF != nil {
+			hcs.add(p.ID, p.Type, p.OdataID, &p.PowerSupplyRF.Status)
+		}
+	}
+	for _, f := range c.Fans.OIDs {
+		if f.LastStatus == DiscoverOK && f.FanRF != nil {
+			hcs.add(f.FanID, f.Type, f.OdataID, &f.FanRF.Status)
+		}
+	}
+}
+
+// Collects the subcomponents of the system whose health raised its flag,
+// along with the system itself.  Power supplies and fans come from the
+// chassis of the same name, if any.  Must be called after the
+// subcomponents have completed discoverLocalPhase2.
+func (s *EpSystem) discoverHealthRollup() {
+	s.HealthContributors = nil
+	if s.State == base.StateEmpty.String() {
+		return
+	}
+	hcs := &s.HealthContributors
+	hcs.add(s.ID, s.Type, s.OdataID, &s.SystemRF.Status)
+	for _, p := range s.Processors.OIDs {
+		if p.LastStatus == DiscoverOK {
+			hcs.add(p.ID, p.Type, p.OdataID, &p.ProcessorRF.Status)
+		}
+	}
+	for _, m := range s.MemoryMods.OIDs {
+		if m.LastStatus == DiscoverOK {
+			hcs.add(m.ID, m.Type, m.OdataID, &m.MemoryRF.Status)
+		}
+	}
+	for _, d := range s.Drives.OIDs {
+		if d.LastStatus == DiscoverOK {
+			hcs.add(d.ID, d.Type, d.OdataID, &d.DriveRF.Status)
+		}
+	}
+	for _, r := range s.NodeAccelRisers.OIDs {
+		if r.LastStatus == DiscoverOK && r.NodeAccelRiserRF != nil {
+			hcs.add(r.ID, r.Type, r.OdataID, &r.NodeAccelRiserRF.Status)
+		}
+	}
+	for _, na := range s.NetworkAdapters.OIDs {
+		if na.LastStatus == DiscoverOK && na.NetworkAdapterRF != nil {
+			hcs.add(na.ID, na.Type, na.OdataID, na.NetworkAdapterRF.Status)
+		}
+	}
+	for _, d := range s.HpeDevices.OIDs {
+		if d.LastStatus == DiscoverOK {
+			hcs.add(d.ID, d.Type, d.OdataID, &d.DeviceRF.Status)
+		}
+	}
+	for _, d := range s.PCIeDevices.OIDs {
+		if d.LastStatus == DiscoverOK {
+			hcs.add(d.ID, d.Type, d.OdataID, d.PCIeDeviceRF.Status)
+		}
+	}
+	if nodeChassis, ok := s.epRF.Chassis.OIDs[s.SystemRF.Id]; ok {
+		hcs.addChassisChildren(nodeChassis)
+	}
+	s.Flag = WorstFlag(s.Flag, hcs.GetFlag())
+}
+
+// Records the manager itself as a contributor if its health raised its
+// flag.  Managers have no subcomponents that report health separately.
+func (m *EpManager) discoverHealthRollup() {
+	m.HealthContributors = nil
+	if m.State == base.StateEmpty.String() ||
+		m.State == base.StatePopulated.String() {
+		return
+	}
+	m.HealthContributors.add(m.ID, m.Type, m.OdataID, &m.ManagerRF.Status)
+}
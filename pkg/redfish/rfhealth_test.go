@@ -0,0 +1,188 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package rf
+
+import (
+	"testing"
+
+	base "github.com/Cray-HPE/hms-base/v2"
+)
+
+func TestHealthFlag(t *testing.T) {
+	tests := []struct {
+		health HealthRF
+		flag   string
+	}{
+		{"OK", base.FlagOK.String()},
+		{"", base.FlagOK.String()},
+		{"Warning", base.FlagWarning.String()},
+		{"critical", base.FlagAlert.String()},
+		{"Critical", base.FlagAlert.String()},
+	}
+	for i, test := range tests {
+		if flag := HealthFlag(test.health); flag != test.flag {
+			t.Errorf("Test %d Failed: Expected flag '%s' for '%s', got '%s'",
+				i, test.flag, test.health, flag)
+		}
+	}
+}
+
+func TestWorstFlag(t *testing.T) {
+	tests := []struct {
+		a, b, worst string
+	}{
+		{"OK", "OK", "OK"},
+		{"OK", "Warning", "Warning"},
+		{"Alert", "Warning", "Alert"},
+		{"Warning", "alert", "alert"},
+		{"Warning", "", "Warning"},
+	}
+	for i, test := range tests {
+		if worst := WorstFlag(test.a, test.b); worst != test.worst {
+			t.Errorf("Test %d Failed: Expected '%s' for '%s'/'%s', got '%s'",
+				i, test.worst, test.a, test.b, worst)
+		}
+	}
+}
+
+func TestSystemHealthRollup(t *testing.T) {
+	newMemory := func(id, oid string, status StatusRF) *EpMemory {
+		m := new(EpMemory)
+		m.ID = id
+		m.Type = "Memory"
+		m.OdataID = oid
+		m.LastStatus = DiscoverOK
+		m.MemoryRF.Status = status
+		return m
+	}
+	newProcessor := func(id, oid string, status StatusRF) *EpProcessor {
+		p := new(EpProcessor)
+		p.ID = id
+		p.Type = "Processor"
+		p.OdataID = oid
+		p.LastStatus = DiscoverOK
+		p.ProcessorRF.Status = status
+		return p
+	}
+	newPSU := func(id, oid string, status StatusRF) *EpPowerSupply {
+		p := new(EpPowerSupply)
+		p.ID = id
+		p.Type = "NodeEnclosurePowerSupply"
+		p.OdataID = oid
+		p.LastStatus = DiscoverOK
+		p.PowerSupplyRF = &PowerSupply{Status: status}
+		return p
+	}
+
+	tests := []struct {
+		sysStatus    StatusRF
+		memory       []*EpMemory
+		procs        []*EpProcessor
+		psus         []*EpPowerSupply
+		flag         string
+		contributors []string // OdataIDs
+	}{{
+		// Everything healthy.
+		sysStatus: StatusRF{State: "Enabled", Health: "OK"},
+		memory: []*EpMemory{
+			newMemory("x0c0s0b0n0d0", "/redfish/v1/Systems/1/Memory/1", StatusRF{State: "Enabled", Health: "OK"}),
+		},
+		flag:         base.FlagOK.String(),
+		contributors: []string{},
+	}, {
+		// Failed DIMM raises an alert, absent DIMM ignored.
+		sysStatus: StatusRF{State: "Enabled", Health: "OK", HealthRollUp: "Critical"},
+		memory: []*EpMemory{
+			newMemory("x0c0s0b0n0d0", "/redfish/v1/Systems/1/Memory/1", StatusRF{State: "Enabled", Health: "Critical"}),
+			newMemory("x0c0s0b0n0d1", "/redfish/v1/Systems/1/Memory/2", StatusRF{State: "Absent", Health: "Warning"}),
+		},
+		procs: []*EpProcessor{
+			newProcessor("x0c0s0b0n0p0", "/redfish/v1/Systems/1/Processors/1", StatusRF{State: "Enabled", Health: "Warning"}),
+		},
+		flag: base.FlagAlert.String(),
+		contributors: []string{
+			"/redfish/v1/Systems/1/Processors/1",
+			"/redfish/v1/Systems/1/Memory/1",
+		},
+	}, {
+		// Degraded PSU in the node's chassis plus system health.
+		sysStatus: StatusRF{State: "Enabled", Health: "Warning"},
+		psus: []*EpPowerSupply{
+			newPSU("x0c0s0e0t0", "/redfish/v1/Chassis/1/Power#/PowerSupplies/0", StatusRF{State: "Enabled", Health: "Warning"}),
+		},
+		flag: base.FlagWarning.String(),
+		contributors: []string{
+			"/redfish/v1/Systems/1",
+			"/redfish/v1/Chassis/1/Power#/PowerSupplies/0",
+		},
+	}}
+
+	for i, test := range tests {
+		ep := new(RedfishEP)
+		ch := new(EpChassis)
+		ch.PowerSupplies.OIDs = make(map[string]*EpPowerSupply)
+		for _, p := range test.psus {
+			ch.PowerSupplies.OIDs[p.OdataID] = p
+		}
+		ep.Chassis.OIDs = map[string]*EpChassis{"1": ch}
+
+		s := new(EpSystem)
+		s.epRF = ep
+		s.ID = "x0c0s0b0n0"
+		s.Type = "Node"
+		s.OdataID = "/redfish/v1/Systems/1"
+		s.SystemRF.Id = "1"
+		s.SystemRF.Status = test.sysStatus
+		s.State = base.StateOn.String()
+		s.Flag = HealthFlag(test.sysStatus.Health)
+		s.MemoryMods.OIDs = make(map[string]*EpMemory)
+		for _, m := range test.memory {
+			s.MemoryMods.OIDs[m.OdataID] = m
+		}
+		s.Processors.OIDs = make(map[string]*EpProcessor)
+		for _, p := range test.procs {
+			s.Processors.OIDs[p.OdataID] = p
+		}
+
+		s.discoverHealthRollup()
+		if s.Flag != test.flag {
+			t.Errorf("Test %d Failed: Expected flag '%s', got '%s'",
+				i, test.flag, s.Flag)
+		}
+		if s.HealthContributors.GetFlag() != test.flag {
+			t.Errorf("Test %d Failed: Expected contributor flag '%s', got '%s'",
+				i, test.flag, s.HealthContributors.GetFlag())
+		}
+		if len(s.HealthContributors) != len(test.contributors) {
+			t.Errorf("Test %d Failed: Expected %d contributors, got %d",
+				i, len(test.contributors), len(s.HealthContributors))
+			continue
+		}
+		for j, oid := range test.contributors {
+			if s.HealthContributors[j].OdataID != oid {
+				t.Errorf("Test %d Failed: Expected contributor %d '%s', got '%s'",
+					i, j, oid, s.HealthContributors[j].OdataID)
+			}
+		}
+	}
+}
@@ -25,9 +25,12 @@ package rf
 import (
 	//"bytes"
 	"encoding/json"
+	"fmt"
 	//"io/ioutil"
-	//"path"
-	//"strings"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
 	//"time"
 )
 
@@ -239,6 +242,98 @@ func (s *EpEventService) discoverRemotePhase1() {
 	}
 }
 
+// Get the EventService at oid on its own, e.g. to check the subscriptions
+// on an endpoint without rediscovering it.
+func (ep *RedfishEP) GetEventService(oid string) (*EpEventService, error) {
+	s := NewEpEventService(ep, oid)
+	s.discoverRemotePhase1()
+	if s.LastStatus != HTTPsGetOk {
+		return nil, fmt.Errorf("%s: %s", oid, s.LastStatus)
+	}
+	ep.EventService = s
+	return s, nil
+}
+
+// URI of the EventDestination collection.
+func (s *EpEventService) subscriptionsOID() string {
+	if s.EventServiceRF.Subscriptions.Oid != "" {
+		return s.EventServiceRF.Subscriptions.Oid
+	}
+	return strings.TrimSuffix(s.OdataID, "/") + "/Subscriptions"
+}
+
+// Get the current event subscriptions on the endpoint.
+func (s *EpEventService) GetSubscriptions() ([]*EventDestination, error) {
+	colJSON, err := s.epRF.GETRelative(s.subscriptionsOID())
+	if err != nil {
+		return nil, err
+	}
+	var col EventDestinationCollection
+	if err := json.Unmarshal(colJSON, &col); err != nil {
+		return nil, err
+	}
+	subs := make([]*EventDestination, 0, len(col.Members))
+	for _, m := range col.Members {
+		subJSON, err := s.epRF.GETRelative(m.Oid)
+		if err != nil {
+			return nil, err
+		}
+		sub := new(EventDestination)
+		if err := json.Unmarshal(subJSON, sub); err != nil {
+			if !IsUnmarshalTypeError(err) {
+				return nil, err
+			}
+			errlog.Printf("bad field(s) skipped: %s: %s\n", m.Oid, err)
+		}
+		if sub.Oid == "" {
+			sub.Oid = m.Oid
+		}
+		subs = append(subs, sub)
+	}
+	return subs, nil
+}
+
+// Subscribe dest to events of the given types, or all types if none are
+// given, tagging them with context.  Returns the URI of the new
+// EventDestination.
+func (s *EpEventService) Subscribe(dest, context string, eventTypes []string) (string, error) {
+	sub := struct {
+		Destination string   `json:"Destination"`
+		Context     string   `json:"Context"`
+		Protocol    string   `json:"Protocol"`
+		EventTypes  []string `json:"EventTypes,omitempty"`
+	}{
+		Destination: dest,
+		Context:     context,
+		Protocol:    "Redfish",
+		EventTypes:  eventTypes,
+	}
+	body, err := json.Marshal(sub)
+	if err != nil {
+		return "", err
+	}
+	rspJSON, loc, err := s.epRF.POSTRelative(s.subscriptionsOID(), body)
+	if err != nil {
+		return "", err
+	}
+	// The Location may be a full URL.
+	if loc != "" {
+		if u, err := url.Parse(loc); err == nil && u.Path != "" {
+			return u.Path, nil
+		}
+	}
+	var created EventDestination
+	if len(rspJSON) > 0 && json.Unmarshal(rspJSON, &created) == nil {
+		return created.Oid, nil
+	}
+	return "", nil
+}
+
+// Remove the subscription at oid.
+func (s *EpEventService) Unsubscribe(oid string) error {
+	return s.epRF.DELETERelative(oid)
+}
+
 // This is the TaskService for the corresponding RedfishEP
 type EpTaskService struct {
 	// Embedded struct: id, type, odataID and associated RfEndpointID.
@@ -318,6 +413,11 @@ type EpUpdateService struct {
 	UpdateServiceRF     UpdateService    `json:"updateServiceRF"`
 	updateServiceURLRaw *json.RawMessage // `json:"eventServiceURLRaw"`
 
+	// Targets listed in the FirmwareInventory and SoftwareInventory
+	// collections linked from the UpdateService.
+	FirmwareInventory EpSoftwareInventories `json:"firmwareInventory"`
+	SoftwareInventory EpSoftwareInventories `json:"softwareInventory"`
+
 	epRF *RedfishEP // Backpointer, for connection details, etc.
 }
 
@@ -365,4 +465,205 @@ func (s *EpUpdateService) discoverRemotePhase1() {
 		s.LastStatus = EPResponseFailedDecode
 		return
 	}
+
+	// Get the firmware and software targets, if the service links them.
+	// A missing or broken inventory collection doesn't invalidate the
+	// service itself.
+	if s.UpdateServiceRF.FirmwareInventory != nil {
+		s.FirmwareInventory.discoverRemotePhase1(s,
+			s.UpdateServiceRF.FirmwareInventory.Oid, FirmwareInventoryName)
+	}
+	if s.UpdateServiceRF.SoftwareInventory != nil {
+		s.SoftwareInventory.discoverRemotePhase1(s,
+			s.UpdateServiceRF.SoftwareInventory.Oid, SoftwareInventoryName)
+	}
+}
+
+// Phase2 discovery for the UpdateService.  Once all of the components
+// under the RedfishEP have their xnames, the firmware and software targets
+// are tied back to the components they apply to.
+func (s *EpUpdateService) discoverLocalPhase2() {
+	// Should never happen
+	if s.epRF == nil {
+		errlog.Printf("Error: RedfishEP == nil for UpdateService odataID: %s\n",
+			s.OdataID)
+		s.LastStatus = EndpointInvalid
+		return
+	}
+	oidMap := s.epRF.getComponentOIDMap()
+	s.FirmwareInventory.discoverLocalPhase2(oidMap)
+	s.SoftwareInventory.discoverLocalPhase2(oidMap)
+}
+
+/////////////////////////////////////////////////////////////////////////////
+// UpdateService - Firmware and Software inventory
+/////////////////////////////////////////////////////////////////////////////
+
+// Names of the UpdateService collections an EpSoftwareInventory came from.
+const (
+	FirmwareInventoryName = "FirmwareInventory"
+	SoftwareInventoryName = "SoftwareInventory"
+)
+
+// This is a single firmware or software target listed under the
+// UpdateService for the corresponding RedfishEP.
+type EpSoftwareInventory struct {
+	ID           string `json:"ID"` // Redfish Id of the target
+	RedfishType  string `json:"RedfishType"`
+	OdataID      string `json:"OdataID"`
+	RfEndpointID string `json:"RedfishEndpointID"`
+	Inventory    string `json:"Inventory"` // FirmwareInventory or SoftwareInventory
+
+	// xname and HMS type of the component the target applies to.  This is
+	// taken from the first RelatedItem we can match against a discovered
+	// component, or the RedfishEP itself if none can be matched.
+	RelatedID   string `json:"RelatedID"`
+	RelatedType string `json:"RelatedType"`
+
+	LastStatus string `json:"LastStatus"`
+
+	SoftwareInventoryRF SoftwareInventory `json:"SoftwareInventoryRF"`
+	softwareInvRaw      *json.RawMessage  //`json:"softwareInvRaw"`
+
+	epRF *RedfishEP // Backpointer, for connection details, etc.
+}
+
+// Set of EpSoftwareInventory, representing the members of a
+// FirmwareInventory or SoftwareInventory collection.
+type EpSoftwareInventories struct {
+	Num  int                             `json:"num"`
+	OIDs map[string]*EpSoftwareInventory `json:"oids"`
+}
+
+// Initializes EpSoftwareInventory struct with minimal information needed to
+// discover it, i.e. endpoint info and the odataID of the target to look at.
+func NewEpSoftwareInventory(epRF *RedfishEP, odataID ResourceID, inventory string) *EpSoftwareInventory {
+	si := new(EpSoftwareInventory)
+	si.ID = odataID.Basename()
+	si.RedfishType = SoftwareInventoryType
+	si.OdataID = odataID.Oid
+	si.RfEndpointID = epRF.ID
+	si.Inventory = inventory
+	si.LastStatus = NotYetQueried
+	si.epRF = epRF
+	return si
+}
+
+// Get the collection at oid and create and discover an EpSoftwareInventory
+// for each of its members.
+func (sis *EpSoftwareInventories) discoverRemotePhase1(s *EpUpdateService, oid, inventory string) {
+	sis.OIDs = make(map[string]*EpSoftwareInventory)
+	if oid == "" {
+		return
+	}
+	collJSON, err := s.epRF.GETRelative(oid)
+	if err != nil || collJSON == nil {
+		errlog.Printf("%s: Could not get %s: %s\n", s.epRF.ID, oid, err)
+		return
+	}
+	if rfDebug > 0 {
+		errlog.Printf("%s: %s\n", s.epRF.FQDN+oid, collJSON)
+	}
+	var coll SoftwareInventoryCollection
+	if err := json.Unmarshal(collJSON, &coll); err != nil {
+		errlog.Printf("Failed to decode %s: %s\n", oid, err)
+		return
+	}
+	sort.Sort(ResourceIDSlice(coll.Members))
+	for _, mOID := range coll.Members {
+		si := NewEpSoftwareInventory(s.epRF, mOID, inventory)
+		sis.OIDs[si.ID] = si
+	}
+	sis.Num = len(sis.OIDs)
+	for _, si := range sis.OIDs {
+		si.discoverRemotePhase1()
+	}
 }
+
+// Makes contact with redfish endpoint to discover information about
+// a particular firmware or software target.
+func (si *EpSoftwareInventory) discoverRemotePhase1() {
+	rpath := si.OdataID
+	url := si.epRF.FQDN + rpath
+	urlJSON, err := si.epRF.GETRelative(rpath)
+	if err != nil || urlJSON == nil {
+		si.LastStatus = HTTPsGetFailed
+		return
+	}
+	si.softwareInvRaw = &urlJSON
+	si.LastStatus = HTTPsGetOk
+
+	if rfDebug > 0 {
+		errlog.Printf("%s: %s\n", url, urlJSON)
+	}
+	// Decode JSON into SoftwareInventory structure containing Redfish data
+	if err := json.Unmarshal(urlJSON, &si.SoftwareInventoryRF); err != nil {
+		if IsUnmarshalTypeError(err) {
+			errlog.Printf("bad field(s) skipped: %s: %s\n", url, err)
+		} else {
+			errlog.Printf("ERROR: json decode failed: %s: %s\n", url, err)
+			si.LastStatus = EPResponseFailedDecode
+			return
+		}
+	}
+	if si.SoftwareInventoryRF.Id != "" {
+		si.ID = si.SoftwareInventoryRF.Id
+	}
+	si.LastStatus = VerifyingData
+}
+
+// Tie each target in the set back to the xname of a discovered component.
+// oidMap is keyed by the (normalized) Redfish odata.id of every component
+// discovered under the RedfishEP.
+func (sis *EpSoftwareInventories) discoverLocalPhase2(oidMap map[string]*ComponentDescription) {
+	for _, si := range sis.OIDs {
+		si.discoverLocalPhase2(oidMap)
+	}
+}
+
+// Phase2 discovery for an individual firmware or software target.
+func (si *EpSoftwareInventory) discoverLocalPhase2(oidMap map[string]*ComponentDescription) {
+	if si.LastStatus != VerifyingData {
+		return
+	}
+	for _, item := range si.SoftwareInventoryRF.RelatedItem {
+		if item == nil {
+			continue
+		}
+		if cd := lookupComponentOID(oidMap, item.Oid); cd != nil {
+			si.RelatedID = cd.ID
+			si.RelatedType = cd.Type
+			break
+		}
+	}
+	// Nothing linked (or nothing we know about), so the best we can do is
+	// to say that it belongs to the controller we got it from.
+	if si.RelatedID == "" {
+		si.RelatedID = si.epRF.ID
+		si.RelatedType = si.epRF.Type
+	}
+	si.LastStatus = DiscoverOK
+}
+
+// Look up the component with the given odata.id.  If there isn't an exact
+// match, the closest parent is used, e.g. a link to a node's BIOS settings
+// resource resolves to the node itself.
+func lookupComponentOID(oidMap map[string]*ComponentDescription, oid string) *ComponentDescription {
+	p := normalizeOID(oid)
+	for p != "/" && p != "." && p != "" {
+		if cd, ok := oidMap[p]; ok {
+			return cd
+		}
+		p = path.Dir(p)
+	}
+	return nil
+}
+
+// Strip trailing slashes so odata.ids compare the same either way.
+func normalizeOID(oid string) string {
+	if len(oid) > 1 {
+		return strings.TrimRight(oid, "/")
+	}
+	return oid
+}
+
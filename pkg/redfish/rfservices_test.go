@@ -0,0 +1,317 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package rf
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+////////////////////////////////////////////////////////////////////////////
+//
+//  UpdateService - Firmware and Software inventory
+//
+///////////////////////////////////////////////////////////////////////////
+
+const testPathUpdateService = "/redfish/v1/UpdateService"
+const testPathFirmwareInventory = "/redfish/v1/UpdateService/FirmwareInventory"
+const testPathFirmwareInventoryBMC = "/redfish/v1/UpdateService/FirmwareInventory/BMC"
+const testPathFirmwareInventoryBIOS = "/redfish/v1/UpdateService/FirmwareInventory/BIOS"
+const testPathFirmwareInventoryCPLD = "/redfish/v1/UpdateService/FirmwareInventory/CPLD"
+
+const testPayloadUpdateService = `{
+	"@odata.id": "/redfish/v1/UpdateService",
+	"@odata.type": "#UpdateService.v1_8_0.UpdateService",
+	"Id": "UpdateService",
+	"Name": "Update Service",
+	"ServiceEnabled": true,
+	"FirmwareInventory": {"@odata.id": "/redfish/v1/UpdateService/FirmwareInventory"}
+}`
+
+const testPayloadFirmwareInventory = `{
+	"@odata.id": "/redfish/v1/UpdateService/FirmwareInventory",
+	"Name": "Firmware Inventory Collection",
+	"Members@odata.count": 3,
+	"Members": [
+		{"@odata.id": "/redfish/v1/UpdateService/FirmwareInventory/BMC"},
+		{"@odata.id": "/redfish/v1/UpdateService/FirmwareInventory/BIOS"},
+		{"@odata.id": "/redfish/v1/UpdateService/FirmwareInventory/CPLD"}
+	]
+}`
+
+const testPayloadFirmwareInventoryBMC = `{
+	"@odata.id": "/redfish/v1/UpdateService/FirmwareInventory/BMC",
+	"Id": "BMC",
+	"Name": "BMC Firmware",
+	"Version": "1.2.3",
+	"Updateable": true,
+	"RelatedItem": [{"@odata.id": "/redfish/v1/Managers/BMC"}]
+}`
+
+const testPayloadFirmwareInventoryBIOS = `{
+	"@odata.id": "/redfish/v1/UpdateService/FirmwareInventory/BIOS",
+	"Id": "BIOS",
+	"Name": "System BIOS",
+	"Version": "2.0.1",
+	"Updateable": false,
+	"RelatedItem": [{"@odata.id": "/redfish/v1/Systems/Self/Bios/"}]
+}`
+
+const testPayloadFirmwareInventoryCPLD = `{
+	"@odata.id": "/redfish/v1/UpdateService/FirmwareInventory/CPLD",
+	"Id": "CPLD",
+	"Name": "CPLD",
+	"Version": "0x10"
+}`
+
+func NewRTFuncFirmwareInventory() RTFunc {
+	payloads := map[string]string{
+		testPathUpdateService:         testPayloadUpdateService,
+		testPathFirmwareInventory:     testPayloadFirmwareInventory,
+		testPathFirmwareInventoryBMC:  testPayloadFirmwareInventoryBMC,
+		testPathFirmwareInventoryBIOS: testPayloadFirmwareInventoryBIOS,
+		testPathFirmwareInventoryCPLD: testPayloadFirmwareInventoryCPLD,
+	}
+	return func(req *http.Request) *http.Response {
+		for path, payload := range payloads {
+			if req.URL.String() == "https://"+testFQDN+path {
+				return &http.Response{
+					StatusCode: 200,
+					// Send mock response for rpath
+					Body: ioutil.NopCloser(bytes.NewBufferString(payload)),
+					// Header must always be non-nil or it will cause a panic.
+					Header: make(http.Header),
+				}
+			}
+		}
+		return &http.Response{
+			StatusCode: 404,
+			Body:       ioutil.NopCloser(bytes.NewBufferString("")),
+			Header:     make(http.Header),
+		}
+	}
+}
+
+func TestUpdateServiceFirmwareInventory(t *testing.T) {
+	ep := TestRedfishEPInitIntel
+	ep.client = NewTestClient(NewRTFuncFirmwareInventory())
+
+	s := NewEpUpdateService(&ep, testPathUpdateService)
+	s.discoverRemotePhase1()
+	if s.LastStatus != HTTPsGetOk {
+		t.Fatalf("UpdateService discovery failed: %s", s.LastStatus)
+	}
+	if s.FirmwareInventory.Num != 3 {
+		t.Fatalf("Expected 3 firmware targets, got %d", s.FirmwareInventory.Num)
+	}
+	if s.SoftwareInventory.Num != 0 {
+		t.Errorf("Expected no software targets, got %d", s.SoftwareInventory.Num)
+	}
+
+	oidMap := map[string]*ComponentDescription{
+		"/redfish/v1/Managers/BMC": &ComponentDescription{
+			ID:   testXName,
+			Type: "NodeBMC",
+		},
+		"/redfish/v1/Systems/Self": &ComponentDescription{
+			ID:   testXName + "n0",
+			Type: "Node",
+		},
+	}
+	s.FirmwareInventory.discoverLocalPhase2(oidMap)
+
+	tests := []struct {
+		id          string
+		version     string
+		updateable  *bool
+		relatedID   string
+		relatedType string
+	}{{
+		// Exact match
+		id:          "BMC",
+		version:     "1.2.3",
+		updateable:  func() *bool { b := true; return &b }(),
+		relatedID:   testXName,
+		relatedType: "NodeBMC",
+	}, {
+		// Parent match, trailing slash
+		id:          "BIOS",
+		version:     "2.0.1",
+		updateable:  func() *bool { b := false; return &b }(),
+		relatedID:   testXName + "n0",
+		relatedType: "Node",
+	}, {
+		// No RelatedItem, so it belongs to the BMC
+		id:          "CPLD",
+		version:     "0x10",
+		updateable:  nil,
+		relatedID:   ep.ID,
+		relatedType: ep.Type,
+	}}
+	for i, test := range tests {
+		si, ok := s.FirmwareInventory.OIDs[test.id]
+		if !ok {
+			t.Errorf("Test %d Failed: %s was not discovered", i, test.id)
+			continue
+		}
+		if si.LastStatus != DiscoverOK {
+			t.Errorf("Test %d Failed: %s LastStatus: %s", i, test.id, si.LastStatus)
+		}
+		if si.Inventory != FirmwareInventoryName {
+			t.Errorf("Test %d Failed: %s Inventory: %s", i, test.id, si.Inventory)
+		}
+		if si.SoftwareInventoryRF.Version != test.version {
+			t.Errorf("Test %d Failed: %s Expected version '%s', got '%s'",
+				i, test.id, test.version, si.SoftwareInventoryRF.Version)
+		}
+		if (test.updateable == nil) != (si.SoftwareInventoryRF.Updateable == nil) ||
+			(test.updateable != nil && *test.updateable != *si.SoftwareInventoryRF.Updateable) {
+			t.Errorf("Test %d Failed: %s Updateable mismatch", i, test.id)
+		}
+		if si.RelatedID != test.relatedID || si.RelatedType != test.relatedType {
+			t.Errorf("Test %d Failed: %s Expected related '%s/%s', got '%s/%s'",
+				i, test.id, test.relatedID, test.relatedType,
+				si.RelatedID, si.RelatedType)
+		}
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////
+//
+//  EventService - Subscriptions
+//
+///////////////////////////////////////////////////////////////////////////
+
+const testPathEventService = "/redfish/v1/EventService"
+const testPathEventSubscriptions = "/redfish/v1/EventService/Subscriptions"
+
+const testPayloadEventService = `{
+	"@odata.id": "/redfish/v1/EventService",
+	"@odata.type": "#EventService.v1_0_8.EventService",
+	"Id": "EventService",
+	"Name": "Event Service",
+	"ServiceEnabled": true,
+	"EventTypesForSubscription": ["StatusChange", "Alert"],
+	"Subscriptions": {"@odata.id": "/redfish/v1/EventService/Subscriptions"}
+}`
+
+// Mock EventService that keeps the subscriptions POSTed to it.
+func NewRTFuncEventSubscriptions(subs map[string]string) RTFunc {
+	next := 1
+	rsp := func(code int, body string, hdr http.Header) *http.Response {
+		if hdr == nil {
+			hdr = make(http.Header)
+		}
+		return &http.Response{
+			StatusCode: code,
+			Body:       ioutil.NopCloser(bytes.NewBufferString(body)),
+			Header:     hdr,
+		}
+	}
+	return func(req *http.Request) *http.Response {
+		path := strings.TrimPrefix(req.URL.String(), "https://"+testFQDN)
+		switch {
+		case req.Method == "GET" && path == testPathEventService:
+			return rsp(200, testPayloadEventService, nil)
+		case req.Method == "GET" && path == testPathEventSubscriptions:
+			members := []string{}
+			for oid := range subs {
+				members = append(members, `{"@odata.id": "`+oid+`"}`)
+			}
+			sort.Strings(members)
+			return rsp(200, `{"Members": [`+strings.Join(members, ",")+`]}`, nil)
+		case req.Method == "POST" && path == testPathEventSubscriptions:
+			var sub map[string]interface{}
+			body, _ := ioutil.ReadAll(req.Body)
+			if err := json.Unmarshal(body, &sub); err != nil {
+				return rsp(400, "", nil)
+			}
+			oid := testPathEventSubscriptions + "/" + strconv.Itoa(next)
+			next++
+			sub["@odata.id"] = oid
+			subJSON, _ := json.Marshal(sub)
+			subs[oid] = string(subJSON)
+			hdr := make(http.Header)
+			hdr.Set("Location", "https://"+testFQDN+oid)
+			return rsp(201, "", hdr)
+		case req.Method == "GET":
+			if sub, ok := subs[path]; ok {
+				return rsp(200, sub, nil)
+			}
+		case req.Method == "DELETE":
+			if _, ok := subs[path]; ok {
+				delete(subs, path)
+				return rsp(204, "", nil)
+			}
+		}
+		return rsp(404, "", nil)
+	}
+}
+
+func TestEventServiceSubscriptions(t *testing.T) {
+	subs := make(map[string]string)
+	ep := TestRedfishEPInitIntel
+	ep.client = NewTestClient(NewRTFuncEventSubscriptions(subs))
+
+	es, err := ep.GetEventService(testPathEventService)
+	if err != nil {
+		t.Fatalf("GetEventService failed: %s", err)
+	}
+	oid, err := es.Subscribe("https://smd:27780/events", "x0c0s0b0:smd",
+		es.EventServiceRF.EventTypesForSubscription)
+	if err != nil {
+		t.Fatalf("Subscribe failed: %s", err)
+	}
+	if oid != testPathEventSubscriptions+"/1" {
+		t.Errorf("Expected subscription %s/1, got '%s'",
+			testPathEventSubscriptions, oid)
+	}
+	got, err := es.GetSubscriptions()
+	if err != nil {
+		t.Fatalf("GetSubscriptions failed: %s", err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("Expected 1 subscription, got %d", len(got))
+	}
+	if got[0].Oid != oid || got[0].Destination != "https://smd:27780/events" ||
+		got[0].Context != "x0c0s0b0:smd" || got[0].Protocol != "Redfish" ||
+		len(got[0].EventTypes) != 2 {
+		t.Errorf("Unexpected subscription: %+v", got[0])
+	}
+	if err := es.Unsubscribe(oid); err != nil {
+		t.Errorf("Unsubscribe failed: %s", err)
+	}
+	if len(subs) != 0 {
+		t.Errorf("Subscription was not removed")
+	}
+	if err := es.Unsubscribe(oid); err != ErrRFDiscURLNotFound {
+		t.Errorf("Expected %s removing a missing subscription, got %v",
+			ErrRFDiscURLNotFound, err)
+	}
+}
@@ -235,6 +235,74 @@ var MemHWInvByLoc2 = sm.HWInvByLoc{
 	PopulatedFRU: &MemHWInvByFRU2,
 }
 
+// PCIe NIC and storage controller for node
+var NodeNicHWInvByFRU1 = sm.HWInvByFRU{
+	FRUID:                "NodeNic.Intel.X710DA2.NIC1SN",
+	Type:                 "NodeNic",
+	HWInventoryByFRUType: "HWInvByFRUNodeNic",
+	HMSNodeNICFRUInfo: &rf.PCIeDeviceFRUInfoRF{
+		Manufacturer: "Intel",
+		Model:        "X710",
+		PartNumber:   "X710-DA2",
+		SerialNumber: "NIC1SN",
+		DeviceClass:  "NetworkController",
+		VendorId:     "0x8086",
+		DeviceId:     "0x1572",
+	},
+}
+
+var NodeNicHWInvByLoc1 = sm.HWInvByLoc{
+	ID:                        "x0c0s0b0n0i0",
+	Type:                      "NodeNic",
+	Ordinal:                   0,
+	Status:                    "Populated",
+	HWInventoryByLocationType: "HWInvByLocNodeNic",
+	HMSNodeNICLocationInfo: &rf.PCIeDeviceLocationInfoRF{
+		Id:   "NIC1",
+		Name: "Ethernet Adapter",
+		Functions: []rf.PCIeFunctionInfo{
+			{FunctionId: 0, DeviceClass: "NetworkController"},
+			{FunctionId: 1, DeviceClass: "NetworkController"},
+		},
+	},
+	PopulatedFRU: &NodeNicHWInvByFRU1,
+}
+
+var StorageGroupHWInvByFRU1 = sm.HWInvByFRU{
+	FRUID:                "StorageGroup.Samsung.PM1733.NVMESN",
+	Type:                 "StorageGroup",
+	HWInventoryByFRUType: "HWInvByFRUStorageGroup",
+	HMSStorageGroupFRUInfo: &rf.PCIeDeviceFRUInfoRF{
+		Manufacturer: "Samsung",
+		PartNumber:   "PM1733",
+		SerialNumber: "NVMESN",
+		DeviceClass:  "MassStorageController",
+	},
+}
+
+var StorageGroupHWInvByLoc1 = sm.HWInvByLoc{
+	ID:                        "x0c0s0b0n0g1",
+	Type:                      "StorageGroup",
+	Ordinal:                   1,
+	Status:                    "Populated",
+	HWInventoryByLocationType: "HWInvByLocStorageGroup",
+	HMSStorageGroupLocationInfo: &rf.PCIeDeviceLocationInfoRF{
+		Id:   "NVMe0",
+		Name: "NVMe Controller",
+	},
+	PopulatedFRU: &StorageGroupHWInvByFRU1,
+}
+
+var HWInvByLocPCIeArray1 = []*sm.HWInvByLoc{
+	&NodeNicHWInvByLoc1,
+	&StorageGroupHWInvByLoc1,
+}
+
+var HWInvByFRUPCIeArray1 = []*sm.HWInvByFRU{
+	&NodeNicHWInvByFRU1,
+	&StorageGroupHWInvByFRU1,
+}
+
 var HWInvByLocArray1 = []*sm.HWInvByLoc{
 	&NodeHWInvByLoc1,
 	&ProcHWInvByLoc1,
@@ -0,0 +1,40 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package sm
+
+// A running instance of HSM, as recorded by its periodic heartbeat.
+type ServiceInstance struct {
+	ID            string `json:"ID"`
+	Started       string `json:"Started"`
+	LastHeartbeat string `json:"LastHeartbeat"`
+	Leader        bool   `json:"Leader"` // Runs the singleton maintenance tasks
+	Live          bool   `json:"Live"`   // Heartbeat is recent enough
+}
+
+// The cluster of HSM instances as seen by the instance reporting it.
+// Only live members share per-endpoint work.
+type ClusterStatus struct {
+	ID      string             `json:"ID"` // The reporting instance
+	Leader  bool               `json:"Leader"`
+	Members []*ServiceInstance `json:"Members"`
+}
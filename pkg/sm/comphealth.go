@@ -0,0 +1,96 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package sm
+
+import (
+	base "github.com/Cray-HPE/hms-base/v2"
+	rf "github.com/Cray-HPE/hms-smd/v2/pkg/redfish"
+	"github.com/Cray-HPE/hms-xname/xnametypes"
+)
+
+var ErrCompHealthInvalid = base.NewHMSError("sm", "Health contributor has an invalid component ID, OdataID or Flag")
+
+// Where a health contributor was last reported from.
+const (
+	CompHealthSourceDiscovery = "Discovery"
+	CompHealthSourceEvent     = "Event"
+)
+
+// The health of a component, i.e. its current Flag plus the components
+// and subcomponents whose Redfish health raised it.
+type CompHealth struct {
+	ID           string                   `json:"ID"`
+	Type         string                   `json:"Type"`
+	State        string                   `json:"State"`
+	Flag         string                   `json:"Flag"`
+	Contributors []*CompHealthContributor `json:"Contributors"`
+}
+
+// A single source of a non-OK health for the component CompID.  This is
+// either the component itself or a subcomponent such as a DIMM or power
+// supply, identified by its xname where there is one, and by its Redfish
+// URI.
+type CompHealthContributor struct {
+	CompID       string `json:"-"`    // Component the flag was raised on
+	ID           string `json:"ID"`   // xname, or Redfish Id if none
+	Type         string `json:"Type"` // HMS type, or Redfish type if none
+	OdataID      string `json:"OdataID"`
+	Health       string `json:"Health"`
+	HealthRollUp string `json:"HealthRollUp,omitempty"`
+	State        string `json:"State,omitempty"`
+	Flag         string `json:"Flag"`
+	Source       string `json:"Source"` // Discovery or Event
+	LastUpdate   string `json:"LastUpdate,omitempty"`
+}
+
+// Create a new CompHealthContributor for compID from one found during
+// discovery.
+func NewCompHealthContributor(compID string, hc *rf.HealthContributor) *CompHealthContributor {
+	if hc == nil {
+		return nil
+	}
+	c := new(CompHealthContributor)
+	c.CompID = compID
+	c.ID = hc.ID
+	c.Type = hc.Type
+	c.OdataID = hc.OdataID
+	c.Health = hc.Health
+	c.HealthRollUp = hc.HealthRollUp
+	c.State = hc.State
+	c.Flag = hc.Flag
+	c.Source = CompHealthSourceDiscovery
+	return c
+}
+
+// Check and normalize the fields that key a health contributor.
+func (c *CompHealthContributor) VerifyNormalize() error {
+	c.CompID = xnametypes.VerifyNormalizeCompID(c.CompID)
+	if c.CompID == "" || c.OdataID == "" {
+		return ErrCompHealthInvalid
+	}
+	c.Flag = base.VerifyNormalizeFlag(c.Flag)
+	if c.Flag == "" {
+		return ErrCompHealthInvalid
+	}
+	return nil
+}
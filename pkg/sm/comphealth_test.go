@@ -0,0 +1,94 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package sm
+
+import (
+	"reflect"
+	"testing"
+
+	rf "github.com/Cray-HPE/hms-smd/v2/pkg/redfish"
+)
+
+func TestNewCompHealthContributor(t *testing.T) {
+	hc := &rf.HealthContributor{
+		ID:           "x0c0s0b0n0d3",
+		Type:         "Memory",
+		OdataID:      "/redfish/v1/Systems/1/Memory/DIMM3",
+		Health:       "Critical",
+		HealthRollUp: "Critical",
+		State:        "Enabled",
+		Flag:         "Alert",
+	}
+	expected := &CompHealthContributor{
+		CompID:       "x0c0s0b0n0",
+		ID:           "x0c0s0b0n0d3",
+		Type:         "Memory",
+		OdataID:      "/redfish/v1/Systems/1/Memory/DIMM3",
+		Health:       "Critical",
+		HealthRollUp: "Critical",
+		State:        "Enabled",
+		Flag:         "Alert",
+		Source:       CompHealthSourceDiscovery,
+	}
+	c := NewCompHealthContributor("x0c0s0b0n0", hc)
+	if !reflect.DeepEqual(expected, c) {
+		t.Errorf("Expected '%v'; got '%v'", expected, c)
+	}
+	if NewCompHealthContributor("x0c0s0b0n0", nil) != nil {
+		t.Errorf("Expected nil for nil contributor")
+	}
+}
+
+func TestCompHealthContributorVerifyNormalize(t *testing.T) {
+	tests := []struct {
+		in          CompHealthContributor
+		expectedID  string
+		expectedFlg string
+		expectedErr error
+	}{{
+		in:          CompHealthContributor{CompID: "X0C0S0B0N0", OdataID: "/redfish/v1/Systems/1", Flag: "warning"},
+		expectedID:  "x0c0s0b0n0",
+		expectedFlg: "Warning",
+	}, {
+		in:          CompHealthContributor{CompID: "foo", OdataID: "/redfish/v1/Systems/1", Flag: "OK"},
+		expectedErr: ErrCompHealthInvalid,
+	}, {
+		in:          CompHealthContributor{CompID: "x0c0s0b0n0", Flag: "OK"},
+		expectedErr: ErrCompHealthInvalid,
+	}, {
+		in:          CompHealthContributor{CompID: "x0c0s0b0n0", OdataID: "/redfish/v1/Systems/1", Flag: "bad"},
+		expectedErr: ErrCompHealthInvalid,
+	}}
+	for i, test := range tests {
+		c := test.in
+		err := c.VerifyNormalize()
+		if err != test.expectedErr {
+			t.Errorf("Test %d Failed: Expected err '%v'; got '%v'", i, test.expectedErr, err)
+			continue
+		}
+		if err == nil && (c.CompID != test.expectedID || c.Flag != test.expectedFlg) {
+			t.Errorf("Test %d Failed: Expected '%s'/'%s'; got '%s'/'%s'",
+				i, test.expectedID, test.expectedFlg, c.CompID, c.Flag)
+		}
+	}
+}
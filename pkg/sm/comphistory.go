@@ -0,0 +1,69 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package sm
+
+import (
+	"strings"
+)
+
+// Component fields whose changes are kept in the component history.
+const (
+	CompHistFieldState    = "State"
+	CompHistFieldFlag     = "Flag"
+	CompHistFieldEnabled  = "Enabled"
+	CompHistFieldRole     = "Role"
+	CompHistFieldSubRole  = "SubRole"
+	CompHistFieldSwStatus = "SoftwareStatus"
+)
+
+// For case-insensitive verification and normalization of field names
+var compHistFieldMap = map[string]string{
+	"state":          CompHistFieldState,
+	"flag":           CompHistFieldFlag,
+	"enabled":        CompHistFieldEnabled,
+	"role":           CompHistFieldRole,
+	"subrole":        CompHistFieldSubRole,
+	"softwarestatus": CompHistFieldSwStatus,
+}
+
+// A single change to one field of a component.  OldValue is empty when
+// the component was created.  Source is the service or user that asked
+// for the change, if known.
+type CompHistoryEntry struct {
+	ID          int64  `json:"ID"`
+	Timestamp   string `json:"Timestamp"`
+	ComponentID string `json:"ComponentID"`
+	Field       string `json:"Field"`
+	OldValue    string `json:"OldValue"`
+	NewValue    string `json:"NewValue"`
+	Source      string `json:"Source,omitempty"`
+}
+
+// Validate and Normalize component history field names used in queries
+func VerifyNormalizeCompHistField(field string) string {
+	value, ok := compHistFieldMap[strings.ToLower(field)]
+	if !ok {
+		return ""
+	}
+	return value
+}
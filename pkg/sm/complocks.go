@@ -168,6 +168,7 @@ type CompLockV2Filter struct {
 	Locked              []string `json:"Locked"`
 	Reserved            []string `json:"Reserved"`
 	ReservationDisabled []string `json:"ReservationDisabled"`
+	Labels              []string `json:"Labels"`
 }
 
 // Release Res, Release/Renew ServRes
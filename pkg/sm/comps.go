@@ -315,3 +315,108 @@ func (cp *ComponentPut) VerifyNormalize() error {
 	}
 	return nil
 }
+
+// A component whose state could not be changed because the state
+// transition policy does not allow it, along with the states it could be
+// changed to instead.
+type CompStateRejection struct {
+	ID              string   `json:"ID"`
+	State           string   `json:"State"`
+	NewState        string   `json:"NewState"`
+	ValidNextStates []string `json:"ValidNextStates"`
+}
+
+// One component's changes in a multi-field Components PATCH.  Fields that
+// are left out are not changed, except that Flag is set to OK if State is
+// given without it.
+type CompPatch struct {
+	ID       string  `json:"ID"`
+	State    string  `json:"State,omitempty"`
+	Flag     string  `json:"Flag,omitempty"`
+	Enabled  *bool   `json:"Enabled,omitempty"`
+	SwStatus *string `json:"SoftwareStatus,omitempty"`
+	Role     *string `json:"Role,omitempty"`
+	SubRole  *string `json:"SubRole,omitempty"`
+}
+
+// The payload for a multi-field Components PATCH
+type CompPatchArray struct {
+	Components []*CompPatch `json:"Components"`
+	Force      bool         `json:"Force,omitempty"`
+}
+
+// Check the fields of a multi-field PATCH entry and normalize them,
+// including the ID.
+func (cp *CompPatch) VerifyNormalize() error {
+	normID := xnametypes.VerifyNormalizeCompID(cp.ID)
+	if normID == "" {
+		return fmt.Errorf("xname ID '%s' is invalid", cp.ID)
+	}
+	cp.ID = normID
+	if cp.State == "" && cp.Flag == "" && cp.Enabled == nil &&
+		cp.SwStatus == nil && cp.Role == nil && cp.SubRole == nil {
+		return fmt.Errorf("no fields to update")
+	}
+	if cp.State != "" {
+		normState := base.VerifyNormalizeState(cp.State)
+		if normState == "" {
+			return fmt.Errorf("state '%s' is invalid", cp.State)
+		}
+		cp.State = normState
+		if cp.Flag == "" {
+			cp.Flag = base.FlagOK.String()
+		}
+	}
+	if cp.Flag != "" {
+		normFlag := base.VerifyNormalizeFlag(cp.Flag)
+		if normFlag == "" {
+			return fmt.Errorf("flag '%s' is invalid", cp.Flag)
+		}
+		cp.Flag = normFlag
+	}
+	if cp.Role != nil {
+		normRole := base.VerifyNormalizeRole(*cp.Role)
+		if normRole == "" {
+			return fmt.Errorf("role '%s' is invalid", *cp.Role)
+		}
+		cp.Role = &normRole
+	}
+	// SubRole can be set to empty to clear it.
+	if cp.SubRole != nil && *cp.SubRole != "" {
+		normSubRole := base.VerifyNormalizeSubRole(*cp.SubRole)
+		if normSubRole == "" {
+			return fmt.Errorf("subRole '%s' is invalid", *cp.SubRole)
+		}
+		cp.SubRole = &normSubRole
+	}
+	return nil
+}
+
+// A component a multi-field PATCH could not be applied to, and why.  If
+// the state transition policy rejected its new state, State, NewState and
+// ValidNextStates are set as in a CompStateRejection.
+type CompPatchFailure struct {
+	ID              string   `json:"ID"`
+	Reason          string   `json:"Reason"`
+	State           string   `json:"State,omitempty"`
+	NewState        string   `json:"NewState,omitempty"`
+	ValidNextStates []string `json:"ValidNextStates,omitempty"`
+}
+
+type CompPatchCount struct {
+	Total   int `json:"Total"`
+	Success int `json:"Success"`
+	Failure int `json:"Failure"`
+}
+
+type CompPatchSuccessArray struct {
+	ComponentIDs []string `json:"ComponentIDs"`
+}
+
+// The per-component results of a multi-field Components PATCH.  Each
+// component either had all of its changes applied, or none of them.
+type CompPatchResult struct {
+	Counts  CompPatchCount        `json:"Counts"`
+	Success CompPatchSuccessArray `json:"Success"`
+	Failure []CompPatchFailure    `json:"Failure"`
+}
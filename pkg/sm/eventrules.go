@@ -0,0 +1,216 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package sm
+
+import (
+	"regexp"
+	"strings"
+
+	base "github.com/Cray-HPE/hms-base/v2"
+)
+
+var ErrEventRuleBadName = base.NewHMSError("sm", "Event rule Name is missing or invalid")
+var ErrEventRuleNoMsgId = base.NewHMSError("sm", "Event rule has no MessageId to match")
+var ErrEventRuleBadRegex = base.NewHMSError("sm", "Event rule has an invalid regular expression")
+var ErrEventRuleBadState = base.NewHMSError("sm", "Event rule has an invalid State")
+var ErrEventRuleBadFlag = base.NewHMSError("sm", "Event rule has an invalid Flag")
+var ErrEventRuleBadTarget = base.NewHMSError("sm", "Event rule has an invalid Target")
+var ErrEventRuleBadChildren = base.NewHMSError("sm", "Event rule has an invalid Children value")
+var ErrEventRuleBadHWInv = base.NewHMSError("sm", "Event rule has an invalid HWInvUpdate value")
+var ErrEventRuleNoAction = base.NewHMSError("sm", "Event rule needs exactly one of Ignore, Parser, or a State and/or Flag")
+
+// Where an event rule came from.  API rules replace file rules of the same
+// name, which replace built-in rules.
+const (
+	EventRuleSourceAPI     = "API"
+	EventRuleSourceFile    = "File"
+	EventRuleSourceBuiltIn = "BuiltIn"
+)
+
+// How the xname of the component an event is about is found.
+const (
+	// The first MessageArg that is a URI, else the OriginOfCondition
+	EventRuleTargetURI = "URI"
+	// The OriginOfCondition only
+	EventRuleTargetOrigin = "Origin"
+	// The URI as above, or the closest parent of it that is a component,
+	// for events about subcomponents such as DIMMs or power supplies.
+	EventRuleTargetSubURI = "SubURI"
+	// The controller that sent the event, plus TargetSuffix
+	EventRuleTargetController = "Controller"
+)
+
+// Additional components to update along with the target.
+const (
+	EventRuleChildrenNone = ""
+	// If the target is a blade slot, the controllers in it, and when
+	// powered off, the nodes and other components under them.
+	EventRuleChildrenSlot = "Slot"
+	// If the target is a controller, all components under it.
+	EventRuleChildrenController = "Controller"
+)
+
+// Hardware inventory refresh when a node is powered on.
+const (
+	EventRuleHWInvNone     = ""
+	EventRuleHWInvStandard = "Standard"
+	EventRuleHWInvFoxconn  = "Foxconn"
+)
+
+// A rule mapping Redfish events to a change in state and/or flag of the
+// components they concern.  The first enabled rule (by descending
+// Priority) whose Match fields all match an event is applied to it.
+type EventRule struct {
+	Name        string          `json:"Name"`
+	Description string          `json:"Description,omitempty"`
+	Priority    int             `json:"Priority"`
+	Disabled    bool            `json:"Disabled,omitempty"`
+	Match       EventRuleMatch  `json:"Match"`
+	Action      EventRuleAction `json:"Action"`
+	Source      string          `json:"Source,omitempty"` // Read-only
+}
+
+// What a rule matches.  MessageId is required and compared without case.
+// The rest are regular expressions, also matched without case, and are
+// ignored if empty.  Each MessageArgs expression must match at least one
+// of the event's MessageArgs, in any order.  ControllerType matches the
+// HMS type of the controller that sent the event, e.g. ChassisBMC.
+type EventRuleMatch struct {
+	MessageId         string   `json:"MessageId"`
+	Registry          string   `json:"Registry,omitempty"`
+	RegistryVersion   string   `json:"RegistryVersion,omitempty"`
+	MessageArgs       []string `json:"MessageArgs,omitempty"`
+	OriginOfCondition string   `json:"OriginOfCondition,omitempty"`
+	Severity          string   `json:"Severity,omitempty"`
+	ControllerType    string   `json:"ControllerType,omitempty"`
+}
+
+// What a rule does.  Either Ignore the event, hand it to a built-in Parser
+// or set the State and/or Flag of the Target and its Children.
+type EventRuleAction struct {
+	Ignore       bool   `json:"Ignore,omitempty"`
+	Parser       string `json:"Parser,omitempty"`
+	State        string `json:"State,omitempty"`
+	Flag         string `json:"Flag,omitempty"`
+	Target       string `json:"Target,omitempty"`
+	TargetSuffix string `json:"TargetSuffix,omitempty"`
+	Children     string `json:"Children,omitempty"`
+	HWInvUpdate  string `json:"HWInvUpdate,omitempty"`
+	Rediscover   bool   `json:"Rediscover,omitempty"`
+}
+
+type EventRuleArray struct {
+	EventRules []*EventRule `json:"EventRules"`
+}
+
+var eventRuleNameRE = regexp.MustCompile(`^[A-Za-z0-9_.:-]+$`)
+
+// Compile a rule regular expression so it matches without case.  Empty
+// expressions give a nil Regexp, which the caller should treat as a match.
+func CompileEventRuleRegex(expr string) (*regexp.Regexp, error) {
+	if expr == "" {
+		return nil, nil
+	}
+	re, err := regexp.Compile("(?i)" + expr)
+	if err != nil {
+		return nil, ErrEventRuleBadRegex
+	}
+	return re, nil
+}
+
+// Check the rule for errors and normalize its enumerated fields.  Does not
+// check that a Parser exists, as parsers belong to the service.
+func (r *EventRule) VerifyNormalize() error {
+	if !eventRuleNameRE.MatchString(r.Name) {
+		return ErrEventRuleBadName
+	}
+	m := &r.Match
+	m.MessageId = strings.TrimSpace(m.MessageId)
+	if m.MessageId == "" {
+		return ErrEventRuleNoMsgId
+	}
+	exprs := append([]string{m.Registry, m.RegistryVersion,
+		m.OriginOfCondition, m.Severity, m.ControllerType}, m.MessageArgs...)
+	for _, expr := range exprs {
+		if _, err := CompileEventRuleRegex(expr); err != nil {
+			return err
+		}
+	}
+	a := &r.Action
+	numActions := 0
+	if a.Ignore {
+		numActions++
+	}
+	if a.Parser != "" {
+		numActions++
+	}
+	if a.State != "" || a.Flag != "" {
+		numActions++
+	}
+	if numActions != 1 {
+		return ErrEventRuleNoAction
+	}
+	if a.State != "" {
+		if a.State = base.VerifyNormalizeState(a.State); a.State == "" {
+			return ErrEventRuleBadState
+		}
+	}
+	if a.Flag != "" {
+		if a.Flag = base.VerifyNormalizeFlag(a.Flag); a.Flag == "" {
+			return ErrEventRuleBadFlag
+		}
+	}
+	switch strings.ToLower(a.Target) {
+	case "", strings.ToLower(EventRuleTargetURI):
+		a.Target = EventRuleTargetURI
+	case strings.ToLower(EventRuleTargetOrigin):
+		a.Target = EventRuleTargetOrigin
+	case strings.ToLower(EventRuleTargetSubURI):
+		a.Target = EventRuleTargetSubURI
+	case strings.ToLower(EventRuleTargetController):
+		a.Target = EventRuleTargetController
+	default:
+		return ErrEventRuleBadTarget
+	}
+	switch strings.ToLower(a.Children) {
+	case "", "none":
+		a.Children = EventRuleChildrenNone
+	case strings.ToLower(EventRuleChildrenSlot):
+		a.Children = EventRuleChildrenSlot
+	case strings.ToLower(EventRuleChildrenController):
+		a.Children = EventRuleChildrenController
+	default:
+		return ErrEventRuleBadChildren
+	}
+	switch strings.ToLower(a.HWInvUpdate) {
+	case "", "none":
+		a.HWInvUpdate = EventRuleHWInvNone
+	case strings.ToLower(EventRuleHWInvStandard):
+		a.HWInvUpdate = EventRuleHWInvStandard
+	case strings.ToLower(EventRuleHWInvFoxconn):
+		a.HWInvUpdate = EventRuleHWInvFoxconn
+	default:
+		return ErrEventRuleBadHWInv
+	}
+	return nil
+}
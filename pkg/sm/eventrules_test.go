@@ -0,0 +1,131 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package sm
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEventRuleVerifyNormalize(t *testing.T) {
+	tests := []struct {
+		in          EventRule
+		expectedErr error
+		expected    EventRuleAction
+	}{{
+		in: EventRule{
+			Name:   "Oem.PowerOn",
+			Match:  EventRuleMatch{MessageId: " PowerOn ", MessageArgs: []string{"^on$"}},
+			Action: EventRuleAction{State: "on", Target: "origin", HWInvUpdate: "foxconn"},
+		},
+		expected: EventRuleAction{State: "On", Target: EventRuleTargetOrigin,
+			HWInvUpdate: EventRuleHWInvFoxconn},
+	}, {
+		in: EventRule{
+			Name:   "Health",
+			Match:  EventRuleMatch{MessageId: "Degraded"},
+			Action: EventRuleAction{Flag: "warning", Children: "none"},
+		},
+		expected: EventRuleAction{Flag: "Warning", Target: EventRuleTargetURI},
+	}, {
+		in: EventRule{
+			Name:   "Ignored",
+			Match:  EventRuleMatch{MessageId: "Noise"},
+			Action: EventRuleAction{Ignore: true},
+		},
+		expected: EventRuleAction{Ignore: true, Target: EventRuleTargetURI},
+	}, {
+		in:          EventRule{Name: "bad name", Match: EventRuleMatch{MessageId: "x"}},
+		expectedErr: ErrEventRuleBadName,
+	}, {
+		in:          EventRule{Name: "NoMsgId", Action: EventRuleAction{Ignore: true}},
+		expectedErr: ErrEventRuleNoMsgId,
+	}, {
+		in: EventRule{
+			Name:   "BadRegex",
+			Match:  EventRuleMatch{MessageId: "x", MessageArgs: []string{"("}},
+			Action: EventRuleAction{Ignore: true},
+		},
+		expectedErr: ErrEventRuleBadRegex,
+	}, {
+		in: EventRule{
+			Name:  "NoAction",
+			Match: EventRuleMatch{MessageId: "x"},
+		},
+		expectedErr: ErrEventRuleNoAction,
+	}, {
+		in: EventRule{
+			Name:   "TwoActions",
+			Match:  EventRuleMatch{MessageId: "x"},
+			Action: EventRuleAction{Parser: "p", State: "On"},
+		},
+		expectedErr: ErrEventRuleNoAction,
+	}, {
+		in: EventRule{
+			Name:   "BadState",
+			Match:  EventRuleMatch{MessageId: "x"},
+			Action: EventRuleAction{State: "Sideways"},
+		},
+		expectedErr: ErrEventRuleBadState,
+	}, {
+		in: EventRule{
+			Name:   "BadFlag",
+			Match:  EventRuleMatch{MessageId: "x"},
+			Action: EventRuleAction{Flag: "Sideways"},
+		},
+		expectedErr: ErrEventRuleBadFlag,
+	}, {
+		in: EventRule{
+			Name:   "BadTarget",
+			Match:  EventRuleMatch{MessageId: "x"},
+			Action: EventRuleAction{State: "On", Target: "Sideways"},
+		},
+		expectedErr: ErrEventRuleBadTarget,
+	}, {
+		in: EventRule{
+			Name:   "BadChildren",
+			Match:  EventRuleMatch{MessageId: "x"},
+			Action: EventRuleAction{State: "On", Children: "Sideways"},
+		},
+		expectedErr: ErrEventRuleBadChildren,
+	}, {
+		in: EventRule{
+			Name:   "BadHWInv",
+			Match:  EventRuleMatch{MessageId: "x"},
+			Action: EventRuleAction{State: "On", HWInvUpdate: "Sideways"},
+		},
+		expectedErr: ErrEventRuleBadHWInv,
+	}}
+
+	for i, test := range tests {
+		r := test.in
+		err := r.VerifyNormalize()
+		if err != test.expectedErr {
+			t.Errorf("Test %d Failed: Expected error '%v'; Received '%v'",
+				i, test.expectedErr, err)
+		} else if err == nil && !reflect.DeepEqual(test.expected, r.Action) {
+			t.Errorf("Test %d Failed: Expected action %+v; Received %+v",
+				i, test.expected, r.Action)
+		}
+	}
+}
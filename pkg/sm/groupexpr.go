@@ -0,0 +1,231 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package sm
+
+// This file implements set-algebra expressions over groups and partitions,
+// e.g. "(compute | gpu) & !p1".
+
+import (
+	"errors"
+	"fmt"
+	"github.com/Cray-HPE/hms-xname/xnametypes"
+	"strings"
+)
+
+// Base error for all expression parsing failures.  The returned errors wrap
+// it with the offset and a description of the problem.
+var ErrGroupExprSyntax = errors.New("invalid group expression")
+
+// Maximum parenthesis/negation nesting accepted by ParseGroupExpr.
+const GroupExprMaxDepth = 64
+
+// GroupExpr node operators.
+const (
+	GroupExprGroup     = "group"     // Leaf, members of group Name
+	GroupExprPartition = "partition" // Leaf, members of partition Name
+	GroupExprAnd       = "&"         // Intersection of Left and Right
+	GroupExprOr        = "|"         // Union of Left and Right
+	GroupExprNot       = "!"         // Complement of Left
+)
+
+// Parsed group expression.  Operators are, in order of increasing
+// precedence, "|" (union), "&" (intersection) and "!" (complement), with
+// parentheses for grouping.  Operands are group labels, or partition names
+// for anything that parses as one, e.g. p1 or p1.2.
+type GroupExpr struct {
+	Op    string     `json:"op"`
+	Name  string     `json:"name,omitempty"`
+	Left  *GroupExpr `json:"left,omitempty"`
+	Right *GroupExpr `json:"right,omitempty"`
+}
+
+// Parse s into a GroupExpr.  Names are normalized to lower case.  Errors
+// wrap ErrGroupExprSyntax.
+func ParseGroupExpr(s string) (*GroupExpr, error) {
+	p := &groupExprParser{in: s}
+	p.next()
+	e, err := p.parseOr(0)
+	if err != nil {
+		return nil, err
+	}
+	if p.tok != "" {
+		return nil, p.errorf("unexpected '%s'", p.tok)
+	}
+	return e, nil
+}
+
+// Return the unique group labels (not partitions) referenced in e, in the
+// order they first appear.
+func (e *GroupExpr) Groups() []string {
+	labels := []string{}
+	seen := make(map[string]bool)
+	e.walk(func(n *GroupExpr) {
+		if n.Op == GroupExprGroup && !seen[n.Name] {
+			seen[n.Name] = true
+			labels = append(labels, n.Name)
+		}
+	})
+	return labels
+}
+
+// Fully parenthesized form of e, e.g. "((a | b) & !c)".
+func (e *GroupExpr) String() string {
+	if e == nil {
+		return ""
+	}
+	switch e.Op {
+	case GroupExprGroup, GroupExprPartition:
+		return e.Name
+	case GroupExprNot:
+		return "!" + e.Left.String()
+	default:
+		return "(" + e.Left.String() + " " + e.Op + " " +
+			e.Right.String() + ")"
+	}
+}
+
+func (e *GroupExpr) walk(fn func(*GroupExpr)) {
+	if e == nil {
+		return
+	}
+	fn(e)
+	e.Left.walk(fn)
+	e.Right.walk(fn)
+}
+
+// Recursive descent parser for GroupExpr.  tok is the current token, ""
+// at the end of input, and pos its offset in the input.
+type groupExprParser struct {
+	in  string
+	off int
+	tok string
+	pos int
+}
+
+// Characters allowed in a group label or partition name, see
+// VerifyGroupField.
+func isGroupExprNameChar(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') ||
+		(c >= '0' && c <= '9') || c == '-' || c == ':' || c == '.' ||
+		c == '_'
+}
+
+func (p *groupExprParser) next() {
+	for p.off < len(p.in) && strings.IndexByte(" \t\r\n", p.in[p.off]) >= 0 {
+		p.off++
+	}
+	p.pos = p.off
+	if p.off >= len(p.in) {
+		p.tok = ""
+		return
+	}
+	if !isGroupExprNameChar(p.in[p.off]) {
+		p.tok = p.in[p.off : p.off+1]
+		p.off++
+		return
+	}
+	for p.off < len(p.in) && isGroupExprNameChar(p.in[p.off]) {
+		p.off++
+	}
+	p.tok = p.in[p.pos:p.off]
+}
+
+func (p *groupExprParser) errorf(format string, a ...interface{}) error {
+	return fmt.Errorf("%w at offset %d: %s", ErrGroupExprSyntax, p.pos,
+		fmt.Sprintf(format, a...))
+}
+
+// or := and ('|' and)*
+func (p *groupExprParser) parseOr(depth int) (*GroupExpr, error) {
+	left, err := p.parseAnd(depth)
+	if err != nil {
+		return nil, err
+	}
+	for p.tok == GroupExprOr {
+		p.next()
+		right, err := p.parseAnd(depth)
+		if err != nil {
+			return nil, err
+		}
+		left = &GroupExpr{Op: GroupExprOr, Left: left, Right: right}
+	}
+	return left, nil
+}
+
+// and := unary ('&' unary)*
+func (p *groupExprParser) parseAnd(depth int) (*GroupExpr, error) {
+	left, err := p.parseUnary(depth)
+	if err != nil {
+		return nil, err
+	}
+	for p.tok == GroupExprAnd {
+		p.next()
+		right, err := p.parseUnary(depth)
+		if err != nil {
+			return nil, err
+		}
+		left = &GroupExpr{Op: GroupExprAnd, Left: left, Right: right}
+	}
+	return left, nil
+}
+
+// unary := '!' unary | '(' or ')' | name
+func (p *groupExprParser) parseUnary(depth int) (*GroupExpr, error) {
+	if depth >= GroupExprMaxDepth {
+		return nil, p.errorf("expression nested too deeply")
+	}
+	switch {
+	case p.tok == "":
+		return nil, p.errorf("unexpected end of expression")
+	case p.tok == GroupExprNot:
+		p.next()
+		operand, err := p.parseUnary(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		return &GroupExpr{Op: GroupExprNot, Left: operand}, nil
+	case p.tok == "(":
+		p.next()
+		e, err := p.parseOr(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		if p.tok != ")" {
+			if p.tok == "" {
+				return nil, p.errorf("missing ')'")
+			}
+			return nil, p.errorf("expected ')' but found '%s'", p.tok)
+		}
+		p.next()
+		return e, nil
+	case isGroupExprNameChar(p.tok[0]):
+		name := strings.ToLower(p.tok)
+		p.next()
+		if xnametypes.GetHMSType(name) == xnametypes.Partition {
+			return &GroupExpr{Op: GroupExprPartition, Name: name}, nil
+		}
+		return &GroupExpr{Op: GroupExprGroup, Name: name}, nil
+	default:
+		return nil, p.errorf("unexpected '%s'", p.tok)
+	}
+}
@@ -0,0 +1,119 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package sm
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseGroupExpr(t *testing.T) {
+	tests := []struct {
+		in             string
+		expectedString string
+		expectedGroups []string
+		expectedErr    error
+	}{{
+		in:             "compute",
+		expectedString: "compute",
+		expectedGroups: []string{"compute"},
+	}, {
+		in:             "(a | B) & !c",
+		expectedString: "((a | b) & !c)",
+		expectedGroups: []string{"a", "b", "c"},
+	}, {
+		in:             "a | b & c",
+		expectedString: "(a | (b & c))",
+		expectedGroups: []string{"a", "b", "c"},
+	}, {
+		in:             "a & b & a",
+		expectedString: "((a & b) & a)",
+		expectedGroups: []string{"a", "b"},
+	}, {
+		in:             "gpu-nodes & !!p1.2",
+		expectedString: "(gpu-nodes & !!p1.2)",
+		expectedGroups: []string{"gpu-nodes"},
+	}, {
+		in:             "p1 | p2",
+		expectedString: "(p1 | p2)",
+		expectedGroups: []string{},
+	}, {
+		in:          "",
+		expectedErr: ErrGroupExprSyntax,
+	}, {
+		in:          "(a | b",
+		expectedErr: ErrGroupExprSyntax,
+	}, {
+		in:          "a b",
+		expectedErr: ErrGroupExprSyntax,
+	}, {
+		in:          "a & ",
+		expectedErr: ErrGroupExprSyntax,
+	}, {
+		in:          "a + b",
+		expectedErr: ErrGroupExprSyntax,
+	}, {
+		in:          "a)",
+		expectedErr: ErrGroupExprSyntax,
+	}}
+	for i, test := range tests {
+		e, err := ParseGroupExpr(test.in)
+		if test.expectedErr != nil {
+			if !errors.Is(err, test.expectedErr) {
+				t.Errorf("Test %v Failed: Expected error '%v'; Received error '%v'", i, test.expectedErr, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Test %v Failed: Unexpected error '%v'", i, err)
+			continue
+		}
+		if e.String() != test.expectedString {
+			t.Errorf("Test %v Failed: Expected expression '%s'; Received '%s'", i, test.expectedString, e.String())
+		}
+		if !reflect.DeepEqual(e.Groups(), test.expectedGroups) {
+			t.Errorf("Test %v Failed: Expected groups '%v'; Received '%v'", i, test.expectedGroups, e.Groups())
+		}
+	}
+}
+
+func TestParseGroupExprPartition(t *testing.T) {
+	e, err := ParseGroupExpr("p1.2")
+	if err != nil {
+		t.Fatalf("Unexpected error '%v'", err)
+	}
+	if e.Op != GroupExprPartition || e.Name != "p1.2" {
+		t.Errorf("Expected partition leaf p1.2; Received '%v'", e)
+	}
+}
+
+func TestParseGroupExprDepth(t *testing.T) {
+	in := ""
+	for i := 0; i < GroupExprMaxDepth+1; i++ {
+		in += "("
+	}
+	if _, err := ParseGroupExpr(in + "a"); !errors.Is(err, ErrGroupExprSyntax) {
+		t.Errorf("Expected error '%v'; Received error '%v'", ErrGroupExprSyntax, err)
+	}
+}
@@ -25,9 +25,11 @@ package sm
 // This package defines structures for groups and partitions
 
 import (
+	"encoding/json"
 	"regexp"
 	base "github.com/Cray-HPE/hms-base/v2"
 	"github.com/Cray-HPE/hms-xname/xnametypes"
+	"sort"
 	"strings"
 )
 
@@ -41,6 +43,16 @@ var ErrGroupBadField = base.NewHMSError("sm",
 	"group or partition field has invalid characters")
 var ErrPartBadName = base.NewHMSError("sm",
 	"Bad partition name. Must be p# or p#.#")
+var ErrPartMoveNoIDs = base.NewHMSError("sm",
+	"No member ids to move")
+var ErrGroupBadQuery = base.NewHMSError("sm",
+	"group query has an invalid or empty field")
+var ErrGroupQueryMembers = base.NewHMSError("sm",
+	"group query cannot be combined with members, groups or exclusiveGroup")
+var ErrGroupExclIncludes = base.NewHMSError("sm",
+	"exclusive groups cannot include other groups")
+var ErrGroupSelfInclude = base.NewHMSError("sm",
+	"group cannot include itself")
 
 // Normalize group field by lowercasing
 func NormalizeGroupField(f string) string {
@@ -84,6 +96,14 @@ type MemberAddBody struct {
 	ID string `json:"id"` // xname
 }
 
+// For POST to a group's snapshot endpoint, creating a new static group with
+// the group's current members.
+type GroupSnapshotBody struct {
+	Label       string   `json:"label"`
+	Description string   `json:"description"`
+	Tags        []string `json:"tags,omitempty"`
+}
+
 // Check ids array for xname fitneess.  If no error is returned,
 // the ids are valid.
 func (ms *Members) Verify() error {
@@ -118,13 +138,20 @@ func (ms *Members) Normalize() {
 
 // Component Group, typically nodes.   Like a partition but just a free
 // form collection, not necessarily non-overlapping, and with no predetermined
-// purpose.
+// purpose.  A group may also include other groups, in which case its
+// effective members are its own plus those of every group it includes,
+// transitively.
 type Group struct {
-	Label          string   `json:"label"`
-	Description    string   `json:"description"`
-	ExclusiveGroup string   `json:"exclusiveGroup,omitempty"`
-	Tags           []string `json:"tags,omitempty"`
-	Members        Members  `json:"members"` // List of xnames, required.
+	Label          string      `json:"label"`
+	Description    string      `json:"description"`
+	ExclusiveGroup string      `json:"exclusiveGroup,omitempty"`
+	Tags           []string    `json:"tags,omitempty"`
+	Query          *GroupQuery `json:"query,omitempty"`  // Dynamic groups only
+	Groups         []string    `json:"groups,omitempty"` // Included groups
+	Members        Members     `json:"members"`          // List of xnames, required.
+
+	// Bumped on every change to the group or its members, read-only.
+	Version int64 `json:"version,omitempty"`
 
 	// Private
 	normalized bool
@@ -158,6 +185,10 @@ func (g *Group) Normalize() {
 	for i, f := range g.Tags {
 		g.Tags[i] = strings.ToLower(f)
 	}
+	for i, f := range g.Groups {
+		g.Groups[i] = strings.ToLower(f)
+	}
+	g.Query.Normalize()
 	g.Members.Normalize()
 }
 
@@ -182,21 +213,62 @@ func (g *Group) Verify() error {
 			return err
 		}
 	}
+	if g.Query != nil {
+		// Membership of a dynamic group is computed, never stored.
+		if len(g.Members.IDs) != 0 || len(g.Groups) != 0 ||
+			g.ExclusiveGroup != "" {
+			return ErrGroupQueryMembers
+		}
+		if err := g.Query.Verify(); err != nil {
+			return err
+		}
+	}
+	if len(g.Groups) != 0 && g.ExclusiveGroup != "" {
+		// Members of included groups can't be checked for exclusivity.
+		return ErrGroupExclIncludes
+	}
+	for _, f := range g.Groups {
+		if err := VerifyGroupField(f); err != nil {
+			return err
+		}
+		if f == g.Label {
+			return ErrGroupSelfInclude
+		}
+	}
 	if err := g.Members.Verify(); err != nil {
 		return err
 	}
 	return nil
 }
 
-// Patchable fields if included in payload.
+// Is this a dynamic group, i.e. one whose members are evaluated from Query
+// at read time rather than stored?
+func (g *Group) IsDynamic() bool {
+	return g != nil && g.Query != nil
+}
+
+// Patchable fields if included in payload.  Query may only be patched on
+// a group that is already dynamic.  Groups replaces the full list of
+// included groups.
 type GroupPatch struct {
-	Description *string   `json:"description"`
-	Tags        *[]string `json:"tags"`
+	Description *string     `json:"description"`
+	Tags        *[]string   `json:"tags"`
+	Query       *GroupQuery `json:"query"`
+	Groups      *[]string   `json:"groups"`
+
+	// If non-zero, only patch if the group is still at this version.
+	Version int64 `json:"version,omitempty"`
 }
 
 // Normalize groupPatch (just lower case tags, basically, but keeping same
 // interface as others.
 func (gp *GroupPatch) Normalize() {
+	gp.Query.Normalize()
+	if gp.Groups != nil {
+		for i, f := range *gp.Groups {
+			(*gp.Groups)[i] = strings.ToLower(f)
+		}
+	}
 	if gp.Tags == nil {
 		return
 	}
@@ -207,6 +279,18 @@ func (gp *GroupPatch) Normalize() {
 
 // Analgous Verify call for GroupPatch objects.
 func (gp *GroupPatch) Verify() error {
+	if gp.Query != nil {
+		if err := gp.Query.Verify(); err != nil {
+			return err
+		}
+	}
+	if gp.Groups != nil {
+		for _, f := range *gp.Groups {
+			if err := VerifyGroupField(f); err != nil {
+				return err
+			}
+		}
+	}
 	if gp.Tags == nil {
 		return nil
 	}
@@ -218,6 +302,120 @@ func (gp *GroupPatch) Verify() error {
 	return nil
 }
 
+///////////////////////////////////////////////////////////////////////////
+//
+// Dynamic group queries
+//
+///////////////////////////////////////////////////////////////////////////
+
+// Stored query for a dynamic group.  Each field works like the
+// corresponding ComponentFilter/query parameter: multiple values of the same
+// field are OR'd, different fields are AND'd, and all but Prefix, Partition
+// and Labels allow "!" negation.  Prefix matches components at or below the
+// given xnames, e.g. "x3000c0" matches x3000c0s1b0n0.  Labels are label
+// selectors, which must all match, e.g. "gpu=a100,row in (3,4)".
+type GroupQuery struct {
+	Type      []string `json:"type,omitempty"`
+	State     []string `json:"state,omitempty"`
+	Role      []string `json:"role,omitempty"`
+	SubRole   []string `json:"subrole,omitempty"`
+	Arch      []string `json:"arch,omitempty"`
+	Class     []string `json:"class,omitempty"`
+	Prefix    []string `json:"prefix,omitempty"`
+	Partition []string `json:"partition,omitempty"`
+	Labels    []string `json:"labels,omitempty"`
+
+	// Private
+	normalized bool
+	verified   bool
+}
+
+// Put query values in their canonical form.  Invalid values are left alone
+// so Verify() can reject them.
+func (gq *GroupQuery) Normalize() {
+	if gq == nil || gq.normalized == true {
+		return
+	}
+	gq.normalized = true
+
+	normalizeQueryField(gq.Type, xnametypes.VerifyNormalizeType)
+	normalizeQueryField(gq.State, base.VerifyNormalizeState)
+	normalizeQueryField(gq.Role, base.VerifyNormalizeRole)
+	normalizeQueryField(gq.SubRole, base.VerifyNormalizeSubRole)
+	normalizeQueryField(gq.Arch, base.VerifyNormalizeArch)
+	normalizeQueryField(gq.Class, base.VerifyNormalizeClass)
+	for i, id := range gq.Prefix {
+		gq.Prefix[i] = xnametypes.NormalizeHMSCompID(id)
+	}
+	for i, p := range gq.Partition {
+		gq.Partition[i] = strings.ToLower(p)
+	}
+}
+
+// Check the query's fields.  At least one must be set so that a dynamic
+// group can't silently contain every component in the system.
+func (gq *GroupQuery) Verify() error {
+	if gq == nil || gq.verified == true {
+		return nil
+	}
+	gq.verified = true
+
+	if len(gq.Type) == 0 && len(gq.State) == 0 && len(gq.Role) == 0 &&
+		len(gq.SubRole) == 0 && len(gq.Arch) == 0 && len(gq.Class) == 0 &&
+		len(gq.Prefix) == 0 && len(gq.Partition) == 0 &&
+		len(gq.Labels) == 0 {
+		return ErrGroupBadQuery
+	}
+	fields := []struct {
+		vals []string
+		f    func(string) string
+	}{
+		{gq.Type, xnametypes.VerifyNormalizeType},
+		{gq.State, base.VerifyNormalizeState},
+		{gq.Role, base.VerifyNormalizeRole},
+		{gq.SubRole, base.VerifyNormalizeSubRole},
+		{gq.Arch, base.VerifyNormalizeArch},
+		{gq.Class, base.VerifyNormalizeClass},
+	}
+	for _, field := range fields {
+		for _, val := range field.vals {
+			if field.f(strings.TrimPrefix(val, "!")) == "" {
+				return ErrGroupBadQuery
+			}
+		}
+	}
+	for _, id := range gq.Prefix {
+		if ok := xnametypes.IsHMSCompIDValid(id); ok == false {
+			return ErrGroupBadQuery
+		}
+	}
+	for _, p := range gq.Partition {
+		if xnametypes.GetHMSType(p) != xnametypes.Partition {
+			return ErrPartBadName
+		}
+	}
+	for _, sel := range gq.Labels {
+		if _, err := ParseLabelSelector(sel); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Replace each value with the normalized form returned by f, keeping any
+// "!" negation.
+func normalizeQueryField(vals []string, f func(string) string) {
+	for i, val := range vals {
+		neg := ""
+		if strings.HasPrefix(val, "!") {
+			neg = "!"
+		}
+		if norm := f(strings.TrimPrefix(val, "!")); norm != "" {
+			vals[i] = neg + norm
+		}
+	}
+}
+
 ///////////////////////////////////////////////////////////////////////////
 //
 // Partitions
@@ -232,6 +430,9 @@ type Partition struct {
 	Tags        []string `json:"tags,omitempty"`
 	Members     Members  `json:"members"` // List of xname ids, required.
 
+	// Bumped on every change to the partition or its members, read-only.
+	Version int64 `json:"version,omitempty"`
+
 	// Private
 	normalized bool
 	verified   bool
@@ -287,10 +488,67 @@ func (p *Partition) Verify() error {
 	return nil
 }
 
+// Get the name of the partition a sub-partition belongs to, e.g. p1 for
+// p1.2.  Empty if name is a top-level partition or not a partition name.
+// A sub-partition's members are always a subset of its parent's, and
+// sibling sub-partitions never overlap.
+func PartitionParent(name string) string {
+	name = strings.ToLower(name)
+	if xnametypes.GetHMSType(name) != xnametypes.Partition {
+		return ""
+	}
+	if i := strings.Index(name, "."); i > 0 {
+		return name[:i]
+	}
+	return ""
+}
+
+// True if name is a top-level partition, e.g. p1, which may have
+// sub-partitions.
+func IsTopPartition(name string) bool {
+	name = strings.ToLower(name)
+	return xnametypes.GetHMSType(name) == xnametypes.Partition &&
+		!strings.Contains(name, ".")
+}
+
+// Move members from one sub-partition to a sibling, i.e. one with the same
+// parent.  The destination is given in the URL.
+type PartitionMove struct {
+	Source string   `json:"source"`
+	IDs    []string `json:"ids"`
+}
+
+// Normalize the source name and xnames of a PartitionMove.
+func (pm *PartitionMove) Normalize() {
+	pm.Source = strings.ToLower(pm.Source)
+	for i, id := range pm.IDs {
+		pm.IDs[i] = xnametypes.NormalizeHMSCompID(id)
+	}
+}
+
+// Check the fields of a PartitionMove.
+func (pm *PartitionMove) Verify() error {
+	if PartitionParent(pm.Source) == "" {
+		return ErrPartBadName
+	}
+	if len(pm.IDs) == 0 {
+		return ErrPartMoveNoIDs
+	}
+	for _, id := range pm.IDs {
+		if !xnametypes.IsHMSCompIDValid(id) {
+			return base.ErrHMSTypeInvalid
+		}
+	}
+	return nil
+}
+
 // Patchable fields if included in payload.
 type PartitionPatch struct {
 	Description *string   `json:"description"`
 	Tags        *[]string `json:"tags"`
+
+	// If non-zero, only patch if the partition is still at this version.
+	Version int64 `json:"version,omitempty"`
 }
 
 // Normalize PartitionPatch (just lower case tags, basically, but keeping same
@@ -317,6 +575,86 @@ func (pp *PartitionPatch) Verify() error {
 	return nil
 }
 
+///////////////////////////////////////////////////////////////////////////
+//
+// Group and partition history
+//
+///////////////////////////////////////////////////////////////////////////
+
+// Valid values for group history operations
+const (
+	GroupHistOpCreate       = "Create"
+	GroupHistOpUpdate       = "Update"
+	GroupHistOpDelete       = "Delete"
+	GroupHistOpAddMember    = "AddMember"
+	GroupHistOpRemoveMember = "RemoveMember"
+	GroupHistOpAddGroup     = "AddGroup"    // Member is the included group
+	GroupHistOpRemoveGroup  = "RemoveGroup" // Member is the included group
+)
+
+// For case-insensitive verification and normalization of op strings
+var groupHistOpMap = map[string]string{
+	"create":       GroupHistOpCreate,
+	"update":       GroupHistOpUpdate,
+	"delete":       GroupHistOpDelete,
+	"addmember":    GroupHistOpAddMember,
+	"removemember": GroupHistOpRemoveMember,
+	"addgroup":     GroupHistOpAddGroup,
+	"removegroup":  GroupHistOpRemoveGroup,
+}
+
+// A single change to a group or partition.  Data holds the fields set by a
+// Create or Update.
+type GroupHistoryEntry struct {
+	ID        int64           `json:"id"`
+	Timestamp string          `json:"timestamp"`
+	Name      string          `json:"name"` // Group label or partition name
+	Op        string          `json:"op"`
+	Member    string          `json:"member,omitempty"`
+	Requester string          `json:"requester,omitempty"`
+	Data      json.RawMessage `json:"data,omitempty"`
+}
+
+// Validate and Normalize group history ops used in queries
+func VerifyNormalizeGroupHistOp(op string) string {
+	value, ok := groupHistOpMap[strings.ToLower(op)]
+	if !ok {
+		return ""
+	}
+	return value
+}
+
+// Replay a group or partition's history, oldest first, to get its members
+// after the last entry.  Returns false if it did not exist at that point.
+func MembersFromGroupHistory(entries []*GroupHistoryEntry) (*Members, bool) {
+	exists := false
+	ids := []string{}
+	for _, e := range entries {
+		switch e.Op {
+		case GroupHistOpCreate:
+			exists = true
+			ids = []string{}
+		case GroupHistOpDelete:
+			exists = false
+			ids = []string{}
+		case GroupHistOpAddMember:
+			ids = append(ids, e.Member)
+		case GroupHistOpRemoveMember:
+			for i, id := range ids {
+				if id == e.Member {
+					ids = append(ids[:i], ids[i+1:]...)
+					break
+				}
+			}
+		}
+	}
+	if !exists {
+		return nil, false
+	}
+	sort.Strings(ids)
+	return &Members{IDs: ids}, true
+}
+
 ///////////////////////////////////////////////////////////////////////////
 //
 // Membership - Reverse lookup of group and partition info by component id
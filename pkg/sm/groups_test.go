@@ -285,6 +285,41 @@ func TestVerifyGroup(t *testing.T) {
 			},
 		},
 		expectedOut: base.ErrHMSTypeInvalid,
+	}, {
+		in: &Group{
+			Label:  "my_group",
+			Groups: []string{"compute", "gpu"},
+			Members: Members{
+				IDs: []string{"x0c0s1b0n0"},
+			},
+		},
+		expectedOut: nil,
+	}, {
+		in: &Group{
+			Label:  "my_group",
+			Groups: []string{"compute", "my_group"},
+		},
+		expectedOut: ErrGroupSelfInclude,
+	}, {
+		in: &Group{
+			Label:  "my_group",
+			Groups: []string{"compute!"},
+		},
+		expectedOut: ErrGroupBadField,
+	}, {
+		in: &Group{
+			Label:          "my_group",
+			ExclusiveGroup: "my_system",
+			Groups:         []string{"compute"},
+		},
+		expectedOut: ErrGroupExclIncludes,
+	}, {
+		in: &Group{
+			Label:  "my_group",
+			Groups: []string{"compute"},
+			Query:  &GroupQuery{Role: []string{"Compute"}},
+		},
+		expectedOut: ErrGroupQueryMembers,
 	}}
 	for i, test := range tests {
 		out := test.in.Verify()
@@ -363,6 +398,16 @@ func TestVerifyGroupPatch(t *testing.T) {
 			Tags: &[]string{"Foo", "bar"},
 		},
 		expectedOut: ErrGroupBadField,
+	}, {
+		in: &GroupPatch{
+			Groups: &[]string{"compute", "gpu"},
+		},
+		expectedOut: nil,
+	}, {
+		in: &GroupPatch{
+			Groups: &[]string{"compute", "Gpu"},
+		},
+		expectedOut: ErrGroupBadField,
 	}}
 	for i, test := range tests {
 		out := test.in.Verify()
@@ -383,6 +428,13 @@ func TestNormalizeGroupPatch(t *testing.T) {
 		expectedOut: &GroupPatch{
 			Tags: &[]string{"foo", "bar"},
 		},
+	}, {
+		in: &GroupPatch{
+			Groups: &[]string{"Compute"},
+		},
+		expectedOut: &GroupPatch{
+			Groups: &[]string{"compute"},
+		},
 	}}
 	for i, test := range tests {
 		test.in.Normalize()
@@ -392,6 +444,109 @@ func TestNormalizeGroupPatch(t *testing.T) {
 	}
 }
 
+func TestVerifyGroupQuery(t *testing.T) {
+	tests := []struct {
+		in          *Group
+		expectedOut error
+	}{{
+		in: &Group{
+			Label: "computes",
+			Query: &GroupQuery{
+				Role:      []string{"compute"},
+				State:     []string{"!off"},
+				Prefix:    []string{"x3000c0"},
+				Partition: []string{"p1"},
+			},
+		},
+		expectedOut: nil,
+	}, {
+		in: &Group{
+			Label: "computes",
+			Query: &GroupQuery{},
+		},
+		expectedOut: ErrGroupBadQuery,
+	}, {
+		in: &Group{
+			Label: "computes",
+			Query: &GroupQuery{Arch: []string{"foo"}},
+		},
+		expectedOut: ErrGroupBadQuery,
+	}, {
+		in: &Group{
+			Label: "computes",
+			Query: &GroupQuery{Prefix: []string{"foo"}},
+		},
+		expectedOut: ErrGroupBadQuery,
+	}, {
+		in: &Group{
+			Label: "computes",
+			Query: &GroupQuery{Partition: []string{"part1"}},
+		},
+		expectedOut: ErrPartBadName,
+	}, {
+		in: &Group{
+			Label: "gpus",
+			Query: &GroupQuery{Labels: []string{"gpu in (a100,h100)"}},
+		},
+		expectedOut: nil,
+	}, {
+		in: &Group{
+			Label: "gpus",
+			Query: &GroupQuery{Labels: []string{"gpu in a100"}},
+		},
+		expectedOut: ErrLabelBadSelector,
+	}, {
+		in: &Group{
+			Label:   "computes",
+			Query:   &GroupQuery{Role: []string{"compute"}},
+			Members: Members{IDs: []string{"x0c0s1b0n0"}},
+		},
+		expectedOut: ErrGroupQueryMembers,
+	}, {
+		in: &Group{
+			Label:          "computes",
+			ExclusiveGroup: "my_system",
+			Query:          &GroupQuery{Role: []string{"compute"}},
+		},
+		expectedOut: ErrGroupQueryMembers,
+	}}
+	for i, test := range tests {
+		test.in.Normalize()
+		out := test.in.Verify()
+		if test.expectedOut != out {
+			t.Errorf("Test %v Failed: Expected error '%v'; Received error '%v'", i, test.expectedOut, out)
+		}
+	}
+}
+
+func TestNormalizeGroupQuery(t *testing.T) {
+	in := &GroupQuery{
+		Role:   []string{"compute", "!SERVICE"},
+		State:  []string{"ready"},
+		Prefix: []string{"x3000c00"},
+	}
+	in.Normalize()
+	if !reflect.DeepEqual(in.Role, []string{"Compute", "!Service"}) ||
+		!reflect.DeepEqual(in.State, []string{"Ready"}) ||
+		!reflect.DeepEqual(in.Prefix, []string{"x3000c0"}) {
+		t.Errorf("Test Failed: Unexpected normalized query '%v'", *in)
+	}
+}
+
+func TestNormalizeGroupPatchQueryOnly(t *testing.T) {
+	in := &GroupPatch{
+		Query: &GroupQuery{
+			Role:  []string{"compute"},
+			State: []string{"ready"},
+		},
+	}
+	in.Normalize()
+	if !reflect.DeepEqual(in.Query.Role, []string{"Compute"}) ||
+		!reflect.DeepEqual(in.Query.State, []string{"Ready"}) {
+		t.Errorf("Test Failed: Unexpected normalized query '%v'", *in.Query)
+	}
+}
+
 //
 // Test partitions
 //
@@ -607,3 +762,129 @@ func TestNormalizePartitionPatch(t *testing.T) {
 		}
 	}
 }
+
+func TestPartitionParent(t *testing.T) {
+	tests := []struct {
+		in          string
+		expectedOut string
+		expectedTop bool
+	}{
+		{"p1", "", true},
+		{"P1.2", "p1", false},
+		{"p10.20", "p10", false},
+		{"p1.2.3", "", false},
+		{"part1", "", false},
+		{"", "", false},
+	}
+	for i, test := range tests {
+		out := PartitionParent(test.in)
+		if test.expectedOut != out {
+			t.Errorf("Test %v Failed: Expected parent '%v'; Received parent '%v'", i, test.expectedOut, out)
+		}
+		top := IsTopPartition(test.in)
+		if test.expectedTop != top {
+			t.Errorf("Test %v Failed: Expected top-level '%v'; Received top-level '%v'", i, test.expectedTop, top)
+		}
+	}
+}
+
+func TestVerifyPartitionMove(t *testing.T) {
+	tests := []struct {
+		in          *PartitionMove
+		expectedOut error
+	}{{
+		in:          &PartitionMove{Source: "P1.2", IDs: []string{"X0c0s0b0n0"}},
+		expectedOut: nil,
+	}, {
+		in:          &PartitionMove{Source: "p1", IDs: []string{"x0c0s0b0n0"}},
+		expectedOut: ErrPartBadName,
+	}, {
+		in:          &PartitionMove{Source: "p1.2"},
+		expectedOut: ErrPartMoveNoIDs,
+	}, {
+		in:          &PartitionMove{Source: "p1.2", IDs: []string{"foo"}},
+		expectedOut: base.ErrHMSTypeInvalid,
+	}}
+	for i, test := range tests {
+		test.in.Normalize()
+		out := test.in.Verify()
+		if test.expectedOut != out {
+			t.Errorf("Test %v Failed: Expected error '%v'; Received error '%v'", i, test.expectedOut, out)
+		}
+	}
+}
+
+func TestVerifyNormalizeGroupHistOp(t *testing.T) {
+	tests := []struct {
+		in          string
+		expectedOut string
+	}{
+		{"addmember", GroupHistOpAddMember},
+		{"RemoveMember", GroupHistOpRemoveMember},
+		{"CREATE", GroupHistOpCreate},
+		{"foo", ""},
+	}
+	for i, test := range tests {
+		out := VerifyNormalizeGroupHistOp(test.in)
+		if test.expectedOut != out {
+			t.Errorf("Test %v Failed: Expected op '%v'; Received op '%v'", i, test.expectedOut, out)
+		}
+	}
+}
+
+func TestMembersFromGroupHistory(t *testing.T) {
+	tests := []struct {
+		in          []*GroupHistoryEntry
+		expectedOut *Members
+		expectedOk  bool
+	}{{
+		in:          []*GroupHistoryEntry{},
+		expectedOut: nil,
+		expectedOk:  false,
+	}, {
+		in: []*GroupHistoryEntry{
+			{Op: GroupHistOpCreate},
+			{Op: GroupHistOpAddMember, Member: "x0c0s1b0n0"},
+			{Op: GroupHistOpAddMember, Member: "x0c0s0b0n0"},
+			{Op: GroupHistOpUpdate},
+			{Op: GroupHistOpAddGroup, Member: "grp2"},
+		},
+		expectedOut: &Members{IDs: []string{"x0c0s0b0n0", "x0c0s1b0n0"}},
+		expectedOk:  true,
+	}, {
+		in: []*GroupHistoryEntry{
+			{Op: GroupHistOpCreate},
+			{Op: GroupHistOpAddMember, Member: "x0c0s1b0n0"},
+			{Op: GroupHistOpAddMember, Member: "x0c0s0b0n0"},
+			{Op: GroupHistOpRemoveMember, Member: "x0c0s1b0n0"},
+		},
+		expectedOut: &Members{IDs: []string{"x0c0s0b0n0"}},
+		expectedOk:  true,
+	}, {
+		in: []*GroupHistoryEntry{
+			{Op: GroupHistOpCreate},
+			{Op: GroupHistOpAddMember, Member: "x0c0s1b0n0"},
+			{Op: GroupHistOpDelete},
+		},
+		expectedOut: nil,
+		expectedOk:  false,
+	}, {
+		in: []*GroupHistoryEntry{
+			{Op: GroupHistOpCreate},
+			{Op: GroupHistOpAddMember, Member: "x0c0s1b0n0"},
+			{Op: GroupHistOpDelete},
+			{Op: GroupHistOpCreate},
+			{Op: GroupHistOpAddMember, Member: "x0c0s2b0n0"},
+		},
+		expectedOut: &Members{IDs: []string{"x0c0s2b0n0"}},
+		expectedOk:  true,
+	}}
+	for i, test := range tests {
+		out, ok := MembersFromGroupHistory(test.in)
+		if test.expectedOk != ok {
+			t.Errorf("Test %v Failed: Expected exists '%v'; Received exists '%v'", i, test.expectedOk, ok)
+		} else if !reflect.DeepEqual(test.expectedOut, out) {
+			t.Errorf("Test %v Failed: Expected members '%v'; Received members '%v'", i, test.expectedOut, out)
+		}
+	}
+}
@@ -70,9 +70,10 @@ type hmsTypeArrays struct {
 	NodeEnclosures *[]*HWInvByLoc `json:"NodeEnclosures,omitempty"`
 	HSNBoards      *[]*HWInvByLoc `json:"HSNBoards,omitempty"`
 
-	Processors *[]*HWInvByLoc `json:"Processors,omitempty"`
-	Memory     *[]*HWInvByLoc `json:"Memory,omitempty"`
-	Drives     *[]*HWInvByLoc `json:"Drives,omitempty"`
+	Processors    *[]*HWInvByLoc `json:"Processors,omitempty"`
+	Memory        *[]*HWInvByLoc `json:"Memory,omitempty"`
+	Drives        *[]*HWInvByLoc `json:"Drives,omitempty"`
+	StorageGroups *[]*HWInvByLoc `json:"StorageGroups,omitempty"`
 
 	CabinetPDUs                *[]*HWInvByLoc `json:"CabinetPDUs,omitempty"`
 	CabinetPDUOutlets          *[]*HWInvByLoc `json:"CabinetPDUPowerConnectors,omitempty"`
@@ -81,6 +82,7 @@ type hmsTypeArrays struct {
 	NodeAccelRisers            *[]*HWInvByLoc `json:"NodeAccelRisers,omitempty"`
 	NodeEnclosurePowerSupplies *[]*HWInvByLoc `json:"NodeEnclosurePowerSupplies,omitempty"`
 	NodeHsnNICs                *[]*HWInvByLoc `json:"NodeHsnNics,omitempty"`
+	NodeNICs                   *[]*HWInvByLoc `json:"NodeNICs,omitempty"`
 
 	// These don't have hardware inventory location/FRU info yet,
 	// either because they aren't known yet or because they are manager
@@ -105,7 +107,6 @@ type hmsTypeArrays struct {
 	CabinetPDUNics      *[]*HWInvByLoc `json:"CabinetPDUNics,omitempty"`
 	NodePowerConnectors *[]*HWInvByLoc `json:"NodePowerConnectors,omitempty"`
 	NodeBMCNics         *[]*HWInvByLoc `json:"NodeBMCNics,omitempty"`
-	NodeNICs            *[]*HWInvByLoc `json:"NodeNICs,omitempty"`
 	RouterBMCNics       *[]*HWInvByLoc `json:"RouterBMCNics,omitempty"`
 
 	MgmtSwitches    *[]*HWInvByLoc `json:"MgmtSwitches,omitempty"`
@@ -252,6 +253,18 @@ func NewSystemHWInventory(hwlocs []*HWInvByLoc, xName, format string) (*SystemHW
 				hwinv.NodeHsnNICs = &arr
 			}
 			*hwinv.NodeHsnNICs = append(*hwinv.NodeHsnNICs, hwloc)
+		case xnametypes.NodeNic:
+			if hwinv.NodeNICs == nil {
+				arr := make([]*HWInvByLoc, 0, 1)
+				hwinv.NodeNICs = &arr
+			}
+			*hwinv.NodeNICs = append(*hwinv.NodeNICs, hwloc)
+		case xnametypes.StorageGroup:
+			if hwinv.StorageGroups == nil {
+				arr := make([]*HWInvByLoc, 0, 1)
+				hwinv.StorageGroups = &arr
+			}
+			*hwinv.StorageGroups = append(*hwinv.StorageGroups, hwloc)
 		case xnametypes.CabinetPDU:
 			if hwinv.CabinetPDUs == nil {
 				arr := make([]*HWInvByLoc, 0, 1)
@@ -329,6 +342,8 @@ func NewSystemHWInventory(hwlocs []*HWInvByLoc, xName, format string) (*SystemHW
 		memArray := hwinv.Memory
 		driveArray := hwinv.Drives
 		hsnNicArray := hwinv.NodeHsnNICs
+		nicArray := hwinv.NodeNICs
+		storageGroupArray := hwinv.StorageGroups
 		nodeAccelRiserArray := hwinv.NodeAccelRisers
 		// Moving these contents to underneath items in node array.
 		// Set these arrays to nil so we won't list them twice.
@@ -337,6 +352,8 @@ func NewSystemHWInventory(hwlocs []*HWInvByLoc, xName, format string) (*SystemHW
 		hwinv.Memory = nil
 		hwinv.Drives = nil
 		hwinv.NodeHsnNICs = nil
+		hwinv.NodeNICs = nil
+		hwinv.StorageGroups = nil
 		hwinv.NodeAccelRisers = nil
 
 		// Processors are children of Node
@@ -460,6 +477,54 @@ func NewSystemHWInventory(hwlocs []*HWInvByLoc, xName, format string) (*SystemHW
 			}
 		}
 
+		// NICs (PCIe NetworkControllers) are children of Nodes
+		if nicArray != nil {
+			for _, n := range *nicArray {
+				parentID := xnametypes.GetHMSCompParent(n.ID)
+				parent, ok := nmap[parentID]
+				if !ok {
+					errlog.Printf("ERROR: Could not find node key %s for %s",
+						parentID, n.ID)
+					if hwinv.NodeNICs == nil {
+						arr := make([]*HWInvByLoc, 0, 1)
+						hwinv.NodeNICs = &arr
+					}
+					// Put orphan components back in their array
+					*hwinv.NodeNICs = append(*hwinv.NodeNICs, n)
+				} else {
+					if parent.NodeNICs == nil {
+						arr := make([]*HWInvByLoc, 0, 1)
+						parent.NodeNICs = &arr
+					}
+					*parent.NodeNICs = append(*parent.NodeNICs, n)
+				}
+			}
+		}
+
+		// StorageGroups (PCIe storage controllers) are children of Nodes
+		if storageGroupArray != nil {
+			for _, g := range *storageGroupArray {
+				parentID := xnametypes.GetHMSCompParent(g.ID)
+				parent, ok := nmap[parentID]
+				if !ok {
+					errlog.Printf("ERROR: Could not find node key %s for %s",
+						parentID, g.ID)
+					if hwinv.StorageGroups == nil {
+						arr := make([]*HWInvByLoc, 0, 1)
+						hwinv.StorageGroups = &arr
+					}
+					// Put orphan components back in their array
+					*hwinv.StorageGroups = append(*hwinv.StorageGroups, g)
+				} else {
+					if parent.StorageGroups == nil {
+						arr := make([]*HWInvByLoc, 0, 1)
+						parent.StorageGroups = &arr
+					}
+					*parent.StorageGroups = append(*parent.StorageGroups, g)
+				}
+			}
+		}
+
 		// NodeAccelRisers are children of Nodes
 		if nodeAccelRiserArray != nil {
 			for _, n := range *nodeAccelRiserArray {
@@ -852,6 +917,26 @@ func NewHWInvByLocs(hwlocs []HWInvByLoc) ([]*HWInvByLoc, error) {
 				errlog.Printf("FRUID Error: %s\n", err.Error())
 				errlog.Printf("Using untrackable FRUID: %s\n", hwloc.PopulatedFRU.FRUID)
 			}
+		case xnametypes.NodeNic:
+			if hwloc.HMSNodeNICLocationInfo == nil {
+				return hls, ErrHWInvMissingLoc
+			}
+			if hwloc.PopulatedFRU.HMSNodeNICFRUInfo == nil {
+				return hls, ErrHWInvMissingFRUInfo
+			}
+			hwloc.HWInventoryByLocationType = HWInvByLocNodeNIC
+			hwloc.PopulatedFRU.HWInventoryByFRUType = HWInvByFRUNodeNIC
+			hwloc.PopulatedFRU.FRUID = rf.GetPCIeDeviceFRUID(hwloc.Type, hwloc.ID, hwloc.PopulatedFRU.HMSNodeNICFRUInfo)
+		case xnametypes.StorageGroup:
+			if hwloc.HMSStorageGroupLocationInfo == nil {
+				return hls, ErrHWInvMissingLoc
+			}
+			if hwloc.PopulatedFRU.HMSStorageGroupFRUInfo == nil {
+				return hls, ErrHWInvMissingFRUInfo
+			}
+			hwloc.HWInventoryByLocationType = HWInvByLocStorageGroup
+			hwloc.PopulatedFRU.HWInventoryByFRUType = HWInvByFRUStorageGroup
+			hwloc.PopulatedFRU.FRUID = rf.GetPCIeDeviceFRUID(hwloc.Type, hwloc.ID, hwloc.PopulatedFRU.HMSStorageGroupFRUInfo)
 		case xnametypes.CabinetPDU:
 			if hwloc.HMSPDULocationInfo == nil {
 				return hls, ErrHWInvMissingLoc
@@ -1056,11 +1141,23 @@ type HWInvByLoc struct {
 	HMSNodeBMCLocationInfo                  *rf.ManagerLocationInfoRF         `json:"NodeBMCLocationInfo,omitempty"`
 	HMSRouterBMCLocationInfo                *rf.ManagerLocationInfoRF         `json:"RouterBMCLocationInfo,omitempty"`
 	HMSNodeAccelRiserLocationInfo           *rf.NodeAccelRiserLocationInfoRF  `json:"NodeAccelRiserLocationInfo,omitempty"`
+
+	// Based on Redfish PCIeDevice, typed by the class of its first function.
+	HMSNodeNICLocationInfo      *rf.PCIeDeviceLocationInfoRF `json:"NodeNicLocationInfo,omitempty"`
+	HMSStorageGroupLocationInfo *rf.PCIeDeviceLocationInfoRF `json:"StorageGroupLocationInfo,omitempty"`
 	// TODO: Remaining types in hmsTypeArrays
 
 	// If status != empty, up to one of following, matching above *Info.
 	PopulatedFRU *HWInvByFRU `json:"PopulatedFRU,omitempty"`
 
+	// Firmware/software targets discovered via the Redfish UpdateService
+	// that apply to this location.  Not stored with the location itself.
+	Firmware []*HWInvFirmware `json:"Firmware,omitempty"`
+
+	// Fans discovered under the Redfish Chassis for this location.  Fans
+	// have no xname of their own, so are not stored with the location.
+	Fans []*HWInvFan `json:"Fans,omitempty"`
+
 	// These are for nested references for subcomponents.
 	hmsTypeArrays
 }
@@ -1083,6 +1180,8 @@ const (
 	HWInvByLocDrive                    string = "HWInvByLocDrive"
 	HWInvByLocMemory                   string = "HWInvByLocMemory"
 	HWInvByLocHSNNIC                   string = "HWInvByLocNodeHsnNic"
+	HWInvByLocNodeNIC                  string = "HWInvByLocNodeNic"
+	HWInvByLocStorageGroup             string = "HWInvByLocStorageGroup"
 	HWInvByLocPDU                      string = "HWInvByLocPDU"
 	HWInvByLocOutlet                   string = "HWInvByLocOutlet"
 	HWInvByLocCMMRectifier             string = "HWInvByLocCMMRectifier"
@@ -1115,6 +1214,7 @@ func (hw *HWInvByLoc) DecodeLocationInfo(locInfoJSON []byte) error {
 		rfDriveLocationInfo                    *rf.DriveLocationInfoRF
 		rfMemoryLocationInfo                   *rf.MemoryLocationInfoRF
 		rfHSNNICLocationInfo                   *rf.NALocationInfoRF
+		rfPCIeDeviceLocationInfo               *rf.PCIeDeviceLocationInfoRF
 		rfPDULocationInfo                      *rf.PowerDistributionLocationInfo
 		rfOutletLocationInfo                   *rf.OutletLocationInfo
 		rfCMMRectifierLocationInfo             *rf.PowerSupplyLocationInfoRF
@@ -1225,6 +1325,22 @@ func (hw *HWInvByLoc) DecodeLocationInfo(locInfoJSON []byte) error {
 			hw.HMSHSNNICLocationInfo = rfHSNNICLocationInfo
 			hw.HWInventoryByLocationType = HWInvByLocHSNNIC
 		}
+	// HWInv based on Redfish "PCIeDevice" Type (NetworkController class).
+	case xnametypes.NodeNic:
+		rfPCIeDeviceLocationInfo = new(rf.PCIeDeviceLocationInfoRF)
+		err = json.Unmarshal(locInfoJSON, rfPCIeDeviceLocationInfo)
+		if err == nil {
+			hw.HMSNodeNICLocationInfo = rfPCIeDeviceLocationInfo
+			hw.HWInventoryByLocationType = HWInvByLocNodeNIC
+		}
+	// HWInv based on Redfish "PCIeDevice" Type (MassStorageController class).
+	case xnametypes.StorageGroup:
+		rfPCIeDeviceLocationInfo = new(rf.PCIeDeviceLocationInfoRF)
+		err = json.Unmarshal(locInfoJSON, rfPCIeDeviceLocationInfo)
+		if err == nil {
+			hw.HMSStorageGroupLocationInfo = rfPCIeDeviceLocationInfo
+			hw.HWInventoryByLocationType = HWInvByLocStorageGroup
+		}
 	// HWInv based on Redfish "PowerDistribution" (aka PDU) Type.
 	case xnametypes.CabinetPDU:
 		rfPDULocationInfo = new(rf.PowerDistributionLocationInfo)
@@ -1338,6 +1454,11 @@ func (hw *HWInvByLoc) EncodeLocationInfo() ([]byte, error) {
 	// HWInv based on Redfish "HSN NIC" Type.
 	case xnametypes.NodeHsnNic:
 		locInfoJSON, err = json.Marshal(hw.HMSHSNNICLocationInfo)
+	// HWInv based on Redfish "PCIeDevice" Type.
+	case xnametypes.NodeNic:
+		locInfoJSON, err = json.Marshal(hw.HMSNodeNICLocationInfo)
+	case xnametypes.StorageGroup:
+		locInfoJSON, err = json.Marshal(hw.HMSStorageGroupLocationInfo)
 	// HWInv based on Redfish "PowerDistribution" (aka PDU) Type.
 	case xnametypes.CabinetPDU:
 		locInfoJSON, err = json.Marshal(hw.HMSPDULocationInfo)
@@ -1411,6 +1532,10 @@ type HWInvByFRU struct {
 	HMSRouterBMCFRUInfo                *rf.ManagerFRUInfoRF         `json:"RouterBMCFRUInfo,omitempty"`
 	HMSNodeAccelRiserFRUInfo           *rf.NodeAccelRiserFRUInfoRF  `json:"NodeAccelRiserFRUInfo,omitempty"`
 
+	// Based on Redfish PCIeDevice, typed by the class of its first function.
+	HMSNodeNICFRUInfo      *rf.PCIeDeviceFRUInfoRF `json:"NodeNicFRUInfo,omitempty"`
+	HMSStorageGroupFRUInfo *rf.PCIeDeviceFRUInfoRF `json:"StorageGroupFRUInfo,omitempty"`
+
 	// TODO: Remaining types in hmsTypeArray
 }
 
@@ -1433,6 +1558,8 @@ const (
 	HWInvByFRUMemory                   string = "HWInvByFRUMemory"
 	HWInvByFRUDrive                    string = "HWInvByFRUDrive"
 	HWInvByFRUHSNNIC                   string = "HWInvByFRUNodeHsnNic"
+	HWInvByFRUNodeNIC                  string = "HWInvByFRUNodeNic"
+	HWInvByFRUStorageGroup             string = "HWInvByFRUStorageGroup"
 	HWInvByFRUPDU                      string = "HWInvByFRUPDU"
 	HWInvByFRUOutlet                   string = "HWInvByFRUOutlet"
 	HWInvByFRUCMMRectifier             string = "HWInvByFRUCMMRectifier"
@@ -1465,6 +1592,7 @@ func (hf *HWInvByFRU) DecodeFRUInfo(fruInfoJSON []byte) error {
 		rfMemoryFRUInfo                   *rf.MemoryFRUInfoRF
 		rfDriveFRUInfo                    *rf.DriveFRUInfoRF
 		rfHSNNICFRUInfo                   *rf.NAFRUInfoRF
+		rfPCIeDeviceFRUInfo               *rf.PCIeDeviceFRUInfoRF
 		rfPDUFRUInfo                      *rf.PowerDistributionFRUInfo
 		rfOutletFRUInfo                   *rf.OutletFRUInfo
 		rfCMMRectifierFRUInfo             *rf.PowerSupplyFRUInfoRF
@@ -1575,6 +1703,22 @@ func (hf *HWInvByFRU) DecodeFRUInfo(fruInfoJSON []byte) error {
 			hf.HMSHSNNICFRUInfo = rfHSNNICFRUInfo
 			hf.HWInventoryByFRUType = HWInvByFRUHSNNIC
 		}
+	// HWInv based on Redfish "PCIeDevice" Type (NetworkController class).
+	case xnametypes.NodeNic:
+		rfPCIeDeviceFRUInfo = new(rf.PCIeDeviceFRUInfoRF)
+		err = json.Unmarshal(fruInfoJSON, rfPCIeDeviceFRUInfo)
+		if err == nil {
+			hf.HMSNodeNICFRUInfo = rfPCIeDeviceFRUInfo
+			hf.HWInventoryByFRUType = HWInvByFRUNodeNIC
+		}
+	// HWInv based on Redfish "PCIeDevice" Type (MassStorageController class).
+	case xnametypes.StorageGroup:
+		rfPCIeDeviceFRUInfo = new(rf.PCIeDeviceFRUInfoRF)
+		err = json.Unmarshal(fruInfoJSON, rfPCIeDeviceFRUInfo)
+		if err == nil {
+			hf.HMSStorageGroupFRUInfo = rfPCIeDeviceFRUInfo
+			hf.HWInventoryByFRUType = HWInvByFRUStorageGroup
+		}
 	// HWInv based on Redfish "PowerDistribution" Type.
 	case xnametypes.CabinetPDU:
 		rfPDUFRUInfo = new(rf.PowerDistributionFRUInfo)
@@ -1693,6 +1837,11 @@ func (hf *HWInvByFRU) EncodeFRUInfo() ([]byte, error) {
 	// HWInv based on Redfish "HSN NIC" Type.
 	case xnametypes.NodeHsnNic:
 		fruInfoJSON, err = json.Marshal(hf.HMSHSNNICFRUInfo)
+	// HWInv based on Redfish "PCIeDevice" Type.
+	case xnametypes.NodeNic:
+		fruInfoJSON, err = json.Marshal(hf.HMSNodeNICFRUInfo)
+	case xnametypes.StorageGroup:
+		fruInfoJSON, err = json.Marshal(hf.HMSStorageGroupFRUInfo)
 	// HWInv based on Redfish "PowerDistribution" (aka PDU) Type.
 	case xnametypes.CabinetPDU:
 		fruInfoJSON, err = json.Marshal(hf.HMSPDUFRUInfo)
@@ -76,6 +76,67 @@ func TestNewSystemHWInventory(t *testing.T) {
 	t.Log("Test 4 PASS")
 }
 
+func TestNewSystemHWInventoryPCIe(t *testing.T) {
+	// Nesting modifies the parent, so use a copy.
+	node := stest.NodeHWInvByLoc1
+	nic := stest.NodeNicHWInvByLoc1
+	sg := stest.StorageGroupHWInvByLoc1
+	hwlocs := []*sm.HWInvByLoc{&node, &nic, &sg}
+
+	hwinv, err := sm.NewSystemHWInventory(hwlocs, "s0", sm.HWInvFormatFullyFlat)
+	if err != nil {
+		t.Fatalf("Test 1 Failed: Got error '%s'", err)
+	}
+	if hwinv.NodeNICs == nil || len(*hwinv.NodeNICs) != 1 ||
+		hwinv.StorageGroups == nil || len(*hwinv.StorageGroups) != 1 {
+		t.Errorf("Test 1 Failed: Expected top-level NodeNICs and StorageGroups")
+	}
+	hwinv, err = sm.NewSystemHWInventory(hwlocs, "s0", sm.HWInvFormatNestNodesOnly)
+	if err != nil {
+		t.Fatalf("Test 2 Failed: Got error '%s'", err)
+	}
+	if hwinv.NodeNICs != nil || hwinv.StorageGroups != nil {
+		t.Errorf("Test 2 Failed: Expected no top-level NodeNICs or StorageGroups")
+	}
+	if node.NodeNICs == nil || len(*node.NodeNICs) != 1 || (*node.NodeNICs)[0].ID != nic.ID {
+		t.Errorf("Test 2 Failed: NodeNic not nested under node")
+	}
+	if node.StorageGroups == nil || len(*node.StorageGroups) != 1 || (*node.StorageGroups)[0].ID != sg.ID {
+		t.Errorf("Test 2 Failed: StorageGroup not nested under node")
+	}
+}
+
+func TestEncodeDecodePCIe(t *testing.T) {
+	for i, hwloc := range stest.HWInvByLocPCIeArray1 {
+		bytes, err := hwloc.EncodeLocationInfo()
+		if err != nil || len(bytes) == 0 || string(bytes) == "null" {
+			t.Errorf("Test %d Failed: Got error '%v' or no location info", i, err)
+			continue
+		}
+		out := sm.HWInvByLoc{ID: hwloc.ID, Type: hwloc.Type}
+		if err := out.DecodeLocationInfo(bytes); err != nil {
+			t.Errorf("Test %d Failed: Got error '%s'", i, err)
+		} else if out.HWInventoryByLocationType != hwloc.HWInventoryByLocationType {
+			t.Errorf("Test %d Failed: Expected '%s', got '%s'", i,
+				hwloc.HWInventoryByLocationType, out.HWInventoryByLocationType)
+		}
+	}
+	for i, hwfru := range stest.HWInvByFRUPCIeArray1 {
+		bytes, err := hwfru.EncodeFRUInfo()
+		if err != nil || len(bytes) == 0 || string(bytes) == "null" {
+			t.Errorf("Test %d Failed: Got error '%v' or no FRU info", i, err)
+			continue
+		}
+		out := sm.HWInvByFRU{FRUID: hwfru.FRUID, Type: hwfru.Type}
+		if err := out.DecodeFRUInfo(bytes); err != nil {
+			t.Errorf("Test %d Failed: Got error '%s'", i, err)
+		} else if out.HWInventoryByFRUType != hwfru.HWInventoryByFRUType {
+			t.Errorf("Test %d Failed: Expected '%s', got '%s'", i,
+				hwfru.HWInventoryByFRUType, out.HWInventoryByFRUType)
+		}
+	}
+}
+
 func TestEncodeLocationInfo(t *testing.T) {
 	for i, hwloc := range stest.HWInvByLocArray1 {
 		bytes, err := hwloc.EncodeLocationInfo()
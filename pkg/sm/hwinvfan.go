@@ -0,0 +1,104 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package sm
+
+import (
+	base "github.com/Cray-HPE/hms-base/v2"
+	rf "github.com/Cray-HPE/hms-smd/v2/pkg/redfish"
+	"github.com/Cray-HPE/hms-xname/xnametypes"
+)
+
+var ErrHWFanInvalid = base.NewHMSError("sm", "Fan entry has an invalid ID, RedfishEndpointID or ParentID")
+
+// A single fan discovered under a Redfish Chassis' Thermal or
+// ThermalSubsystem.  There is no xname type for fans, so they are tracked
+// by the xname of the component they are installed in (ParentID) plus
+// their Redfish Id.
+type HWInvFan struct {
+	ID              string `json:"ID"`       // Redfish Id of the fan
+	ParentID        string `json:"ParentID"` // xname of the component it is in
+	ParentType      string `json:"ParentType"`
+	RfEndpointID    string `json:"RedfishEndpointID"` // BMC it was reported by
+	Name            string `json:"Name,omitempty"`
+	PhysicalContext string `json:"PhysicalContext,omitempty"`
+	ServiceLabel    string `json:"ServiceLabel,omitempty"`
+	Status          string `json:"Status"` // Populated or Empty
+	Health          string `json:"Health,omitempty"`
+	Flag            string `json:"Flag"`
+	FRUID           string `json:"FRUID,omitempty"`
+	Manufacturer    string `json:"Manufacturer,omitempty"`
+	Model           string `json:"Model,omitempty"`
+	PartNumber      string `json:"PartNumber,omitempty"`
+	SparePartNumber string `json:"SparePartNumber,omitempty"`
+	SerialNumber    string `json:"SerialNumber,omitempty"`
+	OdataID         string `json:"OdataID"`
+	LastUpdate      string `json:"LastUpdate,omitempty"`
+}
+
+type HWInvFanArray struct {
+	Fans []*HWInvFan `json:"Fans"`
+}
+
+// Create a new HWInvFan entry from a discovered fan.  Returns nil if the
+// fan was not discovered successfully.
+func NewHWInvFan(f *rf.EpFan) *HWInvFan {
+	if f == nil || f.LastStatus != rf.DiscoverOK || f.FanRF == nil {
+		return nil
+	}
+	fan := new(HWInvFan)
+	fan.ID = f.FanID
+	fan.ParentID = f.ParentID
+	fan.ParentType = xnametypes.GetHMSType(f.ParentID).String()
+	fan.RfEndpointID = f.RfEndpointID
+	fan.Name = f.FanRF.Name
+	fan.PhysicalContext = f.FanRF.PhysicalContext
+	if f.FanRF.Location != nil && f.FanRF.Location.PartLocation != nil {
+		fan.ServiceLabel = f.FanRF.Location.PartLocation.ServiceLabel
+	}
+	fan.Status = f.Status
+	fan.Health = string(f.FanRF.Status.Health)
+	fan.Flag = f.Flag
+	if f.Status != "Empty" {
+		fan.FRUID = f.FRUID
+		fan.Manufacturer = f.FanRF.Manufacturer
+		fan.Model = f.FanRF.Model
+		fan.PartNumber = f.FanRF.PartNumber
+		fan.SparePartNumber = f.FanRF.SparePartNumber
+		fan.SerialNumber = f.FanRF.SerialNumber
+	}
+	fan.OdataID = f.OdataID
+	return fan
+}
+
+// Check and normalize the fields that key a fan entry.
+func (fan *HWInvFan) VerifyNormalize() error {
+	if fan.ID == "" {
+		return ErrHWFanInvalid
+	}
+	fan.RfEndpointID = xnametypes.VerifyNormalizeCompID(fan.RfEndpointID)
+	fan.ParentID = xnametypes.VerifyNormalizeCompID(fan.ParentID)
+	if fan.RfEndpointID == "" || fan.ParentID == "" {
+		return ErrHWFanInvalid
+	}
+	return nil
+}
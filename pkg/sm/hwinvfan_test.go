@@ -0,0 +1,124 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package sm
+
+import (
+	"reflect"
+	"testing"
+
+	rf "github.com/Cray-HPE/hms-smd/v2/pkg/redfish"
+)
+
+//
+// Test HWInventory Fan functions
+//
+func TestNewHWInvFan(t *testing.T) {
+	f := &rf.EpFan{
+		ComponentDescription: rf.ComponentDescription{
+			Type:         rf.FanType,
+			RedfishType:  rf.FanType,
+			OdataID:      "/redfish/v1/Chassis/Enclosure/ThermalSubsystem/Fans/Fan1",
+			RfEndpointID: "x0c0s0b0",
+		},
+		InventoryData: rf.InventoryData{
+			Status: "Populated",
+			Flag:   "Warning",
+			FRUID:  "Fan.Delta.FT100.FAN1SN",
+		},
+		FanID:      "Fan1",
+		ParentID:   "x0c0s0e0",
+		LastStatus: rf.DiscoverOK,
+		FanRF: &rf.Fan{
+			FanLocationInfoRF: rf.FanLocationInfoRF{
+				Id:   "Fan1",
+				Name: "Fan Tray 1",
+				Location: &rf.Location{
+					PartLocation: &rf.PartLocation{ServiceLabel: "FT1"},
+				},
+			},
+			FanFRUInfoRF: rf.FanFRUInfoRF{
+				Manufacturer: "Delta",
+				PartNumber:   "FT-100",
+				SerialNumber: "FAN1SN",
+			},
+			Status: rf.StatusRF{State: "Enabled", Health: "Warning"},
+		},
+	}
+	expected := &HWInvFan{
+		ID:           "Fan1",
+		ParentID:     "x0c0s0e0",
+		ParentType:   "NodeEnclosure",
+		RfEndpointID: "x0c0s0b0",
+		Name:         "Fan Tray 1",
+		ServiceLabel: "FT1",
+		Status:       "Populated",
+		Health:       "Warning",
+		Flag:         "Warning",
+		FRUID:        "Fan.Delta.FT100.FAN1SN",
+		Manufacturer: "Delta",
+		PartNumber:   "FT-100",
+		SerialNumber: "FAN1SN",
+		OdataID:      "/redfish/v1/Chassis/Enclosure/ThermalSubsystem/Fans/Fan1",
+	}
+	fan := NewHWInvFan(f)
+	if !reflect.DeepEqual(expected, fan) {
+		t.Errorf("Test 1 Failed: Expected '%v'; Received '%v'", expected, fan)
+	}
+
+	f.LastStatus = rf.HTTPsGetFailed
+	if fan := NewHWInvFan(f); fan != nil {
+		t.Errorf("Test 2 Failed: Expected nil for undiscovered fan; Received '%v'", fan)
+	}
+	if fan := NewHWInvFan(nil); fan != nil {
+		t.Errorf("Test 3 Failed: Expected nil for nil fan; Received '%v'", fan)
+	}
+}
+
+func TestHWInvFanVerifyNormalize(t *testing.T) {
+	tests := []struct {
+		fan            HWInvFan
+		expectedEP     string
+		expectedParent string
+		expectedErr    error
+	}{{
+		fan:            HWInvFan{ID: "Fan1", RfEndpointID: "X0C0S0B0", ParentID: "x0c0s0e00"},
+		expectedEP:     "x0c0s0b0",
+		expectedParent: "x0c0s0e0",
+		expectedErr:    nil,
+	}, {
+		fan:         HWInvFan{ID: "", RfEndpointID: "x0c0s0b0", ParentID: "x0c0s0e0"},
+		expectedErr: ErrHWFanInvalid,
+	}, {
+		fan:         HWInvFan{ID: "Fan1", RfEndpointID: "x0c0s0b0", ParentID: "foo"},
+		expectedErr: ErrHWFanInvalid,
+	}}
+	for i, test := range tests {
+		err := test.fan.VerifyNormalize()
+		if err != test.expectedErr {
+			t.Errorf("Test %d Failed: Expected error '%v'; Received '%v'", i, test.expectedErr, err)
+		} else if err == nil && (test.fan.RfEndpointID != test.expectedEP || test.fan.ParentID != test.expectedParent) {
+			t.Errorf("Test %d Failed: Expected '%s/%s'; Received '%s/%s'", i,
+				test.expectedEP, test.expectedParent, test.fan.RfEndpointID, test.fan.ParentID)
+		}
+	}
+}
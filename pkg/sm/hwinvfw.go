@@ -0,0 +1,91 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package sm
+
+import (
+	base "github.com/Cray-HPE/hms-base/v2"
+	"github.com/Cray-HPE/hms-xname/xnametypes"
+	rf "github.com/Cray-HPE/hms-smd/v2/pkg/redfish"
+)
+
+var ErrHWFirmwareInvalid = base.NewHMSError("sm", "Firmware entry has an invalid ID, RedfishEndpointID or RelatedID")
+
+// A single firmware or software target, i.e. a member of the Redfish
+// UpdateService's FirmwareInventory or SoftwareInventory collections, along
+// with the xname of the component it applies to.
+type HWInvFirmware struct {
+	ID           string `json:"ID"`                // Redfish Id of the target
+	RfEndpointID string `json:"RedfishEndpointID"` // BMC it was reported by
+	Inventory    string `json:"Inventory"`         // FirmwareInventory or SoftwareInventory
+	RelatedID    string `json:"RelatedID"`         // xname of the component it applies to
+	RelatedType  string `json:"RelatedType"`
+	Name         string `json:"Name,omitempty"`
+	Version      string `json:"Version"`
+	Updateable   bool   `json:"Updateable"`
+	SoftwareId   string `json:"SoftwareId,omitempty"`
+	Manufacturer string `json:"Manufacturer,omitempty"`
+	ReleaseDate  string `json:"ReleaseDate,omitempty"`
+	OdataID      string `json:"OdataID"`
+	LastUpdate   string `json:"LastUpdate,omitempty"`
+}
+
+type HWInvFirmwareArray struct {
+	Firmware []*HWInvFirmware `json:"Firmware"`
+}
+
+// Create a new HWInvFirmware entry from a discovered firmware or software
+// target.  Returns nil if the target was not discovered successfully.
+func NewHWInvFirmware(si *rf.EpSoftwareInventory) *HWInvFirmware {
+	if si == nil || si.LastStatus != rf.DiscoverOK {
+		return nil
+	}
+	fw := new(HWInvFirmware)
+	fw.ID = si.ID
+	fw.RfEndpointID = si.RfEndpointID
+	fw.Inventory = si.Inventory
+	fw.RelatedID = si.RelatedID
+	fw.RelatedType = si.RelatedType
+	fw.Name = si.SoftwareInventoryRF.Name
+	fw.Version = si.SoftwareInventoryRF.Version
+	if si.SoftwareInventoryRF.Updateable != nil {
+		fw.Updateable = *si.SoftwareInventoryRF.Updateable
+	}
+	fw.SoftwareId = si.SoftwareInventoryRF.SoftwareId
+	fw.Manufacturer = si.SoftwareInventoryRF.Manufacturer
+	fw.ReleaseDate = si.SoftwareInventoryRF.ReleaseDate
+	fw.OdataID = si.OdataID
+	return fw
+}
+
+// Check and normalize the fields that key a firmware entry.
+func (fw *HWInvFirmware) VerifyNormalize() error {
+	if fw.ID == "" {
+		return ErrHWFirmwareInvalid
+	}
+	fw.RfEndpointID = xnametypes.VerifyNormalizeCompID(fw.RfEndpointID)
+	fw.RelatedID = xnametypes.VerifyNormalizeCompID(fw.RelatedID)
+	if fw.RfEndpointID == "" || fw.RelatedID == "" {
+		return ErrHWFirmwareInvalid
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package sm
+
+import (
+	"reflect"
+	"testing"
+
+	rf "github.com/Cray-HPE/hms-smd/v2/pkg/redfish"
+)
+
+//
+// Test HWInventory Firmware functions
+//
+func TestNewHWInvFirmware(t *testing.T) {
+	updateable := true
+	si := &rf.EpSoftwareInventory{
+		ID:           "BMC",
+		RedfishType:  rf.SoftwareInventoryType,
+		OdataID:      "/redfish/v1/UpdateService/FirmwareInventory/BMC",
+		RfEndpointID: "x0c0s0b0",
+		Inventory:    rf.FirmwareInventoryName,
+		RelatedID:    "x0c0s0b0",
+		RelatedType:  "NodeBMC",
+		LastStatus:   rf.DiscoverOK,
+		SoftwareInventoryRF: rf.SoftwareInventory{
+			Id:           "BMC",
+			Name:         "BMC Firmware",
+			Version:      "1.2.3",
+			Updateable:   &updateable,
+			Manufacturer: "Cray",
+		},
+	}
+	expected := &HWInvFirmware{
+		ID:           "BMC",
+		RfEndpointID: "x0c0s0b0",
+		Inventory:    rf.FirmwareInventoryName,
+		RelatedID:    "x0c0s0b0",
+		RelatedType:  "NodeBMC",
+		Name:         "BMC Firmware",
+		Version:      "1.2.3",
+		Updateable:   true,
+		Manufacturer: "Cray",
+		OdataID:      "/redfish/v1/UpdateService/FirmwareInventory/BMC",
+	}
+	fw := NewHWInvFirmware(si)
+	if !reflect.DeepEqual(expected, fw) {
+		t.Errorf("Test 1 Failed: Expected '%v'; Received '%v'", expected, fw)
+	}
+
+	si.LastStatus = rf.HTTPsGetFailed
+	if fw := NewHWInvFirmware(si); fw != nil {
+		t.Errorf("Test 2 Failed: Expected nil for undiscovered target; Received '%v'", fw)
+	}
+	if fw := NewHWInvFirmware(nil); fw != nil {
+		t.Errorf("Test 3 Failed: Expected nil for nil target; Received '%v'", fw)
+	}
+}
+
+func TestHWInvFirmwareVerifyNormalize(t *testing.T) {
+	tests := []struct {
+		fw          HWInvFirmware
+		expectedEP  string
+		expectedRel string
+		expectedErr error
+	}{{
+		fw:          HWInvFirmware{ID: "BIOS", RfEndpointID: "X0C0S0B0", RelatedID: "x0c0s0b0n00"},
+		expectedEP:  "x0c0s0b0",
+		expectedRel: "x0c0s0b0n0",
+		expectedErr: nil,
+	}, {
+		fw:          HWInvFirmware{ID: "", RfEndpointID: "x0c0s0b0", RelatedID: "x0c0s0b0n0"},
+		expectedErr: ErrHWFirmwareInvalid,
+	}, {
+		fw:          HWInvFirmware{ID: "BIOS", RfEndpointID: "x0c0s0b0", RelatedID: "foo"},
+		expectedErr: ErrHWFirmwareInvalid,
+	}}
+	for i, test := range tests {
+		err := test.fw.VerifyNormalize()
+		if err != test.expectedErr {
+			t.Errorf("Test %d Failed: Expected error '%v'; Received '%v'", i, test.expectedErr, err)
+		} else if err == nil && (test.fw.RfEndpointID != test.expectedEP || test.fw.RelatedID != test.expectedRel) {
+			t.Errorf("Test %d Failed: Expected '%s/%s'; Received '%s/%s'", i,
+				test.expectedEP, test.expectedRel, test.fw.RfEndpointID, test.fw.RelatedID)
+		}
+	}
+}
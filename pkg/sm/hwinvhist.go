@@ -36,6 +36,12 @@ const (
 	HWInvHistEventTypeRemoved  = "Removed"
 	HWInvHistEventTypeScanned  = "Scanned"
 	HWInvHistEventTypeDetected = "Detected"
+
+	// The firmware version of a target at this location changed.
+	HWInvHistEventTypeFirmwareUpdated = "FirmwareUpdated"
+
+	// A fan at this location was installed, removed or replaced.
+	HWInvHistEventTypeFanReplaced = "FanReplaced"
 )
 
 // For case-insensitive verification and normalization of state strings
@@ -44,6 +50,9 @@ var hwInvHistEventTypeMap = map[string]string{
 	"removed":  HWInvHistEventTypeRemoved,
 	"scanned":  HWInvHistEventTypeScanned,
 	"detected": HWInvHistEventTypeDetected,
+
+	"firmwareupdated": HWInvHistEventTypeFirmwareUpdated,
+	"fanreplaced":     HWInvHistEventTypeFanReplaced,
 }
 
 type HWInvHistFmt int
@@ -110,3 +119,57 @@ func VerifyNormalizeHWInvHistEventType(eventType string) string {
 		return value
 	}
 }
+
+// Compare the locations previously stored for part of the system with
+// those just read for it.  Returns the IDs of the old locations that are no
+// longer present, and Removed and Added history events for every FRU that
+// left or arrived at a location, in that order.
+func DiffHWInvByLocs(oldLocs, newLocs []*HWInvByLoc) ([]string, []*HWInvHist) {
+	gone := []string{}
+	hists := []*HWInvHist{}
+	added := []*HWInvHist{}
+
+	newMap := make(map[string]*HWInvByLoc, len(newLocs))
+	for _, hl := range newLocs {
+		if hl != nil {
+			newMap[hl.ID] = hl
+		}
+	}
+	oldMap := make(map[string]*HWInvByLoc, len(oldLocs))
+	for _, hl := range oldLocs {
+		if hl == nil {
+			continue
+		}
+		oldMap[hl.ID] = hl
+		newHL, ok := newMap[hl.ID]
+		if !ok {
+			gone = append(gone, hl.ID)
+		}
+		if hl.PopulatedFRU == nil {
+			continue
+		}
+		if !ok || newHL.PopulatedFRU == nil ||
+			newHL.PopulatedFRU.FRUID != hl.PopulatedFRU.FRUID {
+			hists = append(hists, &HWInvHist{
+				ID:        hl.ID,
+				FruId:     hl.PopulatedFRU.FRUID,
+				EventType: HWInvHistEventTypeRemoved,
+			})
+		}
+	}
+	for _, hl := range newLocs {
+		if hl == nil || hl.PopulatedFRU == nil {
+			continue
+		}
+		oldHL, ok := oldMap[hl.ID]
+		if !ok || oldHL.PopulatedFRU == nil ||
+			oldHL.PopulatedFRU.FRUID != hl.PopulatedFRU.FRUID {
+			added = append(added, &HWInvHist{
+				ID:        hl.ID,
+				FruId:     hl.PopulatedFRU.FRUID,
+				EventType: HWInvHistEventTypeAdded,
+			})
+		}
+	}
+	return gone, append(hists, added...)
+}
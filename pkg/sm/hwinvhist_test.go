@@ -157,3 +157,78 @@ func TestVerifyNormalizeHWInvHistEventType(t *testing.T) {
 		}
 	}
 }
+
+func TestDiffHWInvByLocs(t *testing.T) {
+	loc := func(id, fruid string) *HWInvByLoc {
+		hl := &HWInvByLoc{ID: id}
+		if fruid != "" {
+			hl.PopulatedFRU = &HWInvByFRU{FRUID: fruid}
+		}
+		return hl
+	}
+	hist := func(id, fruid, evType string) *HWInvHist {
+		return &HWInvHist{ID: id, FruId: fruid, EventType: evType}
+	}
+	tests := []struct {
+		oldLocs       []*HWInvByLoc
+		newLocs       []*HWInvByLoc
+		expectedGone  []string
+		expectedHists []*HWInvHist
+	}{{
+		// DIMM replaced, another DIMM removed, a drive added.
+		oldLocs: []*HWInvByLoc{
+			loc("x3000c0s1b0n0", "Node.1"),
+			loc("x3000c0s1b0n0d0", "Memory.1"),
+			loc("x3000c0s1b0n0d1", "Memory.2"),
+		},
+		newLocs: []*HWInvByLoc{
+			loc("x3000c0s1b0n0", "Node.1"),
+			loc("x3000c0s1b0n0d0", "Memory.3"),
+			loc("x3000c0s1b0n0g1k0", "Drive.1"),
+		},
+		expectedGone: []string{"x3000c0s1b0n0d1"},
+		expectedHists: []*HWInvHist{
+			hist("x3000c0s1b0n0d0", "Memory.1", HWInvHistEventTypeRemoved),
+			hist("x3000c0s1b0n0d1", "Memory.2", HWInvHistEventTypeRemoved),
+			hist("x3000c0s1b0n0d0", "Memory.3", HWInvHistEventTypeAdded),
+			hist("x3000c0s1b0n0g1k0", "Drive.1", HWInvHistEventTypeAdded),
+		},
+	}, {
+		// Empty location populated, nothing else changed.
+		oldLocs: []*HWInvByLoc{
+			loc("x3000c0s1e0", "Enclosure.1"),
+			loc("x3000c0s1e0t0", ""),
+		},
+		newLocs: []*HWInvByLoc{
+			loc("x3000c0s1e0", "Enclosure.1"),
+			loc("x3000c0s1e0t0", "PSU.1"),
+		},
+		expectedGone: []string{},
+		expectedHists: []*HWInvHist{
+			hist("x3000c0s1e0t0", "PSU.1", HWInvHistEventTypeAdded),
+		},
+	}, {
+		// Whole subtree removed.
+		oldLocs: []*HWInvByLoc{
+			loc("x1000c0s3", "Blade.1"),
+			loc("x1000c0s3b0n0", "Node.2"),
+		},
+		newLocs:      []*HWInvByLoc{},
+		expectedGone: []string{"x1000c0s3", "x1000c0s3b0n0"},
+		expectedHists: []*HWInvHist{
+			hist("x1000c0s3", "Blade.1", HWInvHistEventTypeRemoved),
+			hist("x1000c0s3b0n0", "Node.2", HWInvHistEventTypeRemoved),
+		},
+	}}
+	for i, test := range tests {
+		gone, hists := DiffHWInvByLocs(test.oldLocs, test.newLocs)
+		if !reflect.DeepEqual(test.expectedGone, gone) {
+			t.Errorf("Test %v Failed: Expected gone '%v'; Received '%v'",
+				i, test.expectedGone, gone)
+		}
+		if !reflect.DeepEqual(test.expectedHists, hists) {
+			t.Errorf("Test %v Failed: Expected history '%v'; Received '%v'",
+				i, test.expectedHists, hists)
+		}
+	}
+}
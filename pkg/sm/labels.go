@@ -0,0 +1,228 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package sm
+
+// This file implements free-form key/value labels on components and
+// Kubernetes-style selectors over them, e.g. "gpu=a100,row in (3,4)".
+
+import (
+	"regexp"
+	"strings"
+
+	base "github.com/Cray-HPE/hms-base/v2"
+	"github.com/Cray-HPE/hms-xname/xnametypes"
+)
+
+var ErrLabelBadKey = base.NewHMSError("sm",
+	"Invalid label key, must be [prefix/]name")
+var ErrLabelBadValue = base.NewHMSError("sm",
+	"Invalid label value")
+var ErrLabelBadSelector = base.NewHMSError("sm",
+	"Invalid label selector")
+var ErrLabelNoIDs = base.NewHMSError("sm",
+	"No component IDs given")
+
+// Maximum length of a label value or the name part of a key.
+const LabelNameMaxLen = 63
+
+// Maximum length of the optional DNS subdomain prefix of a key.
+const LabelPrefixMaxLen = 253
+
+var labelNameRE = regexp.MustCompile(`^[A-Za-z0-9]([-A-Za-z0-9_.]*[A-Za-z0-9])?$`)
+var labelPrefixRE = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`)
+var labelSetRE = regexp.MustCompile(`^(\S+)\s+(in|notin)\s*\((.*)\)$`)
+
+// The labels on a single component.
+type ComponentLabels struct {
+	ID     string            `json:"ID"`
+	Labels map[string]string `json:"Labels"`
+}
+
+// Changes to the labels on one or more components.  Keys with a null value
+// are removed and all others are set.  ComponentIDs is only used for bulk
+// updates.
+type CompLabelsPatch struct {
+	ComponentIDs []string           `json:"ComponentIDs,omitempty"`
+	Labels       map[string]*string `json:"Labels"`
+}
+
+// Check a label key.  Keys are case-sensitive and have the form
+// [prefix/]name, where prefix is a DNS subdomain, e.g. example.com/gpu.
+func VerifyLabelKey(key string) error {
+	name := key
+	if i := strings.LastIndex(key, "/"); i >= 0 {
+		prefix := key[:i]
+		name = key[i+1:]
+		if len(prefix) > LabelPrefixMaxLen || !labelPrefixRE.MatchString(prefix) {
+			return ErrLabelBadKey
+		}
+	}
+	if len(name) > LabelNameMaxLen || !labelNameRE.MatchString(name) {
+		return ErrLabelBadKey
+	}
+	return nil
+}
+
+// Check a label value, which may be empty.
+func VerifyLabelValue(val string) error {
+	if val != "" &&
+		(len(val) > LabelNameMaxLen || !labelNameRE.MatchString(val)) {
+		return ErrLabelBadValue
+	}
+	return nil
+}
+
+// Check all of the keys and values in labels.
+func VerifyLabels(labels map[string]string) error {
+	for key, val := range labels {
+		if err := VerifyLabelKey(key); err != nil {
+			return err
+		}
+		if err := VerifyLabelValue(val); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Normalize the xnames in the patch.
+func (lp *CompLabelsPatch) Normalize() {
+	for i, id := range lp.ComponentIDs {
+		lp.ComponentIDs[i] = xnametypes.NormalizeHMSCompID(id)
+	}
+}
+
+// Check the keys and values in the patch and, if bulk is set, that there
+// are valid component IDs to apply it to.
+func (lp *CompLabelsPatch) Verify(bulk bool) error {
+	if bulk {
+		if len(lp.ComponentIDs) == 0 {
+			return ErrLabelNoIDs
+		}
+		for _, id := range lp.ComponentIDs {
+			if !xnametypes.IsHMSCompIDValid(id) {
+				return base.ErrHMSTypeInvalid
+			}
+		}
+	}
+	for key, val := range lp.Labels {
+		if err := VerifyLabelKey(key); err != nil {
+			return err
+		}
+		if val != nil {
+			if err := VerifyLabelValue(*val); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// A single requirement of a label selector.  Components match if they have
+// label Key with one of Values, or just have label Key if Values is nil.
+// Negate inverts this, so that e.g. "key!=val" also matches components
+// without the label at all.
+type LabelRequirement struct {
+	Key    string
+	Values []string
+	Negate bool
+}
+
+// Parse a Kubernetes-style label selector: a comma-separated list of
+// requirements that must all match, each one of "key", "!key",
+// "key=value" (or "=="), "key!=value", "key in (v1,v2)" or
+// "key notin (v1,v2)".  An empty selector has no requirements.
+func ParseLabelSelector(s string) ([]LabelRequirement, error) {
+	reqs := []LabelRequirement{}
+	for _, str := range splitLabelSelector(s) {
+		str = strings.TrimSpace(str)
+		if str == "" {
+			if strings.TrimSpace(s) == "" {
+				continue
+			}
+			return nil, ErrLabelBadSelector
+		}
+		req, err := parseLabelRequirement(str)
+		if err != nil {
+			return nil, err
+		}
+		reqs = append(reqs, req)
+	}
+	return reqs, nil
+}
+
+// Split a selector at the commas that aren't inside a value set.
+func splitLabelSelector(s string) []string {
+	strs := []string{}
+	depth := 0
+	start := 0
+	for i, c := range s {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				strs = append(strs, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(strs, s[start:])
+}
+
+func parseLabelRequirement(str string) (LabelRequirement, error) {
+	req := LabelRequirement{}
+	if m := labelSetRE.FindStringSubmatch(str); m != nil {
+		req.Key = m[1]
+		req.Negate = m[2] == "notin"
+		if strings.TrimSpace(m[3]) == "" {
+			return req, ErrLabelBadSelector
+		}
+		for _, val := range strings.Split(m[3], ",") {
+			req.Values = append(req.Values, strings.TrimSpace(val))
+		}
+	} else if i := strings.Index(str, "!="); i >= 0 {
+		req.Key = strings.TrimSpace(str[:i])
+		req.Values = []string{strings.TrimSpace(str[i+2:])}
+		req.Negate = true
+	} else if i := strings.Index(str, "="); i >= 0 {
+		req.Key = strings.TrimSpace(str[:i])
+		req.Values = []string{strings.TrimSpace(strings.TrimPrefix(str[i+1:], "="))}
+	} else if strings.HasPrefix(str, "!") {
+		req.Key = strings.TrimSpace(str[1:])
+		req.Negate = true
+	} else {
+		req.Key = str
+	}
+	if VerifyLabelKey(req.Key) != nil {
+		return req, ErrLabelBadSelector
+	}
+	for _, val := range req.Values {
+		if VerifyLabelValue(val) != nil {
+			return req, ErrLabelBadSelector
+		}
+	}
+	return req, nil
+}
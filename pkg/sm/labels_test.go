@@ -0,0 +1,119 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package sm
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseLabelSelector(t *testing.T) {
+	tests := []struct {
+		in           string
+		expectedReqs []LabelRequirement
+		expectedErr  error
+	}{{
+		in:           "",
+		expectedReqs: []LabelRequirement{},
+	}, {
+		in: "gpu=a100,row in (3, 4)",
+		expectedReqs: []LabelRequirement{
+			{Key: "gpu", Values: []string{"a100"}},
+			{Key: "row", Values: []string{"3", "4"}},
+		},
+	}, {
+		in: "example.com/burn-in==done, vendor != acme,!slurm_feature,rack",
+		expectedReqs: []LabelRequirement{
+			{Key: "example.com/burn-in", Values: []string{"done"}},
+			{Key: "vendor", Values: []string{"acme"}, Negate: true},
+			{Key: "slurm_feature", Negate: true},
+			{Key: "rack"},
+		},
+	}, {
+		in: "row notin (1,2),contract=",
+		expectedReqs: []LabelRequirement{
+			{Key: "row", Values: []string{"1", "2"}, Negate: true},
+			{Key: "contract", Values: []string{""}},
+		},
+	}, {
+		in:          "gpu=a100,,row=3",
+		expectedErr: ErrLabelBadSelector,
+	}, {
+		in:          "row in ()",
+		expectedErr: ErrLabelBadSelector,
+	}, {
+		in:          "-bad=x",
+		expectedErr: ErrLabelBadSelector,
+	}, {
+		in:          "gpu=a 100",
+		expectedErr: ErrLabelBadSelector,
+	}, {
+		in:          "Example.com/gpu",
+		expectedErr: ErrLabelBadSelector,
+	}}
+	for i, test := range tests {
+		reqs, err := ParseLabelSelector(test.in)
+		if err != test.expectedErr {
+			t.Errorf("Test %d (%s) Failed: Expected error %v, got %v",
+				i, test.in, test.expectedErr, err)
+		} else if err == nil && !reflect.DeepEqual(reqs, test.expectedReqs) {
+			t.Errorf("Test %d (%s) Failed: Expected %v, got %v",
+				i, test.in, test.expectedReqs, reqs)
+		}
+	}
+}
+
+func TestVerifyCompLabelsPatch(t *testing.T) {
+	val := "a100"
+	bad := "not valid"
+	tests := []struct {
+		lp          CompLabelsPatch
+		bulk        bool
+		expectedErr error
+	}{{
+		lp: CompLabelsPatch{Labels: map[string]*string{"gpu": &val, "row": nil}},
+	}, {
+		lp: CompLabelsPatch{
+			ComponentIDs: []string{"x0c0s0b0n0"},
+			Labels:       map[string]*string{"gpu": &val},
+		},
+		bulk: true,
+	}, {
+		lp:          CompLabelsPatch{Labels: map[string]*string{"gpu": &val}},
+		bulk:        true,
+		expectedErr: ErrLabelNoIDs,
+	}, {
+		lp:          CompLabelsPatch{Labels: map[string]*string{"gpu": &bad}},
+		expectedErr: ErrLabelBadValue,
+	}, {
+		lp:          CompLabelsPatch{Labels: map[string]*string{"/gpu": &val}},
+		expectedErr: ErrLabelBadKey,
+	}}
+	for i, test := range tests {
+		err := test.lp.Verify(test.bulk)
+		if err != test.expectedErr {
+			t.Errorf("Test %d Failed: Expected error %v, got %v",
+				i, test.expectedErr, err)
+		}
+	}
+}
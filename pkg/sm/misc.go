@@ -27,6 +27,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strings"
 	"github.com/Cray-HPE/hms-xname/xnametypes"
 )
 
@@ -113,10 +114,43 @@ type Job struct {
 	Data interface{}
 }
 
+type JobArray struct {
+	Jobs []*Job `json:"Jobs"`
+}
+
+// Returns the normalized job status, or the empty string if not valid.
+func VerifyNormalizeJobStatus(status string) string {
+	for _, s := range []string{JobNotStarted, JobPending, JobComplete,
+		JobInProgress, JobError} {
+		if strings.EqualFold(status, s) {
+			return s
+		}
+	}
+	return ""
+}
+
+// Returns the normalized job type, or the empty string if not valid.
+func VerifyNormalizeJobType(jobType string) string {
+	if strings.EqualFold(jobType, JobTypeSRFP) {
+		return JobTypeSRFP
+	}
+	return ""
+}
+
 type SrfpJobData struct {
 	CompId string
 	Delay  int
 	Poll   int
+
+	// Live status from the HSM instance that owns the job.  Only CompId
+	// is stored in the database.
+	State        string `json:",omitempty"` // State being polled out of
+	RfEndpointID string `json:",omitempty"`
+	NextPoll     string `json:",omitempty"`
+	Polls        int    `json:",omitempty"`
+	Failures     int    `json:",omitempty"`
+	LastError    string `json:",omitempty"`
+	Owner        string `json:",omitempty"` // HSM instance running the job
 }
 
 func NewStateRFPollJob(xname string, delay, poll, lifetime, keepAlive int) (*Job, error) {
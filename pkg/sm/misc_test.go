@@ -120,3 +120,30 @@ func TestNewStateRFPollJob(t *testing.T) {
 		}
 	}
 }
+
+func TestVerifyNormalizeJobStatusType(t *testing.T) {
+	statuses := map[string]string{
+		"inprogress": JobInProgress,
+		"Error":      JobError,
+		"NOTSTARTED": JobNotStarted,
+		"Running":    "",
+		"":           "",
+	}
+	for in, expected := range statuses {
+		if out := VerifyNormalizeJobStatus(in); out != expected {
+			t.Errorf("FAIL: Status '%s': Expected '%s'; Received '%s'",
+				in, expected, out)
+		}
+	}
+	types := map[string]string{
+		"staterfpoll": JobTypeSRFP,
+		"StateRFPoll": JobTypeSRFP,
+		"Discovery":   "",
+	}
+	for in, expected := range types {
+		if out := VerifyNormalizeJobType(in); out != expected {
+			t.Errorf("FAIL: Type '%s': Expected '%s'; Received '%s'",
+				in, expected, out)
+		}
+	}
+}
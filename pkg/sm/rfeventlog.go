@@ -0,0 +1,72 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package sm
+
+import (
+	"strings"
+)
+
+// Redfish MessageIds (without registry prefix) that mean a FRU was added,
+// removed or replaced.  Log entries for these record the FRU at the
+// component so they can be matched with the hardware inventory history.
+var rfEventFRUMessageIds = map[string]bool{
+	"resourceadded":    true,
+	"resourceremoved":  true,
+	"resourcereplaced": true,
+	"driveinserted":    true,
+	"driveremoved":     true,
+}
+
+// True if events with this MessageId affect which FRU is at a location.
+func IsRFEventFRUMessage(msgId string) bool {
+	return rfEventFRUMessageIds[strings.ToLower(msgId)]
+}
+
+// A single processed Redfish event record, as kept in the event log.
+type RFEventLogEntry struct {
+	ID                int64    `json:"ID"`
+	ComponentID       string   `json:"ComponentID"`       // xname it concerns, if known
+	RfEndpointID      string   `json:"RedfishEndpointID"` // BMC that sent it
+	Timestamp         string   `json:"Timestamp"`         // When HSM received it
+	EventTimestamp    string   `json:"EventTimestamp,omitempty"`
+	EventId           string   `json:"EventId,omitempty"`
+	Registry          string   `json:"Registry"`
+	RegistryVersion   string   `json:"RegistryVersion,omitempty"`
+	MessageId         string   `json:"MessageId"`
+	Severity          string   `json:"Severity,omitempty"`
+	Message           string   `json:"Message,omitempty"`
+	MessageArgs       []string `json:"MessageArgs,omitempty"`
+	OriginOfCondition string   `json:"OriginOfCondition,omitempty"`
+	FRUID             string   `json:"FRUID,omitempty"` // For FRU messages
+
+	// Hardware history of ComponentID around the time of a FRU message.
+	// Filled in on query only.
+	HWInvHistory []*HWInvHist `json:"HWInvHistory,omitempty"`
+}
+
+// A page of event log entries, most recent first.  If there are more,
+// NextMarker is the marker to pass to get the next page.
+type RFEventLogPage struct {
+	Events     []*RFEventLogEntry `json:"Events"`
+	NextMarker int64              `json:"NextMarker,omitempty"`
+}
@@ -0,0 +1,121 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package sm
+
+import (
+	"encoding/json"
+
+	rf "github.com/Cray-HPE/hms-smd/v2/pkg/redfish"
+)
+
+// Where a sensor reading was taken from.
+const (
+	SensorSourceSensors            = "Sensors"
+	SensorSourceEnvironmentMetrics = "EnvironmentMetrics"
+)
+
+// A point-in-time snapshot of the sensor readings for a single component,
+// read on demand from its Redfish endpoint.  Timestamp is when the readings
+// were taken from the endpoint, which will be in the past if Cached is set.
+type CompSensors struct {
+	ID                string           `json:"ID"`
+	Type              string           `json:"Type"`
+	RedfishEndpointID string           `json:"RedfishEndpointID"`
+	Timestamp         string           `json:"Timestamp"`
+	Cached            bool             `json:"Cached"`
+	Sensors           []*SensorReading `json:"Sensors"`
+}
+
+// A single sensor reading, from either a Redfish Sensor or one of the
+// excerpts in an EnvironmentMetrics resource.
+type SensorReading struct {
+	OdataID            string      `json:"OdataID"`
+	Name               string      `json:"Name"`
+	Source             string      `json:"Source"`
+	ReadingType        string      `json:"ReadingType,omitempty"`
+	Reading            json.Number `json:"Reading,omitempty"`
+	ReadingUnits       string      `json:"ReadingUnits,omitempty"`
+	PhysicalContext    string      `json:"PhysicalContext,omitempty"`
+	PhysicalSubContext string      `json:"PhysicalSubContext,omitempty"`
+	Health             string      `json:"Health,omitempty"`
+	State              string      `json:"State,omitempty"`
+}
+
+// Create a SensorReading from a Redfish Sensor.  Returns nil if s is nil.
+func NewSensorReading(s *rf.Sensor) *SensorReading {
+	if s == nil {
+		return nil
+	}
+	sr := &SensorReading{
+		OdataID:            s.Oid,
+		Name:               s.Name,
+		Source:             SensorSourceSensors,
+		ReadingType:        s.ReadingType,
+		Reading:            s.Reading,
+		ReadingUnits:       s.ReadingUnits,
+		PhysicalContext:    s.PhysicalContext,
+		PhysicalSubContext: s.PhysicalSubContext,
+		Health:             string(s.Status.Health),
+		State:              string(s.Status.State),
+	}
+	if sr.Name == "" {
+		sr.Name = s.Id
+	}
+	return sr
+}
+
+// Create a SensorReading from a SensorExcerpt in the EnvironmentMetrics
+// resource at metricsURI.  prop is the name of the EnvironmentMetrics
+// property holding the excerpt and, with units, describes the reading when
+// the excerpt does not.  The excerpt is identified by the Sensor it was
+// taken from if it says so.  Returns nil if e is nil.
+func NewSensorReadingFromExcerpt(
+	metricsURI, prop, readingType, units string,
+	e *rf.SensorExcerpt,
+) *SensorReading {
+	if e == nil {
+		return nil
+	}
+	sr := &SensorReading{
+		OdataID:            e.DataSourceUri,
+		Name:               e.Name,
+		Source:             SensorSourceEnvironmentMetrics,
+		ReadingType:        readingType,
+		Reading:            e.Reading,
+		ReadingUnits:       e.ReadingUnits,
+		PhysicalContext:    e.PhysicalContext,
+		PhysicalSubContext: e.PhysicalSubContext,
+		Health:             string(e.Status.Health),
+		State:              string(e.Status.State),
+	}
+	if sr.OdataID == "" {
+		sr.OdataID = metricsURI + "#/" + prop
+	}
+	if sr.Name == "" {
+		sr.Name = prop
+	}
+	if sr.ReadingUnits == "" {
+		sr.ReadingUnits = units
+	}
+	return sr
+}
@@ -0,0 +1,102 @@
+// MIT License
+//
+// (C) Copyright [2026] Hewlett Packard Enterprise Development LP
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included
+// in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL
+// THE AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR
+// OTHER LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE,
+// ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR
+// OTHER DEALINGS IN THE SOFTWARE.
+
+package sm
+
+import (
+	"reflect"
+	"testing"
+
+	rf "github.com/Cray-HPE/hms-smd/v2/pkg/redfish"
+)
+
+// Test sensor reading functions
+func TestNewSensorReading(t *testing.T) {
+	if sr := NewSensorReading(nil); sr != nil {
+		t.Errorf("Expected nil for nil Sensor, got %+v", sr)
+	}
+	s := &rf.Sensor{
+		Oid:          "/redfish/v1/Chassis/Enclosure/Sensors/Temp1",
+		Id:           "Temp1",
+		ReadingType:  "Temperature",
+		Reading:      "31",
+		ReadingUnits: "Cel",
+		Status:       rf.StatusRF{Health: rf.HealthRF("Critical"), State: "Enabled"},
+	}
+	expected := &SensorReading{
+		OdataID:      "/redfish/v1/Chassis/Enclosure/Sensors/Temp1",
+		Name:         "Temp1",
+		Source:       SensorSourceSensors,
+		ReadingType:  "Temperature",
+		Reading:      "31",
+		ReadingUnits: "Cel",
+		Health:       "Critical",
+		State:        "Enabled",
+	}
+	if sr := NewSensorReading(s); !reflect.DeepEqual(expected, sr) {
+		t.Errorf("Expected %+v, got %+v", expected, sr)
+	}
+}
+
+func TestNewSensorReadingFromExcerpt(t *testing.T) {
+	uri := "/redfish/v1/Chassis/Enclosure/EnvironmentMetrics"
+	tests := []struct {
+		e        *rf.SensorExcerpt
+		expected *SensorReading
+	}{{
+		e:        nil,
+		expected: nil,
+	}, {
+		// Excerpt only, fill in from the property.
+		e: &rf.SensorExcerpt{Reading: "55"},
+		expected: &SensorReading{
+			OdataID:      uri + "#/HumidityPercent",
+			Name:         "HumidityPercent",
+			Source:       SensorSourceEnvironmentMetrics,
+			ReadingType:  "Humidity",
+			Reading:      "55",
+			ReadingUnits: "%",
+		},
+	}, {
+		// Excerpt of a Sensor, use its URI.
+		e: &rf.SensorExcerpt{
+			DataSourceUri: "/redfish/v1/Chassis/Enclosure/Sensors/Humidity",
+			Name:          "Ambient Humidity",
+			Reading:       "41",
+			ReadingUnits:  "%RH",
+		},
+		expected: &SensorReading{
+			OdataID:      "/redfish/v1/Chassis/Enclosure/Sensors/Humidity",
+			Name:         "Ambient Humidity",
+			Source:       SensorSourceEnvironmentMetrics,
+			ReadingType:  "Humidity",
+			Reading:      "41",
+			ReadingUnits: "%RH",
+		},
+	}}
+	for i, test := range tests {
+		sr := NewSensorReadingFromExcerpt(uri, "HumidityPercent", "Humidity", "%", test.e)
+		if !reflect.DeepEqual(test.expected, sr) {
+			t.Errorf("Test %d: Expected %+v, got %+v", i, test.expected, sr)
+		}
+	}
+}